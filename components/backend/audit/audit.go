@@ -0,0 +1,418 @@
+// Package audit records mutating API calls to configurable sinks (stdout JSON, file, webhook).
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Outcomes recorded for an audited request
+const (
+	OutcomeSuccess = "success"
+	OutcomeDenied  = "denied"
+	OutcomeFailure = "failure"
+	OutcomeFlagged = "flagged" // allowed, but matched a policy (e.g. a guardrail warning)
+)
+
+// Record is one audited API call. Request bodies are never recorded.
+type Record struct {
+	Time      time.Time `json:"time"`
+	RequestID string    `json:"requestId"`
+	User      string    `json:"user,omitempty"`
+	APIKeyID  string    `json:"apiKeyId,omitempty"`
+	TokenID   string    `json:"sessionTokenId,omitempty"` // session token the call was made with
+	Verb      string    `json:"verb"`
+	Resource  string    `json:"resource"` // route template, e.g. /api/projects/:projectName/agentic-sessions
+	Path      string    `json:"path"`
+	Project   string    `json:"project,omitempty"`
+	Status    int       `json:"status"`
+	Outcome   string    `json:"outcome"`
+	LatencyMs int64     `json:"latencyMs"`
+	ClientIP  string    `json:"clientIp,omitempty"`
+	Detail    string    `json:"detail,omitempty"` // what matched, for records not tied to one API call
+}
+
+// OutcomeForStatus classifies an HTTP status code
+func OutcomeForStatus(status int) string {
+	switch {
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		return OutcomeDenied
+	case status >= 400:
+		return OutcomeFailure
+	default:
+		return OutcomeSuccess
+	}
+}
+
+// Sink receives audit records
+type Sink interface {
+	Write(r Record) error
+}
+
+// recentCapacity bounds the in-memory records served by Query when no file sink is set
+const recentCapacity = 5000
+
+var (
+	mu       sync.RWMutex
+	sinks    []Sink
+	fileSink *FileSink
+	recent   []Record
+	// external names the configured sinks whose records leave the backend (stdout, webhook)
+	external []string
+)
+
+// Configure replaces the active sinks. kinds is a comma-separated list of
+// stdout, file and webhook; file needs filePath and webhook needs webhookURL.
+func Configure(kinds, filePath, webhookURL, webhookToken string) error {
+	var configured []Sink
+	var file *FileSink
+	var ext []string
+	for _, kind := range strings.Split(kinds, ",") {
+		switch strings.TrimSpace(kind) {
+		case "":
+		case "stdout":
+			configured = append(configured, NewWriterSink(os.Stdout))
+			ext = append(ext, "stdout")
+		case "file":
+			if filePath == "" {
+				return fmt.Errorf("file audit sink requires a file path")
+			}
+			file = &FileSink{Path: filePath}
+			configured = append(configured, file)
+		case "webhook":
+			if webhookURL == "" {
+				return fmt.Errorf("webhook audit sink requires a URL")
+			}
+			configured = append(configured, NewWebhookSink(webhookURL, webhookToken))
+			ext = append(ext, "webhook")
+		default:
+			return fmt.Errorf("unknown audit sink %q", kind)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	sinks = configured
+	fileSink = file
+	external = ext
+	return nil
+}
+
+// ConfigureFromEnv configures sinks from AUDIT_SINKS (default "stdout"), AUDIT_LOG_FILE,
+// AUDIT_WEBHOOK_URL and AUDIT_WEBHOOK_TOKEN
+func ConfigureFromEnv() error {
+	kinds := os.Getenv("AUDIT_SINKS")
+	if kinds == "" {
+		kinds = "stdout"
+	}
+	return Configure(kinds, os.Getenv("AUDIT_LOG_FILE"), os.Getenv("AUDIT_WEBHOOK_URL"), os.Getenv("AUDIT_WEBHOOK_TOKEN"))
+}
+
+// Emit writes r to every sink and keeps it for Query. Sink failures are logged,
+// never returned, so auditing cannot fail a request.
+func Emit(r Record) {
+	mu.Lock()
+	recent = append(recent, r)
+	if len(recent) > recentCapacity {
+		recent = recent[len(recent)-recentCapacity:]
+	}
+	active := sinks
+	mu.Unlock()
+
+	for _, s := range active {
+		if err := s.Write(r); err != nil {
+			log.Printf("audit: failed to write record %s: %v", r.RequestID, err)
+		}
+	}
+}
+
+// Filter selects records for Query; zero fields match everything
+type Filter struct {
+	User    string
+	Project string
+	Verb    string
+	Outcome string
+	Since   time.Time
+	Until   time.Time
+	Limit   int
+}
+
+func (f Filter) matches(r Record) bool {
+	if f.User != "" && r.User != f.User {
+		return false
+	}
+	if f.Project != "" && r.Project != f.Project {
+		return false
+	}
+	if f.Verb != "" && !strings.EqualFold(r.Verb, f.Verb) {
+		return false
+	}
+	if f.Outcome != "" && r.Outcome != f.Outcome {
+		return false
+	}
+	if !f.Since.IsZero() && r.Time.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && r.Time.After(f.Until) {
+		return false
+	}
+	return true
+}
+
+// Query returns matching records newest first, at most f.Limit (default 100). It reads
+// the file sink when one is configured, otherwise the records kept in memory.
+func Query(f Filter) ([]Record, error) {
+	if f.Limit <= 0 {
+		f.Limit = 100
+	}
+
+	mu.RLock()
+	file := fileSink
+	var all []Record
+	if file == nil {
+		all = append(all, recent...)
+	}
+	mu.RUnlock()
+
+	if file != nil {
+		var err error
+		if all, err = file.readAll(); err != nil {
+			return nil, err
+		}
+	}
+
+	out := []Record{}
+	for i := len(all) - 1; i >= 0 && len(out) < f.Limit; i-- {
+		if f.matches(all[i]) {
+			out = append(out, all[i])
+		}
+	}
+	return out, nil
+}
+
+// PseudonymizeUser replaces userID with pseudonym in the records kept in memory and in
+// the file sink, including path segments naming the user, and drops their client IP.
+// It returns the number of records changed. Records already sent to stdout or a webhook
+// are beyond reach; ExternalSinks names them.
+func PseudonymizeUser(userID, pseudonym string) (int, error) {
+	if userID == "" {
+		return 0, fmt.Errorf("userID is required")
+	}
+	rewrite := func(r *Record) bool {
+		changed := false
+		if r.User == userID {
+			r.User = pseudonym
+			r.ClientIP = ""
+			changed = true
+		}
+		if p := strings.ReplaceAll(r.Path+"/", "/"+userID+"/", "/"+pseudonym+"/"); p != r.Path+"/" {
+			r.Path = strings.TrimSuffix(p, "/")
+			changed = true
+		}
+		return changed
+	}
+
+	mu.Lock()
+	changed := 0
+	for i := range recent {
+		if rewrite(&recent[i]) {
+			changed++
+		}
+	}
+	file := fileSink
+	mu.Unlock()
+
+	if file == nil {
+		return changed, nil
+	}
+	// With a file sink, Query reads the file, so its count is the one that matters
+	return file.rewrite(rewrite)
+}
+
+// ExternalSinks names the configured sinks whose records can't be rewritten afterwards
+func ExternalSinks() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	return append([]string(nil), external...)
+}
+
+// WriterSink writes records as JSON lines, e.g. to stdout for log collection
+type WriterSink struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+// NewWriterSink returns a sink writing JSON lines to w
+func NewWriterSink(w io.Writer) *WriterSink {
+	return &WriterSink{enc: json.NewEncoder(w)}
+}
+
+func (s *WriterSink) Write(r Record) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.enc.Encode(r)
+}
+
+// FileSink appends records as JSON lines to Path
+type FileSink struct {
+	Path string
+	mu   sync.Mutex
+}
+
+func (s *FileSink) Write(r Record) error {
+	b, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := os.MkdirAll(filepath.Dir(s.Path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(s.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write(append(b, '\n'))
+	return err
+}
+
+func (s *FileSink) readAll() ([]Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f, err := os.Open(s.Path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var records []Record
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var r Record
+		if err := json.Unmarshal(scanner.Bytes(), &r); err == nil {
+			records = append(records, r)
+		}
+	}
+	return records, scanner.Err()
+}
+
+// rewrite applies fn to every record in the file, replacing it atomically, and returns
+// the number of records fn changed. Unreadable lines are kept as they are.
+func (s *FileSink) rewrite(fn func(r *Record) bool) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, err := os.ReadFile(s.Path)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	var out bytes.Buffer
+	changed := 0
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		if len(line) == 0 {
+			continue
+		}
+		var r Record
+		if json.Unmarshal(line, &r) == nil && fn(&r) {
+			b, err := json.Marshal(r)
+			if err != nil {
+				return 0, err
+			}
+			line = b
+			changed++
+		}
+		out.Write(line)
+		out.WriteByte('\n')
+	}
+	if changed == 0 {
+		return 0, nil
+	}
+
+	tmp := s.Path + ".tmp"
+	if err := os.WriteFile(tmp, out.Bytes(), 0o600); err != nil {
+		return 0, err
+	}
+	if err := os.Rename(tmp, s.Path); err != nil {
+		_ = os.Remove(tmp)
+		return 0, err
+	}
+	return changed, nil
+}
+
+// webhookQueueSize bounds records waiting for delivery; beyond it records are dropped
+const webhookQueueSize = 1000
+
+// WebhookSink POSTs each record as JSON to URL from a background goroutine
+type WebhookSink struct {
+	URL    string
+	Token  string
+	client *http.Client
+	queue  chan Record
+}
+
+// NewWebhookSink starts a sink delivering records to url, with token as a bearer token if set
+func NewWebhookSink(url, token string) *WebhookSink {
+	s := &WebhookSink{URL: url, Token: token, client: &http.Client{Timeout: 10 * time.Second}, queue: make(chan Record, webhookQueueSize)}
+	go s.run()
+	return s
+}
+
+func (s *WebhookSink) Write(r Record) error {
+	select {
+	case s.queue <- r:
+		return nil
+	default:
+		return fmt.Errorf("webhook queue full, record dropped")
+	}
+}
+
+func (s *WebhookSink) run() {
+	for r := range s.queue {
+		if err := s.post(r); err != nil {
+			log.Printf("audit: webhook delivery failed for %s: %v", r.RequestID, err)
+		}
+	}
+}
+
+func (s *WebhookSink) post(r Record) error {
+	b, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, s.URL, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.Token)
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package audit
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestOutcomeForStatus(t *testing.T) {
+	cases := map[int]string{
+		http.StatusCreated:             OutcomeSuccess,
+		http.StatusForbidden:           OutcomeDenied,
+		http.StatusUnauthorized:        OutcomeDenied,
+		http.StatusConflict:            OutcomeFailure,
+		http.StatusInternalServerError: OutcomeFailure,
+	}
+	for status, want := range cases {
+		if got := OutcomeForStatus(status); got != want {
+			t.Errorf("OutcomeForStatus(%d) = %q, want %q", status, got, want)
+		}
+	}
+}
+
+func TestFileSinkQuery(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit", "audit.jsonl")
+	if err := Configure("file", path, "", ""); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = Configure("", "", "", "") })
+
+	start := time.Now().UTC()
+	Emit(Record{Time: start, RequestID: "r1", User: "alice", Verb: "POST", Project: "team-a", Outcome: OutcomeSuccess})
+	Emit(Record{Time: start.Add(time.Second), RequestID: "r2", User: "bob", Verb: "DELETE", Project: "team-a", Outcome: OutcomeDenied})
+	Emit(Record{Time: start.Add(2 * time.Second), RequestID: "r3", User: "alice", Verb: "PUT", Project: "team-b", Outcome: OutcomeSuccess})
+
+	got, err := Query(Filter{User: "alice"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 2 || got[0].RequestID != "r3" || got[1].RequestID != "r1" {
+		t.Fatalf("Query(user=alice) = %+v, want r3, r1", got)
+	}
+
+	got, _ = Query(Filter{Project: "team-a", Outcome: OutcomeDenied})
+	if len(got) != 1 || got[0].RequestID != "r2" {
+		t.Fatalf("Query(project=team-a, outcome=denied) = %+v, want r2", got)
+	}
+
+	got, _ = Query(Filter{Since: start.Add(time.Second), Limit: 1})
+	if len(got) != 1 || got[0].RequestID != "r3" {
+		t.Fatalf("Query(since, limit=1) = %+v, want r3", got)
+	}
+}
+
+func TestPseudonymizeUser(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	if err := Configure("file", path, "", ""); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = Configure("", "", "", "") })
+
+	Emit(Record{RequestID: "r1", User: "alice", Path: "/api/projects/team-a/agentic-sessions", ClientIP: "10.0.0.1"})
+	Emit(Record{RequestID: "r2", User: "admin", Path: "/api/users/alice/data"})
+	Emit(Record{RequestID: "r3", User: "alicia", Path: "/api/projects/alice-team"})
+
+	n, err := PseudonymizeUser("alice", "erased-1234")
+	if err != nil || n != 2 {
+		t.Fatalf("PseudonymizeUser = %d, %v, want 2 records", n, err)
+	}
+	if got, _ := Query(Filter{User: "alice"}); len(got) != 0 {
+		t.Fatalf("records still name alice: %+v", got)
+	}
+	got, _ := Query(Filter{User: "erased-1234"})
+	if len(got) != 1 || got[0].RequestID != "r1" || got[0].ClientIP != "" {
+		t.Fatalf("Query(user=erased-1234) = %+v, want r1 without client IP", got)
+	}
+	got, _ = Query(Filter{User: "admin"})
+	if len(got) != 1 || got[0].Path != "/api/users/erased-1234/data" {
+		t.Fatalf("admin record = %+v, want pseudonymized path", got)
+	}
+	got, _ = Query(Filter{User: "alicia"})
+	if len(got) != 1 || got[0].Path != "/api/projects/alice-team" {
+		t.Fatalf("unrelated record changed: %+v", got)
+	}
+	if ext := ExternalSinks(); len(ext) != 0 {
+		t.Fatalf("ExternalSinks() = %v, want none", ext)
+	}
+}
+
+func TestWebhookSink(t *testing.T) {
+	received := make(chan Record, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			t.Errorf("missing bearer token")
+		}
+		var rec Record
+		_ = json.NewDecoder(r.Body).Decode(&rec)
+		received <- rec
+	}))
+	defer srv.Close()
+
+	sink := NewWebhookSink(srv.URL, "secret")
+	if err := sink.Write(Record{RequestID: "r1"}); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case rec := <-received:
+		if rec.RequestID != "r1" {
+			t.Fatalf("webhook received %q, want r1", rec.RequestID)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("webhook not called")
+	}
+}
+
+func TestConfigureRejectsIncompleteSinks(t *testing.T) {
+	if err := Configure("file", "", "", ""); err == nil {
+		t.Error("file sink without path should fail")
+	}
+	if err := Configure("webhook", "", "", ""); err == nil {
+		t.Error("webhook sink without URL should fail")
+	}
+	if err := Configure("syslog", "", "", ""); err == nil {
+		t.Error("unknown sink should fail")
+	}
+}
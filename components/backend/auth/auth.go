@@ -0,0 +1,119 @@
+// Package auth identifies API callers and builds the Kubernetes clients that act
+// as them. Each Authenticator handles one kind of credential (OpenShift OAuth
+// proxy tokens, OIDC ID tokens, static development tokens); the backend tries the
+// configured ones in order, so handlers never depend on a particular identity
+// provider and can be tested without a live cluster.
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// ContextKey is the gin context key holding the *Result of an authenticated request
+const ContextKey = "authResult"
+
+// ErrNoToken is returned by Clients when the identity carries no token to act with
+var ErrNoToken = errors.New("no user token")
+
+// Identity is an authenticated caller
+type Identity struct {
+	// Username and Groups are the caller's Kubernetes identity
+	Username string   `json:"username"`
+	Groups   []string `json:"groups,omitempty"`
+	// Name and Email are for display and auditing
+	Name  string `json:"name,omitempty"`
+	Email string `json:"email,omitempty"`
+	// Token is the caller's own Kubernetes token. Identities without one are
+	// impersonated by the backend, so headers claiming another identity are ignored.
+	Token string `json:"-"`
+}
+
+// Authenticator identifies callers from one kind of credential
+type Authenticator interface {
+	// Name identifies the backend in AUTH_BACKENDS and logs
+	Name() string
+	// Authenticate returns the caller's identity, nil when the request carries no
+	// credential this backend handles, or an error when the credential is invalid
+	Authenticate(r *http.Request) (*Identity, error)
+	// Clients returns Kubernetes clients acting as id
+	Clients(id *Identity) (kubernetes.Interface, dynamic.Interface, error)
+}
+
+// Result is the outcome of authenticating a request
+type Result struct {
+	Authenticator Authenticator
+	Identity      *Identity
+}
+
+// Clients returns Kubernetes clients acting as the authenticated caller
+func (r *Result) Clients() (kubernetes.Interface, dynamic.Interface, error) {
+	return r.Authenticator.Clients(r.Identity)
+}
+
+// Authenticate tries authenticators in order; the first that recognizes the
+// request's credential decides. It returns nil when none does.
+func Authenticate(r *http.Request, authenticators []Authenticator) (*Result, error) {
+	for _, a := range authenticators {
+		id, err := a.Authenticate(r)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", a.Name(), err)
+		}
+		if id != nil {
+			return &Result{Authenticator: a, Identity: id}, nil
+		}
+	}
+	return nil, nil
+}
+
+// BearerToken returns the token of the request's Authorization: Bearer header
+func BearerToken(r *http.Request) string {
+	parts := strings.SplitN(r.Header.Get("Authorization"), " ", 2)
+	if len(parts) != 2 || !strings.EqualFold(parts[0], "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(parts[1])
+}
+
+// TokenClients returns clients authenticating to Kubernetes with token only,
+// never falling back to base's own credentials
+func TokenClients(base *rest.Config, token string) (kubernetes.Interface, dynamic.Interface, error) {
+	if token == "" {
+		return nil, nil, ErrNoToken
+	}
+	if base == nil {
+		return nil, nil, errors.New("no base Kubernetes config")
+	}
+	cfg := rest.AnonymousClientConfig(base)
+	cfg.BearerToken = token
+	return newClients(cfg)
+}
+
+// ImpersonatingClients returns clients using base's credentials to impersonate
+// username and groups
+func ImpersonatingClients(base *rest.Config, username string, groups []string) (kubernetes.Interface, dynamic.Interface, error) {
+	if base == nil {
+		return nil, nil, errors.New("no base Kubernetes config")
+	}
+	cfg := rest.CopyConfig(base)
+	cfg.Impersonate = rest.ImpersonationConfig{UserName: username, Groups: groups}
+	return newClients(cfg)
+}
+
+func newClients(cfg *rest.Config) (kubernetes.Interface, dynamic.Interface, error) {
+	kc, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	dc, err := dynamic.NewForConfig(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	return kc, dc, nil
+}
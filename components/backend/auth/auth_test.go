@@ -0,0 +1,164 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"ambient-code-backend/oidc"
+
+	"github.com/golang-jwt/jwt/v5"
+	k8sruntime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+)
+
+func request(headers map[string]string) *http.Request {
+	r := httptest.NewRequest(http.MethodGet, "/api/projects", nil)
+	for k, v := range headers {
+		r.Header.Set(k, v)
+	}
+	return r
+}
+
+func TestAuthenticateChain(t *testing.T) {
+	static := &StaticTokens{Tokens: map[string]Identity{"dev-alice": {Username: "alice", Groups: []string{"developers"}}}}
+	openshift := &OpenShift{Config: &rest.Config{Host: "https://api.example.com"}}
+	chain := []Authenticator{static, openshift}
+
+	tests := []struct {
+		name     string
+		headers  map[string]string
+		backend  string
+		username string
+		token    string
+	}{
+		{name: "static token", headers: map[string]string{"Authorization": "Bearer dev-alice"}, backend: "static", username: "alice"},
+		{name: "other bearer token", headers: map[string]string{"Authorization": "Bearer sha256~abc"}, backend: "openshift", token: "sha256~abc"},
+		{name: "raw authorization", headers: map[string]string{"Authorization": "sha256~abc"}, backend: "openshift", token: "sha256~abc"},
+		{
+			name:     "oauth proxy",
+			headers:  map[string]string{"X-Forwarded-Access-Token": "sha256~def", "X-Forwarded-User": "kube:admin", "X-Forwarded-Groups": "a,b"},
+			backend:  "openshift",
+			username: "kube:admin",
+			token:    "sha256~def",
+		},
+		{name: "no credential"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := Authenticate(request(tt.headers), chain)
+			if err != nil {
+				t.Fatalf("Authenticate: %v", err)
+			}
+			if tt.backend == "" {
+				if res != nil {
+					t.Fatalf("Authenticate = %s %+v, want nil", res.Authenticator.Name(), res.Identity)
+				}
+				return
+			}
+			if res == nil || res.Authenticator.Name() != tt.backend {
+				t.Fatalf("Authenticate = %+v, want backend %s", res, tt.backend)
+			}
+			if res.Identity.Username != tt.username || res.Identity.Token != tt.token {
+				t.Errorf("identity = %+v", res.Identity)
+			}
+		})
+	}
+
+	res, _ := Authenticate(request(map[string]string{"X-Forwarded-User": "bob"}), chain)
+	if _, _, err := res.Clients(); err != ErrNoToken {
+		t.Errorf("Clients without token = %v, want ErrNoToken", err)
+	}
+	res, _ = Authenticate(request(map[string]string{"Authorization": "Bearer sha256~abc"}), chain)
+	if kc, dc, err := res.Clients(); err != nil || kc == nil || dc == nil {
+		t.Errorf("token clients = %v, %v, %v", kc, dc, err)
+	}
+}
+
+func TestStaticTokensWithFakeClients(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	static := &StaticTokens{
+		Tokens: map[string]Identity{"dev-alice": {Username: "alice"}},
+		ClientsFunc: func(id *Identity) (kubernetes.Interface, dynamic.Interface, error) {
+			return clientset, dynamicfake.NewSimpleDynamicClient(k8sruntime.NewScheme()), nil
+		},
+	}
+	res, err := Authenticate(request(map[string]string{"Authorization": "Bearer dev-alice"}), []Authenticator{static})
+	if err != nil || res == nil {
+		t.Fatalf("Authenticate = %+v, %v", res, err)
+	}
+	if res.Identity.Name != "alice" || res.Identity.Token != "" {
+		t.Errorf("identity = %+v", res.Identity)
+	}
+	if kc, _, err := res.Clients(); err != nil || kc != clientset {
+		t.Errorf("Clients = %v, %v; want the fake clientset", kc, err)
+	}
+	if res, _ := Authenticate(request(map[string]string{"Authorization": "Bearer dev-mallory"}), []Authenticator{static}); res != nil {
+		t.Errorf("unknown static token authenticated as %+v", res.Identity)
+	}
+}
+
+func TestOIDCSkipsOtherIssuers(t *testing.T) {
+	o := &OIDC{Verifier: oidc.NewVerifier(oidc.Config{IssuerURL: "https://idp.example.com", Audience: "vteam", UsernameClaim: "email"})}
+	unsigned := func(iss string) string {
+		token, err := jwt.NewWithClaims(jwt.SigningMethodNone, jwt.MapClaims{"iss": iss, "email": "alice@example.com"}).
+			SignedString(jwt.UnsafeAllowNoneSignatureType)
+		if err != nil {
+			t.Fatalf("SignedString: %v", err)
+		}
+		return token
+	}
+
+	id, err := o.Authenticate(request(map[string]string{"Authorization": "Bearer " + unsigned("https://other.example.com")}))
+	if id != nil || err != nil {
+		t.Errorf("other issuer = %+v, %v; want nil, nil", id, err)
+	}
+	chain := []Authenticator{o, &OpenShift{}}
+	if _, err := Authenticate(request(map[string]string{"Authorization": "Bearer " + unsigned("https://idp.example.com")}), chain); err == nil || !strings.HasPrefix(err.Error(), "oidc: ") {
+		t.Errorf("unsigned token of the issuer = %v, want an oidc error", err)
+	}
+}
+
+func TestFromEnv(t *testing.T) {
+	t.Setenv("OIDC_ISSUER_URL", "")
+	t.Setenv("AUTH_BACKENDS", "")
+	authenticators, err := FromEnv(nil)
+	if err != nil || len(authenticators) != 1 || authenticators[0].Name() != "openshift" {
+		t.Errorf("default backends = %v, %v", authenticators, err)
+	}
+
+	path := filepath.Join(t.TempDir(), "tokens.yaml")
+	if err := os.WriteFile(path, []byte("dev-alice:\n  username: alice\n  groups: [developers]\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("AUTH_BACKENDS", "static, openshift")
+	t.Setenv("AUTH_STATIC_TOKENS_FILE", path)
+	authenticators, err = FromEnv(nil)
+	if err != nil || len(authenticators) != 2 {
+		t.Fatalf("static,openshift = %v, %v", authenticators, err)
+	}
+	if static := authenticators[0].(*StaticTokens); static.Tokens["dev-alice"].Groups[0] != "developers" {
+		t.Errorf("static tokens = %+v", static.Tokens)
+	}
+
+	for backends, file := range map[string]string{"oidc": path, "static": "", "kerberos": path} {
+		t.Setenv("AUTH_BACKENDS", backends)
+		t.Setenv("AUTH_STATIC_TOKENS_FILE", file)
+		if _, err := FromEnv(nil); err == nil {
+			t.Errorf("AUTH_BACKENDS=%s accepted", backends)
+		}
+	}
+	if err := os.WriteFile(path, []byte("dev-anon: {groups: [a]}\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadStaticTokens(path); err == nil {
+		t.Error("token without username accepted")
+	}
+}
@@ -0,0 +1,57 @@
+package auth
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"ambient-code-backend/oidc"
+
+	"k8s.io/client-go/rest"
+)
+
+// FromEnv returns the authenticators named by AUTH_BACKENDS, in order. The default
+// is "oidc,openshift" when OIDC_ISSUER_URL is set and "openshift" otherwise. The
+// static backend reads its tokens from AUTH_STATIC_TOKENS_FILE.
+func FromEnv(base *rest.Config) ([]Authenticator, error) {
+	oidcCfg, err := oidc.ConfigFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	names := strings.TrimSpace(os.Getenv("AUTH_BACKENDS"))
+	if names == "" {
+		names = "openshift"
+		if oidcCfg != nil {
+			names = "oidc,openshift"
+		}
+	}
+
+	var authenticators []Authenticator
+	for _, name := range strings.Split(names, ",") {
+		switch strings.TrimSpace(name) {
+		case "openshift":
+			authenticators = append(authenticators, &OpenShift{Config: base})
+		case "oidc":
+			if oidcCfg == nil {
+				return nil, fmt.Errorf("AUTH_BACKENDS includes oidc but OIDC_ISSUER_URL is not set")
+			}
+			log.Printf("OIDC authentication enabled (issuer=%s audience=%s usernameClaim=%s)", oidcCfg.IssuerURL, oidcCfg.Audience, oidcCfg.UsernameClaim)
+			authenticators = append(authenticators, &OIDC{Verifier: oidc.NewVerifier(*oidcCfg), Config: base})
+		case "static":
+			path := os.Getenv("AUTH_STATIC_TOKENS_FILE")
+			if path == "" {
+				return nil, fmt.Errorf("AUTH_BACKENDS includes static but AUTH_STATIC_TOKENS_FILE is not set")
+			}
+			tokens, err := LoadStaticTokens(path)
+			if err != nil {
+				return nil, fmt.Errorf("static tokens: %w", err)
+			}
+			log.Printf("WARNING: static token authentication enabled with %d tokens; use it for development only", len(tokens))
+			authenticators = append(authenticators, &StaticTokens{Tokens: tokens, Config: base})
+		default:
+			return nil, fmt.Errorf("unknown authentication backend %q in AUTH_BACKENDS", name)
+		}
+	}
+	return authenticators, nil
+}
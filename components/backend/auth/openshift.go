@@ -0,0 +1,64 @@
+package auth
+
+import (
+	"net/http"
+	"strings"
+
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// OpenShift authenticates requests passed through the OpenShift OAuth proxy: the
+// caller's token (Authorization or X-Forwarded-Access-Token) is used as-is against
+// Kubernetes and the proxy's X-Forwarded-* headers describe the caller. It also
+// covers any install where callers present their own Kubernetes bearer token.
+type OpenShift struct {
+	// Config is the base client config the caller's token is used with
+	Config *rest.Config
+}
+
+// Name implements Authenticator
+func (o *OpenShift) Name() string { return "openshift" }
+
+// Authenticate implements Authenticator. Token validity is left to the Kubernetes
+// API, which checks it on every call.
+func (o *OpenShift) Authenticate(r *http.Request) (*Identity, error) {
+	id := &Identity{Token: requestToken(r)}
+	if v := r.Header.Get("X-Forwarded-User"); v != "" {
+		id.Username = v
+	}
+	// Prefer preferred username; fallback to user id
+	id.Name = r.Header.Get("X-Forwarded-Preferred-Username")
+	if id.Name == "" {
+		id.Name = id.Username
+	}
+	id.Email = r.Header.Get("X-Forwarded-Email")
+	if v := r.Header.Get("X-Forwarded-Groups"); v != "" {
+		id.Groups = strings.Split(v, ",")
+	}
+	if id.Token == "" && id.Username == "" {
+		return nil, nil
+	}
+	return id, nil
+}
+
+// Clients implements Authenticator with clients scoped to the caller's token
+func (o *OpenShift) Clients(id *Identity) (kubernetes.Interface, dynamic.Interface, error) {
+	return TokenClients(o.Config, id.Token)
+}
+
+// requestToken returns the Authorization header's token (Bearer or raw), falling
+// back to X-Forwarded-Access-Token
+func requestToken(r *http.Request) string {
+	if raw := strings.TrimSpace(r.Header.Get("Authorization")); raw != "" {
+		parts := strings.SplitN(raw, " ", 2)
+		if len(parts) == 2 && strings.EqualFold(parts[0], "Bearer") {
+			raw = strings.TrimSpace(parts[1])
+		}
+		if raw != "" {
+			return raw
+		}
+	}
+	return strings.TrimSpace(r.Header.Get("X-Forwarded-Access-Token"))
+}
@@ -0,0 +1,328 @@
+// Package client is a Go client for the backend API. It covers session CRUD, AG-UI
+// run submission and event subscription, and integration credential status, so
+// tools calling the backend do not have to hand-roll requests.
+//
+//	c := client.New("https://ambient.example.com", token)
+//	s, err := c.GetSession(ctx, "my-project", "session-1")
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"ambient-code-backend/logging"
+	"ambient-code-backend/types"
+)
+
+// maxResponseBytes bounds a non-streaming response body
+const maxResponseBytes = 10 << 20
+
+// Client calls the backend API with a bearer token. The token may be a user
+// token, an API key or a session token.
+type Client struct {
+	baseURL    string
+	token      string
+	httpClient *http.Client
+	// streamClient has no overall timeout, for long-lived event streams
+	streamClient *http.Client
+
+	reconnectMin time.Duration
+	reconnectMax time.Duration
+}
+
+// Option configures a Client
+type Option func(*Client)
+
+// WithHTTPClient sets the HTTP client used for API calls. Event streams use its
+// transport without its timeout.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = hc
+		c.streamClient = &http.Client{Transport: hc.Transport}
+	}
+}
+
+// WithReconnectBackoff sets the delay bounds between event stream reconnects
+// (default 1s doubling up to 30s)
+func WithReconnectBackoff(min, max time.Duration) Option {
+	return func(c *Client) {
+		c.reconnectMin = min
+		c.reconnectMax = max
+	}
+}
+
+// New returns a client for the backend at baseURL (without the /api suffix)
+func New(baseURL, token string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:      strings.TrimSuffix(baseURL, "/"),
+		token:        token,
+		httpClient:   &http.Client{Timeout: 60 * time.Second},
+		streamClient: &http.Client{},
+		reconnectMin: time.Second,
+		reconnectMax: 30 * time.Second,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// APIError is a non-2xx response from the backend
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("backend API error %d: %s", e.StatusCode, e.Message)
+}
+
+// IsNotFound reports whether err is a 404 from the backend
+func IsNotFound(err error) bool {
+	apiErr, ok := err.(*APIError)
+	return ok && apiErr.StatusCode == http.StatusNotFound
+}
+
+// newRequest builds an authenticated request for path, forwarding the request ID
+// carried by ctx
+func (c *Client) newRequest(ctx context.Context, method, path string, body interface{}) (*http.Request, error) {
+	var reader io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode request: %w", err)
+		}
+		reader = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return nil, err
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	logging.SetRequestIDHeader(req)
+	return req, nil
+}
+
+// do sends a request and decodes a 2xx JSON response into out (if non-nil)
+func (c *Client) do(ctx context.Context, method, path string, body, out interface{}) error {
+	req, err := c.newRequest(ctx, method, path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if err := checkResponse(resp); err != nil {
+		return err
+	}
+	if out == nil || resp.StatusCode == http.StatusNoContent {
+		return nil
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxResponseBytes)).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// checkResponse turns a non-2xx response into an *APIError carrying the
+// backend's {"error": ...} message
+func checkResponse(resp *http.Response) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+	b, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	var body struct {
+		Error string `json:"error"`
+	}
+	msg := strings.TrimSpace(string(b))
+	if json.Unmarshal(b, &body) == nil && body.Error != "" {
+		msg = body.Error
+	}
+	if msg == "" {
+		msg = http.StatusText(resp.StatusCode)
+	}
+	return &APIError{StatusCode: resp.StatusCode, Message: msg}
+}
+
+func sessionsPath(project string) string {
+	return "/api/projects/" + url.PathEscape(project) + "/agentic-sessions"
+}
+
+func sessionPath(project, session string) string {
+	return sessionsPath(project) + "/" + url.PathEscape(session)
+}
+
+// ListOptions pages and filters ListSessions
+type ListOptions struct {
+	Limit  int
+	Offset int
+	Search string
+}
+
+// SessionList is a page of sessions
+type SessionList struct {
+	Items      []types.AgenticSession `json:"items"`
+	TotalCount int                    `json:"totalCount"`
+	Limit      int                    `json:"limit"`
+	Offset     int                    `json:"offset"`
+	HasMore    bool                   `json:"hasMore"`
+	NextOffset *int                   `json:"nextOffset,omitempty"`
+}
+
+// ListSessions lists a project's sessions
+func (c *Client) ListSessions(ctx context.Context, project string, opts ListOptions) (*SessionList, error) {
+	q := url.Values{}
+	if opts.Limit > 0 {
+		q.Set("limit", strconv.Itoa(opts.Limit))
+	}
+	if opts.Offset > 0 {
+		q.Set("offset", strconv.Itoa(opts.Offset))
+	}
+	if opts.Search != "" {
+		q.Set("search", opts.Search)
+	}
+	path := sessionsPath(project)
+	if len(q) > 0 {
+		path += "?" + q.Encode()
+	}
+	var list SessionList
+	if err := c.do(ctx, http.MethodGet, path, nil, &list); err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+// CreatedSession identifies a newly created session
+type CreatedSession struct {
+	Name       string `json:"name"`
+	UID        string `json:"uid"`
+	AutoBranch string `json:"autoBranch"`
+}
+
+// CreateSession creates a session in project
+func (c *Client) CreateSession(ctx context.Context, project string, req types.CreateAgenticSessionRequest) (*CreatedSession, error) {
+	var created CreatedSession
+	if err := c.do(ctx, http.MethodPost, sessionsPath(project), req, &created); err != nil {
+		return nil, err
+	}
+	return &created, nil
+}
+
+// GetSession returns a session
+func (c *Client) GetSession(ctx context.Context, project, session string) (*types.AgenticSession, error) {
+	return c.sessionCall(ctx, http.MethodGet, sessionPath(project, session), nil)
+}
+
+// UpdateSession updates a session's spec
+func (c *Client) UpdateSession(ctx context.Context, project, session string, req types.UpdateAgenticSessionRequest) (*types.AgenticSession, error) {
+	return c.sessionCall(ctx, http.MethodPut, sessionPath(project, session), req)
+}
+
+// DeleteSession deletes a session
+func (c *Client) DeleteSession(ctx context.Context, project, session string) error {
+	return c.do(ctx, http.MethodDelete, sessionPath(project, session), nil, nil)
+}
+
+// StartSession starts (or restarts) a stopped session
+func (c *Client) StartSession(ctx context.Context, project, session string) (*types.AgenticSession, error) {
+	return c.sessionCall(ctx, http.MethodPost, sessionPath(project, session)+"/start", nil)
+}
+
+// StopSession stops a running session
+func (c *Client) StopSession(ctx context.Context, project, session string) (*types.AgenticSession, error) {
+	return c.sessionCall(ctx, http.MethodPost, sessionPath(project, session)+"/stop", nil)
+}
+
+func (c *Client) sessionCall(ctx context.Context, method, path string, body interface{}) (*types.AgenticSession, error) {
+	var s types.AgenticSession
+	if err := c.do(ctx, method, path, body, &s); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+// Run submits an AG-UI run to a session. The run's events are delivered to
+// SubscribeEvents subscribers.
+func (c *Client) Run(ctx context.Context, project, session string, input types.RunAgentInput) (*types.RunAgentOutput, error) {
+	var out types.RunAgentOutput
+	if err := c.do(ctx, http.MethodPost, sessionPath(project, session)+"/agui/run", input, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// RunCompacted submits a run like Run, first letting the backend replace all but the
+// latest input messages with a summary so long threads fit the runner's context
+func (c *Client) RunCompacted(ctx context.Context, project, session string, input types.RunAgentInput) (*types.RunAgentOutput, error) {
+	var out types.RunAgentOutput
+	if err := c.do(ctx, http.MethodPost, sessionPath(project, session)+"/agui/run?compact=true", input, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// Runs lists a session's AG-UI runs
+func (c *Client) Runs(ctx context.Context, project, session string) ([]types.AGUIRunMetadata, error) {
+	var out struct {
+		Runs []types.AGUIRunMetadata `json:"runs"`
+	}
+	if err := c.do(ctx, http.MethodGet, sessionPath(project, session)+"/agui/runs", nil, &out); err != nil {
+		return nil, err
+	}
+	return out.Runs, nil
+}
+
+// History returns the compacted messages of one run
+func (c *Client) History(ctx context.Context, project, session, runID string) ([]types.Message, error) {
+	var out struct {
+		Messages []types.Message `json:"messages"`
+	}
+	path := sessionPath(project, session) + "/agui/history?runId=" + url.QueryEscape(runID)
+	if err := c.do(ctx, http.MethodGet, path, nil, &out); err != nil {
+		return nil, err
+	}
+	return out.Messages, nil
+}
+
+// Messages returns a session's conversation in the form RunAgentInput.Messages takes
+func (c *Client) Messages(ctx context.Context, project, session string) ([]types.Message, error) {
+	var out struct {
+		Messages []types.Message `json:"messages"`
+	}
+	if err := c.do(ctx, http.MethodGet, sessionPath(project, session)+"/agui/messages", nil, &out); err != nil {
+		return nil, err
+	}
+	return out.Messages, nil
+}
+
+// SendMessage submits a run with a single user message
+func (c *Client) SendMessage(ctx context.Context, project, session, text string) (*types.RunAgentOutput, error) {
+	return c.Run(ctx, project, session, types.RunAgentInput{
+		Messages: []types.Message{{ID: newMessageID(), Role: types.RoleUser, Content: text}},
+	})
+}
+
+// newMessageID returns a random message ID
+func newMessageID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,135 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"ambient-code-backend/logging"
+	"ambient-code-backend/types"
+)
+
+func TestSessionCalls(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Authorization"); got != "Bearer tok" {
+			t.Errorf("Authorization = %q", got)
+		}
+		switch r.Method + " " + r.URL.Path {
+		case "GET /api/projects/team-a/agentic-sessions":
+			if r.URL.Query().Get("limit") != "5" {
+				t.Errorf("query = %s", r.URL.RawQuery)
+			}
+			fmt.Fprint(w, `{"items":[{"metadata":{"name":"s1"}}],"totalCount":1,"limit":5}`)
+		case "POST /api/projects/team-a/agentic-sessions":
+			if got := r.Header.Get(logging.RequestIDHeader); got != "req-1" {
+				t.Errorf("%s = %q", logging.RequestIDHeader, got)
+			}
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprint(w, `{"message":"created","name":"s2","uid":"u2","autoBranch":"ambient/s2"}`)
+		case "GET /api/projects/team-a/agentic-sessions/missing":
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"error":"Session not found"}`)
+		case "DELETE /api/projects/team-a/agentic-sessions/s1":
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer srv.Close()
+
+	c := New(srv.URL+"/", "tok")
+	ctx := context.Background()
+
+	list, err := c.ListSessions(ctx, "team-a", ListOptions{Limit: 5})
+	if err != nil || list.TotalCount != 1 || list.Items[0].Metadata["name"] != "s1" {
+		t.Fatalf("ListSessions = %+v, %v", list, err)
+	}
+
+	created, err := c.CreateSession(logging.WithRequestID(ctx, "req-1"), "team-a", types.CreateAgenticSessionRequest{InitialPrompt: "hi"})
+	if err != nil || created.Name != "s2" || created.AutoBranch != "ambient/s2" {
+		t.Fatalf("CreateSession = %+v, %v", created, err)
+	}
+
+	_, err = c.GetSession(ctx, "team-a", "missing")
+	if !IsNotFound(err) || err.(*APIError).Message != "Session not found" {
+		t.Fatalf("GetSession error = %v", err)
+	}
+
+	if err := c.DeleteSession(ctx, "team-a", "s1"); err != nil {
+		t.Fatalf("DeleteSession = %v", err)
+	}
+}
+
+func TestSubscribeEventsReconnects(t *testing.T) {
+	var connects int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := atomic.AddInt32(&connects, 1)
+		if n == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		// Each connection delivers one event and drops
+		fmt.Fprintf(w, ": keepalive\n\ndata: {\"type\":\"RUN_STARTED\",\"threadId\":\"s1\",\"runId\":\"r%d\"}\n\n", n)
+	}))
+	defer srv.Close()
+
+	c := New(srv.URL, "tok", WithReconnectBackoff(time.Millisecond, 5*time.Millisecond))
+	var runs []string
+	done := errors.New("done")
+	err := c.SubscribeEvents(context.Background(), "team-a", "s1", func(ev Event) error {
+		if ev.Type != types.EventTypeRunStarted || len(ev.Data) == 0 {
+			t.Errorf("event = %+v", ev)
+		}
+		runs = append(runs, ev.RunID)
+		if len(runs) == 2 {
+			return done
+		}
+		return nil
+	})
+	if err != done {
+		t.Fatalf("SubscribeEvents = %v, want handler error", err)
+	}
+	if len(runs) != 2 || runs[0] != "r2" || runs[1] != "r3" {
+		t.Errorf("runs = %v", runs)
+	}
+}
+
+func TestSubscribeEventsStopsOnClientError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, `{"error":"Forbidden"}`)
+	}))
+	defer srv.Close()
+
+	err := New(srv.URL, "tok").SubscribeEvents(context.Background(), "team-a", "s1", func(Event) error { return nil })
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusForbidden {
+		t.Fatalf("SubscribeEvents = %v, want 403", err)
+	}
+}
+
+func TestIntegrationsStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"github":{"installed":true,"installationId":42,"pat":{"configured":false},"active":"app"},
+			"google":{"connected":false},"jira":{"connected":true,"url":"https://jira.example.com","valid":true},
+			"gitlab":{"connected":false}}`)
+	}))
+	defer srv.Close()
+
+	status, err := New(srv.URL, "tok").IntegrationsStatus(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !status.GitHub.Installed || status.GitHub.InstallationID != 42 || status.GitHub.Active != "app" {
+		t.Errorf("github = %+v", status.GitHub)
+	}
+	if !status.Jira.Connected || status.Jira.URL != "https://jira.example.com" || status.Google.Connected {
+		t.Errorf("jira = %+v, google = %+v", status.Jira, status.Google)
+	}
+}
@@ -0,0 +1,61 @@
+package client
+
+import (
+	"context"
+	"net/http"
+)
+
+// IntegrationsStatus is the caller's connection status for every integration
+type IntegrationsStatus struct {
+	GitHub GitHubStatus      `json:"github"`
+	Google IntegrationStatus `json:"google"`
+	Jira   IntegrationStatus `json:"jira"`
+	GitLab IntegrationStatus `json:"gitlab"`
+	Linear IntegrationStatus `json:"linear"`
+}
+
+// GitHubStatus is the caller's GitHub App installation and PAT status
+type GitHubStatus struct {
+	Installed      bool      `json:"installed"`
+	InstallationID int64     `json:"installationId,omitempty"`
+	Host           string    `json:"host,omitempty"`
+	GitHubUserID   string    `json:"githubUserId,omitempty"`
+	UpdatedAt      string    `json:"updatedAt,omitempty"`
+	PAT            PATStatus `json:"pat"`
+	// Active is the method sessions use: "pat", "app" or "" if neither is configured
+	Active string `json:"active,omitempty"`
+	// Status is "ok", or "timeout" when the lookup didn't finish and the rest is unknown
+	Status string `json:"status,omitempty"`
+}
+
+// PATStatus is the caller's GitHub personal access token status
+type PATStatus struct {
+	Configured bool   `json:"configured"`
+	Valid      bool   `json:"valid,omitempty"`
+	UpdatedAt  string `json:"updatedAt,omitempty"`
+}
+
+// IntegrationStatus is the caller's status for a credential-based integration.
+// Only the fields the integration reports are set.
+type IntegrationStatus struct {
+	Connected   bool   `json:"connected"`
+	Valid       bool   `json:"valid,omitempty"`
+	Email       string `json:"email,omitempty"`
+	URL         string `json:"url,omitempty"`
+	InstanceURL string `json:"instanceUrl,omitempty"`
+	// Organization is the Linear workspace URL key
+	Organization string `json:"organization,omitempty"`
+	ExpiresAt    string `json:"expiresAt,omitempty"`
+	UpdatedAt    string `json:"updatedAt,omitempty"`
+	// Status is "ok", or "timeout" when the lookup didn't finish and the rest is unknown
+	Status string `json:"status,omitempty"`
+}
+
+// IntegrationsStatus returns the caller's status for all integrations
+func (c *Client) IntegrationsStatus(ctx context.Context) (*IntegrationsStatus, error) {
+	var status IntegrationsStatus
+	if err := c.do(ctx, http.MethodGet, "/api/auth/integrations/status", nil, &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
@@ -0,0 +1,117 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// maxEventBytes bounds a single SSE event (snapshots carry whole conversations)
+const maxEventBytes = 16 << 20
+
+// Event is one AG-UI event from a session's event stream. Data holds the full
+// event; decode it into the matching types event struct by Type.
+type Event struct {
+	Type     string          `json:"type"`
+	ThreadID string          `json:"threadId"`
+	RunID    string          `json:"runId"`
+	Data     json.RawMessage `json:"-"`
+}
+
+// EventHandler receives events in order. Returning an error stops the
+// subscription and SubscribeEvents returns it.
+type EventHandler func(Event) error
+
+// SubscribeEvents streams a session's AG-UI events to handler until ctx is done
+// or handler returns an error. Dropped connections and 5xx responses are retried
+// with backoff; other client errors (e.g. 401, 403, 404) are returned.
+//
+// On every (re)connect the backend first replays the thread's history as
+// snapshots, so handlers must tolerate seeing a run's events again.
+func (c *Client) SubscribeEvents(ctx context.Context, project, session string, handler EventHandler) error {
+	path := sessionPath(project, session) + "/agui/events"
+	backoff := c.reconnectMin
+	for {
+		received, err := c.streamEvents(ctx, path, handler)
+		var stop *handlerError
+		if errors.As(err, &stop) {
+			return stop.err
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		var apiErr *APIError
+		if errors.As(err, &apiErr) && apiErr.StatusCode < 500 {
+			return err
+		}
+		if received {
+			backoff = c.reconnectMin
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		if backoff *= 2; backoff > c.reconnectMax {
+			backoff = c.reconnectMax
+		}
+	}
+}
+
+// handlerError marks an error returned by the caller's handler
+type handlerError struct{ err error }
+
+func (e *handlerError) Error() string { return e.err.Error() }
+
+// streamEvents reads one SSE connection until it ends. received reports whether
+// any event arrived, so a healthy stream resets the reconnect backoff.
+func (c *Client) streamEvents(ctx context.Context, path string, handler EventHandler) (received bool, err error) {
+	req, err := c.newRequest(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	resp, err := c.streamClient.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+	if err := checkResponse(resp); err != nil {
+		return false, err
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64<<10), maxEventBytes)
+	var data strings.Builder
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.HasPrefix(line, "data:"):
+			if data.Len() > 0 {
+				data.WriteByte('\n')
+			}
+			data.WriteString(strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		case line == "" && data.Len() > 0:
+			ev := Event{Data: json.RawMessage(data.String())}
+			data.Reset()
+			if err := json.Unmarshal(ev.Data, &ev); err != nil {
+				return received, fmt.Errorf("invalid event: %w", err)
+			}
+			received = true
+			if err := handler(ev); err != nil {
+				return received, &handlerError{err}
+			}
+		}
+		// Comments (keepalives), event: and id: lines are ignored
+	}
+	if err := scanner.Err(); err != nil {
+		return received, err
+	}
+	return received, fmt.Errorf("event stream closed")
+}
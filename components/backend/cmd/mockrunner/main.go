@@ -0,0 +1,24 @@
+// Command mockrunner serves pkg/mockrunner on a port, standing in for a session
+// runner during local development.
+//
+//	mockrunner -addr :8001 -delay 200ms
+package main
+
+import (
+	"flag"
+	"log"
+	"net/http"
+
+	"ambient-code-backend/pkg/mockrunner"
+)
+
+func main() {
+	addr := flag.String("addr", ":8001", "address to listen on")
+	delay := flag.Duration("delay", 0, "pause before each streamed event")
+	flag.Parse()
+
+	log.Printf("Mock runner listening on %s", *addr)
+	if err := http.ListenAndServe(*addr, mockrunner.New(mockrunner.WithEventDelay(*delay))); err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,114 @@
+// Command vteam drives sessions and runs from the command line through the
+// backend API, for power users and CI.
+//
+//	vteam session create --prompt "Fix the flaky test" --repo https://github.com/org/repo
+//	vteam run tail my-session
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"ambient-code-backend/client"
+
+	"github.com/spf13/cobra"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// globalOptions are the flags shared by every command
+type globalOptions struct {
+	server     string
+	token      string
+	project    string
+	kubeconfig string
+	output     string
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := newRootCommand().ExecuteContext(ctx); err != nil {
+		os.Exit(1)
+	}
+}
+
+func newRootCommand() *cobra.Command {
+	opts := &globalOptions{}
+	root := &cobra.Command{
+		Use:          "vteam",
+		Short:        "Manage vTeam agentic sessions and runs",
+		SilenceUsage: true,
+	}
+	flags := root.PersistentFlags()
+	flags.StringVar(&opts.server, "server", os.Getenv("VTEAM_API_URL"), "backend URL (env VTEAM_API_URL)")
+	flags.StringVar(&opts.token, "token", os.Getenv("VTEAM_TOKEN"), "bearer token or API key (env VTEAM_TOKEN); defaults to the kubeconfig token")
+	flags.StringVarP(&opts.project, "project", "p", "", "project (defaults to the kubeconfig namespace)")
+	flags.StringVar(&opts.kubeconfig, "kubeconfig", "", "kubeconfig file (defaults to $KUBECONFIG or ~/.kube/config)")
+	flags.StringVarP(&opts.output, "output", "o", "text", "output format: text or json")
+
+	root.AddCommand(newSessionCommand(opts), newRunCommand(opts))
+	return root
+}
+
+// client returns an API client authenticated from flags, env or kubeconfig
+func (o *globalOptions) client() (*client.Client, error) {
+	if o.server == "" {
+		return nil, fmt.Errorf("backend URL required: set --server or VTEAM_API_URL")
+	}
+	token := o.token
+	if token == "" {
+		cfg, err := o.kubeConfig().ClientConfig()
+		if err != nil {
+			return nil, fmt.Errorf("no token: set --token or VTEAM_TOKEN, or log in with oc/kubectl (%v)", err)
+		}
+		token = cfg.BearerToken
+		if token == "" && cfg.BearerTokenFile != "" {
+			b, err := os.ReadFile(cfg.BearerTokenFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read kubeconfig token file: %w", err)
+			}
+			token = string(b)
+		}
+		if token == "" {
+			return nil, fmt.Errorf("current kubeconfig context has no bearer token: set --token or VTEAM_TOKEN")
+		}
+	}
+	return client.New(o.server, token), nil
+}
+
+// projectName returns --project or the current kubeconfig namespace
+func (o *globalOptions) projectName() (string, error) {
+	if o.project != "" {
+		return o.project, nil
+	}
+	ns, _, err := o.kubeConfig().Namespace()
+	if err != nil || ns == "" || ns == "default" {
+		return "", fmt.Errorf("project required: set --project or switch to it with 'oc project'")
+	}
+	return ns, nil
+}
+
+func (o *globalOptions) kubeConfig() clientcmd.ClientConfig {
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	if o.kubeconfig != "" {
+		rules.ExplicitPath = o.kubeconfig
+	}
+	return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules, &clientcmd.ConfigOverrides{})
+}
+
+// setup resolves the client and project every project-scoped command needs
+func (o *globalOptions) setup() (*client.Client, string, error) {
+	c, err := o.client()
+	if err != nil {
+		return nil, "", err
+	}
+	project, err := o.projectName()
+	if err != nil {
+		return nil, "", err
+	}
+	return c, project, nil
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"ambient-code-backend/client"
+)
+
+func event(t *testing.T, raw string) client.Event {
+	t.Helper()
+	ev := client.Event{Data: json.RawMessage(raw)}
+	if err := json.Unmarshal(ev.Data, &ev); err != nil {
+		t.Fatal(err)
+	}
+	return ev
+}
+
+func TestEventPrinterTranscript(t *testing.T) {
+	var out bytes.Buffer
+	p := &eventPrinter{w: &out}
+	for _, raw := range []string{
+		`{"type":"RUN_STARTED","runId":"r1"}`,
+		`{"type":"MESSAGES_SNAPSHOT","runId":"r1","messages":[{"id":"m1","role":"user","content":"hi"}]}`,
+		`{"type":"TEXT_MESSAGE_CONTENT","runId":"r1","delta":"Hel"}`,
+		`{"type":"TEXT_MESSAGE_CONTENT","runId":"r1","delta":"lo"}`,
+		`{"type":"TOOL_CALL_START","runId":"r1","toolCallId":"t1","toolCallName":"Bash"}`,
+		`{"type":"STATE_SNAPSHOT","runId":"r1","state":{}}`,
+		`{"type":"RUN_ERROR","runId":"r1","error":"boom"}`,
+	} {
+		if err := p.print(event(t, raw)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := "--- run r1 started\nuser: hi\nHello\n[tool] Bash\n--- run r1 failed: boom\n"
+	if out.String() != want {
+		t.Errorf("transcript =\n%s\nwant\n%s", out.String(), want)
+	}
+}
+
+func TestEventPrinterJSON(t *testing.T) {
+	var out bytes.Buffer
+	p := &eventPrinter{w: &out, json: true}
+	raw := `{"type":"RUN_FINISHED","runId":"r1"}`
+	if err := p.print(event(t, raw)); err != nil {
+		t.Fatal(err)
+	}
+	if out.String() != raw+"\n" {
+		t.Errorf("output = %q", out.String())
+	}
+}
+
+func TestKubeconfigTokenAndProject(t *testing.T) {
+	kubeconfig := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(kubeconfig, []byte(`apiVersion: v1
+kind: Config
+clusters:
+- name: c
+  cluster: {server: https://api.example.com:6443}
+users:
+- name: u
+  user: {token: sha256~abc}
+contexts:
+- name: ctx
+  context: {cluster: c, user: u, namespace: team-a}
+current-context: ctx
+`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := &globalOptions{server: "https://vteam.example.com", kubeconfig: kubeconfig}
+	if _, err := opts.client(); err != nil {
+		t.Fatalf("client() = %v", err)
+	}
+	if project, err := opts.projectName(); err != nil || project != "team-a" {
+		t.Errorf("projectName() = %q, %v", project, err)
+	}
+
+	opts.server = ""
+	if _, err := opts.client(); err == nil {
+		t.Error("client() without a server should fail")
+	}
+}
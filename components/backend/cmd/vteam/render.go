@@ -0,0 +1,96 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"ambient-code-backend/client"
+	"ambient-code-backend/types"
+)
+
+// eventPrinter renders AG-UI events as a readable transcript, or as one JSON
+// event per line with json set
+type eventPrinter struct {
+	w    io.Writer
+	json bool
+	// midLine is set while a streamed text message has no trailing newline yet
+	midLine bool
+}
+
+func (p *eventPrinter) print(ev client.Event) error {
+	if p.json {
+		_, err := fmt.Fprintf(p.w, "%s\n", ev.Data)
+		return err
+	}
+
+	switch ev.Type {
+	case types.EventTypeRunStarted:
+		p.line("--- run %s started", ev.RunID)
+	case types.EventTypeRunFinished:
+		p.line("--- run %s finished", ev.RunID)
+	case types.EventTypeRunError:
+		var e types.RunErrorEvent
+		_ = json.Unmarshal(ev.Data, &e)
+		p.line("--- run %s failed: %s", ev.RunID, e.Error)
+	case types.EventTypeTextMessageContent:
+		var e types.TextMessageContentEvent
+		if err := json.Unmarshal(ev.Data, &e); err != nil {
+			return err
+		}
+		fmt.Fprint(p.w, e.Delta)
+		p.midLine = true
+	case types.EventTypeTextMessageEnd:
+		p.finish()
+	case types.EventTypeToolCallStart:
+		var e types.ToolCallStartEvent
+		_ = json.Unmarshal(ev.Data, &e)
+		p.line("[tool] %s", e.ToolCallName)
+	case types.EventTypeMessagesSnapshot:
+		var e types.MessagesSnapshotEvent
+		if err := json.Unmarshal(ev.Data, &e); err != nil {
+			return err
+		}
+		p.finish()
+		printMessages(p.w, e.Messages)
+	}
+	// State, step, activity and tool argument events are not shown
+	return nil
+}
+
+// line prints a status line, ending any streamed text first
+func (p *eventPrinter) line(format string, args ...interface{}) {
+	p.finish()
+	fmt.Fprintf(p.w, format+"\n", args...)
+}
+
+// finish ends a streamed text message
+func (p *eventPrinter) finish() {
+	if p.midLine {
+		fmt.Fprintln(p.w)
+		p.midLine = false
+	}
+}
+
+// printMessages prints a conversation, one message per paragraph
+func printMessages(w io.Writer, messages []types.Message) {
+	for _, m := range messages {
+		switch {
+		case m.Role == types.RoleTool:
+			fmt.Fprintf(w, "[tool result] %s\n", truncate(m.Content, 200))
+		case m.Content != "":
+			fmt.Fprintf(w, "%s: %s\n", m.Role, m.Content)
+		}
+		for _, tc := range m.ToolCalls {
+			fmt.Fprintf(w, "[tool] %s\n", tc.Name)
+		}
+	}
+}
+
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n]) + "..."
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+
+	"ambient-code-backend/client"
+	"ambient-code-backend/types"
+
+	"github.com/spf13/cobra"
+)
+
+// errRunDone stops the event subscription once the awaited run has ended
+var errRunDone = errors.New("run done")
+
+func newRunCommand(opts *globalOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "run",
+		Short: "Start and follow runs in a session",
+	}
+	cmd.AddCommand(newRunStartCommand(opts), newRunTailCommand(opts))
+	return cmd
+}
+
+func newRunStartCommand(opts *globalOptions) *cobra.Command {
+	var (
+		prompt string
+		follow bool
+	)
+	cmd := &cobra.Command{
+		Use:   "start SESSION --prompt TEXT",
+		Short: "Send a message to a session, starting a run",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if prompt == "" {
+				return fmt.Errorf("--prompt is required")
+			}
+			c, project, err := opts.setup()
+			if err != nil {
+				return err
+			}
+			out, err := c.SendMessage(cmd.Context(), project, args[0], prompt)
+			if err != nil {
+				return err
+			}
+			if opts.output == "json" && !follow {
+				return printJSON(cmd.OutOrStdout(), out)
+			}
+			if !follow {
+				fmt.Fprintln(cmd.OutOrStdout(), out.RunID)
+				return nil
+			}
+			return opts.tailEvents(cmd, c, project, args[0], out.RunID, true)
+		},
+	}
+	cmd.Flags().StringVar(&prompt, "prompt", "", "message to send")
+	cmd.Flags().BoolVarP(&follow, "follow", "f", false, "stream events until the run ends; exits non-zero if it fails")
+	return cmd
+}
+
+func newRunTailCommand(opts *globalOptions) *cobra.Command {
+	var runID string
+	cmd := &cobra.Command{
+		Use:   "tail SESSION",
+		Short: "Stream a session's events",
+		Long: "Stream a session's events, reconnecting when the connection drops. Without --run-id\n" +
+			"it streams until interrupted; with it, until that run ends.",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, project, err := opts.setup()
+			if err != nil {
+				return err
+			}
+			return opts.tailEvents(cmd, c, project, args[0], runID, runID != "")
+		},
+	}
+	cmd.Flags().StringVar(&runID, "run-id", "", "stop when this run ends")
+	return cmd
+}
+
+// tailEvents prints a session's events. With untilDone it returns when runID (or,
+// if empty, any run) finishes, and an error if that run failed.
+func (o *globalOptions) tailEvents(cmd *cobra.Command, c *client.Client, project, session, runID string, untilDone bool) error {
+	p := &eventPrinter{w: cmd.OutOrStdout(), json: o.output == "json"}
+	var runErr error
+	err := c.SubscribeEvents(cmd.Context(), project, session, func(ev client.Event) error {
+		if err := p.print(ev); err != nil {
+			return err
+		}
+		if !untilDone || (runID != "" && ev.RunID != runID) {
+			return nil
+		}
+		switch ev.Type {
+		case types.EventTypeRunFinished:
+			return errRunDone
+		case types.EventTypeRunError:
+			runErr = fmt.Errorf("run %s failed", ev.RunID)
+			return errRunDone
+		}
+		return nil
+	})
+	p.finish()
+	if errors.Is(err, errRunDone) {
+		return runErr
+	}
+	if cmd.Context().Err() != nil {
+		// Interrupted by the user
+		return nil
+	}
+	return err
+}
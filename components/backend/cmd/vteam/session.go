@@ -0,0 +1,173 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"ambient-code-backend/client"
+	"ambient-code-backend/types"
+
+	"github.com/spf13/cobra"
+)
+
+func newSessionCommand(opts *globalOptions) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "session",
+		Aliases: []string{"sessions"},
+		Short:   "Create, list and inspect sessions",
+	}
+	cmd.AddCommand(newSessionCreateCommand(opts), newSessionListCommand(opts), newSessionLogsCommand(opts))
+	return cmd
+}
+
+func newSessionCreateCommand(opts *globalOptions) *cobra.Command {
+	var (
+		req         types.CreateAgenticSessionRequest
+		model       string
+		repos       []string
+		interactive bool
+		follow      bool
+	)
+	cmd := &cobra.Command{
+		Use:   "create",
+		Short: "Create a session",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			c, project, err := opts.setup()
+			if err != nil {
+				return err
+			}
+			if model != "" {
+				req.LLMSettings = &types.LLMSettings{Model: model}
+			}
+			for _, repo := range repos {
+				req.Repos = append(req.Repos, types.SimpleRepo{URL: repo})
+			}
+			if cmd.Flags().Changed("interactive") {
+				req.Interactive = &interactive
+			}
+
+			created, err := c.CreateSession(cmd.Context(), project, req)
+			if err != nil {
+				return err
+			}
+			if opts.output == "json" {
+				err = printJSON(cmd.OutOrStdout(), created)
+			} else {
+				fmt.Fprintln(cmd.OutOrStdout(), created.Name)
+			}
+			if err != nil || !follow {
+				return err
+			}
+			return opts.tailEvents(cmd, c, project, created.Name, "", true)
+		},
+	}
+	f := cmd.Flags()
+	f.StringVar(&req.InitialPrompt, "prompt", "", "initial prompt")
+	f.StringVar(&req.DisplayName, "display-name", "", "display name")
+	f.StringVar(&model, "model", "", "model (defaults to the project's)")
+	f.StringArrayVar(&repos, "repo", nil, "repository URL to clone (repeatable)")
+	f.BoolVar(&interactive, "interactive", false, "keep the session open for follow-up messages")
+	f.BoolVarP(&follow, "follow", "f", false, "stream the session's events until its first run ends")
+	return cmd
+}
+
+func newSessionListCommand(opts *globalOptions) *cobra.Command {
+	var list client.ListOptions
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List sessions in the project",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			c, project, err := opts.setup()
+			if err != nil {
+				return err
+			}
+			sessions, err := c.ListSessions(cmd.Context(), project, list)
+			if err != nil {
+				return err
+			}
+			if opts.output == "json" {
+				return printJSON(cmd.OutOrStdout(), sessions)
+			}
+			printSessions(cmd.OutOrStdout(), sessions.Items)
+			if sessions.HasMore && sessions.NextOffset != nil {
+				fmt.Fprintf(cmd.ErrOrStderr(), "%d of %d shown; use --offset %d for more\n", len(sessions.Items), sessions.TotalCount, *sessions.NextOffset)
+			}
+			return nil
+		},
+	}
+	f := cmd.Flags()
+	f.IntVar(&list.Limit, "limit", 0, "maximum sessions to return (server default 20, max 100)")
+	f.IntVar(&list.Offset, "offset", 0, "sessions to skip")
+	f.StringVar(&list.Search, "search", "", "filter by name or display name")
+	return cmd
+}
+
+func newSessionLogsCommand(opts *globalOptions) *cobra.Command {
+	var follow bool
+	cmd := &cobra.Command{
+		Use:   "logs SESSION",
+		Short: "Print a session's conversation",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, project, err := opts.setup()
+			if err != nil {
+				return err
+			}
+			if follow {
+				// The event stream replays history before live events
+				return opts.tailEvents(cmd, c, project, args[0], "", false)
+			}
+			runs, err := c.Runs(cmd.Context(), project, args[0])
+			if err != nil {
+				return err
+			}
+			type runLog struct {
+				types.AGUIRunMetadata
+				Messages []types.Message `json:"messages"`
+			}
+			logs := make([]runLog, 0, len(runs))
+			for _, run := range runs {
+				messages, err := c.History(cmd.Context(), project, args[0], run.RunID)
+				if err != nil {
+					return err
+				}
+				logs = append(logs, runLog{run, messages})
+			}
+			if opts.output == "json" {
+				return printJSON(cmd.OutOrStdout(), logs)
+			}
+			for _, l := range logs {
+				fmt.Fprintf(cmd.OutOrStdout(), "=== run %s (%s, started %s)\n", l.RunID, l.Status, l.StartedAt)
+				printMessages(cmd.OutOrStdout(), l.Messages)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().BoolVarP(&follow, "follow", "f", false, "keep streaming new events")
+	return cmd
+}
+
+func printSessions(w io.Writer, sessions []types.AgenticSession) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tPHASE\tDISPLAY NAME\tCREATED")
+	for _, s := range sessions {
+		name, _ := s.Metadata["name"].(string)
+		created, _ := s.Metadata["creationTimestamp"].(string)
+		phase := ""
+		if s.Status != nil {
+			phase = s.Status.Phase
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", name, phase, s.Spec.DisplayName, created)
+	}
+	tw.Flush()
+}
+
+func printJSON(w io.Writer, v interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
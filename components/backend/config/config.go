@@ -0,0 +1,507 @@
+// Package config holds the backend's tunable settings. They are loaded from defaults,
+// an optional YAML file (normally a mounted ConfigMap) and environment variables, in
+// increasing precedence, and validated at startup. Watch reloads the file; settings
+// marked structural keep their startup values until the backend restarts.
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"ambient-code-backend/logging"
+
+	"sigs.k8s.io/yaml"
+)
+
+// DefaultPath is read when CONFIG_FILE is unset; it may be absent
+const DefaultPath = "/etc/ambient-backend/config.yaml"
+
+// Config is the backend's configuration. Field comments name the environment
+// variable that overrides each setting.
+type Config struct {
+	// Structural settings; changes need a restart
+
+	// GRPCPort serves the AgentRuns gRPC API when set (GRPC_PORT)
+	GRPCPort string `json:"grpcPort,omitempty"`
+	// RunnerPort is the port of each session's runner Service (RUNNER_PORT)
+	RunnerPort int `json:"runnerPort"`
+	// RunnerHTTP2 talks to plain-HTTP runners over h2c (RUNNER_HTTP2)
+	RunnerHTTP2 bool `json:"runnerHTTP2"`
+
+	// Reloadable settings
+
+	// LogLevel is debug, info, warn or error (LOG_LEVEL)
+	LogLevel string `json:"logLevel"`
+	// RateLimits are per-user requests per minute by limit name, 0 disabling a limit
+	// (RATE_LIMIT_<NAME>, e.g. RATE_LIMIT_SESSION_CREATE)
+	RateLimits map[string]int `json:"rateLimits"`
+	// RunnerRequestTimeout bounds interrupt, feedback and MCP status calls to runners
+	// (RUNNER_REQUEST_TIMEOUT)
+	RunnerRequestTimeout Duration `json:"runnerRequestTimeout"`
+	// RunnerConnectRetries is how often starting a run retries a runner that isn't
+	// accepting connections yet (RUNNER_CONNECT_RETRIES)
+	RunnerConnectRetries int `json:"runnerConnectRetries"`
+	// IntegrationStatusTimeout bounds each provider's lookup in the integrations
+	// status endpoint (INTEGRATION_STATUS_TIMEOUT)
+	IntegrationStatusTimeout Duration `json:"integrationStatusTimeout"`
+	// ModerationURL is the content-safety endpoint of projects using remote moderation,
+	// speaking the OpenAI moderations API; its bearer token is MODERATION_TOKEN (MODERATION_URL)
+	ModerationURL string `json:"moderationURL,omitempty"`
+	// ModerationModel is sent as the model of moderation requests when set (MODERATION_MODEL)
+	ModerationModel string `json:"moderationModel,omitempty"`
+	// ModerationTimeout bounds each moderation request (MODERATION_TIMEOUT)
+	ModerationTimeout Duration `json:"moderationTimeout"`
+	// AGUIConformance validates runner events against the AG-UI spec, logging and
+	// counting violations (AGUI_CONFORMANCE)
+	AGUIConformance bool `json:"aguiConformance"`
+	// SessionIdleTimeout pauses runners of sessions without run or user activity for
+	// this long, 0 disabling it (SESSION_IDLE_TIMEOUT)
+	SessionIdleTimeout Duration `json:"sessionIdleTimeout"`
+	// CostRates price runner resources in cost reports, in USD
+	CostRates CostRates `json:"costRates"`
+	// MaxAttachmentBytes bounds each file attached to a run message, 0 disabling
+	// attachments (MAX_ATTACHMENT_BYTES)
+	MaxAttachmentBytes int `json:"maxAttachmentBytes"`
+	// TranscriptionBackend transcribes voice input: openai (an OpenAI-compatible
+	// audio transcriptions API) or whisper-asr; empty disables it (TRANSCRIPTION_BACKEND)
+	TranscriptionBackend string `json:"transcriptionBackend,omitempty"`
+	// TranscriptionURL is the transcription service endpoint; its bearer token is
+	// TRANSCRIPTION_TOKEN (TRANSCRIPTION_URL)
+	TranscriptionURL string `json:"transcriptionURL,omitempty"`
+	// TranscriptionModel is sent as the model of openai requests (TRANSCRIPTION_MODEL)
+	TranscriptionModel string `json:"transcriptionModel,omitempty"`
+	// TranscriptionTimeout bounds each transcription request (TRANSCRIPTION_TIMEOUT)
+	TranscriptionTimeout Duration `json:"transcriptionTimeout"`
+	// MaxAudioBytes bounds each audio clip sent as voice input (MAX_AUDIO_BYTES)
+	MaxAudioBytes int `json:"maxAudioBytes"`
+}
+
+// CostRates are the prices of runner resources; 0 leaves a resource unpriced
+type CostRates struct {
+	// CPUCoreHour is the price of one CPU core for an hour (COST_CPU_CORE_HOUR)
+	CPUCoreHour float64 `json:"cpuCoreHour"`
+	// MemoryGiBHour is the price of 1GiB of memory for an hour (COST_MEMORY_GIB_HOUR)
+	MemoryGiBHour float64 `json:"memoryGiBHour"`
+	// StorageGiBMonth is the price of 1GiB of workspace storage for a 730-hour month
+	// (COST_STORAGE_GIB_MONTH)
+	StorageGiBMonth float64 `json:"storageGiBMonth"`
+}
+
+// Duration is a time.Duration written as a Go duration string, e.g. "10s"
+type Duration struct {
+	time.Duration
+}
+
+// MarshalJSON writes d as a duration string
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+// UnmarshalJSON reads a duration string
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("duration must be a string such as \"10s\"")
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	d.Duration = v
+	return nil
+}
+
+// Default returns the built-in configuration
+func Default() *Config {
+	return &Config{
+		RunnerPort: 8001,
+		LogLevel:   "info",
+		RateLimits: map[string]int{
+			"session-create":        30,
+			"run-create":            60,
+			"credential-validation": 10,
+		},
+		RunnerRequestTimeout:     Duration{10 * time.Second},
+		RunnerConnectRetries:     15,
+		IntegrationStatusTimeout: Duration{2 * time.Second},
+		ModerationTimeout:        Duration{10 * time.Second},
+		MaxAttachmentBytes:       10 << 20,
+		TranscriptionModel:       "whisper-1",
+		TranscriptionTimeout:     Duration{60 * time.Second},
+		MaxAudioBytes:            25 << 20,
+	}
+}
+
+// Validate reports the first invalid setting
+func (c *Config) Validate() error {
+	if c.GRPCPort != "" {
+		if p, err := strconv.Atoi(c.GRPCPort); err != nil || p < 1 || p > 65535 {
+			return fmt.Errorf("grpcPort %q is not a port number", c.GRPCPort)
+		}
+	}
+	if c.RunnerPort < 1 || c.RunnerPort > 65535 {
+		return fmt.Errorf("runnerPort %d is not a port number", c.RunnerPort)
+	}
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(c.LogLevel)); err != nil {
+		return fmt.Errorf("logLevel %q must be debug, info, warn or error", c.LogLevel)
+	}
+	for name, limit := range c.RateLimits {
+		if limit < 0 {
+			return fmt.Errorf("rateLimits.%s must not be negative", name)
+		}
+	}
+	if c.RunnerRequestTimeout.Duration <= 0 {
+		return fmt.Errorf("runnerRequestTimeout must be positive")
+	}
+	if c.RunnerConnectRetries < 1 {
+		return fmt.Errorf("runnerConnectRetries must be at least 1")
+	}
+	if c.IntegrationStatusTimeout.Duration <= 0 {
+		return fmt.Errorf("integrationStatusTimeout must be positive")
+	}
+	if c.ModerationURL != "" && !strings.HasPrefix(c.ModerationURL, "https://") && !strings.HasPrefix(c.ModerationURL, "http://") {
+		return fmt.Errorf("moderationURL %q must be an http(s) URL", c.ModerationURL)
+	}
+	if c.ModerationTimeout.Duration <= 0 {
+		return fmt.Errorf("moderationTimeout must be positive")
+	}
+	if c.SessionIdleTimeout.Duration != 0 && c.SessionIdleTimeout.Duration < 5*time.Minute {
+		return fmt.Errorf("sessionIdleTimeout must be 0 (disabled) or at least 5m")
+	}
+	if c.CostRates.CPUCoreHour < 0 || c.CostRates.MemoryGiBHour < 0 || c.CostRates.StorageGiBMonth < 0 {
+		return fmt.Errorf("costRates must not be negative")
+	}
+	if c.MaxAttachmentBytes < 0 {
+		return fmt.Errorf("maxAttachmentBytes must not be negative")
+	}
+	switch c.TranscriptionBackend {
+	case "":
+	case "openai", "whisper-asr":
+		if !strings.HasPrefix(c.TranscriptionURL, "https://") && !strings.HasPrefix(c.TranscriptionURL, "http://") {
+			return fmt.Errorf("transcriptionURL %q must be an http(s) URL", c.TranscriptionURL)
+		}
+	default:
+		return fmt.Errorf("transcriptionBackend must be openai, whisper-asr or empty")
+	}
+	if c.TranscriptionTimeout.Duration <= 0 {
+		return fmt.Errorf("transcriptionTimeout must be positive")
+	}
+	if c.MaxAudioBytes <= 0 {
+		return fmt.Errorf("maxAudioBytes must be positive")
+	}
+	return nil
+}
+
+// restartRequired reports the structural settings that differ between c and next
+func (c *Config) restartRequired(next *Config) []string {
+	var changed []string
+	if c.GRPCPort != next.GRPCPort {
+		changed = append(changed, "grpcPort")
+	}
+	if c.RunnerPort != next.RunnerPort {
+		changed = append(changed, "runnerPort")
+	}
+	if c.RunnerHTTP2 != next.RunnerHTTP2 {
+		changed = append(changed, "runnerHTTP2")
+	}
+	return changed
+}
+
+// Load reads the configuration: defaults, then the YAML file at path (skipped when
+// path is empty), then environment variables. It returns the names of the variables
+// that were applied.
+func Load(path string) (*Config, []string, error) {
+	c := Default()
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read config file: %w", err)
+		}
+		if err := yaml.UnmarshalStrict(data, c); err != nil {
+			return nil, nil, fmt.Errorf("invalid config file %s: %w", path, err)
+		}
+	}
+	env, err := applyEnv(c)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := c.Validate(); err != nil {
+		return nil, nil, fmt.Errorf("invalid configuration: %w", err)
+	}
+	return c, env, nil
+}
+
+// applyEnv applies the environment variable overrides to c
+func applyEnv(c *Config) ([]string, error) {
+	var applied []string
+	lookup := func(name string) (string, bool) {
+		v, ok := os.LookupEnv(name)
+		v = strings.TrimSpace(v)
+		if ok && v != "" {
+			applied = append(applied, name)
+			return v, true
+		}
+		return "", false
+	}
+	parseInt := func(name string, dst *int) error {
+		if v, ok := lookup(name); ok {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				return fmt.Errorf("invalid %s %q", name, v)
+			}
+			*dst = n
+		}
+		return nil
+	}
+	parseFloat := func(name string, dst *float64) error {
+		if v, ok := lookup(name); ok {
+			f, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return fmt.Errorf("invalid %s %q", name, v)
+			}
+			*dst = f
+		}
+		return nil
+	}
+	parseDuration := func(name string, dst *Duration) error {
+		if v, ok := lookup(name); ok {
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				return fmt.Errorf("invalid %s %q", name, v)
+			}
+			dst.Duration = d
+		}
+		return nil
+	}
+
+	if v, ok := lookup("GRPC_PORT"); ok {
+		c.GRPCPort = v
+	}
+	if v, ok := lookup("RUNNER_HTTP2"); ok {
+		c.RunnerHTTP2 = v == "true"
+	}
+	if v, ok := lookup("LOG_LEVEL"); ok {
+		c.LogLevel = strings.ToLower(v)
+	}
+	if v, ok := lookup("MODERATION_URL"); ok {
+		c.ModerationURL = v
+	}
+	if v, ok := lookup("MODERATION_MODEL"); ok {
+		c.ModerationModel = v
+	}
+	if v, ok := lookup("TRANSCRIPTION_BACKEND"); ok {
+		c.TranscriptionBackend = v
+	}
+	if v, ok := lookup("TRANSCRIPTION_URL"); ok {
+		c.TranscriptionURL = v
+	}
+	if v, ok := lookup("TRANSCRIPTION_MODEL"); ok {
+		c.TranscriptionModel = v
+	}
+	if v, ok := lookup("AGUI_CONFORMANCE"); ok {
+		c.AGUIConformance = v == "true"
+	}
+	rateLimits := make(map[string]int, len(c.RateLimits))
+	for name, limit := range c.RateLimits {
+		if err := parseInt(RateLimitEnv(name), &limit); err != nil {
+			return nil, err
+		}
+		rateLimits[name] = limit
+	}
+	c.RateLimits = rateLimits
+	for _, err := range []error{
+		parseInt("RUNNER_PORT", &c.RunnerPort),
+		parseDuration("RUNNER_REQUEST_TIMEOUT", &c.RunnerRequestTimeout),
+		parseInt("RUNNER_CONNECT_RETRIES", &c.RunnerConnectRetries),
+		parseDuration("INTEGRATION_STATUS_TIMEOUT", &c.IntegrationStatusTimeout),
+		parseDuration("MODERATION_TIMEOUT", &c.ModerationTimeout),
+		parseDuration("SESSION_IDLE_TIMEOUT", &c.SessionIdleTimeout),
+		parseFloat("COST_CPU_CORE_HOUR", &c.CostRates.CPUCoreHour),
+		parseFloat("COST_MEMORY_GIB_HOUR", &c.CostRates.MemoryGiBHour),
+		parseFloat("COST_STORAGE_GIB_MONTH", &c.CostRates.StorageGiBMonth),
+		parseInt("MAX_ATTACHMENT_BYTES", &c.MaxAttachmentBytes),
+		parseDuration("TRANSCRIPTION_TIMEOUT", &c.TranscriptionTimeout),
+		parseInt("MAX_AUDIO_BYTES", &c.MaxAudioBytes),
+	} {
+		if err != nil {
+			return nil, err
+		}
+	}
+	sort.Strings(applied)
+	return applied, nil
+}
+
+// RateLimitEnv returns the environment variable overriding the named rate limit
+func RateLimitEnv(name string) string {
+	return "RATE_LIMIT_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+}
+
+// Status describes the loaded configuration for the admin endpoint
+type Status struct {
+	Config *Config `json:"config"`
+	// File is the config file read, "" if none
+	File     string    `json:"file,omitempty"`
+	LoadedAt time.Time `json:"loadedAt"`
+	// EnvOverrides are the environment variables applied over the file; the settings
+	// they set don't change on reload
+	EnvOverrides []string `json:"envOverrides,omitempty"`
+	// PendingRestart are structural settings changed in the file since startup
+	PendingRestart []string `json:"pendingRestart,omitempty"`
+	// LastReloadError is the error of the last failed reload, cleared by a good one
+	LastReloadError string `json:"lastReloadError,omitempty"`
+}
+
+var (
+	current atomic.Pointer[Config]
+
+	mu      sync.Mutex
+	path    string
+	status  Status
+	modTime time.Time
+	hooks   []func(old, next *Config)
+)
+
+// Current returns the active configuration, which must not be modified. Before Init
+// it is the defaults.
+func Current() *Config {
+	if c := current.Load(); c != nil {
+		return c
+	}
+	return Default()
+}
+
+// Init loads the configuration from CONFIG_FILE, or DefaultPath when it exists, and
+// makes it current
+func Init() error {
+	p := os.Getenv("CONFIG_FILE")
+	if p == "" {
+		if _, err := os.Stat(DefaultPath); err == nil {
+			p = DefaultPath
+		}
+	}
+	c, env, err := Load(p)
+	if err != nil {
+		return err
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	path = p
+	if p != "" {
+		if fi, err := os.Stat(p); err == nil {
+			modTime = fi.ModTime()
+		}
+	}
+	status = Status{Config: c, File: p, LoadedAt: time.Now().UTC(), EnvOverrides: env}
+	current.Store(c)
+	return nil
+}
+
+// CurrentStatus returns the active configuration and where it came from
+func CurrentStatus() Status {
+	mu.Lock()
+	defer mu.Unlock()
+	s := status
+	s.Config = Current()
+	return s
+}
+
+// OnReload registers fn to run after each reload that applied new settings
+func OnReload(fn func(old, next *Config)) {
+	mu.Lock()
+	defer mu.Unlock()
+	hooks = append(hooks, fn)
+}
+
+// Watch checks the config file for changes every interval and reloads it. ConfigMap
+// volumes are updated by swapping a symlink, which a modification-time check sees
+// without inotify. Watch returns immediately; it does nothing without a file.
+func Watch(ctx context.Context, interval time.Duration) {
+	mu.Lock()
+	p := path
+	mu.Unlock()
+	if p == "" {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := reloadIfChanged(); err != nil {
+					logging.Errorf(ctx, "Config: reload failed, keeping current settings: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+// reloadIfChanged reloads the config file if its modification time changed
+func reloadIfChanged() error {
+	mu.Lock()
+	p, last := path, modTime
+	mu.Unlock()
+	fi, err := os.Stat(p)
+	if err != nil {
+		return fmt.Errorf("failed to stat config file: %w", err)
+	}
+	if fi.ModTime().Equal(last) {
+		return nil
+	}
+	return Reload()
+}
+
+// Reload re-reads the config file and applies its reloadable settings
+func Reload() error {
+	mu.Lock()
+	p := path
+	mu.Unlock()
+	if p == "" {
+		return errors.New("no config file to reload")
+	}
+	fi, statErr := os.Stat(p)
+	next, env, err := Load(p)
+
+	mu.Lock()
+	if statErr == nil {
+		// Don't retry a broken file until it changes again
+		modTime = fi.ModTime()
+	}
+	if err != nil {
+		status.LastReloadError = err.Error()
+		mu.Unlock()
+		return err
+	}
+	old := Current()
+	if changed := old.restartRequired(next); len(changed) > 0 {
+		logging.Warnf(context.Background(), "Config: %s changed; restart the backend to apply", strings.Join(changed, ", "))
+		next.GRPCPort, next.RunnerPort, next.RunnerHTTP2 = old.GRPCPort, old.RunnerPort, old.RunnerHTTP2
+		status.PendingRestart = changed
+	} else {
+		status.PendingRestart = nil
+	}
+	status.LoadedAt, status.EnvOverrides, status.LastReloadError = time.Now().UTC(), env, ""
+	current.Store(next)
+	fns := append([]func(old, next *Config){}, hooks...)
+	mu.Unlock()
+
+	logging.Infof(context.Background(), "Config: reloaded %s", p)
+	for _, fn := range fns {
+		fn(old, next)
+	}
+	return nil
+}
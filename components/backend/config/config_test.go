@@ -0,0 +1,120 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeConfig(t *testing.T, dir, body string) string {
+	t.Helper()
+	p := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(p, []byte(body), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestLoadDefaults(t *testing.T) {
+	c, env, err := Load("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(env) != 0 {
+		t.Errorf("env overrides = %v, want none", env)
+	}
+	if c.RunnerPort != 8001 || c.LogLevel != "info" || c.RateLimits["session-create"] != 30 {
+		t.Errorf("unexpected defaults: %+v", c)
+	}
+}
+
+func TestLoadFileThenEnv(t *testing.T) {
+	p := writeConfig(t, t.TempDir(), `
+logLevel: debug
+runnerRequestTimeout: 3s
+rateLimits:
+  session-create: 5
+  run-create: 7
+`)
+	t.Setenv("RATE_LIMIT_RUN_CREATE", "9")
+	t.Setenv("RUNNER_PORT", "9000")
+
+	c, env, err := Load(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.LogLevel != "debug" || c.RunnerRequestTimeout.Duration != 3*time.Second {
+		t.Errorf("file settings not applied: %+v", c)
+	}
+	if c.RateLimits["session-create"] != 5 || c.RateLimits["run-create"] != 9 {
+		t.Errorf("rate limits = %v", c.RateLimits)
+	}
+	if c.RunnerPort != 9000 {
+		t.Errorf("RunnerPort = %d, want 9000", c.RunnerPort)
+	}
+	if len(env) != 2 || env[0] != "RATE_LIMIT_RUN_CREATE" || env[1] != "RUNNER_PORT" {
+		t.Errorf("env overrides = %v", env)
+	}
+}
+
+func TestLoadRejectsInvalid(t *testing.T) {
+	dir := t.TempDir()
+	for name, body := range map[string]string{
+		"unknown field":  "logLevl: debug\n",
+		"bad level":      "logLevel: loud\n",
+		"bad duration":   "runnerRequestTimeout: soon\n",
+		"negative limit": "rateLimits:\n  run-create: -1\n",
+		"bad port":       "runnerPort: 70000\n",
+		"bad moderation": "moderationURL: moderation.internal\n",
+		"negative rate":  "costRates:\n  cpuCoreHour: -0.1\n",
+	} {
+		if _, _, err := Load(writeConfig(t, dir, body)); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+
+	t.Setenv("RUNNER_CONNECT_RETRIES", "many")
+	if _, _, err := Load(""); err == nil {
+		t.Error("invalid environment variable: expected an error")
+	}
+}
+
+func TestReloadKeepsStructuralSettings(t *testing.T) {
+	p := writeConfig(t, t.TempDir(), "runnerPort: 8001\nlogLevel: info\n")
+	t.Setenv("CONFIG_FILE", p)
+	if err := Init(); err != nil {
+		t.Fatal(err)
+	}
+	var reloaded *Config
+	OnReload(func(old, next *Config) { reloaded = next })
+
+	writeConfig(t, filepath.Dir(p), "runnerPort: 9000\nlogLevel: warn\n")
+	if err := Reload(); err != nil {
+		t.Fatal(err)
+	}
+	c := Current()
+	if c.LogLevel != "warn" {
+		t.Errorf("LogLevel = %q, want warn", c.LogLevel)
+	}
+	if c.RunnerPort != 8001 {
+		t.Errorf("RunnerPort = %d, want the startup value 8001", c.RunnerPort)
+	}
+	if reloaded != c {
+		t.Error("reload hook did not receive the new config")
+	}
+	if s := CurrentStatus(); len(s.PendingRestart) != 1 || s.PendingRestart[0] != "runnerPort" {
+		t.Errorf("PendingRestart = %v", s.PendingRestart)
+	}
+
+	writeConfig(t, filepath.Dir(p), "logLevel: loud\n")
+	if err := Reload(); err == nil {
+		t.Fatal("expected an invalid file to fail reloading")
+	}
+	if Current().LogLevel != "warn" {
+		t.Error("a failed reload replaced the settings")
+	}
+	if CurrentStatus().LastReloadError == "" {
+		t.Error("LastReloadError not recorded")
+	}
+}
@@ -0,0 +1,208 @@
+// Package confluence is a minimal client for the Confluence REST API (v1, served by
+// Confluence Cloud, Server and Data Center), covering what sessions need: creating or
+// updating a page to publish their output.
+package confluence
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"ambient-code-backend/metrics"
+)
+
+// ErrUnauthorized is returned when Confluence rejects the credentials
+var ErrUnauthorized = errors.New("confluence rejected the credentials")
+
+// ErrNotFound is returned for spaces and pages that don't exist or the user can't see
+var ErrNotFound = errors.New("confluence space or page not found")
+
+// Client is a Confluence API client authenticated with an Atlassian account email and
+// API token
+type Client struct {
+	httpClient *http.Client
+	baseURL    string
+	email      string
+	apiToken   string
+}
+
+// NewClient creates a Confluence API client for the site at baseURL (on Atlassian
+// Cloud, https://<site>.atlassian.net/wiki) with a 15-second timeout
+func NewClient(baseURL, email, apiToken string) *Client {
+	return &Client{
+		httpClient: &http.Client{
+			Timeout:   15 * time.Second,
+			Transport: metrics.Transport("confluence", "api", nil),
+		},
+		baseURL:  strings.TrimRight(baseURL, "/"),
+		email:    email,
+		apiToken: apiToken,
+	}
+}
+
+// Page is a published Confluence page
+type Page struct {
+	ID      string `json:"id"`
+	Title   string `json:"title"`
+	Space   string `json:"space"`
+	Version int    `json:"version"`
+	URL     string `json:"url"`
+}
+
+// content is the API's representation of a page
+type content struct {
+	ID    string `json:"id,omitempty"`
+	Type  string `json:"type"`
+	Title string `json:"title"`
+	Space *struct {
+		Key string `json:"key"`
+	} `json:"space,omitempty"`
+	Version *struct {
+		Number int `json:"number"`
+	} `json:"version,omitempty"`
+	Links struct {
+		Base  string `json:"base"`
+		WebUI string `json:"webui"`
+	} `json:"_links"`
+}
+
+func (c *Client) page(raw content, space string) *Page {
+	p := &Page{ID: raw.ID, Title: raw.Title, Space: space}
+	if raw.Space != nil {
+		p.Space = raw.Space.Key
+	}
+	if raw.Version != nil {
+		p.Version = raw.Version.Number
+	}
+	if raw.Links.WebUI != "" {
+		base := raw.Links.Base
+		if base == "" {
+			base = c.baseURL
+		}
+		p.URL = strings.TrimRight(base, "/") + raw.Links.WebUI
+	}
+	return p
+}
+
+// do sends a request with an optional JSON body and decodes the JSON response into out
+func (c *Client) do(ctx context.Context, method, path string, body, out interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.SetBasicAuth(c.email, c.apiToken)
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		// Don't wrap error - could leak the token from request details
+		return fmt.Errorf("request failed")
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4<<20))
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusCreated:
+	case http.StatusUnauthorized:
+		return ErrUnauthorized
+	case http.StatusNotFound, http.StatusForbidden:
+		return ErrNotFound
+	default:
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(respBody, &apiErr) == nil && apiErr.Message != "" {
+			return fmt.Errorf("confluence returned %d: %s", resp.StatusCode, apiErr.Message)
+		}
+		return fmt.Errorf("confluence returned %d", resp.StatusCode)
+	}
+	return json.Unmarshal(respBody, out)
+}
+
+// GetPage reads a page by ID
+func (c *Client) GetPage(ctx context.Context, id string) (*Page, error) {
+	if _, err := strconv.ParseUint(id, 10, 64); err != nil {
+		return nil, fmt.Errorf("invalid page ID %q", id)
+	}
+	var raw content
+	if err := c.do(ctx, http.MethodGet, "/rest/api/content/"+id+"?expand=space,version", nil, &raw); err != nil {
+		return nil, err
+	}
+	return c.page(raw, ""), nil
+}
+
+// FindPage returns the page titled title in space, or nil when there is none
+func (c *Client) FindPage(ctx context.Context, space, title string) (*Page, error) {
+	q := url.Values{"spaceKey": {space}, "title": {title}, "type": {"page"}, "expand": {"version"}}
+	var result struct {
+		Results []content `json:"results"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/rest/api/content?"+q.Encode(), nil, &result); err != nil {
+		return nil, err
+	}
+	if len(result.Results) == 0 {
+		return nil, nil
+	}
+	return c.page(result.Results[0], space), nil
+}
+
+// storageBody is a page body in Confluence storage format (XHTML)
+func storageBody(body string) map[string]interface{} {
+	return map[string]interface{}{
+		"storage": map[string]string{"value": body, "representation": "storage"},
+	}
+}
+
+// CreatePage creates a page in space with a storage-format body, under the page
+// parentID when it is set
+func (c *Client) CreatePage(ctx context.Context, space, parentID, title, body string) (*Page, error) {
+	req := map[string]interface{}{
+		"type":  "page",
+		"title": title,
+		"space": map[string]string{"key": space},
+		"body":  storageBody(body),
+	}
+	if parentID != "" {
+		req["ancestors"] = []map[string]string{{"id": parentID}}
+	}
+	var raw content
+	if err := c.do(ctx, http.MethodPost, "/rest/api/content", req, &raw); err != nil {
+		return nil, err
+	}
+	return c.page(raw, space), nil
+}
+
+// UpdatePage replaces the body of page as its next version, keeping its title
+func (c *Client) UpdatePage(ctx context.Context, page *Page, body string) (*Page, error) {
+	req := map[string]interface{}{
+		"id":      page.ID,
+		"type":    "page",
+		"title":   page.Title,
+		"version": map[string]int{"number": page.Version + 1},
+		"body":    storageBody(body),
+	}
+	var raw content
+	if err := c.do(ctx, http.MethodPut, "/rest/api/content/"+page.ID, req, &raw); err != nil {
+		return nil, err
+	}
+	return c.page(raw, page.Space), nil
+}
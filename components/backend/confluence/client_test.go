@@ -0,0 +1,83 @@
+package confluence
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPublishPage(t *testing.T) {
+	var updated map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, token, _ := r.BasicAuth(); user != "dev@example.com" || token != "good" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/wiki/rest/api/content":
+			if r.URL.Query().Get("spaceKey") != "ENG" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			if r.URL.Query().Get("title") == "Design" {
+				fmt.Fprint(w, `{"results":[{"id":"42","type":"page","title":"Design","version":{"number":3},"_links":{"webui":"/spaces/ENG/pages/42"}}]}`)
+				return
+			}
+			fmt.Fprint(w, `{"results":[]}`)
+		case r.Method == http.MethodPost && r.URL.Path == "/wiki/rest/api/content":
+			var req struct {
+				Title     string `json:"title"`
+				Ancestors []struct {
+					ID string `json:"id"`
+				} `json:"ancestors"`
+			}
+			if json.NewDecoder(r.Body).Decode(&req) != nil || len(req.Ancestors) != 1 || req.Ancestors[0].ID != "7" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprintf(w, `{"id":"43","type":"page","title":%q,"space":{"key":"ENG"},"version":{"number":1},"_links":{"base":"https://acme.atlassian.net/wiki","webui":"/spaces/ENG/pages/43"}}`, req.Title)
+		case r.Method == http.MethodPut && r.URL.Path == "/wiki/rest/api/content/42":
+			_ = json.NewDecoder(r.Body).Decode(&updated)
+			fmt.Fprint(w, `{"id":"42","type":"page","title":"Design","version":{"number":4},"_links":{"webui":"/spaces/ENG/pages/42"}}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+	ctx := context.Background()
+	client := NewClient(srv.URL+"/wiki/", "dev@example.com", "good")
+
+	page, err := client.FindPage(ctx, "ENG", "Design")
+	if err != nil || page == nil || page.ID != "42" || page.Version != 3 || page.Space != "ENG" {
+		t.Fatalf("FindPage = %+v, %v", page, err)
+	}
+	page, err = client.UpdatePage(ctx, page, "<p>v4</p>")
+	if err != nil || page.Version != 4 || page.URL != srv.URL+"/wiki/spaces/ENG/pages/42" {
+		t.Fatalf("UpdatePage = %+v, %v", page, err)
+	}
+	if version, _ := updated["version"].(map[string]interface{}); version["number"] != float64(4) {
+		t.Errorf("update sent version %v, want 4", updated["version"])
+	}
+
+	if page, err := client.FindPage(ctx, "ENG", "Notes"); err != nil || page != nil {
+		t.Errorf("FindPage(missing) = %+v, %v", page, err)
+	}
+	page, err = client.CreatePage(ctx, "ENG", "7", "Notes", "<p>hi</p>")
+	if err != nil || page.ID != "43" || page.URL != "https://acme.atlassian.net/wiki/spaces/ENG/pages/43" {
+		t.Fatalf("CreatePage = %+v, %v", page, err)
+	}
+
+	if _, err := client.FindPage(ctx, "NOPE", "Design"); err != ErrNotFound {
+		t.Errorf("missing space: err = %v", err)
+	}
+	if _, err := NewClient(srv.URL+"/wiki", "dev@example.com", "bad").FindPage(ctx, "ENG", "Design"); err != ErrUnauthorized {
+		t.Errorf("bad token: err = %v", err)
+	}
+	if _, err := client.GetPage(ctx, "../admin"); err == nil {
+		t.Error("invalid page IDs must be rejected")
+	}
+}
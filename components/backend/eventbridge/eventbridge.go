@@ -0,0 +1,308 @@
+// Package eventbridge mirrors persisted AG-UI events and run lifecycle changes to an
+// external broker (Kafka through its REST proxy, or NATS) for downstream pipelines.
+package eventbridge
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"ambient-code-backend/logging"
+)
+
+// Kinds of records the bridge publishes
+const (
+	KindEvent = "agui.event"
+	KindRun   = "run.status"
+)
+
+// Serialization formats
+const (
+	FormatJSON        = "json"
+	FormatCloudEvents = "cloudevents"
+)
+
+const (
+	// queueSize bounds records waiting to be published; beyond it records are dropped
+	queueSize = 10000
+	// batchSize is the most records sent in one publish call
+	batchSize = 100
+	// publishTimeout bounds one publish call
+	publishTimeout = 10 * time.Second
+)
+
+// Record is one event or run status change
+type Record struct {
+	Kind    string
+	Project string
+	Session string
+	RunID   string
+	// Type is the AG-UI event type, or the run status for KindRun
+	Type string
+	Time time.Time
+	// Data is the event or run metadata exactly as persisted
+	Data json.RawMessage
+}
+
+// Message is a serialized record addressed to a Kafka topic or NATS subject
+type Message struct {
+	// Subject is the NATS subject; Kafka publishers use their configured topic
+	Subject string
+	// Key keeps a session's records in order on one Kafka partition
+	Key   string
+	Value []byte
+}
+
+// Publisher delivers messages to a broker
+type Publisher interface {
+	Publish(ctx context.Context, msgs []Message) error
+	Close() error
+}
+
+// Config selects and configures the broker
+type Config struct {
+	// Broker is "kafka", "nats", or empty to disable the bridge
+	Broker string
+	// URL is the Kafka REST proxy base URL (http[s]://) or the NATS server (nats:// or tls://)
+	URL string
+	// Topic is the Kafka topic, or the NATS subject prefix
+	Topic string
+	// Format is FormatJSON (default) or FormatCloudEvents
+	Format string
+	// Token authenticates to the broker: a bearer token for Kafka, an auth token for NATS
+	Token string
+}
+
+// Bridge serializes records and publishes them from a background goroutine so the
+// event path never blocks on the broker
+type Bridge struct {
+	publisher Publisher
+	format    string
+	topic     string
+	queue     chan Record
+	dropped   atomic.Int64
+	done      chan struct{}
+}
+
+var (
+	mu     sync.RWMutex
+	active *Bridge
+)
+
+// New starts a bridge publishing through p
+func New(p Publisher, topic, format string) *Bridge {
+	b := &Bridge{publisher: p, format: format, topic: topic, queue: make(chan Record, queueSize), done: make(chan struct{})}
+	go b.run()
+	return b
+}
+
+// Configure replaces the active bridge. An empty Broker disables publishing.
+func Configure(cfg Config) error {
+	var next *Bridge
+	if cfg.Broker != "" {
+		if cfg.URL == "" {
+			return fmt.Errorf("event bridge %q requires a URL", cfg.Broker)
+		}
+		format := cfg.Format
+		if format == "" {
+			format = FormatJSON
+		}
+		if format != FormatJSON && format != FormatCloudEvents {
+			return fmt.Errorf("unknown event bridge format %q (must be %s or %s)", format, FormatJSON, FormatCloudEvents)
+		}
+		topic := cfg.Topic
+		if topic == "" {
+			topic = "ambient"
+		}
+
+		var p Publisher
+		var err error
+		switch cfg.Broker {
+		case "kafka":
+			p, err = NewKafkaRESTPublisher(cfg.URL, topic, cfg.Token)
+		case "nats":
+			p, err = NewNATSPublisher(cfg.URL, cfg.Token)
+		default:
+			return fmt.Errorf("unknown event bridge broker %q (must be kafka or nats)", cfg.Broker)
+		}
+		if err != nil {
+			return err
+		}
+		next = New(p, topic, format)
+	}
+
+	mu.Lock()
+	previous := active
+	active = next
+	mu.Unlock()
+	if previous != nil {
+		previous.Close()
+	}
+	return nil
+}
+
+// ConfigureFromEnv configures the bridge from EVENT_BRIDGE (kafka|nats), EVENT_BRIDGE_URL,
+// EVENT_BRIDGE_TOPIC, EVENT_BRIDGE_FORMAT (json|cloudevents) and EVENT_BRIDGE_TOKEN
+func ConfigureFromEnv() error {
+	return Configure(Config{
+		Broker: strings.ToLower(strings.TrimSpace(os.Getenv("EVENT_BRIDGE"))),
+		URL:    os.Getenv("EVENT_BRIDGE_URL"),
+		Topic:  os.Getenv("EVENT_BRIDGE_TOPIC"),
+		Format: strings.ToLower(os.Getenv("EVENT_BRIDGE_FORMAT")),
+		Token:  os.Getenv("EVENT_BRIDGE_TOKEN"),
+	})
+}
+
+// PublishEvent queues a persisted AG-UI event. No-op when the bridge is disabled.
+func PublishEvent(project, session, runID, eventType string, data []byte) {
+	publish(Record{Kind: KindEvent, Project: project, Session: session, RunID: runID, Type: eventType, Data: data})
+}
+
+// PublishRun queues a run status change. No-op when the bridge is disabled.
+func PublishRun(project, session, runID, status string, data []byte) {
+	publish(Record{Kind: KindRun, Project: project, Session: session, RunID: runID, Type: status, Data: data})
+}
+
+func publish(r Record) {
+	mu.RLock()
+	b := active
+	mu.RUnlock()
+	if b == nil {
+		return
+	}
+	r.Time = time.Now().UTC()
+	b.Enqueue(r)
+}
+
+// Enqueue queues r for publishing. Records are dropped if the queue is full.
+func (b *Bridge) Enqueue(r Record) {
+	select {
+	case b.queue <- r:
+	default:
+		// Log the first drop and then every 1000th so a broker outage doesn't flood the logs
+		if n := b.dropped.Add(1); n == 1 || n%1000 == 0 {
+			logging.Warnf(context.Background(), "eventbridge: queue full, %d records dropped so far", n)
+		}
+	}
+}
+
+// Close stops the bridge after publishing what is already queued
+func (b *Bridge) Close() {
+	close(b.queue)
+	<-b.done
+	_ = b.publisher.Close()
+}
+
+func (b *Bridge) run() {
+	defer close(b.done)
+	batch := make([]Message, 0, batchSize)
+	for r := range b.queue {
+		batch = append(batch[:0], b.message(r))
+		// Drain whatever else is already waiting, up to a batch
+	drain:
+		for len(batch) < batchSize {
+			select {
+			case next, ok := <-b.queue:
+				if !ok {
+					break drain
+				}
+				batch = append(batch, b.message(next))
+			default:
+				break drain
+			}
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), publishTimeout)
+		if err := b.publisher.Publish(ctx, batch); err != nil {
+			logging.Warnf(ctx, "eventbridge: failed to publish %d records: %v", len(batch), err)
+		}
+		cancel()
+	}
+}
+
+// message serializes r in the bridge's format and addresses it
+func (b *Bridge) message(r Record) Message {
+	kind := "events"
+	if r.Kind == KindRun {
+		kind = "runs"
+	}
+	return Message{
+		Subject: strings.Join([]string{b.topic, subjectToken(r.Project), subjectToken(r.Session), kind}, "."),
+		Key:     r.Project + "/" + r.Session,
+		Value:   Serialize(r, b.format),
+	}
+}
+
+// subjectToken makes s usable as one NATS subject token
+func subjectToken(s string) string {
+	if s == "" {
+		return "_"
+	}
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '.', '*', '>', ' ', '\t', '\r', '\n':
+			return '_'
+		}
+		return r
+	}, s)
+}
+
+// envelope is the FormatJSON representation of a record
+type envelope struct {
+	Kind    string          `json:"kind"`
+	Project string          `json:"project"`
+	Session string          `json:"session"`
+	RunID   string          `json:"runId,omitempty"`
+	Type    string          `json:"type"`
+	Time    time.Time       `json:"time"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// cloudEvent is a CloudEvents 1.0 structured-mode JSON event
+type cloudEvent struct {
+	SpecVersion     string          `json:"specversion"`
+	ID              string          `json:"id"`
+	Source          string          `json:"source"`
+	Type            string          `json:"type"`
+	Subject         string          `json:"subject,omitempty"`
+	Time            time.Time       `json:"time"`
+	DataContentType string          `json:"datacontenttype"`
+	Data            json.RawMessage `json:"data"`
+}
+
+var cloudEventSeq atomic.Uint64
+
+// Serialize encodes r as FormatJSON or FormatCloudEvents
+func Serialize(r Record, format string) []byte {
+	data := r.Data
+	if len(data) == 0 {
+		data = json.RawMessage("null")
+	}
+	var v interface{}
+	if format == FormatCloudEvents {
+		// Type is e.g. io.ambient-code.agui.event.TEXT_MESSAGE_CONTENT or io.ambient-code.run.status.completed
+		v = cloudEvent{
+			SpecVersion:     "1.0",
+			ID:              fmt.Sprintf("%d-%d", r.Time.UnixNano(), cloudEventSeq.Add(1)),
+			Source:          fmt.Sprintf("/projects/%s/sessions/%s", r.Project, r.Session),
+			Type:            "io.ambient-code." + r.Kind + "." + r.Type,
+			Subject:         r.RunID,
+			Time:            r.Time,
+			DataContentType: "application/json",
+			Data:            data,
+		}
+	} else {
+		v = envelope{Kind: r.Kind, Project: r.Project, Session: r.Session, RunID: r.RunID, Type: r.Type, Time: r.Time, Data: data}
+	}
+	out, err := json.Marshal(v)
+	if err != nil {
+		// Data was produced by json.Marshal, so this only happens for corrupt input
+		out, _ = json.Marshal(envelope{Kind: r.Kind, Project: r.Project, Session: r.Session, RunID: r.RunID, Type: r.Type, Time: r.Time, Data: json.RawMessage("null")})
+	}
+	return out
+}
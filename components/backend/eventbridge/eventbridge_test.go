@@ -0,0 +1,273 @@
+package eventbridge
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type recordingPublisher struct {
+	mu   sync.Mutex
+	msgs []Message
+	got  chan struct{}
+}
+
+func (p *recordingPublisher) Publish(_ context.Context, msgs []Message) error {
+	p.mu.Lock()
+	p.msgs = append(p.msgs, msgs...)
+	p.mu.Unlock()
+	p.got <- struct{}{}
+	return nil
+}
+
+func (p *recordingPublisher) Close() error { return nil }
+
+func TestBridgeAddressesAndSerializes(t *testing.T) {
+	p := &recordingPublisher{got: make(chan struct{}, 10)}
+	b := New(p, "ambient", FormatJSON)
+	b.Enqueue(Record{Kind: KindEvent, Project: "team-a", Session: "s1", RunID: "r1", Type: "RUN_STARTED", Data: []byte(`{"type":"RUN_STARTED"}`)})
+	b.Enqueue(Record{Kind: KindRun, Project: "team-a", Session: "s1", RunID: "r1", Type: "completed", Data: []byte(`{"status":"completed"}`)})
+	b.Close()
+
+	if len(p.msgs) != 2 {
+		t.Fatalf("published %d messages, want 2", len(p.msgs))
+	}
+	if p.msgs[0].Subject != "ambient.team-a.s1.events" || p.msgs[1].Subject != "ambient.team-a.s1.runs" {
+		t.Errorf("subjects = %q, %q", p.msgs[0].Subject, p.msgs[1].Subject)
+	}
+	if p.msgs[0].Key != "team-a/s1" {
+		t.Errorf("key = %q", p.msgs[0].Key)
+	}
+	var env struct {
+		Kind string          `json:"kind"`
+		Type string          `json:"type"`
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(p.msgs[1].Value, &env); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if env.Kind != KindRun || env.Type != "completed" || string(env.Data) != `{"status":"completed"}` {
+		t.Errorf("envelope = %+v", env)
+	}
+}
+
+func TestSerializeCloudEvents(t *testing.T) {
+	out := Serialize(Record{Kind: KindEvent, Project: "p", Session: "s", RunID: "r", Type: "TEXT_MESSAGE_CONTENT", Time: time.Now(), Data: []byte(`{"delta":"hi"}`)}, FormatCloudEvents)
+	var ce map[string]interface{}
+	if err := json.Unmarshal(out, &ce); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if ce["specversion"] != "1.0" || ce["type"] != "io.ambient-code.agui.event.TEXT_MESSAGE_CONTENT" || ce["source"] != "/projects/p/sessions/s" || ce["subject"] != "r" {
+		t.Errorf("cloud event = %v", ce)
+	}
+	if data, _ := ce["data"].(map[string]interface{}); data["delta"] != "hi" {
+		t.Errorf("data = %v", ce["data"])
+	}
+}
+
+func TestConfigureValidates(t *testing.T) {
+	for _, cfg := range []Config{
+		{Broker: "kafka"},
+		{Broker: "rabbitmq", URL: "amqp://x"},
+		{Broker: "nats", URL: "http://x:4222"},
+		{Broker: "kafka", URL: "nats://x"},
+		{Broker: "nats", URL: "nats://x", Format: "avro"},
+	} {
+		if Configure(cfg) == nil {
+			t.Errorf("Configure(%+v) should fail", cfg)
+		}
+	}
+	if err := Configure(Config{}); err != nil {
+		t.Errorf("disabling the bridge failed: %v", err)
+	}
+	PublishEvent("p", "s", "r", "RUN_STARTED", []byte(`{}`)) // no-op when disabled
+}
+
+func TestKafkaRESTPublisher(t *testing.T) {
+	var gotPath, gotType, gotAuth string
+	var body struct {
+		Records []struct {
+			Key   string          `json:"key"`
+			Value json.RawMessage `json:"value"`
+		} `json:"records"`
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotPath, gotType, gotAuth = r.URL.Path, r.Header.Get("Content-Type"), r.Header.Get("Authorization")
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if len(body.Records) > 1 {
+			fmt.Fprint(w, `{"offsets":[{"partition":0,"offset":1},{"error_code":40403,"error":"topic not authorized"}]}`)
+			return
+		}
+		fmt.Fprint(w, `{"offsets":[{"partition":0,"offset":1}]}`)
+	}))
+	defer srv.Close()
+
+	p, err := NewKafkaRESTPublisher(srv.URL, "agent-events", "tok")
+	if err != nil {
+		t.Fatalf("NewKafkaRESTPublisher: %v", err)
+	}
+	if err := p.Publish(context.Background(), []Message{{Key: "p/s", Value: []byte(`{"a":1}`)}}); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+	if gotPath != "/topics/agent-events" || gotType != "application/vnd.kafka.json.v2+json" || gotAuth != "Bearer tok" {
+		t.Errorf("path=%q type=%q auth=%q", gotPath, gotType, gotAuth)
+	}
+	if body.Records[0].Key != "p/s" || string(body.Records[0].Value) != `{"a":1}` {
+		t.Errorf("records = %+v", body.Records)
+	}
+
+	err = p.Publish(context.Background(), []Message{{Value: []byte(`1`)}, {Value: []byte(`2`)}})
+	if err == nil || !strings.Contains(err.Error(), "topic not authorized") {
+		t.Errorf("partial failure: err = %v", err)
+	}
+}
+
+// fakeNATS accepts connections and records published messages
+type fakeNATS struct {
+	ln    net.Listener
+	mu    sync.Mutex
+	pubs  map[string]string
+	conn  []string // CONNECT payloads
+	stall bool     // leave PINGs after a PUB unanswered
+}
+
+func newFakeNATS(t *testing.T) *fakeNATS {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	s := &fakeNATS{ln: ln, pubs: map[string]string{}}
+	go func() {
+		for {
+			c, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go s.serve(c)
+		}
+	}()
+	t.Cleanup(func() { ln.Close() })
+	return s
+}
+
+func (s *fakeNATS) serve(c net.Conn) {
+	defer c.Close()
+	fmt.Fprint(c, "INFO {\"server_id\":\"test\",\"max_payload\":1048576}\r\n")
+	r := bufio.NewReader(c)
+	sawPub := false
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		switch fields[0] {
+		case "CONNECT":
+			s.mu.Lock()
+			s.conn = append(s.conn, strings.TrimSpace(strings.TrimPrefix(line, "CONNECT")))
+			s.mu.Unlock()
+		case "PING":
+			if !(s.stall && sawPub) {
+				fmt.Fprint(c, "PONG\r\n")
+			}
+		case "PUB":
+			sawPub = true
+			n, _ := strconv.Atoi(fields[len(fields)-1])
+			payload := make([]byte, n+2)
+			if _, err := io.ReadFull(r, payload); err != nil {
+				return
+			}
+			if strings.Contains(fields[1], "forbidden") {
+				fmt.Fprint(c, "-ERR 'Permissions Violation for Publish'\r\n")
+				continue
+			}
+			s.mu.Lock()
+			s.pubs[fields[1]] = string(payload[:n])
+			s.mu.Unlock()
+		}
+	}
+}
+
+func TestNATSPublisher(t *testing.T) {
+	srv := newFakeNATS(t)
+	p, err := NewNATSPublisher("nats://bridge:s3cret@"+srv.ln.Addr().String(), "")
+	if err != nil {
+		t.Fatalf("NewNATSPublisher: %v", err)
+	}
+	defer p.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	msgs := []Message{
+		{Subject: "ambient.p.s.events", Value: []byte(`{"n":1}`)},
+		{Subject: "ambient.p.s.runs", Value: []byte(`{"n":2}`)},
+	}
+	if err := p.Publish(ctx, msgs); err != nil {
+		t.Fatalf("Publish: %v", err)
+	}
+
+	srv.mu.Lock()
+	if srv.pubs["ambient.p.s.events"] != `{"n":1}` || srv.pubs["ambient.p.s.runs"] != `{"n":2}` {
+		t.Errorf("published = %v", srv.pubs)
+	}
+	if len(srv.conn) != 1 || !strings.Contains(srv.conn[0], `"user":"bridge"`) || !strings.Contains(srv.conn[0], `"pass":"s3cret"`) {
+		t.Errorf("CONNECT = %v", srv.conn)
+	}
+	srv.mu.Unlock()
+
+	if err := p.Publish(ctx, []Message{{Subject: "forbidden", Value: []byte(`{}`)}}); err == nil || !strings.Contains(err.Error(), "Permissions Violation") {
+		t.Errorf("server error: err = %v", err)
+	}
+	// The publisher reconnects after an error
+	if err := p.Publish(ctx, []Message{{Subject: "ambient.p.s.events", Value: []byte(`{"n":3}`)}}); err != nil {
+		t.Fatalf("Publish after error: %v", err)
+	}
+}
+
+func TestNATSPublisherStalledServer(t *testing.T) {
+	srv := newFakeNATS(t)
+	srv.stall = true
+	origTimeout := natsPublishTimeout
+	natsPublishTimeout = 200 * time.Millisecond
+	defer func() { natsPublishTimeout = origTimeout }()
+	p, err := NewNATSPublisher("nats://"+srv.ln.Addr().String(), "")
+	if err != nil {
+		t.Fatalf("NewNATSPublisher: %v", err)
+	}
+	defer p.Close()
+
+	done := make(chan error, 1)
+	go func() {
+		done <- p.Publish(context.Background(), []Message{{Subject: "ambient.p.s.events", Value: []byte(`{}`)}})
+	}()
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Fatal("Publish to a stalled server succeeded")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Publish without a context deadline did not time out")
+	}
+
+	// A caller waiting for the connection gives up with its context
+	p.lock <- struct{}{}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := p.Publish(ctx, nil); err != context.DeadlineExceeded {
+		t.Errorf("Publish while locked = %v, want context.DeadlineExceeded", err)
+	}
+	<-p.lock
+}
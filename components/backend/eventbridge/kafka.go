@@ -0,0 +1,96 @@
+package eventbridge
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// KafkaRESTPublisher produces to a Kafka topic through the Confluent REST Proxy v2 API,
+// which the Strimzi Kafka Bridge also implements. This keeps the backend free of a
+// native Kafka client.
+type KafkaRESTPublisher struct {
+	endpoint string
+	token    string
+	client   *http.Client
+}
+
+// NewKafkaRESTPublisher publishes to topic through the REST proxy at baseURL. Credentials
+// in the URL are sent as basic auth; token, if set, as a bearer token.
+func NewKafkaRESTPublisher(baseURL, topic, token string) (*KafkaRESTPublisher, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("kafka event bridge URL must be an http(s) REST proxy URL")
+	}
+	return &KafkaRESTPublisher{
+		endpoint: strings.TrimSuffix(baseURL, "/") + "/topics/" + url.PathEscape(topic),
+		token:    token,
+		client:   &http.Client{Timeout: publishTimeout},
+	}, nil
+}
+
+type kafkaRecord struct {
+	Key   string          `json:"key"`
+	Value json.RawMessage `json:"value"`
+}
+
+// Publish sends msgs as one produce request
+func (p *KafkaRESTPublisher) Publish(ctx context.Context, msgs []Message) error {
+	records := make([]kafkaRecord, len(msgs))
+	for i, m := range msgs {
+		records[i] = kafkaRecord{Key: m.Key, Value: m.Value}
+	}
+	payload, err := json.Marshal(map[string]interface{}{"records": records})
+	if err != nil {
+		return fmt.Errorf("failed to encode records: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/vnd.kafka.json.v2+json")
+	req.Header.Set("Accept", "application/vnd.kafka.v2+json, application/json")
+	if p.token != "" {
+		req.Header.Set("Authorization", "Bearer "+p.token)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		// Don't wrap error - the URL may carry credentials
+		return fmt.Errorf("request to REST proxy failed")
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("REST proxy returned %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	// A 200 can still carry per-record failures
+	var result struct {
+		Offsets []struct {
+			Error *string `json:"error"`
+		} `json:"offsets"`
+	}
+	if err := json.Unmarshal(body, &result); err == nil {
+		for _, o := range result.Offsets {
+			if o.Error != nil && *o.Error != "" {
+				return fmt.Errorf("REST proxy rejected records: %s", *o.Error)
+			}
+		}
+	}
+	return nil
+}
+
+// Close releases idle connections to the REST proxy
+func (p *KafkaRESTPublisher) Close() error {
+	p.client.CloseIdleConnections()
+	return nil
+}
+
+var _ Publisher = (*KafkaRESTPublisher)(nil)
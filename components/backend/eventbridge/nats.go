@@ -0,0 +1,207 @@
+package eventbridge
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// natsDialTimeout bounds connecting and the protocol handshake
+const natsDialTimeout = 5 * time.Second
+
+// natsPublishTimeout bounds a publish round trip when the caller's context has no
+// earlier deadline, so a stalled server can't hold the connection forever
+var natsPublishTimeout = 30 * time.Second
+
+// NATSPublisher publishes with the NATS core text protocol (CONNECT/PUB/PING). It keeps
+// one connection, reconnecting on the next publish after a failure, and confirms each
+// batch with a PING/PONG round trip so server errors surface.
+type NATSPublisher struct {
+	addr     string
+	useTLS   bool
+	host     string
+	user     string
+	password string
+	token    string
+
+	// lock guards conn and r. It's a channel so waiting for it respects the caller's
+	// context.
+	lock chan struct{}
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+// NewNATSPublisher publishes to the server at rawURL (nats://host:4222 or tls://host:4222,
+// optionally with user:password). token, if set, is sent as the auth token.
+func NewNATSPublisher(rawURL, token string) (*NATSPublisher, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "nats" && u.Scheme != "tls") || u.Hostname() == "" {
+		return nil, fmt.Errorf("nats event bridge URL must be nats://host:port or tls://host:port")
+	}
+	addr := u.Host
+	if u.Port() == "" {
+		addr = net.JoinHostPort(u.Hostname(), "4222")
+	}
+	p := &NATSPublisher{addr: addr, useTLS: u.Scheme == "tls", host: u.Hostname(), token: token, lock: make(chan struct{}, 1)}
+	if u.User != nil {
+		p.user = u.User.Username()
+		p.password, _ = u.User.Password()
+	}
+	return p, nil
+}
+
+// Publish sends msgs and waits for the server to acknowledge them with a PONG. A
+// connection left idle may have been dropped by the server, so a failure on a reused
+// connection is retried once on a new one.
+func (p *NATSPublisher) Publish(ctx context.Context, msgs []Message) error {
+	select {
+	case p.lock <- struct{}{}:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	defer func() { <-p.lock }()
+
+	reused := p.conn != nil
+	err := p.publish(ctx, msgs)
+	if err != nil && reused && ctx.Err() == nil {
+		err = p.publish(ctx, msgs)
+	}
+	return err
+}
+
+func (p *NATSPublisher) publish(ctx context.Context, msgs []Message) error {
+	if p.conn == nil {
+		if err := p.connect(ctx); err != nil {
+			return err
+		}
+	}
+	deadline := time.Now().Add(natsPublishTimeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	_ = p.conn.SetDeadline(deadline)
+
+	w := bufio.NewWriter(p.conn)
+	for _, m := range msgs {
+		fmt.Fprintf(w, "PUB %s %d\r\n", m.Subject, len(m.Value))
+		w.Write(m.Value)
+		w.WriteString("\r\n")
+	}
+	w.WriteString("PING\r\n")
+	if err := w.Flush(); err != nil {
+		p.reset()
+		return fmt.Errorf("nats write failed: %w", err)
+	}
+	if err := p.awaitPong(); err != nil {
+		p.reset()
+		return err
+	}
+	return nil
+}
+
+// Close closes the connection
+func (p *NATSPublisher) Close() error {
+	p.lock <- struct{}{}
+	defer func() { <-p.lock }()
+	p.reset()
+	return nil
+}
+
+func (p *NATSPublisher) reset() {
+	if p.conn != nil {
+		_ = p.conn.Close()
+	}
+	p.conn, p.r = nil, nil
+}
+
+// connect dials the server, reads its INFO, upgrades to TLS when required and
+// authenticates. Must be called with p.lock held.
+func (p *NATSPublisher) connect(ctx context.Context) error {
+	dialer := &net.Dialer{Timeout: natsDialTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", p.addr)
+	if err != nil {
+		return fmt.Errorf("nats dial failed: %w", err)
+	}
+	_ = conn.SetDeadline(time.Now().Add(natsDialTimeout))
+
+	r := bufio.NewReader(conn)
+	line, err := r.ReadString('\n')
+	if err != nil || !strings.HasPrefix(line, "INFO ") {
+		conn.Close()
+		return fmt.Errorf("nats server did not send INFO")
+	}
+	var info struct {
+		TLSRequired bool `json:"tls_required"`
+	}
+	_ = json.Unmarshal([]byte(strings.TrimPrefix(strings.TrimSpace(line), "INFO ")), &info)
+
+	if p.useTLS || info.TLSRequired {
+		tlsConn := tls.Client(conn, &tls.Config{ServerName: p.host, MinVersion: tls.VersionTLS12})
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			conn.Close()
+			return fmt.Errorf("nats TLS handshake failed: %w", err)
+		}
+		conn = tlsConn
+		r = bufio.NewReader(conn)
+	}
+
+	connect := map[string]interface{}{
+		"verbose":      false,
+		"pedantic":     false,
+		"tls_required": p.useTLS || info.TLSRequired,
+		"name":         "ambient-code-backend",
+		"lang":         "go",
+		"version":      "1.0",
+		"protocol":     0,
+	}
+	if p.user != "" {
+		connect["user"] = p.user
+		connect["pass"] = p.password
+	}
+	if p.token != "" {
+		connect["auth_token"] = p.token
+	}
+	payload, _ := json.Marshal(connect)
+	if _, err := fmt.Fprintf(conn, "CONNECT %s\r\nPING\r\n", payload); err != nil {
+		conn.Close()
+		return fmt.Errorf("nats handshake failed: %w", err)
+	}
+
+	p.conn, p.r = conn, r
+	if err := p.awaitPong(); err != nil {
+		p.reset()
+		return err
+	}
+	_ = conn.SetDeadline(time.Time{})
+	return nil
+}
+
+// awaitPong reads until the server's PONG, answering server PINGs and failing on -ERR
+func (p *NATSPublisher) awaitPong() error {
+	for {
+		line, err := p.r.ReadString('\n')
+		if err != nil {
+			return fmt.Errorf("nats read failed: %w", err)
+		}
+		line = strings.TrimSpace(line)
+		switch {
+		case line == "PONG":
+			return nil
+		case line == "PING":
+			if _, err := p.conn.Write([]byte("PONG\r\n")); err != nil {
+				return fmt.Errorf("nats write failed: %w", err)
+			}
+		case strings.HasPrefix(line, "-ERR"):
+			return fmt.Errorf("nats server error: %s", strings.TrimSpace(strings.TrimPrefix(line, "-ERR")))
+		}
+		// +OK and INFO updates need no action
+	}
+}
+
+var _ Publisher = (*NATSPublisher)(nil)
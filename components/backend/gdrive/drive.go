@@ -0,0 +1,201 @@
+// Package gdrive is a minimal Google Drive API (v3) client covering what sessions
+// need: reading a document as markdown to give a session as context.
+package gdrive
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"ambient-code-backend/metrics"
+)
+
+// MaxDocumentBytes caps the exported content of a document
+const MaxDocumentBytes = 2 << 20
+
+// googleDocMimeType is the Drive type of native Google Docs, which are exported
+const googleDocMimeType = "application/vnd.google-apps.document"
+
+// apiBaseURL is the Drive API root; tests point it at a fake server
+var apiBaseURL = "https://www.googleapis.com/drive/v3"
+
+// ErrUnauthorized is returned when Google rejects the access token or its scopes
+var ErrUnauthorized = errors.New("google rejected the access token")
+
+// ErrNotFound is returned for files that don't exist or the user can't see
+var ErrNotFound = errors.New("drive file not found")
+
+// ErrUnsupported is returned for files that can't be read as markdown or text
+var ErrUnsupported = errors.New("drive file is not a Google Doc or text file")
+
+// ErrTooLarge is returned when a document exceeds MaxDocumentBytes
+var ErrTooLarge = fmt.Errorf("drive document is larger than %d bytes", MaxDocumentBytes)
+
+var (
+	fileIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{10,}$`)
+	// fileURLPattern matches Docs and Drive links, e.g. docs.google.com/document/d/<id>/edit
+	fileURLPattern = regexp.MustCompile(`^https://(?:docs|drive)\.google\.com/(?:[a-z]+/)?(?:document|file)/d/([A-Za-z0-9_-]{10,})(?:[/?#].*)?$`)
+	// openURLPattern matches drive.google.com/open?id=<id>
+	openURLPattern = regexp.MustCompile(`^https://drive\.google\.com/open\?(?:.*&)?id=([A-Za-z0-9_-]{10,})(?:&.*)?$`)
+)
+
+// ParseFileID returns the file ID of a Google Docs or Drive URL, or of a bare ID
+func ParseFileID(s string) (string, error) {
+	s = strings.TrimSpace(s)
+	if fileIDPattern.MatchString(s) {
+		return s, nil
+	}
+	for _, pattern := range []*regexp.Regexp{fileURLPattern, openURLPattern} {
+		if m := pattern.FindStringSubmatch(s); m != nil {
+			return m[1], nil
+		}
+	}
+	return "", fmt.Errorf("expected a Google Docs or Drive URL or a file ID")
+}
+
+// Client is a Drive API client authenticated with a user's OAuth access token
+type Client struct {
+	httpClient  *http.Client
+	accessToken string
+}
+
+// NewClient creates a Drive API client with a 30-second timeout
+func NewClient(accessToken string) *Client {
+	return &Client{
+		httpClient: &http.Client{
+			Timeout:   30 * time.Second,
+			Transport: metrics.Transport("google", "drive", nil),
+		},
+		accessToken: accessToken,
+	}
+}
+
+// Document is a Drive document as sessions see it
+type Document struct {
+	ID           string `json:"id"`
+	Name         string `json:"name"`
+	MimeType     string `json:"mimeType"`
+	URL          string `json:"url,omitempty"`
+	ModifiedTime string `json:"modifiedTime,omitempty"`
+	// Markdown is the document's content: Google Docs exported as markdown, text
+	// files as they are
+	Markdown string `json:"-"`
+}
+
+// get fetches path from the API, returning at most limit bytes of the response
+func (c *Client) get(ctx context.Context, path string, limit int64) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiBaseURL+path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+c.accessToken)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		// Don't wrap error - could leak the token from request details
+		return nil, fmt.Errorf("request failed")
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusUnauthorized, http.StatusForbidden:
+		// 403 means the token lacks the Drive scopes; files not shared with the
+		// user come back as 404
+		return nil, ErrUnauthorized
+	case http.StatusNotFound:
+		return nil, ErrNotFound
+	default:
+		return nil, fmt.Errorf("drive returned %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if int64(len(body)) > limit {
+		return nil, ErrTooLarge
+	}
+	return body, nil
+}
+
+// GetDocument reads a file's metadata and its content as markdown
+func (c *Client) GetDocument(ctx context.Context, fileID string) (*Document, error) {
+	if !fileIDPattern.MatchString(fileID) {
+		return nil, fmt.Errorf("invalid file ID %q", fileID)
+	}
+	path := "/files/" + url.PathEscape(fileID)
+	raw, err := c.get(ctx, path+"?fields=id,name,mimeType,modifiedTime,webViewLink&supportsAllDrives=true", 1<<20)
+	if err != nil {
+		return nil, err
+	}
+	var meta struct {
+		ID           string `json:"id"`
+		Name         string `json:"name"`
+		MimeType     string `json:"mimeType"`
+		ModifiedTime string `json:"modifiedTime"`
+		WebViewLink  string `json:"webViewLink"`
+	}
+	if err := json.Unmarshal(raw, &meta); err != nil {
+		return nil, fmt.Errorf("failed to decode file metadata: %w", err)
+	}
+
+	var content []byte
+	switch {
+	case meta.MimeType == googleDocMimeType:
+		content, err = c.get(ctx, path+"/export?mimeType="+url.QueryEscape("text/markdown"), MaxDocumentBytes)
+	case strings.HasPrefix(meta.MimeType, "text/"):
+		content, err = c.get(ctx, path+"?alt=media&supportsAllDrives=true", MaxDocumentBytes)
+	default:
+		return nil, ErrUnsupported
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &Document{
+		ID:           meta.ID,
+		Name:         meta.Name,
+		MimeType:     meta.MimeType,
+		URL:          meta.WebViewLink,
+		ModifiedTime: meta.ModifiedTime,
+		Markdown:     string(content),
+	}, nil
+}
+
+// Filename is a workspace-safe markdown filename derived from the document name
+func (d *Document) Filename() string {
+	var b strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(d.Name) {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' {
+			b.WriteRune(r)
+			dash = false
+		} else if !dash && b.Len() > 0 {
+			b.WriteByte('-')
+			dash = true
+		}
+	}
+	name := strings.TrimRight(b.String(), "-")
+	if len(name) > 60 {
+		name = strings.TrimRight(name[:60], "-")
+	}
+	if name == "" {
+		name = d.ID
+	}
+	return name + ".md"
+}
+
+// Context formats the document as a <google-doc> block for a session's context
+func (d *Document) Context() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "<google-doc id=%q title=%q url=%q modified=%q>\n", d.ID, d.Name, d.URL, d.ModifiedTime)
+	b.WriteString(strings.TrimSpace(d.Markdown))
+	b.WriteString("\n</google-doc>")
+	return b.String()
+}
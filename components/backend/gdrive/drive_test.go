@@ -0,0 +1,80 @@
+package gdrive
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestParseFileID(t *testing.T) {
+	for in, want := range map[string]string{
+		"1AbCdEfGhIjKlMnOp": "1AbCdEfGhIjKlMnOp",
+		"https://docs.google.com/document/d/1AbCdEfGhIjKlMnOp/edit#h=1": "1AbCdEfGhIjKlMnOp",
+		"https://drive.google.com/file/d/1AbCdEfGhIjKlMnOp/view":        "1AbCdEfGhIjKlMnOp",
+		"https://drive.google.com/open?id=1AbCdEfGhIjKlMnOp":            "1AbCdEfGhIjKlMnOp",
+	} {
+		if id, err := ParseFileID(in); err != nil || id != want {
+			t.Errorf("ParseFileID(%q) = %q, %v; want %s", in, id, err, want)
+		}
+	}
+	for _, in := range []string{"", "short", "../1AbCdEfGhIjKlMnOp", "https://example.com/document/d/1AbCdEfGhIjKlMnOp/edit"} {
+		if _, err := ParseFileID(in); err == nil {
+			t.Errorf("ParseFileID(%q) should fail", in)
+		}
+	}
+}
+
+func TestGetDocument(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer good" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/files/docAAAAAAAAAA":
+			fmt.Fprint(w, `{"id":"docAAAAAAAAAA","name":"Checkout: v2 spec","mimeType":"application/vnd.google-apps.document",
+				"modifiedTime":"2026-03-01T09:00:00Z","webViewLink":"https://docs.google.com/document/d/docAAAAAAAAAA/edit"}`)
+		case "/files/docAAAAAAAAAA/export":
+			if r.URL.Query().Get("mimeType") != "text/markdown" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			fmt.Fprint(w, "# Checkout v2\n\nUsers can pay with saved cards.\n")
+		case "/files/imgAAAAAAAAAA":
+			fmt.Fprint(w, `{"id":"imgAAAAAAAAAA","name":"logo.png","mimeType":"image/png"}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+	original := apiBaseURL
+	apiBaseURL = srv.URL
+	defer func() { apiBaseURL = original }()
+
+	doc, err := NewClient("good").GetDocument(context.Background(), "docAAAAAAAAAA")
+	if err != nil {
+		t.Fatalf("GetDocument: %v", err)
+	}
+	if doc.Name != "Checkout: v2 spec" || !strings.HasPrefix(doc.Markdown, "# Checkout v2") || doc.Filename() != "checkout-v2-spec.md" {
+		t.Errorf("document = %+v (filename %s)", doc, doc.Filename())
+	}
+	sessionContext := doc.Context()
+	for _, part := range []string{`<google-doc id="docAAAAAAAAAA" title="Checkout: v2 spec"`, "saved cards.\n</google-doc>"} {
+		if !strings.Contains(sessionContext, part) {
+			t.Errorf("context is missing %q:\n%s", part, sessionContext)
+		}
+	}
+
+	if _, err := NewClient("good").GetDocument(context.Background(), "imgAAAAAAAAAA"); err != ErrUnsupported {
+		t.Errorf("image: err = %v", err)
+	}
+	if _, err := NewClient("good").GetDocument(context.Background(), "missingAAAAA"); err != ErrNotFound {
+		t.Errorf("missing document: err = %v", err)
+	}
+	if _, err := NewClient("bad").GetDocument(context.Background(), "docAAAAAAAAAA"); err != ErrUnauthorized {
+		t.Errorf("bad token: err = %v", err)
+	}
+}
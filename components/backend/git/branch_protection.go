@@ -0,0 +1,117 @@
+package git
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"ambient-code-backend/gitlab"
+	"ambient-code-backend/types"
+)
+
+// BranchPushCheck reports whether the caller's credentials can push to a remote branch
+type BranchPushCheck struct {
+	RepoURL   string `json:"repoUrl"`
+	Branch    string `json:"branch"`
+	Exists    bool   `json:"exists"`
+	Protected bool   `json:"protected"`
+	CanPush   bool   `json:"canPush"`
+	Reason    string `json:"reason,omitempty"`
+}
+
+// CheckBranchPushable verifies, before a run starts, that the token can push to branch.
+// A branch that does not exist yet is pushable when the token has write access to the repo.
+func CheckBranchPushable(ctx context.Context, repoURL, branch, token string) (*BranchPushCheck, error) {
+	check := &BranchPushCheck{RepoURL: repoURL, Branch: branch}
+	if token == "" {
+		check.Reason = "no git credentials configured for this repository"
+		return check, nil
+	}
+
+	if err := validatePushAccess(ctx, repoURL, token); err != nil {
+		check.Reason = err.Error()
+		return check, nil
+	}
+
+	var err error
+	switch types.DetectProvider(repoURL) {
+	case types.ProviderGitHub:
+		err = checkGitHubBranchProtection(ctx, check, token)
+	case types.ProviderGitLab:
+		err = checkGitLabBranchProtection(ctx, check, token)
+	default:
+		return nil, fmt.Errorf("unsupported repository provider for URL: %s", repoURL)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if check.Protected {
+		check.Reason = fmt.Sprintf("you can't push to %s: the branch is protected", branch)
+		return check, nil
+	}
+	check.CanPush = true
+	return check, nil
+}
+
+func checkGitHubBranchProtection(ctx context.Context, check *BranchPushCheck, token string) error {
+	owner, repo, err := ParseGitHubURL(check.RepoURL)
+	if err != nil {
+		return fmt.Errorf("invalid GitHub repository URL: %w", err)
+	}
+	apiURL := fmt.Sprintf("%s/repos/%s/%s/branches/%s", githubAPIBaseURL, owner, repo, url.PathEscape(check.Branch))
+	body, status, err := doGitAPIRequest(ctx, http.MethodGet, apiURL, "Bearer "+token, nil)
+	if err != nil {
+		return err
+	}
+	switch status {
+	case http.StatusOK:
+		var info struct {
+			Protected bool `json:"protected"`
+		}
+		if err := json.Unmarshal(body, &info); err != nil {
+			return fmt.Errorf("failed to parse branch info: %w", err)
+		}
+		check.Exists = true
+		check.Protected = info.Protected
+		return nil
+	case http.StatusNotFound:
+		return nil
+	default:
+		return fmt.Errorf("GitHub API error: %d (body: %s)", status, string(body))
+	}
+}
+
+func checkGitLabBranchProtection(ctx context.Context, check *BranchPushCheck, token string) error {
+	parsed, err := gitlab.ParseGitLabURL(check.RepoURL)
+	if err != nil {
+		return fmt.Errorf("invalid GitLab repository URL: %w", err)
+	}
+
+	// Note: parsed.ProjectID is already URL-encoded
+	branchURL := fmt.Sprintf("%s/projects/%s/repository/branches/%s", parsed.APIURL, parsed.ProjectID, url.PathEscape(check.Branch))
+	body, status, err := doGitAPIRequest(ctx, http.MethodGet, branchURL, "Bearer "+token, nil)
+	if err != nil {
+		return err
+	}
+	switch status {
+	case http.StatusOK:
+		var info struct {
+			Protected bool `json:"protected"`
+			CanPush   bool `json:"can_push"`
+		}
+		if err := json.Unmarshal(body, &info); err != nil {
+			return fmt.Errorf("failed to parse branch info: %w", err)
+		}
+		check.Exists = true
+		// GitLab reports whether the current user may push despite protection
+		check.Protected = info.Protected && !info.CanPush
+		return nil
+	case http.StatusNotFound:
+		return nil
+	default:
+		return fmt.Errorf("GitLab API error: %d (body: %s)", status, string(body))
+	}
+}
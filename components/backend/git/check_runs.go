@@ -0,0 +1,144 @@
+package git
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+)
+
+// CheckRunName is the check-run / commit status context shown on pull requests
+const CheckRunName = "vTeam agent"
+
+// SessionCheck describes the state of an agent run to publish on a GitHub pull request
+type SessionCheck struct {
+	RepoURL  string
+	PRNumber int
+	// Status is "in_progress" or "completed"
+	Status string
+	// Conclusion is set when Status is "completed": success, failure, or neutral
+	Conclusion string
+	Title      string
+	Summary    string
+	DetailsURL string
+	// CheckRunID updates an existing check run instead of creating one
+	CheckRunID int64
+}
+
+// PublishSessionCheck creates or updates the check run for the PR's head commit and returns
+// its ID. Check runs require GitHub App credentials; with a PAT it falls back to a commit
+// status (returned ID 0).
+func PublishSessionCheck(ctx context.Context, token string, check SessionCheck) (int64, error) {
+	owner, repo, err := ParseGitHubURL(check.RepoURL)
+	if err != nil {
+		return 0, fmt.Errorf("invalid GitHub repository URL: %w", err)
+	}
+
+	sha, err := getPullRequestHeadSHA(ctx, owner, repo, check.PRNumber, token)
+	if err != nil {
+		return 0, err
+	}
+
+	payload := map[string]interface{}{
+		"name":     CheckRunName,
+		"head_sha": sha,
+		"status":   check.Status,
+		"output": map[string]interface{}{
+			"title":   check.Title,
+			"summary": check.Summary,
+		},
+	}
+	if check.DetailsURL != "" {
+		payload["details_url"] = check.DetailsURL
+	}
+	if check.Status == "completed" {
+		payload["conclusion"] = check.Conclusion
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return 0, fmt.Errorf("failed to encode check run: %w", err)
+	}
+
+	method := http.MethodPost
+	apiURL := fmt.Sprintf("%s/repos/%s/%s/check-runs", githubAPIBaseURL, owner, repo)
+	if check.CheckRunID != 0 {
+		method = http.MethodPatch
+		apiURL = fmt.Sprintf("%s/%d", apiURL, check.CheckRunID)
+	}
+	respBody, status, err := doGitAPIRequest(ctx, method, apiURL, "Bearer "+token, body)
+	if err != nil {
+		return 0, err
+	}
+
+	switch status {
+	case http.StatusOK, http.StatusCreated:
+		var run struct {
+			ID int64 `json:"id"`
+		}
+		if err := json.Unmarshal(respBody, &run); err != nil {
+			return 0, fmt.Errorf("failed to parse check run response: %w", err)
+		}
+		return run.ID, nil
+	case http.StatusForbidden, http.StatusNotFound, http.StatusUnprocessableEntity:
+		// Not a GitHub App token (or the App lacks checks:write): use a commit status
+		log.Printf("Check run not permitted on %s/%s (status %d), falling back to commit status", owner, repo, status)
+		return 0, postCommitStatus(ctx, owner, repo, sha, token, check)
+	default:
+		return 0, fmt.Errorf("GitHub API error: %d (body: %s)", status, string(respBody))
+	}
+}
+
+func getPullRequestHeadSHA(ctx context.Context, owner, repo string, number int, token string) (string, error) {
+	apiURL := fmt.Sprintf("%s/repos/%s/%s/pulls/%d", githubAPIBaseURL, owner, repo, number)
+	body, status, err := doGitAPIRequest(ctx, http.MethodGet, apiURL, "Bearer "+token, nil)
+	if err != nil {
+		return "", err
+	}
+	if status != http.StatusOK {
+		return "", fmt.Errorf("failed to get pull request %s/%s#%d: %d", owner, repo, number, status)
+	}
+	var pr struct {
+		Head struct {
+			SHA string `json:"sha"`
+		} `json:"head"`
+	}
+	if err := json.Unmarshal(body, &pr); err != nil {
+		return "", fmt.Errorf("failed to parse pull request: %w", err)
+	}
+	return pr.Head.SHA, nil
+}
+
+func postCommitStatus(ctx context.Context, owner, repo, sha, token string, check SessionCheck) error {
+	state := "pending"
+	if check.Status == "completed" {
+		switch check.Conclusion {
+		case "success", "neutral":
+			state = "success"
+		default:
+			state = "failure"
+		}
+	}
+	payload := map[string]interface{}{
+		"state":       state,
+		"context":     CheckRunName,
+		"description": check.Title,
+	}
+	if check.DetailsURL != "" {
+		payload["target_url"] = check.DetailsURL
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode commit status: %w", err)
+	}
+
+	apiURL := fmt.Sprintf("%s/repos/%s/%s/statuses/%s", githubAPIBaseURL, owner, repo, sha)
+	respBody, status, err := doGitAPIRequest(ctx, http.MethodPost, apiURL, "Bearer "+token, body)
+	if err != nil {
+		return err
+	}
+	if status != http.StatusCreated {
+		return fmt.Errorf("GitHub API error: %d (body: %s)", status, string(respBody))
+	}
+	return nil
+}
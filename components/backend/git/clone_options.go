@@ -0,0 +1,116 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// CloneOptions limits how much of a repository is fetched. The zero value is a full clone.
+type CloneOptions struct {
+	// Depth truncates history to the given number of commits (0 = full history)
+	Depth int
+	// SingleBranch fetches only the checked-out branch
+	SingleBranch bool
+	// SparsePaths restricts the working tree to these directories (cone mode)
+	SparsePaths []string
+	// LFS enables LFS-aware cloning; nil leaves LFS to the git defaults
+	LFS *LFSOptions
+	// ReferenceDir borrows objects from a local mirror (see MirrorReferenceDir)
+	ReferenceDir string
+}
+
+// ValidateCloneOptions rejects negative depths and sparse paths that escape the repository
+func ValidateCloneOptions(opts CloneOptions) error {
+	if opts.Depth < 0 {
+		return fmt.Errorf("depth must be >= 0, got %d", opts.Depth)
+	}
+	if opts.LFS != nil && opts.LFS.MaxObjectSizeBytes < 0 {
+		return fmt.Errorf("LFS max object size must be >= 0, got %d", opts.LFS.MaxObjectSizeBytes)
+	}
+	for _, p := range opts.SparsePaths {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			return fmt.Errorf("sparse path must not be empty")
+		}
+		if strings.HasPrefix(p, "/") || path.Clean(p) == ".." || strings.HasPrefix(path.Clean(p), "../") {
+			return fmt.Errorf("sparse path %q must be relative to the repository root", p)
+		}
+	}
+	return nil
+}
+
+// CloneArgs builds the `git clone` arguments for url@branch into dir.
+// Sparse clones use a blobless partial clone without checkout; call
+// ApplySparseCheckout afterwards to populate the working tree.
+func (o CloneOptions) CloneArgs(url, branch, dir string) []string {
+	args := []string{"clone"}
+	if o.Depth > 0 {
+		args = append(args, "--depth", strconv.Itoa(o.Depth))
+	}
+	if o.SingleBranch {
+		args = append(args, "--single-branch")
+	} else if o.Depth > 0 {
+		// --depth implies --single-branch; keep other branches fetchable
+		args = append(args, "--no-single-branch")
+	}
+	if len(o.SparsePaths) > 0 {
+		args = append(args, "--filter=blob:none", "--no-checkout")
+	}
+	if o.ReferenceDir != "" {
+		args = append(args, "--reference-if-able", o.ReferenceDir)
+	}
+	if branch != "" {
+		args = append(args, "--branch", branch)
+	}
+	return append(args, url, dir)
+}
+
+// ApplySparseCheckout restricts repoDir to the given paths and checks out the working tree
+func ApplySparseCheckout(ctx context.Context, repoDir string, paths []string) error {
+	return applySparseCheckout(ctx, repoDir, paths, nil)
+}
+
+func applySparseCheckout(ctx context.Context, repoDir string, paths []string, env []string) error {
+	setArgs := append([]string{"-C", repoDir, "sparse-checkout", "set", "--cone"}, paths...)
+	if out, err := exec.CommandContext(ctx, "git", setArgs...).CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to configure sparse checkout: %w (output: %s)", err, string(out))
+	}
+	checkout := exec.CommandContext(ctx, "git", "-C", repoDir, "checkout")
+	if len(env) > 0 {
+		checkout.Env = append(os.Environ(), env...)
+	}
+	if out, err := checkout.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to check out sparse working tree: %w (output: %s)", err, string(out))
+	}
+	return nil
+}
+
+// CloneRepository clones url@branch into dir honoring opts. LFS objects are fetched
+// with the credentials embedded in url; use ConfigureLFSCredentials and PullLFSObjects
+// directly when the LFS endpoint needs separate wiring.
+func CloneRepository(ctx context.Context, url, branch, dir string, opts CloneOptions) error {
+	if err := ValidateCloneOptions(opts); err != nil {
+		return err
+	}
+	cmd := exec.CommandContext(ctx, "git", opts.CloneArgs(url, branch, dir)...)
+	if env := opts.CloneEnv(); len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to clone repo: %w (output: %s)", err, string(out))
+	}
+	if len(opts.SparsePaths) > 0 {
+		if err := applySparseCheckout(ctx, dir, opts.SparsePaths, opts.CloneEnv()); err != nil {
+			return err
+		}
+	}
+	if opts.LFS != nil {
+		return PullLFSObjects(ctx, dir, *opts.LFS)
+	}
+	return nil
+}
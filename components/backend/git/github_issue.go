@@ -0,0 +1,334 @@
+package git
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Limits on what FetchGitHubIssue reads, keeping a session's initial context readable
+const (
+	maxIssueComments     = 30
+	maxPullRequestDiff   = 64 << 10
+	maxFailingCheckLogs  = 3
+	maxFailingCheckLog   = 8 << 10
+	maxGitHubAPIResponse = 4 << 20
+)
+
+// Errors of FetchGitHubIssue
+var (
+	ErrGitHubNotFound     = errors.New("GitHub issue not found")
+	ErrGitHubUnauthorized = errors.New("GitHub rejected the token")
+)
+
+// GitHubIssueRef identifies a GitHub issue or pull request
+type GitHubIssueRef struct {
+	Owner  string
+	Repo   string
+	Number int
+}
+
+func (r GitHubIssueRef) String() string {
+	return fmt.Sprintf("%s/%s#%d", r.Owner, r.Repo, r.Number)
+}
+
+var (
+	githubIssueShorthand = regexp.MustCompile(`^([A-Za-z0-9][A-Za-z0-9-]*)/([A-Za-z0-9._-]+)#([1-9][0-9]*)$`)
+	githubIssueURL       = regexp.MustCompile(`^https://github\.com/([A-Za-z0-9][A-Za-z0-9-]*)/([A-Za-z0-9._-]+)/(?:issues|pull)/([1-9][0-9]*)(?:[/?#].*)?$`)
+)
+
+// ParseGitHubIssueRef parses "owner/repo#123" or an issue or pull request URL
+func ParseGitHubIssueRef(s string) (GitHubIssueRef, error) {
+	s = strings.TrimSpace(s)
+	m := githubIssueShorthand.FindStringSubmatch(s)
+	if m == nil {
+		m = githubIssueURL.FindStringSubmatch(s)
+	}
+	if m == nil {
+		return GitHubIssueRef{}, fmt.Errorf("expected owner/repo#number or a GitHub issue or pull request URL")
+	}
+	n, err := strconv.Atoi(m[3])
+	if err != nil {
+		return GitHubIssueRef{}, fmt.Errorf("invalid issue number %q", m[3])
+	}
+	return GitHubIssueRef{Owner: m[1], Repo: strings.TrimSuffix(m[2], ".git"), Number: n}, nil
+}
+
+// GitHubIssue is an issue or pull request as sessions see it
+type GitHubIssue struct {
+	Ref         GitHubIssueRef `json:"-"`
+	URL         string         `json:"url"`
+	Title       string         `json:"title"`
+	Body        string         `json:"body,omitempty"`
+	State       string         `json:"state"`
+	Author      string         `json:"author,omitempty"`
+	Labels      []string       `json:"labels,omitempty"`
+	Comments    []IssueComment `json:"comments,omitempty"`
+	PullRequest *PullRequest   `json:"pullRequest,omitempty"`
+}
+
+// IssueComment is a comment on an issue or pull request
+type IssueComment struct {
+	Author  string `json:"author"`
+	Created string `json:"created"`
+	Body    string `json:"body"`
+}
+
+// PullRequest holds what a session needs to continue a pull request
+type PullRequest struct {
+	// HeadRepoURL and HeadBranch are where the PR's commits live, possibly a fork
+	HeadRepoURL string `json:"headRepoUrl,omitempty"`
+	HeadBranch  string `json:"headBranch"`
+	HeadSHA     string `json:"headSha"`
+	BaseBranch  string `json:"baseBranch"`
+	// Diff is the PR's unified diff, truncated at maxPullRequestDiff
+	Diff          string         `json:"diff,omitempty"`
+	DiffTruncated bool           `json:"diffTruncated,omitempty"`
+	FailingChecks []FailingCheck `json:"failingChecks,omitempty"`
+}
+
+// FailingCheck is a check run of the PR's head commit that didn't pass
+type FailingCheck struct {
+	Name       string `json:"name"`
+	Conclusion string `json:"conclusion"`
+	URL        string `json:"url,omitempty"`
+	// LogTail is the end of the job's log, for GitHub Actions checks
+	LogTail string `json:"logTail,omitempty"`
+}
+
+// getGitHub GETs a GitHub API path, returning at most limit bytes of the body
+func getGitHub(ctx context.Context, token, path, accept string, limit int64) ([]byte, error) {
+	apiURL := githubAPIBaseURL + path
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Accept", accept)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request to %s failed: %w", sanitizeURLForError(apiURL), err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, limit))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %w", err)
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return body, nil
+	case http.StatusNotFound:
+		return nil, ErrGitHubNotFound
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return nil, ErrGitHubUnauthorized
+	}
+	return nil, fmt.Errorf("GitHub API returned %d for %s", resp.StatusCode, sanitizeURLForError(apiURL))
+}
+
+func getGitHubJSON(ctx context.Context, token, path string, out interface{}) error {
+	body, err := getGitHub(ctx, token, path, "application/vnd.github+json", maxGitHubAPIResponse)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(body, out)
+}
+
+// FetchGitHubIssue reads an issue or pull request with its comments. For pull
+// requests it also reads the diff, the branches and the failing checks of the head
+// commit with the end of their logs. Diffs and logs are best-effort.
+func FetchGitHubIssue(ctx context.Context, token string, ref GitHubIssueRef) (*GitHubIssue, error) {
+	if strings.TrimSpace(token) == "" {
+		return nil, fmt.Errorf("no GitHub credentials available")
+	}
+	repoPath := fmt.Sprintf("/repos/%s/%s", ref.Owner, ref.Repo)
+	var raw struct {
+		HTMLURL string `json:"html_url"`
+		Title   string `json:"title"`
+		Body    string `json:"body"`
+		State   string `json:"state"`
+		User    struct {
+			Login string `json:"login"`
+		} `json:"user"`
+		Labels []struct {
+			Name string `json:"name"`
+		} `json:"labels"`
+		PullRequest *struct{} `json:"pull_request"`
+	}
+	err := getGitHubJSON(ctx, token, fmt.Sprintf("%s/issues/%d", repoPath, ref.Number), &raw)
+	if err != nil {
+		return nil, err
+	}
+	issue := &GitHubIssue{Ref: ref, URL: raw.HTMLURL, Title: raw.Title, Body: raw.Body, State: raw.State, Author: raw.User.Login}
+	for _, l := range raw.Labels {
+		issue.Labels = append(issue.Labels, l.Name)
+	}
+
+	var comments []struct {
+		Body      string `json:"body"`
+		CreatedAt string `json:"created_at"`
+		User      struct {
+			Login string `json:"login"`
+		} `json:"user"`
+	}
+	if err := getGitHubJSON(ctx, token, fmt.Sprintf("%s/issues/%d/comments?per_page=100", repoPath, ref.Number), &comments); err != nil {
+		return nil, err
+	}
+	if len(comments) > maxIssueComments {
+		comments = comments[len(comments)-maxIssueComments:]
+	}
+	for _, cm := range comments {
+		issue.Comments = append(issue.Comments, IssueComment{Author: cm.User.Login, Created: cm.CreatedAt, Body: cm.Body})
+	}
+
+	if raw.PullRequest == nil {
+		return issue, nil
+	}
+	if issue.PullRequest, err = FetchGitHubPullRequestBranches(ctx, token, ref); err != nil {
+		return nil, err
+	}
+	pullPath := fmt.Sprintf("%s/pulls/%d", repoPath, ref.Number)
+	if diff, err := getGitHub(ctx, token, pullPath, "application/vnd.github.diff", maxPullRequestDiff+1); err == nil {
+		if len(diff) > maxPullRequestDiff {
+			diff = diff[:maxPullRequestDiff]
+			issue.PullRequest.DiffTruncated = true
+		}
+		issue.PullRequest.Diff = string(diff)
+	}
+	issue.PullRequest.FailingChecks = failingChecks(ctx, token, repoPath, issue.PullRequest.HeadSHA)
+	return issue, nil
+}
+
+// FetchGitHubPullRequestBranches reads where a pull request's commits live and the
+// branch it targets, without its diff or checks
+func FetchGitHubPullRequestBranches(ctx context.Context, token string, ref GitHubIssueRef) (*PullRequest, error) {
+	if strings.TrimSpace(token) == "" {
+		return nil, fmt.Errorf("no GitHub credentials available")
+	}
+	var pr struct {
+		Head struct {
+			Ref  string `json:"ref"`
+			SHA  string `json:"sha"`
+			Repo *struct {
+				CloneURL string `json:"clone_url"`
+			} `json:"repo"`
+		} `json:"head"`
+		Base struct {
+			Ref string `json:"ref"`
+		} `json:"base"`
+	}
+	if err := getGitHubJSON(ctx, token, fmt.Sprintf("/repos/%s/%s/pulls/%d", ref.Owner, ref.Repo, ref.Number), &pr); err != nil {
+		return nil, err
+	}
+	out := &PullRequest{HeadBranch: pr.Head.Ref, HeadSHA: pr.Head.SHA, BaseBranch: pr.Base.Ref}
+	if pr.Head.Repo != nil {
+		// A deleted fork leaves no head repository
+		out.HeadRepoURL = pr.Head.Repo.CloneURL
+	}
+	return out, nil
+}
+
+// failingChecks lists the head commit's failed check runs, with the tail of the logs
+// of the first few GitHub Actions jobs
+func failingChecks(ctx context.Context, token, repoPath, sha string) []FailingCheck {
+	var runs struct {
+		CheckRuns []struct {
+			ID         int64  `json:"id"`
+			Name       string `json:"name"`
+			Conclusion string `json:"conclusion"`
+			HTMLURL    string `json:"html_url"`
+			App        struct {
+				Slug string `json:"slug"`
+			} `json:"app"`
+		} `json:"check_runs"`
+	}
+	if sha == "" || getGitHubJSON(ctx, token, fmt.Sprintf("%s/commits/%s/check-runs?per_page=100", repoPath, sha), &runs) != nil {
+		return nil
+	}
+	var failing []FailingCheck
+	for _, run := range runs.CheckRuns {
+		switch run.Conclusion {
+		case "failure", "timed_out", "cancelled", "action_required":
+		default:
+			continue
+		}
+		check := FailingCheck{Name: run.Name, Conclusion: run.Conclusion, URL: run.HTMLURL}
+		if run.App.Slug == "github-actions" && len(failing) < maxFailingCheckLogs {
+			// Job logs redirect to a pre-signed download; the failure is at the end
+			if log, err := getGitHub(ctx, token, fmt.Sprintf("%s/actions/jobs/%d/logs", repoPath, run.ID), "application/vnd.github+json", maxGitHubAPIResponse); err == nil {
+				if len(log) > maxFailingCheckLog {
+					log = log[len(log)-maxFailingCheckLog:]
+				}
+				check.LogTail = string(log)
+			}
+		}
+		failing = append(failing, check)
+	}
+	return failing
+}
+
+// SessionRepo is the repository a session working on the issue clones: a pull
+// request's head branch (in its fork, if any), otherwise the issue's repository
+func (i *GitHubIssue) SessionRepo() (url, branch string) {
+	if pr := i.PullRequest; pr != nil && pr.HeadRepoURL != "" {
+		return pr.HeadRepoURL, pr.HeadBranch
+	}
+	return fmt.Sprintf("https://github.com/%s/%s.git", i.Ref.Owner, i.Ref.Repo), ""
+}
+
+// Context formats the issue as a session's initial context: a <github-issue> block
+// (<github-pull-request> for pull requests) with its description, comments, failing
+// checks and diff
+func (i *GitHubIssue) Context() string {
+	tag := "github-issue"
+	if i.PullRequest != nil {
+		tag = "github-pull-request"
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "<%s ref=%q url=%q>\n", tag, i.Ref.String(), i.URL)
+	fmt.Fprintf(&b, "# %s: %s\n\n", i.Ref.String(), i.Title)
+	fmt.Fprintf(&b, "- State: %s\n", i.State)
+	if i.Author != "" {
+		fmt.Fprintf(&b, "- Author: %s\n", i.Author)
+	}
+	if len(i.Labels) > 0 {
+		fmt.Fprintf(&b, "- Labels: %s\n", strings.Join(i.Labels, ", "))
+	}
+	if pr := i.PullRequest; pr != nil {
+		fmt.Fprintf(&b, "- Branch: %s into %s\n", pr.HeadBranch, pr.BaseBranch)
+	}
+	if body := strings.TrimSpace(i.Body); body != "" {
+		fmt.Fprintf(&b, "\n## Description\n\n%s\n", body)
+	}
+	if len(i.Comments) > 0 {
+		b.WriteString("\n## Comments\n")
+		for _, cm := range i.Comments {
+			fmt.Fprintf(&b, "\n**%s** (%s):\n%s\n", cm.Author, cm.Created, strings.TrimSpace(cm.Body))
+		}
+	}
+	if pr := i.PullRequest; pr != nil {
+		if len(pr.FailingChecks) > 0 {
+			b.WriteString("\n## Failing checks\n")
+			for _, check := range pr.FailingChecks {
+				fmt.Fprintf(&b, "\n### %s (%s)\n\n%s\n", check.Name, check.Conclusion, check.URL)
+				if check.LogTail != "" {
+					fmt.Fprintf(&b, "\n```\n%s\n```\n", strings.TrimSpace(check.LogTail))
+				}
+			}
+		}
+		if pr.Diff != "" {
+			b.WriteString("\n## Diff\n")
+			if pr.DiffTruncated {
+				fmt.Fprintf(&b, "\n(truncated at %d KiB)\n", maxPullRequestDiff>>10)
+			}
+			fmt.Fprintf(&b, "\n```diff\n%s\n```\n", strings.TrimRight(pr.Diff, "\n"))
+		}
+	}
+	fmt.Fprintf(&b, "</%s>", tag)
+	return b.String()
+}
@@ -0,0 +1,91 @@
+package git
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestParseGitHubIssueRef(t *testing.T) {
+	for in, want := range map[string]string{
+		"acme/web#12": "acme/web#12",
+		"https://github.com/acme/web/pull/12/files": "acme/web#12",
+		"https://github.com/acme/web.js/issues/7":   "acme/web.js#7",
+	} {
+		ref, err := ParseGitHubIssueRef(in)
+		if err != nil || ref.String() != want {
+			t.Errorf("ParseGitHubIssueRef(%q) = %v, %v; want %s", in, ref, err, want)
+		}
+	}
+	for _, in := range []string{"acme/web", "acme/web#0", "https://gitlab.com/acme/web/issues/1", "../x#1"} {
+		if _, err := ParseGitHubIssueRef(in); err == nil {
+			t.Errorf("ParseGitHubIssueRef(%q) should fail", in)
+		}
+	}
+}
+
+func TestFetchGitHubPullRequest(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer good" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/repos/acme/web/issues/12":
+			fmt.Fprint(w, `{"html_url":"https://github.com/acme/web/pull/12","title":"Fix login","body":"Closes #3","state":"open",
+				"user":{"login":"dana"},"labels":[{"name":"bug"}],"pull_request":{}}`)
+		case "/repos/acme/web/issues/12/comments":
+			fmt.Fprint(w, `[{"body":"Tests fail on Safari","created_at":"2026-01-02T10:00:00Z","user":{"login":"sam"}}]`)
+		case "/repos/acme/web/pulls/12":
+			if r.Header.Get("Accept") == "application/vnd.github.diff" {
+				fmt.Fprint(w, "diff --git a/login.go b/login.go\n+fixed\n")
+				return
+			}
+			fmt.Fprint(w, `{"head":{"ref":"fix-login","sha":"abc123","repo":{"clone_url":"https://github.com/dana/web.git"}},"base":{"ref":"main"}}`)
+		case "/repos/acme/web/commits/abc123/check-runs":
+			fmt.Fprint(w, `{"check_runs":[
+				{"id":1,"name":"unit","conclusion":"failure","html_url":"https://github.com/acme/web/runs/1","app":{"slug":"github-actions"}},
+				{"id":2,"name":"lint","conclusion":"success","app":{"slug":"github-actions"}}]}`)
+		case "/repos/acme/web/actions/jobs/1/logs":
+			fmt.Fprint(w, "setup\n--- FAIL: TestLogin\n")
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+	original := githubAPIBaseURL
+	githubAPIBaseURL = srv.URL
+	defer func() { githubAPIBaseURL = original }()
+
+	ref := GitHubIssueRef{Owner: "acme", Repo: "web", Number: 12}
+	issue, err := FetchGitHubIssue(context.Background(), "good", ref)
+	if err != nil {
+		t.Fatalf("FetchGitHubIssue: %v", err)
+	}
+	pr := issue.PullRequest
+	if pr == nil || pr.HeadBranch != "fix-login" || pr.BaseBranch != "main" || !strings.HasPrefix(pr.Diff, "diff --git") {
+		t.Fatalf("pull request = %+v", pr)
+	}
+	if len(pr.FailingChecks) != 1 || pr.FailingChecks[0].Name != "unit" || !strings.Contains(pr.FailingChecks[0].LogTail, "FAIL: TestLogin") {
+		t.Errorf("failing checks = %+v", pr.FailingChecks)
+	}
+	if url, branch := issue.SessionRepo(); url != "https://github.com/dana/web.git" || branch != "fix-login" {
+		t.Errorf("session repo = %s@%s", url, branch)
+	}
+	sessionContext := issue.Context()
+	for _, part := range []string{`<github-pull-request ref="acme/web#12"`, "- Branch: fix-login into main", "**sam**", "### unit (failure)", "```diff\ndiff --git"} {
+		if !strings.Contains(sessionContext, part) {
+			t.Errorf("context is missing %q:\n%s", part, sessionContext)
+		}
+	}
+
+	if _, err := FetchGitHubIssue(context.Background(), "good", GitHubIssueRef{Owner: "acme", Repo: "web", Number: 99}); err != ErrGitHubNotFound {
+		t.Errorf("missing issue: err = %v", err)
+	}
+	if _, err := FetchGitHubIssue(context.Background(), "bad", ref); err != ErrGitHubUnauthorized {
+		t.Errorf("bad token: err = %v", err)
+	}
+}
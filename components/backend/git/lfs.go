@@ -0,0 +1,135 @@
+package git
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os/exec"
+	"strings"
+)
+
+// LFSOptions controls how Git LFS objects are materialized in a clone
+type LFSOptions struct {
+	// SkipSmudge leaves LFS pointer files in the working tree instead of downloading objects
+	SkipSmudge bool
+	// MaxObjectSizeBytes skips downloading LFS objects larger than this (0 = no limit)
+	MaxObjectSizeBytes int64
+}
+
+// lfsDeferred reports whether object downloads must be skipped during clone, either
+// entirely or so that a size-filtered pull can run afterwards.
+func (o *LFSOptions) lfsDeferred() bool {
+	return o != nil && (o.SkipSmudge || o.MaxObjectSizeBytes > 0)
+}
+
+// CloneEnv returns extra environment variables for `git clone` under these options
+func (o CloneOptions) CloneEnv() []string {
+	if o.LFS.lfsDeferred() {
+		return []string{"GIT_LFS_SKIP_SMUDGE=1"}
+	}
+	return nil
+}
+
+// ConfigureLFSCredentials points the repo's LFS endpoint at an authenticated URL so object
+// downloads use the same token as the clone. A custom endpoint from .lfsconfig only gets
+// the token when it is on the same host as the repository.
+func ConfigureLFSCredentials(ctx context.Context, repoDir, repoURL, token string) error {
+	if strings.TrimSpace(token) == "" {
+		return nil
+	}
+	endpoint := strings.TrimSuffix(strings.TrimSuffix(repoURL, "/"), ".git") + ".git/info/lfs"
+	if out, err := exec.CommandContext(ctx, "git", "-C", repoDir, "config", "-f", ".lfsconfig", "--get", "lfs.url").Output(); err == nil {
+		custom := strings.TrimSpace(string(out))
+		if custom != "" {
+			if !sameHost(custom, repoURL) {
+				log.Printf("LFS endpoint %s is not on the repository host; leaving credentials unset", custom)
+				return nil
+			}
+			endpoint = custom
+		}
+	}
+	authURL, err := InjectGitToken(endpoint, token)
+	if err != nil {
+		return fmt.Errorf("failed to prepare LFS endpoint: %w", err)
+	}
+	if out, err := exec.CommandContext(ctx, "git", "-C", repoDir, "config", "lfs.url", authURL).CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to set LFS endpoint: %w (output: %s)", err, string(out))
+	}
+	return nil
+}
+
+// PullLFSObjects installs LFS hooks in repoDir and downloads objects per opts.
+// With SkipSmudge nothing is downloaded; with MaxObjectSizeBytes only smaller objects are.
+func PullLFSObjects(ctx context.Context, repoDir string, opts LFSOptions) error {
+	if out, err := exec.CommandContext(ctx, "git", "-C", repoDir, "lfs", "install", "--local").CombinedOutput(); err != nil {
+		return fmt.Errorf("git lfs is not available: %w (output: %s)", err, string(out))
+	}
+	if opts.SkipSmudge {
+		return nil
+	}
+
+	args := []string{"-C", repoDir, "lfs", "pull"}
+	if opts.MaxObjectSizeBytes > 0 {
+		include, excluded, err := lfsFilesUnderSize(ctx, repoDir, opts.MaxObjectSizeBytes)
+		if err != nil {
+			return err
+		}
+		if excluded > 0 {
+			log.Printf("Skipping %d LFS object(s) over %d bytes in %s", excluded, opts.MaxObjectSizeBytes, repoDir)
+		}
+		if len(include) == 0 {
+			return nil
+		}
+		args = append(args, "--include", strings.Join(include, ","))
+	}
+	if out, err := exec.CommandContext(ctx, "git", args...).CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to pull LFS objects: %w (output: %s)", err, string(out))
+	}
+	return nil
+}
+
+// lfsFilesUnderSize lists LFS-tracked paths no larger than maxBytes and counts the rest
+func lfsFilesUnderSize(ctx context.Context, repoDir string, maxBytes int64) ([]string, int, error) {
+	out, err := exec.CommandContext(ctx, "git", "-C", repoDir, "lfs", "ls-files", "--json").Output()
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to list LFS files: %w", err)
+	}
+	return filterLFSFilesBySize(out, maxBytes)
+}
+
+func filterLFSFilesBySize(lsFilesJSON []byte, maxBytes int64) ([]string, int, error) {
+	var listing struct {
+		Files []struct {
+			Name string `json:"name"`
+			Size int64  `json:"size"`
+		} `json:"files"`
+	}
+	if err := json.Unmarshal(lsFilesJSON, &listing); err != nil {
+		return nil, 0, fmt.Errorf("failed to parse LFS file list: %w", err)
+	}
+	include := []string{}
+	excluded := 0
+	for _, f := range listing.Files {
+		if f.Size > maxBytes {
+			excluded++
+			continue
+		}
+		include = append(include, f.Name)
+	}
+	return include, excluded, nil
+}
+
+func sameHost(a, b string) bool {
+	hostOf := func(u string) string {
+		u = strings.TrimPrefix(strings.TrimPrefix(u, "https://"), "http://")
+		if i := strings.Index(u, "@"); i >= 0 && i < strings.Index(u+"/", "/") {
+			u = u[i+1:]
+		}
+		if i := strings.Index(u, "/"); i >= 0 {
+			u = u[:i]
+		}
+		return strings.ToLower(u)
+	}
+	return hostOf(a) == hostOf(b)
+}
@@ -0,0 +1,131 @@
+package git
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// MirrorRoot is the local directory holding bare mirrors, when the mirror volume is mounted
+	MirrorRoot string
+	// MirrorServiceURL is the base URL of the repo-mirror service, when deployed
+	MirrorServiceURL string
+)
+
+// mirrorLocks serializes syncs of the same mirror within this process
+var mirrorLocks sync.Map
+
+// MirrorKey returns the cache key for a repository URL. Keep the normalization in sync
+// with mirror_key() in the state-sync hydrate.sh.
+func MirrorKey(repoURL string) string {
+	u := strings.TrimSuffix(strings.TrimSuffix(strings.TrimSpace(repoURL), "/"), ".git")
+	sum := sha256.Sum256([]byte(strings.ToLower(u)))
+	return hex.EncodeToString(sum[:])
+}
+
+// MirrorPath returns the bare mirror directory for repoURL under root
+func MirrorPath(root, repoURL string) string {
+	return filepath.Join(root, MirrorKey(repoURL)+".git")
+}
+
+// MirrorReferenceDir returns the cached mirror for repoURL, or "" if it has not been populated
+func MirrorReferenceDir(root, repoURL string) string {
+	if root == "" {
+		return ""
+	}
+	dir := MirrorPath(root, repoURL)
+	if _, err := os.Stat(filepath.Join(dir, "HEAD")); err != nil {
+		return ""
+	}
+	return dir
+}
+
+// SyncMirror creates or refreshes the bare mirror for repoURL under root and returns its path.
+// Mirrors are fetched without credentials so the cache only ever holds publicly readable
+// repositories; private repositories fail here and are cloned directly instead.
+func SyncMirror(ctx context.Context, root, repoURL string) (string, error) {
+	if err := validateMirrorURL(repoURL); err != nil {
+		return "", err
+	}
+	dir := MirrorPath(root, repoURL)
+
+	lock, _ := mirrorLocks.LoadOrStore(dir, &sync.Mutex{})
+	lock.(*sync.Mutex).Lock()
+	defer lock.(*sync.Mutex).Unlock()
+
+	// Never prompt for credentials; a private repo should fail fast
+	env := append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+
+	if MirrorReferenceDir(root, repoURL) != "" {
+		cmd := exec.CommandContext(ctx, "git", "-C", dir, "fetch", "--prune", "origin")
+		cmd.Env = env
+		if out, err := cmd.CombinedOutput(); err != nil {
+			return "", fmt.Errorf("failed to refresh mirror: %w (output: %s)", err, string(out))
+		}
+		return dir, nil
+	}
+
+	if err := os.MkdirAll(root, 0755); err != nil {
+		return "", fmt.Errorf("failed to create mirror root: %w", err)
+	}
+	// Clone into a temp dir and rename so readers never see a partial mirror
+	tmp, err := os.MkdirTemp(root, ".mirror-*")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temp dir: %w", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	cmd := exec.CommandContext(ctx, "git", "clone", "--mirror", repoURL, tmp)
+	cmd.Env = env
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return "", fmt.Errorf("failed to mirror repo: %w (output: %s)", err, string(out))
+	}
+	// Objects may be borrowed by clones via alternates, so gc must never drop them;
+	// partial clones (sparse checkout) served over HTTP need filter support.
+	for _, kv := range [][2]string{{"gc.auto", "0"}, {"uploadpack.allowFilter", "true"}} {
+		if out, err := exec.CommandContext(ctx, "git", "-C", tmp, "config", kv[0], kv[1]).CombinedOutput(); err != nil {
+			log.Printf("Warning: failed to set %s on mirror %s: %v (output: %s)", kv[0], repoURL, err, string(out))
+		}
+	}
+	if err := os.Rename(tmp, dir); err != nil {
+		return "", fmt.Errorf("failed to publish mirror: %w", err)
+	}
+	return dir, nil
+}
+
+// RequestMirrorSync asks the repo-mirror service to populate or refresh the mirror for
+// repoURL so later clones can use it. Best effort; no-op when the service is not configured.
+func RequestMirrorSync(repoURL string) {
+	if MirrorServiceURL == "" || validateMirrorURL(repoURL) != nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	body, _ := json.Marshal(map[string]string{"url": repoURL})
+	endpoint := strings.TrimSuffix(MirrorServiceURL, "/") + "/mirror/sync"
+	if _, status, err := doGitAPIRequest(ctx, http.MethodPost, endpoint, "", body); err != nil || status >= 300 {
+		log.Printf("Mirror sync request for %s failed: status=%d err=%v", repoURL, status, err)
+	}
+}
+
+func validateMirrorURL(repoURL string) error {
+	u := strings.TrimSpace(repoURL)
+	if !strings.HasPrefix(u, "https://") {
+		return fmt.Errorf("only https repository URLs can be mirrored")
+	}
+	if strings.Contains(strings.SplitN(strings.TrimPrefix(u, "https://"), "/", 2)[0], "@") {
+		return fmt.Errorf("repository URL must not contain credentials")
+	}
+	return nil
+}
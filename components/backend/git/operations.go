@@ -1579,8 +1579,11 @@ func createBranchInRepo(ctx context.Context, repo GitRepo, branchName, token str
 	}
 
 	log.Printf("Cloning supporting repo: %s (branch: %s)", repoURL, baseBranch)
-	cloneArgs := []string{"clone", "--depth", "1", "--branch", baseBranch, authenticatedURL, repoDir}
-	cmd := exec.CommandContext(ctx, "git", cloneArgs...)
+	cloneOpts := CloneOptions{Depth: 1, SingleBranch: true, ReferenceDir: MirrorReferenceDir(MirrorRoot, repoURL)}
+	if cloneOpts.ReferenceDir == "" {
+		go RequestMirrorSync(repoURL)
+	}
+	cmd := exec.CommandContext(ctx, "git", cloneOpts.CloneArgs(authenticatedURL, baseBranch, repoDir)...)
 	if out, err := cmd.CombinedOutput(); err != nil {
 		return fmt.Errorf("failed to clone repo: %w (output: %s)", err, string(out))
 	}
@@ -0,0 +1,247 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"ambient-code-backend/gitlab"
+	"ambient-code-backend/types"
+)
+
+// githubAPIBaseURL is overridable in tests.
+var githubAPIBaseURL = "https://api.github.com"
+
+// PullRequestOptions describes a pull/merge request to open from a pushed branch
+type PullRequestOptions struct {
+	RepoURL string
+	Head    string
+	Base    string
+	Title   string
+	Body    string
+	Draft   bool
+}
+
+// PullRequestResult identifies the created (or already open) pull/merge request
+type PullRequestResult struct {
+	URL      string
+	Number   int
+	Provider types.ProviderType
+	// Existing is true when an open PR for the same head/base was returned instead of a new one
+	Existing bool
+}
+
+// CreatePullRequest opens a GitHub pull request or GitLab merge request from opts.Head
+// into opts.Base. If one is already open for the branch pair it is returned instead.
+func CreatePullRequest(ctx context.Context, opts PullRequestOptions, token string) (*PullRequestResult, error) {
+	if strings.TrimSpace(token) == "" {
+		return nil, fmt.Errorf("no git credentials available for %s", opts.RepoURL)
+	}
+	if opts.Head == "" || opts.Base == "" {
+		return nil, fmt.Errorf("head and base branches are required")
+	}
+	if opts.Head == opts.Base {
+		return nil, fmt.Errorf("head and base branches must differ")
+	}
+
+	switch types.DetectProvider(opts.RepoURL) {
+	case types.ProviderGitHub:
+		return createGitHubPullRequest(ctx, opts, token)
+	case types.ProviderGitLab:
+		return createGitLabMergeRequest(ctx, opts, token)
+	default:
+		return nil, fmt.Errorf("unsupported repository provider for URL: %s", opts.RepoURL)
+	}
+}
+
+func createGitHubPullRequest(ctx context.Context, opts PullRequestOptions, token string) (*PullRequestResult, error) {
+	owner, repo, err := ParseGitHubURL(opts.RepoURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid GitHub repository URL: %w", err)
+	}
+
+	payload, err := json.Marshal(map[string]interface{}{
+		"title": opts.Title,
+		"body":  opts.Body,
+		"head":  opts.Head,
+		"base":  opts.Base,
+		"draft": opts.Draft,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode pull request: %w", err)
+	}
+
+	apiURL := fmt.Sprintf("%s/repos/%s/%s/pulls", githubAPIBaseURL, owner, repo)
+	body, status, err := doGitAPIRequest(ctx, http.MethodPost, apiURL, "Bearer "+token, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	var pr struct {
+		HTMLURL string `json:"html_url"`
+		Number  int    `json:"number"`
+	}
+	switch status {
+	case http.StatusCreated:
+		if err := json.Unmarshal(body, &pr); err != nil {
+			return nil, fmt.Errorf("failed to parse pull request response: %w", err)
+		}
+		log.Printf("Created GitHub pull request %s (%s -> %s)", pr.HTMLURL, opts.Head, opts.Base)
+		return &PullRequestResult{URL: pr.HTMLURL, Number: pr.Number, Provider: types.ProviderGitHub}, nil
+	case http.StatusUnprocessableEntity:
+		// GitHub returns 422 both for validation errors and for "A pull request already exists"
+		if strings.Contains(string(body), "already exists") {
+			return findGitHubPullRequest(ctx, owner, repo, opts, token)
+		}
+		return nil, fmt.Errorf("GitHub rejected the pull request: %s", string(body))
+	case http.StatusNotFound:
+		return nil, fmt.Errorf("repository %s/%s not found or you don't have access to it", owner, repo)
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return nil, fmt.Errorf("GitHub credentials are not allowed to open pull requests on %s/%s", owner, repo)
+	default:
+		return nil, fmt.Errorf("GitHub API error: %d (body: %s)", status, string(body))
+	}
+}
+
+func findGitHubPullRequest(ctx context.Context, owner, repo string, opts PullRequestOptions, token string) (*PullRequestResult, error) {
+	q := url.Values{}
+	q.Set("state", "open")
+	q.Set("head", fmt.Sprintf("%s:%s", owner, opts.Head))
+	q.Set("base", opts.Base)
+	apiURL := fmt.Sprintf("%s/repos/%s/%s/pulls?%s", githubAPIBaseURL, owner, repo, q.Encode())
+
+	body, status, err := doGitAPIRequest(ctx, http.MethodGet, apiURL, "Bearer "+token, nil)
+	if err != nil {
+		return nil, err
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("GitHub API error: %d (body: %s)", status, string(body))
+	}
+	var prs []struct {
+		HTMLURL string `json:"html_url"`
+		Number  int    `json:"number"`
+	}
+	if err := json.Unmarshal(body, &prs); err != nil {
+		return nil, fmt.Errorf("failed to parse pull request list: %w", err)
+	}
+	if len(prs) == 0 {
+		return nil, fmt.Errorf("GitHub reported an existing pull request for %s but none was found", opts.Head)
+	}
+	return &PullRequestResult{URL: prs[0].HTMLURL, Number: prs[0].Number, Provider: types.ProviderGitHub, Existing: true}, nil
+}
+
+func createGitLabMergeRequest(ctx context.Context, opts PullRequestOptions, token string) (*PullRequestResult, error) {
+	parsed, err := gitlab.ParseGitLabURL(opts.RepoURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid GitLab repository URL: %w", err)
+	}
+
+	title := opts.Title
+	if opts.Draft && !strings.HasPrefix(strings.ToLower(title), "draft:") {
+		title = "Draft: " + title
+	}
+	payload, err := json.Marshal(map[string]interface{}{
+		"title":         title,
+		"description":   opts.Body,
+		"source_branch": opts.Head,
+		"target_branch": opts.Base,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode merge request: %w", err)
+	}
+
+	// Note: parsed.ProjectID is already URL-encoded
+	apiURL := fmt.Sprintf("%s/projects/%s/merge_requests", parsed.APIURL, parsed.ProjectID)
+	body, status, err := doGitAPIRequest(ctx, http.MethodPost, apiURL, "Bearer "+token, payload)
+	if err != nil {
+		return nil, err
+	}
+
+	var mr struct {
+		WebURL string `json:"web_url"`
+		IID    int    `json:"iid"`
+	}
+	switch status {
+	case http.StatusCreated:
+		if err := json.Unmarshal(body, &mr); err != nil {
+			return nil, fmt.Errorf("failed to parse merge request response: %w", err)
+		}
+		log.Printf("Created GitLab merge request %s (%s -> %s)", mr.WebURL, opts.Head, opts.Base)
+		return &PullRequestResult{URL: mr.WebURL, Number: mr.IID, Provider: types.ProviderGitLab}, nil
+	case http.StatusConflict:
+		return findGitLabMergeRequest(ctx, parsed, opts, token)
+	case http.StatusNotFound:
+		return nil, fmt.Errorf("repository %s/%s not found or you don't have access to it", parsed.Owner, parsed.Repo)
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return nil, fmt.Errorf("GitLab credentials are not allowed to open merge requests on %s/%s. Ensure the token has 'api' scope", parsed.Owner, parsed.Repo)
+	default:
+		return nil, fmt.Errorf("GitLab API error: %d (body: %s)", status, string(body))
+	}
+}
+
+func findGitLabMergeRequest(ctx context.Context, parsed *types.ParsedGitLabRepo, opts PullRequestOptions, token string) (*PullRequestResult, error) {
+	q := url.Values{}
+	q.Set("state", "opened")
+	q.Set("source_branch", opts.Head)
+	q.Set("target_branch", opts.Base)
+	apiURL := fmt.Sprintf("%s/projects/%s/merge_requests?%s", parsed.APIURL, parsed.ProjectID, q.Encode())
+
+	body, status, err := doGitAPIRequest(ctx, http.MethodGet, apiURL, "Bearer "+token, nil)
+	if err != nil {
+		return nil, err
+	}
+	if status != http.StatusOK {
+		return nil, fmt.Errorf("GitLab API error: %d (body: %s)", status, string(body))
+	}
+	var mrs []struct {
+		WebURL string `json:"web_url"`
+		IID    int    `json:"iid"`
+	}
+	if err := json.Unmarshal(body, &mrs); err != nil {
+		return nil, fmt.Errorf("failed to parse merge request list: %w", err)
+	}
+	if len(mrs) == 0 {
+		return nil, fmt.Errorf("GitLab reported an existing merge request for %s but none was found", opts.Head)
+	}
+	return &PullRequestResult{URL: mrs[0].WebURL, Number: mrs[0].IID, Provider: types.ProviderGitLab, Existing: true}, nil
+}
+
+// gitAPIClient bounds requests to git provider APIs so a slow provider can't hold a
+// request handler open
+var gitAPIClient = &http.Client{Timeout: 30 * time.Second}
+
+// doGitAPIRequest performs a JSON request against a git provider API and returns the body and status
+func doGitAPIRequest(ctx context.Context, method, apiURL, authorization string, payload []byte) ([]byte, int, error) {
+	var reader io.Reader
+	if payload != nil {
+		reader = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, apiURL, reader)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", authorization)
+	req.Header.Set("Accept", "application/json")
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := gitAPIClient.Do(req)
+	if err != nil {
+		return nil, 0, fmt.Errorf("request to %s failed: %w", sanitizeURLForError(apiURL), err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, resp.StatusCode, fmt.Errorf("failed to read response body: %w", err)
+	}
+	return body, resp.StatusCode, nil
+}
@@ -0,0 +1,160 @@
+package git
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// maxFileDiffBytes caps the unified diff returned for a single request
+const maxFileDiffBytes = 1 << 20
+
+// FileStatus is one entry of `git status` for a workspace repository
+type FileStatus struct {
+	Path string `json:"path"`
+	// Status is one of: modified, added, deleted, renamed, untracked, conflicted
+	Status  string `json:"status"`
+	Staged  bool   `json:"staged"`
+	OldPath string `json:"oldPath,omitempty"`
+}
+
+// FileDiff is a unified diff for a workspace repository, optionally limited to one file
+type FileDiff struct {
+	Path      string `json:"path,omitempty"`
+	Diff      string `json:"diff"`
+	Truncated bool   `json:"truncated"`
+}
+
+// StatusFiles lists changed and untracked files in repoDir relative to HEAD
+func StatusFiles(ctx context.Context, repoDir string) ([]FileStatus, error) {
+	cmd := exec.CommandContext(ctx, "git", "status", "--porcelain=v1", "-z", "--untracked-files=all")
+	cmd.Dir = repoDir
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git status failed: %w", err)
+	}
+	return parsePorcelainStatus(string(out)), nil
+}
+
+// parsePorcelainStatus parses NUL-separated `git status --porcelain=v1 -z` output
+func parsePorcelainStatus(out string) []FileStatus {
+	files := []FileStatus{}
+	entries := strings.Split(out, "\x00")
+	for i := 0; i < len(entries); i++ {
+		entry := entries[i]
+		if len(entry) < 4 {
+			continue
+		}
+		x, y, path := entry[0], entry[1], entry[3:]
+		fs := FileStatus{Path: path, Staged: x != ' ' && x != '?'}
+
+		switch {
+		case x == '?' && y == '?':
+			fs.Status = "untracked"
+		case x == 'U' || y == 'U' || (x == 'A' && y == 'A') || (x == 'D' && y == 'D'):
+			fs.Status = "conflicted"
+		case x == 'R' || y == 'R':
+			fs.Status = "renamed"
+			// With -z the original path follows as a separate entry
+			if i+1 < len(entries) {
+				fs.OldPath = entries[i+1]
+				i++
+			}
+		case x == 'A':
+			fs.Status = "added"
+		case x == 'D' || y == 'D':
+			fs.Status = "deleted"
+		default:
+			fs.Status = "modified"
+		}
+		files = append(files, fs)
+	}
+	return files
+}
+
+// WorkspaceDiff returns the unified diff of the working tree against HEAD. When file is
+// set, only that path is diffed; untracked files are shown as full additions.
+func WorkspaceDiff(ctx context.Context, repoDir, file string) (*FileDiff, error) {
+	result := &FileDiff{Path: file}
+
+	args := []string{"diff", "--no-color", "HEAD"}
+	if file != "" {
+		abs := filepath.Clean(filepath.Join(repoDir, file))
+		if !strings.HasPrefix(abs, filepath.Clean(repoDir)+string(filepath.Separator)) {
+			return nil, fmt.Errorf("invalid file path")
+		}
+		tracked := exec.CommandContext(ctx, "git", "ls-files", "--error-unmatch", "--", file)
+		tracked.Dir = repoDir
+		if err := tracked.Run(); err != nil {
+			info, statErr := untrackedFileInfo(repoDir, abs)
+			if statErr != nil {
+				return nil, fmt.Errorf("file not found: %s", file)
+			}
+			if info.Mode()&os.ModeSymlink != 0 {
+				// Show the link target as the file's content, as git does, rather than
+				// following the link out of the workspace
+				target, err := os.Readlink(abs)
+				if err != nil {
+					return nil, fmt.Errorf("file not found: %s", file)
+				}
+				result.Diff = symlinkAdditionDiff(file, target)
+				return result, nil
+			}
+			if !info.Mode().IsRegular() {
+				return nil, fmt.Errorf("not a regular file: %s", file)
+			}
+			// Untracked: diff against /dev/null. Exit status 1 means "differences found".
+			args = []string{"diff", "--no-color", "--no-index", "--", os.DevNull, file}
+		} else {
+			args = append(args, "--", file)
+		}
+	}
+
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = repoDir
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) || exitErr.ExitCode() != 1 {
+			return nil, fmt.Errorf("git diff failed: %w (stderr: %s)", err, stderr.String())
+		}
+	}
+
+	diff := stdout.Bytes()
+	if len(diff) > maxFileDiffBytes {
+		diff = diff[:maxFileDiffBytes]
+		result.Truncated = true
+	}
+	result.Diff = string(diff)
+	return result, nil
+}
+
+// untrackedFileInfo lstats an untracked file, refusing paths whose parent directories
+// resolve outside repoDir through a symlink
+func untrackedFileInfo(repoDir, abs string) (os.FileInfo, error) {
+	root, err := filepath.EvalSymlinks(repoDir)
+	if err != nil {
+		return nil, err
+	}
+	parent, err := filepath.EvalSymlinks(filepath.Dir(abs))
+	if err != nil {
+		return nil, err
+	}
+	if parent != root && !strings.HasPrefix(parent, root+string(filepath.Separator)) {
+		return nil, fmt.Errorf("path leaves the repository: %s", abs)
+	}
+	return os.Lstat(abs)
+}
+
+// symlinkAdditionDiff renders an untracked symlink the way git diffs a new link: a
+// one-line file holding the link target
+func symlinkAdditionDiff(file, target string) string {
+	return fmt.Sprintf("diff --git a/%[1]s b/%[1]s\nnew file mode 120000\n--- /dev/null\n+++ b/%[1]s\n@@ -0,0 +1 @@\n+%[2]s\n\\ No newline at end of file\n", file, target)
+}
@@ -0,0 +1,50 @@
+package git
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestWorkspaceDiffUntrackedSymlink(t *testing.T) {
+	repo := t.TempDir()
+	outside := t.TempDir()
+	secret := filepath.Join(outside, "secret.txt")
+	if err := os.WriteFile(secret, []byte("do-not-leak\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"-c", "user.name=t", "-c", "user.email=t@example.com", "commit", "-q", "--allow-empty", "-m", "init"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = repo
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v: %s", args, err, out)
+		}
+	}
+	if err := os.Symlink(secret, filepath.Join(repo, "link.txt")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(outside, filepath.Join(repo, "linkdir")); err != nil {
+		t.Fatal(err)
+	}
+
+	diff, err := WorkspaceDiff(context.Background(), repo, "link.txt")
+	if err != nil {
+		t.Fatalf("WorkspaceDiff(link.txt): %v", err)
+	}
+	if strings.Contains(diff.Diff, "do-not-leak") {
+		t.Fatalf("diff followed the symlink: %s", diff.Diff)
+	}
+	if !strings.Contains(diff.Diff, "new file mode 120000") || !strings.Contains(diff.Diff, "+"+secret) {
+		t.Errorf("diff does not show the link target: %s", diff.Diff)
+	}
+
+	if _, err := WorkspaceDiff(context.Background(), repo, "linkdir/secret.txt"); err == nil {
+		t.Error("WorkspaceDiff followed a symlinked directory out of the repository")
+	}
+}
@@ -16,6 +16,8 @@ import (
 	"sync"
 	"time"
 
+	"ambient-code-backend/metrics"
+
 	"github.com/golang-jwt/jwt/v5"
 )
 
@@ -129,6 +131,13 @@ func (m *TokenManager) MintInstallationTokenForHost(ctx context.Context, install
 	}
 	m.cacheMu.Unlock()
 
+	token, exp, err := m.mintInstallationToken(ctx, installationID, host)
+	metrics.ObserveRefresh("github-app", err)
+	return token, exp, err
+}
+
+// mintInstallationToken requests a new installation token from GitHub and caches it
+func (m *TokenManager) mintInstallationToken(ctx context.Context, installationID int64, host string) (string, time.Time, error) {
 	jwtToken, err := m.GenerateJWT()
 	if err != nil {
 		return "", time.Time{}, fmt.Errorf("failed to generate JWT: %w", err)
@@ -146,7 +155,7 @@ func (m *TokenManager) MintInstallationTokenForHost(ctx context.Context, install
 	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
 	req.Header.Set("User-Agent", "vTeam-Backend")
 
-	client := &http.Client{Timeout: 15 * time.Second}
+	client := &http.Client{Timeout: 15 * time.Second, Transport: metrics.Transport("github", "installation_token", nil)}
 	resp, err := client.Do(req)
 	if err != nil {
 		return "", time.Time{}, fmt.Errorf("failed to call GitHub: %w", err)
@@ -209,7 +218,7 @@ func (m *TokenManager) ValidateInstallationAccess(ctx context.Context, installat
 	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
 	req.Header.Set("User-Agent", "vTeam-Backend")
 
-	client := &http.Client{Timeout: 15 * time.Second}
+	client := &http.Client{Timeout: 15 * time.Second, Transport: metrics.Transport("github", "api", nil)}
 	resp, err := client.Do(req)
 	if err != nil {
 		return fmt.Errorf("GitHub request failed: %w", err)
@@ -12,6 +12,7 @@ import (
 	"strconv"
 	"time"
 
+	"ambient-code-backend/metrics"
 	"ambient-code-backend/types"
 	"github.com/google/uuid"
 )
@@ -32,7 +33,8 @@ type Client struct {
 func NewClient(baseURL, token string) *Client {
 	return &Client{
 		httpClient: &http.Client{
-			Timeout: 15 * time.Second,
+			Timeout:   15 * time.Second,
+			Transport: metrics.Transport("gitlab", "api", nil),
 		},
 		baseURL: baseURL,
 		token:   token,
@@ -432,3 +434,31 @@ func (c *Client) GetRawFileContents(ctx context.Context, projectID, filePath, re
 
 	return body, nil
 }
+
+// GetProjectMemberAccessLevel returns a user's access level in a project, including
+// access inherited from groups, or 0 when they aren't a member.
+// Levels: 10=Guest, 20=Reporter, 30=Developer, 40=Maintainer, 50=Owner
+func (c *Client) GetProjectMemberAccessLevel(ctx context.Context, projectID string, userID int) (int, error) {
+	path := fmt.Sprintf("/projects/%s/members/all/%d", projectID, userID)
+
+	resp, err := c.doRequest(ctx, "GET", path, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return 0, nil
+	}
+	if err := CheckResponse(resp); err != nil {
+		return 0, err
+	}
+
+	var member struct {
+		AccessLevel int `json:"access_level"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&member); err != nil {
+		return 0, fmt.Errorf("failed to parse member response: %w", err)
+	}
+	return member.AccessLevel, nil
+}
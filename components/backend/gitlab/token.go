@@ -8,6 +8,7 @@ import (
 	"net/url"
 	"time"
 
+	"ambient-code-backend/metrics"
 	"ambient-code-backend/types"
 )
 
@@ -29,10 +30,11 @@ type TokenValidationResult struct {
 }
 
 // ValidateGitLabToken validates a GitLab Personal Access Token
-func ValidateGitLabToken(ctx context.Context, token, instanceURL string) (*TokenValidationResult, error) {
+func ValidateGitLabToken(ctx context.Context, token, instanceURL string) (result *TokenValidationResult, err error) {
 	if token == "" {
 		return nil, fmt.Errorf("token cannot be empty")
 	}
+	defer func() { metrics.ObserveValidation("gitlab", result != nil && result.Valid, err) }()
 
 	if instanceURL == "" {
 		instanceURL = "https://gitlab.com"
@@ -0,0 +1,121 @@
+package grpcapi
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"ambient-code-backend/grpcapi/aguiv1"
+	"ambient-code-backend/types"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// payloadOptions ignores event fields the typed payloads don't carry
+var payloadOptions = protojson.UnmarshalOptions{DiscardUnknown: true}
+
+// ToProto converts an AG-UI event, as broadcast or persisted (a *types.Event, a map
+// or one of the types event structs), to its protobuf form. Event types without a
+// typed payload are passed through whole as Other.
+func ToProto(event interface{}) (*aguiv1.Event, error) {
+	var raw []byte
+	if e, ok := event.(*types.Event); ok {
+		raw = e.Raw
+	} else {
+		var err error
+		if raw, err = json.Marshal(event); err != nil {
+			return nil, fmt.Errorf("failed to encode event: %w", err)
+		}
+	}
+	var base types.BaseEvent
+	if err := json.Unmarshal(raw, &base); err != nil {
+		return nil, fmt.Errorf("invalid event: %w", err)
+	}
+	out := &aguiv1.Event{
+		Type:        base.Type,
+		ThreadId:    base.ThreadID,
+		RunId:       base.RunID,
+		Timestamp:   base.Timestamp,
+		MessageId:   base.MessageID,
+		ParentRunId: base.ParentRunID,
+	}
+
+	var msg proto.Message
+	switch base.Type {
+	case types.EventTypeRunStarted:
+		p := &aguiv1.RunStarted{}
+		msg, out.Payload = p, &aguiv1.Event_RunStarted{RunStarted: p}
+	case types.EventTypeRunFinished:
+		p := &aguiv1.RunFinished{}
+		msg, out.Payload = p, &aguiv1.Event_RunFinished{RunFinished: p}
+	case types.EventTypeRunError:
+		p := &aguiv1.RunError{}
+		msg, out.Payload = p, &aguiv1.Event_RunError{RunError: p}
+	case types.EventTypeStepStarted:
+		p := &aguiv1.StepStarted{}
+		msg, out.Payload = p, &aguiv1.Event_StepStarted{StepStarted: p}
+	case types.EventTypeStepFinished:
+		p := &aguiv1.StepFinished{}
+		msg, out.Payload = p, &aguiv1.Event_StepFinished{StepFinished: p}
+	case types.EventTypeTextMessageStart:
+		p := &aguiv1.TextMessageStart{}
+		msg, out.Payload = p, &aguiv1.Event_TextMessageStart{TextMessageStart: p}
+	case types.EventTypeTextMessageContent:
+		p := &aguiv1.TextMessageContent{}
+		msg, out.Payload = p, &aguiv1.Event_TextMessageContent{TextMessageContent: p}
+	case types.EventTypeTextMessageEnd:
+		// The base fields carry everything
+	case types.EventTypeToolCallStart:
+		p := &aguiv1.ToolCallStart{}
+		msg, out.Payload = p, &aguiv1.Event_ToolCallStart{ToolCallStart: p}
+	case types.EventTypeToolCallArgs:
+		p := &aguiv1.ToolCallArgs{}
+		msg, out.Payload = p, &aguiv1.Event_ToolCallArgs{ToolCallArgs: p}
+	case types.EventTypeToolCallEnd:
+		p := &aguiv1.ToolCallEnd{}
+		msg, out.Payload = p, &aguiv1.Event_ToolCallEnd{ToolCallEnd: p}
+	case types.EventTypeStateSnapshot:
+		p := &aguiv1.StateSnapshot{}
+		msg, out.Payload = p, &aguiv1.Event_StateSnapshot{StateSnapshot: p}
+	case types.EventTypStateDelta:
+		p := &aguiv1.StateDelta{}
+		msg, out.Payload = p, &aguiv1.Event_StateDelta{StateDelta: p}
+	case types.EventTypeMessagesSnapshot:
+		p := &aguiv1.MessagesSnapshot{}
+		msg, out.Payload = p, &aguiv1.Event_MessagesSnapshot{MessagesSnapshot: p}
+	case types.EventTypeActivitySnapshot:
+		p := &aguiv1.ActivitySnapshot{}
+		msg, out.Payload = p, &aguiv1.Event_ActivitySnapshot{ActivitySnapshot: p}
+	case types.EventTypeActivityDelta:
+		p := &aguiv1.ActivityDelta{}
+		msg, out.Payload = p, &aguiv1.Event_ActivityDelta{ActivityDelta: p}
+	case types.EventTypeRaw:
+		p := &aguiv1.Raw{}
+		msg, out.Payload = p, &aguiv1.Event_Raw{Raw: p}
+	case types.EventTypeMeta:
+		p := &aguiv1.Meta{}
+		msg, out.Payload = p, &aguiv1.Event_Meta{Meta: p}
+	default:
+		msg = other(out)
+	}
+	if msg == nil {
+		return out, nil
+	}
+
+	// The payload field names match the JSON ones. Pass through events whose fields
+	// don't fit the typed payload rather than dropping them.
+	if err := payloadOptions.Unmarshal(raw, msg); err != nil {
+		if err := payloadOptions.Unmarshal(raw, other(out)); err != nil {
+			return nil, fmt.Errorf("invalid %s event: %w", base.Type, err)
+		}
+	}
+	return out, nil
+}
+
+// other sets out's payload to an empty Other and returns it
+func other(out *aguiv1.Event) *structpb.Struct {
+	p := &structpb.Struct{}
+	out.Payload = &aguiv1.Event_Other{Other: p}
+	return p
+}
@@ -0,0 +1,79 @@
+package grpcapi
+
+import (
+	"testing"
+
+	"ambient-code-backend/grpcapi/aguiv1"
+	"ambient-code-backend/types"
+)
+
+func TestToProtoTypedPayloads(t *testing.T) {
+	ev, err := ToProto(map[string]interface{}{
+		"type": "TOOL_CALL_START", "threadId": "s1", "runId": "r1", "timestamp": "2026-01-01T00:00:00Z",
+		"toolCallId": "t1", "toolCallName": "Bash", "unknownField": true,
+	})
+	if err != nil {
+		t.Fatalf("ToProto: %v", err)
+	}
+	if ev.GetType() != types.EventTypeToolCallStart || ev.GetThreadId() != "s1" || ev.GetRunId() != "r1" {
+		t.Errorf("base fields = %v", ev)
+	}
+	if p := ev.GetToolCallStart(); p.GetToolCallId() != "t1" || p.GetToolCallName() != "Bash" {
+		t.Errorf("payload = %v", p)
+	}
+
+	snapshot := &types.MessagesSnapshotEvent{
+		BaseEvent: types.NewBaseEvent(types.EventTypeMessagesSnapshot, "s1", "r1"),
+		Messages:  []types.Message{{ID: "m1", Role: types.RoleAssistant, Content: "done", Metadata: map[string]interface{}{"model": "x"}}},
+	}
+	ev, err = ToProto(snapshot)
+	if err != nil {
+		t.Fatalf("ToProto: %v", err)
+	}
+	msgs := ev.GetMessagesSnapshot().GetMessages()
+	if len(msgs) != 1 || msgs[0].GetContent() != "done" || msgs[0].GetMetadata().GetStructValue().GetFields()["model"].GetStringValue() != "x" {
+		t.Errorf("messages = %v", msgs)
+	}
+}
+
+func TestToProtoPassesThroughUntypedEvents(t *testing.T) {
+	ev, err := ToProto(map[string]interface{}{"type": "CUSTOM", "runId": "r1", "name": "progress"})
+	if err != nil {
+		t.Fatalf("ToProto: %v", err)
+	}
+	other, ok := ev.GetPayload().(*aguiv1.Event_Other)
+	if !ok || other.Other.GetFields()["name"].GetStringValue() != "progress" {
+		t.Errorf("payload = %v", ev.GetPayload())
+	}
+
+	// A field that doesn't fit the typed payload keeps the event instead of dropping it
+	ev, err = ToProto(map[string]interface{}{"type": "RUN_ERROR", "runId": "r1", "message": "boom", "code": 137})
+	if err != nil {
+		t.Fatalf("ToProto: %v", err)
+	}
+	if ev.GetOther().GetFields()["message"].GetStringValue() != "boom" {
+		t.Errorf("payload = %v", ev.GetPayload())
+	}
+
+	ev, err = ToProto(map[string]interface{}{"type": "TEXT_MESSAGE_END", "messageId": "m1"})
+	if err != nil || ev.GetPayload() != nil || ev.GetMessageId() != "m1" {
+		t.Errorf("TEXT_MESSAGE_END = %v, %v", ev, err)
+	}
+}
+
+func TestHTTPError(t *testing.T) {
+	err := httpError(403, []byte(`{"error":"Unauthorized"}`))
+	if err.Error() != "rpc error: code = PermissionDenied desc = Unauthorized" {
+		t.Errorf("err = %v", err)
+	}
+	if err := httpError(503, nil); err.Error() != "rpc error: code = Unavailable desc = Service Unavailable" {
+		t.Errorf("err = %v", err)
+	}
+}
+
+func TestRunEnded(t *testing.T) {
+	body := []byte(`{"threadId":"s1","runs":[{"runId":"r1","status":"completed"},{"runId":"r2","status":"running"}]}`)
+	if !runEnded(body, "r1") || runEnded(body, "r2") || runEnded(body, "r3") {
+		t.Error("runEnded misreported run states")
+	}
+}
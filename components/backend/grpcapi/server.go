@@ -0,0 +1,235 @@
+// Package grpcapi serves the AgentRuns gRPC service (proto/ambient/agui/v1) alongside the
+// REST API, for internal services that prefer gRPC flow control to parsing SSE.
+package grpcapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"time"
+
+	"ambient-code-backend/grpcapi/aguiv1"
+	"ambient-code-backend/logging"
+	"ambient-code-backend/types"
+	"ambient-code-backend/websocket"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+// identityMetadata are the metadata keys forwarded to the REST router as headers. Only
+// the bearer token is forwarded: the port isn't behind the OAuth proxy, so X-Forwarded-*
+// identity metadata would be caller-controlled and is never passed on.
+var identityMetadata = map[string]string{
+	"authorization": "Authorization",
+	"x-request-id":  logging.RequestIDHeader,
+}
+
+// Server implements aguiv1.AgentRunsServer. Authentication, authorization, rate limits
+// and auditing are left to the REST router: each call is dispatched through it with
+// the caller's identity, like the MCP server's tool calls.
+type Server struct {
+	aguiv1.UnimplementedAgentRunsServer
+	router http.Handler
+}
+
+// NewServer returns a Server dispatching through router
+func NewServer(router http.Handler) *Server {
+	return &Server{router: router}
+}
+
+// Serve listens on addr and serves the AgentRuns service until the listener fails
+func Serve(addr string, router http.Handler) error {
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+	// Ping idle streams so gateways don't drop them, like the SSE keepalive
+	s := grpc.NewServer(grpc.KeepaliveParams(keepalive.ServerParameters{Time: 30 * time.Second}))
+	aguiv1.RegisterAgentRunsServer(s, NewServer(router))
+	log.Printf("gRPC server starting on %s", addr)
+	return s.Serve(lis)
+}
+
+// SubmitRun starts a run through POST .../agui/run
+func (s *Server) SubmitRun(ctx context.Context, req *aguiv1.SubmitRunRequest) (*aguiv1.SubmitRunResponse, error) {
+	path, err := sessionPath(req.GetProject(), req.GetSession())
+	if err != nil {
+		return nil, err
+	}
+	body := []byte("{}")
+	if req.GetInput() != nil {
+		// protojson uses the same camelCase names as types.RunAgentInput
+		if body, err = protojson.Marshal(req.GetInput()); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid input: %v", err)
+		}
+	}
+
+	code, resp := s.dispatch(ctx, http.MethodPost, path+"/agui/run", body)
+	// 202 is a run that is queued or waits for its idle session to resume
+	if code != http.StatusOK && code != http.StatusAccepted {
+		return nil, httpError(code, resp)
+	}
+	var out struct {
+		ThreadID string `json:"threadId"`
+		RunID    string `json:"runId"`
+		Status   string `json:"status"`
+	}
+	if err := json.Unmarshal(resp, &out); err != nil {
+		return nil, status.Error(codes.Internal, "invalid run response")
+	}
+	return &aguiv1.SubmitRunResponse{ThreadId: out.ThreadID, RunId: out.RunID, Status: out.Status}, nil
+}
+
+// StreamEvents streams the session's events as they are broadcast to SSE clients
+func (s *Server) StreamEvents(req *aguiv1.StreamEventsRequest, stream aguiv1.AgentRuns_StreamEventsServer) error {
+	ctx := stream.Context()
+	path, err := sessionPath(req.GetProject(), req.GetSession())
+	if err != nil {
+		return err
+	}
+
+	// Listing the session's runs checks the caller may read the session
+	code, resp := s.dispatch(ctx, http.MethodGet, path+"/agui/runs", nil)
+	if code != http.StatusOK {
+		return httpError(code, resp)
+	}
+	runID := req.GetRunId()
+	if runID != "" && !req.GetReplay() && runEnded(resp, runID) {
+		return nil
+	}
+
+	// Subscribe before reading history so nothing is missed in between
+	events, unsubscribe := websocket.SubscribeSession(req.GetSession())
+	defer unsubscribe()
+
+	if req.GetReplay() {
+		history, err := websocket.SessionEvents(req.GetSession(), runID)
+		if err != nil {
+			logging.Errorf(ctx, "gRPC: failed to load events for %s/%s: %v", req.GetProject(), req.GetSession(), err)
+			return status.Error(codes.Internal, "failed to load events")
+		}
+		for _, event := range history {
+			if done, err := send(stream, event, runID); done || err != nil {
+				return err
+			}
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		case event, ok := <-events:
+			if !ok {
+				return nil
+			}
+			if done, err := send(stream, event, runID); done || err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// send converts and sends event unless it belongs to a run other than runID. done
+// reports that runID has ended.
+func send(stream aguiv1.AgentRuns_StreamEventsServer, event interface{}, runID string) (done bool, err error) {
+	ev, err := ToProto(event)
+	if err != nil {
+		logging.Warnf(stream.Context(), "gRPC: skipping unconvertible event: %v", err)
+		return false, nil
+	}
+	if runID != "" && ev.GetRunId() != runID {
+		return false, nil
+	}
+	if err := stream.Send(ev); err != nil {
+		return false, err
+	}
+	return runID != "" && (ev.GetType() == types.EventTypeRunFinished || ev.GetType() == types.EventTypeRunError), nil
+}
+
+// runEnded reports whether the runs listing in body has runID in a final state
+func runEnded(body []byte, runID string) bool {
+	var list struct {
+		Runs []types.AGUIRunMetadata `json:"runs"`
+	}
+	if err := json.Unmarshal(body, &list); err != nil {
+		return false
+	}
+	for _, r := range list.Runs {
+		if r.RunID == runID {
+			return r.Status != "running"
+		}
+	}
+	return false
+}
+
+func sessionPath(project, session string) (string, error) {
+	if project == "" || session == "" {
+		return "", status.Error(codes.InvalidArgument, "project and session are required")
+	}
+	return fmt.Sprintf("/api/projects/%s/agentic-sessions/%s", url.PathEscape(project), url.PathEscape(session)), nil
+}
+
+// dispatch serves a request through the REST router with the caller's identity metadata
+func (s *Server) dispatch(ctx context.Context, method, path string, body []byte) (int, []byte) {
+	req, err := http.NewRequestWithContext(ctx, method, path, bytes.NewReader(body))
+	if err != nil {
+		return http.StatusInternalServerError, nil
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		for key, header := range identityMetadata {
+			if v := md.Get(key); len(v) > 0 && v[0] != "" {
+				req.Header.Set(header, v[0])
+			}
+		}
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	rec := httptest.NewRecorder()
+	s.router.ServeHTTP(rec, req)
+	return rec.Code, rec.Body.Bytes()
+}
+
+// httpError converts a REST error response to a gRPC status
+func httpError(code int, body []byte) error {
+	var resp struct {
+		Error string `json:"error"`
+	}
+	msg := http.StatusText(code)
+	if json.Unmarshal(body, &resp) == nil && resp.Error != "" {
+		msg = resp.Error
+	}
+
+	c := codes.Internal
+	switch code {
+	case http.StatusBadRequest:
+		c = codes.InvalidArgument
+	case http.StatusUnauthorized:
+		c = codes.Unauthenticated
+	case http.StatusForbidden:
+		c = codes.PermissionDenied
+	case http.StatusNotFound:
+		c = codes.NotFound
+	case http.StatusConflict:
+		c = codes.AlreadyExists
+	case http.StatusPreconditionFailed:
+		c = codes.FailedPrecondition
+	case http.StatusTooManyRequests:
+		c = codes.ResourceExhausted
+	case http.StatusServiceUnavailable, http.StatusBadGateway:
+		c = codes.Unavailable
+	}
+	return status.Error(c, msg)
+}
@@ -0,0 +1,35 @@
+package grpcapi
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"google.golang.org/grpc/metadata"
+)
+
+// TestDispatchForwardsOnlyTokenAndRequestID verifies caller-supplied X-Forwarded-*
+// metadata never reaches the REST router
+func TestDispatchForwardsOnlyTokenAndRequestID(t *testing.T) {
+	var got http.Header
+	s := NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+	}))
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(
+		"authorization", "Bearer t1",
+		"x-request-id", "req-1",
+		"x-forwarded-user", "admin",
+		"x-forwarded-groups", "system:masters",
+		"x-forwarded-access-token", "other-token",
+	))
+	s.dispatch(ctx, http.MethodGet, "/api/projects/p/agentic-sessions/s", nil)
+
+	if got.Get("Authorization") != "Bearer t1" || got.Get("X-Request-Id") != "req-1" {
+		t.Errorf("token and request ID not forwarded: %v", got)
+	}
+	for _, h := range []string{"X-Forwarded-User", "X-Forwarded-Groups", "X-Forwarded-Access-Token"} {
+		if v := got.Get(h); v != "" {
+			t.Errorf("%s forwarded as %q", h, v)
+		}
+	}
+}
@@ -0,0 +1,16 @@
+package handlers
+
+import (
+	"net/http"
+
+	"ambient-code-backend/config"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetAdminConfig returns the active backend configuration, the file and environment
+// variables it came from, and any file changes waiting for a restart.
+// GET /api/admin/config
+func GetAdminConfig(c *gin.Context) {
+	c.JSON(http.StatusOK, config.CurrentStatus())
+}
@@ -0,0 +1,233 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"ambient-code-backend/logging"
+	"ambient-code-backend/types"
+
+	"github.com/gin-gonic/gin"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// ActiveRunCounts reports running AG-UI runs per session name. Set from main to
+// avoid an import cycle with the websocket package; nil reports no active runs.
+var ActiveRunCounts func() map[string]int
+
+// runnerPodSelector matches the runner pods the operator creates for sessions
+const runnerPodSelector = "app=ambient-code-runner"
+
+// adminSessionQuery holds the inventory filters on top of the usual pagination params
+type adminSessionQuery struct {
+	types.PaginationParams
+	Project    string `form:"project"`
+	Phase      string `form:"phase"`      // comma-separated, case-insensitive
+	Owner      string `form:"owner"`      // user id or display name
+	OlderThan  string `form:"olderThan"`  // Go duration, e.g. "6h"
+	ActiveRuns *bool  `form:"activeRuns"` // only sessions with (true) or without (false) running runs
+}
+
+// ListAllSessions lists AgenticSessions across all projects for platform operators,
+// oldest first so stuck sessions surface at the top.
+// GET /api/admin/agentic-sessions?project=&phase=Running,Pending&owner=&olderThan=6h&activeRuns=true
+func ListAllSessions(c *gin.Context) {
+	reqK8s, reqDyn := GetK8sClientsForRequest(c)
+	if reqK8s == nil || reqDyn == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing token"})
+		c.Abort()
+		return
+	}
+
+	var q adminSessionQuery
+	if err := c.ShouldBindQuery(&q); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query parameters"})
+		return
+	}
+	types.NormalizePaginationParams(&q.PaginationParams)
+	if q.Project != "" && !isValidKubernetesName(q.Project) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project name format"})
+		return
+	}
+	var olderThan time.Duration
+	if q.OlderThan != "" {
+		d, err := time.ParseDuration(q.OlderThan)
+		if err != nil || d < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "olderThan must be a duration such as 30m or 6h"})
+			return
+		}
+		olderThan = d
+	}
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 30*time.Second)
+	defer cancel()
+
+	// Empty namespace lists across all namespaces
+	list, err := reqDyn.Resource(GetAgenticSessionV1Alpha1Resource()).Namespace(q.Project).List(ctx, v1.ListOptions{})
+	if err != nil {
+		logging.Errorf(c, "ListAllSessions: failed to list agentic sessions: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list agentic sessions"})
+		return
+	}
+
+	resources := map[string]*types.AdminSessionResources{}
+	pods, err := reqK8s.CoreV1().Pods(q.Project).List(ctx, v1.ListOptions{LabelSelector: runnerPodSelector})
+	if err != nil {
+		// Inventory is still useful without pod data
+		logging.Errorf(c, "ListAllSessions: failed to list runner pods: %v", err)
+	} else {
+		resources = summarizeRunnerPods(pods.Items)
+	}
+
+	activeRuns := map[string]int{}
+	if ActiveRunCounts != nil {
+		activeRuns = ActiveRunCounts()
+	}
+
+	var phases []string
+	for _, p := range strings.Split(q.Phase, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			phases = append(phases, p)
+		}
+	}
+
+	now := time.Now()
+	items := make([]types.AdminSessionSummary, 0, len(list.Items))
+	for _, item := range list.Items {
+		summary := summarizeAdminSession(item, now)
+		summary.ActiveRuns = activeRuns[summary.Name]
+		if r, ok := resources[summary.Namespace+"/"+summary.Name]; ok {
+			summary.Resources = *r
+		}
+
+		if len(phases) > 0 && !containsFold(phases, summary.Phase) {
+			continue
+		}
+		if q.Owner != "" && !strings.EqualFold(q.Owner, summary.Owner) && !strings.EqualFold(q.Owner, summary.OwnerName) {
+			continue
+		}
+		if olderThan > 0 && time.Duration(summary.AgeSeconds)*time.Second < olderThan {
+			continue
+		}
+		if q.ActiveRuns != nil && (summary.ActiveRuns > 0) != *q.ActiveRuns {
+			continue
+		}
+		if q.Search != "" && !strings.Contains(strings.ToLower(summary.Name+" "+summary.DisplayName), strings.ToLower(q.Search)) {
+			continue
+		}
+		items = append(items, summary)
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].AgeSeconds > items[j].AgeSeconds
+	})
+
+	total := len(items)
+	start := min(q.Offset, total)
+	end := min(start+q.Limit, total)
+	response := types.PaginatedResponse{
+		Items:      items[start:end],
+		TotalCount: total,
+		Limit:      q.Limit,
+		Offset:     q.Offset,
+		HasMore:    end < total,
+	}
+	if response.HasMore {
+		response.NextOffset = &end
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// summarizeAdminSession extracts the inventory fields from an AgenticSession
+func summarizeAdminSession(item unstructured.Unstructured, now time.Time) types.AdminSessionSummary {
+	created := item.GetCreationTimestamp().Time
+	summary := types.AdminSessionSummary{
+		Name:       item.GetName(),
+		Namespace:  item.GetNamespace(),
+		CreatedAt:  created.UTC().Format(time.RFC3339),
+		AgeSeconds: int64(now.Sub(created).Seconds()),
+	}
+	if spec, found, err := unstructured.NestedMap(item.Object, "spec"); err == nil && found {
+		parsed := parseSpec(spec)
+		summary.DisplayName = parsed.DisplayName
+		summary.Interactive = parsed.Interactive
+		if parsed.UserContext != nil {
+			summary.Owner = parsed.UserContext.UserID
+			summary.OwnerName = parsed.UserContext.DisplayName
+		}
+	}
+	if status, found, err := unstructured.NestedMap(item.Object, "status"); err == nil && found {
+		parsed := parseStatus(status)
+		summary.Phase = parsed.Phase
+		summary.StartTime = parsed.StartTime
+	}
+	if summary.Phase == "" {
+		summary.Phase = "Pending"
+	}
+	return summary
+}
+
+// summarizeRunnerPods totals runner pod requests, limits and restarts per
+// "<namespace>/<session>", using the operator's agentic-session pod label
+func summarizeRunnerPods(pods []corev1.Pod) map[string]*types.AdminSessionResources {
+	type totals struct {
+		cpuReq, cpuLim, memReq, memLim resource.Quantity
+	}
+	sums := map[string]*totals{}
+	out := map[string]*types.AdminSessionResources{}
+	for _, pod := range pods {
+		session := pod.Labels["agentic-session"]
+		if session == "" {
+			continue
+		}
+		key := pod.Namespace + "/" + session
+		r, ok := out[key]
+		if !ok {
+			r = &types.AdminSessionResources{}
+			out[key] = r
+			sums[key] = &totals{}
+		}
+		t := sums[key]
+		r.Pods++
+		for _, cs := range pod.Status.ContainerStatuses {
+			r.Restarts += cs.RestartCount
+		}
+		for _, ctr := range pod.Spec.Containers {
+			t.cpuReq.Add(ctr.Resources.Requests[corev1.ResourceCPU])
+			t.cpuLim.Add(ctr.Resources.Limits[corev1.ResourceCPU])
+			t.memReq.Add(ctr.Resources.Requests[corev1.ResourceMemory])
+			t.memLim.Add(ctr.Resources.Limits[corev1.ResourceMemory])
+		}
+	}
+	for key, r := range out {
+		t := sums[key]
+		r.CPURequest = quantityString(t.cpuReq)
+		r.CPULimit = quantityString(t.cpuLim)
+		r.MemoryRequest = quantityString(t.memReq)
+		r.MemoryLimit = quantityString(t.memLim)
+	}
+	return out
+}
+
+func quantityString(q resource.Quantity) string {
+	if q.IsZero() {
+		return ""
+	}
+	return q.String()
+}
+
+func containsFold(values []string, s string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,153 @@
+//go:build test
+
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	test_constants "ambient-code-backend/tests/constants"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	authv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+var _ = Describe("Admin Session Inventory", Label(test_constants.LabelUnit, test_constants.LabelHandlers), func() {
+	var (
+		originalK8sClientMw     kubernetes.Interface
+		originalDynamicClient   dynamic.Interface
+		originalGVR             func() schema.GroupVersionResource
+		originalActiveRunCounts func() map[string]int
+		clusterAdmin            bool
+		router                  *gin.Engine
+	)
+	gvr := schema.GroupVersionResource{Group: "vteam.ambient-code", Version: "v1alpha1", Resource: "agenticsessions"}
+
+	session := func(namespace, name, phase, owner string, age time.Duration) *unstructured.Unstructured {
+		obj := &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "vteam.ambient-code/v1alpha1",
+			"kind":       "AgenticSession",
+			"metadata": map[string]interface{}{
+				"name":              name,
+				"namespace":         namespace,
+				"creationTimestamp": time.Now().Add(-age).UTC().Format(time.RFC3339),
+			},
+			"spec": map[string]interface{}{
+				"displayName": name,
+				"userContext": map[string]interface{}{"userId": owner, "displayName": owner},
+			},
+			"status": map[string]interface{}{"phase": phase},
+		}}
+		return obj
+	}
+
+	BeforeEach(func() {
+		originalK8sClientMw = K8sClientMw
+		originalDynamicClient = DynamicClient
+		originalGVR = GetAgenticSessionV1Alpha1Resource
+		originalActiveRunCounts = ActiveRunCounts
+		clusterAdmin = true
+
+		pod := &corev1.Pod{
+			ObjectMeta: v1.ObjectMeta{Name: "stuck-runner", Namespace: "team-a", Labels: map[string]string{"app": "ambient-code-runner", "agentic-session": "stuck"}},
+			Spec: corev1.PodSpec{Containers: []corev1.Container{{
+				Name: "runner",
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("500m"), corev1.ResourceMemory: resource.MustParse("1Gi")},
+				},
+			}}},
+			Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{{Name: "runner", RestartCount: 3}}},
+		}
+		client := fake.NewSimpleClientset(pod)
+		client.PrependReactor("create", "selfsubjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			return true, &authv1.SelfSubjectAccessReview{Status: authv1.SubjectAccessReviewStatus{Allowed: clusterAdmin}}, nil
+		})
+		K8sClientMw = client
+		DynamicClient = dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+			map[schema.GroupVersionResource]string{gvr: "AgenticSessionList"},
+			session("team-a", "stuck", "Running", "alice", 48*time.Hour),
+			session("team-a", "fresh", "Running", "bob", time.Minute),
+			session("team-b", "done", "Completed", "alice", 24*time.Hour),
+		)
+		GetAgenticSessionV1Alpha1Resource = func() schema.GroupVersionResource { return gvr }
+		ActiveRunCounts = func() map[string]int { return map[string]int{"fresh": 1} }
+
+		router = gin.New()
+		router.GET("/admin/agentic-sessions", RequireClusterAdmin(), ListAllSessions)
+	})
+
+	AfterEach(func() {
+		K8sClientMw = originalK8sClientMw
+		DynamicClient = originalDynamicClient
+		GetAgenticSessionV1Alpha1Resource = originalGVR
+		ActiveRunCounts = originalActiveRunCounts
+	})
+
+	list := func(query string) (int, []map[string]interface{}) {
+		req := httptest.NewRequest(http.MethodGet, "/admin/agentic-sessions"+query, nil)
+		req.Header.Set("Authorization", "Bearer admin-token")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		var body struct {
+			Items []map[string]interface{} `json:"items"`
+		}
+		_ = json.Unmarshal(w.Body.Bytes(), &body)
+		return w.Code, body.Items
+	}
+
+	names := func(items []map[string]interface{}) []string {
+		var out []string
+		for _, item := range items {
+			out = append(out, item["name"].(string))
+		}
+		return out
+	}
+
+	It("Should list sessions across projects oldest first with pods and active runs", func() {
+		code, items := list("")
+		Expect(code).To(Equal(http.StatusOK))
+		Expect(names(items)).To(Equal([]string{"stuck", "done", "fresh"}))
+
+		resources := items[0]["resources"].(map[string]interface{})
+		Expect(resources["pods"]).To(BeEquivalentTo(1))
+		Expect(resources["restarts"]).To(BeEquivalentTo(3))
+		Expect(resources["cpuRequest"]).To(Equal("500m"))
+		Expect(resources["memoryRequest"]).To(Equal("1Gi"))
+		Expect(items[2]["activeRuns"]).To(BeEquivalentTo(1))
+	})
+
+	It("Should apply filters", func() {
+		_, items := list("?phase=running&olderThan=1h")
+		Expect(names(items)).To(Equal([]string{"stuck"}))
+
+		_, items = list("?owner=alice&project=team-b")
+		Expect(names(items)).To(Equal([]string{"done"}))
+
+		_, items = list("?activeRuns=true")
+		Expect(names(items)).To(Equal([]string{"fresh"}))
+
+		code, _ := list("?olderThan=yesterday")
+		Expect(code).To(Equal(http.StatusBadRequest))
+	})
+
+	It("Should require cluster admin", func() {
+		clusterAdmin = false
+		code, _ := list("")
+		Expect(code).To(Equal(http.StatusForbidden))
+	})
+})
@@ -0,0 +1,488 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"ambient-code-backend/logging"
+	"ambient-code-backend/types"
+
+	"github.com/gin-gonic/gin"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic"
+)
+
+// Sessions are admitted when they are created: if the project has no room for another
+// runner pod they are queued or rejected per the project's placement policy, rather
+// than left with a pod that stays Pending. The operator holds queued sessions and pins
+// admitted ones to the chosen node pool.
+// IMPORTANT: Keep in sync with operator (internal/handlers/admission.go)
+const (
+	// admissionLabel is "queued" while a session waits for capacity
+	admissionLabel            = "ambient-code.io/admission"
+	admissionQueued           = "queued"
+	admissionReasonAnnotation = "ambient-code.io/admission-reason"
+	// nodePoolAnnotation names the policy node pool the runner is pinned to; runner
+	// pods carry it as a label
+	nodePoolAnnotation = "ambient-code.io/node-pool"
+
+	admissionQueueInterval = 30 * time.Second
+)
+
+// runnerPodRequests are the resource requests of a runner pod (runner and state-sync
+// containers). IMPORTANT: Keep in sync with operator (internal/handlers/sessions.go)
+var runnerPodRequests = corev1.ResourceList{
+	corev1.ResourceCPU:    resource.MustParse("600m"),
+	corev1.ResourceMemory: resource.MustParse("640Mi"),
+}
+
+// validatePlacementPolicy checks a policy before it is stored or applied
+func validatePlacementPolicy(p types.PlacementPolicy) error {
+	switch p.OnInsufficientCapacity {
+	case types.PlacementQueue, types.PlacementReject, types.PlacementFallback:
+	default:
+		return fmt.Errorf("onInsufficientCapacity must be one of: queue, reject, fallback")
+	}
+	if p.MaxPendingRunners < 0 {
+		return fmt.Errorf("maxPendingRunners must not be negative")
+	}
+	if p.MaxConcurrentRuns < 0 || p.MaxQueuedRuns < 0 {
+		return fmt.Errorf("maxConcurrentRuns and maxQueuedRuns must not be negative")
+	}
+	if p.MaxConcurrentRuns == 0 && (p.MaxQueuedRuns > 0 || p.PreemptQueuedRuns) {
+		return fmt.Errorf("maxQueuedRuns and preemptQueuedRuns need maxConcurrentRuns")
+	}
+	if p.OnInsufficientCapacity == types.PlacementFallback && len(p.NodePools) < 2 {
+		return fmt.Errorf("fallback needs at least two node pools")
+	}
+	seen := map[string]bool{}
+	for i, pool := range p.NodePools {
+		if !isValidKubernetesName(pool.Name) {
+			return fmt.Errorf("nodePools[%d]: invalid name %q", i, pool.Name)
+		}
+		if seen[pool.Name] {
+			return fmt.Errorf("nodePools[%d]: duplicate name %q", i, pool.Name)
+		}
+		seen[pool.Name] = true
+		if len(pool.NodeSelector) == 0 {
+			return fmt.Errorf("nodePools[%d]: nodeSelector is required", i)
+		}
+	}
+	return nil
+}
+
+// placementPolicyFromSettings reads spec.placementPolicy from a ProjectSettings object
+func placementPolicyFromSettings(obj *unstructured.Unstructured) (*types.PlacementPolicy, error) {
+	raw, found, err := unstructured.NestedMap(obj.Object, "spec", "placementPolicy")
+	if err != nil || !found {
+		return nil, err
+	}
+	var policy types.PlacementPolicy
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(raw, &policy); err != nil {
+		return nil, err
+	}
+	if policy.OnInsufficientCapacity == "" {
+		policy.OnInsufficientCapacity = types.PlacementQueue
+	}
+	if err := validatePlacementPolicy(policy); err != nil {
+		return nil, fmt.Errorf("invalid placementPolicy: %w", err)
+	}
+	return &policy, nil
+}
+
+// getPlacementPolicy returns the project's placement policy, or nil if none is configured
+func getPlacementPolicy(ctx context.Context, dynClient dynamic.Interface, project string) (*types.PlacementPolicy, error) {
+	obj, err := dynClient.Resource(GetProjectSettingsResource()).Namespace(project).Get(ctx, "projectsettings", v1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return placementPolicyFromSettings(obj)
+}
+
+// ProjectPlacementPolicy returns the project's placement policy using the backend
+// service account, or nil if none is configured
+func ProjectPlacementPolicy(ctx context.Context, project string) (*types.PlacementPolicy, error) {
+	return getPlacementPolicy(ctx, DynamicClient, project)
+}
+
+// admitSession decides whether a new runner pod fits in the project. Without a policy
+// only quota headroom is checked and sessions that don't fit are queued.
+func admitSession(ctx context.Context, project string, policy *types.PlacementPolicy) (types.AdmissionDecision, error) {
+	if policy == nil {
+		policy = &types.PlacementPolicy{OnInsufficientCapacity: types.PlacementQueue}
+	}
+
+	reason, err := quotaShortfall(ctx, project)
+	if err != nil {
+		return types.AdmissionDecision{}, err
+	}
+	if reason == "" {
+		// Quota is per namespace, so only pending pods differ between pools
+		pools := policy.NodePools
+		if len(pools) == 0 {
+			pools = []types.NodePool{{}}
+		}
+		if policy.OnInsufficientCapacity != types.PlacementFallback {
+			pools = pools[:1]
+		}
+		for _, pool := range pools {
+			if reason, err = pendingShortfall(ctx, project, pool.Name, policy.MaxPendingRunners); err != nil {
+				return types.AdmissionDecision{}, err
+			}
+			if reason == "" {
+				return types.AdmissionDecision{Outcome: types.AdmissionAdmitted, NodePool: pool.Name}, nil
+			}
+		}
+	}
+
+	if policy.OnInsufficientCapacity == types.PlacementReject {
+		return types.AdmissionDecision{Outcome: types.AdmissionRejected, Reason: reason}, nil
+	}
+	return types.AdmissionDecision{Outcome: types.AdmissionQueued, Reason: reason}, nil
+}
+
+// quotaShortfall returns why the project's ResourceQuotas can't fit another runner
+// pod, or "" if they can
+func quotaShortfall(ctx context.Context, project string) (string, error) {
+	quotas, err := K8sClient.CoreV1().ResourceQuotas(project).List(ctx, v1.ListOptions{})
+	if err != nil {
+		return "", fmt.Errorf("failed to list resource quotas: %w", err)
+	}
+	need := map[corev1.ResourceName]resource.Quantity{
+		corev1.ResourcePods:           resource.MustParse("1"),
+		"count/pods":                  resource.MustParse("1"),
+		corev1.ResourceCPU:            runnerPodRequests[corev1.ResourceCPU],
+		corev1.ResourceRequestsCPU:    runnerPodRequests[corev1.ResourceCPU],
+		corev1.ResourceMemory:         runnerPodRequests[corev1.ResourceMemory],
+		corev1.ResourceRequestsMemory: runnerPodRequests[corev1.ResourceMemory],
+	}
+	for _, q := range quotas.Items {
+		names := make([]string, 0, len(q.Status.Hard))
+		for name := range q.Status.Hard {
+			names = append(names, string(name))
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			amount, ok := need[corev1.ResourceName(name)]
+			if !ok {
+				continue
+			}
+			left := q.Status.Hard[corev1.ResourceName(name)].DeepCopy()
+			left.Sub(q.Status.Used[corev1.ResourceName(name)])
+			if left.Cmp(amount) < 0 {
+				return fmt.Sprintf("resource quota %s has %s %s left, a runner needs %s", q.Name, left.String(), name, amount.String()), nil
+			}
+		}
+	}
+	return "", nil
+}
+
+// pendingShortfall returns why the node pool can't take another runner pod because
+// maxPending runner pods in the project are already waiting to be scheduled, or ""
+func pendingShortfall(ctx context.Context, project, pool string, maxPending int) (string, error) {
+	if maxPending <= 0 {
+		return "", nil
+	}
+	selector := "app=ambient-code-runner"
+	if pool != "" {
+		selector += "," + nodePoolAnnotation + "=" + pool
+	}
+	pods, err := K8sClient.CoreV1().Pods(project).List(ctx, v1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return "", fmt.Errorf("failed to list runner pods: %w", err)
+	}
+	pending := 0
+	for i := range pods.Items {
+		if isUnscheduled(&pods.Items[i]) {
+			pending++
+		}
+	}
+	if pending < maxPending {
+		return "", nil
+	}
+	if pool != "" {
+		return fmt.Sprintf("%d runner pods are waiting for nodes in pool %s", pending, pool), nil
+	}
+	return fmt.Sprintf("%d runner pods are waiting for nodes", pending), nil
+}
+
+// isUnscheduled reports whether a pod is Pending without a node
+func isUnscheduled(pod *corev1.Pod) bool {
+	if pod.Status.Phase != corev1.PodPending || pod.Spec.NodeName != "" {
+		return false
+	}
+	for _, c := range pod.Status.Conditions {
+		if c.Type == corev1.PodScheduled && c.Status == corev1.ConditionTrue {
+			return false
+		}
+	}
+	return true
+}
+
+// applyAdmission records the decision on a new session's metadata map, replacing any
+// admission keys the caller supplied
+func applyAdmission(metadata map[string]interface{}, decision types.AdmissionDecision) {
+	labels, _ := metadata["labels"].(map[string]interface{})
+	if labels == nil {
+		labels = map[string]interface{}{}
+	}
+	delete(labels, admissionLabel)
+	if decision.Outcome == types.AdmissionQueued {
+		labels[admissionLabel] = admissionQueued
+	}
+	annotations, _ := metadata["annotations"].(map[string]interface{})
+	if annotations == nil {
+		annotations = map[string]interface{}{}
+	}
+	delete(annotations, admissionReasonAnnotation)
+	delete(annotations, nodePoolAnnotation)
+	if decision.Reason != "" {
+		annotations[admissionReasonAnnotation] = decision.Reason
+	}
+	if decision.NodePool != "" {
+		annotations[nodePoolAnnotation] = decision.NodePool
+	}
+	if len(labels) > 0 {
+		metadata["labels"] = labels
+	} else {
+		delete(metadata, "labels")
+	}
+	if len(annotations) > 0 {
+		metadata["annotations"] = annotations
+	} else {
+		delete(metadata, "annotations")
+	}
+}
+
+// admissionRejectedError is returned by createAdmittedSession when the project's
+// placement policy rejects a session for lack of capacity
+type admissionRejectedError struct {
+	reason string
+}
+
+func (e *admissionRejectedError) Error() string {
+	return "insufficient capacity to start a session: " + e.reason
+}
+
+// createAdmittedSession admits a new session per its project's placement policy,
+// records the decision on it and creates it with dynClient. Every session the backend
+// creates goes through here, so sessions started by triggers, incidents and workflows
+// are queued like interactive ones. A failed capacity check admits the session rather
+// than blocking it; a rejection returns *admissionRejectedError.
+func createAdmittedSession(ctx context.Context, dynClient dynamic.Interface, obj *unstructured.Unstructured) (*unstructured.Unstructured, types.AdmissionDecision, error) {
+	project := obj.GetNamespace()
+	admitted := types.AdmissionDecision{Outcome: types.AdmissionAdmitted}
+	policy, err := getPlacementPolicy(ctx, dynClient, project)
+	if err != nil {
+		return nil, admitted, fmt.Errorf("failed to load placement policy: %w", err)
+	}
+	decision := admitted
+	if K8sClient != nil {
+		if decision, err = admitSession(ctx, project, policy); err != nil {
+			logging.Warnf(ctx, "Capacity check for project %s failed, admitting session: %v", project, err)
+			decision = admitted
+		}
+	}
+	if decision.Outcome == types.AdmissionRejected {
+		return nil, decision, &admissionRejectedError{reason: decision.Reason}
+	}
+
+	metadata, _ := obj.Object["metadata"].(map[string]interface{})
+	if metadata == nil {
+		metadata = map[string]interface{}{}
+		obj.Object["metadata"] = metadata
+	}
+	applyAdmission(metadata, decision)
+	created, err := dynClient.Resource(GetAgenticSessionV1Alpha1Resource()).Namespace(project).Create(ctx, obj, v1.CreateOptions{})
+	return created, decision, err
+}
+
+// StartAdmissionQueue periodically admits queued sessions for the life of the process
+func StartAdmissionQueue() {
+	if DynamicClient == nil || K8sClient == nil {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(admissionQueueInterval)
+		defer ticker.Stop()
+		for range ticker.C {
+			ctx, cancel := context.WithTimeout(context.Background(), admissionQueueInterval)
+			processAdmissionQueue(ctx)
+			cancel()
+		}
+	}()
+}
+
+// processAdmissionQueue admits queued sessions oldest first, at most one per project
+// per pass so a freshly admitted runner shows up in the next capacity check. Sessions
+// that left Pending while queued (e.g. were stopped) are released from the queue.
+func processAdmissionQueue(ctx context.Context) {
+	gvr := GetAgenticSessionV1Alpha1Resource()
+	list, err := DynamicClient.Resource(gvr).Namespace("").List(ctx, v1.ListOptions{
+		LabelSelector: admissionLabel + "=" + admissionQueued,
+	})
+	if err != nil {
+		logging.Errorf(ctx, "Admission queue: failed to list queued sessions: %v", err)
+		return
+	}
+	items := list.Items
+	sort.SliceStable(items, func(a, b int) bool {
+		return items[a].GetCreationTimestamp().Time.Before(items[b].GetCreationTimestamp().Time)
+	})
+
+	done := map[string]bool{}
+	for i := range items {
+		item := &items[i]
+		project := item.GetNamespace()
+		phase, _, _ := unstructured.NestedString(item.Object, "status", "phase")
+		decision := types.AdmissionDecision{Outcome: types.AdmissionAdmitted}
+		if phase == "" || phase == "Pending" {
+			if done[project] {
+				continue
+			}
+			done[project] = true
+			policy, err := getPlacementPolicy(ctx, DynamicClient, project)
+			if err != nil {
+				logging.Errorf(ctx, "Admission queue: failed to load placement policy for %s: %v", project, err)
+				continue
+			}
+			if decision, err = admitSession(ctx, project, policy); err != nil {
+				logging.Errorf(ctx, "Admission queue: capacity check for %s failed: %v", project, err)
+				continue
+			}
+			if decision.Outcome != types.AdmissionAdmitted {
+				updateQueuedReason(ctx, item, decision.Reason)
+				continue
+			}
+		}
+
+		labels := item.GetLabels()
+		delete(labels, admissionLabel)
+		item.SetLabels(labels)
+		annotations := item.GetAnnotations()
+		delete(annotations, admissionReasonAnnotation)
+		if decision.NodePool != "" {
+			if annotations == nil {
+				annotations = map[string]string{}
+			}
+			annotations[nodePoolAnnotation] = decision.NodePool
+		}
+		item.SetAnnotations(annotations)
+		// The resourceVersion check ensures only one backend replica admits the session
+		if _, err := DynamicClient.Resource(gvr).Namespace(project).Update(ctx, item, v1.UpdateOptions{}); err != nil {
+			if !errors.IsConflict(err) && !errors.IsNotFound(err) {
+				logging.Errorf(ctx, "Admission queue: failed to admit session %s/%s: %v", project, item.GetName(), err)
+			}
+			continue
+		}
+		logging.Infof(ctx, "Admission queue: admitted session %s/%s (pool %q)", project, item.GetName(), decision.NodePool)
+	}
+}
+
+// updateQueuedReason refreshes the reason shown on a session that stays queued
+func updateQueuedReason(ctx context.Context, item *unstructured.Unstructured, reason string) {
+	annotations := item.GetAnnotations()
+	if reason == "" || annotations[admissionReasonAnnotation] == reason {
+		return
+	}
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[admissionReasonAnnotation] = reason
+	item.SetAnnotations(annotations)
+	_, err := DynamicClient.Resource(GetAgenticSessionV1Alpha1Resource()).Namespace(item.GetNamespace()).Update(ctx, item, v1.UpdateOptions{})
+	if err != nil && !errors.IsConflict(err) && !errors.IsNotFound(err) {
+		logging.Errorf(ctx, "Admission queue: failed to update reason on %s/%s: %v", item.GetNamespace(), item.GetName(), err)
+	}
+}
+
+// GetPlacementPolicy handles GET /api/projects/:projectName/placement-policy
+func GetPlacementPolicy(c *gin.Context) {
+	project := c.GetString("project")
+	_, reqDyn := GetK8sClientsForRequest(c)
+	if reqDyn == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing token"})
+		c.Abort()
+		return
+	}
+
+	policy, err := getPlacementPolicy(c.Request.Context(), reqDyn, project)
+	if err != nil {
+		if errors.IsForbidden(err) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions to read project settings"})
+			return
+		}
+		logging.Errorf(c, "Failed to get placement policy for project %s: %v", project, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get placement policy"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"policy": policy})
+}
+
+// UpdatePlacementPolicy handles PUT /api/projects/:projectName/placement-policy
+// Requires update permission on ProjectSettings (project admins). Applies to sessions admitted afterwards.
+func UpdatePlacementPolicy(c *gin.Context) {
+	project := c.GetString("project")
+	_, reqDyn := GetK8sClientsForRequest(c)
+	if reqDyn == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing token"})
+		c.Abort()
+		return
+	}
+
+	var policy types.PlacementPolicy
+	if err := c.ShouldBindJSON(&policy); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if policy.OnInsufficientCapacity == "" {
+		policy.OnInsufficientCapacity = types.PlacementQueue
+	}
+	for i := range policy.NodePools {
+		policy.NodePools[i].Name = strings.TrimSpace(policy.NodePools[i].Name)
+	}
+	if err := validatePlacementPolicy(policy); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	value, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&policy)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := setProjectSettingsField(c.Request.Context(), reqDyn, project, "placementPolicy", value); err != nil {
+		respondProjectSettingsError(c, project, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"policy": policy})
+}
+
+// DeletePlacementPolicy handles DELETE /api/projects/:projectName/placement-policy
+func DeletePlacementPolicy(c *gin.Context) {
+	project := c.GetString("project")
+	_, reqDyn := GetK8sClientsForRequest(c)
+	if reqDyn == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing token"})
+		c.Abort()
+		return
+	}
+
+	if err := setProjectSettingsField(c.Request.Context(), reqDyn, project, "placementPolicy", nil); err != nil && !errors.IsNotFound(err) {
+		respondProjectSettingsError(c, project, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Placement policy removed successfully"})
+}
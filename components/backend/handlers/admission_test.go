@@ -0,0 +1,198 @@
+//go:build test
+
+package handlers
+
+import (
+	"context"
+
+	test_constants "ambient-code-backend/tests/constants"
+	"ambient-code-backend/types"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+var _ = Describe("Session Admission", Label(test_constants.LabelUnit, test_constants.LabelHandlers), func() {
+	gvr := schema.GroupVersionResource{Group: "vteam.ambient-code", Version: "v1alpha1", Resource: "agenticsessions"}
+
+	var (
+		originalK8sClient     kubernetes.Interface
+		originalDynamicClient dynamic.Interface
+		originalGVR           func() schema.GroupVersionResource
+		ctx                   context.Context
+	)
+
+	unscheduledRunner := func(name, pool string) *corev1.Pod {
+		labels := map[string]string{"app": "ambient-code-runner"}
+		if pool != "" {
+			labels[nodePoolAnnotation] = pool
+		}
+		return &corev1.Pod{
+			ObjectMeta: v1.ObjectMeta{Name: name, Namespace: "team-a", Labels: labels},
+			Status:     corev1.PodStatus{Phase: corev1.PodPending},
+		}
+	}
+
+	quota := func(hard, used string) *corev1.ResourceQuota {
+		return &corev1.ResourceQuota{
+			ObjectMeta: v1.ObjectMeta{Name: "compute", Namespace: "team-a"},
+			Status: corev1.ResourceQuotaStatus{
+				Hard: corev1.ResourceList{corev1.ResourceRequestsCPU: resource.MustParse(hard)},
+				Used: corev1.ResourceList{corev1.ResourceRequestsCPU: resource.MustParse(used)},
+			},
+		}
+	}
+
+	pools := []types.NodePool{
+		{Name: "general", NodeSelector: map[string]string{"pool": "general"}},
+		{Name: "burst", NodeSelector: map[string]string{"pool": "burst"}},
+	}
+
+	BeforeEach(func() {
+		originalK8sClient, originalDynamicClient, originalGVR = K8sClient, DynamicClient, GetAgenticSessionV1Alpha1Resource
+		GetAgenticSessionV1Alpha1Resource = func() schema.GroupVersionResource { return gvr }
+		ctx = context.Background()
+	})
+
+	AfterEach(func() {
+		K8sClient, DynamicClient, GetAgenticSessionV1Alpha1Resource = originalK8sClient, originalDynamicClient, originalGVR
+	})
+
+	It("Should validate policies", func() {
+		Expect(validatePlacementPolicy(types.PlacementPolicy{OnInsufficientCapacity: "queue"})).To(Succeed())
+		Expect(validatePlacementPolicy(types.PlacementPolicy{OnInsufficientCapacity: "fallback", NodePools: pools})).To(Succeed())
+		Expect(validatePlacementPolicy(types.PlacementPolicy{OnInsufficientCapacity: "wait"})).To(HaveOccurred())
+		Expect(validatePlacementPolicy(types.PlacementPolicy{OnInsufficientCapacity: "queue", MaxPendingRunners: -1})).To(HaveOccurred())
+		Expect(validatePlacementPolicy(types.PlacementPolicy{OnInsufficientCapacity: "fallback", NodePools: pools[:1]})).To(HaveOccurred())
+		Expect(validatePlacementPolicy(types.PlacementPolicy{OnInsufficientCapacity: "queue", NodePools: []types.NodePool{{Name: "general"}}})).To(HaveOccurred())
+		Expect(validatePlacementPolicy(types.PlacementPolicy{OnInsufficientCapacity: "queue", NodePools: []types.NodePool{pools[0], pools[0]}})).To(HaveOccurred())
+	})
+
+	It("Should queue or reject when quota has no headroom", func() {
+		K8sClient = fake.NewSimpleClientset(quota("2", "1800m"))
+
+		decision, err := admitSession(ctx, "team-a", nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(decision.Outcome).To(Equal(types.AdmissionQueued))
+		Expect(decision.Reason).To(ContainSubstring("resource quota compute"))
+
+		decision, err = admitSession(ctx, "team-a", &types.PlacementPolicy{OnInsufficientCapacity: types.PlacementReject})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(decision.Outcome).To(Equal(types.AdmissionRejected))
+
+		K8sClient = fake.NewSimpleClientset(quota("2", "1"))
+		decision, err = admitSession(ctx, "team-a", nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(decision.Outcome).To(Equal(types.AdmissionAdmitted))
+	})
+
+	It("Should fall back to the next node pool when runners are waiting", func() {
+		scheduled := unscheduledRunner("s3-runner", "burst")
+		scheduled.Spec.NodeName = "node-1"
+		K8sClient = fake.NewSimpleClientset(
+			unscheduledRunner("s1-runner", "general"),
+			unscheduledRunner("s2-runner", "general"),
+			scheduled,
+		)
+
+		policy := &types.PlacementPolicy{OnInsufficientCapacity: types.PlacementFallback, MaxPendingRunners: 2, NodePools: pools}
+		decision, err := admitSession(ctx, "team-a", policy)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(decision.Outcome).To(Equal(types.AdmissionAdmitted))
+		Expect(decision.NodePool).To(Equal("burst"))
+
+		policy.OnInsufficientCapacity = types.PlacementQueue
+		decision, err = admitSession(ctx, "team-a", policy)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(decision.Outcome).To(Equal(types.AdmissionQueued))
+		Expect(decision.Reason).To(ContainSubstring("pool general"))
+	})
+
+	It("Should replace caller-supplied admission metadata", func() {
+		metadata := map[string]interface{}{
+			"labels":      map[string]interface{}{admissionLabel: "none", "team": "a"},
+			"annotations": map[string]interface{}{nodePoolAnnotation: "gpu"},
+		}
+		applyAdmission(metadata, types.AdmissionDecision{Outcome: types.AdmissionAdmitted})
+		Expect(metadata["labels"]).To(Equal(map[string]interface{}{"team": "a"}))
+		Expect(metadata).NotTo(HaveKey("annotations"))
+
+		metadata = map[string]interface{}{}
+		applyAdmission(metadata, types.AdmissionDecision{Outcome: types.AdmissionQueued, Reason: "full"})
+		Expect(metadata["labels"]).To(HaveKeyWithValue(admissionLabel, admissionQueued))
+		Expect(metadata["annotations"]).To(HaveKeyWithValue(admissionReasonAnnotation, "full"))
+	})
+
+	It("Should queue non-interactive sessions when the project is out of capacity", func() {
+		K8sClient = fake.NewSimpleClientset(quota("2", "1800m"))
+		DynamicClient = dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+			map[schema.GroupVersionResource]string{
+				gvr:                          "AgenticSessionList",
+				GetProjectSettingsResource(): "ProjectSettingsList",
+			},
+		)
+
+		repo := map[string]interface{}{"url": "https://github.com/org/app", "branch": "main"}
+		Expect(createTriggeredSession(ctx, "team-a", "webhook-1", "push", "Fix it", repo,
+			map[string]interface{}{"ambient-code.io/trigger": "github-push"}, map[string]interface{}{})).To(Succeed())
+		Expect(CreateWorkflowStageSession(ctx, "team-a", WorkflowStageSession{Name: "wf-1-spec-1", Prompt: "Write the spec"})).To(Succeed())
+
+		for _, name := range []string{"webhook-1", "wf-1-spec-1"} {
+			session, err := DynamicClient.Resource(gvr).Namespace("team-a").Get(ctx, name, v1.GetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(session.GetLabels()).To(HaveKeyWithValue(admissionLabel, admissionQueued), name)
+			Expect(session.GetAnnotations()).To(HaveKeyWithValue(admissionReasonAnnotation, ContainSubstring("resource quota compute")), name)
+		}
+		session, _ := DynamicClient.Resource(gvr).Namespace("team-a").Get(ctx, "webhook-1", v1.GetOptions{})
+		Expect(session.GetLabels()).To(HaveKeyWithValue("ambient-code.io/trigger", "github-push"))
+	})
+
+	It("Should admit queued sessions once capacity frees up, oldest first", func() {
+		queued := func(name string, created int64) *unstructured.Unstructured {
+			obj := &unstructured.Unstructured{Object: map[string]interface{}{
+				"apiVersion": "vteam.ambient-code/v1alpha1",
+				"kind":       "AgenticSession",
+				"metadata": map[string]interface{}{
+					"name":        name,
+					"namespace":   "team-a",
+					"labels":      map[string]interface{}{admissionLabel: admissionQueued},
+					"annotations": map[string]interface{}{admissionReasonAnnotation: "full"},
+				},
+				"status": map[string]interface{}{"phase": "Pending"},
+			}}
+			obj.SetCreationTimestamp(v1.Unix(created, 0))
+			return obj
+		}
+		K8sClient = fake.NewSimpleClientset()
+		DynamicClient = dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+			map[schema.GroupVersionResource]string{
+				gvr:                          "AgenticSessionList",
+				GetProjectSettingsResource(): "ProjectSettingsList",
+			},
+			queued("newer", 200),
+			queued("older", 100),
+		)
+
+		processAdmissionQueue(ctx)
+
+		older, err := DynamicClient.Resource(gvr).Namespace("team-a").Get(ctx, "older", v1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(older.GetLabels()).NotTo(HaveKey(admissionLabel))
+		Expect(older.GetAnnotations()).NotTo(HaveKey(admissionReasonAnnotation))
+
+		// One session per project per pass
+		newer, err := DynamicClient.Resource(gvr).Namespace("team-a").Get(ctx, "newer", v1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(newer.GetLabels()).To(HaveKeyWithValue(admissionLabel, admissionQueued))
+	})
+})
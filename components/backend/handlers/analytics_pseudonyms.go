@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"sync"
+)
+
+// analyticsPseudonymSecretName holds the key pseudonyms in analytics exports are derived from
+const analyticsPseudonymSecretName = "ambient-analytics-pseudonym-key"
+
+var (
+	analyticsPseudonymKeyMu sync.Mutex
+	analyticsPseudonymKey   []byte
+)
+
+// AnalyticsPseudonymizer returns a function mapping an identifier of the given kind
+// (e.g. "u" for users, "s" for sessions) to a stable pseudonym such as "u-3f9a1c0b2d4e".
+// Pseudonyms are keyed with a random key kept in a Secret of the backend namespace, so
+// they are the same across exports and replicas but can't be reversed by hashing
+// guessed names.
+func AnalyticsPseudonymizer(ctx context.Context) (func(kind, value string) string, error) {
+	analyticsPseudonymKeyMu.Lock()
+	defer analyticsPseudonymKeyMu.Unlock()
+	if analyticsPseudonymKey == nil {
+		key, err := loadOrCreateKeySecret(ctx, analyticsPseudonymSecretName)
+		if err != nil {
+			return nil, err
+		}
+		analyticsPseudonymKey = key
+	}
+	key := analyticsPseudonymKey
+	return func(kind, value string) string {
+		if value == "" {
+			return ""
+		}
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(kind + ":" + value))
+		return kind + "-" + hex.EncodeToString(mac.Sum(nil)[:6])
+	}, nil
+}
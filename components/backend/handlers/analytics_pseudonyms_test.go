@@ -0,0 +1,55 @@
+//go:build test
+
+package handlers
+
+import (
+	"context"
+
+	test_constants "ambient-code-backend/tests/constants"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+var _ = Describe("Analytics Pseudonyms", Label(test_constants.LabelUnit, test_constants.LabelHandlers), func() {
+	var (
+		originalK8sClient kubernetes.Interface
+		originalNamespace string
+	)
+
+	BeforeEach(func() {
+		originalK8sClient = K8sClient
+		originalNamespace = Namespace
+		Namespace = "ambient-code"
+		K8sClient = fake.NewSimpleClientset()
+		analyticsPseudonymKey = nil
+	})
+
+	AfterEach(func() {
+		K8sClient = originalK8sClient
+		Namespace = originalNamespace
+		analyticsPseudonymKey = nil
+	})
+
+	It("Should map identifiers to stable keyed pseudonyms", func() {
+		pseudonym, err := AnalyticsPseudonymizer(context.Background())
+		Expect(err).NotTo(HaveOccurred())
+
+		user := pseudonym("u", "alice@example.com")
+		Expect(user).To(MatchRegexp(`^u-[0-9a-f]{12}$`))
+		Expect(pseudonym("u", "alice@example.com")).To(Equal(user))
+		Expect(pseudonym("s", "alice@example.com")).NotTo(Equal("s" + user[1:]))
+		Expect(pseudonym("u", "")).To(BeEmpty())
+
+		// The key is persisted, so another replica derives the same pseudonyms
+		_, err = K8sClient.CoreV1().Secrets(Namespace).Get(context.Background(), analyticsPseudonymSecretName, v1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		analyticsPseudonymKey = nil
+		again, err := AnalyticsPseudonymizer(context.Background())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(again("u", "alice@example.com")).To(Equal(user))
+	})
+})
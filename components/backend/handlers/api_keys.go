@@ -0,0 +1,547 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"ambient-code-backend/logging"
+
+	"github.com/gin-gonic/gin"
+	authnv1 "k8s.io/api/authentication/v1"
+	authv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+)
+
+// API keys let scripts and CI call the API as a user without a browser login.
+// A key is "vtk_<id>_<secret>"; only its SHA-256 is stored, in a cluster-level
+// Secret keyed by id. Requests made with a key run as the key's owner via
+// Kubernetes impersonation, so they are subject to the owner's RBAC. Only the
+// username is impersonated: groups are not copied into the key, so removing the
+// owner from a group takes effect for their keys too.
+const (
+	APIKeyPrefix = "vtk_"
+
+	apiKeysSecretName = "ambient-api-keys"
+	apiKeyContextKey  = "apiKey"
+	maxAPIKeysPerUser = 20
+	maxAPIKeyTTLDays  = 365
+	// defaultAPIKeyTTLDays applies when a key is created without expiresInDays
+	defaultAPIKeyTTLDays = 90
+	// apiKeyLastUsedResolution throttles last-used writes to the key Secret
+	apiKeyLastUsedResolution = 5 * time.Minute
+	// apiKeyLastUsedAnnotationPrefix prefixes the key Secret annotation holding a
+	// key's last use, so recording it patches one field instead of the key records
+	apiKeyLastUsedAnnotationPrefix = "ambient-code.io/last-used."
+)
+
+// APIKey is a stored API key record
+type APIKey struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	UserID string `json:"userId"`
+	// Username is the owner's Kubernetes user, impersonated for key requests
+	Username   string     `json:"username"`
+	Project    string     `json:"project,omitempty"` // optional project scope
+	Hash       string     `json:"hash"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	ExpiresAt  *time.Time `json:"expiresAt,omitempty"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+}
+
+// apiKeyInfo is the API representation of a key; the hash is never returned
+type apiKeyInfo struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	Project    string `json:"project,omitempty"`
+	CreatedAt  string `json:"createdAt"`
+	ExpiresAt  string `json:"expiresAt,omitempty"`
+	LastUsedAt string `json:"lastUsedAt,omitempty"`
+}
+
+func (k *APIKey) info() apiKeyInfo {
+	out := apiKeyInfo{ID: k.ID, Name: k.Name, Project: k.Project, CreatedAt: k.CreatedAt.Format(time.RFC3339)}
+	if k.ExpiresAt != nil {
+		out.ExpiresAt = k.ExpiresAt.Format(time.RFC3339)
+	} else {
+		out.ExpiresAt = k.CreatedAt.Add(maxAPIKeyTTLDays * 24 * time.Hour).Format(time.RFC3339)
+	}
+	if k.LastUsedAt != nil {
+		out.LastUsedAt = k.LastUsedAt.Format(time.RFC3339)
+	}
+	return out
+}
+
+// expired reports whether the key has expired. Keys stored without an expiry expire
+// after the maximum TTL.
+func (k *APIKey) expired(now time.Time) bool {
+	if k.ExpiresAt == nil {
+		return now.After(k.CreatedAt.Add(maxAPIKeyTTLDays * 24 * time.Hour))
+	}
+	return now.After(*k.ExpiresAt)
+}
+
+// applyLastUsed sets the key's LastUsedAt from the key Secret's annotations
+func (k *APIKey) applyLastUsed(annotations map[string]string) {
+	t, err := time.Parse(time.RFC3339, annotations[apiKeyLastUsedAnnotationPrefix+k.ID])
+	if err != nil {
+		return
+	}
+	if k.LastUsedAt == nil || t.After(*k.LastUsedAt) {
+		k.LastUsedAt = &t
+	}
+}
+
+// generateAPIKey returns a new key id and the full key token
+func generateAPIKey() (string, string, error) {
+	idBytes := make([]byte, 8)
+	secret := make([]byte, 32)
+	if _, err := rand.Read(idBytes); err != nil {
+		return "", "", err
+	}
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", err
+	}
+	id := hex.EncodeToString(idBytes)
+	return id, APIKeyPrefix + id + "_" + base64.RawURLEncoding.EncodeToString(secret), nil
+}
+
+// parseAPIKeyID extracts the key id from a key token
+func parseAPIKeyID(token string) (string, bool) {
+	if !strings.HasPrefix(token, APIKeyPrefix) {
+		return "", false
+	}
+	parts := strings.SplitN(strings.TrimPrefix(token, APIKeyPrefix), "_", 2)
+	if len(parts) != 2 || len(parts[0]) != 16 || parts[1] == "" {
+		return "", false
+	}
+	if _, err := hex.DecodeString(parts[0]); err != nil {
+		return "", false
+	}
+	return parts[0], true
+}
+
+func hashAPIKey(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// loadAPIKeys reads all stored API keys
+func loadAPIKeys(ctx context.Context) (map[string]*APIKey, error) {
+	keys := map[string]*APIKey{}
+	secret, err := K8sClient.CoreV1().Secrets(Namespace).Get(ctx, apiKeysSecretName, v1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return keys, nil
+		}
+		return nil, err
+	}
+	for id, raw := range secret.Data {
+		var k APIKey
+		if err := json.Unmarshal(raw, &k); err != nil {
+			logging.Warnf(ctx, "Skipping unreadable API key record %s: %v", id, err)
+			continue
+		}
+		k.applyLastUsed(secret.Annotations)
+		keys[id] = &k
+	}
+	return keys, nil
+}
+
+// updateAPIKeys applies mutate to the stored keys and persists the result,
+// retrying on update conflicts
+func updateAPIKeys(ctx context.Context, mutate func(keys map[string]*APIKey) error) error {
+	for i := 0; i < 3; i++ { // retry on conflict
+		secret, err := K8sClient.CoreV1().Secrets(Namespace).Get(ctx, apiKeysSecretName, v1.GetOptions{})
+		if err != nil {
+			if !errors.IsNotFound(err) {
+				return fmt.Errorf("failed to get Secret: %w", err)
+			}
+			secret = &corev1.Secret{
+				ObjectMeta: v1.ObjectMeta{
+					Name:      apiKeysSecretName,
+					Namespace: Namespace,
+					Labels:    map[string]string{"app": "ambient-code"},
+				},
+				Type: corev1.SecretTypeOpaque,
+				Data: map[string][]byte{},
+			}
+			if _, cerr := K8sClient.CoreV1().Secrets(Namespace).Create(ctx, secret, v1.CreateOptions{}); cerr != nil && !errors.IsAlreadyExists(cerr) {
+				return fmt.Errorf("failed to create Secret: %w", cerr)
+			}
+			if secret, err = K8sClient.CoreV1().Secrets(Namespace).Get(ctx, apiKeysSecretName, v1.GetOptions{}); err != nil {
+				return fmt.Errorf("failed to fetch Secret after create: %w", err)
+			}
+		}
+
+		keys := map[string]*APIKey{}
+		for id, raw := range secret.Data {
+			var k APIKey
+			if err := json.Unmarshal(raw, &k); err == nil {
+				keys[id] = &k
+			}
+		}
+		if err := mutate(keys); err != nil {
+			return err
+		}
+		secret.Data = map[string][]byte{}
+		for id, k := range keys {
+			b, err := json.Marshal(k)
+			if err != nil {
+				return fmt.Errorf("failed to marshal API key: %w", err)
+			}
+			secret.Data[id] = b
+		}
+		// Last-use annotations go with their keys
+		for name := range secret.Annotations {
+			if id, ok := strings.CutPrefix(name, apiKeyLastUsedAnnotationPrefix); ok && keys[id] == nil {
+				delete(secret.Annotations, name)
+			}
+		}
+
+		if _, uerr := K8sClient.CoreV1().Secrets(Namespace).Update(ctx, secret, v1.UpdateOptions{}); uerr != nil {
+			if errors.IsConflict(uerr) {
+				continue // retry
+			}
+			return fmt.Errorf("failed to update Secret: %w", uerr)
+		}
+		return nil
+	}
+	return fmt.Errorf("failed to update Secret after retries")
+}
+
+// lookupAPIKey returns the stored key matching token, or nil if it is unknown,
+// revoked or expired
+func lookupAPIKey(ctx context.Context, token string) (*APIKey, error) {
+	id, ok := parseAPIKeyID(token)
+	if !ok {
+		return nil, nil
+	}
+	secret, err := K8sClient.CoreV1().Secrets(Namespace).Get(ctx, apiKeysSecretName, v1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	raw := secret.Data[id]
+	if len(raw) == 0 {
+		return nil, nil
+	}
+	var k APIKey
+	if err := json.Unmarshal(raw, &k); err != nil {
+		return nil, fmt.Errorf("failed to parse API key: %w", err)
+	}
+	if subtle.ConstantTimeCompare([]byte(k.Hash), []byte(hashAPIKey(token))) != 1 {
+		return nil, nil
+	}
+	if k.expired(time.Now()) {
+		return nil, nil
+	}
+	k.applyLastUsed(secret.Annotations)
+	return &k, nil
+}
+
+// touchAPIKey records key usage, at most once per apiKeyLastUsedResolution, by
+// patching the key's last-used annotation on the key Secret. Best-effort.
+func touchAPIKey(key *APIKey) {
+	if key.LastUsedAt != nil && time.Since(*key.LastUsedAt) < apiKeyLastUsedResolution {
+		return
+	}
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]string{apiKeyLastUsedAnnotationPrefix + key.ID: time.Now().UTC().Format(time.RFC3339)},
+		},
+	})
+	if err != nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	_, err = K8sClient.CoreV1().Secrets(Namespace).Patch(ctx, apiKeysSecretName, k8stypes.MergePatchType, patch, v1.PatchOptions{})
+	if err != nil {
+		logging.Errorf(context.Background(), "Failed to update last-used for API key %s: %v", key.ID, err)
+	}
+}
+
+// apiKeyIdentityHeaders are proxy identity headers replaced by the key owner's identity
+var apiKeyIdentityHeaders = []string{
+	"X-Forwarded-User",
+	"X-Forwarded-Preferred-Username",
+	"X-Forwarded-Email",
+	"X-Forwarded-Groups",
+	"X-Forwarded-Access-Token",
+}
+
+// APIKeyAuth authenticates requests bearing an API key. Other tokens pass through
+// untouched to the normal Kubernetes token path.
+//
+// Key requests get the owner's identity in context. Project-scoped keys may only
+// call /api/projects/<project>/... routes, and keys can never manage API keys.
+func APIKeyAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token, _, _, _ := extractRequestToken(c)
+		if !strings.HasPrefix(token, APIKeyPrefix) {
+			c.Next()
+			return
+		}
+		if K8sClient == nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Kubernetes client not initialized"})
+			c.Abort()
+			return
+		}
+
+		key, err := lookupAPIKey(c.Request.Context(), token)
+		if err != nil {
+			logging.Errorf(c, "API key lookup failed: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to validate API key"})
+			c.Abort()
+			return
+		}
+		if key == nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired API key"})
+			c.Abort()
+			return
+		}
+
+		if strings.HasPrefix(c.FullPath(), "/api/api-keys") {
+			c.JSON(http.StatusForbidden, gin.H{"error": "API keys cannot be managed with an API key"})
+			c.Abort()
+			return
+		}
+		// MCP tool calls are re-dispatched through the router, where scope is checked per call
+		mcpEndpoint := strings.HasPrefix(c.FullPath(), "/api/mcp")
+		if key.Project != "" && !mcpEndpoint && c.Param("projectName") != key.Project {
+			c.JSON(http.StatusForbidden, gin.H{"error": fmt.Sprintf("API key is scoped to project %s", key.Project)})
+			c.Abort()
+			return
+		}
+
+		// Identity comes from the key, never from headers sent alongside it
+		for _, h := range apiKeyIdentityHeaders {
+			c.Request.Header.Del(h)
+		}
+		c.Set("userID", key.UserID)
+		c.Set("userIDOriginal", key.Username)
+		c.Set("userName", key.Username)
+		c.Set(apiKeyContextKey, key)
+
+		go touchAPIKey(key)
+		c.Next()
+	}
+}
+
+// apiKeyK8sClients builds clients that impersonate the key's owner by username alone
+func apiKeyK8sClients(key *APIKey) (kubernetes.Interface, dynamic.Interface) {
+	return impersonatedK8sClients("API key "+key.ID, key.Username, nil)
+}
+
+// callerKubernetesIdentity resolves the caller's Kubernetes username and groups,
+// preferring a SelfSubjectReview over proxy headers
+func callerKubernetesIdentity(c *gin.Context, reqK8s kubernetes.Interface) (string, []string) {
+	review, err := reqK8s.AuthenticationV1().SelfSubjectReviews().Create(c.Request.Context(), &authnv1.SelfSubjectReview{}, v1.CreateOptions{})
+	if err == nil && review.Status.UserInfo.Username != "" {
+		return review.Status.UserInfo.Username, review.Status.UserInfo.Groups
+	}
+	username := c.GetString("userIDOriginal")
+	if username == "" {
+		username = c.GetString("userID")
+	}
+	var groups []string
+	if v, ok := c.Get("userGroups"); ok {
+		groups, _ = v.([]string)
+	}
+	return username, groups
+}
+
+// ListAPIKeys handles GET /api/api-keys
+// Lists the caller's API keys (metadata only)
+func ListAPIKeys(c *gin.Context) {
+	reqK8s, _ := GetK8sClientsForRequest(c)
+	if reqK8s == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing token"})
+		return
+	}
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User authentication required"})
+		return
+	}
+
+	keys, err := loadAPIKeys(c.Request.Context())
+	if err != nil {
+		logging.Errorf(c, "Failed to load API keys: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list API keys"})
+		return
+	}
+	items := []apiKeyInfo{}
+	for _, k := range keys {
+		if k.UserID == userID {
+			items = append(items, k.info())
+		}
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].CreatedAt > items[j].CreatedAt })
+	c.JSON(http.StatusOK, gin.H{"items": items})
+}
+
+// CreateAPIKey handles POST /api/api-keys
+// Creates an API key bound to the caller, optionally scoped to one project.
+// The key is returned only in this response.
+func CreateAPIKey(c *gin.Context) {
+	reqK8s, _ := GetK8sClientsForRequest(c)
+	if reqK8s == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing token"})
+		return
+	}
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User authentication required"})
+		return
+	}
+	if !isValidUserID(userID) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user identifier"})
+		return
+	}
+
+	var req struct {
+		Name          string `json:"name" binding:"required"`
+		Project       string `json:"project"`
+		ExpiresInDays int    `json:"expiresInDays"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" || len(req.Name) > 64 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "name must be 1-64 characters"})
+		return
+	}
+	if req.ExpiresInDays == 0 {
+		req.ExpiresInDays = defaultAPIKeyTTLDays
+	}
+	if req.ExpiresInDays < 1 || req.ExpiresInDays > maxAPIKeyTTLDays {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("expiresInDays must be between 1 and %d", maxAPIKeyTTLDays)})
+		return
+	}
+	if req.Project != "" {
+		if !isValidKubernetesName(req.Project) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project name format"})
+			return
+		}
+		// The caller must be able to use the project they scope the key to
+		allowed, err := CheckAccessForRequest(c, reqK8s, authv1.ResourceAttributes{
+			Group:     "vteam.ambient-code",
+			Resource:  "agenticsessions",
+			Verb:      "list",
+			Namespace: req.Project,
+		})
+		if err != nil || !allowed {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Unauthorized to access project"})
+			return
+		}
+	}
+
+	username, _ := callerKubernetesIdentity(c, reqK8s)
+	if username == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User authentication required"})
+		return
+	}
+
+	id, token, err := generateAPIKey()
+	if err != nil {
+		logging.Errorf(c, "Failed to generate API key: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create API key"})
+		return
+	}
+	key := &APIKey{
+		ID:        id,
+		Name:      req.Name,
+		UserID:    userID,
+		Username:  username,
+		Project:   req.Project,
+		Hash:      hashAPIKey(token),
+		CreatedAt: time.Now().UTC(),
+	}
+	exp := key.CreatedAt.Add(time.Duration(req.ExpiresInDays) * 24 * time.Hour)
+	key.ExpiresAt = &exp
+
+	errTooManyKeys := fmt.Errorf("a user may have at most %d API keys", maxAPIKeysPerUser)
+	err = updateAPIKeys(c.Request.Context(), func(keys map[string]*APIKey) error {
+		count := 0
+		for _, k := range keys {
+			if k.UserID == userID {
+				count++
+			}
+		}
+		if count >= maxAPIKeysPerUser {
+			return errTooManyKeys
+		}
+		keys[id] = key
+		return nil
+	})
+	if err != nil {
+		if err == errTooManyKeys {
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		logging.Errorf(c, "Failed to store API key for user %s: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create API key"})
+		return
+	}
+
+	logging.Infof(c, "Created API key %s for user %s (project scope: %q)", id, userID, req.Project)
+	c.JSON(http.StatusCreated, gin.H{"key": token, "apiKey": key.info()})
+}
+
+// RevokeAPIKey handles DELETE /api/api-keys/:keyId
+// Revokes one of the caller's API keys
+func RevokeAPIKey(c *gin.Context) {
+	reqK8s, _ := GetK8sClientsForRequest(c)
+	if reqK8s == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing token"})
+		return
+	}
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User authentication required"})
+		return
+	}
+	keyID := c.Param("keyId")
+
+	errNotFound := fmt.Errorf("API key not found")
+	err := updateAPIKeys(c.Request.Context(), func(keys map[string]*APIKey) error {
+		// Keys of other users are reported as missing, not forbidden
+		if k, ok := keys[keyID]; !ok || k.UserID != userID {
+			return errNotFound
+		}
+		delete(keys, keyID)
+		return nil
+	})
+	if err != nil {
+		if err == errNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		logging.Errorf(c, "Failed to revoke API key %s for user %s: %v", keyID, userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to revoke API key"})
+		return
+	}
+
+	logging.Infof(c, "Revoked API key %s for user %s", keyID, userID)
+	c.JSON(http.StatusOK, gin.H{"message": "API key revoked"})
+}
@@ -0,0 +1,168 @@
+//go:build test
+
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	test_constants "ambient-code-backend/tests/constants"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+var _ = Describe("API Keys", Label(test_constants.LabelUnit, test_constants.LabelHandlers, test_constants.LabelMiddleware), func() {
+	var (
+		originalK8sClient kubernetes.Interface
+		originalNamespace string
+	)
+
+	BeforeEach(func() {
+		originalK8sClient = K8sClient
+		originalNamespace = Namespace
+		K8sClient = fake.NewSimpleClientset()
+		Namespace = "ambient-code"
+	})
+
+	AfterEach(func() {
+		K8sClient = originalK8sClient
+		Namespace = originalNamespace
+	})
+
+	storeKey := func(key *APIKey) {
+		Expect(updateAPIKeys(context.Background(), func(keys map[string]*APIKey) error {
+			keys[key.ID] = key
+			return nil
+		})).To(Succeed())
+	}
+
+	newKey := func(project string) (*APIKey, string) {
+		id, token, err := generateAPIKey()
+		Expect(err).NotTo(HaveOccurred())
+		// Recently used, so the middleware does not write last-used in the background
+		now := time.Now()
+		key := &APIKey{ID: id, Name: "ci", UserID: "alice", Username: "alice", Project: project, Hash: hashAPIKey(token), CreatedAt: now, LastUsedAt: &now}
+		storeKey(key)
+		return key, token
+	}
+
+	It("Should generate keys whose id can be parsed back", func() {
+		id, token, err := generateAPIKey()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(token).To(HavePrefix(APIKeyPrefix))
+		parsed, ok := parseAPIKeyID(token)
+		Expect(ok).To(BeTrue())
+		Expect(parsed).To(Equal(id))
+
+		_, ok = parseAPIKeyID("vtk_short_secret")
+		Expect(ok).To(BeFalse())
+		_, ok = parseAPIKeyID("sha256~openshift-token")
+		Expect(ok).To(BeFalse())
+	})
+
+	It("Should store only the hash and resolve the key by its token", func() {
+		key, token := newKey("")
+
+		secret, err := K8sClient.CoreV1().Secrets(Namespace).Get(context.Background(), apiKeysSecretName, v1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(secret.Data[key.ID])).NotTo(ContainSubstring(token))
+
+		found, err := lookupAPIKey(context.Background(), token)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(found).NotTo(BeNil())
+		Expect(found.UserID).To(Equal("alice"))
+
+		// Same id, wrong secret
+		found, err = lookupAPIKey(context.Background(), APIKeyPrefix+key.ID+"_wrong")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(found).To(BeNil())
+	})
+
+	It("Should reject expired keys", func() {
+		key, token := newKey("")
+		past := time.Now().Add(-time.Hour)
+		key.ExpiresAt = &past
+		storeKey(key)
+
+		found, err := lookupAPIKey(context.Background(), token)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(found).To(BeNil())
+	})
+
+	It("Should expire keys stored without an expiry after the maximum TTL", func() {
+		key, token := newKey("")
+		key.CreatedAt = time.Now().Add(-(maxAPIKeyTTLDays + 1) * 24 * time.Hour)
+		storeKey(key)
+
+		found, err := lookupAPIKey(context.Background(), token)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(found).To(BeNil())
+	})
+
+	It("Should record last use on an annotation without rewriting the key records", func() {
+		key, token := newKey("")
+		key.LastUsedAt = nil
+		storeKey(key)
+		before, err := K8sClient.CoreV1().Secrets(Namespace).Get(context.Background(), apiKeysSecretName, v1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+
+		touchAPIKey(key)
+		after, err := K8sClient.CoreV1().Secrets(Namespace).Get(context.Background(), apiKeysSecretName, v1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(after.Data).To(Equal(before.Data))
+		Expect(after.Annotations).To(HaveKey(apiKeyLastUsedAnnotationPrefix + key.ID))
+
+		found, err := lookupAPIKey(context.Background(), token)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(found.LastUsedAt).NotTo(BeNil())
+
+		// Revoking the key drops its annotation
+		Expect(updateAPIKeys(context.Background(), func(keys map[string]*APIKey) error {
+			delete(keys, key.ID)
+			return nil
+		})).To(Succeed())
+		after, err = K8sClient.CoreV1().Secrets(Namespace).Get(context.Background(), apiKeysSecretName, v1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(after.Annotations).NotTo(HaveKey(apiKeyLastUsedAnnotationPrefix + key.ID))
+	})
+
+	It("Should enforce project scope and owner identity in the middleware", func() {
+		_, token := newKey("team-a")
+
+		r := gin.New()
+		api := r.Group("/api", APIKeyAuth())
+		api.GET("/projects/:projectName/whoami", func(c *gin.Context) {
+			c.String(http.StatusOK, c.GetString("userID"))
+		})
+		api.GET("/api-keys", func(c *gin.Context) { c.Status(http.StatusOK) })
+
+		call := func(path string, header map[string]string) *httptest.ResponseRecorder {
+			req := httptest.NewRequest(http.MethodGet, path, nil)
+			for k, v := range header {
+				req.Header.Set(k, v)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			return w
+		}
+		auth := map[string]string{"Authorization": "Bearer " + token, "X-Forwarded-User": "mallory"}
+
+		w := call("/api/projects/team-a/whoami", auth)
+		Expect(w.Code).To(Equal(http.StatusOK))
+		Expect(w.Body.String()).To(Equal("alice"))
+
+		Expect(call("/api/projects/team-b/whoami", auth).Code).To(Equal(http.StatusForbidden))
+		Expect(call("/api/api-keys", auth).Code).To(Equal(http.StatusForbidden))
+		Expect(call("/api/projects/team-a/whoami", map[string]string{"Authorization": "Bearer " + token + "x"}).Code).To(Equal(http.StatusUnauthorized))
+
+		// Non-key tokens pass through untouched
+		Expect(call("/api/projects/team-b/whoami", map[string]string{"Authorization": "Bearer sha256~abc"}).Code).To(Equal(http.StatusOK))
+	})
+})
@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"ambient-code-backend/audit"
+	"ambient-code-backend/logging"
+
+	"github.com/gin-gonic/gin"
+)
+
+// isMutatingMethod reports whether an HTTP method changes state and must be audited
+func isMutatingMethod(method string) bool {
+	switch method {
+	case http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// AuditLog records every mutating request (user, verb, route, project, outcome,
+// latency, request ID) to the configured audit sinks after it completes
+func AuditLog() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !isMutatingMethod(c.Request.Method) {
+			c.Next()
+			return
+		}
+		start := time.Now()
+		requestID := RequestID(c)
+		c.Next()
+
+		resource := c.FullPath()
+		if resource == "" {
+			resource = "unmatched"
+		}
+		record := audit.Record{
+			Time:      start.UTC(),
+			RequestID: requestID,
+			User:      c.GetString("userIDOriginal"),
+			Verb:      c.Request.Method,
+			Resource:  resource,
+			Path:      c.Request.URL.Path,
+			Project:   c.Param("projectName"),
+			Status:    c.Writer.Status(),
+			Outcome:   audit.OutcomeForStatus(c.Writer.Status()),
+			LatencyMs: time.Since(start).Milliseconds(),
+			ClientIP:  c.ClientIP(),
+		}
+		if record.User == "" {
+			record.User = c.GetString("userID")
+		}
+		if path := c.GetString(auditPathContextKey); path != "" {
+			record.Path = path
+		}
+		if v, ok := c.Get(apiKeyContextKey); ok {
+			if key, ok := v.(*APIKey); ok {
+				record.APIKeyID = key.ID
+			}
+		}
+		if v, ok := c.Get(sessionTokenContextKey); ok {
+			if claims, ok := v.(*SessionTokenClaims); ok {
+				record.TokenID = claims.ID
+			}
+		}
+		audit.Emit(record)
+	}
+}
+
+// QueryAuditLog returns recent audit records, newest first. Cluster admins only.
+// GET /api/admin/audit?user=&project=&verb=&outcome=&since=RFC3339&until=RFC3339&limit=100
+func QueryAuditLog(c *gin.Context) {
+	filter := audit.Filter{
+		User:    c.Query("user"),
+		Project: c.Query("project"),
+		Verb:    c.Query("verb"),
+		Outcome: c.Query("outcome"),
+	}
+	for param, dst := range map[string]*time.Time{"since": &filter.Since, "until": &filter.Until} {
+		if v := c.Query(param); v != "" {
+			t, err := time.Parse(time.RFC3339, v)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": param + " must be an RFC3339 timestamp"})
+				return
+			}
+			*dst = t
+		}
+	}
+	if v := c.Query("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 || n > 1000 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 1000"})
+			return
+		}
+		filter.Limit = n
+	}
+
+	records, err := audit.Query(filter)
+	if err != nil {
+		logging.Errorf(c, "Failed to query audit log: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read audit log"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": records})
+}
@@ -0,0 +1,82 @@
+//go:build test
+
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+
+	"ambient-code-backend/audit"
+	"ambient-code-backend/logging"
+	test_constants "ambient-code-backend/tests/constants"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Audit Logging", Label(test_constants.LabelUnit, test_constants.LabelHandlers, test_constants.LabelMiddleware), func() {
+	var router *gin.Engine
+
+	BeforeEach(func() {
+		Expect(audit.Configure("file", filepath.Join(GinkgoT().TempDir(), "audit.jsonl"), "", "")).To(Succeed())
+		DeferCleanup(audit.Configure, "", "", "", "")
+
+		router = gin.New()
+		router.Use(AuditLog())
+		setUser := func(c *gin.Context) { c.Set("userID", "alice") }
+		router.POST("/api/projects/:projectName/things", setUser, func(c *gin.Context) {
+			c.Status(http.StatusCreated)
+		})
+		router.DELETE("/api/projects/:projectName/things/:name", setUser, func(c *gin.Context) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Unauthorized"})
+		})
+		router.GET("/api/projects/:projectName/things", func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})
+	})
+
+	serve := func(method, path string, header map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		for k, v := range header {
+			req.Header.Set(k, v)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	It("Should record mutating requests with outcome and request ID", func() {
+		w := serve(http.MethodPost, "/api/projects/team-a/things", map[string]string{logging.RequestIDHeader: "req-123"})
+		Expect(w.Header().Get(logging.RequestIDHeader)).To(Equal("req-123"))
+		serve(http.MethodDelete, "/api/projects/team-a/things/x", nil)
+
+		records, err := audit.Query(audit.Filter{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(records).To(HaveLen(2))
+
+		Expect(records[0].Verb).To(Equal(http.MethodDelete))
+		Expect(records[0].Outcome).To(Equal(audit.OutcomeDenied))
+		Expect(records[0].RequestID).NotTo(BeEmpty())
+
+		Expect(records[1].RequestID).To(Equal("req-123"))
+		Expect(records[1].User).To(Equal("alice"))
+		Expect(records[1].Project).To(Equal("team-a"))
+		Expect(records[1].Resource).To(Equal("/api/projects/:projectName/things"))
+		Expect(records[1].Status).To(Equal(http.StatusCreated))
+		Expect(records[1].Outcome).To(Equal(audit.OutcomeSuccess))
+	})
+
+	It("Should not record reads", func() {
+		serve(http.MethodGet, "/api/projects/team-a/things", nil)
+		records, err := audit.Query(audit.Filter{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(records).To(BeEmpty())
+	})
+
+	It("Should replace malformed request IDs", func() {
+		w := serve(http.MethodPost, "/api/projects/team-a/things", map[string]string{logging.RequestIDHeader: "bad id!"})
+		Expect(w.Header().Get(logging.RequestIDHeader)).To(MatchRegexp(`^[0-9a-f]{16}$`))
+	})
+})
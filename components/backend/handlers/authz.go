@@ -0,0 +1,313 @@
+package handlers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"ambient-code-backend/logging"
+
+	"github.com/gin-gonic/gin"
+	authv1 "k8s.io/api/authorization/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// SelfSubjectAccessReview results are cached briefly per caller so streaming and
+// polling endpoints do not issue an SSAR per request. Only allowed results are
+// cached, so newly granted access applies immediately; any 403 returned to a
+// caller drops their cached results so revoked access applies on the next request.
+const (
+	accessCacheTTL        = 30 * time.Second
+	accessCacheMaxEntries = 10000
+)
+
+type accessCacheEntry struct {
+	caller  string
+	expires time.Time
+}
+
+var (
+	accessCacheMu sync.Mutex
+	accessCache   = map[string]accessCacheEntry{}
+)
+
+// AccessCaller identifies the caller of a request for access caching: the API key
+// id, or a hash of the bearer token. Returns "" when there is no token.
+func AccessCaller(c *gin.Context) string {
+	if v, ok := c.Get(apiKeyContextKey); ok {
+		if key, ok := v.(*APIKey); ok {
+			return "apikey:" + key.ID
+		}
+	}
+	token, _, _, _ := extractRequestToken(c)
+	if token == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(token))
+	return "token:" + hex.EncodeToString(sum[:])
+}
+
+func accessCacheKey(caller string, attrs authv1.ResourceAttributes) string {
+	return strings.Join([]string{caller, attrs.Group, attrs.Resource, attrs.Subresource, attrs.Verb, attrs.Namespace, attrs.Name}, "|")
+}
+
+// CheckAccess runs a SelfSubjectAccessReview with k8s, reusing a recent allowed
+// result for the same caller and attributes. An empty caller disables caching.
+func CheckAccess(ctx context.Context, k8s kubernetes.Interface, caller string, attrs authv1.ResourceAttributes) (bool, error) {
+	key := accessCacheKey(caller, attrs)
+	if caller != "" {
+		accessCacheMu.Lock()
+		entry, ok := accessCache[key]
+		accessCacheMu.Unlock()
+		if ok && time.Now().Before(entry.expires) {
+			return true, nil
+		}
+	}
+
+	ssar := &authv1.SelfSubjectAccessReview{
+		Spec: authv1.SelfSubjectAccessReviewSpec{ResourceAttributes: &attrs},
+	}
+	res, err := k8s.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, ssar, v1.CreateOptions{})
+	if err != nil {
+		return false, err
+	}
+	if caller == "" {
+		return res.Status.Allowed, nil
+	}
+	if !res.Status.Allowed {
+		InvalidateAccessCache(caller)
+		return false, nil
+	}
+
+	now := time.Now()
+	accessCacheMu.Lock()
+	if len(accessCache) >= accessCacheMaxEntries {
+		for k, e := range accessCache {
+			if now.After(e.expires) {
+				delete(accessCache, k)
+			}
+		}
+	}
+	if len(accessCache) < accessCacheMaxEntries {
+		accessCache[key] = accessCacheEntry{caller: caller, expires: now.Add(accessCacheTTL)}
+	}
+	accessCacheMu.Unlock()
+	return true, nil
+}
+
+// CheckAccessForRequest is CheckAccess for the caller of c
+func CheckAccessForRequest(c *gin.Context, k8s kubernetes.Interface, attrs authv1.ResourceAttributes) (bool, error) {
+	return CheckAccess(c.Request.Context(), k8s, AccessCaller(c), attrs)
+}
+
+// InvalidateAccessCache drops all cached access results for caller
+func InvalidateAccessCache(caller string) {
+	if caller == "" {
+		return
+	}
+	accessCacheMu.Lock()
+	defer accessCacheMu.Unlock()
+	for k, e := range accessCache {
+		if e.caller == caller {
+			delete(accessCache, k)
+		}
+	}
+}
+
+// AccessCacheBuster drops the caller's cached access results whenever a request
+// ends in 403, e.g. when the API server denies an operation after RBAC changed
+func AccessCacheBuster() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+		if c.Writer.Status() == http.StatusForbidden {
+			InvalidateAccessCache(AccessCaller(c))
+		}
+	}
+}
+
+// sessionAccessContextKey holds the verb RequireSessionAccess granted on the session
+const sessionAccessContextKey = "sessionAccess"
+
+// TranscriptsSubresource is the AgenticSession subresource guarding conversation
+// content. RBAC grants it as agenticsessions/transcripts with verb get, separately
+// from update, so callers can be allowed to trigger runs without reading transcripts.
+const TranscriptsSubresource = "transcripts"
+
+// ObserveSubresource is the AgenticSession subresource guarding the live event stream
+// of .../agui/observe. The observer role grants it without transcripts or update, so
+// observers can watch runs but not send messages, interrupt them or fetch credentials.
+const ObserveSubresource = "observe"
+
+// RequireSessionAccess authorizes the caller for verb on the AgenticSession named by
+// the :sessionName route param. It validates the route params, authenticates the
+// caller and runs a (cached) SSAR, so session routes registered behind it cannot
+// skip authorization. Handlers can read the granted verb with SessionAccessVerb.
+func RequireSessionAccess(verb string) gin.HandlerFunc {
+	return requireSessionAccess(verb, "")
+}
+
+// RequireTranscriptAccess authorizes reading the session's persisted and live
+// conversation content: get on its transcripts subresource
+func RequireTranscriptAccess() gin.HandlerFunc {
+	return requireSessionAccess("get", TranscriptsSubresource)
+}
+
+// RequireObserveAccess authorizes watching the session's live events: get on its
+// observe subresource
+func RequireObserveAccess() gin.HandlerFunc {
+	return requireSessionAccess("get", ObserveSubresource)
+}
+
+// callerCanReadTranscripts reports whether the caller may read the transcripts of the
+// named session, or of every session in the project when sessionName is "". For
+// responses that include conversation content only for such callers.
+func callerCanReadTranscripts(c *gin.Context, project, sessionName string) bool {
+	reqK8s, _ := GetK8sClientsForRequest(c)
+	if reqK8s == nil {
+		return false
+	}
+	allowed, err := CheckAccessForRequest(c, reqK8s, authv1.ResourceAttributes{
+		Group:       "vteam.ambient-code",
+		Resource:    "agenticsessions",
+		Subresource: TranscriptsSubresource,
+		Verb:        "get",
+		Namespace:   project,
+		Name:        sessionName,
+	})
+	if err != nil {
+		logging.Warnf(c, "Transcript access check failed for %s/%s: %v", project, sessionName, err)
+		return false
+	}
+	return allowed
+}
+
+func requireSessionAccess(verb, subresource string) gin.HandlerFunc {
+	resource := "agenticsessions"
+	if subresource != "" {
+		resource += "/" + subresource
+	}
+	return func(c *gin.Context) {
+		project := c.Param("projectName")
+		sessionName := c.Param("sessionName")
+		if !isValidKubernetesName(project) || !isValidKubernetesName(sessionName) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project or session name"})
+			c.Abort()
+			return
+		}
+
+		reqK8s, _ := GetK8sClientsForRequest(c)
+		if reqK8s == nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing token"})
+			c.Abort()
+			return
+		}
+
+		allowed, err := CheckAccessForRequest(c, reqK8s, authv1.ResourceAttributes{
+			Group:       "vteam.ambient-code",
+			Resource:    "agenticsessions",
+			Subresource: subresource,
+			Verb:        verb,
+			Namespace:   project,
+			Name:        sessionName,
+		})
+		if err != nil {
+			logging.Errorf(c, "RequireSessionAccess: SSAR failed for %s %s %s/%s: %v", verb, resource, project, sessionName, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify permissions"})
+			c.Abort()
+			return
+		}
+		if !allowed {
+			logging.Warnf(c, "RequireSessionAccess: caller not allowed to %s %s %s/%s", verb, resource, project, sessionName)
+			switch subresource {
+			case TranscriptsSubresource:
+				c.JSON(http.StatusForbidden, gin.H{"error": "Transcript access required"})
+			case ObserveSubresource:
+				c.JSON(http.StatusForbidden, gin.H{"error": "Observer access required"})
+			default:
+				c.JSON(http.StatusForbidden, gin.H{"error": "Unauthorized"})
+			}
+			c.Abort()
+			return
+		}
+
+		if subresource == "" {
+			c.Set(sessionAccessContextKey, verb)
+		}
+		c.Next()
+	}
+}
+
+// SessionAccessVerb returns the most recent verb RequireSessionAccess granted for
+// this request, or "" when the route is not behind it
+func SessionAccessVerb(c *gin.Context) string {
+	return c.GetString(sessionAccessContextKey)
+}
+
+// RequireProjectAdmin allows only callers who may manage the project's RoleBindings,
+// the same test AccessCheck uses to report the admin role
+func RequireProjectAdmin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		project := c.Param("projectName")
+		reqK8s, _ := GetK8sClientsForRequest(c)
+		if reqK8s == nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing token"})
+			c.Abort()
+			return
+		}
+
+		allowed, err := CheckAccessForRequest(c, reqK8s, authv1.ResourceAttributes{
+			Group:     "rbac.authorization.k8s.io",
+			Resource:  "rolebindings",
+			Verb:      "create",
+			Namespace: project,
+		})
+		if err != nil {
+			logging.Errorf(c, "RequireProjectAdmin: SSAR failed for project %s: %v", project, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify permissions"})
+			c.Abort()
+			return
+		}
+		if !allowed {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Project admin access required"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireClusterAdmin allows only callers who may perform any verb on any resource
+// cluster-wide, i.e. cluster-admin
+func RequireClusterAdmin() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		reqK8s, _ := GetK8sClientsForRequest(c)
+		if reqK8s == nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing token"})
+			c.Abort()
+			return
+		}
+
+		allowed, err := CheckAccessForRequest(c, reqK8s, authv1.ResourceAttributes{
+			Group:    "*",
+			Resource: "*",
+			Verb:     "*",
+		})
+		if err != nil {
+			logging.Errorf(c, "RequireClusterAdmin: SSAR failed: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify permissions"})
+			c.Abort()
+			return
+		}
+		if !allowed {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Cluster admin access required"})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
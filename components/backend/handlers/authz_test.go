@@ -0,0 +1,179 @@
+//go:build test
+
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+
+	test_constants "ambient-code-backend/tests/constants"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	authv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+var _ = Describe("Access Review Cache", Label(test_constants.LabelUnit, test_constants.LabelHandlers, test_constants.LabelMiddleware), func() {
+	var (
+		client  *fake.Clientset
+		allowed bool
+		reviews int
+	)
+	attrs := authv1.ResourceAttributes{Group: "vteam.ambient-code", Resource: "agenticsessions", Verb: "get", Namespace: "team-a", Name: "s1"}
+
+	BeforeEach(func() {
+		allowed, reviews = true, 0
+		client = fake.NewSimpleClientset()
+		client.PrependReactor("create", "selfsubjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			reviews++
+			return true, &authv1.SelfSubjectAccessReview{Status: authv1.SubjectAccessReviewStatus{Allowed: allowed}}, nil
+		})
+	})
+
+	It("Should reuse allowed results per caller and attributes", func() {
+		ctx := context.Background()
+		for i := 0; i < 3; i++ {
+			ok, err := CheckAccess(ctx, client, "token:alice", attrs)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ok).To(BeTrue())
+		}
+		Expect(reviews).To(Equal(1))
+
+		// Different caller or attributes are reviewed separately
+		_, _ = CheckAccess(ctx, client, "token:bob", attrs)
+		other := attrs
+		other.Verb = "update"
+		_, _ = CheckAccess(ctx, client, "token:alice", other)
+		Expect(reviews).To(Equal(3))
+
+		// No caller disables caching
+		_, _ = CheckAccess(ctx, client, "", attrs)
+		_, _ = CheckAccess(ctx, client, "", attrs)
+		Expect(reviews).To(Equal(5))
+	})
+
+	It("Should not cache denials and should drop the caller's results on invalidation", func() {
+		ctx := context.Background()
+		_, _ = CheckAccess(ctx, client, "token:alice", attrs)
+		InvalidateAccessCache("token:alice")
+
+		allowed = false
+		ok, err := CheckAccess(ctx, client, "token:alice", attrs)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ok).To(BeFalse())
+
+		allowed = true
+		ok, _ = CheckAccess(ctx, client, "token:alice", attrs)
+		Expect(ok).To(BeTrue())
+		Expect(reviews).To(Equal(3))
+	})
+})
+
+var _ = Describe("RequireSessionAccess", Label(test_constants.LabelUnit, test_constants.LabelHandlers, test_constants.LabelMiddleware), func() {
+	var (
+		originalK8sClientMw   kubernetes.Interface
+		originalDynamicClient dynamic.Interface
+		router                *gin.Engine
+		reviewed              []authv1.ResourceAttributes
+		allowedVerbs          map[string]bool
+	)
+
+	BeforeEach(func() {
+		originalK8sClientMw = K8sClientMw
+		originalDynamicClient = DynamicClient
+		reviewed = nil
+		allowedVerbs = map[string]bool{"get": true}
+
+		client := fake.NewSimpleClientset()
+		client.PrependReactor("create", "selfsubjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			ssar := action.(k8stesting.CreateAction).GetObject().(*authv1.SelfSubjectAccessReview)
+			attrs := *ssar.Spec.ResourceAttributes
+			reviewed = append(reviewed, attrs)
+			verb := attrs.Verb
+			if attrs.Subresource != "" {
+				verb += " " + attrs.Subresource
+			}
+			return true, &authv1.SelfSubjectAccessReview{Status: authv1.SubjectAccessReviewStatus{Allowed: allowedVerbs[verb]}}, nil
+		})
+		K8sClientMw = client
+		DynamicClient = dynamicfake.NewSimpleDynamicClient(runtime.NewScheme())
+
+		router = gin.New()
+		session := router.Group("/projects/:projectName/agentic-sessions/:sessionName", RequireSessionAccess("get"))
+		session.GET("", func(c *gin.Context) { c.String(http.StatusOK, SessionAccessVerb(c)) })
+		session.POST("/stop", RequireSessionAccess("update"), func(c *gin.Context) { c.String(http.StatusOK, SessionAccessVerb(c)) })
+		session.GET("/agui/events", RequireTranscriptAccess(), func(c *gin.Context) { c.String(http.StatusOK, SessionAccessVerb(c)) })
+		session.GET("/agui/observe", RequireObserveAccess(), func(c *gin.Context) { c.String(http.StatusOK, SessionAccessVerb(c)) })
+	})
+
+	AfterEach(func() {
+		K8sClientMw = originalK8sClientMw
+		DynamicClient = originalDynamicClient
+	})
+
+	call := func(method, path, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	It("Should review the named session and record the granted verb", func() {
+		w := call(http.MethodGet, "/projects/team-a/agentic-sessions/s1", "alice")
+		Expect(w.Code).To(Equal(http.StatusOK))
+		Expect(w.Body.String()).To(Equal("get"))
+		Expect(reviewed).To(HaveLen(1))
+		Expect(reviewed[0].Namespace).To(Equal("team-a"))
+		Expect(reviewed[0].Name).To(Equal("s1"))
+	})
+
+	It("Should require every verb in the chain", func() {
+		Expect(call(http.MethodPost, "/projects/team-a/agentic-sessions/s1/stop", "alice").Code).To(Equal(http.StatusForbidden))
+
+		allowedVerbs["update"] = true
+		w := call(http.MethodPost, "/projects/team-a/agentic-sessions/s1/stop", "alice")
+		Expect(w.Code).To(Equal(http.StatusOK))
+		Expect(w.Body.String()).To(Equal("update"))
+	})
+
+	It("Should require transcript access separately from update", func() {
+		allowedVerbs["update"] = true
+		Expect(call(http.MethodGet, "/projects/team-a/agentic-sessions/s1/agui/events", "alice").Code).To(Equal(http.StatusForbidden))
+		Expect(reviewed[len(reviewed)-1].Subresource).To(Equal(TranscriptsSubresource))
+
+		allowedVerbs["get "+TranscriptsSubresource] = true
+		w := call(http.MethodGet, "/projects/team-a/agentic-sessions/s1/agui/events", "alice")
+		Expect(w.Code).To(Equal(http.StatusOK))
+		Expect(w.Body.String()).To(Equal("get"))
+	})
+
+	It("Should let observers watch live events without transcripts or update", func() {
+		allowedVerbs["get "+ObserveSubresource] = true
+		Expect(call(http.MethodGet, "/projects/team-a/agentic-sessions/s1/agui/observe", "alice").Code).To(Equal(http.StatusOK))
+		Expect(reviewed[len(reviewed)-1].Subresource).To(Equal(ObserveSubresource))
+		Expect(call(http.MethodGet, "/projects/team-a/agentic-sessions/s1/agui/events", "alice").Code).To(Equal(http.StatusForbidden))
+		Expect(call(http.MethodPost, "/projects/team-a/agentic-sessions/s1/stop", "alice").Code).To(Equal(http.StatusForbidden))
+
+		delete(allowedVerbs, "get "+ObserveSubresource)
+		w := call(http.MethodGet, "/projects/team-b/agentic-sessions/s1/agui/observe", "alice")
+		Expect(w.Code).To(Equal(http.StatusForbidden))
+		Expect(w.Body.String()).To(ContainSubstring("Observer access required"))
+	})
+
+	It("Should reject invalid names and missing tokens before any review", func() {
+		Expect(call(http.MethodGet, "/projects/team-a/agentic-sessions/Bad_Name", "alice").Code).To(Equal(http.StatusBadRequest))
+		Expect(call(http.MethodGet, "/projects/team-a/agentic-sessions/s1", "").Code).To(Equal(http.StatusUnauthorized))
+		Expect(reviewed).To(BeEmpty())
+	})
+})
@@ -118,6 +118,13 @@ var _ = AfterSuite(func() {
 	logger.Log("=== Suite Cleanup Complete ===")
 })
 
+// Cached SSAR results must not carry over between specs that change SSAR behavior
+var _ = BeforeEach(func() {
+	accessCacheMu.Lock()
+	accessCache = map[string]accessCacheEntry{}
+	accessCacheMu.Unlock()
+})
+
 // ReportAfterEach captures test failures and logs following KFP pattern
 var _ = ReportAfterEach(func(specReport SpecReport) {
 	if specReport.Failed() {
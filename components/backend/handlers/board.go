@@ -0,0 +1,227 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+
+	"ambient-code-backend/logging"
+	"ambient-code-backend/types"
+
+	"github.com/gin-gonic/gin"
+	"k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+)
+
+const (
+	// boardStageLabel holds the session's board stage; a label so clients can select
+	// a column directly
+	boardStageLabel = "ambient-code.io/board-stage"
+	// boardRankAnnotation orders sessions within a column, lowest first. Ranks are
+	// spaced so a move usually re-ranks only the moved session.
+	boardRankAnnotation = "ambient-code.io/board-rank"
+	boardRankSpacing    = 1024
+)
+
+// boardEntry is a session in a board column
+type boardEntry struct {
+	item   *unstructured.Unstructured
+	rank   float64
+	ranked bool
+}
+
+// boardStage returns the session's stage, the backlog when unset or unknown
+func boardStage(item *unstructured.Unstructured) string {
+	stage := item.GetLabels()[boardStageLabel]
+	if slices.Contains(types.BoardStages, stage) {
+		return stage
+	}
+	return types.BoardStageBacklog
+}
+
+// boardColumns groups sessions by stage in board order: ranked sessions by rank, then
+// sessions never moved on the board, newest first
+func boardColumns(items []unstructured.Unstructured) map[string][]boardEntry {
+	columns := map[string][]boardEntry{}
+	for i := range items {
+		item := &items[i]
+		entry := boardEntry{item: item}
+		if r, ok := item.GetAnnotations()[boardRankAnnotation]; ok {
+			if rank, err := strconv.ParseFloat(r, 64); err == nil {
+				entry.rank, entry.ranked = rank, true
+			}
+		}
+		stage := boardStage(item)
+		columns[stage] = append(columns[stage], entry)
+	}
+	for _, column := range columns {
+		sort.SliceStable(column, func(i, j int) bool {
+			a, b := column[i], column[j]
+			if a.ranked != b.ranked {
+				return a.ranked
+			}
+			if a.ranked && a.rank != b.rank {
+				return a.rank < b.rank
+			}
+			ta, tb := a.item.GetCreationTimestamp(), b.item.GetCreationTimestamp()
+			if !ta.Equal(&tb) {
+				return tb.Before(&ta)
+			}
+			return a.item.GetName() < b.item.GetName()
+		})
+	}
+	return columns
+}
+
+// insertRank returns the rank that places a session at position in column, or false
+// when the column must be re-ranked first: it has unranked sessions or no gap left
+func insertRank(column []boardEntry, position int) (float64, bool) {
+	for _, e := range column {
+		if !e.ranked {
+			return 0, false
+		}
+	}
+	switch {
+	case len(column) == 0:
+		return boardRankSpacing, true
+	case position == 0:
+		return column[0].rank - boardRankSpacing, true
+	case position == len(column):
+		return column[len(column)-1].rank + boardRankSpacing, true
+	}
+	lo, hi := column[position-1].rank, column[position].rank
+	mid := lo + (hi-lo)/2
+	return mid, lo < mid && mid < hi
+}
+
+// patchBoardPosition sets the session's stage and rank
+func patchBoardPosition(ctx context.Context, dyn dynamic.Interface, project, sessionName, stage string, rank float64) (*unstructured.Unstructured, error) {
+	patch, _ := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"labels":      map[string]string{boardStageLabel: stage},
+			"annotations": map[string]string{boardRankAnnotation: strconv.FormatFloat(rank, 'f', -1, 64)},
+		},
+	})
+	return dyn.Resource(GetAgenticSessionV1Alpha1Resource()).Namespace(project).Patch(ctx, sessionName, k8stypes.MergePatchType, patch, v1.PatchOptions{})
+}
+
+// GetSessionBoard returns the project's sessions grouped into board columns
+// GET /api/projects/:projectName/board
+func GetSessionBoard(c *gin.Context) {
+	project := c.GetString("project")
+	_, k8sDyn := GetK8sClientsForRequest(c)
+	if k8sDyn == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing token"})
+		c.Abort()
+		return
+	}
+
+	list, err := k8sDyn.Resource(GetAgenticSessionV1Alpha1Resource()).Namespace(project).List(c.Request.Context(), v1.ListOptions{})
+	if err != nil {
+		logging.Errorf(c, "GetSessionBoard: failed to list sessions in %s: %v", project, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list agentic sessions"})
+		return
+	}
+
+	columns := boardColumns(list.Items)
+	board := types.SessionBoard{Columns: make([]types.BoardColumn, 0, len(types.BoardStages))}
+	for _, stage := range types.BoardStages {
+		column := types.BoardColumn{Stage: stage, Sessions: []types.AgenticSession{}}
+		for _, e := range columns[stage] {
+			column.Sessions = append(column.Sessions, sessionFromItem(c, e.item))
+		}
+		board.Columns = append(board.Columns, column)
+	}
+	c.JSON(http.StatusOK, board)
+}
+
+// MoveSessionOnBoard moves a session to a board column and position, as when it is
+// dragged on the board
+// PUT /api/projects/:projectName/agentic-sessions/:sessionName/board
+func MoveSessionOnBoard(c *gin.Context) {
+	project := c.GetString("project")
+	sessionName := c.Param("sessionName")
+	_, k8sDyn := GetK8sClientsForRequest(c)
+	if k8sDyn == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing token"})
+		c.Abort()
+		return
+	}
+
+	var req types.MoveOnBoardRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "stage is required"})
+		return
+	}
+	if !slices.Contains(types.BoardStages, req.Stage) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "stage must be one of backlog, in-progress, review, done"})
+		return
+	}
+	if req.Position != nil && *req.Position < 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "position must not be negative"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	list, err := k8sDyn.Resource(GetAgenticSessionV1Alpha1Resource()).Namespace(project).List(ctx, v1.ListOptions{})
+	if err != nil {
+		logging.Errorf(c, "MoveSessionOnBoard: failed to list sessions in %s: %v", project, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list agentic sessions"})
+		return
+	}
+	found := false
+	column := []boardEntry{}
+	for _, e := range boardColumns(list.Items)[req.Stage] {
+		if e.item.GetName() == sessionName {
+			found = true
+			continue
+		}
+		column = append(column, e)
+	}
+	if !found && !slices.ContainsFunc(list.Items, func(item unstructured.Unstructured) bool { return item.GetName() == sessionName }) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+		return
+	}
+
+	position := len(column)
+	if req.Position != nil && *req.Position < position {
+		position = *req.Position
+	}
+	rank, ok := insertRank(column, position)
+	if !ok {
+		// Re-rank the column around the moved session, evenly spaced
+		for i, e := range column {
+			want := float64(i+1) * boardRankSpacing
+			if i >= position {
+				want += boardRankSpacing
+			}
+			if e.ranked && e.rank == want {
+				continue
+			}
+			if _, err := patchBoardPosition(ctx, k8sDyn, project, e.item.GetName(), req.Stage, want); err != nil {
+				logging.Errorf(c, "MoveSessionOnBoard: failed to re-rank %s/%s: %v", project, e.item.GetName(), err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update the board"})
+				return
+			}
+		}
+		rank = float64(position+1) * boardRankSpacing
+	}
+
+	updated, err := patchBoardPosition(ctx, k8sDyn, project, sessionName, req.Stage, rank)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+			return
+		}
+		logging.Errorf(c, "MoveSessionOnBoard: failed to move %s/%s: %v", project, sessionName, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update the board"})
+		return
+	}
+	c.JSON(http.StatusOK, sessionFromItem(c, updated))
+}
@@ -0,0 +1,140 @@
+//go:build test
+
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	test_constants "ambient-code-backend/tests/constants"
+	"ambient-code-backend/types"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+var _ = Describe("Session Board", Label(test_constants.LabelUnit, test_constants.LabelHandlers), func() {
+	gvr := schema.GroupVersionResource{Group: "vteam.ambient-code", Version: "v1alpha1", Resource: "agenticsessions"}
+	created := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+
+	var (
+		originalK8sClientMw   kubernetes.Interface
+		originalDynamicClient dynamic.Interface
+		originalGVR           func() schema.GroupVersionResource
+	)
+
+	session := func(name string, age time.Duration, stage, rank string) *unstructured.Unstructured {
+		obj := &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "vteam.ambient-code/v1alpha1",
+			"kind":       "AgenticSession",
+			"metadata":   map[string]interface{}{"name": name, "namespace": "team-a"},
+			"spec":       map[string]interface{}{"displayName": name},
+		}}
+		obj.SetCreationTimestamp(v1.NewTime(created.Add(-age)))
+		if stage != "" {
+			obj.SetLabels(map[string]string{boardStageLabel: stage})
+		}
+		if rank != "" {
+			obj.SetAnnotations(map[string]string{boardRankAnnotation: rank})
+		}
+		return obj
+	}
+	request := func(method, sessionName, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(method, "/", strings.NewReader(body))
+		c.Request.Header.Set("Authorization", "Bearer test-token")
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Set("project", "team-a")
+		c.Params = gin.Params{{Key: "sessionName", Value: sessionName}}
+		if method == http.MethodGet {
+			GetSessionBoard(c)
+		} else {
+			MoveSessionOnBoard(c)
+		}
+		return w
+	}
+	board := func() map[string][]string {
+		w := request(http.MethodGet, "", "")
+		Expect(w.Code).To(Equal(http.StatusOK))
+		var resp types.SessionBoard
+		Expect(json.Unmarshal(w.Body.Bytes(), &resp)).To(Succeed())
+		columns := map[string][]string{}
+		for _, column := range resp.Columns {
+			columns[column.Stage] = []string{}
+			for _, s := range column.Sessions {
+				columns[column.Stage] = append(columns[column.Stage], s.Metadata["name"].(string))
+			}
+		}
+		return columns
+	}
+
+	BeforeEach(func() {
+		originalK8sClientMw, originalDynamicClient, originalGVR = K8sClientMw, DynamicClient, GetAgenticSessionV1Alpha1Resource
+		GetAgenticSessionV1Alpha1Resource = func() schema.GroupVersionResource { return gvr }
+		K8sClientMw = fake.NewSimpleClientset()
+		DynamicClient = dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+			map[schema.GroupVersionResource]string{gvr: "AgenticSessionList"},
+			session("new", time.Hour, "", ""),
+			session("old", 3*time.Hour, "", ""),
+			session("ranked", 2*time.Hour, "", "1024"),
+			session("unknown-stage", 4*time.Hour, "someday", ""),
+			session("building", time.Hour, types.BoardStageInProgress, "2048"),
+			session("first", time.Hour, types.BoardStageInProgress, "1024"),
+		)
+		gin.SetMode(gin.TestMode)
+	})
+
+	AfterEach(func() {
+		K8sClientMw, DynamicClient, GetAgenticSessionV1Alpha1Resource = originalK8sClientMw, originalDynamicClient, originalGVR
+	})
+
+	It("Should group sessions by stage, ranked first then newest first", func() {
+		Expect(board()).To(Equal(map[string][]string{
+			types.BoardStageBacklog:    {"ranked", "new", "old", "unknown-stage"},
+			types.BoardStageInProgress: {"first", "building"},
+			types.BoardStageReview:     {},
+			types.BoardStageDone:       {},
+		}))
+	})
+
+	It("Should move a session between ranked sessions without re-ranking them", func() {
+		w := request(http.MethodPut, "new", `{"stage": "in-progress", "position": 1}`)
+		Expect(w.Code).To(Equal(http.StatusOK))
+		Expect(board()[types.BoardStageInProgress]).To(Equal([]string{"first", "new", "building"}))
+
+		obj, err := DynamicClient.Resource(gvr).Namespace("team-a").Get(context.Background(), "new", v1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(obj.GetAnnotations()).To(HaveKeyWithValue(boardRankAnnotation, "1536"))
+		Expect(obj.GetLabels()).To(HaveKeyWithValue(boardStageLabel, types.BoardStageInProgress))
+	})
+
+	It("Should re-rank a column with unranked sessions when moving within it", func() {
+		w := request(http.MethodPut, "old", `{"stage": "backlog", "position": 0}`)
+		Expect(w.Code).To(Equal(http.StatusOK))
+		Expect(board()[types.BoardStageBacklog]).To(Equal([]string{"old", "ranked", "new", "unknown-stage"}))
+
+		// Moving to the end needs no position
+		Expect(request(http.MethodPut, "ranked", `{"stage": "backlog"}`).Code).To(Equal(http.StatusOK))
+		Expect(board()[types.BoardStageBacklog]).To(Equal([]string{"old", "new", "unknown-stage", "ranked"}))
+	})
+
+	It("Should reject unknown stages and sessions", func() {
+		Expect(request(http.MethodPut, "new", `{"stage": "someday"}`).Code).To(Equal(http.StatusBadRequest))
+		Expect(request(http.MethodPut, "new", `{"stage": "done", "position": -1}`).Code).To(Equal(http.StatusBadRequest))
+		Expect(request(http.MethodPut, "missing", `{"stage": "done"}`).Code).To(Equal(http.StatusNotFound))
+	})
+})
@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"ambient-code-backend/git"
+	"ambient-code-backend/logging"
+	"ambient-code-backend/types"
+
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/util/retry"
+)
+
+// Package-level git dependency (mockable in tests)
+var PublishSessionCheck = git.PublishSessionCheck
+
+// githubPRURLPattern extracts the repository and number from a GitHub PR web URL
+var githubPRURLPattern = regexp.MustCompile(`^(https://github\.com/[^/]+/[^/]+)/pull/(\d+)`)
+
+// ReportRunCheck mirrors a run's status onto the GitHub PRs linked to the session:
+// PRs opened via git/pull-requests and the PR whose comment triggered the session.
+// runStatus is the AG-UI run status: running, completed, error, or interrupted.
+// Best effort; intended to be called in a goroutine.
+func ReportRunCheck(project, sessionName, runStatus string) {
+	if DynamicClient == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	obj, err := GetCachedSession(ctx, project, sessionName)
+	if err != nil {
+		return
+	}
+
+	prs := linkedGitHubPullRequests(obj)
+	if len(prs) == 0 {
+		return
+	}
+
+	var userID string
+	if uc, found, _ := unstructured.NestedMap(obj.Object, "spec", "userContext"); found {
+		userID, _ = uc["userId"].(string)
+	}
+	if GetGitHubToken == nil {
+		return
+	}
+	token, err := GetGitHubToken(ctx, K8sClient, DynamicClient, project, userID)
+	if err != nil || token == "" {
+		logging.Warnf(context.Background(), "ReportRunCheck: no GitHub credentials for %s/%s: %v", project, sessionName, err)
+		return
+	}
+
+	check := sessionCheckForStatus(runStatus)
+	check.DetailsURL = sessionTranscriptURL(project, sessionName)
+	for _, pr := range prs {
+		c := check
+		c.RepoURL = pr.RepoURL
+		c.PRNumber = pr.Number
+		c.CheckRunID = pr.CheckRunID
+		id, err := PublishSessionCheck(ctx, token, c)
+		if err != nil {
+			logging.Infof(context.Background(), "ReportRunCheck: %s/%s -> %s: %v", project, sessionName, pr.URL, err)
+			continue
+		}
+		if id != 0 && id != pr.CheckRunID {
+			if err := setPullRequestCheckRunID(ctx, project, sessionName, pr, id); err != nil {
+				logging.Errorf(context.Background(), "ReportRunCheck: failed to record check run on %s/%s: %v", project, sessionName, err)
+			}
+		}
+	}
+}
+
+// sessionCheckForStatus maps an AG-UI run status onto check-run state
+func sessionCheckForStatus(runStatus string) git.SessionCheck {
+	switch runStatus {
+	case "completed":
+		return git.SessionCheck{Status: "completed", Conclusion: "success", Title: "Run completed", Summary: "The agent run finished."}
+	case "error":
+		return git.SessionCheck{Status: "completed", Conclusion: "failure", Title: "Run failed", Summary: "The agent run ended with an error. See the transcript for details."}
+	case "interrupted":
+		return git.SessionCheck{Status: "completed", Conclusion: "neutral", Title: "Run interrupted", Summary: "The agent run was interrupted before it finished."}
+	default:
+		return git.SessionCheck{Status: "in_progress", Title: "Run in progress", Summary: "The agent is working on this pull request."}
+	}
+}
+
+// sessionTranscriptURL links to the session page in the UI, when FRONTEND_URL is configured
+func sessionTranscriptURL(project, sessionName string) string {
+	base := strings.TrimSuffix(os.Getenv("FRONTEND_URL"), "/")
+	if base == "" {
+		return ""
+	}
+	return fmt.Sprintf("%s/projects/%s/sessions/%s", base, project, sessionName)
+}
+
+// linkedGitHubPullRequests returns the GitHub PRs recorded in status.pullRequests plus the
+// PR referenced by the webhook trigger annotation, deduplicated by URL.
+func linkedGitHubPullRequests(obj *unstructured.Unstructured) []types.SessionPullRequest {
+	seen := map[string]bool{}
+	prs := []types.SessionPullRequest{}
+
+	if status, ok := obj.Object["status"].(map[string]interface{}); ok {
+		for _, pr := range parseStatus(status).PullRequests {
+			if pr.Provider == string(types.ProviderGitHub) && pr.Number > 0 && !seen[pr.URL] {
+				seen[pr.URL] = true
+				prs = append(prs, pr)
+			}
+		}
+	}
+
+	if m := githubPRURLPattern.FindStringSubmatch(obj.GetAnnotations()["ambient-code.io/trigger-url"]); m != nil {
+		prURL := m[0]
+		if !seen[prURL] {
+			number, _ := strconv.Atoi(m[2])
+			prs = append(prs, types.SessionPullRequest{
+				URL:      prURL,
+				Number:   number,
+				Provider: string(types.ProviderGitHub),
+				RepoURL:  m[1],
+			})
+		}
+	}
+	return prs
+}
+
+// setPullRequestCheckRunID stores the check run ID on the matching status.pullRequests
+// entry, adding the entry when the PR was linked by a webhook trigger.
+func setPullRequestCheckRunID(ctx context.Context, project, sessionName string, pr types.SessionPullRequest, checkRunID int64) error {
+	gvr := GetAgenticSessionV1Alpha1Resource()
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		obj, err := DynamicClient.Resource(gvr).Namespace(project).Get(ctx, sessionName, v1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		prs, _, _ := unstructured.NestedSlice(obj.Object, "status", "pullRequests")
+		found := false
+		for _, existing := range prs {
+			if m, ok := existing.(map[string]interface{}); ok && m["url"] == pr.URL {
+				m["checkRunId"] = checkRunID
+				found = true
+			}
+		}
+		if !found {
+			prs = append(prs, map[string]interface{}{
+				"url":        pr.URL,
+				"number":     int64(pr.Number),
+				"provider":   pr.Provider,
+				"repoUrl":    pr.RepoURL,
+				"checkRunId": checkRunID,
+			})
+		}
+		if err := unstructured.SetNestedSlice(obj.Object, prs, "status", "pullRequests"); err != nil {
+			return err
+		}
+		_, err = DynamicClient.Resource(gvr).Namespace(project).UpdateStatus(ctx, obj, v1.UpdateOptions{})
+		return err
+	})
+}
@@ -0,0 +1,45 @@
+//go:build test
+
+package handlers
+
+import (
+	test_constants "ambient-code-backend/tests/constants"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+var _ = Describe("Session Check Runs", Label(test_constants.LabelUnit, test_constants.LabelHandlers, test_constants.LabelGit), func() {
+	It("Should map run statuses onto check states", func() {
+		Expect(sessionCheckForStatus("running").Status).To(Equal("in_progress"))
+		Expect(sessionCheckForStatus("completed").Conclusion).To(Equal("success"))
+		Expect(sessionCheckForStatus("error").Conclusion).To(Equal("failure"))
+		Expect(sessionCheckForStatus("interrupted").Conclusion).To(Equal("neutral"))
+	})
+
+	It("Should collect GitHub PRs from status and the trigger annotation", func() {
+		obj := &unstructured.Unstructured{Object: map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"name": "session-1",
+				"annotations": map[string]interface{}{
+					"ambient-code.io/trigger-url": "https://github.com/org/app/pull/7#issuecomment-1",
+				},
+			},
+			"status": map[string]interface{}{
+				"pullRequests": []interface{}{
+					map[string]interface{}{"url": "https://github.com/org/app/pull/3", "number": int64(3), "provider": "github", "repoUrl": "https://github.com/org/app", "checkRunId": int64(99)},
+					map[string]interface{}{"url": "https://gitlab.com/org/app/-/merge_requests/4", "number": int64(4), "provider": "gitlab"},
+				},
+			},
+		}}
+
+		prs := linkedGitHubPullRequests(obj)
+		Expect(prs).To(HaveLen(2))
+		Expect(prs[0].Number).To(Equal(3))
+		Expect(prs[0].CheckRunID).To(Equal(int64(99)))
+		Expect(prs[1].URL).To(Equal("https://github.com/org/app/pull/7"))
+		Expect(prs[1].Number).To(Equal(7))
+		Expect(prs[1].RepoURL).To(Equal("https://github.com/org/app"))
+	})
+})
@@ -0,0 +1,278 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+
+	"ambient-code-backend/confluence"
+	"ambient-code-backend/logging"
+	"ambient-code-backend/render"
+	"ambient-code-backend/types"
+
+	"github.com/gin-gonic/gin"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+)
+
+// maxPublishedArtifactBytes bounds the workspace files published to Confluence
+const maxPublishedArtifactBytes = 1 << 20
+
+// confluenceSettings is the ProjectSettings spec.confluence block
+type confluenceSettings struct {
+	// URL is the Confluence site; empty means the publisher's Jira site + /wiki,
+	// which is where Atlassian Cloud serves Confluence
+	URL          string
+	Space        string
+	ParentPageID string
+}
+
+func getConfluenceSettings(ctx context.Context, dynClient dynamic.Interface, project string) (confluenceSettings, error) {
+	settings := confluenceSettings{}
+	obj, err := dynClient.Resource(GetProjectSettingsResource()).Namespace(project).Get(ctx, "projectsettings", v1.GetOptions{})
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			return settings, nil
+		}
+		return settings, err
+	}
+	settings.URL, _, _ = unstructured.NestedString(obj.Object, "spec", "confluence", "url")
+	settings.Space, _, _ = unstructured.NestedString(obj.Object, "spec", "confluence", "space")
+	settings.ParentPageID, _, _ = unstructured.NestedString(obj.Object, "spec", "confluence", "parentPageId")
+	return settings, nil
+}
+
+// confluenceBaseURL is the Confluence site to publish to
+func confluenceBaseURL(settings confluenceSettings, creds *JiraCredentials) string {
+	if settings.URL != "" {
+		return settings.URL
+	}
+	base := strings.TrimRight(creds.URL, "/")
+	if strings.HasSuffix(base, "/wiki") {
+		return base
+	}
+	return base + "/wiki"
+}
+
+// PublishSessionConfluence renders the session's latest run summary or a workspace
+// file as a Confluence page in the project's space (ProjectSettings spec.confluence),
+// with the caller's Atlassian credentials from the Jira integration. The page titled
+// like the output is updated when it exists, so republishing keeps one page current.
+// POST /api/projects/:projectName/agentic-sessions/:sessionName/publish/confluence
+func PublishSessionConfluence(c *gin.Context) {
+	project := c.GetString("project")
+	sessionName := c.Param("sessionName")
+
+	// An empty body publishes the run summary
+	var req types.PublishConfluenceRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+	switch req.Source {
+	case "", "summary":
+		req.Source = "summary"
+	case "artifact":
+		if strings.TrimSpace(req.Path) == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "path is required to publish an artifact"})
+			return
+		}
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": `source must be "summary" or "artifact"`})
+		return
+	}
+
+	_, reqDyn := GetK8sClientsForRequest(c)
+	if reqDyn == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing token"})
+		return
+	}
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User authentication required"})
+		return
+	}
+
+	settings, err := getConfluenceSettings(c.Request.Context(), reqDyn, project)
+	if err != nil {
+		logging.Errorf(c, "PublishSessionConfluence: failed to read settings of %s: %v", project, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read project settings"})
+		return
+	}
+	if settings.Space == "" {
+		c.JSON(http.StatusPreconditionFailed, gin.H{
+			"error": "Confluence publishing is not configured for this project",
+			"hint":  "Set ProjectSettings spec.confluence.space to the key of the space to publish to.",
+		})
+		return
+	}
+
+	session, err := reqDyn.Resource(GetAgenticSessionV1Alpha1Resource()).Namespace(project).Get(c.Request.Context(), sessionName, v1.GetOptions{})
+	if err != nil {
+		if k8serrors.IsNotFound(err) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+			return
+		}
+		logging.Errorf(c, "PublishSessionConfluence: failed to get session %s/%s: %v", project, sessionName, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get session"})
+		return
+	}
+	name, _, _ := unstructured.NestedString(session.Object, "spec", "displayName")
+	if strings.TrimSpace(name) == "" {
+		name = sessionName
+	}
+
+	creds, err := GetJiraCredentials(c.Request.Context(), userID)
+	if err != nil && !k8serrors.IsNotFound(err) {
+		logging.Errorf(c, "Failed to get Jira credentials for user %s: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get Jira credentials"})
+		return
+	}
+	if creds == nil || creds.APIToken == "" {
+		c.JSON(http.StatusPreconditionFailed, gin.H{"error": "Jira is not connected; connect it with your Atlassian account to publish to Confluence"})
+		return
+	}
+
+	var title, body string
+	if req.Source == "summary" {
+		var lastSummary *types.RunSummary
+		if LatestRunSummary != nil {
+			lastSummary = LatestRunSummary(sessionName)
+		}
+		if lastSummary == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Session has no run summary yet"})
+			return
+		}
+		title, body = name+": run summary", render.Markdown(runSummaryMarkdown(lastSummary))
+	} else {
+		relPath, content, ok := readArtifactForPublishing(c, project, sessionName, req.Path)
+		if !ok {
+			return
+		}
+		title = name + ": " + path.Base(relPath)
+		switch ext := strings.ToLower(path.Ext(relPath)); ext {
+		case ".md", ".markdown", "":
+			body = render.Markdown(content)
+		default:
+			body = render.Code(content, strings.TrimPrefix(ext, "."))
+		}
+	}
+	if t := strings.TrimSpace(req.Title); t != "" {
+		title = t
+	}
+	if link := sessionTranscriptURL(project, sessionName); link != "" {
+		body += fmt.Sprintf(`<p>Published from session <a href="%s">%s</a>.</p>`, html.EscapeString(link), html.EscapeString(name))
+	}
+
+	client := confluence.NewClient(confluenceBaseURL(settings, creds), creds.Email, creds.APIToken)
+	ctx := c.Request.Context()
+	var page *confluence.Page
+	if req.PageID != "" {
+		page, err = client.GetPage(ctx, req.PageID)
+		if page != nil && strings.TrimSpace(req.Title) != "" {
+			page.Title = title
+		}
+	} else {
+		page, err = client.FindPage(ctx, settings.Space, title)
+	}
+	created := false
+	if err == nil {
+		if page != nil {
+			page, err = client.UpdatePage(ctx, page, body)
+		} else {
+			page, err = client.CreatePage(ctx, settings.Space, settings.ParentPageID, title, body)
+			created = true
+		}
+	}
+	switch {
+	case err == nil:
+	case errors.Is(err, confluence.ErrUnauthorized):
+		c.JSON(http.StatusPreconditionFailed, gin.H{"error": "Confluence rejected your Atlassian credentials; reconnect Jira"})
+		return
+	case errors.Is(err, confluence.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Confluence space " + settings.Space + " or the page was not found"})
+		return
+	default:
+		logging.Infof(c, "PublishSessionConfluence: failed to publish %s/%s: %v", project, sessionName, err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to publish to Confluence: " + err.Error()})
+		return
+	}
+
+	logging.Infof(c, "PublishSessionConfluence: published %s of %s/%s as page %s", req.Source, project, sessionName, page.ID)
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+	c.JSON(status, gin.H{"page": page, "created": created})
+}
+
+// readArtifactForPublishing reads a workspace file through the session's content
+// service. On failure it writes the response and returns false.
+func readArtifactForPublishing(c *gin.Context, project, sessionName, relPath string) (string, string, bool) {
+	// Cleaning against the root keeps the path inside the workspace
+	p := path.Clean("/" + relPath)
+	if p == "/" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "path must name a file"})
+		return "", "", false
+	}
+	relPath = p[1:]
+
+	authorization := c.GetHeader("Authorization")
+	if strings.TrimSpace(authorization) == "" {
+		authorization = c.GetHeader("X-Forwarded-Access-Token")
+	}
+	status, _, content, err := readSessionWorkspaceFile(c.Request.Context(), project, sessionName, relPath, authorization)
+	switch {
+	case err != nil:
+		logging.Infof(c, "PublishSessionConfluence: content service unreachable for %s/%s: %v", project, sessionName, err)
+		c.JSON(http.StatusConflict, gin.H{"error": "Session is not running. Start the session to publish workspace files."})
+	case status == http.StatusNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": "Workspace file " + relPath + " not found"})
+	case status >= 400:
+		logging.Errorf(c, "PublishSessionConfluence: content service returned %d reading %s", status, relPath)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to read the workspace file"})
+	case len(content) > maxPublishedArtifactBytes:
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("%s is larger than %d bytes", relPath, maxPublishedArtifactBytes)})
+	default:
+		return relPath, string(content), true
+	}
+	return "", "", false
+}
+
+// runSummaryMarkdown formats a run summary for publishing
+func runSummaryMarkdown(summary *types.RunSummary) string {
+	var b strings.Builder
+	b.WriteString(strings.TrimSpace(summary.Summary))
+	b.WriteString("\n")
+	for _, section := range []struct {
+		heading string
+		items   []string
+		code    bool
+	}{
+		{"Decisions", summary.Decisions, false},
+		{"Open questions", summary.OpenQuestions, false},
+		{"Files changed", summary.FilesChanged, true},
+	} {
+		if len(section.items) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "\n## %s\n\n", section.heading)
+		for _, item := range section.items {
+			if section.code {
+				item = "`" + item + "`"
+			}
+			fmt.Fprintf(&b, "- %s\n", item)
+		}
+	}
+	if summary.GeneratedAt != "" {
+		fmt.Fprintf(&b, "\n*Run %s, summarized %s*\n", summary.RunID, summary.GeneratedAt)
+	}
+	return b.String()
+}
@@ -5,7 +5,6 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
-	"log"
 	"net/http"
 	"os"
 	"os/exec"
@@ -16,6 +15,7 @@ import (
 	"time"
 
 	"ambient-code-backend/git"
+	"ambient-code-backend/logging"
 	"ambient-code-backend/pathutil"
 	"ambient-code-backend/types"
 
@@ -38,6 +38,8 @@ var (
 	GitCreateBranch       func(ctx context.Context, repoDir, branchName string) error
 	GitListRemoteBranches func(ctx context.Context, repoDir string) ([]string, error)
 	GitSyncRepo           func(ctx context.Context, repoDir, commitMessage, branch, githubToken string) error
+	GitStatusFiles        func(ctx context.Context, repoDir string) ([]git.FileStatus, error)
+	GitWorkspaceDiff      func(ctx context.Context, repoDir, file string) (*git.FileDiff, error)
 	// GetRemoteURL retrieves the origin remote URL - mockable for testing
 	GetRemoteURL func(ctx context.Context, repoDir string) (string, error)
 )
@@ -99,7 +101,7 @@ func ContentGitPush(c *gin.Context) {
 		Branch        string `json:"branch"`
 	}
 	_ = c.BindJSON(&body)
-	log.Printf("contentGitPush: request received repoPath=%q outputRepoUrl=%q branch=%q commitLen=%d", body.RepoPath, body.OutputRepoURL, body.Branch, len(strings.TrimSpace(body.CommitMessage)))
+	logging.Infof(c, "contentGitPush: request received repoPath=%q outputRepoUrl=%q branch=%q commitLen=%d", body.RepoPath, body.OutputRepoURL, body.Branch, len(strings.TrimSpace(body.CommitMessage)))
 
 	// Require explicit output repo URL and branch from caller
 	if strings.TrimSpace(body.OutputRepoURL) == "" {
@@ -118,16 +120,16 @@ func ContentGitPush(c *gin.Context) {
 
 	// Basic safety: repoDir must be under StateBaseDir
 	if !pathutil.IsPathWithinBase(repoDir, StateBaseDir) && repoDir != StateBaseDir {
-		log.Printf("contentGitPush: invalid repoPath resolved=%q stateBaseDir=%q", repoDir, StateBaseDir)
+		logging.Errorf(c, "contentGitPush: invalid repoPath resolved=%q stateBaseDir=%q", repoDir, StateBaseDir)
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid repoPath"})
 		return
 	}
 
-	log.Printf("contentGitPush: using repoDir=%q (stateBaseDir=%q)", repoDir, StateBaseDir)
+	logging.Debugf(c, "contentGitPush: using repoDir=%q (stateBaseDir=%q)", repoDir, StateBaseDir)
 
 	// Get appropriate token based on repository URL
 	gitToken := getGitTokenForURL(c, body.OutputRepoURL)
-	log.Printf("contentGitPush: tokenHeaderPresent=%t url.host.redacted=%t branch=%q", gitToken != "", strings.HasPrefix(body.OutputRepoURL, "https://"), body.Branch)
+	logging.Debugf(c, "contentGitPush: tokenHeaderPresent=%t url.host.redacted=%t branch=%q", gitToken != "", strings.HasPrefix(body.OutputRepoURL, "https://"), body.Branch)
 
 	// Call refactored git push function
 	out, err := GitPushRepo(c.Request.Context(), repoDir, body.CommitMessage, body.OutputRepoURL, body.Branch, gitToken)
@@ -150,7 +152,7 @@ func ContentGitAbandon(c *gin.Context) {
 		RepoPath string `json:"repoPath"`
 	}
 	_ = c.BindJSON(&body)
-	log.Printf("contentGitAbandon: request repoPath=%q", body.RepoPath)
+	logging.Infof(c, "contentGitAbandon: request repoPath=%q", body.RepoPath)
 
 	repoDir := filepath.Clean(filepath.Join(StateBaseDir, body.RepoPath))
 	if body.RepoPath == "" {
@@ -158,12 +160,12 @@ func ContentGitAbandon(c *gin.Context) {
 	}
 
 	if !pathutil.IsPathWithinBase(repoDir, StateBaseDir) && repoDir != StateBaseDir {
-		log.Printf("contentGitAbandon: invalid repoPath resolved=%q base=%q", repoDir, StateBaseDir)
+		logging.Errorf(c, "contentGitAbandon: invalid repoPath resolved=%q base=%q", repoDir, StateBaseDir)
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid repoPath"})
 		return
 	}
 
-	log.Printf("contentGitAbandon: using repoDir=%q", repoDir)
+	logging.Debugf(c, "contentGitAbandon: using repoDir=%q", repoDir)
 
 	if err := GitAbandonRepo(c.Request.Context(), repoDir); err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
@@ -187,7 +189,7 @@ func ContentGitDiff(c *gin.Context) {
 		return
 	}
 
-	log.Printf("contentGitDiff: repoPath=%q repoDir=%q", repoPath, repoDir)
+	logging.Infof(c, "contentGitDiff: repoPath=%q repoDir=%q", repoPath, repoDir)
 
 	summary, err := GitDiffRepo(c.Request.Context(), repoDir)
 	if err != nil {
@@ -257,7 +259,7 @@ func ContentGitStatus(c *gin.Context) {
 	// Get git status using existing git package
 	summary, err := GitDiffRepo(c.Request.Context(), abs)
 	if err != nil {
-		log.Printf("ContentGitStatus: git diff failed: %v", err)
+		logging.Errorf(c, "ContentGitStatus: git diff failed: %v", err)
 		c.JSON(http.StatusOK, gin.H{
 			"initialized": true,
 			"hasChanges":  false,
@@ -270,7 +272,7 @@ func ContentGitStatus(c *gin.Context) {
 
 	hasChanges := summary.FilesAdded > 0 || summary.FilesRemoved > 0 || summary.TotalAdded > 0 || summary.TotalRemoved > 0
 
-	c.JSON(http.StatusOK, gin.H{
+	resp := gin.H{
 		"initialized":      true,
 		"hasChanges":       hasChanges,
 		"branch":           currentBranch,
@@ -281,7 +283,47 @@ func ContentGitStatus(c *gin.Context) {
 		"uncommittedFiles": summary.FilesAdded + summary.FilesRemoved,
 		"totalAdded":       summary.TotalAdded,
 		"totalRemoved":     summary.TotalRemoved,
-	})
+	}
+
+	// Per-file status lets reviewers see exactly what the agent touched
+	if GitStatusFiles != nil {
+		if files, err := GitStatusFiles(c.Request.Context(), abs); err == nil {
+			resp["files"] = files
+			resp["hasChanges"] = hasChanges || len(files) > 0
+		} else {
+			logging.Errorf(c, "ContentGitStatus: git status failed: %v", err)
+		}
+	}
+
+	c.JSON(http.StatusOK, resp)
+}
+
+// ContentGitWorkspaceDiff handles GET /content/git-diff?path=&file=
+// Returns the unified diff of the repo at path against HEAD, optionally for a single file.
+func ContentGitWorkspaceDiff(c *gin.Context) {
+	path := filepath.Clean("/" + strings.TrimSpace(c.Query("path")))
+	abs := filepath.Join(StateBaseDir, path)
+	if !pathutil.IsPathWithinBase(abs, StateBaseDir) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid path"})
+		return
+	}
+	if _, err := os.Stat(filepath.Join(abs, ".git")); err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "not a git repository"})
+		return
+	}
+	if GitWorkspaceDiff == nil {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "diff not available"})
+		return
+	}
+
+	file := strings.TrimSpace(c.Query("file"))
+	diff, err := GitWorkspaceDiff(c.Request.Context(), abs, file)
+	if err != nil {
+		logging.Infof(c, "ContentGitWorkspaceDiff: path=%q file=%q: %v", path, file, err)
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, diff)
 }
 
 // ContentGitConfigureRemote handles POST /content/git-configure-remote
@@ -319,7 +361,7 @@ func ContentGitConfigureRemote(c *gin.Context) {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to initialize git"})
 			return
 		}
-		log.Printf("Initialized git repository at %s", abs)
+		logging.Infof(c, "Initialized git repository at %s", abs)
 	}
 
 	// Get appropriate token and inject into URL for authentication
@@ -329,7 +371,7 @@ func ContentGitConfigureRemote(c *gin.Context) {
 	if token != "" {
 		if authenticatedURL, err := git.InjectGitToken(remoteURL, token); err == nil {
 			remoteURL = authenticatedURL
-			log.Printf("ContentConfigureRemote: configured authentication for provider=%s tokenLen=%d", types.DetectProvider(body.RemoteURL), len(token))
+			logging.Infof(c, "ContentConfigureRemote: configured authentication for provider=%s tokenLen=%d", types.DetectProvider(body.RemoteURL), len(token))
 		}
 	}
 
@@ -339,7 +381,7 @@ func ContentGitConfigureRemote(c *gin.Context) {
 		return
 	}
 
-	log.Printf("Configured remote for %s: %s", abs, body.RemoteURL)
+	logging.Infof(c, "Configured remote for %s: %s", abs, body.RemoteURL)
 
 	// Fetch from remote so merge status can be checked
 	// This is best-effort - don't fail if fetch fails
@@ -350,9 +392,9 @@ func ContentGitConfigureRemote(c *gin.Context) {
 	cmd := exec.CommandContext(c.Request.Context(), "git", "fetch", "origin", branch)
 	cmd.Dir = abs
 	if out, err := cmd.CombinedOutput(); err != nil {
-		log.Printf("Initial fetch after configure remote failed (non-fatal): %v (output: %s)", err, string(out))
+		logging.Warnf(c, "Initial fetch after configure remote failed (non-fatal): %v (output: %s)", err, string(out))
 	} else {
-		log.Printf("Fetched origin/%s after configuring remote", branch)
+		logging.Infof(c, "Fetched origin/%s after configuring remote", branch)
 	}
 
 	c.JSON(http.StatusOK, gin.H{
@@ -394,7 +436,7 @@ func ContentGitSync(c *gin.Context) {
 	// Get remote URL to determine which token to use
 	remoteURL, err := GetRemoteURL(c.Request.Context(), abs)
 	if err != nil {
-		log.Printf("ContentGitSync: failed to get remote URL for %s: %v", abs, err)
+		logging.Errorf(c, "ContentGitSync: failed to get remote URL for %s: %v", abs, err)
 		c.JSON(http.StatusBadRequest, gin.H{"error": "no remote configured"})
 		return
 	}
@@ -403,12 +445,12 @@ func ContentGitSync(c *gin.Context) {
 	gitToken := getGitTokenForURL(c, remoteURL)
 	if err := GitSyncRepo(c.Request.Context(), abs, body.Message, body.Branch, gitToken); err != nil {
 		// Log actual error for debugging, but return generic message to avoid leaking internal details
-		log.Printf("Internal server error: %v", err)
+		logging.Errorf(c, "Internal server error: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
 		return
 	}
 
-	log.Printf("Synchronized git repository at %s to branch %s", abs, body.Branch)
+	logging.Infof(c, "Synchronized git repository at %s to branch %s", abs, body.Branch)
 	c.JSON(http.StatusOK, gin.H{
 		"message": "synchronized successfully",
 		"branch":  body.Branch,
@@ -423,24 +465,24 @@ func ContentWrite(c *gin.Context) {
 		Encoding string `json:"encoding"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
-		log.Printf("ContentWrite: bind JSON failed: %v", err)
+		logging.Errorf(c, "ContentWrite: bind JSON failed: %v", err)
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	log.Printf("ContentWrite: path=%q contentLen=%d encoding=%q StateBaseDir=%q", req.Path, len(req.Content), req.Encoding, StateBaseDir)
+	logging.Debugf(c, "ContentWrite: path=%q contentLen=%d encoding=%q StateBaseDir=%q", req.Path, len(req.Content), req.Encoding, StateBaseDir)
 
 	path := filepath.Clean("/" + strings.TrimSpace(req.Path))
 	abs := filepath.Join(StateBaseDir, path)
 	// Verify abs is within StateBaseDir to prevent path traversal
 	if !pathutil.IsPathWithinBase(abs, StateBaseDir) {
-		log.Printf("ContentWrite: path traversal attempt rejected: path=%q abs=%q", path, abs)
+		logging.Warnf(c, "ContentWrite: path traversal attempt rejected: path=%q abs=%q", path, abs)
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid path"})
 		return
 	}
-	log.Printf("ContentWrite: absolute path=%q", abs)
+	logging.Debugf(c, "ContentWrite: absolute path=%q", abs)
 
 	if err := os.MkdirAll(filepath.Dir(abs), 0755); err != nil {
-		log.Printf("ContentWrite: mkdir failed for %q: %v", filepath.Dir(abs), err)
+		logging.Errorf(c, "ContentWrite: mkdir failed for %q: %v", filepath.Dir(abs), err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create directory"})
 		return
 	}
@@ -448,7 +490,7 @@ func ContentWrite(c *gin.Context) {
 	if strings.EqualFold(req.Encoding, "base64") {
 		b, err := base64.StdEncoding.DecodeString(req.Content)
 		if err != nil {
-			log.Printf("ContentWrite: base64 decode failed: %v", err)
+			logging.Errorf(c, "ContentWrite: base64 decode failed: %v", err)
 			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid base64 content"})
 			return
 		}
@@ -457,32 +499,32 @@ func ContentWrite(c *gin.Context) {
 		data = []byte(req.Content)
 	}
 	if err := os.WriteFile(abs, data, 0644); err != nil {
-		log.Printf("ContentWrite: write failed for %q: %v", abs, err)
+		logging.Errorf(c, "ContentWrite: write failed for %q: %v", abs, err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to write file"})
 		return
 	}
-	log.Printf("ContentWrite: successfully wrote %d bytes to %q", len(data), abs)
+	logging.Infof(c, "ContentWrite: successfully wrote %d bytes to %q", len(data), abs)
 	c.JSON(http.StatusOK, gin.H{"message": "ok"})
 }
 
 // ContentRead handles GET /content/file?path=
 func ContentRead(c *gin.Context) {
 	path := filepath.Clean("/" + strings.TrimSpace(c.Query("path")))
-	log.Printf("ContentRead: requested path=%q StateBaseDir=%q", c.Query("path"), StateBaseDir)
-	log.Printf("ContentRead: cleaned path=%q", path)
+	logging.Debugf(c, "ContentRead: requested path=%q StateBaseDir=%q", c.Query("path"), StateBaseDir)
+	logging.Debugf(c, "ContentRead: cleaned path=%q", path)
 
 	abs := filepath.Join(StateBaseDir, path)
 	// Verify abs is within StateBaseDir to prevent path traversal
 	if !pathutil.IsPathWithinBase(abs, StateBaseDir) {
-		log.Printf("ContentRead: path traversal attempt rejected: path=%q abs=%q", path, abs)
+		logging.Warnf(c, "ContentRead: path traversal attempt rejected: path=%q abs=%q", path, abs)
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid path"})
 		return
 	}
-	log.Printf("ContentRead: absolute path=%q", abs)
+	logging.Debugf(c, "ContentRead: absolute path=%q", abs)
 
 	b, err := os.ReadFile(abs)
 	if err != nil {
-		log.Printf("ContentRead: read failed for %q: %v", abs, err)
+		logging.Errorf(c, "ContentRead: read failed for %q: %v", abs, err)
 		if os.IsNotExist(err) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
 		} else {
@@ -490,29 +532,29 @@ func ContentRead(c *gin.Context) {
 		}
 		return
 	}
-	log.Printf("ContentRead: successfully read %d bytes from %q", len(b), abs)
+	logging.Infof(c, "ContentRead: successfully read %d bytes from %q", len(b), abs)
 	c.Data(http.StatusOK, "application/octet-stream", b)
 }
 
 // ContentList handles GET /content/list?path=
 func ContentList(c *gin.Context) {
 	path := filepath.Clean("/" + strings.TrimSpace(c.Query("path")))
-	log.Printf("ContentList: requested path=%q", c.Query("path"))
-	log.Printf("ContentList: cleaned path=%q", path)
-	log.Printf("ContentList: StateBaseDir=%q", StateBaseDir)
+	logging.Debugf(c, "ContentList: requested path=%q", c.Query("path"))
+	logging.Debugf(c, "ContentList: cleaned path=%q", path)
+	logging.Debugf(c, "ContentList: StateBaseDir=%q", StateBaseDir)
 
 	abs := filepath.Join(StateBaseDir, path)
 	// Verify abs is within StateBaseDir to prevent path traversal
 	if !pathutil.IsPathWithinBase(abs, StateBaseDir) {
-		log.Printf("ContentList: path traversal attempt rejected: path=%q abs=%q", path, abs)
+		logging.Warnf(c, "ContentList: path traversal attempt rejected: path=%q abs=%q", path, abs)
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid path"})
 		return
 	}
-	log.Printf("ContentList: absolute path=%q", abs)
+	logging.Debugf(c, "ContentList: absolute path=%q", abs)
 
 	info, err := os.Stat(abs)
 	if err != nil {
-		log.Printf("ContentList: stat failed for %q: %v", abs, err)
+		logging.Errorf(c, "ContentList: stat failed for %q: %v", abs, err)
 		if os.IsNotExist(err) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
 		} else {
@@ -547,7 +589,7 @@ func ContentList(c *gin.Context) {
 			"modifiedAt": info.ModTime().UTC().Format(time.RFC3339),
 		})
 	}
-	log.Printf("ContentList: returning %d items for path=%q", len(items), path)
+	logging.Infof(c, "ContentList: returning %d items for path=%q", len(items), path)
 	c.JSON(http.StatusOK, gin.H{"items": items})
 }
 
@@ -560,12 +602,12 @@ func ContentWorkflowMetadata(c *gin.Context) {
 		return
 	}
 
-	log.Printf("ContentWorkflowMetadata: session=%q", sessionName)
+	logging.Infof(c, "ContentWorkflowMetadata: session=%q", sessionName)
 
 	// Find active workflow directory
 	workflowDir := findActiveWorkflowDir(sessionName)
 	if workflowDir == "" {
-		log.Printf("ContentWorkflowMetadata: no active workflow found for session=%q", sessionName)
+		logging.Infof(c, "ContentWorkflowMetadata: no active workflow found for session=%q", sessionName)
 		c.JSON(http.StatusOK, gin.H{
 			"commands": []interface{}{},
 			"agents":   []interface{}{},
@@ -574,7 +616,7 @@ func ContentWorkflowMetadata(c *gin.Context) {
 		return
 	}
 
-	log.Printf("ContentWorkflowMetadata: found workflow at %q", workflowDir)
+	logging.Infof(c, "ContentWorkflowMetadata: found workflow at %q", workflowDir)
 
 	// Parse ambient.json configuration
 	ambientConfig := parseAmbientConfig(workflowDir)
@@ -634,9 +676,9 @@ func ContentWorkflowMetadata(c *gin.Context) {
 			return iOrder < jOrder
 		})
 
-		log.Printf("ContentWorkflowMetadata: found %d commands", len(commands))
+		logging.Infof(c, "ContentWorkflowMetadata: found %d commands", len(commands))
 	} else {
-		log.Printf("ContentWorkflowMetadata: commands directory not found or unreadable: %v", err)
+		logging.Errorf(c, "ContentWorkflowMetadata: commands directory not found or unreadable: %v", err)
 	}
 
 	// Parse agents from .claude/agents/*.md
@@ -658,9 +700,9 @@ func ContentWorkflowMetadata(c *gin.Context) {
 				})
 			}
 		}
-		log.Printf("ContentWorkflowMetadata: found %d agents", len(agents))
+		logging.Infof(c, "ContentWorkflowMetadata: found %d agents", len(agents))
 	} else {
-		log.Printf("ContentWorkflowMetadata: agents directory not found or unreadable: %v", err)
+		logging.Errorf(c, "ContentWorkflowMetadata: agents directory not found or unreadable: %v", err)
 	}
 
 	configResponse := gin.H{
@@ -684,7 +726,7 @@ func ContentWorkflowMetadata(c *gin.Context) {
 func parseFrontmatter(filePath string) map[string]string {
 	content, err := os.ReadFile(filePath)
 	if err != nil {
-		log.Printf("parseFrontmatter: failed to read %q: %v", filePath, err)
+		logging.Errorf(context.Background(), "parseFrontmatter: failed to read %q: %v", filePath, err)
 		return map[string]string{}
 	}
 
@@ -744,7 +786,7 @@ func parseAmbientConfig(workflowDir string) *AmbientConfig {
 
 	// Check if file exists
 	if _, err := os.Stat(configPath); os.IsNotExist(err) {
-		log.Printf("parseAmbientConfig: no ambient.json found at %q, using defaults", configPath)
+		logging.Infof(context.Background(), "parseAmbientConfig: no ambient.json found at %q, using defaults", configPath)
 		return &AmbientConfig{
 			ArtifactsDir: "", // Empty string means root (custom workflows manage their own structure)
 		}
@@ -753,18 +795,18 @@ func parseAmbientConfig(workflowDir string) *AmbientConfig {
 	// Read file
 	data, err := os.ReadFile(configPath)
 	if err != nil {
-		log.Printf("parseAmbientConfig: failed to read %q: %v", configPath, err)
+		logging.Errorf(context.Background(), "parseAmbientConfig: failed to read %q: %v", configPath, err)
 		return &AmbientConfig{ArtifactsDir: ""}
 	}
 
 	// Parse JSON
 	var config AmbientConfig
 	if err := json.Unmarshal(data, &config); err != nil {
-		log.Printf("parseAmbientConfig: failed to parse JSON from %q: %v", configPath, err)
+		logging.Errorf(context.Background(), "parseAmbientConfig: failed to parse JSON from %q: %v", configPath, err)
 		return &AmbientConfig{ArtifactsDir: ""}
 	}
 
-	log.Printf("parseAmbientConfig: loaded config: name=%q artifactsDir=%q", config.Name, config.ArtifactsDir)
+	logging.Infof(context.Background(), "parseAmbientConfig: loaded config: name=%q artifactsDir=%q", config.Name, config.ArtifactsDir)
 	return &config
 }
 
@@ -776,7 +818,7 @@ func findActiveWorkflowDir(sessionName string) string {
 
 	entries, err := os.ReadDir(workflowsBase)
 	if err != nil {
-		log.Printf("findActiveWorkflowDir: failed to read workflows directory %q: %v", workflowsBase, err)
+		logging.Errorf(context.Background(), "findActiveWorkflowDir: failed to read workflows directory %q: %v", workflowsBase, err)
 		return ""
 	}
 
@@ -832,7 +874,7 @@ func ContentGitMergeStatus(c *gin.Context) {
 
 	status, err := GitCheckMergeStatus(c.Request.Context(), abs, branch, gitToken)
 	if err != nil {
-		log.Printf("ContentGitMergeStatus: check failed: %v", err)
+		logging.Errorf(c, "ContentGitMergeStatus: check failed: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
 		return
 	}
@@ -868,7 +910,7 @@ func ContentGitPull(c *gin.Context) {
 	// Get remote URL to determine which token to use
 	remoteURL, err := GetRemoteURL(c.Request.Context(), abs)
 	if err != nil {
-		log.Printf("ContentGitPull: failed to get remote URL for %s: %v", abs, err)
+		logging.Errorf(c, "ContentGitPull: failed to get remote URL for %s: %v", abs, err)
 		c.JSON(http.StatusBadRequest, gin.H{"error": "no remote configured"})
 		return
 	}
@@ -879,7 +921,7 @@ func ContentGitPull(c *gin.Context) {
 		return
 	}
 
-	log.Printf("Pulled changes from origin/%s in %s", body.Branch, abs)
+	logging.Infof(c, "Pulled changes from origin/%s in %s", body.Branch, abs)
 	c.JSON(http.StatusOK, gin.H{"message": "pulled successfully", "branch": body.Branch})
 }
 
@@ -916,7 +958,7 @@ func ContentGitPushToBranch(c *gin.Context) {
 	// Get remote URL to determine which token to use
 	remoteURL, err := GetRemoteURL(c.Request.Context(), abs)
 	if err != nil {
-		log.Printf("ContentGitPushToBranch: failed to get remote URL for %s: %v", abs, err)
+		logging.Errorf(c, "ContentGitPushToBranch: failed to get remote URL for %s: %v", abs, err)
 		c.JSON(http.StatusBadRequest, gin.H{"error": "no remote configured"})
 		return
 	}
@@ -927,7 +969,7 @@ func ContentGitPushToBranch(c *gin.Context) {
 		return
 	}
 
-	log.Printf("Pushed changes to origin/%s in %s", body.Branch, abs)
+	logging.Infof(c, "Pushed changes to origin/%s in %s", body.Branch, abs)
 	c.JSON(http.StatusOK, gin.H{"message": "pushed successfully", "branch": body.Branch})
 }
 
@@ -962,7 +1004,7 @@ func ContentGitCreateBranch(c *gin.Context) {
 		return
 	}
 
-	log.Printf("Created branch %s in %s", body.BranchName, abs)
+	logging.Infof(c, "Created branch %s in %s", body.BranchName, abs)
 	c.JSON(http.StatusOK, gin.H{"message": "branch created", "branchName": body.BranchName})
 }
 
@@ -980,7 +1022,7 @@ func ContentGitListBranches(c *gin.Context) {
 	branches, err := GitListRemoteBranches(c.Request.Context(), abs)
 	if err != nil {
 		// Log actual error for debugging, but return generic message to avoid leaking internal details
-		log.Printf("Internal server error: %v", err)
+		logging.Errorf(c, "Internal server error: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal server error"})
 		return
 	}
@@ -994,36 +1036,36 @@ func ContentDelete(c *gin.Context) {
 		Path string `json:"path"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
-		log.Printf("ContentDelete: bind JSON failed: %v", err)
+		logging.Errorf(c, "ContentDelete: bind JSON failed: %v", err)
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	log.Printf("ContentDelete: path=%q StateBaseDir=%q", req.Path, StateBaseDir)
+	logging.Debugf(c, "ContentDelete: path=%q StateBaseDir=%q", req.Path, StateBaseDir)
 
 	path := filepath.Clean("/" + strings.TrimSpace(req.Path))
 	abs := filepath.Join(StateBaseDir, path)
 	// Verify abs is within StateBaseDir to prevent path traversal
 	if !pathutil.IsPathWithinBase(abs, StateBaseDir) {
-		log.Printf("ContentDelete: path traversal attempt rejected: path=%q abs=%q", path, abs)
+		logging.Warnf(c, "ContentDelete: path traversal attempt rejected: path=%q abs=%q", path, abs)
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid path"})
 		return
 	}
-	log.Printf("ContentDelete: absolute path=%q", abs)
+	logging.Debugf(c, "ContentDelete: absolute path=%q", abs)
 
 	// Check if file exists
 	if _, err := os.Stat(abs); os.IsNotExist(err) {
-		log.Printf("ContentDelete: file not found: %q", abs)
+		logging.Errorf(c, "ContentDelete: file not found: %q", abs)
 		c.JSON(http.StatusNotFound, gin.H{"error": "file not found"})
 		return
 	}
 
 	// Delete the file
 	if err := os.Remove(abs); err != nil {
-		log.Printf("ContentDelete: delete failed for %q: %v", abs, err)
+		logging.Errorf(c, "ContentDelete: delete failed for %q: %v", abs, err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete file"})
 		return
 	}
 
-	log.Printf("ContentDelete: successfully deleted %q", abs)
+	logging.Infof(c, "ContentDelete: successfully deleted %q", abs)
 	c.JSON(http.StatusOK, gin.H{"message": "file deleted successfully"})
 }
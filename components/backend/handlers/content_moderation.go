@@ -0,0 +1,293 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+
+	"ambient-code-backend/config"
+	"ambient-code-backend/logging"
+	"ambient-code-backend/metrics"
+	"ambient-code-backend/types"
+
+	"github.com/gin-gonic/gin"
+	"k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+)
+
+// Names of the local moderation rules, reported as verdict categories
+const (
+	moderationBlockedTerm    = "blocked-term"
+	moderationBlockedPattern = "blocked-pattern"
+)
+
+// maxModerationResponseBytes bounds the provider response read
+const maxModerationResponseBytes = 1 << 20
+
+// ContentModerator moderates a project's assistant messages
+type ContentModerator struct {
+	rules    *types.GuardrailEngine
+	remote   bool
+	broken   error // why the project's configuration couldn't be used
+	FailOpen bool
+}
+
+// newContentModerator compiles a moderation configuration
+func newContentModerator(m types.ContentModeration) (*ContentModerator, error) {
+	var rules []types.GuardrailRule
+	if len(m.BlockedTerms) > 0 {
+		rules = append(rules, types.GuardrailRule{Name: moderationBlockedTerm, Kind: types.GuardrailKindKeyword, Patterns: m.BlockedTerms, Action: types.GuardrailActionWarn})
+	}
+	if len(m.BlockedPatterns) > 0 {
+		rules = append(rules, types.GuardrailRule{Name: moderationBlockedPattern, Kind: types.GuardrailKindRegex, Patterns: m.BlockedPatterns, Action: types.GuardrailActionWarn})
+	}
+	engine, err := types.CompileGuardrailPolicy(types.GuardrailPolicy{Rules: rules})
+	if err != nil {
+		return nil, err
+	}
+	return &ContentModerator{rules: engine, remote: m.Provider == types.ModerationProviderRemote, FailOpen: m.FailOpen}, nil
+}
+
+// validateContentModeration checks a configuration before it is stored
+func validateContentModeration(m types.ContentModeration) error {
+	switch m.Provider {
+	case types.ModerationProviderLocal:
+		if len(m.BlockedTerms) == 0 && len(m.BlockedPatterns) == 0 {
+			return fmt.Errorf("local moderation needs blockedTerms or blockedPatterns")
+		}
+	case types.ModerationProviderRemote:
+		if config.Current().ModerationURL == "" {
+			return fmt.Errorf("remote moderation is not available: the backend has no moderation endpoint configured")
+		}
+	default:
+		return fmt.Errorf("provider must be one of: local, remote")
+	}
+	_, err := newContentModerator(m)
+	return err
+}
+
+// contentModerationFromSettings reads spec.contentModeration from a ProjectSettings object
+func contentModerationFromSettings(obj *unstructured.Unstructured) (*types.ContentModeration, error) {
+	raw, found, err := unstructured.NestedMap(obj.Object, "spec", "contentModeration")
+	if err != nil || !found {
+		return nil, err
+	}
+	b, err := json.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+	var m types.ContentModeration
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// getContentModeration returns the project's moderation configuration, or nil if none is set
+func getContentModeration(ctx context.Context, dynClient dynamic.Interface, project string) (*types.ContentModeration, error) {
+	obj, err := dynClient.Resource(GetProjectSettingsResource()).Namespace(project).Get(ctx, "projectsettings", v1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return contentModerationFromSettings(obj)
+}
+
+// ContentModeratorForProject returns the moderator for a new run, or nil when the
+// project hasn't enabled moderation. A configuration that can't be read or compiled
+// yields a moderator that quarantines every message, so a broken setup never lets
+// unchecked content through.
+func ContentModeratorForProject(ctx context.Context, project string) *ContentModerator {
+	if DynamicClient == nil {
+		return nil
+	}
+	m, err := getContentModeration(ctx, DynamicClient, project)
+	if err == nil && (m == nil || !m.Enabled) {
+		return nil
+	}
+	if err == nil {
+		var moderator *ContentModerator
+		if moderator, err = newContentModerator(*m); err == nil {
+			return moderator
+		}
+	}
+	logging.Errorf(ctx, "Content moderation for project %s is enabled but unusable, quarantining all output: %v", project, err)
+	return &ContentModerator{broken: err}
+}
+
+// Moderate checks one assistant message: the local rules first, then the remote
+// provider when configured. The error is the provider's; the verdict then reflects
+// the local rules only.
+func (m *ContentModerator) Moderate(ctx context.Context, text string) (types.ModerationVerdict, error) {
+	verdict := types.ModerationVerdict{Provider: types.ModerationProviderLocal}
+	if m.broken != nil {
+		return verdict, fmt.Errorf("moderation configuration unusable: %w", m.broken)
+	}
+	_, matches := m.rules.Inspect(types.GuardrailTargetOutput, text)
+	for _, match := range matches {
+		verdict.Categories = append(verdict.Categories, match.Rule)
+	}
+	verdict.Flagged = len(verdict.Categories) > 0
+	if verdict.Flagged || !m.remote {
+		return verdict, nil
+	}
+
+	verdict.Provider = types.ModerationProviderRemote
+	categories, err := moderateRemote(ctx, text)
+	if err != nil {
+		return verdict, err
+	}
+	verdict.Categories = categories
+	verdict.Flagged = len(categories) > 0
+	return verdict, nil
+}
+
+// moderateRemote sends text to the configured OpenAI-compatible moderation endpoint
+// and returns the categories it flagged
+func moderateRemote(ctx context.Context, text string) ([]string, error) {
+	cfg := config.Current()
+	if cfg.ModerationURL == "" {
+		return nil, fmt.Errorf("no moderation endpoint configured")
+	}
+	payload := map[string]string{"input": text}
+	if cfg.ModerationModel != "" {
+		payload["model"] = cfg.ModerationModel
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, cfg.ModerationURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token := os.Getenv("MODERATION_TOKEN"); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	client := &http.Client{Timeout: cfg.ModerationTimeout.Duration, Transport: metrics.Transport("moderation", "moderations", nil)}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("moderation request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxModerationResponseBytes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read moderation response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("moderation endpoint returned %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Results []struct {
+			Flagged    bool            `json:"flagged"`
+			Categories map[string]bool `json:"categories"`
+		} `json:"results"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil || len(result.Results) == 0 {
+		return nil, fmt.Errorf("invalid moderation response")
+	}
+	var categories []string
+	for _, r := range result.Results {
+		flagged := false
+		for name, hit := range r.Categories {
+			if hit {
+				categories = append(categories, name)
+				flagged = true
+			}
+		}
+		if r.Flagged && !flagged {
+			categories = append(categories, "flagged")
+		}
+	}
+	sort.Strings(categories)
+	return categories, nil
+}
+
+// GetContentModeration handles GET /api/projects/:projectName/content-moderation
+func GetContentModeration(c *gin.Context) {
+	project := c.GetString("project")
+	_, reqDyn := GetK8sClientsForRequest(c)
+	if reqDyn == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing token"})
+		c.Abort()
+		return
+	}
+
+	m, err := getContentModeration(c.Request.Context(), reqDyn, project)
+	if err != nil {
+		if errors.IsForbidden(err) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions to read project settings"})
+			return
+		}
+		logging.Errorf(c, "Failed to get content moderation for project %s: %v", project, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get content moderation"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"moderation": m, "remoteAvailable": config.Current().ModerationURL != ""})
+}
+
+// UpdateContentModeration handles PUT /api/projects/:projectName/content-moderation
+// Requires update permission on ProjectSettings (project admins). Applies to runs started afterwards.
+func UpdateContentModeration(c *gin.Context) {
+	project := c.GetString("project")
+	_, reqDyn := GetK8sClientsForRequest(c)
+	if reqDyn == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing token"})
+		c.Abort()
+		return
+	}
+
+	var m types.ContentModeration
+	if err := c.ShouldBindJSON(&m); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := validateContentModeration(m); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	b, err := json.Marshal(m)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to encode content moderation"})
+		return
+	}
+	var value map[string]interface{}
+	if err := json.Unmarshal(b, &value); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to encode content moderation"})
+		return
+	}
+	if err := setProjectSettingsField(c.Request.Context(), reqDyn, project, "contentModeration", value); err != nil {
+		respondProjectSettingsError(c, project, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"moderation": m})
+}
+
+// DeleteContentModeration handles DELETE /api/projects/:projectName/content-moderation
+func DeleteContentModeration(c *gin.Context) {
+	project := c.GetString("project")
+	_, reqDyn := GetK8sClientsForRequest(c)
+	if reqDyn == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing token"})
+		c.Abort()
+		return
+	}
+
+	if err := setProjectSettingsField(c.Request.Context(), reqDyn, project, "contentModeration", nil); err != nil && !errors.IsNotFound(err) {
+		respondProjectSettingsError(c, project, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Content moderation removed successfully"})
+}
@@ -0,0 +1,108 @@
+//go:build test
+
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+
+	"ambient-code-backend/config"
+	test_constants "ambient-code-backend/tests/constants"
+	"ambient-code-backend/types"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Content Moderation", Label(test_constants.LabelUnit, test_constants.LabelHandlers), func() {
+	It("Should block messages matching local rules", func() {
+		moderator, err := newContentModerator(types.ContentModeration{
+			Enabled: true, Provider: "local", BlockedTerms: []string{"Acme Internal"}, BlockedPatterns: []string{`\bcase-[0-9]{5}\b`},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		verdict, err := moderator.Moderate(context.Background(), "see acme internal docs for case-12345")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(verdict.Flagged).To(BeTrue())
+		Expect(verdict.Provider).To(Equal("local"))
+		Expect(verdict.Categories).To(ConsistOf(moderationBlockedTerm, moderationBlockedPattern))
+
+		verdict, err = moderator.Moderate(context.Background(), "all good")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(verdict.Flagged).To(BeFalse())
+	})
+
+	It("Should validate configurations", func() {
+		Expect(validateContentModeration(types.ContentModeration{Provider: "local", BlockedTerms: []string{"x"}})).To(Succeed())
+		Expect(validateContentModeration(types.ContentModeration{Provider: "local"})).To(HaveOccurred())
+		Expect(validateContentModeration(types.ContentModeration{Provider: "local", BlockedPatterns: []string{"("}})).To(HaveOccurred())
+		Expect(validateContentModeration(types.ContentModeration{Provider: "other", BlockedTerms: []string{"x"}})).To(HaveOccurred())
+		// No moderation endpoint is configured
+		Expect(validateContentModeration(types.ContentModeration{Provider: "remote"})).To(HaveOccurred())
+	})
+
+	It("Should quarantine everything when the configuration is unusable", func() {
+		moderator := &ContentModerator{broken: context.DeadlineExceeded}
+		_, err := moderator.Moderate(context.Background(), "hello")
+		Expect(err).To(HaveOccurred())
+		Expect(moderator.FailOpen).To(BeFalse())
+	})
+
+	Describe("remote provider", func() {
+		var srv *httptest.Server
+		var gotInput, gotAuth string
+		flagged := false
+
+		BeforeEach(func() {
+			srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var body struct {
+					Input string `json:"input"`
+				}
+				_ = json.NewDecoder(r.Body).Decode(&body)
+				gotInput, gotAuth = body.Input, r.Header.Get("Authorization")
+				_ = json.NewEncoder(w).Encode(map[string]interface{}{
+					"results": []interface{}{map[string]interface{}{
+						"flagged":    flagged,
+						"categories": map[string]bool{"violence": flagged, "hate": false},
+					}},
+				})
+			}))
+			os.Setenv("MODERATION_URL", srv.URL)
+			os.Setenv("MODERATION_TOKEN", "mod-token")
+			Expect(config.Init()).To(Succeed())
+		})
+
+		AfterEach(func() {
+			srv.Close()
+			os.Unsetenv("MODERATION_URL")
+			os.Unsetenv("MODERATION_TOKEN")
+			Expect(config.Init()).To(Succeed())
+		})
+
+		It("Should report the categories the provider flagged", func() {
+			moderator, err := newContentModerator(types.ContentModeration{Enabled: true, Provider: "remote"})
+			Expect(err).NotTo(HaveOccurred())
+
+			flagged = true
+			verdict, err := moderator.Moderate(context.Background(), "some reply")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(gotInput).To(Equal("some reply"))
+			Expect(gotAuth).To(Equal("Bearer mod-token"))
+			Expect(verdict.Flagged).To(BeTrue())
+			Expect(verdict.Provider).To(Equal("remote"))
+			Expect(verdict.Categories).To(ConsistOf("violence"))
+
+			flagged = false
+			verdict, err = moderator.Moderate(context.Background(), "another reply")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(verdict.Flagged).To(BeFalse())
+		})
+
+		It("Should accept remote configurations once an endpoint is set", func() {
+			Expect(validateContentModeration(types.ContentModeration{Provider: "remote"})).To(Succeed())
+		})
+	})
+})
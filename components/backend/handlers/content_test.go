@@ -33,6 +33,8 @@ var _ = Describe("Content Handler", Label(test_constants.LabelUnit, test_constan
 		originalGitCreateBranch       func(ctx context.Context, repoDir, branchName string) error
 		originalGitListRemoteBranches func(ctx context.Context, repoDir string) ([]string, error)
 		originalGetRemoteURL          func(ctx context.Context, repoDir string) (string, error)
+		originalGitStatusFiles        func(ctx context.Context, repoDir string) ([]git.FileStatus, error)
+		originalGitWorkspaceDiff      func(ctx context.Context, repoDir, file string) (*git.FileDiff, error)
 	)
 
 	BeforeEach(func() {
@@ -60,6 +62,8 @@ var _ = Describe("Content Handler", Label(test_constants.LabelUnit, test_constan
 		originalGitCreateBranch = GitCreateBranch
 		originalGitListRemoteBranches = GitListRemoteBranches
 		originalGetRemoteURL = GetRemoteURL
+		originalGitStatusFiles = GitStatusFiles
+		originalGitWorkspaceDiff = GitWorkspaceDiff
 
 		// Default mock for GetRemoteURL - returns GitHub URL
 		GetRemoteURL = func(ctx context.Context, repoDir string) (string, error) {
@@ -79,6 +83,8 @@ var _ = Describe("Content Handler", Label(test_constants.LabelUnit, test_constan
 		GitCreateBranch = originalGitCreateBranch
 		GitListRemoteBranches = originalGitListRemoteBranches
 		GetRemoteURL = originalGetRemoteURL
+		GitStatusFiles = originalGitStatusFiles
+		GitWorkspaceDiff = originalGitWorkspaceDiff
 
 		// Clean up temp directory
 		if tempStateDir != "" {
@@ -344,6 +350,60 @@ var _ = Describe("Content Handler", Label(test_constants.LabelUnit, test_constan
 					"initialized": false,
 				})
 			})
+
+			It("Should list changed files when available", func() {
+				testDir := filepath.Join(tempStateDir, "test-repo")
+				Expect(os.MkdirAll(filepath.Join(testDir, ".git"), 0755)).To(Succeed())
+
+				GitDiffRepo = func(ctx context.Context, repoDir string) (*git.DiffSummary, error) {
+					return &git.DiffSummary{}, nil
+				}
+				GitStatusFiles = func(ctx context.Context, repoDir string) ([]git.FileStatus, error) {
+					return []git.FileStatus{{Path: "main.go", Status: "modified"}}, nil
+				}
+
+				context := httpUtils.CreateTestGinContext("GET", "/content/git-status?path=test-repo", nil)
+
+				ContentGitStatus(context)
+
+				httpUtils.AssertHTTPStatus(http.StatusOK)
+				var response map[string]interface{}
+				httpUtils.GetResponseJSON(&response)
+				Expect(response["hasChanges"]).To(BeTrue())
+				Expect(response["files"]).To(HaveLen(1))
+			})
+		})
+
+		Describe("ContentGitWorkspaceDiff", func() {
+			It("Should return the diff for a single file", func() {
+				testDir := filepath.Join(tempStateDir, "test-repo")
+				Expect(os.MkdirAll(filepath.Join(testDir, ".git"), 0755)).To(Succeed())
+
+				var gotFile string
+				GitWorkspaceDiff = func(ctx context.Context, repoDir, file string) (*git.FileDiff, error) {
+					gotFile = file
+					return &git.FileDiff{Path: file, Diff: "--- a/main.go\n+++ b/main.go\n"}, nil
+				}
+
+				context := httpUtils.CreateTestGinContext("GET", "/content/git-diff?path=test-repo&file=main.go", nil)
+
+				ContentGitWorkspaceDiff(context)
+
+				httpUtils.AssertHTTPStatus(http.StatusOK)
+				Expect(gotFile).To(Equal("main.go"))
+				httpUtils.AssertJSONContains(map[string]interface{}{
+					"path":      "main.go",
+					"truncated": false,
+				})
+			})
+
+			It("Should return 404 for a directory that is not a repository", func() {
+				context := httpUtils.CreateTestGinContext("GET", "/content/git-diff?path=nonexistent", nil)
+
+				ContentGitWorkspaceDiff(context)
+
+				httpUtils.AssertHTTPStatus(http.StatusNotFound)
+			})
 		})
 	})
 
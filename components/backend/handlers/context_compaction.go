@@ -0,0 +1,40 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// contextCompactionAPITimeout bounds the summary call; the run request waits for it
+const contextCompactionAPITimeout = 45 * time.Second
+
+// SummarizeContext condenses the older turns of a long thread into a context block the
+// agent can continue from, using the project's Haiku credentials
+func SummarizeContext(ctx context.Context, projectName, transcript string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, contextCompactionAPITimeout)
+	defer cancel()
+
+	text, err := completeWithHaiku(ctx, projectName, buildContextCompactionPrompt(transcript), 2000)
+	if err != nil {
+		return "", err
+	}
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return "", fmt.Errorf("empty summary")
+	}
+	return text, nil
+}
+
+// buildContextCompactionPrompt constructs the prompt for context compaction
+func buildContextCompactionPrompt(transcript string) string {
+	return fmt.Sprintf(`The following is the beginning of a long conversation between a user and an AI coding agent.
+It will be replaced by your summary so the conversation can continue within the agent's context limit.
+
+%s
+
+Write a dense summary the agent can continue from: the user's goals and instructions, what was done
+(files, commands, results), decisions and their reasons, and anything still pending. Keep names,
+paths and identifiers exact. Return only the summary.`, transcript)
+}
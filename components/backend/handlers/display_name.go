@@ -3,13 +3,15 @@ package handlers
 import (
 	"context"
 	"fmt"
-	"log"
 	"os"
 	"regexp"
 	"strings"
 	"time"
 	"unicode/utf8"
 
+	"ambient-code-backend/logging"
+	"ambient-code-backend/workpool"
+
 	"github.com/anthropics/anthropic-sdk-go"
 	"github.com/anthropics/anthropic-sdk-go/option"
 	"github.com/anthropics/anthropic-sdk-go/vertex"
@@ -71,21 +73,35 @@ func ValidateDisplayName(name string) string {
 	return ""
 }
 
+// issueDisplayName names a session created from an issue: "KEY: title", shortened to
+// maxDisplayNameLength
+func issueDisplayName(key, title string) string {
+	name := key + ": " + title
+	if utf8.RuneCountInString(name) > maxDisplayNameLength {
+		name = string([]rune(name)[:maxDisplayNameLength-1]) + "…"
+	}
+	return name
+}
+
+// displayNamePool bounds concurrent display-name generation; each task is a model call
+// of up to displayNameAPITimeout. Names are cosmetic, so a burst drops the overflow.
+var displayNamePool = workpool.New("display-name", 4, 64, workpool.Drop)
+
 // GenerateDisplayNameAsync asynchronously generates a display name for a session
-// based on the user's first message and session context. Runs in a goroutine
-// and fails silently on error.
+// based on the user's first message and session context. Runs on displayNamePool
+// and fails silently on error, including when the pool is full.
 //
-// Goroutine Lifecycle:
+// Task Lifecycle:
 // - Bounded by displayNameAPITimeout (10s max) preventing indefinite hangs
 // - Gracefully handles session deletion during generation (checks IsNotFound)
-// - No cancellation mechanism exists; goroutine runs to completion or timeout
-// - Safe for backend restarts: orphaned goroutines will timeout naturally
+// - No cancellation mechanism exists; the task runs to completion or timeout
+// - Safe for backend restarts: queued tasks are simply lost
 func GenerateDisplayNameAsync(projectName, sessionName, userMessage string, sessionCtx SessionContext) {
-	go func() {
+	displayNamePool.Submit(sessionName, func() {
 		if err := generateAndUpdateDisplayName(projectName, sessionName, userMessage, sessionCtx); err != nil {
-			log.Printf("DisplayNameGen: Failed to generate display name for %s/%s: %v", projectName, sessionName, err)
+			logging.Errorf(context.Background(), "DisplayNameGen: Failed to generate display name for %s/%s: %v", projectName, sessionName, err)
 		}
-	}()
+	})
 }
 
 // generateAndUpdateDisplayName generates a display name using Claude Haiku and updates the CR
@@ -126,7 +142,7 @@ func generateAndUpdateDisplayName(projectName, sessionName, userMessage string,
 		return fmt.Errorf("failed to update session display name: %w", err)
 	}
 
-	log.Printf("DisplayNameGen: Successfully generated display name for %s/%s: %q", projectName, sessionName, displayName)
+	logging.Infof(context.Background(), "DisplayNameGen: Successfully generated display name for %s/%s: %q", projectName, sessionName, displayName)
 	return nil
 }
 
@@ -149,7 +165,7 @@ func getAnthropicClient(ctx context.Context, projectName string) (anthropic.Clie
 			return anthropic.Client{}, false, fmt.Errorf("ANTHROPIC_VERTEX_PROJECT_ID is required when CLAUDE_CODE_USE_VERTEX=1 (check backend deployment env vars)")
 		}
 
-		log.Printf("DisplayNameGen: Using Vertex AI for %s (region: %s, project: %s)", projectName, region, gcpProjectID)
+		logging.Infof(ctx, "DisplayNameGen: Using Vertex AI for %s (region: %s, project: %s)", projectName, region, gcpProjectID)
 		// Must pass OAuth scope for Vertex AI - without it, auth fails with "invalid_scope" error
 		client := anthropic.NewClient(
 			vertex.WithGoogleAuth(ctx, region, gcpProjectID, "https://www.googleapis.com/auth/cloud-platform"),
@@ -282,7 +298,7 @@ func updateSessionDisplayNameInternal(projectName, sessionName, displayName stri
 	if err != nil {
 		if errors.IsNotFound(err) {
 			// Session was deleted, this is not an error for async generation
-			log.Printf("DisplayNameGen: Session %s/%s no longer exists, skipping update", projectName, sessionName)
+			logging.Warnf(context.Background(), "DisplayNameGen: Session %s/%s no longer exists, skipping update", projectName, sessionName)
 			return nil
 		}
 		return fmt.Errorf("failed to get session: %w", err)
@@ -300,7 +316,7 @@ func updateSessionDisplayNameInternal(projectName, sessionName, displayName stri
 	// Check if displayName was already set (race condition mitigation)
 	existingName, _, _ := unstructured.NestedString(spec, "displayName")
 	if existingName != "" {
-		log.Printf("DisplayNameGen: Session %s/%s already has display name %q, skipping", projectName, sessionName, existingName)
+		logging.Warnf(context.Background(), "DisplayNameGen: Session %s/%s already has display name %q, skipping", projectName, sessionName, existingName)
 		return nil
 	}
 
@@ -316,7 +332,7 @@ func updateSessionDisplayNameInternal(projectName, sessionName, displayName stri
 	if err != nil {
 		if errors.IsNotFound(err) {
 			// Session was deleted during update
-			log.Printf("DisplayNameGen: Session %s/%s deleted during update, skipping", projectName, sessionName)
+			logging.Warnf(context.Background(), "DisplayNameGen: Session %s/%s deleted during update, skipping", projectName, sessionName)
 			return nil
 		}
 		return fmt.Errorf("failed to update session: %w", err)
@@ -0,0 +1,184 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"ambient-code-backend/logging"
+	"ambient-code-backend/types"
+
+	"github.com/gin-gonic/gin"
+	"k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+)
+
+// validateEgressPolicy checks a policy before it is stored or applied
+func validateEgressPolicy(p types.EgressPolicy) error {
+	if p.Enforcement != types.EgressPolicyFlag && p.Enforcement != types.EgressPolicyBlock {
+		return fmt.Errorf("enforcement must be one of: flag, block")
+	}
+	for _, h := range p.AllowedHosts {
+		h = strings.TrimSpace(h)
+		switch {
+		case h == "":
+			return fmt.Errorf("allowed hosts must not be empty")
+		case strings.Contains(h, "/"):
+			if _, _, err := net.ParseCIDR(h); err != nil {
+				return fmt.Errorf("invalid CIDR range %q", h)
+			}
+		case strings.Contains(h, "://") || strings.ContainsAny(h, " :@"):
+			return fmt.Errorf("allowed host %q must be a host name, not a URL", h)
+		case strings.Contains(strings.TrimPrefix(h, "*."), "*"):
+			return fmt.Errorf("allowed host %q may only use '*.' as a leading wildcard", h)
+		}
+	}
+	return nil
+}
+
+// egressPolicyFromSettings reads spec.egressPolicy from a ProjectSettings object
+func egressPolicyFromSettings(obj *unstructured.Unstructured) (*types.EgressPolicy, error) {
+	raw, found, err := unstructured.NestedMap(obj.Object, "spec", "egressPolicy")
+	if err != nil || !found {
+		return nil, err
+	}
+	b, err := json.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+	var policy types.EgressPolicy
+	if err := json.Unmarshal(b, &policy); err != nil {
+		return nil, err
+	}
+	if policy.Enforcement == "" {
+		policy.Enforcement = types.EgressPolicyFlag
+	}
+	if err := validateEgressPolicy(policy); err != nil {
+		return nil, fmt.Errorf("invalid egressPolicy: %w", err)
+	}
+	return &policy, nil
+}
+
+// getEgressPolicy returns the project's egress policy, or nil if none is configured
+func getEgressPolicy(ctx context.Context, dynClient dynamic.Interface, project string) (*types.EgressPolicy, error) {
+	obj, err := dynClient.Resource(GetProjectSettingsResource()).Namespace(project).Get(ctx, "projectsettings", v1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return egressPolicyFromSettings(obj)
+}
+
+// egressPolicyEnv returns the policy snapshot to pass to the runner, or "" if none applies
+func egressPolicyEnv(ctx context.Context, dynClient dynamic.Interface, project string) (string, error) {
+	policy, err := getEgressPolicy(ctx, dynClient, project)
+	if err != nil || policy == nil {
+		return "", err
+	}
+	b, err := json.Marshal(policy)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// EgressPolicyForSession returns the egress policy snapshot recorded on a session at creation
+func EgressPolicyForSession(obj *unstructured.Unstructured) *types.EgressPolicy {
+	raw, _, _ := unstructured.NestedString(obj.Object, "spec", "environmentVariables", types.EgressPolicyEnvVar)
+	if raw == "" {
+		return nil
+	}
+	var policy types.EgressPolicy
+	if err := json.Unmarshal([]byte(raw), &policy); err != nil {
+		logging.Warnf(context.Background(), "Ignoring invalid egress policy on session %s/%s: %v", obj.GetNamespace(), obj.GetName(), err)
+		return nil
+	}
+	return &policy
+}
+
+// GetEgressPolicy handles GET /api/projects/:projectName/egress-policy
+func GetEgressPolicy(c *gin.Context) {
+	project := c.GetString("project")
+	_, reqDyn := GetK8sClientsForRequest(c)
+	if reqDyn == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing token"})
+		c.Abort()
+		return
+	}
+
+	policy, err := getEgressPolicy(c.Request.Context(), reqDyn, project)
+	if err != nil {
+		if errors.IsForbidden(err) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions to read project settings"})
+			return
+		}
+		logging.Errorf(c, "Failed to get egress policy for project %s: %v", project, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get egress policy"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"policy": policy})
+}
+
+// UpdateEgressPolicy handles PUT /api/projects/:projectName/egress-policy
+// Requires update permission on ProjectSettings (project admins). Applies to sessions created afterwards.
+func UpdateEgressPolicy(c *gin.Context) {
+	project := c.GetString("project")
+	_, reqDyn := GetK8sClientsForRequest(c)
+	if reqDyn == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing token"})
+		c.Abort()
+		return
+	}
+
+	var policy types.EgressPolicy
+	if err := c.ShouldBindJSON(&policy); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if policy.Enforcement == "" {
+		policy.Enforcement = types.EgressPolicyFlag
+	}
+	if err := validateEgressPolicy(policy); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	hosts := make([]interface{}, 0, len(policy.AllowedHosts))
+	for i, h := range policy.AllowedHosts {
+		policy.AllowedHosts[i] = strings.ToLower(strings.TrimSpace(h))
+		hosts = append(hosts, policy.AllowedHosts[i])
+	}
+	value := map[string]interface{}{
+		"allowedHosts": hosts,
+		"enforcement":  policy.Enforcement,
+	}
+	if err := setProjectSettingsField(c.Request.Context(), reqDyn, project, "egressPolicy", value); err != nil {
+		respondProjectSettingsError(c, project, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"policy": policy})
+}
+
+// DeleteEgressPolicy handles DELETE /api/projects/:projectName/egress-policy
+func DeleteEgressPolicy(c *gin.Context) {
+	project := c.GetString("project")
+	_, reqDyn := GetK8sClientsForRequest(c)
+	if reqDyn == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing token"})
+		c.Abort()
+		return
+	}
+
+	if err := setProjectSettingsField(c.Request.Context(), reqDyn, project, "egressPolicy", nil); err != nil && !errors.IsNotFound(err) {
+		respondProjectSettingsError(c, project, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Egress policy removed successfully"})
+}
@@ -0,0 +1,65 @@
+//go:build test
+
+package handlers
+
+import (
+	test_constants "ambient-code-backend/tests/constants"
+	"ambient-code-backend/types"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+var _ = Describe("Egress Policy", Label(test_constants.LabelUnit, test_constants.LabelHandlers), func() {
+	It("Should extract hosts from URLs and git remotes in tool args", func() {
+		args := `{"command":"curl -s https://API.example.org/v1?x=1 && git clone git@github.com:org/repo.git"}`
+		Expect(types.ExtractEgressHosts(args)).To(Equal([]string{"api.example.org", "github.com"}))
+		Expect(types.ExtractEgressHosts(`{"url":"http://10.1.2.3:8080/health."}`)).To(Equal([]string{"10.1.2.3"}))
+		Expect(types.ExtractEgressHosts(`{"command":"ls -la"}`)).To(BeEmpty())
+	})
+
+	It("Should match exact hosts, subdomain wildcards and CIDR ranges", func() {
+		policy := &types.EgressPolicy{AllowedHosts: []string{"github.com", "*.internal.example.com", "10.0.0.0/8"}}
+		Expect(policy.AllowsHost("github.com")).To(BeTrue())
+		Expect(policy.AllowsHost("git.internal.example.com")).To(BeTrue())
+		Expect(policy.AllowsHost("10.20.30.40")).To(BeTrue())
+		Expect(policy.AllowsHost("internal.example.com")).To(BeFalse())
+		Expect(policy.AllowsHost("evil-github.com")).To(BeFalse())
+		Expect(policy.AllowsHost("192.168.0.1")).To(BeFalse())
+
+		var none *types.EgressPolicy
+		Expect(none.AllowsHost("example.org")).To(BeTrue())
+	})
+
+	It("Should validate policies", func() {
+		Expect(validateEgressPolicy(types.EgressPolicy{Enforcement: "block", AllowedHosts: []string{"github.com", "*.corp.example", "10.0.0.0/8"}})).To(Succeed())
+		Expect(validateEgressPolicy(types.EgressPolicy{Enforcement: "warn"})).To(HaveOccurred())
+		Expect(validateEgressPolicy(types.EgressPolicy{Enforcement: "flag", AllowedHosts: []string{"https://github.com"}})).To(HaveOccurred())
+		Expect(validateEgressPolicy(types.EgressPolicy{Enforcement: "flag", AllowedHosts: []string{"git*.com"}})).To(HaveOccurred())
+		Expect(validateEgressPolicy(types.EgressPolicy{Enforcement: "flag", AllowedHosts: []string{"10.0.0.0/33"}})).To(HaveOccurred())
+	})
+
+	It("Should read the policy from ProjectSettings and session snapshots", func() {
+		obj := &unstructured.Unstructured{Object: map[string]interface{}{
+			"spec": map[string]interface{}{
+				"egressPolicy": map[string]interface{}{"allowedHosts": []interface{}{"github.com"}},
+			},
+		}}
+		policy, err := egressPolicyFromSettings(obj)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(policy.Enforcement).To(Equal(types.EgressPolicyFlag))
+
+		session := &unstructured.Unstructured{Object: map[string]interface{}{
+			"spec": map[string]interface{}{
+				"environmentVariables": map[string]interface{}{
+					types.EgressPolicyEnvVar: `{"allowedHosts":["github.com"],"enforcement":"block"}`,
+				},
+			},
+		}}
+		snapshot := EgressPolicyForSession(session)
+		Expect(snapshot).NotTo(BeNil())
+		Expect(snapshot.Enforcement).To(Equal(types.EgressPolicyBlock))
+		Expect(EgressPolicyForSession(&unstructured.Unstructured{Object: map[string]interface{}{}})).To(BeNil())
+	})
+})
@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+
+	"ambient-code-backend/logging"
+	"ambient-code-backend/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+// StructuredErrors completes JSON error responses (status >= 400 with an "error"
+// message) into types.APIError: it adds the code for the status unless the handler
+// set a more specific one, whether retrying can help, and the request ID. Handlers
+// keep answering gin.H{"error": ...}, adding "code" where clients need to tell
+// errors of the same status apart. Other fields of the response are kept.
+func StructuredErrors() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		w := &errorResponseWriter{ResponseWriter: c.Writer}
+		c.Writer = w
+		defer func() { c.Writer = w.ResponseWriter }()
+		c.Next()
+		if w.buf.Len() == 0 {
+			return
+		}
+		body := structuredError(w.buf.Bytes(), w.Status(), c.GetString(logging.GinRequestIDKey))
+		if _, err := w.ResponseWriter.Write(body); err != nil {
+			logging.Warnf(c, "StructuredErrors: failed to write response: %v", err)
+		}
+	}
+}
+
+// errorResponseWriter holds back JSON error bodies for StructuredErrors; everything
+// else, including streams, passes straight through
+type errorResponseWriter struct {
+	gin.ResponseWriter
+	buf bytes.Buffer
+}
+
+func (w *errorResponseWriter) buffering() bool {
+	if w.buf.Len() > 0 {
+		return true
+	}
+	return w.Status() >= 400 && !w.ResponseWriter.Written() &&
+		strings.HasPrefix(w.Header().Get("Content-Type"), "application/json")
+}
+
+func (w *errorResponseWriter) Write(data []byte) (int, error) {
+	if w.buffering() {
+		return w.buf.Write(data)
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *errorResponseWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+func (w *errorResponseWriter) Written() bool {
+	return w.buf.Len() > 0 || w.ResponseWriter.Written()
+}
+
+func (w *errorResponseWriter) Size() int {
+	if w.buf.Len() > 0 {
+		return w.buf.Len()
+	}
+	return w.ResponseWriter.Size()
+}
+
+func (w *errorResponseWriter) Flush() {
+	if w.buf.Len() == 0 {
+		w.ResponseWriter.Flush()
+	}
+}
+
+// structuredError adds the types.APIError fields missing from an error body; bodies
+// that aren't an object with an "error" message are returned unchanged
+func structuredError(body []byte, status int, requestID string) []byte {
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var fields map[string]interface{}
+	if err := dec.Decode(&fields); err != nil {
+		return body
+	}
+	if _, ok := fields["error"].(string); !ok {
+		return body
+	}
+	code, _ := fields["code"].(string)
+	if code == "" {
+		code = types.ErrorCodeForStatus(status)
+		fields["code"] = code
+	}
+	if _, ok := fields["retryable"]; !ok {
+		fields["retryable"] = types.ErrorRetryable(code)
+	}
+	if _, ok := fields["requestId"]; !ok && requestID != "" {
+		fields["requestId"] = requestID
+	}
+	out, err := json.Marshal(fields)
+	if err != nil {
+		return body
+	}
+	return out
+}
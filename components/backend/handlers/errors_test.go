@@ -0,0 +1,92 @@
+//go:build test
+
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	"ambient-code-backend/logging"
+	test_constants "ambient-code-backend/tests/constants"
+	"ambient-code-backend/types"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Structured Errors", Label(test_constants.LabelUnit, test_constants.LabelHandlers), func() {
+	var router *gin.Engine
+
+	BeforeEach(func() {
+		gin.SetMode(gin.TestMode)
+		router = gin.New()
+		router.Use(RequestIDMiddleware(), StructuredErrors())
+		router.GET("/missing", func(c *gin.Context) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+		})
+		router.GET("/quota", func(c *gin.Context) {
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": "Monthly token quota exceeded", "code": types.ErrorCodeQuotaExceeded, "limit": 1000000})
+		})
+		router.GET("/upstream", func(c *gin.Context) {
+			c.AbortWithStatusJSON(http.StatusBadGateway, gin.H{"error": "Jira returned 500"})
+		})
+		router.GET("/ok", func(c *gin.Context) {
+			c.JSON(http.StatusOK, gin.H{"error": "not an error response"})
+		})
+		router.GET("/text", func(c *gin.Context) {
+			c.String(http.StatusConflict, "plain conflict")
+		})
+	})
+
+	get := func(path string) (*httptest.ResponseRecorder, map[string]interface{}) {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set(logging.RequestIDHeader, "req-123")
+		router.ServeHTTP(w, req)
+		var body map[string]interface{}
+		_ = json.Unmarshal(w.Body.Bytes(), &body)
+		return w, body
+	}
+
+	It("Should add the status code, retryability and request ID to error messages", func() {
+		w, body := get("/missing")
+		Expect(w.Code).To(Equal(http.StatusNotFound))
+		Expect(body).To(Equal(map[string]interface{}{
+			"error":     "Session not found",
+			"code":      types.ErrorCodeNotFound,
+			"retryable": false,
+			"requestId": "req-123",
+		}))
+
+		w, body = get("/upstream")
+		Expect(w.Code).To(Equal(http.StatusBadGateway))
+		Expect(body).To(HaveKeyWithValue("code", types.ErrorCodeUpstream))
+		Expect(body).To(HaveKeyWithValue("retryable", true))
+	})
+
+	It("Should keep a handler's code and other fields", func() {
+		w, _ := get("/quota")
+		Expect(w.Code).To(Equal(http.StatusTooManyRequests))
+		Expect(w.Body.String()).To(ContainSubstring(`"limit":1000000`))
+		var apiErr types.APIError
+		Expect(json.Unmarshal(w.Body.Bytes(), &apiErr)).To(Succeed())
+		Expect(apiErr).To(Equal(types.APIError{
+			Error:     "Monthly token quota exceeded",
+			Code:      types.ErrorCodeQuotaExceeded,
+			Retryable: false,
+			RequestID: "req-123",
+		}))
+	})
+
+	It("Should pass other responses through unchanged", func() {
+		w, body := get("/ok")
+		Expect(w.Code).To(Equal(http.StatusOK))
+		Expect(body).To(Equal(map[string]interface{}{"error": "not an error response"}))
+
+		w, _ = get("/text")
+		Expect(w.Code).To(Equal(http.StatusConflict))
+		Expect(w.Body.String()).To(Equal("plain conflict"))
+	})
+})
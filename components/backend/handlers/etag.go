@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ResourceETag is the ETag of a response rendered from a Kubernetes object at
+// resourceVersion. Weak: the same object version may serialize differently.
+func ResourceETag(resourceVersion string) string {
+	if resourceVersion == "" {
+		return ""
+	}
+	return `W/"` + resourceVersion + `"`
+}
+
+// etagMatches reports whether an If-None-Match header matches etag, comparing weakly
+// as RFC 9110 requires for If-None-Match
+func etagMatches(ifNoneMatch, etag string) bool {
+	if etag == "" {
+		return false
+	}
+	etag = strings.TrimPrefix(etag, "W/")
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// NotModified answers 304 with etag when the request's If-None-Match matches it.
+// Otherwise it sets the ETag header for the response about to be written and returns
+// false. Responses carrying an ETag are marked for revalidation on every use, so
+// polling clients keep asking and get 304 while nothing changed.
+func NotModified(c *gin.Context, etag string) bool {
+	if etag == "" {
+		return false
+	}
+	c.Header("ETag", etag)
+	c.Header("Cache-Control", "private, no-cache")
+	if !etagMatches(c.GetHeader("If-None-Match"), etag) {
+		return false
+	}
+	c.Status(http.StatusNotModified)
+	c.Writer.WriteHeaderNow()
+	return true
+}
+
+// JSONWithETag writes body as JSON with an ETag hashed from it, or 304 when the
+// client's copy is current. For responses that aren't a single Kubernetes object, so
+// have no resourceVersion; it saves the transfer, not the work of building body.
+func JSONWithETag(c *gin.Context, status int, body interface{}) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		c.JSON(status, body)
+		return
+	}
+	sum := sha256.Sum256(data)
+	if NotModified(c, `W/"`+hex.EncodeToString(sum[:12])+`"`) {
+		return
+	}
+	c.Data(status, "application/json; charset=utf-8", data)
+}
@@ -0,0 +1,110 @@
+//go:build test
+
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+
+	test_constants "ambient-code-backend/tests/constants"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+var _ = Describe("Conditional Requests", Label(test_constants.LabelUnit, test_constants.LabelHandlers), func() {
+	It("Should match If-None-Match lists weakly", func() {
+		Expect(etagMatches(`W/"42"`, `W/"42"`)).To(BeTrue())
+		Expect(etagMatches(`"41", "42"`, `W/"42"`)).To(BeTrue())
+		Expect(etagMatches(`*`, `W/"42"`)).To(BeTrue())
+		Expect(etagMatches(`W/"41"`, `W/"42"`)).To(BeFalse())
+		Expect(etagMatches(``, `W/"42"`)).To(BeFalse())
+		Expect(etagMatches(`*`, ``)).To(BeFalse())
+	})
+
+	Context("When getting a session", func() {
+		gvr := schema.GroupVersionResource{Group: "vteam.ambient-code", Version: "v1alpha1", Resource: "agenticsessions"}
+		var (
+			originalK8sClientMw   kubernetes.Interface
+			originalDynamicClient dynamic.Interface
+			originalGVR           func() schema.GroupVersionResource
+		)
+
+		BeforeEach(func() {
+			originalK8sClientMw, originalDynamicClient, originalGVR = K8sClientMw, DynamicClient, GetAgenticSessionV1Alpha1Resource
+			GetAgenticSessionV1Alpha1Resource = func() schema.GroupVersionResource { return gvr }
+			K8sClientMw = fake.NewSimpleClientset()
+			DynamicClient = dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+				map[schema.GroupVersionResource]string{gvr: "AgenticSessionList"},
+				&unstructured.Unstructured{Object: map[string]interface{}{
+					"apiVersion": "vteam.ambient-code/v1alpha1",
+					"kind":       "AgenticSession",
+					"metadata":   map[string]interface{}{"name": "s1", "namespace": "team-a", "resourceVersion": "42"},
+					"spec":       map[string]interface{}{"displayName": "First"},
+					"status":     map[string]interface{}{"phase": "Running"},
+				}},
+			)
+			gin.SetMode(gin.TestMode)
+		})
+
+		AfterEach(func() {
+			K8sClientMw, DynamicClient, GetAgenticSessionV1Alpha1Resource = originalK8sClientMw, originalDynamicClient, originalGVR
+		})
+
+		get := func(handler gin.HandlerFunc, ifNoneMatch string) *httptest.ResponseRecorder {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+			c.Request.Header.Set("Authorization", "Bearer test-token")
+			if ifNoneMatch != "" {
+				c.Request.Header.Set("If-None-Match", ifNoneMatch)
+			}
+			c.Set("project", "team-a")
+			c.Params = gin.Params{{Key: "sessionName", Value: "s1"}}
+			handler(c)
+			return w
+		}
+
+		It("Should return the resourceVersion as ETag", func() {
+			w := get(GetSession, "")
+			Expect(w.Code).To(Equal(http.StatusOK))
+			Expect(w.Header().Get("ETag")).To(Equal(`W/"42"`))
+			Expect(w.Header().Get("Cache-Control")).To(Equal("private, no-cache"))
+		})
+
+		It("Should answer 304 while the session is unchanged", func() {
+			w := get(GetSession, `W/"42"`)
+			Expect(w.Code).To(Equal(http.StatusNotModified))
+			Expect(w.Body.Len()).To(BeZero())
+
+			w = get(GetSession, `W/"41"`)
+			Expect(w.Code).To(Equal(http.StatusOK))
+			Expect(w.Body.String()).To(ContainSubstring("First"))
+		})
+
+		It("Should change the conditions ETag when a run starts", func() {
+			origCounts := ActiveRunCounts
+			defer func() { ActiveRunCounts = origCounts }()
+			ActiveRunCounts = func() map[string]int { return map[string]int{} }
+
+			w := get(GetSessionConditions, "")
+			Expect(w.Code).To(Equal(http.StatusOK))
+			etag := w.Header().Get("ETag")
+			Expect(etag).NotTo(BeEmpty())
+			Expect(get(GetSessionConditions, etag).Code).To(Equal(http.StatusNotModified))
+
+			ActiveRunCounts = func() map[string]int { return map[string]int{"s1": 1} }
+			w = get(GetSessionConditions, etag)
+			Expect(w.Code).To(Equal(http.StatusOK))
+			Expect(w.Body.String()).To(ContainSubstring("RunInProgress"))
+		})
+	})
+})
@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/util/retry"
+)
+
+// Labels marking eval sandbox sessions
+const (
+	EvalIDLabel            = "ambient-code.io/eval-id"
+	EvalSourceSessionLabel = "ambient-code.io/eval-source-session"
+)
+
+// CreateEvalSandbox creates an interactive copy of the source session for replaying
+// one of its runs. The sandbox has no initial prompt and never pushes to its repos;
+// model, when set, replaces the session model. Uses the caller's client so their
+// permissions apply.
+func CreateEvalSandbox(ctx context.Context, k8sDyn dynamic.Interface, project, sourceSession, name, evalID, model string) error {
+	gvr := GetAgenticSessionV1Alpha1Resource()
+	source, err := k8sDyn.Resource(gvr).Namespace(project).Get(ctx, sourceSession, v1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	spec, _, _ := unstructured.NestedMap(source.Object, "spec")
+	if spec == nil {
+		return fmt.Errorf("source session has no spec")
+	}
+	delete(spec, "initialPrompt")
+	spec["interactive"] = true
+	spec["displayName"] = fmt.Sprintf("Eval %s of %s", evalID, sourceSession)
+	if model != "" {
+		llmSettings, _ := spec["llmSettings"].(map[string]interface{})
+		if llmSettings == nil {
+			llmSettings = map[string]interface{}{}
+		}
+		llmSettings["model"] = model
+		// The replay measures the chosen model, not a fallback
+		delete(llmSettings, "fallbackModels")
+		spec["llmSettings"] = llmSettings
+	}
+	if repos, ok := spec["repos"].([]interface{}); ok {
+		for _, r := range repos {
+			if repo, ok := r.(map[string]interface{}); ok {
+				repo["autoPush"] = false
+			}
+		}
+	}
+
+	sandbox := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "vteam.ambient-code/v1alpha1",
+		"kind":       "AgenticSession",
+		"metadata": map[string]interface{}{
+			"name":      name,
+			"namespace": project,
+			"labels": map[string]interface{}{
+				EvalIDLabel:            evalID,
+				EvalSourceSessionLabel: sourceSession,
+			},
+		},
+		"spec": spec,
+		"status": map[string]interface{}{
+			"phase": "Pending",
+		},
+	}}
+	_, _, err = createAdmittedSession(ctx, k8sDyn, sandbox)
+	return err
+}
+
+// RequestSessionStop asks the operator to stop a session, as StopSession does. Uses
+// the backend service account; for sessions the backend started on a user's behalf.
+func RequestSessionStop(ctx context.Context, project, sessionName string) error {
+	if DynamicClient == nil {
+		return nil
+	}
+	gvr := GetAgenticSessionV1Alpha1Resource()
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		item, err := DynamicClient.Resource(gvr).Namespace(project).Get(ctx, sessionName, v1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		annotations := item.GetAnnotations()
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		annotations["ambient-code.io/desired-phase"] = "Stopped"
+		annotations["ambient-code.io/stop-requested-at"] = time.Now().Format(time.RFC3339)
+		item.SetAnnotations(annotations)
+		_, err = DynamicClient.Resource(gvr).Namespace(project).Update(ctx, item, v1.UpdateOptions{})
+		return err
+	})
+	if errors.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,75 @@
+//go:build test
+
+package handlers
+
+import (
+	"context"
+
+	test_constants "ambient-code-backend/tests/constants"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+var _ = Describe("Eval Sandbox Sessions", Label(test_constants.LabelUnit, test_constants.LabelHandlers), func() {
+	gvr := schema.GroupVersionResource{Group: "vteam.ambient-code", Version: "v1alpha1", Resource: "agenticsessions"}
+
+	var (
+		originalDynamicClient dynamic.Interface
+		originalGVR           func() schema.GroupVersionResource
+	)
+
+	BeforeEach(func() {
+		originalDynamicClient, originalGVR = DynamicClient, GetAgenticSessionV1Alpha1Resource
+		GetAgenticSessionV1Alpha1Resource = func() schema.GroupVersionResource { return gvr }
+		DynamicClient = dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "vteam.ambient-code/v1alpha1",
+			"kind":       "AgenticSession",
+			"metadata":   map[string]interface{}{"name": "source", "namespace": "team-a"},
+			"spec": map[string]interface{}{
+				"initialPrompt": "Fix the bug",
+				"llmSettings":   map[string]interface{}{"model": "claude-sonnet-4-5", "fallbackModels": []interface{}{"claude-haiku-4-5"}},
+				"repos":         []interface{}{map[string]interface{}{"url": "https://github.com/org/repo", "autoPush": true}},
+			},
+		}})
+	})
+
+	AfterEach(func() {
+		DynamicClient, GetAgenticSessionV1Alpha1Resource = originalDynamicClient, originalGVR
+	})
+
+	It("Should copy the source session without its prompt, pushes or fallbacks", func() {
+		ctx := context.Background()
+		Expect(CreateEvalSandbox(ctx, DynamicClient, "team-a", "source", "eval-abc", "abc", "claude-opus-4-1")).To(Succeed())
+
+		obj, err := DynamicClient.Resource(gvr).Namespace("team-a").Get(ctx, "eval-abc", v1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(obj.GetLabels()).To(HaveKeyWithValue(EvalIDLabel, "abc"))
+		Expect(obj.GetLabels()).To(HaveKeyWithValue(EvalSourceSessionLabel, "source"))
+
+		_, found, _ := unstructured.NestedString(obj.Object, "spec", "initialPrompt")
+		Expect(found).To(BeFalse())
+		model, _, _ := unstructured.NestedString(obj.Object, "spec", "llmSettings", "model")
+		Expect(model).To(Equal("claude-opus-4-1"))
+		_, found, _ = unstructured.NestedSlice(obj.Object, "spec", "llmSettings", "fallbackModels")
+		Expect(found).To(BeFalse())
+		repos, _, _ := unstructured.NestedSlice(obj.Object, "spec", "repos")
+		Expect(repos[0].(map[string]interface{})["autoPush"]).To(BeFalse())
+	})
+
+	It("Should request the operator to stop a session", func() {
+		ctx := context.Background()
+		Expect(RequestSessionStop(ctx, "team-a", "source")).To(Succeed())
+
+		obj, err := DynamicClient.Resource(gvr).Namespace("team-a").Get(ctx, "source", v1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(obj.GetAnnotations()).To(HaveKeyWithValue("ambient-code.io/desired-phase", "Stopped"))
+		Expect(RequestSessionStop(ctx, "team-a", "gone")).To(Succeed())
+	})
+})
@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"ambient-code-backend/logging"
+	"ambient-code-backend/types"
+
+	"github.com/gin-gonic/gin"
+	"k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// FeatureFlagsConfigMap holds feature flags: in the backend namespace for global
+// values, in a project namespace for that project. Each key is a flag name with a
+// "true" or "false" value; project values override global ones, which override the
+// flag's default.
+const FeatureFlagsConfigMap = "ambient-feature-flags"
+
+// Feature flags gating capabilities that are still being rolled out
+const (
+	FeatureMCPToolPolicy = "mcp-tool-policy"
+	FeatureLinear        = "linear"
+	FeatureRunSummaries  = "run-summaries"
+	FeatureGuardrails    = "guardrails"
+)
+
+// FeatureFlag is a known flag and its value when no ConfigMap sets it
+type FeatureFlag struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Default     bool   `json:"default"`
+}
+
+// featureFlags are the flags the backend evaluates; keys for other names are ignored
+var featureFlags = []FeatureFlag{
+	{FeatureMCPToolPolicy, "Project MCP tool allow/deny policies", true},
+	{FeatureLinear, "Linear integration and session issue endpoints", true},
+	{FeatureRunSummaries, "Summarize each completed run with the project's model", true},
+	{FeatureGuardrails, "Project guardrail policies on streamed run output", true},
+}
+
+// Flag ConfigMaps are read with the backend service account and cached briefly, so
+// evaluating a flag on every request doesn't reach the API server. Changes apply
+// within the TTL.
+const featureFlagCacheTTL = 30 * time.Second
+
+type featureFlagCacheEntry struct {
+	values  map[string]bool
+	expires time.Time
+}
+
+var (
+	featureFlagCacheMu sync.Mutex
+	featureFlagCache   = map[string]featureFlagCacheEntry{}
+)
+
+// featureFlagValues returns the flags set in namespace's ConfigMap. A failed read is
+// logged and treated as setting nothing.
+func featureFlagValues(ctx context.Context, namespace string) map[string]bool {
+	featureFlagCacheMu.Lock()
+	entry, ok := featureFlagCache[namespace]
+	featureFlagCacheMu.Unlock()
+	if ok && time.Now().Before(entry.expires) {
+		return entry.values
+	}
+
+	values := map[string]bool{}
+	cm, err := K8sClient.CoreV1().ConfigMaps(namespace).Get(ctx, FeatureFlagsConfigMap, v1.GetOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		logging.Errorf(ctx, "Feature flags: failed to read %s/%s: %v", namespace, FeatureFlagsConfigMap, err)
+		return values
+	}
+	if err == nil {
+		for name, raw := range cm.Data {
+			v, perr := strconv.ParseBool(raw)
+			if perr != nil {
+				logging.Warnf(ctx, "Feature flags: ignoring %s=%q in %s/%s, want true or false", name, raw, namespace, FeatureFlagsConfigMap)
+				continue
+			}
+			values[name] = v
+		}
+	}
+
+	featureFlagCacheMu.Lock()
+	featureFlagCache[namespace] = featureFlagCacheEntry{values: values, expires: time.Now().Add(featureFlagCacheTTL)}
+	featureFlagCacheMu.Unlock()
+	return values
+}
+
+// FeatureFlagStatus is a flag evaluated for a project
+type FeatureFlagStatus struct {
+	FeatureFlag
+	Enabled bool `json:"enabled"`
+	// Source is where the value came from: "default", "global" or "project"
+	Source string `json:"source"`
+}
+
+// EvaluateFeatureFlags evaluates every known flag for project, or globally when
+// project is ""
+func EvaluateFeatureFlags(ctx context.Context, project string) []FeatureFlagStatus {
+	global := featureFlagValues(ctx, Namespace)
+	var local map[string]bool
+	if project != "" && project != Namespace {
+		local = featureFlagValues(ctx, project)
+	}
+
+	statuses := make([]FeatureFlagStatus, 0, len(featureFlags))
+	for _, f := range featureFlags {
+		s := FeatureFlagStatus{FeatureFlag: f, Enabled: f.Default, Source: "default"}
+		if v, ok := global[f.Name]; ok {
+			s.Enabled, s.Source = v, "global"
+		}
+		if v, ok := local[f.Name]; ok {
+			s.Enabled, s.Source = v, "project"
+		}
+		statuses = append(statuses, s)
+	}
+	return statuses
+}
+
+// FeatureEnabled reports whether flag is on for project (global value when project
+// is ""). Unknown flags are off.
+func FeatureEnabled(ctx context.Context, project, flag string) bool {
+	for _, s := range EvaluateFeatureFlags(ctx, project) {
+		if s.Name == flag {
+			return s.Enabled
+		}
+	}
+	return false
+}
+
+// RequireFeature answers 404 when flag is off for the route's project, or globally
+// on routes without a project, so disabled endpoints look absent
+func RequireFeature(flag string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !FeatureEnabled(c.Request.Context(), c.Param("projectName"), flag) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Feature " + flag + " is not enabled", "code": types.ErrorCodeFeatureDisabled})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
+
+// ListProjectFeatureFlags handles GET /api/projects/:projectName/feature-flags
+func ListProjectFeatureFlags(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"flags": EvaluateFeatureFlags(c.Request.Context(), c.Param("projectName"))})
+}
+
+// ListFeatureFlags handles GET /api/admin/feature-flags, the global values
+func ListFeatureFlags(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"flags": EvaluateFeatureFlags(c.Request.Context(), "")})
+}
@@ -0,0 +1,82 @@
+//go:build test
+
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+
+	test_constants "ambient-code-backend/tests/constants"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+var _ = Describe("Feature Flags", Label(test_constants.LabelUnit, test_constants.LabelHandlers), func() {
+	var (
+		originalK8sClient kubernetes.Interface
+		originalNamespace string
+	)
+
+	flagsConfigMap := func(namespace string, data map[string]string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{
+			ObjectMeta: v1.ObjectMeta{Name: FeatureFlagsConfigMap, Namespace: namespace},
+			Data:       data,
+		}
+	}
+
+	BeforeEach(func() {
+		originalK8sClient, originalNamespace = K8sClient, Namespace
+		Namespace = "ambient-code"
+		K8sClient = fake.NewSimpleClientset(
+			flagsConfigMap("ambient-code", map[string]string{FeatureLinear: "false", FeatureMCPToolPolicy: "maybe"}),
+			flagsConfigMap("team-a", map[string]string{FeatureLinear: "true"}),
+		)
+		featureFlagCacheMu.Lock()
+		featureFlagCache = map[string]featureFlagCacheEntry{}
+		featureFlagCacheMu.Unlock()
+	})
+
+	AfterEach(func() {
+		K8sClient, Namespace = originalK8sClient, originalNamespace
+	})
+
+	It("Should let project values override global values and defaults", func() {
+		ctx := context.Background()
+		Expect(FeatureEnabled(ctx, "", FeatureLinear)).To(BeFalse())
+		Expect(FeatureEnabled(ctx, "team-a", FeatureLinear)).To(BeTrue())
+		Expect(FeatureEnabled(ctx, "team-b", FeatureLinear)).To(BeFalse())
+		// Invalid values are ignored, leaving the default
+		Expect(FeatureEnabled(ctx, "team-b", FeatureMCPToolPolicy)).To(BeTrue())
+		Expect(FeatureEnabled(ctx, "team-a", "unknown")).To(BeFalse())
+
+		for _, s := range EvaluateFeatureFlags(ctx, "team-a") {
+			switch s.Name {
+			case FeatureLinear:
+				Expect(s.Source).To(Equal("project"))
+			case FeatureMCPToolPolicy:
+				Expect(s.Source).To(Equal("default"))
+			}
+		}
+	})
+
+	It("Should hide routes whose flag is off", func() {
+		gin.SetMode(gin.TestMode)
+		r := gin.New()
+		r.GET("/projects/:projectName/linear", RequireFeature(FeatureLinear), func(c *gin.Context) {
+			c.Status(http.StatusNoContent)
+		})
+
+		for project, want := range map[string]int{"team-a": http.StatusNoContent, "team-b": http.StatusNotFound} {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/projects/"+project+"/linear", nil))
+			Expect(w.Code).To(Equal(want), project)
+		}
+	})
+})
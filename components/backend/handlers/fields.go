@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	maxSelectedFields = 50
+	maxFieldDepth     = 8
+)
+
+// FieldSelection is a parsed ?fields= list: the JSON paths each list item is cut
+// down to. A nil child keeps the whole value at that path.
+type FieldSelection map[string]FieldSelection
+
+// ParseFieldSelection parses comma-separated dot paths such as
+// "metadata.name,status.phase". An empty list selects everything (nil).
+func ParseFieldSelection(raw string) (FieldSelection, error) {
+	if strings.TrimSpace(raw) == "" {
+		return nil, nil
+	}
+	paths := strings.Split(raw, ",")
+	if len(paths) > maxSelectedFields {
+		return nil, fmt.Errorf("at most %d fields can be selected", maxSelectedFields)
+	}
+	root := FieldSelection{}
+	for _, p := range paths {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		parts := strings.Split(p, ".")
+		if len(parts) > maxFieldDepth {
+			return nil, fmt.Errorf("field %q is nested deeper than %d levels", p, maxFieldDepth)
+		}
+		node := root
+		for i, part := range parts {
+			if part == "" {
+				return nil, fmt.Errorf("invalid field %q", p)
+			}
+			child, seen := node[part]
+			if i == len(parts)-1 {
+				// The whole value wins over parts of it
+				node[part] = nil
+				break
+			}
+			if seen && child == nil {
+				break
+			}
+			if child == nil {
+				child = FieldSelection{}
+				node[part] = child
+			}
+			node = child
+		}
+	}
+	if len(root) == 0 {
+		return nil, nil
+	}
+	return root, nil
+}
+
+// ParseExpand parses a comma-separated ?expand= list, allowing only the given names
+func ParseExpand(raw string, allowed ...string) (map[string]bool, error) {
+	expand := map[string]bool{}
+	for _, name := range strings.Split(raw, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		valid := false
+		for _, a := range allowed {
+			valid = valid || a == name
+		}
+		if !valid {
+			return nil, fmt.Errorf("cannot expand %q; expandable: %s", name, strings.Join(allowed, ", "))
+		}
+		expand[name] = true
+	}
+	return expand, nil
+}
+
+// ParseListSelection reads ?fields= and ?expand= of a list request. On invalid
+// values it answers 400 and returns false.
+func ParseListSelection(c *gin.Context, expandable ...string) (FieldSelection, map[string]bool, bool) {
+	fields, err := ParseFieldSelection(c.Query("fields"))
+	if err == nil {
+		var expand map[string]bool
+		if expand, err = ParseExpand(c.Query("expand"), expandable...); err == nil {
+			return fields, expand, true
+		}
+	}
+	c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	return nil, nil, false
+}
+
+// ToJSONValue converts a response value to its generic JSON form, so list items can
+// be extended with expansions and cut down by a FieldSelection
+func ToJSONValue(v interface{}) (interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	// Keep numbers as written rather than as float64
+	dec.UseNumber()
+	var out interface{}
+	if err := dec.Decode(&out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Select returns the selected fields of a generic JSON object. Paths through arrays
+// apply to each element; paths that don't exist are left out.
+func (s FieldSelection) Select(obj map[string]interface{}) map[string]interface{} {
+	if s == nil {
+		return obj
+	}
+	v, _ := s.selectValue(obj)
+	out, _ := v.(map[string]interface{})
+	return out
+}
+
+func (s FieldSelection) selectValue(v interface{}) (interface{}, bool) {
+	switch x := v.(type) {
+	case map[string]interface{}:
+		out := map[string]interface{}{}
+		for key, child := range s {
+			val, ok := x[key]
+			if !ok {
+				continue
+			}
+			if child == nil {
+				out[key] = val
+			} else if selected, ok := child.selectValue(val); ok {
+				out[key] = selected
+			}
+		}
+		return out, true
+	case []interface{}:
+		out := make([]interface{}, 0, len(x))
+		for _, elem := range x {
+			if selected, ok := s.selectValue(elem); ok {
+				out = append(out, selected)
+			}
+		}
+		return out, true
+	}
+	// A path into a scalar selects nothing
+	return nil, false
+}
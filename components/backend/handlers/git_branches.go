@@ -0,0 +1,278 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"ambient-code-backend/git"
+	"ambient-code-backend/logging"
+	"ambient-code-backend/types"
+
+	"github.com/gin-gonic/gin"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Package-level git dependency (mockable in tests)
+var CheckBranchPushable = git.CheckBranchPushable
+
+// contentServiceGitClient bounds git operations proxied to a session's content
+// service; pushes of large histories can take a while
+var contentServiceGitClient = &http.Client{Timeout: 2 * time.Minute}
+
+// CreateSessionGitBranch creates and checks out a working branch in a workspace repo
+// Body: { path: string, branchName: string }
+// POST /api/projects/:projectName/agentic-sessions/:sessionName/git/branches
+func CreateSessionGitBranch(c *gin.Context) {
+	project := c.Param("projectName")
+	sessionName := c.Param("sessionName")
+
+	k8sClt, _ := GetK8sClientsForRequest(c)
+	if k8sClt == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing token"})
+		c.Abort()
+		return
+	}
+
+	var body struct {
+		Path       string `json:"path" binding:"required"`
+		BranchName string `json:"branchName" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "path and branchName are required"})
+		return
+	}
+	if err := git.ValidateBranchName(body.BranchName); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	endpoint := fmt.Sprintf("http://%s.%s.svc:8080/content/git-create-branch", getContentServiceName(sessionName), project)
+	proxyContentServiceJSON(c, endpoint, body, nil, "CreateSessionGitBranch")
+}
+
+// PushSessionGitBranch commits and pushes a workspace repo using the session owner's stored token.
+// Only the owner can call it, so other users with update access can't push as the owner.
+// The target branch is checked against the remote's protection rules first.
+// Body: { path: string, branch: string, message?: string }
+// POST /api/projects/:projectName/agentic-sessions/:sessionName/git/push
+func PushSessionGitBranch(c *gin.Context) {
+	project := c.Param("projectName")
+	sessionName := c.Param("sessionName")
+
+	k8sClt, k8sDyn := GetK8sClientsForRequest(c)
+	if k8sClt == nil || k8sDyn == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing token"})
+		c.Abort()
+		return
+	}
+
+	var body struct {
+		Path    string `json:"path" binding:"required"`
+		Branch  string `json:"branch" binding:"required"`
+		Message string `json:"message"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "path and branch are required"})
+		return
+	}
+
+	userID, repoURL := sessionOwnerAndRepo(c.Request.Context(), k8sDyn, project, sessionName, body.Path)
+	if userID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Session has no owner to resolve git credentials for"})
+		return
+	}
+	if c.GetString("userID") != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the session owner can push with their git credentials"})
+		return
+	}
+
+	headers := map[string]string{}
+	if GetGitHubToken != nil {
+		if token, err := GetGitHubToken(c.Request.Context(), k8sClt, k8sDyn, project, userID); err == nil && token != "" {
+			headers["X-GitHub-Token"] = token
+		}
+	}
+	if GetGitLabToken != nil {
+		if token, err := GetGitLabToken(c.Request.Context(), k8sClt, project, userID); err == nil && token != "" {
+			headers["X-GitLab-Token"] = token
+		}
+	}
+
+	if repoURL != "" {
+		check, err := CheckBranchPushable(c.Request.Context(), repoURL, body.Branch, gitTokenForProvider(repoURL, headers))
+		if err != nil {
+			logging.Warnf(c, "PushSessionGitBranch: protection check for %s failed (continuing): %v", repoURL, err)
+		} else if !check.CanPush {
+			c.JSON(http.StatusConflict, gin.H{"error": check.Reason, "check": check})
+			return
+		}
+	}
+
+	endpoint := fmt.Sprintf("http://%s.%s.svc:8080/content/git-push", getContentServiceName(sessionName), project)
+	proxyContentServiceJSON(c, endpoint, body, headers, "PushSessionGitBranch")
+}
+
+// CheckBranchProtection reports whether the caller can push to a branch of a repository
+// GET /api/projects/:projectName/git/branch-protection?repoUrl=&branch=
+func CheckBranchProtection(c *gin.Context) {
+	project := c.GetString("project")
+	repoURL := strings.TrimSpace(c.Query("repoUrl"))
+	branch := strings.TrimSpace(c.Query("branch"))
+	if repoURL == "" || branch == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "repoUrl and branch are required"})
+		return
+	}
+
+	k8sClt, k8sDyn := GetK8sClientsForRequest(c)
+	if k8sClt == nil || k8sDyn == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing token"})
+		c.Abort()
+		return
+	}
+
+	userID := strings.TrimSpace(c.GetString("userID"))
+	token := resolveUserGitToken(c.Request.Context(), k8sClt, k8sDyn, project, userID, repoURL)
+	check, err := CheckBranchPushable(c.Request.Context(), repoURL, branch, token)
+	if err != nil {
+		logging.Infof(c, "CheckBranchProtection: %s@%s: %v", repoURL, branch, err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, check)
+}
+
+// validateAutoPushBranches rejects session creation when an autoPush repo targets a branch
+// the user cannot push to, so the failure surfaces before the run instead of mid-run.
+// Provider API errors are logged and do not block creation.
+func validateAutoPushBranches(ctx context.Context, k8sClt kubernetes.Interface, k8sDyn dynamic.Interface, project, userID string, repos []types.SimpleRepo) error {
+	for _, r := range repos {
+		if r.AutoPush == nil || !*r.AutoPush || r.Branch == nil || strings.TrimSpace(*r.Branch) == "" {
+			continue
+		}
+		branch := strings.TrimSpace(*r.Branch)
+		token := resolveUserGitToken(ctx, k8sClt, k8sDyn, project, userID, r.URL)
+		if token == "" {
+			// Credentials may still be supplied at runtime; nothing to check against
+			continue
+		}
+		check, err := CheckBranchPushable(ctx, r.URL, branch, token)
+		if err != nil {
+			logging.Warnf(ctx, "validateAutoPushBranches: protection check for %s@%s failed (continuing): %v", r.URL, branch, err)
+			continue
+		}
+		if !check.CanPush {
+			return fmt.Errorf("%s (%s)", check.Reason, r.URL)
+		}
+	}
+	return nil
+}
+
+// resolveUserGitToken returns the user's token for the repository's provider, or "".
+func resolveUserGitToken(ctx context.Context, k8sClt kubernetes.Interface, k8sDyn dynamic.Interface, project, userID, repoURL string) string {
+	if userID == "" {
+		return ""
+	}
+	var token string
+	var err error
+	switch types.DetectProvider(repoURL) {
+	case types.ProviderGitHub:
+		if GetGitHubToken != nil {
+			token, err = GetGitHubToken(ctx, k8sClt, k8sDyn, project, userID)
+		}
+	case types.ProviderGitLab:
+		if GetGitLabToken != nil {
+			token, err = GetGitLabToken(ctx, k8sClt, project, userID)
+		}
+	}
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
+
+// gitTokenForProvider picks the forwarded token header matching the repository's provider
+func gitTokenForProvider(repoURL string, headers map[string]string) string {
+	if types.DetectProvider(repoURL) == types.ProviderGitLab {
+		return headers["X-GitLab-Token"]
+	}
+	return headers["X-GitHub-Token"]
+}
+
+// sessionOwnerAndRepo returns the session's userId and, when the workspace path is
+// repos/<name>, the URL of the matching spec repo.
+func sessionOwnerAndRepo(ctx context.Context, k8sDyn dynamic.Interface, project, sessionName, path string) (string, string) {
+	gvr := GetAgenticSessionV1Alpha1Resource()
+	obj, err := k8sDyn.Resource(gvr).Namespace(project).Get(ctx, sessionName, v1.GetOptions{})
+	if err != nil {
+		return "", ""
+	}
+	var userID, repoURL string
+	if uc, found, _ := unstructured.NestedMap(obj.Object, "spec", "userContext"); found {
+		if v, ok := uc["userId"].(string); ok {
+			userID = strings.TrimSpace(v)
+		}
+	}
+	repoName := strings.TrimPrefix(strings.Trim(path, "/"), "repos/")
+	repos, _, _ := unstructured.NestedSlice(obj.Object, "spec", "repos")
+	for _, entry := range repos {
+		m, ok := entry.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		u, _ := m["url"].(string)
+		if u != "" && git.DeriveRepoFolderFromURL(u) == repoName {
+			repoURL = u
+			break
+		}
+	}
+	return userID, repoURL
+}
+
+// proxyContentServiceJSON POSTs body to the session's content service and relays the response
+func proxyContentServiceJSON(c *gin.Context, endpoint string, body interface{}, headers map[string]string, logPrefix string) {
+	reqBody, err := json.Marshal(body)
+	if err != nil {
+		logging.Errorf(c, "%s: failed to marshal request: %v", logPrefix, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to prepare request"})
+		return
+	}
+
+	req, err := http.NewRequestWithContext(c.Request.Context(), http.MethodPost, endpoint, bytes.NewReader(reqBody))
+	if err != nil {
+		logging.Errorf(c, "%s: failed to create HTTP request: %v", logPrefix, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create request"})
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if v := c.GetHeader("Authorization"); v != "" {
+		req.Header.Set("Authorization", v)
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	logging.SetRequestIDHeader(req)
+	resp, err := contentServiceGitClient.Do(req)
+	if err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "content service unavailable"})
+		return
+	}
+	defer resp.Body.Close()
+
+	bodyBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		logging.Errorf(c, "%s: failed to read response body: %v", logPrefix, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read response from content service"})
+		return
+	}
+	c.Data(resp.StatusCode, resp.Header.Get("Content-Type"), bodyBytes)
+}
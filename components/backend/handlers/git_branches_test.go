@@ -0,0 +1,101 @@
+//go:build test
+
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"ambient-code-backend/git"
+	test_constants "ambient-code-backend/tests/constants"
+	"ambient-code-backend/types"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+var _ = Describe("Git Branch Validation", Label(test_constants.LabelUnit, test_constants.LabelHandlers, test_constants.LabelGit), func() {
+	var (
+		originalCheck          func(context.Context, string, string, string) (*git.BranchPushCheck, error)
+		originalGetGitHubToken func(context.Context, kubernetes.Interface, dynamic.Interface, string, string) (string, error)
+	)
+
+	BeforeEach(func() {
+		originalCheck = CheckBranchPushable
+		originalGetGitHubToken = GetGitHubToken
+		GetGitHubToken = func(ctx context.Context, k kubernetes.Interface, d dynamic.Interface, project, userID string) (string, error) {
+			return "test-token", nil
+		}
+	})
+
+	AfterEach(func() {
+		CheckBranchPushable = originalCheck
+		GetGitHubToken = originalGetGitHubToken
+	})
+
+	It("Should reject autoPush repos targeting a protected branch", func() {
+		CheckBranchPushable = func(ctx context.Context, repoURL, branch, token string) (*git.BranchPushCheck, error) {
+			return &git.BranchPushCheck{RepoURL: repoURL, Branch: branch, Exists: true, Protected: true,
+				Reason: "you can't push to main: the branch is protected"}, nil
+		}
+		repos := []types.SimpleRepo{{URL: "https://github.com/org/app", Branch: types.StringPtr("main"), AutoPush: types.BoolPtr(true)}}
+
+		err := validateAutoPushBranches(context.Background(), nil, nil, "project", "alice", repos)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("you can't push to main"))
+	})
+
+	It("Should only push with the owner's credentials for the owner", func() {
+		gvr := schema.GroupVersionResource{Group: "vteam.ambient-code", Version: "v1alpha1", Resource: "agenticsessions"}
+		origK8s, origDyn, origGVR := K8sClientMw, DynamicClient, GetAgenticSessionV1Alpha1Resource
+		defer func() { K8sClientMw, DynamicClient, GetAgenticSessionV1Alpha1Resource = origK8s, origDyn, origGVR }()
+		GetAgenticSessionV1Alpha1Resource = func() schema.GroupVersionResource { return gvr }
+		K8sClientMw = fake.NewSimpleClientset()
+		DynamicClient = dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "vteam.ambient-code/v1alpha1",
+			"kind":       "AgenticSession",
+			"metadata":   map[string]interface{}{"name": "session-1", "namespace": "team-a"},
+			"spec":       map[string]interface{}{"userContext": map[string]interface{}{"userId": "alice"}},
+		}})
+		tokenFetched := false
+		GetGitHubToken = func(context.Context, kubernetes.Interface, dynamic.Interface, string, string) (string, error) {
+			tokenFetched = true
+			return "test-token", nil
+		}
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"path":"repos/app","branch":"fix"}`))
+		c.Request.Header.Set("Authorization", "Bearer collaborator-token")
+		c.Set("userID", "bob")
+		c.Params = gin.Params{{Key: "projectName", Value: "team-a"}, {Key: "sessionName", Value: "session-1"}}
+		PushSessionGitBranch(c)
+		Expect(w.Code).To(Equal(http.StatusForbidden))
+		Expect(tokenFetched).To(BeFalse())
+	})
+
+	It("Should skip repos without autoPush or an explicit branch", func() {
+		called := false
+		CheckBranchPushable = func(ctx context.Context, repoURL, branch, token string) (*git.BranchPushCheck, error) {
+			called = true
+			return &git.BranchPushCheck{}, nil
+		}
+		repos := []types.SimpleRepo{
+			{URL: "https://github.com/org/app", Branch: types.StringPtr("main")},
+			{URL: "https://github.com/org/lib", AutoPush: types.BoolPtr(true)},
+		}
+
+		Expect(validateAutoPushBranches(context.Background(), nil, nil, "project", "alice", repos)).To(Succeed())
+		Expect(called).To(BeFalse())
+	})
+})
@@ -9,13 +9,15 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"log"
 	"net/http"
 	"os"
 	"strconv"
 	"strings"
 	"time"
 
+	"ambient-code-backend/logging"
+	"ambient-code-backend/metrics"
+
 	"github.com/gin-gonic/gin"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
@@ -125,7 +127,7 @@ func doGitHubRequest(ctx context.Context, method string, url string, authHeader
 			req.Header.Set("If-None-Match", s)
 		}
 	}
-	client := &http.Client{Timeout: 15 * time.Second}
+	client := &http.Client{Timeout: 15 * time.Second, Transport: metrics.Transport("github", "api", nil)}
 	return client.Do(req)
 }
 
@@ -358,18 +360,18 @@ func GetGitHubInstallation(ctx context.Context, userID string) (*GitHubAppInstal
 	cm, err := K8sClient.CoreV1().ConfigMaps(Namespace).Get(ctx, cmName, v1.GetOptions{})
 	if err != nil {
 		if errors.IsNotFound(err) {
-			log.Printf("GetGitHubInstallation: ConfigMap %s not found for user=%s", cmName, userID)
+			logging.Errorf(ctx, "GetGitHubInstallation: ConfigMap %s not found for user=%s", cmName, userID)
 			return nil, fmt.Errorf("installation not found")
 		}
 		return nil, fmt.Errorf("failed to read ConfigMap: %w", err)
 	}
 	if cm.Data == nil {
-		log.Printf("GetGitHubInstallation: no data in ConfigMap for user=%s", userID)
+		logging.Infof(ctx, "GetGitHubInstallation: no data in ConfigMap for user=%s", userID)
 		return nil, fmt.Errorf("installation not found")
 	}
 	raw, ok := cm.Data[userID]
 	if !ok || raw == "" {
-		log.Printf("GetGitHubInstallation: no entry for user=%s in ConfigMap", userID)
+		logging.Infof(ctx, "GetGitHubInstallation: no entry for user=%s in ConfigMap", userID)
 		return nil, fmt.Errorf("installation not found")
 	}
 	var inst GitHubAppInstallation
@@ -414,7 +416,7 @@ func LinkGitHubInstallationGlobal(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
-	log.Printf("LinkGitHubInstallationGlobal: user=%s installationId=%d codePresent=%v", userIDStr, req.InstallationID, req.Code != "")
+	logging.Infof(c, "LinkGitHubInstallationGlobal: user=%s installationId=%d codePresent=%v", userIDStr, req.InstallationID, req.Code != "")
 	installation := GitHubAppInstallation{
 		UserID:         userIDStr,
 		InstallationID: req.InstallationID,
@@ -428,18 +430,18 @@ func LinkGitHubInstallationGlobal(c *gin.Context) {
 	if req.Code != "" && clientID != "" && clientSecret != "" {
 		token, err := exchangeOAuthCodeForUserToken(clientID, clientSecret, req.Code)
 		if err != nil {
-			log.Printf("LinkGitHubInstallationGlobal: OAuth code exchange failed for user=%s: %v", userIDStr, err)
+			logging.Errorf(c, "LinkGitHubInstallationGlobal: OAuth code exchange failed for user=%s: %v", userIDStr, err)
 			// Fall through to best-effort enrichment below
 		} else {
 			owns, login, err := userOwnsInstallation(token, req.InstallationID)
 			if err != nil {
-				log.Printf("LinkGitHubInstallationGlobal: ownership verification failed for user=%s: %v", userIDStr, err)
+				logging.Errorf(c, "LinkGitHubInstallationGlobal: ownership verification failed for user=%s: %v", userIDStr, err)
 			} else if !owns {
-				log.Printf("LinkGitHubInstallationGlobal: user=%s does not own installation %d", userIDStr, req.InstallationID)
+				logging.Warnf(c, "LinkGitHubInstallationGlobal: user=%s does not own installation %d", userIDStr, req.InstallationID)
 				c.JSON(http.StatusForbidden, gin.H{"error": "installation not owned by user"})
 				return
 			} else {
-				log.Printf("LinkGitHubInstallationGlobal: verified ownership via OAuth for user=%s login=%s", userIDStr, login)
+				logging.Infof(c, "LinkGitHubInstallationGlobal: verified ownership via OAuth for user=%s login=%s", userIDStr, login)
 				installation.GitHubUserID = login
 			}
 		}
@@ -581,12 +583,12 @@ func SaveGitHubPAT(c *gin.Context) {
 	}
 
 	if err := storeGitHubPATCredentials(c.Request.Context(), creds); err != nil {
-		log.Printf("Failed to store GitHub PAT for user %s: %v", userID, err)
+		logging.Errorf(c, "Failed to store GitHub PAT for user %s: %v", userID, err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save GitHub PAT"})
 		return
 	}
 
-	log.Printf("✓ Stored GitHub PAT for user %s", userID)
+	logging.Infof(c, "✓ Stored GitHub PAT for user %s", userID)
 	c.JSON(http.StatusOK, gin.H{"message": "GitHub PAT saved successfully"})
 }
 
@@ -608,7 +610,7 @@ func GetGitHubPATStatus(c *gin.Context) {
 
 	creds, err := GetGitHubPATCredentials(c.Request.Context(), userID)
 	if err != nil {
-		log.Printf("Failed to get GitHub PAT for user %s: %v", userID, err)
+		logging.Errorf(c, "Failed to get GitHub PAT for user %s: %v", userID, err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check GitHub PAT status"})
 		return
 	}
@@ -641,12 +643,12 @@ func DeleteGitHubPAT(c *gin.Context) {
 	}
 
 	if err := DeleteGitHubPATCredentials(c.Request.Context(), userID); err != nil {
-		log.Printf("Failed to delete GitHub PAT for user %s: %v", userID, err)
+		logging.Errorf(c, "Failed to delete GitHub PAT for user %s: %v", userID, err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove GitHub PAT"})
 		return
 	}
 
-	log.Printf("✓ Deleted GitHub PAT for user %s", userID)
+	logging.Infof(c, "✓ Deleted GitHub PAT for user %s", userID)
 	c.JSON(http.StatusOK, gin.H{"message": "GitHub PAT removed successfully"})
 }
 
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"ambient-code-backend/git"
+	"ambient-code-backend/logging"
+	"ambient-code-backend/types"
+
+	"github.com/gin-gonic/gin"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+)
+
+// githubIssueAnnotation records the GitHub issue or pull request a session was created from
+const githubIssueAnnotation = "ambient-code.io/github-issue"
+
+// fetchGitHubIssue reads the issue or pull request named by fromGitHubIssue with the
+// caller's GitHub token. On failure it writes the response and returns false.
+func fetchGitHubIssue(c *gin.Context, reqK8s kubernetes.Interface, reqDyn dynamic.Interface, project, ref string) (*git.GitHubIssue, bool) {
+	issueRef, err := git.ParseGitHubIssueRef(ref)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "fromGitHubIssue: " + err.Error()})
+		return nil, false
+	}
+	token := ""
+	if GetGitHubToken != nil {
+		token, err = GetGitHubToken(c.Request.Context(), reqK8s, reqDyn, project, c.GetString("userID"))
+	}
+	if err != nil || strings.TrimSpace(token) == "" {
+		c.JSON(http.StatusPreconditionFailed, gin.H{"error": "GitHub is not connected; connect it to import issues"})
+		return nil, false
+	}
+
+	issue, err := git.FetchGitHubIssue(c.Request.Context(), token, issueRef)
+	switch {
+	case err == nil:
+		return issue, true
+	case errors.Is(err, git.ErrGitHubUnauthorized):
+		c.JSON(http.StatusPreconditionFailed, gin.H{"error": "GitHub rejected your token for " + issueRef.String() + "; reconnect GitHub"})
+	case errors.Is(err, git.ErrGitHubNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "GitHub issue " + issueRef.String() + " not found"})
+	default:
+		logging.Infof(c, "Failed to fetch GitHub issue %s: %v", issueRef, err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to fetch GitHub issue: " + err.Error()})
+	}
+	return nil, false
+}
+
+// applyGitHubIssue makes the issue or pull request the session's initial context,
+// like applyJiraIssue. Sessions without repos clone its repository: pull requests on
+// their head branch, so the agent continues the PR.
+func applyGitHubIssue(req *types.CreateAgenticSessionRequest, issue *git.GitHubIssue) {
+	ref := issue.Ref.String()
+	prompt := strings.TrimSpace(req.InitialPrompt)
+	if prompt == "" {
+		if issue.PullRequest != nil {
+			prompt = "Work on pull request " + ref + " described below: address the review comments and failing checks, then push to its branch."
+		} else {
+			prompt = "Work on GitHub issue " + ref + " described below: make the changes it asks for and verify them."
+		}
+	}
+	req.InitialPrompt = prompt + "\n\n" + issue.Context()
+
+	if strings.TrimSpace(req.DisplayName) == "" {
+		req.DisplayName = issueDisplayName(ref, issue.Title)
+	}
+	if len(req.Repos) == 0 {
+		url, branch := issue.SessionRepo()
+		repo := types.SimpleRepo{URL: url}
+		if branch != "" {
+			repo.Branch = &branch
+		}
+		req.Repos = []types.SimpleRepo{repo}
+	}
+	if req.Annotations == nil {
+		req.Annotations = map[string]string{}
+	}
+	req.Annotations[githubIssueAnnotation] = ref
+}
@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"ambient-code-backend/logging"
+	"ambient-code-backend/types"
+
+	"github.com/gin-gonic/gin"
+	"k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+)
+
+// maxGuardrailRules bounds the rules every streamed event is checked against
+const maxGuardrailRules = 50
+
+// validateGuardrailPolicy checks a policy before it is stored
+func validateGuardrailPolicy(p types.GuardrailPolicy) error {
+	if len(p.Rules) > maxGuardrailRules {
+		return fmt.Errorf("a policy may have at most %d rules", maxGuardrailRules)
+	}
+	_, err := types.CompileGuardrailPolicy(p)
+	return err
+}
+
+// guardrailPolicyFromSettings reads spec.guardrailPolicy from a ProjectSettings object
+func guardrailPolicyFromSettings(obj *unstructured.Unstructured) (*types.GuardrailPolicy, error) {
+	raw, found, err := unstructured.NestedMap(obj.Object, "spec", "guardrailPolicy")
+	if err != nil || !found {
+		return nil, err
+	}
+	b, err := json.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+	var policy types.GuardrailPolicy
+	if err := json.Unmarshal(b, &policy); err != nil {
+		return nil, err
+	}
+	if err := validateGuardrailPolicy(policy); err != nil {
+		return nil, fmt.Errorf("invalid guardrailPolicy: %w", err)
+	}
+	return &policy, nil
+}
+
+// getGuardrailPolicy returns the project's guardrail policy, or nil if none is configured
+func getGuardrailPolicy(ctx context.Context, dynClient dynamic.Interface, project string) (*types.GuardrailPolicy, error) {
+	obj, err := dynClient.Resource(GetProjectSettingsResource()).Namespace(project).Get(ctx, "projectsettings", v1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return guardrailPolicyFromSettings(obj)
+}
+
+// GuardrailEngineForProject compiles the project's current guardrail policy for a new
+// run. It returns nil when the feature is off or no policy is configured.
+func GuardrailEngineForProject(ctx context.Context, project string) *types.GuardrailEngine {
+	if DynamicClient == nil || !FeatureEnabled(ctx, project, FeatureGuardrails) {
+		return nil
+	}
+	policy, err := getGuardrailPolicy(ctx, DynamicClient, project)
+	if err != nil {
+		logging.Errorf(ctx, "Failed to load guardrail policy for project %s: %v", project, err)
+		return nil
+	}
+	if policy == nil || len(policy.Rules) == 0 {
+		return nil
+	}
+	engine, err := types.CompileGuardrailPolicy(*policy)
+	if err != nil {
+		logging.Errorf(ctx, "Ignoring invalid guardrail policy for project %s: %v", project, err)
+		return nil
+	}
+	return engine
+}
+
+// GetGuardrailPolicy handles GET /api/projects/:projectName/guardrail-policy
+func GetGuardrailPolicy(c *gin.Context) {
+	project := c.GetString("project")
+	_, reqDyn := GetK8sClientsForRequest(c)
+	if reqDyn == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing token"})
+		c.Abort()
+		return
+	}
+
+	policy, err := getGuardrailPolicy(c.Request.Context(), reqDyn, project)
+	if err != nil {
+		if errors.IsForbidden(err) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions to read project settings"})
+			return
+		}
+		logging.Errorf(c, "Failed to get guardrail policy for project %s: %v", project, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get guardrail policy"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"policy": policy})
+}
+
+// UpdateGuardrailPolicy handles PUT /api/projects/:projectName/guardrail-policy
+// Requires update permission on ProjectSettings (project admins). Applies to runs started afterwards.
+func UpdateGuardrailPolicy(c *gin.Context) {
+	project := c.GetString("project")
+	_, reqDyn := GetK8sClientsForRequest(c)
+	if reqDyn == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing token"})
+		c.Abort()
+		return
+	}
+
+	var policy types.GuardrailPolicy
+	if err := c.ShouldBindJSON(&policy); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := validateGuardrailPolicy(policy); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	b, err := json.Marshal(policy)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to encode guardrail policy"})
+		return
+	}
+	var value map[string]interface{}
+	if err := json.Unmarshal(b, &value); err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to encode guardrail policy"})
+		return
+	}
+	if err := setProjectSettingsField(c.Request.Context(), reqDyn, project, "guardrailPolicy", value); err != nil {
+		respondProjectSettingsError(c, project, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"policy": policy})
+}
+
+// DeleteGuardrailPolicy handles DELETE /api/projects/:projectName/guardrail-policy
+func DeleteGuardrailPolicy(c *gin.Context) {
+	project := c.GetString("project")
+	_, reqDyn := GetK8sClientsForRequest(c)
+	if reqDyn == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing token"})
+		c.Abort()
+		return
+	}
+
+	if err := setProjectSettingsField(c.Request.Context(), reqDyn, project, "guardrailPolicy", nil); err != nil && !errors.IsNotFound(err) {
+		respondProjectSettingsError(c, project, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Guardrail policy removed successfully"})
+}
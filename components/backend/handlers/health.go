@@ -1,12 +1,170 @@
 package handlers
 
 import (
+	"context"
+	"fmt"
 	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"ambient-code-backend/logging"
 
 	"github.com/gin-gonic/gin"
 )
 
+// healthCheckTimeout bounds each dependency check so probes answer within their timeout
+const healthCheckTimeout = 2 * time.Second
+
+// Component check results
+const (
+	componentOK     = "ok"
+	componentFailed = "failed"
+)
+
+// ComponentStatus is the result of one dependency check
+type ComponentStatus struct {
+	Status    string `json:"status"`
+	Message   string `json:"message,omitempty"`
+	LatencyMs int64  `json:"latencyMs"`
+}
+
+// healthCheck is a named dependency check
+type healthCheck struct {
+	name  string
+	check func(ctx context.Context) error
+}
+
 // Health returns a simple health check handler
 func Health(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"status": "healthy"})
 }
+
+// Healthz is the liveness probe. It only checks in-process state, so an
+// unreachable Kubernetes API does not get the pod restarted.
+// GET /healthz
+func Healthz(c *gin.Context) {
+	respondHealth(c, []healthCheck{
+		{"kubernetesClient", checkKubernetesClient},
+		{"dynamicClient", checkDynamicClient},
+	})
+}
+
+// Readyz is the readiness probe. It checks every dependency the API needs to
+// serve traffic; OAuth providers are checked when READYZ_OAUTH_PROVIDERS lists them.
+// GET /readyz
+func Readyz(c *gin.Context) {
+	checks := []healthCheck{
+		{"kubernetesClient", checkKubernetesClient},
+		{"dynamicClient", checkDynamicClient},
+		{"kubernetesApi", checkKubernetesAPI},
+		{"eventStore", checkEventStore},
+	}
+	if providers := os.Getenv("READYZ_OAUTH_PROVIDERS"); strings.TrimSpace(providers) != "" {
+		checks = append(checks, healthCheck{"oauth", func(context.Context) error { return checkOAuthConfig(providers) }})
+	}
+	respondHealth(c, checks)
+}
+
+// respondHealth runs checks concurrently and answers 200 if all pass, else 503
+func respondHealth(c *gin.Context, checks []healthCheck) {
+	ctx, cancel := context.WithTimeout(c.Request.Context(), healthCheckTimeout)
+	defer cancel()
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	components := make(map[string]ComponentStatus, len(checks))
+	healthy := true
+	for _, hc := range checks {
+		wg.Add(1)
+		go func(hc healthCheck) {
+			defer wg.Done()
+			start := time.Now()
+			err := hc.check(ctx)
+			status := ComponentStatus{Status: componentOK, LatencyMs: time.Since(start).Milliseconds()}
+			if err != nil {
+				status.Status = componentFailed
+				status.Message = err.Error()
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			components[hc.name] = status
+			if err != nil {
+				healthy = false
+			}
+		}(hc)
+	}
+	wg.Wait()
+
+	if !healthy {
+		logging.Warnf(c, "%s failed: %v", c.Request.URL.Path, components)
+		c.JSON(http.StatusServiceUnavailable, gin.H{"status": "unavailable", "components": components})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": componentOK, "components": components})
+}
+
+func checkKubernetesClient(context.Context) error {
+	if K8sClient == nil {
+		return fmt.Errorf("not initialized")
+	}
+	return nil
+}
+
+func checkDynamicClient(context.Context) error {
+	if DynamicClient == nil {
+		return fmt.Errorf("not initialized")
+	}
+	return nil
+}
+
+// checkKubernetesAPI verifies the API server answers with the backend's credentials
+func checkKubernetesAPI(ctx context.Context) error {
+	if K8sClient == nil {
+		return fmt.Errorf("client not initialized")
+	}
+	done := make(chan error, 1)
+	go func() {
+		_, err := K8sClient.Discovery().ServerVersion()
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			return fmt.Errorf("unreachable: %v", err)
+		}
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("unreachable: %v", ctx.Err())
+	}
+}
+
+// checkEventStore verifies the AG-UI event store directory is writable
+func checkEventStore(context.Context) error {
+	if StateBaseDir == "" {
+		return fmt.Errorf("state directory not configured")
+	}
+	f, err := os.CreateTemp(StateBaseDir, ".readyz-*")
+	if err != nil {
+		return fmt.Errorf("not writable: %v", err)
+	}
+	f.Close()
+	return os.Remove(f.Name())
+}
+
+// checkOAuthConfig verifies the state secret and each listed provider are configured
+func checkOAuthConfig(providers string) error {
+	if os.Getenv("OAUTH_STATE_SECRET") == "" {
+		return fmt.Errorf("OAUTH_STATE_SECRET not configured")
+	}
+	for _, p := range strings.Split(providers, ",") {
+		if p = strings.TrimSpace(p); p == "" {
+			continue
+		}
+		if _, err := getOAuthProvider(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -12,6 +12,11 @@ import (
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
 )
 
 var _ = Describe("Health Handler", Label(test_constants.LabelUnit, test_constants.LabelHandlers, test_constants.LabelHealth), func() {
@@ -83,6 +88,75 @@ var _ = Describe("Health Handler", Label(test_constants.LabelUnit, test_constant
 		})
 	})
 
+	Context("When probing liveness and readiness", func() {
+		var (
+			originalK8sClient     kubernetes.Interface
+			originalDynamicClient dynamic.Interface
+			originalStateBaseDir  string
+		)
+
+		BeforeEach(func() {
+			originalK8sClient = K8sClient
+			originalDynamicClient = DynamicClient
+			originalStateBaseDir = StateBaseDir
+			K8sClient = fake.NewSimpleClientset()
+			DynamicClient = dynamicfake.NewSimpleDynamicClient(runtime.NewScheme())
+			StateBaseDir = GinkgoT().TempDir()
+		})
+
+		AfterEach(func() {
+			K8sClient = originalK8sClient
+			DynamicClient = originalDynamicClient
+			StateBaseDir = originalStateBaseDir
+		})
+
+		components := func() map[string]interface{} {
+			var body map[string]interface{}
+			httpUtils.GetResponseJSON(&body)
+			return body["components"].(map[string]interface{})
+		}
+
+		It("Should report ready when all dependencies are available", func() {
+			Readyz(httpUtils.CreateTestGinContext("GET", "/readyz", nil))
+
+			httpUtils.AssertHTTPStatus(http.StatusOK)
+			httpUtils.AssertJSONContains(map[string]interface{}{"status": "ok"})
+			Expect(components()).To(HaveKey("kubernetesApi"))
+			Expect(components()).To(HaveKey("eventStore"))
+			Expect(components()).NotTo(HaveKey("oauth"))
+		})
+
+		It("Should report unavailable with the failing component", func() {
+			DynamicClient = nil
+			StateBaseDir = "/nonexistent/state"
+			Readyz(httpUtils.CreateTestGinContext("GET", "/readyz", nil))
+
+			httpUtils.AssertHTTPStatus(http.StatusServiceUnavailable)
+			Expect(components()["dynamicClient"]).To(HaveKeyWithValue("status", "failed"))
+			Expect(components()["eventStore"]).To(HaveKeyWithValue("status", "failed"))
+			Expect(components()["kubernetesClient"]).To(HaveKeyWithValue("status", "ok"))
+		})
+
+		It("Should check OAuth providers only when requested", func() {
+			GinkgoT().Setenv("READYZ_OAUTH_PROVIDERS", "google")
+			GinkgoT().Setenv("OAUTH_STATE_SECRET", "secret")
+			GinkgoT().Setenv("GOOGLE_OAUTH_CLIENT_ID", "")
+			Readyz(httpUtils.CreateTestGinContext("GET", "/readyz", nil))
+
+			httpUtils.AssertHTTPStatus(http.StatusServiceUnavailable)
+			Expect(components()["oauth"]).To(HaveKeyWithValue("status", "failed"))
+		})
+
+		It("Should keep liveness independent of external dependencies", func() {
+			StateBaseDir = "/nonexistent/state"
+			Healthz(httpUtils.CreateTestGinContext("GET", "/healthz", nil))
+
+			httpUtils.AssertHTTPStatus(http.StatusOK)
+			Expect(components()).NotTo(HaveKey("kubernetesApi"))
+			Expect(components()).NotTo(HaveKey("eventStore"))
+		})
+	})
+
 	Context("Edge cases", func() {
 		It("Should handle concurrent requests", func() {
 			// Arrange
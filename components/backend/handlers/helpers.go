@@ -3,13 +3,13 @@ package handlers
 import (
 	"context"
 	"fmt"
-	"log"
 	"math"
 	"regexp"
 	"time"
 
+	"ambient-code-backend/logging"
+
 	authv1 "k8s.io/api/authorization/v1"
-	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/kubernetes"
 )
@@ -48,7 +48,7 @@ func RetryWithBackoff(maxRetries int, initialDelay, maxDelay time.Duration, oper
 				if delay > maxDelay {
 					delay = maxDelay
 				}
-				log.Printf("Operation failed (attempt %d/%d), retrying in %v: %v", i+1, maxRetries, delay, err)
+				logging.Warnf(context.Background(), "Operation failed (attempt %d/%d), retrying in %v: %v", i+1, maxRetries, delay, err)
 				time.Sleep(delay)
 				continue
 			}
@@ -71,23 +71,18 @@ func ComputeAutoBranch(sessionName string) string {
 // Returns an error if the user lacks the required permission
 // Accepts kubernetes.Interface for compatibility with dependency injection in tests
 func ValidateSecretAccess(ctx context.Context, k8sClient kubernetes.Interface, namespace, verb string) error {
-	ssar := &authv1.SelfSubjectAccessReview{
-		Spec: authv1.SelfSubjectAccessReviewSpec{
-			ResourceAttributes: &authv1.ResourceAttributes{
-				Group:     "", // core API group for secrets
-				Resource:  "secrets",
-				Verb:      verb, // "create", "get", "update", "delete"
-				Namespace: namespace,
-			},
-		},
-	}
-
-	res, err := k8sClient.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, ssar, v1.CreateOptions{})
+	// Not cached: the caller is unknown here and secret writes are infrequent
+	allowed, err := CheckAccess(ctx, k8sClient, "", authv1.ResourceAttributes{
+		Group:     "", // core API group for secrets
+		Resource:  "secrets",
+		Verb:      verb, // "create", "get", "update", "delete"
+		Namespace: namespace,
+	})
 	if err != nil {
 		return fmt.Errorf("RBAC check failed: %w", err)
 	}
 
-	if !res.Status.Allowed {
+	if !allowed {
 		return fmt.Errorf("user not allowed to %s secrets in namespace %s", verb, namespace)
 	}
 
@@ -0,0 +1,249 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"ambient-code-backend/config"
+	"ambient-code-backend/logging"
+	"ambient-code-backend/metrics"
+	"ambient-code-backend/notifications"
+
+	"github.com/gin-gonic/gin"
+	"k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+)
+
+// Runners of sessions without run or end-user activity for the configured
+// sessionIdleTimeout are paused: the session is stopped like StopSession does, keeping
+// its state, so the pod's CPU and memory reservations are released. The session's next
+// run request resumes it.
+const (
+	// lastActivityAnnotation is when a run or end user last touched the session (RFC3339)
+	lastActivityAnnotation = "ambient-code.io/last-activity-at"
+	// idlePausedAnnotation marks a session paused for inactivity, with the pause time
+	idlePausedAnnotation = "ambient-code.io/idle-paused-at"
+
+	idleSweepInterval = time.Minute
+	// activityWriteInterval throttles writes of lastActivityAnnotation per session
+	activityWriteInterval = time.Minute
+)
+
+var (
+	// When each session's activity was last written, by project/session
+	activityWritten   = make(map[string]time.Time)
+	activityWrittenMu sync.Mutex
+)
+
+// RecordSessionActivity notes run or end-user activity on a session so the idle
+// sweeper leaves it running. The annotation is written in the background, at most
+// once a minute per session, and only while idle pausing is enabled.
+func RecordSessionActivity(project, sessionName string) {
+	if DynamicClient == nil || config.Current().SessionIdleTimeout.Duration <= 0 {
+		return
+	}
+	key := project + "/" + sessionName
+	now := time.Now()
+	activityWrittenMu.Lock()
+	if now.Sub(activityWritten[key]) < activityWriteInterval {
+		activityWrittenMu.Unlock()
+		return
+	}
+	activityWritten[key] = now
+	activityWrittenMu.Unlock()
+
+	go func() {
+		patch, _ := json.Marshal(map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"annotations": map[string]string{lastActivityAnnotation: now.UTC().Format(time.RFC3339)},
+			},
+		})
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		_, err := DynamicClient.Resource(GetAgenticSessionV1Alpha1Resource()).Namespace(project).Patch(ctx, sessionName, k8stypes.MergePatchType, patch, v1.PatchOptions{})
+		if err != nil && !errors.IsNotFound(err) {
+			logging.Errorf(ctx, "Idle sessions: failed to record activity on %s: %v", key, err)
+		}
+	}()
+}
+
+// StartIdleSessionSweeper periodically pauses idle sessions for the life of the process
+func StartIdleSessionSweeper() {
+	if DynamicClient == nil {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(idleSweepInterval)
+		defer ticker.Stop()
+		for range ticker.C {
+			timeout := config.Current().SessionIdleTimeout.Duration
+			if timeout <= 0 {
+				continue
+			}
+			ctx, cancel := context.WithTimeout(context.Background(), idleSweepInterval)
+			pauseIdleSessions(ctx, timeout, time.Now())
+			cancel()
+		}
+	}()
+}
+
+// pauseIdleSessions pauses Running sessions whose last activity is older than timeout.
+// Sessions with a run streaming through this replica are active whatever their
+// annotation says.
+func pauseIdleSessions(ctx context.Context, timeout time.Duration, now time.Time) {
+	sessions, err := listSessionsForSweep(ctx)
+	if err != nil {
+		logging.Errorf(ctx, "Idle sessions: failed to list sessions: %v", err)
+		return
+	}
+	var activeRuns map[string]int
+	if ActiveRunCounts != nil {
+		activeRuns = ActiveRunCounts()
+	}
+
+	for _, item := range sessions {
+		phase, _, _ := unstructured.NestedString(item.Object, "status", "phase")
+		annotations := item.GetAnnotations()
+		if phase != "Running" || annotations["ambient-code.io/desired-phase"] == "Stopped" || activeRuns[item.GetName()] > 0 {
+			continue
+		}
+		idle := now.Sub(lastSessionActivity(item))
+		if idle < timeout {
+			continue
+		}
+		if err := pauseIdleSession(ctx, item, now); err != nil {
+			// A conflict means the session changed since it was listed, e.g. new activity
+			if !errors.IsConflict(err) && !errors.IsNotFound(err) {
+				logging.Errorf(ctx, "Idle sessions: failed to pause %s/%s: %v", item.GetNamespace(), item.GetName(), err)
+			}
+			continue
+		}
+		logging.Infof(ctx, "Idle sessions: paused %s/%s after %s without activity", item.GetNamespace(), item.GetName(), idle.Round(time.Minute))
+		metrics.ObserveSessionIdlePaused()
+		notifySessionPaused(item, idle)
+	}
+
+	// Sessions not touched for a while no longer need their write throttled
+	activityWrittenMu.Lock()
+	for key, at := range activityWritten {
+		if now.Sub(at) > activityWriteInterval {
+			delete(activityWritten, key)
+		}
+	}
+	activityWrittenMu.Unlock()
+}
+
+// listSessionsForSweep returns all sessions, from the informer cache once it synced.
+// The objects are copies that may be modified.
+func listSessionsForSweep(ctx context.Context) ([]*unstructured.Unstructured, error) {
+	var sessions []*unstructured.Unstructured
+	if informer := sessionInformer; informer != nil && informer.Informer().HasSynced() {
+		objs, err := informer.Lister().List(labels.Everything())
+		if err != nil {
+			return nil, err
+		}
+		for _, obj := range objs {
+			if u, ok := obj.(*unstructured.Unstructured); ok {
+				sessions = append(sessions, u.DeepCopy())
+			}
+		}
+		return sessions, nil
+	}
+	list, err := DynamicClient.Resource(GetAgenticSessionV1Alpha1Resource()).Namespace("").List(ctx, v1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	for i := range list.Items {
+		sessions = append(sessions, &list.Items[i])
+	}
+	return sessions, nil
+}
+
+// lastSessionActivity is the latest of the session's recorded activity, its last
+// start request and its start time
+func lastSessionActivity(item *unstructured.Unstructured) time.Time {
+	last := item.GetCreationTimestamp().Time
+	annotations := item.GetAnnotations()
+	startTime, _, _ := unstructured.NestedString(item.Object, "status", "startTime")
+	for _, v := range []string{annotations[lastActivityAnnotation], annotations["ambient-code.io/start-requested-at"], startTime} {
+		if t, err := time.Parse(time.RFC3339, v); err == nil && t.After(last) {
+			last = t
+		}
+	}
+	return last
+}
+
+// pauseIdleSession asks the operator to stop the session's runner. The update carries
+// the listed resourceVersion, so only one backend replica pauses it and a session
+// touched since it was listed is left running.
+func pauseIdleSession(ctx context.Context, item *unstructured.Unstructured, now time.Time) error {
+	annotations := item.GetAnnotations()
+	if annotations == nil {
+		annotations = make(map[string]string)
+	}
+	stamp := now.UTC().Format(time.RFC3339)
+	annotations["ambient-code.io/desired-phase"] = "Stopped"
+	annotations["ambient-code.io/stop-requested-at"] = stamp
+	annotations[idlePausedAnnotation] = stamp
+	item.SetAnnotations(annotations)
+	_, err := DynamicClient.Resource(GetAgenticSessionV1Alpha1Resource()).Namespace(item.GetNamespace()).Update(ctx, item, v1.UpdateOptions{})
+	return err
+}
+
+// notifySessionPaused tells the project's notification rules about a paused session
+func notifySessionPaused(item *unstructured.Unstructured, idle time.Duration) {
+	if Notifier == nil {
+		return
+	}
+	event := notifications.Event{
+		Type:    notifications.EventSessionPaused,
+		Project: item.GetNamespace(),
+		Session: item.GetName(),
+		Detail:  fmt.Sprintf("No activity for %s. The runner was stopped to free its resources; the next run resumes the session.", idle.Round(time.Minute)),
+		URL:     sessionTranscriptURL(item.GetNamespace(), item.GetName()),
+	}
+	event.DisplayName, _, _ = unstructured.NestedString(item.Object, "spec", "displayName")
+	Notifier.Notify(event)
+}
+
+// ResumeIdleSession restarts a session paused for inactivity with the caller's
+// credentials and reports whether it did. Sessions stopped otherwise are left alone.
+func ResumeIdleSession(c *gin.Context, project, sessionName string) (bool, error) {
+	cached, err := GetCachedSession(c.Request.Context(), project, sessionName)
+	if err != nil {
+		return false, err
+	}
+	if cached.GetAnnotations()[idlePausedAnnotation] == "" {
+		return false, nil
+	}
+	_, reqDyn := GetK8sClientsForRequest(c)
+	if reqDyn == nil {
+		return false, fmt.Errorf("invalid or missing token")
+	}
+	gvr := GetAgenticSessionV1Alpha1Resource()
+	item, err := reqDyn.Resource(gvr).Namespace(project).Get(c.Request.Context(), sessionName, v1.GetOptions{})
+	if err != nil {
+		return false, err
+	}
+	annotations := item.GetAnnotations()
+	if annotations[idlePausedAnnotation] == "" {
+		return false, nil
+	}
+	stamp := time.Now().UTC().Format(time.RFC3339)
+	delete(annotations, idlePausedAnnotation)
+	annotations["ambient-code.io/desired-phase"] = "Running"
+	annotations["ambient-code.io/start-requested-at"] = stamp
+	annotations[lastActivityAnnotation] = stamp
+	item.SetAnnotations(annotations)
+	if _, err := reqDyn.Resource(gvr).Namespace(project).Update(c.Request.Context(), item, v1.UpdateOptions{}); err != nil {
+		return false, err
+	}
+	logging.Infof(c, "Idle sessions: resuming %s/%s for a run request", project, sessionName)
+	return true, nil
+}
@@ -0,0 +1,131 @@
+//go:build test
+
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	test_constants "ambient-code-backend/tests/constants"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+var _ = Describe("Idle Sessions", Label(test_constants.LabelUnit, test_constants.LabelHandlers), func() {
+	gvr := schema.GroupVersionResource{Group: "vteam.ambient-code", Version: "v1alpha1", Resource: "agenticsessions"}
+	now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+
+	var (
+		originalK8sClientMw     kubernetes.Interface
+		originalDynamicClient   dynamic.Interface
+		originalGVR             func() schema.GroupVersionResource
+		originalActiveRunCounts func() map[string]int
+		ctx                     context.Context
+	)
+
+	session := func(name, phase string, lastActivity time.Time, annotations map[string]interface{}) *unstructured.Unstructured {
+		if annotations == nil {
+			annotations = map[string]interface{}{}
+		}
+		annotations[lastActivityAnnotation] = lastActivity.Format(time.RFC3339)
+		obj := &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "vteam.ambient-code/v1alpha1",
+			"kind":       "AgenticSession",
+			"metadata":   map[string]interface{}{"name": name, "namespace": "team-a", "annotations": annotations},
+			"spec":       map[string]interface{}{"displayName": name},
+			"status":     map[string]interface{}{"phase": phase, "startTime": now.Add(-48 * time.Hour).Format(time.RFC3339)},
+		}}
+		obj.SetCreationTimestamp(v1.NewTime(now.Add(-72 * time.Hour)))
+		return obj
+	}
+	annotationsOf := func(name string) map[string]string {
+		obj, err := DynamicClient.Resource(gvr).Namespace("team-a").Get(ctx, name, v1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		return obj.GetAnnotations()
+	}
+
+	BeforeEach(func() {
+		originalK8sClientMw, originalDynamicClient, originalGVR = K8sClientMw, DynamicClient, GetAgenticSessionV1Alpha1Resource
+		originalActiveRunCounts = ActiveRunCounts
+		GetAgenticSessionV1Alpha1Resource = func() schema.GroupVersionResource { return gvr }
+		ctx = context.Background()
+	})
+
+	AfterEach(func() {
+		K8sClientMw, DynamicClient, GetAgenticSessionV1Alpha1Resource = originalK8sClientMw, originalDynamicClient, originalGVR
+		ActiveRunCounts = originalActiveRunCounts
+	})
+
+	It("Should take the latest activity of a session", func() {
+		obj := session("s1", "Running", now.Add(-3*time.Hour), map[string]interface{}{
+			"ambient-code.io/start-requested-at": now.Add(-time.Hour).Format(time.RFC3339),
+		})
+		Expect(lastSessionActivity(obj)).To(BeTemporally("==", now.Add(-time.Hour)))
+
+		fresh := &unstructured.Unstructured{Object: map[string]interface{}{"metadata": map[string]interface{}{"name": "s2"}}}
+		fresh.SetCreationTimestamp(v1.NewTime(now))
+		Expect(lastSessionActivity(fresh)).To(BeTemporally("==", now))
+	})
+
+	It("Should pause only running sessions idle longer than the timeout", func() {
+		DynamicClient = dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+			map[schema.GroupVersionResource]string{gvr: "AgenticSessionList"},
+			session("idle", "Running", now.Add(-3*time.Hour), nil),
+			session("recent", "Running", now.Add(-10*time.Minute), nil),
+			session("streaming", "Running", now.Add(-3*time.Hour), nil),
+			session("stopped", "Stopped", now.Add(-3*time.Hour), nil),
+		)
+		ActiveRunCounts = func() map[string]int { return map[string]int{"streaming": 1} }
+
+		pauseIdleSessions(ctx, 2*time.Hour, now)
+
+		idle := annotationsOf("idle")
+		Expect(idle).To(HaveKeyWithValue("ambient-code.io/desired-phase", "Stopped"))
+		Expect(idle).To(HaveKeyWithValue(idlePausedAnnotation, now.Format(time.RFC3339)))
+		for _, name := range []string{"recent", "streaming", "stopped"} {
+			Expect(annotationsOf(name)).NotTo(HaveKey(idlePausedAnnotation), name)
+			Expect(annotationsOf(name)).NotTo(HaveKey("ambient-code.io/desired-phase"), name)
+		}
+	})
+
+	It("Should resume paused sessions on request and leave others stopped", func() {
+		DynamicClient = dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(),
+			session("paused", "Stopped", now.Add(-3*time.Hour), map[string]interface{}{
+				"ambient-code.io/desired-phase": "Stopped",
+				idlePausedAnnotation:            now.Format(time.RFC3339),
+			}),
+			session("user-stopped", "Stopped", now.Add(-3*time.Hour), map[string]interface{}{
+				"ambient-code.io/desired-phase": "Stopped",
+			}),
+		)
+		K8sClientMw = fake.NewSimpleClientset()
+		gin.SetMode(gin.TestMode)
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request = httptest.NewRequest(http.MethodPost, "/", nil)
+		c.Request.Header.Set("Authorization", "Bearer test-token")
+
+		resumed, err := ResumeIdleSession(c, "team-a", "paused")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resumed).To(BeTrue())
+		paused := annotationsOf("paused")
+		Expect(paused).To(HaveKeyWithValue("ambient-code.io/desired-phase", "Running"))
+		Expect(paused).NotTo(HaveKey(idlePausedAnnotation))
+
+		resumed, err = ResumeIdleSession(c, "team-a", "user-stopped")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(resumed).To(BeFalse())
+		Expect(annotationsOf("user-stopped")).To(HaveKeyWithValue("ambient-code.io/desired-phase", "Stopped"))
+	})
+})
@@ -6,16 +6,20 @@ import (
 	"net/http"
 	"time"
 
+	"ambient-code-backend/linear"
+	"ambient-code-backend/metrics"
+
 	"github.com/gin-gonic/gin"
 )
 
 // ValidateGitHubToken checks if a GitHub token is valid by calling the GitHub API
-func ValidateGitHubToken(ctx context.Context, token string) (bool, error) {
+func ValidateGitHubToken(ctx context.Context, token string) (valid bool, err error) {
 	if token == "" {
 		return false, fmt.Errorf("token is empty")
 	}
+	defer func() { metrics.ObserveValidation("github", valid, err) }()
 
-	client := &http.Client{Timeout: 10 * time.Second}
+	client := &http.Client{Timeout: 10 * time.Second, Transport: metrics.Transport("github", "validate", nil)}
 	req, err := http.NewRequestWithContext(ctx, "GET", "https://api.github.com/user", nil)
 	if err != nil {
 		return false, fmt.Errorf("failed to create request")
@@ -36,15 +40,16 @@ func ValidateGitHubToken(ctx context.Context, token string) (bool, error) {
 }
 
 // ValidateGitLabToken checks if a GitLab token is valid
-func ValidateGitLabToken(ctx context.Context, token, instanceURL string) (bool, error) {
+func ValidateGitLabToken(ctx context.Context, token, instanceURL string) (valid bool, err error) {
 	if token == "" {
 		return false, fmt.Errorf("token is empty")
 	}
 	if instanceURL == "" {
 		instanceURL = "https://gitlab.com"
 	}
+	defer func() { metrics.ObserveValidation("gitlab", valid, err) }()
 
-	client := &http.Client{Timeout: 10 * time.Second}
+	client := &http.Client{Timeout: 10 * time.Second, Transport: metrics.Transport("gitlab", "validate", nil)}
 	apiURL := fmt.Sprintf("%s/api/v4/user", instanceURL)
 
 	req, err := http.NewRequestWithContext(ctx, "GET", apiURL, nil)
@@ -67,12 +72,13 @@ func ValidateGitLabToken(ctx context.Context, token, instanceURL string) (bool,
 
 // ValidateJiraToken checks if Jira credentials are valid
 // Uses /rest/api/*/myself endpoint which accepts Basic Auth (API tokens)
-func ValidateJiraToken(ctx context.Context, url, email, apiToken string) (bool, error) {
+func ValidateJiraToken(ctx context.Context, url, email, apiToken string) (valid bool, err error) {
 	if url == "" || email == "" || apiToken == "" {
 		return false, fmt.Errorf("missing required credentials")
 	}
+	defer func() { metrics.ObserveValidation("jira", valid, err) }()
 
-	client := &http.Client{Timeout: 15 * time.Second}
+	client := &http.Client{Timeout: 15 * time.Second, Transport: metrics.Transport("jira", "validate", nil)}
 
 	// Try API v3 first (Jira Cloud), fallback to v2 (Jira Server/DC)
 	apiURLs := []string{
@@ -117,13 +123,38 @@ func ValidateJiraToken(ctx context.Context, url, email, apiToken string) (bool,
 	return true, nil
 }
 
+// ValidateLinearToken checks if a Linear API key is valid
+func ValidateLinearToken(ctx context.Context, apiKey string) (valid bool, err error) {
+	_, valid, err = lookupLinearViewer(ctx, apiKey)
+	return valid, err
+}
+
+// lookupLinearViewer returns the user a Linear API key belongs to. valid is false
+// (with a nil error) when Linear rejects the key.
+func lookupLinearViewer(ctx context.Context, apiKey string) (viewer *linear.Viewer, valid bool, err error) {
+	if apiKey == "" {
+		return nil, false, fmt.Errorf("token is empty")
+	}
+	defer func() { metrics.ObserveValidation("linear", valid, err) }()
+
+	viewer, err = linear.NewClient(apiKey).Viewer(ctx)
+	if err == linear.ErrUnauthorized {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return viewer, true, nil
+}
+
 // ValidateGoogleToken checks if Google OAuth token is valid
-func ValidateGoogleToken(ctx context.Context, accessToken string) (bool, error) {
+func ValidateGoogleToken(ctx context.Context, accessToken string) (valid bool, err error) {
 	if accessToken == "" {
 		return false, fmt.Errorf("token is empty")
 	}
+	defer func() { metrics.ObserveValidation("google", valid, err) }()
 
-	client := &http.Client{Timeout: 10 * time.Second}
+	client := &http.Client{Timeout: 10 * time.Second, Transport: metrics.Transport("google", "validate", nil)}
 
 	req, err := http.NewRequestWithContext(ctx, "GET", "https://www.googleapis.com/oauth2/v1/userinfo", nil)
 	if err != nil {
@@ -201,3 +232,29 @@ func TestGitLabConnection(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"valid": true, "message": "GitLab connection successful"})
 }
+
+// TestLinearConnection handles POST /api/auth/linear/test
+// Tests a Linear API key without saving it
+func TestLinearConnection(c *gin.Context) {
+	var req struct {
+		APIKey string `json:"apiKey" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	valid, err := ValidateLinearToken(c.Request.Context(), req.APIKey)
+	if err != nil {
+		c.JSON(http.StatusOK, gin.H{"valid": false, "error": err.Error()})
+		return
+	}
+
+	if !valid {
+		c.JSON(http.StatusOK, gin.H{"valid": false, "error": "Invalid credentials"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"valid": true, "message": "Linear connection successful"})
+}
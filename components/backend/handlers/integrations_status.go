@@ -2,14 +2,39 @@ package handlers
 
 import (
 	"context"
-	"log"
 	"net/http"
+	"sync"
+	"time"
+
+	"ambient-code-backend/config"
+	"ambient-code-backend/logging"
 
 	"github.com/gin-gonic/gin"
 )
 
+// integrationStatusProvider looks up one integration's status. unavailable is the
+// shape reported when the lookup times out, so clients can still read its fields.
+type integrationStatusProvider struct {
+	name        string
+	get         func(ctx context.Context, userID string) gin.H
+	unavailable func() gin.H
+}
+
+var integrationStatusProviders = []integrationStatusProvider{
+	{"github", getGitHubStatusForUser, func() gin.H { return gin.H{"installed": false, "pat": gin.H{"configured": false}} }},
+	{"google", getGoogleStatusForUser, disconnectedStatus},
+	{"jira", getJiraStatusForUser, disconnectedStatus},
+	{"gitlab", getGitLabStatusForUser, disconnectedStatus},
+	{"linear", getLinearStatusForUser, disconnectedStatus},
+}
+
+func disconnectedStatus() gin.H {
+	return gin.H{"connected": false}
+}
+
 // GetIntegrationsStatus handles GET /api/auth/integrations/status
-// Returns unified status for all integrations (GitHub, Google, Jira, GitLab)
+// Returns unified status for all integrations (GitHub, Google, Jira, GitLab, Linear).
+// Providers are queried concurrently; each entry has "status" "ok" or "timeout".
 func GetIntegrationsStatus(c *gin.Context) {
 	// Verify user has valid K8s token
 	reqK8s, _ := GetK8sClientsForRequest(c)
@@ -24,28 +49,61 @@ func GetIntegrationsStatus(c *gin.Context) {
 		return
 	}
 
-	ctx := c.Request.Context()
-	response := gin.H{}
-
-	// GitHub status (App + PAT)
-	response["github"] = getGitHubStatusForUser(ctx, userID)
-
-	// Google status
-	response["google"] = getGoogleStatusForUser(ctx, userID)
+	// Providers that don't answer in time are reported with "status": "timeout"
+	// instead of holding up the rest
+	timeout := config.Current().IntegrationStatusTimeout.Duration
+	c.JSON(http.StatusOK, fetchIntegrationsStatus(c.Request.Context(), userID, integrationStatusProviders, timeout))
+}
 
-	// Jira status
-	response["jira"] = getJiraStatusForUser(ctx, userID)
+// fetchIntegrationsStatus queries providers concurrently, giving each up to timeout
+func fetchIntegrationsStatus(ctx context.Context, userID string, providers []integrationStatusProvider, timeout time.Duration) gin.H {
+	statuses := make([]gin.H, len(providers))
+	var wg sync.WaitGroup
+	for i, p := range providers {
+		wg.Add(1)
+		go func(i int, p integrationStatusProvider) {
+			defer wg.Done()
+			statuses[i] = fetchIntegrationStatus(ctx, userID, p, timeout)
+		}(i, p)
+	}
+	wg.Wait()
 
-	// GitLab status
-	response["gitlab"] = getGitLabStatusForUser(ctx, userID)
+	response := gin.H{}
+	for i, p := range providers {
+		response[p.name] = statuses[i]
+	}
+	return response
+}
 
-	c.JSON(http.StatusOK, response)
+func fetchIntegrationStatus(ctx context.Context, userID string, p integrationStatusProvider, timeout time.Duration) gin.H {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	// Buffered so a lookup finishing after the deadline doesn't leak its goroutine
+	result := make(chan gin.H, 1)
+	go func() {
+		result <- p.get(ctx, userID)
+	}()
+
+	select {
+	case status := <-result:
+		// A lookup cut short by the deadline reports as not connected; don't trust it
+		if ctx.Err() == nil {
+			status["status"] = "ok"
+			return status
+		}
+	case <-ctx.Done():
+	}
+	logging.Warnf(ctx, "GetIntegrationsStatus: %s status for user=%s timed out after %v", p.name, userID, timeout)
+	status := p.unavailable()
+	status["status"] = "timeout"
+	return status
 }
 
 // Helper functions to get individual integration statuses
 
 func getGitHubStatusForUser(ctx context.Context, userID string) gin.H {
-	log.Printf("getGitHubStatusForUser: querying status for user=%s", userID)
+	logging.Infof(ctx, "getGitHubStatusForUser: querying status for user=%s", userID)
 	status := gin.H{
 		"installed": false,
 		"pat":       gin.H{"configured": false},
@@ -54,14 +112,14 @@ func getGitHubStatusForUser(ctx context.Context, userID string) gin.H {
 	// Check GitHub App
 	inst, err := GetGitHubInstallation(ctx, userID)
 	if err == nil && inst != nil {
-		log.Printf("getGitHubStatusForUser: found installation for user=%s installationId=%d githubUser=%s", userID, inst.InstallationID, inst.GitHubUserID)
+		logging.Infof(ctx, "getGitHubStatusForUser: found installation for user=%s installationId=%d githubUser=%s", userID, inst.InstallationID, inst.GitHubUserID)
 		status["installed"] = true
 		status["installationId"] = inst.InstallationID
 		status["host"] = inst.Host
 		status["githubUserId"] = inst.GitHubUserID
 		status["updatedAt"] = inst.UpdatedAt.Format("2006-01-02T15:04:05Z07:00")
 	} else {
-		log.Printf("getGitHubStatusForUser: no installation found for user=%s", userID)
+		logging.Infof(ctx, "getGitHubStatusForUser: no installation found for user=%s", userID)
 	}
 
 	// Check GitHub PAT
@@ -141,3 +199,20 @@ func getGitLabStatusForUser(ctx context.Context, userID string) gin.H {
 		"valid":       true,
 	}
 }
+
+func getLinearStatusForUser(ctx context.Context, userID string) gin.H {
+	creds, err := GetLinearCredentials(ctx, userID)
+	if err != nil || creds == nil {
+		return gin.H{"connected": false}
+	}
+
+	// The key was validated when it was saved; sessions fail gracefully if it was revoked since
+
+	return gin.H{
+		"connected":    true,
+		"email":        creds.Email,
+		"organization": creds.Organization,
+		"updatedAt":    creds.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		"valid":        true,
+	}
+}
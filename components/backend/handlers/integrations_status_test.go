@@ -0,0 +1,49 @@
+//go:build test
+
+package handlers
+
+import (
+	"context"
+	"time"
+
+	test_constants "ambient-code-backend/tests/constants"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Integrations status", Label(test_constants.LabelUnit, test_constants.LabelHandlers), func() {
+	It("Should fetch providers concurrently and report slow ones as timed out", func() {
+		slow := func(ctx context.Context, _ string) gin.H {
+			select {
+			case <-ctx.Done():
+				// Like a secret read failing on the expired context
+				return gin.H{"connected": false}
+			case <-time.After(5 * time.Second):
+				return gin.H{"connected": true}
+			}
+		}
+		fast := func(context.Context, string) gin.H {
+			time.Sleep(50 * time.Millisecond)
+			return gin.H{"connected": true}
+		}
+		providers := []integrationStatusProvider{
+			{"github", slow, func() gin.H { return gin.H{"installed": false, "pat": gin.H{"configured": false}} }},
+			{"google", fast, disconnectedStatus},
+			{"jira", fast, disconnectedStatus},
+			{"gitlab", slow, disconnectedStatus},
+		}
+
+		start := time.Now()
+		response := fetchIntegrationsStatus(context.Background(), "alice", providers, 200*time.Millisecond)
+		Expect(time.Since(start)).To(BeNumerically("<", time.Second))
+
+		Expect(response["google"]).To(Equal(gin.H{"connected": true, "status": "ok"}))
+		Expect(response["jira"]).To(Equal(gin.H{"connected": true, "status": "ok"}))
+		Expect(response["gitlab"]).To(Equal(gin.H{"connected": false, "status": "timeout"}))
+		github := response["github"].(gin.H)
+		Expect(github["status"]).To(Equal("timeout"))
+		Expect(github["pat"]).To(Equal(gin.H{"configured": false}))
+	})
+})
@@ -4,10 +4,11 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
 	"net/http"
 	"time"
 
+	"ambient-code-backend/logging"
+
 	"github.com/gin-gonic/gin"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
@@ -71,12 +72,12 @@ func ConnectJira(c *gin.Context) {
 	}
 
 	if err := storeJiraCredentials(c.Request.Context(), creds); err != nil {
-		log.Printf("Failed to store Jira credentials for user %s: %v", userID, err)
+		logging.Errorf(c, "Failed to store Jira credentials for user %s: %v", userID, err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save Jira credentials"})
 		return
 	}
 
-	log.Printf("✓ Stored Jira credentials for user %s", userID)
+	logging.Infof(c, "✓ Stored Jira credentials for user %s", userID)
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Jira connected successfully",
 		"url":     req.URL,
@@ -106,7 +107,7 @@ func GetJiraStatus(c *gin.Context) {
 			c.JSON(http.StatusOK, gin.H{"connected": false})
 			return
 		}
-		log.Printf("Failed to get Jira credentials for user %s: %v", userID, err)
+		logging.Errorf(c, "Failed to get Jira credentials for user %s: %v", userID, err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check Jira status"})
 		return
 	}
@@ -141,12 +142,12 @@ func DisconnectJira(c *gin.Context) {
 	}
 
 	if err := DeleteJiraCredentials(c.Request.Context(), userID); err != nil {
-		log.Printf("Failed to delete Jira credentials for user %s: %v", userID, err)
+		logging.Errorf(c, "Failed to delete Jira credentials for user %s: %v", userID, err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to disconnect Jira"})
 		return
 	}
 
-	log.Printf("✓ Deleted Jira credentials for user %s", userID)
+	logging.Infof(c, "✓ Deleted Jira credentials for user %s", userID)
 	c.JSON(http.StatusOK, gin.H{"message": "Jira disconnected successfully"})
 }
 
@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"ambient-code-backend/jira"
+	"ambient-code-backend/logging"
+	"ambient-code-backend/types"
+
+	"github.com/gin-gonic/gin"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// jiraIssueAnnotation records the Jira issue a session was created from
+const jiraIssueAnnotation = "ambient-code.io/jira-issue"
+
+// fetchJiraIssue reads the issue named by fromJiraIssue with the caller's stored Jira
+// credentials. On failure it writes the response and returns false.
+func fetchJiraIssue(c *gin.Context, key string) (*jira.Issue, bool) {
+	key = strings.ToUpper(strings.TrimSpace(key))
+	if !jira.ValidKey(key) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "fromJiraIssue must be an issue key such as PROJ-123"})
+		return nil, false
+	}
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User authentication required"})
+		return nil, false
+	}
+	creds, err := GetJiraCredentials(c.Request.Context(), userID)
+	if err != nil && !k8serrors.IsNotFound(err) {
+		logging.Errorf(c, "Failed to get Jira credentials for user %s: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get Jira credentials"})
+		return nil, false
+	}
+	if creds == nil || creds.APIToken == "" {
+		c.JSON(http.StatusPreconditionFailed, gin.H{"error": "Jira is not connected; connect it to import issues"})
+		return nil, false
+	}
+
+	issue, err := jira.NewClient(creds.URL, creds.Email, creds.APIToken).GetIssue(c.Request.Context(), key)
+	switch {
+	case err == nil:
+		return issue, true
+	case errors.Is(err, jira.ErrUnauthorized):
+		c.JSON(http.StatusPreconditionFailed, gin.H{"error": "Jira rejected your credentials; reconnect Jira"})
+	case errors.Is(err, jira.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Jira issue " + key + " not found"})
+	default:
+		logging.Infof(c, "Failed to fetch Jira issue %s: %v", key, err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to fetch Jira issue: " + err.Error()})
+	}
+	return nil, false
+}
+
+// applyJiraIssue makes the issue the session's initial context: the prompt (by default
+// to work the ticket) is followed by the issue's <jira-issue> block. Sessions without
+// a display name are named after the issue.
+func applyJiraIssue(req *types.CreateAgenticSessionRequest, issue *jira.Issue) {
+	prompt := strings.TrimSpace(req.InitialPrompt)
+	if prompt == "" {
+		prompt = "Work on Jira issue " + issue.Key + " described below: make the changes it asks for and verify them."
+	}
+	req.InitialPrompt = prompt + "\n\n" + issue.Context()
+
+	if strings.TrimSpace(req.DisplayName) == "" {
+		req.DisplayName = issueDisplayName(issue.Key, issue.Summary)
+	}
+	if req.Annotations == nil {
+		req.Annotations = map[string]string{}
+	}
+	req.Annotations[jiraIssueAnnotation] = issue.Key
+}
@@ -0,0 +1,287 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"ambient-code-backend/linear"
+	"ambient-code-backend/logging"
+
+	"github.com/gin-gonic/gin"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// linearCredentialsSecret holds every user's Linear API key, keyed by userID
+const linearCredentialsSecret = "linear-credentials"
+
+// LinearCredentials represents cluster-level Linear credentials for a user
+type LinearCredentials struct {
+	UserID       string    `json:"userId"`
+	APIKey       string    `json:"apiKey"`       // Linear personal API key
+	Email        string    `json:"email"`        // Linear account email
+	Organization string    `json:"organization"` // Linear workspace URL key
+	UpdatedAt    time.Time `json:"updatedAt"`
+}
+
+// ConnectLinear handles POST /api/auth/linear/connect
+// Validates the user's Linear API key and saves it at cluster level
+func ConnectLinear(c *gin.Context) {
+	// Verify user has valid K8s token (follows RBAC pattern)
+	reqK8s, _ := GetK8sClientsForRequest(c)
+	if reqK8s == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing token"})
+		return
+	}
+
+	// Verify user is authenticated and userID is valid
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User authentication required"})
+		return
+	}
+	if !isValidUserID(userID) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user identifier"})
+		return
+	}
+
+	var req struct {
+		APIKey string `json:"apiKey" binding:"required"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Unlike Jira, Linear has a single API endpoint, so the key can be checked reliably
+	viewer, valid, err := lookupLinearViewer(c.Request.Context(), req.APIKey)
+	if err != nil {
+		logging.Warnf(c, "Failed to validate Linear API key for user %s: %v", userID, err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to reach Linear"})
+		return
+	}
+	if !valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid Linear API key"})
+		return
+	}
+
+	creds := &LinearCredentials{
+		UserID:       userID,
+		APIKey:       req.APIKey,
+		Email:        viewer.Email,
+		Organization: viewer.Organization.URLKey,
+		UpdatedAt:    time.Now(),
+	}
+
+	if err := storeLinearCredentials(c.Request.Context(), creds); err != nil {
+		logging.Errorf(c, "Failed to store Linear credentials for user %s: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save Linear credentials"})
+		return
+	}
+
+	logging.Infof(c, "✓ Stored Linear credentials for user %s", userID)
+	c.JSON(http.StatusOK, gin.H{
+		"message":      "Linear connected successfully",
+		"email":        creds.Email,
+		"organization": creds.Organization,
+	})
+}
+
+// GetLinearStatus handles GET /api/auth/linear/status
+// Returns connection status for the authenticated user
+func GetLinearStatus(c *gin.Context) {
+	// Verify user has valid K8s token
+	reqK8s, _ := GetK8sClientsForRequest(c)
+	if reqK8s == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing token"})
+		return
+	}
+
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User authentication required"})
+		return
+	}
+
+	creds, err := GetLinearCredentials(c.Request.Context(), userID)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			c.JSON(http.StatusOK, gin.H{"connected": false})
+			return
+		}
+		logging.Errorf(c, "Failed to get Linear credentials for user %s: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check Linear status"})
+		return
+	}
+
+	if creds == nil {
+		c.JSON(http.StatusOK, gin.H{"connected": false})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"connected":    true,
+		"email":        creds.Email,
+		"organization": creds.Organization,
+		"updatedAt":    creds.UpdatedAt.Format(time.RFC3339),
+	})
+}
+
+// DisconnectLinear handles DELETE /api/auth/linear/disconnect
+// Removes user's Linear credentials
+func DisconnectLinear(c *gin.Context) {
+	// Verify user has valid K8s token
+	reqK8s, _ := GetK8sClientsForRequest(c)
+	if reqK8s == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing token"})
+		return
+	}
+
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User authentication required"})
+		return
+	}
+
+	if err := DeleteLinearCredentials(c.Request.Context(), userID); err != nil {
+		logging.Errorf(c, "Failed to delete Linear credentials for user %s: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to disconnect Linear"})
+		return
+	}
+
+	logging.Infof(c, "✓ Deleted Linear credentials for user %s", userID)
+	c.JSON(http.StatusOK, gin.H{"message": "Linear disconnected successfully"})
+}
+
+// storeLinearCredentials stores Linear credentials in cluster-level Secret
+func storeLinearCredentials(ctx context.Context, creds *LinearCredentials) error {
+	if creds == nil || creds.UserID == "" {
+		return fmt.Errorf("invalid credentials payload")
+	}
+
+	for i := 0; i < 3; i++ { // retry on conflict
+		secret, err := K8sClient.CoreV1().Secrets(Namespace).Get(ctx, linearCredentialsSecret, v1.GetOptions{})
+		if err != nil {
+			if errors.IsNotFound(err) {
+				// Create Secret
+				secret = &corev1.Secret{
+					ObjectMeta: v1.ObjectMeta{
+						Name:      linearCredentialsSecret,
+						Namespace: Namespace,
+						Labels: map[string]string{
+							"app":                      "ambient-code",
+							"ambient-code.io/provider": "linear",
+						},
+					},
+					Type: corev1.SecretTypeOpaque,
+					Data: map[string][]byte{},
+				}
+				if _, cerr := K8sClient.CoreV1().Secrets(Namespace).Create(ctx, secret, v1.CreateOptions{}); cerr != nil && !errors.IsAlreadyExists(cerr) {
+					return fmt.Errorf("failed to create Secret: %w", cerr)
+				}
+				// Fetch again to get resourceVersion
+				secret, err = K8sClient.CoreV1().Secrets(Namespace).Get(ctx, linearCredentialsSecret, v1.GetOptions{})
+				if err != nil {
+					return fmt.Errorf("failed to fetch Secret after create: %w", err)
+				}
+			} else {
+				return fmt.Errorf("failed to get Secret: %w", err)
+			}
+		}
+
+		if secret.Data == nil {
+			secret.Data = map[string][]byte{}
+		}
+
+		b, err := json.Marshal(creds)
+		if err != nil {
+			return fmt.Errorf("failed to marshal credentials: %w", err)
+		}
+		secret.Data[creds.UserID] = b
+
+		if _, uerr := K8sClient.CoreV1().Secrets(Namespace).Update(ctx, secret, v1.UpdateOptions{}); uerr != nil {
+			if errors.IsConflict(uerr) {
+				continue // retry
+			}
+			return fmt.Errorf("failed to update Secret: %w", uerr)
+		}
+		return nil
+	}
+	return fmt.Errorf("failed to update Secret after retries")
+}
+
+// GetLinearCredentials retrieves cluster-level Linear credentials for a user
+func GetLinearCredentials(ctx context.Context, userID string) (*LinearCredentials, error) {
+	if userID == "" {
+		return nil, fmt.Errorf("userID is required")
+	}
+
+	secret, err := K8sClient.CoreV1().Secrets(Namespace).Get(ctx, linearCredentialsSecret, v1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	if secret.Data == nil || len(secret.Data[userID]) == 0 {
+		return nil, nil // User hasn't connected Linear
+	}
+
+	var creds LinearCredentials
+	if err := json.Unmarshal(secret.Data[userID], &creds); err != nil {
+		return nil, fmt.Errorf("failed to parse credentials: %w", err)
+	}
+
+	return &creds, nil
+}
+
+// DeleteLinearCredentials removes Linear credentials for a user
+func DeleteLinearCredentials(ctx context.Context, userID string) error {
+	if userID == "" {
+		return fmt.Errorf("userID is required")
+	}
+
+	for i := 0; i < 3; i++ { // retry on conflict
+		secret, err := K8sClient.CoreV1().Secrets(Namespace).Get(ctx, linearCredentialsSecret, v1.GetOptions{})
+		if err != nil {
+			if errors.IsNotFound(err) {
+				return nil // Secret doesn't exist, nothing to delete
+			}
+			return fmt.Errorf("failed to get Secret: %w", err)
+		}
+
+		if secret.Data == nil || len(secret.Data[userID]) == 0 {
+			return nil // User's credentials don't exist
+		}
+
+		delete(secret.Data, userID)
+
+		if _, uerr := K8sClient.CoreV1().Secrets(Namespace).Update(ctx, secret, v1.UpdateOptions{}); uerr != nil {
+			if errors.IsConflict(uerr) {
+				continue // retry
+			}
+			return fmt.Errorf("failed to update Secret: %w", uerr)
+		}
+		return nil
+	}
+	return fmt.Errorf("failed to update Secret after retries")
+}
+
+// linearClientForUser returns a Linear client for userID's stored API key, or nil if
+// the user hasn't connected Linear
+func linearClientForUser(ctx context.Context, userID string) (*linear.Client, error) {
+	creds, err := GetLinearCredentials(ctx, userID)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if creds == nil || creds.APIKey == "" {
+		return nil, nil
+	}
+	return linear.NewClient(creds.APIKey), nil
+}
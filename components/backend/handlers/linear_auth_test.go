@@ -0,0 +1,81 @@
+//go:build test
+
+package handlers
+
+import (
+	"context"
+	"time"
+
+	test_constants "ambient-code-backend/tests/constants"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+var _ = Describe("Linear integration", Label(test_constants.LabelUnit, test_constants.LabelHandlers), func() {
+	Context("Credential storage", func() {
+		var (
+			originalK8sClient kubernetes.Interface
+			originalNamespace string
+		)
+
+		BeforeEach(func() {
+			originalK8sClient = K8sClient
+			originalNamespace = Namespace
+			K8sClient = fake.NewSimpleClientset()
+			Namespace = "ambient-code"
+		})
+
+		AfterEach(func() {
+			K8sClient = originalK8sClient
+			Namespace = originalNamespace
+		})
+
+		It("Should store, read and delete per-user keys", func() {
+			ctx := context.Background()
+
+			err := storeLinearCredentials(ctx, &LinearCredentials{UserID: "alice", APIKey: "lin_api_a", Email: "alice@example.com", Organization: "acme", UpdatedAt: time.Now()})
+			Expect(err).NotTo(HaveOccurred())
+			err = storeLinearCredentials(ctx, &LinearCredentials{UserID: "bob", APIKey: "lin_api_b"})
+			Expect(err).NotTo(HaveOccurred())
+
+			creds, err := GetLinearCredentials(ctx, "alice")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(creds.APIKey).To(Equal("lin_api_a"))
+			Expect(creds.Organization).To(Equal("acme"))
+
+			Expect(DeleteLinearCredentials(ctx, "alice")).To(Succeed())
+			creds, err = GetLinearCredentials(ctx, "alice")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(creds).To(BeNil())
+
+			client, err := linearClientForUser(ctx, "bob")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(client).NotTo(BeNil())
+		})
+
+		It("Should report no client when the user never connected", func() {
+			client, err := linearClientForUser(context.Background(), "carol")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(client).To(BeNil())
+		})
+	})
+
+	It("Should parse linked issues from session status", func() {
+		status := parseStatus(map[string]interface{}{
+			"linearIssues": []interface{}{
+				map[string]interface{}{
+					"id":         "i1",
+					"identifier": "ENG-7",
+					"url":        "https://linear.app/acme/issue/ENG-7",
+					"created":    true,
+				},
+			},
+		})
+		Expect(status.LinearIssues).To(HaveLen(1))
+		Expect(status.LinearIssues[0].Identifier).To(Equal("ENG-7"))
+		Expect(status.LinearIssues[0].Created).To(BeTrue())
+	})
+})
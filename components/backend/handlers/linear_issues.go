@@ -0,0 +1,199 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"ambient-code-backend/linear"
+	"ambient-code-backend/logging"
+	"ambient-code-backend/types"
+
+	"github.com/gin-gonic/gin"
+	"k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/util/retry"
+)
+
+// CreateSessionLinearIssue creates a Linear issue with the session owner's API key and
+// records it in status.linearIssues. The description links back to the session.
+// POST /api/projects/:projectName/agentic-sessions/:sessionName/linear/issues
+func CreateSessionLinearIssue(c *gin.Context) {
+	project := c.GetString("project")
+	sessionName := c.Param("sessionName")
+
+	var req types.CreateLinearIssueRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "team and title are required"})
+		return
+	}
+	req.Title = strings.TrimSpace(req.Title)
+	if req.Title == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "title is required"})
+		return
+	}
+
+	client, ok := linearClientForSession(c, project, sessionName)
+	if !ok {
+		return
+	}
+
+	description := req.Description
+	if link := sessionTranscriptURL(project, sessionName); link != "" {
+		if description != "" {
+			description += "\n\n"
+		}
+		description += "Created from session [" + sessionName + "](" + link + ")"
+	}
+
+	issue, err := client.CreateIssue(c.Request.Context(), linear.IssueInput{Team: req.Team, Title: req.Title, Description: description})
+	if err != nil {
+		respondLinearError(c, "CreateSessionLinearIssue", project, sessionName, err)
+		return
+	}
+
+	entry := sessionLinearIssue(issue, true)
+	if err := recordSessionLinearIssue(c.Request.Context(), project, sessionName, entry); err != nil {
+		// The issue exists; failing to record it should not hide it from the caller
+		logging.Errorf(c, "CreateSessionLinearIssue: failed to record %s on %s/%s: %v", issue.Identifier, project, sessionName, err)
+	}
+	c.JSON(http.StatusCreated, entry)
+}
+
+// LinkSessionLinearIssue records an existing Linear issue in status.linearIssues after
+// checking it is visible to the session owner.
+// POST /api/projects/:projectName/agentic-sessions/:sessionName/linear/issues/link
+func LinkSessionLinearIssue(c *gin.Context) {
+	project := c.GetString("project")
+	sessionName := c.Param("sessionName")
+
+	var req types.LinkLinearIssueRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "issue is required"})
+		return
+	}
+	req.Issue = strings.TrimSpace(req.Issue)
+	if req.Issue == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "issue is required"})
+		return
+	}
+
+	client, ok := linearClientForSession(c, project, sessionName)
+	if !ok {
+		return
+	}
+
+	issue, err := client.GetIssue(c.Request.Context(), req.Issue)
+	if err != nil {
+		respondLinearError(c, "LinkSessionLinearIssue", project, sessionName, err)
+		return
+	}
+
+	entry := sessionLinearIssue(issue, false)
+	if err := recordSessionLinearIssue(c.Request.Context(), project, sessionName, entry); err != nil {
+		logging.Errorf(c, "LinkSessionLinearIssue: failed to record %s on %s/%s: %v", issue.Identifier, project, sessionName, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to link Linear issue"})
+		return
+	}
+	c.JSON(http.StatusOK, entry)
+}
+
+// linearClientForSession loads the session with the caller's client and returns a Linear
+// client for its owner. On failure it writes the response and returns false.
+func linearClientForSession(c *gin.Context, project, sessionName string) (*linear.Client, bool) {
+	k8sClt, k8sDyn := GetK8sClientsForRequest(c)
+	if k8sClt == nil || k8sDyn == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing token"})
+		c.Abort()
+		return nil, false
+	}
+
+	gvr := GetAgenticSessionV1Alpha1Resource()
+	item, err := k8sDyn.Resource(gvr).Namespace(project).Get(c.Request.Context(), sessionName, v1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+			return nil, false
+		}
+		logging.Errorf(c, "Failed to get session %s/%s: %v", project, sessionName, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get agentic session"})
+		return nil, false
+	}
+
+	userID, _, _ := unstructured.NestedString(item.Object, "spec", "userContext", "userId")
+	if strings.TrimSpace(userID) == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Session has no owner to resolve Linear credentials for"})
+		return nil, false
+	}
+
+	client, err := linearClientForUser(c.Request.Context(), userID)
+	if err != nil {
+		logging.Errorf(c, "Failed to get Linear credentials for user %s: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get Linear credentials"})
+		return nil, false
+	}
+	if client == nil {
+		c.JSON(http.StatusPreconditionFailed, gin.H{"error": "Linear is not connected for the session owner"})
+		return nil, false
+	}
+	return client, true
+}
+
+// respondLinearError maps a Linear API failure to a response
+func respondLinearError(c *gin.Context, op, project, sessionName string, err error) {
+	if err == linear.ErrUnauthorized {
+		c.JSON(http.StatusPreconditionFailed, gin.H{"error": "Linear rejected the session owner's API key; reconnect Linear"})
+		return
+	}
+	logging.Infof(c, "%s: %s/%s: %v", op, project, sessionName, err)
+	c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+}
+
+func sessionLinearIssue(issue *linear.Issue, created bool) types.SessionLinearIssue {
+	return types.SessionLinearIssue{
+		ID:         issue.ID,
+		Identifier: issue.Identifier,
+		Title:      issue.Title,
+		URL:        issue.URL,
+		Created:    created,
+		LinkedAt:   time.Now().UTC().Format(time.RFC3339),
+	}
+}
+
+// recordSessionLinearIssue appends the issue to status.linearIssues unless it is already
+// there. Uses the backend service account since status is not user-writable.
+func recordSessionLinearIssue(ctx context.Context, project, sessionName string, issue types.SessionLinearIssue) error {
+	if DynamicClient == nil {
+		return nil
+	}
+	gvr := GetAgenticSessionV1Alpha1Resource()
+	entry := map[string]interface{}{
+		"id":         issue.ID,
+		"identifier": issue.Identifier,
+		"title":      issue.Title,
+		"url":        issue.URL,
+		"created":    issue.Created,
+		"linkedAt":   issue.LinkedAt,
+	}
+
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		obj, err := DynamicClient.Resource(gvr).Namespace(project).Get(ctx, sessionName, v1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		issues, _, _ := unstructured.NestedSlice(obj.Object, "status", "linearIssues")
+		for _, existing := range issues {
+			if m, ok := existing.(map[string]interface{}); ok && m["id"] == issue.ID {
+				return nil
+			}
+		}
+		issues = append(issues, entry)
+		if err := unstructured.SetNestedSlice(obj.Object, issues, "status", "linearIssues"); err != nil {
+			return err
+		}
+		_, err = DynamicClient.Resource(gvr).Namespace(project).UpdateStatus(ctx, obj, v1.UpdateOptions{})
+		return err
+	})
+}
@@ -0,0 +1,244 @@
+package handlers
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"ambient-code-backend/git"
+	"ambient-code-backend/logging"
+	"ambient-code-backend/types"
+
+	"github.com/gin-gonic/gin"
+	"k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/kubernetes"
+)
+
+// mcpOAuthToken is a user token resolved for an MCP server
+type mcpOAuthToken struct {
+	Token string
+	// Authorization is the ready-to-use Authorization header value
+	Authorization string
+	ExpiresAt     string
+}
+
+// errMCPOAuthNotConnected means the session owner has not connected the provider
+var errMCPOAuthNotConnected = fmt.Errorf("provider not connected")
+
+// resolveMCPOAuthToken returns userID's token for provider from their stored credentials
+func resolveMCPOAuthToken(ctx context.Context, project, userID, provider string) (*mcpOAuthToken, error) {
+	switch provider {
+	case "github":
+		k8sClientset, ok := K8sClient.(*kubernetes.Clientset)
+		if !ok {
+			return nil, fmt.Errorf("failed to convert K8sClient to *kubernetes.Clientset")
+		}
+		token, err := git.GetGitHubToken(ctx, k8sClientset, DynamicClient, project, userID)
+		if err != nil || token == "" {
+			return nil, errMCPOAuthNotConnected
+		}
+		return &mcpOAuthToken{Token: token, Authorization: "Bearer " + token}, nil
+	case "gitlab":
+		creds, err := GetGitLabCredentials(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		if creds == nil || creds.Token == "" {
+			return nil, errMCPOAuthNotConnected
+		}
+		return &mcpOAuthToken{Token: creds.Token, Authorization: "Bearer " + creds.Token}, nil
+	case "google":
+		creds, err := GetFreshGoogleCredentials(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		if creds == nil {
+			return nil, errMCPOAuthNotConnected
+		}
+		return &mcpOAuthToken{
+			Token:         creds.AccessToken,
+			Authorization: "Bearer " + creds.AccessToken,
+			ExpiresAt:     creds.ExpiresAt.Format(time.RFC3339),
+		}, nil
+	case "jira":
+		creds, err := GetJiraCredentials(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		if creds == nil || creds.APIToken == "" {
+			return nil, errMCPOAuthNotConnected
+		}
+		basic := base64.StdEncoding.EncodeToString([]byte(creds.Email + ":" + creds.APIToken))
+		return &mcpOAuthToken{Token: creds.APIToken, Authorization: "Basic " + basic}, nil
+	case "linear":
+		creds, err := GetLinearCredentials(ctx, userID)
+		if err != nil {
+			return nil, err
+		}
+		if creds == nil || creds.APIKey == "" {
+			return nil, errMCPOAuthNotConnected
+		}
+		return &mcpOAuthToken{Token: creds.APIKey, Authorization: "Bearer " + creds.APIKey}, nil
+	default:
+		return nil, fmt.Errorf("unsupported provider %q", provider)
+	}
+}
+
+// mcpOAuthServer returns the registry entry for serverName if it may receive user
+// tokens: it must be registered, enabled, declare an allowed oauthProvider, and target
+// a host (or, for stdio servers, a command) the project admins allowlisted for that
+// provider. The registry is editable by more members than the allowlist.
+func mcpOAuthServer(servers []types.MCPServerConfig, serverName string, allowlist map[string][]string) (*types.MCPServerConfig, error) {
+	for i := range servers {
+		s := &servers[i]
+		if s.Name != serverName {
+			continue
+		}
+		if !s.Enabled {
+			return nil, fmt.Errorf("MCP server %q is disabled", serverName)
+		}
+		if s.OAuthProvider == "" {
+			return nil, fmt.Errorf("MCP server %q is not configured for token passthrough", serverName)
+		}
+		// Re-validate: the ConfigMap may have been edited outside the API
+		if err := validateMCPServerConfig(*s); err != nil {
+			return nil, fmt.Errorf("MCP server %q is invalid: %v", serverName, err)
+		}
+		if target := mcpOAuthTarget(*s); !slices.ContainsFunc(allowlist[s.OAuthProvider], func(entry string) bool {
+			return strings.EqualFold(strings.TrimSpace(entry), target)
+		}) {
+			return nil, fmt.Errorf("MCP server %q target %q is not in the project's mcpOAuthAllowlist for %s", serverName, target, s.OAuthProvider)
+		}
+		return s, nil
+	}
+	return nil, errMCPServerNotFound
+}
+
+// mcpOAuthTarget is what an allowlist entry names for the server: the URL's host for
+// http and sse servers, the command for stdio servers
+func mcpOAuthTarget(s types.MCPServerConfig) string {
+	if s.Transport == types.MCPTransportStdio {
+		return s.Command
+	}
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		return ""
+	}
+	return u.Host
+}
+
+// getMCPOAuthAllowlist reads the ProjectSettings mcpOAuthAllowlist, by provider, with
+// the backend service account. Only project admins can write ProjectSettings.
+func getMCPOAuthAllowlist(ctx context.Context, project string) (map[string][]string, error) {
+	obj, err := DynamicClient.Resource(GetProjectSettingsResource()).Namespace(project).Get(ctx, "projectsettings", v1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	raw, _, err := unstructured.NestedMap(obj.Object, "spec", "mcpOAuthAllowlist")
+	if err != nil {
+		return nil, err
+	}
+	allowlist := map[string][]string{}
+	for provider := range raw {
+		if allowlist[provider], _, err = unstructured.NestedStringSlice(raw, provider); err != nil {
+			return nil, err
+		}
+	}
+	return allowlist, nil
+}
+
+// GetMCPServerTokenForSession handles GET /api/projects/:project/agentic-sessions/:session/credentials/mcp/:serverName
+// Returns the session owner's token for the provider the MCP server is configured with
+func GetMCPServerTokenForSession(c *gin.Context) {
+	project := c.Param("projectName")
+	session := c.Param("sessionName")
+	serverName := c.Param("serverName")
+
+	// Get user-scoped K8s client
+	reqK8s, _ := GetK8sClientsForRequest(c)
+	if reqK8s == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing token"})
+		return
+	}
+
+	// Get userID from session CR (RequireSessionAccess has authorized the caller)
+	obj, err := GetCachedSession(c.Request.Context(), project, session)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+			return
+		}
+		logging.Errorf(c, "Failed to get session %s/%s: %v", project, session, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get session"})
+		return
+	}
+
+	userID, found, err := unstructured.NestedString(obj.Object, "spec", "userContext", "userId")
+	if !found || err != nil || userID == "" {
+		logging.Errorf(c, "Failed to extract userID from session %s/%s: found=%v, err=%v", project, session, found, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "User ID not found in session"})
+		return
+	}
+
+	// Verify authenticated user owns this session; BOT_TOKEN is already session-scoped
+	authenticatedUserID := c.GetString("userID")
+	if authenticatedUserID != "" && authenticatedUserID != userID {
+		logging.Warnf(c, "RBAC violation: user %s attempted to access MCP credentials for session owned by %s", authenticatedUserID, userID)
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied: session belongs to different user"})
+		return
+	}
+
+	// Only servers in the project registry that opt in may receive user tokens
+	servers, err := LoadProjectMCPServers(c.Request.Context(), K8sClient, project)
+	if err != nil {
+		logging.Errorf(c, "Failed to load MCP registry for %s: %v", project, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load MCP servers"})
+		return
+	}
+	allowlist, err := getMCPOAuthAllowlist(c.Request.Context(), project)
+	if err != nil {
+		logging.Errorf(c, "Failed to read MCP OAuth allowlist for %s: %v", project, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load MCP servers"})
+		return
+	}
+	server, err := mcpOAuthServer(servers, serverName, allowlist)
+	if err != nil {
+		if err == errMCPServerNotFound {
+			c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
+		logging.Errorf(c, "Denied MCP token for %s/%s server %s: %v", project, session, serverName, err)
+		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		return
+	}
+
+	tok, err := resolveMCPOAuthToken(c.Request.Context(), project, userID, server.OAuthProvider)
+	if err != nil {
+		if err == errMCPOAuthNotConnected {
+			c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("%s credentials not configured", server.OAuthProvider)})
+			return
+		}
+		logging.Errorf(c, "Failed to resolve %s token for user %s (MCP server %s): %v", server.OAuthProvider, userID, serverName, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get credentials"})
+		return
+	}
+
+	logging.Infof(c, "Issued %s token for MCP server %s to session %s/%s", server.OAuthProvider, serverName, project, session)
+	c.JSON(http.StatusOK, gin.H{
+		"server":        serverName,
+		"provider":      server.OAuthProvider,
+		"token":         tok.Token,
+		"authorization": tok.Authorization,
+		"expiresAt":     tok.ExpiresAt,
+	})
+}
@@ -0,0 +1,321 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"sort"
+	"strings"
+
+	"ambient-code-backend/logging"
+	"ambient-code-backend/types"
+
+	"github.com/gin-gonic/gin"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// reservedMCPServerNames are registered by the runner itself and cannot be overridden
+var reservedMCPServerNames = map[string]bool{"session": true, "rubric": true}
+
+// errMCPServerNotFound and errMCPServerExists are returned by registry mutations
+var (
+	errMCPServerNotFound = fmt.Errorf("MCP server not found")
+	errMCPServerExists   = fmt.Errorf("MCP server already exists")
+)
+
+// validateMCPServerConfig checks a server definition before it is stored
+func validateMCPServerConfig(s types.MCPServerConfig) error {
+	if !isValidKubernetesName(s.Name) {
+		return fmt.Errorf("name must be a lowercase DNS label (a-z, 0-9, '-')")
+	}
+	if reservedMCPServerNames[s.Name] {
+		return fmt.Errorf("name %q is reserved", s.Name)
+	}
+	switch s.Transport {
+	case types.MCPTransportStdio:
+		if strings.TrimSpace(s.Command) == "" {
+			return fmt.Errorf("command is required for stdio servers")
+		}
+		if s.URL != "" || len(s.Headers) > 0 {
+			return fmt.Errorf("url and headers are not supported for stdio servers")
+		}
+	case types.MCPTransportHTTP, types.MCPTransportSSE:
+		u, err := url.Parse(s.URL)
+		if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			return fmt.Errorf("a valid http(s) url is required for %s servers", s.Transport)
+		}
+		if u.User != nil {
+			return fmt.Errorf("url must not contain credentials; use credentialsSecretRef")
+		}
+		if s.Command != "" || len(s.Args) > 0 {
+			return fmt.Errorf("command and args are not supported for %s servers", s.Transport)
+		}
+	default:
+		return fmt.Errorf("transport must be one of: stdio, http, sse")
+	}
+	if s.CredentialsSecretRef != "" && !isValidKubernetesName(s.CredentialsSecretRef) {
+		return fmt.Errorf("credentialsSecretRef must be a valid secret name")
+	}
+	if s.OAuthProvider != "" {
+		if !slices.Contains(types.MCPOAuthProviders, s.OAuthProvider) {
+			return fmt.Errorf("oauthProvider must be one of: %s", strings.Join(types.MCPOAuthProviders, ", "))
+		}
+		// User tokens are never sent in the clear
+		if s.Transport != types.MCPTransportStdio && !strings.HasPrefix(s.URL, "https://") {
+			return fmt.Errorf("oauthProvider requires an https url")
+		}
+	}
+	return nil
+}
+
+// LoadProjectMCPServers reads the project's MCP server registry
+func LoadProjectMCPServers(ctx context.Context, k8s kubernetes.Interface, project string) ([]types.MCPServerConfig, error) {
+	cm, err := k8s.CoreV1().ConfigMaps(project).Get(ctx, types.ProjectMCPServersConfigMap, v1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return []types.MCPServerConfig{}, nil
+		}
+		return nil, err
+	}
+	return parseProjectMCPServers(cm)
+}
+
+func parseProjectMCPServers(cm *corev1.ConfigMap) ([]types.MCPServerConfig, error) {
+	servers := []types.MCPServerConfig{}
+	raw := cm.Data[types.ProjectMCPServersKey]
+	if strings.TrimSpace(raw) == "" {
+		return servers, nil
+	}
+	if err := json.Unmarshal([]byte(raw), &servers); err != nil {
+		return nil, fmt.Errorf("invalid MCP server registry: %w", err)
+	}
+	return servers, nil
+}
+
+// updateProjectMCPServers applies mutate to the registry and persists the result,
+// retrying on update conflicts
+func updateProjectMCPServers(ctx context.Context, k8s kubernetes.Interface, project string, mutate func([]types.MCPServerConfig) ([]types.MCPServerConfig, error)) error {
+	cms := k8s.CoreV1().ConfigMaps(project)
+	for i := 0; i < 3; i++ {
+		cm, err := cms.Get(ctx, types.ProjectMCPServersConfigMap, v1.GetOptions{})
+		notFound := errors.IsNotFound(err)
+		if err != nil && !notFound {
+			return err
+		}
+		if notFound {
+			cm = &corev1.ConfigMap{
+				ObjectMeta: v1.ObjectMeta{
+					Name:      types.ProjectMCPServersConfigMap,
+					Namespace: project,
+					Labels:    map[string]string{"app": "ambient-code", "ambient-code.io/purpose": "mcp-servers"},
+				},
+			}
+		}
+
+		servers, err := parseProjectMCPServers(cm)
+		if err != nil {
+			return err
+		}
+		servers, err = mutate(servers)
+		if err != nil {
+			return err
+		}
+		sort.Slice(servers, func(a, b int) bool { return servers[a].Name < servers[b].Name })
+		b, err := json.MarshalIndent(servers, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode MCP servers: %w", err)
+		}
+		if cm.Data == nil {
+			cm.Data = map[string]string{}
+		}
+		cm.Data[types.ProjectMCPServersKey] = string(b)
+
+		if notFound {
+			_, err = cms.Create(ctx, cm, v1.CreateOptions{})
+			if errors.IsAlreadyExists(err) {
+				continue
+			}
+		} else {
+			_, err = cms.Update(ctx, cm, v1.UpdateOptions{})
+			if errors.IsConflict(err) {
+				continue
+			}
+		}
+		return err
+	}
+	return fmt.Errorf("failed to update MCP servers after retries")
+}
+
+// respondMCPRegistryError maps registry errors to HTTP responses
+func respondMCPRegistryError(c *gin.Context, project string, err error) {
+	switch {
+	case err == errMCPServerNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case err == errMCPServerExists:
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.IsForbidden(err):
+		c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions to manage MCP servers"})
+	default:
+		logging.Errorf(c, "Failed to update MCP servers for project %s: %v", project, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update MCP servers"})
+	}
+}
+
+// ListMCPServers handles GET /api/projects/:projectName/mcp-servers
+func ListMCPServers(c *gin.Context) {
+	project := c.GetString("project")
+	reqK8s, _ := GetK8sClientsForRequest(c)
+	if reqK8s == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing token"})
+		c.Abort()
+		return
+	}
+
+	servers, err := LoadProjectMCPServers(c.Request.Context(), reqK8s, project)
+	if err != nil {
+		if errors.IsForbidden(err) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions to read MCP servers"})
+			return
+		}
+		logging.Errorf(c, "Failed to list MCP servers for project %s: %v", project, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list MCP servers"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": servers})
+}
+
+// GetMCPServer handles GET /api/projects/:projectName/mcp-servers/:serverName
+func GetMCPServer(c *gin.Context) {
+	project := c.GetString("project")
+	name := c.Param("serverName")
+	reqK8s, _ := GetK8sClientsForRequest(c)
+	if reqK8s == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing token"})
+		c.Abort()
+		return
+	}
+
+	servers, err := LoadProjectMCPServers(c.Request.Context(), reqK8s, project)
+	if err != nil {
+		if errors.IsForbidden(err) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions to read MCP servers"})
+			return
+		}
+		logging.Errorf(c, "Failed to get MCP server %s for project %s: %v", name, project, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get MCP server"})
+		return
+	}
+	for _, s := range servers {
+		if s.Name == name {
+			c.JSON(http.StatusOK, s)
+			return
+		}
+	}
+	c.JSON(http.StatusNotFound, gin.H{"error": errMCPServerNotFound.Error()})
+}
+
+// CreateMCPServer handles POST /api/projects/:projectName/mcp-servers
+func CreateMCPServer(c *gin.Context) {
+	project := c.GetString("project")
+	reqK8s, _ := GetK8sClientsForRequest(c)
+	if reqK8s == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing token"})
+		c.Abort()
+		return
+	}
+
+	var req types.MCPServerConfig
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := validateMCPServerConfig(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err := updateProjectMCPServers(c.Request.Context(), reqK8s, project, func(servers []types.MCPServerConfig) ([]types.MCPServerConfig, error) {
+		for _, s := range servers {
+			if s.Name == req.Name {
+				return nil, errMCPServerExists
+			}
+		}
+		return append(servers, req), nil
+	})
+	if err != nil {
+		respondMCPRegistryError(c, project, err)
+		return
+	}
+	c.JSON(http.StatusCreated, req)
+}
+
+// UpdateMCPServer handles PUT /api/projects/:projectName/mcp-servers/:serverName
+func UpdateMCPServer(c *gin.Context) {
+	project := c.GetString("project")
+	name := c.Param("serverName")
+	reqK8s, _ := GetK8sClientsForRequest(c)
+	if reqK8s == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing token"})
+		c.Abort()
+		return
+	}
+
+	var req types.MCPServerConfig
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	// The path names the server; renames are not supported
+	req.Name = name
+	if err := validateMCPServerConfig(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err := updateProjectMCPServers(c.Request.Context(), reqK8s, project, func(servers []types.MCPServerConfig) ([]types.MCPServerConfig, error) {
+		for i := range servers {
+			if servers[i].Name == name {
+				servers[i] = req
+				return servers, nil
+			}
+		}
+		return nil, errMCPServerNotFound
+	})
+	if err != nil {
+		respondMCPRegistryError(c, project, err)
+		return
+	}
+	c.JSON(http.StatusOK, req)
+}
+
+// DeleteMCPServer handles DELETE /api/projects/:projectName/mcp-servers/:serverName
+func DeleteMCPServer(c *gin.Context) {
+	project := c.GetString("project")
+	name := c.Param("serverName")
+	reqK8s, _ := GetK8sClientsForRequest(c)
+	if reqK8s == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing token"})
+		c.Abort()
+		return
+	}
+
+	err := updateProjectMCPServers(c.Request.Context(), reqK8s, project, func(servers []types.MCPServerConfig) ([]types.MCPServerConfig, error) {
+		for i := range servers {
+			if servers[i].Name == name {
+				return append(servers[:i], servers[i+1:]...), nil
+			}
+		}
+		return nil, errMCPServerNotFound
+	})
+	if err != nil {
+		respondMCPRegistryError(c, project, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "MCP server removed successfully"})
+}
@@ -0,0 +1,119 @@
+//go:build test
+
+package handlers
+
+import (
+	"context"
+
+	test_constants "ambient-code-backend/tests/constants"
+	"ambient-code-backend/types"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+var _ = Describe("Project MCP Server Registry", Label(test_constants.LabelUnit, test_constants.LabelHandlers), func() {
+	It("Should validate transports and their required fields", func() {
+		Expect(validateMCPServerConfig(types.MCPServerConfig{Name: "github", Transport: "stdio", Command: "npx", Args: []string{"-y", "server"}})).To(Succeed())
+		Expect(validateMCPServerConfig(types.MCPServerConfig{Name: "docs", Transport: "http", URL: "https://mcp.example.com/mcp"})).To(Succeed())
+		Expect(validateMCPServerConfig(types.MCPServerConfig{Name: "events", Transport: "sse", URL: "https://mcp.example.com/sse"})).To(Succeed())
+
+		Expect(validateMCPServerConfig(types.MCPServerConfig{Name: "github", Transport: "stdio"})).To(HaveOccurred())
+		Expect(validateMCPServerConfig(types.MCPServerConfig{Name: "docs", Transport: "http"})).To(HaveOccurred())
+		Expect(validateMCPServerConfig(types.MCPServerConfig{Name: "docs", Transport: "http", URL: "https://user:pw@mcp.example.com"})).To(HaveOccurred())
+		Expect(validateMCPServerConfig(types.MCPServerConfig{Name: "docs", Transport: "websocket", URL: "https://mcp.example.com"})).To(HaveOccurred())
+	})
+
+	It("Should only allow token passthrough to known providers over https", func() {
+		Expect(validateMCPServerConfig(types.MCPServerConfig{Name: "drive", Transport: "http", URL: "https://mcp.example.com/mcp", OAuthProvider: "google"})).To(Succeed())
+		Expect(validateMCPServerConfig(types.MCPServerConfig{Name: "jira", Transport: "stdio", Command: "mcp-atlassian", OAuthProvider: "jira"})).To(Succeed())
+
+		Expect(validateMCPServerConfig(types.MCPServerConfig{Name: "drive", Transport: "http", URL: "https://mcp.example.com/mcp", OAuthProvider: "dropbox"})).To(HaveOccurred())
+		Expect(validateMCPServerConfig(types.MCPServerConfig{Name: "drive", Transport: "http", URL: "http://mcp.example.com/mcp", OAuthProvider: "google"})).To(HaveOccurred())
+	})
+
+	It("Should only broker tokens for enabled servers that opt in", func() {
+		servers := []types.MCPServerConfig{
+			{Name: "drive", Transport: "http", URL: "https://mcp.example.com/mcp", OAuthProvider: "google", Enabled: true},
+			{Name: "docs", Transport: "http", URL: "https://docs.example.com/mcp", Enabled: true},
+			{Name: "jira", Transport: "stdio", Command: "mcp-atlassian", OAuthProvider: "jira", Enabled: false},
+			{Name: "plain", Transport: "http", URL: "http://plain.example.com/mcp", OAuthProvider: "github", Enabled: true},
+		}
+
+		allowlist := map[string][]string{"google": {"mcp.example.com"}, "jira": {"mcp-atlassian"}, "github": {"plain.example.com"}}
+		server, err := mcpOAuthServer(servers, "drive", allowlist)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(server.OAuthProvider).To(Equal("google"))
+
+		_, err = mcpOAuthServer(servers, "missing", allowlist)
+		Expect(err).To(Equal(errMCPServerNotFound))
+		_, err = mcpOAuthServer(servers, "docs", allowlist)
+		Expect(err).To(HaveOccurred())
+		_, err = mcpOAuthServer(servers, "jira", allowlist)
+		Expect(err).To(HaveOccurred())
+		_, err = mcpOAuthServer(servers, "plain", allowlist)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("Should only broker tokens to hosts and commands the project admins allowlisted", func() {
+		servers := []types.MCPServerConfig{
+			{Name: "drive", Transport: "http", URL: "https://mcp.example.com/mcp", OAuthProvider: "google", Enabled: true},
+			{Name: "jira", Transport: "stdio", Command: "mcp-atlassian", OAuthProvider: "jira", Enabled: true},
+		}
+
+		_, err := mcpOAuthServer(servers, "drive", nil)
+		Expect(err).To(MatchError(ContainSubstring("mcpOAuthAllowlist")))
+		// Allowed for another provider only
+		_, err = mcpOAuthServer(servers, "drive", map[string][]string{"jira": {"mcp.example.com"}})
+		Expect(err).To(HaveOccurred())
+		_, err = mcpOAuthServer(servers, "drive", map[string][]string{"google": {"evil.example.com"}})
+		Expect(err).To(HaveOccurred())
+
+		_, err = mcpOAuthServer(servers, "jira", map[string][]string{"jira": {"mcp-atlassian"}})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("Should reject invalid and reserved names", func() {
+		Expect(validateMCPServerConfig(types.MCPServerConfig{Name: "My Server", Transport: "stdio", Command: "x"})).To(HaveOccurred())
+		Expect(validateMCPServerConfig(types.MCPServerConfig{Name: "session", Transport: "stdio", Command: "x"})).To(HaveOccurred())
+	})
+
+	It("Should create, update and delete entries in the project ConfigMap", func() {
+		ctx := context.Background()
+		k8s := fake.NewSimpleClientset()
+		add := func(s types.MCPServerConfig) error {
+			return updateProjectMCPServers(ctx, k8s, "proj", func(servers []types.MCPServerConfig) ([]types.MCPServerConfig, error) {
+				for _, existing := range servers {
+					if existing.Name == s.Name {
+						return nil, errMCPServerExists
+					}
+				}
+				return append(servers, s), nil
+			})
+		}
+
+		Expect(add(types.MCPServerConfig{Name: "zeta", Transport: "stdio", Command: "z", Enabled: true})).To(Succeed())
+		Expect(add(types.MCPServerConfig{Name: "alpha", Transport: "http", URL: "https://a.example.com"})).To(Succeed())
+		Expect(add(types.MCPServerConfig{Name: "alpha", Transport: "http", URL: "https://a.example.com"})).To(MatchError(errMCPServerExists))
+
+		servers, err := LoadProjectMCPServers(ctx, k8s, "proj")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(servers).To(HaveLen(2))
+		Expect(servers[0].Name).To(Equal("alpha"))
+
+		Expect(updateProjectMCPServers(ctx, k8s, "proj", func(servers []types.MCPServerConfig) ([]types.MCPServerConfig, error) {
+			return servers[1:], nil
+		})).To(Succeed())
+		servers, err = LoadProjectMCPServers(ctx, k8s, "proj")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(servers).To(HaveLen(1))
+		Expect(servers[0].Name).To(Equal("zeta"))
+	})
+
+	It("Should return an empty registry when the ConfigMap does not exist", func() {
+		servers, err := LoadProjectMCPServers(context.Background(), fake.NewSimpleClientset(), "empty")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(servers).To(BeEmpty())
+	})
+})
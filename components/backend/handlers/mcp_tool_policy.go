@@ -0,0 +1,216 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"ambient-code-backend/logging"
+	"ambient-code-backend/types"
+
+	"github.com/gin-gonic/gin"
+	"k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+)
+
+// validateMCPToolPolicy checks a policy before it is stored or applied
+func validateMCPToolPolicy(p types.MCPToolPolicy) error {
+	if p.Mode != types.MCPToolPolicyAllowlist && p.Mode != types.MCPToolPolicyDenylist {
+		return fmt.Errorf("mode must be one of: allowlist, denylist")
+	}
+	if p.Enforcement != types.MCPToolPolicyFlag && p.Enforcement != types.MCPToolPolicyBlock {
+		return fmt.Errorf("enforcement must be one of: flag, block")
+	}
+	for _, t := range p.Tools {
+		t = strings.TrimSpace(t)
+		if !strings.HasPrefix(t, "mcp__") {
+			return fmt.Errorf("tool pattern %q must start with mcp__", t)
+		}
+		if strings.Contains(strings.TrimSuffix(t, "*"), "*") {
+			return fmt.Errorf("tool pattern %q may only use '*' as a trailing wildcard", t)
+		}
+	}
+	return nil
+}
+
+// mcpToolPolicyFromSettings reads spec.mcpToolPolicy from a ProjectSettings object
+func mcpToolPolicyFromSettings(obj *unstructured.Unstructured) (*types.MCPToolPolicy, error) {
+	raw, found, err := unstructured.NestedMap(obj.Object, "spec", "mcpToolPolicy")
+	if err != nil || !found {
+		return nil, err
+	}
+	b, err := json.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+	var policy types.MCPToolPolicy
+	if err := json.Unmarshal(b, &policy); err != nil {
+		return nil, err
+	}
+	if policy.Enforcement == "" {
+		policy.Enforcement = types.MCPToolPolicyFlag
+	}
+	if err := validateMCPToolPolicy(policy); err != nil {
+		return nil, fmt.Errorf("invalid mcpToolPolicy: %w", err)
+	}
+	return &policy, nil
+}
+
+// getMCPToolPolicy returns the project's MCP tool policy, or nil if none is configured
+func getMCPToolPolicy(ctx context.Context, dynClient dynamic.Interface, project string) (*types.MCPToolPolicy, error) {
+	obj, err := dynClient.Resource(GetProjectSettingsResource()).Namespace(project).Get(ctx, "projectsettings", v1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return mcpToolPolicyFromSettings(obj)
+}
+
+// mcpToolPolicyEnv returns the policy snapshot to pass to the runner, or "" if none applies
+func mcpToolPolicyEnv(ctx context.Context, dynClient dynamic.Interface, project string) (string, error) {
+	policy, err := getMCPToolPolicy(ctx, dynClient, project)
+	if err != nil || policy == nil {
+		return "", err
+	}
+	b, err := json.Marshal(policy)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// MCPToolPolicyForSession returns the policy snapshot recorded on a session at creation.
+// Sessions keep the policy they started with even if ProjectSettings changes later.
+func MCPToolPolicyForSession(obj *unstructured.Unstructured) *types.MCPToolPolicy {
+	raw, _, _ := unstructured.NestedString(obj.Object, "spec", "environmentVariables", types.MCPToolPolicyEnvVar)
+	if raw == "" {
+		return nil
+	}
+	var policy types.MCPToolPolicy
+	if err := json.Unmarshal([]byte(raw), &policy); err != nil {
+		logging.Warnf(context.Background(), "Ignoring invalid MCP tool policy on session %s/%s: %v", obj.GetNamespace(), obj.GetName(), err)
+		return nil
+	}
+	return &policy
+}
+
+// GetMCPToolPolicy handles GET /api/projects/:projectName/mcp-tool-policy
+func GetMCPToolPolicy(c *gin.Context) {
+	project := c.GetString("project")
+	_, reqDyn := GetK8sClientsForRequest(c)
+	if reqDyn == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing token"})
+		c.Abort()
+		return
+	}
+
+	policy, err := getMCPToolPolicy(c.Request.Context(), reqDyn, project)
+	if err != nil {
+		if errors.IsForbidden(err) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions to read project settings"})
+			return
+		}
+		logging.Errorf(c, "Failed to get MCP tool policy for project %s: %v", project, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get MCP tool policy"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"policy": policy})
+}
+
+// UpdateMCPToolPolicy handles PUT /api/projects/:projectName/mcp-tool-policy
+// Requires update permission on ProjectSettings (project admins). Applies to sessions created afterwards.
+func UpdateMCPToolPolicy(c *gin.Context) {
+	project := c.GetString("project")
+	_, reqDyn := GetK8sClientsForRequest(c)
+	if reqDyn == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing token"})
+		c.Abort()
+		return
+	}
+
+	var policy types.MCPToolPolicy
+	if err := c.ShouldBindJSON(&policy); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if policy.Enforcement == "" {
+		policy.Enforcement = types.MCPToolPolicyFlag
+	}
+	if err := validateMCPToolPolicy(policy); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	tools := make([]interface{}, 0, len(policy.Tools))
+	for _, t := range policy.Tools {
+		tools = append(tools, strings.TrimSpace(t))
+	}
+	value := map[string]interface{}{
+		"mode":        policy.Mode,
+		"tools":       tools,
+		"enforcement": policy.Enforcement,
+	}
+	if err := setProjectSettingsField(c.Request.Context(), reqDyn, project, "mcpToolPolicy", value); err != nil {
+		respondProjectSettingsError(c, project, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"policy": policy})
+}
+
+// DeleteMCPToolPolicy handles DELETE /api/projects/:projectName/mcp-tool-policy
+func DeleteMCPToolPolicy(c *gin.Context) {
+	project := c.GetString("project")
+	_, reqDyn := GetK8sClientsForRequest(c)
+	if reqDyn == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing token"})
+		c.Abort()
+		return
+	}
+
+	if err := setProjectSettingsField(c.Request.Context(), reqDyn, project, "mcpToolPolicy", nil); err != nil && !errors.IsNotFound(err) {
+		respondProjectSettingsError(c, project, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "MCP tool policy removed successfully"})
+}
+
+// setProjectSettingsField sets (or removes, when value is nil) a top-level spec field on the
+// project's ProjectSettings using the caller's permissions
+func setProjectSettingsField(ctx context.Context, dynClient dynamic.Interface, project, field string, value interface{}) error {
+	res := dynClient.Resource(GetProjectSettingsResource()).Namespace(project)
+	for i := 0; i < 3; i++ {
+		obj, err := res.Get(ctx, "projectsettings", v1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		if value == nil {
+			unstructured.RemoveNestedField(obj.Object, "spec", field)
+		} else if err := unstructured.SetNestedField(obj.Object, value, "spec", field); err != nil {
+			return err
+		}
+		_, err = res.Update(ctx, obj, v1.UpdateOptions{})
+		if errors.IsConflict(err) {
+			continue
+		}
+		return err
+	}
+	return fmt.Errorf("failed to update project settings after retries")
+}
+
+func respondProjectSettingsError(c *gin.Context, project string, err error) {
+	switch {
+	case errors.IsNotFound(err):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Project settings not found"})
+	case errors.IsForbidden(err):
+		c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions to update project settings"})
+	default:
+		logging.Errorf(c, "Failed to update project settings for %s: %v", project, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update project settings"})
+	}
+}
@@ -0,0 +1,73 @@
+//go:build test
+
+package handlers
+
+import (
+	test_constants "ambient-code-backend/tests/constants"
+	"ambient-code-backend/types"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+var _ = Describe("MCP Tool Policy", Label(test_constants.LabelUnit, test_constants.LabelHandlers), func() {
+	It("Should allow only listed MCP tools in allowlist mode", func() {
+		policy := &types.MCPToolPolicy{Mode: "allowlist", Tools: []string{"mcp__github__get_*", "mcp__jira__search"}}
+		Expect(policy.Allows("mcp__github__get_issue")).To(BeTrue())
+		Expect(policy.Allows("mcp__jira__search")).To(BeTrue())
+		Expect(policy.Allows("mcp__github__delete_repo")).To(BeFalse())
+		// Built-in tools and platform MCP servers are never restricted
+		Expect(policy.Allows("Bash")).To(BeTrue())
+		Expect(policy.Allows("mcp__session__restart_session")).To(BeTrue())
+	})
+
+	It("Should reject listed MCP tools in denylist mode", func() {
+		policy := &types.MCPToolPolicy{Mode: "denylist", Tools: []string{"mcp__github__delete_*"}}
+		Expect(policy.Allows("mcp__github__delete_repo")).To(BeFalse())
+		Expect(policy.Allows("mcp__github__get_issue")).To(BeTrue())
+
+		var none *types.MCPToolPolicy
+		Expect(none.Allows("mcp__github__delete_repo")).To(BeTrue())
+	})
+
+	It("Should validate policies", func() {
+		Expect(validateMCPToolPolicy(types.MCPToolPolicy{Mode: "allowlist", Enforcement: "block", Tools: []string{"mcp__github__*"}})).To(Succeed())
+		Expect(validateMCPToolPolicy(types.MCPToolPolicy{Mode: "other", Enforcement: "flag"})).To(HaveOccurred())
+		Expect(validateMCPToolPolicy(types.MCPToolPolicy{Mode: "denylist", Enforcement: "warn"})).To(HaveOccurred())
+		Expect(validateMCPToolPolicy(types.MCPToolPolicy{Mode: "denylist", Enforcement: "flag", Tools: []string{"Bash"}})).To(HaveOccurred())
+		Expect(validateMCPToolPolicy(types.MCPToolPolicy{Mode: "denylist", Enforcement: "flag", Tools: []string{"mcp__*__get"}})).To(HaveOccurred())
+	})
+
+	It("Should read the policy from ProjectSettings and default enforcement to flag", func() {
+		obj := &unstructured.Unstructured{Object: map[string]interface{}{
+			"spec": map[string]interface{}{
+				"mcpToolPolicy": map[string]interface{}{
+					"mode":  "denylist",
+					"tools": []interface{}{"mcp__jira__*"},
+				},
+			},
+		}}
+		policy, err := mcpToolPolicyFromSettings(obj)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(policy.Enforcement).To(Equal(types.MCPToolPolicyFlag))
+		Expect(policy.Tools).To(ConsistOf("mcp__jira__*"))
+
+		policy, err = mcpToolPolicyFromSettings(&unstructured.Unstructured{Object: map[string]interface{}{"spec": map[string]interface{}{}}})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(policy).To(BeNil())
+	})
+
+	It("Should read the snapshot recorded on a session", func() {
+		obj := &unstructured.Unstructured{Object: map[string]interface{}{
+			"spec": map[string]interface{}{
+				"environmentVariables": map[string]interface{}{
+					types.MCPToolPolicyEnvVar: `{"mode":"allowlist","tools":["mcp__github__*"],"enforcement":"block"}`,
+				},
+			},
+		}}
+		policy := MCPToolPolicyForSession(obj)
+		Expect(policy).NotTo(BeNil())
+		Expect(policy.Enforcement).To(Equal(types.MCPToolPolicyBlock))
+	})
+})
@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"ambient-code-backend/config"
+	"ambient-code-backend/logging"
+	"ambient-code-backend/server"
+	"ambient-code-backend/types"
+
+	"github.com/gin-gonic/gin"
+	rbacv1 "k8s.io/api/rbac/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// memberStatusConcurrency bounds how many members' integrations are looked up at once
+const memberStatusConcurrency = 8
+
+// permissionRoleOrder ranks roles from most to least access, to report a member bound
+// more than once by their highest role
+var permissionRoleOrder = []string{"admin", "edit", "run", "view", "observer"}
+
+func higherRole(a, b string) string {
+	for _, role := range permissionRoleOrder {
+		if a == role || b == role {
+			return role
+		}
+	}
+	return a
+}
+
+// GetProjectMembersIntegrationsStatus handles GET /api/projects/:projectName/integrations/status
+// Project admins only. Reports which integrations each user granted access to the
+// project has configured, so missing or lapsed credentials show up before work
+// depends on them. Members of groups granted access can't be listed; the groups are
+// returned instead.
+func GetProjectMembersIntegrationsStatus(c *gin.Context) {
+	projectName := c.Param("projectName")
+	reqK8s, _ := GetK8sClientsForRequest(c)
+	if reqK8s == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing token"})
+		return
+	}
+
+	rbs, err := reqK8s.RbacV1().RoleBindings(projectName).List(c.Request.Context(), v1.ListOptions{})
+	if err != nil {
+		logging.Errorf(c, "Failed to list RoleBindings in %s: %v", projectName, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list project members"})
+		return
+	}
+	roles, groups := projectMembers(rbs.Items)
+
+	timeout := config.Current().IntegrationStatusTimeout.Duration
+	resp := types.ProjectIntegrationsStatus{
+		Project:    projectName,
+		Members:    fetchMembersIntegrationsStatus(c.Request.Context(), roles, integrationStatusProviders, timeout),
+		Configured: map[string]int{},
+		Groups:     groups,
+	}
+	for _, p := range integrationStatusProviders {
+		resp.Configured[p.name] = 0
+	}
+	for _, m := range resp.Members {
+		for name, summary := range m.Integrations {
+			if summary.Configured {
+				resp.Configured[name]++
+			}
+		}
+	}
+	c.JSON(http.StatusOK, resp)
+}
+
+// projectMembers returns the users granted access to the project with their highest
+// role, and the sorted names of the groups granted access
+func projectMembers(bindings []rbacv1.RoleBinding) (map[string]string, []string) {
+	users := map[string]string{}
+	groupSet := map[string]bool{}
+	for i := range bindings {
+		rb := &bindings[i]
+		if !isPermissionRoleBinding(rb) {
+			continue
+		}
+		role := permissionBindingRole(rb)
+		if role == "" {
+			continue
+		}
+		for _, sub := range rb.Subjects {
+			switch {
+			case strings.EqualFold(sub.Kind, "User"):
+				users[sub.Name] = higherRole(users[sub.Name], role)
+			case strings.EqualFold(sub.Kind, "Group"):
+				groupSet[sub.Name] = true
+			}
+		}
+	}
+	groups := make([]string, 0, len(groupSet))
+	for g := range groupSet {
+		groups = append(groups, g)
+	}
+	sort.Strings(groups)
+	return users, groups
+}
+
+// fetchMembersIntegrationsStatus looks up each member's integrations, a few members at
+// a time, and returns them sorted by user
+func fetchMembersIntegrationsStatus(ctx context.Context, roles map[string]string, providers []integrationStatusProvider, timeout time.Duration) []types.MemberIntegrationsStatus {
+	members := make([]types.MemberIntegrationsStatus, 0, len(roles))
+	for user, role := range roles {
+		members = append(members, types.MemberIntegrationsStatus{User: user, Role: role})
+	}
+	sort.Slice(members, func(i, j int) bool { return members[i].User < members[j].User })
+
+	sem := make(chan struct{}, memberStatusConcurrency)
+	var wg sync.WaitGroup
+	for i := range members {
+		wg.Add(1)
+		go func(m *types.MemberIntegrationsStatus) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			// Credentials are stored under the sanitized username, as for the member's own requests
+			statuses := fetchIntegrationsStatus(ctx, server.SanitizeUserID(m.User), providers, timeout)
+			m.Integrations = make(map[string]types.IntegrationSummary, len(statuses))
+			for name, status := range statuses {
+				m.Integrations[name] = summarizeIntegration(status.(gin.H))
+			}
+		}(&members[i])
+	}
+	wg.Wait()
+	return members
+}
+
+// summarizeIntegration reduces a provider's status, as returned to the user themselves,
+// to what project admins see
+func summarizeIntegration(status gin.H) types.IntegrationSummary {
+	summary := types.IntegrationSummary{}
+	summary.Status, _ = status["status"].(string)
+	if _, ok := status["installed"]; ok {
+		// GitHub: an App installation or a PAT; the PAT is used when both exist
+		pat, _ := status["pat"].(gin.H)
+		patConfigured, _ := pat["configured"].(bool)
+		installed, _ := status["installed"].(bool)
+		summary.Configured = patConfigured || installed
+		summary.Valid = installed
+		summary.UpdatedAt, _ = status["updatedAt"].(string)
+		if patConfigured {
+			summary.Valid, _ = pat["valid"].(bool)
+			summary.UpdatedAt, _ = pat["updatedAt"].(string)
+		}
+		return summary
+	}
+	summary.Configured, _ = status["connected"].(bool)
+	summary.Valid, _ = status["valid"].(bool)
+	summary.UpdatedAt, _ = status["updatedAt"].(string)
+	return summary
+}
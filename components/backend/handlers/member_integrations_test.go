@@ -0,0 +1,103 @@
+//go:build test
+
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	test_constants "ambient-code-backend/tests/constants"
+	"ambient-code-backend/types"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	authv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+var _ = Describe("Project Members Integrations Status", Label(test_constants.LabelUnit, test_constants.LabelHandlers), func() {
+	var (
+		originalK8sClient   kubernetes.Interface
+		originalK8sClientMw kubernetes.Interface
+		originalNamespace   string
+		isAdmin             bool
+		router              *gin.Engine
+	)
+
+	BeforeEach(func() {
+		originalK8sClient, originalK8sClientMw, originalNamespace = K8sClient, K8sClientMw, Namespace
+		Namespace = "ambient-code"
+		K8sClient = fake.NewSimpleClientset(&corev1.Secret{
+			ObjectMeta: v1.ObjectMeta{Name: "github-pat-credentials", Namespace: "ambient-code"},
+			Data:       map[string][]byte{"kube-bob": []byte(`{"token":"ghp_x","updatedAt":"2026-10-01T00:00:00Z"}`)},
+		})
+		mw := fake.NewSimpleClientset(
+			newPermissionRoleBinding("team-a", "user", "alice", "admin"),
+			newPermissionRoleBinding("team-a", "user", "kube:bob", "view"),
+			newPermissionRoleBinding("team-a", "user", "kube:bob", "edit"),
+			newPermissionRoleBinding("team-a", "group", "eng", "view"),
+			&rbacv1.RoleBinding{
+				ObjectMeta: v1.ObjectMeta{Name: "runner", Namespace: "team-a"},
+				Subjects:   []rbacv1.Subject{{Kind: "ServiceAccount", Name: "runner"}},
+			},
+		)
+		isAdmin = true
+		mw.PrependReactor("create", "selfsubjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			return true, &authv1.SelfSubjectAccessReview{Status: authv1.SubjectAccessReviewStatus{Allowed: isAdmin}}, nil
+		})
+		K8sClientMw = mw
+
+		gin.SetMode(gin.TestMode)
+		router = gin.New()
+		router.GET("/projects/:projectName/integrations/status", RequireProjectAdmin(), GetProjectMembersIntegrationsStatus)
+	})
+
+	AfterEach(func() {
+		K8sClient, K8sClientMw, Namespace = originalK8sClient, originalK8sClientMw, originalNamespace
+	})
+
+	get := func(token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/projects/team-a/integrations/status", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	It("Should report each member's integrations by their highest role", func() {
+		w := get("admin-token")
+		Expect(w.Code).To(Equal(http.StatusOK))
+		var resp types.ProjectIntegrationsStatus
+		Expect(json.Unmarshal(w.Body.Bytes(), &resp)).To(Succeed())
+
+		Expect(resp.Groups).To(Equal([]string{"eng"}))
+		Expect(resp.Members).To(HaveLen(2))
+		Expect(resp.Members[0].User).To(Equal("alice"))
+		Expect(resp.Members[0].Role).To(Equal("admin"))
+		Expect(resp.Members[0].Integrations["github"].Configured).To(BeFalse())
+		Expect(resp.Members[1].User).To(Equal("kube:bob"))
+		Expect(resp.Members[1].Role).To(Equal("edit"))
+		Expect(resp.Members[1].Integrations["github"]).To(Equal(types.IntegrationSummary{
+			Configured: true,
+			Valid:      true,
+			UpdatedAt:  "2026-10-01T00:00:00Z",
+			Status:     "ok",
+		}))
+		Expect(resp.Members[1].Integrations).To(HaveKey("jira"))
+		Expect(resp.Configured).To(HaveKeyWithValue("github", 1))
+		Expect(resp.Configured).To(HaveKeyWithValue("jira", 0))
+	})
+
+	It("Should require project admin access", func() {
+		isAdmin = false
+		Expect(get("member-token").Code).To(Equal(http.StatusForbidden))
+	})
+})
@@ -1,14 +1,17 @@
 package handlers
 
 import (
+	"context"
 	"encoding/base64"
 	"encoding/json"
-	"log"
 	"net/http"
 	"regexp"
 	"strings"
 	"time"
 
+	"ambient-code-backend/auth"
+	"ambient-code-backend/logging"
+
 	"github.com/gin-gonic/gin"
 	authv1 "k8s.io/api/authorization/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
@@ -23,6 +26,9 @@ import (
 var (
 	BaseKubeConfig *rest.Config
 	K8sClientMw    kubernetes.Interface
+	// Authenticators identify callers whose credential arrived after the server
+	// middleware ran (e.g. ?token=); defaults to the OpenShift OAuth proxy
+	Authenticators []auth.Authenticator
 )
 
 // Helper functions and types
@@ -31,6 +37,31 @@ var (
 	StringPtr = func(s string) *string { return &s }
 )
 
+// RequestID returns the ID of the current request, assigning one (from the
+// X-Request-ID header when it is sane, else random) on first use. The ID is also
+// stored on the request context so logging and outbound calls can carry it.
+func RequestID(c *gin.Context) string {
+	if id := c.GetString(logging.GinRequestIDKey); id != "" {
+		return id
+	}
+	id := c.GetHeader(logging.RequestIDHeader)
+	if !logging.ValidRequestID(id) {
+		id = logging.NewRequestID()
+	}
+	c.Set(logging.GinRequestIDKey, id)
+	c.Request = c.Request.WithContext(logging.WithRequestID(c.Request.Context(), id))
+	c.Header(logging.RequestIDHeader, id)
+	return id
+}
+
+// RequestIDMiddleware assigns every request an ID and echoes it in the X-Request-ID response header
+func RequestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		RequestID(c)
+		c.Next()
+	}
+}
+
 // Kubernetes DNS-1123 label validation (namespace, service account names)
 var kubernetesNameRegex = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?$`)
 
@@ -82,43 +113,83 @@ type ContentListItem struct {
 func getK8sClientsDefault(c *gin.Context) (kubernetes.Interface, dynamic.Interface) {
 	token, tokenSource, hasAuthHeader, hasFwdToken := extractRequestToken(c)
 
-	// SECURITY: No authentication bypass in production code.
-	// All requests must provide a valid user token. No environment variable checks.
-	// No fallback to service account credentials.
-
-	if token != "" && BaseKubeConfig != nil {
-		cfg := *BaseKubeConfig
-		cfg.BearerToken = token
-		// Ensure we do NOT fall back to the in-cluster SA token or other auth providers
-		cfg.BearerTokenFile = ""
-		cfg.AuthProvider = nil
-		cfg.ExecProvider = nil
-		cfg.Username = ""
-		cfg.Password = ""
-
-		kc, err1 := kubernetes.NewForConfig(&cfg)
-		dc, err2 := dynamic.NewForConfig(&cfg)
-
-		if err1 == nil && err2 == nil {
-
-			// Best-effort update last-used for service account tokens
-			updateAccessKeyLastUsedAnnotation(c)
-			return kc, dc
+	// API keys were resolved by APIKeyAuth; act as the key's owner
+	if v, ok := c.Get(apiKeyContextKey); ok {
+		if key, ok := v.(*APIKey); ok {
+			return apiKeyK8sClients(key)
 		}
-		// Token provided but client build failed – treat as invalid token
-		log.Printf("Failed to build user-scoped k8s clients (source=%s tokenLen=%d) typedErr=%v dynamicErr=%v for %s", tokenSource, len(token), err1, err2, c.FullPath())
+	}
+	// Session tokens were verified and scoped by SessionTokenAuth; act as the minting user
+	if v, ok := c.Get(sessionTokenContextKey); ok {
+		if claims, ok := v.(*SessionTokenClaims); ok {
+			return impersonatedK8sClients("session token "+claims.ID, claims.Subject, claims.Groups)
+		}
+	}
+	if strings.HasPrefix(token, APIKeyPrefix) {
+		// Never forward an unresolved API key to the Kubernetes API
+		logging.Infof(c, "Unresolved API key presented for %s", c.FullPath())
+		return nil, nil
+	}
+	if strings.HasPrefix(token, SessionTokenPrefix) {
+		// Likewise for session tokens outside the routes SessionTokenAuth guards
+		logging.Infof(c, "Unresolved session token presented for %s", c.FullPath())
 		return nil, nil
 	}
 
-	if token != "" && BaseKubeConfig == nil {
-		// Token was provided but the backend is misconfigured; don't pretend it's a missing token.
-		log.Printf("Cannot build user-scoped k8s clients: BaseKubeConfig is nil (source=%s tokenLen=%d) for %s", tokenSource, len(token), c.FullPath())
+	// SECURITY: No authentication bypass in production code.
+	// All requests must be authenticated by a configured backend. No environment
+	// variable checks. No fallback to service account credentials.
+	res, err := requestAuthResult(c)
+	if err != nil {
+		logging.Infof(c, "Rejected credentials for %s: %v", c.FullPath(), err)
+		return nil, nil
+	}
+	if res == nil {
+		// No token provided (or headers present but parsed to empty token)
+		logging.Infof(c, "No user token found for %s (tokenSource=%s hasAuthHeader=%t hasFwdToken=%t)", c.FullPath(), tokenSource, hasAuthHeader, hasFwdToken)
 		return nil, nil
 	}
+	kc, dc, err := res.Clients()
+	if err == auth.ErrNoToken {
+		logging.Infof(c, "No user token found for %s (tokenSource=%s hasAuthHeader=%t hasFwdToken=%t)", c.FullPath(), tokenSource, hasAuthHeader, hasFwdToken)
+		return nil, nil
+	}
+	if err != nil {
+		// Credential accepted but client build failed – treat as invalid
+		logging.Errorf(c, "Failed to build %s k8s clients (source=%s tokenLen=%d) for %s: %v", res.Authenticator.Name(), tokenSource, len(token), c.FullPath(), err)
+		return nil, nil
+	}
+	if res.Identity.Token != "" {
+		// Best-effort update last-used for service account tokens
+		updateAccessKeyLastUsedAnnotation(c)
+	}
+	return kc, dc
+}
 
-	// No token provided (or headers present but parsed to empty token)
-	log.Printf("No user token found for %s (tokenSource=%s hasAuthHeader=%t hasFwdToken=%t)", c.FullPath(), tokenSource, hasAuthHeader, hasFwdToken)
-	return nil, nil
+// requestAuthResult returns the caller authenticated by the server middleware, or
+// authenticates the request now when its credential was added later
+func requestAuthResult(c *gin.Context) (*auth.Result, error) {
+	if v, ok := c.Get(auth.ContextKey); ok {
+		if res, ok := v.(*auth.Result); ok && res != nil {
+			return res, nil
+		}
+	}
+	authenticators := Authenticators
+	if len(authenticators) == 0 {
+		authenticators = []auth.Authenticator{&auth.OpenShift{Config: BaseKubeConfig}}
+	}
+	return auth.Authenticate(c.Request, authenticators)
+}
+
+// impersonatedK8sClients builds clients that act as username and groups using the
+// backend's own credentials. caller describes the principal in log messages.
+func impersonatedK8sClients(caller, username string, groups []string) (kubernetes.Interface, dynamic.Interface) {
+	kc, dc, err := auth.ImpersonatingClients(BaseKubeConfig, username, groups)
+	if err != nil {
+		logging.Errorf(context.Background(), "Failed to build clients for %s: %v", caller, err)
+		return nil, nil
+	}
+	return kc, dc
 }
 
 // extractRequestToken extracts a caller token from request headers with consistent semantics across
@@ -253,7 +324,7 @@ func updateAccessKeyLastUsedAnnotation(c *gin.Context) {
 	}
 	_, err = K8sClientMw.CoreV1().ServiceAccounts(ns).Patch(c.Request.Context(), saName, types.MergePatchType, b, v1.PatchOptions{})
 	if err != nil && !errors.IsNotFound(err) {
-		log.Printf("Failed to update last-used annotation for SA %s/%s: %v", ns, saName, err)
+		logging.Errorf(c, "Failed to update last-used annotation for SA %s/%s: %v", ns, saName, err)
 	}
 }
 
@@ -321,6 +392,8 @@ func ValidateProjectContext() gin.HandlerFunc {
 		if c.GetHeader("Authorization") == "" && c.GetHeader("X-Forwarded-Access-Token") == "" {
 			if qp := strings.TrimSpace(c.Query("token")); qp != "" {
 				c.Request.Header.Set("Authorization", "Bearer "+qp)
+				// Authenticate the new credential rather than the headers seen before
+				c.Set(auth.ContextKey, (*auth.Result)(nil))
 			}
 		}
 
@@ -356,24 +429,19 @@ func ValidateProjectContext() gin.HandlerFunc {
 		}
 
 		// Ensure the caller has at least list permission on agenticsessions in the namespace
-		ssar := &authv1.SelfSubjectAccessReview{
-			Spec: authv1.SelfSubjectAccessReviewSpec{
-				ResourceAttributes: &authv1.ResourceAttributes{
-					Group:     "vteam.ambient-code",
-					Resource:  "agenticsessions",
-					Verb:      "list",
-					Namespace: projectHeader,
-				},
-			},
-		}
-		res, err := reqK8s.AuthorizationV1().SelfSubjectAccessReviews().Create(c.Request.Context(), ssar, v1.CreateOptions{})
+		allowed, err := CheckAccessForRequest(c, reqK8s, authv1.ResourceAttributes{
+			Group:     "vteam.ambient-code",
+			Resource:  "agenticsessions",
+			Verb:      "list",
+			Namespace: projectHeader,
+		})
 		if err != nil {
-			log.Printf("validateProjectContext: SSAR failed for %s: %v", projectHeader, err)
+			logging.Errorf(c, "validateProjectContext: SSAR failed for %s: %v", projectHeader, err)
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to perform access review"})
 			c.Abort()
 			return
 		}
-		if !res.Status.Allowed {
+		if !allowed {
 			c.JSON(http.StatusForbidden, gin.H{"error": "Unauthorized to access project"})
 			c.Abort()
 			return
@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/cgi"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"ambient-code-backend/git"
+	"ambient-code-backend/logging"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MirrorRoot is the directory holding bare mirrors in MIRROR_SERVICE_MODE
+var MirrorRoot = "/git-mirror"
+
+// Package-level git dependency (mockable in tests)
+var GitSyncMirror = git.SyncMirror
+
+var (
+	mirrorSyncsMu sync.Mutex
+	mirrorSyncs   = map[string]bool{}
+)
+
+// mirrorRepoPathPattern matches /mirror/git/<key>.git/<smart-http path>
+var mirrorRepoPathPattern = regexp.MustCompile(`^/([0-9a-f]{64}\.git)(/.*)$`)
+
+// MirrorSync populates or refreshes the mirror for a repository in the background
+// Body: { url: string }
+// POST /mirror/sync
+func MirrorSync(c *gin.Context) {
+	var body struct {
+		URL string `json:"url" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&body); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "url is required"})
+		return
+	}
+	if !strings.HasPrefix(strings.TrimSpace(body.URL), "https://") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "only https repository URLs can be mirrored"})
+		return
+	}
+
+	key := git.MirrorKey(body.URL)
+	mirrorSyncsMu.Lock()
+	inFlight := mirrorSyncs[key]
+	mirrorSyncs[key] = true
+	mirrorSyncsMu.Unlock()
+
+	if !inFlight {
+		go func(repoURL string) {
+			defer func() {
+				mirrorSyncsMu.Lock()
+				delete(mirrorSyncs, key)
+				mirrorSyncsMu.Unlock()
+			}()
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+			defer cancel()
+			start := time.Now()
+			if _, err := GitSyncMirror(ctx, MirrorRoot, repoURL); err != nil {
+				logging.Infof(context.Background(), "MirrorSync: %s: %v", repoURL, err)
+				return
+			}
+			logging.Infof(context.Background(), "MirrorSync: %s synced in %s", repoURL, time.Since(start).Round(time.Second))
+		}(body.URL)
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{"key": key, "syncing": true})
+}
+
+// MirrorStatus reports whether a repository is cached
+// GET /mirror/status?url=
+func MirrorStatus(c *gin.Context) {
+	repoURL := strings.TrimSpace(c.Query("url"))
+	if repoURL == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "url is required"})
+		return
+	}
+	key := git.MirrorKey(repoURL)
+	mirrorSyncsMu.Lock()
+	syncing := mirrorSyncs[key]
+	mirrorSyncsMu.Unlock()
+	c.JSON(http.StatusOK, gin.H{
+		"key":     key,
+		"cached":  git.MirrorReferenceDir(MirrorRoot, repoURL) != "",
+		"syncing": syncing,
+	})
+}
+
+// MirrorGitHTTP serves cached mirrors read-only over git smart HTTP so sessions in any
+// namespace can bootstrap clones from the cluster instead of the upstream host.
+// GET /mirror/git/<key>.git/info/refs, POST /mirror/git/<key>.git/git-upload-pack
+func MirrorGitHTTP(c *gin.Context) {
+	m := mirrorRepoPathPattern.FindStringSubmatch(c.Param("path"))
+	if m == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "not found"})
+		return
+	}
+	// Read-only: refuse pushes
+	if c.Query("service") == "git-receive-pack" || strings.HasSuffix(m[2], "/git-receive-pack") {
+		c.JSON(http.StatusForbidden, gin.H{"error": "mirror is read-only"})
+		return
+	}
+
+	execPath, err := exec.Command("git", "--exec-path").Output()
+	if err != nil {
+		logging.Warnf(c, "MirrorGitHTTP: git not available: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "git not available"})
+		return
+	}
+	handler := &cgi.Handler{
+		Path: filepath.Join(strings.TrimSpace(string(execPath)), "git-http-backend"),
+		Root: "/mirror/git",
+		Env: []string{
+			"GIT_PROJECT_ROOT=" + MirrorRoot,
+			"GIT_HTTP_EXPORT_ALL=1",
+		},
+	}
+	handler.ServeHTTP(c.Writer, c.Request)
+}
@@ -0,0 +1,82 @@
+//go:build test
+
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"ambient-code-backend/git"
+	test_constants "ambient-code-backend/tests/constants"
+	"ambient-code-backend/tests/test_utils"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Repo Mirror Service", Label(test_constants.LabelUnit, test_constants.LabelHandlers, test_constants.LabelGit), func() {
+	var (
+		httpUtils          *test_utils.HTTPTestUtils
+		originalRoot       string
+		originalSyncMirror func(context.Context, string, string) (string, error)
+	)
+	repo := "https://github.com/org/app.git"
+
+	BeforeEach(func() {
+		httpUtils = test_utils.NewHTTPTestUtils()
+		originalRoot = MirrorRoot
+		originalSyncMirror = GitSyncMirror
+		MirrorRoot = GinkgoT().TempDir()
+	})
+
+	AfterEach(func() {
+		MirrorRoot = originalRoot
+		GitSyncMirror = originalSyncMirror
+	})
+
+	It("Should normalize equivalent repository URLs to the same key", func() {
+		Expect(git.MirrorKey("https://github.com/Org/App.git")).To(Equal(git.MirrorKey("https://github.com/org/app/")))
+	})
+
+	It("Should report uncached repositories", func() {
+		context := httpUtils.CreateTestGinContext("GET", "/mirror/status?url="+repo, nil)
+		MirrorStatus(context)
+
+		httpUtils.AssertHTTPStatus(http.StatusOK)
+		httpUtils.AssertJSONContains(map[string]interface{}{"cached": false, "key": git.MirrorKey(repo)})
+	})
+
+	It("Should report cached repositories", func() {
+		dir := git.MirrorPath(MirrorRoot, repo)
+		Expect(os.MkdirAll(dir, 0755)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(dir, "HEAD"), []byte("ref: refs/heads/main\n"), 0644)).To(Succeed())
+
+		context := httpUtils.CreateTestGinContext("GET", "/mirror/status?url="+repo, nil)
+		MirrorStatus(context)
+
+		httpUtils.AssertJSONContains(map[string]interface{}{"cached": true})
+	})
+
+	It("Should start a background sync", func() {
+		synced := make(chan string, 1)
+		GitSyncMirror = func(ctx context.Context, root, repoURL string) (string, error) {
+			synced <- repoURL
+			return git.MirrorPath(root, repoURL), nil
+		}
+
+		context := httpUtils.CreateTestGinContext("POST", "/mirror/sync", map[string]interface{}{"url": repo})
+		MirrorSync(context)
+
+		httpUtils.AssertHTTPStatus(http.StatusAccepted)
+		Eventually(synced).Should(Receive(Equal(repo)))
+	})
+
+	It("Should reject non-https URLs", func() {
+		context := httpUtils.CreateTestGinContext("POST", "/mirror/sync", map[string]interface{}{"url": "file:///etc"})
+		MirrorSync(context)
+
+		httpUtils.AssertHTTPStatus(http.StatusBadRequest)
+	})
+})
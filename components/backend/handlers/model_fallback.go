@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"fmt"
+	"strings"
+)
+
+// maxFallbackModels bounds a model fallback chain
+const maxFallbackModels = 5
+
+// ValidateFallbackModels checks a fallback chain names distinct models
+func ValidateFallbackModels(primary string, models []string) error {
+	if len(models) > maxFallbackModels {
+		return fmt.Errorf("at most %d fallback models are allowed", maxFallbackModels)
+	}
+	seen := map[string]bool{primary: primary != ""}
+	for _, m := range models {
+		if strings.TrimSpace(m) == "" {
+			return fmt.Errorf("fallback model names must not be empty")
+		}
+		if seen[m] {
+			return fmt.Errorf("model %q appears more than once in the fallback chain", m)
+		}
+		seen[m] = true
+	}
+	return nil
+}
+
+// fallbackModelsValue converts a fallback chain for an unstructured spec
+func fallbackModelsValue(models []string) []interface{} {
+	values := make([]interface{}, len(models))
+	for i, m := range models {
+		values[i] = m
+	}
+	return values
+}
@@ -0,0 +1,343 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"ambient-code-backend/logging"
+	"ambient-code-backend/notifications"
+
+	"github.com/gin-gonic/gin"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Notification rules live in ProjectSettings spec.notificationRules; the Slack webhook
+// URLs they name are credentials and live in this Secret, one data key per webhook.
+const notificationWebhooksSecret = "ambient-notification-webhooks"
+
+// Notifier delivers project notifications; nil disables them
+var Notifier *notifications.Dispatcher
+
+// NotificationSource resolves rules and webhooks with the backend service account
+type NotificationSource struct{}
+
+// Rules returns the project's notification rules
+func (NotificationSource) Rules(ctx context.Context, project string) ([]notifications.Rule, error) {
+	if DynamicClient == nil {
+		return nil, fmt.Errorf("dynamic client not initialized")
+	}
+	obj, err := DynamicClient.Resource(GetProjectSettingsResource()).Namespace(project).Get(ctx, "projectsettings", v1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return notificationRulesFromSettings(obj)
+}
+
+// WebhookURL returns the URL stored for the named webhook
+func (NotificationSource) WebhookURL(ctx context.Context, project, name string) (string, error) {
+	if K8sClient == nil {
+		return "", fmt.Errorf("kubernetes client not initialized")
+	}
+	secret, err := K8sClient.CoreV1().Secrets(project).Get(ctx, notificationWebhooksSecret, v1.GetOptions{})
+	if err != nil {
+		return "", err
+	}
+	url := string(secret.Data[name])
+	if url == "" {
+		return "", fmt.Errorf("webhook not configured")
+	}
+	return url, nil
+}
+
+// notificationRulesFromSettings reads spec.notificationRules from a ProjectSettings object
+func notificationRulesFromSettings(obj *unstructured.Unstructured) ([]notifications.Rule, error) {
+	raw, found, err := unstructured.NestedSlice(obj.Object, "spec", "notificationRules")
+	if err != nil || !found {
+		return nil, err
+	}
+	b, err := json.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+	var rules []notifications.Rule
+	if err := json.Unmarshal(b, &rules); err != nil {
+		return nil, fmt.Errorf("invalid notificationRules: %w", err)
+	}
+	return rules, nil
+}
+
+// NotifyRunStatus notifies the session's project that a run ended. runStatus is the
+// AG-UI run status; interrupted runs are resubmitted, so they are not reported.
+// Best effort; intended to be called in a goroutine.
+func NotifyRunStatus(project, sessionName, runID, runStatus, errorMessage string) {
+	if Notifier == nil {
+		return
+	}
+	event := notifications.Event{
+		Project: project,
+		Session: sessionName,
+		RunID:   runID,
+		URL:     sessionTranscriptURL(project, sessionName),
+	}
+	switch runStatus {
+	case "completed":
+		event.Type = notifications.EventRunFinished
+	case "error":
+		event.Type = notifications.EventRunErrored
+		event.Detail = errorMessage
+	default:
+		return
+	}
+
+	if DynamicClient != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		obj, err := GetCachedSession(ctx, project, sessionName)
+		if err == nil {
+			event.DisplayName, _, _ = unstructured.NestedString(obj.Object, "spec", "displayName")
+		}
+	}
+	Notifier.Notify(event)
+}
+
+// GetNotifications handles GET /api/projects/:projectName/notifications
+// Returns the rules and the names of configured webhooks (URLs are never returned).
+func GetNotifications(c *gin.Context) {
+	project := c.GetString("project")
+	reqK8s, reqDyn := GetK8sClientsForRequest(c)
+	if reqK8s == nil || reqDyn == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing token"})
+		c.Abort()
+		return
+	}
+	ctx := c.Request.Context()
+
+	rules := []notifications.Rule{}
+	obj, err := reqDyn.Resource(GetProjectSettingsResource()).Namespace(project).Get(ctx, "projectsettings", v1.GetOptions{})
+	switch {
+	case err == nil:
+		if stored, err := notificationRulesFromSettings(obj); err != nil {
+			logging.Warnf(c, "Ignoring invalid notification rules in project %s: %v", project, err)
+		} else if stored != nil {
+			rules = stored
+		}
+	case errors.IsForbidden(err):
+		c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions to read project settings"})
+		return
+	case !errors.IsNotFound(err):
+		logging.Errorf(c, "Failed to get notification rules for project %s: %v", project, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get notification rules"})
+		return
+	}
+
+	// Webhook names need secret read access; viewers just see none
+	webhooks := []string{}
+	if secret, err := reqK8s.CoreV1().Secrets(project).Get(ctx, notificationWebhooksSecret, v1.GetOptions{}); err == nil {
+		for name := range secret.Data {
+			webhooks = append(webhooks, name)
+		}
+		sort.Strings(webhooks)
+	}
+
+	c.JSON(http.StatusOK, gin.H{"rules": rules, "webhooks": webhooks, "events": notifications.EventTypes})
+}
+
+// UpdateNotificationRules handles PUT /api/projects/:projectName/notifications/rules
+// Replaces the project's rules. Requires update permission on ProjectSettings.
+func UpdateNotificationRules(c *gin.Context) {
+	project := c.GetString("project")
+	_, reqDyn := GetK8sClientsForRequest(c)
+	if reqDyn == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing token"})
+		c.Abort()
+		return
+	}
+
+	var req struct {
+		Rules []notifications.Rule `json:"rules"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	value := make([]interface{}, 0, len(req.Rules))
+	for i, r := range req.Rules {
+		if err := notifications.ValidateRule(r); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("rule %d: %v", i, err)})
+			return
+		}
+		events := make([]interface{}, 0, len(r.Events))
+		for _, e := range r.Events {
+			events = append(events, e)
+		}
+		rule := map[string]interface{}{"events": events, "webhook": r.Webhook}
+		if r.Name != "" {
+			rule["name"] = r.Name
+		}
+		if r.Mention != "" {
+			rule["mention"] = r.Mention
+		}
+		value = append(value, rule)
+	}
+
+	var field interface{} = value
+	if len(value) == 0 {
+		field = nil
+	}
+	if err := setProjectSettingsField(c.Request.Context(), reqDyn, project, "notificationRules", field); err != nil {
+		respondProjectSettingsError(c, project, err)
+		return
+	}
+	if req.Rules == nil {
+		req.Rules = []notifications.Rule{}
+	}
+	c.JSON(http.StatusOK, gin.H{"rules": req.Rules})
+}
+
+// PutNotificationWebhook handles PUT /api/projects/:projectName/notifications/webhooks/:name
+// Stores a Slack incoming webhook URL with the caller's permissions (requires secret write access).
+func PutNotificationWebhook(c *gin.Context) {
+	project := c.GetString("project")
+	name := c.Param("name")
+	reqK8s, _ := GetK8sClientsForRequest(c)
+	if reqK8s == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing token"})
+		c.Abort()
+		return
+	}
+
+	var req struct {
+		URL string `json:"url" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := notifications.ValidateWebhookName(name); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := notifications.ValidateWebhookURL(req.URL); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx := c.Request.Context()
+	secrets := reqK8s.CoreV1().Secrets(project)
+	existing, err := secrets.Get(ctx, notificationWebhooksSecret, v1.GetOptions{})
+	switch {
+	case errors.IsNotFound(err):
+		_, err = secrets.Create(ctx, &corev1.Secret{
+			ObjectMeta: v1.ObjectMeta{
+				Name:      notificationWebhooksSecret,
+				Namespace: project,
+				Labels:    map[string]string{"app": "ambient-code", "ambient-code.io/purpose": "notifications"},
+			},
+			Type: corev1.SecretTypeOpaque,
+			Data: map[string][]byte{name: []byte(req.URL)},
+		}, v1.CreateOptions{})
+	case err == nil:
+		if existing.Data == nil {
+			existing.Data = map[string][]byte{}
+		}
+		existing.Data[name] = []byte(req.URL)
+		_, err = secrets.Update(ctx, existing, v1.UpdateOptions{})
+	}
+	if err != nil {
+		if errors.IsForbidden(err) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions to manage project secrets"})
+			return
+		}
+		logging.Errorf(c, "Failed to store notification webhook %s for project %s: %v", name, project, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save webhook"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"name": name, "configured": true})
+}
+
+// DeleteNotificationWebhook handles DELETE /api/projects/:projectName/notifications/webhooks/:name
+// Rules still naming the webhook are skipped until it is configured again.
+func DeleteNotificationWebhook(c *gin.Context) {
+	project := c.GetString("project")
+	name := c.Param("name")
+	reqK8s, _ := GetK8sClientsForRequest(c)
+	if reqK8s == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing token"})
+		c.Abort()
+		return
+	}
+
+	ctx := c.Request.Context()
+	secrets := reqK8s.CoreV1().Secrets(project)
+	existing, err := secrets.Get(ctx, notificationWebhooksSecret, v1.GetOptions{})
+	if err == nil {
+		if _, ok := existing.Data[name]; !ok {
+			c.JSON(http.StatusOK, gin.H{"message": "Webhook removed successfully"})
+			return
+		}
+		delete(existing.Data, name)
+		_, err = secrets.Update(ctx, existing, v1.UpdateOptions{})
+	}
+	if err != nil && !errors.IsNotFound(err) {
+		if errors.IsForbidden(err) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions to manage project secrets"})
+			return
+		}
+		logging.Errorf(c, "Failed to delete notification webhook %s for project %s: %v", name, project, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove webhook"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Webhook removed successfully"})
+}
+
+// TestNotificationWebhook handles POST /api/projects/:projectName/notifications/webhooks/:name/test
+// Sends a sample message so admins can check the webhook and channel
+func TestNotificationWebhook(c *gin.Context) {
+	project := c.GetString("project")
+	name := c.Param("name")
+	reqK8s, _ := GetK8sClientsForRequest(c)
+	if reqK8s == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing token"})
+		c.Abort()
+		return
+	}
+
+	secret, err := reqK8s.CoreV1().Secrets(project).Get(c.Request.Context(), notificationWebhooksSecret, v1.GetOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		if errors.IsForbidden(err) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions to read project secrets"})
+			return
+		}
+		logging.Errorf(c, "Failed to get notification webhooks for project %s: %v", project, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get webhook"})
+		return
+	}
+	if secret == nil || len(secret.Data[name]) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Webhook not found"})
+		return
+	}
+
+	msg := notifications.SlackMessage(notifications.Event{
+		Type:    notifications.EventRunFinished,
+		Project: project,
+		Session: "example-session",
+		Detail:  "Test notification from vTeam. Rules using this webhook will post here.",
+	}, "")
+	client := &http.Client{Timeout: 10 * time.Second}
+	if err := notifications.PostSlack(c.Request.Context(), client, string(secret.Data[name]), msg); err != nil {
+		logging.Infof(c, "Test notification to webhook %s in project %s failed: %v", name, project, err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": fmt.Sprintf("Slack rejected the message: %v", err)})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Test notification sent"})
+}
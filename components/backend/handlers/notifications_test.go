@@ -0,0 +1,71 @@
+//go:build test
+
+package handlers
+
+import (
+	"context"
+
+	"ambient-code-backend/notifications"
+	test_constants "ambient-code-backend/tests/constants"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+var _ = Describe("Notifications", Label(test_constants.LabelUnit, test_constants.LabelHandlers), func() {
+	It("Should read rules from ProjectSettings", func() {
+		obj := &unstructured.Unstructured{Object: map[string]interface{}{
+			"spec": map[string]interface{}{
+				"notificationRules": []interface{}{
+					map[string]interface{}{
+						"events":  []interface{}{notifications.EventRunErrored},
+						"webhook": "alerts",
+						"mention": "<!here>",
+					},
+				},
+			},
+		}}
+		rules, err := notificationRulesFromSettings(obj)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rules).To(HaveLen(1))
+		Expect(rules[0].Matches(notifications.EventRunErrored)).To(BeTrue())
+		Expect(rules[0].Matches(notifications.EventRunFinished)).To(BeFalse())
+		Expect(rules[0].Mention).To(Equal("<!here>"))
+
+		rules, err = notificationRulesFromSettings(&unstructured.Unstructured{Object: map[string]interface{}{"spec": map[string]interface{}{}}})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rules).To(BeNil())
+	})
+
+	Context("Webhook lookup", func() {
+		var originalK8sClient kubernetes.Interface
+
+		BeforeEach(func() {
+			originalK8sClient = K8sClient
+			K8sClient = fake.NewSimpleClientset(&corev1.Secret{
+				ObjectMeta: v1.ObjectMeta{Name: notificationWebhooksSecret, Namespace: "team-a"},
+				Data:       map[string][]byte{"alerts": []byte("https://hooks.slack.com/services/T/B/X")},
+			})
+		})
+
+		AfterEach(func() {
+			K8sClient = originalK8sClient
+		})
+
+		It("Should resolve configured webhooks and reject unknown ones", func() {
+			url, err := NotificationSource{}.WebhookURL(context.Background(), "team-a", "alerts")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(url).To(Equal("https://hooks.slack.com/services/T/B/X"))
+
+			_, err = NotificationSource{}.WebhookURL(context.Background(), "team-a", "other")
+			Expect(err).To(HaveOccurred())
+			_, err = NotificationSource{}.WebhookURL(context.Background(), "team-b", "alerts")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
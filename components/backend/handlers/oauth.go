@@ -8,12 +8,14 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"log"
 	"net/http"
 	"os"
 	"strings"
 	"time"
 
+	"ambient-code-backend/logging"
+	"ambient-code-backend/metrics"
+
 	"github.com/gin-gonic/gin"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
@@ -129,7 +131,7 @@ func GetOAuthURL(c *gin.Context) {
 		return
 	}
 	if err != nil {
-		log.Printf("Failed to get session %s/%s: %v", projectName, sessionName, err)
+		logging.Errorf(c, "Failed to get session %s/%s: %v", projectName, sessionName, err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify session"})
 		return
 	}
@@ -137,7 +139,7 @@ func GetOAuthURL(c *gin.Context) {
 	// Get OAuth provider config
 	provider, err := getOAuthProvider(providerName)
 	if err != nil {
-		log.Printf("Failed to get OAuth provider: %v", err)
+		logging.Errorf(c, "Failed to get OAuth provider: %v", err)
 		c.JSON(http.StatusServiceUnavailable, gin.H{"error": fmt.Sprintf("%s OAuth not configured", providerName)})
 		return
 	}
@@ -153,7 +155,7 @@ func GetOAuthURL(c *gin.Context) {
 	// Serialize state to JSON
 	stateJSON, err := json.Marshal(stateData)
 	if err != nil {
-		log.Printf("Failed to marshal state: %v", err)
+		logging.Errorf(c, "Failed to marshal state: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate OAuth state"})
 		return
 	}
@@ -161,7 +163,7 @@ func GetOAuthURL(c *gin.Context) {
 	// Get HMAC secret from environment
 	secret := os.Getenv("OAUTH_STATE_SECRET")
 	if secret == "" {
-		log.Printf("OAUTH_STATE_SECRET not configured")
+		logging.Warnf(c, "OAUTH_STATE_SECRET not configured")
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "OAuth state validation not configured"})
 		return
 	}
@@ -205,7 +207,7 @@ func GetOAuthURL(c *gin.Context) {
 		return
 	}
 
-	log.Printf("Generated OAuth URL for %s/%s (provider: %s, stateLen: %d)", projectName, sessionName, providerName, len(stateToken))
+	logging.Infof(c, "Generated OAuth URL for %s/%s (provider: %s, stateLen: %d)", projectName, sessionName, providerName, len(stateToken))
 
 	c.JSON(http.StatusOK, gin.H{
 		"url":   authURL,
@@ -227,12 +229,12 @@ func HandleOAuth2Callback(c *gin.Context) {
 		provider = "google"
 	}
 
-	log.Printf("OAuth2 callback received - provider: %s, hasCode: %v, hasState: %v, error: %s",
+	logging.Errorf(c, "OAuth2 callback received - provider: %s, hasCode: %v, hasState: %v, error: %s",
 		provider, code != "", state != "", errorParam)
 
 	// Handle OAuth errors early
 	if errorParam != "" {
-		log.Printf("OAuth error received: %s - %s", errorParam, errorDesc)
+		logging.Errorf(c, "OAuth error received: %s - %s", errorParam, errorDesc)
 		callbackData := OAuthCallbackData{
 			Provider:   provider,
 			Code:       code,
@@ -244,7 +246,7 @@ func HandleOAuth2Callback(c *gin.Context) {
 		}
 		// Store the error for MCP to retrieve
 		if err := storeOAuthCallback(c.Request.Context(), state, &callbackData); err != nil {
-			log.Printf("Failed to store OAuth error: %v", err)
+			logging.Errorf(c, "Failed to store OAuth error: %v", err)
 		}
 		c.HTML(http.StatusOK, "<html><body><h1>Authorization Error</h1><p>Error: "+errorParam+"</p><p>"+errorDesc+"</p><p>Provider: "+provider+"</p><p>You can close this window.</p></body></html>", nil)
 		return
@@ -264,11 +266,11 @@ func HandleOAuth2Callback(c *gin.Context) {
 		if jsonErr := json.Unmarshal(stateBytes, &stateMap); jsonErr == nil {
 			// Check if this is cluster-level OAuth
 			if isCluster, ok := stateMap["cluster"].(bool); ok && isCluster {
-				log.Printf("Detected cluster-level OAuth flow")
+				logging.Infof(c, "Detected cluster-level OAuth flow")
 
 				// Handle cluster-level Google OAuth (this will exchange the code)
 				if err := HandleGoogleOAuthCallback(c.Request.Context(), code, stateMap); err != nil {
-					log.Printf("Cluster-level OAuth failed: %v", err)
+					logging.Errorf(c, "Cluster-level OAuth failed: %v", err)
 					// Return generic error to client, details logged server-side only
 					c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(
 						"<html><body><h1>Authorization Error</h1><p>Failed to connect Google Drive. Please try again.</p><p>You can close this window.</p><script>window.close();</script></body></html>",
@@ -305,7 +307,7 @@ func HandleOAuth2Callback(c *gin.Context) {
 	// Get provider configuration
 	providerConfig, err := getOAuthProvider(provider)
 	if err != nil {
-		log.Printf("Failed to get OAuth provider config: %v", err)
+		logging.Errorf(c, "Failed to get OAuth provider config: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "OAuth provider not configured"})
 		return
 	}
@@ -320,12 +322,12 @@ func HandleOAuth2Callback(c *gin.Context) {
 	// Exchange code for token (for legacy session-specific flow)
 	tokenData, err := exchangeOAuthCode(c.Request.Context(), providerConfig, code, redirectURI)
 	if err != nil {
-		log.Printf("Failed to exchange OAuth code: %v", err)
+		logging.Errorf(c, "Failed to exchange OAuth code: %v", err)
 		callbackData.Error = "token_exchange_failed"
 		callbackData.ErrorDesc = err.Error()
 		// Store the failure
 		if serr := storeOAuthCallback(c.Request.Context(), state, &callbackData); serr != nil {
-			log.Printf("Failed to store OAuth exchange error: %v", serr)
+			logging.Errorf(c, "Failed to store OAuth exchange error: %v", serr)
 		}
 		c.JSON(http.StatusBadGateway, gin.H{"error": "failed to exchange authorization code"})
 		return
@@ -340,7 +342,7 @@ func HandleOAuth2Callback(c *gin.Context) {
 	// Fallback to legacy session-specific OAuth
 	stateData, err := validateAndParseOAuthState(state)
 	if err != nil {
-		log.Printf("ERROR: State validation failed: %v (possible CSRF attack or tampering)", err)
+		logging.Errorf(c, "ERROR: State validation failed: %v (possible CSRF attack or tampering)", err)
 		// DO NOT store credentials or proceed - this is a security violation
 		c.Data(http.StatusForbidden, "text/html; charset=utf-8", []byte(
 			"<html><body><h1>Authorization Failed</h1><p>Provider: "+provider+"</p><p><strong>Error:</strong> Invalid or expired state parameter. This may indicate a CSRF attack or session timeout.</p><p>Please try again from the beginning.</p><p>You can close this window.</p><script>window.close();</script></body></html>",
@@ -360,22 +362,22 @@ func HandleOAuth2Callback(c *gin.Context) {
 			tokenData.ExpiresIn,
 		)
 		if err != nil {
-			log.Printf("Failed to store credentials in Secret: %v", err)
+			logging.Errorf(c, "Failed to store credentials in Secret: %v", err)
 			c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(
 				"<html><body><h1>Authorization Error</h1><p>Provider: "+provider+"</p><p><strong>Error:</strong> Failed to store credentials. Please contact support.</p><p>You can close this window.</p><script>window.close();</script></body></html>",
 			))
 			return
 		}
 
-		log.Printf("✓ OAuth flow completed for session %s/%s", stateData.ProjectName, stateData.SessionName)
+		logging.Infof(c, "✓ OAuth flow completed for session %s/%s", stateData.ProjectName, stateData.SessionName)
 		c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(
 			"<html><body><h1>Authorization Successful!</h1><p>Provider: "+provider+"</p><p>Google Drive credentials are now available in your session!</p><p>You can close this window.</p><script>window.close();</script></body></html>",
 		))
 	} else {
-		log.Printf("Warning: State missing session context (projectName=%s, sessionName=%s)", stateData.ProjectName, stateData.SessionName)
+		logging.Warnf(c, "State missing session context (projectName=%s, sessionName=%s)", stateData.ProjectName, stateData.SessionName)
 		// Fallback: store in oauth-callbacks
 		if err := storeOAuthCallback(c.Request.Context(), state, &callbackData); err != nil {
-			log.Printf("Failed to store OAuth callback: %v", err)
+			logging.Errorf(c, "Failed to store OAuth callback: %v", err)
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to store OAuth data"})
 			return
 		}
@@ -412,7 +414,7 @@ func exchangeOAuthCode(ctx context.Context, provider *OAuthProvider, code string
 	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 	req.Header.Set("Accept", "application/json")
 
-	client := &http.Client{Timeout: 15 * time.Second}
+	client := &http.Client{Timeout: 15 * time.Second, Transport: metrics.Transport(provider.Name, "token", nil)}
 	resp, err := client.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("failed to exchange code: %w", err)
@@ -651,7 +653,7 @@ func validateAndParseOAuthState(state string) (*OAuthStateData, error) {
 		return nil, fmt.Errorf("state token has future timestamp (possible replay attack)")
 	}
 
-	log.Printf("✓ Validated OAuth state for %s/%s (provider: %s, age: %ds)", stateData.ProjectName, stateData.SessionName, stateData.Provider, age)
+	logging.Infof(context.Background(), "✓ Validated OAuth state for %s/%s (provider: %s, age: %ds)", stateData.ProjectName, stateData.SessionName, stateData.Provider, age)
 
 	return &stateData, nil
 }
@@ -782,12 +784,12 @@ func storeCredentialsInSecret(ctx context.Context, projectName, sessionName, pro
 			if err != nil {
 				return fmt.Errorf("failed to update Secret %s/%s: %w", projectName, secretName, err)
 			}
-			log.Printf("✓ Updated OAuth credentials Secret %s/%s", projectName, secretName)
+			logging.Infof(ctx, "✓ Updated OAuth credentials Secret %s/%s", projectName, secretName)
 		} else {
 			return fmt.Errorf("failed to create Secret %s/%s: %w", projectName, secretName, err)
 		}
 	} else {
-		log.Printf("✓ Created OAuth credentials Secret %s/%s", projectName, secretName)
+		logging.Infof(ctx, "✓ Created OAuth credentials Secret %s/%s", projectName, secretName)
 	}
 
 	return nil
@@ -810,7 +812,7 @@ type GoogleOAuthCredentials struct {
 
 // isValidUserID validates userID for use as a Kubernetes Secret data key
 // Keys must match regex: [-._a-zA-Z0-9]+
-// Note: userID is sanitized in forwardedIdentityMiddleware to ensure validity
+// Note: userID is sanitized in the server identityMiddleware to ensure validity
 func isValidUserID(userID string) bool {
 	if userID == "" || len(userID) > 253 {
 		return false
@@ -853,7 +855,7 @@ func GetGoogleOAuthURLGlobal(c *gin.Context) {
 	// Get OAuth provider config
 	provider, err := getOAuthProvider("google")
 	if err != nil {
-		log.Printf("Failed to get OAuth provider: %v", err)
+		logging.Errorf(c, "Failed to get OAuth provider: %v", err)
 		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Google OAuth not configured"})
 		return
 	}
@@ -869,7 +871,7 @@ func GetGoogleOAuthURLGlobal(c *gin.Context) {
 	// Serialize state to JSON
 	stateJSON, err := json.Marshal(stateData)
 	if err != nil {
-		log.Printf("Failed to marshal state: %v", err)
+		logging.Errorf(c, "Failed to marshal state: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate OAuth state"})
 		return
 	}
@@ -877,7 +879,7 @@ func GetGoogleOAuthURLGlobal(c *gin.Context) {
 	// Get HMAC secret from environment
 	secret := os.Getenv("OAUTH_STATE_SECRET")
 	if secret == "" {
-		log.Printf("OAUTH_STATE_SECRET not configured")
+		logging.Warnf(c, "OAUTH_STATE_SECRET not configured")
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "OAuth state validation not configured"})
 		return
 	}
@@ -906,7 +908,7 @@ func GetGoogleOAuthURLGlobal(c *gin.Context) {
 		stateToken,
 	)
 
-	log.Printf("Generated cluster-level Google OAuth URL for user %s", userID)
+	logging.Infof(c, "Generated cluster-level Google OAuth URL for user %s", userID)
 
 	c.JSON(http.StatusOK, gin.H{
 		"url":   authURL,
@@ -944,7 +946,7 @@ func HandleGoogleOAuthCallback(ctx context.Context, code string, stateData map[s
 	// Get user's email from Google
 	userEmail, err := getGoogleUserEmail(ctx, tokenData.AccessToken)
 	if err != nil {
-		log.Printf("Warning: failed to get user email: %v", err)
+		logging.Warnf(ctx, "Failed to get user email: %v", err)
 		userEmail = "" // Non-fatal
 	}
 
@@ -963,7 +965,7 @@ func HandleGoogleOAuthCallback(ctx context.Context, code string, stateData map[s
 		return fmt.Errorf("failed to store credentials: %w", err)
 	}
 
-	log.Printf("✓ Stored cluster-level Google OAuth credentials for user %s", userID)
+	logging.Infof(ctx, "✓ Stored cluster-level Google OAuth credentials for user %s", userID)
 	return nil
 }
 
@@ -1064,6 +1066,24 @@ func GetGoogleCredentials(ctx context.Context, userID string) (*GoogleOAuthCrede
 	return &creds, nil
 }
 
+// GetFreshGoogleCredentials returns userID's Google credentials with an access token
+// valid for at least five more minutes, refreshing it when needed. It returns nil
+// when the user hasn't connected Google.
+func GetFreshGoogleCredentials(ctx context.Context, userID string) (*GoogleOAuthCredentials, error) {
+	creds, err := GetGoogleCredentials(ctx, userID)
+	if err != nil || creds == nil || creds.AccessToken == "" {
+		return nil, err
+	}
+	if time.Now().After(creds.ExpiresAt.Add(-5*time.Minute)) && creds.RefreshToken != "" {
+		newCreds, err := refreshGoogleAccessToken(ctx, creds)
+		if err != nil {
+			return nil, fmt.Errorf("google token refresh failed: %w", err)
+		}
+		creds = newCreds
+	}
+	return creds, nil
+}
+
 // GetGoogleOAuthStatusGlobal handles GET /api/auth/google/status
 // Returns connection status for current user
 func GetGoogleOAuthStatusGlobal(c *gin.Context) {
@@ -1086,7 +1106,7 @@ func GetGoogleOAuthStatusGlobal(c *gin.Context) {
 
 	creds, err := GetGoogleCredentials(c.Request.Context(), userID)
 	if err != nil {
-		log.Printf("Failed to get Google credentials for user %s: %v", userID, err)
+		logging.Errorf(c, "Failed to get Google credentials for user %s: %v", userID, err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check connection status"})
 		return
 	}
@@ -1129,26 +1149,36 @@ func DisconnectGoogleOAuthGlobal(c *gin.Context) {
 		return
 	}
 
+	if err := DeleteGoogleCredentials(c.Request.Context(), userID); err != nil {
+		logging.Errorf(c, "Failed to remove Google OAuth credentials for user %s: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to disconnect"})
+		return
+	}
+
+	logging.Infof(c, "✓ Removed Google OAuth credentials for user %s", userID)
+	c.JSON(http.StatusOK, gin.H{"message": "Google Drive disconnected successfully"})
+}
+
+// DeleteGoogleCredentials removes a user's Google OAuth credentials
+func DeleteGoogleCredentials(ctx context.Context, userID string) error {
+	if userID == "" {
+		return fmt.Errorf("userID is required")
+	}
+
 	const secretName = "google-oauth-credentials"
 	secretKey := sanitizeSecretKey(userID)
-	ctx := c.Request.Context()
 
 	for i := 0; i < 3; i++ { // retry on conflict
 		secret, err := K8sClient.CoreV1().Secrets(Namespace).Get(ctx, secretName, v1.GetOptions{})
 		if err != nil {
 			if errors.IsNotFound(err) {
-				// Already disconnected
-				c.JSON(http.StatusOK, gin.H{"message": "Google Drive disconnected"})
-				return
+				return nil // Already disconnected
 			}
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to access credentials"})
-			return
+			return fmt.Errorf("failed to get Secret: %w", err)
 		}
 
 		if secret.Data == nil || len(secret.Data[secretKey]) == 0 {
-			// Already disconnected
-			c.JSON(http.StatusOK, gin.H{"message": "Google Drive disconnected"})
-			return
+			return nil // Already disconnected
 		}
 
 		delete(secret.Data, secretKey)
@@ -1157,17 +1187,11 @@ func DisconnectGoogleOAuthGlobal(c *gin.Context) {
 			if errors.IsConflict(uerr) {
 				continue // retry
 			}
-			log.Printf("Failed to update Secret: %v", uerr)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to disconnect"})
-			return
+			return fmt.Errorf("failed to update Secret: %w", uerr)
 		}
-
-		log.Printf("✓ Removed Google OAuth credentials for user %s", userID)
-		c.JSON(http.StatusOK, gin.H{"message": "Google Drive disconnected successfully"})
-		return
+		return nil
 	}
-
-	c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to disconnect after retries"})
+	return fmt.Errorf("failed to update Secret after retries")
 }
 
 // getGoogleUserEmail fetches the user's email from Google using the access token
@@ -1180,7 +1204,7 @@ func getGoogleUserEmail(ctx context.Context, accessToken string) (string, error)
 	req.Header.Set("Authorization", "Bearer "+accessToken)
 
 	// Use client with timeout instead of DefaultClient
-	client := &http.Client{Timeout: 10 * time.Second}
+	client := &http.Client{Timeout: 10 * time.Second, Transport: metrics.Transport("google", "userinfo", nil)}
 	resp, err := client.Do(req)
 	if err != nil {
 		return "", err
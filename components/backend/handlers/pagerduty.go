@@ -0,0 +1,352 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"ambient-code-backend/logging"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// pagerDutyIncidentLabel marks sessions created for a PagerDuty incident; the value is the incident ID
+const pagerDutyIncidentLabel = "ambient-code.io/pagerduty-incident"
+
+// pagerDutyAPIBaseURL is overridable in tests.
+var pagerDutyAPIBaseURL = "https://api.pagerduty.com"
+
+// maxPagerDutyNoteLength stays under PagerDuty's 25,000 character limit for incident notes
+const maxPagerDutyNoteLength = 24000
+
+// RunFinalMessage returns the last assistant message of a run. Set from main to avoid
+// an import cycle with the websocket package; nil reports no message.
+var RunFinalMessage func(sessionName, runID string) string
+
+// incidentTrigger is one entry of ProjectSettings spec.incidentTriggers
+type incidentTrigger struct {
+	Project   string
+	ServiceID string
+	RepoURL   string
+	Urgencies []string
+	Prompt    string
+}
+
+// pagerDutyIncident is the part of a PagerDuty V3 incident webhook sessions need
+type pagerDutyIncident struct {
+	ID        string `json:"id"`
+	Number    int    `json:"number"`
+	Title     string `json:"title"`
+	HTMLURL   string `json:"html_url"`
+	Urgency   string `json:"urgency"`
+	CreatedAt string `json:"created_at"`
+	Service   struct {
+		ID      string `json:"id"`
+		Summary string `json:"summary"`
+	} `json:"service"`
+	Priority *struct {
+		Summary string `json:"summary"`
+	} `json:"priority"`
+}
+
+// HandlePagerDutyWebhook receives PagerDuty V3 webhook subscriptions and creates a triage
+// session for each newly triggered incident on a service mapped to a repository
+// POST /api/webhooks/pagerduty
+func HandlePagerDutyWebhook(c *gin.Context) {
+	secret := os.Getenv("PAGERDUTY_WEBHOOK_SECRET")
+	if secret == "" {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "PagerDuty webhooks are not configured"})
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxWebhookPayloadBytes))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read payload"})
+		return
+	}
+	if !verifyPagerDutySignature(secret, body, c.GetHeader("X-PagerDuty-Signature")) {
+		logging.Errorf(c, "PagerDuty webhook: invalid signature (webhook=%s)", c.GetHeader("X-Webhook-Id"))
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid signature"})
+		return
+	}
+
+	incident, ok, err := parsePagerDutyWebhook(body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !ok {
+		c.JSON(http.StatusOK, gin.H{"message": "event ignored"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	triggers, err := listIncidentTriggers(ctx)
+	if err != nil {
+		logging.Errorf(c, "PagerDuty webhook: failed to list triggers: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load incident trigger configuration"})
+		return
+	}
+
+	created := []string{}
+	for _, t := range triggers {
+		if !incidentTriggerMatches(t, incident) {
+			continue
+		}
+		name, err := createIncidentSession(ctx, t, incident)
+		if err != nil {
+			logging.Errorf(c, "PagerDuty webhook: failed to create session in %s for incident %s: %v", t.Project, incident.ID, err)
+			continue
+		}
+		if name == "" {
+			continue // retried delivery; a session already exists
+		}
+		logging.Infof(c, "PagerDuty webhook: created session %s/%s for incident %s on %s", t.Project, name, incident.ID, t.RepoURL)
+		created = append(created, t.Project+"/"+name)
+	}
+
+	if len(created) == 0 {
+		c.JSON(http.StatusOK, gin.H{"message": "no matching triggers"})
+		return
+	}
+	c.JSON(http.StatusAccepted, gin.H{"sessions": created})
+}
+
+// verifyPagerDutySignature checks the X-PagerDuty-Signature header, which lists one
+// "v1=<hex HMAC-SHA256>" entry per active signing secret
+func verifyPagerDutySignature(secret string, body []byte, header string) bool {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	want := mac.Sum(nil)
+	for _, entry := range strings.Split(header, ",") {
+		sig, found := strings.CutPrefix(strings.TrimSpace(entry), "v1=")
+		if !found {
+			continue
+		}
+		got, err := hex.DecodeString(sig)
+		if err == nil && hmac.Equal(got, want) {
+			return true
+		}
+	}
+	return false
+}
+
+// parsePagerDutyWebhook returns the incident of an incident.triggered event.
+// Other events (acknowledgements, resolutions, pings) are ignored.
+func parsePagerDutyWebhook(body []byte) (pagerDutyIncident, bool, error) {
+	var p struct {
+		Event struct {
+			EventType    string            `json:"event_type"`
+			ResourceType string            `json:"resource_type"`
+			Data         pagerDutyIncident `json:"data"`
+		} `json:"event"`
+	}
+	if err := json.Unmarshal(body, &p); err != nil {
+		return pagerDutyIncident{}, false, fmt.Errorf("invalid PagerDuty payload")
+	}
+	if p.Event.EventType != "incident.triggered" || p.Event.ResourceType != "incident" {
+		return pagerDutyIncident{}, false, nil
+	}
+	if p.Event.Data.ID == "" || p.Event.Data.Service.ID == "" {
+		return pagerDutyIncident{}, false, fmt.Errorf("incident payload is missing its id or service")
+	}
+	return p.Event.Data, true, nil
+}
+
+// incidentTriggerMatches reports whether a configured trigger fires for the incident
+func incidentTriggerMatches(t incidentTrigger, incident pagerDutyIncident) bool {
+	if t.ServiceID == "" || t.RepoURL == "" || t.ServiceID != incident.Service.ID {
+		return false
+	}
+	if len(t.Urgencies) == 0 {
+		return true
+	}
+	for _, u := range t.Urgencies {
+		if strings.EqualFold(u, incident.Urgency) {
+			return true
+		}
+	}
+	return false
+}
+
+// listIncidentTriggers reads spec.incidentTriggers from every project's ProjectSettings.
+// Uses the backend service account: webhook requests carry no user identity.
+func listIncidentTriggers(ctx context.Context) ([]incidentTrigger, error) {
+	if DynamicClient == nil {
+		return nil, fmt.Errorf("dynamic client not initialized")
+	}
+	list, err := DynamicClient.Resource(GetProjectSettingsResource()).List(ctx, v1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	triggers := []incidentTrigger{}
+	for _, item := range list.Items {
+		entries, _, _ := unstructured.NestedSlice(item.Object, "spec", "incidentTriggers")
+		for _, entry := range entries {
+			m, ok := entry.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			t := incidentTrigger{Project: item.GetNamespace()}
+			t.ServiceID, _ = m["serviceId"].(string)
+			t.RepoURL, _ = m["repoUrl"].(string)
+			t.Prompt, _ = m["prompt"].(string)
+			if urgencies, ok := m["urgencies"].([]interface{}); ok {
+				for _, u := range urgencies {
+					if s, ok := u.(string); ok && s != "" {
+						t.Urgencies = append(t.Urgencies, s)
+					}
+				}
+			}
+			triggers = append(triggers, t)
+		}
+	}
+	return triggers, nil
+}
+
+// createIncidentSession creates a triage session for the incident unless the project
+// already has one; PagerDuty retries deliveries it considers failed. Returns "" when
+// a session already existed.
+func createIncidentSession(ctx context.Context, t incidentTrigger, incident pagerDutyIncident) (string, error) {
+	existing, err := DynamicClient.Resource(GetAgenticSessionV1Alpha1Resource()).Namespace(t.Project).List(ctx, v1.ListOptions{
+		LabelSelector: pagerDutyIncidentLabel + "=" + incident.ID,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to check for an existing session: %w", err)
+	}
+	if len(existing.Items) > 0 {
+		return "", nil
+	}
+
+	name := fmt.Sprintf("incident-%d-%s", time.Now().Unix(), uuid.New().String()[:8])
+	// Triage only: the agent investigates on its own branch and never pushes
+	repo := map[string]interface{}{"url": t.RepoURL, "branch": ComputeAutoBranch(name), "autoPush": false}
+	displayName := fmt.Sprintf("PagerDuty #%d: %s", incident.Number, truncateWebhookText(incident.Title, 60))
+	labels := map[string]interface{}{
+		"ambient-code.io/trigger": "pagerduty-incident",
+		pagerDutyIncidentLabel:    incident.ID,
+	}
+	annotations := map[string]interface{}{"ambient-code.io/trigger-url": incident.HTMLURL}
+	if err := createTriggeredSession(ctx, t.Project, name, displayName, buildIncidentPrompt(t, incident), repo, labels, annotations); err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+func buildIncidentPrompt(t incidentTrigger, incident pagerDutyIncident) string {
+	var b strings.Builder
+	if t.Prompt != "" {
+		b.WriteString(t.Prompt)
+		b.WriteString("\n\n")
+	}
+	fmt.Fprintf(&b, "PagerDuty incident #%d was triggered on service %s.\n", incident.Number, incident.Service.Summary)
+	fmt.Fprintf(&b, "Title: %s\n", incident.Title)
+	fmt.Fprintf(&b, "Urgency: %s\n", incident.Urgency)
+	if incident.Priority != nil && incident.Priority.Summary != "" {
+		fmt.Fprintf(&b, "Priority: %s\n", incident.Priority.Summary)
+	}
+	if incident.CreatedAt != "" {
+		fmt.Fprintf(&b, "Triggered at: %s\n", incident.CreatedAt)
+	}
+	fmt.Fprintf(&b, "Incident: %s\n\n", incident.HTMLURL)
+	b.WriteString("Investigate the repository for the likely cause. Do not push changes. Finish with a concise " +
+		"triage report for the on-call engineer: suspected cause, affected components, supporting evidence " +
+		"(files, commits), and recommended next steps. Your final message is attached to the incident as a note.\n")
+	return b.String()
+}
+
+// ReportIncidentNote attaches a finished run's final message to the PagerDuty incident
+// that triggered the session. Requires PAGERDUTY_API_TOKEN and PAGERDUTY_FROM_EMAIL.
+// Best effort; intended to be called in a goroutine.
+func ReportIncidentNote(project, sessionName, runID, runStatus string) {
+	if DynamicClient == nil || (runStatus != "completed" && runStatus != "error") {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	obj, err := GetCachedSession(ctx, project, sessionName)
+	if err != nil {
+		return
+	}
+	incidentID := obj.GetLabels()[pagerDutyIncidentLabel]
+	if incidentID == "" {
+		return
+	}
+	token, from := os.Getenv("PAGERDUTY_API_TOKEN"), os.Getenv("PAGERDUTY_FROM_EMAIL")
+	if token == "" || from == "" {
+		logging.Warnf(ctx, "ReportIncidentNote: PAGERDUTY_API_TOKEN/PAGERDUTY_FROM_EMAIL not set, not reporting %s/%s to incident %s", project, sessionName, incidentID)
+		return
+	}
+
+	var report string
+	if runStatus == "completed" && RunFinalMessage != nil {
+		report = RunFinalMessage(sessionName, runID)
+	}
+	if err := postPagerDutyNote(ctx, token, from, incidentID, incidentNote(report, runStatus, sessionTranscriptURL(project, sessionName))); err != nil {
+		logging.Infof(ctx, "ReportIncidentNote: %s/%s -> incident %s: %v", project, sessionName, incidentID, err)
+	}
+}
+
+// incidentNote formats the note body: the agent's report, or a failure notice, plus a link to the session
+func incidentNote(report, runStatus, transcriptURL string) string {
+	var b strings.Builder
+	switch {
+	case runStatus == "error":
+		b.WriteString("Agent triage run failed before producing a report.")
+	case strings.TrimSpace(report) == "":
+		b.WriteString("Agent triage run finished without a report.")
+	default:
+		b.WriteString("Agent triage report:\n\n")
+		b.WriteString(strings.TrimSpace(report))
+	}
+	if transcriptURL != "" {
+		fmt.Fprintf(&b, "\n\nSession: %s", transcriptURL)
+	}
+	note := b.String()
+	if len(note) > maxPagerDutyNoteLength {
+		note = note[:maxPagerDutyNoteLength] + "\n\n[truncated]"
+	}
+	return note
+}
+
+// postPagerDutyNote adds a note to an incident through the REST API
+func postPagerDutyNote(ctx context.Context, token, from, incidentID, content string) error {
+	payload, err := json.Marshal(map[string]interface{}{"note": map[string]string{"content": content}})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf("%s/incidents/%s/notes", pagerDutyAPIBaseURL, incidentID), bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Token token="+token)
+	req.Header.Set("From", from)
+	req.Header.Set("Accept", "application/vnd.pagerduty+json;version=2")
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := (&http.Client{Timeout: 15 * time.Second}).Do(req)
+	if err != nil {
+		// Don't wrap error - could leak token from request details
+		return fmt.Errorf("request failed")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("PagerDuty returned %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
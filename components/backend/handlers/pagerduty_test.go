@@ -0,0 +1,119 @@
+//go:build test
+
+package handlers
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	test_constants "ambient-code-backend/tests/constants"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PagerDuty Webhooks", Label(test_constants.LabelUnit, test_constants.LabelHandlers), func() {
+	triggered := []byte(`{"event":{"event_type":"incident.triggered","resource_type":"incident","data":{
+		"id":"Q1ABC","number":42,"title":"Checkout latency above SLO","html_url":"https://acme.pagerduty.com/incidents/Q1ABC",
+		"urgency":"high","service":{"id":"PSVC1","summary":"checkout-api"},"priority":{"summary":"P1"}}}}`)
+
+	Describe("verifyPagerDutySignature", func() {
+		sign := func(secret string) string {
+			mac := hmac.New(sha256.New, []byte(secret))
+			mac.Write(triggered)
+			return "v1=" + hex.EncodeToString(mac.Sum(nil))
+		}
+
+		It("Should accept any matching signature in the header", func() {
+			Expect(verifyPagerDutySignature("s3cret", triggered, sign("s3cret"))).To(BeTrue())
+			// During secret rotation PagerDuty sends one signature per secret
+			Expect(verifyPagerDutySignature("s3cret", triggered, sign("old")+","+sign("s3cret"))).To(BeTrue())
+		})
+
+		It("Should reject wrong, missing or malformed signatures", func() {
+			Expect(verifyPagerDutySignature("s3cret", triggered, sign("other"))).To(BeFalse())
+			Expect(verifyPagerDutySignature("s3cret", triggered, "")).To(BeFalse())
+			Expect(verifyPagerDutySignature("s3cret", triggered, "v1=zz")).To(BeFalse())
+		})
+	})
+
+	Describe("parsePagerDutyWebhook", func() {
+		It("Should parse triggered incidents", func() {
+			incident, ok, err := parsePagerDutyWebhook(triggered)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ok).To(BeTrue())
+			Expect(incident.Number).To(Equal(42))
+			Expect(incident.Service.ID).To(Equal("PSVC1"))
+
+			prompt := buildIncidentPrompt(incidentTrigger{Prompt: "Check recent deploys."}, incident)
+			Expect(prompt).To(HavePrefix("Check recent deploys."))
+			Expect(prompt).To(ContainSubstring("Priority: P1"))
+			Expect(prompt).To(ContainSubstring("https://acme.pagerduty.com/incidents/Q1ABC"))
+		})
+
+		It("Should ignore other incident events", func() {
+			_, ok, err := parsePagerDutyWebhook([]byte(`{"event":{"event_type":"incident.resolved","resource_type":"incident","data":{"id":"Q1ABC"}}}`))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ok).To(BeFalse())
+		})
+	})
+
+	Describe("incidentTriggerMatches", func() {
+		incident := pagerDutyIncident{Urgency: "low"}
+		incident.Service.ID = "PSVC1"
+
+		It("Should match on service and optional urgency", func() {
+			Expect(incidentTriggerMatches(incidentTrigger{ServiceID: "PSVC1", RepoURL: "https://github.com/org/app"}, incident)).To(BeTrue())
+			Expect(incidentTriggerMatches(incidentTrigger{ServiceID: "PSVC1", RepoURL: "https://github.com/org/app", Urgencies: []string{"high"}}, incident)).To(BeFalse())
+			Expect(incidentTriggerMatches(incidentTrigger{ServiceID: "PSVC2", RepoURL: "https://github.com/org/app"}, incident)).To(BeFalse())
+		})
+	})
+
+	Describe("postPagerDutyNote", func() {
+		var originalBaseURL string
+
+		BeforeEach(func() {
+			originalBaseURL = pagerDutyAPIBaseURL
+		})
+
+		AfterEach(func() {
+			pagerDutyAPIBaseURL = originalBaseURL
+		})
+
+		It("Should post the note with the API token and From header", func() {
+			var gotPath, gotAuth, gotFrom, gotContent string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotPath, gotAuth, gotFrom = r.URL.Path, r.Header.Get("Authorization"), r.Header.Get("From")
+				var body struct {
+					Note struct {
+						Content string `json:"content"`
+					} `json:"note"`
+				}
+				_ = json.NewDecoder(r.Body).Decode(&body)
+				gotContent = body.Note.Content
+				w.WriteHeader(http.StatusCreated)
+			}))
+			defer srv.Close()
+			pagerDutyAPIBaseURL = srv.URL
+
+			note := incidentNote("Likely cause: connection pool exhaustion.", "completed", "https://ui/projects/p/sessions/s")
+			Expect(postPagerDutyNote(context.Background(), "tok", "oncall@example.com", "Q1ABC", note)).To(Succeed())
+			Expect(gotPath).To(Equal("/incidents/Q1ABC/notes"))
+			Expect(gotAuth).To(Equal("Token token=tok"))
+			Expect(gotFrom).To(Equal("oncall@example.com"))
+			Expect(gotContent).To(ContainSubstring("connection pool exhaustion"))
+			Expect(gotContent).To(HaveSuffix("Session: https://ui/projects/p/sessions/s"))
+		})
+
+		It("Should truncate long reports and describe failed runs", func() {
+			Expect(len(incidentNote(strings.Repeat("x", 30000), "completed", ""))).To(BeNumerically("<", 25000))
+			Expect(incidentNote("", "error", "")).To(ContainSubstring("failed"))
+		})
+	})
+})
@@ -3,11 +3,12 @@ package handlers
 import (
 	"context"
 	"fmt"
-	"log"
 	"net/http"
 	"strings"
 	"time"
 
+	"ambient-code-backend/logging"
+
 	"github.com/gin-gonic/gin"
 	authnv1 "k8s.io/api/authentication/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -21,6 +22,10 @@ const (
 	AmbientRoleAdmin = "ambient-project-admin"
 	AmbientRoleEdit  = "ambient-project-edit"
 	AmbientRoleView  = "ambient-project-view"
+	// AmbientRoleRun can create sessions and trigger runs but not read transcripts
+	AmbientRoleRun = "ambient-project-run"
+	// AmbientRoleObserver can watch sessions' live events but not act on them
+	AmbientRoleObserver = "ambient-project-observer"
 )
 
 // sanitizeName converts input to a Kubernetes-safe name (lowercase alphanumeric with dashes, max 63 chars)
@@ -57,6 +62,96 @@ type PermissionAssignment struct {
 	Role        string `json:"role"`
 }
 
+// permissionRoleRefs maps permission roles to the Ambient ClusterRoles they bind
+var permissionRoleRefs = map[string]string{
+	"admin":    AmbientRoleAdmin,
+	"edit":     AmbientRoleEdit,
+	"run":      AmbientRoleRun,
+	"view":     AmbientRoleView,
+	"observer": AmbientRoleObserver,
+}
+
+// isPermissionRoleBinding reports whether rb grants project membership: bindings made
+// through this API, legacy group-access bindings, and the creator's admin binding
+func isPermissionRoleBinding(rb *rbacv1.RoleBinding) bool {
+	switch rb.Labels["app"] {
+	case "ambient-permission", "ambient-group-access":
+		return true
+	case "":
+		return rb.Labels["ambient-code.io/role"] != ""
+	}
+	return false
+}
+
+// permissionBindingRole returns the admin/edit/view role granted by rb, or ""
+func permissionBindingRole(rb *rbacv1.RoleBinding) string {
+	if annRole := rb.Annotations["ambient-code.io/role"]; annRole != "" {
+		return strings.ToLower(annRole)
+	}
+	if rb.RoleRef.Kind == "ClusterRole" {
+		for role, ref := range permissionRoleRefs {
+			if rb.RoleRef.Name == ref {
+				return role
+			}
+		}
+	}
+	return strings.ToLower(rb.Labels["ambient-code.io/role"])
+}
+
+// bindingHasSubject reports whether rb binds the given user or group
+func bindingHasSubject(rb *rbacv1.RoleBinding, subjectType, subjectName string) bool {
+	for _, sub := range rb.Subjects {
+		if strings.EqualFold(sub.Kind, subjectType) && sub.Name == subjectName {
+			return true
+		}
+	}
+	return false
+}
+
+// otherAdminExists reports whether a user or group other than subjectType/subjectName
+// keeps admin access, so members cannot lock everyone out of a project
+func otherAdminExists(bindings []rbacv1.RoleBinding, subjectType, subjectName string) bool {
+	for i := range bindings {
+		rb := &bindings[i]
+		if !isPermissionRoleBinding(rb) || permissionBindingRole(rb) != "admin" {
+			continue
+		}
+		for _, sub := range rb.Subjects {
+			if !strings.EqualFold(sub.Kind, "User") && !strings.EqualFold(sub.Kind, "Group") {
+				continue
+			}
+			if !strings.EqualFold(sub.Kind, subjectType) || sub.Name != subjectName {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// newPermissionRoleBinding builds the RoleBinding granting role to a user or group
+func newPermissionRoleBinding(projectName, subjectType, subjectName, role string) *rbacv1.RoleBinding {
+	subjectKind := "Group"
+	if subjectType == "user" {
+		subjectKind = "User"
+	}
+	return &rbacv1.RoleBinding{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      "ambient-permission-" + role + "-" + sanitizeName(subjectName) + "-" + subjectType,
+			Namespace: projectName,
+			Labels: map[string]string{
+				"app": "ambient-permission",
+			},
+			Annotations: map[string]string{
+				"ambient-code.io/subject-kind": subjectKind,
+				"ambient-code.io/subject-name": subjectName,
+				"ambient-code.io/role":         role,
+			},
+		},
+		RoleRef:  rbacv1.RoleRef{APIGroup: "rbac.authorization.k8s.io", Kind: "ClusterRole", Name: permissionRoleRefs[role]},
+		Subjects: []rbacv1.Subject{{Kind: subjectKind, APIGroup: "rbac.authorization.k8s.io", Name: subjectName}},
+	}
+}
+
 // ListProjectPermissions handles GET /api/projects/:projectName/permissions
 func ListProjectPermissions(c *gin.Context) {
 	projectName := c.Param("projectName")
@@ -75,35 +170,22 @@ func ListProjectPermissions(c *gin.Context) {
 	// Prefer new label, but also include legacy group-access for backward-compat listing
 	rbsAll, err := k8sClient.RbacV1().RoleBindings(projectName).List(context.TODO(), v1.ListOptions{})
 	if err != nil {
-		log.Printf("Failed to list RoleBindings in %s: %v", projectName, err)
+		logging.Errorf(c, "Failed to list RoleBindings in %s: %v", projectName, err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list permissions"})
 		return
 	}
 
-	validRoles := map[string]string{
-		AmbientRoleAdmin: "admin",
-		AmbientRoleEdit:  "edit",
-		AmbientRoleView:  "view",
-	}
-
 	type key struct{ kind, name, role string }
 	seen := map[key]struct{}{}
 	assignments := []PermissionAssignment{}
 
-	for _, rb := range rbsAll.Items {
+	for i := range rbsAll.Items {
+		rb := &rbsAll.Items[i]
 		// Filter to Ambient-managed permission rolebindings
-		if rb.Labels["app"] != "ambient-permission" && rb.Labels["app"] != "ambient-group-access" {
+		if !isPermissionRoleBinding(rb) {
 			continue
 		}
-
-		// Determine role from RoleRef or annotation
-		role := ""
-		if r, ok := validRoles[rb.RoleRef.Name]; ok && rb.RoleRef.Kind == "ClusterRole" {
-			role = r
-		}
-		if annRole := rb.Annotations["ambient-code.io/role"]; annRole != "" {
-			role = strings.ToLower(annRole)
-		}
+		role := permissionBindingRole(rb)
 		if role == "" {
 			continue
 		}
@@ -168,42 +250,13 @@ func AddProjectPermission(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "subjectType must be one of: group, user"})
 		return
 	}
-	subjectKind := "Group"
-	if st == "user" {
-		subjectKind = "User"
-	}
-
-	roleRefName := ""
-	switch strings.ToLower(req.Role) {
-	case "admin":
-		roleRefName = AmbientRoleAdmin
-	case "edit":
-		roleRefName = AmbientRoleEdit
-	case "view":
-		roleRefName = AmbientRoleView
-	default:
-		c.JSON(http.StatusBadRequest, gin.H{"error": "role must be one of: admin, edit, view"})
+	role := strings.ToLower(req.Role)
+	if _, ok := permissionRoleRefs[role]; !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "role must be one of: admin, edit, run, view, observer"})
 		return
 	}
 
-	rbName := "ambient-permission-" + strings.ToLower(req.Role) + "-" + sanitizeName(req.SubjectName) + "-" + st
-	rb := &rbacv1.RoleBinding{
-		ObjectMeta: v1.ObjectMeta{
-			Name:      rbName,
-			Namespace: projectName,
-			Labels: map[string]string{
-				"app": "ambient-permission",
-			},
-			Annotations: map[string]string{
-				"ambient-code.io/subject-kind": subjectKind,
-				"ambient-code.io/subject-name": req.SubjectName,
-				"ambient-code.io/role":         strings.ToLower(req.Role),
-			},
-		},
-		RoleRef:  rbacv1.RoleRef{APIGroup: "rbac.authorization.k8s.io", Kind: "ClusterRole", Name: roleRefName},
-		Subjects: []rbacv1.Subject{{Kind: subjectKind, APIGroup: "rbac.authorization.k8s.io", Name: req.SubjectName}},
-	}
-
+	rb := newPermissionRoleBinding(projectName, st, req.SubjectName, role)
 	if _, err := k8sClient.RbacV1().RoleBindings(projectName).Create(context.TODO(), rb, v1.CreateOptions{}); err != nil {
 		if errors.IsAlreadyExists(err) {
 			c.JSON(http.StatusConflict, gin.H{"error": "permission already exists for this subject and role"})
@@ -213,7 +266,7 @@ func AddProjectPermission(c *gin.Context) {
 			c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions to grant permission"})
 			return
 		}
-		log.Printf("Failed to create RoleBinding in %s for %s %s: %v", projectName, st, req.SubjectName, err)
+		logging.Errorf(c, "Failed to create RoleBinding in %s for %s %s: %v", projectName, st, req.SubjectName, err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to grant permission"})
 		return
 	}
@@ -247,29 +300,134 @@ func RemoveProjectPermission(c *gin.Context) {
 		return
 	}
 
-	rbs, err := k8sClient.RbacV1().RoleBindings(projectName).List(context.TODO(), v1.ListOptions{LabelSelector: "app=ambient-permission"})
+	rbs, err := k8sClient.RbacV1().RoleBindings(projectName).List(context.TODO(), v1.ListOptions{})
 	if err != nil {
-		log.Printf("Failed to list RoleBindings in %s: %v", projectName, err)
+		logging.Errorf(c, "Failed to list RoleBindings in %s: %v", projectName, err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove permission"})
 		return
 	}
 
-	for _, rb := range rbs.Items {
-		for _, sub := range rb.Subjects {
-			if strings.EqualFold(sub.Kind, "Group") && subjectType == "group" && sub.Name == subjectName {
-				_ = k8sClient.RbacV1().RoleBindings(projectName).Delete(context.TODO(), rb.Name, v1.DeleteOptions{})
-				break
-			}
-			if strings.EqualFold(sub.Kind, "User") && subjectType == "user" && sub.Name == subjectName {
-				_ = k8sClient.RbacV1().RoleBindings(projectName).Delete(context.TODO(), rb.Name, v1.DeleteOptions{})
-				break
+	var matched []*rbacv1.RoleBinding
+	removesAdmin := false
+	for i := range rbs.Items {
+		rb := &rbs.Items[i]
+		if isPermissionRoleBinding(rb) && bindingHasSubject(rb, subjectType, subjectName) {
+			matched = append(matched, rb)
+			removesAdmin = removesAdmin || permissionBindingRole(rb) == "admin"
+		}
+	}
+	if removesAdmin && !otherAdminExists(rbs.Items, subjectType, subjectName) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Cannot remove the last project admin"})
+		return
+	}
+
+	for _, rb := range matched {
+		if err := k8sClient.RbacV1().RoleBindings(projectName).Delete(context.TODO(), rb.Name, v1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+			if errors.IsForbidden(err) {
+				c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions to remove permission"})
+				return
 			}
+			logging.Errorf(c, "Failed to delete RoleBinding %s in %s: %v", rb.Name, projectName, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove permission"})
+			return
 		}
 	}
 
 	c.JSON(http.StatusNoContent, nil)
 }
 
+// UpdateProjectPermission handles PUT /api/projects/:projectName/permissions/:subjectType/:subjectName
+// Changes the role of an existing user or group: the new binding is created before the
+// old ones are deleted, so the subject never loses access mid-change.
+func UpdateProjectPermission(c *gin.Context) {
+	projectName := c.Param("projectName")
+	subjectType := strings.ToLower(c.Param("subjectType"))
+	subjectName := c.Param("subjectName")
+
+	reqK8s, _ := GetK8sClientsForRequest(c)
+	k8sClient := reqK8s
+	if k8sClient == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing token"})
+		return
+	}
+
+	if subjectType != "group" && subjectType != "user" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "subjectType must be one of: group, user"})
+		return
+	}
+	if !isValidRBACSubject(subjectName) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid subject name format"})
+		return
+	}
+
+	var req struct {
+		Role string `json:"role" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	role := strings.ToLower(strings.TrimSpace(req.Role))
+	if _, ok := permissionRoleRefs[role]; !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "role must be one of: admin, edit, run, view, observer"})
+		return
+	}
+
+	rbs, err := k8sClient.RbacV1().RoleBindings(projectName).List(context.TODO(), v1.ListOptions{})
+	if err != nil {
+		logging.Errorf(c, "Failed to list RoleBindings in %s: %v", projectName, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update permission"})
+		return
+	}
+
+	var stale []*rbacv1.RoleBinding
+	found, hasRole, demotesAdmin := false, false, false
+	for i := range rbs.Items {
+		rb := &rbs.Items[i]
+		if !isPermissionRoleBinding(rb) || !bindingHasSubject(rb, subjectType, subjectName) {
+			continue
+		}
+		found = true
+		current := permissionBindingRole(rb)
+		if current == role {
+			hasRole = true
+			continue
+		}
+		stale = append(stale, rb)
+		demotesAdmin = demotesAdmin || current == "admin"
+	}
+	if !found {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No permission found for this subject"})
+		return
+	}
+	if demotesAdmin && !otherAdminExists(rbs.Items, subjectType, subjectName) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Cannot demote the last project admin"})
+		return
+	}
+
+	if !hasRole {
+		rb := newPermissionRoleBinding(projectName, subjectType, subjectName, role)
+		if _, err := k8sClient.RbacV1().RoleBindings(projectName).Create(context.TODO(), rb, v1.CreateOptions{}); err != nil && !errors.IsAlreadyExists(err) {
+			if errors.IsForbidden(err) {
+				c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions to grant permission"})
+				return
+			}
+			logging.Errorf(c, "Failed to create RoleBinding in %s for %s %s: %v", projectName, subjectType, subjectName, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update permission"})
+			return
+		}
+	}
+	for _, rb := range stale {
+		if err := k8sClient.RbacV1().RoleBindings(projectName).Delete(context.TODO(), rb.Name, v1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+			logging.Errorf(c, "Failed to delete RoleBinding %s in %s: %v", rb.Name, projectName, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove previous role"})
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, PermissionAssignment{SubjectType: subjectType, SubjectName: subjectName, Role: role})
+}
+
 // ListProjectKeys handles GET /api/projects/:projectName/keys
 // Lists access keys (ServiceAccounts with label app=ambient-access-key)
 func ListProjectKeys(c *gin.Context) {
@@ -285,7 +443,7 @@ func ListProjectKeys(c *gin.Context) {
 	// List ServiceAccounts with label app=ambient-access-key
 	sas, err := k8sClient.CoreV1().ServiceAccounts(projectName).List(context.TODO(), v1.ListOptions{LabelSelector: "app=ambient-access-key"})
 	if err != nil {
-		log.Printf("Failed to list access keys in %s: %v", projectName, err)
+		logging.Errorf(c, "Failed to list access keys in %s: %v", projectName, err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list access keys"})
 		return
 	}
@@ -301,8 +459,12 @@ func ListProjectKeys(c *gin.Context) {
 					role = "admin"
 				case AmbientRoleEdit:
 					role = "edit"
+				case AmbientRoleRun:
+					role = "run"
 				case AmbientRoleView:
 					role = "view"
+				case AmbientRoleObserver:
+					role = "observer"
 				}
 			}
 			for _, sub := range rb.Subjects {
@@ -373,10 +535,14 @@ func CreateProjectKey(c *gin.Context) {
 		roleRefName = AmbientRoleAdmin
 	case "edit":
 		roleRefName = AmbientRoleEdit
+	case "run":
+		roleRefName = AmbientRoleRun
 	case "view":
 		roleRefName = AmbientRoleView
+	case "observer":
+		roleRefName = AmbientRoleObserver
 	default:
-		c.JSON(http.StatusBadRequest, gin.H{"error": "role must be one of: admin, edit, view"})
+		c.JSON(http.StatusBadRequest, gin.H{"error": "role must be one of: admin, edit, run, view, observer"})
 		return
 	}
 
@@ -397,7 +563,7 @@ func CreateProjectKey(c *gin.Context) {
 		},
 	}
 	if _, err := k8sClient.CoreV1().ServiceAccounts(projectName).Create(context.TODO(), sa, v1.CreateOptions{}); err != nil && !errors.IsAlreadyExists(err) {
-		log.Printf("Failed to create ServiceAccount %s in %s: %v", saName, projectName, err)
+		logging.Errorf(c, "Failed to create ServiceAccount %s in %s: %v", saName, projectName, err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create service account"})
 		return
 	}
@@ -419,7 +585,7 @@ func CreateProjectKey(c *gin.Context) {
 		Subjects: []rbacv1.Subject{{Kind: "ServiceAccount", Name: saName, Namespace: projectName}},
 	}
 	if _, err := k8sClient.RbacV1().RoleBindings(projectName).Create(context.TODO(), rb, v1.CreateOptions{}); err != nil && !errors.IsAlreadyExists(err) {
-		log.Printf("Failed to create RoleBinding %s in %s: %v", rbName, projectName, err)
+		logging.Errorf(c, "Failed to create RoleBinding %s in %s: %v", rbName, projectName, err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to bind service account"})
 		return
 	}
@@ -428,7 +594,7 @@ func CreateProjectKey(c *gin.Context) {
 	tr := &authnv1.TokenRequest{Spec: authnv1.TokenRequestSpec{}}
 	tok, err := k8sClient.CoreV1().ServiceAccounts(projectName).CreateToken(context.TODO(), saName, tr, v1.CreateOptions{})
 	if err != nil {
-		log.Printf("Failed to create token for SA %s/%s: %v", projectName, saName, err)
+		logging.Errorf(c, "Failed to create token for SA %s/%s: %v", projectName, saName, err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate access token"})
 		return
 	}
@@ -476,7 +642,7 @@ func DeleteProjectKey(c *gin.Context) {
 	// Delete the ServiceAccount itself
 	if err := k8sClient.CoreV1().ServiceAccounts(projectName).Delete(context.TODO(), keyID, v1.DeleteOptions{}); err != nil {
 		if !errors.IsNotFound(err) {
-			log.Printf("Failed to delete service account %s in %s: %v", keyID, projectName, err)
+			logging.Errorf(c, "Failed to delete service account %s in %s: %v", keyID, projectName, err)
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete access key"})
 			return
 		}
@@ -521,7 +521,7 @@ var _ = Describe("Permissions Handler", Ordered, Label(test_constants.LabelUnit,
 
 				httpUtils.AssertHTTPStatus(http.StatusBadRequest)
 				httpUtils.AssertJSONContains(map[string]interface{}{
-					"error": "role must be one of: admin, edit, view",
+					"error": "role must be one of: admin, edit, run, view, observer",
 				})
 			})
 
@@ -735,6 +735,105 @@ var _ = Describe("Permissions Handler", Ordered, Label(test_constants.LabelUnit,
 		})
 	})
 
+	Context("Project Role Changes", func() {
+		var createBinding func(name, kind, subject, role string)
+
+		BeforeEach(func() {
+			createBinding = func(name, kind, subject, role string) {
+				rb := &rbacv1.RoleBinding{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:        name,
+						Namespace:   "test-project",
+						Labels:      map[string]string{"app": "ambient-permission"},
+						Annotations: map[string]string{"ambient-code.io/role": role},
+					},
+					Subjects: []rbacv1.Subject{{Kind: kind, Name: subject, APIGroup: "rbac.authorization.k8s.io"}},
+					RoleRef:  rbacv1.RoleRef{Kind: "ClusterRole", Name: "ambient-project-" + role, APIGroup: "rbac.authorization.k8s.io"},
+				}
+				_, err := fakeClients.GetK8sClient().RbacV1().RoleBindings("test-project").Create(context.Background(), rb, metav1.CreateOptions{})
+				Expect(err).NotTo(HaveOccurred())
+			}
+		})
+
+		updateRole := func(subjectType, subjectName, role string) {
+			ginContext := httpUtils.CreateTestGinContext("PUT", "/api/projects/test-project/permissions/"+subjectType+"/"+subjectName, map[string]interface{}{"role": role})
+			ginContext.Params = gin.Params{
+				{Key: "projectName", Value: "test-project"},
+				{Key: "subjectType", Value: subjectType},
+				{Key: "subjectName", Value: subjectName},
+			}
+			httpUtils.SetAuthHeader("test-token")
+			UpdateProjectPermission(ginContext)
+		}
+
+		It("Should replace the subject's binding with the new role", func() {
+			createBinding("ambient-permission-admin-owner-user", "User", "owner", "admin")
+			createBinding("ambient-permission-view-dev-team-group", "Group", "dev-team", "view")
+
+			updateRole("group", "dev-team", "edit")
+
+			httpUtils.AssertHTTPStatus(http.StatusOK)
+			rbs, err := fakeClients.GetK8sClient().RbacV1().RoleBindings("test-project").List(context.Background(), metav1.ListOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			var names []string
+			for _, rb := range rbs.Items {
+				names = append(names, rb.Name)
+			}
+			Expect(names).To(ContainElement("ambient-permission-edit-dev-team-group"))
+			Expect(names).NotTo(ContainElement("ambient-permission-view-dev-team-group"))
+		})
+
+		It("Should return 404 for subjects without access", func() {
+			updateRole("user", "stranger", "edit")
+
+			httpUtils.AssertHTTPStatus(http.StatusNotFound)
+		})
+
+		It("Should not demote or remove the last admin", func() {
+			createBinding("ambient-permission-admin-owner-user", "User", "owner", "admin")
+
+			updateRole("user", "owner", "view")
+			httpUtils.AssertHTTPStatus(http.StatusConflict)
+
+			ginContext := httpUtils.CreateTestGinContext("DELETE", "/api/projects/test-project/permissions/user/owner", nil)
+			ginContext.Params = gin.Params{
+				{Key: "projectName", Value: "test-project"},
+				{Key: "subjectType", Value: "user"},
+				{Key: "subjectName", Value: "owner"},
+			}
+			httpUtils.SetAuthHeader("test-token")
+			RemoveProjectPermission(ginContext)
+			httpUtils.AssertHTTPStatus(http.StatusConflict)
+
+			_, err := fakeClients.GetK8sClient().RbacV1().RoleBindings("test-project").Get(context.Background(), "ambient-permission-admin-owner-user", metav1.GetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("Should list the project creator's admin binding", func() {
+			rb := &rbacv1.RoleBinding{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "ambient-admin-creator",
+					Namespace: "test-project",
+					Labels:    map[string]string{"ambient-code.io/role": "admin"},
+				},
+				Subjects: []rbacv1.Subject{{Kind: "User", Name: "creator", APIGroup: "rbac.authorization.k8s.io"}},
+				RoleRef:  rbacv1.RoleRef{Kind: "ClusterRole", Name: AmbientRoleAdmin, APIGroup: "rbac.authorization.k8s.io"},
+			}
+			_, err := fakeClients.GetK8sClient().RbacV1().RoleBindings("test-project").Create(context.Background(), rb, metav1.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+
+			ginContext := httpUtils.CreateTestGinContext("GET", "/api/projects/test-project/permissions", nil)
+			ginContext.Params = gin.Params{{Key: "projectName", Value: "test-project"}}
+			httpUtils.SetAuthHeader("test-token")
+			ListProjectPermissions(ginContext)
+
+			httpUtils.AssertHTTPStatus(http.StatusOK)
+			var response map[string][]PermissionAssignment
+			httpUtils.GetResponseJSON(&response)
+			Expect(response["items"]).To(ContainElement(PermissionAssignment{SubjectType: "user", SubjectName: "creator", Role: "admin"}))
+		})
+	})
+
 	Context("Input Validation", func() {
 		It("Should reject userNames with invalid characters", func() {
 			invalidUserNames := []string{
@@ -0,0 +1,326 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"ambient-code-backend/logging"
+	"ambient-code-backend/types"
+
+	"github.com/gin-gonic/gin"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// maxPersonaSystemPromptLength keeps the registry within ConfigMap size limits
+	maxPersonaSystemPromptLength = 32 * 1024
+	// maxPersonaListItems bounds allowedTools and mcpServers
+	maxPersonaListItems = 50
+)
+
+// errPersonaNotFound and errPersonaExists are returned by registry lookups and mutations
+var (
+	errPersonaNotFound = fmt.Errorf("persona not found")
+	errPersonaExists   = fmt.Errorf("persona already exists")
+)
+
+// validateAgentPersona checks a persona before it is stored
+func validateAgentPersona(p types.AgentPersona) error {
+	if !isValidKubernetesName(p.Name) {
+		return fmt.Errorf("name must be a lowercase DNS label (a-z, 0-9, '-')")
+	}
+	if len(p.SystemPrompt) > maxPersonaSystemPromptLength {
+		return fmt.Errorf("systemPrompt must be at most %d bytes", maxPersonaSystemPromptLength)
+	}
+	if len(p.AllowedTools) > maxPersonaListItems || len(p.MCPServers) > maxPersonaListItems {
+		return fmt.Errorf("allowedTools and mcpServers may have at most %d entries", maxPersonaListItems)
+	}
+	for _, tool := range p.AllowedTools {
+		if strings.TrimSpace(tool) == "" || strings.ContainsAny(tool, " \t\n") {
+			return fmt.Errorf("invalid tool name %q", tool)
+		}
+	}
+	for _, server := range p.MCPServers {
+		if !isValidKubernetesName(server) {
+			return fmt.Errorf("invalid MCP server name %q", server)
+		}
+	}
+	if strings.TrimSpace(p.Model) != p.Model {
+		return fmt.Errorf("model must not have surrounding whitespace")
+	}
+	return nil
+}
+
+// LoadAgentPersona returns a persona of the project's registry
+func LoadAgentPersona(ctx context.Context, k8s kubernetes.Interface, project, name string) (types.AgentPersona, error) {
+	personas, err := LoadProjectPersonas(ctx, k8s, project)
+	if err != nil {
+		return types.AgentPersona{}, err
+	}
+	for _, p := range personas {
+		if p.Name == name {
+			return p, nil
+		}
+	}
+	return types.AgentPersona{}, errPersonaNotFound
+}
+
+// IsPersonaNotFound reports whether err means the persona is not in the registry
+func IsPersonaNotFound(err error) bool {
+	return err == errPersonaNotFound
+}
+
+// LoadProjectPersonas reads the project's persona registry
+func LoadProjectPersonas(ctx context.Context, k8s kubernetes.Interface, project string) ([]types.AgentPersona, error) {
+	cm, err := k8s.CoreV1().ConfigMaps(project).Get(ctx, types.ProjectPersonasConfigMap, v1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return []types.AgentPersona{}, nil
+		}
+		return nil, err
+	}
+	return parseProjectPersonas(cm)
+}
+
+func parseProjectPersonas(cm *corev1.ConfigMap) ([]types.AgentPersona, error) {
+	personas := []types.AgentPersona{}
+	raw := cm.Data[types.ProjectPersonasKey]
+	if strings.TrimSpace(raw) == "" {
+		return personas, nil
+	}
+	if err := json.Unmarshal([]byte(raw), &personas); err != nil {
+		return nil, fmt.Errorf("invalid persona registry: %w", err)
+	}
+	return personas, nil
+}
+
+// updateProjectPersonas applies mutate to the registry and persists the result,
+// retrying on update conflicts
+func updateProjectPersonas(ctx context.Context, k8s kubernetes.Interface, project string, mutate func([]types.AgentPersona) ([]types.AgentPersona, error)) error {
+	cms := k8s.CoreV1().ConfigMaps(project)
+	for i := 0; i < 3; i++ {
+		cm, err := cms.Get(ctx, types.ProjectPersonasConfigMap, v1.GetOptions{})
+		notFound := errors.IsNotFound(err)
+		if err != nil && !notFound {
+			return err
+		}
+		if notFound {
+			cm = &corev1.ConfigMap{
+				ObjectMeta: v1.ObjectMeta{
+					Name:      types.ProjectPersonasConfigMap,
+					Namespace: project,
+					Labels:    map[string]string{"app": "ambient-code", "ambient-code.io/purpose": "agent-personas"},
+				},
+			}
+		}
+
+		personas, err := parseProjectPersonas(cm)
+		if err != nil {
+			return err
+		}
+		personas, err = mutate(personas)
+		if err != nil {
+			return err
+		}
+		sort.Slice(personas, func(a, b int) bool { return personas[a].Name < personas[b].Name })
+		b, err := json.MarshalIndent(personas, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode personas: %w", err)
+		}
+		if cm.Data == nil {
+			cm.Data = map[string]string{}
+		}
+		cm.Data[types.ProjectPersonasKey] = string(b)
+
+		if notFound {
+			_, err = cms.Create(ctx, cm, v1.CreateOptions{})
+			if errors.IsAlreadyExists(err) {
+				continue
+			}
+		} else {
+			_, err = cms.Update(ctx, cm, v1.UpdateOptions{})
+			if errors.IsConflict(err) {
+				continue
+			}
+		}
+		return err
+	}
+	return fmt.Errorf("failed to update personas after retries")
+}
+
+// respondPersonaError maps registry errors to HTTP responses
+func respondPersonaError(c *gin.Context, project string, err error) {
+	switch {
+	case err == errPersonaNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case err == errPersonaExists:
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.IsForbidden(err):
+		c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions to manage personas"})
+	default:
+		logging.Errorf(c, "Failed to access personas for project %s: %v", project, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to access personas"})
+	}
+}
+
+// validateSessionPersona checks a session's persona exists in the project registry
+func validateSessionPersona(ctx context.Context, k8s kubernetes.Interface, project, name string) error {
+	if _, err := LoadAgentPersona(ctx, k8s, project, name); err != nil {
+		if err == errPersonaNotFound {
+			return fmt.Errorf("persona %q is not registered in this project", name)
+		}
+		return err
+	}
+	return nil
+}
+
+// ListPersonas handles GET /api/projects/:projectName/personas
+func ListPersonas(c *gin.Context) {
+	project := c.GetString("project")
+	reqK8s, _ := GetK8sClientsForRequest(c)
+	if reqK8s == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing token"})
+		c.Abort()
+		return
+	}
+
+	personas, err := LoadProjectPersonas(c.Request.Context(), reqK8s, project)
+	if err != nil {
+		respondPersonaError(c, project, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": personas})
+}
+
+// GetPersona handles GET /api/projects/:projectName/personas/:personaName
+func GetPersona(c *gin.Context) {
+	project := c.GetString("project")
+	reqK8s, _ := GetK8sClientsForRequest(c)
+	if reqK8s == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing token"})
+		c.Abort()
+		return
+	}
+
+	p, err := LoadAgentPersona(c.Request.Context(), reqK8s, project, c.Param("personaName"))
+	if err != nil {
+		respondPersonaError(c, project, err)
+		return
+	}
+	c.JSON(http.StatusOK, p)
+}
+
+// CreatePersona handles POST /api/projects/:projectName/personas
+func CreatePersona(c *gin.Context) {
+	project := c.GetString("project")
+	reqK8s, _ := GetK8sClientsForRequest(c)
+	if reqK8s == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing token"})
+		c.Abort()
+		return
+	}
+
+	var req types.AgentPersona
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := validateAgentPersona(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.UpdatedBy = c.GetString("userID")
+	req.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
+
+	err := updateProjectPersonas(c.Request.Context(), reqK8s, project, func(personas []types.AgentPersona) ([]types.AgentPersona, error) {
+		for _, p := range personas {
+			if p.Name == req.Name {
+				return nil, errPersonaExists
+			}
+		}
+		return append(personas, req), nil
+	})
+	if err != nil {
+		respondPersonaError(c, project, err)
+		return
+	}
+	c.JSON(http.StatusCreated, req)
+}
+
+// UpdatePersona handles PUT /api/projects/:projectName/personas/:personaName
+// Sessions using the persona pick up the change on their next run.
+func UpdatePersona(c *gin.Context) {
+	project := c.GetString("project")
+	name := c.Param("personaName")
+	reqK8s, _ := GetK8sClientsForRequest(c)
+	if reqK8s == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing token"})
+		c.Abort()
+		return
+	}
+
+	var req types.AgentPersona
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	// The path names the persona; renames are not supported
+	req.Name = name
+	if err := validateAgentPersona(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	req.UpdatedBy = c.GetString("userID")
+	req.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
+
+	err := updateProjectPersonas(c.Request.Context(), reqK8s, project, func(personas []types.AgentPersona) ([]types.AgentPersona, error) {
+		for i := range personas {
+			if personas[i].Name == name {
+				personas[i] = req
+				return personas, nil
+			}
+		}
+		return nil, errPersonaNotFound
+	})
+	if err != nil {
+		respondPersonaError(c, project, err)
+		return
+	}
+	c.JSON(http.StatusOK, req)
+}
+
+// DeletePersona handles DELETE /api/projects/:projectName/personas/:personaName
+// Runs of sessions still referencing the persona fail until it is restored or the
+// session's persona is cleared.
+func DeletePersona(c *gin.Context) {
+	project := c.GetString("project")
+	name := c.Param("personaName")
+	reqK8s, _ := GetK8sClientsForRequest(c)
+	if reqK8s == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing token"})
+		c.Abort()
+		return
+	}
+
+	err := updateProjectPersonas(c.Request.Context(), reqK8s, project, func(personas []types.AgentPersona) ([]types.AgentPersona, error) {
+		for i := range personas {
+			if personas[i].Name == name {
+				return append(personas[:i], personas[i+1:]...), nil
+			}
+		}
+		return nil, errPersonaNotFound
+	})
+	if err != nil {
+		respondPersonaError(c, project, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Persona removed successfully"})
+}
@@ -0,0 +1,42 @@
+//go:build test
+
+package handlers
+
+import (
+	"context"
+
+	test_constants "ambient-code-backend/tests/constants"
+	"ambient-code-backend/types"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+var _ = Describe("Project Persona Registry", Label(test_constants.LabelUnit, test_constants.LabelHandlers), func() {
+	It("Should validate names, tools and MCP servers", func() {
+		Expect(validateAgentPersona(types.AgentPersona{Name: "staff-reviewer", SystemPrompt: "Review like a staff engineer.", AllowedTools: []string{"Read", "Grep"}, MCPServers: []string{"github"}})).To(Succeed())
+		Expect(validateAgentPersona(types.AgentPersona{Name: "test-writer"})).To(Succeed())
+
+		Expect(validateAgentPersona(types.AgentPersona{Name: "Test Writer"})).To(HaveOccurred())
+		Expect(validateAgentPersona(types.AgentPersona{Name: "test-writer", AllowedTools: []string{"Read Write"}})).To(HaveOccurred())
+		Expect(validateAgentPersona(types.AgentPersona{Name: "test-writer", MCPServers: []string{"GitHub"}})).To(HaveOccurred())
+	})
+
+	It("Should store personas and require sessions to reference registered ones", func() {
+		ctx := context.Background()
+		k8s := fake.NewSimpleClientset()
+		Expect(updateProjectPersonas(ctx, k8s, "proj", func(personas []types.AgentPersona) ([]types.AgentPersona, error) {
+			return append(personas, types.AgentPersona{Name: "test-writer", Model: "claude-sonnet-4-5"}), nil
+		})).To(Succeed())
+
+		p, err := LoadAgentPersona(ctx, k8s, "proj", "test-writer")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(p.Model).To(Equal("claude-sonnet-4-5"))
+		Expect(validateSessionPersona(ctx, k8s, "proj", "test-writer")).To(Succeed())
+
+		_, err = LoadAgentPersona(ctx, k8s, "proj", "missing")
+		Expect(IsPersonaNotFound(err)).To(BeTrue())
+		Expect(validateSessionPersona(ctx, k8s, "proj", "missing")).To(MatchError(ContainSubstring("not registered")))
+	})
+})
@@ -3,7 +3,6 @@ package handlers
 import (
 	"context"
 	"fmt"
-	"log"
 	"net/http"
 	"os"
 	"regexp"
@@ -12,6 +11,7 @@ import (
 	"sync"
 	"time"
 
+	"ambient-code-backend/logging"
 	"ambient-code-backend/types"
 
 	"github.com/gin-gonic/gin"
@@ -102,7 +102,7 @@ func sanitizeForK8sName(subject string) string {
 func isOpenShiftCluster() bool {
 	isOpenShiftOnce.Do(func() {
 		if K8sClientProjects == nil {
-			log.Printf("K8s client not initialized, assuming vanilla Kubernetes")
+			logging.Infof(context.Background(), "K8s client not initialized, assuming vanilla Kubernetes")
 			isOpenShiftCache = false
 			return
 		}
@@ -110,20 +110,20 @@ func isOpenShiftCluster() bool {
 		// Try to list API groups and look for project.openshift.io
 		groups, err := K8sClientProjects.Discovery().ServerGroups()
 		if err != nil {
-			log.Printf("Failed to detect OpenShift (assuming vanilla Kubernetes): %v", err)
+			logging.Errorf(context.Background(), "Failed to detect OpenShift (assuming vanilla Kubernetes): %v", err)
 			isOpenShiftCache = false
 			return
 		}
 
 		for _, group := range groups.Groups {
 			if group.Name == "project.openshift.io" {
-				log.Printf("Detected OpenShift cluster")
+				logging.Infof(context.Background(), "Detected OpenShift cluster")
 				isOpenShiftCache = true
 				return
 			}
 		}
 
-		log.Printf("Detected vanilla Kubernetes cluster")
+		logging.Infof(context.Background(), "Detected vanilla Kubernetes cluster")
 		isOpenShiftCache = false
 	})
 	return isOpenShiftCache
@@ -189,7 +189,7 @@ func ListProjects(c *gin.Context) {
 		LabelSelector: "ambient-code.io/managed=true",
 	})
 	if err != nil {
-		log.Printf("Failed to list Namespaces: %v", err)
+		logging.Errorf(c, "Failed to list Namespaces: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list projects"})
 		return
 	}
@@ -198,7 +198,7 @@ func ListProjects(c *gin.Context) {
 	filteredNamespaces := filterNamespacesBySearch(nsList.Items, params.Search, isOpenShift)
 
 	// Perform parallel SSAR checks using worker pool
-	accessibleProjects := performParallelSSARChecks(ctx, k8sClt, filteredNamespaces, isOpenShift)
+	accessibleProjects := performParallelSSARChecks(ctx, k8sClt, AccessCaller(c), filteredNamespaces, isOpenShift)
 
 	// Sort by creation timestamp (newest first)
 	sortProjectsByCreationTime(accessibleProjects)
@@ -251,7 +251,7 @@ func filterNamespacesBySearch(namespaces []corev1.Namespace, search string, isOp
 }
 
 // performParallelSSARChecks performs SSAR checks in parallel using a worker pool
-func performParallelSSARChecks(ctx context.Context, reqK8s kubernetes.Interface, namespaces []corev1.Namespace, isOpenShift bool) []types.AmbientProject {
+func performParallelSSARChecks(ctx context.Context, reqK8s kubernetes.Interface, caller string, namespaces []corev1.Namespace, isOpenShift bool) []types.AmbientProject {
 	if len(namespaces) == 0 {
 		return []types.AmbientProject{}
 	}
@@ -290,7 +290,7 @@ func performParallelSSARChecks(ctx context.Context, reqK8s kubernetes.Interface,
 				default:
 				}
 
-				hasAccess, err := checkUserCanAccessNamespace(reqK8s, ns.Name)
+				hasAccess, err := checkUserCanAccessNamespace(reqK8s, caller, ns.Name)
 				resultChan <- accessCheckResult{
 					namespace: ns,
 					hasAccess: hasAccess,
@@ -321,7 +321,7 @@ func performParallelSSARChecks(ctx context.Context, reqK8s kubernetes.Interface,
 			continue
 		}
 		if result.err != nil {
-			log.Printf("Failed to check access for namespace %s: %v", result.namespace.Name, result.err)
+			logging.Errorf(ctx, "Failed to check access for namespace %s: %v", result.namespace.Name, result.err)
 			continue
 		}
 		if result.hasAccess {
@@ -330,7 +330,7 @@ func performParallelSSARChecks(ctx context.Context, reqK8s kubernetes.Interface,
 	}
 
 	if cancelledCount > 0 {
-		log.Printf("Warning: %d SSAR checks were cancelled due to context timeout", cancelledCount)
+		logging.Warnf(ctx, "%d SSAR checks were cancelled due to context timeout", cancelledCount)
 	}
 
 	return projects
@@ -427,7 +427,7 @@ func CreateProject(c *gin.Context) {
 	// Extract user identity from token
 	userSubject, err := getUserSubjectFromContext(c)
 	if err != nil {
-		log.Printf("CreateProject: Failed to extract user subject: %v", err)
+		logging.Errorf(c, "CreateProject: Failed to extract user subject: %v", err)
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
 		return
 	}
@@ -465,7 +465,7 @@ func CreateProject(c *gin.Context) {
 
 	createdNs, err := K8sClientProjects.CoreV1().Namespaces().Create(ctx, ns, v1.CreateOptions{})
 	if err != nil {
-		log.Printf("Failed to create namespace %s: %v", req.Name, err)
+		logging.Errorf(c, "Failed to create namespace %s: %v", req.Name, err)
 		if errors.IsAlreadyExists(err) {
 			c.JSON(http.StatusConflict, gin.H{"error": "Project already exists"})
 		} else if errors.IsForbidden(err) {
@@ -513,7 +513,7 @@ func CreateProject(c *gin.Context) {
 
 	_, err = K8sClientProjects.RbacV1().RoleBindings(req.Name).Create(ctx2, roleBinding, v1.CreateOptions{})
 	if err != nil {
-		log.Printf("ERROR: Created namespace %s but failed to assign admin role: %v", req.Name, err)
+		logging.Errorf(c, "ERROR: Created namespace %s but failed to assign admin role: %v", req.Name, err)
 
 		// ROLLBACK: Delete the namespace since role binding failed
 		// Without the role binding, the user won't have access to their project
@@ -522,7 +522,7 @@ func CreateProject(c *gin.Context) {
 
 		deleteErr := K8sClientProjects.CoreV1().Namespaces().Delete(ctx3, req.Name, v1.DeleteOptions{})
 		if deleteErr != nil {
-			log.Printf("CRITICAL: Failed to rollback namespace %s after role binding failure: %v", req.Name, deleteErr)
+			logging.Errorf(c, "CRITICAL: Failed to rollback namespace %s after role binding failure: %v", req.Name, deleteErr)
 
 			// Label the namespace as orphaned for manual cleanup
 			patch := []byte(`{"metadata":{"labels":{"ambient-code.io/orphaned":"true","ambient-code.io/orphan-reason":"role-binding-failed"}}}`)
@@ -533,9 +533,9 @@ func CreateProject(c *gin.Context) {
 				ctx4, req.Name, k8stypes.MergePatchType, patch, v1.PatchOptions{},
 			)
 			if labelErr != nil {
-				log.Printf("CRITICAL: Failed to label orphaned namespace %s: %v", req.Name, labelErr)
+				logging.Errorf(c, "CRITICAL: Failed to label orphaned namespace %s: %v", req.Name, labelErr)
 			} else {
-				log.Printf("Labeled orphaned namespace %s for manual cleanup", req.Name)
+				logging.Infof(c, "Labeled orphaned namespace %s for manual cleanup", req.Name)
 			}
 		}
 
@@ -597,9 +597,9 @@ func CreateProject(c *gin.Context) {
 		})
 
 		if retryErr != nil {
-			log.Printf("WARNING: Failed to update Project resource for %s after retries: %v", req.Name, retryErr)
+			logging.Warnf(c, "Failed to update Project resource for %s after retries: %v", req.Name, retryErr)
 		} else {
-			log.Printf("Successfully updated Project resource with display metadata for %s", req.Name)
+			logging.Infof(c, "Successfully updated Project resource with display metadata for %s", req.Name)
 		}
 	}
 
@@ -657,28 +657,28 @@ func GetProject(c *gin.Context) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
 			return
 		}
-		log.Printf("Failed to get Namespace %s: %v", projectName, err)
+		logging.Errorf(c, "Failed to get Namespace %s: %v", projectName, err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get project"})
 		return
 	}
 
 	// Validate it's an Ambient-managed namespace
 	if ns.Labels["ambient-code.io/managed"] != "true" {
-		log.Printf("SECURITY: User attempted to access non-managed namespace: %s", projectName)
+		logging.Warnf(c, "SECURITY: User attempted to access non-managed namespace: %s", projectName)
 		c.JSON(http.StatusNotFound, gin.H{"error": "Project not found or not an Ambient project"})
 		return
 	}
 
 	// Verify user can view the project (GET projectsettings)
-	canView, err := checkUserCanViewProject(k8sClt, projectName)
+	canView, err := checkUserCanViewProject(k8sClt, AccessCaller(c), projectName)
 	if err != nil {
-		log.Printf("GetProject: Failed to check access for %s: %v", projectName, err)
+		logging.Errorf(c, "GetProject: Failed to check access for %s: %v", projectName, err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify permissions"})
 		return
 	}
 
 	if !canView {
-		log.Printf("User attempted to view project %s without GET projectsettings permission", projectName)
+		logging.Warnf(c, "User attempted to view project %s without GET projectsettings permission", projectName)
 		c.JSON(http.StatusForbidden, gin.H{"error": "Unauthorized to view project"})
 		return
 	}
@@ -730,28 +730,28 @@ func UpdateProject(c *gin.Context) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
 			return
 		}
-		log.Printf("Failed to get Namespace %s: %v", projectName, err)
+		logging.Errorf(c, "Failed to get Namespace %s: %v", projectName, err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get project"})
 		return
 	}
 
 	// Validate it's an Ambient-managed namespace
 	if ns.Labels["ambient-code.io/managed"] != "true" {
-		log.Printf("SECURITY: User attempted to update non-managed namespace: %s", projectName)
+		logging.Warnf(c, "SECURITY: User attempted to update non-managed namespace: %s", projectName)
 		c.JSON(http.StatusNotFound, gin.H{"error": "Project not found or not an Ambient project"})
 		return
 	}
 
 	// Verify user can modify the project (UPDATE projectsettings)
-	canModify, err := checkUserCanModifyProject(k8sClt, projectName)
+	canModify, err := checkUserCanModifyProject(k8sClt, AccessCaller(c), projectName)
 	if err != nil {
-		log.Printf("UpdateProject: Failed to check access for %s: %v", projectName, err)
+		logging.Errorf(c, "UpdateProject: Failed to check access for %s: %v", projectName, err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify permissions"})
 		return
 	}
 
 	if !canModify {
-		log.Printf("User attempted to update project %s without UPDATE projectsettings permission", projectName)
+		logging.Warnf(c, "User attempted to update project %s without UPDATE projectsettings permission", projectName)
 		c.JSON(http.StatusForbidden, gin.H{"error": "Unauthorized to update project"})
 		return
 	}
@@ -777,7 +777,7 @@ func UpdateProject(c *gin.Context) {
 		// Update using backend SA (users can't update namespace annotations)
 		_, err = K8sClientProjects.CoreV1().Namespaces().Update(ctx2, ns, v1.UpdateOptions{})
 		if err != nil {
-			log.Printf("Failed to update Namespace annotations for %s: %v", projectName, err)
+			logging.Errorf(c, "Failed to update Namespace annotations for %s: %v", projectName, err)
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update project"})
 			return
 		}
@@ -823,28 +823,28 @@ func DeleteProject(c *gin.Context) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
 			return
 		}
-		log.Printf("Failed to get namespace %s: %v", projectName, err)
+		logging.Errorf(c, "Failed to get namespace %s: %v", projectName, err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get project"})
 		return
 	}
 
 	// Validate it's an Ambient-managed namespace
 	if ns.Labels["ambient-code.io/managed"] != "true" {
-		log.Printf("SECURITY: User attempted to delete non-managed namespace: %s", projectName)
+		logging.Warnf(c, "SECURITY: User attempted to delete non-managed namespace: %s", projectName)
 		c.JSON(http.StatusNotFound, gin.H{"error": "Project not found or not an Ambient project"})
 		return
 	}
 
 	// Verify user can modify the project (UPDATE projectsettings)
-	canModify, err := checkUserCanModifyProject(k8sClt, projectName)
+	canModify, err := checkUserCanModifyProject(k8sClt, AccessCaller(c), projectName)
 	if err != nil {
-		log.Printf("DeleteProject: Failed to check access for %s: %v", projectName, err)
+		logging.Errorf(c, "DeleteProject: Failed to check access for %s: %v", projectName, err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify permissions"})
 		return
 	}
 
 	if !canModify {
-		log.Printf("User attempted to delete project %s without UPDATE projectsettings permission", projectName)
+		logging.Warnf(c, "User attempted to delete project %s without UPDATE projectsettings permission", projectName)
 		c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions to delete project"})
 		return
 	}
@@ -859,7 +859,7 @@ func DeleteProject(c *gin.Context) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Project not found"})
 			return
 		}
-		log.Printf("Failed to delete namespace %s: %v", projectName, err)
+		logging.Errorf(c, "Failed to delete namespace %s: %v", projectName, err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete project"})
 		return
 	}
@@ -870,65 +870,43 @@ func DeleteProject(c *gin.Context) {
 
 // checkUserCanViewProject checks if user can GET projectsettings in the namespace
 // This determines if they can view the project/namespace details
-func checkUserCanViewProject(userClient kubernetes.Interface, namespace string) (bool, error) {
+func checkUserCanViewProject(userClient kubernetes.Interface, caller, namespace string) (bool, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
 	defer cancel()
 
-	ssar := &authv1.SelfSubjectAccessReview{
-		Spec: authv1.SelfSubjectAccessReviewSpec{
-			ResourceAttributes: &authv1.ResourceAttributes{
-				Namespace: namespace,
-				Verb:      "get",
-				Group:     "vteam.ambient-code",
-				Resource:  "projectsettings",
-			},
-		},
-	}
-
-	result, err := userClient.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, ssar, v1.CreateOptions{})
-	if err != nil {
-		return false, err
-	}
-
-	return result.Status.Allowed, nil
+	return CheckAccess(ctx, userClient, caller, authv1.ResourceAttributes{
+		Namespace: namespace,
+		Verb:      "get",
+		Group:     "vteam.ambient-code",
+		Resource:  "projectsettings",
+	})
 }
 
 // checkUserCanModifyProject checks if user can UPDATE projectsettings in the namespace
 // This determines if they can update or delete the project/namespace
-func checkUserCanModifyProject(userClient kubernetes.Interface, namespace string) (bool, error) {
+func checkUserCanModifyProject(userClient kubernetes.Interface, caller, namespace string) (bool, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
 	defer cancel()
 
-	ssar := &authv1.SelfSubjectAccessReview{
-		Spec: authv1.SelfSubjectAccessReviewSpec{
-			ResourceAttributes: &authv1.ResourceAttributes{
-				Namespace: namespace,
-				Verb:      "update",
-				Group:     "vteam.ambient-code",
-				Resource:  "projectsettings",
-			},
-		},
-	}
-
-	result, err := userClient.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, ssar, v1.CreateOptions{})
-	if err != nil {
-		return false, err
-	}
-
-	return result.Status.Allowed, nil
+	return CheckAccess(ctx, userClient, caller, authv1.ResourceAttributes{
+		Namespace: namespace,
+		Verb:      "update",
+		Group:     "vteam.ambient-code",
+		Resource:  "projectsettings",
+	})
 }
 
 // checkUserCanAccessNamespace uses SelfSubjectAccessReview to verify if user can access a namespace
 // This is the proper Kubernetes-native way - lets RBAC engine determine access from ALL sources
 // (RoleBindings, ClusterRoleBindings, groups, etc.)
 // Deprecated: Use checkUserCanViewProject or checkUserCanModifyProject instead
-func checkUserCanAccessNamespace(userClient kubernetes.Interface, namespace string) (bool, error) {
+func checkUserCanAccessNamespace(userClient kubernetes.Interface, caller, namespace string) (bool, error) {
 	// Safety check: ensure client is not nil
 	if userClient == nil {
 		return false, fmt.Errorf("kubernetes client is nil")
 	}
 	// For backward compatibility, check if user can list agenticsessions
-	return checkUserCanViewProject(userClient, namespace)
+	return checkUserCanViewProject(userClient, caller, namespace)
 }
 
 // getUserSubjectFromContext extracts the user subject from the JWT token in the request
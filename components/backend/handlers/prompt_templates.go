@@ -0,0 +1,383 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"ambient-code-backend/logging"
+	"ambient-code-backend/types"
+
+	"github.com/gin-gonic/gin"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// maxPromptTemplateLength keeps the library within ConfigMap size limits
+const maxPromptTemplateLength = 32 * 1024
+
+// promptVariablePattern matches {{variable}} placeholders, allowing inner spaces
+var promptVariablePattern = regexp.MustCompile(`\{\{\s*([a-zA-Z_][a-zA-Z0-9_]*)\s*\}\}`)
+
+// errPromptNotFound, errPromptExists and errPromptVersionMismatch are returned by
+// library lookups and mutations
+var (
+	errPromptNotFound        = fmt.Errorf("prompt template not found")
+	errPromptExists          = fmt.Errorf("prompt template already exists")
+	errPromptVersionMismatch = fmt.Errorf("prompt template version does not match")
+)
+
+// promptTemplateVariables lists the placeholders of a template in order of first use
+func promptTemplateVariables(template string) []string {
+	seen := map[string]bool{}
+	variables := []string{}
+	for _, m := range promptVariablePattern.FindAllStringSubmatch(template, -1) {
+		if !seen[m[1]] {
+			seen[m[1]] = true
+			variables = append(variables, m[1])
+		}
+	}
+	return variables
+}
+
+// validatePromptTemplate checks a template before it is stored
+func validatePromptTemplate(p types.PromptTemplate) error {
+	if !isValidKubernetesName(p.Name) {
+		return fmt.Errorf("name must be a lowercase DNS label (a-z, 0-9, '-')")
+	}
+	if strings.TrimSpace(p.Template) == "" {
+		return fmt.Errorf("template is required")
+	}
+	if len(p.Template) > maxPromptTemplateLength {
+		return fmt.Errorf("template must be at most %d bytes", maxPromptTemplateLength)
+	}
+	return nil
+}
+
+// RenderPromptTemplate substitutes values for the template's placeholders. Every
+// placeholder needs a value and every value a placeholder, so typos fail loudly
+// instead of reaching the agent. Values are inserted verbatim and not re-expanded.
+func RenderPromptTemplate(p types.PromptTemplate, values map[string]string) (string, error) {
+	variables := promptTemplateVariables(p.Template)
+	var missing []string
+	for _, name := range variables {
+		if _, ok := values[name]; !ok {
+			missing = append(missing, name)
+		}
+	}
+	if len(missing) > 0 {
+		return "", fmt.Errorf("missing values for variables: %s", strings.Join(missing, ", "))
+	}
+	var unknown []string
+	for name := range values {
+		if !slices.Contains(variables, name) {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return "", fmt.Errorf("unknown variables: %s", strings.Join(unknown, ", "))
+	}
+	return promptVariablePattern.ReplaceAllStringFunc(p.Template, func(placeholder string) string {
+		return values[promptVariablePattern.FindStringSubmatch(placeholder)[1]]
+	}), nil
+}
+
+// LoadPromptTemplate returns a template of the project's library. A non-zero version
+// must match the current one, so callers pinning a version notice edits.
+func LoadPromptTemplate(ctx context.Context, k8s kubernetes.Interface, project, name string, version int) (types.PromptTemplate, error) {
+	prompts, err := LoadProjectPromptTemplates(ctx, k8s, project)
+	if err != nil {
+		return types.PromptTemplate{}, err
+	}
+	for _, p := range prompts {
+		if p.Name == name {
+			if version != 0 && p.Version != version {
+				return types.PromptTemplate{}, errPromptVersionMismatch
+			}
+			return p, nil
+		}
+	}
+	return types.PromptTemplate{}, errPromptNotFound
+}
+
+// LoadProjectPromptTemplates reads the project's prompt library
+func LoadProjectPromptTemplates(ctx context.Context, k8s kubernetes.Interface, project string) ([]types.PromptTemplate, error) {
+	cm, err := k8s.CoreV1().ConfigMaps(project).Get(ctx, types.ProjectPromptsConfigMap, v1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return []types.PromptTemplate{}, nil
+		}
+		return nil, err
+	}
+	return parseProjectPromptTemplates(cm)
+}
+
+func parseProjectPromptTemplates(cm *corev1.ConfigMap) ([]types.PromptTemplate, error) {
+	prompts := []types.PromptTemplate{}
+	raw := cm.Data[types.ProjectPromptsKey]
+	if strings.TrimSpace(raw) == "" {
+		return prompts, nil
+	}
+	if err := json.Unmarshal([]byte(raw), &prompts); err != nil {
+		return nil, fmt.Errorf("invalid prompt library: %w", err)
+	}
+	return prompts, nil
+}
+
+// updateProjectPromptTemplates applies mutate to the library and persists the result,
+// retrying on update conflicts
+func updateProjectPromptTemplates(ctx context.Context, k8s kubernetes.Interface, project string, mutate func([]types.PromptTemplate) ([]types.PromptTemplate, error)) error {
+	cms := k8s.CoreV1().ConfigMaps(project)
+	for i := 0; i < 3; i++ {
+		cm, err := cms.Get(ctx, types.ProjectPromptsConfigMap, v1.GetOptions{})
+		notFound := errors.IsNotFound(err)
+		if err != nil && !notFound {
+			return err
+		}
+		if notFound {
+			cm = &corev1.ConfigMap{
+				ObjectMeta: v1.ObjectMeta{
+					Name:      types.ProjectPromptsConfigMap,
+					Namespace: project,
+					Labels:    map[string]string{"app": "ambient-code", "ambient-code.io/purpose": "prompt-templates"},
+				},
+			}
+		}
+
+		prompts, err := parseProjectPromptTemplates(cm)
+		if err != nil {
+			return err
+		}
+		prompts, err = mutate(prompts)
+		if err != nil {
+			return err
+		}
+		sort.Slice(prompts, func(a, b int) bool { return prompts[a].Name < prompts[b].Name })
+		b, err := json.MarshalIndent(prompts, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode prompt templates: %w", err)
+		}
+		if cm.Data == nil {
+			cm.Data = map[string]string{}
+		}
+		cm.Data[types.ProjectPromptsKey] = string(b)
+
+		if notFound {
+			_, err = cms.Create(ctx, cm, v1.CreateOptions{})
+			if errors.IsAlreadyExists(err) {
+				continue
+			}
+		} else {
+			_, err = cms.Update(ctx, cm, v1.UpdateOptions{})
+			if errors.IsConflict(err) {
+				continue
+			}
+		}
+		return err
+	}
+	return fmt.Errorf("failed to update prompt templates after retries")
+}
+
+// RespondPromptTemplateError maps library errors to HTTP responses
+func RespondPromptTemplateError(c *gin.Context, project string, err error) {
+	switch {
+	case err == errPromptNotFound:
+		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	case err == errPromptExists, err == errPromptVersionMismatch:
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.IsForbidden(err):
+		c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions to manage prompt templates"})
+	default:
+		logging.Errorf(c, "Failed to access prompt templates for project %s: %v", project, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to access prompt templates"})
+	}
+}
+
+// stampPromptTemplate derives the stored fields of a template being saved
+func stampPromptTemplate(c *gin.Context, p *types.PromptTemplate, version int) {
+	p.Variables = promptTemplateVariables(p.Template)
+	p.Version = version
+	p.UpdatedBy = c.GetString("userID")
+	p.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
+}
+
+// ListPromptTemplates handles GET /api/projects/:projectName/prompts
+func ListPromptTemplates(c *gin.Context) {
+	project := c.GetString("project")
+	reqK8s, _ := GetK8sClientsForRequest(c)
+	if reqK8s == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing token"})
+		c.Abort()
+		return
+	}
+
+	prompts, err := LoadProjectPromptTemplates(c.Request.Context(), reqK8s, project)
+	if err != nil {
+		RespondPromptTemplateError(c, project, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"items": prompts})
+}
+
+// GetPromptTemplate handles GET /api/projects/:projectName/prompts/:promptName
+func GetPromptTemplate(c *gin.Context) {
+	project := c.GetString("project")
+	reqK8s, _ := GetK8sClientsForRequest(c)
+	if reqK8s == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing token"})
+		c.Abort()
+		return
+	}
+
+	p, err := LoadPromptTemplate(c.Request.Context(), reqK8s, project, c.Param("promptName"), 0)
+	if err != nil {
+		RespondPromptTemplateError(c, project, err)
+		return
+	}
+	c.JSON(http.StatusOK, p)
+}
+
+// CreatePromptTemplate handles POST /api/projects/:projectName/prompts
+func CreatePromptTemplate(c *gin.Context) {
+	project := c.GetString("project")
+	reqK8s, _ := GetK8sClientsForRequest(c)
+	if reqK8s == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing token"})
+		c.Abort()
+		return
+	}
+
+	var req types.PromptTemplate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := validatePromptTemplate(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	stampPromptTemplate(c, &req, 1)
+
+	err := updateProjectPromptTemplates(c.Request.Context(), reqK8s, project, func(prompts []types.PromptTemplate) ([]types.PromptTemplate, error) {
+		for _, p := range prompts {
+			if p.Name == req.Name {
+				return nil, errPromptExists
+			}
+		}
+		return append(prompts, req), nil
+	})
+	if err != nil {
+		RespondPromptTemplateError(c, project, err)
+		return
+	}
+	c.JSON(http.StatusCreated, req)
+}
+
+// UpdatePromptTemplate handles PUT /api/projects/:projectName/prompts/:promptName
+// A non-zero version in the body must match the stored one, guarding against
+// overwriting a concurrent edit.
+func UpdatePromptTemplate(c *gin.Context) {
+	project := c.GetString("project")
+	name := c.Param("promptName")
+	reqK8s, _ := GetK8sClientsForRequest(c)
+	if reqK8s == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing token"})
+		c.Abort()
+		return
+	}
+
+	var req types.PromptTemplate
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	// The path names the template; renames are not supported
+	req.Name = name
+	if err := validatePromptTemplate(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	err := updateProjectPromptTemplates(c.Request.Context(), reqK8s, project, func(prompts []types.PromptTemplate) ([]types.PromptTemplate, error) {
+		for i := range prompts {
+			if prompts[i].Name == name {
+				if req.Version != 0 && req.Version != prompts[i].Version {
+					return nil, errPromptVersionMismatch
+				}
+				stampPromptTemplate(c, &req, prompts[i].Version+1)
+				prompts[i] = req
+				return prompts, nil
+			}
+		}
+		return nil, errPromptNotFound
+	})
+	if err != nil {
+		RespondPromptTemplateError(c, project, err)
+		return
+	}
+	c.JSON(http.StatusOK, req)
+}
+
+// DeletePromptTemplate handles DELETE /api/projects/:projectName/prompts/:promptName
+func DeletePromptTemplate(c *gin.Context) {
+	project := c.GetString("project")
+	name := c.Param("promptName")
+	reqK8s, _ := GetK8sClientsForRequest(c)
+	if reqK8s == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing token"})
+		c.Abort()
+		return
+	}
+
+	err := updateProjectPromptTemplates(c.Request.Context(), reqK8s, project, func(prompts []types.PromptTemplate) ([]types.PromptTemplate, error) {
+		for i := range prompts {
+			if prompts[i].Name == name {
+				return append(prompts[:i], prompts[i+1:]...), nil
+			}
+		}
+		return nil, errPromptNotFound
+	})
+	if err != nil {
+		RespondPromptTemplateError(c, project, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Prompt template removed successfully"})
+}
+
+// RenderPromptTemplatePreview handles POST /api/projects/:projectName/prompts/:promptName/render
+// Renders the template with the posted variables without starting a run
+func RenderPromptTemplatePreview(c *gin.Context) {
+	project := c.GetString("project")
+	reqK8s, _ := GetK8sClientsForRequest(c)
+	if reqK8s == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing token"})
+		c.Abort()
+		return
+	}
+
+	var req types.PromptTemplateRef
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	p, err := LoadPromptTemplate(c.Request.Context(), reqK8s, project, c.Param("promptName"), req.Version)
+	if err != nil {
+		RespondPromptTemplateError(c, project, err)
+		return
+	}
+	rendered, err := RenderPromptTemplate(p, req.Variables)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"name": p.Name, "version": p.Version, "prompt": rendered})
+}
@@ -0,0 +1,60 @@
+//go:build test
+
+package handlers
+
+import (
+	"context"
+
+	test_constants "ambient-code-backend/tests/constants"
+	"ambient-code-backend/types"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+var _ = Describe("Project Prompt Library", Label(test_constants.LabelUnit, test_constants.LabelHandlers), func() {
+	It("Should list placeholders in order of first use", func() {
+		Expect(promptTemplateVariables("Fix {{ issue }} in {{repo}}, then close {{issue}}")).To(Equal([]string{"issue", "repo"}))
+		Expect(promptTemplateVariables("No placeholders, {{ not valid-name }}")).To(BeEmpty())
+	})
+
+	It("Should render values verbatim without re-expanding them", func() {
+		p := types.PromptTemplate{Name: "triage", Template: "Triage {{issue}} in {{ repo }}."}
+		out, err := RenderPromptTemplate(p, map[string]string{"issue": "{{repo}}", "repo": "vteam"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out).To(Equal("Triage {{repo}} in vteam."))
+	})
+
+	It("Should reject missing and unknown variables", func() {
+		p := types.PromptTemplate{Name: "triage", Template: "Triage {{issue}} in {{repo}}."}
+		_, err := RenderPromptTemplate(p, map[string]string{"issue": "RHOAI-1"})
+		Expect(err).To(MatchError(ContainSubstring("repo")))
+		_, err = RenderPromptTemplate(p, map[string]string{"issue": "RHOAI-1", "repo": "vteam", "isue": "x"})
+		Expect(err).To(MatchError(ContainSubstring("isue")))
+	})
+
+	It("Should validate names and template size", func() {
+		Expect(validatePromptTemplate(types.PromptTemplate{Name: "triage-bug", Template: "Hi"})).To(Succeed())
+		Expect(validatePromptTemplate(types.PromptTemplate{Name: "Triage Bug", Template: "Hi"})).To(HaveOccurred())
+		Expect(validatePromptTemplate(types.PromptTemplate{Name: "triage-bug", Template: "  "})).To(HaveOccurred())
+	})
+
+	It("Should store templates and check pinned versions", func() {
+		ctx := context.Background()
+		k8s := fake.NewSimpleClientset()
+		Expect(updateProjectPromptTemplates(ctx, k8s, "proj", func(prompts []types.PromptTemplate) ([]types.PromptTemplate, error) {
+			return append(prompts, types.PromptTemplate{Name: "triage", Template: "Triage {{issue}}", Version: 2}), nil
+		})).To(Succeed())
+
+		p, err := LoadPromptTemplate(ctx, k8s, "proj", "triage", 0)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(p.Version).To(Equal(2))
+		_, err = LoadPromptTemplate(ctx, k8s, "proj", "triage", 2)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = LoadPromptTemplate(ctx, k8s, "proj", "triage", 1)
+		Expect(err).To(Equal(errPromptVersionMismatch))
+		_, err = LoadPromptTemplate(ctx, k8s, "proj", "missing", 0)
+		Expect(err).To(Equal(errPromptNotFound))
+	})
+})
@@ -0,0 +1,236 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"time"
+
+	"ambient-code-backend/git"
+	"ambient-code-backend/logging"
+	"ambient-code-backend/types"
+
+	"github.com/gin-gonic/gin"
+	"k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/util/retry"
+)
+
+// CreateSessionPullRequest opens a GitHub PR / GitLab MR from the session's working branch
+// using the session owner's resolved git credentials, and records it in status.pullRequests.
+// Only the owner can call it, so other users with update access can't act on the owner's
+// git account.
+// POST /api/projects/:projectName/agentic-sessions/:sessionName/git/pull-requests
+func CreateSessionPullRequest(c *gin.Context) {
+	project := c.GetString("project")
+	sessionName := c.Param("sessionName")
+
+	k8sClt, k8sDyn := GetK8sClientsForRequest(c)
+	if k8sClt == nil || k8sDyn == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing token"})
+		c.Abort()
+		return
+	}
+
+	var req types.CreatePullRequestRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "title is required"})
+		return
+	}
+	req.Title = strings.TrimSpace(req.Title)
+	if req.Title == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "title is required"})
+		return
+	}
+
+	gvr := GetAgenticSessionV1Alpha1Resource()
+	item, err := k8sDyn.Resource(gvr).Namespace(project).Get(c.Request.Context(), sessionName, v1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+			return
+		}
+		logging.Errorf(c, "CreateSessionPullRequest: failed to get session %s/%s: %v", project, sessionName, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get agentic session"})
+		return
+	}
+
+	opts, userID, errMsg := resolvePullRequestOptions(item, req)
+	if errMsg != "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": errMsg})
+		return
+	}
+	if userID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Session has no owner to resolve git credentials for"})
+		return
+	}
+	if c.GetString("userID") != userID {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Only the session owner can open pull requests with their git credentials"})
+		return
+	}
+
+	var token string
+	switch types.DetectProvider(opts.RepoURL) {
+	case types.ProviderGitHub:
+		if GetGitHubToken != nil {
+			token, err = GetGitHubToken(c.Request.Context(), k8sClt, k8sDyn, project, userID)
+		}
+	case types.ProviderGitLab:
+		if GetGitLabToken != nil {
+			token, err = GetGitLabToken(c.Request.Context(), k8sClt, project, userID)
+		}
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Unsupported repository provider"})
+		return
+	}
+	if err != nil || strings.TrimSpace(token) == "" {
+		logging.Warnf(c, "CreateSessionPullRequest: no git credentials for %s/%s (%s): %v", project, sessionName, opts.RepoURL, err)
+		c.JSON(http.StatusPreconditionFailed, gin.H{"error": "No git credentials configured for this repository"})
+		return
+	}
+
+	result, err := git.CreatePullRequest(c.Request.Context(), opts, token)
+	if err != nil {
+		logging.Infof(c, "CreateSessionPullRequest: %s/%s: %v", project, sessionName, err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
+		return
+	}
+
+	pr := types.SessionPullRequest{
+		URL:       result.URL,
+		Number:    result.Number,
+		Provider:  string(result.Provider),
+		RepoURL:   opts.RepoURL,
+		Head:      opts.Head,
+		Base:      opts.Base,
+		CreatedAt: time.Now().UTC().Format(time.RFC3339),
+	}
+	if err := recordSessionPullRequest(c.Request.Context(), project, sessionName, pr); err != nil {
+		// The PR exists; failing to record it should not hide the URL from the caller
+		logging.Errorf(c, "CreateSessionPullRequest: failed to record PR on %s/%s: %v", project, sessionName, err)
+	}
+
+	status := http.StatusCreated
+	if result.Existing {
+		status = http.StatusOK
+	}
+	c.JSON(status, pr)
+}
+
+// resolvePullRequestOptions fills in repo, head, and base from the session when the request
+// leaves them empty. Returns the session owner's userID, or a client error message.
+func resolvePullRequestOptions(item *unstructured.Unstructured, req types.CreatePullRequestRequest) (git.PullRequestOptions, string, string) {
+	opts := git.PullRequestOptions{
+		RepoURL: strings.TrimSpace(req.RepoURL),
+		Head:    strings.TrimSpace(req.Head),
+		Base:    strings.TrimSpace(req.Base),
+		Title:   req.Title,
+		Body:    req.Body,
+		Draft:   req.Draft,
+	}
+
+	var userID string
+	if uc, found, _ := unstructured.NestedMap(item.Object, "spec", "userContext"); found {
+		if v, ok := uc["userId"].(string); ok {
+			userID = strings.TrimSpace(v)
+		}
+	}
+
+	specRepos, _, _ := unstructured.NestedSlice(item.Object, "spec", "repos")
+	if opts.RepoURL == "" {
+		if len(specRepos) != 1 {
+			return opts, userID, "repoUrl is required for sessions with multiple repositories"
+		}
+		if m, ok := specRepos[0].(map[string]interface{}); ok {
+			opts.RepoURL, _ = m["url"].(string)
+		}
+		if opts.RepoURL == "" {
+			return opts, userID, "repoUrl is required"
+		}
+	}
+
+	var specBranch string
+	found := false
+	for _, entry := range specRepos {
+		if m, ok := entry.(map[string]interface{}); ok && m["url"] == opts.RepoURL {
+			specBranch, _ = m["branch"].(string)
+			found = true
+			break
+		}
+	}
+	if !found {
+		return opts, userID, "repoUrl is not one of this session's repositories"
+	}
+
+	var activeBranch, defaultBranch string
+	reconciled, _, _ := unstructured.NestedSlice(item.Object, "status", "reconciledRepos")
+	for _, entry := range reconciled {
+		if m, ok := entry.(map[string]interface{}); ok && m["url"] == opts.RepoURL {
+			activeBranch, _ = m["currentActiveBranch"].(string)
+			defaultBranch, _ = m["defaultBranch"].(string)
+			break
+		}
+	}
+
+	if opts.Head == "" {
+		opts.Head = activeBranch
+	}
+	if opts.Head == "" {
+		// Runner works on ambient/<session-name> unless told otherwise
+		opts.Head = "ambient/" + item.GetName()
+	}
+	if opts.Base == "" {
+		opts.Base = specBranch
+	}
+	if opts.Base == "" {
+		opts.Base = defaultBranch
+	}
+	if opts.Base == "" {
+		opts.Base = "main"
+	}
+	if opts.Head == opts.Base {
+		return opts, userID, "head and base branches must differ"
+	}
+	if err := git.ValidateBranchName(opts.Head); err != nil {
+		return opts, userID, "invalid head branch: " + err.Error()
+	}
+	return opts, userID, ""
+}
+
+// recordSessionPullRequest appends (or updates by URL) the PR in status.pullRequests.
+// Uses the backend service account since status is not user-writable.
+func recordSessionPullRequest(ctx context.Context, project, sessionName string, pr types.SessionPullRequest) error {
+	if DynamicClient == nil {
+		return nil
+	}
+	gvr := GetAgenticSessionV1Alpha1Resource()
+	entry := map[string]interface{}{
+		"url":       pr.URL,
+		"number":    int64(pr.Number),
+		"provider":  pr.Provider,
+		"repoUrl":   pr.RepoURL,
+		"head":      pr.Head,
+		"base":      pr.Base,
+		"createdAt": pr.CreatedAt,
+	}
+
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		obj, err := DynamicClient.Resource(gvr).Namespace(project).Get(ctx, sessionName, v1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		prs, _, _ := unstructured.NestedSlice(obj.Object, "status", "pullRequests")
+		for _, existing := range prs {
+			if m, ok := existing.(map[string]interface{}); ok && m["url"] == pr.URL {
+				return nil
+			}
+		}
+		prs = append(prs, entry)
+		if err := unstructured.SetNestedSlice(obj.Object, prs, "status", "pullRequests"); err != nil {
+			return err
+		}
+		_, err = DynamicClient.Resource(gvr).Namespace(project).UpdateStatus(ctx, obj, v1.UpdateOptions{})
+		return err
+	})
+}
@@ -0,0 +1,112 @@
+//go:build test
+
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	test_constants "ambient-code-backend/tests/constants"
+	"ambient-code-backend/types"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+var _ = Describe("Session Pull Requests", Label(test_constants.LabelUnit, test_constants.LabelHandlers, test_constants.LabelGit), func() {
+	newSession := func(repos []interface{}, reconciled []interface{}) *unstructured.Unstructured {
+		obj := &unstructured.Unstructured{Object: map[string]interface{}{
+			"metadata": map[string]interface{}{"name": "session-1"},
+			"spec": map[string]interface{}{
+				"repos":       repos,
+				"userContext": map[string]interface{}{"userId": "alice"},
+			},
+			"status": map[string]interface{}{"reconciledRepos": reconciled},
+		}}
+		return obj
+	}
+	repo := "https://github.com/org/app"
+
+	It("Should default head to the active branch and base to the cloned branch", func() {
+		session := newSession(
+			[]interface{}{map[string]interface{}{"url": repo, "branch": "develop"}},
+			[]interface{}{map[string]interface{}{"url": repo, "currentActiveBranch": "feature/x"}},
+		)
+		opts, userID, errMsg := resolvePullRequestOptions(session, types.CreatePullRequestRequest{Title: "Fix"})
+		Expect(errMsg).To(BeEmpty())
+		Expect(userID).To(Equal("alice"))
+		Expect(opts.RepoURL).To(Equal(repo))
+		Expect(opts.Head).To(Equal("feature/x"))
+		Expect(opts.Base).To(Equal("develop"))
+	})
+
+	It("Should fall back to the session working branch and main", func() {
+		session := newSession([]interface{}{map[string]interface{}{"url": repo}}, nil)
+		opts, _, errMsg := resolvePullRequestOptions(session, types.CreatePullRequestRequest{Title: "Fix"})
+		Expect(errMsg).To(BeEmpty())
+		Expect(opts.Head).To(Equal("ambient/session-1"))
+		Expect(opts.Base).To(Equal("main"))
+	})
+
+	It("Should require repoUrl for multi-repo sessions", func() {
+		session := newSession([]interface{}{
+			map[string]interface{}{"url": repo},
+			map[string]interface{}{"url": "https://github.com/org/lib"},
+		}, nil)
+		_, _, errMsg := resolvePullRequestOptions(session, types.CreatePullRequestRequest{Title: "Fix"})
+		Expect(errMsg).To(ContainSubstring("repoUrl is required"))
+	})
+
+	It("Should reject repositories outside the session", func() {
+		session := newSession([]interface{}{map[string]interface{}{"url": repo}}, nil)
+		_, _, errMsg := resolvePullRequestOptions(session, types.CreatePullRequestRequest{Title: "Fix", RepoURL: "https://github.com/evil/repo"})
+		Expect(errMsg).To(ContainSubstring("not one of this session's repositories"))
+	})
+
+	It("Should only open PRs with the owner's credentials for the owner", func() {
+		gvr := schema.GroupVersionResource{Group: "vteam.ambient-code", Version: "v1alpha1", Resource: "agenticsessions"}
+		origK8s, origDyn, origGVR, origToken := K8sClientMw, DynamicClient, GetAgenticSessionV1Alpha1Resource, GetGitHubToken
+		defer func() {
+			K8sClientMw, DynamicClient, GetAgenticSessionV1Alpha1Resource, GetGitHubToken = origK8s, origDyn, origGVR, origToken
+		}()
+		session := newSession([]interface{}{map[string]interface{}{"url": repo}}, nil)
+		session.SetAPIVersion("vteam.ambient-code/v1alpha1")
+		session.SetKind("AgenticSession")
+		session.SetNamespace("team-a")
+		GetAgenticSessionV1Alpha1Resource = func() schema.GroupVersionResource { return gvr }
+		K8sClientMw = fake.NewSimpleClientset()
+		DynamicClient = dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), session)
+		tokenFetched := false
+		GetGitHubToken = func(context.Context, kubernetes.Interface, dynamic.Interface, string, string) (string, error) {
+			tokenFetched = true
+			return "", nil
+		}
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"title":"Fix"}`))
+		c.Request.Header.Set("Authorization", "Bearer collaborator-token")
+		c.Set("project", "team-a")
+		c.Set("userID", "bob")
+		c.Params = gin.Params{{Key: "sessionName", Value: "session-1"}}
+		CreateSessionPullRequest(c)
+		Expect(w.Code).To(Equal(http.StatusForbidden))
+		Expect(tokenFetched).To(BeFalse())
+	})
+
+	It("Should reject opening a PR from a protected branch", func() {
+		session := newSession([]interface{}{map[string]interface{}{"url": repo, "branch": "release"}}, nil)
+		_, _, errMsg := resolvePullRequestOptions(session, types.CreatePullRequestRequest{Title: "Fix", Head: "main"})
+		Expect(errMsg).To(ContainSubstring("invalid head branch"))
+	})
+})
@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"ambient-code-backend/config"
+	"ambient-code-backend/logging"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Per-user request limits for endpoints that are expensive for the K8s API server or
+// external providers. Each limit is a fixed one-minute window per user, set by
+// rateLimits in the config file (reloadable) and overridden with RATE_LIMIT_<NAME>
+// (e.g. RATE_LIMIT_SESSION_CREATE=10); 0 disables it.
+const (
+	RateLimitSessionCreate        = "session-create"
+	RateLimitRunCreate            = "run-create"
+	RateLimitCredentialValidation = "credential-validation"
+
+	rateLimitWindow     = time.Minute
+	rateLimitMaxWindows = 10000
+)
+
+type rateWindow struct {
+	start time.Time
+	count int
+}
+
+var (
+	rateWindowsMu sync.Mutex
+	rateWindows   = map[string]*rateWindow{}
+)
+
+// rateLimitPerMinute returns the configured limit for name, 0 when disabled
+func rateLimitPerMinute(name string) int {
+	limit := config.Current().RateLimits[name]
+	env := config.RateLimitEnv(name)
+	if v := strings.TrimSpace(os.Getenv(env)); v != "" {
+		n, err := strconv.Atoi(v)
+		if err == nil && n >= 0 {
+			return n
+		}
+		logging.Warnf(context.Background(), "Ignoring invalid %s=%q, using %d", env, v, limit)
+	}
+	return limit
+}
+
+// rateLimitKey identifies the caller: the authenticated user, else their token, else their IP
+func rateLimitKey(c *gin.Context) string {
+	if userID := c.GetString("userID"); userID != "" {
+		return "user:" + userID
+	}
+	if caller := AccessCaller(c); caller != "" {
+		return caller
+	}
+	return "ip:" + c.ClientIP()
+}
+
+// allowRequest counts a request against key's current window. It returns whether the
+// request is allowed, the requests left in the window, and when the window resets.
+func allowRequest(key string, limit int, now time.Time) (bool, int, time.Time) {
+	rateWindowsMu.Lock()
+	defer rateWindowsMu.Unlock()
+
+	w, ok := rateWindows[key]
+	if !ok || now.Sub(w.start) >= rateLimitWindow {
+		if !ok && len(rateWindows) >= rateLimitMaxWindows {
+			for k, old := range rateWindows {
+				if now.Sub(old.start) >= rateLimitWindow {
+					delete(rateWindows, k)
+				}
+			}
+		}
+		w = &rateWindow{start: now}
+		rateWindows[key] = w
+	}
+	reset := w.start.Add(rateLimitWindow)
+	if w.count >= limit {
+		return false, 0, reset
+	}
+	w.count++
+	return true, limit - w.count, reset
+}
+
+// RateLimit limits each user to the configured requests per minute for the named
+// limit, answering 429 with Retry-After once exceeded. Endpoints sharing a name share
+// the budget. The limit is read when the route is registered.
+func RateLimit(name string) gin.HandlerFunc {
+	limit := rateLimitPerMinute(name)
+	return func(c *gin.Context) {
+		if limit <= 0 {
+			c.Next()
+			return
+		}
+		now := time.Now()
+		allowed, remaining, reset := allowRequest(name+"|"+rateLimitKey(c), limit, now)
+		c.Header("X-RateLimit-Limit", strconv.Itoa(limit))
+		c.Header("X-RateLimit-Remaining", strconv.Itoa(remaining))
+		if !allowed {
+			retryAfter := int(reset.Sub(now).Seconds()) + 1
+			c.Header("Retry-After", strconv.Itoa(retryAfter))
+			logging.Warnf(c, "Rate limit %s exceeded for %s %s", name, c.Request.Method, c.FullPath())
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": fmt.Sprintf("Rate limit exceeded, retry in %d seconds", retryAfter)})
+			c.Abort()
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,83 @@
+//go:build test
+
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"time"
+
+	test_constants "ambient-code-backend/tests/constants"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Rate Limiting", Label(test_constants.LabelUnit, test_constants.LabelHandlers, test_constants.LabelMiddleware), func() {
+	var router *gin.Engine
+
+	BeforeEach(func() {
+		rateWindowsMu.Lock()
+		rateWindows = map[string]*rateWindow{}
+		rateWindowsMu.Unlock()
+		os.Setenv("RATE_LIMIT_SESSION_CREATE", "2")
+		DeferCleanup(os.Unsetenv, "RATE_LIMIT_SESSION_CREATE")
+
+		router = gin.New()
+		router.POST("/sessions", func(c *gin.Context) {
+			c.Set("userID", c.GetHeader("X-Test-User"))
+			c.Next()
+		}, RateLimit(RateLimitSessionCreate), func(c *gin.Context) {
+			c.Status(http.StatusCreated)
+		})
+	})
+
+	call := func(user string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/sessions", nil)
+		req.Header.Set("X-Test-User", user)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	It("Should answer 429 with Retry-After once a user exceeds the limit", func() {
+		Expect(call("alice").Code).To(Equal(http.StatusCreated))
+		w := call("alice")
+		Expect(w.Code).To(Equal(http.StatusCreated))
+		Expect(w.Header().Get("X-RateLimit-Remaining")).To(Equal("0"))
+
+		w = call("alice")
+		Expect(w.Code).To(Equal(http.StatusTooManyRequests))
+		retryAfter, err := strconv.Atoi(w.Header().Get("Retry-After"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(retryAfter).To(BeNumerically(">", 0))
+		Expect(retryAfter).To(BeNumerically("<=", 61))
+
+		// Other users have their own budget
+		Expect(call("bob").Code).To(Equal(http.StatusCreated))
+	})
+
+	It("Should start a new window after a minute", func() {
+		now := time.Now()
+		allowed, _, _ := allowRequest("k", 1, now)
+		Expect(allowed).To(BeTrue())
+		allowed, _, _ = allowRequest("k", 1, now.Add(30*time.Second))
+		Expect(allowed).To(BeFalse())
+		allowed, _, _ = allowRequest("k", 1, now.Add(rateLimitWindow))
+		Expect(allowed).To(BeTrue())
+	})
+
+	It("Should be disabled by a zero limit", func() {
+		os.Setenv("RATE_LIMIT_SESSION_CREATE", "0")
+		router = gin.New()
+		router.POST("/sessions", RateLimit(RateLimitSessionCreate), func(c *gin.Context) {
+			c.Status(http.StatusCreated)
+		})
+		for i := 0; i < 5; i++ {
+			Expect(call("alice").Code).To(Equal(http.StatusCreated))
+		}
+	})
+})
@@ -5,17 +5,16 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"log"
 	"net/http"
 	"strings"
 
 	"ambient-code-backend/git"
 	"ambient-code-backend/gitlab"
+	"ambient-code-backend/logging"
 	"ambient-code-backend/types"
 
 	"github.com/gin-gonic/gin"
 	authv1 "k8s.io/api/authorization/v1"
-	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 // Dependencies injected from main package
@@ -66,53 +65,53 @@ func AccessCheck(c *gin.Context) {
 	}
 	k8sClt := reqK8s
 
-	// Build the SSAR spec for RoleBinding management in the project namespace
-	ssar := &authv1.SelfSubjectAccessReview{
-		Spec: authv1.SelfSubjectAccessReviewSpec{
-			ResourceAttributes: &authv1.ResourceAttributes{
-				Group:     "rbac.authorization.k8s.io",
-				Resource:  "rolebindings",
-				Verb:      "create",
-				Namespace: projectName,
-			},
-		},
-	}
-
-	// Perform the review
-	res, err := k8sClt.AuthorizationV1().SelfSubjectAccessReviews().Create(c.Request.Context(), ssar, v1.CreateOptions{})
+	// Review RoleBinding management in the project namespace
+	allowed, err := CheckAccessForRequest(c, k8sClt, authv1.ResourceAttributes{
+		Group:     "rbac.authorization.k8s.io",
+		Resource:  "rolebindings",
+		Verb:      "create",
+		Namespace: projectName,
+	})
 	if err != nil {
-		log.Printf("SSAR failed for project %s: %v", projectName, err)
+		logging.Errorf(c, "SSAR failed for project %s: %v", projectName, err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to perform access review"})
 		return
 	}
 
 	role := "view"
-	if res.Status.Allowed {
+	if allowed {
 		// If update on ProjectSettings is allowed, treat as admin for this page
 		role = "admin"
 	} else {
 		// Optional: try a lesser check for create sessions to infer "edit"
-		editSSAR := &authv1.SelfSubjectAccessReview{
-			Spec: authv1.SelfSubjectAccessReviewSpec{
-				ResourceAttributes: &authv1.ResourceAttributes{
-					Group:     "vteam.ambient-code",
-					Resource:  "agenticsessions",
-					Verb:      "create",
-					Namespace: projectName,
-				},
-			},
-		}
-		res2, err2 := k8sClt.AuthorizationV1().SelfSubjectAccessReviews().Create(c.Request.Context(), editSSAR, v1.CreateOptions{})
-		if err2 == nil && res2.Status.Allowed {
+		allowed2, err2 := CheckAccessForRequest(c, k8sClt, authv1.ResourceAttributes{
+			Group:     "vteam.ambient-code",
+			Resource:  "agenticsessions",
+			Verb:      "create",
+			Namespace: projectName,
+		})
+		if err2 == nil && allowed2 {
 			role = "edit"
 		}
 	}
 
+	// Transcript access is granted separately from the role, e.g. not to run-only members
+	canReadTranscripts, err := CheckAccessForRequest(c, k8sClt, authv1.ResourceAttributes{
+		Group:       "vteam.ambient-code",
+		Resource:    "agenticsessions",
+		Subresource: TranscriptsSubresource,
+		Verb:        "get",
+		Namespace:   projectName,
+	})
+	if err != nil {
+		canReadTranscripts = false
+	}
+
 	c.JSON(http.StatusOK, gin.H{
-		"project":  projectName,
-		"allowed":  res.Status.Allowed,
-		"reason":   res.Status.Reason,
-		"userRole": role,
+		"project":            projectName,
+		"allowed":            allowed,
+		"userRole":           role,
+		"canReadTranscripts": canReadTranscripts,
 	})
 }
 
@@ -143,7 +142,7 @@ func ListUserForks(c *gin.Context) {
 	}
 	if err != nil {
 		// Log actual error for debugging, but return generic message to avoid leaking internal details
-		log.Printf("Failed to get GitHub token for project %s, user %s: %v", project, userID, err)
+		logging.Errorf(c, "Failed to get GitHub token for project %s, user %s: %v", project, userID, err)
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing token"})
 		return
 	}
@@ -235,7 +234,7 @@ func CreateUserFork(c *gin.Context) {
 	token, err := GetGitHubTokenRepo(c.Request.Context(), reqK8s, reqDyn, project, userIDStr)
 	if err != nil {
 		// Log actual error for debugging, but return generic message to avoid leaking internal details
-		log.Printf("Failed to get GitHub token for project %s, user %s: %v", project, userIDStr, err)
+		logging.Errorf(c, "Failed to get GitHub token for project %s, user %s: %v", project, userIDStr, err)
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing token"})
 		return
 	}
@@ -299,7 +298,7 @@ func GetRepoTree(c *gin.Context) {
 		token, err := git.GetGitLabToken(c.Request.Context(), reqK8s, project, userID.(string))
 		if err != nil {
 			// Log actual error for debugging, but return generic message to avoid leaking internal details
-			log.Printf("Failed to get GitLab token for project %s, user %s: %v", project, userID, err)
+			logging.Errorf(c, "Failed to get GitLab token for project %s, user %s: %v", project, userID, err)
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing token"})
 			return
 		}
@@ -332,7 +331,7 @@ func GetRepoTree(c *gin.Context) {
 		token, err := GetGitHubTokenRepo(c.Request.Context(), reqK8s, reqDyn, project, userID.(string))
 		if err != nil {
 			// Log actual error for debugging, but return generic message to avoid leaking internal details
-			log.Printf("Failed to get GitHub token for project %s, user %s: %v", project, userID, err)
+			logging.Errorf(c, "Failed to get GitHub token for project %s, user %s: %v", project, userID, err)
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing token"})
 			return
 		}
@@ -444,7 +443,7 @@ func ListRepoBranches(c *gin.Context) {
 		token, err := git.GetGitLabToken(c.Request.Context(), reqK8s, project, userID.(string))
 		if err != nil {
 			// Log actual error for debugging, but return generic message to avoid leaking internal details
-			log.Printf("Failed to get GitLab token for project %s, user %s: %v", project, userID, err)
+			logging.Errorf(c, "Failed to get GitLab token for project %s, user %s: %v", project, userID, err)
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing token"})
 			return
 		}
@@ -477,7 +476,7 @@ func ListRepoBranches(c *gin.Context) {
 		token, err := GetGitHubTokenRepo(c.Request.Context(), reqK8s, reqDyn, project, userID.(string))
 		if err != nil {
 			// Log actual error for debugging, but return generic message to avoid leaking internal details
-			log.Printf("Failed to get GitHub token for project %s, user %s: %v", project, userID, err)
+			logging.Errorf(c, "Failed to get GitHub token for project %s, user %s: %v", project, userID, err)
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing token"})
 			return
 		}
@@ -556,7 +555,7 @@ func GetRepoBlob(c *gin.Context) {
 		token, err := git.GetGitLabToken(c.Request.Context(), reqK8s, project, userID.(string))
 		if err != nil {
 			// Log actual error for debugging, but return generic message to avoid leaking internal details
-			log.Printf("Failed to get GitLab token for project %s, user %s: %v", project, userID, err)
+			logging.Errorf(c, "Failed to get GitLab token for project %s, user %s: %v", project, userID, err)
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing token"})
 			return
 		}
@@ -598,7 +597,7 @@ func GetRepoBlob(c *gin.Context) {
 		token, err := GetGitHubTokenRepo(c.Request.Context(), reqK8s, reqDyn, project, userID.(string))
 		if err != nil {
 			// Log actual error for debugging, but return generic message to avoid leaking internal details
-			log.Printf("Failed to get GitHub token for project %s, user %s: %v", project, userID, err)
+			logging.Errorf(c, "Failed to get GitHub token for project %s, user %s: %v", project, userID, err)
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing token"})
 			return
 		}
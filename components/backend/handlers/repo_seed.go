@@ -3,7 +3,6 @@ package handlers
 import (
 	"context"
 	"fmt"
-	"log"
 	"net/http"
 	"os"
 	"os/exec"
@@ -14,6 +13,7 @@ import (
 	"github.com/gin-gonic/gin"
 
 	"ambient-code-backend/git"
+	"ambient-code-backend/logging"
 	"ambient-code-backend/types"
 )
 
@@ -249,7 +249,7 @@ func GetRepoSeedStatus(c *gin.Context) {
 	}
 	defer func() {
 		if err := os.RemoveAll(tmpDir); err != nil {
-			log.Printf("Warning: failed to cleanup temp directory %s: %v", tmpDir, err)
+			logging.Warnf(c, "Failed to cleanup temp directory %s: %v", tmpDir, err)
 		}
 	}()
 
@@ -260,7 +260,7 @@ func GetRepoSeedStatus(c *gin.Context) {
 		token, err = git.GetGitLabToken(c.Request.Context(), reqK8s, project, userID.(string))
 		if err != nil {
 			// Log actual error for debugging, but return generic message to avoid leaking internal details
-			log.Printf("Failed to get GitLab token for project %s, user %s: %v", project, userID, err)
+			logging.Errorf(c, "Failed to get GitLab token for project %s, user %s: %v", project, userID, err)
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing token"})
 			return
 		}
@@ -268,7 +268,7 @@ func GetRepoSeedStatus(c *gin.Context) {
 		token, err = GetGitHubTokenRepo(c.Request.Context(), reqK8s, reqDyn, project, userID.(string))
 		if err != nil {
 			// Log actual error for debugging, but return generic message to avoid leaking internal details
-			log.Printf("Failed to get GitHub token for project %s, user %s: %v", project, userID, err)
+			logging.Errorf(c, "Failed to get GitHub token for project %s, user %s: %v", project, userID, err)
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing token"})
 			return
 		}
@@ -335,7 +335,7 @@ func SeedRepositoryEndpoint(c *gin.Context) {
 		token, err = git.GetGitLabToken(c.Request.Context(), reqK8s, project, userID.(string))
 		if err != nil {
 			// Log actual error for debugging, but return generic message to avoid leaking internal details
-			log.Printf("Failed to get GitLab token for project %s, user %s: %v", project, userID, err)
+			logging.Errorf(c, "Failed to get GitLab token for project %s, user %s: %v", project, userID, err)
 			c.JSON(http.StatusUnauthorized, gin.H{
 				"error":       "Invalid or missing token",
 				"remediation": "Connect your GitLab account via /auth/gitlab/connect",
@@ -346,7 +346,7 @@ func SeedRepositoryEndpoint(c *gin.Context) {
 		token, err = GetGitHubTokenRepo(c.Request.Context(), reqK8s, reqDyn, project, userID.(string))
 		if err != nil {
 			// Log actual error for debugging, but return generic message to avoid leaking internal details
-			log.Printf("Failed to get GitHub token for project %s, user %s: %v", project, userID, err)
+			logging.Errorf(c, "Failed to get GitHub token for project %s, user %s: %v", project, userID, err)
 			c.JSON(http.StatusUnauthorized, gin.H{
 				"error":       "Invalid or missing token",
 				"remediation": "Ensure GitHub App is installed or configure GIT_TOKEN in project runner secret",
@@ -366,7 +366,7 @@ func SeedRepositoryEndpoint(c *gin.Context) {
 	}
 	defer func() {
 		if err := os.RemoveAll(tmpDir); err != nil {
-			log.Printf("Warning: failed to cleanup temp directory %s: %v", tmpDir, err)
+			logging.Warnf(c, "Failed to cleanup temp directory %s: %v", tmpDir, err)
 		}
 	}()
 
@@ -171,9 +171,10 @@ var _ = Describe("Repo Handler >", Label(test_constants.LabelUnit, test_constant
 
 				httpUtils.AssertHTTPStatus(http.StatusOK)
 				httpUtils.AssertJSONContains(map[string]interface{}{
-					"project":  "test-project",
-					"allowed":  false,
-					"userRole": "edit",
+					"project":            "test-project",
+					"allowed":            false,
+					"userRole":           "edit",
+					"canReadTranscripts": false,
 				})
 			})
 
@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"ambient-code-backend/logging"
+	"ambient-code-backend/types"
+	"ambient-code-backend/workpool"
+
+	"github.com/anthropics/anthropic-sdk-go"
+)
+
+const (
+	// Timeout for the summary model call
+	runSummaryAPITimeout = 30 * time.Second
+	// maxRunSummaryItems caps the decisions and open questions kept from the model
+	maxRunSummaryItems = 5
+)
+
+// runSummaryPool bounds concurrent run summarization. Summaries are a convenience, so
+// a burst drops the overflow.
+var runSummaryPool = workpool.New("run-summary", 2, 64, workpool.Drop)
+
+// SummarizeRunAsync generates a summary of a completed run from its transcript and
+// the files it changed and hands it to store for the run index. The summary retells
+// the conversation, so it is kept out of the AgenticSession, which anyone who can get
+// sessions can read. Does nothing when the run-summaries flag is off; fails silently
+// on error, including when the pool is full.
+func SummarizeRunAsync(projectName, sessionName, runID, transcript string, filesChanged []string, store func(types.RunSummary)) {
+	runSummaryPool.Submit(sessionName, func() {
+		ctx, cancel := context.WithTimeout(context.Background(), runSummaryAPITimeout)
+		defer cancel()
+		if !FeatureEnabled(ctx, projectName, FeatureRunSummaries) {
+			return
+		}
+		summary, err := generateRunSummary(ctx, projectName, transcript, filesChanged)
+		if err != nil {
+			logging.Errorf(ctx, "RunSummary: Failed to summarize run %s of %s/%s: %v", runID, projectName, sessionName, err)
+			return
+		}
+		summary.RunID = runID
+		store(summary)
+		logging.Infof(ctx, "RunSummary: Summarized run %s of %s/%s", runID, projectName, sessionName)
+	})
+}
+
+// generateRunSummary asks the project's model for a summary of the transcript
+func generateRunSummary(ctx context.Context, projectName, transcript string, filesChanged []string) (types.RunSummary, error) {
+	text, err := completeWithHaiku(ctx, projectName, buildRunSummaryPrompt(transcript, filesChanged), 600)
+	if err != nil {
+		return types.RunSummary{}, err
+	}
+	summary, err := parseRunSummary(text)
+	if err != nil {
+		return types.RunSummary{}, err
+	}
+	summary.FilesChanged = filesChanged
+	summary.GeneratedAt = time.Now().UTC().Format(time.RFC3339)
+	return summary, nil
+}
+
+// completeWithHaiku sends prompt to the Haiku model with the project's credentials and
+// returns the text of the answer
+func completeWithHaiku(ctx context.Context, projectName, prompt string, maxTokens int64) (string, error) {
+	client, isVertex, err := getAnthropicClient(ctx, projectName)
+	if err != nil {
+		return "", fmt.Errorf("failed to get Anthropic client: %w", err)
+	}
+	modelName := haiku3Model
+	if isVertex {
+		modelName = haiku3ModelVertex
+	}
+
+	message, err := client.Messages.New(ctx, anthropic.MessageNewParams{
+		Model:     anthropic.Model(modelName),
+		MaxTokens: maxTokens,
+		Messages: []anthropic.MessageParam{
+			anthropic.NewUserMessage(anthropic.NewTextBlock(prompt)),
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("API call failed: %w", err)
+	}
+	for _, block := range message.Content {
+		if block.Type == "text" {
+			return block.Text, nil
+		}
+	}
+	return "", fmt.Errorf("no text content in response")
+}
+
+// buildRunSummaryPrompt constructs the prompt for run summarization
+func buildRunSummaryPrompt(transcript string, filesChanged []string) string {
+	files := "none"
+	if len(filesChanged) > 0 {
+		files = strings.Join(filesChanged, ", ")
+	}
+	return fmt.Sprintf(`Summarize what this run of an AI coding session accomplished, for someone browsing old sessions.
+Files changed: %s
+
+Transcript:
+%s
+
+Return ONLY a JSON object, no explanation:
+{"summary": "<1-3 sentences>", "decisions": ["<decision made>"], "openQuestions": ["<question left open>"]}
+Use empty arrays when there are no decisions or open questions.`, files, transcript)
+}
+
+// parseRunSummary reads the model's JSON answer, tolerating text around the object
+func parseRunSummary(text string) (types.RunSummary, error) {
+	start, end := strings.Index(text, "{"), strings.LastIndex(text, "}")
+	if start < 0 || end < start {
+		return types.RunSummary{}, fmt.Errorf("no JSON object in response")
+	}
+	var summary types.RunSummary
+	if err := json.Unmarshal([]byte(text[start:end+1]), &summary); err != nil {
+		return types.RunSummary{}, fmt.Errorf("invalid summary JSON: %w", err)
+	}
+	summary.Summary = sanitizeDisplayName(summary.Summary)
+	if summary.Summary == "" {
+		return types.RunSummary{}, fmt.Errorf("empty summary")
+	}
+	if len(summary.Decisions) > maxRunSummaryItems {
+		summary.Decisions = summary.Decisions[:maxRunSummaryItems]
+	}
+	if len(summary.OpenQuestions) > maxRunSummaryItems {
+		summary.OpenQuestions = summary.OpenQuestions[:maxRunSummaryItems]
+	}
+	return summary, nil
+}
+
+// LatestRunSummary returns the summary of the session's latest summarized run, or nil.
+// Set from main to websocket.LatestRunSummary.
+var LatestRunSummary func(sessionName string) *types.RunSummary
+
+// attachRunSummary adds the session's latest run summary to its status as
+// lastRunSummary. The summary retells the conversation, so callers add it only for
+// those allowed to read transcripts.
+func attachRunSummary(session *types.AgenticSession, sessionName string) {
+	if LatestRunSummary == nil || session.Status == nil {
+		return
+	}
+	session.Status.LastRunSummary = LatestRunSummary(sessionName)
+}
@@ -0,0 +1,102 @@
+//go:build test
+
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+
+	test_constants "ambient-code-backend/tests/constants"
+	"ambient-code-backend/types"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	authv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+var _ = Describe("Run Summaries", Label(test_constants.LabelUnit, test_constants.LabelHandlers), func() {
+	gvr := schema.GroupVersionResource{Group: "vteam.ambient-code", Version: "v1alpha1", Resource: "agenticsessions"}
+
+	var (
+		originalDynamicClient dynamic.Interface
+		originalGVR           func() schema.GroupVersionResource
+	)
+
+	BeforeEach(func() {
+		originalDynamicClient, originalGVR = DynamicClient, GetAgenticSessionV1Alpha1Resource
+		GetAgenticSessionV1Alpha1Resource = func() schema.GroupVersionResource { return gvr }
+	})
+
+	AfterEach(func() {
+		DynamicClient, GetAgenticSessionV1Alpha1Resource = originalDynamicClient, originalGVR
+	})
+
+	It("Should parse the model's JSON answer", func() {
+		summary, err := parseRunSummary("Here you go:\n" +
+			`{"summary": "Fixed the flaky login test.", "decisions": ["Retry on 503", "a", "b", "c", "d", "e"], "openQuestions": []}`)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(summary.Summary).To(Equal("Fixed the flaky login test."))
+		Expect(summary.Decisions).To(HaveLen(maxRunSummaryItems))
+		Expect(summary.OpenQuestions).To(BeEmpty())
+
+		_, err = parseRunSummary("I could not summarize this run")
+		Expect(err).To(HaveOccurred())
+		_, err = parseRunSummary(`{"summary": "  "}`)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("Should return the summary only to callers with transcript access", func() {
+		originalK8sClientMw, originalLatest := K8sClientMw, LatestRunSummary
+		defer func() { K8sClientMw, LatestRunSummary = originalK8sClientMw, originalLatest }()
+		LatestRunSummary = func(sessionName string) *types.RunSummary {
+			return &types.RunSummary{RunID: "r1", Summary: "Rotated the API keys."}
+		}
+		mw := fake.NewSimpleClientset()
+		mw.PrependReactor("create", "selfsubjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			ssar := action.(k8stesting.CreateAction).GetObject().(*authv1.SelfSubjectAccessReview)
+			// A run-only member: may get sessions, not their transcripts
+			allowed := ssar.Spec.ResourceAttributes.Subresource != TranscriptsSubresource
+			return true, &authv1.SelfSubjectAccessReview{Status: authv1.SubjectAccessReviewStatus{Allowed: allowed}}, nil
+		})
+		K8sClientMw = mw
+		DynamicClient = dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+			map[schema.GroupVersionResource]string{gvr: "AgenticSessionList"},
+			&unstructured.Unstructured{Object: map[string]interface{}{
+				"apiVersion": "vteam.ambient-code/v1alpha1",
+				"kind":       "AgenticSession",
+				"metadata":   map[string]interface{}{"name": "s1", "namespace": "team-a"},
+				"status":     map[string]interface{}{"phase": "Completed"},
+			}},
+		)
+		gin.SetMode(gin.TestMode)
+
+		get := func(handler gin.HandlerFunc, token string) string {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+			c.Request.Header.Set("Authorization", "Bearer "+token)
+			c.Set("project", "team-a")
+			c.Params = gin.Params{{Key: "sessionName", Value: "s1"}}
+			handler(c)
+			Expect(w.Code).To(Equal(http.StatusOK))
+			return w.Body.String()
+		}
+
+		Expect(get(GetSession, "run-only-token")).NotTo(ContainSubstring("lastRunSummary"))
+		Expect(get(ListSessions, "run-only-token")).NotTo(ContainSubstring("lastRunSummary"))
+
+		mw.PrependReactor("create", "selfsubjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			return true, &authv1.SelfSubjectAccessReview{Status: authv1.SubjectAccessReviewStatus{Allowed: true}}, nil
+		})
+		Expect(get(GetSession, "viewer-token")).To(ContainSubstring("Rotated the API keys."))
+		Expect(get(ListSessions, "viewer-token")).To(ContainSubstring("Rotated the API keys."))
+	})
+})
@@ -0,0 +1,279 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"ambient-code-backend/config"
+	"ambient-code-backend/logging"
+
+	"github.com/gin-gonic/gin"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// Runner clusters are additional clusters the operator can place session runners on.
+// Each is registered as a Secret in the backend namespace holding its kubeconfig; the
+// operator records a session's placement in the runner-cluster annotation.
+// IMPORTANT: Keep in sync with operator (internal/handlers/runner_clusters.go)
+const (
+	runnerClusterLabel              = "ambient-code.io/runner-cluster"
+	runnerClusterSecretPrefix       = "runner-cluster-"
+	runnerClusterKubeconfigKey      = "kubeconfig"
+	runnerClusterMaxSessionsKey     = "ambient-code.io/max-sessions"
+	runnerClusterBackendURLKey      = "ambient-code.io/backend-url"
+	runnerClusterRunnerURLKey       = "ambient-code.io/runner-url"
+	runnerClusterDefaultMaxSessions = 20
+	localRunnerCluster              = "local"
+)
+
+// RunnerCluster is a registered runner cluster. The kubeconfig is never returned.
+type RunnerCluster struct {
+	Name string `json:"name"`
+	// MaxSessions is how many active sessions are placed here before it counts as full
+	MaxSessions int `json:"maxSessions"`
+	// BackendURL is the backend API as reachable from the cluster's runners
+	BackendURL string `json:"backendUrl"`
+	// RunnerURL is the template for a runner's AG-UI endpoint, with {project} and {session}
+	RunnerURL      string    `json:"runnerUrl"`
+	ActiveSessions int       `json:"activeSessions"`
+	CreatedAt      time.Time `json:"createdAt"`
+}
+
+// runnerURLCacheTTL bounds how long a cluster's runner URL template is reused by the
+// AG-UI proxy; re-registering a cluster applies within it
+const runnerURLCacheTTL = 30 * time.Second
+
+type runnerURLCacheEntry struct {
+	template string
+	expires  time.Time
+}
+
+var (
+	runnerURLCacheMu sync.Mutex
+	runnerURLCache   = map[string]runnerURLCacheEntry{}
+)
+
+// RunnerEndpoint returns the base URL, ending in "/", of the session's runner: its
+// Service in this cluster, or the runner URL of the cluster the session is placed on
+func RunnerEndpoint(ctx context.Context, project, session string) (string, error) {
+	local := fmt.Sprintf("http://session-%s.%s.svc.cluster.local:%d/", session, project, config.Current().RunnerPort)
+	obj, err := GetCachedSession(ctx, project, session)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return local, nil
+		}
+		return "", err
+	}
+	cluster := obj.GetAnnotations()[runnerClusterLabel]
+	if cluster == "" || cluster == localRunnerCluster {
+		return local, nil
+	}
+
+	template, err := runnerURLTemplate(ctx, cluster)
+	if err != nil {
+		return "", err
+	}
+	u := strings.NewReplacer("{project}", project, "{session}", session).Replace(template)
+	return strings.TrimSuffix(u, "/") + "/", nil
+}
+
+func runnerURLTemplate(ctx context.Context, cluster string) (string, error) {
+	runnerURLCacheMu.Lock()
+	entry, ok := runnerURLCache[cluster]
+	runnerURLCacheMu.Unlock()
+	if ok && time.Now().Before(entry.expires) {
+		return entry.template, nil
+	}
+
+	secret, err := K8sClient.CoreV1().Secrets(Namespace).Get(ctx, runnerClusterSecretPrefix+cluster, v1.GetOptions{})
+	if err != nil {
+		return "", fmt.Errorf("failed to look up runner cluster %s: %w", cluster, err)
+	}
+	template := secret.Annotations[runnerClusterRunnerURLKey]
+	if template == "" {
+		return "", fmt.Errorf("runner cluster %s has no runner URL", cluster)
+	}
+	runnerURLCacheMu.Lock()
+	runnerURLCache[cluster] = runnerURLCacheEntry{template: template, expires: time.Now().Add(runnerURLCacheTTL)}
+	runnerURLCacheMu.Unlock()
+	return template, nil
+}
+
+func runnerClusterFromSecret(s *corev1.Secret) RunnerCluster {
+	maxSessions := runnerClusterDefaultMaxSessions
+	if v, err := strconv.Atoi(s.Annotations[runnerClusterMaxSessionsKey]); err == nil {
+		maxSessions = v
+	}
+	return RunnerCluster{
+		Name:        s.Labels[runnerClusterLabel],
+		MaxSessions: maxSessions,
+		BackendURL:  s.Annotations[runnerClusterBackendURLKey],
+		RunnerURL:   s.Annotations[runnerClusterRunnerURLKey],
+		CreatedAt:   s.CreationTimestamp.Time,
+	}
+}
+
+// activeSessionsByRunnerCluster counts Pending, Creating and Running sessions per
+// runner cluster, as the operator does when placing sessions
+func activeSessionsByRunnerCluster(ctx context.Context) (map[string]int, error) {
+	list, err := DynamicClient.Resource(GetAgenticSessionV1Alpha1Resource()).Namespace("").List(ctx, v1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	load := map[string]int{}
+	for _, item := range list.Items {
+		phase, _, _ := unstructured.NestedString(item.Object, "status", "phase")
+		if phase != "Pending" && phase != "Creating" && phase != "Running" {
+			continue
+		}
+		name := item.GetAnnotations()[runnerClusterLabel]
+		if name == "" {
+			name = localRunnerCluster
+		}
+		load[name]++
+	}
+	return load, nil
+}
+
+// ListRunnerClusters handles GET /api/admin/runner-clusters
+func ListRunnerClusters(c *gin.Context) {
+	ctx := c.Request.Context()
+	secrets, err := K8sClient.CoreV1().Secrets(Namespace).List(ctx, v1.ListOptions{LabelSelector: runnerClusterLabel})
+	if err != nil {
+		logging.Errorf(c, "ListRunnerClusters: failed to list runner clusters: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list runner clusters"})
+		return
+	}
+	load, err := activeSessionsByRunnerCluster(ctx)
+	if err != nil {
+		logging.Warnf(c, "ListRunnerClusters: failed to count active sessions: %v", err)
+	}
+
+	clusters := []RunnerCluster{}
+	for i := range secrets.Items {
+		rc := runnerClusterFromSecret(&secrets.Items[i])
+		rc.ActiveSessions = load[rc.Name]
+		clusters = append(clusters, rc)
+	}
+	sort.Slice(clusters, func(a, b int) bool { return clusters[a].Name < clusters[b].Name })
+	c.JSON(http.StatusOK, gin.H{"items": clusters, "localActiveSessions": load[localRunnerCluster]})
+}
+
+// RegisterRunnerCluster handles PUT /api/admin/runner-clusters/:clusterName, creating
+// or replacing the registration
+func RegisterRunnerCluster(c *gin.Context) {
+	name := c.Param("clusterName")
+	if !isValidKubernetesName(name) || name == localRunnerCluster || len(runnerClusterSecretPrefix+name) > 63 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid cluster name"})
+		return
+	}
+
+	var req struct {
+		Kubeconfig  string `json:"kubeconfig" binding:"required"`
+		MaxSessions *int   `json:"maxSessions"`
+		BackendURL  string `json:"backendUrl" binding:"required"`
+		RunnerURL   string `json:"runnerUrl" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if _, err := clientcmd.RESTConfigFromKubeConfig([]byte(req.Kubeconfig)); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Invalid kubeconfig: %v", err)})
+		return
+	}
+	maxSessions := runnerClusterDefaultMaxSessions
+	if req.MaxSessions != nil {
+		if *req.MaxSessions < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "maxSessions must not be negative"})
+			return
+		}
+		maxSessions = *req.MaxSessions
+	}
+	for field, raw := range map[string]string{"backendUrl": req.BackendURL, "runnerUrl": req.RunnerURL} {
+		u, err := url.Parse(strings.NewReplacer("{project}", "p", "{session}", "s").Replace(raw))
+		if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("%s must be an http(s) URL", field)})
+			return
+		}
+	}
+	if !strings.Contains(req.RunnerURL, "{session}") {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "runnerUrl must contain {session}"})
+		return
+	}
+
+	secret := &corev1.Secret{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      runnerClusterSecretPrefix + name,
+			Namespace: Namespace,
+			Labels:    map[string]string{"app": "ambient-code", runnerClusterLabel: name},
+			Annotations: map[string]string{
+				runnerClusterMaxSessionsKey: strconv.Itoa(maxSessions),
+				runnerClusterBackendURLKey:  req.BackendURL,
+				runnerClusterRunnerURLKey:   req.RunnerURL,
+			},
+		},
+		Type: corev1.SecretTypeOpaque,
+		Data: map[string][]byte{runnerClusterKubeconfigKey: []byte(req.Kubeconfig)},
+	}
+	ctx := c.Request.Context()
+	secrets := K8sClient.CoreV1().Secrets(Namespace)
+	created, err := secrets.Create(ctx, secret, v1.CreateOptions{})
+	status := http.StatusCreated
+	if errors.IsAlreadyExists(err) {
+		var existing *corev1.Secret
+		if existing, err = secrets.Get(ctx, secret.Name, v1.GetOptions{}); err == nil {
+			secret.ResourceVersion = existing.ResourceVersion
+			created, err = secrets.Update(ctx, secret, v1.UpdateOptions{})
+			status = http.StatusOK
+		}
+	}
+	if err != nil {
+		logging.Errorf(c, "RegisterRunnerCluster: failed to store runner cluster %s: %v", name, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to register runner cluster"})
+		return
+	}
+
+	runnerURLCacheMu.Lock()
+	delete(runnerURLCache, name)
+	runnerURLCacheMu.Unlock()
+	logging.Infof(c, "RegisterRunnerCluster: registered runner cluster %s (maxSessions=%d)", name, maxSessions)
+	c.JSON(status, runnerClusterFromSecret(created))
+}
+
+// DeleteRunnerCluster handles DELETE /api/admin/runner-clusters/:clusterName. Sessions
+// placed there are placed again the next time they start.
+func DeleteRunnerCluster(c *gin.Context) {
+	name := c.Param("clusterName")
+	if !isValidKubernetesName(name) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid cluster name"})
+		return
+	}
+	err := K8sClient.CoreV1().Secrets(Namespace).Delete(c.Request.Context(), runnerClusterSecretPrefix+name, v1.DeleteOptions{})
+	if errors.IsNotFound(err) {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Runner cluster not found"})
+		return
+	}
+	if err != nil {
+		logging.Errorf(c, "DeleteRunnerCluster: failed to delete runner cluster %s: %v", name, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete runner cluster"})
+		return
+	}
+
+	runnerURLCacheMu.Lock()
+	delete(runnerURLCache, name)
+	runnerURLCacheMu.Unlock()
+	logging.Infof(c, "DeleteRunnerCluster: removed runner cluster %s", name)
+	c.Status(http.StatusNoContent)
+}
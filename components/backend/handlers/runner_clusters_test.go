@@ -0,0 +1,182 @@
+//go:build test
+
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	test_constants "ambient-code-backend/tests/constants"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+var _ = Describe("Runner Clusters", Label(test_constants.LabelUnit, test_constants.LabelHandlers), func() {
+	gvr := schema.GroupVersionResource{Group: "vteam.ambient-code", Version: "v1alpha1", Resource: "agenticsessions"}
+
+	const kubeconfig = `apiVersion: v1
+kind: Config
+clusters:
+- name: east
+  cluster:
+    server: https://api.east.example.com:6443
+users:
+- name: runner
+  user:
+    token: abc
+contexts:
+- name: east
+  context:
+    cluster: east
+    user: runner
+current-context: east
+`
+
+	var (
+		originalK8sClient     kubernetes.Interface
+		originalDynamicClient dynamic.Interface
+		originalGVR           func() schema.GroupVersionResource
+		originalInformer      informers.GenericInformer
+		originalNamespace     string
+		router                *gin.Engine
+	)
+
+	newSession := func(name, cluster string) *unstructured.Unstructured {
+		obj := &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "vteam.ambient-code/v1alpha1",
+			"kind":       "AgenticSession",
+			"metadata":   map[string]interface{}{"name": name, "namespace": "team-a"},
+			"status":     map[string]interface{}{"phase": "Running"},
+		}}
+		if cluster != "" {
+			obj.SetAnnotations(map[string]string{runnerClusterLabel: cluster})
+		}
+		return obj
+	}
+
+	register := func(name, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPut, "/admin/runner-clusters/"+name, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	// body is a valid registration with fields replaced by overrides
+	body := func(overrides map[string]interface{}) string {
+		m := map[string]interface{}{
+			"kubeconfig": kubeconfig,
+			"backendUrl": "https://ambient.example.com/api",
+			"runnerUrl":  "https://runners.east.example.com/{project}/{session}",
+		}
+		for k, v := range overrides {
+			m[k] = v
+		}
+		b, _ := json.Marshal(m)
+		return string(b)
+	}
+
+	BeforeEach(func() {
+		originalK8sClient, originalDynamicClient, originalNamespace = K8sClient, DynamicClient, Namespace
+		originalGVR, originalInformer = GetAgenticSessionV1Alpha1Resource, sessionInformer
+
+		Namespace = "ambient-code"
+		K8sClient = fake.NewSimpleClientset()
+		DynamicClient = dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+			map[schema.GroupVersionResource]string{gvr: "AgenticSessionList"},
+			newSession("s1", ""),
+			newSession("s2", "east"),
+		)
+		GetAgenticSessionV1Alpha1Resource = func() schema.GroupVersionResource { return gvr }
+		sessionInformer = nil
+		runnerURLCacheMu.Lock()
+		runnerURLCache = map[string]runnerURLCacheEntry{}
+		runnerURLCacheMu.Unlock()
+
+		gin.SetMode(gin.TestMode)
+		router = gin.New()
+		router.GET("/admin/runner-clusters", ListRunnerClusters)
+		router.PUT("/admin/runner-clusters/:clusterName", RegisterRunnerCluster)
+		router.DELETE("/admin/runner-clusters/:clusterName", DeleteRunnerCluster)
+	})
+
+	AfterEach(func() {
+		K8sClient, DynamicClient, Namespace = originalK8sClient, originalDynamicClient, originalNamespace
+		GetAgenticSessionV1Alpha1Resource, sessionInformer = originalGVR, originalInformer
+	})
+
+	It("Should reject invalid registrations", func() {
+		Expect(register("local", body(nil)).Code).To(Equal(http.StatusBadRequest))
+		Expect(register("East_1", body(nil)).Code).To(Equal(http.StatusBadRequest))
+		Expect(register("east", body(map[string]interface{}{"kubeconfig": "not: [yaml"})).Code).To(Equal(http.StatusBadRequest))
+		Expect(register("east", body(map[string]interface{}{"backendUrl": "ambient.example.com"})).Code).To(Equal(http.StatusBadRequest))
+		Expect(register("east", body(map[string]interface{}{"runnerUrl": "https://runners.east.example.com/"})).Code).To(Equal(http.StatusBadRequest))
+		Expect(register("east", body(map[string]interface{}{"maxSessions": -1})).Code).To(Equal(http.StatusBadRequest))
+
+		w := register("east", body(map[string]interface{}{"maxSessions": 5}))
+		Expect(w.Code).To(Equal(http.StatusCreated))
+		Expect(w.Body.String()).NotTo(ContainSubstring("abc"))
+		Expect(register("east", body(nil)).Code).To(Equal(http.StatusOK))
+	})
+
+	It("Should list clusters with their active sessions", func() {
+		Expect(register("east", body(nil)).Code).To(Equal(http.StatusCreated))
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/runner-clusters", nil))
+		Expect(w.Code).To(Equal(http.StatusOK))
+
+		var resp struct {
+			Items               []RunnerCluster `json:"items"`
+			LocalActiveSessions int             `json:"localActiveSessions"`
+		}
+		Expect(json.Unmarshal(w.Body.Bytes(), &resp)).To(Succeed())
+		Expect(resp.Items).To(HaveLen(1))
+		Expect(resp.Items[0].Name).To(Equal("east"))
+		Expect(resp.Items[0].MaxSessions).To(Equal(runnerClusterDefaultMaxSessions))
+		Expect(resp.Items[0].ActiveSessions).To(Equal(1))
+		Expect(resp.LocalActiveSessions).To(Equal(1))
+
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/admin/runner-clusters/east", nil))
+		Expect(w.Code).To(Equal(http.StatusNoContent))
+		w = httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/admin/runner-clusters/east", nil))
+		Expect(w.Code).To(Equal(http.StatusNotFound))
+	})
+
+	It("Should resolve runner endpoints by session placement", func() {
+		ctx := context.Background()
+
+		local, err := RunnerEndpoint(ctx, "team-a", "s1")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(local).To(Equal("http://session-s1.team-a.svc.cluster.local:8001/"))
+
+		// A session placed on an unregistered cluster can't be reached
+		_, err = RunnerEndpoint(ctx, "team-a", "s2")
+		Expect(err).To(HaveOccurred())
+
+		Expect(register("east", body(nil)).Code).To(Equal(http.StatusCreated))
+		remote, err := RunnerEndpoint(ctx, "team-a", "s2")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(remote).To(Equal("https://runners.east.example.com/team-a/s2/"))
+
+		// Deleted sessions resolve to this cluster
+		gone, err := RunnerEndpoint(ctx, "team-a", "gone")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(gone).To(HavePrefix("http://session-gone.team-a.svc.cluster.local"))
+	})
+})
@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"ambient-code-backend/logging"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+)
+
+// IsRunnerImageAllowed reports whether image matches any entry in allowlist.
+// IMPORTANT: Keep matching rules in sync with operator (internal/handlers/runner_image.go)
+//
+// Entry forms:
+//   - "quay.io/ambient_code/" or "quay.io/ambient_code/*": any image under that prefix
+//   - "quay.io/ambient_code/runner": any tag or digest of that repository
+//   - "quay.io/ambient_code/runner:v1.2": exact match only
+func IsRunnerImageAllowed(image string, allowlist []string) bool {
+	image = strings.TrimSpace(image)
+	if image == "" || strings.ContainsAny(image, " \t\n") {
+		return false
+	}
+	for _, entry := range allowlist {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if strings.HasSuffix(entry, "*") {
+			if strings.HasPrefix(image, strings.TrimSuffix(entry, "*")) {
+				return true
+			}
+			continue
+		}
+		if strings.HasSuffix(entry, "/") {
+			if strings.HasPrefix(image, entry) {
+				return true
+			}
+			continue
+		}
+		if image == entry {
+			return true
+		}
+		if !imageHasTagOrDigest(entry) &&
+			(strings.HasPrefix(image, entry+":") || strings.HasPrefix(image, entry+"@")) {
+			return true
+		}
+	}
+	return false
+}
+
+// imageHasTagOrDigest reports whether ref pins a tag or digest.
+// A colon before the last slash is a registry port, not a tag.
+func imageHasTagOrDigest(ref string) bool {
+	if strings.Contains(ref, "@") {
+		return true
+	}
+	return strings.Contains(ref[strings.LastIndex(ref, "/")+1:], ":")
+}
+
+// validateRunnerImage checks a requested runner image against the project's
+// ProjectSettings runnerImageAllowlist. An empty or missing allowlist rejects all overrides.
+func validateRunnerImage(ctx context.Context, dynClient dynamic.Interface, project, image string) error {
+	allowlist, err := getRunnerImageAllowlist(ctx, dynClient, project)
+	if err != nil {
+		logging.Errorf(ctx, "Failed to read runner image allowlist for project %s: %v", project, err)
+		return fmt.Errorf("unable to verify runner image against project allowlist")
+	}
+	if len(allowlist) == 0 {
+		return fmt.Errorf("custom runner images are not enabled for this project")
+	}
+	if !IsRunnerImageAllowed(image, allowlist) {
+		return fmt.Errorf("runner image %q is not in the project allowlist", image)
+	}
+	return nil
+}
+
+func getRunnerImageAllowlist(ctx context.Context, dynClient dynamic.Interface, project string) ([]string, error) {
+	obj, err := dynClient.Resource(GetProjectSettingsResource()).Namespace(project).Get(ctx, "projectsettings", v1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	allowlist, _, err := unstructured.NestedStringSlice(obj.Object, "spec", "runnerImageAllowlist")
+	if err != nil {
+		return nil, err
+	}
+	return allowlist, nil
+}
@@ -0,0 +1,30 @@
+//go:build test
+
+package handlers
+
+import (
+	test_constants "ambient-code-backend/tests/constants"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("Runner Image Allowlist", Label(test_constants.LabelUnit, test_constants.LabelHandlers, test_constants.LabelSessions), func() {
+	DescribeTable("IsRunnerImageAllowed",
+		func(image string, allowlist []string, expected bool) {
+			Expect(IsRunnerImageAllowed(image, allowlist)).To(Equal(expected))
+		},
+		Entry("empty allowlist rejects", "quay.io/org/runner:v1", nil, false),
+		Entry("empty image rejects", "", []string{"quay.io/"}, false),
+		Entry("registry prefix matches", "quay.io/org/runner:v1", []string{"quay.io/"}, true),
+		Entry("wildcard prefix matches", "quay.io/org/runner-gpu:v1", []string{"quay.io/org/runner*"}, true),
+		Entry("prefix does not match other registry", "docker.io/org/runner:v1", []string{"quay.io/"}, false),
+		Entry("repository matches any tag", "quay.io/org/runner:v2", []string{"quay.io/org/runner"}, true),
+		Entry("repository matches digest", "quay.io/org/runner@sha256:abc", []string{"quay.io/org/runner"}, true),
+		Entry("repository does not match sibling repository", "quay.io/org/runner-evil:v1", []string{"quay.io/org/runner"}, false),
+		Entry("pinned tag matches exactly", "quay.io/org/runner:v1", []string{"quay.io/org/runner:v1"}, true),
+		Entry("pinned tag rejects other tag", "quay.io/org/runner:v2", []string{"quay.io/org/runner:v1"}, false),
+		Entry("registry port is not a tag", "registry:5000/runner:v1", []string{"registry:5000/runner"}, true),
+		Entry("whitespace in image rejects", "quay.io/org/runner:v1 --privileged", []string{"quay.io/"}, false),
+	)
+})
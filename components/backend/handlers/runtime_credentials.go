@@ -4,17 +4,17 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
 	"net/http"
 	"net/url"
 	"strings"
 	"time"
 
 	"ambient-code-backend/git"
+	"ambient-code-backend/logging"
+	"ambient-code-backend/metrics"
 
 	"github.com/gin-gonic/gin"
 	"k8s.io/apimachinery/pkg/api/errors"
-	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/client-go/kubernetes"
 )
@@ -22,25 +22,26 @@ import (
 // GetGitHubTokenForSession handles GET /api/projects/:project/agentic-sessions/:session/credentials/github
 // Returns PAT (priority 1) or freshly minted GitHub App token (priority 2)
 func GetGitHubTokenForSession(c *gin.Context) {
+	defer observeCredentialFetch(c, "github")
+
 	project := c.Param("projectName")
 	session := c.Param("sessionName")
 
 	// Get user-scoped K8s client
-	reqK8s, reqDyn := GetK8sClientsForRequest(c)
+	reqK8s, _ := GetK8sClientsForRequest(c)
 	if reqK8s == nil {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing token"})
 		return
 	}
 
-	// Get userID from session CR
-	gvr := GetAgenticSessionV1Alpha1Resource()
-	obj, err := reqDyn.Resource(gvr).Namespace(project).Get(c.Request.Context(), session, v1.GetOptions{})
+	// Get userID from session CR (RequireSessionAccess has authorized the caller)
+	obj, err := GetCachedSession(c.Request.Context(), project, session)
 	if err != nil {
 		if errors.IsNotFound(err) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
 			return
 		}
-		log.Printf("Failed to get session %s/%s: %v", project, session, err)
+		logging.Errorf(c, "Failed to get session %s/%s: %v", project, session, err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get session"})
 		return
 	}
@@ -48,7 +49,7 @@ func GetGitHubTokenForSession(c *gin.Context) {
 	// Extract userID from spec.userContext using type-safe unstructured helpers
 	userID, found, err := unstructured.NestedString(obj.Object, "spec", "userContext", "userId")
 	if !found || err != nil || userID == "" {
-		log.Printf("Failed to extract userID from session %s/%s: found=%v, err=%v", project, session, found, err)
+		logging.Errorf(c, "Failed to extract userID from session %s/%s: found=%v, err=%v", project, session, found, err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "User ID not found in session"})
 		return
 	}
@@ -58,7 +59,7 @@ func GetGitHubTokenForSession(c *gin.Context) {
 	// BOT_TOKEN is already scoped to this specific session via RBAC
 	authenticatedUserID := c.GetString("userID")
 	if authenticatedUserID != "" && authenticatedUserID != userID {
-		log.Printf("RBAC violation: user %s attempted to access credentials for session owned by %s", authenticatedUserID, userID)
+		logging.Warnf(c, "RBAC violation: user %s attempted to access credentials for session owned by %s", authenticatedUserID, userID)
 		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied: session belongs to different user"})
 		return
 	}
@@ -69,14 +70,14 @@ func GetGitHubTokenForSession(c *gin.Context) {
 	// Need to convert K8sClient interface to *kubernetes.Clientset for git.GetGitHubToken
 	k8sClientset, ok := K8sClient.(*kubernetes.Clientset)
 	if !ok {
-		log.Printf("Failed to convert K8sClient to *kubernetes.Clientset")
+		logging.Errorf(c, "Failed to convert K8sClient to *kubernetes.Clientset")
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Internal error"})
 		return
 	}
 
 	token, err := git.GetGitHubToken(c.Request.Context(), k8sClientset, DynamicClient, project, userID)
 	if err != nil {
-		log.Printf("Failed to get GitHub token for user %s: %v", userID, err)
+		logging.Errorf(c, "Failed to get GitHub token for user %s: %v", userID, err)
 		c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
 		return
 	}
@@ -87,25 +88,26 @@ func GetGitHubTokenForSession(c *gin.Context) {
 // GetGoogleCredentialsForSession handles GET /api/projects/:project/agentic-sessions/:session/credentials/google
 // Returns fresh Google OAuth credentials (refreshes if needed)
 func GetGoogleCredentialsForSession(c *gin.Context) {
+	defer observeCredentialFetch(c, "google")
+
 	project := c.Param("projectName")
 	session := c.Param("sessionName")
 
 	// Get user-scoped K8s client
-	reqK8s, reqDyn := GetK8sClientsForRequest(c)
+	reqK8s, _ := GetK8sClientsForRequest(c)
 	if reqK8s == nil {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing token"})
 		return
 	}
 
-	// Get userID from session CR
-	gvr := GetAgenticSessionV1Alpha1Resource()
-	obj, err := reqDyn.Resource(gvr).Namespace(project).Get(c.Request.Context(), session, v1.GetOptions{})
+	// Get userID from session CR (RequireSessionAccess has authorized the caller)
+	obj, err := GetCachedSession(c.Request.Context(), project, session)
 	if err != nil {
 		if errors.IsNotFound(err) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
 			return
 		}
-		log.Printf("Failed to get session %s/%s: %v", project, session, err)
+		logging.Errorf(c, "Failed to get session %s/%s: %v", project, session, err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get session"})
 		return
 	}
@@ -113,7 +115,7 @@ func GetGoogleCredentialsForSession(c *gin.Context) {
 	// Extract userID from spec.userContext using type-safe unstructured helpers
 	userID, found, err := unstructured.NestedString(obj.Object, "spec", "userContext", "userId")
 	if !found || err != nil || userID == "" {
-		log.Printf("Failed to extract userID from session %s/%s: found=%v, err=%v", project, session, found, err)
+		logging.Errorf(c, "Failed to extract userID from session %s/%s: found=%v, err=%v", project, session, found, err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "User ID not found in session"})
 		return
 	}
@@ -123,7 +125,7 @@ func GetGoogleCredentialsForSession(c *gin.Context) {
 	// BOT_TOKEN is already scoped to this specific session via RBAC
 	authenticatedUserID := c.GetString("userID")
 	if authenticatedUserID != "" && authenticatedUserID != userID {
-		log.Printf("RBAC violation: user %s attempted to access credentials for session owned by %s", authenticatedUserID, userID)
+		logging.Warnf(c, "RBAC violation: user %s attempted to access credentials for session owned by %s", authenticatedUserID, userID)
 		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied: session belongs to different user"})
 		return
 	}
@@ -137,7 +139,7 @@ func GetGoogleCredentialsForSession(c *gin.Context) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Google credentials not configured"})
 			return
 		}
-		log.Printf("Failed to get Google credentials for user %s: %v", userID, err)
+		logging.Errorf(c, "Failed to get Google credentials for user %s: %v", userID, err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get Google credentials"})
 		return
 	}
@@ -152,15 +154,15 @@ func GetGoogleCredentialsForSession(c *gin.Context) {
 
 	if needsRefresh && creds.RefreshToken != "" {
 		// Refresh the token
-		log.Printf("Google token expired for user %s, refreshing...", userID)
+		logging.Warnf(c, "Google token expired for user %s, refreshing...", userID)
 		newCreds, err := refreshGoogleAccessToken(c.Request.Context(), creds)
 		if err != nil {
-			log.Printf("Failed to refresh Google token for user %s: %v", userID, err)
+			logging.Errorf(c, "Failed to refresh Google token for user %s: %v", userID, err)
 			c.JSON(http.StatusUnauthorized, gin.H{"error": "Google token expired and refresh failed. Please re-authenticate."})
 			return
 		}
 		creds = newCreds
-		log.Printf("✓ Refreshed Google token for user %s", userID)
+		logging.Infof(c, "✓ Refreshed Google token for user %s", userID)
 	}
 
 	c.JSON(http.StatusOK, gin.H{
@@ -174,25 +176,26 @@ func GetGoogleCredentialsForSession(c *gin.Context) {
 // GetJiraCredentialsForSession handles GET /api/projects/:project/agentic-sessions/:session/credentials/jira
 // Returns Jira credentials for the session's user
 func GetJiraCredentialsForSession(c *gin.Context) {
+	defer observeCredentialFetch(c, "jira")
+
 	project := c.Param("projectName")
 	session := c.Param("sessionName")
 
 	// Get user-scoped K8s client
-	reqK8s, reqDyn := GetK8sClientsForRequest(c)
+	reqK8s, _ := GetK8sClientsForRequest(c)
 	if reqK8s == nil {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing token"})
 		return
 	}
 
-	// Get userID from session CR
-	gvr := GetAgenticSessionV1Alpha1Resource()
-	obj, err := reqDyn.Resource(gvr).Namespace(project).Get(c.Request.Context(), session, v1.GetOptions{})
+	// Get userID from session CR (RequireSessionAccess has authorized the caller)
+	obj, err := GetCachedSession(c.Request.Context(), project, session)
 	if err != nil {
 		if errors.IsNotFound(err) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
 			return
 		}
-		log.Printf("Failed to get session %s/%s: %v", project, session, err)
+		logging.Errorf(c, "Failed to get session %s/%s: %v", project, session, err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get session"})
 		return
 	}
@@ -200,7 +203,7 @@ func GetJiraCredentialsForSession(c *gin.Context) {
 	// Extract userID from spec.userContext using type-safe unstructured helpers
 	userID, found, err := unstructured.NestedString(obj.Object, "spec", "userContext", "userId")
 	if !found || err != nil || userID == "" {
-		log.Printf("Failed to extract userID from session %s/%s: found=%v, err=%v", project, session, found, err)
+		logging.Errorf(c, "Failed to extract userID from session %s/%s: found=%v, err=%v", project, session, found, err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "User ID not found in session"})
 		return
 	}
@@ -210,7 +213,7 @@ func GetJiraCredentialsForSession(c *gin.Context) {
 	// BOT_TOKEN is already scoped to this specific session via RBAC
 	authenticatedUserID := c.GetString("userID")
 	if authenticatedUserID != "" && authenticatedUserID != userID {
-		log.Printf("RBAC violation: user %s attempted to access credentials for session owned by %s", authenticatedUserID, userID)
+		logging.Warnf(c, "RBAC violation: user %s attempted to access credentials for session owned by %s", authenticatedUserID, userID)
 		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied: session belongs to different user"})
 		return
 	}
@@ -220,7 +223,7 @@ func GetJiraCredentialsForSession(c *gin.Context) {
 	// Get Jira credentials
 	creds, err := GetJiraCredentials(c.Request.Context(), userID)
 	if err != nil {
-		log.Printf("Failed to get Jira credentials for user %s: %v", userID, err)
+		logging.Errorf(c, "Failed to get Jira credentials for user %s: %v", userID, err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get Jira credentials"})
 		return
 	}
@@ -237,28 +240,93 @@ func GetJiraCredentialsForSession(c *gin.Context) {
 	})
 }
 
+// GetLinearCredentialsForSession handles GET /api/projects/:project/agentic-sessions/:session/credentials/linear
+// Returns the Linear API key for the session's user
+func GetLinearCredentialsForSession(c *gin.Context) {
+	defer observeCredentialFetch(c, "linear")
+
+	project := c.Param("projectName")
+	session := c.Param("sessionName")
+
+	// Get user-scoped K8s client
+	reqK8s, _ := GetK8sClientsForRequest(c)
+	if reqK8s == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing token"})
+		return
+	}
+
+	// Get userID from session CR (RequireSessionAccess has authorized the caller)
+	obj, err := GetCachedSession(c.Request.Context(), project, session)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+			return
+		}
+		logging.Errorf(c, "Failed to get session %s/%s: %v", project, session, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get session"})
+		return
+	}
+
+	// Extract userID from spec.userContext using type-safe unstructured helpers
+	userID, found, err := unstructured.NestedString(obj.Object, "spec", "userContext", "userId")
+	if !found || err != nil || userID == "" {
+		logging.Errorf(c, "Failed to extract userID from session %s/%s: found=%v, err=%v", project, session, found, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "User ID not found in session"})
+		return
+	}
+
+	// Verify authenticated user owns this session (RBAC: prevent accessing other users' credentials)
+	// BOT_TOKEN (session ServiceAccount) has no userID and is already scoped to this session
+	authenticatedUserID := c.GetString("userID")
+	if authenticatedUserID != "" && authenticatedUserID != userID {
+		logging.Warnf(c, "RBAC violation: user %s attempted to access credentials for session owned by %s", authenticatedUserID, userID)
+		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied: session belongs to different user"})
+		return
+	}
+
+	// Get Linear credentials
+	creds, err := GetLinearCredentials(c.Request.Context(), userID)
+	if err != nil && !errors.IsNotFound(err) {
+		logging.Errorf(c, "Failed to get Linear credentials for user %s: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get Linear credentials"})
+		return
+	}
+
+	if creds == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Linear credentials not configured"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"apiKey":       creds.APIKey,
+		"email":        creds.Email,
+		"organization": creds.Organization,
+	})
+}
+
 // GetGitLabTokenForSession handles GET /api/projects/:project/agentic-sessions/:session/credentials/gitlab
 // Returns GitLab token for the session's user
 func GetGitLabTokenForSession(c *gin.Context) {
+	defer observeCredentialFetch(c, "gitlab")
+
 	project := c.Param("projectName")
 	session := c.Param("sessionName")
 
 	// Get user-scoped K8s client
-	reqK8s, reqDyn := GetK8sClientsForRequest(c)
+	reqK8s, _ := GetK8sClientsForRequest(c)
 	if reqK8s == nil {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing token"})
 		return
 	}
 
-	// Get userID from session CR
-	gvr := GetAgenticSessionV1Alpha1Resource()
-	obj, err := reqDyn.Resource(gvr).Namespace(project).Get(c.Request.Context(), session, v1.GetOptions{})
+	// Get userID from session CR (RequireSessionAccess has authorized the caller)
+	obj, err := GetCachedSession(c.Request.Context(), project, session)
 	if err != nil {
 		if errors.IsNotFound(err) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
 			return
 		}
-		log.Printf("Failed to get session %s/%s: %v", project, session, err)
+		logging.Errorf(c, "Failed to get session %s/%s: %v", project, session, err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get session"})
 		return
 	}
@@ -266,7 +334,7 @@ func GetGitLabTokenForSession(c *gin.Context) {
 	// Extract userID from spec.userContext using type-safe unstructured helpers
 	userID, found, err := unstructured.NestedString(obj.Object, "spec", "userContext", "userId")
 	if !found || err != nil || userID == "" {
-		log.Printf("Failed to extract userID from session %s/%s: found=%v, err=%v", project, session, found, err)
+		logging.Errorf(c, "Failed to extract userID from session %s/%s: found=%v, err=%v", project, session, found, err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "User ID not found in session"})
 		return
 	}
@@ -276,7 +344,7 @@ func GetGitLabTokenForSession(c *gin.Context) {
 	// BOT_TOKEN is already scoped to this specific session via RBAC
 	authenticatedUserID := c.GetString("userID")
 	if authenticatedUserID != "" && authenticatedUserID != userID {
-		log.Printf("RBAC violation: user %s attempted to access credentials for session owned by %s", authenticatedUserID, userID)
+		logging.Warnf(c, "RBAC violation: user %s attempted to access credentials for session owned by %s", authenticatedUserID, userID)
 		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied: session belongs to different user"})
 		return
 	}
@@ -286,7 +354,7 @@ func GetGitLabTokenForSession(c *gin.Context) {
 	// Get GitLab credentials
 	creds, err := GetGitLabCredentials(c.Request.Context(), userID)
 	if err != nil {
-		log.Printf("Failed to get GitLab credentials for user %s: %v", userID, err)
+		logging.Errorf(c, "Failed to get GitLab credentials for user %s: %v", userID, err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get GitLab credentials"})
 		return
 	}
@@ -302,11 +370,26 @@ func GetGitLabTokenForSession(c *gin.Context) {
 	})
 }
 
+// observeCredentialFetch records a runner credential fetch by the status it was answered with
+func observeCredentialFetch(c *gin.Context, provider string) {
+	result := metrics.ResultError
+	switch c.Writer.Status() {
+	case http.StatusOK:
+		result = metrics.ResultSuccess
+	case http.StatusNotFound:
+		result = metrics.ResultNotConfigured
+	case http.StatusUnauthorized, http.StatusForbidden:
+		result = metrics.ResultDenied
+	}
+	metrics.ObserveCredentialFetch(provider, result)
+}
+
 // refreshGoogleAccessToken refreshes a Google OAuth access token using the refresh token
-func refreshGoogleAccessToken(ctx context.Context, oldCreds *GoogleOAuthCredentials) (*GoogleOAuthCredentials, error) {
+func refreshGoogleAccessToken(ctx context.Context, oldCreds *GoogleOAuthCredentials) (_ *GoogleOAuthCredentials, err error) {
 	if oldCreds.RefreshToken == "" {
 		return nil, fmt.Errorf("no refresh token available")
 	}
+	defer func() { metrics.ObserveRefresh("google", err) }()
 
 	// Get OAuth provider config
 	provider, err := getOAuthProvider("google")
@@ -323,7 +406,7 @@ func refreshGoogleAccessToken(ctx context.Context, oldCreds *GoogleOAuthCredenti
 		"grant_type":    "refresh_token",
 	}
 
-	tokenData, err := exchangeOAuthToken(ctx, tokenURL, payload)
+	tokenData, err := exchangeOAuthToken(ctx, "google", tokenURL, payload)
 	if err != nil {
 		return nil, fmt.Errorf("failed to refresh token: %w", err)
 	}
@@ -348,14 +431,14 @@ func refreshGoogleAccessToken(ctx context.Context, oldCreds *GoogleOAuthCredenti
 }
 
 // exchangeOAuthToken makes a token exchange request to an OAuth provider
-func exchangeOAuthToken(ctx context.Context, tokenURL string, payload map[string]string) (*OAuthTokenResponse, error) {
+func exchangeOAuthToken(ctx context.Context, provider, tokenURL string, payload map[string]string) (*OAuthTokenResponse, error) {
 	// Convert map to form data
 	form := url.Values{}
 	for k, v := range payload {
 		form.Set(k, v)
 	}
 
-	client := &http.Client{Timeout: 10 * time.Second}
+	client := &http.Client{Timeout: 10 * time.Second, Transport: metrics.Transport(provider, "token", nil)}
 	resp, err := client.Post(tokenURL, "application/x-www-form-urlencoded", strings.NewReader(form.Encode()))
 	if err != nil {
 		return nil, fmt.Errorf("request failed: %w", err)
@@ -2,10 +2,11 @@ package handlers
 
 import (
 	"fmt"
-	"log"
 	"net/http"
 	"time"
 
+	"ambient-code-backend/logging"
+
 	"github.com/gin-gonic/gin"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
@@ -28,7 +29,7 @@ func ListNamespaceSecrets(c *gin.Context) {
 
 	list, err := k8sClient.CoreV1().Secrets(projectName).List(c.Request.Context(), v1.ListOptions{})
 	if err != nil {
-		log.Printf("Failed to list secrets in %s: %v", projectName, err)
+		logging.Errorf(c, "Failed to list secrets in %s: %v", projectName, err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list secrets"})
 		return
 	}
@@ -78,7 +79,7 @@ func ListRunnerSecrets(c *gin.Context) {
 			c.JSON(http.StatusOK, gin.H{"data": map[string]string{}})
 			return
 		}
-		log.Printf("Failed to get Secret %s/%s: %v", projectName, secretName, err)
+		logging.Errorf(c, "Failed to get Secret %s/%s: %v", projectName, secretName, err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read runner secrets"})
 		return
 	}
@@ -140,12 +141,12 @@ func UpdateRunnerSecrets(c *gin.Context) {
 			StringData: req.Data,
 		}
 		if _, err := k8sClient.CoreV1().Secrets(projectName).Create(c.Request.Context(), newSec, v1.CreateOptions{}); err != nil {
-			log.Printf("Failed to create Secret %s/%s: %v", projectName, secretName, err)
+			logging.Errorf(c, "Failed to create Secret %s/%s: %v", projectName, secretName, err)
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create runner secrets"})
 			return
 		}
 	} else if err != nil {
-		log.Printf("Failed to get Secret %s/%s: %v", projectName, secretName, err)
+		logging.Errorf(c, "Failed to get Secret %s/%s: %v", projectName, secretName, err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read runner secrets"})
 		return
 	} else {
@@ -156,7 +157,7 @@ func UpdateRunnerSecrets(c *gin.Context) {
 			sec.Data[k] = []byte(v)
 		}
 		if _, err := k8sClient.CoreV1().Secrets(projectName).Update(c.Request.Context(), sec, v1.UpdateOptions{}); err != nil {
-			log.Printf("Failed to update Secret %s/%s: %v", projectName, secretName, err)
+			logging.Errorf(c, "Failed to update Secret %s/%s: %v", projectName, secretName, err)
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update runner secrets"})
 			return
 		}
@@ -187,7 +188,7 @@ func ListIntegrationSecrets(c *gin.Context) {
 			c.JSON(http.StatusOK, gin.H{"data": map[string]string{}})
 			return
 		}
-		log.Printf("Failed to get Secret %s/%s: %v", projectName, secretName, err)
+		logging.Errorf(c, "Failed to get Secret %s/%s: %v", projectName, secretName, err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read integration secrets"})
 		return
 	}
@@ -234,12 +235,12 @@ func UpdateIntegrationSecrets(c *gin.Context) {
 			StringData: req.Data,
 		}
 		if _, err := k8sClient.CoreV1().Secrets(projectName).Create(c.Request.Context(), newSec, v1.CreateOptions{}); err != nil {
-			log.Printf("Failed to create Secret %s/%s: %v", projectName, secretName, err)
+			logging.Errorf(c, "Failed to create Secret %s/%s: %v", projectName, secretName, err)
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create integration secrets"})
 			return
 		}
 	} else if err != nil {
-		log.Printf("Failed to get Secret %s/%s: %v", projectName, secretName, err)
+		logging.Errorf(c, "Failed to get Secret %s/%s: %v", projectName, secretName, err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read integration secrets"})
 		return
 	} else {
@@ -249,7 +250,7 @@ func UpdateIntegrationSecrets(c *gin.Context) {
 			sec.Data[k] = []byte(v)
 		}
 		if _, err := k8sClient.CoreV1().Secrets(projectName).Update(c.Request.Context(), sec, v1.UpdateOptions{}); err != nil {
-			log.Printf("Failed to update Secret %s/%s: %v", projectName, secretName, err)
+			logging.Errorf(c, "Failed to update Secret %s/%s: %v", projectName, secretName, err)
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update integration secrets"})
 			return
 		}
@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+
+	"ambient-code-backend/logging"
+
+	"github.com/gin-gonic/gin"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
+)
+
+// sessionInformer watches AgenticSessions in all namespaces with the backend service
+// account. Nil until StartSessionCache.
+var sessionInformer informers.GenericInformer
+
+// StartSessionCache starts the shared AgenticSession informer used by GetCachedSession
+// for the life of the process. It returns immediately; reads go to the API server until
+// the cache has synced.
+func StartSessionCache() {
+	startSessionCache(make(chan struct{}))
+}
+
+func startSessionCache(stopCh <-chan struct{}) {
+	if DynamicClient == nil {
+		return
+	}
+	factory := dynamicinformer.NewDynamicSharedInformerFactory(DynamicClient, 0)
+	informer := factory.ForResource(GetAgenticSessionV1Alpha1Resource())
+	// Managed fields are never read and make up much of each cached object
+	_ = informer.Informer().SetTransform(func(obj interface{}) (interface{}, error) {
+		if u, ok := obj.(*unstructured.Unstructured); ok {
+			u.SetManagedFields(nil)
+		}
+		return obj, nil
+	})
+	sessionInformer = informer
+	factory.Start(stopCh)
+
+	go func() {
+		if cache.WaitForCacheSync(stopCh, informer.Informer().HasSynced) {
+			logging.Infof(context.Background(), "AgenticSession cache synced")
+		}
+	}()
+}
+
+// GetCachedSession returns an AgenticSession from the informer cache, read with the
+// backend service account. Callers must authorize the request first (e.g. with
+// RequireSessionAccess). A session missing from the cache may have just been created,
+// so misses and reads before the cache syncs fall back to a GET.
+//
+// The cache can lag the API server briefly. Read-modify-write paths must GET the
+// session themselves so their update carries the current resourceVersion.
+func GetCachedSession(ctx context.Context, project, name string) (*unstructured.Unstructured, error) {
+	if u := lookupCachedSession(project, name); u != nil {
+		// Cached objects are shared and must not be modified
+		return u.DeepCopy(), nil
+	}
+	if DynamicClient == nil {
+		return nil, fmt.Errorf("dynamic client not initialized")
+	}
+	return DynamicClient.Resource(GetAgenticSessionV1Alpha1Resource()).Namespace(project).Get(ctx, name, v1.GetOptions{})
+}
+
+// lookupCachedSession returns the cached session, shared and read-only, or nil when
+// it isn't cached or the cache hasn't synced
+func lookupCachedSession(project, name string) *unstructured.Unstructured {
+	informer := sessionInformer
+	if informer == nil || !informer.Informer().HasSynced() {
+		return nil
+	}
+	obj, err := informer.Lister().ByNamespace(project).Get(name)
+	if err != nil {
+		return nil
+	}
+	u, _ := obj.(*unstructured.Unstructured)
+	return u
+}
+
+// sessionNotModified answers 304 when the caller's If-None-Match names the cached
+// session's resourceVersion, saving the API server GET. The cache can lag an update by
+// moments, which at worst delays a poller seeing it until its next poll.
+func sessionNotModified(c *gin.Context, project, name string) bool {
+	ifNoneMatch := c.GetHeader("If-None-Match")
+	if ifNoneMatch == "" {
+		return false
+	}
+	u := lookupCachedSession(project, name)
+	if u == nil || !etagMatches(ifNoneMatch, ResourceETag(u.GetResourceVersion())) {
+		return false
+	}
+	return NotModified(c, ResourceETag(u.GetResourceVersion()))
+}
@@ -0,0 +1,95 @@
+//go:build test
+
+package handlers
+
+import (
+	"context"
+	"time"
+
+	test_constants "ambient-code-backend/tests/constants"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/informers"
+)
+
+var _ = Describe("AgenticSession cache", Label(test_constants.LabelUnit, test_constants.LabelHandlers, test_constants.LabelSessions), func() {
+	gvr := schema.GroupVersionResource{Group: "vteam.ambient-code", Version: "v1alpha1", Resource: "agenticsessions"}
+
+	var (
+		originalDynamicClient dynamic.Interface
+		originalGVR           func() schema.GroupVersionResource
+		originalInformer      informers.GenericInformer
+		stopCh                chan struct{}
+	)
+
+	newSession := func(name, displayName string) *unstructured.Unstructured {
+		obj := &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "vteam.ambient-code/v1alpha1",
+			"kind":       "AgenticSession",
+			"metadata":   map[string]interface{}{"name": name, "namespace": "team-a"},
+			"spec":       map[string]interface{}{"displayName": displayName},
+		}}
+		obj.SetManagedFields([]v1.ManagedFieldsEntry{{Manager: "backend"}})
+		return obj
+	}
+
+	BeforeEach(func() {
+		originalDynamicClient = DynamicClient
+		originalGVR = GetAgenticSessionV1Alpha1Resource
+		originalInformer = sessionInformer
+		stopCh = make(chan struct{})
+
+		DynamicClient = dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+			map[schema.GroupVersionResource]string{gvr: "AgenticSessionList"},
+			newSession("cached", "Cached"),
+		)
+		GetAgenticSessionV1Alpha1Resource = func() schema.GroupVersionResource { return gvr }
+	})
+
+	AfterEach(func() {
+		close(stopCh)
+		DynamicClient = originalDynamicClient
+		GetAgenticSessionV1Alpha1Resource = originalGVR
+		sessionInformer = originalInformer
+	})
+
+	It("Should read from the API server before the cache starts", func() {
+		sessionInformer = nil
+		obj, err := GetCachedSession(context.Background(), "team-a", "cached")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(obj.GetName()).To(Equal("cached"))
+
+		_, err = GetCachedSession(context.Background(), "team-a", "missing")
+		Expect(errors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("Should serve copies from the synced cache and fall back on misses", func() {
+		startSessionCache(stopCh)
+		Eventually(func() bool { return sessionInformer.Informer().HasSynced() }, 5*time.Second).Should(BeTrue())
+
+		obj, err := GetCachedSession(context.Background(), "team-a", "cached")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(obj.GetManagedFields()).To(BeEmpty())
+		obj.Object["spec"] = map[string]interface{}{"displayName": "Changed"}
+
+		again, err := GetCachedSession(context.Background(), "team-a", "cached")
+		Expect(err).NotTo(HaveOccurred())
+		name, _, _ := unstructured.NestedString(again.Object, "spec", "displayName")
+		Expect(name).To(Equal("Cached"))
+
+		// Created behind the informer's back, e.g. before its watch event arrives
+		_, err = DynamicClient.Resource(gvr).Namespace("team-a").Create(context.Background(), newSession("fresh", "Fresh"), v1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		obj, err = GetCachedSession(context.Background(), "team-a", "fresh")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(obj.GetName()).To(Equal("fresh"))
+	})
+})
@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"ambient-code-backend/types"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+)
+
+// Built-in session defaults, used where ProjectSettings spec.sessionDefaults doesn't
+// set its own
+const (
+	builtinSessionModel     = "sonnet"
+	builtinSessionMaxTokens = 4000
+	builtinSessionTimeout   = 300
+)
+
+// sessionDefaults are the LLM settings and timeout (seconds) of sessions that don't
+// set their own
+type sessionDefaults struct {
+	LLMSettings types.LLMSettings
+	Timeout     int
+}
+
+// getSessionDefaults returns the project's session defaults from ProjectSettings
+// spec.sessionDefaults over the built-in ones
+func getSessionDefaults(ctx context.Context, dynClient dynamic.Interface, project string) (sessionDefaults, error) {
+	defaults := sessionDefaults{
+		LLMSettings: types.LLMSettings{Model: builtinSessionModel, Temperature: 0.7, MaxTokens: builtinSessionMaxTokens},
+		Timeout:     builtinSessionTimeout,
+	}
+	if dynClient == nil {
+		return defaults, nil
+	}
+	obj, err := dynClient.Resource(GetProjectSettingsResource()).Namespace(project).Get(ctx, "projectsettings", v1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return defaults, nil
+		}
+		return defaults, err
+	}
+	m, _, _ := unstructured.NestedMap(obj.Object, "spec", "sessionDefaults")
+	if model, _ := m["model"].(string); strings.TrimSpace(model) != "" {
+		defaults.LLMSettings.Model = strings.TrimSpace(model)
+	}
+	if v, ok := m["maxTokens"].(int64); ok {
+		if v < 1 {
+			return defaults, fmt.Errorf("invalid sessionDefaults.maxTokens %d", v)
+		}
+		defaults.LLMSettings.MaxTokens = int(v)
+	}
+	if v, ok := m["timeout"].(int64); ok {
+		if v < 1 {
+			return defaults, fmt.Errorf("invalid sessionDefaults.timeout %d", v)
+		}
+		defaults.Timeout = int(v)
+	}
+	return defaults, nil
+}
@@ -0,0 +1,264 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"ambient-code-backend/logging"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Session tokens let the frontend drive one session without holding the user's
+// broad token. A token is "vts_<jwt>", HMAC-signed with a key kept in a Secret in
+// the backend namespace, valid for minutes and only for that session's AG-UI
+// routes. Requests run as the minting user via impersonation. Deleting the Secret
+// rotates the key and invalidates all outstanding tokens.
+const (
+	SessionTokenPrefix = "vts_"
+
+	sessionTokenSecretName = "ambient-session-token-key"
+	sessionTokenContextKey = "sessionToken"
+	sessionTokenAudience   = "ambient-session"
+	// sessionTokenRoutePrefix is the only route prefix a session token may call
+	sessionTokenRoutePrefix = "/api/projects/:projectName/agentic-sessions/:sessionName/agui/"
+
+	defaultSessionTokenTTL = 15 * time.Minute
+	maxSessionTokenTTL     = time.Hour
+	// sessionTokenKeyCacheTTL bounds how long a rotated key keeps being accepted
+	sessionTokenKeyCacheTTL = 5 * time.Minute
+)
+
+// SessionTokenClaims are the claims of a session token. Subject is the
+// impersonated Kubernetes username.
+type SessionTokenClaims struct {
+	UserID  string   `json:"uid"`
+	Groups  []string `json:"groups,omitempty"`
+	Project string   `json:"project"`
+	Session string   `json:"session"`
+	jwt.RegisteredClaims
+}
+
+var (
+	sessionTokenKeyMu       sync.Mutex
+	sessionTokenKey         []byte
+	sessionTokenKeyLoadedAt time.Time
+)
+
+// sessionTokenSigningKey returns the signing key, creating its Secret on first use
+func sessionTokenSigningKey(ctx context.Context) ([]byte, error) {
+	sessionTokenKeyMu.Lock()
+	defer sessionTokenKeyMu.Unlock()
+	if sessionTokenKey != nil && time.Since(sessionTokenKeyLoadedAt) < sessionTokenKeyCacheTTL {
+		return sessionTokenKey, nil
+	}
+
+	key, err := loadOrCreateKeySecret(ctx, sessionTokenSecretName)
+	if err != nil {
+		return nil, err
+	}
+	sessionTokenKey = key
+	sessionTokenKeyLoadedAt = time.Now()
+	return sessionTokenKey, nil
+}
+
+// loadOrCreateKeySecret returns the 32-byte random key kept in the named Secret of
+// the backend namespace, creating the Secret on first use
+func loadOrCreateKeySecret(ctx context.Context, name string) ([]byte, error) {
+	secret, err := K8sClient.CoreV1().Secrets(Namespace).Get(ctx, name, v1.GetOptions{})
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			return nil, fmt.Errorf("failed to get Secret: %w", err)
+		}
+		key := make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			return nil, err
+		}
+		secret = &corev1.Secret{
+			ObjectMeta: v1.ObjectMeta{
+				Name:      name,
+				Namespace: Namespace,
+				Labels:    map[string]string{"app": "ambient-code"},
+			},
+			Type: corev1.SecretTypeOpaque,
+			Data: map[string][]byte{"key": key},
+		}
+		if _, cerr := K8sClient.CoreV1().Secrets(Namespace).Create(ctx, secret, v1.CreateOptions{}); cerr != nil {
+			if !errors.IsAlreadyExists(cerr) {
+				return nil, fmt.Errorf("failed to create Secret: %w", cerr)
+			}
+			// Another replica created it first; use theirs
+			if secret, err = K8sClient.CoreV1().Secrets(Namespace).Get(ctx, name, v1.GetOptions{}); err != nil {
+				return nil, fmt.Errorf("failed to fetch Secret after create: %w", err)
+			}
+		}
+	}
+	if len(secret.Data["key"]) < 32 {
+		return nil, fmt.Errorf("secret %s has no usable key", name)
+	}
+	return secret.Data["key"], nil
+}
+
+// mintSessionToken signs a session token for claims valid for ttl
+func mintSessionToken(ctx context.Context, claims *SessionTokenClaims, ttl time.Duration) (string, error) {
+	key, err := sessionTokenSigningKey(ctx)
+	if err != nil {
+		return "", err
+	}
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return "", err
+	}
+	now := time.Now()
+	claims.ID = hex.EncodeToString(id)
+	claims.Audience = jwt.ClaimStrings{sessionTokenAudience}
+	claims.IssuedAt = jwt.NewNumericDate(now)
+	claims.ExpiresAt = jwt.NewNumericDate(now.Add(ttl))
+	signed, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(key)
+	if err != nil {
+		return "", err
+	}
+	return SessionTokenPrefix + signed, nil
+}
+
+// verifySessionToken verifies a session token signed with key and returns its claims
+func verifySessionToken(key []byte, token string) (*SessionTokenClaims, error) {
+	claims := &SessionTokenClaims{}
+	_, err := jwt.ParseWithClaims(strings.TrimPrefix(token, SessionTokenPrefix), claims,
+		func(*jwt.Token) (interface{}, error) { return key, nil },
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithAudience(sessionTokenAudience),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Subject == "" || claims.Project == "" || claims.Session == "" {
+		return nil, fmt.Errorf("missing required claims")
+	}
+	return claims, nil
+}
+
+// SessionTokenAuth authenticates requests bearing a session token. Other tokens
+// pass through untouched.
+//
+// A session token may only call the AG-UI routes of the session it was minted for,
+// and its requests get the minting user's identity in context.
+func SessionTokenAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token, _, _, _ := extractRequestToken(c)
+		if !strings.HasPrefix(token, SessionTokenPrefix) {
+			c.Next()
+			return
+		}
+		if K8sClient == nil {
+			c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Kubernetes client not initialized"})
+			c.Abort()
+			return
+		}
+
+		key, err := sessionTokenSigningKey(c.Request.Context())
+		if err != nil {
+			logging.Errorf(c, "Session token key unavailable: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to validate session token"})
+			c.Abort()
+			return
+		}
+		claims, err := verifySessionToken(key, token)
+		if err != nil {
+			logging.Debugf(c, "Rejected session token: %v", err)
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired session token"})
+			c.Abort()
+			return
+		}
+
+		if !strings.HasPrefix(c.FullPath(), sessionTokenRoutePrefix) ||
+			c.Param("projectName") != claims.Project || c.Param("sessionName") != claims.Session {
+			logging.Warnf(c, "Session token for %s/%s rejected on %s %s", claims.Project, claims.Session, c.Request.Method, c.FullPath())
+			c.JSON(http.StatusForbidden, gin.H{"error": fmt.Sprintf("Session token only permits AG-UI operations on session %s/%s", claims.Project, claims.Session)})
+			c.Abort()
+			return
+		}
+
+		// Identity comes from the token, never from headers sent alongside it
+		for _, h := range apiKeyIdentityHeaders {
+			c.Request.Header.Del(h)
+		}
+		c.Set("userID", claims.UserID)
+		c.Set("userIDOriginal", claims.Subject)
+		c.Set("userName", claims.Subject)
+		c.Set("userGroups", claims.Groups)
+		c.Set(sessionTokenContextKey, claims)
+		c.Next()
+	}
+}
+
+// CreateSessionToken handles POST /api/projects/:projectName/agentic-sessions/:sessionName/session-token
+// Mints a short-lived token limited to this session's AG-UI operations for the caller.
+// The optional body {"ttlSeconds": n} sets its lifetime (default 15m, max 1h).
+func CreateSessionToken(c *gin.Context) {
+	project := c.Param("projectName")
+	sessionName := c.Param("sessionName")
+
+	reqK8s, _ := GetK8sClientsForRequest(c)
+	if reqK8s == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing token"})
+		return
+	}
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User authentication required"})
+		return
+	}
+
+	var req struct {
+		TTLSeconds int `json:"ttlSeconds"`
+	}
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+	ttl := defaultSessionTokenTTL
+	if req.TTLSeconds != 0 {
+		ttl = time.Duration(req.TTLSeconds) * time.Second
+		if ttl < time.Minute || ttl > maxSessionTokenTTL {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("ttlSeconds must be between 60 and %d", int(maxSessionTokenTTL.Seconds()))})
+			return
+		}
+	}
+
+	username, groups := callerKubernetesIdentity(c, reqK8s)
+	if username == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User authentication required"})
+		return
+	}
+
+	claims := &SessionTokenClaims{UserID: userID, Groups: groups, Project: project, Session: sessionName}
+	claims.Subject = username
+	token, err := mintSessionToken(c.Request.Context(), claims, ttl)
+	if err != nil {
+		logging.Errorf(c, "Failed to mint session token for %s/%s: %v", project, sessionName, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create session token"})
+		return
+	}
+
+	logging.Infof(c, "Minted session token %s for %s on %s/%s (expires %s)", claims.ID, userID, project, sessionName, claims.ExpiresAt.Format(time.RFC3339))
+	c.JSON(http.StatusCreated, gin.H{
+		"token":     token,
+		"expiresAt": claims.ExpiresAt.UTC().Format(time.RFC3339),
+		"project":   project,
+		"session":   sessionName,
+	})
+}
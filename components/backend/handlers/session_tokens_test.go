@@ -0,0 +1,125 @@
+//go:build test
+
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	test_constants "ambient-code-backend/tests/constants"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+var _ = Describe("Session Tokens", Label(test_constants.LabelUnit, test_constants.LabelHandlers, test_constants.LabelMiddleware), func() {
+	var (
+		originalK8sClient kubernetes.Interface
+		originalNamespace string
+	)
+
+	resetKey := func() {
+		sessionTokenKeyMu.Lock()
+		sessionTokenKey = nil
+		sessionTokenKeyMu.Unlock()
+	}
+
+	BeforeEach(func() {
+		originalK8sClient = K8sClient
+		originalNamespace = Namespace
+		K8sClient = fake.NewSimpleClientset()
+		Namespace = "ambient-code"
+		resetKey()
+	})
+
+	AfterEach(func() {
+		K8sClient = originalK8sClient
+		Namespace = originalNamespace
+		resetKey()
+	})
+
+	mint := func(project, session string, ttl time.Duration) string {
+		claims := &SessionTokenClaims{UserID: "alice", Groups: []string{"devs"}, Project: project, Session: session}
+		claims.Subject = "alice@example.com"
+		token, err := mintSessionToken(context.Background(), claims, ttl)
+		Expect(err).NotTo(HaveOccurred())
+		return token
+	}
+
+	It("Should create the signing key Secret once and verify minted tokens", func() {
+		token := mint("team-a", "s1", time.Minute)
+		Expect(token).To(HavePrefix(SessionTokenPrefix))
+
+		secret, err := K8sClient.CoreV1().Secrets(Namespace).Get(context.Background(), sessionTokenSecretName, v1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(secret.Data["key"]).To(HaveLen(32))
+
+		// A fresh replica loads the same key
+		resetKey()
+		key, err := sessionTokenSigningKey(context.Background())
+		Expect(err).NotTo(HaveOccurred())
+		claims, err := verifySessionToken(key, token)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(claims.Subject).To(Equal("alice@example.com"))
+		Expect(claims.Project).To(Equal("team-a"))
+		Expect(claims.Session).To(Equal("s1"))
+		Expect(claims.ID).NotTo(BeEmpty())
+	})
+
+	It("Should reject expired, tampered and foreign tokens", func() {
+		key, err := sessionTokenSigningKey(context.Background())
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = verifySessionToken(key, mint("team-a", "s1", -time.Minute))
+		Expect(err).To(HaveOccurred())
+
+		token := mint("team-a", "s1", time.Minute)
+		_, err = verifySessionToken(key, token[:len(token)-2]+"xx")
+		Expect(err).To(HaveOccurred())
+
+		_, err = verifySessionToken([]byte(strings.Repeat("k", 32)), token)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("Should only admit the token's own session AG-UI routes", func() {
+		token := mint("team-a", "s1", time.Minute)
+
+		r := gin.New()
+		api := r.Group("/api", SessionTokenAuth())
+		whoami := func(c *gin.Context) { c.String(http.StatusOK, c.GetString("userID")) }
+		api.GET("/projects/:projectName/agentic-sessions/:sessionName/agui/events", whoami)
+		api.POST("/projects/:projectName/agentic-sessions/:sessionName/stop", whoami)
+		api.GET("/projects/:projectName/agentic-sessions", whoami)
+
+		call := func(method, path string, header map[string]string) *httptest.ResponseRecorder {
+			req := httptest.NewRequest(method, path, nil)
+			for k, v := range header {
+				req.Header.Set(k, v)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			return w
+		}
+		auth := map[string]string{"Authorization": "Bearer " + token, "X-Forwarded-User": "mallory"}
+
+		w := call(http.MethodGet, "/api/projects/team-a/agentic-sessions/s1/agui/events", auth)
+		Expect(w.Code).To(Equal(http.StatusOK))
+		Expect(w.Body.String()).To(Equal("alice"))
+
+		Expect(call(http.MethodGet, "/api/projects/team-a/agentic-sessions/s2/agui/events", auth).Code).To(Equal(http.StatusForbidden))
+		Expect(call(http.MethodGet, "/api/projects/team-b/agentic-sessions/s1/agui/events", auth).Code).To(Equal(http.StatusForbidden))
+		Expect(call(http.MethodPost, "/api/projects/team-a/agentic-sessions/s1/stop", auth).Code).To(Equal(http.StatusForbidden))
+		Expect(call(http.MethodGet, "/api/projects/team-a/agentic-sessions", auth).Code).To(Equal(http.StatusForbidden))
+		Expect(call(http.MethodGet, "/api/projects/team-a/agentic-sessions/s1/agui/events", map[string]string{"Authorization": "Bearer " + token + "x"}).Code).To(Equal(http.StatusUnauthorized))
+
+		// Other tokens pass through untouched
+		Expect(call(http.MethodPost, "/api/projects/team-a/agentic-sessions/s1/stop", map[string]string{"Authorization": "Bearer sha256~abc"}).Code).To(Equal(http.StatusOK))
+	})
+})
@@ -8,7 +8,6 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"log"
 	"net/http"
 	"net/url"
 	"os"
@@ -20,12 +19,12 @@ import (
 	"unicode/utf8"
 
 	"ambient-code-backend/git"
+	"ambient-code-backend/logging"
 	"ambient-code-backend/pathutil"
 	"ambient-code-backend/types"
 
 	"github.com/gin-gonic/gin"
 	authnv1 "k8s.io/api/authentication/v1"
-	authzv1 "k8s.io/api/authorization/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
@@ -122,6 +121,22 @@ func parseSpec(spec map[string]interface{}) types.AgenticSessionSpec {
 		result.Timeout = int(timeout)
 	}
 
+	if runnerImage, ok := spec["runnerImage"].(string); ok {
+		result.RunnerImage = runnerImage
+	}
+
+	if spot, ok := spec["spot"].(bool); ok {
+		result.Spot = spot
+	}
+
+	if persona, ok := spec["persona"].(string); ok {
+		result.Persona = persona
+	}
+
+	if size, found, _ := unstructured.NestedString(spec, "workspace", "size"); found && size != "" {
+		result.Workspace = &types.WorkspaceSpec{Size: size}
+	}
+
 	if llmSettings, ok := spec["llmSettings"].(map[string]interface{}); ok {
 		if model, ok := llmSettings["model"].(string); ok {
 			result.LLMSettings.Model = model
@@ -132,6 +147,7 @@ func parseSpec(spec map[string]interface{}) types.AgenticSessionSpec {
 		if maxTokens, ok := llmSettings["maxTokens"].(float64); ok {
 			result.LLMSettings.MaxTokens = int(maxTokens)
 		}
+		result.LLMSettings.FallbackModels, _, _ = unstructured.NestedStringSlice(llmSettings, "fallbackModels")
 	}
 
 	// environmentVariables passthrough
@@ -185,6 +201,34 @@ func parseSpec(spec map[string]interface{}) types.AgenticSessionSpec {
 			if autoPush, ok := m["autoPush"].(bool); ok {
 				r.AutoPush = types.BoolPtr(autoPush)
 			}
+			switch v := m["depth"].(type) {
+			case int64:
+				depth := int(v)
+				r.Depth = &depth
+			case float64:
+				depth := int(v)
+				r.Depth = &depth
+			}
+			if singleBranch, ok := m["singleBranch"].(bool); ok {
+				r.SingleBranch = types.BoolPtr(singleBranch)
+			}
+			if paths, ok := m["sparsePaths"].([]interface{}); ok {
+				for _, p := range paths {
+					if s, ok := p.(string); ok && strings.TrimSpace(s) != "" {
+						r.SparsePaths = append(r.SparsePaths, s)
+					}
+				}
+			}
+			if lfs, ok := m["lfs"].(map[string]interface{}); ok {
+				r.LFS = &types.RepoLFS{}
+				r.LFS.SkipSmudge, _ = lfs["skipSmudge"].(bool)
+				switch v := lfs["maxObjectSizeMB"].(type) {
+				case int64:
+					r.LFS.MaxObjectSizeMB = int(v)
+				case float64:
+					r.LFS.MaxObjectSizeMB = int(v)
+				}
+			}
 			if strings.TrimSpace(r.URL) != "" {
 				repos = append(repos, r)
 			}
@@ -252,6 +296,10 @@ func parseStatus(status map[string]interface{}) *types.AgenticSessionStatus {
 		result.SDKSessionID = sdkSessionID
 	}
 
+	if debugURL, ok := status["debugURL"].(string); ok {
+		result.DebugURL = debugURL
+	}
+
 	if restarts, ok := status["sdkRestartCount"]; ok {
 		switch v := restarts.(type) {
 		case int64:
@@ -352,6 +400,57 @@ func parseStatus(status map[string]interface{}) *types.AgenticSessionStatus {
 		}
 	}
 
+	if prs, ok := status["pullRequests"].([]interface{}); ok && len(prs) > 0 {
+		result.PullRequests = make([]types.SessionPullRequest, 0, len(prs))
+		for _, entry := range prs {
+			m, ok := entry.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			pr := types.SessionPullRequest{}
+			pr.URL, _ = m["url"].(string)
+			pr.Provider, _ = m["provider"].(string)
+			pr.RepoURL, _ = m["repoUrl"].(string)
+			pr.Head, _ = m["head"].(string)
+			pr.Base, _ = m["base"].(string)
+			pr.CreatedAt, _ = m["createdAt"].(string)
+			switch v := m["number"].(type) {
+			case int64:
+				pr.Number = int(v)
+			case float64:
+				pr.Number = int(v)
+			}
+			switch v := m["checkRunId"].(type) {
+			case int64:
+				pr.CheckRunID = v
+			case float64:
+				pr.CheckRunID = int64(v)
+			}
+			result.PullRequests = append(result.PullRequests, pr)
+		}
+	}
+
+	if issues, ok := status["linearIssues"].([]interface{}); ok && len(issues) > 0 {
+		result.LinearIssues = make([]types.SessionLinearIssue, 0, len(issues))
+		for _, entry := range issues {
+			m, ok := entry.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			issue := types.SessionLinearIssue{}
+			issue.ID, _ = m["id"].(string)
+			issue.Identifier, _ = m["identifier"].(string)
+			issue.Title, _ = m["title"].(string)
+			issue.URL, _ = m["url"].(string)
+			issue.Created, _ = m["created"].(bool)
+			issue.LinkedAt, _ = m["linkedAt"].(string)
+			result.LinearIssues = append(result.LinearIssues, issue)
+		}
+	}
+
+	// lastRunSummary retells the conversation; attachRunSummary adds it for callers
+	// allowed to read transcripts
+
 	return result
 }
 
@@ -375,6 +474,10 @@ func ListSessions(c *gin.Context) {
 		return
 	}
 	types.NormalizePaginationParams(&params)
+	fields, expand, ok := ParseListSelection(c, "runs")
+	if !ok {
+		return
+	}
 
 	// Build list options with pagination
 	// Note: Kubernetes List with Limit returns a continue token for server-side pagination
@@ -384,34 +487,18 @@ func ListSessions(c *gin.Context) {
 
 	list, err := k8sDyn.Resource(gvr).Namespace(project).List(ctx, v1.ListOptions{})
 	if err != nil {
-		log.Printf("Failed to list agentic sessions in project %s: %v", project, err)
+		logging.Errorf(c, "Failed to list agentic sessions in project %s: %v", project, err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list agentic sessions"})
 		return
 	}
 
+	withSummaries := callerCanReadTranscripts(c, project, "")
 	var sessions []types.AgenticSession
-	for _, item := range list.Items {
-		meta, _, err := unstructured.NestedMap(item.Object, "metadata")
-		if err != nil {
-			log.Printf("ListSessions: failed to read metadata for %s/%s: %v", project, item.GetName(), err)
-			meta = map[string]interface{}{}
-		}
-		session := types.AgenticSession{
-			APIVersion: item.GetAPIVersion(),
-			Kind:       item.GetKind(),
-			Metadata:   meta,
-		}
-
-		if spec, found, err := unstructured.NestedMap(item.Object, "spec"); err == nil && found {
-			session.Spec = parseSpec(spec)
+	for i := range list.Items {
+		session := sessionFromItem(c, &list.Items[i])
+		if withSummaries {
+			attachRunSummary(&session, list.Items[i].GetName())
 		}
-
-		if status, found, err := unstructured.NestedMap(item.Object, "status"); err == nil && found {
-			session.Status = parseStatus(status)
-		}
-
-		session.AutoBranch = ComputeAutoBranch(item.GetName())
-
 		sessions = append(sessions, session)
 	}
 
@@ -427,8 +514,19 @@ func ListSessions(c *gin.Context) {
 	totalCount := len(sessions)
 	paginatedSessions, hasMore, nextOffset := paginateSessions(sessions, params.Offset, params.Limit)
 
+	var items interface{} = paginatedSessions
+	if fields != nil || len(expand) > 0 {
+		selected, err := selectSessions(paginatedSessions, fields, expand, withSummaries)
+		if err != nil {
+			logging.Errorf(c, "ListSessions: failed to select fields: %v", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list agentic sessions"})
+			return
+		}
+		items = selected
+	}
+
 	response := types.PaginatedResponse{
-		Items:      paginatedSessions,
+		Items:      items,
 		TotalCount: totalCount,
 		Limit:      params.Limit,
 		Offset:     params.Offset,
@@ -441,6 +539,65 @@ func ListSessions(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
+// SessionRuns returns a session's AG-UI runs, for ?expand=runs. Set from main to
+// websocket.SessionRuns.
+var SessionRuns func(sessionName string) []types.AGUIRunMetadata
+
+// selectSessions adds the requested expansions to listed sessions and cuts them down
+// to the selected fields. Expanded runs keep their summaries only withSummaries, as
+// for the caller's transcripts access.
+func selectSessions(sessions []types.AgenticSession, fields FieldSelection, expand map[string]bool, withSummaries bool) ([]interface{}, error) {
+	out := make([]interface{}, 0, len(sessions))
+	for _, session := range sessions {
+		v, err := ToJSONValue(session)
+		if err != nil {
+			return nil, err
+		}
+		obj, _ := v.(map[string]interface{})
+		if expand["runs"] {
+			runs := []types.AGUIRunMetadata{}
+			if name, _ := session.Metadata["name"].(string); name != "" && SessionRuns != nil {
+				runs = SessionRuns(name)
+			}
+			if !withSummaries {
+				for i := range runs {
+					runs[i].Summary = nil
+				}
+			}
+			if obj["runs"], err = ToJSONValue(runs); err != nil {
+				return nil, err
+			}
+		}
+		out = append(out, fields.Select(obj))
+	}
+	return out, nil
+}
+
+// sessionFromItem converts a listed AgenticSession resource for API responses
+func sessionFromItem(ctx context.Context, item *unstructured.Unstructured) types.AgenticSession {
+	meta, _, err := unstructured.NestedMap(item.Object, "metadata")
+	if err != nil {
+		logging.Errorf(ctx, "failed to read metadata for session %s/%s: %v", item.GetNamespace(), item.GetName(), err)
+		meta = map[string]interface{}{}
+	}
+	session := types.AgenticSession{
+		APIVersion: item.GetAPIVersion(),
+		Kind:       item.GetKind(),
+		Metadata:   meta,
+	}
+
+	if spec, found, err := unstructured.NestedMap(item.Object, "spec"); err == nil && found {
+		session.Spec = parseSpec(spec)
+	}
+
+	if status, found, err := unstructured.NestedMap(item.Object, "status"); err == nil && found {
+		session.Status = parseStatus(status)
+	}
+
+	session.AutoBranch = ComputeAutoBranch(item.GetName())
+	return session
+}
+
 // filterSessionsBySearch filters sessions by search term (name or displayName)
 func filterSessionsBySearch(sessions []types.AgenticSession, search string) []types.AgenticSession {
 	if search == "" {
@@ -516,6 +673,48 @@ func paginateSessions(sessions []types.AgenticSession, offset, limit int) ([]typ
 	return sessions[offset:end], hasMore, nextOffset
 }
 
+// repoCloneOptions converts a spec repo's partial clone fields into git clone options
+func repoCloneOptions(r types.SimpleRepo) git.CloneOptions {
+	opts := git.CloneOptions{SparsePaths: r.SparsePaths}
+	if r.Depth != nil {
+		opts.Depth = *r.Depth
+	}
+	if r.SingleBranch != nil {
+		opts.SingleBranch = *r.SingleBranch
+	}
+	if r.LFS != nil {
+		opts.LFS = &git.LFSOptions{
+			SkipSmudge:         r.LFS.SkipSmudge,
+			MaxObjectSizeBytes: int64(r.LFS.MaxObjectSizeMB) << 20,
+		}
+	}
+	return opts
+}
+
+// setRepoCloneOptions copies a repo's partial clone fields onto its spec entry
+func setRepoCloneOptions(m map[string]interface{}, r types.SimpleRepo) {
+	if r.Depth != nil && *r.Depth > 0 {
+		m["depth"] = int64(*r.Depth)
+	}
+	if r.SingleBranch != nil {
+		m["singleBranch"] = *r.SingleBranch
+	}
+	if len(r.SparsePaths) > 0 {
+		paths := make([]interface{}, 0, len(r.SparsePaths))
+		for _, p := range r.SparsePaths {
+			paths = append(paths, strings.TrimSpace(p))
+		}
+		m["sparsePaths"] = paths
+	}
+	if r.LFS != nil {
+		lfs := map[string]interface{}{"skipSmudge": r.LFS.SkipSmudge}
+		if r.LFS.MaxObjectSizeMB > 0 {
+			lfs["maxObjectSizeMB"] = int64(r.LFS.MaxObjectSizeMB)
+		}
+		m["lfs"] = lfs
+	}
+}
+
 func CreateSession(c *gin.Context) {
 	project := c.GetString("project")
 
@@ -531,14 +730,35 @@ func CreateSession(c *gin.Context) {
 		return
 	}
 
+	// fromJiraIssue=KEY starts the session on a Jira ticket: the issue, its comments and
+	// linked PRs become the initial context
+	if key := c.Query("fromJiraIssue"); key != "" {
+		issue, ok := fetchJiraIssue(c, key)
+		if !ok {
+			return
+		}
+		applyJiraIssue(&req, issue)
+	}
+	// fromGitHubIssue=owner/repo#123 (or an issue or PR URL) does the same for GitHub,
+	// also cloning the repository, on the PR's branch for pull requests
+	if ref := c.Query("fromGitHubIssue"); ref != "" {
+		issue, ok := fetchGitHubIssue(c, reqK8s, k8sDyn, project, ref)
+		if !ok {
+			return
+		}
+		applyGitHubIssue(&req, issue)
+	}
+
 	// Validation for multi-repo can be added here if needed
 
-	// Set defaults for LLM settings if not provided
-	llmSettings := types.LLMSettings{
-		Model:       "sonnet",
-		Temperature: 0.7,
-		MaxTokens:   4000,
+	// Settings the request leaves out come from the project's session defaults
+	defaults, err := getSessionDefaults(c.Request.Context(), k8sDyn, project)
+	if err != nil {
+		logging.Errorf(c, "Failed to read session defaults for project %s: %v", project, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read project session defaults"})
+		return
 	}
+	llmSettings := defaults.LLMSettings
 	if req.LLMSettings != nil {
 		if req.LLMSettings.Model != "" {
 			llmSettings.Model = req.LLMSettings.Model
@@ -549,13 +769,56 @@ func CreateSession(c *gin.Context) {
 		if req.LLMSettings.MaxTokens != 0 {
 			llmSettings.MaxTokens = req.LLMSettings.MaxTokens
 		}
+		llmSettings.FallbackModels = req.LLMSettings.FallbackModels
+	}
+	if err := ValidateFallbackModels(llmSettings.Model, llmSettings.FallbackModels); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
 
-	timeout := 300
+	timeout := defaults.Timeout
 	if req.Timeout != nil {
 		timeout = *req.Timeout
 	}
 
+	runnerImage := strings.TrimSpace(req.RunnerImage)
+	if runnerImage != "" {
+		if err := validateRunnerImage(c.Request.Context(), k8sDyn, project, runnerImage); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	if req.Persona != "" {
+		if err := validateSessionPersona(c.Request.Context(), reqK8s, project, req.Persona); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	workspaceSize := ""
+	if req.Workspace != nil && strings.TrimSpace(req.Workspace.Size) != "" {
+		size, err := validateWorkspaceSize(c.Request.Context(), k8sDyn, project, req.Workspace.Size)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		workspaceSize = size.String()
+	}
+
+	for _, r := range req.Repos {
+		if err := git.ValidateCloneOptions(repoCloneOptions(r)); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid clone options for %s: %v", r.URL, err)})
+			return
+		}
+	}
+
+	// Pre-run check: autoPush repos must target a branch the user can push to
+	if err := validateAutoPushBranches(c.Request.Context(), reqK8s, k8sDyn, project, strings.TrimSpace(c.GetString("userID")), req.Repos); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
 	// Generate unique name (timestamp-based)
 	// Note: Runner will create branch as "ambient/{session-name}"
 	timestamp := time.Now().Unix()
@@ -595,6 +858,21 @@ func CreateSession(c *gin.Context) {
 	if strings.TrimSpace(req.InitialPrompt) != "" {
 		spec["initialPrompt"] = req.InitialPrompt
 	}
+	if runnerImage != "" {
+		spec["runnerImage"] = runnerImage
+	}
+	if len(llmSettings.FallbackModels) > 0 {
+		spec["llmSettings"].(map[string]interface{})["fallbackModels"] = fallbackModelsValue(llmSettings.FallbackModels)
+	}
+	if req.Spot {
+		spec["spot"] = true
+	}
+	if req.Persona != "" {
+		spec["persona"] = req.Persona
+	}
+	if workspaceSize != "" {
+		spec["workspace"] = map[string]interface{}{"size": workspaceSize}
+	}
 
 	session := map[string]interface{}{
 		"apiVersion": "vteam.ambient-code/v1alpha1",
@@ -621,10 +899,34 @@ func CreateSession(c *gin.Context) {
 		}
 		annotations := metadata["annotations"].(map[string]interface{})
 		annotations["vteam.ambient-code/parent-session-id"] = req.ParentSessionID
-		log.Printf("Creating continuation session from parent %s (operator will handle temp pod cleanup)", req.ParentSessionID)
+		logging.Infof(c, "Creating continuation session from parent %s (operator will handle temp pod cleanup)", req.ParentSessionID)
 		// Note: Operator will delete temp pod when session starts (desired-phase=Running)
 	}
 
+	// Snapshot the project's MCP tool policy; callers cannot supply or override it
+	delete(envVars, types.MCPToolPolicyEnvVar)
+	toolPolicy, err := mcpToolPolicyEnv(c.Request.Context(), k8sDyn, project)
+	if err != nil {
+		logging.Errorf(c, "Failed to load MCP tool policy for project %s: %v", project, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load project MCP tool policy"})
+		return
+	}
+	if toolPolicy != "" {
+		envVars[types.MCPToolPolicyEnvVar] = toolPolicy
+	}
+
+	// Snapshot the project's egress policy the same way
+	delete(envVars, types.EgressPolicyEnvVar)
+	egressPolicy, err := egressPolicyEnv(c.Request.Context(), k8sDyn, project)
+	if err != nil {
+		logging.Errorf(c, "Failed to load egress policy for project %s: %v", project, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load project egress policy"})
+		return
+	}
+	if egressPolicy != "" {
+		envVars[types.EgressPolicyEnvVar] = egressPolicy
+	}
+
 	if len(envVars) > 0 {
 		spec := session["spec"].(map[string]interface{})
 		spec["environmentVariables"] = envVars
@@ -651,6 +953,7 @@ func CreateSession(c *gin.Context) {
 				if r.AutoPush != nil {
 					m["autoPush"] = *r.AutoPush
 				}
+				setRepoCloneOptions(m, r)
 				arr = append(arr, m)
 			}
 			spec["repos"] = arr
@@ -690,13 +993,20 @@ func CreateSession(c *gin.Context) {
 		}
 	}
 
-	gvr := GetAgenticSessionV1Alpha1Resource()
 	obj := &unstructured.Unstructured{Object: session}
 
-	// Create AgenticSession using user token (enforces user RBAC permissions)
-	created, err := k8sDyn.Resource(gvr).Namespace(project).Create(context.TODO(), obj, v1.CreateOptions{})
+	// Create AgenticSession using user token (enforces user RBAC permissions), once
+	// admission finds room for the runner per the project's placement policy
+	created, admission, err := createAdmittedSession(c.Request.Context(), k8sDyn, obj)
 	if err != nil {
-		log.Printf("Failed to create agentic session in project %s: %v", project, err)
+		if rejected, ok := err.(*admissionRejectedError); ok {
+			c.JSON(http.StatusServiceUnavailable, gin.H{
+				"error":  fmt.Sprintf("Insufficient capacity to start a session: %s", rejected.reason),
+				"reason": "InsufficientCapacity",
+			})
+			return
+		}
+		logging.Errorf(c, "Failed to create agentic session in project %s: %v", project, err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create agentic session"})
 		return
 	}
@@ -733,6 +1043,7 @@ func CreateSession(c *gin.Context) {
 		"name":       name,
 		"uid":        created.GetUID(),
 		"autoBranch": ComputeAutoBranch(name),
+		"admission":  admission,
 	})
 }
 
@@ -747,6 +1058,9 @@ func GetSession(c *gin.Context) {
 		return
 	}
 	gvr := GetAgenticSessionV1Alpha1Resource()
+	if sessionNotModified(c, project, sessionName) {
+		return
+	}
 
 	item, err := k8sDyn.Resource(gvr).Namespace(project).Get(context.TODO(), sessionName, v1.GetOptions{})
 	if err != nil {
@@ -754,15 +1068,18 @@ func GetSession(c *gin.Context) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
 			return
 		}
-		log.Printf("Failed to get agentic session %s in project %s: %v", sessionName, project, err)
+		logging.Errorf(c, "Failed to get agentic session %s in project %s: %v", sessionName, project, err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get agentic session"})
 		return
 	}
+	if NotModified(c, ResourceETag(item.GetResourceVersion())) {
+		return
+	}
 
 	// Safely extract metadata using type-safe pattern
 	metadata, ok := item.Object["metadata"].(map[string]interface{})
 	if !ok {
-		log.Printf("GetSession: invalid metadata for session %s", sessionName)
+		logging.Errorf(c, "GetSession: invalid metadata for session %s", sessionName)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid session metadata"})
 		return
 	}
@@ -780,12 +1097,86 @@ func GetSession(c *gin.Context) {
 	if status, ok := item.Object["status"].(map[string]interface{}); ok {
 		session.Status = parseStatus(status)
 	}
+	if callerCanReadTranscripts(c, project, sessionName) {
+		attachRunSummary(&session, sessionName)
+	}
 
 	session.AutoBranch = ComputeAutoBranch(sessionName)
 
 	c.JSON(http.StatusOK, session)
 }
 
+// GetSessionConditions returns the session phase and operator-maintained conditions
+// (WorkspaceReady, RunnerReady, CredentialsResolved, ...) without the full spec.
+// RunActive reflects the AG-UI runs the backend is tracking for the session.
+// GET /api/projects/:projectName/agentic-sessions/:sessionName/conditions
+// Optional query: ?type=RunnerReady returns only that condition.
+func GetSessionConditions(c *gin.Context) {
+	project := c.GetString("project")
+	sessionName := c.Param("sessionName")
+
+	reqK8s, k8sDyn := GetK8sClientsForRequest(c)
+	if reqK8s == nil || k8sDyn == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing token"})
+		c.Abort()
+		return
+	}
+	gvr := GetAgenticSessionV1Alpha1Resource()
+
+	item, err := k8sDyn.Resource(gvr).Namespace(project).Get(c.Request.Context(), sessionName, v1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+			return
+		}
+		logging.Errorf(c, "Failed to get agentic session %s in project %s: %v", sessionName, project, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get agentic session"})
+		return
+	}
+
+	resp := types.SessionConditionsResponse{Conditions: []types.Condition{}}
+	parsed := &types.AgenticSessionStatus{}
+	if status, ok := item.Object["status"].(map[string]interface{}); ok {
+		parsed = parseStatus(status)
+		resp.Phase = parsed.Phase
+		resp.ObservedGeneration = parsed.ObservedGeneration
+	}
+	setRunActiveCondition(parsed, sessionName)
+	if condType := strings.TrimSpace(c.Query("type")); condType != "" {
+		if cond := parsed.FindCondition(condType); cond != nil {
+			resp.Conditions = append(resp.Conditions, *cond)
+		}
+	} else if len(parsed.Conditions) > 0 {
+		resp.Conditions = parsed.Conditions
+	}
+
+	// RunActive comes from in-memory run tracking, so the resourceVersion alone
+	// doesn't identify the response
+	JSONWithETag(c, http.StatusOK, resp)
+}
+
+// setRunActiveCondition sets RunActive from the AG-UI runs the backend is tracking for
+// the session. The operator only records why no run can be active (stopped, failed,
+// interrupted); that condition is kept while no run is in progress.
+func setRunActiveCondition(status *types.AgenticSessionStatus, sessionName string) {
+	active := 0
+	if ActiveRunCounts != nil {
+		active = ActiveRunCounts()[sessionName]
+	}
+	cond := types.Condition{Type: types.ConditionRunActive, Status: "False", Reason: "Idle", Message: "No run in progress"}
+	if active > 0 {
+		cond = types.Condition{Type: types.ConditionRunActive, Status: "True", Reason: "RunInProgress", Message: fmt.Sprintf("%d run(s) in progress", active)}
+	}
+	if existing := status.FindCondition(types.ConditionRunActive); existing != nil {
+		if active == 0 && existing.Status == "False" {
+			return
+		}
+		*existing = cond
+		return
+	}
+	status.Conditions = append(status.Conditions, cond)
+}
+
 // MintSessionGitHubToken validates the token via TokenReview, ensures SA matches CR annotation, and returns a short-lived GitHub token.
 // POST /api/projects/:projectName/agentic-sessions/:sessionName/github/token
 // Auth: Authorization: Bearer <BOT_TOKEN> (K8s SA token with audience "ambient-backend")
@@ -880,7 +1271,7 @@ func MintSessionGitHubToken(c *gin.Context) {
 	// Get GitHub token (GitHub App or PAT fallback via project runner secret)
 	tokenStr, err := GetGitHubToken(c.Request.Context(), K8sClient, DynamicClient, project, userID)
 	if err != nil {
-		log.Printf("Failed to get GitHub token for project %s: %v", project, err)
+		logging.Errorf(c, "Failed to get GitHub token for project %s: %v", project, err)
 		c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to retrieve GitHub token"})
 		return
 	}
@@ -948,7 +1339,7 @@ func PatchSession(c *gin.Context) {
 	// Update the resource
 	updated, err := k8sDyn.Resource(gvr).Namespace(project).Update(context.TODO(), item, v1.UpdateOptions{})
 	if err != nil {
-		log.Printf("Failed to patch agentic session %s: %v", sessionName, err)
+		logging.Errorf(c, "Failed to patch agentic session %s: %v", sessionName, err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to patch session"})
 		return
 	}
@@ -959,15 +1350,15 @@ func PatchSession(c *gin.Context) {
 func UpdateSession(c *gin.Context) {
 	project := c.GetString("project")
 	sessionName := c.Param("sessionName")
-	_, k8sDyn := GetK8sClientsForRequest(c)
-	if k8sDyn == nil {
+	reqK8s, k8sDyn := GetK8sClientsForRequest(c)
+	if reqK8s == nil || k8sDyn == nil {
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing token"})
 		c.Abort()
 		return
 	}
 	var req types.UpdateAgenticSessionRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		log.Printf("Invalid request body for UpdateSession (project=%s session=%s): %v", project, sessionName, err)
+		logging.Errorf(c, "Invalid request body for UpdateSession (project=%s session=%s): %v", project, sessionName, err)
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
 		return
 	}
@@ -986,7 +1377,7 @@ func UpdateSession(c *gin.Context) {
 			time.Sleep(300 * time.Millisecond)
 			continue
 		}
-		log.Printf("Failed to get agentic session %s in project %s: %v", sessionName, project, err)
+		logging.Errorf(c, "Failed to get agentic session %s in project %s: %v", sessionName, project, err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get agentic session"})
 		return
 	}
@@ -1028,6 +1419,13 @@ func UpdateSession(c *gin.Context) {
 		if req.LLMSettings.MaxTokens != 0 {
 			llmSettings["maxTokens"] = req.LLMSettings.MaxTokens
 		}
+		if len(req.LLMSettings.FallbackModels) > 0 {
+			if err := ValidateFallbackModels(req.LLMSettings.Model, req.LLMSettings.FallbackModels); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			llmSettings["fallbackModels"] = fallbackModelsValue(req.LLMSettings.FallbackModels)
+		}
 		spec["llmSettings"] = llmSettings
 	}
 
@@ -1035,10 +1433,23 @@ func UpdateSession(c *gin.Context) {
 		spec["timeout"] = *req.Timeout
 	}
 
+	// An empty persona clears it
+	if req.Persona != nil {
+		if *req.Persona == "" {
+			delete(spec, "persona")
+		} else {
+			if err := validateSessionPersona(c.Request.Context(), reqK8s, project, *req.Persona); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			spec["persona"] = *req.Persona
+		}
+	}
+
 	// Update the resource
 	updated, err := k8sDyn.Resource(gvr).Namespace(project).Update(context.TODO(), item, v1.UpdateOptions{})
 	if err != nil {
-		log.Printf("Failed to update agentic session %s in project %s: %v", sessionName, project, err)
+		logging.Errorf(c, "Failed to update agentic session %s in project %s: %v", sessionName, project, err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update agentic session"})
 		return
 	}
@@ -1073,28 +1484,6 @@ func UpdateSessionDisplayName(c *gin.Context) {
 		return
 	}
 
-	// RBAC check: verify user has update permission on agenticsessions in this namespace
-	ssar := &authzv1.SelfSubjectAccessReview{
-		Spec: authzv1.SelfSubjectAccessReviewSpec{
-			ResourceAttributes: &authzv1.ResourceAttributes{
-				Group:     "vteam.ambient-code",
-				Resource:  "agenticsessions",
-				Verb:      "update",
-				Namespace: project,
-			},
-		},
-	}
-	res, err := k8sClt.AuthorizationV1().SelfSubjectAccessReviews().Create(c.Request.Context(), ssar, v1.CreateOptions{})
-	if err != nil {
-		log.Printf("RBAC check failed for update session display name in project %s: %v", project, err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify permissions"})
-		return
-	}
-	if !res.Status.Allowed {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Unauthorized to update session in this project"})
-		return
-	}
-
 	var req struct {
 		DisplayName string `json:"displayName" binding:"required"`
 	}
@@ -1118,7 +1507,7 @@ func UpdateSessionDisplayName(c *gin.Context) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
 			return
 		}
-		log.Printf("Failed to get agentic session %s in project %s: %v", sessionName, project, err)
+		logging.Errorf(c, "Failed to get agentic session %s in project %s: %v", sessionName, project, err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get agentic session"})
 		return
 	}
@@ -1126,7 +1515,7 @@ func UpdateSessionDisplayName(c *gin.Context) {
 	// Use unstructured helper for safe type access (per CLAUDE.md guidelines)
 	spec, found, err := unstructured.NestedMap(item.Object, "spec")
 	if err != nil {
-		log.Printf("Failed to get spec from session %s in project %s: %v", sessionName, project, err)
+		logging.Errorf(c, "Failed to get spec from session %s in project %s: %v", sessionName, project, err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse session spec"})
 		return
 	}
@@ -1137,7 +1526,7 @@ func UpdateSessionDisplayName(c *gin.Context) {
 
 	// Set the updated spec back using unstructured helper
 	if err := unstructured.SetNestedMap(item.Object, spec, "spec"); err != nil {
-		log.Printf("Failed to set spec for session %s in project %s: %v", sessionName, project, err)
+		logging.Errorf(c, "Failed to set spec for session %s in project %s: %v", sessionName, project, err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update session spec"})
 		return
 	}
@@ -1145,7 +1534,7 @@ func UpdateSessionDisplayName(c *gin.Context) {
 	// Persist the change
 	updated, err := k8sDyn.Resource(gvr).Namespace(project).Update(context.TODO(), item, v1.UpdateOptions{})
 	if err != nil {
-		log.Printf("Failed to update display name for agentic session %s in project %s: %v", sessionName, project, err)
+		logging.Errorf(c, "Failed to update display name for agentic session %s in project %s: %v", sessionName, project, err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update display name"})
 		return
 	}
@@ -1195,7 +1584,7 @@ func SelectWorkflow(c *gin.Context) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
 			return
 		}
-		log.Printf("Failed to get agentic session %s in project %s: %v", sessionName, project, err)
+		logging.Errorf(c, "Failed to get agentic session %s in project %s: %v", sessionName, project, err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get agentic session"})
 		return
 	}
@@ -1231,12 +1620,12 @@ func SelectWorkflow(c *gin.Context) {
 	// Persist the change
 	updated, err := k8sDyn.Resource(gvr).Namespace(project).Update(context.TODO(), item, v1.UpdateOptions{})
 	if err != nil {
-		log.Printf("Failed to update workflow for agentic session %s in project %s: %v", sessionName, project, err)
+		logging.Errorf(c, "Failed to update workflow for agentic session %s in project %s: %v", sessionName, project, err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update workflow"})
 		return
 	}
 
-	log.Printf("Workflow updated for session %s: %s@%s", sessionName, req.GitURL, branch)
+	logging.Infof(c, "Workflow updated for session %s: %s@%s", sessionName, req.GitURL, branch)
 
 	// Respond with updated session summary
 	session := types.AgenticSession{
@@ -1291,7 +1680,7 @@ func AddRepo(c *gin.Context) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
 			return
 		}
-		log.Printf("Failed to get session %s in project %s: %v", sessionName, project, err)
+		logging.Errorf(c, "Failed to get session %s in project %s: %v", sessionName, project, err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get session"})
 		return
 	}
@@ -1312,7 +1701,13 @@ func AddRepo(c *gin.Context) {
 	status, _ := item.Object["status"].(map[string]interface{})
 	phase, _ := status["phase"].(string)
 	if phase == "Running" {
-		runnerURL := fmt.Sprintf("http://session-%s.%s.svc.cluster.local:8001/repos/add", sessionName, project)
+		runnerBase, err := RunnerEndpoint(c.Request.Context(), project, sessionName)
+		if err != nil {
+			logging.Errorf(c, "Failed to resolve runner endpoint: %v", err)
+			c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to reach session runner"})
+			return
+		}
+		runnerURL := runnerBase + "repos/add"
 		runnerReq := map[string]string{
 			"url":    req.URL,
 			"branch": req.Branch,
@@ -1320,10 +1715,10 @@ func AddRepo(c *gin.Context) {
 		}
 		reqBody, _ := json.Marshal(runnerReq)
 
-		log.Printf("Calling runner to clone repo: %s -> %s", req.URL, runnerURL)
+		logging.Infof(c, "Calling runner to clone repo: %s -> %s", req.URL, runnerURL)
 		httpReq, err := http.NewRequestWithContext(c.Request.Context(), "POST", runnerURL, bytes.NewReader(reqBody))
 		if err != nil {
-			log.Printf("Failed to create runner request: %v", err)
+			logging.Errorf(c, "Failed to create runner request: %v", err)
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create runner request"})
 			return
 		}
@@ -1349,25 +1744,26 @@ func AddRepo(c *gin.Context) {
 				if GetGitHubToken != nil {
 					if token, err := GetGitHubToken(c.Request.Context(), k8sClt, k8sDyn, project, userID); err == nil && token != "" {
 						httpReq.Header.Set("X-GitHub-Token", token)
-						log.Printf("AddRepo: configured authentication for project=%s session=%s", project, sessionName)
+						logging.Infof(c, "AddRepo: configured authentication for project=%s session=%s", project, sessionName)
 					}
 				}
 			case types.ProviderGitLab:
 				if GetGitLabToken != nil {
 					if token, err := GetGitLabToken(c.Request.Context(), k8sClt, project, userID); err == nil && token != "" {
 						httpReq.Header.Set("X-GitLab-Token", token)
-						log.Printf("AddRepo: configured authentication for project=%s session=%s", project, sessionName)
+						logging.Infof(c, "AddRepo: configured authentication for project=%s session=%s", project, sessionName)
 					}
 				}
 			default:
-				log.Printf("AddRepo: unknown provider detected, proceeding without authentication")
+				logging.Warnf(c, "AddRepo: unknown provider detected, proceeding without authentication")
 			}
 		}
 
+		logging.SetRequestIDHeader(httpReq)
 		client := &http.Client{Timeout: 120 * time.Second} // Allow time for clone
 		resp, err := client.Do(httpReq)
 		if err != nil {
-			log.Printf("Failed to call runner to clone repo: %v", err)
+			logging.Errorf(c, "Failed to call runner to clone repo: %v", err)
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to clone repository (runner not reachable)"})
 			return
 		}
@@ -1375,11 +1771,11 @@ func AddRepo(c *gin.Context) {
 
 		if resp.StatusCode != http.StatusOK {
 			body, _ := io.ReadAll(resp.Body)
-			log.Printf("Runner failed to clone repo (status %d): %s", resp.StatusCode, string(body))
+			logging.Errorf(c, "Runner failed to clone repo (status %d): %s", resp.StatusCode, string(body))
 			c.JSON(resp.StatusCode, gin.H{"error": fmt.Sprintf("Failed to clone repository: %s", string(body))})
 			return
 		}
-		log.Printf("Runner successfully cloned repo %s for session %s", repoName, sessionName)
+		logging.Infof(c, "Runner successfully cloned repo %s for session %s", repoName, sessionName)
 	}
 
 	// Update spec.repos
@@ -1406,7 +1802,7 @@ func AddRepo(c *gin.Context) {
 	// Persist change
 	updated, err := k8sDyn.Resource(gvr).Namespace(project).Update(context.TODO(), item, v1.UpdateOptions{})
 	if err != nil {
-		log.Printf("Failed to update session %s in project %s: %v", sessionName, project, err)
+		logging.Errorf(c, "Failed to update session %s in project %s: %v", sessionName, project, err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update session"})
 		return
 	}
@@ -1423,7 +1819,7 @@ func AddRepo(c *gin.Context) {
 		session.Status = parseStatus(statusMap)
 	}
 
-	log.Printf("Added repository %s to session %s in project %s", req.URL, sessionName, project)
+	logging.Infof(c, "Added repository %s to session %s in project %s", req.URL, sessionName, project)
 	c.JSON(http.StatusOK, gin.H{"message": "Repository added", "name": repoName, "session": session})
 }
 
@@ -1446,7 +1842,7 @@ func RemoveRepo(c *gin.Context) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
 			return
 		}
-		log.Printf("Failed to get session %s in project %s: %v", sessionName, project, err)
+		logging.Errorf(c, "Failed to get session %s in project %s: %v", sessionName, project, err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get session"})
 		return
 	}
@@ -1480,13 +1876,13 @@ func RemoveRepo(c *gin.Context) {
 	// Note: status map is read-only here, not persisted back to CR
 	status, found, err := unstructured.NestedMap(item.Object, "status")
 	if !found || err != nil {
-		log.Printf("Failed to get status: %v", err)
+		logging.Errorf(c, "Failed to get status: %v", err)
 		status = make(map[string]interface{}) // Local empty map for safe reads
 	}
 
 	reconciledRepos, found, err := unstructured.NestedSlice(status, "reconciledRepos")
 	if !found || err != nil {
-		log.Printf("Failed to get reconciledRepos: %v", err)
+		logging.Errorf(c, "Failed to get reconciledRepos: %v", err)
 		reconciledRepos = []interface{}{}
 	}
 
@@ -1516,20 +1912,23 @@ func RemoveRepo(c *gin.Context) {
 	phase, _, _ := unstructured.NestedString(status, "phase")
 	runnerRemoved := false
 	if phase == "Running" {
-		runnerURL := fmt.Sprintf("http://session-%s.%s.svc.cluster.local:8001/repos/remove", sessionName, project)
 		runnerReq := map[string]string{"name": repoName}
 		reqBody, _ := json.Marshal(runnerReq)
-		resp, err := http.Post(runnerURL, "application/json", bytes.NewReader(reqBody))
+		var resp *http.Response
+		runnerBase, err := RunnerEndpoint(c.Request.Context(), project, sessionName)
+		if err == nil {
+			resp, err = http.Post(runnerBase+"repos/remove", "application/json", bytes.NewReader(reqBody))
+		}
 		if err != nil {
-			log.Printf("Warning: failed to call runner /repos/remove: %v", err)
+			logging.Warnf(c, "Failed to call runner /repos/remove: %v", err)
 		} else {
 			defer resp.Body.Close()
 			if resp.StatusCode == http.StatusOK {
 				runnerRemoved = true
-				log.Printf("Runner successfully removed repo %s from filesystem", repoName)
+				logging.Infof(c, "Runner successfully removed repo %s from filesystem", repoName)
 			} else {
 				body, _ := io.ReadAll(resp.Body)
-				log.Printf("Runner failed to remove repo %s (status %d): %s", repoName, resp.StatusCode, string(body))
+				logging.Errorf(c, "Runner failed to remove repo %s (status %d): %s", repoName, resp.StatusCode, string(body))
 			}
 		}
 	}
@@ -1545,7 +1944,7 @@ func RemoveRepo(c *gin.Context) {
 	// Persist change
 	updated, err := reqDyn.Resource(gvr).Namespace(project).Update(context.TODO(), item, v1.UpdateOptions{})
 	if err != nil {
-		log.Printf("Failed to update session %s in project %s: %v", sessionName, project, err)
+		logging.Errorf(c, "Failed to update session %s in project %s: %v", sessionName, project, err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update session"})
 		return
 	}
@@ -1562,7 +1961,7 @@ func RemoveRepo(c *gin.Context) {
 		session.Status = parseStatus(statusMap)
 	}
 
-	log.Printf("Removed repository %s from session %s in project %s", repoName, sessionName, project)
+	logging.Infof(c, "Removed repository %s from session %s in project %s", repoName, sessionName, project)
 	c.JSON(http.StatusOK, gin.H{"message": "Repository removed", "session": session})
 }
 
@@ -1583,7 +1982,7 @@ func GetWorkflowMetadata(c *gin.Context) {
 	sessionName := c.Param("sessionName")
 
 	if project == "" {
-		log.Printf("GetWorkflowMetadata: project is empty, session=%s", sessionName)
+		logging.Infof(c, "GetWorkflowMetadata: project is empty, session=%s", sessionName)
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Project namespace required"})
 		return
 	}
@@ -1609,17 +2008,18 @@ func GetWorkflowMetadata(c *gin.Context) {
 	endpoint := fmt.Sprintf("http://%s.%s.svc:8080", serviceName, project)
 	u := fmt.Sprintf("%s/content/workflow-metadata?session=%s", endpoint, sessionName)
 
-	log.Printf("GetWorkflowMetadata: project=%s session=%s endpoint=%s", project, sessionName, endpoint)
+	logging.Infof(c, "GetWorkflowMetadata: project=%s session=%s endpoint=%s", project, sessionName, endpoint)
 
 	// Create and send request to content pod
 	req, _ := http.NewRequestWithContext(c.Request.Context(), http.MethodGet, u, nil)
 	if strings.TrimSpace(token) != "" {
 		req.Header.Set("Authorization", token)
 	}
+	logging.SetRequestIDHeader(req)
 	client := &http.Client{Timeout: 4 * time.Second}
 	resp, err := client.Do(req)
 	if err != nil {
-		log.Printf("GetWorkflowMetadata: content service request failed: %v", err)
+		logging.Errorf(c, "GetWorkflowMetadata: content service request failed: %v", err)
 		// Return empty metadata on error
 		c.JSON(http.StatusOK, gin.H{"commands": []interface{}{}, "agents": []interface{}{}})
 		return
@@ -1628,14 +2028,14 @@ func GetWorkflowMetadata(c *gin.Context) {
 
 	b, err := io.ReadAll(resp.Body)
 	if err != nil {
-		log.Printf("GetWorkflowMetadata: failed to read response body: %v", err)
+		logging.Errorf(c, "GetWorkflowMetadata: failed to read response body: %v", err)
 		c.JSON(http.StatusOK, gin.H{"commands": []interface{}{}, "agents": []interface{}{}})
 		return
 	}
 
 	// Log if content service returned an error
 	if resp.StatusCode >= 400 {
-		log.Printf("GetWorkflowMetadata: content service returned error status %d: %s", resp.StatusCode, string(b))
+		logging.Errorf(c, "GetWorkflowMetadata: content service returned error status %d: %s", resp.StatusCode, string(b))
 	}
 
 	c.Data(resp.StatusCode, "application/json", b)
@@ -1756,7 +2156,7 @@ func ListOOTBWorkflows(c *gin.Context) {
 	if ootbCache.cacheKey == cacheKey && time.Since(ootbCache.cachedAt) < ootbCacheTTL && len(ootbCache.workflows) > 0 {
 		workflows := ootbCache.workflows
 		ootbCache.mu.RUnlock()
-		log.Printf("ListOOTBWorkflows: returning %d cached workflows (age: %v)", len(workflows), time.Since(ootbCache.cachedAt).Round(time.Second))
+		logging.Infof(c, "ListOOTBWorkflows: returning %d cached workflows (age: %v)", len(workflows), time.Since(ootbCache.cachedAt).Round(time.Second))
 		c.JSON(http.StatusOK, gin.H{"workflows": workflows})
 		return
 	}
@@ -1774,21 +2174,21 @@ func ListOOTBWorkflows(c *gin.Context) {
 			if userIDStr, ok := usrID.(string); ok && userIDStr != "" {
 				if githubToken, err := GetGitHubToken(c.Request.Context(), k8sClt, sessDyn, project, userIDStr); err == nil {
 					token = githubToken
-					log.Printf("ListOOTBWorkflows: using user's GitHub token for project %s (better rate limits)", project)
+					logging.Infof(c, "ListOOTBWorkflows: using user's GitHub token for project %s (better rate limits)", project)
 				} else {
-					log.Printf("ListOOTBWorkflows: failed to get GitHub token for project %s: %v", project, err)
+					logging.Errorf(c, "ListOOTBWorkflows: failed to get GitHub token for project %s: %v", project, err)
 				}
 			}
 		}
 	}
 	if token == "" {
-		log.Printf("ListOOTBWorkflows: proceeding without GitHub token (public repo, lower rate limits)")
+		logging.Warnf(c, "ListOOTBWorkflows: proceeding without GitHub token (public repo, lower rate limits)")
 	}
 
 	// Parse GitHub URL
 	owner, repoName, err := git.ParseGitHubURL(ootbRepo)
 	if err != nil {
-		log.Printf("ListOOTBWorkflows: invalid repo URL: %v", err)
+		logging.Errorf(c, "ListOOTBWorkflows: invalid repo URL: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Invalid OOTB repo URL"})
 		return
 	}
@@ -1796,13 +2196,13 @@ func ListOOTBWorkflows(c *gin.Context) {
 	// List workflow directories
 	entries, err := fetchGitHubDirectoryListing(c.Request.Context(), owner, repoName, ootbBranch, ootbWorkflowsPath, token)
 	if err != nil {
-		log.Printf("ListOOTBWorkflows: failed to list workflows directory: %v", err)
+		logging.Errorf(c, "ListOOTBWorkflows: failed to list workflows directory: %v", err)
 		// On error, try to return stale cache if available
 		ootbCache.mu.RLock()
 		if len(ootbCache.workflows) > 0 && ootbCache.cacheKey == cacheKey {
 			workflows := ootbCache.workflows
 			ootbCache.mu.RUnlock()
-			log.Printf("ListOOTBWorkflows: returning stale cached workflows due to GitHub error")
+			logging.Errorf(c, "ListOOTBWorkflows: returning stale cached workflows due to GitHub error")
 			c.JSON(http.StatusOK, gin.H{"workflows": workflows})
 			return
 		}
@@ -1839,7 +2239,7 @@ func ListOOTBWorkflows(c *gin.Context) {
 		if err == nil {
 			// Parse ambient.json if found
 			if parseErr := json.Unmarshal(ambientData, &ambientConfig); parseErr != nil {
-				log.Printf("ListOOTBWorkflows: failed to parse ambient.json for %s: %v", entryName, parseErr)
+				logging.Errorf(c, "ListOOTBWorkflows: failed to parse ambient.json for %s: %v", entryName, parseErr)
 			}
 		}
 
@@ -1868,7 +2268,7 @@ func ListOOTBWorkflows(c *gin.Context) {
 	ootbCache.cacheKey = cacheKey
 	ootbCache.mu.Unlock()
 
-	log.Printf("ListOOTBWorkflows: discovered %d workflows from %s (cached for %v)", len(workflows), ootbRepo, ootbCacheTTL)
+	logging.Infof(c, "ListOOTBWorkflows: discovered %d workflows from %s (cached for %v)", len(workflows), ootbRepo, ootbCacheTTL)
 	c.JSON(http.StatusOK, gin.H{"workflows": workflows})
 }
 
@@ -1889,7 +2289,7 @@ func DeleteSession(c *gin.Context) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
 			return
 		}
-		log.Printf("Failed to delete agentic session %s in project %s: %v", sessionName, project, err)
+		logging.Errorf(c, "Failed to delete agentic session %s in project %s: %v", sessionName, project, err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete agentic session"})
 		return
 	}
@@ -1921,7 +2321,7 @@ func CloneSession(c *gin.Context) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Source session not found"})
 			return
 		}
-		log.Printf("Failed to get source agentic session %s in project %s: %v", sessionName, project, err)
+		logging.Errorf(c, "Failed to get source agentic session %s in project %s: %v", sessionName, project, err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get source agentic session"})
 		return
 	}
@@ -1965,7 +2365,7 @@ func CloneSession(c *gin.Context) {
 		}
 		if getErr != nil && !errors.IsNotFound(getErr) {
 			// On unexpected error, still attempt to proceed with a duplicate suffix to reduce collision chance
-			log.Printf("cloneSession: name check encountered error for %s/%s: %v", req.TargetProject, finalName, getErr)
+			logging.Errorf(c, "cloneSession: name check encountered error for %s/%s: %v", req.TargetProject, finalName, getErr)
 		}
 		conflicted = true
 		if i == 0 {
@@ -2002,9 +2402,9 @@ func CloneSession(c *gin.Context) {
 
 	obj := &unstructured.Unstructured{Object: clonedSession}
 
-	created, err := k8sDyn.Resource(gvr).Namespace(req.TargetProject).Create(context.TODO(), obj, v1.CreateOptions{})
+	created, _, err := createAdmittedSession(context.TODO(), k8sDyn, obj)
 	if err != nil {
-		log.Printf("Failed to create cloned agentic session in project %s: %v", req.TargetProject, err)
+		logging.Errorf(c, "Failed to create cloned agentic session in project %s: %v", req.TargetProject, err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create cloned agentic session"})
 		return
 	}
@@ -2046,7 +2446,7 @@ func StartSession(c *gin.Context) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
 			return
 		}
-		log.Printf("Failed to get agentic session %s in project %s: %v", sessionName, project, err)
+		logging.Errorf(c, "Failed to get agentic session %s in project %s: %v", sessionName, project, err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get agentic session"})
 		return
 	}
@@ -2054,7 +2454,7 @@ func StartSession(c *gin.Context) {
 	// Log current phase for debugging
 	if currentStatus, ok := item.Object["status"].(map[string]interface{}); ok {
 		if phase, ok := currentStatus["phase"].(string); ok {
-			log.Printf("StartSession: Current phase is %s", phase)
+			logging.Infof(c, "StartSession: Current phase is %s", phase)
 		}
 	}
 
@@ -2067,6 +2467,7 @@ func StartSession(c *gin.Context) {
 	// Signal start/restart request to operator
 	annotations["ambient-code.io/desired-phase"] = "Running"
 	annotations["ambient-code.io/start-requested-at"] = time.Now().Format(time.RFC3339)
+	delete(annotations, idlePausedAnnotation)
 
 	// Clean up self-referential parent-session-id annotations.
 	// Old code used to set parent-session-id to the session's own name for PVC reuse,
@@ -2075,7 +2476,7 @@ func StartSession(c *gin.Context) {
 	// Keep legitimate parent-session-id annotations (pointing to a DIFFERENT session).
 	if existingParent, ok := annotations["vteam.ambient-code/parent-session-id"]; ok {
 		if existingParent == sessionName {
-			log.Printf("StartSession: Clearing self-referential parent-session-id annotation")
+			logging.Infof(c, "StartSession: Clearing self-referential parent-session-id annotation")
 			delete(annotations, "vteam.ambient-code/parent-session-id")
 		}
 	}
@@ -2086,19 +2487,19 @@ func StartSession(c *gin.Context) {
 	if spec, ok := item.Object["spec"].(map[string]interface{}); ok {
 		if interactive, ok := spec["interactive"].(bool); !ok || !interactive {
 			spec["interactive"] = true
-			log.Printf("StartSession: Converting headless session to interactive for continuation")
+			logging.Infof(c, "StartSession: Converting headless session to interactive for continuation")
 		}
 	}
 
 	// Update spec and annotations (operator will observe and handle job lifecycle)
 	updated, err := k8sDyn.Resource(gvr).Namespace(project).Update(context.TODO(), item, v1.UpdateOptions{})
 	if err != nil {
-		log.Printf("Failed to update agentic session %s in project %s: %v", sessionName, project, err)
+		logging.Errorf(c, "Failed to update agentic session %s in project %s: %v", sessionName, project, err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update session"})
 		return
 	}
 
-	log.Printf("StartSession: Set desired-phase=Running annotation (operator will reconcile)")
+	logging.Infof(c, "StartSession: Set desired-phase=Running annotation (operator will reconcile)")
 
 	// Parse and return updated session
 	session := types.AgenticSession{
@@ -2177,7 +2578,7 @@ func StopSession(c *gin.Context) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
 			return
 		}
-		log.Printf("Failed to get agentic session %s in project %s: %v", sessionName, project, err)
+		logging.Errorf(c, "Failed to get agentic session %s in project %s: %v", sessionName, project, err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get agentic session"})
 		return
 	}
@@ -2191,13 +2592,15 @@ func StopSession(c *gin.Context) {
 	// Signal stop request to operator
 	annotations["ambient-code.io/desired-phase"] = "Stopped"
 	annotations["ambient-code.io/stop-requested-at"] = time.Now().Format(time.RFC3339)
+	// A stop by the user isn't undone by the next run request
+	delete(annotations, idlePausedAnnotation)
 	item.SetAnnotations(annotations)
 
 	// Force interactive mode so session can be restarted later
 	if spec, ok := item.Object["spec"].(map[string]interface{}); ok {
 		if interactive, ok := spec["interactive"].(bool); !ok || !interactive {
 			spec["interactive"] = true
-			log.Printf("StopSession: Converting headless session to interactive for future restart capability")
+			logging.Infof(c, "StopSession: Converting headless session to interactive for future restart capability")
 		}
 	}
 
@@ -2208,12 +2611,12 @@ func StopSession(c *gin.Context) {
 			c.JSON(http.StatusOK, gin.H{"message": "Session no longer exists (already deleted)"})
 			return
 		}
-		log.Printf("Failed to update agentic session %s: %v", sessionName, err)
+		logging.Errorf(c, "Failed to update agentic session %s: %v", sessionName, err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update session"})
 		return
 	}
 
-	log.Printf("StopSession: Set desired-phase=Stopped annotation (operator will reconcile)")
+	logging.Infof(c, "StopSession: Set desired-phase=Stopped annotation (operator will reconcile)")
 
 	session := types.AgenticSession{
 		APIVersion: updated.GetAPIVersion(),
@@ -2286,13 +2689,13 @@ func GetSessionK8sResources(c *gin.Context) {
 		result["jobConditions"] = job.Status.Conditions
 	} else if errors.IsNotFound(err) {
 		// Job not found - don't return job info at all
-		log.Printf("GetSessionK8sResources: Job %s not found, omitting from response", jobName)
+		logging.Errorf(c, "GetSessionK8sResources: Job %s not found, omitting from response", jobName)
 		// Don't include jobName or jobStatus in result
 	} else {
 		// Other error - still show job name but with error status
 		result["jobName"] = jobName
 		result["jobStatus"] = "Error"
-		log.Printf("GetSessionK8sResources: Error getting job %s: %v", jobName, err)
+		logging.Errorf(c, "GetSessionK8sResources: Error getting job %s: %v", jobName, err)
 	}
 
 	// Get Pods for this job (only if job exists)
@@ -2346,10 +2749,22 @@ func GetSessionK8sResources(c *gin.Context) {
 
 	result["pods"] = podInfos
 
-	// PVCs deprecated - sessions now use EmptyDir with S3 state persistence
-	result["pvcExists"] = false
-	result["pvcName"] = "N/A (using EmptyDir + S3)"
-	result["storageMode"] = "EmptyDir + S3"
+	// Sessions use EmptyDir with S3 state persistence unless spec.workspace.size asks for a PVC
+	if size, _, _ := unstructured.NestedString(session.Object, "spec", "workspace", "size"); size != "" {
+		pvcName := workspacePVCName(sessionName)
+		pvc, err := k8sClt.CoreV1().PersistentVolumeClaims(project).Get(c.Request.Context(), pvcName, v1.GetOptions{})
+		result["pvcExists"] = err == nil
+		result["pvcName"] = pvcName
+		result["storageMode"] = "PVC + S3"
+		result["workspaceSize"] = size
+		if err == nil {
+			result["pvcCapacity"] = pvc.Status.Capacity.Storage().String()
+		}
+	} else {
+		result["pvcExists"] = false
+		result["pvcName"] = "N/A (using EmptyDir + S3)"
+		result["storageMode"] = "EmptyDir + S3"
+	}
 
 	c.JSON(http.StatusOK, result)
 }
@@ -2366,7 +2781,7 @@ func ListSessionWorkspace(c *gin.Context) {
 	session := c.Param("sessionName")
 
 	if project == "" {
-		log.Printf("ListSessionWorkspace: project is empty, session=%s", session)
+		logging.Infof(c, "ListSessionWorkspace: project is empty, session=%s", session)
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Project namespace required"})
 		return
 	}
@@ -2398,20 +2813,21 @@ func ListSessionWorkspace(c *gin.Context) {
 
 	endpoint := fmt.Sprintf("http://%s.%s.svc:8080", serviceName, project)
 	u := fmt.Sprintf("%s/content/list?path=%s", endpoint, url.QueryEscape(absPath))
-	log.Printf("ListSessionWorkspace: project=%s session=%s endpoint=%s", project, session, endpoint)
+	logging.Infof(c, "ListSessionWorkspace: project=%s session=%s endpoint=%s", project, session, endpoint)
 	req, err := http.NewRequestWithContext(c.Request.Context(), http.MethodGet, u, nil)
 	if err != nil {
-		log.Printf("ListSessionWorkspace: failed to create HTTP request: %v", err)
+		logging.Errorf(c, "ListSessionWorkspace: failed to create HTTP request: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create request"})
 		return
 	}
 	if strings.TrimSpace(token) != "" {
 		req.Header.Set("Authorization", token)
 	}
+	logging.SetRequestIDHeader(req)
 	client := &http.Client{Timeout: 4 * time.Second}
 	resp, err := client.Do(req)
 	if err != nil {
-		log.Printf("ListSessionWorkspace: content service request failed: %v", err)
+		logging.Errorf(c, "ListSessionWorkspace: content service request failed: %v", err)
 		// Soften error to 200 with empty list so UI doesn't spam
 		c.JSON(http.StatusOK, gin.H{"items": []any{}})
 		return
@@ -2419,19 +2835,19 @@ func ListSessionWorkspace(c *gin.Context) {
 	defer resp.Body.Close()
 	b, err := io.ReadAll(resp.Body)
 	if err != nil {
-		log.Printf("ListSessionWorkspace: failed to read response body: %v", err)
+		logging.Errorf(c, "ListSessionWorkspace: failed to read response body: %v", err)
 		c.JSON(http.StatusOK, gin.H{"items": []any{}})
 		return
 	}
 
 	// Log if content service returned an error (other than 404 which is handled below)
 	if resp.StatusCode >= 400 && resp.StatusCode != http.StatusNotFound {
-		log.Printf("ListSessionWorkspace: content service returned error status %d: %s", resp.StatusCode, string(b))
+		logging.Errorf(c, "ListSessionWorkspace: content service returned error status %d: %s", resp.StatusCode, string(b))
 	}
 
 	// If content service returns 404, check if it's because workspace doesn't exist yet
 	if resp.StatusCode == http.StatusNotFound {
-		log.Printf("ListSessionWorkspace: workspace not found (may not be created yet by runner)")
+		logging.Errorf(c, "ListSessionWorkspace: workspace not found (may not be created yet by runner)")
 		// Return empty list instead of error for better UX during session startup
 		c.JSON(http.StatusOK, gin.H{"items": []any{}})
 		return
@@ -2450,7 +2866,7 @@ func GetSessionWorkspaceFile(c *gin.Context) {
 	session := c.Param("sessionName")
 
 	if project == "" {
-		log.Printf("GetSessionWorkspaceFile: project is empty, session=%s", session)
+		logging.Infof(c, "GetSessionWorkspaceFile: project is empty, session=%s", session)
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Project namespace required"})
 		return
 	}
@@ -2471,40 +2887,48 @@ func GetSessionWorkspaceFile(c *gin.Context) {
 		token = c.GetHeader("X-Forwarded-Access-Token")
 	}
 
-	// Use ambient-content service (per-session content service)
-	serviceName := fmt.Sprintf("ambient-content-%s", session)
-
-	endpoint := fmt.Sprintf("http://%s.%s.svc:8080", serviceName, project)
-	u := fmt.Sprintf("%s/content/file?path=%s", endpoint, url.QueryEscape(absPath))
-	req, err := http.NewRequestWithContext(c.Request.Context(), http.MethodGet, u, nil)
+	status, contentType, b, err := readSessionWorkspaceFile(c.Request.Context(), project, session, absPath, token)
 	if err != nil {
-		log.Printf("GetSessionWorkspaceFile: failed to create HTTP request: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create request"})
+		logging.Errorf(c, "GetSessionWorkspaceFile: %v", err)
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
 		return
 	}
-	if strings.TrimSpace(token) != "" {
-		req.Header.Set("Authorization", token)
+
+	// Log if content service returned an error
+	if status >= 400 {
+		logging.Errorf(c, "GetSessionWorkspaceFile: content service returned error status %d for path %s", status, sub)
+	}
+
+	c.Data(status, contentType, b)
+}
+
+// readSessionWorkspaceFile reads relPath (relative to /workspace) through the running
+// session's content service, forwarding the caller's authorization. It returns the
+// content service's status, content type and response body; err is set when the
+// service couldn't be reached.
+func readSessionWorkspaceFile(ctx context.Context, project, session, relPath, authorization string) (int, string, []byte, error) {
+	// Use ambient-content service (per-session content service)
+	endpoint := fmt.Sprintf("http://ambient-content-%s.%s.svc:8080", session, project)
+	u := fmt.Sprintf("%s/content/file?path=%s", endpoint, url.QueryEscape(relPath))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return 0, "", nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	if strings.TrimSpace(authorization) != "" {
+		req.Header.Set("Authorization", authorization)
 	}
+	logging.SetRequestIDHeader(req)
 	client := &http.Client{Timeout: 4 * time.Second}
 	resp, err := client.Do(req)
 	if err != nil {
-		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
-		return
+		return 0, "", nil, err
 	}
 	defer resp.Body.Close()
 	b, err := io.ReadAll(resp.Body)
 	if err != nil {
-		log.Printf("GetSessionWorkspaceFile: failed to read response body: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read file from content service"})
-		return
+		return 0, "", nil, fmt.Errorf("failed to read file from content service: %w", err)
 	}
-
-	// Log if content service returned an error
-	if resp.StatusCode >= 400 {
-		log.Printf("GetSessionWorkspaceFile: content service returned error status %d for path %s", resp.StatusCode, sub)
-	}
-
-	c.Data(resp.StatusCode, resp.Header.Get("Content-Type"), b)
+	return resp.StatusCode, resp.Header.Get("Content-Type"), b, nil
 }
 
 // PutSessionWorkspaceFile writes a file via content service.
@@ -2517,7 +2941,7 @@ func PutSessionWorkspaceFile(c *gin.Context) {
 	session := c.Param("sessionName")
 
 	if project == "" {
-		log.Printf("PutSessionWorkspaceFile: project is empty, session=%s", session)
+		logging.Infof(c, "PutSessionWorkspaceFile: project is empty, session=%s", session)
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Project namespace required"})
 		return
 	}
@@ -2541,7 +2965,7 @@ func PutSessionWorkspaceFile(c *gin.Context) {
 	// Use robust path validation from pathutil package
 	// This is more secure than manual string checks and works across platforms
 	if !pathutil.IsPathWithinBase(validationPath, workspaceBase) {
-		log.Printf("PutSessionWorkspaceFile: path traversal attempt detected - path=%q escapes workspace=%q", validationPath, workspaceBase)
+		logging.Warnf(c, "PutSessionWorkspaceFile: path traversal attempt detected - path=%q escapes workspace=%q", validationPath, workspaceBase)
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid path: must be within workspace directory"})
 		return
 	}
@@ -2555,34 +2979,10 @@ func PutSessionWorkspaceFile(c *gin.Context) {
 		token = c.GetHeader("X-Forwarded-Access-Token")
 	}
 
-	// RBAC check: verify user has update permission on agenticsessions (file operations modify session state)
-	// IMPORTANT: RBAC check MUST happen BEFORE checking session existence to prevent enumeration attacks
-	ssar := &authzv1.SelfSubjectAccessReview{
-		Spec: authzv1.SelfSubjectAccessReviewSpec{
-			ResourceAttributes: &authzv1.ResourceAttributes{
-				Group:     "vteam.ambient-code",
-				Resource:  "agenticsessions",
-				Verb:      "update",
-				Namespace: project,
-			},
-		},
-	}
-	res, err := reqK8s.AuthorizationV1().SelfSubjectAccessReviews().Create(c.Request.Context(), ssar, v1.CreateOptions{})
-	if err != nil {
-		log.Printf("RBAC check failed for file upload in project %s: %v", project, err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify permissions"})
-		return
-	}
-	if !res.Status.Allowed {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Unauthorized to modify session workspace"})
-		return
-	}
-
-	// Verify session exists using reqDyn AFTER RBAC check
-	// This prevents enumeration attacks - unauthorized users get same "Forbidden" response
+	// Verify session exists using reqDyn; RequireSessionAccess already ran the RBAC
+	// check, so unauthorized users get "Forbidden" rather than learning whether it exists
 	gvr := GetAgenticSessionV1Alpha1Resource()
-	_, err = reqDyn.Resource(gvr).Namespace(project).Get(c.Request.Context(), session, v1.GetOptions{})
-	if err != nil {
+	if _, err := reqDyn.Resource(gvr).Namespace(project).Get(c.Request.Context(), session, v1.GetOptions{}); err != nil {
 		if errors.IsNotFound(err) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
 			return
@@ -2595,7 +2995,7 @@ func PutSessionWorkspaceFile(c *gin.Context) {
 	serviceName := fmt.Sprintf("ambient-content-%s", session)
 	if _, err := reqK8s.CoreV1().Services(project).Get(c.Request.Context(), serviceName, v1.GetOptions{}); err != nil {
 		// Service doesn't exist - session is not running
-		log.Printf("PutSessionWorkspaceFile: Content service not found for session %s (session not running)", session)
+		logging.Errorf(c, "PutSessionWorkspaceFile: Content service not found for session %s (session not running)", session)
 		c.JSON(http.StatusConflict, gin.H{
 			"error": "Session is not running. Start the session to upload files.",
 			"hint":  "File uploads require an active session. Start the session and try again.",
@@ -2603,19 +3003,39 @@ func PutSessionWorkspaceFile(c *gin.Context) {
 		return
 	}
 
-	endpoint := fmt.Sprintf("http://%s.%s.svc:8080", serviceName, project)
-	log.Printf("PutSessionWorkspaceFile: using service %s for session %s", serviceName, session)
+	logging.Infof(c, "PutSessionWorkspaceFile: using service %s for session %s", serviceName, session)
 	payload, err := io.ReadAll(c.Request.Body)
 	if err != nil {
-		log.Printf("PutSessionWorkspaceFile: failed to read request body: %v", err)
+		logging.Errorf(c, "PutSessionWorkspaceFile: failed to read request body: %v", err)
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read file data"})
 		return
 	}
 
+	status, respContentType, rb, err := WriteSessionWorkspaceFile(c.Request.Context(), project, session, absPath, payload, c.GetHeader("Content-Type"), token)
+	if err != nil {
+		logging.Errorf(c, "PutSessionWorkspaceFile: %v", err)
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Log if content service returned an error
+	if status >= 400 {
+		logging.Errorf(c, "PutSessionWorkspaceFile: content service returned error status %d for path %s: %s", status, sub, string(rb))
+	}
+
+	c.Data(status, respContentType, rb)
+}
+
+// WriteSessionWorkspaceFile writes payload to relPath (relative to /workspace, already
+// validated by the caller) through the running session's content service, forwarding
+// the caller's authorization. It returns the content service's status, content type
+// and response body; err is set when the service couldn't be reached.
+func WriteSessionWorkspaceFile(ctx context.Context, project, session, relPath string, payload []byte, contentType, authorization string) (int, string, []byte, error) {
+	endpoint := fmt.Sprintf("http://ambient-content-%s.%s.svc:8080", session, project)
+
 	// Detect if content is binary and encode accordingly
 	encoding := "utf8"
 	var content string
-	contentType := c.GetHeader("Content-Type")
 
 	// If no Content-Type header, detect from payload
 	if contentType == "" {
@@ -2631,7 +3051,7 @@ func PutSessionWorkspaceFile(c *gin.Context) {
 		encoding = "base64"
 		content = base64.StdEncoding.EncodeToString(payload)
 		// Don't log user-controlled strings (contentType header) to prevent log injection
-		log.Printf("PutSessionWorkspaceFile: detected binary content, using base64 encoding (size=%d, contentTypeLen=%d)", len(payload), len(contentType))
+		logging.Infof(ctx, "WriteSessionWorkspaceFile: detected binary content, using base64 encoding (size=%d, contentTypeLen=%d)", len(payload), len(contentType))
 	} else {
 		// Only convert to string after validating UTF-8
 		content = string(payload)
@@ -2641,43 +3061,31 @@ func PutSessionWorkspaceFile(c *gin.Context) {
 		Path     string `json:"path"`
 		Content  string `json:"content"`
 		Encoding string `json:"encoding"`
-	}{Path: absPath, Content: content, Encoding: encoding}
+	}{Path: relPath, Content: content, Encoding: encoding}
 	b, err := json.Marshal(wreq)
 	if err != nil {
-		log.Printf("PutSessionWorkspaceFile: failed to marshal request: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to prepare request"})
-		return
+		return 0, "", nil, fmt.Errorf("failed to prepare request: %w", err)
 	}
-	req, err := http.NewRequestWithContext(c.Request.Context(), http.MethodPost, endpoint+"/content/write", strings.NewReader(string(b)))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint+"/content/write", strings.NewReader(string(b)))
 	if err != nil {
-		log.Printf("PutSessionWorkspaceFile: failed to create HTTP request: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create request"})
-		return
+		return 0, "", nil, fmt.Errorf("failed to create request: %w", err)
 	}
-	if strings.TrimSpace(token) != "" {
-		req.Header.Set("Authorization", token)
+	if strings.TrimSpace(authorization) != "" {
+		req.Header.Set("Authorization", authorization)
 	}
 	req.Header.Set("Content-Type", "application/json")
+	logging.SetRequestIDHeader(req)
 	client := &http.Client{Timeout: 4 * time.Second}
 	resp, err := client.Do(req)
 	if err != nil {
-		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
-		return
+		return 0, "", nil, err
 	}
 	defer resp.Body.Close()
 	rb, err := io.ReadAll(resp.Body)
 	if err != nil {
-		log.Printf("PutSessionWorkspaceFile: failed to read response body: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read response from content service"})
-		return
-	}
-
-	// Log if content service returned an error
-	if resp.StatusCode >= 400 {
-		log.Printf("PutSessionWorkspaceFile: content service returned error status %d for path %s: %s", resp.StatusCode, sub, string(rb))
+		return 0, "", nil, fmt.Errorf("failed to read response from content service: %w", err)
 	}
-
-	c.Data(resp.StatusCode, resp.Header.Get("Content-Type"), rb)
+	return resp.StatusCode, resp.Header.Get("Content-Type"), rb, nil
 }
 
 // DeleteSessionWorkspaceFile deletes a file via content service.
@@ -2690,7 +3098,7 @@ func DeleteSessionWorkspaceFile(c *gin.Context) {
 	session := c.Param("sessionName")
 
 	if project == "" {
-		log.Printf("DeleteSessionWorkspaceFile: project is empty, session=%s", session)
+		logging.Infof(c, "DeleteSessionWorkspaceFile: project is empty, session=%s", session)
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Project namespace required"})
 		return
 	}
@@ -2714,7 +3122,7 @@ func DeleteSessionWorkspaceFile(c *gin.Context) {
 	// Use robust path validation from pathutil package
 	// This is more secure than manual string checks and works across platforms
 	if !pathutil.IsPathWithinBase(validationPath, workspaceBase) {
-		log.Printf("DeleteSessionWorkspaceFile: path traversal attempt detected - path=%q escapes workspace=%q", validationPath, workspaceBase)
+		logging.Warnf(c, "DeleteSessionWorkspaceFile: path traversal attempt detected - path=%q escapes workspace=%q", validationPath, workspaceBase)
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid path: must be within workspace directory"})
 		return
 	}
@@ -2728,38 +3136,15 @@ func DeleteSessionWorkspaceFile(c *gin.Context) {
 		token = c.GetHeader("X-Forwarded-Access-Token")
 	}
 
-	// RBAC check: verify user has update permission on agenticsessions (file operations modify session state)
-	// IMPORTANT: RBAC check MUST happen BEFORE checking session existence to prevent enumeration attacks
-	ssar := &authzv1.SelfSubjectAccessReview{
-		Spec: authzv1.SelfSubjectAccessReviewSpec{
-			ResourceAttributes: &authzv1.ResourceAttributes{
-				Group:     "vteam.ambient-code",
-				Resource:  "agenticsessions",
-				Verb:      "update",
-				Namespace: project,
-			},
-		},
-	}
-	res, err := reqK8s.AuthorizationV1().SelfSubjectAccessReviews().Create(c.Request.Context(), ssar, v1.CreateOptions{})
-	if err != nil {
-		log.Printf("RBAC check failed for file deletion in project %s: %v", project, err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify permissions"})
-		return
-	}
-	if !res.Status.Allowed {
-		c.JSON(http.StatusForbidden, gin.H{"error": "Unauthorized to modify session workspace"})
-		return
-	}
-
-	// Verify session exists using reqDyn AFTER RBAC check
-	// This prevents enumeration attacks - unauthorized users get same "Forbidden" response
+	// Verify session exists using reqDyn; RequireSessionAccess already ran the RBAC
+	// check, so unauthorized users get "Forbidden" rather than learning whether it exists
 	gvr := GetAgenticSessionV1Alpha1Resource()
 	if _, err := reqDyn.Resource(gvr).Namespace(project).Get(c.Request.Context(), session, v1.GetOptions{}); err != nil {
 		if errors.IsNotFound(err) {
 			c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
 			return
 		}
-		log.Printf("DeleteSessionWorkspaceFile: Failed to verify session existence: %v", err)
+		logging.Errorf(c, "DeleteSessionWorkspaceFile: Failed to verify session existence: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify session"})
 		return
 	}
@@ -2767,13 +3152,13 @@ func DeleteSessionWorkspaceFile(c *gin.Context) {
 	// Check if content service exists (session must be running)
 	serviceName := getContentServiceName(session)
 	if _, err := reqK8s.CoreV1().Services(project).Get(c.Request.Context(), serviceName, v1.GetOptions{}); err != nil {
-		log.Printf("DeleteSessionWorkspaceFile: Content service not found for session %s (session not running)", session)
+		logging.Errorf(c, "DeleteSessionWorkspaceFile: Content service not found for session %s (session not running)", session)
 		c.JSON(http.StatusConflict, gin.H{"error": "Session is not running. Start the session to access files."})
 		return
 	}
 
 	endpoint := fmt.Sprintf("http://%s.%s.svc:8080", serviceName, project)
-	log.Printf("DeleteSessionWorkspaceFile: using service %s for session %s, path=%s", serviceName, session, absPath)
+	logging.Infof(c, "DeleteSessionWorkspaceFile: using service %s for session %s, path=%s", serviceName, session, absPath)
 
 	// Use DELETE request with path in body
 	wreq := struct {
@@ -2781,13 +3166,13 @@ func DeleteSessionWorkspaceFile(c *gin.Context) {
 	}{Path: absPath}
 	b, err := json.Marshal(wreq)
 	if err != nil {
-		log.Printf("DeleteSessionWorkspaceFile: failed to marshal request: %v", err)
+		logging.Errorf(c, "DeleteSessionWorkspaceFile: failed to marshal request: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to prepare request"})
 		return
 	}
 	req, err := http.NewRequestWithContext(c.Request.Context(), http.MethodDelete, endpoint+"/content/delete", strings.NewReader(string(b)))
 	if err != nil {
-		log.Printf("DeleteSessionWorkspaceFile: failed to create HTTP request: %v", err)
+		logging.Errorf(c, "DeleteSessionWorkspaceFile: failed to create HTTP request: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create request"})
 		return
 	}
@@ -2795,6 +3180,7 @@ func DeleteSessionWorkspaceFile(c *gin.Context) {
 		req.Header.Set("Authorization", token)
 	}
 	req.Header.Set("Content-Type", "application/json")
+	logging.SetRequestIDHeader(req)
 	client := &http.Client{Timeout: 4 * time.Second}
 	resp, err := client.Do(req)
 	if err != nil {
@@ -2809,7 +3195,7 @@ func DeleteSessionWorkspaceFile(c *gin.Context) {
 	} else {
 		rb, err := io.ReadAll(resp.Body)
 		if err != nil {
-			log.Printf("DeleteSessionWorkspaceFile: failed to read error response: %v", err)
+			logging.Errorf(c, "DeleteSessionWorkspaceFile: failed to read error response: %v", err)
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete file"})
 			return
 		}
@@ -2838,7 +3224,7 @@ func PushSessionRepo(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid JSON body"})
 		return
 	}
-	log.Printf("pushSessionRepo: request project=%s session=%s repoIndex=%d commitLen=%d", project, session, body.RepoIndex, len(strings.TrimSpace(body.CommitMessage)))
+	logging.Infof(c, "pushSessionRepo: request project=%s session=%s repoIndex=%d commitLen=%d", project, session, body.RepoIndex, len(strings.TrimSpace(body.CommitMessage)))
 
 	// Try temp service first (for completed sessions), then regular service
 	serviceName := getContentServiceName(session)
@@ -2849,7 +3235,7 @@ func PushSessionRepo(c *gin.Context) {
 		return
 	}
 	endpoint := fmt.Sprintf("http://%s.%s.svc:8080", serviceName, project)
-	log.Printf("pushSessionRepo: using service %s", serviceName)
+	logging.Infof(c, "pushSessionRepo: using service %s", serviceName)
 
 	// Simplified: 1) get session; 2) compute repoPath from INPUT repo folder; 3) get output url/branch; 4) proxy
 	resolvedRepoPath := ""
@@ -2901,7 +3287,7 @@ func PushSessionRepo(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "missing output repo url"})
 		return
 	}
-	log.Printf("pushSessionRepo: resolved repoPath=%q outputUrl=%q branch=%q", resolvedRepoPath, resolvedOutputURL, resolvedBranch)
+	logging.Infof(c, "pushSessionRepo: resolved repoPath=%q outputUrl=%q branch=%q", resolvedRepoPath, resolvedOutputURL, resolvedBranch)
 
 	payload := map[string]interface{}{
 		"repoPath":      resolvedRepoPath,
@@ -2911,13 +3297,13 @@ func PushSessionRepo(c *gin.Context) {
 	}
 	b, err := json.Marshal(payload)
 	if err != nil {
-		log.Printf("pushSessionRepo: failed to marshal request: %v", err)
+		logging.Errorf(c, "pushSessionRepo: failed to marshal request: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to prepare request"})
 		return
 	}
 	req, err := http.NewRequestWithContext(c.Request.Context(), http.MethodPost, endpoint+"/content/github/push", strings.NewReader(string(b)))
 	if err != nil {
-		log.Printf("pushSessionRepo: failed to create HTTP request: %v", err)
+		logging.Errorf(c, "pushSessionRepo: failed to create HTTP request: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create request"})
 		return
 	}
@@ -2954,39 +3340,40 @@ func PushSessionRepo(c *gin.Context) {
 			if tokenStr, err := GetGitHubToken(c.Request.Context(), k8sClt, k8sDyn, project, userID); err == nil && strings.TrimSpace(tokenStr) != "" {
 				req.Header.Set("X-GitHub-Token", tokenStr)
 			} else if err != nil {
-				log.Printf("pushSessionRepo: failed to resolve authentication: %v", err)
+				logging.Errorf(c, "pushSessionRepo: failed to resolve authentication: %v", err)
 			}
 			if GetGitLabToken != nil {
 				if tokenStr, err := GetGitLabToken(c.Request.Context(), k8sClt, project, userID); err == nil && strings.TrimSpace(tokenStr) != "" {
 					req.Header.Set("X-GitLab-Token", tokenStr)
 				} else if err != nil {
-					log.Printf("pushSessionRepo: failed to resolve GitLab authentication: %v", err)
+					logging.Errorf(c, "pushSessionRepo: failed to resolve GitLab authentication: %v", err)
 				}
 			}
 		} else {
-			log.Printf("pushSessionRepo: session %s/%s missing userContext.userId; proceeding without authentication", project, session)
+			logging.Warnf(c, "pushSessionRepo: session %s/%s missing userContext.userId; proceeding without authentication", project, session)
 		}
 	} else {
-		log.Printf("pushSessionRepo: failed to read session for token attach: %v", err)
+		logging.Errorf(c, "pushSessionRepo: failed to read session for token attach: %v", err)
 	}
 
-	log.Printf("pushSessionRepo: proxy push project=%s session=%s repoIndex=%d repoPath=%s endpoint=%s", project, session, body.RepoIndex, resolvedRepoPath, endpoint+"/content/github/push")
+	logging.Infof(c, "pushSessionRepo: proxy push project=%s session=%s repoIndex=%d repoPath=%s endpoint=%s", project, session, body.RepoIndex, resolvedRepoPath, endpoint+"/content/github/push")
+	logging.SetRequestIDHeader(req)
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
 		// Log actual error for debugging, but return generic message to avoid leaking internal details
-		log.Printf("Bad gateway error: %v", err)
+		logging.Errorf(c, "Bad gateway error: %v", err)
 		c.JSON(http.StatusBadGateway, gin.H{"error": "Service temporarily unavailable"})
 		return
 	}
 	defer resp.Body.Close()
 	bodyBytes, err := io.ReadAll(resp.Body)
 	if err != nil {
-		log.Printf("pushSessionRepo: failed to read response body: %v", err)
+		logging.Errorf(c, "pushSessionRepo: failed to read response body: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read response from content service"})
 		return
 	}
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		log.Printf("pushSessionRepo: content returned status=%d body.snip=%q", resp.StatusCode, func() string {
+		logging.Warnf(c, "pushSessionRepo: content returned status=%d body.snip=%q", resp.StatusCode, func() string {
 			s := string(bodyBytes)
 			if len(s) > 1500 {
 				return s[:1500] + "..."
@@ -2997,7 +3384,7 @@ func PushSessionRepo(c *gin.Context) {
 		return
 	}
 	// Note: status.repos removed from CRD - no longer tracking per-repo status
-	log.Printf("pushSessionRepo: content push succeeded status=%d body.len=%d", resp.StatusCode, len(bodyBytes))
+	logging.Infof(c, "pushSessionRepo: content push succeeded status=%d body.len=%d", resp.StatusCode, len(bodyBytes))
 	c.Data(http.StatusOK, "application/json", bodyBytes)
 }
 
@@ -3023,7 +3410,7 @@ func AbandonSessionRepo(c *gin.Context) {
 		return
 	}
 	endpoint := fmt.Sprintf("http://%s.%s.svc:8080", serviceName, project)
-	log.Printf("AbandonSessionRepo: using service %s", serviceName)
+	logging.Infof(c, "AbandonSessionRepo: using service %s", serviceName)
 	repoPath := strings.TrimSpace(body.RepoPath)
 	if repoPath == "" {
 		if body.RepoIndex >= 0 {
@@ -3037,13 +3424,13 @@ func AbandonSessionRepo(c *gin.Context) {
 	}
 	b, err := json.Marshal(payload)
 	if err != nil {
-		log.Printf("abandonSessionRepo: failed to marshal request: %v", err)
+		logging.Errorf(c, "abandonSessionRepo: failed to marshal request: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to prepare request"})
 		return
 	}
 	req, err := http.NewRequestWithContext(c.Request.Context(), http.MethodPost, endpoint+"/content/github/abandon", strings.NewReader(string(b)))
 	if err != nil {
-		log.Printf("abandonSessionRepo: failed to create HTTP request: %v", err)
+		logging.Errorf(c, "abandonSessionRepo: failed to create HTTP request: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create request"})
 		return
 	}
@@ -3054,23 +3441,24 @@ func AbandonSessionRepo(c *gin.Context) {
 		req.Header.Set("X-Forwarded-Access-Token", v)
 	}
 	req.Header.Set("Content-Type", "application/json")
-	log.Printf("abandonSessionRepo: proxy abandon project=%s session=%s repoIndex=%d repoPath=%s", project, session, body.RepoIndex, repoPath)
+	logging.Infof(c, "abandonSessionRepo: proxy abandon project=%s session=%s repoIndex=%d repoPath=%s", project, session, body.RepoIndex, repoPath)
+	logging.SetRequestIDHeader(req)
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
 		// Log actual error for debugging, but return generic message to avoid leaking internal details
-		log.Printf("Bad gateway error: %v", err)
+		logging.Errorf(c, "Bad gateway error: %v", err)
 		c.JSON(http.StatusBadGateway, gin.H{"error": "Service temporarily unavailable"})
 		return
 	}
 	defer resp.Body.Close()
 	bodyBytes, err := io.ReadAll(resp.Body)
 	if err != nil {
-		log.Printf("abandonSessionRepo: failed to read response body: %v", err)
+		logging.Errorf(c, "abandonSessionRepo: failed to read response body: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read response from content service"})
 		return
 	}
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		log.Printf("abandonSessionRepo: content returned status=%d body=%s", resp.StatusCode, string(bodyBytes))
+		logging.Warnf(c, "abandonSessionRepo: content returned status=%d body=%s", resp.StatusCode, string(bodyBytes))
 		c.Data(resp.StatusCode, "application/json", bodyBytes)
 		return
 	}
@@ -3103,7 +3491,7 @@ func DiffSessionRepo(c *gin.Context) {
 		return
 	}
 	endpoint := fmt.Sprintf("http://%s.%s.svc:8080", serviceName, project)
-	log.Printf("DiffSessionRepo: using service %s", serviceName)
+	logging.Infof(c, "DiffSessionRepo: using service %s", serviceName)
 	url := fmt.Sprintf("%s/content/github/diff?repoPath=%s", endpoint, url.QueryEscape(repoPath))
 	req, _ := http.NewRequestWithContext(c.Request.Context(), http.MethodGet, url, nil)
 	if v := c.GetHeader("Authorization"); v != "" {
@@ -3112,6 +3500,7 @@ func DiffSessionRepo(c *gin.Context) {
 	if v := c.GetHeader("X-Forwarded-Access-Token"); v != "" {
 		req.Header.Set("X-Forwarded-Access-Token", v)
 	}
+	logging.SetRequestIDHeader(req)
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
 		c.JSON(http.StatusOK, gin.H{
@@ -3127,7 +3516,7 @@ func DiffSessionRepo(c *gin.Context) {
 	defer resp.Body.Close()
 	bodyBytes, err := io.ReadAll(resp.Body)
 	if err != nil {
-		log.Printf("DiffSessionRepo: failed to read response body: %v", err)
+		logging.Errorf(c, "DiffSessionRepo: failed to read response body: %v", err)
 		c.JSON(http.StatusOK, gin.H{
 			"files": gin.H{
 				"added":   0,
@@ -3163,7 +3552,7 @@ func GetReposStatus(c *gin.Context) {
 		return
 	}
 	if err != nil {
-		log.Printf("GetReposStatus: failed to verify session access: %v", err)
+		logging.Errorf(c, "GetReposStatus: failed to verify session access: %v", err)
 		c.JSON(http.StatusForbidden, gin.H{"error": "Access denied"})
 		return
 	}
@@ -3173,23 +3562,28 @@ func GetReposStatus(c *gin.Context) {
 	// 1. Backend validated user has access to session (above)
 	// 2. Backend calls runner as trusted internal service (no auth header forwarding)
 	// 3. Runner trusts backend's validation
-	// Port 8001 matches AG-UI Service defined in operator (sessions.go:1384)
-	// If changing this port, also update: operator containerPort, Service port, and AGUI_PORT env
-	runnerURL := fmt.Sprintf("http://session-%s.%s.svc.cluster.local:8001/repos/status", session, project)
+	// The runner may be on another runner cluster; RunnerEndpoint resolves where
+	runnerBase, err := RunnerEndpoint(c.Request.Context(), project, session)
+	if err != nil {
+		logging.Warnf(c, "GetReposStatus: failed to resolve runner endpoint: %v", err)
+		c.JSON(http.StatusOK, gin.H{"repos": []interface{}{}})
+		return
+	}
 
-	req, err := http.NewRequestWithContext(c.Request.Context(), http.MethodGet, runnerURL, nil)
+	req, err := http.NewRequestWithContext(c.Request.Context(), http.MethodGet, runnerBase+"repos/status", nil)
 	if err != nil {
-		log.Printf("GetReposStatus: failed to create HTTP request: %v", err)
+		logging.Errorf(c, "GetReposStatus: failed to create HTTP request: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create request"})
 		return
 	}
 	// NOTE: Do NOT forward Authorization header to runner (matches pattern of AddWorkflow, AddRepository, RemoveRepo)
 	// Runner is treated as a trusted backend service; RBAC enforcement happens in backend
 
+	logging.SetRequestIDHeader(req)
 	client := &http.Client{Timeout: 5 * time.Second}
 	resp, err := client.Do(req)
 	if err != nil {
-		log.Printf("GetReposStatus: runner not reachable: %v", err)
+		logging.Warnf(c, "GetReposStatus: runner not reachable: %v", err)
 		// Return empty repos list instead of error for better UX
 		c.JSON(http.StatusOK, gin.H{"repos": []interface{}{}})
 		return
@@ -3198,13 +3592,13 @@ func GetReposStatus(c *gin.Context) {
 
 	bodyBytes, err := io.ReadAll(resp.Body)
 	if err != nil {
-		log.Printf("GetReposStatus: failed to read response body: %v", err)
+		logging.Errorf(c, "GetReposStatus: failed to read response body: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read response from runner"})
 		return
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		log.Printf("GetReposStatus: runner returned status %d", resp.StatusCode)
+		logging.Warnf(c, "GetReposStatus: runner returned status %d", resp.StatusCode)
 		c.JSON(http.StatusOK, gin.H{"repos": []interface{}{}})
 		return
 	}
@@ -3240,7 +3634,7 @@ func GetGitStatus(c *gin.Context) {
 
 	req, err := http.NewRequestWithContext(c.Request.Context(), http.MethodGet, endpoint, nil)
 	if err != nil {
-		log.Printf("GetGitStatus: failed to create HTTP request: %v", err)
+		logging.Errorf(c, "GetGitStatus: failed to create HTTP request: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create request"})
 		return
 	}
@@ -3267,6 +3661,62 @@ func GetGitStatus(c *gin.Context) {
 		}
 	}
 
+	logging.SetRequestIDHeader(req)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "content service unavailable"})
+		return
+	}
+	defer resp.Body.Close()
+
+	bodyBytes, err := io.ReadAll(resp.Body)
+	if err != nil {
+		logging.Errorf(c, "GetGitStatus: failed to read response body: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read response from content service"})
+		return
+	}
+	c.Data(resp.StatusCode, resp.Header.Get("Content-Type"), bodyBytes)
+}
+
+// GetGitDiff returns the unified diff of a workspace repository against HEAD
+// GET /api/projects/:projectName/agentic-sessions/:sessionName/git/diff?path=artifacts&file=README.md
+func GetGitDiff(c *gin.Context) {
+	project := c.Param("projectName")
+	session := c.Param("sessionName")
+	relativePath := strings.TrimSpace(c.Query("path"))
+	file := strings.TrimSpace(c.Query("file"))
+
+	if relativePath == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "path parameter required"})
+		return
+	}
+
+	k8sClt, _ := GetK8sClientsForRequest(c)
+	if k8sClt == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing token"})
+		c.Abort()
+		return
+	}
+
+	serviceName := getContentServiceName(session)
+	query := url.Values{}
+	query.Set("path", relativePath)
+	if file != "" {
+		query.Set("file", file)
+	}
+	endpoint := fmt.Sprintf("http://%s.%s.svc:8080/content/git-diff?%s", serviceName, project, query.Encode())
+
+	req, err := http.NewRequestWithContext(c.Request.Context(), http.MethodGet, endpoint, nil)
+	if err != nil {
+		logging.Errorf(c, "GetGitDiff: failed to create HTTP request: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create request"})
+		return
+	}
+	if v := c.GetHeader("Authorization"); v != "" {
+		req.Header.Set("Authorization", v)
+	}
+
+	logging.SetRequestIDHeader(req)
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
 		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "content service unavailable"})
@@ -3276,7 +3726,7 @@ func GetGitStatus(c *gin.Context) {
 
 	bodyBytes, err := io.ReadAll(resp.Body)
 	if err != nil {
-		log.Printf("GetGitStatus: failed to read response body: %v", err)
+		logging.Errorf(c, "GetGitDiff: failed to read response body: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read response from content service"})
 		return
 	}
@@ -3331,14 +3781,14 @@ func ConfigureGitRemote(c *gin.Context) {
 		"branch":    body.Branch,
 	})
 	if err != nil {
-		log.Printf("ConfigureGitRemote: failed to marshal request: %v", err)
+		logging.Errorf(c, "ConfigureGitRemote: failed to marshal request: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to prepare request"})
 		return
 	}
 
 	req, err := http.NewRequestWithContext(c.Request.Context(), http.MethodPost, endpoint, strings.NewReader(string(reqBody)))
 	if err != nil {
-		log.Printf("ConfigureGitRemote: failed to create HTTP request: %v", err)
+		logging.Errorf(c, "ConfigureGitRemote: failed to create HTTP request: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create request"})
 		return
 	}
@@ -3376,9 +3826,10 @@ func ConfigureGitRemote(c *gin.Context) {
 			}
 		}
 	default:
-		log.Printf("ConfigureGitRemote: unknown provider detected, proceeding without authentication")
+		logging.Warnf(c, "ConfigureGitRemote: unknown provider detected, proceeding without authentication")
 	}
 
+	logging.SetRequestIDHeader(req)
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
 		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "content service unavailable"})
@@ -3410,16 +3861,16 @@ func ConfigureGitRemote(c *gin.Context) {
 
 			_, err = k8sDyn.Resource(gvr).Namespace(project).Update(c.Request.Context(), item, v1.UpdateOptions{})
 			if err != nil {
-				log.Printf("Warning: Failed to persist remote config to annotations: %v", err)
+				logging.Warnf(c, "Failed to persist remote config to annotations: %v", err)
 			} else {
-				log.Printf("Persisted remote config for %s to session annotations: %s@%s", body.Path, body.RemoteURL, body.Branch)
+				logging.Infof(c, "Persisted remote config for %s to session annotations: %s@%s", body.Path, body.RemoteURL, body.Branch)
 			}
 		}
 	}
 
 	bodyBytes, err := io.ReadAll(resp.Body)
 	if err != nil {
-		log.Printf("ConfigureGitRemote: failed to read response body: %v", err)
+		logging.Errorf(c, "ConfigureGitRemote: failed to read response body: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read response from content service"})
 		return
 	}
@@ -3469,14 +3920,14 @@ func SynchronizeGit(c *gin.Context) {
 		"branch":  body.Branch,
 	})
 	if err != nil {
-		log.Printf("SynchronizeGit: failed to marshal request: %v", err)
+		logging.Errorf(c, "SynchronizeGit: failed to marshal request: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to prepare request"})
 		return
 	}
 
 	req, err := http.NewRequestWithContext(c.Request.Context(), http.MethodPost, endpoint, strings.NewReader(string(reqBody)))
 	if err != nil {
-		log.Printf("SynchronizeGit: failed to create HTTP request: %v", err)
+		logging.Errorf(c, "SynchronizeGit: failed to create HTTP request: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create request"})
 		return
 	}
@@ -3504,6 +3955,7 @@ func SynchronizeGit(c *gin.Context) {
 		}
 	}
 
+	logging.SetRequestIDHeader(req)
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
 		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "content service unavailable"})
@@ -3513,7 +3965,7 @@ func SynchronizeGit(c *gin.Context) {
 
 	bodyBytes, err := io.ReadAll(resp.Body)
 	if err != nil {
-		log.Printf("SynchronizeGit: failed to read response body: %v", err)
+		logging.Errorf(c, "SynchronizeGit: failed to read response body: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read response from content service"})
 		return
 	}
@@ -3573,6 +4025,7 @@ func GetGitMergeStatus(c *gin.Context) {
 		}
 	}
 
+	logging.SetRequestIDHeader(req)
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
 		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "content service unavailable"})
@@ -3582,7 +4035,7 @@ func GetGitMergeStatus(c *gin.Context) {
 
 	bodyBytes, err := io.ReadAll(resp.Body)
 	if err != nil {
-		log.Printf("GetGitMergeStatus: failed to read response body: %v", err)
+		logging.Errorf(c, "GetGitMergeStatus: failed to read response body: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read response from content service"})
 		return
 	}
@@ -3630,14 +4083,14 @@ func GitPullSession(c *gin.Context) {
 		"branch": body.Branch,
 	})
 	if err != nil {
-		log.Printf("GitPullSession: failed to marshal request: %v", err)
+		logging.Errorf(c, "GitPullSession: failed to marshal request: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to prepare request"})
 		return
 	}
 
 	req, err := http.NewRequestWithContext(c.Request.Context(), http.MethodPost, endpoint, strings.NewReader(string(reqBody)))
 	if err != nil {
-		log.Printf("GitPullSession: failed to create HTTP request: %v", err)
+		logging.Errorf(c, "GitPullSession: failed to create HTTP request: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create request"})
 		return
 	}
@@ -3665,6 +4118,7 @@ func GitPullSession(c *gin.Context) {
 		}
 	}
 
+	logging.SetRequestIDHeader(req)
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
 		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "content service unavailable"})
@@ -3674,7 +4128,7 @@ func GitPullSession(c *gin.Context) {
 
 	bodyBytes, err := io.ReadAll(resp.Body)
 	if err != nil {
-		log.Printf("GitPullSession: failed to read response body: %v", err)
+		logging.Errorf(c, "GitPullSession: failed to read response body: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read response from content service"})
 		return
 	}
@@ -3727,14 +4181,14 @@ func GitPushSession(c *gin.Context) {
 		"message": body.Message,
 	})
 	if err != nil {
-		log.Printf("GitPushSession: failed to marshal request: %v", err)
+		logging.Errorf(c, "GitPushSession: failed to marshal request: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to prepare request"})
 		return
 	}
 
 	req, err := http.NewRequestWithContext(c.Request.Context(), http.MethodPost, endpoint, strings.NewReader(string(reqBody)))
 	if err != nil {
-		log.Printf("GitPushSession: failed to create HTTP request: %v", err)
+		logging.Errorf(c, "GitPushSession: failed to create HTTP request: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create request"})
 		return
 	}
@@ -3762,6 +4216,7 @@ func GitPushSession(c *gin.Context) {
 		}
 	}
 
+	logging.SetRequestIDHeader(req)
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
 		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "content service unavailable"})
@@ -3771,7 +4226,7 @@ func GitPushSession(c *gin.Context) {
 
 	bodyBytes, err := io.ReadAll(resp.Body)
 	if err != nil {
-		log.Printf("GitPushSession: failed to read response body: %v", err)
+		logging.Errorf(c, "GitPushSession: failed to read response body: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read response from content service"})
 		return
 	}
@@ -3816,14 +4271,14 @@ func GitCreateBranchSession(c *gin.Context) {
 		"branchName": body.BranchName,
 	})
 	if err != nil {
-		log.Printf("GitCreateBranchSession: failed to marshal request: %v", err)
+		logging.Errorf(c, "GitCreateBranchSession: failed to marshal request: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to prepare request"})
 		return
 	}
 
 	req, err := http.NewRequestWithContext(c.Request.Context(), http.MethodPost, endpoint, strings.NewReader(string(reqBody)))
 	if err != nil {
-		log.Printf("GitCreateBranchSession: failed to create HTTP request: %v", err)
+		logging.Errorf(c, "GitCreateBranchSession: failed to create HTTP request: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create request"})
 		return
 	}
@@ -3832,6 +4287,7 @@ func GitCreateBranchSession(c *gin.Context) {
 		req.Header.Set("Authorization", v)
 	}
 
+	logging.SetRequestIDHeader(req)
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
 		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "content service unavailable"})
@@ -3841,7 +4297,7 @@ func GitCreateBranchSession(c *gin.Context) {
 
 	bodyBytes, err := io.ReadAll(resp.Body)
 	if err != nil {
-		log.Printf("GitCreateBranchSession: failed to read response body: %v", err)
+		logging.Errorf(c, "GitCreateBranchSession: failed to read response body: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read response from content service"})
 		return
 	}
@@ -3875,7 +4331,7 @@ func GitListBranchesSession(c *gin.Context) {
 
 	req, err := http.NewRequestWithContext(c.Request.Context(), http.MethodGet, endpoint, nil)
 	if err != nil {
-		log.Printf("GitListBranchesSession: failed to create HTTP request: %v", err)
+		logging.Errorf(c, "GitListBranchesSession: failed to create HTTP request: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create request"})
 		return
 	}
@@ -3883,6 +4339,7 @@ func GitListBranchesSession(c *gin.Context) {
 		req.Header.Set("Authorization", v)
 	}
 
+	logging.SetRequestIDHeader(req)
 	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
 		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "content service unavailable"})
@@ -3892,7 +4349,7 @@ func GitListBranchesSession(c *gin.Context) {
 
 	bodyBytes, err := io.ReadAll(resp.Body)
 	if err != nil {
-		log.Printf("GitListBranchesSession: failed to read response body: %v", err)
+		logging.Errorf(c, "GitListBranchesSession: failed to read response body: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read response from content service"})
 		return
 	}
@@ -11,6 +11,7 @@ import (
 	"strconv"
 	"time"
 
+	"ambient-code-backend/git"
 	"ambient-code-backend/tests/logger"
 	"ambient-code-backend/tests/test_utils"
 	"ambient-code-backend/types"
@@ -343,6 +344,86 @@ var _ = Describe("Sessions Handler", Label(test_constants.LabelUnit, test_consta
 			})
 		})
 
+		Context("When requesting a custom runner image", func() {
+			createProjectSettings := func(allowlist []interface{}) {
+				settings := &unstructured.Unstructured{
+					Object: map[string]interface{}{
+						"apiVersion": "vteam.ambient-code/v1alpha1",
+						"kind":       "ProjectSettings",
+						"metadata": map[string]interface{}{
+							"name":      "projectsettings",
+							"namespace": testNamespace,
+						},
+						"spec": map[string]interface{}{
+							"groupAccess":          []interface{}{},
+							"runnerImageAllowlist": allowlist,
+						},
+					},
+				}
+				_, err := k8sUtils.DynamicClient.Resource(GetProjectSettingsResource()).Namespace(testNamespace).Create(ctx, settings, v1.CreateOptions{})
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			It("Should reject the image when the project has no allowlist", func() {
+				sessionRequest := map[string]interface{}{
+					"initialPrompt": "Test prompt",
+					"runnerImage":   "quay.io/example/runner:latest",
+				}
+
+				context := httpUtils.CreateTestGinContext("POST", "/api/projects/"+testNamespace+"/agentic-sessions", sessionRequest)
+				httpUtils.SetAuthHeader(testToken)
+				httpUtils.SetProjectContext(testNamespace)
+
+				CreateSession(context)
+
+				httpUtils.AssertHTTPStatus(http.StatusBadRequest)
+			})
+
+			It("Should reject an image outside the allowlist", func() {
+				createProjectSettings([]interface{}{"quay.io/ambient_code/"})
+
+				sessionRequest := map[string]interface{}{
+					"initialPrompt": "Test prompt",
+					"runnerImage":   "docker.io/evil/runner:latest",
+				}
+
+				context := httpUtils.CreateTestGinContext("POST", "/api/projects/"+testNamespace+"/agentic-sessions", sessionRequest)
+				httpUtils.SetAuthHeader(testToken)
+				httpUtils.SetProjectContext(testNamespace)
+
+				CreateSession(context)
+
+				httpUtils.AssertHTTPStatus(http.StatusBadRequest)
+			})
+
+			It("Should store an allowed image on the session spec", func() {
+				createProjectSettings([]interface{}{"quay.io/ambient_code/vteam_claude_runner"})
+
+				sessionRequest := map[string]interface{}{
+					"initialPrompt": "Test prompt",
+					"runnerImage":   "quay.io/ambient_code/vteam_claude_runner:v2",
+				}
+
+				context := httpUtils.CreateTestGinContext("POST", "/api/projects/"+testNamespace+"/agentic-sessions", sessionRequest)
+				httpUtils.SetAuthHeader(testToken)
+				httpUtils.SetProjectContext(testNamespace)
+
+				CreateSession(context)
+
+				httpUtils.AssertHTTPStatus(http.StatusCreated)
+
+				var response map[string]interface{}
+				httpUtils.GetResponseJSON(&response)
+				sessionName, ok := response["name"].(string)
+				Expect(ok).To(BeTrue())
+
+				created, err := k8sUtils.DynamicClient.Resource(sessionGVR).Namespace(testNamespace).Get(ctx, sessionName, v1.GetOptions{})
+				Expect(err).NotTo(HaveOccurred())
+				image, _, _ := unstructured.NestedString(created.Object, "spec", "runnerImage")
+				Expect(image).To(Equal("quay.io/ambient_code/vteam_claude_runner:v2"))
+			})
+		})
+
 		Context("When creating session with edge case data", func() {
 			It("Should handle empty initial prompt", func() {
 				// Arrange
@@ -472,6 +553,117 @@ var _ = Describe("Sessions Handler", Label(test_constants.LabelUnit, test_consta
 		})
 	})
 
+	Describe("GetSessionConditions", func() {
+		var sessionName string
+
+		BeforeEach(func() {
+			sessionName = testSession
+			session := createTestSession(sessionName, testNamespace, k8sUtils)
+			conditions := []interface{}{
+				map[string]interface{}{"type": "WorkspaceReady", "status": "True", "reason": "Hydrated"},
+				map[string]interface{}{"type": "RunnerReady", "status": "False", "reason": "ContainerNotReady"},
+			}
+			Expect(unstructured.SetNestedSlice(session.Object, conditions, "status", "conditions")).To(Succeed())
+			_, err := k8sUtils.DynamicClient.Resource(sessionGVR).Namespace(testNamespace).Update(ctx, session, v1.UpdateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("Should return phase and all conditions", func() {
+			path := fmt.Sprintf("/api/projects/%s/agentic-sessions/%s/conditions", testNamespace, sessionName)
+			context := httpUtils.CreateTestGinContext("GET", path, nil)
+			httpUtils.SetAuthHeader(testToken)
+			httpUtils.SetProjectContext(testNamespace)
+			context.Params = gin.Params{{Key: "sessionName", Value: sessionName}}
+
+			GetSessionConditions(context)
+
+			httpUtils.AssertHTTPStatus(http.StatusOK)
+			var response types.SessionConditionsResponse
+			httpUtils.GetResponseJSON(&response)
+			Expect(response.Phase).To(Equal("Pending"))
+			Expect(response.Conditions).To(HaveLen(3))
+		})
+
+		It("Should report RunActive from the tracked AG-UI runs", func() {
+			origCounts := ActiveRunCounts
+			defer func() { ActiveRunCounts = origCounts }()
+			ActiveRunCounts = func() map[string]int { return map[string]int{sessionName: 1} }
+
+			path := fmt.Sprintf("/api/projects/%s/agentic-sessions/%s/conditions?type=RunActive", testNamespace, sessionName)
+			context := httpUtils.CreateTestGinContext("GET", path, nil)
+			httpUtils.SetAuthHeader(testToken)
+			httpUtils.SetProjectContext(testNamespace)
+			context.Params = gin.Params{{Key: "sessionName", Value: sessionName}}
+
+			GetSessionConditions(context)
+
+			httpUtils.AssertHTTPStatus(http.StatusOK)
+			var response types.SessionConditionsResponse
+			httpUtils.GetResponseJSON(&response)
+			Expect(response.Conditions).To(HaveLen(1))
+			Expect(response.Conditions[0].Status).To(Equal("True"))
+			Expect(response.Conditions[0].Reason).To(Equal("RunInProgress"))
+		})
+
+		It("Should keep the operator's RunActive reason while no run is tracked", func() {
+			origCounts := ActiveRunCounts
+			defer func() { ActiveRunCounts = origCounts }()
+			ActiveRunCounts = func() map[string]int { return map[string]int{} }
+			session, err := k8sUtils.DynamicClient.Resource(sessionGVR).Namespace(testNamespace).Get(ctx, sessionName, v1.GetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			conditions := []interface{}{
+				map[string]interface{}{"type": "RunActive", "status": "False", "reason": "UserStopped"},
+			}
+			Expect(unstructured.SetNestedSlice(session.Object, conditions, "status", "conditions")).To(Succeed())
+			_, err = k8sUtils.DynamicClient.Resource(sessionGVR).Namespace(testNamespace).Update(ctx, session, v1.UpdateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+
+			path := fmt.Sprintf("/api/projects/%s/agentic-sessions/%s/conditions?type=RunActive", testNamespace, sessionName)
+			context := httpUtils.CreateTestGinContext("GET", path, nil)
+			httpUtils.SetAuthHeader(testToken)
+			httpUtils.SetProjectContext(testNamespace)
+			context.Params = gin.Params{{Key: "sessionName", Value: sessionName}}
+
+			GetSessionConditions(context)
+
+			httpUtils.AssertHTTPStatus(http.StatusOK)
+			var response types.SessionConditionsResponse
+			httpUtils.GetResponseJSON(&response)
+			Expect(response.Conditions).To(HaveLen(1))
+			Expect(response.Conditions[0].Status).To(Equal("False"))
+			Expect(response.Conditions[0].Reason).To(Equal("UserStopped"))
+		})
+
+		It("Should filter by condition type", func() {
+			path := fmt.Sprintf("/api/projects/%s/agentic-sessions/%s/conditions?type=RunnerReady", testNamespace, sessionName)
+			context := httpUtils.CreateTestGinContext("GET", path, nil)
+			httpUtils.SetAuthHeader(testToken)
+			httpUtils.SetProjectContext(testNamespace)
+			context.Params = gin.Params{{Key: "sessionName", Value: sessionName}}
+
+			GetSessionConditions(context)
+
+			httpUtils.AssertHTTPStatus(http.StatusOK)
+			var response types.SessionConditionsResponse
+			httpUtils.GetResponseJSON(&response)
+			Expect(response.Conditions).To(HaveLen(1))
+			Expect(response.Conditions[0].Type).To(Equal(types.ConditionRunnerReady))
+			Expect(response.Conditions[0].Status).To(Equal("False"))
+		})
+
+		It("Should return 404 for missing session", func() {
+			path := fmt.Sprintf("/api/projects/%s/agentic-sessions/missing/conditions", testNamespace)
+			context := httpUtils.CreateTestGinContext("GET", path, nil)
+			httpUtils.SetAuthHeader(testToken)
+			httpUtils.SetProjectContext(testNamespace)
+			context.Params = gin.Params{{Key: "sessionName", Value: "missing"}}
+
+			GetSessionConditions(context)
+
+			httpUtils.AssertHTTPStatus(http.StatusNotFound)
+		})
+	})
+
 	Describe("DeleteSession", func() {
 		var sessionName string
 
@@ -600,6 +792,59 @@ var _ = Describe("Sessions Handler", Label(test_constants.LabelUnit, test_consta
 				Expect(*parsed.Repos[0].AutoPush).To(BeFalse())
 			})
 
+			It("Should parse partial clone options from repo", func() {
+				spec := map[string]interface{}{
+					"repos": []interface{}{
+						map[string]interface{}{
+							"url":          "https://github.com/owner/monorepo.git",
+							"depth":        int64(1),
+							"singleBranch": true,
+							"sparsePaths":  []interface{}{"services/api", "libs/common"},
+						},
+					},
+				}
+
+				parsed := parseSpec(spec)
+				Expect(parsed.Repos).To(HaveLen(1))
+				Expect(parsed.Repos[0].Depth).NotTo(BeNil())
+				Expect(*parsed.Repos[0].Depth).To(Equal(1))
+				Expect(*parsed.Repos[0].SingleBranch).To(BeTrue())
+				Expect(parsed.Repos[0].SparsePaths).To(Equal([]string{"services/api", "libs/common"}))
+
+				opts := repoCloneOptions(parsed.Repos[0])
+				Expect(opts.CloneArgs("https://github.com/owner/monorepo.git", "main", "/workspace/repos/monorepo")).To(Equal([]string{
+					"clone", "--depth", "1", "--single-branch", "--filter=blob:none", "--no-checkout",
+					"--branch", "main", "https://github.com/owner/monorepo.git", "/workspace/repos/monorepo",
+				}))
+			})
+
+			It("Should parse LFS options from repo", func() {
+				spec := map[string]interface{}{
+					"repos": []interface{}{
+						map[string]interface{}{
+							"url": "https://github.com/owner/assets.git",
+							"lfs": map[string]interface{}{"maxObjectSizeMB": int64(50)},
+						},
+					},
+				}
+
+				parsed := parseSpec(spec)
+				Expect(parsed.Repos).To(HaveLen(1))
+				Expect(parsed.Repos[0].LFS).NotTo(BeNil())
+				Expect(parsed.Repos[0].LFS.SkipSmudge).To(BeFalse())
+
+				opts := repoCloneOptions(parsed.Repos[0])
+				Expect(opts.LFS.MaxObjectSizeBytes).To(Equal(int64(50 << 20)))
+				Expect(opts.CloneEnv()).To(ContainElement("GIT_LFS_SKIP_SMUDGE=1"))
+			})
+
+			It("Should reject sparse paths outside the repository", func() {
+				Expect(git.ValidateCloneOptions(git.CloneOptions{SparsePaths: []string{"../etc"}})).To(HaveOccurred())
+				Expect(git.ValidateCloneOptions(git.CloneOptions{SparsePaths: []string{"/abs"}})).To(HaveOccurred())
+				Expect(git.ValidateCloneOptions(git.CloneOptions{Depth: -1})).To(HaveOccurred())
+				Expect(git.ValidateCloneOptions(git.CloneOptions{Depth: 5, SparsePaths: []string{"docs"}})).To(Succeed())
+			})
+
 			It("Should handle missing autoPush field", func() {
 				spec := map[string]interface{}{
 					"repos": []interface{}{
@@ -0,0 +1,202 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"ambient-code-backend/logging"
+	"ambient-code-backend/pathutil"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxSharedFileBytes caps a single shared workspace upload
+const maxSharedFileBytes = 50 << 20
+
+// SharedContentServiceURL returns the content service writing a project's shared
+// workspace volume. The operator runs it when ProjectSettings spec.sharedWorkspace.enabled
+// is set. IMPORTANT: Keep the service name in sync with operator (internal/handlers/shared_workspace.go)
+var SharedContentServiceURL = func(project string) string {
+	return fmt.Sprintf("http://ambient-shared-content.%s.svc:8080", project)
+}
+
+// authorizeSharedWorkspace checks the caller can view the project, or modify it for
+// writes, and returns the project name
+func authorizeSharedWorkspace(c *gin.Context, write bool) (string, bool) {
+	project := c.GetString("project")
+	if project == "" {
+		project = c.Param("projectName")
+	}
+	reqK8s, _ := GetK8sClientsForRequest(c)
+	if reqK8s == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing token"})
+		c.Abort()
+		return "", false
+	}
+
+	check, verb := checkUserCanViewProject, "view"
+	if write {
+		check, verb = checkUserCanModifyProject, "modify"
+	}
+	allowed, err := check(reqK8s, AccessCaller(c), project)
+	if err != nil {
+		logging.Errorf(c, "Shared workspace: failed to check access for %s: %v", project, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify permissions"})
+		return "", false
+	}
+	if !allowed {
+		logging.Warnf(c, "Shared workspace: user not allowed to %s project %s", verb, project)
+		c.JSON(http.StatusForbidden, gin.H{"error": "Unauthorized"})
+		return "", false
+	}
+	return project, true
+}
+
+// sharedWorkspacePath returns the request's path relative to the shared volume root,
+// rejecting paths that escape it
+func sharedWorkspacePath(c *gin.Context, raw string) (string, bool) {
+	sub := strings.TrimPrefix(strings.TrimSpace(raw), "/")
+	base := "/shared"
+	if !pathutil.IsPathWithinBase(filepath.Join(base, sub), base) {
+		logging.Warnf(c, "Shared workspace: path traversal attempt rejected")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid path: must be within the shared workspace"})
+		return "", false
+	}
+	return filepath.ToSlash(sub), true
+}
+
+// proxySharedContent forwards a request to the project's shared content service and
+// relays its response
+func proxySharedContent(c *gin.Context, project, method, path string, body []byte) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(c.Request.Context(), method, SharedContentServiceURL(project)+path, reader)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create request"})
+		return
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	logging.SetRequestIDHeader(req)
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		logging.Errorf(c, "Shared workspace: content service request failed for %s: %v", project, err)
+		c.JSON(http.StatusServiceUnavailable, gin.H{
+			"error": "Shared workspace is not available",
+			"hint":  "Enable it with ProjectSettings spec.sharedWorkspace.enabled and wait for the ambient-shared-content service to become ready.",
+		})
+		return
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to read content service response"})
+		return
+	}
+	c.Data(resp.StatusCode, resp.Header.Get("Content-Type"), b)
+}
+
+// ListSharedWorkspace lists a directory of the project's shared workspace
+// GET /api/projects/:projectName/shared-workspace?path=
+func ListSharedWorkspace(c *gin.Context) {
+	project, ok := authorizeSharedWorkspace(c, false)
+	if !ok {
+		return
+	}
+	path, ok := sharedWorkspacePath(c, c.Query("path"))
+	if !ok {
+		return
+	}
+	proxySharedContent(c, project, http.MethodGet, "/content/list?path="+url.QueryEscape(path), nil)
+}
+
+// GetSharedWorkspaceFile reads a file of the project's shared workspace
+// GET /api/projects/:projectName/shared-workspace/*path
+func GetSharedWorkspaceFile(c *gin.Context) {
+	project, ok := authorizeSharedWorkspace(c, false)
+	if !ok {
+		return
+	}
+	path, ok := sharedWorkspacePath(c, c.Param("path"))
+	if !ok {
+		return
+	}
+	proxySharedContent(c, project, http.MethodGet, "/content/file?path="+url.QueryEscape(path), nil)
+}
+
+// PutSharedWorkspaceFile uploads the request body as a file of the project's shared
+// workspace. Runner pods see it read-only under /shared. Project admins only.
+// PUT /api/projects/:projectName/shared-workspace/*path
+func PutSharedWorkspaceFile(c *gin.Context) {
+	project, ok := authorizeSharedWorkspace(c, true)
+	if !ok {
+		return
+	}
+	path, ok := sharedWorkspacePath(c, c.Param("path"))
+	if !ok {
+		return
+	}
+	if path == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "File path required"})
+		return
+	}
+
+	payload, err := io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, maxSharedFileBytes))
+	if err != nil {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("File exceeds %d MiB", maxSharedFileBytes>>20)})
+		return
+	}
+	contentType := c.GetHeader("Content-Type")
+	if contentType == "" {
+		contentType = http.DetectContentType(payload)
+	}
+	wreq := struct {
+		Path     string `json:"path"`
+		Content  string `json:"content"`
+		Encoding string `json:"encoding"`
+	}{Path: path, Content: string(payload), Encoding: "utf8"}
+	if isBinaryContentType(contentType) || !utf8.Valid(payload) {
+		wreq.Content = base64.StdEncoding.EncodeToString(payload)
+		wreq.Encoding = "base64"
+	}
+	b, err := json.Marshal(wreq)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to prepare request"})
+		return
+	}
+	logging.Infof(c, "Shared workspace: writing %d bytes to %s in %s", len(payload), path, project)
+	proxySharedContent(c, project, http.MethodPost, "/content/write", b)
+}
+
+// DeleteSharedWorkspaceFile removes a file from the project's shared workspace. Project admins only.
+// DELETE /api/projects/:projectName/shared-workspace/*path
+func DeleteSharedWorkspaceFile(c *gin.Context) {
+	project, ok := authorizeSharedWorkspace(c, true)
+	if !ok {
+		return
+	}
+	path, ok := sharedWorkspacePath(c, c.Param("path"))
+	if !ok {
+		return
+	}
+	if path == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "File path required"})
+		return
+	}
+	b, _ := json.Marshal(map[string]string{"path": path})
+	logging.Infof(c, "Shared workspace: deleting %s in %s", path, project)
+	proxySharedContent(c, project, http.MethodDelete, "/content/delete", b)
+}
@@ -0,0 +1,124 @@
+//go:build test
+
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+
+	test_constants "ambient-code-backend/tests/constants"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	authv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+var _ = Describe("Shared Workspace", Label(test_constants.LabelUnit, test_constants.LabelHandlers), func() {
+	var (
+		originalK8sClientMw   kubernetes.Interface
+		originalDynamicClient dynamic.Interface
+		originalStateBaseDir  string
+		originalServiceURL    func(string) string
+		contentService        *httptest.Server
+		sharedDir             string
+		canModify             bool
+		router                *gin.Engine
+	)
+
+	BeforeEach(func() {
+		originalK8sClientMw = K8sClientMw
+		originalDynamicClient = DynamicClient
+		originalStateBaseDir = StateBaseDir
+		originalServiceURL = SharedContentServiceURL
+		canModify = true
+
+		mw := fake.NewSimpleClientset()
+		mw.PrependReactor("create", "selfsubjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			ssar := action.(k8stesting.CreateAction).GetObject().(*authv1.SelfSubjectAccessReview)
+			allowed := ssar.Spec.ResourceAttributes.Verb == "get" || canModify
+			return true, &authv1.SelfSubjectAccessReview{Status: authv1.SubjectAccessReviewStatus{Allowed: allowed}}, nil
+		})
+		K8sClientMw = mw
+		DynamicClient = dynamicfake.NewSimpleDynamicClient(runtime.NewScheme())
+
+		// The shared content service is the backend in CONTENT_SERVICE_MODE over the volume
+		sharedDir = GinkgoT().TempDir()
+		StateBaseDir = sharedDir
+		content := gin.New()
+		content.POST("/content/write", ContentWrite)
+		content.GET("/content/file", ContentRead)
+		content.GET("/content/list", ContentList)
+		content.DELETE("/content/delete", ContentDelete)
+		contentService = httptest.NewServer(content)
+		SharedContentServiceURL = func(string) string { return contentService.URL }
+
+		router = gin.New()
+		router.GET("/api/projects/:projectName/shared-workspace", ListSharedWorkspace)
+		router.GET("/api/projects/:projectName/shared-workspace/*path", GetSharedWorkspaceFile)
+		router.PUT("/api/projects/:projectName/shared-workspace/*path", PutSharedWorkspaceFile)
+		router.DELETE("/api/projects/:projectName/shared-workspace/*path", DeleteSharedWorkspaceFile)
+	})
+
+	AfterEach(func() {
+		contentService.Close()
+		K8sClientMw = originalK8sClientMw
+		DynamicClient = originalDynamicClient
+		StateBaseDir = originalStateBaseDir
+		SharedContentServiceURL = originalServiceURL
+		accessCacheMu.Lock()
+		accessCache = map[string]accessCacheEntry{}
+		accessCacheMu.Unlock()
+	})
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/api/projects/team-a/shared-workspace"+path, strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer test-token")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	It("Should upload, list, read and delete shared files", func() {
+		Expect(do(http.MethodPut, "/guides/style.md", "# Style guide").Code).To(Equal(http.StatusOK))
+		written, err := os.ReadFile(filepath.Join(sharedDir, "guides", "style.md"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(written)).To(Equal("# Style guide"))
+
+		w := do(http.MethodGet, "?path=guides", "")
+		Expect(w.Code).To(Equal(http.StatusOK))
+		Expect(w.Body.String()).To(ContainSubstring(`"name":"style.md"`))
+
+		w = do(http.MethodGet, "/guides/style.md", "")
+		Expect(w.Code).To(Equal(http.StatusOK))
+		Expect(w.Body.String()).To(Equal("# Style guide"))
+
+		Expect(do(http.MethodDelete, "/guides/style.md", "").Code).To(Equal(http.StatusOK))
+		Expect(filepath.Join(sharedDir, "guides", "style.md")).NotTo(BeAnExistingFile())
+	})
+
+	It("Should let viewers read but not change shared files", func() {
+		canModify = false
+		Expect(do(http.MethodPut, "/notes.txt", "hello").Code).To(Equal(http.StatusForbidden))
+		Expect(do(http.MethodDelete, "/notes.txt", "").Code).To(Equal(http.StatusForbidden))
+		Expect(do(http.MethodGet, "", "").Code).To(Equal(http.StatusOK))
+	})
+
+	It("Should reject paths outside the shared workspace", func() {
+		Expect(do(http.MethodGet, "?path=../../etc", "").Code).To(Equal(http.StatusBadRequest))
+	})
+
+	It("Should report a missing content service as unavailable", func() {
+		contentService.Close()
+		Expect(do(http.MethodGet, "", "").Code).To(Equal(http.StatusServiceUnavailable))
+	})
+})
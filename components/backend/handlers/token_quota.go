@@ -0,0 +1,441 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"ambient-code-backend/logging"
+	"ambient-code-backend/metrics"
+	"ambient-code-backend/notifications"
+	"ambient-code-backend/types"
+
+	"github.com/gin-gonic/gin"
+	authv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic"
+)
+
+// Token usage is kept per project and calendar month (UTC) in
+// <StateBaseDir>/token-usage/<project>/<YYYY-MM>.json. Runs are charged to their
+// session's owner with the input and output tokens, including cache writes, of the
+// results the runner reports.
+
+// tokenLedger is a project's token usage and overrides of one month
+type tokenLedger struct {
+	Total     int64                      `json:"total"`
+	Users     map[string]int64           `json:"users,omitempty"`
+	Overrides []types.TokenQuotaOverride `json:"overrides,omitempty"`
+	// Notified are the caps already reported as exceeded: "" for the project, or a user
+	Notified []string `json:"notified,omitempty"`
+}
+
+// tokenLedgerMu serializes reads and writes of all ledgers
+var tokenLedgerMu sync.Mutex
+
+func quotaMonth(t time.Time) string {
+	return t.UTC().Format("2006-01")
+}
+
+func tokenLedgerPath(project, month string) string {
+	return filepath.Join(StateBaseDir, "token-usage", project, month+".json")
+}
+
+// loadTokenLedger reads a ledger, empty when none was written; tokenLedgerMu must be held
+func loadTokenLedger(project, month string) (*tokenLedger, error) {
+	ledger := &tokenLedger{Users: map[string]int64{}}
+	data, err := os.ReadFile(tokenLedgerPath(project, month))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return ledger, nil
+		}
+		return nil, err
+	}
+	if err := json.Unmarshal(data, ledger); err != nil {
+		return nil, err
+	}
+	if ledger.Users == nil {
+		ledger.Users = map[string]int64{}
+	}
+	return ledger, nil
+}
+
+// saveTokenLedger replaces a ledger; tokenLedgerMu must be held
+func saveTokenLedger(project, month string, ledger *tokenLedger) error {
+	path := tokenLedgerPath(project, month)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.Marshal(ledger)
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// tokenQuotaFromSettings reads spec.tokenQuota from a ProjectSettings object
+func tokenQuotaFromSettings(obj *unstructured.Unstructured) (*types.TokenQuota, error) {
+	raw, found, err := unstructured.NestedMap(obj.Object, "spec", "tokenQuota")
+	if err != nil || !found {
+		return nil, err
+	}
+	var quota types.TokenQuota
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(raw, &quota); err != nil {
+		return nil, fmt.Errorf("invalid tokenQuota: %w", err)
+	}
+	return &quota, nil
+}
+
+// getTokenQuota returns the project's token quota, or nil if none is configured
+func getTokenQuota(ctx context.Context, dynClient dynamic.Interface, project string) (*types.TokenQuota, error) {
+	obj, err := dynClient.Resource(GetProjectSettingsResource()).Namespace(project).Get(ctx, "projectsettings", v1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return tokenQuotaFromSettings(obj)
+}
+
+func validateTokenQuota(quota types.TokenQuota) error {
+	if quota.MonthlyTokens < 0 || quota.PerUserMonthlyTokens < 0 {
+		return fmt.Errorf("token caps must not be negative")
+	}
+	for user, limit := range quota.Users {
+		if strings.TrimSpace(user) == "" {
+			return fmt.Errorf("users: empty user ID")
+		}
+		if limit < 0 {
+			return fmt.Errorf("users.%s must not be negative", user)
+		}
+	}
+	return nil
+}
+
+// quotaUsage compares used against a cap raised by overrides; a cap of 0 is unlimited
+func quotaUsage(user string, used, limit int64, overrides []types.TokenQuotaOverride) types.TokenQuotaUsage {
+	usage := types.TokenQuotaUsage{User: user, Used: used}
+	if limit <= 0 {
+		return usage
+	}
+	for _, o := range overrides {
+		if o.User == user {
+			limit += o.Tokens
+		}
+	}
+	remaining := max(limit-used, 0)
+	usage.Limit = limit
+	usage.Remaining = &remaining
+	usage.Exceeded = used >= limit
+	return usage
+}
+
+// tokenQuotaStatus is the month's usage of the project and, when set, one user
+func tokenQuotaStatus(quota *types.TokenQuota, ledger *tokenLedger, now time.Time, user string) types.TokenQuotaStatus {
+	if quota == nil {
+		quota = &types.TokenQuota{}
+	}
+	month := time.Date(now.UTC().Year(), now.UTC().Month(), 1, 0, 0, 0, 0, time.UTC)
+	status := types.TokenQuotaStatus{
+		Month:     quotaMonth(now),
+		ResetsAt:  month.AddDate(0, 1, 0).Format(time.RFC3339),
+		Project:   quotaUsage("", ledger.Total, quota.MonthlyTokens, ledger.Overrides),
+		Overrides: ledger.Overrides,
+	}
+	if user != "" {
+		limit := quota.PerUserMonthlyTokens
+		if v, ok := quota.Users[user]; ok {
+			limit = v
+		}
+		u := quotaUsage(user, ledger.Users[user], limit, ledger.Overrides)
+		status.User = &u
+	}
+	return status
+}
+
+// sessionOwner returns the user a session's runs are charged to
+func sessionOwner(ctx context.Context, project, sessionName string) string {
+	obj, err := GetCachedSession(ctx, project, sessionName)
+	if err != nil {
+		return ""
+	}
+	owner, _, _ := unstructured.NestedString(obj.Object, "spec", "userContext", "userId")
+	return owner
+}
+
+// CheckTokenQuota returns the month's quota status when the project or the session's
+// owner used up their tokens, nil when a run may start. Runs are allowed when the
+// quota can't be read.
+func CheckTokenQuota(ctx context.Context, project, sessionName string) *types.TokenQuotaStatus {
+	if DynamicClient == nil {
+		return nil
+	}
+	quota, err := getTokenQuota(ctx, DynamicClient, project)
+	if err != nil {
+		logging.Errorf(ctx, "Token quota: failed to read the quota of %s: %v", project, err)
+		return nil
+	}
+	if quota == nil {
+		return nil
+	}
+	now := time.Now()
+	tokenLedgerMu.Lock()
+	ledger, err := loadTokenLedger(project, quotaMonth(now))
+	tokenLedgerMu.Unlock()
+	if err != nil {
+		logging.Errorf(ctx, "Token quota: failed to read the usage of %s: %v", project, err)
+		return nil
+	}
+	status := tokenQuotaStatus(quota, ledger, now, sessionOwner(ctx, project, sessionName))
+	switch {
+	case status.Project.Exceeded:
+		metrics.ObserveTokenQuotaRejection("project")
+	case status.User != nil && status.User.Exceeded:
+		metrics.ObserveTokenQuotaRejection("user")
+	default:
+		return nil
+	}
+	return &status
+}
+
+// RecordTokenUsage charges tokens used by a run of the session to the project and the
+// session's owner, and notifies the project the first time a cap is exceeded in a month
+func RecordTokenUsage(ctx context.Context, project, sessionName string, tokens int64) {
+	if tokens <= 0 {
+		return
+	}
+	owner := sessionOwner(ctx, project, sessionName)
+	var quota *types.TokenQuota
+	if DynamicClient != nil {
+		var err error
+		if quota, err = getTokenQuota(ctx, DynamicClient, project); err != nil {
+			logging.Warnf(ctx, "Token quota: failed to read the quota of %s: %v", project, err)
+		}
+	}
+	now := time.Now()
+	month := quotaMonth(now)
+
+	tokenLedgerMu.Lock()
+	ledger, err := loadTokenLedger(project, month)
+	if err != nil {
+		tokenLedgerMu.Unlock()
+		logging.Errorf(ctx, "Token quota: failed to read the usage of %s: %v", project, err)
+		return
+	}
+	ledger.Total += tokens
+	if owner != "" {
+		ledger.Users[owner] += tokens
+	}
+	var exceeded []types.TokenQuotaUsage
+	if quota != nil {
+		status := tokenQuotaStatus(quota, ledger, now, owner)
+		for _, usage := range []*types.TokenQuotaUsage{&status.Project, status.User} {
+			if usage != nil && usage.Exceeded && !slices.Contains(ledger.Notified, usage.User) {
+				ledger.Notified = append(ledger.Notified, usage.User)
+				exceeded = append(exceeded, *usage)
+			}
+		}
+	}
+	err = saveTokenLedger(project, month, ledger)
+	tokenLedgerMu.Unlock()
+	if err != nil {
+		logging.Errorf(ctx, "Token quota: failed to record the usage of %s: %v", project, err)
+	}
+
+	for _, usage := range exceeded {
+		notifyTokenQuotaExceeded(project, sessionName, usage)
+	}
+}
+
+// notifyTokenQuotaExceeded raises budget.exceeded for the project's notification rules
+func notifyTokenQuotaExceeded(project, sessionName string, usage types.TokenQuotaUsage) {
+	if Notifier == nil {
+		return
+	}
+	detail := fmt.Sprintf("The project used %d of its %d monthly tokens. New runs are refused until the quota resets or an admin grants an override.", usage.Used, usage.Limit)
+	if usage.User != "" {
+		detail = fmt.Sprintf("%s used %d of their %d monthly tokens. Their new runs are refused until the quota resets or an admin grants an override.", usage.User, usage.Used, usage.Limit)
+	}
+	Notifier.Notify(notifications.Event{
+		Type:    notifications.EventBudgetExceeded,
+		Project: project,
+		Session: sessionName,
+		Detail:  detail,
+		URL:     sessionTranscriptURL(project, sessionName),
+	})
+}
+
+// GetTokenQuota handles GET /api/projects/:projectName/token-quota?user=
+// Returns the quota and this month's usage of the project and the caller (or ?user=).
+func GetTokenQuota(c *gin.Context) {
+	project := c.GetString("project")
+	_, reqDyn := GetK8sClientsForRequest(c)
+	if reqDyn == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing token"})
+		c.Abort()
+		return
+	}
+
+	quota, err := getTokenQuota(c.Request.Context(), reqDyn, project)
+	if err != nil {
+		if errors.IsForbidden(err) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions to read project settings"})
+			return
+		}
+		logging.Errorf(c, "Failed to get token quota for project %s: %v", project, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get token quota"})
+		return
+	}
+	user := c.Query("user")
+	if user == "" {
+		user = c.GetString("userID")
+	}
+
+	now := time.Now()
+	tokenLedgerMu.Lock()
+	ledger, err := loadTokenLedger(project, quotaMonth(now))
+	tokenLedgerMu.Unlock()
+	if err != nil {
+		logging.Errorf(c, "Failed to read token usage for project %s: %v", project, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read token usage"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"quota": quota, "usage": tokenQuotaStatus(quota, ledger, now, user)})
+}
+
+// UpdateTokenQuota handles PUT /api/projects/:projectName/token-quota
+// Requires update permission on ProjectSettings (project admins). Applies to runs started afterwards.
+func UpdateTokenQuota(c *gin.Context) {
+	project := c.GetString("project")
+	_, reqDyn := GetK8sClientsForRequest(c)
+	if reqDyn == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing token"})
+		c.Abort()
+		return
+	}
+
+	var quota types.TokenQuota
+	if err := c.ShouldBindJSON(&quota); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := validateTokenQuota(quota); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	value, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&quota)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := setProjectSettingsField(c.Request.Context(), reqDyn, project, "tokenQuota", value); err != nil {
+		respondProjectSettingsError(c, project, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"quota": quota})
+}
+
+// DeleteTokenQuota handles DELETE /api/projects/:projectName/token-quota
+func DeleteTokenQuota(c *gin.Context) {
+	project := c.GetString("project")
+	_, reqDyn := GetK8sClientsForRequest(c)
+	if reqDyn == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing token"})
+		c.Abort()
+		return
+	}
+
+	if err := setProjectSettingsField(c.Request.Context(), reqDyn, project, "tokenQuota", nil); err != nil && !errors.IsNotFound(err) {
+		respondProjectSettingsError(c, project, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Token quota removed successfully"})
+}
+
+// GrantTokenQuotaOverride handles POST /api/projects/:projectName/token-quota/overrides
+// Raises the project's (no user) or a user's cap by tokens until the month ends.
+// Requires update permission on ProjectSettings (project admins).
+func GrantTokenQuotaOverride(c *gin.Context) {
+	project := c.GetString("project")
+	reqK8s, _ := GetK8sClientsForRequest(c)
+	if reqK8s == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing token"})
+		c.Abort()
+		return
+	}
+
+	// Overrides live in the backend's usage ledger, so RBAC is checked explicitly
+	allowed, err := CheckAccessForRequest(c, reqK8s, authv1.ResourceAttributes{
+		Group:     GetProjectSettingsResource().Group,
+		Resource:  GetProjectSettingsResource().Resource,
+		Verb:      "update",
+		Namespace: project,
+	})
+	if err != nil {
+		logging.Errorf(c, "Token quota: SSAR failed for %s: %v", project, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to verify permissions"})
+		return
+	}
+	if !allowed {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions to override the token quota"})
+		return
+	}
+
+	var override types.TokenQuotaOverride
+	if err := c.ShouldBindJSON(&override); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	override.User = strings.TrimSpace(override.User)
+	if override.Tokens <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "tokens must be positive"})
+		return
+	}
+	now := time.Now()
+	override.GrantedBy = c.GetString("userID")
+	override.GrantedAt = now.UTC().Format(time.RFC3339)
+
+	month := quotaMonth(now)
+	tokenLedgerMu.Lock()
+	ledger, err := loadTokenLedger(project, month)
+	if err == nil {
+		ledger.Overrides = append(ledger.Overrides, override)
+		// A cap exceeded again after the override is reported again
+		var notified []string
+		for _, n := range ledger.Notified {
+			if n != override.User {
+				notified = append(notified, n)
+			}
+		}
+		ledger.Notified = notified
+		err = saveTokenLedger(project, month, ledger)
+	}
+	tokenLedgerMu.Unlock()
+	if err != nil {
+		logging.Errorf(c, "Token quota: failed to record override for %s: %v", project, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record override"})
+		return
+	}
+	logging.Infof(c, "Token quota: %s granted %d tokens to %s/%s for %s", override.GrantedBy, override.Tokens, project, override.User, month)
+	c.JSON(http.StatusCreated, gin.H{"override": override})
+}
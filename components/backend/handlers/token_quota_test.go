@@ -0,0 +1,116 @@
+//go:build test
+
+package handlers
+
+import (
+	"context"
+	"time"
+
+	test_constants "ambient-code-backend/tests/constants"
+	"ambient-code-backend/types"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+var _ = Describe("Token Quotas", Label(test_constants.LabelUnit, test_constants.LabelHandlers), func() {
+	gvr := schema.GroupVersionResource{Group: "vteam.ambient-code", Version: "v1alpha1", Resource: "agenticsessions"}
+
+	var (
+		originalDynamicClient dynamic.Interface
+		originalGVR           func() schema.GroupVersionResource
+		originalStateBaseDir  string
+		ctx                   context.Context
+	)
+
+	session := func(name, owner string) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "vteam.ambient-code/v1alpha1",
+			"kind":       "AgenticSession",
+			"metadata":   map[string]interface{}{"name": name, "namespace": "team-a"},
+			"spec":       map[string]interface{}{"userContext": map[string]interface{}{"userId": owner}},
+		}}
+	}
+	settings := func(quota map[string]interface{}) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "vteam.ambient-code/v1alpha1",
+			"kind":       "ProjectSettings",
+			"metadata":   map[string]interface{}{"name": "projectsettings", "namespace": "team-a"},
+			"spec":       map[string]interface{}{"tokenQuota": quota},
+		}}
+	}
+
+	BeforeEach(func() {
+		originalDynamicClient, originalGVR, originalStateBaseDir = DynamicClient, GetAgenticSessionV1Alpha1Resource, StateBaseDir
+		GetAgenticSessionV1Alpha1Resource = func() schema.GroupVersionResource { return gvr }
+		StateBaseDir = GinkgoT().TempDir()
+		ctx = context.Background()
+	})
+
+	AfterEach(func() {
+		DynamicClient, GetAgenticSessionV1Alpha1Resource, StateBaseDir = originalDynamicClient, originalGVR, originalStateBaseDir
+	})
+
+	It("Should raise caps by the month's overrides and report remaining tokens", func() {
+		quota := &types.TokenQuota{MonthlyTokens: 1000, PerUserMonthlyTokens: 100, Users: map[string]int64{"alice": 300}}
+		ledger := &tokenLedger{
+			Total:     900,
+			Users:     map[string]int64{"alice": 350, "bob": 100},
+			Overrides: []types.TokenQuotaOverride{{User: "alice", Tokens: 100}, {Tokens: 50}},
+		}
+		now := time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC)
+
+		status := tokenQuotaStatus(quota, ledger, now, "alice")
+		Expect(status.Month).To(Equal("2026-10"))
+		Expect(status.ResetsAt).To(Equal("2026-11-01T00:00:00Z"))
+		Expect(status.Project.Limit).To(Equal(int64(1050)))
+		Expect(*status.Project.Remaining).To(Equal(int64(150)))
+		Expect(status.User.Limit).To(Equal(int64(400)))
+		Expect(status.User.Exceeded).To(BeFalse())
+
+		Expect(tokenQuotaStatus(quota, ledger, now, "bob").User.Exceeded).To(BeTrue())
+		unlimited := tokenQuotaStatus(&types.TokenQuota{}, ledger, now, "bob")
+		Expect(unlimited.Project.Remaining).To(BeNil())
+		Expect(unlimited.User.Exceeded).To(BeFalse())
+	})
+
+	It("Should refuse runs once the session owner's tokens are used up", func() {
+		DynamicClient = dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+			map[schema.GroupVersionResource]string{
+				gvr:                          "AgenticSessionList",
+				GetProjectSettingsResource(): "ProjectSettingsList",
+			},
+			session("alice-session", "alice"),
+			session("bob-session", "bob"),
+		)
+		_, err := DynamicClient.Resource(GetProjectSettingsResource()).Namespace("team-a").Create(ctx,
+			settings(map[string]interface{}{"perUserMonthlyTokens": int64(1000)}), v1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred())
+
+		RecordTokenUsage(ctx, "team-a", "alice-session", 600)
+		Expect(CheckTokenQuota(ctx, "team-a", "alice-session")).To(BeNil())
+		RecordTokenUsage(ctx, "team-a", "alice-session", 400)
+
+		status := CheckTokenQuota(ctx, "team-a", "alice-session")
+		Expect(status).NotTo(BeNil())
+		Expect(status.User.User).To(Equal("alice"))
+		Expect(status.User.Used).To(Equal(int64(1000)))
+		Expect(CheckTokenQuota(ctx, "team-a", "bob-session")).To(BeNil())
+
+		tokenLedgerMu.Lock()
+		ledger, err := loadTokenLedger("team-a", quotaMonth(time.Now()))
+		Expect(err).NotTo(HaveOccurred())
+		ledger.Overrides = append(ledger.Overrides, types.TokenQuotaOverride{User: "alice", Tokens: 500})
+		Expect(saveTokenLedger("team-a", quotaMonth(time.Now()), ledger)).To(Succeed())
+		tokenLedgerMu.Unlock()
+		Expect(ledger.Total).To(Equal(int64(1000)))
+		Expect(ledger.Notified).To(ConsistOf("alice"))
+		Expect(CheckTokenQuota(ctx, "team-a", "alice-session")).To(BeNil())
+	})
+})
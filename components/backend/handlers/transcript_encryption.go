@@ -0,0 +1,271 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"ambient-code-backend/kms"
+	"ambient-code-backend/logging"
+
+	"github.com/gin-gonic/gin"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+)
+
+// transcriptKeysSecretName holds each project's wrapped transcript data key, keyed by
+// key ID ("<project>.<random hex>"). Only the KMS provider can unwrap them.
+const transcriptKeysSecretName = "ambient-transcript-keys"
+
+// TranscriptEncryption is ProjectSettings spec.transcriptEncryption
+type TranscriptEncryption struct {
+	Enabled bool `json:"enabled"`
+}
+
+var (
+	transcriptKeysMu sync.Mutex
+	// transcriptKeys caches unwrapped data keys by key ID
+	transcriptKeys = map[string][]byte{}
+	// transcriptKeyIDs caches each project's key ID
+	transcriptKeyIDs = map[string]string{}
+)
+
+// transcriptEncryptionFromSettings reads spec.transcriptEncryption from a ProjectSettings object
+func transcriptEncryptionFromSettings(obj *unstructured.Unstructured) TranscriptEncryption {
+	enabled, _, _ := unstructured.NestedBool(obj.Object, "spec", "transcriptEncryption", "enabled")
+	return TranscriptEncryption{Enabled: enabled}
+}
+
+// getTranscriptEncryption returns the project's transcript encryption setting
+func getTranscriptEncryption(ctx context.Context, dynClient dynamic.Interface, project string) (TranscriptEncryption, error) {
+	obj, err := dynClient.Resource(GetProjectSettingsResource()).Namespace(project).Get(ctx, "projectsettings", v1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return TranscriptEncryption{}, nil
+		}
+		return TranscriptEncryption{}, err
+	}
+	return transcriptEncryptionFromSettings(obj), nil
+}
+
+// TranscriptEncryptionEnabled reports whether new runs in the project persist their
+// events encrypted. It is false when no KMS provider is configured.
+func TranscriptEncryptionEnabled(ctx context.Context, project string) bool {
+	if DynamicClient == nil || kms.Current() == nil {
+		return false
+	}
+	setting, err := getTranscriptEncryption(ctx, DynamicClient, project)
+	if err != nil {
+		logging.Errorf(ctx, "Failed to load transcript encryption setting for project %s: %v", project, err)
+		return false
+	}
+	return setting.Enabled
+}
+
+// projectForTranscriptKey returns the project a key ID belongs to
+func projectForTranscriptKey(keyID string) string {
+	i := strings.LastIndex(keyID, ".")
+	if i <= 0 {
+		return ""
+	}
+	return keyID[:i]
+}
+
+// TranscriptDataKey returns the project's data key and its ID, generating and storing
+// a wrapped key the first time
+func TranscriptDataKey(ctx context.Context, project string) (string, []byte, error) {
+	transcriptKeysMu.Lock()
+	if id, ok := transcriptKeyIDs[project]; ok {
+		key := transcriptKeys[id]
+		transcriptKeysMu.Unlock()
+		return id, key, nil
+	}
+	transcriptKeysMu.Unlock()
+
+	provider := kms.Current()
+	if provider == nil {
+		return "", nil, fmt.Errorf("no KMS provider is configured")
+	}
+	if K8sClient == nil {
+		return "", nil, fmt.Errorf("kubernetes client not initialized")
+	}
+
+	for i := 0; i < 3; i++ { // retry on conflict
+		secret, err := K8sClient.CoreV1().Secrets(Namespace).Get(ctx, transcriptKeysSecretName, v1.GetOptions{})
+		if err != nil {
+			if !errors.IsNotFound(err) {
+				return "", nil, fmt.Errorf("failed to get Secret: %w", err)
+			}
+			secret = &corev1.Secret{
+				ObjectMeta: v1.ObjectMeta{
+					Name:      transcriptKeysSecretName,
+					Namespace: Namespace,
+					Labels:    map[string]string{"app": "ambient-code"},
+				},
+				Type: corev1.SecretTypeOpaque,
+				Data: map[string][]byte{},
+			}
+			if _, cerr := K8sClient.CoreV1().Secrets(Namespace).Create(ctx, secret, v1.CreateOptions{}); cerr != nil && !errors.IsAlreadyExists(cerr) {
+				return "", nil, fmt.Errorf("failed to create Secret: %w", cerr)
+			}
+			if secret, err = K8sClient.CoreV1().Secrets(Namespace).Get(ctx, transcriptKeysSecretName, v1.GetOptions{}); err != nil {
+				return "", nil, fmt.Errorf("failed to fetch Secret after create: %w", err)
+			}
+		}
+
+		for id := range secret.Data {
+			if projectForTranscriptKey(id) == project {
+				key, err := TranscriptKey(ctx, id)
+				if err != nil {
+					return "", nil, err
+				}
+				transcriptKeysMu.Lock()
+				transcriptKeyIDs[project] = id
+				transcriptKeysMu.Unlock()
+				return id, key, nil
+			}
+		}
+
+		key, err := kms.NewDataKey()
+		if err != nil {
+			return "", nil, err
+		}
+		wrapped, err := provider.Wrap(ctx, key)
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to wrap data key with %s KMS: %w", provider.Name(), err)
+		}
+		suffix := make([]byte, 4)
+		if _, err := rand.Read(suffix); err != nil {
+			return "", nil, err
+		}
+		id := project + "." + hex.EncodeToString(suffix)
+		if secret.Data == nil {
+			secret.Data = map[string][]byte{}
+		}
+		secret.Data[id] = wrapped
+		if _, uerr := K8sClient.CoreV1().Secrets(Namespace).Update(ctx, secret, v1.UpdateOptions{}); uerr != nil {
+			if errors.IsConflict(uerr) {
+				continue // retry; another replica may have created the key
+			}
+			return "", nil, fmt.Errorf("failed to update Secret: %w", uerr)
+		}
+
+		transcriptKeysMu.Lock()
+		transcriptKeys[id] = key
+		transcriptKeyIDs[project] = id
+		transcriptKeysMu.Unlock()
+		logging.Infof(ctx, "Created transcript data key %s for project %s", id, project)
+		return id, key, nil
+	}
+	return "", nil, fmt.Errorf("failed to update Secret after retries")
+}
+
+// TranscriptKey returns the unwrapped data key with the given ID
+func TranscriptKey(ctx context.Context, keyID string) ([]byte, error) {
+	transcriptKeysMu.Lock()
+	key, ok := transcriptKeys[keyID]
+	transcriptKeysMu.Unlock()
+	if ok {
+		return key, nil
+	}
+
+	provider := kms.Current()
+	if provider == nil {
+		return nil, fmt.Errorf("no KMS provider is configured")
+	}
+	if K8sClient == nil {
+		return nil, fmt.Errorf("kubernetes client not initialized")
+	}
+	secret, err := K8sClient.CoreV1().Secrets(Namespace).Get(ctx, transcriptKeysSecretName, v1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Secret: %w", err)
+	}
+	wrapped, ok := secret.Data[keyID]
+	if !ok {
+		return nil, fmt.Errorf("transcript key %s not found", keyID)
+	}
+	key, err = provider.Unwrap(ctx, wrapped)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap transcript key %s with %s KMS: %w", keyID, provider.Name(), err)
+	}
+
+	transcriptKeysMu.Lock()
+	transcriptKeys[keyID] = key
+	transcriptKeysMu.Unlock()
+	return key, nil
+}
+
+// GetTranscriptEncryption handles GET /api/projects/:projectName/transcript-encryption
+func GetTranscriptEncryption(c *gin.Context) {
+	project := c.GetString("project")
+	_, reqDyn := GetK8sClientsForRequest(c)
+	if reqDyn == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing token"})
+		c.Abort()
+		return
+	}
+
+	setting, err := getTranscriptEncryption(c.Request.Context(), reqDyn, project)
+	if err != nil {
+		if errors.IsForbidden(err) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions to read project settings"})
+			return
+		}
+		logging.Errorf(c, "Failed to get transcript encryption for project %s: %v", project, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get transcript encryption"})
+		return
+	}
+	provider := ""
+	if p := kms.Current(); p != nil {
+		provider = p.Name()
+	}
+	c.JSON(http.StatusOK, gin.H{"enabled": setting.Enabled, "kmsProvider": provider})
+}
+
+// UpdateTranscriptEncryption handles PUT /api/projects/:projectName/transcript-encryption
+// Requires update permission on ProjectSettings (project admins). Applies to sessions
+// whose next run starts afterwards; sessions already encrypted stay encrypted.
+func UpdateTranscriptEncryption(c *gin.Context) {
+	project := c.GetString("project")
+	_, reqDyn := GetK8sClientsForRequest(c)
+	if reqDyn == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing token"})
+		c.Abort()
+		return
+	}
+
+	var setting TranscriptEncryption
+	if err := c.ShouldBindJSON(&setting); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if setting.Enabled && kms.Current() == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Transcript encryption needs a KMS provider; none is configured on this deployment"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	if err := setProjectSettingsField(ctx, reqDyn, project, "transcriptEncryption", map[string]interface{}{"enabled": setting.Enabled}); err != nil {
+		respondProjectSettingsError(c, project, err)
+		return
+	}
+	if setting.Enabled {
+		// Create the data key now so KMS problems surface here rather than on the first run
+		if _, _, err := TranscriptDataKey(ctx, project); err != nil {
+			logging.Errorf(c, "Failed to create transcript data key for project %s: %v", project, err)
+			if rerr := setProjectSettingsField(ctx, reqDyn, project, "transcriptEncryption", map[string]interface{}{"enabled": false}); rerr != nil {
+				logging.Errorf(c, "Failed to roll back transcript encryption for project %s: %v", project, rerr)
+			}
+			c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to create the project's data key with the KMS provider"})
+			return
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{"enabled": setting.Enabled})
+}
@@ -0,0 +1,91 @@
+//go:build test
+
+package handlers
+
+import (
+	"bytes"
+	"context"
+
+	"ambient-code-backend/kms"
+	test_constants "ambient-code-backend/tests/constants"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+var _ = Describe("Transcript Encryption", Label(test_constants.LabelUnit, test_constants.LabelHandlers), func() {
+	var (
+		originalK8sClient kubernetes.Interface
+		originalNamespace string
+	)
+
+	BeforeEach(func() {
+		originalK8sClient = K8sClient
+		originalNamespace = Namespace
+		K8sClient = fake.NewSimpleClientset()
+		Namespace = "ambient-code"
+		provider, err := kms.NewLocalProvider(bytes.Repeat([]byte{3}, kms.DataKeySize))
+		Expect(err).NotTo(HaveOccurred())
+		kms.Configure(provider)
+	})
+
+	AfterEach(func() {
+		K8sClient = originalK8sClient
+		Namespace = originalNamespace
+		kms.Configure(nil)
+		transcriptKeysMu.Lock()
+		transcriptKeys = map[string][]byte{}
+		transcriptKeyIDs = map[string]string{}
+		transcriptKeysMu.Unlock()
+	})
+
+	It("Should read the setting from ProjectSettings", func() {
+		obj := &unstructured.Unstructured{Object: map[string]interface{}{
+			"spec": map[string]interface{}{"transcriptEncryption": map[string]interface{}{"enabled": true}},
+		}}
+		Expect(transcriptEncryptionFromSettings(obj).Enabled).To(BeTrue())
+		Expect(transcriptEncryptionFromSettings(&unstructured.Unstructured{Object: map[string]interface{}{}}).Enabled).To(BeFalse())
+	})
+
+	It("Should store one wrapped data key per project and unwrap it after a restart", func() {
+		ctx := context.Background()
+		id, key, err := TranscriptDataKey(ctx, "team-a")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(key).To(HaveLen(kms.DataKeySize))
+		Expect(projectForTranscriptKey(id)).To(Equal("team-a"))
+
+		again, _, err := TranscriptDataKey(ctx, "team-a")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(again).To(Equal(id))
+		other, _, err := TranscriptDataKey(ctx, "team-b")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(other).NotTo(Equal(id))
+
+		secret, err := K8sClient.CoreV1().Secrets(Namespace).Get(ctx, transcriptKeysSecretName, v1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(secret.Data).To(HaveLen(2))
+		Expect(bytes.Contains(secret.Data[id], key)).To(BeFalse())
+
+		// A fresh process only has the Secret
+		transcriptKeysMu.Lock()
+		transcriptKeys = map[string][]byte{}
+		transcriptKeyIDs = map[string]string{}
+		transcriptKeysMu.Unlock()
+		unwrapped, err := TranscriptKey(ctx, id)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(unwrapped).To(Equal(key))
+		reloaded, _, err := TranscriptDataKey(ctx, "team-a")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(reloaded).To(Equal(id))
+	})
+
+	It("Should fail without a KMS provider", func() {
+		kms.Configure(nil)
+		_, _, err := TranscriptDataKey(context.Background(), "team-a")
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,241 @@
+package handlers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"ambient-code-backend/audit"
+	"ambient-code-backend/logging"
+	"ambient-code-backend/types"
+
+	"github.com/gin-gonic/gin"
+	"k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/dynamic"
+)
+
+// PurgeSessionData removes a session's persisted transcript and related files and
+// returns how many were removed. Set from main to avoid an import cycle with the
+// websocket package; nil skips transcript removal.
+var PurgeSessionData func(project, sessionName string) (int, error)
+
+// PurgeUserContributions removes the annotations a user added to sessions that remain
+// after their own were erased. It returns how many it removed and the feedback the user
+// gave in those sessions, which stays in their event logs. Set from the websocket
+// package; nil skips it.
+var PurgeUserContributions func(userID string) (int, []types.ErasureRetained, error)
+
+// auditPathContextKey overrides the path the audit log records for a request, so the
+// erasure request itself doesn't record the erased user ID
+const auditPathContextKey = "auditPath"
+
+// erasedUserPseudonym is the stable ID an erased user's audit records are rewritten to
+func erasedUserPseudonym(userID string) string {
+	sum := sha256.Sum256([]byte(userID))
+	return "erased-" + hex.EncodeToString(sum[:8])
+}
+
+// userCredentialStores deletes each kind of credential a user can connect. Each
+// deleter succeeds when there is nothing to delete.
+var userCredentialStores = []struct {
+	kind   string
+	delete func(ctx context.Context, userID string) error
+}{
+	{"github-app", func(ctx context.Context, userID string) error {
+		if err := deleteGitHubInstallation(ctx, userID); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+		return nil
+	}},
+	{"github-pat", DeleteGitHubPATCredentials},
+	{"gitlab", DeleteGitLabCredentials},
+	{"jira", DeleteJiraCredentials},
+	{"linear", DeleteLinearCredentials},
+	{"google", DeleteGoogleCredentials},
+	{"signing-key", deleteUserSigningKey},
+}
+
+// EraseUserData handles DELETE /api/users/:userId/data?reassignTo=<userId>
+// Cluster admins only. Removes the user's stored credentials and API keys, deletes the
+// sessions they own with their transcripts (or hands them to reassignTo), removes their
+// annotations on other sessions, and pseudonymizes the user in the audit log. Returns a
+// deletion report; safe to retry.
+func EraseUserData(c *gin.Context) {
+	_, reqDyn := GetK8sClientsForRequest(c)
+	if reqDyn == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing token"})
+		c.Abort()
+		return
+	}
+
+	userID := c.Param("userId")
+	if !isValidUserID(userID) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid user identifier"})
+		return
+	}
+	reassignTo := c.Query("reassignTo")
+	if reassignTo != "" && (!isValidUserID(reassignTo) || reassignTo == userID) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "reassignTo must be another valid user identifier"})
+		return
+	}
+
+	pseudonym := erasedUserPseudonym(userID)
+	c.Set(auditPathContextKey, "/api/users/"+pseudonym+"/data")
+
+	ctx, cancel := context.WithTimeout(c.Request.Context(), 2*time.Minute)
+	defer cancel()
+
+	report := types.UserErasureReport{
+		UserPseudonym: pseudonym,
+		RequestedBy:   c.GetString("userID"),
+		StartedAt:     time.Now().UTC().Format(time.RFC3339),
+		ReassignedTo:  reassignTo,
+		Credentials:   []types.ErasureStep{},
+		Sessions:      []types.ErasureSession{},
+		Retained:      []types.ErasureRetained{},
+		Complete:      true,
+	}
+	fail := func(step *types.ErasureStep, err error) {
+		step.Status = types.ErasureFailed
+		step.Error = err.Error()
+		report.Complete = false
+	}
+
+	for _, store := range userCredentialStores {
+		step := types.ErasureStep{Kind: store.kind, Status: types.ErasureRemoved}
+		if err := store.delete(ctx, userID); err != nil {
+			logging.Errorf(c, "EraseUserData: failed to remove %s credentials of %s: %v", store.kind, pseudonym, err)
+			fail(&step, err)
+		}
+		report.Credentials = append(report.Credentials, step)
+	}
+
+	report.APIKeys = types.ErasureStep{Kind: "api-keys", Status: types.ErasureRemoved}
+	if err := updateAPIKeys(ctx, func(keys map[string]*APIKey) error {
+		for id, key := range keys {
+			if key.UserID == userID {
+				delete(keys, id)
+				report.APIKeys.Count++
+			}
+		}
+		return nil
+	}); err != nil {
+		logging.Errorf(c, "EraseUserData: failed to revoke API keys of %s: %v", pseudonym, err)
+		report.APIKeys.Count = 0
+		fail(&report.APIKeys, err)
+	}
+
+	sessions, err := eraseUserSessions(ctx, reqDyn, userID, reassignTo)
+	report.Sessions = sessions
+	if err != nil {
+		logging.Errorf(c, "EraseUserData: failed to list sessions of %s: %v", pseudonym, err)
+		report.Complete = false
+		report.Notes = append(report.Notes, "Sessions could not be listed: "+err.Error())
+	}
+	for _, s := range sessions {
+		if s.Status == types.ErasureFailed {
+			report.Complete = false
+		}
+	}
+
+	report.Annotations = types.ErasureStep{Kind: "annotations", Status: types.ErasureRemoved}
+	if PurgeUserContributions != nil {
+		n, retained, err := PurgeUserContributions(userID)
+		report.Annotations.Count = n
+		if retained != nil {
+			report.Retained = retained
+		}
+		if err != nil {
+			logging.Errorf(c, "EraseUserData: failed to remove annotations of %s: %v", pseudonym, err)
+			fail(&report.Annotations, err)
+		}
+	}
+	if len(report.Retained) > 0 {
+		report.Notes = append(report.Notes, "Feedback the user gave in sessions that were not erased is kept in those sessions' event logs; see retained")
+	}
+
+	report.AuditRecords = types.ErasureStep{Kind: "audit-log", Status: types.ErasureRemoved}
+	if n, err := audit.PseudonymizeUser(userID, pseudonym); err != nil {
+		logging.Errorf(c, "EraseUserData: failed to pseudonymize audit records of %s: %v", pseudonym, err)
+		fail(&report.AuditRecords, err)
+	} else {
+		report.AuditRecords.Count = n
+	}
+	if ext := audit.ExternalSinks(); len(ext) > 0 {
+		report.Notes = append(report.Notes, "Audit records already delivered to external sinks must be erased there: "+strings.Join(ext, ", "))
+	}
+
+	report.CompletedAt = time.Now().UTC().Format(time.RFC3339)
+	logging.Infof(c, "EraseUserData: erased %s (complete=%v, sessions=%d, reassignedTo=%q)", pseudonym, report.Complete, len(report.Sessions), reassignTo)
+	if !report.Complete {
+		c.JSON(http.StatusInternalServerError, report)
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// eraseUserSessions deletes, or reassigns to reassignTo, every session userID owns
+func eraseUserSessions(ctx context.Context, dyn dynamic.Interface, userID, reassignTo string) ([]types.ErasureSession, error) {
+	gvr := GetAgenticSessionV1Alpha1Resource()
+	// Empty namespace lists across all projects
+	list, err := dyn.Resource(gvr).Namespace("").List(ctx, v1.ListOptions{})
+	if err != nil {
+		return []types.ErasureSession{}, err
+	}
+
+	results := []types.ErasureSession{}
+	for i := range list.Items {
+		item := &list.Items[i]
+		owner, _, _ := unstructured.NestedString(item.Object, "spec", "userContext", "userId")
+		if owner != userID {
+			continue
+		}
+		result := types.ErasureSession{Project: item.GetNamespace(), Name: item.GetName()}
+		if reassignTo != "" {
+			err = reassignSession(ctx, dyn, item.GetNamespace(), item.GetName(), reassignTo)
+			result.Status = types.ErasureReassigned
+		} else {
+			err = dyn.Resource(gvr).Namespace(item.GetNamespace()).Delete(ctx, item.GetName(), v1.DeleteOptions{})
+			if errors.IsNotFound(err) {
+				err = nil
+			}
+			if err == nil && PurgeSessionData != nil {
+				result.TranscriptFiles, err = PurgeSessionData(item.GetNamespace(), item.GetName())
+			}
+			result.Status = types.ErasureRemoved
+		}
+		if err != nil {
+			result.Status = types.ErasureFailed
+			result.Error = err.Error()
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// reassignSession makes newOwner the session's owner, dropping the previous owner's groups
+func reassignSession(ctx context.Context, dyn dynamic.Interface, project, name, newOwner string) error {
+	res := dyn.Resource(GetAgenticSessionV1Alpha1Resource()).Namespace(project)
+	for i := 0; i < 3; i++ { // retry on conflict
+		obj, err := res.Get(ctx, name, v1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		userContext := map[string]interface{}{"userId": newOwner, "displayName": newOwner, "groups": []interface{}{}}
+		if err := unstructured.SetNestedField(obj.Object, userContext, "spec", "userContext"); err != nil {
+			return err
+		}
+		_, err = res.Update(ctx, obj, v1.UpdateOptions{})
+		if errors.IsConflict(err) {
+			continue
+		}
+		return err
+	}
+	return fmt.Errorf("failed to update session after retries")
+}
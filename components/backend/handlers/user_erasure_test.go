@@ -0,0 +1,181 @@
+//go:build test
+
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	"ambient-code-backend/audit"
+	test_constants "ambient-code-backend/tests/constants"
+	"ambient-code-backend/types"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	authv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+var _ = Describe("User Data Erasure", Label(test_constants.LabelUnit, test_constants.LabelHandlers), func() {
+	var (
+		originalK8sClient          kubernetes.Interface
+		originalK8sClientMw        kubernetes.Interface
+		originalDynamicClient      dynamic.Interface
+		originalNamespace          string
+		originalGVR                func() schema.GroupVersionResource
+		originalPurge              func(project, sessionName string) (int, error)
+		originalPurgeContributions func(userID string) (int, []types.ErasureRetained, error)
+		purged                     []string
+		router                     *gin.Engine
+	)
+	gvr := schema.GroupVersionResource{Group: "vteam.ambient-code", Version: "v1alpha1", Resource: "agenticsessions"}
+
+	session := func(namespace, name, owner string) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "vteam.ambient-code/v1alpha1",
+			"kind":       "AgenticSession",
+			"metadata":   map[string]interface{}{"name": name, "namespace": namespace},
+			"spec": map[string]interface{}{
+				"userContext": map[string]interface{}{"userId": owner, "displayName": owner, "groups": []interface{}{"eng"}},
+			},
+		}}
+	}
+
+	BeforeEach(func() {
+		originalK8sClient = K8sClient
+		originalK8sClientMw = K8sClientMw
+		originalDynamicClient = DynamicClient
+		originalNamespace = Namespace
+		originalGVR = GetAgenticSessionV1Alpha1Resource
+		originalPurge = PurgeSessionData
+		originalPurgeContributions = PurgeUserContributions
+		purged = nil
+
+		Namespace = "ambient-code"
+		K8sClient = fake.NewSimpleClientset(&corev1.Secret{
+			ObjectMeta: v1.ObjectMeta{Name: "github-pat-credentials", Namespace: "ambient-code"},
+			Data:       map[string][]byte{"alice": []byte(`{"token":"ghp_x"}`), "bob": []byte(`{"token":"ghp_y"}`)},
+		})
+		mw := fake.NewSimpleClientset()
+		mw.PrependReactor("create", "selfsubjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+			return true, &authv1.SelfSubjectAccessReview{Status: authv1.SubjectAccessReviewStatus{Allowed: true}}, nil
+		})
+		K8sClientMw = mw
+		DynamicClient = dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+			map[schema.GroupVersionResource]string{gvr: "AgenticSessionList"},
+			session("team-a", "alice-1", "alice"),
+			session("team-b", "alice-2", "alice"),
+			session("team-a", "bob-1", "bob"),
+		)
+		GetAgenticSessionV1Alpha1Resource = func() schema.GroupVersionResource { return gvr }
+		PurgeSessionData = func(project, sessionName string) (int, error) {
+			purged = append(purged, project+"/"+sessionName)
+			return 2, nil
+		}
+		PurgeUserContributions = func(userID string) (int, []types.ErasureRetained, error) {
+			return 3, []types.ErasureRetained{{Project: "team-a", Session: "bob-1", Kind: "feedback", Count: 1}}, nil
+		}
+		Expect(audit.Configure("", "", "", "")).To(Succeed())
+
+		Expect(updateAPIKeys(context.Background(), func(keys map[string]*APIKey) error {
+			keys["k1"] = &APIKey{ID: "k1", UserID: "alice"}
+			keys["k2"] = &APIKey{ID: "k2", UserID: "bob"}
+			return nil
+		})).To(Succeed())
+
+		router = gin.New()
+		router.Use(AuditLog())
+		router.DELETE("/users/:userId/data", RequireClusterAdmin(), EraseUserData)
+	})
+
+	AfterEach(func() {
+		K8sClient = originalK8sClient
+		K8sClientMw = originalK8sClientMw
+		DynamicClient = originalDynamicClient
+		Namespace = originalNamespace
+		GetAgenticSessionV1Alpha1Resource = originalGVR
+		PurgeSessionData = originalPurge
+		PurgeUserContributions = originalPurgeContributions
+	})
+
+	erase := func(path string) (int, types.UserErasureReport) {
+		req := httptest.NewRequest(http.MethodDelete, path, nil)
+		req.Header.Set("Authorization", "Bearer admin-token")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		var report types.UserErasureReport
+		_ = json.Unmarshal(w.Body.Bytes(), &report)
+		return w.Code, report
+	}
+
+	It("Should remove credentials, API keys, sessions and transcripts", func() {
+		audit.Emit(audit.Record{RequestID: "r0", User: "alice", Path: "/api/projects/team-a/agentic-sessions"})
+
+		code, report := erase("/users/alice/data")
+		Expect(code).To(Equal(http.StatusOK))
+		Expect(report.Complete).To(BeTrue())
+		Expect(report.UserPseudonym).To(Equal(erasedUserPseudonym("alice")))
+		Expect(report.Credentials).To(HaveLen(len(userCredentialStores)))
+		Expect(report.APIKeys.Count).To(Equal(1))
+		Expect(report.Sessions).To(HaveLen(2))
+		Expect(report.AuditRecords.Count).To(BeNumerically(">=", 1))
+		Expect(purged).To(ConsistOf("team-a/alice-1", "team-b/alice-2"))
+		Expect(report.Annotations.Count).To(Equal(3))
+		Expect(report.Retained).To(ConsistOf(types.ErasureRetained{Project: "team-a", Session: "bob-1", Kind: "feedback", Count: 1}))
+		Expect(report.Notes).To(ContainElement(ContainSubstring("retained")))
+
+		secret, err := K8sClient.CoreV1().Secrets(Namespace).Get(context.Background(), "github-pat-credentials", v1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(secret.Data).NotTo(HaveKey("alice"))
+		Expect(secret.Data).To(HaveKey("bob"))
+
+		remaining, err := DynamicClient.Resource(gvr).Namespace("").List(context.Background(), v1.ListOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(remaining.Items).To(HaveLen(1))
+		Expect(remaining.Items[0].GetName()).To(Equal("bob-1"))
+
+		// Neither earlier records nor the erasure request itself name the user
+		records, err := audit.Query(audit.Filter{})
+		Expect(err).NotTo(HaveOccurred())
+		for _, r := range records {
+			Expect(r.User).NotTo(Equal("alice"))
+			Expect(r.Path).NotTo(ContainSubstring("/alice/"))
+		}
+	})
+
+	It("Should reassign sessions instead of deleting them", func() {
+		code, report := erase("/users/alice/data?reassignTo=carol")
+		Expect(code).To(Equal(http.StatusOK))
+		Expect(report.ReassignedTo).To(Equal("carol"))
+		Expect(purged).To(BeEmpty())
+		for _, s := range report.Sessions {
+			Expect(s.Status).To(Equal(types.ErasureReassigned))
+		}
+
+		obj, err := DynamicClient.Resource(gvr).Namespace("team-b").Get(context.Background(), "alice-2", v1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		owner, _, _ := unstructured.NestedString(obj.Object, "spec", "userContext", "userId")
+		Expect(owner).To(Equal("carol"))
+		groups, _, _ := unstructured.NestedSlice(obj.Object, "spec", "userContext", "groups")
+		Expect(groups).To(BeEmpty())
+	})
+
+	It("Should reject invalid user identifiers", func() {
+		code, _ := erase("/users/alice/data?reassignTo=alice")
+		Expect(code).To(Equal(http.StatusBadRequest))
+		code, _ = erase("/users/al%20ice/data")
+		Expect(code).To(Equal(http.StatusBadRequest))
+	})
+})
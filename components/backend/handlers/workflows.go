@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"ambient-code-backend/types"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// Labels marking the sessions that run workflow stages
+const (
+	WorkflowIDLabel    = "ambient-code.io/workflow-id"
+	WorkflowStageLabel = "ambient-code.io/workflow-stage"
+)
+
+// WorkflowStageSession describes the session running one attempt of a workflow stage
+type WorkflowStageSession struct {
+	Name        string
+	DisplayName string
+	Prompt      string
+	Model       string
+	Repos       []types.SimpleRepo
+	// Owner is the user who started the workflow; the session runs with their
+	// integrations as if they had created it
+	Owner  types.UserContext
+	Labels map[string]string
+}
+
+// CreateWorkflowStageSession creates a non-interactive session that runs the stage
+// prompt on start, with the project's tool and egress policies, queued like any
+// other session when the project is out of capacity. Uses the backend
+// service account: later stages start when an earlier one is approved, outside the
+// request that started the workflow, whose caller was checked for session create
+// permission.
+func CreateWorkflowStageSession(ctx context.Context, project string, s WorkflowStageSession) error {
+	if DynamicClient == nil {
+		return fmt.Errorf("backend client not initialized")
+	}
+	defaults, err := getSessionDefaults(ctx, DynamicClient, project)
+	if err != nil {
+		return fmt.Errorf("failed to load session defaults: %w", err)
+	}
+	model := s.Model
+	if model == "" {
+		model = defaults.LLMSettings.Model
+	}
+	spec := map[string]interface{}{
+		"displayName":   s.DisplayName,
+		"project":       project,
+		"initialPrompt": s.Prompt,
+		"interactive":   false,
+		"llmSettings": map[string]interface{}{
+			"model":       model,
+			"temperature": defaults.LLMSettings.Temperature,
+			"maxTokens":   int64(defaults.LLMSettings.MaxTokens),
+		},
+		"timeout": int64(defaults.Timeout),
+		"userContext": map[string]interface{}{
+			"userId":      s.Owner.UserID,
+			"displayName": s.Owner.DisplayName,
+			"groups":      stringsToInterfaces(s.Owner.Groups),
+		},
+	}
+	if len(s.Repos) > 0 {
+		repos := make([]interface{}, 0, len(s.Repos))
+		for _, r := range s.Repos {
+			m := map[string]interface{}{"url": r.URL, "branch": ComputeAutoBranch(s.Name)}
+			if r.Branch != nil && strings.TrimSpace(*r.Branch) != "" {
+				m["branch"] = *r.Branch
+			}
+			if r.AutoPush != nil {
+				m["autoPush"] = *r.AutoPush
+			}
+			setRepoCloneOptions(m, r)
+			repos = append(repos, m)
+		}
+		spec["repos"] = repos
+	}
+
+	toolPolicy, err := mcpToolPolicyEnv(ctx, DynamicClient, project)
+	if err != nil {
+		return fmt.Errorf("failed to load MCP tool policy: %w", err)
+	}
+	egressPolicy, err := egressPolicyEnv(ctx, DynamicClient, project)
+	if err != nil {
+		return fmt.Errorf("failed to load egress policy: %w", err)
+	}
+	envVars := map[string]interface{}{}
+	if toolPolicy != "" {
+		envVars[types.MCPToolPolicyEnvVar] = toolPolicy
+	}
+	if egressPolicy != "" {
+		envVars[types.EgressPolicyEnvVar] = egressPolicy
+	}
+	if len(envVars) > 0 {
+		spec["environmentVariables"] = envVars
+	}
+
+	labels := map[string]interface{}{}
+	for k, v := range s.Labels {
+		labels[k] = v
+	}
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "vteam.ambient-code/v1alpha1",
+		"kind":       "AgenticSession",
+		"metadata": map[string]interface{}{
+			"name":      s.Name,
+			"namespace": project,
+			"labels":    labels,
+		},
+		"spec":   spec,
+		"status": map[string]interface{}{"phase": "Pending"},
+	}}
+	_, _, err = createAdmittedSession(ctx, DynamicClient, obj)
+	return err
+}
+
+func stringsToInterfaces(values []string) []interface{} {
+	out := make([]interface{}, len(values))
+	for i, v := range values {
+		out[i] = v
+	}
+	return out
+}
@@ -0,0 +1,218 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"ambient-code-backend/logging"
+	"ambient-code-backend/types"
+
+	"github.com/gin-gonic/gin"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+)
+
+// workspacePVCName is the PVC Kubernetes creates for a sized session workspace
+// (generic ephemeral volume "workspace" of pod "<session>-runner").
+// IMPORTANT: Keep in sync with operator (internal/handlers/workspace_volume.go)
+func workspacePVCName(sessionName string) string {
+	return sessionName + "-runner-workspace"
+}
+
+// workspaceSettings is the ProjectSettings spec.workspace block
+type workspaceSettings struct {
+	MaxSize      *resource.Quantity
+	StorageClass string
+}
+
+func getWorkspaceSettings(ctx context.Context, dynClient dynamic.Interface, project string) (workspaceSettings, error) {
+	settings := workspaceSettings{}
+	obj, err := dynClient.Resource(GetProjectSettingsResource()).Namespace(project).Get(ctx, "projectsettings", v1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return settings, nil
+		}
+		return settings, err
+	}
+	settings.StorageClass, _, _ = unstructured.NestedString(obj.Object, "spec", "workspace", "storageClass")
+	if maxSize, _, _ := unstructured.NestedString(obj.Object, "spec", "workspace", "maxSize"); maxSize != "" {
+		q, err := resource.ParseQuantity(maxSize)
+		if err != nil {
+			return settings, fmt.Errorf("invalid workspace.maxSize %q: %w", maxSize, err)
+		}
+		settings.MaxSize = &q
+	}
+	return settings, nil
+}
+
+// checkWorkspaceSize parses a requested workspace size and checks it against the project maximum
+func checkWorkspaceSize(size string, settings workspaceSettings) (resource.Quantity, error) {
+	q, err := resource.ParseQuantity(strings.TrimSpace(size))
+	if err != nil || q.Sign() <= 0 {
+		return resource.Quantity{}, fmt.Errorf("invalid workspace size %q", size)
+	}
+	if settings.MaxSize == nil {
+		return resource.Quantity{}, fmt.Errorf("workspace sizing is not enabled for this project")
+	}
+	if q.Cmp(*settings.MaxSize) > 0 {
+		return resource.Quantity{}, fmt.Errorf("workspace size %s exceeds the project maximum of %s", q.String(), settings.MaxSize.String())
+	}
+	return q, nil
+}
+
+// validateWorkspaceSize checks a requested workspace size against the project's
+// ProjectSettings workspace.maxSize. A missing maxSize rejects all sizes.
+func validateWorkspaceSize(ctx context.Context, dynClient dynamic.Interface, project, size string) (resource.Quantity, error) {
+	settings, err := getWorkspaceSettings(ctx, dynClient, project)
+	if err != nil {
+		logging.Errorf(ctx, "Failed to read workspace settings for project %s: %v", project, err)
+		return resource.Quantity{}, fmt.Errorf("unable to verify workspace size against project settings")
+	}
+	return checkWorkspaceSize(size, settings)
+}
+
+// ExpandSessionWorkspace grows a session's PVC-backed workspace, e.g. when the agent
+// runs out of disk mid-task. The new size is stored on the session spec and the
+// operator resizes the running pod's PVC, so the storage class must allow expansion.
+// POST /api/projects/:projectName/agentic-sessions/:sessionName/workspace/expand
+func ExpandSessionWorkspace(c *gin.Context) {
+	project := c.GetString("project")
+	if project == "" {
+		project = c.Param("projectName")
+	}
+	sessionName := c.Param("sessionName")
+
+	_, reqDyn := GetK8sClientsForRequest(c)
+	if reqDyn == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing token"})
+		c.Abort()
+		return
+	}
+
+	var req types.ExpandWorkspaceRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	}
+
+	ctx := c.Request.Context()
+	gvr := GetAgenticSessionV1Alpha1Resource()
+	session, err := reqDyn.Resource(gvr).Namespace(project).Get(ctx, sessionName, v1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+			return
+		}
+		logging.Errorf(c, "ExpandSessionWorkspace: failed to get session %s/%s: %v", project, sessionName, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get session"})
+		return
+	}
+
+	currentSize, _, _ := unstructured.NestedString(session.Object, "spec", "workspace", "size")
+	if currentSize == "" {
+		c.JSON(http.StatusConflict, gin.H{"error": "Session workspace is not resizable; create the session with workspace.size to use a PVC-backed workspace"})
+		return
+	}
+	current, err := resource.ParseQuantity(currentSize)
+	if err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("Session has an invalid workspace size %q", currentSize)})
+		return
+	}
+
+	settings, err := getWorkspaceSettings(ctx, reqDyn, project)
+	if err != nil {
+		logging.Errorf(c, "ExpandSessionWorkspace: failed to read workspace settings for %s: %v", project, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read project workspace settings"})
+		return
+	}
+	if settings.MaxSize == nil {
+		c.JSON(http.StatusConflict, gin.H{"error": "workspace sizing is not enabled for this project"})
+		return
+	}
+
+	requested := strings.TrimSpace(req.Size)
+	if requested == "" {
+		// Default: double the workspace, up to the project maximum
+		doubled := current.DeepCopy()
+		doubled.Add(current)
+		if doubled.Cmp(*settings.MaxSize) > 0 {
+			doubled = settings.MaxSize.DeepCopy()
+		}
+		requested = doubled.String()
+	}
+	size, err := checkWorkspaceSize(requested, settings)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if size.Cmp(current) <= 0 {
+		if current.Cmp(*settings.MaxSize) >= 0 {
+			c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("Workspace is already at the project maximum of %s", settings.MaxSize.String())})
+			return
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Workspace can only grow; current size is %s", current.String())})
+		return
+	}
+
+	storageClass, err := checkWorkspaceExpandable(ctx, project, sessionName)
+	if err != nil {
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+		return
+	}
+
+	patch, _ := json.Marshal(map[string]interface{}{
+		"spec": map[string]interface{}{"workspace": map[string]interface{}{"size": size.String()}},
+	})
+	if _, err := reqDyn.Resource(gvr).Namespace(project).Patch(ctx, sessionName, k8stypes.MergePatchType, patch, v1.PatchOptions{}); err != nil {
+		logging.Errorf(c, "ExpandSessionWorkspace: failed to update session %s/%s: %v", project, sessionName, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update workspace size"})
+		return
+	}
+
+	logging.Infof(c, "ExpandSessionWorkspace: %s/%s workspace %s -> %s", project, sessionName, current.String(), size.String())
+	c.JSON(http.StatusAccepted, types.ExpandWorkspaceResponse{
+		PreviousSize: current.String(),
+		Size:         size.String(),
+		MaxSize:      settings.MaxSize.String(),
+		StorageClass: storageClass,
+	})
+}
+
+// checkWorkspaceExpandable returns the storage class of the session's workspace PVC and
+// an error if that class doesn't allow volume expansion. A PVC that doesn't exist yet
+// (session not running, or running on a remote cluster) is created at the new size, so
+// there is nothing to check.
+func checkWorkspaceExpandable(ctx context.Context, project, sessionName string) (string, error) {
+	if K8sClient == nil {
+		return "", nil
+	}
+	pvc, err := K8sClient.CoreV1().PersistentVolumeClaims(project).Get(ctx, workspacePVCName(sessionName), v1.GetOptions{})
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			logging.Warnf(ctx, "Failed to get workspace PVC of %s/%s: %v", project, sessionName, err)
+		}
+		return "", nil
+	}
+	if pvc.Spec.StorageClassName == nil || *pvc.Spec.StorageClassName == "" {
+		return "", fmt.Errorf("workspace PVC has no storage class and cannot be expanded")
+	}
+	className := *pvc.Spec.StorageClassName
+	class, err := K8sClient.StorageV1().StorageClasses().Get(ctx, className, v1.GetOptions{})
+	if err != nil {
+		logging.Warnf(ctx, "Failed to get storage class %s: %v", className, err)
+		return className, nil
+	}
+	if class.AllowVolumeExpansion == nil || !*class.AllowVolumeExpansion {
+		return className, fmt.Errorf("storage class %s does not support volume expansion", className)
+	}
+	return className, nil
+}
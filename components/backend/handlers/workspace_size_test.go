@@ -0,0 +1,161 @@
+//go:build test
+
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	test_constants "ambient-code-backend/tests/constants"
+	"ambient-code-backend/types"
+
+	"github.com/gin-gonic/gin"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+var _ = Describe("Workspace Size", Label(test_constants.LabelUnit, test_constants.LabelHandlers), func() {
+	var (
+		originalK8sClient     kubernetes.Interface
+		originalK8sClientMw   kubernetes.Interface
+		originalDynamicClient dynamic.Interface
+		originalGVR           func() schema.GroupVersionResource
+		router                *gin.Engine
+	)
+	gvr := schema.GroupVersionResource{Group: "vteam.ambient-code", Version: "v1alpha1", Resource: "agenticsessions"}
+
+	session := func(name, size string) *unstructured.Unstructured {
+		spec := map[string]interface{}{"displayName": name}
+		if size != "" {
+			spec["workspace"] = map[string]interface{}{"size": size}
+		}
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "vteam.ambient-code/v1alpha1",
+			"kind":       "AgenticSession",
+			"metadata":   map[string]interface{}{"name": name, "namespace": "team-a"},
+			"spec":       spec,
+		}}
+	}
+	pvc := func(sessionName, storageClass string) *corev1.PersistentVolumeClaim {
+		return &corev1.PersistentVolumeClaim{
+			ObjectMeta: v1.ObjectMeta{Name: workspacePVCName(sessionName), Namespace: "team-a"},
+			Spec:       corev1.PersistentVolumeClaimSpec{StorageClassName: &storageClass},
+		}
+	}
+	boolPtr := func(b bool) *bool { return &b }
+
+	BeforeEach(func() {
+		originalK8sClient = K8sClient
+		originalK8sClientMw = K8sClientMw
+		originalDynamicClient = DynamicClient
+		originalGVR = GetAgenticSessionV1Alpha1Resource
+
+		K8sClient = fake.NewSimpleClientset(
+			pvc("s-fixed", "fixed"),
+			pvc("s-max", "expandable"),
+			&storagev1.StorageClass{ObjectMeta: v1.ObjectMeta{Name: "expandable"}, AllowVolumeExpansion: boolPtr(true)},
+			&storagev1.StorageClass{ObjectMeta: v1.ObjectMeta{Name: "fixed"}},
+		)
+		K8sClientMw = fake.NewSimpleClientset()
+		DynamicClient = dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(),
+			session("s-default", ""),
+			session("s-sized", "10Gi"),
+			session("s-fixed", "10Gi"),
+			session("s-max", "50Gi"),
+		)
+		_, err := DynamicClient.Resource(GetProjectSettingsResource()).Namespace("team-a").Create(context.Background(), &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "vteam.ambient-code/v1alpha1",
+			"kind":       "ProjectSettings",
+			"metadata":   map[string]interface{}{"name": "projectsettings", "namespace": "team-a"},
+			"spec": map[string]interface{}{
+				"workspace": map[string]interface{}{"maxSize": "50Gi", "storageClass": "expandable"},
+			},
+		}}, v1.CreateOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		GetAgenticSessionV1Alpha1Resource = func() schema.GroupVersionResource { return gvr }
+
+		router = gin.New()
+		router.POST("/api/projects/:projectName/agentic-sessions/:sessionName/workspace/expand", ExpandSessionWorkspace)
+	})
+
+	AfterEach(func() {
+		K8sClient = originalK8sClient
+		K8sClientMw = originalK8sClientMw
+		DynamicClient = originalDynamicClient
+		GetAgenticSessionV1Alpha1Resource = originalGVR
+	})
+
+	expand := func(sessionName, body string) (int, types.ExpandWorkspaceResponse) {
+		req := httptest.NewRequest(http.MethodPost, "/api/projects/team-a/agentic-sessions/"+sessionName+"/workspace/expand", strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer test-token")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		var resp types.ExpandWorkspaceResponse
+		_ = json.Unmarshal(w.Body.Bytes(), &resp)
+		return w.Code, resp
+	}
+	specSize := func(sessionName string) string {
+		obj, err := DynamicClient.Resource(gvr).Namespace("team-a").Get(context.Background(), sessionName, v1.GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		size, _, _ := unstructured.NestedString(obj.Object, "spec", "workspace", "size")
+		return size
+	}
+
+	It("Should validate requested sizes against the project maximum", func() {
+		size, err := validateWorkspaceSize(context.Background(), DynamicClient, "team-a", "20Gi")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(size.String()).To(Equal("20Gi"))
+
+		_, err = validateWorkspaceSize(context.Background(), DynamicClient, "team-a", "60Gi")
+		Expect(err).To(MatchError(ContainSubstring("exceeds the project maximum")))
+		_, err = validateWorkspaceSize(context.Background(), DynamicClient, "team-a", "plenty")
+		Expect(err).To(HaveOccurred())
+		_, err = validateWorkspaceSize(context.Background(), DynamicClient, "team-b", "20Gi")
+		Expect(err).To(MatchError(ContainSubstring("not enabled")))
+	})
+
+	It("Should store the requested size on the session", func() {
+		code, resp := expand("s-sized", `{"size":"30Gi"}`)
+		Expect(code).To(Equal(http.StatusAccepted))
+		Expect(resp.PreviousSize).To(Equal("10Gi"))
+		Expect(resp.Size).To(Equal("30Gi"))
+		Expect(specSize("s-sized")).To(Equal("30Gi"))
+	})
+
+	It("Should double the workspace when no size is given", func() {
+		code, resp := expand("s-sized", "")
+		Expect(code).To(Equal(http.StatusAccepted))
+		Expect(resp.Size).To(Equal("20Gi"))
+	})
+
+	It("Should refuse to grow beyond the maximum, shrink, or resize emptyDir workspaces", func() {
+		code, _ := expand("s-sized", `{"size":"60Gi"}`)
+		Expect(code).To(Equal(http.StatusBadRequest))
+		code, _ = expand("s-sized", `{"size":"5Gi"}`)
+		Expect(code).To(Equal(http.StatusBadRequest))
+		code, _ = expand("s-max", "")
+		Expect(code).To(Equal(http.StatusConflict))
+		code, _ = expand("s-default", "")
+		Expect(code).To(Equal(http.StatusConflict))
+		Expect(specSize("s-sized")).To(Equal("10Gi"))
+	})
+
+	It("Should reject expansion when the storage class can't expand volumes", func() {
+		code, _ := expand("s-fixed", `{"size":"20Gi"}`)
+		Expect(code).To(Equal(http.StatusConflict))
+		Expect(specSize("s-fixed")).To(Equal("10Gi"))
+	})
+})
@@ -0,0 +1,256 @@
+// Package jira is a minimal client for the Jira REST API (v2, served by Jira Cloud,
+// Server and Data Center), covering what sessions need: reading an issue with its
+// comments and linked pull requests to give a session as context.
+package jira
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"ambient-code-backend/metrics"
+)
+
+// ErrUnauthorized is returned when Jira rejects the credentials
+var ErrUnauthorized = errors.New("jira rejected the credentials")
+
+// ErrNotFound is returned for issues that don't exist or the user can't see
+var ErrNotFound = errors.New("jira issue not found")
+
+// keyPattern matches issue keys, e.g. "PROJ-123"
+var keyPattern = regexp.MustCompile(`^[A-Z][A-Z0-9_]+-[1-9][0-9]*$`)
+
+// ValidKey reports whether key looks like an issue key
+func ValidKey(key string) bool {
+	return keyPattern.MatchString(key)
+}
+
+// Client is a Jira API client authenticated with an account email and API token
+type Client struct {
+	httpClient *http.Client
+	baseURL    string
+	email      string
+	apiToken   string
+}
+
+// NewClient creates a Jira API client for the site at baseURL with a 15-second timeout
+func NewClient(baseURL, email, apiToken string) *Client {
+	return &Client{
+		httpClient: &http.Client{
+			Timeout:   15 * time.Second,
+			Transport: metrics.Transport("jira", "api", nil),
+		},
+		baseURL:  strings.TrimRight(baseURL, "/"),
+		email:    email,
+		apiToken: apiToken,
+	}
+}
+
+// Issue is a Jira issue as sessions see it
+type Issue struct {
+	Key         string    `json:"key"`
+	URL         string    `json:"url"`
+	Summary     string    `json:"summary"`
+	Description string    `json:"description,omitempty"`
+	Type        string    `json:"type,omitempty"`
+	Status      string    `json:"status,omitempty"`
+	Priority    string    `json:"priority,omitempty"`
+	Assignee    string    `json:"assignee,omitempty"`
+	Reporter    string    `json:"reporter,omitempty"`
+	Labels      []string  `json:"labels,omitempty"`
+	Comments    []Comment `json:"comments,omitempty"`
+	// PullRequests are linked pull/merge request URLs, from remote links and URLs
+	// mentioned in the description or comments
+	PullRequests []string `json:"pullRequests,omitempty"`
+}
+
+// Comment is a comment on an issue
+type Comment struct {
+	Author  string `json:"author"`
+	Created string `json:"created"`
+	Body    string `json:"body"`
+}
+
+type user struct {
+	DisplayName string `json:"displayName"`
+}
+
+type named struct {
+	Name string `json:"name"`
+}
+
+// get fetches path from the API and decodes the JSON response into out
+func (c *Client) get(ctx context.Context, path string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+path, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.SetBasicAuth(c.email, c.apiToken)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		// Don't wrap error - could leak the token from request details
+		return fmt.Errorf("request failed")
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 4<<20))
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusUnauthorized:
+		return ErrUnauthorized
+	case http.StatusNotFound, http.StatusForbidden:
+		return ErrNotFound
+	default:
+		return fmt.Errorf("jira returned %d", resp.StatusCode)
+	}
+	return json.Unmarshal(body, out)
+}
+
+// GetIssue reads an issue with its comments and linked pull requests
+func (c *Client) GetIssue(ctx context.Context, key string) (*Issue, error) {
+	if !ValidKey(key) {
+		return nil, fmt.Errorf("invalid issue key %q", key)
+	}
+	var raw struct {
+		Key    string `json:"key"`
+		Fields struct {
+			Summary     string   `json:"summary"`
+			Description string   `json:"description"`
+			IssueType   *named   `json:"issuetype"`
+			Status      *named   `json:"status"`
+			Priority    *named   `json:"priority"`
+			Assignee    *user    `json:"assignee"`
+			Reporter    *user    `json:"reporter"`
+			Labels      []string `json:"labels"`
+			Comment     struct {
+				Comments []struct {
+					Author  *user  `json:"author"`
+					Created string `json:"created"`
+					Body    string `json:"body"`
+				} `json:"comments"`
+			} `json:"comment"`
+		} `json:"fields"`
+	}
+	fields := "summary,description,issuetype,status,priority,assignee,reporter,labels,comment"
+	if err := c.get(ctx, "/rest/api/2/issue/"+url.PathEscape(key)+"?fields="+fields, &raw); err != nil {
+		return nil, err
+	}
+
+	f := raw.Fields
+	issue := &Issue{
+		Key:         raw.Key,
+		URL:         c.baseURL + "/browse/" + raw.Key,
+		Summary:     f.Summary,
+		Description: f.Description,
+		Labels:      f.Labels,
+	}
+	if f.IssueType != nil {
+		issue.Type = f.IssueType.Name
+	}
+	if f.Status != nil {
+		issue.Status = f.Status.Name
+	}
+	if f.Priority != nil {
+		issue.Priority = f.Priority.Name
+	}
+	if f.Assignee != nil {
+		issue.Assignee = f.Assignee.DisplayName
+	}
+	if f.Reporter != nil {
+		issue.Reporter = f.Reporter.DisplayName
+	}
+	texts := []string{f.Description}
+	for _, cm := range f.Comment.Comments {
+		comment := Comment{Created: cm.Created, Body: cm.Body}
+		if cm.Author != nil {
+			comment.Author = cm.Author.DisplayName
+		}
+		issue.Comments = append(issue.Comments, comment)
+		texts = append(texts, cm.Body)
+	}
+
+	// Remote links hold the PRs linked by the GitHub/GitLab integrations; they're
+	// best-effort since some sites restrict them
+	var links []struct {
+		Object struct {
+			URL string `json:"url"`
+		} `json:"object"`
+	}
+	if err := c.get(ctx, "/rest/api/2/issue/"+url.PathEscape(key)+"/remotelink", &links); err == nil {
+		for _, l := range links {
+			texts = append(texts, l.Object.URL)
+		}
+	}
+	issue.PullRequests = pullRequestURLs(texts...)
+	return issue, nil
+}
+
+// pullRequestPattern matches GitHub pull request and GitLab merge request URLs
+var pullRequestPattern = regexp.MustCompile(`https?://[^\s|\]\[)(<>"']+/(?:pull/\d+|-/merge_requests/\d+)`)
+
+// pullRequestURLs returns the distinct pull request URLs mentioned in texts, sorted
+func pullRequestURLs(texts ...string) []string {
+	seen := map[string]bool{}
+	var urls []string
+	for _, text := range texts {
+		for _, u := range pullRequestPattern.FindAllString(text, -1) {
+			if !seen[u] {
+				seen[u] = true
+				urls = append(urls, u)
+			}
+		}
+	}
+	sort.Strings(urls)
+	return urls
+}
+
+// maxContextComments is how many of the latest comments Context includes
+const maxContextComments = 20
+
+// Context formats the issue as a session's initial context: a <jira-issue> block
+// with its fields, description, latest comments and linked pull requests
+func (i *Issue) Context() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "<jira-issue key=%q url=%q>\n", i.Key, i.URL)
+	fmt.Fprintf(&b, "# %s: %s\n\n", i.Key, i.Summary)
+	for _, field := range []struct{ name, value string }{
+		{"Type", i.Type}, {"Status", i.Status}, {"Priority", i.Priority},
+		{"Assignee", i.Assignee}, {"Reporter", i.Reporter}, {"Labels", strings.Join(i.Labels, ", ")},
+	} {
+		if field.value != "" {
+			fmt.Fprintf(&b, "- %s: %s\n", field.name, field.value)
+		}
+	}
+	if d := strings.TrimSpace(i.Description); d != "" {
+		fmt.Fprintf(&b, "\n## Description\n\n%s\n", d)
+	}
+	if len(i.Comments) > 0 {
+		comments := i.Comments
+		b.WriteString("\n## Comments\n")
+		if len(comments) > maxContextComments {
+			fmt.Fprintf(&b, "\n(%d earlier comments omitted)\n", len(comments)-maxContextComments)
+			comments = comments[len(comments)-maxContextComments:]
+		}
+		for _, cm := range comments {
+			fmt.Fprintf(&b, "\n**%s** (%s):\n%s\n", cm.Author, cm.Created, strings.TrimSpace(cm.Body))
+		}
+	}
+	if len(i.PullRequests) > 0 {
+		b.WriteString("\n## Linked pull requests\n\n")
+		for _, u := range i.PullRequests {
+			fmt.Fprintf(&b, "- %s\n", u)
+		}
+	}
+	b.WriteString("</jira-issue>")
+	return b.String()
+}
@@ -0,0 +1,70 @@
+package jira
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func fakeJira(t *testing.T) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, token, _ := r.BasicAuth(); user != "dev@example.com" || token != "good" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/rest/api/2/issue/PROJ-7":
+			fmt.Fprint(w, `{"key":"PROJ-7","fields":{
+				"summary":"Login fails on Safari",
+				"description":"Steps in https://github.com/acme/web/pull/12",
+				"issuetype":{"name":"Bug"},"status":{"name":"To Do"},"priority":null,
+				"assignee":{"displayName":"Dana"},"labels":["frontend"],
+				"comment":{"comments":[{"author":{"displayName":"Sam"},"created":"2026-01-02T10:00:00.000+0000","body":"Also on iOS"}]}}}`)
+		case "/rest/api/2/issue/PROJ-7/remotelink":
+			fmt.Fprint(w, `[{"object":{"url":"https://gitlab.example.com/acme/api/-/merge_requests/3"}},{"object":{"url":"https://github.com/acme/web/pull/12"}},{"object":{"url":"https://wiki.example.com/x"}}]`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestGetIssue(t *testing.T) {
+	srv := fakeJira(t)
+	issue, err := NewClient(srv.URL+"/", "dev@example.com", "good").GetIssue(context.Background(), "PROJ-7")
+	if err != nil {
+		t.Fatalf("GetIssue: %v", err)
+	}
+	if issue.Summary != "Login fails on Safari" || issue.Type != "Bug" || issue.Assignee != "Dana" || issue.Priority != "" || issue.URL != srv.URL+"/browse/PROJ-7" {
+		t.Errorf("issue = %+v", issue)
+	}
+	if len(issue.Comments) != 1 || issue.Comments[0].Author != "Sam" {
+		t.Errorf("comments = %+v", issue.Comments)
+	}
+	want := []string{"https://github.com/acme/web/pull/12", "https://gitlab.example.com/acme/api/-/merge_requests/3"}
+	if strings.Join(issue.PullRequests, " ") != strings.Join(want, " ") {
+		t.Errorf("pull requests = %v, want %v", issue.PullRequests, want)
+	}
+
+	sessionContext := issue.Context()
+	for _, part := range []string{`<jira-issue key="PROJ-7"`, "# PROJ-7: Login fails on Safari", "- Status: To Do", "## Description", "**Sam**", "## Linked pull requests", "</jira-issue>"} {
+		if !strings.Contains(sessionContext, part) {
+			t.Errorf("context is missing %q:\n%s", part, sessionContext)
+		}
+	}
+
+	if _, err := NewClient(srv.URL, "dev@example.com", "good").GetIssue(context.Background(), "PROJ-8"); err != ErrNotFound {
+		t.Errorf("missing issue: err = %v", err)
+	}
+	if _, err := NewClient(srv.URL, "dev@example.com", "bad").GetIssue(context.Background(), "PROJ-7"); err != ErrUnauthorized {
+		t.Errorf("bad token: err = %v", err)
+	}
+	if _, err := NewClient(srv.URL, "dev@example.com", "good").GetIssue(context.Background(), "../admin"); err == nil {
+		t.Error("invalid keys must be rejected")
+	}
+}
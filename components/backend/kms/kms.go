@@ -0,0 +1,234 @@
+// Package kms wraps the data keys that encrypt data at rest (e.g. persisted AG-UI
+// transcripts). The backend only stores wrapped data keys; the key-encryption key stays
+// with the provider: a master key given to the backend, or a Vault Transit key.
+package kms
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DataKeySize is the size of generated data keys (AES-256)
+const DataKeySize = 32
+
+// Provider wraps and unwraps data keys with a key-encryption key it holds
+type Provider interface {
+	// Name identifies the provider in logs and API responses
+	Name() string
+	Wrap(ctx context.Context, dataKey []byte) ([]byte, error)
+	Unwrap(ctx context.Context, wrapped []byte) ([]byte, error)
+}
+
+var (
+	mu     sync.RWMutex
+	active Provider
+)
+
+// Configure replaces the active provider; nil disables encryption at rest
+func Configure(p Provider) {
+	mu.Lock()
+	active = p
+	mu.Unlock()
+}
+
+// Current returns the active provider, or nil when none is configured
+func Current() Provider {
+	mu.RLock()
+	defer mu.RUnlock()
+	return active
+}
+
+// ConfigureFromEnv selects the provider named by KMS_PROVIDER:
+//   - "" disables encryption at rest
+//   - "local" wraps keys with KMS_LOCAL_MASTER_KEY (base64, 32 bytes)
+//   - "vault" uses the Vault Transit key KMS_VAULT_KEY at KMS_VAULT_ADDR,
+//     authenticating with KMS_VAULT_TOKEN (mount KMS_VAULT_MOUNT, default "transit")
+func ConfigureFromEnv() error {
+	switch kind := strings.ToLower(strings.TrimSpace(os.Getenv("KMS_PROVIDER"))); kind {
+	case "":
+		Configure(nil)
+	case "local":
+		key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(os.Getenv("KMS_LOCAL_MASTER_KEY")))
+		if err != nil {
+			return fmt.Errorf("KMS_LOCAL_MASTER_KEY is not valid base64: %w", err)
+		}
+		p, err := NewLocalProvider(key)
+		if err != nil {
+			return err
+		}
+		Configure(p)
+	case "vault":
+		p, err := NewVaultTransitProvider(os.Getenv("KMS_VAULT_ADDR"), os.Getenv("KMS_VAULT_MOUNT"), os.Getenv("KMS_VAULT_KEY"), os.Getenv("KMS_VAULT_TOKEN"))
+		if err != nil {
+			return err
+		}
+		Configure(p)
+	default:
+		return fmt.Errorf("unknown KMS_PROVIDER %q (want local or vault)", kind)
+	}
+	return nil
+}
+
+// NewDataKey returns a random AES-256 data key
+func NewDataKey() ([]byte, error) {
+	key := make([]byte, DataKeySize)
+	if _, err := io.ReadFull(rand.Reader, key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// Seal encrypts plaintext with AES-GCM under key, binding it to aad. The result is the
+// random nonce followed by the ciphertext.
+func Seal(key, plaintext, aad []byte) ([]byte, error) {
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(plaintext)+aead.Overhead())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plaintext, aad), nil
+}
+
+// Open decrypts the output of Seal. It fails if the data or aad was altered.
+func Open(key, sealed, aad []byte) ([]byte, error) {
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < aead.NonceSize()+aead.Overhead() {
+		return nil, errors.New("kms: ciphertext too short")
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	return aead.Open(nil, nonce, ciphertext, aad)
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	if len(key) != DataKeySize {
+		return nil, fmt.Errorf("kms: key must be %d bytes, got %d", DataKeySize, len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// LocalProvider wraps data keys with AES-256-GCM under a master key held by the backend
+type LocalProvider struct {
+	masterKey []byte
+}
+
+// NewLocalProvider returns a provider wrapping keys under masterKey (32 bytes)
+func NewLocalProvider(masterKey []byte) (*LocalProvider, error) {
+	if len(masterKey) != DataKeySize {
+		return nil, fmt.Errorf("local KMS master key must be %d bytes, got %d", DataKeySize, len(masterKey))
+	}
+	return &LocalProvider{masterKey: masterKey}, nil
+}
+
+func (p *LocalProvider) Name() string { return "local" }
+
+func (p *LocalProvider) Wrap(_ context.Context, dataKey []byte) ([]byte, error) {
+	return Seal(p.masterKey, dataKey, []byte("kms-data-key"))
+}
+
+func (p *LocalProvider) Unwrap(_ context.Context, wrapped []byte) ([]byte, error) {
+	return Open(p.masterKey, wrapped, []byte("kms-data-key"))
+}
+
+// VaultTransitProvider wraps data keys with a HashiCorp Vault Transit key
+type VaultTransitProvider struct {
+	addr   string
+	mount  string
+	key    string
+	token  string
+	client *http.Client
+}
+
+// NewVaultTransitProvider returns a provider using the Transit key name at addr
+func NewVaultTransitProvider(addr, mount, key, token string) (*VaultTransitProvider, error) {
+	addr = strings.TrimRight(strings.TrimSpace(addr), "/")
+	if !strings.HasPrefix(addr, "http://") && !strings.HasPrefix(addr, "https://") {
+		return nil, fmt.Errorf("KMS_VAULT_ADDR must be an http(s) URL")
+	}
+	if key == "" || token == "" {
+		return nil, fmt.Errorf("KMS_VAULT_KEY and KMS_VAULT_TOKEN are required for the vault KMS provider")
+	}
+	if mount == "" {
+		mount = "transit"
+	}
+	return &VaultTransitProvider{
+		addr:   addr,
+		mount:  strings.Trim(mount, "/"),
+		key:    key,
+		token:  token,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+func (p *VaultTransitProvider) Name() string { return "vault" }
+
+func (p *VaultTransitProvider) Wrap(ctx context.Context, dataKey []byte) ([]byte, error) {
+	var out struct {
+		Data struct {
+			Ciphertext string `json:"ciphertext"`
+		} `json:"data"`
+	}
+	if err := p.call(ctx, "encrypt", map[string]string{"plaintext": base64.StdEncoding.EncodeToString(dataKey)}, &out); err != nil {
+		return nil, err
+	}
+	// Transit ciphertexts are "vault:v<n>:..." strings, stored as-is
+	return []byte(out.Data.Ciphertext), nil
+}
+
+func (p *VaultTransitProvider) Unwrap(ctx context.Context, wrapped []byte) ([]byte, error) {
+	var out struct {
+		Data struct {
+			Plaintext string `json:"plaintext"`
+		} `json:"data"`
+	}
+	if err := p.call(ctx, "decrypt", map[string]string{"ciphertext": string(wrapped)}, &out); err != nil {
+		return nil, err
+	}
+	return base64.StdEncoding.DecodeString(out.Data.Plaintext)
+}
+
+func (p *VaultTransitProvider) call(ctx context.Context, op string, body interface{}, out interface{}) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	url := fmt.Sprintf("%s/v1/%s/%s/%s", p.addr, p.mount, op, p.key)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-Vault-Token", p.token)
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("vault transit %s: %w", op, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("vault transit %s: status %d: %s", op, resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
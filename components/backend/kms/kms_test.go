@@ -0,0 +1,123 @@
+package kms
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSealOpenBindsAAD(t *testing.T) {
+	key, err := NewDataKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sealed, err := Seal(key, []byte(`{"type":"RUN_STARTED"}`), []byte("session-1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	plain, err := Open(key, sealed, []byte("session-1"))
+	if err != nil || string(plain) != `{"type":"RUN_STARTED"}` {
+		t.Fatalf("Open = %q, %v", plain, err)
+	}
+	if _, err := Open(key, sealed, []byte("session-2")); err == nil {
+		t.Fatal("expected Open to fail with a different aad")
+	}
+	sealed[len(sealed)-1] ^= 1
+	if _, err := Open(key, sealed, []byte("session-1")); err == nil {
+		t.Fatal("expected Open to fail on tampered ciphertext")
+	}
+}
+
+func TestLocalProviderRoundTrip(t *testing.T) {
+	if _, err := NewLocalProvider([]byte("short")); err == nil {
+		t.Fatal("expected short master key to be rejected")
+	}
+	p, err := NewLocalProvider(bytes.Repeat([]byte{7}, DataKeySize))
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, _ := NewDataKey()
+	wrapped, err := p.Wrap(context.Background(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(wrapped, key) {
+		t.Fatal("wrapped key contains the data key")
+	}
+	unwrapped, err := p.Unwrap(context.Background(), wrapped)
+	if err != nil || !bytes.Equal(unwrapped, key) {
+		t.Fatalf("Unwrap = %x, %v", unwrapped, err)
+	}
+}
+
+func TestVaultTransitProvider(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "tok" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		var body map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		switch r.URL.Path {
+		case "/v1/transit/encrypt/transcripts":
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]string{"ciphertext": "vault:v1:" + body["plaintext"]}})
+		case "/v1/transit/decrypt/transcripts":
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"data": map[string]string{"plaintext": strings.TrimPrefix(body["ciphertext"], "vault:v1:")}})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	p, err := NewVaultTransitProvider(srv.URL, "", "transcripts", "tok")
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, _ := NewDataKey()
+	wrapped, err := p.Wrap(context.Background(), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(wrapped) != "vault:v1:"+base64.StdEncoding.EncodeToString(key) {
+		t.Fatalf("unexpected wrapped key %q", wrapped)
+	}
+	unwrapped, err := p.Unwrap(context.Background(), wrapped)
+	if err != nil || !bytes.Equal(unwrapped, key) {
+		t.Fatalf("Unwrap = %x, %v", unwrapped, err)
+	}
+
+	bad, _ := NewVaultTransitProvider(srv.URL, "transit", "transcripts", "wrong")
+	if _, err := bad.Wrap(context.Background(), key); err == nil {
+		t.Fatal("expected an error for a rejected token")
+	}
+}
+
+func TestConfigureFromEnv(t *testing.T) {
+	defer Configure(nil)
+
+	t.Setenv("KMS_PROVIDER", "")
+	if err := ConfigureFromEnv(); err != nil || Current() != nil {
+		t.Fatalf("expected no provider, got %v, %v", Current(), err)
+	}
+
+	t.Setenv("KMS_PROVIDER", "local")
+	t.Setenv("KMS_LOCAL_MASTER_KEY", base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{1}, DataKeySize)))
+	if err := ConfigureFromEnv(); err != nil || Current() == nil || Current().Name() != "local" {
+		t.Fatalf("expected local provider, got %v, %v", Current(), err)
+	}
+
+	t.Setenv("KMS_LOCAL_MASTER_KEY", "not base64!")
+	if err := ConfigureFromEnv(); err == nil {
+		t.Fatal("expected invalid master key to be rejected")
+	}
+
+	t.Setenv("KMS_PROVIDER", "aws")
+	if err := ConfigureFromEnv(); err == nil {
+		t.Fatal("expected unknown provider to be rejected")
+	}
+}
@@ -0,0 +1,214 @@
+// Package linear is a minimal client for the Linear GraphQL API, covering what sessions
+// need: validating an API key and creating or looking up issues.
+package linear
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"ambient-code-backend/metrics"
+)
+
+// apiURL is overridable in tests.
+var apiURL = "https://api.linear.app/graphql"
+
+// ErrUnauthorized is returned when Linear rejects the API key
+var ErrUnauthorized = fmt.Errorf("linear rejected the API key")
+
+// Client represents a Linear API client authenticated with a personal API key
+type Client struct {
+	httpClient *http.Client
+	apiKey     string
+}
+
+// NewClient creates a new Linear API client with 15-second timeout
+func NewClient(apiKey string) *Client {
+	return &Client{
+		httpClient: &http.Client{
+			Timeout:   15 * time.Second,
+			Transport: metrics.Transport("linear", "api", nil),
+		},
+		apiKey: apiKey,
+	}
+}
+
+// Viewer is the user the API key belongs to
+type Viewer struct {
+	ID           string `json:"id"`
+	Name         string `json:"name"`
+	Email        string `json:"email"`
+	Organization struct {
+		Name   string `json:"name"`
+		URLKey string `json:"urlKey"`
+	} `json:"organization"`
+}
+
+// Issue identifies a Linear issue
+type Issue struct {
+	ID         string `json:"id"`
+	Identifier string `json:"identifier"` // e.g. "ENG-123"
+	Title      string `json:"title"`
+	URL        string `json:"url"`
+	State      struct {
+		Name string `json:"name"`
+	} `json:"state"`
+}
+
+// IssueInput describes an issue to create. Team may be the team's ID or its key (e.g. "ENG").
+type IssueInput struct {
+	Team        string
+	Title       string
+	Description string
+}
+
+// graphQLError is one entry of a GraphQL "errors" array
+type graphQLError struct {
+	Message    string `json:"message"`
+	Extensions struct {
+		Code string `json:"code"`
+	} `json:"extensions"`
+}
+
+// query runs a GraphQL operation and decodes its "data" into out
+func (c *Client) query(ctx context.Context, query string, variables map[string]interface{}, out interface{}) error {
+	payload, err := json.Marshal(map[string]interface{}{"query": query, "variables": variables})
+	if err != nil {
+		return fmt.Errorf("failed to encode request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, apiURL, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	// Personal API keys are sent as-is, without a Bearer prefix
+	req.Header.Set("Authorization", c.apiKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		// Don't wrap error - could leak the key from request details
+		return fmt.Errorf("request failed")
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if resp.StatusCode == http.StatusUnauthorized {
+		return ErrUnauthorized
+	}
+
+	var result struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []graphQLError  `json:"errors"`
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return fmt.Errorf("linear returned %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	if len(result.Errors) > 0 {
+		for _, e := range result.Errors {
+			if e.Extensions.Code == "AUTHENTICATION_ERROR" {
+				return ErrUnauthorized
+			}
+		}
+		return fmt.Errorf("linear: %s", result.Errors[0].Message)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("linear returned %d", resp.StatusCode)
+	}
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(result.Data, out)
+}
+
+// Viewer returns the user the API key belongs to. It fails with ErrUnauthorized for
+// invalid or revoked keys.
+func (c *Client) Viewer(ctx context.Context) (*Viewer, error) {
+	var data struct {
+		Viewer Viewer `json:"viewer"`
+	}
+	err := c.query(ctx, `query { viewer { id name email organization { name urlKey } } }`, nil, &data)
+	if err != nil {
+		return nil, err
+	}
+	return &data.Viewer, nil
+}
+
+const issueFields = `id identifier title url state { name }`
+
+// GetIssue looks up an issue by ID or identifier (e.g. "ENG-123")
+func (c *Client) GetIssue(ctx context.Context, id string) (*Issue, error) {
+	var data struct {
+		Issue *Issue `json:"issue"`
+	}
+	err := c.query(ctx, `query($id: String!) { issue(id: $id) { `+issueFields+` } }`, map[string]interface{}{"id": id}, &data)
+	if err != nil {
+		return nil, err
+	}
+	if data.Issue == nil {
+		return nil, fmt.Errorf("issue %s not found", id)
+	}
+	return data.Issue, nil
+}
+
+// CreateIssue creates an issue in the given team
+func (c *Client) CreateIssue(ctx context.Context, in IssueInput) (*Issue, error) {
+	teamID, err := c.resolveTeam(ctx, in.Team)
+	if err != nil {
+		return nil, err
+	}
+	input := map[string]interface{}{"teamId": teamID, "title": in.Title}
+	if in.Description != "" {
+		input["description"] = in.Description
+	}
+	var data struct {
+		IssueCreate struct {
+			Success bool   `json:"success"`
+			Issue   *Issue `json:"issue"`
+		} `json:"issueCreate"`
+	}
+	err = c.query(ctx, `mutation($input: IssueCreateInput!) { issueCreate(input: $input) { success issue { `+issueFields+` } } }`,
+		map[string]interface{}{"input": input}, &data)
+	if err != nil {
+		return nil, err
+	}
+	if !data.IssueCreate.Success || data.IssueCreate.Issue == nil {
+		return nil, fmt.Errorf("linear did not create the issue")
+	}
+	return data.IssueCreate.Issue, nil
+}
+
+// uuidPattern matches Linear entity IDs
+var uuidPattern = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// resolveTeam returns team unchanged if it is an ID, otherwise looks it up by key
+func (c *Client) resolveTeam(ctx context.Context, team string) (string, error) {
+	team = strings.TrimSpace(team)
+	if team == "" {
+		return "", fmt.Errorf("team is required")
+	}
+	if uuidPattern.MatchString(team) {
+		return team, nil
+	}
+	var data struct {
+		Teams struct {
+			Nodes []struct {
+				ID string `json:"id"`
+			} `json:"nodes"`
+		} `json:"teams"`
+	}
+	err := c.query(ctx, `query($key: String!) { teams(filter: { key: { eqIgnoreCase: $key } }) { nodes { id } } }`,
+		map[string]interface{}{"key": team}, &data)
+	if err != nil {
+		return "", err
+	}
+	if len(data.Teams.Nodes) == 0 {
+		return "", fmt.Errorf("team %q not found", team)
+	}
+	return data.Teams.Nodes[0].ID, nil
+}
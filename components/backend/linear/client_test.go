@@ -0,0 +1,96 @@
+package linear
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// fakeLinear serves GraphQL requests by matching the query text
+func fakeLinear(t *testing.T, handle func(query string, vars map[string]interface{}) string) {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "lin_api_good" {
+			w.WriteHeader(http.StatusUnauthorized)
+			fmt.Fprint(w, `{"errors":[{"message":"Authentication required","extensions":{"code":"AUTHENTICATION_ERROR"}}]}`)
+			return
+		}
+		var req struct {
+			Query     string                 `json:"query"`
+			Variables map[string]interface{} `json:"variables"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decode: %v", err)
+		}
+		fmt.Fprint(w, handle(req.Query, req.Variables))
+	}))
+	original := apiURL
+	apiURL = srv.URL
+	t.Cleanup(func() {
+		apiURL = original
+		srv.Close()
+	})
+}
+
+func TestViewer(t *testing.T) {
+	fakeLinear(t, func(string, map[string]interface{}) string {
+		return `{"data":{"viewer":{"id":"u1","email":"dev@example.com","organization":{"urlKey":"acme"}}}}`
+	})
+
+	v, err := NewClient("lin_api_good").Viewer(context.Background())
+	if err != nil {
+		t.Fatalf("Viewer: %v", err)
+	}
+	if v.Email != "dev@example.com" || v.Organization.URLKey != "acme" {
+		t.Errorf("viewer = %+v", v)
+	}
+
+	if _, err := NewClient("lin_api_bad").Viewer(context.Background()); err != ErrUnauthorized {
+		t.Errorf("bad key: err = %v, want ErrUnauthorized", err)
+	}
+}
+
+func TestCreateIssueResolvesTeamKey(t *testing.T) {
+	fakeLinear(t, func(query string, vars map[string]interface{}) string {
+		switch {
+		case strings.Contains(query, "teams("):
+			if vars["key"] != "ENG" {
+				return `{"data":{"teams":{"nodes":[]}}}`
+			}
+			return `{"data":{"teams":{"nodes":[{"id":"team-uuid"}]}}}`
+		case strings.Contains(query, "issueCreate"):
+			input, _ := vars["input"].(map[string]interface{})
+			if input["teamId"] != "team-uuid" || input["title"] != "Flaky test" {
+				return `{"errors":[{"message":"bad input"}]}`
+			}
+			return `{"data":{"issueCreate":{"success":true,"issue":{"id":"i1","identifier":"ENG-7","title":"Flaky test","url":"https://linear.app/acme/issue/ENG-7"}}}}`
+		}
+		return `{"errors":[{"message":"unexpected query"}]}`
+	})
+
+	c := NewClient("lin_api_good")
+	issue, err := c.CreateIssue(context.Background(), IssueInput{Team: "ENG", Title: "Flaky test"})
+	if err != nil {
+		t.Fatalf("CreateIssue: %v", err)
+	}
+	if issue.Identifier != "ENG-7" {
+		t.Errorf("identifier = %q", issue.Identifier)
+	}
+
+	if _, err := c.CreateIssue(context.Background(), IssueInput{Team: "OPS", Title: "x"}); err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Errorf("unknown team: err = %v", err)
+	}
+}
+
+func TestGetIssueNotFound(t *testing.T) {
+	fakeLinear(t, func(string, map[string]interface{}) string {
+		return `{"data":{"issue":null}}`
+	})
+	if _, err := NewClient("lin_api_good").GetIssue(context.Background(), "ENG-404"); err == nil {
+		t.Error("expected an error for a missing issue")
+	}
+}
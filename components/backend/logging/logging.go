@@ -0,0 +1,159 @@
+// Package logging configures structured (slog) logging and carries request IDs
+// through contexts so a request can be traced across backend, proxy and runner logs.
+package logging
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// RequestIDHeader carries the request ID on incoming requests, responses and
+// requests forwarded to runners
+const RequestIDHeader = "X-Request-ID"
+
+// GinRequestIDKey is the gin context key holding the request ID. A *gin.Context
+// resolves string keys from its own values, so it can be passed as the context.
+const GinRequestIDKey = "requestID"
+
+type requestIDKey struct{}
+
+// WithRequestID returns a copy of ctx carrying id
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFromContext returns the request ID carried by ctx, or ""
+func RequestIDFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	if id, ok := ctx.Value(requestIDKey{}).(string); ok {
+		return id
+	}
+	if id, ok := ctx.Value(GinRequestIDKey).(string); ok {
+		return id
+	}
+	return ""
+}
+
+// SetRequestIDHeader forwards the request ID carried by req's context in its
+// X-Request-ID header, e.g. on calls to a session's runner or content service
+func SetRequestIDHeader(req *http.Request) {
+	if id := RequestIDFromContext(req.Context()); id != "" {
+		req.Header.Set(RequestIDHeader, id)
+	}
+}
+
+// NewRequestID returns a random 16 hex character request ID
+func NewRequestID() string {
+	b := make([]byte, 8)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// ValidRequestID accepts caller-supplied IDs of up to 128 URL-safe characters
+func ValidRequestID(id string) bool {
+	if id == "" || len(id) > 128 {
+		return false
+	}
+	for _, r := range id {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' || r == '.') {
+			return false
+		}
+	}
+	return true
+}
+
+// contextHandler adds the request ID from the record's context to every record
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if id := RequestIDFromContext(ctx); id != "" {
+		r.AddAttrs(slog.String("requestId", id))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
+
+// level is the default logger's minimum level, adjustable at runtime with SetLevel
+var level slog.LevelVar
+
+// Setup installs the default slog logger: JSON on stderr, or text when
+// LOG_FORMAT=text, at LOG_LEVEL (debug, info, warn, error; default info).
+// Output of the standard log package is routed through it as well.
+func Setup() error {
+	if v := os.Getenv("LOG_LEVEL"); v != "" {
+		if err := SetLevel(v); err != nil {
+			return err
+		}
+	}
+	opts := &slog.HandlerOptions{Level: &level}
+
+	var handler slog.Handler
+	switch strings.ToLower(os.Getenv("LOG_FORMAT")) {
+	case "", "json":
+		handler = slog.NewJSONHandler(os.Stderr, opts)
+	case "text":
+		handler = slog.NewTextHandler(os.Stderr, opts)
+	default:
+		return fmt.Errorf("invalid LOG_FORMAT %q", os.Getenv("LOG_FORMAT"))
+	}
+	slog.SetDefault(slog.New(contextHandler{handler}))
+	return nil
+}
+
+// SetLevel changes the default logger's minimum level (debug, info, warn, error)
+func SetLevel(name string) error {
+	var l slog.Level
+	if err := l.UnmarshalText([]byte(name)); err != nil {
+		return fmt.Errorf("invalid log level %q: %w", name, err)
+	}
+	level.Set(l)
+	return nil
+}
+
+// Debugf logs a formatted message at debug level with ctx's request ID
+func Debugf(ctx context.Context, format string, args ...any) {
+	logf(ctx, slog.LevelDebug, format, args...)
+}
+
+// Infof logs a formatted message at info level with ctx's request ID
+func Infof(ctx context.Context, format string, args ...any) {
+	logf(ctx, slog.LevelInfo, format, args...)
+}
+
+// Warnf logs a formatted message at warn level with ctx's request ID
+func Warnf(ctx context.Context, format string, args ...any) {
+	logf(ctx, slog.LevelWarn, format, args...)
+}
+
+// Errorf logs a formatted message at error level with ctx's request ID
+func Errorf(ctx context.Context, format string, args ...any) {
+	logf(ctx, slog.LevelError, format, args...)
+}
+
+func logf(ctx context.Context, level slog.Level, format string, args ...any) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	logger := slog.Default()
+	if !logger.Enabled(ctx, level) {
+		return
+	}
+	logger.Log(ctx, level, fmt.Sprintf(format, args...))
+}
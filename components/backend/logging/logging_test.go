@@ -0,0 +1,63 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestRequestIDContext(t *testing.T) {
+	if got := RequestIDFromContext(context.Background()); got != "" {
+		t.Fatalf("RequestIDFromContext(empty) = %q, want empty", got)
+	}
+	ctx := WithRequestID(context.Background(), "abc123")
+	if got := RequestIDFromContext(ctx); got != "abc123" {
+		t.Fatalf("RequestIDFromContext = %q, want abc123", got)
+	}
+
+	req, _ := http.NewRequestWithContext(ctx, http.MethodGet, "http://runner/", nil)
+	SetRequestIDHeader(req)
+	if got := req.Header.Get(RequestIDHeader); got != "abc123" {
+		t.Fatalf("%s = %q, want abc123", RequestIDHeader, got)
+	}
+}
+
+func TestValidRequestID(t *testing.T) {
+	for id, want := range map[string]bool{
+		"3f2a9c1e7b4d5a60":       true,
+		"trace-1.2_x":            true,
+		"":                       false,
+		"has space":              false,
+		"new\nline":              false,
+		strings.Repeat("a", 129): false,
+	} {
+		if got := ValidRequestID(id); got != want {
+			t.Errorf("ValidRequestID(%q) = %v, want %v", id, got, want)
+		}
+	}
+	if id := NewRequestID(); !ValidRequestID(id) || len(id) != 16 {
+		t.Errorf("NewRequestID() = %q, want 16 valid characters", id)
+	}
+}
+
+func TestHandlerAddsRequestID(t *testing.T) {
+	var buf bytes.Buffer
+	prev := slog.Default()
+	slog.SetDefault(slog.New(contextHandler{slog.NewJSONHandler(&buf, nil)}))
+	t.Cleanup(func() { slog.SetDefault(prev) })
+
+	Warnf(WithRequestID(context.Background(), "req-1"), "runner %s not ready", "s1")
+	Debugf(context.Background(), "dropped below default level")
+
+	var line map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &line); err != nil {
+		t.Fatalf("expected exactly one JSON line, got %q: %v", buf.String(), err)
+	}
+	if line["requestId"] != "req-1" || line["level"] != "WARN" || line["msg"] != "runner s1 not ready" {
+		t.Fatalf("unexpected log line %v", line)
+	}
+}
@@ -4,14 +4,24 @@ import (
 	"context"
 	"log"
 	"os"
+	"time"
 
+	"ambient-code-backend/audit"
+	"ambient-code-backend/config"
+	"ambient-code-backend/eventbridge"
 	"ambient-code-backend/git"
 	"ambient-code-backend/github"
+	"ambient-code-backend/grpcapi"
 	"ambient-code-backend/handlers"
 	"ambient-code-backend/k8s"
+	"ambient-code-backend/kms"
+	"ambient-code-backend/logging"
+	"ambient-code-backend/mcpserver"
+	"ambient-code-backend/notifications"
 	"ambient-code-backend/server"
 	"ambient-code-backend/websocket"
 
+	"github.com/gin-gonic/gin"
 	"github.com/joho/godotenv"
 )
 
@@ -49,9 +59,57 @@ func main() {
 	_ = godotenv.Overload(".env.local")
 	_ = godotenv.Overload(".env")
 
+	// Structured logging (also captures the standard log package)
+	if err := logging.Setup(); err != nil {
+		log.Fatalf("Invalid logging configuration: %v", err)
+	}
+
+	// Typed configuration from CONFIG_FILE and the environment. Non-structural
+	// settings such as the log level and rate limits reload when the file changes.
+	if err := config.Init(); err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+	if err := logging.SetLevel(config.Current().LogLevel); err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+	config.OnReload(func(old, next *config.Config) {
+		if next.LogLevel != old.LogLevel {
+			_ = logging.SetLevel(next.LogLevel)
+		}
+	})
+	config.Watch(context.Background(), 30*time.Second)
+
 	// Log build information
 	logBuildInfo()
 
+	// Optional in-cluster repo mirror consulted by the git layer
+	git.MirrorRoot = os.Getenv("GIT_MIRROR_ROOT")
+	git.MirrorServiceURL = os.Getenv("GIT_MIRROR_SERVICE_URL")
+
+	// MCP stdio mode - serves vTeam tools to a local MCP client against a remote backend
+	if os.Getenv("MCP_STDIO_MODE") == "true" {
+		apiURL, token := os.Getenv("VTEAM_API_URL"), os.Getenv("VTEAM_TOKEN")
+		if apiURL == "" || token == "" {
+			log.Fatalf("MCP_STDIO_MODE requires VTEAM_API_URL and VTEAM_TOKEN")
+		}
+		d := &mcpserver.RemoteDispatcher{BaseURL: apiURL, Token: token}
+		if err := mcpserver.ServeStdio(context.Background(), mcpserver.NewServer(GitVersion), d, os.Stdin, os.Stdout); err != nil {
+			log.Fatalf("MCP stdio server error: %v", err)
+		}
+		return
+	}
+
+	// Repo mirror service mode - serves cached bare mirrors, no K8s access needed
+	if os.Getenv("MIRROR_SERVICE_MODE") == "true" {
+		log.Println("Starting in MIRROR_SERVICE_MODE (no K8s client initialization)")
+		handlers.MirrorRoot = getEnvOrDefault("GIT_MIRROR_ROOT", handlers.MirrorRoot)
+		log.Printf("Mirror service using root: %s", handlers.MirrorRoot)
+		if err := server.RunContentService(registerMirrorRoutes); err != nil {
+			log.Fatalf("Mirror service error: %v", err)
+		}
+		return
+	}
+
 	// Content service mode - minimal initialization, no K8s access needed
 	if os.Getenv("CONTENT_SERVICE_MODE") == "true" {
 		log.Println("Starting in CONTENT_SERVICE_MODE (no K8s client initialization)")
@@ -68,6 +126,8 @@ func main() {
 		handlers.GitPullRepo = git.PullRepo
 		handlers.GitPushToRepo = git.PushToRepo
 		handlers.GitSyncRepo = git.SyncRepo
+		handlers.GitStatusFiles = git.StatusFiles
+		handlers.GitWorkspaceDiff = git.WorkspaceDiff
 		handlers.GitCreateBranch = git.CreateBranch
 		handlers.GitListRemoteBranches = git.ListRemoteBranches
 
@@ -128,6 +188,8 @@ func main() {
 	handlers.GitPullRepo = git.PullRepo
 	handlers.GitPushToRepo = git.PushToRepo
 	handlers.GitSyncRepo = git.SyncRepo
+	handlers.GitStatusFiles = git.StatusFiles
+	handlers.GitWorkspaceDiff = git.WorkspaceDiff
 	handlers.GitCreateBranch = git.CreateBranch
 	handlers.GitListRemoteBranches = git.ListRemoteBranches
 
@@ -144,6 +206,9 @@ func main() {
 	// Initialize session handlers
 	handlers.GetAgenticSessionV1Alpha1Resource = k8s.GetAgenticSessionV1Alpha1Resource
 	handlers.DynamicClient = server.DynamicClient
+	handlers.StartSessionCache()
+	handlers.StartAdmissionQueue()
+	handlers.StartIdleSessionSweeper()
 	handlers.GetGitHubToken = handlers.WrapGitHubTokenForRepo(git.GetGitHubToken)
 	handlers.GetGitLabToken = git.GetGitLabToken
 	handlers.DeriveRepoFolderFromURL = git.DeriveRepoFolderFromURL
@@ -160,9 +225,52 @@ func main() {
 
 	// Initialize websocket package
 	websocket.StateBaseDir = server.StateBaseDir
+	handlers.ActiveRunCounts = websocket.ActiveRunCounts
+	handlers.RunFinalMessage = websocket.RunFinalMessage
+	handlers.PurgeSessionData = websocket.PurgeSessionData
+	handlers.PurgeUserContributions = websocket.PurgeUserContributions
+	handlers.SessionRuns = websocket.SessionRuns
+	handlers.LatestRunSummary = websocket.LatestRunSummary
+	websocket.StartResourceUsageSampler()
+
+	// Audit sinks for mutating API calls
+	if err := audit.ConfigureFromEnv(); err != nil {
+		log.Fatalf("Invalid audit configuration: %v", err)
+	}
+
+	// Optional KMS provider for encrypting persisted transcripts
+	if err := kms.ConfigureFromEnv(); err != nil {
+		log.Fatalf("Invalid KMS configuration: %v", err)
+	}
 
-	// Normal server mode
-	if err := server.Run(registerRoutes); err != nil {
+	// Optional Kafka/NATS mirror of AG-UI events and run status changes
+	if err := eventbridge.ConfigureFromEnv(); err != nil {
+		log.Fatalf("Invalid event bridge configuration: %v", err)
+	}
+
+	// Slack notifications for project rules (run finished/errored, ...)
+	handlers.Notifier = notifications.NewDispatcher(handlers.NotificationSource{})
+
+	// Authentication backends (OpenShift OAuth proxy, optional corporate OIDC, dev static tokens)
+	authenticators, err := server.InitAuth()
+	if err != nil {
+		log.Fatalf("Invalid authentication configuration: %v", err)
+	}
+	handlers.Authenticators = authenticators
+
+	// Normal server mode. The optional gRPC API dispatches its calls through the REST
+	// router so both share authentication and authorization.
+	grpcPort := config.Current().GRPCPort
+	if err := server.Run(func(r *gin.Engine) {
+		registerRoutes(r)
+		if grpcPort != "" {
+			go func() {
+				if err := grpcapi.Serve(":"+grpcPort, r); err != nil {
+					log.Fatalf("gRPC server error: %v", err)
+				}
+			}()
+		}
+	}); err != nil {
 		log.Fatalf("Server error: %v", err)
 	}
 }
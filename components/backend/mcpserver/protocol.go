@@ -0,0 +1,156 @@
+// Package mcpserver exposes vTeam platform operations as Model Context Protocol tools.
+//
+// Tools are thin wrappers over the backend's own REST API, dispatched with the
+// caller's credentials, so RBAC and validation are identical to the UI. The server
+// is reachable over HTTP (streamable HTTP and SSE) from the backend, and over stdio
+// by running the backend binary in MCP_STDIO_MODE against a remote backend.
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+)
+
+// ProtocolVersion is the MCP revision implemented by this server
+const ProtocolVersion = "2025-03-26"
+
+// JSON-RPC 2.0 error codes
+const (
+	codeParseError     = -32700
+	codeInvalidRequest = -32600
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+)
+
+type rpcRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type rpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+// Tool is an MCP tool backed by the vTeam API
+type Tool struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	InputSchema map[string]interface{} `json:"inputSchema"`
+	handler     func(ctx context.Context, d Dispatcher, args map[string]interface{}) (interface{}, error)
+}
+
+// Server dispatches MCP requests to the registered tools
+type Server struct {
+	Name    string
+	Version string
+	tools   []Tool
+}
+
+// NewServer returns a server with the standard vTeam tool set
+func NewServer(version string) *Server {
+	return &Server{Name: "vteam", Version: version, tools: platformTools()}
+}
+
+// Handle processes one JSON-RPC message and returns the encoded response, or nil
+// for notifications. Errors are reported in-band as JSON-RPC errors.
+func (s *Server) Handle(ctx context.Context, d Dispatcher, raw []byte) []byte {
+	var req rpcRequest
+	if err := json.Unmarshal(raw, &req); err != nil {
+		return encodeResponse(rpcResponse{JSONRPC: "2.0", ID: json.RawMessage("null"), Error: &rpcError{Code: codeParseError, Message: "parse error"}})
+	}
+	if req.JSONRPC != "2.0" || req.Method == "" {
+		return encodeResponse(rpcResponse{JSONRPC: "2.0", ID: idOrNull(req.ID), Error: &rpcError{Code: codeInvalidRequest, Message: "invalid request"}})
+	}
+	// Notifications (no id) never get a response
+	if len(req.ID) == 0 {
+		return nil
+	}
+
+	result, rerr := s.dispatch(ctx, d, req)
+	resp := rpcResponse{JSONRPC: "2.0", ID: req.ID}
+	if rerr != nil {
+		resp.Error = rerr
+	} else {
+		resp.Result = result
+	}
+	return encodeResponse(resp)
+}
+
+func (s *Server) dispatch(ctx context.Context, d Dispatcher, req rpcRequest) (interface{}, *rpcError) {
+	switch req.Method {
+	case "initialize":
+		return map[string]interface{}{
+			"protocolVersion": ProtocolVersion,
+			"capabilities":    map[string]interface{}{"tools": map[string]interface{}{"listChanged": false}},
+			"serverInfo":      map[string]interface{}{"name": s.Name, "version": s.Version},
+		}, nil
+	case "ping":
+		return map[string]interface{}{}, nil
+	case "tools/list":
+		return map[string]interface{}{"tools": s.tools}, nil
+	case "tools/call":
+		var params struct {
+			Name      string                 `json:"name"`
+			Arguments map[string]interface{} `json:"arguments"`
+		}
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, &rpcError{Code: codeInvalidParams, Message: "invalid params"}
+		}
+		for _, t := range s.tools {
+			if t.Name != params.Name {
+				continue
+			}
+			if params.Arguments == nil {
+				params.Arguments = map[string]interface{}{}
+			}
+			out, err := t.handler(ctx, d, params.Arguments)
+			if err != nil {
+				// Tool failures are results, not protocol errors, so the model can react
+				return toolResult(err.Error(), true), nil
+			}
+			b, err := json.MarshalIndent(out, "", "  ")
+			if err != nil {
+				return toolResult(fmt.Sprintf("failed to encode result: %v", err), true), nil
+			}
+			return toolResult(string(b), false), nil
+		}
+		return nil, &rpcError{Code: codeInvalidParams, Message: fmt.Sprintf("unknown tool: %s", params.Name)}
+	default:
+		return nil, &rpcError{Code: codeMethodNotFound, Message: fmt.Sprintf("method not found: %s", req.Method)}
+	}
+}
+
+func toolResult(text string, isError bool) map[string]interface{} {
+	return map[string]interface{}{
+		"content": []map[string]interface{}{{"type": "text", "text": text}},
+		"isError": isError,
+	}
+}
+
+func idOrNull(id json.RawMessage) json.RawMessage {
+	if len(id) == 0 {
+		return json.RawMessage("null")
+	}
+	return id
+}
+
+func encodeResponse(resp rpcResponse) []byte {
+	b, err := json.Marshal(resp)
+	if err != nil {
+		log.Printf("MCP: failed to encode response: %v", err)
+		return nil
+	}
+	return b
+}
@@ -0,0 +1,105 @@
+package mcpserver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// fakeDispatcher records API calls and returns canned responses keyed by "METHOD path"
+type fakeDispatcher struct {
+	responses map[string]string
+	calls     []string
+}
+
+func (f *fakeDispatcher) Do(_ context.Context, method, path string, _ []byte) (int, []byte, error) {
+	key := method + " " + path
+	f.calls = append(f.calls, key)
+	if resp, ok := f.responses[key]; ok {
+		return http.StatusOK, []byte(resp), nil
+	}
+	return http.StatusNotFound, []byte(`{"error":"not found"}`), nil
+}
+
+func decode(t *testing.T, raw []byte) map[string]interface{} {
+	t.Helper()
+	var out map[string]interface{}
+	if err := json.Unmarshal(raw, &out); err != nil {
+		t.Fatalf("invalid response %q: %v", raw, err)
+	}
+	return out
+}
+
+func TestInitializeAndListTools(t *testing.T) {
+	s := NewServer("test")
+	resp := decode(t, s.Handle(context.Background(), &fakeDispatcher{}, []byte(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`)))
+	result := resp["result"].(map[string]interface{})
+	if result["protocolVersion"] != ProtocolVersion {
+		t.Errorf("unexpected protocol version: %v", result["protocolVersion"])
+	}
+
+	resp = decode(t, s.Handle(context.Background(), &fakeDispatcher{}, []byte(`{"jsonrpc":"2.0","id":2,"method":"tools/list"}`)))
+	tools := resp["result"].(map[string]interface{})["tools"].([]interface{})
+	names := map[string]bool{}
+	for _, tool := range tools {
+		names[tool.(map[string]interface{})["name"].(string)] = true
+	}
+	for _, want := range []string{"list_sessions", "create_session", "search_runs", "get_credential_status"} {
+		if !names[want] {
+			t.Errorf("expected tool %s to be listed", want)
+		}
+	}
+}
+
+func TestNotificationsAndUnknownMethods(t *testing.T) {
+	s := NewServer("test")
+	if resp := s.Handle(context.Background(), &fakeDispatcher{}, []byte(`{"jsonrpc":"2.0","method":"notifications/initialized"}`)); resp != nil {
+		t.Errorf("expected no response to a notification, got %s", resp)
+	}
+	resp := decode(t, s.Handle(context.Background(), &fakeDispatcher{}, []byte(`{"jsonrpc":"2.0","id":3,"method":"resources/list"}`)))
+	if resp["error"].(map[string]interface{})["code"].(float64) != codeMethodNotFound {
+		t.Errorf("expected method-not-found, got %v", resp["error"])
+	}
+	resp = decode(t, s.Handle(context.Background(), &fakeDispatcher{}, []byte(`not json`)))
+	if resp["error"].(map[string]interface{})["code"].(float64) != codeParseError {
+		t.Errorf("expected parse error, got %v", resp["error"])
+	}
+}
+
+func TestToolCallSummarizesSessionsAndReportsAPIErrors(t *testing.T) {
+	s := NewServer("test")
+	d := &fakeDispatcher{responses: map[string]string{
+		"GET /api/projects/demo/agentic-sessions": `{"items":[{"metadata":{"name":"s1"},"spec":{"displayName":"Fix"},"status":{"phase":"Running"}}],"totalCount":1}`,
+	}}
+
+	resp := decode(t, s.Handle(context.Background(), d, []byte(`{"jsonrpc":"2.0","id":4,"method":"tools/call","params":{"name":"list_sessions","arguments":{"project":"demo"}}}`)))
+	result := resp["result"].(map[string]interface{})
+	if result["isError"] != false {
+		t.Fatalf("expected success, got %v", result)
+	}
+	text := result["content"].([]interface{})[0].(map[string]interface{})["text"].(string)
+	if !strings.Contains(text, `"phase": "Running"`) || !strings.Contains(text, `"name": "s1"`) {
+		t.Errorf("unexpected summary: %s", text)
+	}
+
+	resp = decode(t, s.Handle(context.Background(), d, []byte(`{"jsonrpc":"2.0","id":5,"method":"tools/call","params":{"name":"get_session","arguments":{"project":"demo","session":"missing"}}}`)))
+	result = resp["result"].(map[string]interface{})
+	if result["isError"] != true {
+		t.Errorf("expected tool error for a failed API call, got %v", result)
+	}
+}
+
+func TestServeStdio(t *testing.T) {
+	in := strings.NewReader("{\"jsonrpc\":\"2.0\",\"id\":1,\"method\":\"ping\"}\n\n{\"jsonrpc\":\"2.0\",\"method\":\"notifications/initialized\"}\n")
+	var out bytes.Buffer
+	if err := ServeStdio(context.Background(), NewServer("test"), &fakeDispatcher{}, in, &out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("expected one response line, got %q", out.String())
+	}
+}
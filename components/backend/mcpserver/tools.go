@@ -0,0 +1,360 @@
+package mcpserver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// maxRunSearchSessions bounds how many sessions search_runs inspects per call
+const maxRunSearchSessions = 50
+
+func stringProp(description string) map[string]interface{} {
+	return map[string]interface{}{"type": "string", "description": description}
+}
+
+func objectSchema(required []string, props map[string]interface{}) map[string]interface{} {
+	schema := map[string]interface{}{"type": "object", "properties": props}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// platformTools returns the vTeam tool set
+func platformTools() []Tool {
+	projectProp := stringProp("Project (namespace) name")
+	sessionProp := stringProp("Session name")
+
+	return []Tool{
+		{
+			Name:        "list_projects",
+			Description: "List vTeam projects the caller can access.",
+			InputSchema: objectSchema(nil, map[string]interface{}{}),
+			handler: func(ctx context.Context, d Dispatcher, args map[string]interface{}) (interface{}, error) {
+				return callAPI(ctx, d, http.MethodGet, "/api/projects", nil)
+			},
+		},
+		{
+			Name:        "list_sessions",
+			Description: "List agentic sessions in a project, newest first, with their phase.",
+			InputSchema: objectSchema([]string{"project"}, map[string]interface{}{
+				"project": projectProp,
+				"search":  stringProp("Optional filter on session name or display name"),
+				"limit":   map[string]interface{}{"type": "integer", "description": "Maximum sessions to return (default 20, max 100)"},
+			}),
+			handler: listSessionsTool,
+		},
+		{
+			Name:        "get_session",
+			Description: "Get the full spec and status of an agentic session.",
+			InputSchema: objectSchema([]string{"project", "session"}, map[string]interface{}{
+				"project": projectProp,
+				"session": sessionProp,
+			}),
+			handler: func(ctx context.Context, d Dispatcher, args map[string]interface{}) (interface{}, error) {
+				project, session, err := projectAndSession(args)
+				if err != nil {
+					return nil, err
+				}
+				return callAPI(ctx, d, http.MethodGet, sessionPath(project, session), nil)
+			},
+		},
+		{
+			Name:        "create_session",
+			Description: "Create an agentic session in a project with an initial prompt and optional repositories.",
+			InputSchema: objectSchema([]string{"project", "prompt"}, map[string]interface{}{
+				"project":     projectProp,
+				"prompt":      stringProp("Initial prompt for the agent"),
+				"displayName": stringProp("Optional human-readable session name"),
+				"model":       stringProp("Optional model (e.g. sonnet, opus)"),
+				"interactive": map[string]interface{}{"type": "boolean", "description": "Keep the session open for follow-up messages (default true)"},
+				"repos": map[string]interface{}{
+					"type":        "array",
+					"description": "Repositories to clone into the workspace",
+					"items": objectSchema([]string{"url"}, map[string]interface{}{
+						"url":    stringProp("Repository URL"),
+						"branch": stringProp("Optional branch"),
+					}),
+				},
+			}),
+			handler: createSessionTool,
+		},
+		{
+			Name:        "send_message",
+			Description: "Send a user message to a running interactive session. Returns the run ID; use search_runs to follow progress.",
+			InputSchema: objectSchema([]string{"project", "session", "message"}, map[string]interface{}{
+				"project": projectProp,
+				"session": sessionProp,
+				"message": stringProp("Message text"),
+			}),
+			handler: sendMessageTool,
+		},
+		{
+			Name:        "start_session",
+			Description: "Start (or restart) a stopped session.",
+			InputSchema: objectSchema([]string{"project", "session"}, map[string]interface{}{
+				"project": projectProp,
+				"session": sessionProp,
+			}),
+			handler: func(ctx context.Context, d Dispatcher, args map[string]interface{}) (interface{}, error) {
+				project, session, err := projectAndSession(args)
+				if err != nil {
+					return nil, err
+				}
+				return callAPI(ctx, d, http.MethodPost, sessionPath(project, session)+"/start", nil)
+			},
+		},
+		{
+			Name:        "stop_session",
+			Description: "Stop a running session.",
+			InputSchema: objectSchema([]string{"project", "session"}, map[string]interface{}{
+				"project": projectProp,
+				"session": sessionProp,
+			}),
+			handler: func(ctx context.Context, d Dispatcher, args map[string]interface{}) (interface{}, error) {
+				project, session, err := projectAndSession(args)
+				if err != nil {
+					return nil, err
+				}
+				return callAPI(ctx, d, http.MethodPost, sessionPath(project, session)+"/stop", nil)
+			},
+		},
+		{
+			Name:        "search_runs",
+			Description: "Search run history across a project's sessions, filtered by session, status, or start time.",
+			InputSchema: objectSchema([]string{"project"}, map[string]interface{}{
+				"project": projectProp,
+				"session": stringProp("Optional session name to restrict the search"),
+				"status":  stringProp("Optional run status: running, completed, error, interrupted"),
+				"since":   stringProp("Optional RFC3339 timestamp; only runs started at or after it"),
+			}),
+			handler: searchRunsTool,
+		},
+		{
+			Name:        "get_credential_status",
+			Description: "Show which integrations (GitHub, GitLab, Google, Jira, Linear) the caller has connected.",
+			InputSchema: objectSchema(nil, map[string]interface{}{}),
+			handler: func(ctx context.Context, d Dispatcher, args map[string]interface{}) (interface{}, error) {
+				return callAPI(ctx, d, http.MethodGet, "/api/auth/integrations/status", nil)
+			},
+		},
+	}
+}
+
+// callAPI invokes a backend endpoint and decodes its JSON response. Non-2xx responses
+// are surfaced as errors carrying the API's error message.
+func callAPI(ctx context.Context, d Dispatcher, method, path string, body interface{}) (interface{}, error) {
+	var payload []byte
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return nil, err
+		}
+		payload = b
+	}
+	status, resp, err := d.Do(ctx, method, path, payload)
+	if err != nil {
+		return nil, err
+	}
+	var out interface{}
+	if len(resp) > 0 {
+		if err := json.Unmarshal(resp, &out); err != nil {
+			out = string(resp)
+		}
+	}
+	if status < 200 || status >= 300 {
+		if m, ok := out.(map[string]interface{}); ok {
+			if msg, ok := m["error"].(string); ok {
+				return nil, fmt.Errorf("%s (HTTP %d)", msg, status)
+			}
+		}
+		return nil, fmt.Errorf("request failed with HTTP %d", status)
+	}
+	return out, nil
+}
+
+func stringArg(args map[string]interface{}, key string) string {
+	v, _ := args[key].(string)
+	return strings.TrimSpace(v)
+}
+
+func projectAndSession(args map[string]interface{}) (string, string, error) {
+	project, session := stringArg(args, "project"), stringArg(args, "session")
+	if project == "" || session == "" {
+		return "", "", fmt.Errorf("project and session are required")
+	}
+	return project, session, nil
+}
+
+func sessionPath(project, session string) string {
+	return fmt.Sprintf("/api/projects/%s/agentic-sessions/%s", url.PathEscape(project), url.PathEscape(session))
+}
+
+func listSessionsTool(ctx context.Context, d Dispatcher, args map[string]interface{}) (interface{}, error) {
+	project := stringArg(args, "project")
+	if project == "" {
+		return nil, fmt.Errorf("project is required")
+	}
+	q := url.Values{}
+	if s := stringArg(args, "search"); s != "" {
+		q.Set("search", s)
+	}
+	if limit, ok := args["limit"].(float64); ok && limit > 0 {
+		q.Set("limit", fmt.Sprintf("%d", int(limit)))
+	}
+	path := fmt.Sprintf("/api/projects/%s/agentic-sessions", url.PathEscape(project))
+	if len(q) > 0 {
+		path += "?" + q.Encode()
+	}
+	out, err := callAPI(ctx, d, http.MethodGet, path, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, _ := out.(map[string]interface{})
+	items, _ := resp["items"].([]interface{})
+	summaries := make([]map[string]interface{}, 0, len(items))
+	for _, item := range items {
+		summaries = append(summaries, summarizeSession(item))
+	}
+	return map[string]interface{}{"sessions": summaries, "totalCount": resp["totalCount"]}, nil
+}
+
+// summarizeSession keeps the fields an agent needs to pick a session
+func summarizeSession(item interface{}) map[string]interface{} {
+	s, _ := item.(map[string]interface{})
+	meta, _ := s["metadata"].(map[string]interface{})
+	spec, _ := s["spec"].(map[string]interface{})
+	status, _ := s["status"].(map[string]interface{})
+	summary := map[string]interface{}{
+		"name":              meta["name"],
+		"displayName":       spec["displayName"],
+		"creationTimestamp": meta["creationTimestamp"],
+		"phase":             "",
+	}
+	if status != nil {
+		summary["phase"] = status["phase"]
+	}
+	return summary
+}
+
+func createSessionTool(ctx context.Context, d Dispatcher, args map[string]interface{}) (interface{}, error) {
+	project, prompt := stringArg(args, "project"), stringArg(args, "prompt")
+	if project == "" || prompt == "" {
+		return nil, fmt.Errorf("project and prompt are required")
+	}
+	req := map[string]interface{}{"initialPrompt": prompt}
+	if v := stringArg(args, "displayName"); v != "" {
+		req["displayName"] = v
+	}
+	if v := stringArg(args, "model"); v != "" {
+		req["llmSettings"] = map[string]interface{}{"model": v}
+	}
+	if v, ok := args["interactive"].(bool); ok {
+		req["interactive"] = v
+	}
+	if repos, ok := args["repos"].([]interface{}); ok {
+		out := make([]map[string]interface{}, 0, len(repos))
+		for _, r := range repos {
+			m, _ := r.(map[string]interface{})
+			u, _ := m["url"].(string)
+			if strings.TrimSpace(u) == "" {
+				return nil, fmt.Errorf("each repo needs a url")
+			}
+			repo := map[string]interface{}{"url": strings.TrimSpace(u)}
+			if b, _ := m["branch"].(string); strings.TrimSpace(b) != "" {
+				repo["branch"] = strings.TrimSpace(b)
+			}
+			out = append(out, repo)
+		}
+		req["repos"] = out
+	}
+	return callAPI(ctx, d, http.MethodPost, fmt.Sprintf("/api/projects/%s/agentic-sessions", url.PathEscape(project)), req)
+}
+
+func sendMessageTool(ctx context.Context, d Dispatcher, args map[string]interface{}) (interface{}, error) {
+	project, session, err := projectAndSession(args)
+	if err != nil {
+		return nil, err
+	}
+	message := stringArg(args, "message")
+	if message == "" {
+		return nil, fmt.Errorf("message is required")
+	}
+	input := map[string]interface{}{
+		"threadId": session,
+		"messages": []map[string]interface{}{{"id": uuid.New().String(), "role": "user", "content": message}},
+	}
+	return callAPI(ctx, d, http.MethodPost, sessionPath(project, session)+"/agui/run", input)
+}
+
+func searchRunsTool(ctx context.Context, d Dispatcher, args map[string]interface{}) (interface{}, error) {
+	project := stringArg(args, "project")
+	if project == "" {
+		return nil, fmt.Errorf("project is required")
+	}
+	status := stringArg(args, "status")
+	var since time.Time
+	if v := stringArg(args, "since"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return nil, fmt.Errorf("since must be an RFC3339 timestamp")
+		}
+		since = t
+	}
+
+	sessions := []string{}
+	if s := stringArg(args, "session"); s != "" {
+		sessions = append(sessions, s)
+	} else {
+		out, err := listSessionsTool(ctx, d, map[string]interface{}{"project": project, "limit": float64(maxRunSearchSessions)})
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range out.(map[string]interface{})["sessions"].([]map[string]interface{}) {
+			if name, ok := s["name"].(string); ok {
+				sessions = append(sessions, name)
+			}
+		}
+	}
+
+	runs := []map[string]interface{}{}
+	for _, session := range sessions {
+		out, err := callAPI(ctx, d, http.MethodGet, sessionPath(project, session)+"/agui/runs", nil)
+		if err != nil {
+			return nil, fmt.Errorf("session %s: %w", session, err)
+		}
+		resp, _ := out.(map[string]interface{})
+		items, _ := resp["runs"].([]interface{})
+		for _, item := range items {
+			run, _ := item.(map[string]interface{})
+			if run == nil {
+				continue
+			}
+			if status != "" && run["status"] != status {
+				continue
+			}
+			if !since.IsZero() {
+				started, _ := run["startedAt"].(string)
+				t, err := time.Parse(time.RFC3339, started)
+				if err != nil || t.Before(since) {
+					continue
+				}
+			}
+			runs = append(runs, run)
+		}
+	}
+	sort.Slice(runs, func(a, b int) bool {
+		sa, _ := runs[a]["startedAt"].(string)
+		sb, _ := runs[b]["startedAt"].(string)
+		return sa > sb
+	})
+	return map[string]interface{}{"runs": runs, "sessionsSearched": len(sessions)}, nil
+}
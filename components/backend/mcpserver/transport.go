@@ -0,0 +1,258 @@
+package mcpserver
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"ambient-code-backend/logging"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// maxMessageBytes bounds a single JSON-RPC message
+const maxMessageBytes = 1 << 20
+
+// Dispatcher performs a backend API call on behalf of the MCP caller
+type Dispatcher interface {
+	Do(ctx context.Context, method, path string, body []byte) (int, []byte, error)
+}
+
+// identityHeaders are forwarded from the MCP request to in-process API calls so they
+// run with the caller's token and identity
+var identityHeaders = []string{
+	"Authorization",
+	"X-Forwarded-Access-Token",
+	"X-Forwarded-User",
+	"X-Forwarded-Preferred-Username",
+	"X-Forwarded-Email",
+	"X-Forwarded-Groups",
+}
+
+// localDispatcher serves API calls through the backend's own router
+type localDispatcher struct {
+	handler http.Handler
+	header  http.Header
+}
+
+func (d *localDispatcher) Do(ctx context.Context, method, path string, body []byte) (int, []byte, error) {
+	req, err := http.NewRequestWithContext(ctx, method, path, bytes.NewReader(body))
+	if err != nil {
+		return 0, nil, err
+	}
+	for _, h := range identityHeaders {
+		if v := d.header.Get(h); v != "" {
+			req.Header.Set(h, v)
+		}
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	rec := httptest.NewRecorder()
+	d.handler.ServeHTTP(rec, req)
+	return rec.Code, rec.Body.Bytes(), nil
+}
+
+// RemoteDispatcher calls a remote backend API with a bearer token (stdio mode)
+type RemoteDispatcher struct {
+	BaseURL string
+	Token   string
+	Client  *http.Client
+}
+
+func (d *RemoteDispatcher) Do(ctx context.Context, method, path string, body []byte) (int, []byte, error) {
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(d.BaseURL, "/")+path, bytes.NewReader(body))
+	if err != nil {
+		return 0, nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+d.Token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	client := d.Client
+	if client == nil {
+		client = &http.Client{Timeout: 60 * time.Second}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer resp.Body.Close()
+	b, err := io.ReadAll(io.LimitReader(resp.Body, 10<<20))
+	return resp.StatusCode, b, err
+}
+
+// HTTPHandlers serves the MCP server over HTTP, dispatching tool calls to router
+type HTTPHandlers struct {
+	server *Server
+	router http.Handler
+
+	mu       sync.Mutex
+	sessions map[string]*sseSession // SSE session ID -> session
+}
+
+// sseSession is an open SSE stream and the caller who opened it
+type sseSession struct {
+	out    chan []byte // outbound messages
+	caller string
+}
+
+// NewHTTPHandlers returns MCP HTTP handlers backed by the given API router
+func NewHTTPHandlers(server *Server, router http.Handler) *HTTPHandlers {
+	return &HTTPHandlers{server: server, router: router, sessions: map[string]*sseSession{}}
+}
+
+func hasCallerToken(c *gin.Context) bool {
+	return strings.TrimSpace(c.GetHeader("Authorization")) != "" || strings.TrimSpace(c.GetHeader("X-Forwarded-Access-Token")) != ""
+}
+
+// callerIdentity identifies the caller of an SSE transport request: the authenticated
+// user, or a hash of their token when the request has no authenticated user
+func callerIdentity(c *gin.Context) string {
+	if user := c.GetString("userID"); user != "" {
+		return "user:" + user
+	}
+	token := strings.TrimSpace(c.GetHeader("Authorization"))
+	if token == "" {
+		token = strings.TrimSpace(c.GetHeader("X-Forwarded-Access-Token"))
+	}
+	sum := sha256.Sum256([]byte(token))
+	return "token:" + hex.EncodeToString(sum[:])
+}
+
+func readMessage(c *gin.Context) ([]byte, bool) {
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxMessageBytes+1))
+	if err != nil || len(body) > maxMessageBytes {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid or oversized message"})
+		return nil, false
+	}
+	return body, true
+}
+
+// Streamable handles POST /api/mcp (MCP streamable HTTP transport, JSON responses)
+func (h *HTTPHandlers) Streamable(c *gin.Context) {
+	if !hasCallerToken(c) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing token"})
+		return
+	}
+	body, ok := readMessage(c)
+	if !ok {
+		return
+	}
+	d := &localDispatcher{handler: h.router, header: c.Request.Header.Clone()}
+	resp := h.server.Handle(c.Request.Context(), d, body)
+	if resp == nil {
+		c.Status(http.StatusAccepted)
+		return
+	}
+	c.Data(http.StatusOK, "application/json", resp)
+}
+
+// SSE handles GET /api/mcp/sse (MCP HTTP+SSE transport). It announces the message
+// endpoint and streams responses to messages posted there.
+func (h *HTTPHandlers) SSE(c *gin.Context) {
+	if !hasCallerToken(c) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing token"})
+		return
+	}
+	id := uuid.New().String()
+	out := make(chan []byte, 16)
+	h.mu.Lock()
+	h.sessions[id] = &sseSession{out: out, caller: callerIdentity(c)}
+	h.mu.Unlock()
+	defer func() {
+		h.mu.Lock()
+		delete(h.sessions, id)
+		h.mu.Unlock()
+	}()
+
+	c.Writer.Header().Set("Content-Type", "text/event-stream")
+	c.Writer.Header().Set("Cache-Control", "no-cache")
+	c.Writer.Header().Set("Connection", "keep-alive")
+	c.Writer.Header().Set("X-Accel-Buffering", "no")
+	fmt.Fprintf(c.Writer, "event: endpoint\ndata: %s?sessionId=%s\n\n", strings.TrimSuffix(c.Request.URL.Path, "/sse")+"/messages", id)
+	c.Writer.Flush()
+
+	keepalive := time.NewTicker(30 * time.Second)
+	defer keepalive.Stop()
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case msg := <-out:
+			fmt.Fprintf(c.Writer, "event: message\ndata: %s\n\n", msg)
+			c.Writer.Flush()
+		case <-keepalive.C:
+			fmt.Fprint(c.Writer, ": keepalive\n\n")
+			c.Writer.Flush()
+		}
+	}
+}
+
+// Messages handles POST /api/mcp/messages?sessionId= for the SSE transport. Only the
+// caller who opened the session can post to it; its responses go to their stream.
+func (h *HTTPHandlers) Messages(c *gin.Context) {
+	if !hasCallerToken(c) {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing token"})
+		return
+	}
+	h.mu.Lock()
+	session, ok := h.sessions[c.Query("sessionId")]
+	h.mu.Unlock()
+	if ok && session.caller != callerIdentity(c) {
+		logging.Warnf(c, "MCP SSE: rejected message for a session opened by another caller")
+		ok = false
+	}
+	if !ok {
+		// Another caller's session is reported as unknown, so IDs can't be probed
+		c.JSON(http.StatusNotFound, gin.H{"error": "unknown MCP session"})
+		return
+	}
+	out := session.out
+	body, ok := readMessage(c)
+	if !ok {
+		return
+	}
+	// Each message is dispatched with the credentials it was posted with
+	d := &localDispatcher{handler: h.router, header: c.Request.Header.Clone()}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	go func() {
+		defer cancel()
+		if resp := h.server.Handle(ctx, d, body); resp != nil {
+			select {
+			case out <- resp:
+			case <-ctx.Done():
+				logging.Warnf(ctx, "MCP SSE: dropped response for closed session")
+			}
+		}
+	}()
+	c.Status(http.StatusAccepted)
+}
+
+// ServeStdio runs the MCP server over newline-delimited JSON-RPC on r/w
+func ServeStdio(ctx context.Context, server *Server, d Dispatcher, r io.Reader, w io.Writer) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxMessageBytes)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		if resp := server.Handle(ctx, d, line); resp != nil {
+			if _, err := w.Write(append(resp, '\n')); err != nil {
+				return err
+			}
+		}
+	}
+	return scanner.Err()
+}
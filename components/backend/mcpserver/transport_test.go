@@ -0,0 +1,51 @@
+package mcpserver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestMessagesOnlyFromSessionOpener(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	h := NewHTTPHandlers(NewServer("test"), http.NotFoundHandler())
+	h.sessions["s1"] = &sseSession{out: make(chan []byte, 1), caller: "user:alice"}
+
+	request := func(userID, token string) (*gin.Context, *httptest.ResponseRecorder) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPost, "/api/mcp/messages?sessionId=s1",
+			strings.NewReader(`{"jsonrpc":"2.0","method":"notifications/initialized"}`))
+		c.Request.Header.Set("Authorization", "Bearer "+token)
+		if userID != "" {
+			c.Set("userID", userID)
+		}
+		return c, w
+	}
+	post := func(userID, token string) int {
+		c, w := request(userID, token)
+		h.Messages(c)
+		c.Writer.WriteHeaderNow()
+		return w.Code
+	}
+
+	if code := post("bob", "bob-token"); code != http.StatusNotFound {
+		t.Errorf("another user's post = %d, want 404", code)
+	}
+	if code := post("alice", "alice-token"); code != http.StatusAccepted {
+		t.Errorf("opener's post = %d, want 202", code)
+	}
+
+	// Without an authenticated user the session is bound to the token
+	opener, _ := request("", "alice-token")
+	h.sessions["s1"].caller = callerIdentity(opener)
+	if code := post("", "other-token"); code != http.StatusNotFound {
+		t.Errorf("post with another token = %d, want 404", code)
+	}
+	if code := post("", "alice-token"); code != http.StatusAccepted {
+		t.Errorf("post with the opener's token = %d, want 202", code)
+	}
+}
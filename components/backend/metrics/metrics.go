@@ -0,0 +1,229 @@
+// Package metrics defines the backend's Prometheus metrics for credential and
+// integration subsystems, served on /metrics. They show when upstream providers
+// (GitHub rate limits, Google token refresh, GitLab, Jira, Linear) start failing sessions.
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// Result label values
+const (
+	ResultSuccess       = "success"
+	ResultInvalid       = "invalid"
+	ResultNotConfigured = "not_configured"
+	ResultDenied        = "denied"
+	ResultError         = "error"
+)
+
+var (
+	credentialFetches = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "ambient_backend_credential_fetches_total",
+			Help: "Runner credential fetches, by provider and result.",
+		},
+		[]string{"provider", "result"},
+	)
+
+	credentialValidations = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "ambient_backend_credential_validations_total",
+			Help: "Credential validation calls against upstream providers, by provider and result.",
+		},
+		[]string{"provider", "result"},
+	)
+
+	credentialRefreshes = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "ambient_backend_credential_refreshes_total",
+			Help: "Token refreshes and GitHub App installation token mints, by provider and result.",
+		},
+		[]string{"provider", "result"},
+	)
+
+	upstreamRequestDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "ambient_backend_upstream_request_duration_seconds",
+			Help:    "Latency of requests to integration providers, by provider, operation and status code (\"error\" if no response).",
+			Buckets: prometheus.ExponentialBuckets(0.05, 2, 10), // 50ms .. ~25s
+		},
+		[]string{"provider", "operation", "code"},
+	)
+
+	upstreamRateLimited = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "ambient_backend_upstream_rate_limited_total",
+			Help: "Requests to integration providers rejected by rate limiting, by provider.",
+		},
+		[]string{"provider"},
+	)
+
+	upstreamRateLimitRemaining = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "ambient_backend_upstream_rate_limit_remaining",
+			Help: "Requests left in the provider's current rate limit window, from the last response that reported it.",
+		},
+		[]string{"provider"},
+	)
+
+	workTasksRejected = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "ambient_backend_work_tasks_rejected_total",
+			Help: "Background tasks dropped because their worker pool's queue was full, by pool.",
+		},
+		[]string{"pool"},
+	)
+
+	aguiConformanceViolations = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "ambient_backend_agui_conformance_violations_total",
+			Help: "Runner events violating the AG-UI spec in conformance mode, by event type.",
+		},
+		[]string{"type"},
+	)
+
+	sessionsIdlePaused = prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "ambient_backend_sessions_idle_paused_total",
+			Help: "Sessions whose runner was paused after the idle timeout without activity.",
+		},
+	)
+
+	tokenQuotaRejections = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "ambient_backend_token_quota_rejections_total",
+			Help: "Runs refused because a monthly token quota was used up, by scope (project, user).",
+		},
+		[]string{"scope"},
+	)
+	runQueueOutcomes = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "ambient_backend_run_queue_total",
+			Help: "Runs held by a project's run limit, by outcome (queued, preempted, rejected).",
+		},
+		[]string{"outcome"},
+	)
+)
+
+func init() {
+	prometheus.MustRegister(credentialFetches, credentialValidations, credentialRefreshes,
+		upstreamRequestDuration, upstreamRateLimited, upstreamRateLimitRemaining, workTasksRejected, aguiConformanceViolations,
+		sessionsIdlePaused, tokenQuotaRejections, runQueueOutcomes)
+}
+
+// Handler serves the metrics in the Prometheus exposition format
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// ObserveCredentialFetch records a runner credential fetch
+func ObserveCredentialFetch(provider, result string) {
+	credentialFetches.WithLabelValues(provider, result).Inc()
+}
+
+// ObserveValidation records a credential validation. err means the provider
+// could not be asked; otherwise valid decides between success and invalid.
+func ObserveValidation(provider string, valid bool, err error) {
+	result := ResultSuccess
+	switch {
+	case err != nil:
+		result = ResultError
+	case !valid:
+		result = ResultInvalid
+	}
+	credentialValidations.WithLabelValues(provider, result).Inc()
+}
+
+// ObserveRefresh records a token refresh or mint
+func ObserveRefresh(provider string, err error) {
+	result := ResultSuccess
+	if err != nil {
+		result = ResultError
+	}
+	credentialRefreshes.WithLabelValues(provider, result).Inc()
+}
+
+// RegisterWorkQueue exports depth, the number of tasks queued in a worker pool, as
+// ambient_backend_work_queue_depth{pool=...}. Registering a pool name again is a no-op.
+func RegisterWorkQueue(pool string, depth func() int) {
+	gauge := prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+		Name:        "ambient_backend_work_queue_depth",
+		Help:        "Background tasks waiting in a worker pool's queues, by pool.",
+		ConstLabels: prometheus.Labels{"pool": pool},
+	}, func() float64 { return float64(depth()) })
+	_ = prometheus.Register(gauge)
+}
+
+// ObserveWorkRejected records a task a worker pool dropped
+func ObserveWorkRejected(pool string) {
+	workTasksRejected.WithLabelValues(pool).Inc()
+}
+
+// ObserveAGUIConformanceViolation records a runner event that violated the AG-UI spec
+func ObserveAGUIConformanceViolation(eventType string) {
+	aguiConformanceViolations.WithLabelValues(eventType).Inc()
+}
+
+// ObserveSessionIdlePaused records a session paused for inactivity
+func ObserveSessionIdlePaused() {
+	sessionsIdlePaused.Inc()
+}
+
+// ObserveTokenQuotaRejection records a run refused by the project's or a user's token quota
+func ObserveTokenQuotaRejection(scope string) {
+	tokenQuotaRejections.WithLabelValues(scope).Inc()
+}
+
+// ObserveRunQueue records a run queued behind the project's run limit, preempted from
+// the queue, or rejected because the queue was full
+func ObserveRunQueue(outcome string) {
+	runQueueOutcomes.WithLabelValues(outcome).Inc()
+}
+
+// Transport wraps base (http.DefaultTransport if nil) to record latency, status
+// and rate limit state of requests to provider
+func Transport(provider, operation string, base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &instrumentedTransport{provider: provider, operation: operation, base: base}
+}
+
+type instrumentedTransport struct {
+	provider  string
+	operation string
+	base      http.RoundTripper
+}
+
+func (t *instrumentedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	code := "error"
+	if err == nil {
+		code = strconv.Itoa(resp.StatusCode)
+		t.observeRateLimit(resp)
+	}
+	upstreamRequestDuration.WithLabelValues(t.provider, t.operation, code).Observe(time.Since(start).Seconds())
+	return resp, err
+}
+
+// observeRateLimit reads the GitHub (X-RateLimit-Remaining) or GitLab
+// (RateLimit-Remaining) headers and counts rate limited responses
+func (t *instrumentedTransport) observeRateLimit(resp *http.Response) {
+	remaining := resp.Header.Get("X-RateLimit-Remaining")
+	if remaining == "" {
+		remaining = resp.Header.Get("RateLimit-Remaining")
+	}
+	if n, err := strconv.Atoi(remaining); err == nil {
+		upstreamRateLimitRemaining.WithLabelValues(t.provider).Set(float64(n))
+	}
+	// GitHub answers 403 with no remaining requests when the primary limit is hit
+	if resp.StatusCode == http.StatusTooManyRequests || (resp.StatusCode == http.StatusForbidden && remaining == "0") {
+		upstreamRateLimited.WithLabelValues(t.provider).Inc()
+	}
+}
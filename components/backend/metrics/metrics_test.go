@@ -0,0 +1,69 @@
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestTransportRecordsLatencyAndRateLimits(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/limited" {
+			w.Header().Set("X-RateLimit-Remaining", "0")
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Header().Set("X-RateLimit-Remaining", "4999")
+	}))
+	defer srv.Close()
+
+	before := testutil.CollectAndCount(upstreamRequestDuration)
+	client := &http.Client{Transport: Transport("test-github", "api", nil)}
+	for _, path := range []string{"/ok", "/limited"} {
+		resp, err := client.Get(srv.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}
+
+	if n := testutil.CollectAndCount(upstreamRequestDuration) - before; n != 2 {
+		t.Errorf("new duration series = %d, want 2 (codes 200 and 403)", n)
+	}
+	if v := testutil.ToFloat64(upstreamRateLimited.WithLabelValues("test-github")); v != 1 {
+		t.Errorf("rate limited = %v, want 1", v)
+	}
+	if v := testutil.ToFloat64(upstreamRateLimitRemaining.WithLabelValues("test-github")); v != 0 {
+		t.Errorf("remaining = %v, want 0", v)
+	}
+}
+
+func TestTransportRecordsConnectionErrors(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	url := srv.URL
+	srv.Close()
+
+	before := testutil.CollectAndCount(upstreamRequestDuration)
+	client := &http.Client{Transport: Transport("test-down", "api", nil)}
+	if _, err := client.Get(url); err == nil {
+		t.Fatal("expected connection error")
+	}
+	if n := testutil.CollectAndCount(upstreamRequestDuration) - before; n != 1 {
+		t.Errorf("new duration series = %d, want 1 (code error)", n)
+	}
+}
+
+func TestObserveValidationResults(t *testing.T) {
+	ObserveValidation("test-jira", true, nil)
+	ObserveValidation("test-jira", false, nil)
+	ObserveValidation("test-jira", false, errors.New("timeout"))
+
+	for _, result := range []string{ResultSuccess, ResultInvalid, ResultError} {
+		if v := testutil.ToFloat64(credentialValidations.WithLabelValues("test-jira", result)); v != 1 {
+			t.Errorf("%s = %v, want 1", result, v)
+		}
+	}
+}
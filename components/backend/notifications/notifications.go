@@ -0,0 +1,174 @@
+// Package notifications dispatches project events (run finished or errored, approval
+// pending, budget exceeded, session paused) to Slack incoming webhooks according to
+// per-project rules.
+package notifications
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"ambient-code-backend/logging"
+)
+
+// Event types a rule can subscribe to
+const (
+	EventRunFinished     = "run.finished"
+	EventRunErrored      = "run.errored"
+	EventApprovalPending = "approval.pending"
+	EventBudgetExceeded  = "budget.exceeded"
+	EventSessionPaused   = "session.paused"
+)
+
+// EventTypes lists the valid event types
+var EventTypes = []string{EventRunFinished, EventRunErrored, EventApprovalPending, EventBudgetExceeded, EventSessionPaused}
+
+// queueSize bounds events waiting for delivery; beyond it events are dropped
+const queueSize = 500
+
+// deliveryTimeout bounds rule lookup and delivery of one event
+const deliveryTimeout = 30 * time.Second
+
+// Event is something a project may want to be told about
+type Event struct {
+	Type        string
+	Project     string
+	Session     string
+	DisplayName string
+	RunID       string
+	// Detail is shown under the title, e.g. the error of a failed run
+	Detail string
+	// URL links back to the session in the UI
+	URL string
+}
+
+// Rule sends events of the listed types to one of the project's named webhooks
+type Rule struct {
+	Name    string   `json:"name,omitempty"`
+	Events  []string `json:"events"`
+	Webhook string   `json:"webhook"`
+	// Mention is prepended to the message, e.g. "<!here>" or "<@U012AB3CD>"
+	Mention string `json:"mention,omitempty"`
+}
+
+// Matches reports whether the rule subscribes to eventType
+func (r Rule) Matches(eventType string) bool {
+	for _, e := range r.Events {
+		if e == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateRule checks a rule before it is stored
+func ValidateRule(r Rule) error {
+	if len(r.Events) == 0 {
+		return fmt.Errorf("rule must list at least one event")
+	}
+	for _, e := range r.Events {
+		if !validEventType(e) {
+			return fmt.Errorf("unknown event %q (must be one of: %s)", e, strings.Join(EventTypes, ", "))
+		}
+	}
+	if r.Mention != "" && !mentionPattern.MatchString(r.Mention) {
+		return fmt.Errorf("mention must be a Slack mention such as <!here>, <!channel>, <@U012AB3CD> or <!subteam^S012AB3CD>")
+	}
+	return ValidateWebhookName(r.Webhook)
+}
+
+// mentionPattern matches the Slack mention syntaxes a rule may use
+var mentionPattern = regexp.MustCompile(`^<(!here|!channel|@[UW][A-Z0-9]+|!subteam\^S[A-Z0-9]+)>$`)
+
+func validEventType(e string) bool {
+	for _, t := range EventTypes {
+		if e == t {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateWebhookName checks a webhook name is usable as a Secret data key
+func ValidateWebhookName(name string) error {
+	if name == "" || len(name) > 63 {
+		return fmt.Errorf("webhook name must be 1-63 characters")
+	}
+	for _, r := range name {
+		if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-' || r == '_') {
+			return fmt.Errorf("webhook name %q may only contain lowercase letters, digits, '-' and '_'", name)
+		}
+	}
+	return nil
+}
+
+// Source looks up a project's rules and webhooks
+type Source interface {
+	// Rules returns the project's notification rules
+	Rules(ctx context.Context, project string) ([]Rule, error)
+	// WebhookURL resolves a webhook name to its Slack incoming webhook URL
+	WebhookURL(ctx context.Context, project, name string) (string, error)
+}
+
+// Dispatcher delivers events from a background goroutine so callers never block on Slack
+type Dispatcher struct {
+	source Source
+	client *http.Client
+	queue  chan Event
+}
+
+// NewDispatcher starts a dispatcher resolving rules and webhooks through source
+func NewDispatcher(source Source) *Dispatcher {
+	d := &Dispatcher{source: source, client: &http.Client{Timeout: 10 * time.Second}, queue: make(chan Event, queueSize)}
+	go d.run()
+	return d
+}
+
+// Notify queues e for delivery. Events are dropped if the queue is full.
+func (d *Dispatcher) Notify(e Event) {
+	if d == nil {
+		return
+	}
+	select {
+	case d.queue <- e:
+	default:
+		logging.Warnf(context.Background(), "notifications: queue full, dropped %s for %s/%s", e.Type, e.Project, e.Session)
+	}
+}
+
+func (d *Dispatcher) run() {
+	for e := range d.queue {
+		ctx, cancel := context.WithTimeout(context.Background(), deliveryTimeout)
+		d.deliver(ctx, e)
+		cancel()
+	}
+}
+
+// deliver posts e once per matching rule. Rules pointing at the same webhook with
+// the same mention are sent once.
+func (d *Dispatcher) deliver(ctx context.Context, e Event) {
+	rules, err := d.source.Rules(ctx, e.Project)
+	if err != nil {
+		logging.Warnf(ctx, "notifications: failed to load rules for %s: %v", e.Project, err)
+		return
+	}
+	sent := map[string]bool{}
+	for _, r := range rules {
+		key := r.Webhook + "\x00" + r.Mention
+		if !r.Matches(e.Type) || sent[key] {
+			continue
+		}
+		sent[key] = true
+		url, err := d.source.WebhookURL(ctx, e.Project, r.Webhook)
+		if err != nil {
+			logging.Warnf(ctx, "notifications: %s/%s webhook %q unavailable: %v", e.Project, e.Session, r.Webhook, err)
+			continue
+		}
+		if err := PostSlack(ctx, d.client, url, SlackMessage(e, r.Mention)); err != nil {
+			logging.Warnf(ctx, "notifications: %s for %s/%s to webhook %q failed: %v", e.Type, e.Project, e.Session, r.Webhook, err)
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package notifications
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type fakeSource struct {
+	rules    []Rule
+	webhooks map[string]string
+}
+
+func (s fakeSource) Rules(context.Context, string) ([]Rule, error) { return s.rules, nil }
+
+func (s fakeSource) WebhookURL(_ context.Context, _, name string) (string, error) {
+	if url, ok := s.webhooks[name]; ok {
+		return url, nil
+	}
+	return "", fmt.Errorf("webhook not configured")
+}
+
+func TestValidateRule(t *testing.T) {
+	valid := Rule{Events: []string{EventRunErrored, EventBudgetExceeded}, Webhook: "team-alerts", Mention: "<!here>"}
+	if err := ValidateRule(valid); err != nil {
+		t.Errorf("ValidateRule(valid) = %v", err)
+	}
+	for name, r := range map[string]Rule{
+		"no events":     {Webhook: "a"},
+		"unknown event": {Events: []string{"run.started"}, Webhook: "a"},
+		"bad webhook":   {Events: []string{EventRunFinished}, Webhook: "Team Alerts"},
+		"bad mention":   {Events: []string{EventRunFinished}, Webhook: "a", Mention: "@everyone"},
+	} {
+		if ValidateRule(r) == nil {
+			t.Errorf("ValidateRule(%s) should fail", name)
+		}
+	}
+}
+
+func TestValidateWebhookURL(t *testing.T) {
+	if err := ValidateWebhookURL("https://hooks.slack.com/services/T000/B000/XXXX"); err != nil {
+		t.Errorf("slack URL rejected: %v", err)
+	}
+	for _, u := range []string{"http://hooks.slack.com/services/T000", "https://example.com/services/T000", "https://hooks.slack.com/", "not a url"} {
+		if ValidateWebhookURL(u) == nil {
+			t.Errorf("%q should be rejected", u)
+		}
+	}
+}
+
+func TestDispatcherDeliversMatchingRules(t *testing.T) {
+	var mu sync.Mutex
+	var got []map[string]interface{}
+	received := make(chan struct{}, 10)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var msg map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&msg); err != nil {
+			t.Errorf("decode: %v", err)
+		}
+		mu.Lock()
+		got = append(got, msg)
+		mu.Unlock()
+		received <- struct{}{}
+	}))
+	defer srv.Close()
+
+	d := NewDispatcher(fakeSource{
+		rules: []Rule{
+			{Events: []string{EventRunErrored}, Webhook: "alerts", Mention: "<!here>"},
+			{Events: []string{EventRunErrored, EventRunFinished}, Webhook: "alerts", Mention: "<!here>"}, // duplicate target
+			{Events: []string{EventRunFinished}, Webhook: "alerts"},
+			{Events: []string{EventRunErrored}, Webhook: "missing"},
+		},
+		webhooks: map[string]string{"alerts": srv.URL},
+	})
+	d.Notify(Event{Type: EventRunErrored, Project: "team-a", Session: "s1", DisplayName: "Fix tests", RunID: "r1", Detail: "boom", URL: "https://ui/s1"})
+
+	select {
+	case <-received:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no message delivered")
+	}
+	time.Sleep(50 * time.Millisecond) // let any unexpected duplicate arrive
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(got) != 1 {
+		t.Fatalf("delivered %d messages, want 1", len(got))
+	}
+	text, _ := got[0]["text"].(string)
+	if !strings.HasPrefix(text, "<!here> ") || !strings.Contains(text, "Fix tests (s1)") {
+		t.Errorf("text = %q", text)
+	}
+	blocks, _ := got[0]["blocks"].([]interface{})
+	if len(blocks) != 3 {
+		t.Errorf("blocks = %d, want section, detail and button", len(blocks))
+	}
+}
+
+func TestPostSlackReportsRejection(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, "invalid_token")
+	}))
+	defer srv.Close()
+
+	err := PostSlack(context.Background(), srv.Client(), srv.URL, SlackMessage(Event{Type: EventRunFinished}, ""))
+	if err == nil || !strings.Contains(err.Error(), "invalid_token") {
+		t.Errorf("PostSlack = %v, want invalid_token error", err)
+	}
+}
@@ -0,0 +1,117 @@
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// slackWebhookHosts are the hosts Slack serves incoming webhooks from. Webhook URLs
+// are restricted to them so project admins cannot make the backend POST elsewhere.
+var slackWebhookHosts = map[string]bool{
+	"hooks.slack.com":     true,
+	"hooks.slack-gov.com": true,
+}
+
+// ValidateWebhookURL checks u is a Slack incoming webhook URL
+func ValidateWebhookURL(u string) error {
+	parsed, err := url.Parse(u)
+	if err != nil || parsed.Scheme != "https" || !slackWebhookHosts[parsed.Host] || len(parsed.Path) <= 1 {
+		return fmt.Errorf("webhook URL must be a Slack incoming webhook (https://hooks.slack.com/services/...)")
+	}
+	return nil
+}
+
+// eventTitles are the message headlines per event type
+var eventTitles = map[string]string{
+	EventRunFinished:     ":white_check_mark: Run finished",
+	EventRunErrored:      ":x: Run failed",
+	EventApprovalPending: ":raised_hand: Approval needed",
+	EventBudgetExceeded:  ":warning: Budget exceeded",
+	EventSessionPaused:   ":zzz: Session paused",
+}
+
+// SlackMessage builds a Block Kit message for e, with a button linking to the session
+func SlackMessage(e Event, mention string) map[string]interface{} {
+	title := eventTitles[e.Type]
+	if title == "" {
+		title = e.Type
+	}
+	session := e.Session
+	if e.DisplayName != "" {
+		session = fmt.Sprintf("%s (%s)", e.DisplayName, e.Session)
+	}
+	text := fmt.Sprintf("%s: %s in %s", title, session, e.Project)
+	if mention != "" {
+		text = mention + " " + text
+	}
+
+	body := fmt.Sprintf("*%s*\n*Session:* %s\n*Project:* %s", title, session, e.Project)
+	if mention != "" {
+		body = mention + " " + body
+	}
+	if e.RunID != "" {
+		body += fmt.Sprintf("\n*Run:* `%s`", e.RunID)
+	}
+	blocks := []interface{}{
+		map[string]interface{}{"type": "section", "text": map[string]interface{}{"type": "mrkdwn", "text": body}},
+	}
+	if e.Detail != "" {
+		blocks = append(blocks, map[string]interface{}{
+			"type": "context",
+			"elements": []interface{}{
+				map[string]interface{}{"type": "mrkdwn", "text": truncate(e.Detail, 2000)},
+			},
+		})
+	}
+	if e.URL != "" {
+		blocks = append(blocks, map[string]interface{}{
+			"type": "actions",
+			"elements": []interface{}{
+				map[string]interface{}{
+					"type": "button",
+					"text": map[string]interface{}{"type": "plain_text", "text": "Open session"},
+					"url":  e.URL,
+				},
+			},
+		})
+	}
+	// text is the fallback shown in notifications and clients without blocks
+	return map[string]interface{}{"text": text, "blocks": blocks}
+}
+
+// PostSlack posts msg to a Slack incoming webhook
+func PostSlack(ctx context.Context, client *http.Client, webhookURL string, msg map[string]interface{}) error {
+	b, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhookURL, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		// Slack explains failures in a short plain-text body, e.g. "invalid_token"
+		reason, _ := io.ReadAll(io.LimitReader(resp.Body, 256))
+		return fmt.Errorf("slack returned %d: %s", resp.StatusCode, reason)
+	}
+	return nil
+}
+
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n]) + "…"
+}
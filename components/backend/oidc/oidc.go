@@ -0,0 +1,354 @@
+// Package oidc verifies ID tokens from a corporate OpenID Connect provider so the
+// backend can serve users who do not sign in through the OpenShift OAuth proxy.
+// Verified identities are mapped to Kubernetes users and impersonated.
+package oidc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Config describes the identity provider and how its claims map to Kubernetes identities
+type Config struct {
+	IssuerURL string
+	Audience  string
+	// JWKSURL skips discovery when set
+	JWKSURL string
+	// UsernameClaim and GroupsClaim name the claims holding the user and groups
+	UsernameClaim string
+	GroupsClaim   string
+	// UsernamePrefix and GroupsPrefix are prepended to the mapped Kubernetes identity,
+	// matching the API server's --oidc-username-prefix / --oidc-groups-prefix
+	UsernamePrefix string
+	GroupsPrefix   string
+}
+
+// ConfigFromEnv reads OIDC_ISSUER_URL, OIDC_AUDIENCE, OIDC_JWKS_URL, OIDC_USERNAME_CLAIM
+// (default "email"), OIDC_GROUPS_CLAIM (default "groups"), OIDC_USERNAME_PREFIX and
+// OIDC_GROUPS_PREFIX. It returns nil when OIDC_ISSUER_URL is unset.
+func ConfigFromEnv() (*Config, error) {
+	issuer := strings.TrimSpace(os.Getenv("OIDC_ISSUER_URL"))
+	if issuer == "" {
+		return nil, nil
+	}
+	cfg := &Config{
+		IssuerURL:      issuer,
+		Audience:       strings.TrimSpace(os.Getenv("OIDC_AUDIENCE")),
+		JWKSURL:        strings.TrimSpace(os.Getenv("OIDC_JWKS_URL")),
+		UsernameClaim:  os.Getenv("OIDC_USERNAME_CLAIM"),
+		GroupsClaim:    os.Getenv("OIDC_GROUPS_CLAIM"),
+		UsernamePrefix: os.Getenv("OIDC_USERNAME_PREFIX"),
+		GroupsPrefix:   os.Getenv("OIDC_GROUPS_PREFIX"),
+	}
+	if cfg.Audience == "" {
+		return nil, fmt.Errorf("OIDC_AUDIENCE is required when OIDC_ISSUER_URL is set")
+	}
+	if !strings.HasPrefix(cfg.IssuerURL, "https://") {
+		return nil, fmt.Errorf("OIDC_ISSUER_URL must use https")
+	}
+	if cfg.UsernameClaim == "" {
+		cfg.UsernameClaim = "email"
+	}
+	if cfg.GroupsClaim == "" {
+		cfg.GroupsClaim = "groups"
+	}
+	return cfg, nil
+}
+
+// Identity is a verified IdP user mapped to a Kubernetes identity
+type Identity struct {
+	// Username and Groups are the Kubernetes identity to impersonate (prefixes applied)
+	Username string
+	Groups   []string
+	// Subject, Email and Name come from the token for display and auditing
+	Subject string
+	Email   string
+	Name    string
+}
+
+// ErrNotOIDCToken is returned for tokens not issued by the configured provider, which
+// should be handled by the normal Kubernetes token path
+var ErrNotOIDCToken = errors.New("token was not issued by the configured OIDC provider")
+
+// validMethods are the asymmetric algorithms accepted; HMAC and "none" are never accepted
+var validMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512"}
+
+const (
+	// jwksMinRefresh throttles key refetches triggered by unknown key IDs
+	jwksMinRefresh = time.Minute
+	// jwksMaxAge bounds how long fetched keys are trusted before a refetch
+	jwksMaxAge  = time.Hour
+	clockLeeway = 30 * time.Second
+)
+
+// Verifier checks ID tokens against the provider's published signing keys
+type Verifier struct {
+	cfg    Config
+	client *http.Client
+
+	mu        sync.Mutex
+	jwksURL   string
+	keys      map[string]interface{}
+	fetchedAt time.Time
+}
+
+// NewVerifier returns a verifier for cfg. Keys are fetched lazily on first use.
+func NewVerifier(cfg Config) *Verifier {
+	return &Verifier{cfg: cfg, client: &http.Client{Timeout: 10 * time.Second}, jwksURL: cfg.JWKSURL}
+}
+
+// IssuedBy reports whether raw looks like a JWT whose (unverified) issuer is the
+// configured provider. Only such tokens are verified; others pass through.
+func (v *Verifier) IssuedBy(raw string) bool {
+	if strings.Count(raw, ".") != 2 {
+		return false
+	}
+	claims := jwt.MapClaims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(raw, claims); err != nil {
+		return false
+	}
+	iss, _ := claims["iss"].(string)
+	return iss != "" && iss == v.cfg.IssuerURL
+}
+
+// Verify validates raw's signature, issuer, audience and expiry and maps its claims
+// to a Kubernetes identity
+func (v *Verifier) Verify(ctx context.Context, raw string) (*Identity, error) {
+	if !v.IssuedBy(raw) {
+		return nil, ErrNotOIDCToken
+	}
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(raw, claims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		return v.key(ctx, kid)
+	},
+		jwt.WithValidMethods(validMethods),
+		jwt.WithIssuer(v.cfg.IssuerURL),
+		jwt.WithAudience(v.cfg.Audience),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(clockLeeway),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("invalid token: %w", err)
+	}
+	return v.identity(claims)
+}
+
+// identity maps verified claims to a Kubernetes identity
+func (v *Verifier) identity(claims jwt.MapClaims) (*Identity, error) {
+	user, _ := claims[v.cfg.UsernameClaim].(string)
+	if user == "" {
+		return nil, fmt.Errorf("token has no %q claim", v.cfg.UsernameClaim)
+	}
+	if v.cfg.UsernameClaim == "email" {
+		// An unverified email must not become an identity (same rule as the API server)
+		if verified, ok := claims["email_verified"].(bool); ok && !verified {
+			return nil, fmt.Errorf("email %q is not verified", user)
+		}
+	}
+	id := &Identity{Username: v.cfg.UsernamePrefix + user}
+	id.Subject, _ = claims["sub"].(string)
+	id.Email, _ = claims["email"].(string)
+	id.Name, _ = claims["name"].(string)
+
+	switch groups := claims[v.cfg.GroupsClaim].(type) {
+	case string:
+		if groups != "" {
+			id.Groups = append(id.Groups, v.cfg.GroupsPrefix+groups)
+		}
+	case []interface{}:
+		for _, g := range groups {
+			if s, ok := g.(string); ok && s != "" {
+				id.Groups = append(id.Groups, v.cfg.GroupsPrefix+s)
+			}
+		}
+	}
+
+	// Never let an IdP claim impersonate a Kubernetes system identity (e.g. system:masters)
+	if strings.HasPrefix(id.Username, "system:") {
+		return nil, fmt.Errorf("username %q maps to a reserved Kubernetes identity", id.Username)
+	}
+	groups := id.Groups[:0]
+	for _, g := range id.Groups {
+		if !strings.HasPrefix(g, "system:") {
+			groups = append(groups, g)
+		}
+	}
+	id.Groups = groups
+	return id, nil
+}
+
+// key returns the signing key for kid, refetching the key set when kid is unknown
+// (key rotation) or the cached set is stale
+func (v *Verifier) key(ctx context.Context, kid string) (interface{}, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	now := time.Now()
+	stale := v.keys == nil || now.Sub(v.fetchedAt) > jwksMaxAge
+	if k, ok := v.lookup(kid); ok && !stale {
+		return k, nil
+	}
+	if stale || now.Sub(v.fetchedAt) >= jwksMinRefresh {
+		if err := v.fetchKeys(ctx); err != nil {
+			// Keep serving cached keys if the provider is briefly unreachable
+			if k, ok := v.lookup(kid); ok {
+				return k, nil
+			}
+			return nil, err
+		}
+	}
+	if k, ok := v.lookup(kid); ok {
+		return k, nil
+	}
+	return nil, fmt.Errorf("no signing key %q in provider key set", kid)
+}
+
+// lookup finds kid in the cached keys; a token without kid matches a single-key set
+func (v *Verifier) lookup(kid string) (interface{}, bool) {
+	if kid == "" && len(v.keys) == 1 {
+		for _, k := range v.keys {
+			return k, true
+		}
+	}
+	k, ok := v.keys[kid]
+	return k, ok
+}
+
+// fetchKeys resolves the JWKS URL through discovery if needed and loads the key set
+func (v *Verifier) fetchKeys(ctx context.Context) error {
+	v.fetchedAt = time.Now()
+	if v.jwksURL == "" {
+		var discovery struct {
+			Issuer  string `json:"issuer"`
+			JWKSURI string `json:"jwks_uri"`
+		}
+		if err := v.getJSON(ctx, strings.TrimSuffix(v.cfg.IssuerURL, "/")+"/.well-known/openid-configuration", &discovery); err != nil {
+			return fmt.Errorf("OIDC discovery failed: %w", err)
+		}
+		if discovery.Issuer != v.cfg.IssuerURL {
+			return fmt.Errorf("OIDC discovery returned issuer %q, expected %q", discovery.Issuer, v.cfg.IssuerURL)
+		}
+		if discovery.JWKSURI == "" {
+			return fmt.Errorf("OIDC discovery document has no jwks_uri")
+		}
+		v.jwksURL = discovery.JWKSURI
+	}
+
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := v.getJSON(ctx, v.jwksURL, &set); err != nil {
+		return fmt.Errorf("failed to fetch OIDC signing keys: %w", err)
+	}
+	keys := map[string]interface{}{}
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		if k, err := jwk.publicKey(); err == nil {
+			keys[jwk.Kid] = k
+		}
+	}
+	if len(keys) == 0 {
+		return fmt.Errorf("OIDC provider published no usable signing keys")
+	}
+	v.keys = keys
+	return nil
+}
+
+func (v *Verifier) getJSON(ctx context.Context, url string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s returned %d", url, resp.StatusCode)
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// jsonWebKey is an RSA or EC public key from a JWKS document (RFC 7517)
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+func (k jsonWebKey) publicKey() (interface{}, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() {
+			return nil, fmt.Errorf("RSA exponent too large")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, fmt.Errorf("EC key is not on curve %s", k.Crv)
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	}
+	return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return nil, fmt.Errorf("empty key component")
+	}
+	return new(big.Int).SetBytes(b), nil
+}
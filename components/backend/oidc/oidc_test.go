@@ -0,0 +1,221 @@
+package oidc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// testProvider is a minimal OIDC provider serving discovery and a JWKS document
+type testProvider struct {
+	srv  *httptest.Server
+	mu   sync.Mutex
+	keys []map[string]string
+}
+
+func newTestProvider(t *testing.T) *testProvider {
+	p := &testProvider{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{"issuer": p.srv.URL, "jwks_uri": p.srv.URL + "/keys"})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"keys": p.keys})
+	})
+	p.srv = httptest.NewTLSServer(mux)
+	t.Cleanup(p.srv.Close)
+	return p
+}
+
+func b64(b []byte) string { return base64.RawURLEncoding.EncodeToString(b) }
+
+func (p *testProvider) addRSAKey(t *testing.T, kid string) *rsa.PrivateKey {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.keys = append(p.keys, map[string]string{
+		"kty": "RSA", "kid": kid, "use": "sig",
+		"n": b64(key.N.Bytes()), "e": b64(big.NewInt(int64(key.E)).Bytes()),
+	})
+	return key
+}
+
+func (p *testProvider) addECKey(t *testing.T, kid string) *ecdsa.PrivateKey {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.keys = append(p.keys, map[string]string{
+		"kty": "EC", "kid": kid, "crv": "P-256",
+		"x": b64(key.X.FillBytes(make([]byte, 32))), "y": b64(key.Y.FillBytes(make([]byte, 32))),
+	})
+	return key
+}
+
+func (p *testProvider) verifier() *Verifier {
+	v := NewVerifier(Config{IssuerURL: p.srv.URL, Audience: "vteam", UsernameClaim: "email", GroupsClaim: "groups", UsernamePrefix: "oidc:", GroupsPrefix: "oidc:"})
+	v.client = p.srv.Client()
+	return v
+}
+
+func (p *testProvider) claims(overrides jwt.MapClaims) jwt.MapClaims {
+	claims := jwt.MapClaims{
+		"iss":            p.srv.URL,
+		"aud":            "vteam",
+		"sub":            "1234",
+		"email":          "alice@example.com",
+		"email_verified": true,
+		"groups":         []string{"eng", "system:masters"},
+		"exp":            time.Now().Add(time.Hour).Unix(),
+	}
+	for k, v := range overrides {
+		if v == nil {
+			delete(claims, k)
+		} else {
+			claims[k] = v
+		}
+	}
+	return claims
+}
+
+func sign(t *testing.T, method jwt.SigningMethod, kid string, key interface{}, claims jwt.MapClaims) string {
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	raw, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return raw
+}
+
+func TestVerifyMapsIdentity(t *testing.T) {
+	p := newTestProvider(t)
+	key := p.addRSAKey(t, "k1")
+	v := p.verifier()
+
+	id, err := v.Verify(context.Background(), sign(t, jwt.SigningMethodRS256, "k1", key, p.claims(nil)))
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if id.Username != "oidc:alice@example.com" || id.Email != "alice@example.com" || id.Subject != "1234" {
+		t.Errorf("unexpected identity %+v", id)
+	}
+	// A prefixed group cannot collide with Kubernetes system: groups, so it is kept
+	if len(id.Groups) != 2 || id.Groups[0] != "oidc:eng" || id.Groups[1] != "oidc:system:masters" {
+		t.Errorf("groups = %v", id.Groups)
+	}
+
+	v.cfg.GroupsPrefix = ""
+	id, err = v.Verify(context.Background(), sign(t, jwt.SigningMethodRS256, "k1", key, p.claims(nil)))
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if len(id.Groups) != 1 || id.Groups[0] != "eng" {
+		t.Errorf("system: groups must be dropped, got %v", id.Groups)
+	}
+}
+
+func TestVerifyRejectsInvalidTokens(t *testing.T) {
+	p := newTestProvider(t)
+	key := p.addRSAKey(t, "k1")
+	other, _ := rsa.GenerateKey(rand.Reader, 2048)
+	v := p.verifier()
+
+	cases := map[string]string{
+		"wrong audience":     sign(t, jwt.SigningMethodRS256, "k1", key, p.claims(jwt.MapClaims{"aud": "someone-else"})),
+		"expired":            sign(t, jwt.SigningMethodRS256, "k1", key, p.claims(jwt.MapClaims{"exp": time.Now().Add(-time.Hour).Unix()})),
+		"no expiry":          sign(t, jwt.SigningMethodRS256, "k1", key, p.claims(jwt.MapClaims{"exp": nil})),
+		"wrong key":          sign(t, jwt.SigningMethodRS256, "k1", other, p.claims(nil)),
+		"hmac":               sign(t, jwt.SigningMethodHS256, "k1", []byte("secret"), p.claims(nil)),
+		"unverified email":   sign(t, jwt.SigningMethodRS256, "k1", key, p.claims(jwt.MapClaims{"email_verified": false})),
+		"missing username":   sign(t, jwt.SigningMethodRS256, "k1", key, p.claims(jwt.MapClaims{"email": nil})),
+		"system username":    sign(t, jwt.SigningMethodRS256, "k1", key, p.claims(jwt.MapClaims{"email": "system:admin"})),
+		"unknown signing id": sign(t, jwt.SigningMethodRS256, "k9", key, p.claims(nil)),
+	}
+	v.cfg.UsernamePrefix = ""
+	for name, raw := range cases {
+		if _, err := v.Verify(context.Background(), raw); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestVerifyIgnoresOtherIssuers(t *testing.T) {
+	p := newTestProvider(t)
+	key := p.addRSAKey(t, "k1")
+	v := p.verifier()
+
+	raw := sign(t, jwt.SigningMethodRS256, "k1", key, p.claims(jwt.MapClaims{"iss": "https://kubernetes.default.svc"}))
+	if v.IssuedBy(raw) {
+		t.Error("IssuedBy should be false for another issuer")
+	}
+	if _, err := v.Verify(context.Background(), raw); !errors.Is(err, ErrNotOIDCToken) {
+		t.Errorf("Verify err = %v, want ErrNotOIDCToken", err)
+	}
+	if v.IssuedBy("sha256~opaque-openshift-token") {
+		t.Error("IssuedBy should be false for opaque tokens")
+	}
+}
+
+func TestVerifyRefetchesRotatedKeys(t *testing.T) {
+	p := newTestProvider(t)
+	key := p.addRSAKey(t, "k1")
+	v := p.verifier()
+	if _, err := v.Verify(context.Background(), sign(t, jwt.SigningMethodRS256, "k1", key, p.claims(nil))); err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+
+	ecKey := p.addECKey(t, "k2")
+	v.fetchedAt = time.Now().Add(-2 * jwksMinRefresh)
+	if _, err := v.Verify(context.Background(), sign(t, jwt.SigningMethodES256, "k2", ecKey, p.claims(nil))); err != nil {
+		t.Fatalf("Verify with rotated key: %v", err)
+	}
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	t.Setenv("OIDC_ISSUER_URL", "")
+	if cfg, err := ConfigFromEnv(); cfg != nil || err != nil {
+		t.Fatalf("unset issuer: cfg=%v err=%v, want nil, nil", cfg, err)
+	}
+
+	t.Setenv("OIDC_ISSUER_URL", "https://idp.example.com")
+	if _, err := ConfigFromEnv(); err == nil {
+		t.Error("missing audience should fail")
+	}
+
+	t.Setenv("OIDC_AUDIENCE", "vteam")
+	cfg, err := ConfigFromEnv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.UsernameClaim != "email" || cfg.GroupsClaim != "groups" {
+		t.Errorf("defaults not applied: %+v", cfg)
+	}
+
+	t.Setenv("OIDC_ISSUER_URL", "http://idp.example.com")
+	if _, err := ConfigFromEnv(); err == nil {
+		t.Error("non-https issuer should fail")
+	}
+}
@@ -0,0 +1,197 @@
+package agui
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// TypedEvent is implemented by the AG-UI event structs, which all embed BaseEvent
+type TypedEvent interface {
+	Base() *BaseEvent
+}
+
+// Base returns the event's common fields
+func (e *BaseEvent) Base() *BaseEvent {
+	return e
+}
+
+// eventDecoders maps each event type with a struct to a constructor for it
+var eventDecoders = map[string]func() TypedEvent{
+	EventTypeRunStarted:         func() TypedEvent { return &RunStartedEvent{} },
+	EventTypeRunFinished:        func() TypedEvent { return &RunFinishedEvent{} },
+	EventTypeRunError:           func() TypedEvent { return &RunErrorEvent{} },
+	EventTypeStepStarted:        func() TypedEvent { return &StepStartedEvent{} },
+	EventTypeStepFinished:       func() TypedEvent { return &StepFinishedEvent{} },
+	EventTypeTextMessageStart:   func() TypedEvent { return &TextMessageStartEvent{} },
+	EventTypeTextMessageContent: func() TypedEvent { return &TextMessageContentEvent{} },
+	EventTypeTextMessageEnd:     func() TypedEvent { return &TextMessageEndEvent{} },
+	EventTypeToolCallStart:      func() TypedEvent { return &ToolCallStartEvent{} },
+	EventTypeToolCallArgs:       func() TypedEvent { return &ToolCallArgsEvent{} },
+	EventTypeToolCallEnd:        func() TypedEvent { return &ToolCallEndEvent{} },
+	EventTypeStateSnapshot:      func() TypedEvent { return &StateSnapshotEvent{} },
+	EventTypStateDelta:          func() TypedEvent { return &StateDeltaEvent{} },
+	EventTypeMessagesSnapshot:   func() TypedEvent { return &MessagesSnapshotEvent{} },
+	EventTypeActivitySnapshot:   func() TypedEvent { return &ActivitySnapshotEvent{} },
+	EventTypeActivityDelta:      func() TypedEvent { return &ActivityDeltaEvent{} },
+	EventTypeRaw:                func() TypedEvent { return &RawEvent{} },
+	EventTypeMeta:               func() TypedEvent { return &MetaEvent{} },
+
+	EventTypeTextMessageChunk:           func() TypedEvent { return &TextMessageChunkEvent{} },
+	EventTypeToolCallChunk:              func() TypedEvent { return &ToolCallChunkEvent{} },
+	EventTypeToolCallResult:             func() TypedEvent { return &ToolCallResultEvent{} },
+	EventTypeThinkingStart:              func() TypedEvent { return &ThinkingStartEvent{} },
+	EventTypeThinkingEnd:                func() TypedEvent { return &ThinkingEndEvent{} },
+	EventTypeThinkingTextMessageStart:   func() TypedEvent { return &ThinkingTextMessageStartEvent{} },
+	EventTypeThinkingTextMessageContent: func() TypedEvent { return &ThinkingTextMessageContentEvent{} },
+	EventTypeThinkingTextMessageEnd:     func() TypedEvent { return &ThinkingTextMessageEndEvent{} },
+	EventTypeCustom:                     func() TypedEvent { return &CustomEvent{} },
+}
+
+// Event is a decoded AG-UI event. Payload is the struct for the event's type (e.g.
+// *ToolCallStartEvent), or a *BaseEvent for types without one. Raw is the event's
+// JSON; it is what gets persisted and streamed, so fields the structs don't model
+// are never lost.
+type Event struct {
+	Payload TypedEvent
+	Raw     json.RawMessage
+}
+
+// DecodeEvent decodes a JSON AG-UI event into the struct for its type. data is
+// retained as Raw and must not be modified afterwards.
+func DecodeEvent(data []byte) (*Event, error) {
+	var head struct {
+		Type string `json:"type"`
+	}
+	if err := json.Unmarshal(data, &head); err != nil {
+		return nil, fmt.Errorf("invalid event JSON: %w", err)
+	}
+	if head.Type == "" {
+		return nil, fmt.Errorf("event has no type")
+	}
+
+	var payload TypedEvent = &BaseEvent{}
+	if newPayload, ok := eventDecoders[head.Type]; ok {
+		payload = newPayload()
+	}
+	if err := json.Unmarshal(data, payload); err != nil {
+		// Fields that don't fit the struct (e.g. from a newer runner) must not drop the
+		// event; Raw still carries them
+		payload = &BaseEvent{}
+		if err := json.Unmarshal(data, payload); err != nil {
+			return nil, fmt.Errorf("invalid %s event: %w", head.Type, err)
+		}
+	}
+	normalizeToolCallID(payload, data)
+	return &Event{Payload: payload, Raw: data}, nil
+}
+
+// NewEvent encodes payload as an Event, e.g. for events the backend emits itself
+func NewEvent(payload TypedEvent) (*Event, error) {
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s event: %w", payload.Base().Type, err)
+	}
+	return &Event{Payload: payload, Raw: raw}, nil
+}
+
+// normalizeToolCallID fills ToolCallID from a snake_case tool_call_id, which some
+// runners send. The second decode only happens when the camelCase field is missing.
+func normalizeToolCallID(payload TypedEvent, data []byte) {
+	var id *string
+	switch e := payload.(type) {
+	case *ToolCallStartEvent:
+		id = &e.ToolCallID
+	case *ToolCallArgsEvent:
+		id = &e.ToolCallID
+	case *ToolCallEndEvent:
+		id = &e.ToolCallID
+	case *ToolCallResultEvent:
+		id = &e.ToolCallID
+	default:
+		return
+	}
+	if *id != "" {
+		return
+	}
+	var snake struct {
+		ToolCallID   string `json:"tool_call_id"`
+		ToolCallName string `json:"tool_call_name"`
+	}
+	if json.Unmarshal(data, &snake) != nil {
+		return
+	}
+	*id = snake.ToolCallID
+	if start, ok := payload.(*ToolCallStartEvent); ok && start.ToolCallName == "" {
+		start.ToolCallName = snake.ToolCallName
+	}
+}
+
+// Base returns the event's common fields
+func (e *Event) Base() *BaseEvent {
+	return e.Payload.Base()
+}
+
+// Type returns the event type
+func (e *Event) Type() string {
+	return e.Payload.Base().Type
+}
+
+// MarshalJSON returns Raw, so streaming an Event writes it exactly as persisted
+func (e *Event) MarshalJSON() ([]byte, error) {
+	return e.Raw, nil
+}
+
+// SetField replaces a top-level field of Raw. Callers update the matching Payload
+// field themselves.
+func (e *Event) SetField(key string, value interface{}) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(e.Raw, &fields); err != nil {
+		return fmt.Errorf("invalid event JSON: %w", err)
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	fields[key] = encoded
+	raw, err := json.Marshal(fields)
+	if err != nil {
+		return err
+	}
+	e.Raw = raw
+	return nil
+}
+
+// FillBase sets the thread ID, run ID and timestamp where the event has none. The
+// fields are appended to Raw; a key that was present but empty is repeated, and
+// JSON decoders keep the last value.
+func (e *Event) FillBase(threadID, runID, timestamp string) {
+	base := e.Base()
+	var extra []byte
+	add := func(field *string, key, value string) {
+		if *field != "" || value == "" {
+			return
+		}
+		*field = value
+		quoted, _ := json.Marshal(value)
+		extra = append(append(append(extra, ','), key...), quoted...)
+	}
+	add(&base.ThreadID, `"threadId":`, threadID)
+	add(&base.RunID, `"runId":`, runID)
+	add(&base.Timestamp, `"timestamp":`, timestamp)
+	if extra == nil {
+		return
+	}
+
+	raw := bytes.TrimRight(e.Raw, " \t\r\n")
+	if len(raw) == 0 || raw[len(raw)-1] != '}' {
+		return
+	}
+	body := bytes.TrimSpace(raw[:len(raw)-1])
+	if len(body) == 1 { // "{" - no members yet
+		extra = extra[1:]
+	}
+	out := make([]byte, 0, len(body)+len(extra)+1)
+	out = append(append(append(out, body...), extra...), '}')
+	e.Raw = out
+}
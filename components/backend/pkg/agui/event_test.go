@@ -0,0 +1,143 @@
+package agui
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestDecodeEventTypedPayloads(t *testing.T) {
+	ev, err := DecodeEvent([]byte(`{"type":"TOOL_CALL_START","threadId":"s1","runId":"r1","toolCallId":"t1","toolCallName":"Bash","rawEvent":{"x":1}}`))
+	if err != nil {
+		t.Fatalf("DecodeEvent: %v", err)
+	}
+	start, ok := ev.Payload.(*ToolCallStartEvent)
+	if !ok || start.ToolCallID != "t1" || start.ToolCallName != "Bash" || ev.Base().RunID != "r1" {
+		t.Errorf("payload = %#v", ev.Payload)
+	}
+
+	// snake_case tool call IDs are normalized
+	ev, err = DecodeEvent([]byte(`{"type":"TOOL_CALL_END","tool_call_id":"t2","error":"exit 1"}`))
+	if err != nil {
+		t.Fatalf("DecodeEvent: %v", err)
+	}
+	if end := ev.Payload.(*ToolCallEndEvent); end.ToolCallID != "t2" || end.Error != "exit 1" {
+		t.Errorf("payload = %#v", end)
+	}
+
+	// Unknown types and mismatched fields keep the base fields
+	for _, data := range []string{
+		`{"type":"X_PROGRESS","runId":"r1","name":"progress"}`,
+		`{"type":"RUN_ERROR","runId":"r1","code":137}`,
+	} {
+		ev, err := DecodeEvent([]byte(data))
+		if err != nil {
+			t.Fatalf("DecodeEvent(%s): %v", data, err)
+		}
+		if _, ok := ev.Payload.(*BaseEvent); !ok || ev.Base().RunID != "r1" {
+			t.Errorf("DecodeEvent(%s) payload = %#v", data, ev.Payload)
+		}
+	}
+
+	for _, data := range []string{`{"runId":"r1"}`, `not json`, `null`} {
+		if _, err := DecodeEvent([]byte(data)); err == nil {
+			t.Errorf("DecodeEvent(%s) should fail", data)
+		}
+	}
+}
+
+func TestEventFillBasePreservesUnknownFields(t *testing.T) {
+	ev, err := DecodeEvent([]byte(`{"type":"TEXT_MESSAGE_CONTENT","runId":"r9","delta":"hi","rawEvent":{"k":"v"}} `))
+	if err != nil {
+		t.Fatalf("DecodeEvent: %v", err)
+	}
+	ev.FillBase("s1", "r1", "2026-01-01T00:00:00Z")
+
+	var got map[string]interface{}
+	out, _ := json.Marshal(ev)
+	if err := json.Unmarshal(out, &got); err != nil {
+		t.Fatalf("filled event is not valid JSON: %s", out)
+	}
+	if got["threadId"] != "s1" || got["runId"] != "r9" || got["timestamp"] != "2026-01-01T00:00:00Z" || got["delta"] != "hi" {
+		t.Errorf("event = %v", got)
+	}
+	if raw, _ := got["rawEvent"].(map[string]interface{}); raw["k"] != "v" {
+		t.Errorf("unknown field lost: %v", got)
+	}
+	if b := ev.Base(); b.ThreadID != "s1" || b.RunID != "r9" {
+		t.Errorf("base = %+v", b)
+	}
+
+	empty, _ := DecodeEvent([]byte(`{"type":"RUN_FINISHED"}`))
+	empty.FillBase("s1", "", "")
+	if string(empty.Raw) != `{"type":"RUN_FINISHED","threadId":"s1"}` {
+		t.Errorf("raw = %s", empty.Raw)
+	}
+}
+
+func TestNewEvent(t *testing.T) {
+	ev, err := NewEvent(&RawEvent{BaseEvent: NewBaseEvent(EventTypeRaw, "s1", "r1"), Event: map[string]string{"type": "x"}})
+	if err != nil {
+		t.Fatalf("NewEvent: %v", err)
+	}
+	decoded, err := DecodeEvent(ev.Raw)
+	if err != nil {
+		t.Fatalf("DecodeEvent: %v", err)
+	}
+	if raw, ok := decoded.Payload.(*RawEvent); !ok || raw.RunID != "r1" || raw.Event == nil {
+		t.Errorf("round trip = %#v", decoded.Payload)
+	}
+}
+
+var benchEvents = [][]byte{
+	[]byte(`{"type":"TEXT_MESSAGE_CONTENT","messageId":"m-1f3a","delta":"Looking at the failing test, the fixture never closes the server so"}`),
+	[]byte(`{"type":"TOOL_CALL_START","messageId":"m-1f3a","toolCallId":"toolu_01","toolCallName":"mcp__github__get_file","parentMessageId":"m-1f3a"}`),
+	[]byte(`{"type":"TOOL_CALL_ARGS","toolCallId":"toolu_01","delta":"{\"path\": \"components/backend/websocket/agui.go\"}"}`),
+	[]byte(`{"type":"TOOL_CALL_END","toolCallId":"toolu_01","result":"package websocket\n\nimport (\n\t\"context\"\n)","duration":412}`),
+}
+
+// BenchmarkEventPipelineMap is the previous pipeline: decode into a map, fill the
+// base fields, then encode once to persist and once more to stream
+func BenchmarkEventPipelineMap(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		data := benchEvents[i%len(benchEvents)]
+		var event map[string]interface{}
+		if err := json.Unmarshal(data, &event); err != nil {
+			b.Fatal(err)
+		}
+		if _, ok := event["threadId"]; !ok {
+			event["threadId"] = "session-1"
+		}
+		if _, ok := event["runId"]; !ok {
+			event["runId"] = "run-1"
+		}
+		if _, ok := event["timestamp"]; !ok {
+			event["timestamp"] = "2026-01-01T00:00:00.123456789Z"
+		}
+		eventType, _ := event["type"].(string)
+		_ = eventType
+		if _, err := json.Marshal(event); err != nil {
+			b.Fatal(err)
+		}
+		if _, err := json.Marshal(event); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// BenchmarkEventPipelineTyped decodes into the typed struct, splices in the base
+// fields, persists Raw as-is and streams it through MarshalJSON
+func BenchmarkEventPipelineTyped(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		event, err := DecodeEvent(benchEvents[i%len(benchEvents)])
+		if err != nil {
+			b.Fatal(err)
+		}
+		event.FillBase("session-1", "run-1", "2026-01-01T00:00:00.123456789Z")
+		_ = event.Type()
+		if _, err := json.Marshal(event); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
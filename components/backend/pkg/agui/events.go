@@ -0,0 +1,390 @@
+// Package agui implements the AG-UI protocol: the typed events, their JSON
+// encoding and decoding, validation, and server-sent event streams. It has no
+// dependencies on the rest of the backend, so Go runners can share it.
+// Reference: https://docs.ag-ui.com/concepts/events
+package agui
+
+import "time"
+
+// Timestamp format constants for AG-UI events and metadata.
+// These ensure consistent timestamp formatting across the codebase.
+const (
+	// AGUITimestampFormat is used for event timestamps that require nanosecond precision.
+	// This preserves event ordering when multiple events occur in rapid succession.
+	// Format: "2006-01-02T15:04:05.999999999Z07:00" (RFC3339 with nanoseconds)
+	// Used in: BaseEvent.Timestamp, streamed events
+	AGUITimestampFormat = time.RFC3339Nano
+
+	// AGUIMetadataTimestampFormat is used for run/session metadata timestamps.
+	// This is sufficient for human-readable timestamps where nanosecond precision isn't needed.
+	// Format: "2006-01-02T15:04:05Z07:00" (RFC3339)
+	AGUIMetadataTimestampFormat = time.RFC3339
+)
+
+// AG-UI Event Types as defined in the protocol specification
+// See: https://docs.ag-ui.com/concepts/events
+const (
+	// Lifecycle events
+	EventTypeRunStarted  = "RUN_STARTED"
+	EventTypeRunFinished = "RUN_FINISHED"
+	EventTypeRunError    = "RUN_ERROR"
+
+	// Step events
+	EventTypeStepStarted  = "STEP_STARTED"
+	EventTypeStepFinished = "STEP_FINISHED"
+
+	// Text message events (streaming)
+	EventTypeTextMessageStart   = "TEXT_MESSAGE_START"
+	EventTypeTextMessageContent = "TEXT_MESSAGE_CONTENT"
+	EventTypeTextMessageEnd     = "TEXT_MESSAGE_END"
+	EventTypeTextMessageChunk   = "TEXT_MESSAGE_CHUNK"
+
+	// Tool call events (streaming)
+	EventTypeToolCallStart  = "TOOL_CALL_START"
+	EventTypeToolCallArgs   = "TOOL_CALL_ARGS"
+	EventTypeToolCallEnd    = "TOOL_CALL_END"
+	EventTypeToolCallChunk  = "TOOL_CALL_CHUNK"
+	EventTypeToolCallResult = "TOOL_CALL_RESULT"
+
+	// Thinking events (reasoning shown apart from the answer)
+	EventTypeThinkingStart              = "THINKING_START"
+	EventTypeThinkingEnd                = "THINKING_END"
+	EventTypeThinkingTextMessageStart   = "THINKING_TEXT_MESSAGE_START"
+	EventTypeThinkingTextMessageContent = "THINKING_TEXT_MESSAGE_CONTENT"
+	EventTypeThinkingTextMessageEnd     = "THINKING_TEXT_MESSAGE_END"
+
+	// State management events
+	EventTypeStateSnapshot = "STATE_SNAPSHOT"
+	EventTypStateDelta     = "STATE_DELTA"
+
+	// Message snapshot for restore/reconnect
+	EventTypeMessagesSnapshot = "MESSAGES_SNAPSHOT"
+
+	// Activity events (frontend-only durable UI)
+	EventTypeActivitySnapshot = "ACTIVITY_SNAPSHOT"
+	EventTypeActivityDelta    = "ACTIVITY_DELTA"
+
+	// Raw event for pass-through, and application-defined custom events
+	EventTypeRaw    = "RAW"
+	EventTypeCustom = "CUSTOM"
+
+	// META event for user feedback (thumbs up/down)
+	// See: https://docs.ag-ui.com/drafts/meta-events
+	EventTypeMeta = "META"
+)
+
+// AG-UI Message Roles
+// See: https://docs.ag-ui.com/concepts/messages
+const (
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+	RoleSystem    = "system"
+	RoleTool      = "tool"
+	RoleDeveloper = "developer"
+	RoleActivity  = "activity"
+)
+
+// BaseEvent is the common structure for all AG-UI events
+// See: https://docs.ag-ui.com/concepts/events#baseeventproperties
+type BaseEvent struct {
+	Type      string `json:"type"`
+	ThreadID  string `json:"threadId"`
+	RunID     string `json:"runId"`
+	Timestamp string `json:"timestamp"` // Format: AGUITimestampFormat (RFC3339Nano)
+	// Optional fields
+	MessageID   string `json:"messageId,omitempty"`
+	ParentRunID string `json:"parentRunId,omitempty"`
+	// BranchID tags events of a sub-agent run streamed within its parent's stream
+	BranchID string `json:"branchId,omitempty"`
+}
+
+// RunAgentInput is the input of an AG-UI run as the protocol defines it
+// See: https://docs.ag-ui.com/sdk/js/core/types#runagentinput
+type RunAgentInput struct {
+	ThreadID       string                 `json:"threadId,omitempty"`
+	RunID          string                 `json:"runId,omitempty"`
+	ParentRunID    string                 `json:"parentRunId,omitempty"`
+	Messages       []Message              `json:"messages,omitempty"`
+	State          map[string]interface{} `json:"state,omitempty"`
+	Tools          []ToolDefinition       `json:"tools,omitempty"`
+	Context        map[string]interface{} `json:"context,omitempty"`
+	ForwardedProps map[string]interface{} `json:"forwardedProps,omitempty"`
+}
+
+// Message represents an AG-UI message in the conversation
+// See: https://docs.ag-ui.com/concepts/messages
+type Message struct {
+	ID         string      `json:"id"`
+	Role       string      `json:"role"`
+	Content    string      `json:"content,omitempty"`
+	ToolCalls  []ToolCall  `json:"toolCalls,omitempty"`
+	ToolCallID string      `json:"toolCallId,omitempty"`
+	Name       string      `json:"name,omitempty"`
+	Timestamp  string      `json:"timestamp,omitempty"`
+	Metadata   interface{} `json:"metadata,omitempty"`
+	// Attachments are files sent with a user message, e.g. screenshots
+	Attachments []Attachment `json:"attachments,omitempty"`
+}
+
+// Attachment is a file sent with a message. Clients reference a file uploaded to the
+// backend by ID or a session workspace file by Path; the backend resolves it to Data
+// (base64) or a URL the runner can read before the input reaches the runner.
+type Attachment struct {
+	ID       string `json:"id,omitempty"`
+	Path     string `json:"path,omitempty"`
+	Filename string `json:"filename,omitempty"`
+	MimeType string `json:"mimeType,omitempty"`
+	Size     int64  `json:"size,omitempty"`
+	URL      string `json:"url,omitempty"`
+	Data     string `json:"data,omitempty"`
+}
+
+// ToolCall represents a tool call made by the assistant
+type ToolCall struct {
+	ID              string `json:"id"`
+	Name            string `json:"name"`
+	Args            string `json:"args"`
+	Type            string `json:"type,omitempty"`            // "function"
+	ParentToolUseID string `json:"parentToolUseId,omitempty"` // For hierarchical nesting
+	Result          string `json:"result,omitempty"`
+	Status          string `json:"status,omitempty"` // "pending", "running", "completed", "error"
+	Error           string `json:"error,omitempty"`
+	Duration        int64  `json:"duration,omitempty"` // milliseconds
+}
+
+// ToolDefinition describes an available tool
+type ToolDefinition struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description,omitempty"`
+	Parameters  map[string]interface{} `json:"parameters,omitempty"`
+}
+
+// RunStartedEvent is emitted when a run begins
+type RunStartedEvent struct {
+	BaseEvent
+	Input *RunAgentInput `json:"input,omitempty"`
+}
+
+// RunFinishedEvent is emitted when a run completes successfully
+type RunFinishedEvent struct {
+	BaseEvent
+	Result interface{} `json:"result,omitempty"`
+	Output interface{} `json:"output,omitempty"` // legacy alternative to Result
+}
+
+// RunErrorEvent is emitted when a run fails
+type RunErrorEvent struct {
+	BaseEvent
+	Message string `json:"message,omitempty"` // AG-UI spec field; older runners send error
+	Error   string `json:"error"`
+	Code    string `json:"code,omitempty"`
+	Details string `json:"details,omitempty"`
+}
+
+// StepStartedEvent marks the beginning of a processing step
+type StepStartedEvent struct {
+	BaseEvent
+	StepID   string `json:"stepId"`
+	StepName string `json:"stepName"`
+}
+
+// StepFinishedEvent marks the completion of a processing step
+type StepFinishedEvent struct {
+	BaseEvent
+	StepID   string `json:"stepId"`
+	StepName string `json:"stepName"`
+	Duration int64  `json:"duration,omitempty"` // milliseconds
+}
+
+// TextMessageStartEvent begins a streaming text message
+type TextMessageStartEvent struct {
+	BaseEvent
+	Role string `json:"role"`
+}
+
+// TextMessageContentEvent contains a chunk of text content
+type TextMessageContentEvent struct {
+	BaseEvent
+	Delta string `json:"delta"`
+}
+
+// TextMessageEndEvent marks the end of a streaming text message
+type TextMessageEndEvent struct {
+	BaseEvent
+}
+
+// TextMessageChunkEvent is a self-contained piece of a text message; clients expand
+// chunks into start, content and end events
+type TextMessageChunkEvent struct {
+	BaseEvent
+	Role  string `json:"role,omitempty"`
+	Delta string `json:"delta,omitempty"`
+}
+
+// ToolCallStartEvent begins a streaming tool call
+type ToolCallStartEvent struct {
+	BaseEvent
+	ToolCallID      string `json:"toolCallId"`
+	ToolCallName    string `json:"toolCallName"`
+	ParentMessageID string `json:"parentMessageId,omitempty"`
+	ParentToolUseID string `json:"parentToolUseId,omitempty"`
+}
+
+// ToolCallArgsEvent contains a chunk of tool call arguments
+type ToolCallArgsEvent struct {
+	BaseEvent
+	ToolCallID string `json:"toolCallId"`
+	Delta      string `json:"delta"`
+}
+
+// ToolCallEndEvent marks the end of a streaming tool call
+type ToolCallEndEvent struct {
+	BaseEvent
+	ToolCallID string `json:"toolCallId"`
+	Result     string `json:"result,omitempty"`
+	Error      string `json:"error,omitempty"`
+	Duration   int64  `json:"duration,omitempty"` // milliseconds
+}
+
+// ToolCallChunkEvent is a self-contained piece of a tool call; the first chunk of
+// a call carries its ID and name
+type ToolCallChunkEvent struct {
+	BaseEvent
+	ToolCallID      string `json:"toolCallId,omitempty"`
+	ToolCallName    string `json:"toolCallName,omitempty"`
+	ParentMessageID string `json:"parentMessageId,omitempty"`
+	Delta           string `json:"delta,omitempty"`
+}
+
+// ToolCallResultEvent carries the result of a tool call as a tool message
+type ToolCallResultEvent struct {
+	BaseEvent
+	ToolCallID string `json:"toolCallId"`
+	Content    string `json:"content"`
+	Role       string `json:"role,omitempty"` // "tool"
+}
+
+// ThinkingStartEvent begins a block of model reasoning
+type ThinkingStartEvent struct {
+	BaseEvent
+	Title string `json:"title,omitempty"`
+}
+
+// ThinkingEndEvent ends a block of model reasoning
+type ThinkingEndEvent struct {
+	BaseEvent
+}
+
+// ThinkingTextMessageStartEvent begins a reasoning message
+type ThinkingTextMessageStartEvent struct {
+	BaseEvent
+}
+
+// ThinkingTextMessageContentEvent contains a chunk of reasoning text
+type ThinkingTextMessageContentEvent struct {
+	BaseEvent
+	Delta string `json:"delta"`
+}
+
+// ThinkingTextMessageEndEvent ends a reasoning message
+type ThinkingTextMessageEndEvent struct {
+	BaseEvent
+}
+
+// StateSnapshotEvent provides complete state for hydration
+type StateSnapshotEvent struct {
+	BaseEvent
+	State map[string]interface{} `json:"state"`
+}
+
+// StateDeltaEvent provides incremental state updates
+type StateDeltaEvent struct {
+	BaseEvent
+	Delta []StatePatch `json:"delta"`
+}
+
+// StatePatch represents a JSON Patch operation for state updates
+type StatePatch struct {
+	Op    string      `json:"op"`   // "add", "remove", "replace"
+	Path  string      `json:"path"` // JSON Pointer
+	Value interface{} `json:"value,omitempty"`
+}
+
+// MessagesSnapshotEvent provides complete message history for hydration
+type MessagesSnapshotEvent struct {
+	BaseEvent
+	Messages []Message `json:"messages"`
+}
+
+// ActivitySnapshotEvent provides complete activity UI state
+type ActivitySnapshotEvent struct {
+	BaseEvent
+	Activities []Activity `json:"activities"`
+}
+
+// ActivityDeltaEvent provides incremental activity updates
+type ActivityDeltaEvent struct {
+	BaseEvent
+	Delta []ActivityPatch `json:"delta"`
+}
+
+// Activity represents a durable frontend UI element
+type Activity struct {
+	ID       string                 `json:"id"`
+	Type     string                 `json:"type"`
+	Title    string                 `json:"title,omitempty"`
+	Status   string                 `json:"status,omitempty"` // "pending", "running", "completed", "error"
+	Progress float64                `json:"progress,omitempty"`
+	Data     map[string]interface{} `json:"data,omitempty"`
+}
+
+// ActivityPatch represents an update to an activity
+type ActivityPatch struct {
+	Op       string   `json:"op"` // "add", "update", "remove"
+	Activity Activity `json:"activity"`
+}
+
+// RawEvent allows pass-through of arbitrary data
+type RawEvent struct {
+	BaseEvent
+	Event  interface{} `json:"event,omitempty"`
+	Source string      `json:"source,omitempty"`
+	Data   interface{} `json:"data,omitempty"` // legacy alternative to Event
+}
+
+// CustomEvent is an application-defined event with a name and a value
+type CustomEvent struct {
+	BaseEvent
+	Name  string      `json:"name"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// MetaEvent represents AG-UI META events for user feedback
+// See: https://docs.ag-ui.com/drafts/meta-events#user-feedback
+type MetaEvent struct {
+	BaseEvent
+	MetaType string                 `json:"metaType"` // "thumbs_up" or "thumbs_down"
+	Payload  map[string]interface{} `json:"payload"`
+}
+
+// NewBaseEvent creates a new BaseEvent with current timestamp
+func NewBaseEvent(eventType, threadID, runID string) BaseEvent {
+	return BaseEvent{
+		Type:      eventType,
+		ThreadID:  threadID,
+		RunID:     runID,
+		Timestamp: time.Now().UTC().Format(AGUITimestampFormat),
+	}
+}
+
+// WithMessageID adds a message ID to the event
+func (e BaseEvent) WithMessageID(messageID string) BaseEvent {
+	e.MessageID = messageID
+	return e
+}
+
+// WithParentRunID adds a parent run ID to the event
+func (e BaseEvent) WithParentRunID(parentRunID string) BaseEvent {
+	e.ParentRunID = parentRunID
+	return e
+}
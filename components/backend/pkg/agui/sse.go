@@ -0,0 +1,195 @@
+package agui
+
+// Server-sent event streams as specified by the WHATWG HTML standard
+// (https://html.spec.whatwg.org/multipage/server-sent-events.html): SSEReader
+// parses them and WriteSSE encodes AG-UI events onto them.
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// MaxSSEEventBytes bounds a single line and a single event's data (snapshots carry
+// whole conversations)
+const MaxSSEEventBytes = 32 << 20
+
+// ErrSSEEventTooLarge is returned when a line or an event's data exceeds MaxSSEEventBytes
+var ErrSSEEventTooLarge = errors.New("sse: event too large")
+
+// SSEEvent is a dispatched server-sent event
+type SSEEvent struct {
+	// Type is the event: field, or "message" when the event had none
+	Type string
+	// ID is the last event ID seen on the stream, which carries over between events
+	ID string
+	// Data is the data: lines joined by newlines
+	Data []byte
+}
+
+// SSEReader parses events from a stream. Lines may end in CRLF, LF or CR, data may
+// span several data: lines, and comments (e.g. keepalives) are skipped.
+type SSEReader struct {
+	br *bufio.Reader
+
+	line      []byte
+	started   bool
+	skipLF    bool
+	eventType string
+	data      []byte
+	hasData   bool
+	lastID    string
+	retry     time.Duration
+}
+
+// NewSSEReader returns an SSEReader reading from r
+func NewSSEReader(r io.Reader) *SSEReader {
+	return &SSEReader{br: bufio.NewReaderSize(r, 64<<10)}
+}
+
+// Retry returns the reconnection time the stream last set, or 0 if it set none
+func (r *SSEReader) Retry() time.Duration {
+	return r.retry
+}
+
+// Next returns the next event. At the end of the stream it returns io.EOF, and an
+// event not terminated by a blank line is discarded.
+func (r *SSEReader) Next() (*SSEEvent, error) {
+	for {
+		line, err := r.readLine()
+		if err != nil {
+			return nil, err
+		}
+
+		if len(line) == 0 {
+			if ev := r.dispatch(); ev != nil {
+				return ev, nil
+			}
+			continue
+		}
+		if line[0] == ':' {
+			continue
+		}
+
+		field, value := line, []byte(nil)
+		if i := bytes.IndexByte(line, ':'); i >= 0 {
+			field, value = line[:i], line[i+1:]
+			if len(value) > 0 && value[0] == ' ' {
+				value = value[1:]
+			}
+		}
+		switch string(field) {
+		case "event":
+			r.eventType = string(value)
+		case "data":
+			if len(r.data)+len(value)+1 > MaxSSEEventBytes {
+				return nil, ErrSSEEventTooLarge
+			}
+			r.data = append(append(r.data, value...), '\n')
+			r.hasData = true
+		case "id":
+			if bytes.IndexByte(value, 0) < 0 {
+				r.lastID = string(value)
+			}
+		case "retry":
+			if !isDigits(value) {
+				break
+			}
+			if ms, err := strconv.ParseInt(string(value), 10, 64); err == nil {
+				r.retry = time.Duration(ms) * time.Millisecond
+			}
+		}
+		// Other fields are ignored
+	}
+}
+
+// dispatch returns the buffered event and resets the buffers, or returns nil when
+// no data was buffered
+func (r *SSEReader) dispatch() *SSEEvent {
+	eventType := r.eventType
+	r.eventType = ""
+	if !r.hasData {
+		return nil
+	}
+	ev := &SSEEvent{Type: eventType, ID: r.lastID, Data: r.data[:len(r.data)-1]}
+	if ev.Type == "" {
+		ev.Type = "message"
+	}
+	// The event keeps the buffer
+	r.data, r.hasData = nil, false
+	return ev
+}
+
+// readLine returns the next line without its terminator. The line is only valid
+// until the next call.
+func (r *SSEReader) readLine() ([]byte, error) {
+	r.line = r.line[:0]
+	if r.skipLF {
+		// The previous line ended in CR; a following LF completes that CRLF. This
+		// only waits when the next line is needed anyway.
+		r.skipLF = false
+		if b, err := r.br.Peek(1); err == nil && b[0] == '\n' {
+			_, _ = r.br.Discard(1)
+		}
+	}
+	for {
+		n := r.br.Buffered()
+		if n == 0 {
+			n = 1
+		}
+		buf, err := r.br.Peek(n)
+		if len(buf) == 0 {
+			if err == nil {
+				err = io.ErrNoProgress
+			}
+			// A final line without a terminator is incomplete
+			return nil, err
+		}
+
+		if i := bytes.IndexAny(buf, "\r\n"); i >= 0 {
+			r.line = append(r.line, buf[:i]...)
+			r.skipLF = buf[i] == '\r'
+			_, _ = r.br.Discard(i + 1)
+			break
+		}
+		if len(r.line)+len(buf) > MaxSSEEventBytes {
+			return nil, ErrSSEEventTooLarge
+		}
+		r.line = append(r.line, buf...)
+		_, _ = r.br.Discard(len(buf))
+	}
+	if len(r.line) > MaxSSEEventBytes {
+		return nil, ErrSSEEventTooLarge
+	}
+
+	if !r.started {
+		r.started = true
+		r.line = bytes.TrimPrefix(r.line, []byte("\xEF\xBB\xBF"))
+	}
+	return r.line, nil
+}
+
+func isDigits(b []byte) bool {
+	for _, c := range b {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return len(b) > 0
+}
+
+// WriteSSE writes event as a single data: line followed by the blank line that
+// dispatches it. An *Event is written as its Raw JSON.
+func WriteSSE(w io.Writer, event interface{}) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+	_, err = fmt.Fprintf(w, "data: %s\n\n", data)
+	return err
+}
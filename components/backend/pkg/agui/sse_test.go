@@ -0,0 +1,130 @@
+package agui
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func readAll(t *testing.T, stream string) []SSEEvent {
+	t.Helper()
+	r := NewSSEReader(strings.NewReader(stream))
+	var events []SSEEvent
+	for {
+		ev, err := r.Next()
+		if err == io.EOF {
+			return events
+		}
+		if err != nil {
+			t.Fatalf("Next: %v", err)
+		}
+		events = append(events, *ev)
+	}
+}
+
+func TestSSEReaderLineEndings(t *testing.T) {
+	for name, stream := range map[string]string{
+		"LF":   "data: {\"a\":1}\n\ndata: {\"b\":2}\n\n",
+		"CRLF": "data: {\"a\":1}\r\n\r\ndata: {\"b\":2}\r\n\r\n",
+		"CR":   "data: {\"a\":1}\r\rdata: {\"b\":2}\r\r",
+		"BOM":  "\xEF\xBB\xBFdata: {\"a\":1}\n\ndata: {\"b\":2}\n\n",
+	} {
+		events := readAll(t, stream)
+		if len(events) != 2 || string(events[0].Data) != `{"a":1}` || string(events[1].Data) != `{"b":2}` {
+			t.Errorf("%s: events = %q", name, events)
+		}
+	}
+}
+
+func TestSSEReaderFields(t *testing.T) {
+	stream := ": keepalive\n\n" +
+		"event: agui\nid: 7\ndata: {\"type\":\n" +
+		"data:\"RUN_STARTED\"}\n\n" +
+		"retry: 2500\ndata\n\n" +
+		"id: bad\x00id\ndata: x\nunknown: y\n\n" +
+		"data: cut off\n"
+	events := readAll(t, stream)
+	if len(events) != 3 {
+		t.Fatalf("events = %q", events)
+	}
+	if ev := events[0]; ev.Type != "agui" || ev.ID != "7" || string(ev.Data) != "{\"type\":\n\"RUN_STARTED\"}" {
+		t.Errorf("multi-line event = %+v", ev)
+	}
+	// A field without a colon has an empty value; the event type resets
+	if ev := events[1]; ev.Type != "message" || ev.ID != "7" || len(ev.Data) != 0 {
+		t.Errorf("empty data event = %+v", ev)
+	}
+	// IDs containing NULL are ignored
+	if ev := events[2]; ev.ID != "7" || string(ev.Data) != "x" {
+		t.Errorf("event = %+v", ev)
+	}
+
+	r := NewSSEReader(strings.NewReader("retry: 2500\nretry: 1s\n\n"))
+	if _, err := r.Next(); err != io.EOF || r.Retry() != 2500*time.Millisecond {
+		t.Errorf("Next = %v, Retry = %v", err, r.Retry())
+	}
+}
+
+// chunkedReader returns one byte per read, so line endings straddle reads
+type chunkedReader struct{ s string }
+
+func (c *chunkedReader) Read(p []byte) (int, error) {
+	if c.s == "" {
+		return 0, io.EOF
+	}
+	p[0], c.s = c.s[0], c.s[1:]
+	return 1, nil
+}
+
+func TestSSEReaderSplitReads(t *testing.T) {
+	r := NewSSEReader(&chunkedReader{"data: a\r\n\r\ndata: b\r\rdata: c\n\n"})
+	var got []string
+	for {
+		ev, err := r.Next()
+		if err != nil {
+			if err != io.EOF {
+				t.Fatalf("Next: %v", err)
+			}
+			break
+		}
+		got = append(got, string(ev.Data))
+	}
+	if strings.Join(got, ",") != "a,b,c" {
+		t.Errorf("events = %q", got)
+	}
+}
+
+func TestSSEReaderEventTooLarge(t *testing.T) {
+	r := NewSSEReader(io.MultiReader(strings.NewReader("data: "), strings.NewReader(strings.Repeat("x", MaxSSEEventBytes+1))))
+	if _, err := r.Next(); !errors.Is(err, ErrSSEEventTooLarge) {
+		t.Errorf("Next = %v, want ErrSSEEventTooLarge", err)
+	}
+}
+
+func TestWriteSSERoundTrip(t *testing.T) {
+	ev, err := NewEvent(&TextMessageContentEvent{
+		BaseEvent: NewBaseEvent(EventTypeTextMessageContent, "s1", "r1").WithMessageID("m1"),
+		Delta:     "line one\nline two",
+	})
+	if err != nil {
+		t.Fatalf("NewEvent: %v", err)
+	}
+	var buf strings.Builder
+	if err := WriteSSE(&buf, ev); err != nil {
+		t.Fatalf("WriteSSE: %v", err)
+	}
+
+	events := readAll(t, buf.String())
+	if len(events) != 1 {
+		t.Fatalf("events = %q", events)
+	}
+	got, err := DecodeEvent(events[0].Data)
+	if err != nil {
+		t.Fatalf("DecodeEvent: %v", err)
+	}
+	if content, ok := got.Payload.(*TextMessageContentEvent); !ok || content.Delta != "line one\nline two" || content.MessageID != "m1" {
+		t.Errorf("payload = %#v", got.Payload)
+	}
+}
@@ -0,0 +1,210 @@
+package agui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// validRoles are the message roles the protocol defines
+var validRoles = map[string]bool{
+	RoleUser:      true,
+	RoleAssistant: true,
+	RoleSystem:    true,
+	RoleTool:      true,
+	RoleDeveloper: true,
+	RoleActivity:  true,
+}
+
+// validPatchOps are the JSON Patch (RFC 6902) operations of STATE_DELTA
+var validPatchOps = map[string]bool{
+	"add": true, "remove": true, "replace": true, "move": true, "copy": true, "test": true,
+}
+
+// Validate checks that an event carries the fields the protocol requires for its
+// type. Events of types without a struct (e.g. runner-defined ones) only need a type.
+func Validate(event TypedEvent) error {
+	base := event.Base()
+	if base.Type == "" {
+		return fmt.Errorf("event has no type")
+	}
+	if base.Timestamp != "" {
+		if _, err := time.Parse(AGUITimestampFormat, base.Timestamp); err != nil {
+			return fmt.Errorf("%s: invalid timestamp %q", base.Type, base.Timestamp)
+		}
+	}
+	require := func(value, field string) error {
+		if value == "" {
+			return fmt.Errorf("%s: %s is required", base.Type, field)
+		}
+		return nil
+	}
+
+	switch e := event.(type) {
+	case *BaseEvent:
+		// DecodeEvent falls back to the base fields when an event's fields don't fit
+		// the struct for its type
+		if _, ok := eventDecoders[base.Type]; ok {
+			return fmt.Errorf("%s: fields do not match the event type", base.Type)
+		}
+	case *RunStartedEvent, *RunFinishedEvent:
+		if err := require(base.ThreadID, "threadId"); err != nil {
+			return err
+		}
+		return require(base.RunID, "runId")
+	case *RunErrorEvent:
+		return require(e.Message+e.Error, "message")
+	case *StepStartedEvent:
+		return require(e.StepName, "stepName")
+	case *StepFinishedEvent:
+		return require(e.StepName, "stepName")
+	case *TextMessageStartEvent:
+		if err := require(base.MessageID, "messageId"); err != nil {
+			return err
+		}
+		if e.Role != "" && !validRoles[e.Role] {
+			return fmt.Errorf("%s: invalid role %q", base.Type, e.Role)
+		}
+	case *TextMessageContentEvent:
+		if err := require(base.MessageID, "messageId"); err != nil {
+			return err
+		}
+		return require(e.Delta, "delta")
+	case *TextMessageEndEvent:
+		return require(base.MessageID, "messageId")
+	case *TextMessageChunkEvent:
+		if e.Role != "" && !validRoles[e.Role] {
+			return fmt.Errorf("%s: invalid role %q", base.Type, e.Role)
+		}
+	case *ToolCallStartEvent:
+		if err := require(e.ToolCallID, "toolCallId"); err != nil {
+			return err
+		}
+		return require(e.ToolCallName, "toolCallName")
+	case *ToolCallArgsEvent:
+		return require(e.ToolCallID, "toolCallId")
+	case *ToolCallEndEvent:
+		return require(e.ToolCallID, "toolCallId")
+	case *ToolCallResultEvent:
+		if err := require(base.MessageID, "messageId"); err != nil {
+			return err
+		}
+		return require(e.ToolCallID, "toolCallId")
+	case *ThinkingTextMessageContentEvent:
+		return require(e.Delta, "delta")
+	case *StateSnapshotEvent:
+		if e.State == nil {
+			return fmt.Errorf("%s: state is required", base.Type)
+		}
+	case *StateDeltaEvent:
+		for i, op := range e.Delta {
+			if !validPatchOps[op.Op] {
+				return fmt.Errorf("%s: delta[%d]: invalid op %q", base.Type, i, op.Op)
+			}
+			if op.Path != "" && !strings.HasPrefix(op.Path, "/") {
+				return fmt.Errorf("%s: delta[%d]: path %q is not a JSON Pointer", base.Type, i, op.Path)
+			}
+		}
+	case *MessagesSnapshotEvent:
+		for i, msg := range e.Messages {
+			if msg.ID == "" {
+				return fmt.Errorf("%s: messages[%d]: id is required", base.Type, i)
+			}
+			if !validRoles[msg.Role] {
+				return fmt.Errorf("%s: messages[%d]: invalid role %q", base.Type, i, msg.Role)
+			}
+		}
+	case *CustomEvent:
+		return require(e.Name, "name")
+	case *MetaEvent:
+		return require(e.MetaType, "metaType")
+	}
+	return nil
+}
+
+// StreamValidator checks the order of a run's events: text messages, thinking
+// messages and tool calls are started before their content and ended once, and
+// nothing follows RUN_FINISHED or RUN_ERROR. Feed it events in stream order.
+type StreamValidator struct {
+	started  bool
+	finished bool
+	messages map[string]bool // open text messages
+	thinking bool            // a thinking text message is open
+	tools    map[string]bool // open tool calls
+}
+
+// NewStreamValidator returns a StreamValidator for one run
+func NewStreamValidator() *StreamValidator {
+	return &StreamValidator{messages: map[string]bool{}, tools: map[string]bool{}}
+}
+
+// Next validates event and its place in the stream
+func (v *StreamValidator) Next(event TypedEvent) error {
+	if err := Validate(event); err != nil {
+		return err
+	}
+	base := event.Base()
+	if v.finished {
+		return fmt.Errorf("%s after the run ended", base.Type)
+	}
+	if _, ok := event.(*RunStartedEvent); !ok && !v.started {
+		return fmt.Errorf("%s before RUN_STARTED", base.Type)
+	}
+
+	switch e := event.(type) {
+	case *RunStartedEvent:
+		if v.started {
+			return fmt.Errorf("%s: run already started", base.Type)
+		}
+		v.started = true
+	case *RunFinishedEvent:
+		if len(v.messages) > 0 || len(v.tools) > 0 {
+			return fmt.Errorf("%s with open text messages or tool calls", base.Type)
+		}
+		v.finished = true
+	case *RunErrorEvent:
+		// An error may cut a run off at any point
+		v.finished = true
+	case *TextMessageStartEvent:
+		if v.messages[base.MessageID] {
+			return fmt.Errorf("%s: message %s already started", base.Type, base.MessageID)
+		}
+		v.messages[base.MessageID] = true
+	case *TextMessageContentEvent:
+		if !v.messages[base.MessageID] {
+			return fmt.Errorf("%s: message %s not started", base.Type, base.MessageID)
+		}
+	case *TextMessageEndEvent:
+		if !v.messages[base.MessageID] {
+			return fmt.Errorf("%s: message %s not started", base.Type, base.MessageID)
+		}
+		delete(v.messages, base.MessageID)
+	case *ThinkingTextMessageStartEvent:
+		if v.thinking {
+			return fmt.Errorf("%s: a thinking message is already open", base.Type)
+		}
+		v.thinking = true
+	case *ThinkingTextMessageContentEvent, *ThinkingTextMessageEndEvent:
+		if !v.thinking {
+			return fmt.Errorf("%s: no thinking message started", base.Type)
+		}
+		if _, end := e.(*ThinkingTextMessageEndEvent); end {
+			v.thinking = false
+		}
+	case *ToolCallStartEvent:
+		if v.tools[e.ToolCallID] {
+			return fmt.Errorf("%s: tool call %s already started", base.Type, e.ToolCallID)
+		}
+		v.tools[e.ToolCallID] = true
+	case *ToolCallArgsEvent:
+		if !v.tools[e.ToolCallID] {
+			return fmt.Errorf("%s: tool call %s not started", base.Type, e.ToolCallID)
+		}
+	case *ToolCallEndEvent:
+		if !v.tools[e.ToolCallID] {
+			return fmt.Errorf("%s: tool call %s not started", base.Type, e.ToolCallID)
+		}
+		delete(v.tools, e.ToolCallID)
+	}
+	return nil
+}
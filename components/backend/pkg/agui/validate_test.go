@@ -0,0 +1,116 @@
+package agui
+
+import (
+	"strings"
+	"testing"
+)
+
+func decode(t *testing.T, data string) TypedEvent {
+	t.Helper()
+	ev, err := DecodeEvent([]byte(data))
+	if err != nil {
+		t.Fatalf("DecodeEvent(%s): %v", data, err)
+	}
+	return ev.Payload
+}
+
+func TestValidate(t *testing.T) {
+	for _, data := range []string{
+		`{"type":"RUN_STARTED","threadId":"s1","runId":"r1","timestamp":"2026-01-01T00:00:00.123Z"}`,
+		`{"type":"RUN_ERROR","message":"boom"}`,
+		`{"type":"TEXT_MESSAGE_START","messageId":"m1","role":"assistant"}`,
+		`{"type":"TEXT_MESSAGE_CHUNK","delta":"hi"}`,
+		`{"type":"TOOL_CALL_START","toolCallId":"t1","toolCallName":"Bash"}`,
+		`{"type":"TOOL_CALL_RESULT","messageId":"m2","toolCallId":"t1","content":"ok"}`,
+		`{"type":"STATE_SNAPSHOT","state":{}}`,
+		`{"type":"STATE_DELTA","delta":[{"op":"add","path":"/a","value":1}]}`,
+		`{"type":"MESSAGES_SNAPSHOT","messages":[{"id":"m1","role":"user","content":"hi"}]}`,
+		`{"type":"CUSTOM","name":"progress","value":{"pct":50}}`,
+		`{"type":"X_PROGRESS"}`,
+	} {
+		if err := Validate(decode(t, data)); err != nil {
+			t.Errorf("Validate(%s): %v", data, err)
+		}
+	}
+
+	for data, want := range map[string]string{
+		`{"type":"RUN_STARTED","runId":"r1"}`:                                 "threadId is required",
+		`{"type":"RUN_STARTED","threadId":"s1","runId":"r1","timestamp":"x"}`: "invalid timestamp",
+		`{"type":"RUN_ERROR"}`:                                                "message is required",
+		`{"type":"TEXT_MESSAGE_START","messageId":"m1","role":"robot"}`:       "invalid role",
+		`{"type":"TEXT_MESSAGE_CONTENT","messageId":"m1"}`:                    "delta is required",
+		`{"type":"TOOL_CALL_START","toolCallId":"t1"}`:                        "toolCallName is required",
+		`{"type":"TOOL_CALL_RESULT","messageId":"m2"}`:                        "toolCallId is required",
+		`{"type":"STATE_DELTA","delta":[{"op":"merge","path":"/a"}]}`:         "invalid op",
+		`{"type":"STATE_DELTA","delta":[{"op":"add","path":"a"}]}`:            "not a JSON Pointer",
+		`{"type":"MESSAGES_SNAPSHOT","messages":[{"role":"user"}]}`:           "id is required",
+		`{"type":"CUSTOM","value":1}`:                                         "name is required",
+		`{"type":"RUN_ERROR","code":137}`:                                     "fields do not match",
+	} {
+		err := Validate(decode(t, data))
+		if err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Validate(%s) = %v, want %q", data, err, want)
+		}
+	}
+}
+
+func TestStreamValidator(t *testing.T) {
+	valid := []string{
+		`{"type":"RUN_STARTED","threadId":"s1","runId":"r1"}`,
+		`{"type":"THINKING_TEXT_MESSAGE_START"}`,
+		`{"type":"THINKING_TEXT_MESSAGE_CONTENT","delta":"hmm"}`,
+		`{"type":"THINKING_TEXT_MESSAGE_END"}`,
+		`{"type":"TEXT_MESSAGE_START","messageId":"m1","role":"assistant"}`,
+		`{"type":"TOOL_CALL_START","toolCallId":"t1","toolCallName":"Bash"}`,
+		`{"type":"TEXT_MESSAGE_CONTENT","messageId":"m1","delta":"hi"}`,
+		`{"type":"TOOL_CALL_ARGS","toolCallId":"t1","delta":"{}"}`,
+		`{"type":"TOOL_CALL_END","toolCallId":"t1"}`,
+		`{"type":"TEXT_MESSAGE_END","messageId":"m1"}`,
+		`{"type":"RUN_FINISHED","threadId":"s1","runId":"r1"}`,
+	}
+	v := NewStreamValidator()
+	for _, data := range valid {
+		if err := v.Next(decode(t, data)); err != nil {
+			t.Fatalf("Next(%s): %v", data, err)
+		}
+	}
+
+	for name, stream := range map[string][]string{
+		"before RUN_STARTED": {`{"type":"TEXT_MESSAGE_START","messageId":"m1"}`},
+		"started twice": {
+			`{"type":"RUN_STARTED","threadId":"s1","runId":"r1"}`,
+			`{"type":"RUN_STARTED","threadId":"s1","runId":"r1"}`,
+		},
+		"content before start": {
+			`{"type":"RUN_STARTED","threadId":"s1","runId":"r1"}`,
+			`{"type":"TEXT_MESSAGE_CONTENT","messageId":"m1","delta":"hi"}`,
+		},
+		"args after end": {
+			`{"type":"RUN_STARTED","threadId":"s1","runId":"r1"}`,
+			`{"type":"TOOL_CALL_START","toolCallId":"t1","toolCallName":"Bash"}`,
+			`{"type":"TOOL_CALL_END","toolCallId":"t1"}`,
+			`{"type":"TOOL_CALL_ARGS","toolCallId":"t1","delta":"{}"}`,
+		},
+		"finished with open message": {
+			`{"type":"RUN_STARTED","threadId":"s1","runId":"r1"}`,
+			`{"type":"TEXT_MESSAGE_START","messageId":"m1"}`,
+			`{"type":"RUN_FINISHED","threadId":"s1","runId":"r1"}`,
+		},
+		"event after error": {
+			`{"type":"RUN_STARTED","threadId":"s1","runId":"r1"}`,
+			`{"type":"RUN_ERROR","message":"boom"}`,
+			`{"type":"STEP_STARTED","stepName":"retry"}`,
+		},
+	} {
+		v := NewStreamValidator()
+		var err error
+		for _, data := range stream {
+			if err = v.Next(decode(t, data)); err != nil {
+				break
+			}
+		}
+		if err == nil {
+			t.Errorf("%s: stream should be rejected", name)
+		}
+	}
+}
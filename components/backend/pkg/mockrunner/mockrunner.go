@@ -0,0 +1,284 @@
+// Package mockrunner is an in-process stand-in for a session runner. It speaks the
+// runner's HTTP contract (POST / streaming AG-UI events, POST /interrupt, POST
+// /feedback, GET /mcp/status, GET /version and GET /health) so backend changes can
+// be validated without deploying the Python runner.
+//
+//	runner := mockrunner.New(mockrunner.WithEventDelay(50 * time.Millisecond))
+//	server := httptest.NewServer(runner)
+//	defer server.Close()
+package mockrunner
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"ambient-code-backend/pkg/agui"
+
+	"github.com/google/uuid"
+)
+
+// ProtocolVersion is the runner protocol the mock reports on GET /version. It
+// announces no capabilities: its scripts don't echo user messages.
+const ProtocolVersion = "1"
+
+// Script returns the events a run streams for its input. The base fields the events
+// leave empty (threadId, runId, timestamp) are filled from the run.
+type Script func(input agui.RunAgentInput) []agui.TypedEvent
+
+// MCPServer is a server reported by GET /mcp/status
+type MCPServer struct {
+	Name        string    `json:"name"`
+	DisplayName string    `json:"displayName"`
+	Status      string    `json:"status"`
+	Version     string    `json:"version"`
+	Tools       []MCPTool `json:"tools"`
+}
+
+// MCPTool is a tool of an MCPServer
+type MCPTool struct {
+	Name        string                 `json:"name"`
+	Annotations map[string]interface{} `json:"annotations"`
+}
+
+// Feedback is a META event received by POST /feedback
+type Feedback struct {
+	Type     string                 `json:"type"`
+	MetaType string                 `json:"metaType"`
+	Payload  map[string]interface{} `json:"payload"`
+	ThreadID string                 `json:"threadId,omitempty"`
+	Ts       int64                  `json:"ts,omitempty"`
+}
+
+// Runner serves the runner's HTTP contract and records the requests it receives.
+// It runs one run at a time, like a runner pod.
+type Runner struct {
+	script     Script
+	delay      time.Duration
+	mcpServers []MCPServer
+	mux        *http.ServeMux
+
+	mu         sync.Mutex
+	runs       []agui.RunAgentInput
+	feedback   []Feedback
+	interrupts int
+	interrupt  context.CancelFunc // of the active run
+}
+
+// Option configures a Runner
+type Option func(*Runner)
+
+// WithScript sets the events of each run (default EchoScript)
+func WithScript(script Script) Option {
+	return func(r *Runner) {
+		r.script = script
+	}
+}
+
+// WithEventDelay pauses before each event, leaving time to interrupt a run
+func WithEventDelay(d time.Duration) Option {
+	return func(r *Runner) {
+		r.delay = d
+	}
+}
+
+// WithMCPServers sets the servers GET /mcp/status reports
+func WithMCPServers(servers []MCPServer) Option {
+	return func(r *Runner) {
+		r.mcpServers = servers
+	}
+}
+
+// New returns a Runner; serve it with httptest.NewServer or http.ListenAndServe
+func New(opts ...Option) *Runner {
+	r := &Runner{script: EchoScript, mcpServers: []MCPServer{}}
+	for _, opt := range opts {
+		opt(r)
+	}
+	r.mux = http.NewServeMux()
+	r.mux.HandleFunc("POST /{$}", r.handleRun)
+	r.mux.HandleFunc("POST /interrupt", r.handleInterrupt)
+	r.mux.HandleFunc("POST /feedback", r.handleFeedback)
+	r.mux.HandleFunc("GET /mcp/status", r.handleMCPStatus)
+	r.mux.HandleFunc("GET /version", r.handleVersion)
+	r.mux.HandleFunc("GET /health", r.handleHealth)
+	return r
+}
+
+// ServeHTTP implements http.Handler
+func (r *Runner) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.mux.ServeHTTP(w, req)
+}
+
+// Runs returns the inputs of the runs received so far
+func (r *Runner) Runs() []agui.RunAgentInput {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]agui.RunAgentInput(nil), r.runs...)
+}
+
+// Feedback returns the feedback received so far
+func (r *Runner) Feedback() []Feedback {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Feedback(nil), r.feedback...)
+}
+
+// Interrupts returns how many interrupts were received
+func (r *Runner) Interrupts() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.interrupts
+}
+
+// EchoScript answers with one assistant message echoing the last user message
+func EchoScript(input agui.RunAgentInput) []agui.TypedEvent {
+	var prompt string
+	for _, msg := range input.Messages {
+		if msg.Role == agui.RoleUser {
+			prompt = msg.Content
+		}
+	}
+	messageID := uuid.New().String()
+	return []agui.TypedEvent{
+		&agui.RunStartedEvent{BaseEvent: agui.BaseEvent{Type: agui.EventTypeRunStarted}},
+		&agui.TextMessageStartEvent{
+			BaseEvent: agui.BaseEvent{Type: agui.EventTypeTextMessageStart, MessageID: messageID},
+			Role:      agui.RoleAssistant,
+		},
+		&agui.TextMessageContentEvent{
+			BaseEvent: agui.BaseEvent{Type: agui.EventTypeTextMessageContent, MessageID: messageID},
+			Delta:     "Echo: " + prompt,
+		},
+		&agui.TextMessageEndEvent{BaseEvent: agui.BaseEvent{Type: agui.EventTypeTextMessageEnd, MessageID: messageID}},
+		&agui.RunFinishedEvent{BaseEvent: agui.BaseEvent{Type: agui.EventTypeRunFinished}},
+	}
+}
+
+// handleRun streams the script's events for a run as SSE. An interrupt stops the
+// script and ends the run with RUN_FINISHED, as the runner does when the SDK stops.
+func (r *Runner) handleRun(w http.ResponseWriter, req *http.Request) {
+	var input agui.RunAgentInput
+	if err := json.NewDecoder(req.Body).Decode(&input); err != nil {
+		writeDetail(w, http.StatusUnprocessableEntity, "invalid run input: "+err.Error())
+		return
+	}
+	if input.RunID == "" {
+		input.RunID = uuid.New().String()
+	}
+	threadID, runID := input.ThreadID, input.RunID
+
+	ctx, cancel := context.WithCancel(req.Context())
+	defer cancel()
+	r.mu.Lock()
+	r.runs = append(r.runs, input)
+	r.interrupt = cancel
+	r.mu.Unlock()
+	defer func() {
+		r.mu.Lock()
+		r.interrupt = nil
+		r.mu.Unlock()
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	send := func(payload agui.TypedEvent) bool {
+		event, err := agui.NewEvent(payload)
+		if err != nil {
+			return false
+		}
+		event.FillBase(threadID, runID, time.Now().UTC().Format(agui.AGUITimestampFormat))
+		if err := agui.WriteSSE(w, event); err != nil {
+			return false
+		}
+		if f, ok := w.(http.Flusher); ok {
+			f.Flush()
+		}
+		return true
+	}
+
+	for _, payload := range r.script(input) {
+		if r.delay > 0 {
+			select {
+			case <-ctx.Done():
+			case <-time.After(r.delay):
+			}
+		}
+		if ctx.Err() != nil {
+			if req.Context().Err() == nil {
+				send(&agui.RunFinishedEvent{BaseEvent: agui.BaseEvent{Type: agui.EventTypeRunFinished}})
+			}
+			return
+		}
+		if !send(payload) {
+			return
+		}
+	}
+}
+
+func (r *Runner) handleInterrupt(w http.ResponseWriter, req *http.Request) {
+	r.mu.Lock()
+	r.interrupts++
+	if r.interrupt != nil {
+		r.interrupt()
+	}
+	r.mu.Unlock()
+	writeJSON(w, http.StatusOK, map[string]interface{}{"message": "Interrupt signal sent to Claude SDK"})
+}
+
+func (r *Runner) handleFeedback(w http.ResponseWriter, req *http.Request) {
+	var feedback Feedback
+	if err := json.NewDecoder(req.Body).Decode(&feedback); err != nil {
+		writeDetail(w, http.StatusUnprocessableEntity, "invalid feedback: "+err.Error())
+		return
+	}
+	if feedback.Type != agui.EventTypeMeta {
+		writeDetail(w, http.StatusBadRequest, "Expected META event type")
+		return
+	}
+	if feedback.MetaType != "thumbs_up" && feedback.MetaType != "thumbs_down" {
+		writeDetail(w, http.StatusBadRequest, "metaType must be 'thumbs_up' or 'thumbs_down'")
+		return
+	}
+	r.mu.Lock()
+	r.feedback = append(r.feedback, feedback)
+	r.mu.Unlock()
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"message":  "Feedback received",
+		"metaType": feedback.MetaType,
+		"recorded": false,
+	})
+}
+
+func (r *Runner) handleMCPStatus(w http.ResponseWriter, req *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"servers":    r.mcpServers,
+		"totalCount": len(r.mcpServers),
+	})
+}
+
+func (r *Runner) handleVersion(w http.ResponseWriter, req *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"runnerVersion":   "mock",
+		"protocolVersion": ProtocolVersion,
+		"capabilities":    []string{},
+	})
+}
+
+func (r *Runner) handleHealth(w http.ResponseWriter, req *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]interface{}{"status": "healthy", "session_id": nil})
+}
+
+// writeDetail writes an error the way FastAPI does
+func writeDetail(w http.ResponseWriter, status int, detail string) {
+	writeJSON(w, status, map[string]interface{}{"detail": detail})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,142 @@
+package mockrunner
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"ambient-code-backend/pkg/agui"
+)
+
+func startRun(t *testing.T, server *httptest.Server, body string) []*agui.Event {
+	t.Helper()
+	resp, err := http.Post(server.URL+"/", "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatalf("POST /: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "text/event-stream" {
+		t.Fatalf("POST / = %d %s", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+	reader := agui.NewSSEReader(resp.Body)
+	var events []*agui.Event
+	for {
+		sse, err := reader.Next()
+		if err == io.EOF {
+			return events
+		}
+		if err != nil {
+			t.Fatalf("Next: %v", err)
+		}
+		event, err := agui.DecodeEvent(sse.Data)
+		if err != nil {
+			t.Fatalf("DecodeEvent(%s): %v", sse.Data, err)
+		}
+		events = append(events, event)
+	}
+}
+
+func TestRunEchoes(t *testing.T) {
+	runner := New()
+	server := httptest.NewServer(runner)
+	defer server.Close()
+
+	events := startRun(t, server, `{"threadId":"s1","runId":"r1","messages":[{"id":"u1","role":"user","content":"hello"}],"model":"claude-sonnet-4-5"}`)
+	v := agui.NewStreamValidator()
+	var reply string
+	for _, event := range events {
+		if err := v.Next(event.Payload); err != nil {
+			t.Errorf("stream does not conform: %v", err)
+		}
+		if base := event.Base(); base.ThreadID != "s1" || base.RunID != "r1" || base.Timestamp == "" {
+			t.Errorf("%s base fields = %+v", event.Type(), base)
+		}
+		if content, ok := event.Payload.(*agui.TextMessageContentEvent); ok {
+			reply += content.Delta
+		}
+	}
+	if len(events) != 5 || events[len(events)-1].Type() != agui.EventTypeRunFinished || reply != "Echo: hello" {
+		t.Errorf("events = %d, reply = %q", len(events), reply)
+	}
+	if runs := runner.Runs(); len(runs) != 1 || runs[0].RunID != "r1" || len(runs[0].Messages) != 1 {
+		t.Errorf("Runs() = %+v", runs)
+	}
+
+	resp, err := http.Post(server.URL+"/", "application/json", strings.NewReader(`{"messages":"nope"}`))
+	if err != nil {
+		t.Fatalf("POST /: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnprocessableEntity {
+		t.Errorf("invalid input = %d, want 422", resp.StatusCode)
+	}
+}
+
+func TestInterruptEndsRun(t *testing.T) {
+	runner := New(WithEventDelay(100 * time.Millisecond))
+	server := httptest.NewServer(runner)
+	defer server.Close()
+
+	go func() {
+		time.Sleep(150 * time.Millisecond)
+		resp, err := http.Post(server.URL+"/interrupt", "application/json", nil)
+		if err == nil {
+			resp.Body.Close()
+		}
+	}()
+	events := startRun(t, server, `{"threadId":"s1","runId":"r1"}`)
+	if len(events) == 0 || len(events) >= 5 || events[len(events)-1].Type() != agui.EventTypeRunFinished {
+		var types []string
+		for _, event := range events {
+			types = append(types, event.Type())
+		}
+		t.Errorf("interrupted run events = %v", types)
+	}
+	if runner.Interrupts() != 1 {
+		t.Errorf("Interrupts() = %d", runner.Interrupts())
+	}
+}
+
+func TestFeedbackAndMCPStatus(t *testing.T) {
+	runner := New(WithMCPServers([]MCPServer{{Name: "github", DisplayName: "GitHub", Status: "connected"}}))
+	server := httptest.NewServer(runner)
+	defer server.Close()
+
+	for body, want := range map[string]int{
+		`{"type":"META","metaType":"thumbs_up","payload":{"userId":"u1"}}`: http.StatusOK,
+		`{"type":"CUSTOM","metaType":"thumbs_up","payload":{}}`:            http.StatusBadRequest,
+		`{"type":"META","metaType":"meh","payload":{}}`:                    http.StatusBadRequest,
+	} {
+		resp, err := http.Post(server.URL+"/feedback", "application/json", strings.NewReader(body))
+		if err != nil {
+			t.Fatalf("POST /feedback: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != want {
+			t.Errorf("POST /feedback %s = %d, want %d", body, resp.StatusCode, want)
+		}
+	}
+	if feedback := runner.Feedback(); len(feedback) != 1 || feedback[0].Payload["userId"] != "u1" {
+		t.Errorf("Feedback() = %+v", feedback)
+	}
+
+	resp, err := http.Get(server.URL + "/mcp/status")
+	if err != nil {
+		t.Fatalf("GET /mcp/status: %v", err)
+	}
+	defer resp.Body.Close()
+	var status struct {
+		Servers    []MCPServer `json:"servers"`
+		TotalCount int         `json:"totalCount"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if status.TotalCount != 1 || status.Servers[0].Name != "github" {
+		t.Errorf("status = %+v", status)
+	}
+}
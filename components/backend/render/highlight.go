@@ -0,0 +1,195 @@
+package render
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+// lexer describes enough of a language to color its comments, strings, numbers and
+// keywords
+type lexer struct {
+	lineComments []string
+	blockComment [2]string
+	quotes       string
+	// rawQuote starts strings without escapes that may span lines
+	rawQuote byte
+	keywords map[string]bool
+}
+
+func words(s string) map[string]bool {
+	m := map[string]bool{}
+	for _, w := range strings.Fields(s) {
+		m[w] = true
+	}
+	return m
+}
+
+var (
+	goLexer = &lexer{lineComments: []string{"//"}, blockComment: [2]string{"/*", "*/"}, quotes: `"'`, rawQuote: '`',
+		keywords: words("break case chan const continue default defer else fallthrough for func go goto if import interface map package range return select struct switch type var nil true false iota")}
+	jsLexer = &lexer{lineComments: []string{"//"}, blockComment: [2]string{"/*", "*/"}, quotes: `"'`, rawQuote: '`',
+		keywords: words("async await break case catch class const continue default delete do else export extends finally for from function if import in instanceof interface let new null of return super switch this throw try type typeof undefined var void while yield true false enum implements private public readonly")}
+	cLexer = &lexer{lineComments: []string{"//"}, blockComment: [2]string{"/*", "*/"}, quotes: `"'`,
+		keywords: words("abstract auto bool break case catch char class const continue default delete do double else enum extends final float fn for if impl implements import int let long match mod mut namespace new null package private protected pub public return self static struct super switch this throw throws trait try use void while true false")}
+	pythonLexer = &lexer{lineComments: []string{"#"}, quotes: `"'`,
+		keywords: words("and as assert async await break class continue def del elif else except finally for from global if import in is lambda nonlocal not or pass raise return try while with yield None True False self")}
+	shellLexer = &lexer{lineComments: []string{"#"}, quotes: `"'`,
+		keywords: words("if then else elif fi for in do done while until case esac function return export local set unset echo exit")}
+	yamlLexer = &lexer{lineComments: []string{"#"}, quotes: `"'`, keywords: words("true false null yes no")}
+	sqlLexer  = &lexer{lineComments: []string{"--"}, blockComment: [2]string{"/*", "*/"}, quotes: `'"`,
+		keywords: words("select from where and or not insert into values update set delete create table index drop alter join left right inner outer on group by order having limit as null is in distinct union all case when then else end primary key SELECT FROM WHERE AND OR NOT INSERT INTO VALUES UPDATE SET DELETE CREATE TABLE INDEX DROP ALTER JOIN LEFT RIGHT INNER OUTER ON GROUP BY ORDER HAVING LIMIT AS NULL IS IN DISTINCT UNION ALL CASE WHEN THEN ELSE END PRIMARY KEY")}
+	jsonLexer = &lexer{quotes: `"`, keywords: words("true false null")}
+)
+
+// lexers maps fenced code block languages to their lexers
+var lexers = map[string]*lexer{
+	"go": goLexer, "golang": goLexer,
+	"js": jsLexer, "javascript": jsLexer, "jsx": jsLexer, "ts": jsLexer, "typescript": jsLexer, "tsx": jsLexer,
+	"c": cLexer, "cpp": cLexer, "c++": cLexer, "h": cLexer, "java": cLexer, "kotlin": cLexer, "rust": cLexer, "rs": cLexer, "cs": cLexer, "csharp": cLexer, "swift": cLexer, "scala": cLexer,
+	"python": pythonLexer, "py": pythonLexer,
+	"sh": shellLexer, "bash": shellLexer, "shell": shellLexer, "zsh": shellLexer, "console": shellLexer, "dockerfile": shellLexer, "makefile": shellLexer,
+	"yaml": yamlLexer, "yml": yamlLexer, "toml": yamlLexer, "ini": yamlLexer,
+	"sql":  sqlLexer,
+	"json": jsonLexer, "jsonc": jsonLexer,
+}
+
+// Code renders a code block, highlighted for lang when it's known. Diffs (lang diff or
+// patch, or unlabeled blocks that look like one) are rendered with Diff.
+func Code(src, lang string) string {
+	lang = strings.ToLower(lang)
+	if lang == "diff" || lang == "patch" || (lang == "" && LooksLikeDiff(src)) {
+		return Diff(src)
+	}
+	body := html.EscapeString(src)
+	if l := lexers[lang]; l != nil {
+		body = l.highlight(src)
+	}
+	return `<pre style="` + stylePre + `"><code>` + body + "</code></pre>\n"
+}
+
+func span(style, text string) string {
+	return `<span style="` + style + `">` + html.EscapeString(text) + "</span>"
+}
+
+func isIdent(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+// highlight escapes src, wrapping comments, strings, numbers and keywords in spans
+func (l *lexer) highlight(src string) string {
+	var b strings.Builder
+	for i := 0; i < len(src); {
+		rest := src[i:]
+		if l.blockComment[0] != "" && strings.HasPrefix(rest, l.blockComment[0]) {
+			end := strings.Index(rest[len(l.blockComment[0]):], l.blockComment[1])
+			n := len(rest)
+			if end >= 0 {
+				n = len(l.blockComment[0]) + end + len(l.blockComment[1])
+			}
+			b.WriteString(span(styleComment, rest[:n]))
+			i += n
+			continue
+		}
+		if l.lineComment(rest) {
+			n := strings.IndexByte(rest, '\n')
+			if n < 0 {
+				n = len(rest)
+			}
+			b.WriteString(span(styleComment, rest[:n]))
+			i += n
+			continue
+		}
+		c := src[i]
+		switch {
+		case l.rawQuote != 0 && c == l.rawQuote:
+			n := strings.IndexByte(rest[1:], c)
+			if n < 0 {
+				n = len(rest)
+			} else {
+				n += 2
+			}
+			b.WriteString(span(styleString, rest[:n]))
+			i += n
+		case strings.IndexByte(l.quotes, c) >= 0:
+			n := 1
+			for n < len(rest) && rest[n] != c && rest[n] != '\n' {
+				if rest[n] == '\\' {
+					n++
+				}
+				n++
+			}
+			if n < len(rest) && rest[n] == c {
+				n++
+			}
+			if n > len(rest) {
+				n = len(rest)
+			}
+			b.WriteString(span(styleString, rest[:n]))
+			i += n
+		case c >= '0' && c <= '9' && (i == 0 || !isIdent(src[i-1])):
+			n := 1
+			for n < len(rest) && (isIdent(rest[n]) || rest[n] == '.') {
+				n++
+			}
+			b.WriteString(span(styleNumber, rest[:n]))
+			i += n
+		case isIdent(c):
+			n := 1
+			for n < len(rest) && isIdent(rest[n]) {
+				n++
+			}
+			if l.keywords[rest[:n]] {
+				b.WriteString(span(styleKeyword, rest[:n]))
+			} else {
+				b.WriteString(html.EscapeString(rest[:n]))
+			}
+			i += n
+		default:
+			b.WriteString(html.EscapeString(rest[:1]))
+			i++
+		}
+	}
+	return b.String()
+}
+
+func (l *lexer) lineComment(s string) bool {
+	for _, prefix := range l.lineComments {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+var hunkRe = regexp.MustCompile(`(?m)^@@ -\d+(,\d+)? \+\d+(,\d+)? @@`)
+
+// LooksLikeDiff reports whether text is a unified diff
+func LooksLikeDiff(text string) bool {
+	return hunkRe.MatchString(text) || strings.HasPrefix(text, "diff --git ") ||
+		(strings.HasPrefix(text, "--- ") && strings.Contains(text, "\n+++ "))
+}
+
+// Diff renders a unified diff with added, removed and hunk lines colored
+func Diff(src string) string {
+	var b strings.Builder
+	b.WriteString(`<pre style="` + stylePre + `"><code>`)
+	for _, line := range strings.Split(strings.TrimRight(src, "\n"), "\n") {
+		switch {
+		case strings.HasPrefix(line, "diff --git ") || strings.HasPrefix(line, "index ") ||
+			strings.HasPrefix(line, "+++ ") || strings.HasPrefix(line, "--- "):
+			b.WriteString(span(styleDiffHeader, line))
+		case strings.HasPrefix(line, "@@"):
+			b.WriteString(span(styleDiffHunk, line))
+		case strings.HasPrefix(line, "+"):
+			b.WriteString(span(styleDiffAdded, line))
+		case strings.HasPrefix(line, "-"):
+			b.WriteString(span(styleDiffRemoved, line))
+		default:
+			b.WriteString(html.EscapeString(line))
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("</code></pre>\n")
+	return b.String()
+}
@@ -0,0 +1,173 @@
+// Package render turns transcripts into standalone HTML for consumers that can't run
+// the frontend's renderer, such as email notifications and PR comments. It covers the
+// markdown agents write (headings, lists, quotes, emphasis, links, code) with
+// syntax-highlighted code blocks and rendered diffs. Styles are inline because email
+// clients drop stylesheets; all input is escaped, and only http(s) and mailto links
+// are kept.
+package render
+
+import (
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Markdown renders markdown as an HTML fragment. The fragment is also well-formed
+// XHTML, so it can serve as Confluence storage format.
+func Markdown(src string) string {
+	var b strings.Builder
+	renderBlocks(&b, strings.Split(strings.ReplaceAll(src, "\r\n", "\n"), "\n"))
+	return b.String()
+}
+
+var (
+	headingRe     = regexp.MustCompile(`^(#{1,6})\s+(.*?)\s*#*\s*$`)
+	fenceRe       = regexp.MustCompile("^\\s*(```+|~~~+)\\s*([\\w+#.-]*)")
+	bulletRe      = regexp.MustCompile(`^\s*[-*+]\s+(.*)$`)
+	orderedRe     = regexp.MustCompile(`^\s*(\d{1,9})[.)]\s+(.*)$`)
+	ruleRe        = regexp.MustCompile(`^\s*([-*_])(\s*[-*_]){2,}\s*$`)
+	blockquoteRe  = regexp.MustCompile(`^\s*>\s?(.*)$`)
+	continuedItem = regexp.MustCompile(`^\s{2,}\S`)
+)
+
+func renderBlocks(b *strings.Builder, lines []string) {
+	for i := 0; i < len(lines); {
+		line := lines[i]
+		switch {
+		case strings.TrimSpace(line) == "":
+			i++
+		case fenceRe.MatchString(line):
+			m := fenceRe.FindStringSubmatch(line)
+			fence, lang := strings.TrimSpace(m[1]), m[2]
+			i++
+			var code []string
+			for ; i < len(lines); i++ {
+				if strings.HasPrefix(strings.TrimSpace(lines[i]), fence) {
+					i++
+					break
+				}
+				code = append(code, lines[i])
+			}
+			b.WriteString(Code(strings.Join(code, "\n"), lang))
+		case headingRe.MatchString(line):
+			m := headingRe.FindStringSubmatch(line)
+			level := strconv.Itoa(len(m[1]))
+			b.WriteString("<h" + level + ` style="` + styleHeading + `">` + inline(m[2]) + "</h" + level + ">\n")
+			i++
+		case ruleRe.MatchString(line):
+			b.WriteString(`<hr style="` + styleRule + `"/>` + "\n")
+			i++
+		case blockquoteRe.MatchString(line):
+			var quoted []string
+			for ; i < len(lines) && blockquoteRe.MatchString(lines[i]); i++ {
+				quoted = append(quoted, blockquoteRe.FindStringSubmatch(lines[i])[1])
+			}
+			b.WriteString(`<blockquote style="` + styleQuote + `">` + "\n")
+			renderBlocks(b, quoted)
+			b.WriteString("</blockquote>\n")
+		case bulletRe.MatchString(line), orderedRe.MatchString(line):
+			i = renderList(b, lines, i)
+		default:
+			var para []string
+			for ; i < len(lines); i++ {
+				l := lines[i]
+				if strings.TrimSpace(l) == "" || fenceRe.MatchString(l) || headingRe.MatchString(l) || blockquoteRe.MatchString(l) ||
+					bulletRe.MatchString(l) || orderedRe.MatchString(l) || (len(para) > 0 && ruleRe.MatchString(l)) {
+					break
+				}
+				para = append(para, strings.TrimSpace(l))
+			}
+			b.WriteString(`<p style="` + styleParagraph + `">` + inline(strings.Join(para, "\n")) + "</p>\n")
+		}
+	}
+}
+
+// renderList renders the list starting at lines[i] and returns the index after it.
+// Items continue on indented lines; nested lists are flattened into their item.
+func renderList(b *strings.Builder, lines []string, i int) int {
+	ordered := orderedRe.MatchString(lines[i]) && !bulletRe.MatchString(lines[i])
+	tag := "ul"
+	if ordered {
+		tag = "ol"
+		if start := orderedRe.FindStringSubmatch(lines[i])[1]; start != "1" {
+			n, _ := strconv.Atoi(start)
+			tag = `ol start="` + strconv.Itoa(n) + `"`
+		}
+	}
+	b.WriteString("<" + tag + ` style="` + styleList + `">` + "\n")
+	for i < len(lines) {
+		var item string
+		if m := bulletRe.FindStringSubmatch(lines[i]); m != nil && !ordered {
+			item = m[1]
+		} else if m := orderedRe.FindStringSubmatch(lines[i]); m != nil && ordered {
+			item = m[2]
+		} else {
+			break
+		}
+		i++
+		for i < len(lines) && continuedItem.MatchString(lines[i]) && !fenceRe.MatchString(lines[i]) {
+			l := strings.TrimSpace(lines[i])
+			if m := bulletRe.FindStringSubmatch(l); m != nil {
+				l = "• " + m[1]
+			}
+			item += "\n" + l
+			i++
+		}
+		b.WriteString("<li>" + inline(item) + "</li>\n")
+	}
+	b.WriteString("</" + strings.Fields(tag)[0] + ">\n")
+	return i
+}
+
+var (
+	linkRe   = regexp.MustCompile(`\[([^\]]+)\]\(([^)\s]+)\)`)
+	boldRe   = regexp.MustCompile(`\*\*([^*]+)\*\*|__([^_]+)__`)
+	italicRe = regexp.MustCompile(`(^|[^\w*])\*([^*\s][^*]*)\*|(^|[^\w_])_([^_\s][^_]*)_`)
+)
+
+// inline renders a paragraph's text: code spans, links, bold and italics
+func inline(text string) string {
+	var b strings.Builder
+	// Odd segments between backticks are code spans, rendered verbatim
+	parts := strings.Split(text, "`")
+	for i, part := range parts {
+		switch {
+		case i%2 == 1 && i < len(parts)-1:
+			b.WriteString(`<code style="` + styleInlineCode + `">` + html.EscapeString(part) + "</code>")
+		case i%2 == 1:
+			b.WriteString("`" + emphasis(part))
+		default:
+			b.WriteString(emphasis(part))
+		}
+	}
+	return strings.ReplaceAll(b.String(), "\n", "<br/>\n")
+}
+
+// emphasis escapes text and renders its links, bold and italics. Links are swapped for
+// placeholders while emphasis is applied so URLs are never rewritten.
+func emphasis(text string) string {
+	s := html.EscapeString(strings.ReplaceAll(text, "\x00", ""))
+	var links []string
+	s = linkRe.ReplaceAllStringFunc(s, func(m string) string {
+		parts := linkRe.FindStringSubmatch(m)
+		href := html.UnescapeString(parts[2])
+		if !strings.HasPrefix(href, "https://") && !strings.HasPrefix(href, "http://") && !strings.HasPrefix(href, "mailto:") {
+			return parts[1]
+		}
+		links = append(links, `<a href="`+html.EscapeString(href)+`" style="`+styleLink+`">`+markEmphasis(parts[1])+"</a>")
+		return "\x00" + strconv.Itoa(len(links)-1) + "\x00"
+	})
+	s = markEmphasis(s)
+	return placeholderRe.ReplaceAllStringFunc(s, func(m string) string {
+		n, _ := strconv.Atoi(strings.Trim(m, "\x00"))
+		return links[n]
+	})
+}
+
+var placeholderRe = regexp.MustCompile("\x00\\d+\x00")
+
+func markEmphasis(s string) string {
+	s = boldRe.ReplaceAllString(s, "<strong>$1$2</strong>")
+	return italicRe.ReplaceAllString(s, "$1$3<em>$2$4</em>")
+}
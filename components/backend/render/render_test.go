@@ -0,0 +1,85 @@
+package render
+
+import (
+	"strings"
+	"testing"
+
+	"ambient-code-backend/types"
+)
+
+func TestMarkdown(t *testing.T) {
+	got := Markdown("# Plan\n\nFix **the** `<bug>` in _parser.go_, see [docs](https://example.com/a_b_c) and [x](javascript:alert(1)).\n\n" +
+		"- one\n- two\n\n3. three\n\n> quoted <script>\n\n```go\n// done\nreturn \"ok\", 42\n```\n")
+	for _, want := range []string{
+		`<h1 style="`, ">Plan</h1>",
+		"<strong>the</strong>",
+		`<code style="` + styleInlineCode + `">&lt;bug&gt;</code>`,
+		"<em>parser.go</em>",
+		`<a href="https://example.com/a_b_c" style="` + styleLink + `">docs</a>`,
+		"<li>one</li>", "<li>two</li>", `<ol start="3"`,
+		"quoted &lt;script&gt;</p>\n</blockquote>",
+		`<span style="` + styleComment + `">// done</span>`,
+		`<span style="` + styleKeyword + `">return</span>`,
+		`<span style="` + styleString + `">&#34;ok&#34;</span>`,
+		`<span style="` + styleNumber + `">42</span>`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("missing %q in\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, `href="javascript`) {
+		t.Errorf("unsafe link kept:\n%s", got)
+	}
+	if strings.Contains(got, "<script>") || strings.Contains(got, "<bug>") {
+		t.Errorf("unescaped input:\n%s", got)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	diff := "--- a/main.go\n+++ b/main.go\n@@ -1,2 +1,2 @@\n package main\n-var x = 1\n+var x = 2\n"
+	if !LooksLikeDiff(diff) || LooksLikeDiff("- a list\n+ not a diff") {
+		t.Fatal("LooksLikeDiff")
+	}
+	got := Code(diff, "")
+	for _, want := range []string{
+		`<span style="` + styleDiffHeader + `">--- a/main.go</span>`,
+		`<span style="` + styleDiffHunk + `">@@ -1,2 +1,2 @@</span>`,
+		`<span style="` + styleDiffRemoved + `">-var x = 1</span>`,
+		`<span style="` + styleDiffAdded + `">+var x = 2</span>`,
+		"\n package main\n",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("missing %q in\n%s", want, got)
+		}
+	}
+}
+
+func TestTranscript(t *testing.T) {
+	messages := []types.Message{
+		{Role: types.RoleUser, Content: "Bump x"},
+		{Role: types.RoleAssistant, Content: "Done.", ToolCalls: []types.ToolCall{
+			{Name: "Edit", Args: `{"file":"main.go"}`, Result: "@@ -1 +1 @@\n-var x = 1\n+var x = 2"},
+			{Name: "Bash", Args: `{"command":"go test"}`, Error: strings.Repeat("x", maxToolOutputBytes+10)},
+			{Name: "Read", ParentToolUseID: "sub-agent"},
+		}},
+	}
+	page := Transcript(messages, Options{Title: "Session <1>"})
+	for _, want := range []string{
+		"<!DOCTYPE html>", "<title>Session &lt;1&gt;</title>",
+		">user</div>", "<p style=\"" + styleParagraph + "\">Bump x</p>",
+		">Edit</summary>", `<span style="` + styleDiffAdded + `">+var x = 2</span>`,
+		`Bash <span style="` + styleToolError + `">failed</span>`, "… (truncated)",
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("missing %q", want)
+		}
+	}
+	if strings.Contains(page, ">Read</summary>") {
+		t.Error("sub-agent tool calls must be left out")
+	}
+
+	fragment := Transcript(messages, Options{Fragment: true, OmitToolCalls: true})
+	if strings.Contains(fragment, "<html>") || strings.Contains(fragment, "<details") || !strings.HasPrefix(fragment, "<div>") {
+		t.Errorf("fragment = %s", fragment)
+	}
+}
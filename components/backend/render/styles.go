@@ -0,0 +1,31 @@
+package render
+
+// Inline styles, after GitHub's light theme
+const (
+	styleBody       = "margin:0;padding:24px;background:#ffffff;color:#1f2328;font-family:-apple-system,BlinkMacSystemFont,'Segoe UI',Helvetica,Arial,sans-serif;font-size:14px;line-height:1.5"
+	styleHeading    = "margin:16px 0 8px;font-weight:600;line-height:1.25"
+	styleParagraph  = "margin:0 0 12px"
+	styleList       = "margin:0 0 12px;padding-left:24px"
+	styleQuote      = "margin:0 0 12px;padding:0 12px;border-left:4px solid #d0d7de;color:#59636e"
+	styleRule       = "border:0;border-top:1px solid #d0d7de;margin:16px 0"
+	styleLink       = "color:#0969da"
+	styleInlineCode = "padding:2px 4px;background:#eff1f3;border-radius:4px;font-family:ui-monospace,SFMono-Regular,Menlo,Consolas,monospace;font-size:85%"
+	stylePre        = "margin:0 0 12px;padding:12px;background:#f6f8fa;border:1px solid #d0d7de;border-radius:6px;overflow:auto;font-family:ui-monospace,SFMono-Regular,Menlo,Consolas,monospace;font-size:12px;line-height:1.45;white-space:pre"
+
+	styleKeyword = "color:#cf222e"
+	styleString  = "color:#0a3069"
+	styleComment = "color:#6e7781;font-style:italic"
+	styleNumber  = "color:#0550ae"
+
+	styleDiffAdded   = "background:#e6ffec;color:#116329"
+	styleDiffRemoved = "background:#ffebe9;color:#82071e"
+	styleDiffHunk    = "background:#ddf4ff;color:#8250df"
+	styleDiffHeader  = "font-weight:600"
+
+	styleMessage     = "margin:0 0 16px;padding:12px 16px;border:1px solid #d0d7de;border-radius:6px"
+	styleUserMessage = "margin:0 0 16px;padding:12px 16px;border:1px solid #54aeff;border-radius:6px;background:#f6fbff"
+	styleRole        = "margin:0 0 8px;font-size:12px;font-weight:600;color:#59636e;text-transform:uppercase"
+	styleToolCall    = "margin:0 0 12px;padding:8px 12px;background:#f6f8fa;border-radius:6px"
+	styleToolSummary = "font-family:ui-monospace,SFMono-Regular,Menlo,Consolas,monospace;font-size:12px;cursor:pointer"
+	styleToolError   = "color:#d1242f"
+)
@@ -0,0 +1,124 @@
+package render
+
+import (
+	"encoding/json"
+	"html"
+	"strings"
+	"unicode/utf8"
+
+	"ambient-code-backend/types"
+)
+
+// maxToolOutputBytes bounds each tool call's arguments and result in a rendered
+// transcript; the rest is elided
+const maxToolOutputBytes = 16 << 10
+
+// Options control how a transcript is rendered
+type Options struct {
+	// Title heads the document, e.g. the session's display name
+	Title string
+	// Fragment renders only the transcript's <div>, for embedding in another
+	// document such as an email body or a PR comment
+	Fragment bool
+	// OmitToolCalls leaves tool calls out, keeping what users and the agent said
+	OmitToolCalls bool
+}
+
+// Transcript renders a conversation as HTML: a standalone document, or a fragment
+// with Options.Fragment. Messages render as markdown; tool calls as collapsible
+// blocks with their arguments and results, diffs included.
+func Transcript(messages []types.Message, opts Options) string {
+	var b strings.Builder
+	if !opts.Fragment {
+		b.WriteString("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n")
+		b.WriteString("<meta name=\"viewport\" content=\"width=device-width, initial-scale=1\">\n")
+		b.WriteString("<title>" + html.EscapeString(opts.Title) + "</title>\n</head>\n")
+		b.WriteString(`<body style="` + styleBody + `">` + "\n")
+	}
+	b.WriteString("<div>\n")
+	if opts.Title != "" {
+		b.WriteString(`<h1 style="` + styleHeading + `">` + html.EscapeString(opts.Title) + "</h1>\n")
+	}
+	for _, msg := range messages {
+		renderMessage(&b, msg, opts)
+	}
+	b.WriteString("</div>\n")
+	if !opts.Fragment {
+		b.WriteString("</body>\n</html>\n")
+	}
+	return b.String()
+}
+
+func renderMessage(b *strings.Builder, msg types.Message, opts Options) {
+	var toolCalls []types.ToolCall
+	if !opts.OmitToolCalls {
+		for _, tc := range msg.ToolCalls {
+			// Sub-agent calls are summarized by the call that started the sub-agent
+			if tc.ParentToolUseID == "" {
+				toolCalls = append(toolCalls, tc)
+			}
+		}
+	}
+	if strings.TrimSpace(msg.Content) == "" && len(toolCalls) == 0 {
+		return
+	}
+	if msg.Role == types.RoleTool && opts.OmitToolCalls {
+		return
+	}
+
+	style := styleMessage
+	if msg.Role == types.RoleUser {
+		style = styleUserMessage
+	}
+	b.WriteString(`<div style="` + style + `">` + "\n")
+	b.WriteString(`<div style="` + styleRole + `">` + html.EscapeString(msg.Role) + "</div>\n")
+	if msg.Role == types.RoleTool {
+		b.WriteString(toolOutput(msg.Content, ""))
+	} else if strings.TrimSpace(msg.Content) != "" {
+		b.WriteString(Markdown(msg.Content))
+	}
+	for _, tc := range toolCalls {
+		renderToolCall(b, tc)
+	}
+	b.WriteString("</div>\n")
+}
+
+func renderToolCall(b *strings.Builder, tc types.ToolCall) {
+	b.WriteString(`<details style="` + styleToolCall + `">` + "\n")
+	summary := html.EscapeString(tc.Name)
+	if tc.Error != "" {
+		summary += ` <span style="` + styleToolError + `">failed</span>`
+	}
+	b.WriteString(`<summary style="` + styleToolSummary + `">` + summary + "</summary>\n")
+	if args := strings.TrimSpace(tc.Args); args != "" && args != "{}" {
+		var pretty json.RawMessage
+		lang := "json"
+		if json.Unmarshal([]byte(args), &pretty) == nil {
+			if indented, err := json.MarshalIndent(pretty, "", "  "); err == nil {
+				args = string(indented)
+			}
+		} else {
+			lang = ""
+		}
+		b.WriteString(toolOutput(args, lang))
+	}
+	if tc.Error != "" {
+		b.WriteString(toolOutput(tc.Error, ""))
+	} else if tc.Result != "" {
+		b.WriteString(toolOutput(tc.Result, ""))
+	}
+	b.WriteString("</details>\n")
+}
+
+// toolOutput renders tool arguments or results as a code block, truncated to
+// maxToolOutputBytes; diffs are rendered as such
+func toolOutput(text, lang string) string {
+	if len(text) > maxToolOutputBytes {
+		cut := maxToolOutputBytes
+		for cut > 0 && !utf8.RuneStart(text[cut]) {
+			cut--
+		}
+		text = text[:cut] + "\n… (truncated)"
+	}
+	return Code(text, lang)
+}
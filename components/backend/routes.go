@@ -2,101 +2,204 @@ package main
 
 import (
 	"ambient-code-backend/handlers"
+	"ambient-code-backend/mcpserver"
+	"ambient-code-backend/metrics"
 	"ambient-code-backend/websocket"
 
 	"github.com/gin-gonic/gin"
 )
 
 func registerContentRoutes(r *gin.Engine) {
+	r.Use(handlers.RequestIDMiddleware())
 	r.POST("/content/write", handlers.ContentWrite)
 	r.GET("/content/file", handlers.ContentRead)
 	r.GET("/content/list", handlers.ContentList)
 	r.DELETE("/content/delete", handlers.ContentDelete)
 	r.GET("/content/git-status", handlers.ContentGitStatus)
+	r.GET("/content/git-diff", handlers.ContentGitWorkspaceDiff)
+	r.POST("/content/git-create-branch", handlers.ContentGitCreateBranch)
+	r.POST("/content/git-push", handlers.ContentGitPushToBranch)
 	r.POST("/content/git-configure-remote", handlers.ContentGitConfigureRemote)
 	r.GET("/content/workflow-metadata", handlers.ContentWorkflowMetadata)
-	// Removed: All manual git operation endpoints - agent handles all git operations
+	// Removed: manual git operation endpoints - agent handles most git operations
 	// - /content/github/push, /content/github/abandon, /content/github/diff
-	// - /content/git-pull, /content/git-push, /content/git-sync
-	// - /content/git-create-branch, /content/git-list-branches
+	// - /content/git-pull, /content/git-sync, /content/git-list-branches
+}
+
+func registerMirrorRoutes(r *gin.Engine) {
+	r.Use(handlers.RequestIDMiddleware())
+	r.POST("/mirror/sync", handlers.MirrorSync)
+	r.GET("/mirror/status", handlers.MirrorStatus)
+	r.Any("/mirror/git/*path", handlers.MirrorGitHTTP)
 }
 
 func registerRoutes(r *gin.Engine) {
+	// Every request gets an ID, echoed in X-Request-ID and attached to its logs
+	r.Use(handlers.RequestIDMiddleware())
+	// Error responses carry a machine-readable code, retryability and the request ID
+	r.Use(handlers.StructuredErrors())
+
 	// API routes
-	api := r.Group("/api")
+	api := r.Group("/api", handlers.AuditLog(), handlers.APIKeyAuth(), handlers.SessionTokenAuth(), handlers.AccessCacheBuster())
 	{
+		// Credential connect/test endpoints call external providers; they share one per-user budget
+		validateCreds := handlers.RateLimit(handlers.RateLimitCredentialValidation)
+		// Capabilities still being rolled out; see handlers.FeatureFlagsConfigMap
+		linear := handlers.RequireFeature(handlers.FeatureLinear)
+		toolPolicy := handlers.RequireFeature(handlers.FeatureMCPToolPolicy)
+		guardrails := handlers.RequireFeature(handlers.FeatureGuardrails)
+
 		// Public endpoints (no auth required)
 		api.GET("/workflows/ootb", handlers.ListOOTBWorkflows)
 
 		api.POST("/projects/:projectName/agentic-sessions/:sessionName/github/token", handlers.MintSessionGitHubToken)
 
+		// Git provider and PagerDuty webhooks (authenticated by signature / shared token, not user tokens)
+		api.POST("/webhooks/github", handlers.HandleGitHubWebhook)
+		api.POST("/webhooks/gitlab", handlers.HandleGitLabWebhook)
+		api.POST("/webhooks/pagerduty", handlers.HandlePagerDutyWebhook)
+
+		// MCP server exposing platform operations as tools; tool calls are dispatched
+		// back through this router with the caller's credentials
+		mcp := mcpserver.NewHTTPHandlers(mcpserver.NewServer(GitVersion), r)
+		api.POST("/mcp", mcp.Streamable)
+		api.GET("/mcp/sse", mcp.SSE)
+		api.POST("/mcp/messages", mcp.Messages)
+
 		projectGroup := api.Group("/projects/:projectName", handlers.ValidateProjectContext())
 		{
 			projectGroup.GET("/access", handlers.AccessCheck)
+			projectGroup.GET("/feature-flags", handlers.ListProjectFeatureFlags)
 			projectGroup.GET("/integration-status", handlers.GetProjectIntegrationStatus)
+			projectGroup.GET("/integrations/status", handlers.RequireProjectAdmin(), handlers.GetProjectMembersIntegrationsStatus)
 			projectGroup.GET("/users/forks", handlers.ListUserForks)
 			projectGroup.POST("/users/forks", handlers.CreateUserFork)
 
 			projectGroup.GET("/repo/tree", handlers.GetRepoTree)
 			projectGroup.GET("/repo/blob", handlers.GetRepoBlob)
 			projectGroup.GET("/repo/branches", handlers.ListRepoBranches)
+			projectGroup.GET("/git/branch-protection", handlers.CheckBranchProtection)
 			projectGroup.GET("/repo/seed-status", handlers.GetRepoSeedStatus)
 			projectGroup.POST("/repo/seed", handlers.SeedRepositoryEndpoint)
 
 			projectGroup.GET("/agentic-sessions", handlers.ListSessions)
-			projectGroup.POST("/agentic-sessions", handlers.CreateSession)
-			projectGroup.GET("/agentic-sessions/:sessionName", handlers.GetSession)
-			projectGroup.PUT("/agentic-sessions/:sessionName", handlers.UpdateSession)
-			projectGroup.PATCH("/agentic-sessions/:sessionName", handlers.PatchSession)
-			projectGroup.DELETE("/agentic-sessions/:sessionName", handlers.DeleteSession)
-			projectGroup.POST("/agentic-sessions/:sessionName/clone", handlers.CloneSession)
-			projectGroup.POST("/agentic-sessions/:sessionName/start", handlers.StartSession)
-			projectGroup.POST("/agentic-sessions/:sessionName/stop", handlers.StopSession)
-			projectGroup.GET("/agentic-sessions/:sessionName/workspace", handlers.ListSessionWorkspace)
-			projectGroup.GET("/agentic-sessions/:sessionName/workspace/*path", handlers.GetSessionWorkspaceFile)
-			projectGroup.PUT("/agentic-sessions/:sessionName/workspace/*path", handlers.PutSessionWorkspaceFile)
-			projectGroup.DELETE("/agentic-sessions/:sessionName/workspace/*path", handlers.DeleteSessionWorkspaceFile)
-			// Removed: github/push, github/abandon, github/diff - agent handles all git operations
-			projectGroup.GET("/agentic-sessions/:sessionName/git/status", handlers.GetGitStatus)
-			projectGroup.POST("/agentic-sessions/:sessionName/git/configure-remote", handlers.ConfigureGitRemote)
-			// Removed: git/pull, git/push, git/synchronize, git/create-branch, git/list-branches - agent handles all git operations
-			projectGroup.GET("/agentic-sessions/:sessionName/git/list-branches", handlers.GitListBranchesSession)
-			projectGroup.GET("/agentic-sessions/:sessionName/k8s-resources", handlers.GetSessionK8sResources)
-			projectGroup.POST("/agentic-sessions/:sessionName/workflow", handlers.SelectWorkflow)
-			projectGroup.GET("/agentic-sessions/:sessionName/workflow/metadata", handlers.GetWorkflowMetadata)
-			projectGroup.POST("/agentic-sessions/:sessionName/repos", handlers.AddRepo)
-			// NOTE: /repos/status must come BEFORE /repos/:repoName to avoid wildcard matching
-			projectGroup.GET("/agentic-sessions/:sessionName/repos/status", handlers.GetReposStatus)
-			projectGroup.DELETE("/agentic-sessions/:sessionName/repos/:repoName", handlers.RemoveRepo)
-			projectGroup.PUT("/agentic-sessions/:sessionName/displayname", handlers.UpdateSessionDisplayName)
-
-			// OAuth integration - requires user auth like all other session endpoints
-			projectGroup.GET("/agentic-sessions/:sessionName/oauth/:provider/url", handlers.GetOAuthURL)
-
-			// AG-UI Protocol endpoints (HttpAgent-compatible)
-			// See: https://docs.ag-ui.com/quickstart/introduction
-			// Runner is a FastAPI server - backend proxies requests and streams SSE responses
-			projectGroup.POST("/agentic-sessions/:sessionName/agui/run", websocket.HandleAGUIRunProxy)
-			projectGroup.POST("/agentic-sessions/:sessionName/agui/interrupt", websocket.HandleAGUIInterrupt)
-			projectGroup.POST("/agentic-sessions/:sessionName/agui/feedback", websocket.HandleAGUIFeedback)
-			projectGroup.GET("/agentic-sessions/:sessionName/agui/events", websocket.HandleAGUIEvents)
-			projectGroup.GET("/agentic-sessions/:sessionName/agui/history", websocket.HandleAGUIHistory)
-			projectGroup.GET("/agentic-sessions/:sessionName/agui/runs", websocket.HandleAGUIRuns)
-
-			// MCP status endpoint
-			projectGroup.GET("/agentic-sessions/:sessionName/mcp/status", websocket.HandleMCPStatus)
-
-			// Runtime credential fetch endpoints (for long-running sessions)
-			projectGroup.GET("/agentic-sessions/:sessionName/credentials/github", handlers.GetGitHubTokenForSession)
-			projectGroup.GET("/agentic-sessions/:sessionName/credentials/google", handlers.GetGoogleCredentialsForSession)
-			projectGroup.GET("/agentic-sessions/:sessionName/credentials/jira", handlers.GetJiraCredentialsForSession)
-			projectGroup.GET("/agentic-sessions/:sessionName/credentials/gitlab", handlers.GetGitLabTokenForSession)
-
-			// Session export
-			projectGroup.GET("/agentic-sessions/:sessionName/export", websocket.HandleExportSession)
+			projectGroup.POST("/agentic-sessions", handlers.RateLimit(handlers.RateLimitSessionCreate), handlers.CreateSession)
+			projectGroup.GET("/mcp/status", websocket.HandleProjectMCPStatus)
+			projectGroup.GET("/mcp/analytics", websocket.HandleToolUsageAnalytics)
+			projectGroup.GET("/feedback/analytics", websocket.HandleFeedbackAnalytics)
+			projectGroup.GET("/feedback/export", websocket.HandleFeedbackExport)
+			projectGroup.GET("/analytics/export", websocket.HandleAnalyticsExport)
+			projectGroup.GET("/cost-report", websocket.HandleProjectCostReport)
+			projectGroup.GET("/run-queue", websocket.HandleRunQueue)
+			projectGroup.GET("/board", handlers.GetSessionBoard)
+			projectGroup.GET("/evals", websocket.HandleListEvals)
+			projectGroup.POST("/evals", websocket.HandleCreateEval)
+			projectGroup.GET("/evals/:evalId", websocket.HandleGetEval)
+			projectGroup.GET("/workflows", websocket.HandleListWorkflows)
+			projectGroup.POST("/workflows", websocket.HandleCreateWorkflow)
+			projectGroup.GET("/workflows/templates", websocket.HandleListWorkflowTemplates)
+			projectGroup.GET("/workflows/:workflowId", websocket.HandleGetWorkflow)
+			projectGroup.GET("/quarantine", websocket.HandleListQuarantined)
+			projectGroup.GET("/quarantine/:quarantineId", websocket.HandleGetQuarantined)
+
+			// Every route under a session requires get on it; mutating routes also require
+			// update (or delete), and routes returning conversation content require get on
+			// agenticsessions/transcripts. Register new session endpoints here so they cannot skip authz.
+			update := handlers.RequireSessionAccess("update")
+			transcripts := handlers.RequireTranscriptAccess()
+			observe := handlers.RequireObserveAccess()
+			session := projectGroup.Group("/agentic-sessions/:sessionName", handlers.RequireSessionAccess("get"))
+			{
+				session.GET("", handlers.GetSession)
+				session.GET("/conditions", handlers.GetSessionConditions)
+				session.PUT("", update, handlers.UpdateSession)
+				session.PATCH("", update, handlers.PatchSession)
+				session.DELETE("", handlers.RequireSessionAccess("delete"), handlers.DeleteSession)
+				session.POST("/clone", handlers.RateLimit(handlers.RateLimitSessionCreate), handlers.CloneSession)
+				session.POST("/start", update, handlers.StartSession)
+				session.POST("/stop", update, handlers.StopSession)
+				session.GET("/workspace", handlers.ListSessionWorkspace)
+				session.POST("/workspace/expand", update, handlers.ExpandSessionWorkspace)
+				session.GET("/workspace/*path", handlers.GetSessionWorkspaceFile)
+				session.PUT("/workspace/*path", update, handlers.PutSessionWorkspaceFile)
+				session.DELETE("/workspace/*path", update, handlers.DeleteSessionWorkspaceFile)
+				// Removed: github/push, github/abandon, github/diff - agent handles all git operations
+				session.GET("/git/status", handlers.GetGitStatus)
+				session.GET("/git/diff", handlers.GetGitDiff)
+				session.POST("/git/configure-remote", update, handlers.ConfigureGitRemote)
+				// Removed: git/pull, git/synchronize - agent handles those git operations
+				session.GET("/git/list-branches", handlers.GitListBranchesSession)
+				session.POST("/git/pull-requests", update, handlers.CreateSessionPullRequest)
+				session.POST("/git/branches", update, handlers.CreateSessionGitBranch)
+				session.POST("/git/push", update, handlers.PushSessionGitBranch)
+				session.POST("/linear/issues", linear, update, handlers.CreateSessionLinearIssue)
+				session.POST("/linear/issues/link", linear, update, handlers.LinkSessionLinearIssue)
+				session.POST("/publish/confluence", update, transcripts, handlers.PublishSessionConfluence)
+				session.GET("/k8s-resources", handlers.GetSessionK8sResources)
+				session.POST("/workflow", update, handlers.SelectWorkflow)
+				session.GET("/workflow/metadata", handlers.GetWorkflowMetadata)
+				session.POST("/repos", update, handlers.AddRepo)
+				// NOTE: /repos/status must come BEFORE /repos/:repoName to avoid wildcard matching
+				session.GET("/repos/status", handlers.GetReposStatus)
+				session.DELETE("/repos/:repoName", update, handlers.RemoveRepo)
+				session.PUT("/displayname", update, handlers.UpdateSessionDisplayName)
+				session.PUT("/board", update, handlers.MoveSessionOnBoard)
+
+				// OAuth integration - requires user auth like all other session endpoints
+				session.GET("/oauth/:provider/url", handlers.GetOAuthURL)
+
+				// AG-UI Protocol endpoints (HttpAgent-compatible)
+				// See: https://docs.ag-ui.com/quickstart/introduction
+				// Runner is a FastAPI server - backend proxies requests and streams SSE responses
+				// Short-lived token limited to this session's AG-UI endpoints, for the frontend
+				session.POST("/session-token", update, handlers.CreateSessionToken)
+				session.POST("/agui/run", update, handlers.RateLimit(handlers.RateLimitRunCreate), websocket.HandleAGUIRunProxy)
+				session.POST("/agui/voice", update, handlers.RateLimit(handlers.RateLimitRunCreate), websocket.HandleAGUIVoiceRun)
+				session.POST("/agui/interrupt", update, websocket.HandleAGUIInterrupt)
+				session.POST("/agui/feedback", update, websocket.HandleAGUIFeedback)
+				session.POST("/agui/attachments", update, websocket.HandleUploadAttachments)
+				session.GET("/agui/attachments/:attachmentId", transcripts, websocket.HandleGetAttachment)
+				session.POST("/context/google-drive", update, websocket.HandleImportGoogleDoc)
+				session.GET("/agui/annotations", transcripts, websocket.HandleListAnnotations)
+				session.POST("/agui/annotations", update, websocket.HandleAddAnnotation)
+				session.DELETE("/agui/annotations/:annotationId", update, websocket.HandleDeleteAnnotation)
+				session.GET("/agui/events", transcripts, websocket.HandleAGUIEvents)
+				// Live-only stream for observers, who can't act on the session
+				session.GET("/agui/observe", observe, websocket.HandleAGUIObserve)
+				session.GET("/agui/presence", websocket.HandleGetPresence)
+				session.POST("/agui/presence", update, websocket.HandleUpdatePresence)
+				session.GET("/agui/history", transcripts, websocket.HandleAGUIHistory)
+				session.GET("/agui/messages", transcripts, websocket.HandleAGUIMessages)
+				session.GET("/agui/compactions", transcripts, websocket.HandleAGUICompactions)
+				session.GET("/agui/runs", transcripts, websocket.HandleAGUIRuns)
+				session.GET("/agui/runs/compare", transcripts, websocket.HandleAGUIRunCompare)
+				session.GET("/agui/runs/tree", transcripts, websocket.HandleAGUIRunTree)
+				session.GET("/agui/runs/:runId/tree", transcripts, websocket.HandleAGUIRunLineage)
+				session.GET("/agui/runs/:runId/replay", transcripts, websocket.HandleAGUIRunReplay)
+				// Runner registers sub-agent runs it streams within a run
+				session.POST("/agui/runs/:runId/children", update, websocket.HandleRegisterChildRun)
+
+				session.GET("/mcp/status", websocket.HandleMCPStatus)
+
+				// Runtime credential fetch endpoints (for long-running sessions). They require
+				// update, which runner tokens and session owners have, so read-only callers such
+				// as observers never reach them
+				session.GET("/credentials/github", update, handlers.GetGitHubTokenForSession)
+				session.GET("/credentials/google", update, handlers.GetGoogleCredentialsForSession)
+				session.GET("/credentials/jira", update, handlers.GetJiraCredentialsForSession)
+				session.GET("/credentials/gitlab", update, handlers.GetGitLabTokenForSession)
+				session.GET("/credentials/linear", linear, update, handlers.GetLinearCredentialsForSession)
+				session.GET("/credentials/signing-key", update, handlers.GetSigningKeyForSession)
+				session.GET("/credentials/mcp/:serverName", update, handlers.GetMCPServerTokenForSession)
+
+				// Session export
+				session.GET("/export", transcripts, websocket.HandleExportSession)
+				session.GET("/export/html", transcripts, websocket.HandleRenderSession)
+			}
 
 			projectGroup.GET("/permissions", handlers.ListProjectPermissions)
 			projectGroup.POST("/permissions", handlers.AddProjectPermission)
+			projectGroup.PUT("/permissions/:subjectType/:subjectName", handlers.UpdateProjectPermission)
 			projectGroup.DELETE("/permissions/:subjectType/:subjectName", handlers.RemoveProjectPermission)
 
 			projectGroup.GET("/keys", handlers.ListProjectKeys)
@@ -106,12 +209,61 @@ func registerRoutes(r *gin.Engine) {
 			projectGroup.GET("/secrets", handlers.ListNamespaceSecrets)
 			projectGroup.GET("/runner-secrets", handlers.ListRunnerSecrets)
 			projectGroup.PUT("/runner-secrets", handlers.UpdateRunnerSecrets)
+			projectGroup.GET("/signing-key", handlers.GetProjectSigningKey)
+			projectGroup.PUT("/signing-key", handlers.PutProjectSigningKey)
+			projectGroup.DELETE("/signing-key", handlers.DeleteProjectSigningKey)
+			projectGroup.GET("/mcp-servers", handlers.ListMCPServers)
+			projectGroup.POST("/mcp-servers", handlers.CreateMCPServer)
+			projectGroup.GET("/mcp-servers/:serverName", handlers.GetMCPServer)
+			projectGroup.PUT("/mcp-servers/:serverName", handlers.UpdateMCPServer)
+			projectGroup.DELETE("/mcp-servers/:serverName", handlers.DeleteMCPServer)
+			projectGroup.GET("/prompts", handlers.ListPromptTemplates)
+			projectGroup.POST("/prompts", handlers.CreatePromptTemplate)
+			projectGroup.GET("/prompts/:promptName", handlers.GetPromptTemplate)
+			projectGroup.PUT("/prompts/:promptName", handlers.UpdatePromptTemplate)
+			projectGroup.DELETE("/prompts/:promptName", handlers.DeletePromptTemplate)
+			projectGroup.POST("/prompts/:promptName/render", handlers.RenderPromptTemplatePreview)
+			projectGroup.GET("/shared-workspace", handlers.ListSharedWorkspace)
+			projectGroup.GET("/shared-workspace/*path", handlers.GetSharedWorkspaceFile)
+			projectGroup.PUT("/shared-workspace/*path", handlers.PutSharedWorkspaceFile)
+			projectGroup.DELETE("/shared-workspace/*path", handlers.DeleteSharedWorkspaceFile)
+			projectGroup.GET("/personas", handlers.ListPersonas)
+			projectGroup.POST("/personas", handlers.CreatePersona)
+			projectGroup.GET("/personas/:personaName", handlers.GetPersona)
+			projectGroup.PUT("/personas/:personaName", handlers.UpdatePersona)
+			projectGroup.DELETE("/personas/:personaName", handlers.DeletePersona)
+			projectGroup.GET("/mcp-tool-policy", toolPolicy, handlers.GetMCPToolPolicy)
+			projectGroup.PUT("/mcp-tool-policy", toolPolicy, handlers.UpdateMCPToolPolicy)
+			projectGroup.DELETE("/mcp-tool-policy", toolPolicy, handlers.DeleteMCPToolPolicy)
+			projectGroup.GET("/guardrail-policy", guardrails, handlers.GetGuardrailPolicy)
+			projectGroup.PUT("/guardrail-policy", guardrails, handlers.UpdateGuardrailPolicy)
+			projectGroup.DELETE("/guardrail-policy", guardrails, handlers.DeleteGuardrailPolicy)
+			projectGroup.GET("/egress-policy", handlers.GetEgressPolicy)
+			projectGroup.PUT("/egress-policy", handlers.UpdateEgressPolicy)
+			projectGroup.DELETE("/egress-policy", handlers.DeleteEgressPolicy)
+			projectGroup.GET("/content-moderation", handlers.GetContentModeration)
+			projectGroup.PUT("/content-moderation", handlers.UpdateContentModeration)
+			projectGroup.DELETE("/content-moderation", handlers.DeleteContentModeration)
+			projectGroup.GET("/transcript-encryption", handlers.GetTranscriptEncryption)
+			projectGroup.PUT("/transcript-encryption", handlers.UpdateTranscriptEncryption)
+			projectGroup.GET("/placement-policy", handlers.GetPlacementPolicy)
+			projectGroup.PUT("/placement-policy", handlers.UpdatePlacementPolicy)
+			projectGroup.DELETE("/placement-policy", handlers.DeletePlacementPolicy)
+			projectGroup.GET("/token-quota", handlers.GetTokenQuota)
+			projectGroup.PUT("/token-quota", handlers.UpdateTokenQuota)
+			projectGroup.DELETE("/token-quota", handlers.DeleteTokenQuota)
+			projectGroup.POST("/token-quota/overrides", handlers.GrantTokenQuotaOverride)
+			projectGroup.GET("/notifications", handlers.GetNotifications)
+			projectGroup.PUT("/notifications/rules", handlers.UpdateNotificationRules)
+			projectGroup.PUT("/notifications/webhooks/:name", handlers.PutNotificationWebhook)
+			projectGroup.DELETE("/notifications/webhooks/:name", handlers.DeleteNotificationWebhook)
+			projectGroup.POST("/notifications/webhooks/:name/test", handlers.TestNotificationWebhook)
 			projectGroup.GET("/integration-secrets", handlers.ListIntegrationSecrets)
 			projectGroup.PUT("/integration-secrets", handlers.UpdateIntegrationSecrets)
 
 			// GitLab authentication endpoints (DEPRECATED - moved to cluster-scoped)
 			// Kept for backward compatibility, will be removed in future version
-			projectGroup.POST("/auth/gitlab/connect", handlers.ConnectGitLabGlobal)
+			projectGroup.POST("/auth/gitlab/connect", validateCreds, handlers.ConnectGitLabGlobal)
 			projectGroup.GET("/auth/gitlab/status", handlers.GetGitLabStatusGlobal)
 			projectGroup.POST("/auth/gitlab/disconnect", handlers.DisconnectGitLabGlobal)
 		}
@@ -122,7 +274,7 @@ func registerRoutes(r *gin.Engine) {
 		api.GET("/auth/github/user/callback", handlers.HandleGitHubUserOAuthCallback)
 
 		// GitHub PAT (alternative to GitHub App)
-		api.POST("/auth/github/pat", handlers.SaveGitHubPAT)
+		api.POST("/auth/github/pat", validateCreds, handlers.SaveGitHubPAT)
 		api.GET("/auth/github/pat/status", handlers.GetGitHubPATStatus)
 		api.DELETE("/auth/github/pat", handlers.DeleteGitHubPAT)
 
@@ -135,16 +287,48 @@ func registerRoutes(r *gin.Engine) {
 		api.GET("/auth/integrations/status", handlers.GetIntegrationsStatus)
 
 		// Cluster-level Jira (user-scoped)
-		api.POST("/auth/jira/connect", handlers.ConnectJira)
+		api.POST("/auth/jira/connect", validateCreds, handlers.ConnectJira)
 		api.GET("/auth/jira/status", handlers.GetJiraStatus)
 		api.DELETE("/auth/jira/disconnect", handlers.DisconnectJira)
-		api.POST("/auth/jira/test", handlers.TestJiraConnection)
+		api.POST("/auth/jira/test", validateCreds, handlers.TestJiraConnection)
+
+		// Cluster-level Linear (user-scoped)
+		api.POST("/auth/linear/connect", linear, validateCreds, handlers.ConnectLinear)
+		api.GET("/auth/linear/status", linear, handlers.GetLinearStatus)
+		api.DELETE("/auth/linear/disconnect", linear, handlers.DisconnectLinear)
+		api.POST("/auth/linear/test", linear, validateCreds, handlers.TestLinearConnection)
+
+		// Commit signing key (SSH, GPG, or gitsign keyless)
+		api.POST("/auth/signing-key/connect", handlers.ConnectSigningKey)
+		api.GET("/auth/signing-key/status", handlers.GetSigningKeyStatus)
+		api.DELETE("/auth/signing-key/disconnect", handlers.DisconnectSigningKey)
 
 		// Cluster-level GitLab (user-scoped)
-		api.POST("/auth/gitlab/connect", handlers.ConnectGitLabGlobal)
+		api.POST("/auth/gitlab/connect", validateCreds, handlers.ConnectGitLabGlobal)
 		api.GET("/auth/gitlab/status", handlers.GetGitLabStatusGlobal)
 		api.DELETE("/auth/gitlab/disconnect", handlers.DisconnectGitLabGlobal)
-		api.POST("/auth/gitlab/test", handlers.TestGitLabConnection)
+		api.POST("/auth/gitlab/test", validateCreds, handlers.TestGitLabConnection)
+
+		// API keys for programmatic access (scripts, CI)
+		api.GET("/api-keys", handlers.ListAPIKeys)
+		api.POST("/api-keys", handlers.CreateAPIKey)
+		api.DELETE("/api-keys/:keyId", handlers.RevokeAPIKey)
+
+		// Platform operator endpoints (cluster-admin only)
+		admin := api.Group("/admin", handlers.RequireClusterAdmin())
+		{
+			admin.GET("/agentic-sessions", handlers.ListAllSessions)
+			admin.GET("/audit", handlers.QueryAuditLog)
+			admin.GET("/config", handlers.GetAdminConfig)
+			admin.GET("/cost-report", websocket.HandleAdminCostReport)
+			admin.GET("/feature-flags", handlers.ListFeatureFlags)
+			admin.GET("/runner-clusters", handlers.ListRunnerClusters)
+			admin.PUT("/runner-clusters/:clusterName", handlers.RegisterRunnerCluster)
+			admin.DELETE("/runner-clusters/:clusterName", handlers.DeleteRunnerCluster)
+		}
+
+		// Right-to-erasure: purge a user's credentials, sessions and audit references (cluster-admin only)
+		api.DELETE("/users/:userId/data", handlers.RequireClusterAdmin(), handlers.EraseUserData)
 
 		// Cluster info endpoint (public, no auth required)
 		api.GET("/cluster-info", handlers.GetClusterInfo)
@@ -156,8 +340,14 @@ func registerRoutes(r *gin.Engine) {
 		api.DELETE("/projects/:projectName", handlers.DeleteProject)
 	}
 
-	// Health check endpoint
+	// Health check endpoints: /health is kept for existing clients; probes use
+	// /healthz (liveness) and /readyz (readiness, with dependency checks)
 	r.GET("/health", handlers.Health)
+	r.GET("/healthz", handlers.Healthz)
+	r.GET("/readyz", handlers.Readyz)
+
+	// Prometheus metrics (credential and integration subsystems)
+	r.GET("/metrics", gin.WrapH(metrics.Handler()))
 
 	// Generic OAuth2 callback endpoint (outside /api for MCP compatibility)
 	r.GET("/oauth2callback", handlers.HandleOAuth2Callback)
@@ -0,0 +1,80 @@
+package server
+
+import (
+	"net/http"
+
+	"ambient-code-backend/auth"
+	"ambient-code-backend/logging"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Authenticators identify callers, tried in order; InitAuth sets them from
+// AUTH_BACKENDS and Run defaults them to the OpenShift OAuth proxy
+var Authenticators []auth.Authenticator
+
+// proxyIdentityHeaders are proxy identity headers ignored when the caller is
+// impersonated, as only the authenticator's identity may be acted on
+var proxyIdentityHeaders = []string{
+	"X-Forwarded-User",
+	"X-Forwarded-Preferred-Username",
+	"X-Forwarded-Email",
+	"X-Forwarded-Groups",
+	"X-Forwarded-Access-Token",
+}
+
+// InitAuth configures the authentication backends from AUTH_BACKENDS
+func InitAuth() ([]auth.Authenticator, error) {
+	authenticators, err := auth.FromEnv(BaseKubeConfig)
+	if err != nil {
+		return nil, err
+	}
+	Authenticators = authenticators
+	return authenticators, nil
+}
+
+// identityMiddleware authenticates the request with the first authenticator that
+// recognizes its credential and populates the Gin context with the caller.
+// Invalid credentials are rejected; requests without any pass through for the
+// route's own checks.
+func identityMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		res, err := auth.Authenticate(c.Request, Authenticators)
+		if err != nil {
+			logging.Warnf(c, "Rejected credentials for %s: %v", c.Request.URL.Path, err)
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
+			c.Abort()
+			return
+		}
+		if res == nil {
+			c.Next()
+			return
+		}
+
+		id := res.Identity
+		if id.Token == "" {
+			// Identity comes from the verified credential, never from headers sent alongside it
+			for _, h := range proxyIdentityHeaders {
+				c.Request.Header.Del(h)
+			}
+		}
+		if id.Username != "" {
+			// Sanitize userID to make it valid for K8s Secret keys
+			// Example: "kube:admin" becomes "kube-admin"
+			c.Set("userID", SanitizeUserID(id.Username))
+			// Keep original for display purposes
+			c.Set("userIDOriginal", id.Username)
+		}
+		if id.Name != "" {
+			c.Set("userName", id.Name)
+		}
+		if id.Email != "" {
+			c.Set("userEmail", id.Email)
+		}
+		if id.Groups != nil {
+			c.Set("userGroups", id.Groups)
+		}
+		c.Set(auth.ContextKey, res)
+		c.Next()
+	}
+}
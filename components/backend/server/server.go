@@ -12,6 +12,9 @@ import (
 	"syscall"
 	"time"
 
+	"ambient-code-backend/auth"
+	"ambient-code-backend/logging"
+
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 )
@@ -41,23 +44,28 @@ func Run(registerRoutes RouterFunc) error {
 			}
 		}
 
-		return fmt.Sprintf("[GIN] %s | %3d | %s | %s | Auth: %s\n",
+		return fmt.Sprintf("[GIN] %s | %3d | %s | %s | Auth: %s | req: %s\n",
 			param.Method,
 			param.StatusCode,
 			param.ClientIP,
 			path,
 			authHeader,
+			requestIDOf(param),
 		)
 	}))
 
-	// Middleware to populate user context from forwarded headers
-	r.Use(forwardedIdentityMiddleware())
+	// Middleware to populate user context from the configured authentication backends
+	if Authenticators == nil {
+		Authenticators = []auth.Authenticator{&auth.OpenShift{Config: BaseKubeConfig}}
+	}
+	r.Use(identityMiddleware())
 
 	// Configure CORS
 	config := cors.DefaultConfig()
 	config.AllowAllOrigins = true
 	config.AllowMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "HEAD", "OPTIONS"}
-	config.AllowHeaders = []string{"Origin", "Content-Length", "Content-Type", "Authorization"}
+	config.AllowHeaders = []string{"Origin", "Content-Length", "Content-Type", "Authorization", logging.RequestIDHeader}
+	config.ExposeHeaders = []string{logging.RequestIDHeader}
 	r.Use(cors.New(config))
 
 	// Register routes
@@ -79,7 +87,15 @@ func Run(registerRoutes RouterFunc) error {
 	return nil
 }
 
-// sanitizeUserID converts userID to a valid Kubernetes Secret data key
+// requestIDOf returns the request ID assigned by the request ID middleware, or "-"
+func requestIDOf(param gin.LogFormatterParams) string {
+	if id, ok := param.Keys[logging.GinRequestIDKey].(string); ok && id != "" {
+		return id
+	}
+	return "-"
+}
+
+// SanitizeUserID converts userID to a valid Kubernetes Secret data key
 // K8s Secret keys must match regex: [-._a-zA-Z0-9]+
 // Follows cert-manager's sanitization pattern for consistent, secure key generation
 //
@@ -91,7 +107,7 @@ func Run(registerRoutes RouterFunc) error {
 // - Spaces: "First Last" → "First-Last"
 //
 // Security: Only replaces characters, never interprets them (no injection risk)
-func sanitizeUserID(userID string) string {
+func SanitizeUserID(userID string) string {
 	if userID == "" {
 		return ""
 	}
@@ -131,42 +147,6 @@ func sanitizeUserID(userID string) string {
 	return sanitized
 }
 
-// forwardedIdentityMiddleware populates Gin context from common OAuth proxy headers
-func forwardedIdentityMiddleware() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		if v := c.GetHeader("X-Forwarded-User"); v != "" {
-			// Sanitize userID to make it valid for K8s Secret keys
-			// Example: "kube:admin" becomes "kube-admin"
-			c.Set("userID", sanitizeUserID(v))
-			// Keep original for display purposes
-			c.Set("userIDOriginal", v)
-		}
-		// Prefer preferred username; fallback to user id
-		name := c.GetHeader("X-Forwarded-Preferred-Username")
-		if name == "" {
-			name = c.GetHeader("X-Forwarded-User")
-		}
-		if name != "" {
-			c.Set("userName", name)
-		}
-		if v := c.GetHeader("X-Forwarded-Email"); v != "" {
-			c.Set("userEmail", v)
-		}
-		if v := c.GetHeader("X-Forwarded-Groups"); v != "" {
-			c.Set("userGroups", strings.Split(v, ","))
-		}
-		// Also expose access token if present
-		auth := c.GetHeader("Authorization")
-		if auth != "" {
-			c.Set("authorizationHeader", auth)
-		}
-		if v := c.GetHeader("X-Forwarded-Access-Token"); v != "" {
-			c.Set("forwardedAccessToken", v)
-		}
-		c.Next()
-	}
-}
-
 // RunContentService starts the server in content service mode with graceful shutdown
 func RunContentService(registerContentRoutes RouterFunc) error {
 	r := gin.New()
@@ -176,11 +156,12 @@ func RunContentService(registerContentRoutes RouterFunc) error {
 		if strings.Contains(param.Request.URL.RawQuery, "token=") {
 			path = strings.Split(path, "?")[0] + "?token=[REDACTED]"
 		}
-		return fmt.Sprintf("[GIN] %s | %3d | %s | %s\n",
+		return fmt.Sprintf("[GIN] %s | %3d | %s | %s | req: %s\n",
 			param.Method,
 			param.StatusCode,
 			param.ClientIP,
 			path,
+			requestIDOf(param),
 		)
 	}))
 
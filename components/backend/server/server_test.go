@@ -1,7 +1,13 @@
 package server
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"testing"
+
+	"ambient-code-backend/auth"
+
+	"github.com/gin-gonic/gin"
 )
 
 func TestSanitizeUserID(t *testing.T) {
@@ -69,20 +75,20 @@ func TestSanitizeUserID(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result := sanitizeUserID(tt.input)
+			result := SanitizeUserID(tt.input)
 			if tt.name == "Very long username (truncated to 253)" {
 				// Just check length is <= 253
 				if len(result) > 253 {
-					t.Errorf("sanitizeUserID() length = %d, want <= 253", len(result))
+					t.Errorf("SanitizeUserID() length = %d, want <= 253", len(result))
 				}
 			} else if result != tt.expected {
-				t.Errorf("sanitizeUserID(%q) = %q, want %q", tt.input, result, tt.expected)
+				t.Errorf("SanitizeUserID(%q) = %q, want %q", tt.input, result, tt.expected)
 			}
 
 			// Security check: result should only contain valid chars
 			for _, r := range result {
 				if !((r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '-' || r == '_' || r == '.') {
-					t.Errorf("sanitizeUserID(%q) contains invalid character: %q", tt.input, r)
+					t.Errorf("SanitizeUserID(%q) contains invalid character: %q", tt.input, r)
 				}
 			}
 		})
@@ -98,12 +104,66 @@ func TestSanitizeUserIDDeterministic(t *testing.T) {
 	}
 
 	for _, input := range inputs {
-		first := sanitizeUserID(input)
+		first := SanitizeUserID(input)
 		for i := 0; i < 10; i++ {
-			result := sanitizeUserID(input)
+			result := SanitizeUserID(input)
 			if result != first {
-				t.Errorf("sanitizeUserID() not deterministic: %q != %q", result, first)
+				t.Errorf("SanitizeUserID() not deterministic: %q != %q", result, first)
 			}
 		}
 	}
 }
+
+func TestIdentityMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	Authenticators = []auth.Authenticator{
+		&auth.StaticTokens{Tokens: map[string]auth.Identity{"dev-admin": {Username: "kube:admin", Groups: []string{"admins"}}}},
+		&auth.OpenShift{},
+	}
+	defer func() { Authenticators = nil }()
+
+	r := gin.New()
+	r.Use(identityMiddleware())
+	r.GET("/whoami", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{
+			"userID":    c.GetString("userID"),
+			"userName":  c.GetString("userName"),
+			"groups":    c.GetStringSlice("userGroups"),
+			"forwarded": c.GetHeader("X-Forwarded-User"),
+		})
+	})
+
+	tests := []struct {
+		name    string
+		headers map[string]string
+		want    string
+	}{
+		{
+			name:    "static token ignores proxy headers",
+			headers: map[string]string{"Authorization": "Bearer dev-admin", "X-Forwarded-User": "mallory"},
+			want:    `{"forwarded":"","groups":["admins"],"userID":"kube-admin","userName":"kube:admin"}`,
+		},
+		{
+			name:    "oauth proxy headers",
+			headers: map[string]string{"X-Forwarded-Access-Token": "sha256~abc", "X-Forwarded-User": "kube:admin", "X-Forwarded-Preferred-Username": "admin"},
+			want:    `{"forwarded":"kube:admin","groups":null,"userID":"kube-admin","userName":"admin"}`,
+		},
+		{
+			name: "anonymous",
+			want: `{"forwarded":"","groups":null,"userID":"","userName":""}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/whoami", nil)
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			if w.Code != http.StatusOK || w.Body.String() != tt.want {
+				t.Errorf("GET /whoami = %d %s, want %s", w.Code, w.Body.String(), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,199 @@
+// Package transcription turns audio clips into text for voice input. Each
+// Transcriber speaks one service's API (an OpenAI-compatible /audio/transcriptions
+// endpoint, or a self-hosted whisper-asr-webservice); the backend picks one with
+// TRANSCRIPTION_BACKEND, so handlers never depend on a particular speech-to-text service.
+package transcription
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"strings"
+	"time"
+
+	"ambient-code-backend/metrics"
+)
+
+// Backend names accepted by TRANSCRIPTION_BACKEND
+const (
+	BackendOpenAI     = "openai"
+	BackendWhisperASR = "whisper-asr"
+)
+
+// maxResponseBytes bounds how much of a transcription response is read
+const maxResponseBytes = 1 << 20
+
+// ErrDisabled is returned by New when no backend is configured
+var ErrDisabled = errors.New("audio transcription is not configured")
+
+// Clip is an audio recording to transcribe
+type Clip struct {
+	Data     []byte
+	Filename string
+	MimeType string
+	// Language is an optional ISO-639-1 hint, e.g. "en"
+	Language string
+}
+
+// Transcriber transcribes audio clips with one speech-to-text service
+type Transcriber interface {
+	// Name identifies the backend in TRANSCRIPTION_BACKEND and logs
+	Name() string
+	// Transcribe returns the clip's text
+	Transcribe(ctx context.Context, clip Clip) (string, error)
+}
+
+// Options configure a Transcriber
+type Options struct {
+	// URL is the service endpoint: .../v1/audio/transcriptions for openai,
+	// .../asr for whisper-asr
+	URL string
+	// Model is sent with openai requests, e.g. whisper-1
+	Model string
+	// Token is sent as a bearer token when set
+	Token   string
+	Timeout time.Duration
+}
+
+// New returns the named backend's Transcriber; an empty name returns ErrDisabled
+func New(backend string, opts Options) (Transcriber, error) {
+	if backend == "" {
+		return nil, ErrDisabled
+	}
+	if opts.URL == "" {
+		return nil, fmt.Errorf("transcription backend %s needs a URL", backend)
+	}
+	client := &http.Client{Timeout: opts.Timeout, Transport: metrics.Transport("transcription", "transcriptions", nil)}
+	switch backend {
+	case BackendOpenAI:
+		return &openAI{opts: opts, client: client}, nil
+	case BackendWhisperASR:
+		return &whisperASR{opts: opts, client: client}, nil
+	}
+	return nil, fmt.Errorf("unknown transcription backend %q", backend)
+}
+
+// openAI speaks the OpenAI audio transcriptions API, also served by Whisper servers
+// such as faster-whisper-server, LocalAI and vLLM
+type openAI struct {
+	opts   Options
+	client *http.Client
+}
+
+func (t *openAI) Name() string { return BackendOpenAI }
+
+func (t *openAI) Transcribe(ctx context.Context, clip Clip) (string, error) {
+	fields := map[string]string{"response_format": "json"}
+	if t.opts.Model != "" {
+		fields["model"] = t.opts.Model
+	}
+	if clip.Language != "" {
+		fields["language"] = clip.Language
+	}
+	data, err := post(ctx, t.client, t.opts.URL, t.opts.Token, "file", clip, fields)
+	if err != nil {
+		return "", err
+	}
+	var result struct {
+		Text string `json:"text"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return "", fmt.Errorf("invalid transcription response")
+	}
+	return strings.TrimSpace(result.Text), nil
+}
+
+// whisperASR speaks whisper-asr-webservice's POST /asr
+type whisperASR struct {
+	opts   Options
+	client *http.Client
+}
+
+func (t *whisperASR) Name() string { return BackendWhisperASR }
+
+func (t *whisperASR) Transcribe(ctx context.Context, clip Clip) (string, error) {
+	u, err := url.Parse(t.opts.URL)
+	if err != nil {
+		return "", err
+	}
+	q := u.Query()
+	q.Set("task", "transcribe")
+	q.Set("output", "json")
+	if clip.Language != "" {
+		q.Set("language", clip.Language)
+	}
+	u.RawQuery = q.Encode()
+	data, err := post(ctx, t.client, u.String(), t.opts.Token, "audio_file", clip, nil)
+	if err != nil {
+		return "", err
+	}
+	var result struct {
+		Text string `json:"text"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return "", fmt.Errorf("invalid transcription response")
+	}
+	return strings.TrimSpace(result.Text), nil
+}
+
+// post uploads the clip as a multipart form and returns the response body
+func post(ctx context.Context, client *http.Client, endpoint, token, filePart string, clip Clip, fields map[string]string) ([]byte, error) {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	for name, value := range fields {
+		if err := form.WriteField(name, value); err != nil {
+			return nil, err
+		}
+	}
+	filename := clip.Filename
+	if filename == "" {
+		filename = "audio"
+	}
+	header := textproto.MIMEHeader{}
+	header.Set("Content-Disposition", fmt.Sprintf(`form-data; name=%q; filename=%q`, filePart, filename))
+	if clip.MimeType != "" {
+		header.Set("Content-Type", clip.MimeType)
+	} else {
+		header.Set("Content-Type", "application/octet-stream")
+	}
+	part, err := form.CreatePart(header)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := part.Write(clip.Data); err != nil {
+		return nil, err
+	}
+	if err := form.Close(); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, &body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	req.Header.Set("Accept", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("transcription request failed: %w", err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read transcription response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("transcription endpoint returned %d", resp.StatusCode)
+	}
+	return data, nil
+}
@@ -0,0 +1,81 @@
+package transcription
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestBackends(t *testing.T) {
+	var got *http.Request
+	var audio []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			t.Errorf("ParseMultipartForm: %v", err)
+		}
+		part := "file"
+		if r.URL.Path == "/asr" {
+			part = "audio_file"
+		}
+		f, _, err := r.FormFile(part)
+		if err != nil {
+			t.Errorf("%s part: %v", part, err)
+			return
+		}
+		audio, _ = io.ReadAll(f)
+		got = r
+		w.Write([]byte(`{"text": " make the tests pass \n"}`))
+	}))
+	defer server.Close()
+	clip := Clip{Data: []byte("RIFF....WAVE"), Filename: "note.wav", MimeType: "audio/wav", Language: "en"}
+
+	openai, err := New(BackendOpenAI, Options{URL: server.URL + "/v1/audio/transcriptions", Model: "whisper-1", Token: "secret", Timeout: time.Second})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	text, err := openai.Transcribe(context.Background(), clip)
+	if err != nil || text != "make the tests pass" {
+		t.Fatalf("openai = %q, %v", text, err)
+	}
+	if got.FormValue("model") != "whisper-1" || got.FormValue("language") != "en" || got.Header.Get("Authorization") != "Bearer secret" || string(audio) != "RIFF....WAVE" {
+		t.Errorf("openai request: model=%q language=%q auth=%q audio=%q", got.FormValue("model"), got.FormValue("language"), got.Header.Get("Authorization"), audio)
+	}
+
+	asr, err := New(BackendWhisperASR, Options{URL: server.URL + "/asr", Timeout: time.Second})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if text, err := asr.Transcribe(context.Background(), clip); err != nil || text != "make the tests pass" {
+		t.Fatalf("whisper-asr = %q, %v", text, err)
+	}
+	if q := got.URL.Query(); q.Get("output") != "json" || q.Get("language") != "en" || got.Header.Get("Authorization") != "" {
+		t.Errorf("whisper-asr request: %s auth=%q", got.URL, got.Header.Get("Authorization"))
+	}
+}
+
+func TestNew(t *testing.T) {
+	if _, err := New("", Options{}); !errors.Is(err, ErrDisabled) {
+		t.Errorf("no backend: %v", err)
+	}
+	if _, err := New(BackendOpenAI, Options{}); err == nil {
+		t.Error("a backend without a URL must fail")
+	}
+	if _, err := New("vosk", Options{URL: "http://stt"}); err == nil {
+		t.Error("an unknown backend must fail")
+	}
+}
+
+func TestTranscribeError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "model overloaded", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+	transcriber, _ := New(BackendOpenAI, Options{URL: server.URL, Timeout: time.Second})
+	if _, err := transcriber.Transcribe(context.Background(), Clip{Data: []byte("x")}); err == nil {
+		t.Error("expected an error for a failed request")
+	}
+}
@@ -30,6 +30,8 @@ type LLMSettings struct {
 	Model       string  `json:"model"`
 	Temperature float64 `json:"temperature"`
 	MaxTokens   int     `json:"maxTokens"`
+	// FallbackModels are tried in order when the model is unavailable or rate limited
+	FallbackModels []string `json:"fallbackModels,omitempty"`
 }
 
 type GitConfig struct {
@@ -0,0 +1,114 @@
+package types
+
+import (
+	"encoding/json"
+	"net"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Egress policy enforcement levels (ProjectSettings spec.egressPolicy)
+const (
+	EgressPolicyFlag  = "flag"
+	EgressPolicyBlock = "block"
+
+	// EgressPolicyEnvVar carries the policy snapshot taken at session creation to the runner
+	EgressPolicyEnvVar = "EGRESS_POLICY"
+)
+
+// EgressPolicy lists the hosts a project's tool calls may reach. Entries are host
+// names, "*.example.com" for any subdomain, or IP addresses and CIDR ranges.
+type EgressPolicy struct {
+	AllowedHosts []string `json:"allowedHosts"`
+	Enforcement  string   `json:"enforcement"`
+}
+
+// IMPORTANT: Keep host extraction and matching in sync with runner (claude-code-runner/egress.py)
+
+// EgressLocalTools work on the workspace only; URLs in their arguments (e.g. links
+// written to a file) are not checked
+var EgressLocalTools = map[string]bool{
+	"Read": true, "Write": true, "Edit": true, "MultiEdit": true, "NotebookEdit": true,
+	"Glob": true, "Grep": true, "LS": true, "TodoWrite": true,
+}
+
+// egressURLPattern finds URLs in tool arguments
+var egressURLPattern = regexp.MustCompile(`(?i)\b(?:https?|wss?|ftp|ssh|git)://[^\s"'<>\\]+`)
+
+// egressSCPPattern finds scp-style git remotes (git@host:org/repo)
+var egressSCPPattern = regexp.MustCompile(`\b[A-Za-z0-9._-]+@([A-Za-z0-9.-]+\.[A-Za-z]{2,}):`)
+
+// AllowsHost reports whether the policy permits reaching host
+func (p *EgressPolicy) AllowsHost(host string) bool {
+	if p == nil {
+		return true
+	}
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	ip := net.ParseIP(host)
+	for _, entry := range p.AllowedHosts {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		switch {
+		case strings.Contains(entry, "/"):
+			if _, cidr, err := net.ParseCIDR(entry); err == nil && ip != nil && cidr.Contains(ip) {
+				return true
+			}
+		case strings.HasPrefix(entry, "*."):
+			if strings.HasSuffix(host, entry[1:]) {
+				return true
+			}
+		case entry == host:
+			return true
+		}
+	}
+	return false
+}
+
+// ExtractEgressHosts returns the hosts named by URLs and git remotes in a tool call's
+// arguments, sorted and without duplicates. JSON arguments are decoded first so
+// escaped strings are matched as the tool will see them.
+func ExtractEgressHosts(args string) []string {
+	var texts []string
+	var decoded interface{}
+	if json.Unmarshal([]byte(args), &decoded) == nil {
+		collectStrings(decoded, &texts)
+	} else {
+		texts = []string{args}
+	}
+
+	seen := map[string]bool{}
+	for _, text := range texts {
+		for _, raw := range egressURLPattern.FindAllString(text, -1) {
+			u, err := url.Parse(strings.TrimRight(raw, ".,;:)]}"))
+			if err != nil || u.Hostname() == "" {
+				continue
+			}
+			seen[strings.ToLower(u.Hostname())] = true
+		}
+		for _, m := range egressSCPPattern.FindAllStringSubmatch(text, -1) {
+			seen[strings.ToLower(m[1])] = true
+		}
+	}
+	hosts := make([]string, 0, len(seen))
+	for h := range seen {
+		hosts = append(hosts, h)
+	}
+	sort.Strings(hosts)
+	return hosts
+}
+
+func collectStrings(v interface{}, out *[]string) {
+	switch t := v.(type) {
+	case string:
+		*out = append(*out, t)
+	case []interface{}:
+		for _, item := range t {
+			collectStrings(item, out)
+		}
+	case map[string]interface{}:
+		for _, item := range t {
+			collectStrings(item, out)
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package types
+
+// Outcomes of one step of a user data erasure
+const (
+	ErasureRemoved    = "removed"
+	ErasureReassigned = "reassigned"
+	ErasureFailed     = "failed"
+)
+
+// UserErasureReport records what DELETE /api/users/:userId/data did. The erased user
+// appears only as UserPseudonym, the ID their audit records were rewritten to.
+type UserErasureReport struct {
+	UserPseudonym string `json:"userPseudonym"`
+	RequestedBy   string `json:"requestedBy"`
+	StartedAt     string `json:"startedAt"`
+	CompletedAt   string `json:"completedAt"`
+	// ReassignedTo is the user owning the erased user's sessions, when reassigned
+	ReassignedTo string `json:"reassignedTo,omitempty"`
+
+	Credentials  []ErasureStep    `json:"credentials"`
+	Sessions     []ErasureSession `json:"sessions"`
+	APIKeys      ErasureStep      `json:"apiKeys"`
+	AuditRecords ErasureStep      `json:"auditRecords"`
+	// Annotations counts the user's annotations removed from sessions that remain
+	Annotations ErasureStep `json:"annotations"`
+	// Retained lists what the user contributed to remaining sessions that was kept
+	Retained []ErasureRetained `json:"retained"`
+
+	// Complete is false when any step failed; the request can be retried
+	Complete bool     `json:"complete"`
+	Notes    []string `json:"notes,omitempty"`
+}
+
+// ErasureStep is the outcome for one kind of stored data
+type ErasureStep struct {
+	Kind   string `json:"kind"`
+	Status string `json:"status"`
+	Count  int    `json:"count,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+// ErasureSession is the outcome for one session the user owned
+type ErasureSession struct {
+	Project string `json:"project"`
+	Name    string `json:"name"`
+	Status  string `json:"status"`
+	// TranscriptFiles counts the persisted event logs and session files removed
+	TranscriptFiles int    `json:"transcriptFiles,omitempty"`
+	Error           string `json:"error,omitempty"`
+}
+
+// ErasureRetained is data the user contributed to a session that was not erased, such
+// as feedback recorded in the session's event log
+type ErasureRetained struct {
+	Project string `json:"project,omitempty"`
+	Session string `json:"session"`
+	Kind    string `json:"kind"`
+	Count   int    `json:"count"`
+}
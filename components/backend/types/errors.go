@@ -1,5 +1,102 @@
 package types
 
+import "net/http"
+
+// API error codes, the machine-readable "code" of error responses. Most follow from
+// the status; handlers set a more specific one where clients act on the difference.
+const (
+	ErrorCodeInvalidRequest       = "invalid_request"
+	ErrorCodeUnauthenticated      = "unauthenticated"
+	ErrorCodeForbidden            = "forbidden"
+	ErrorCodeNotFound             = "not_found"
+	ErrorCodeMethodNotAllowed     = "method_not_allowed"
+	ErrorCodeTimeout              = "timeout"
+	ErrorCodeConflict             = "conflict"
+	ErrorCodeGone                 = "gone"
+	ErrorCodePreconditionFailed   = "precondition_failed"
+	ErrorCodeTooLarge             = "too_large"
+	ErrorCodeUnsupportedMediaType = "unsupported_media_type"
+	ErrorCodeUnprocessable        = "unprocessable"
+	ErrorCodeRateLimited          = "rate_limited"
+	ErrorCodeInternal             = "internal"
+	ErrorCodeNotImplemented       = "not_implemented"
+	ErrorCodeUpstream             = "upstream_error"
+	ErrorCodeUnavailable          = "unavailable"
+	ErrorCodeUpstreamTimeout      = "upstream_timeout"
+
+	// ErrorCodeFeatureDisabled: the project has the feature flag guarding the endpoint off
+	ErrorCodeFeatureDisabled = "feature_disabled"
+	// ErrorCodeQuotaExceeded: a budget that resets on a schedule (e.g. the monthly token
+	// quota) is used up; retrying before the reset fails again
+	ErrorCodeQuotaExceeded = "quota_exceeded"
+	// ErrorCodeLimitExceeded: a fixed limit was hit; retrying the same request fails again
+	ErrorCodeLimitExceeded = "limit_exceeded"
+)
+
+// APIError is the body of error responses. Error keeps the human-readable message
+// where clients have always read it; Code is what they branch on.
+type APIError struct {
+	Error string `json:"error"`
+	Code  string `json:"code"`
+	// Retryable: the same request may succeed later, after Retry-After when set
+	Retryable bool `json:"retryable"`
+	// RequestID correlates the error with backend logs; also in X-Request-ID
+	RequestID string `json:"requestId,omitempty"`
+}
+
+// ErrorCodeForStatus returns the error code of responses with status that don't set one
+func ErrorCodeForStatus(status int) string {
+	switch status {
+	case http.StatusBadRequest:
+		return ErrorCodeInvalidRequest
+	case http.StatusUnauthorized:
+		return ErrorCodeUnauthenticated
+	case http.StatusForbidden:
+		return ErrorCodeForbidden
+	case http.StatusNotFound:
+		return ErrorCodeNotFound
+	case http.StatusMethodNotAllowed:
+		return ErrorCodeMethodNotAllowed
+	case http.StatusRequestTimeout:
+		return ErrorCodeTimeout
+	case http.StatusConflict:
+		return ErrorCodeConflict
+	case http.StatusGone:
+		return ErrorCodeGone
+	case http.StatusPreconditionFailed:
+		return ErrorCodePreconditionFailed
+	case http.StatusRequestEntityTooLarge:
+		return ErrorCodeTooLarge
+	case http.StatusUnsupportedMediaType:
+		return ErrorCodeUnsupportedMediaType
+	case http.StatusUnprocessableEntity:
+		return ErrorCodeUnprocessable
+	case http.StatusTooManyRequests:
+		return ErrorCodeRateLimited
+	case http.StatusNotImplemented:
+		return ErrorCodeNotImplemented
+	case http.StatusBadGateway:
+		return ErrorCodeUpstream
+	case http.StatusServiceUnavailable:
+		return ErrorCodeUnavailable
+	case http.StatusGatewayTimeout:
+		return ErrorCodeUpstreamTimeout
+	}
+	if status >= 500 {
+		return ErrorCodeInternal
+	}
+	return ErrorCodeInvalidRequest
+}
+
+// ErrorRetryable reports whether an error with code may succeed when retried unchanged
+func ErrorRetryable(code string) bool {
+	switch code {
+	case ErrorCodeTimeout, ErrorCodeRateLimited, ErrorCodeUpstream, ErrorCodeUnavailable, ErrorCodeUpstreamTimeout:
+		return true
+	}
+	return false
+}
+
 // GetProviderSpecificGuidance returns remediation guidance for provider-specific errors
 func GetProviderSpecificGuidance(provider ProviderType, errorType string) string {
 	switch provider {
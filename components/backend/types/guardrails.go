@@ -0,0 +1,275 @@
+package types
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+)
+
+// Guardrail rule kinds (ProjectSettings spec.guardrailPolicy)
+const (
+	GuardrailKindRegex   = "regex"   // patterns are regular expressions
+	GuardrailKindKeyword = "keyword" // patterns are case-insensitive literals
+	GuardrailKindSecrets = "secrets" // built-in credential formats, patterns are ignored
+	GuardrailKindEntropy = "entropy" // long high-entropy tokens
+	GuardrailKindCommand = "command" // patterns are regular expressions matched against commands in tool args
+)
+
+// Guardrail actions, from least to most severe
+const (
+	GuardrailActionWarn      = "warn"
+	GuardrailActionRedact    = "redact"
+	GuardrailActionInterrupt = "interrupt"
+)
+
+// Parts of the stream a guardrail rule inspects
+const (
+	GuardrailTargetOutput     = "output"     // assistant text
+	GuardrailTargetToolArgs   = "toolArgs"   // tool call arguments
+	GuardrailTargetToolResult = "toolResult" // tool call results
+)
+
+// Entropy rule defaults
+const (
+	DefaultGuardrailMinEntropy = 4.0
+	DefaultGuardrailMinLength  = 20
+)
+
+// GuardrailRedaction replaces redacted matches
+const GuardrailRedaction = "[REDACTED]"
+
+// GuardrailPolicy inspects a project's streamed run output
+type GuardrailPolicy struct {
+	Rules []GuardrailRule `json:"rules"`
+}
+
+// GuardrailRule is one check of a guardrail policy. Targets default to every target
+// (tool args only for command rules).
+type GuardrailRule struct {
+	Name       string   `json:"name"`
+	Kind       string   `json:"kind"`
+	Patterns   []string `json:"patterns,omitempty"`
+	MinEntropy float64  `json:"minEntropy,omitempty"` // bits per character, entropy rules only
+	MinLength  int      `json:"minLength,omitempty"`  // entropy rules only
+	Targets    []string `json:"targets,omitempty"`
+	Action     string   `json:"action"`
+}
+
+// secretPatterns are the credential formats matched by secrets rules
+var secretPatterns = []string{
+	`AKIA[0-9A-Z]{16}`,                   // AWS access key ID
+	`gh[pousr]_[A-Za-z0-9]{36,}`,         // GitHub tokens
+	`github_pat_[A-Za-z0-9_]{22,}`,       // GitHub fine-grained tokens
+	`glpat-[A-Za-z0-9_\-]{20,}`,          // GitLab personal access tokens
+	`sk-ant-[A-Za-z0-9_\-]{20,}`,         // Anthropic API keys
+	`sk-[A-Za-z0-9]{32,}`,                // OpenAI-style API keys
+	`xox[abprs]-[A-Za-z0-9\-]{10,}`,      // Slack tokens
+	`AIza[0-9A-Za-z_\-]{35}`,             // Google API keys
+	`-----BEGIN [A-Z ]*PRIVATE KEY-----`, // PEM private keys
+	`eyJ[A-Za-z0-9_\-]{10,}\.eyJ[A-Za-z0-9_\-]{10,}\.[A-Za-z0-9_\-]{10,}`, // JWTs
+}
+
+// entropyTokenPattern splits text into the tokens entropy rules measure
+var entropyTokenPattern = regexp.MustCompile(`[A-Za-z0-9+/=_\-]+`)
+
+// commandArgKeys are the tool argument fields holding a command line
+var commandArgKeys = []string{"command", "cmd", "script"}
+
+// GuardrailMatch is a rule that matched inspected text
+type GuardrailMatch struct {
+	Rule   string `json:"rule"`
+	Kind   string `json:"kind"`
+	Action string `json:"action"`
+	Target string `json:"target"`
+	Count  int    `json:"count"`
+}
+
+// GuardrailEngine is a compiled guardrail policy
+type GuardrailEngine struct {
+	rules []compiledGuardrailRule
+}
+
+type compiledGuardrailRule struct {
+	GuardrailRule
+	patterns []*regexp.Regexp
+	targets  map[string]bool
+}
+
+// CompileGuardrailPolicy validates a policy and compiles its patterns
+func CompileGuardrailPolicy(p GuardrailPolicy) (*GuardrailEngine, error) {
+	engine := &GuardrailEngine{}
+	names := map[string]bool{}
+	for i, rule := range p.Rules {
+		if strings.TrimSpace(rule.Name) == "" {
+			return nil, fmt.Errorf("rule %d: name is required", i)
+		}
+		if names[rule.Name] {
+			return nil, fmt.Errorf("rule %q: duplicate name", rule.Name)
+		}
+		names[rule.Name] = true
+
+		switch rule.Action {
+		case GuardrailActionWarn, GuardrailActionInterrupt:
+		case GuardrailActionRedact:
+			if rule.Kind == GuardrailKindCommand {
+				return nil, fmt.Errorf("rule %q: command rules cannot redact, use warn or interrupt", rule.Name)
+			}
+		default:
+			return nil, fmt.Errorf("rule %q: action must be one of: warn, redact, interrupt", rule.Name)
+		}
+
+		compiled := compiledGuardrailRule{GuardrailRule: rule, targets: map[string]bool{}}
+		var sources []string
+		switch rule.Kind {
+		case GuardrailKindRegex, GuardrailKindCommand:
+			sources = rule.Patterns
+		case GuardrailKindKeyword:
+			for _, k := range rule.Patterns {
+				sources = append(sources, "(?i)"+regexp.QuoteMeta(k))
+			}
+		case GuardrailKindSecrets:
+			sources = secretPatterns
+		case GuardrailKindEntropy:
+			if compiled.MinEntropy == 0 {
+				compiled.MinEntropy = DefaultGuardrailMinEntropy
+			}
+			if compiled.MinLength == 0 {
+				compiled.MinLength = DefaultGuardrailMinLength
+			}
+			if compiled.MinEntropy < 0 || compiled.MinLength < 8 {
+				return nil, fmt.Errorf("rule %q: minEntropy must be positive and minLength at least 8", rule.Name)
+			}
+		default:
+			return nil, fmt.Errorf("rule %q: kind must be one of: regex, keyword, secrets, entropy, command", rule.Name)
+		}
+		if len(sources) == 0 && rule.Kind != GuardrailKindEntropy {
+			return nil, fmt.Errorf("rule %q: at least one pattern is required", rule.Name)
+		}
+		for _, src := range sources {
+			if strings.TrimSpace(src) == "" {
+				return nil, fmt.Errorf("rule %q: empty pattern", rule.Name)
+			}
+			re, err := regexp.Compile(src)
+			if err != nil {
+				return nil, fmt.Errorf("rule %q: invalid pattern %q: %v", rule.Name, src, err)
+			}
+			compiled.patterns = append(compiled.patterns, re)
+		}
+
+		targets := rule.Targets
+		if rule.Kind == GuardrailKindCommand {
+			if len(targets) > 0 && (len(targets) != 1 || targets[0] != GuardrailTargetToolArgs) {
+				return nil, fmt.Errorf("rule %q: command rules only inspect toolArgs", rule.Name)
+			}
+			targets = []string{GuardrailTargetToolArgs}
+		} else if len(targets) == 0 {
+			targets = []string{GuardrailTargetOutput, GuardrailTargetToolArgs, GuardrailTargetToolResult}
+		}
+		for _, t := range targets {
+			switch t {
+			case GuardrailTargetOutput, GuardrailTargetToolArgs, GuardrailTargetToolResult:
+				compiled.targets[t] = true
+			default:
+				return nil, fmt.Errorf("rule %q: target must be one of: output, toolArgs, toolResult", rule.Name)
+			}
+		}
+		engine.rules = append(engine.rules, compiled)
+	}
+	return engine, nil
+}
+
+// Inspects reports whether any rule inspects target
+func (e *GuardrailEngine) Inspects(target string) bool {
+	if e == nil {
+		return false
+	}
+	for _, rule := range e.rules {
+		if rule.targets[target] {
+			return true
+		}
+	}
+	return false
+}
+
+// Inspect runs the rules for target over text. It returns text with the matches of
+// redact rules replaced, and every rule that matched.
+func (e *GuardrailEngine) Inspect(target, text string) (string, []GuardrailMatch) {
+	if e == nil || text == "" {
+		return text, nil
+	}
+	var matches []GuardrailMatch
+	for _, rule := range e.rules {
+		if !rule.targets[target] {
+			continue
+		}
+		count := 0
+		switch rule.Kind {
+		case GuardrailKindCommand:
+			for _, cmd := range toolArgCommands(text) {
+				for _, re := range rule.patterns {
+					count += len(re.FindAllStringIndex(cmd, -1))
+				}
+			}
+		case GuardrailKindEntropy:
+			text = entropyTokenPattern.ReplaceAllStringFunc(text, func(token string) string {
+				if len(token) < rule.MinLength || ShannonEntropy(token) < rule.MinEntropy {
+					return token
+				}
+				count++
+				if rule.Action == GuardrailActionRedact {
+					return GuardrailRedaction
+				}
+				return token
+			})
+		default:
+			for _, re := range rule.patterns {
+				n := len(re.FindAllStringIndex(text, -1))
+				if n > 0 && rule.Action == GuardrailActionRedact {
+					text = re.ReplaceAllLiteralString(text, GuardrailRedaction)
+				}
+				count += n
+			}
+		}
+		if count > 0 {
+			matches = append(matches, GuardrailMatch{Rule: rule.Name, Kind: rule.Kind, Action: rule.Action, Target: target, Count: count})
+		}
+	}
+	return text, matches
+}
+
+// toolArgCommands returns the command lines in a tool call's JSON arguments, or the
+// whole arguments when they aren't a JSON object
+func toolArgCommands(args string) []string {
+	var fields map[string]interface{}
+	if json.Unmarshal([]byte(args), &fields) != nil {
+		return []string{args}
+	}
+	var commands []string
+	for _, key := range commandArgKeys {
+		if cmd, ok := fields[key].(string); ok && cmd != "" {
+			commands = append(commands, cmd)
+		}
+	}
+	return commands
+}
+
+// ShannonEntropy returns the entropy of s in bits per character
+func ShannonEntropy(s string) float64 {
+	if s == "" {
+		return 0
+	}
+	counts := map[rune]int{}
+	n := 0
+	for _, r := range s {
+		counts[r]++
+		n++
+	}
+	entropy := 0.0
+	for _, c := range counts {
+		p := float64(c) / float64(n)
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
@@ -0,0 +1,30 @@
+package types
+
+// ProjectIntegrationsStatus is returned by GET /api/projects/:projectName/integrations/status:
+// which integrations each project member has connected
+type ProjectIntegrationsStatus struct {
+	Project string                     `json:"project"`
+	Members []MemberIntegrationsStatus `json:"members"`
+	// Configured counts, per integration, the members who have it configured
+	Configured map[string]int `json:"configured"`
+	// Groups granted access to the project; their members can't be listed
+	Groups []string `json:"groups,omitempty"`
+}
+
+// MemberIntegrationsStatus is one project member's integrations, keyed by name
+// (github, google, jira, gitlab, linear)
+type MemberIntegrationsStatus struct {
+	User         string                        `json:"user"`
+	Role         string                        `json:"role"`
+	Integrations map[string]IntegrationSummary `json:"integrations"`
+}
+
+// IntegrationSummary is what a project admin sees of a member's integration: whether
+// it is set up, never the account details
+type IntegrationSummary struct {
+	Configured bool   `json:"configured"`
+	Valid      bool   `json:"valid"`
+	UpdatedAt  string `json:"updatedAt,omitempty"`
+	// Status is "ok", or "timeout" when the lookup didn't answer in time
+	Status string `json:"status"`
+}
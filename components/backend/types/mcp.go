@@ -0,0 +1,94 @@
+package types
+
+import "strings"
+
+// MCP server transports supported by the runner
+const (
+	MCPTransportStdio = "stdio"
+	MCPTransportHTTP  = "http"
+	MCPTransportSSE   = "sse"
+)
+
+// ProjectMCPServersConfigMap holds the project's MCP server registry; the operator
+// mounts it into every runner pod in the project.
+const (
+	ProjectMCPServersConfigMap = "ambient-mcp-servers"
+	ProjectMCPServersKey       = "mcp-servers.json"
+)
+
+// MCPServerConfig is a project-level MCP server definition
+type MCPServerConfig struct {
+	Name      string            `json:"name"`
+	Transport string            `json:"transport"`
+	URL       string            `json:"url,omitempty"`
+	Command   string            `json:"command,omitempty"`
+	Args      []string          `json:"args,omitempty"`
+	Env       map[string]string `json:"env,omitempty"`
+	Headers   map[string]string `json:"headers,omitempty"`
+	// CredentialsSecretRef names a Secret in the project namespace whose keys are
+	// injected into the runner environment, for use as ${VAR} in env/headers.
+	CredentialsSecretRef string `json:"credentialsSecretRef,omitempty"`
+	// OAuthProvider passes the session owner's own token for this provider to the
+	// server instead of a shared secret. The runner fetches it from the backend.
+	OAuthProvider string `json:"oauthProvider,omitempty"`
+	Enabled       bool   `json:"enabled"`
+}
+
+// MCPOAuthProviders are the providers whose user tokens may be passed through to
+// project MCP servers
+var MCPOAuthProviders = []string{"github", "gitlab", "google", "jira", "linear"}
+
+// MCP tool policy modes and enforcement levels (ProjectSettings spec.mcpToolPolicy)
+const (
+	MCPToolPolicyAllowlist = "allowlist"
+	MCPToolPolicyDenylist  = "denylist"
+
+	MCPToolPolicyFlag  = "flag"
+	MCPToolPolicyBlock = "block"
+
+	// MCPToolPolicyEnvVar carries the policy snapshot taken at session creation to the runner
+	MCPToolPolicyEnvVar = "MCP_TOOL_POLICY"
+)
+
+// MCPToolPolicy restricts which MCP tools (mcp__<server>__<tool>) sessions may call.
+// Tool patterns are exact names or prefixes ending in '*'.
+type MCPToolPolicy struct {
+	Mode        string   `json:"mode"`
+	Tools       []string `json:"tools"`
+	Enforcement string   `json:"enforcement"`
+}
+
+// platformMCPServers are provided by the runner itself and are never restricted
+var platformMCPServers = []string{"mcp__session__", "mcp__rubric__"}
+
+// Allows reports whether the policy permits calling toolName. Non-MCP tools and
+// platform-provided MCP tools are always allowed.
+func (p *MCPToolPolicy) Allows(toolName string) bool {
+	if p == nil || !strings.HasPrefix(toolName, "mcp__") {
+		return true
+	}
+	for _, prefix := range platformMCPServers {
+		if strings.HasPrefix(toolName, prefix) {
+			return true
+		}
+	}
+	matched := false
+	for _, pattern := range p.Tools {
+		if matchMCPToolPattern(pattern, toolName) {
+			matched = true
+			break
+		}
+	}
+	if p.Mode == MCPToolPolicyAllowlist {
+		return matched
+	}
+	return !matched
+}
+
+func matchMCPToolPattern(pattern, toolName string) bool {
+	pattern = strings.TrimSpace(pattern)
+	if strings.HasSuffix(pattern, "*") {
+		return strings.HasPrefix(toolName, strings.TrimSuffix(pattern, "*"))
+	}
+	return pattern == toolName
+}
@@ -0,0 +1,38 @@
+package types
+
+// Content moderation providers (ProjectSettings spec.contentModeration)
+const (
+	ModerationProviderLocal  = "local"  // the project's blocked terms and patterns only
+	ModerationProviderRemote = "remote" // the backend's moderation endpoint, after the local rules
+)
+
+// ContentModeration checks each assistant message before it is stored or streamed
+type ContentModeration struct {
+	Enabled         bool     `json:"enabled"`
+	Provider        string   `json:"provider"`
+	BlockedTerms    []string `json:"blockedTerms,omitempty"`    // case-insensitive
+	BlockedPatterns []string `json:"blockedPatterns,omitempty"` // regular expressions
+	// FailOpen delivers a message the remote provider couldn't check; by default it
+	// is quarantined
+	FailOpen bool `json:"failOpen,omitempty"`
+}
+
+// ModerationVerdict is the outcome of moderating one message
+type ModerationVerdict struct {
+	Flagged    bool     `json:"flagged"`
+	Categories []string `json:"categories,omitempty"`
+	Provider   string   `json:"provider"`
+}
+
+// QuarantinedContent is assistant text withheld by moderation
+type QuarantinedContent struct {
+	ID          string   `json:"id"`
+	SessionName string   `json:"sessionName"`
+	RunID       string   `json:"runId"`
+	MessageID   string   `json:"messageId"`
+	Text        string   `json:"text"`
+	Provider    string   `json:"provider"`
+	Categories  []string `json:"categories,omitempty"`
+	Error       string   `json:"error,omitempty"` // set when the provider failed and the project fails closed
+	CreatedAt   string   `json:"createdAt"`
+}
@@ -0,0 +1,24 @@
+package types
+
+// ProjectPersonasConfigMap holds the project's agent persona registry
+const (
+	ProjectPersonasConfigMap = "ambient-agent-personas"
+	ProjectPersonasKey       = "personas.json"
+)
+
+// AgentPersona is a named agent configuration sessions select with spec.persona.
+// Empty fields keep the session's defaults.
+type AgentPersona struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	// SystemPrompt is appended to the runner's system prompt
+	SystemPrompt string `json:"systemPrompt,omitempty"`
+	// AllowedTools replaces the runner's built-in tool set (e.g. Read, Grep, Bash)
+	AllowedTools []string `json:"allowedTools,omitempty"`
+	// Model is used for runs that don't choose one
+	Model string `json:"model,omitempty"`
+	// MCPServers limits the project MCP servers available to these names
+	MCPServers []string `json:"mcpServers,omitempty"`
+	UpdatedBy  string   `json:"updatedBy,omitempty"`
+	UpdatedAt  string   `json:"updatedAt,omitempty"`
+}
@@ -0,0 +1,54 @@
+package types
+
+// Placement policy actions (ProjectSettings spec.placementPolicy.onInsufficientCapacity)
+const (
+	// PlacementQueue creates the session but holds it until capacity frees up
+	PlacementQueue = "queue"
+	// PlacementReject refuses to create the session
+	PlacementReject = "reject"
+	// PlacementFallback tries the next node pool, queueing when none has room
+	PlacementFallback = "fallback"
+)
+
+// PlacementPolicy decides what happens to a new session when the project has no room
+// for another runner pod.
+type PlacementPolicy struct {
+	OnInsufficientCapacity string `json:"onInsufficientCapacity"`
+	// MaxPendingRunners is how many runner pods may wait for scheduling (per node pool
+	// when pools are configured) before capacity counts as exhausted; 0 disables the check
+	MaxPendingRunners int `json:"maxPendingRunners,omitempty"`
+	// MaxConcurrentRuns is how many runs of the project may stream at once; further runs
+	// are queued by priority. 0 is unlimited.
+	MaxConcurrentRuns int `json:"maxConcurrentRuns,omitempty"`
+	// MaxQueuedRuns bounds the run queue; 0 is unbounded
+	MaxQueuedRuns int `json:"maxQueuedRuns,omitempty"`
+	// PreemptQueuedRuns lets an interactive run arriving at a full queue drop the newest
+	// queued batch run rather than be rejected
+	PreemptQueuedRuns bool `json:"preemptQueuedRuns,omitempty"`
+	// NodePools are tried in order. With no pools, runners go wherever the scheduler
+	// puts them.
+	NodePools []NodePool `json:"nodePools,omitempty"`
+}
+
+// NodePool is a set of nodes runner pods can be pinned to
+type NodePool struct {
+	Name         string            `json:"name"`
+	NodeSelector map[string]string `json:"nodeSelector"`
+}
+
+// AdmissionDecision is the outcome of admitting a session
+type AdmissionDecision struct {
+	// Outcome is admitted, queued or rejected
+	Outcome string `json:"outcome"`
+	// NodePool is the pool the runner is pinned to, if any
+	NodePool string `json:"nodePool,omitempty"`
+	// Reason explains why the session was queued or rejected
+	Reason string `json:"reason,omitempty"`
+}
+
+// Admission outcomes
+const (
+	AdmissionAdmitted = "admitted"
+	AdmissionQueued   = "queued"
+	AdmissionRejected = "rejected"
+)
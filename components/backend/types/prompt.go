@@ -0,0 +1,28 @@
+package types
+
+// ProjectPromptsConfigMap holds the project's prompt library
+const (
+	ProjectPromptsConfigMap = "ambient-prompt-templates"
+	ProjectPromptsKey       = "prompts.json"
+)
+
+// PromptTemplate is a project prompt with {{variable}} placeholders. Version starts
+// at 1 and increases with every update.
+type PromptTemplate struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Template    string `json:"template"`
+	// Variables are the placeholders found in Template, in order of first use
+	Variables []string `json:"variables,omitempty"`
+	Version   int      `json:"version"`
+	UpdatedBy string   `json:"updatedBy,omitempty"`
+	UpdatedAt string   `json:"updatedAt,omitempty"`
+}
+
+// PromptTemplateRef asks for a prompt from the library to be rendered server-side.
+// A non-zero Version must match the template's current version.
+type PromptTemplateRef struct {
+	Name      string            `json:"name"`
+	Version   int               `json:"version,omitempty"`
+	Variables map[string]string `json:"variables,omitempty"`
+}
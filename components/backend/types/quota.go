@@ -0,0 +1,40 @@
+package types
+
+// TokenQuota caps the LLM tokens a project and each of its users may use per calendar
+// month (UTC), stored as ProjectSettings spec.tokenQuota. A cap of 0 is unlimited.
+type TokenQuota struct {
+	MonthlyTokens        int64 `json:"monthlyTokens,omitempty"`
+	PerUserMonthlyTokens int64 `json:"perUserMonthlyTokens,omitempty"`
+	// Users overrides PerUserMonthlyTokens for individual user IDs
+	Users map[string]int64 `json:"users,omitempty"`
+}
+
+// TokenQuotaOverride grants tokens past a cap for the rest of the month
+type TokenQuotaOverride struct {
+	// User is the user whose cap is raised; empty raises the project cap
+	User      string `json:"user,omitempty"`
+	Tokens    int64  `json:"tokens"`
+	Reason    string `json:"reason,omitempty"`
+	GrantedBy string `json:"grantedBy,omitempty"`
+	GrantedAt string `json:"grantedAt,omitempty"`
+}
+
+// TokenQuotaUsage is the month's usage against one cap
+type TokenQuotaUsage struct {
+	User string `json:"user,omitempty"`
+	Used int64  `json:"used"`
+	// Limit is the cap plus this month's overrides; 0 is unlimited
+	Limit int64 `json:"limit,omitempty"`
+	// Remaining is omitted when unlimited
+	Remaining *int64 `json:"remaining,omitempty"`
+	Exceeded  bool   `json:"exceeded"`
+}
+
+// TokenQuotaStatus is the month's usage of a project and one of its users
+type TokenQuotaStatus struct {
+	Month     string               `json:"month"` // YYYY-MM
+	ResetsAt  string               `json:"resetsAt"`
+	Project   TokenQuotaUsage      `json:"project"`
+	User      *TokenQuotaUsage     `json:"user,omitempty"`
+	Overrides []TokenQuotaOverride `json:"overrides,omitempty"`
+}
@@ -27,6 +27,35 @@ type AgenticSessionSpec struct {
 	Repos []SimpleRepo `json:"repos,omitempty"`
 	// Active workflow for dynamic workflow switching
 	ActiveWorkflow *WorkflowSelection `json:"activeWorkflow,omitempty"`
+	// Optional runner image override (validated against ProjectSettings allowlist)
+	RunnerImage string `json:"runnerImage,omitempty"`
+	// Schedule the runner on spot capacity; preempted runs are resubmitted on on-demand nodes
+	Spot bool `json:"spot,omitempty"`
+	// Persona names the project agent persona applied to each run
+	Persona string `json:"persona,omitempty"`
+	// Workspace sizes the runner's workspace volume
+	Workspace *WorkspaceSpec `json:"workspace,omitempty"`
+}
+
+// WorkspaceSpec configures the session workspace volume
+type WorkspaceSpec struct {
+	// Size requests a PVC-backed workspace of this size (e.g. "20Gi"), up to the
+	// project's workspace.maxSize. Empty keeps the default ephemeral workspace.
+	Size string `json:"size,omitempty"`
+}
+
+// ExpandWorkspaceRequest grows a session's workspace; an empty size doubles it,
+// capped at the project maximum
+type ExpandWorkspaceRequest struct {
+	Size string `json:"size,omitempty"`
+}
+
+// ExpandWorkspaceResponse reports a requested workspace expansion
+type ExpandWorkspaceResponse struct {
+	PreviousSize string `json:"previousSize"`
+	Size         string `json:"size"`
+	MaxSize      string `json:"maxSize"`
+	StorageClass string `json:"storageClass,omitempty"`
 }
 
 // SimpleRepo represents a simplified repository configuration
@@ -34,18 +63,37 @@ type SimpleRepo struct {
 	URL      string  `json:"url"`
 	Branch   *string `json:"branch,omitempty"`
 	AutoPush *bool   `json:"autoPush,omitempty"`
+	// Partial clone options for large repositories (monorepos)
+	Depth        *int     `json:"depth,omitempty"`
+	SingleBranch *bool    `json:"singleBranch,omitempty"`
+	SparsePaths  []string `json:"sparsePaths,omitempty"`
+	// LFS enables Git LFS handling; nil clones with the image's git defaults
+	LFS *RepoLFS `json:"lfs,omitempty"`
+}
+
+// RepoLFS configures how Git LFS objects are fetched into the workspace
+type RepoLFS struct {
+	// SkipSmudge keeps pointer files instead of downloading objects
+	SkipSmudge bool `json:"skipSmudge,omitempty"`
+	// MaxObjectSizeMB skips objects larger than this many MiB (0 = no limit)
+	MaxObjectSizeMB int `json:"maxObjectSizeMB,omitempty"`
 }
 
 type AgenticSessionStatus struct {
-	ObservedGeneration int64               `json:"observedGeneration,omitempty"`
-	Phase              string              `json:"phase,omitempty"`
-	StartTime          *string             `json:"startTime,omitempty"`
-	CompletionTime     *string             `json:"completionTime,omitempty"`
-	ReconciledRepos    []ReconciledRepo    `json:"reconciledRepos,omitempty"`
-	ReconciledWorkflow *ReconciledWorkflow `json:"reconciledWorkflow,omitempty"`
-	SDKSessionID       string              `json:"sdkSessionId,omitempty"`
-	SDKRestartCount    int                 `json:"sdkRestartCount,omitempty"`
-	Conditions         []Condition         `json:"conditions,omitempty"`
+	ObservedGeneration int64                `json:"observedGeneration,omitempty"`
+	Phase              string               `json:"phase,omitempty"`
+	StartTime          *string              `json:"startTime,omitempty"`
+	CompletionTime     *string              `json:"completionTime,omitempty"`
+	ReconciledRepos    []ReconciledRepo     `json:"reconciledRepos,omitempty"`
+	ReconciledWorkflow *ReconciledWorkflow  `json:"reconciledWorkflow,omitempty"`
+	SDKSessionID       string               `json:"sdkSessionId,omitempty"`
+	SDKRestartCount    int                  `json:"sdkRestartCount,omitempty"`
+	Conditions         []Condition          `json:"conditions,omitempty"`
+	PullRequests       []SessionPullRequest `json:"pullRequests,omitempty"`
+	LinearIssues       []SessionLinearIssue `json:"linearIssues,omitempty"`
+	LastRunSummary     *RunSummary          `json:"lastRunSummary,omitempty"`
+	// DebugURL is the runner's authenticated debug endpoint (ProjectSettings spec.runnerDebugAccess)
+	DebugURL string `json:"debugURL,omitempty"`
 }
 
 type CreateAgenticSessionRequest struct {
@@ -61,6 +109,10 @@ type CreateAgenticSessionRequest struct {
 	EnvironmentVariables map[string]string `json:"environmentVariables,omitempty"`
 	Labels               map[string]string `json:"labels,omitempty"`
 	Annotations          map[string]string `json:"annotations,omitempty"`
+	RunnerImage          string            `json:"runnerImage,omitempty"`
+	Spot                 bool              `json:"spot,omitempty"`
+	Persona              string            `json:"persona,omitempty"`
+	Workspace            *WorkspaceSpec    `json:"workspace,omitempty"`
 }
 
 type CloneSessionRequest struct {
@@ -73,6 +125,7 @@ type UpdateAgenticSessionRequest struct {
 	DisplayName   *string      `json:"displayName,omitempty"`
 	Timeout       *int         `json:"timeout,omitempty"`
 	LLMSettings   *LLMSettings `json:"llmSettings,omitempty"`
+	Persona       *string      `json:"persona,omitempty"`
 }
 
 type CloneAgenticSessionRequest struct {
@@ -98,6 +151,107 @@ type ReconciledRepo struct {
 	ClonedAt *string `json:"clonedAt,omitempty"`
 }
 
+// SessionPullRequest records a pull/merge request opened from a session's working branch
+type SessionPullRequest struct {
+	URL       string `json:"url"`
+	Number    int    `json:"number,omitempty"`
+	Provider  string `json:"provider,omitempty"`
+	RepoURL   string `json:"repoUrl"`
+	Head      string `json:"head"`
+	Base      string `json:"base"`
+	CreatedAt string `json:"createdAt,omitempty"`
+	// CheckRunID is the GitHub check run reflecting the session's latest run
+	CheckRunID int64 `json:"checkRunId,omitempty"`
+}
+
+// CreatePullRequestRequest is the body of POST .../git/pull-requests.
+// RepoURL may be omitted for single-repo sessions; Head defaults to the repo's active
+// branch and Base to the branch the repo was cloned from.
+type CreatePullRequestRequest struct {
+	RepoURL string `json:"repoUrl,omitempty"`
+	Head    string `json:"head,omitempty"`
+	Base    string `json:"base,omitempty"`
+	Title   string `json:"title" binding:"required"`
+	Body    string `json:"body,omitempty"`
+	Draft   bool   `json:"draft,omitempty"`
+}
+
+// SessionLinearIssue records a Linear issue created from or linked to a session
+type SessionLinearIssue struct {
+	ID         string `json:"id"`
+	Identifier string `json:"identifier"`
+	Title      string `json:"title,omitempty"`
+	URL        string `json:"url"`
+	// Created is true when the issue was created from the session rather than linked
+	Created  bool   `json:"created,omitempty"`
+	LinkedAt string `json:"linkedAt,omitempty"`
+}
+
+// RunSummary is a generated account of what one run accomplished, so old sessions can
+// be browsed without reading their transcripts
+type RunSummary struct {
+	RunID         string   `json:"runId"`
+	Summary       string   `json:"summary"`
+	FilesChanged  []string `json:"filesChanged,omitempty"`
+	Decisions     []string `json:"decisions,omitempty"`
+	OpenQuestions []string `json:"openQuestions,omitempty"`
+	GeneratedAt   string   `json:"generatedAt"`
+}
+
+// CreateLinearIssueRequest is the body of POST .../linear/issues.
+// Team is the Linear team's key (e.g. "ENG") or ID.
+type CreateLinearIssueRequest struct {
+	Team        string `json:"team" binding:"required"`
+	Title       string `json:"title" binding:"required"`
+	Description string `json:"description,omitempty"`
+}
+
+// LinkLinearIssueRequest is the body of POST .../linear/issues/link.
+// Issue is the issue's identifier (e.g. "ENG-123") or ID.
+type LinkLinearIssueRequest struct {
+	Issue string `json:"issue" binding:"required"`
+}
+
+// PublishConfluenceRequest is the body of POST .../publish/confluence. Source is
+// "summary" (the default, the latest run summary) or "artifact", the workspace file at
+// Path. Without PageID the page titled Title in the project's space is updated, or
+// created when there is none.
+type PublishConfluenceRequest struct {
+	Source string `json:"source,omitempty"`
+	Path   string `json:"path,omitempty"`
+	Title  string `json:"title,omitempty"`
+	PageID string `json:"pageId,omitempty"`
+}
+
+// Session board stages, in column order. Sessions without a stage are in the backlog.
+const (
+	BoardStageBacklog    = "backlog"
+	BoardStageInProgress = "in-progress"
+	BoardStageReview     = "review"
+	BoardStageDone       = "done"
+)
+
+// BoardStages are the session board's columns, in order
+var BoardStages = []string{BoardStageBacklog, BoardStageInProgress, BoardStageReview, BoardStageDone}
+
+// SessionBoard is a project's sessions grouped by board stage
+type SessionBoard struct {
+	Columns []BoardColumn `json:"columns"`
+}
+
+// BoardColumn is one board stage and its sessions, in board order
+type BoardColumn struct {
+	Stage    string           `json:"stage"`
+	Sessions []AgenticSession `json:"sessions"`
+}
+
+// MoveOnBoardRequest moves a session to Position (0-based) in the Stage column, or
+// to the end of it when Position is unset
+type MoveOnBoardRequest struct {
+	Stage    string `json:"stage" binding:"required"`
+	Position *int   `json:"position,omitempty"`
+}
+
 // ReconciledWorkflow captures reconciliation state for the active workflow
 type ReconciledWorkflow struct {
 	GitURL    string  `json:"gitUrl"`
@@ -116,3 +270,61 @@ type Condition struct {
 	LastTransitionTime string `json:"lastTransitionTime,omitempty"`
 	ObservedGeneration int64  `json:"observedGeneration,omitempty"`
 }
+
+// Condition types maintained by the operator on AgenticSession status. RunActive is
+// reported by the backend from its AG-UI run tracking.
+// IMPORTANT: Keep in sync with operator (internal/handlers/helpers.go)
+const (
+	ConditionReady               = "Ready"
+	ConditionWorkspaceReady      = "WorkspaceReady"
+	ConditionRunnerReady         = "RunnerReady"
+	ConditionCredentialsResolved = "CredentialsResolved"
+	ConditionRunActive           = "RunActive"
+)
+
+// FindCondition returns the condition with the given type, or nil if absent.
+func (s *AgenticSessionStatus) FindCondition(condType string) *Condition {
+	if s == nil {
+		return nil
+	}
+	for i := range s.Conditions {
+		if s.Conditions[i].Type == condType {
+			return &s.Conditions[i]
+		}
+	}
+	return nil
+}
+
+// SessionConditionsResponse is the lightweight status view returned by the conditions endpoint
+type SessionConditionsResponse struct {
+	Phase              string      `json:"phase"`
+	ObservedGeneration int64       `json:"observedGeneration,omitempty"`
+	Conditions         []Condition `json:"conditions"`
+}
+
+// AdminSessionSummary is one row of the cross-project session inventory
+type AdminSessionSummary struct {
+	Name        string                `json:"name"`
+	Namespace   string                `json:"namespace"`
+	DisplayName string                `json:"displayName,omitempty"`
+	Phase       string                `json:"phase"`
+	Owner       string                `json:"owner,omitempty"`
+	OwnerName   string                `json:"ownerName,omitempty"`
+	CreatedAt   string                `json:"createdAt"`
+	AgeSeconds  int64                 `json:"ageSeconds"`
+	StartTime   *string               `json:"startTime,omitempty"`
+	Interactive bool                  `json:"interactive"`
+	ActiveRuns  int                   `json:"activeRuns"`
+	Resources   AdminSessionResources `json:"resources"`
+}
+
+// AdminSessionResources summarizes the runner pods backing a session. CPU and memory
+// are the pods' declared requests and limits, not live usage.
+type AdminSessionResources struct {
+	Pods          int    `json:"pods"`
+	Restarts      int32  `json:"restarts"`
+	CPURequest    string `json:"cpuRequest,omitempty"`
+	CPULimit      string `json:"cpuLimit,omitempty"`
+	MemoryRequest string `json:"memoryRequest,omitempty"`
+	MemoryLimit   string `json:"memoryLimit,omitempty"`
+}
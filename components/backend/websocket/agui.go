@@ -3,19 +3,22 @@
 package websocket
 
 import (
+	"ambient-code-backend/eventbridge"
 	"ambient-code-backend/handlers"
+	"ambient-code-backend/logging"
+	"ambient-code-backend/pkg/agui"
 	"ambient-code-backend/types"
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
 	"net/http"
 	"os"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	authv1 "k8s.io/api/authorization/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -33,16 +36,46 @@ var (
 
 // AGUIRunState tracks the state of an AG-UI run
 type AGUIRunState struct {
-	ThreadID     string
-	RunID        string
-	ParentRunID  string
-	SessionID    string // maps to our sessionName
-	ProjectName  string
-	Status       string // "running", "completed", "error"
-	StartedAt    time.Time
-	subscribers  map[chan *types.BaseEvent]bool
-	fullEventSub map[chan interface{}]bool // For full events with all fields
-	subscriberMu sync.RWMutex
+	ThreadID             string
+	RunID                string
+	ParentRunID          string
+	BranchID             string // set on sub-agent runs registered by the runner
+	AgentName            string
+	streamRunID          string // run whose runner stream carries a sub-agent run's events
+	SessionID            string // maps to our sessionName
+	ProjectName          string
+	RequestID            string // API request that started the run, forwarded to the runner
+	Status               string // "running", "completed", "error"
+	StartedAt            time.Time
+	errorMessage         string // from RUN_ERROR, included in notifications
+	subscribers          map[chan *types.BaseEvent]bool
+	fullEventSub         map[chan interface{}]bool // For full events with all fields
+	subscriberMu         sync.RWMutex
+	toolPolicy           *types.MCPToolPolicy        // MCP tool policy snapshotted on the session
+	egressPolicy         *types.EgressPolicy         // egress allowlist snapshotted on the session
+	guardrails           *types.GuardrailEngine      // project guardrail policy when the run started
+	heldText             map[string]string           // per message, text held back for guardrails; stream goroutine only
+	guardrailInterrupted bool                        // an interrupt rule already stopped the run
+	moderator            *handlers.ContentModerator  // project content moderation when the run started
+	moderationHeld       map[string][]*types.Event   // per message, content awaiting moderation; stream goroutine only
+	toolCalls            map[string]*pendingToolCall // in-flight tool calls for usage tracking
+	toolCallsMu          sync.Mutex
+	input                types.RunAgentInput  // as submitted to the runner
+	fallback             *types.RunAgentInput // retry with the next model, started when the stream ends
+	// Per run of the stream, validators of conformance mode; stream goroutine only
+	conformance map[string]*agui.StreamValidator
+	// Negotiated with the runner's GET /version before its stream is read
+	ProtocolVersion  string
+	RunnerVersion    string
+	echoUserMessages bool // the runner doesn't echo user messages; the proxy does
+}
+
+// logContext returns a context carrying the run's request ID for logging
+func (r *AGUIRunState) logContext() context.Context {
+	if r == nil {
+		return context.Background()
+	}
+	return logging.WithRequestID(context.Background(), r.RequestID)
 }
 
 // Subscribe adds a subscriber to this run's events
@@ -103,12 +136,9 @@ func (r *AGUIRunState) BroadcastFull(event interface{}) {
 
 // RouteAGUIEvent routes an AG-UI event directly from WebSocket to subscribers
 // This is the simplified flow - no SessionMessage wrapping, no translation needed
-func RouteAGUIEvent(sessionID string, event map[string]interface{}) {
-	eventType, ok := event["type"].(string)
-	if !ok {
-		log.Printf("AGUI: Event missing type field, skipping")
-		return
-	}
+func RouteAGUIEvent(sessionID string, event *types.Event) {
+	base := event.Base()
+	now := time.Now().UTC().Format(types.AGUITimestampFormat)
 
 	// Find active run for this session
 	var activeRunState *AGUIRunState
@@ -124,23 +154,20 @@ func RouteAGUIEvent(sessionID string, event map[string]interface{}) {
 	// If no active run found, check if event has a runId we should create
 	if activeRunState == nil {
 		// Ensure timestamp is set before any early returns
-		if event["timestamp"] == nil || event["timestamp"] == "" {
-			event["timestamp"] = time.Now().UTC().Format(types.AGUITimestampFormat)
-		}
+		event.FillBase("", "", now)
 
 		// Don't create lazy runs for terminal events - they should only apply to existing runs
-		if isTerminalEventType(eventType) {
-			go persistAGUIEventMap(sessionID, "", event)
+		if isTerminalEventType(base.Type) {
+			persistPool.Submit(sessionID, func() { persistAGUIEvent(sessionID, "", event) })
 			return
 		}
 
-		eventRunID, ok := event["runId"].(string)
-		if ok && eventRunID != "" {
+		if base.RunID != "" {
 			// Create run lazily from event's runId
 			threadID := sessionID
 			activeRunState = &AGUIRunState{
 				ThreadID:     threadID,
-				RunID:        eventRunID,
+				RunID:        base.RunID,
 				SessionID:    sessionID,
 				Status:       "running",
 				StartedAt:    time.Now(),
@@ -148,62 +175,34 @@ func RouteAGUIEvent(sessionID string, event map[string]interface{}) {
 				fullEventSub: make(map[chan interface{}]bool),
 			}
 			aguiRunsMu.Lock()
-			aguiRuns[eventRunID] = activeRunState
+			aguiRuns[base.RunID] = activeRunState
 			aguiRunsMu.Unlock()
 		} else {
-			go persistAGUIEventMap(sessionID, "", event)
+			persistPool.Submit(sessionID, func() { persistAGUIEvent(sessionID, "", event) })
 			return
 		}
 	}
 
-	threadID := activeRunState.ThreadID
-	runID := activeRunState.RunID
-
-	// CRITICAL: Use runId from event if present (event is source of truth)
-	// Don't use activeRunState.RunID which might be stale
-	if eventRunID, ok := event["runId"].(string); ok && eventRunID != "" {
-		runID = eventRunID
-	}
-	if eventThreadID, ok := event["threadId"].(string); ok && eventThreadID != "" {
-		threadID = eventThreadID
-	}
-
-	// Fill in missing IDs and timestamp
-	if event["threadId"] == nil || event["threadId"] == "" {
-		event["threadId"] = threadID
-	}
-	if event["runId"] == nil || event["runId"] == "" {
-		event["runId"] = runID
-	}
-	// Add timestamp if not present - critical for message timestamp tracking
-	if event["timestamp"] == nil || event["timestamp"] == "" {
-		event["timestamp"] = time.Now().UTC().Format(types.AGUITimestampFormat)
-	}
+	// Fill in missing IDs and timestamp - critical for message timestamp tracking.
+	// IDs already on the event are the source of truth; activeRunState's may be stale.
+	event.FillBase(activeRunState.ThreadID, activeRunState.RunID, now)
+	runID := base.RunID
 
 	// Broadcast to run-specific SSE subscribers
 	activeRunState.BroadcastFull(event)
 
 	// Also broadcast to thread-level subscribers (clients watching entire session)
-	threadSubscribersMu.RLock()
-	if subscribers, exists := threadSubscribers[sessionID]; exists {
-		for ch := range subscribers {
-			select {
-			case ch <- event:
-			default:
-			}
-		}
-	}
-	threadSubscribersMu.RUnlock()
+	broadcastToThread(sessionID, event)
 
 	// Persist the event (use runID from event, not activeRunState)
-	go persistAGUIEventMap(sessionID, runID, event)
+	persistPool.Submit(sessionID, func() { persistAGUIEvent(sessionID, runID, event) })
 
 	// Check for terminal events - mark run as complete
-	if isTerminalEventType(eventType) {
-		activeRunState.Status = getTerminalStatusFromType(eventType)
+	if isTerminalEventType(base.Type) {
+		activeRunState.Status = getTerminalStatusFromType(base.Type)
 
 		// Schedule cleanup of run state (no need to compact async - we compact on SSE connect)
-		go scheduleRunCleanup(runID, 5*time.Minute)
+		scheduleRunCleanup(runID, 5*time.Minute)
 	}
 }
 
@@ -212,29 +211,72 @@ func RouteAGUIEvent(sessionID string, event map[string]interface{}) {
 // We now use "compact-on-read" strategy in streamThreadEvents.
 // This eliminates race conditions, dual-file complexity, and async compaction issues.
 
-// persistAGUIEventMap persists a map[string]interface{} event to disk
-func persistAGUIEventMap(sessionID, runID string, event map[string]interface{}) {
-	path := fmt.Sprintf("%s/sessions/%s/agui-events.jsonl", StateBaseDir, sessionID)
-	_ = ensureDir(fmt.Sprintf("%s/sessions/%s", StateBaseDir, sessionID))
-
-	data, err := json.Marshal(event)
-	if err != nil {
-		log.Printf("AGUI: failed to marshal event for persistence: %v", err)
+// persistAGUIEvent appends an event to the session's event log as received, without
+// re-encoding it. Events of encrypted sessions are not published to the event bridge.
+func persistAGUIEvent(sessionID, runID string, event *types.Event) {
+	// The log is one event per line
+	line := bytes.NewBuffer(make([]byte, 0, len(event.Raw)+1))
+	if bytes.ContainsAny(event.Raw, "\r\n") {
+		if err := json.Compact(line, event.Raw); err != nil {
+			logging.Errorf(context.Background(), "AGUI: failed to compact event for persistence: %v", err)
+			return
+		}
+	} else {
+		line.Write(event.Raw)
+	}
+	// Never fall back to plaintext for an encrypted session: events that can't be
+	// sealed are held back and written once the data key is available again
+	if !appendEventRecords(sessionID, sealEventLines(sessionID, line.Bytes())) {
 		return
 	}
 
+	if encryptedSessionProject(sessionID) == "" {
+		eventbridge.PublishEvent(projectForRun(sessionID, runID), sessionID, runID, event.Type(), event.Raw)
+	}
+}
+
+// appendEventRecords appends records to the session's event log, reporting whether
+// all were written
+func appendEventRecords(sessionID string, records [][]byte) bool {
+	if len(records) == 0 {
+		return false
+	}
+	path := fmt.Sprintf("%s/sessions/%s/agui-events.jsonl", StateBaseDir, sessionID)
+	_ = ensureDir(fmt.Sprintf("%s/sessions/%s", StateBaseDir, sessionID))
+
 	f, err := openFileAppend(path)
 	if err != nil {
-		log.Printf("AGUI: failed to open event log: %v", err)
-		return
+		logging.Errorf(context.Background(), "AGUI: failed to open event log: %v", err)
+		return false
 	}
 	defer f.Close()
 
-	if _, err := f.Write(append(data, '\n')); err != nil {
-		log.Printf("AGUI: failed to write event: %v", err)
-		return
+	var buf bytes.Buffer
+	for _, record := range records {
+		buf.Write(record)
+		buf.WriteByte('\n')
 	}
+	if _, err := f.Write(buf.Bytes()); err != nil {
+		logging.Errorf(context.Background(), "AGUI: failed to write event: %v", err)
+		return false
+	}
+	return true
+}
 
+// projectForRun returns the project of a tracked run, falling back to any tracked run
+// of the session, or "" once the session's runs are no longer tracked
+func projectForRun(sessionID, runID string) string {
+	aguiRunsMu.RLock()
+	defer aguiRunsMu.RUnlock()
+	if state, ok := aguiRuns[runID]; ok && runID != "" {
+		return state.ProjectName
+	}
+	for _, state := range aguiRuns {
+		if state.SessionID == sessionID && state.ProjectName != "" {
+			return state.ProjectName
+		}
+	}
+	return ""
 }
 
 // isTerminalEventType checks if an event type indicates run completion
@@ -260,46 +302,10 @@ func getTerminalStatusFromType(eventType string) string {
 
 // extractBaseEvent extracts the BaseEvent from any AG-UI event type
 func extractBaseEvent(event interface{}) (*types.BaseEvent, bool) {
-	switch e := event.(type) {
-	case *types.BaseEvent:
-		return e, true
-	case *types.TextMessageStartEvent:
-		return &e.BaseEvent, true
-	case *types.TextMessageContentEvent:
-		return &e.BaseEvent, true
-	case *types.TextMessageEndEvent:
-		return &e.BaseEvent, true
-	case *types.ToolCallStartEvent:
-		return &e.BaseEvent, true
-	case *types.ToolCallArgsEvent:
-		return &e.BaseEvent, true
-	case *types.ToolCallEndEvent:
-		return &e.BaseEvent, true
-	case *types.StepStartedEvent:
-		return &e.BaseEvent, true
-	case *types.StepFinishedEvent:
-		return &e.BaseEvent, true
-	case *types.RunStartedEvent:
-		return &e.BaseEvent, true
-	case *types.RunFinishedEvent:
-		return &e.BaseEvent, true
-	case *types.RunErrorEvent:
-		return &e.BaseEvent, true
-	case *types.StateSnapshotEvent:
-		return &e.BaseEvent, true
-	case *types.StateDeltaEvent:
-		return &e.BaseEvent, true
-	case *types.MessagesSnapshotEvent:
-		return &e.BaseEvent, true
-	case *types.ActivitySnapshotEvent:
-		return &e.BaseEvent, true
-	case *types.ActivityDeltaEvent:
-		return &e.BaseEvent, true
-	case *types.RawEvent:
-		return &e.BaseEvent, true
-	default:
-		return nil, false
+	if e, ok := event.(types.TypedEvent); ok {
+		return e.Base(), true
 	}
+	return nil, false
 }
 
 // LEGACY: Old HandleAGUIRun function removed - replaced by HandleAGUIRunProxy
@@ -309,26 +315,11 @@ func extractBaseEvent(event interface{}) (*types.BaseEvent, bool) {
 // This is the correct AG-UI pattern: client connects to thread, not individual runs
 func streamThreadEvents(c *gin.Context, projectName, sessionName string) {
 	threadID := sessionName
-	eventCh := make(chan interface{}, 100)
 	ctx := c.Request.Context()
 
 	// Subscribe to all current and future runs for this session
-	threadSubscribersMu.Lock()
-	if threadSubscribers[sessionName] == nil {
-		threadSubscribers[sessionName] = make(map[chan interface{}]bool)
-	}
-	threadSubscribers[sessionName][eventCh] = true
-	threadSubscribersMu.Unlock()
-
-	defer func() {
-		threadSubscribersMu.Lock()
-		delete(threadSubscribers[sessionName], eventCh)
-		if len(threadSubscribers[sessionName]) == 0 {
-			delete(threadSubscribers, sessionName)
-		}
-		threadSubscribersMu.Unlock()
-		close(eventCh)
-	}()
+	eventCh, unsubscribe := SubscribeSession(sessionName)
+	defer unsubscribe()
 
 	// OPTION 1: Compact-on-Read Strategy (COMPLETED RUNS ONLY)
 	// Load events from agui-events.jsonl and compact only COMPLETED runs
@@ -437,7 +428,7 @@ func streamThreadEvents(c *gin.Context, projectName, sessionName string) {
 			c.Writer.(http.Flusher).Flush()
 		}
 	} else if err != nil {
-		log.Printf("AGUI: Failed to load events: %v", err)
+		logging.Errorf(c, "AGUI: Failed to load events: %v", err)
 	}
 
 	// Replay ALL active runs (not just most recent)
@@ -464,6 +455,7 @@ func streamThreadEvents(c *gin.Context, projectName, sessionName string) {
 				if activeRunState.ParentRunID != "" {
 					runStarted.ParentRunID = activeRunState.ParentRunID
 				}
+				runStarted.BranchID = activeRunState.BranchID
 				writeSSEEvent(c.Writer, runStarted)
 
 				// Send state snapshot
@@ -501,7 +493,7 @@ func streamThreadEvents(c *gin.Context, projectName, sessionName string) {
 			// Send SSE comment to prevent gateway timeout
 			_, err := c.Writer.Write([]byte(": keepalive\n\n"))
 			if err != nil {
-				log.Printf("AGUI: Keepalive write failed, closing stream: %v", err)
+				logging.Errorf(c, "AGUI: Keepalive write failed, closing stream: %v", err)
 				return
 			}
 			c.Writer.(http.Flusher).Flush()
@@ -515,6 +507,35 @@ func streamThreadEvents(c *gin.Context, projectName, sessionName string) {
 	}
 }
 
+// SubscribeSession subscribes to the events of all current and future runs of
+// sessionName. Call the returned function to unsubscribe; it closes the channel.
+func SubscribeSession(sessionName string) (<-chan interface{}, func()) {
+	eventCh := make(chan interface{}, 100)
+	threadSubscribersMu.Lock()
+	if threadSubscribers[sessionName] == nil {
+		threadSubscribers[sessionName] = make(map[chan interface{}]bool)
+	}
+	threadSubscribers[sessionName][eventCh] = true
+	threadSubscribersMu.Unlock()
+
+	unsubscribe := func() {
+		threadSubscribersMu.Lock()
+		delete(threadSubscribers[sessionName], eventCh)
+		if len(threadSubscribers[sessionName]) == 0 {
+			delete(threadSubscribers, sessionName)
+		}
+		threadSubscribersMu.Unlock()
+		close(eventCh)
+	}
+	return eventCh, unsubscribe
+}
+
+// SessionEvents returns the persisted events of sessionName, only those of runID
+// when it is set
+func SessionEvents(sessionName, runID string) ([]map[string]interface{}, error) {
+	return loadEventsForRun(sessionName, runID)
+}
+
 // HandleAGUIEvents handles GET /api/projects/:projectName/agentic-sessions/:sessionName/agui/events
 // This is the AG-UI SSE stream endpoint
 // See: https://docs.ag-ui.com/quickstart/middleware
@@ -523,35 +544,6 @@ func HandleAGUIEvents(c *gin.Context) {
 	sessionName := c.Param("sessionName")
 	runID := c.Query("runId")
 
-	// SECURITY: Authenticate user and get user-scoped K8s client
-	reqK8s, _ := handlers.GetK8sClientsForRequest(c)
-	if reqK8s == nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing token"})
-		c.Abort()
-		return
-	}
-
-	// SECURITY: Verify user has permission to read this session
-	ctx := context.Background()
-	ssar := &authv1.SelfSubjectAccessReview{
-		Spec: authv1.SelfSubjectAccessReviewSpec{
-			ResourceAttributes: &authv1.ResourceAttributes{
-				Group:     "vteam.ambient-code",
-				Resource:  "agenticsessions",
-				Verb:      "get",
-				Namespace: projectName,
-				Name:      sessionName,
-			},
-		},
-	}
-	res, err := reqK8s.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, ssar, metav1.CreateOptions{})
-	if err != nil || !res.Status.Allowed {
-		log.Printf("AGUI Events: User not authorized to read session %s/%s", projectName, sessionName)
-		c.JSON(http.StatusForbidden, gin.H{"error": "Unauthorized"})
-		c.Abort()
-		return
-	}
-
 	// Set SSE headers
 	c.Header("Content-Type", "text/event-stream")
 	c.Header("Cache-Control", "no-cache")
@@ -561,6 +553,7 @@ func HandleAGUIEvents(c *gin.Context) {
 	// If no runId specified, stream the entire THREAD (all runs for this session)
 	// This is the correct AG-UI pattern: client connects once to thread stream
 	if runID == "" {
+		defer joinPresence(c, sessionName, presenceParticipant)()
 		streamThreadEvents(c, projectName, sessionName)
 		return
 	}
@@ -606,7 +599,7 @@ func HandleAGUIEvents(c *gin.Context) {
 	func() {
 		defer func() {
 			if r := recover(); r != nil {
-				log.Printf("AGUI: panic in sendInitialSyncEvents: %v", r)
+				logging.Errorf(c, "AGUI: panic in sendInitialSyncEvents: %v", r)
 			}
 		}()
 		sendInitialSyncEvents(c, runState, projectName, sessionName)
@@ -652,7 +645,7 @@ func sendInitialSyncEvents(c *gin.Context, runState *AGUIRunState, projectName,
 	// Per AG-UI spec: compact at read-time, not write-time
 	events, err := loadEventsForRun(sessionName, runID)
 	if err != nil {
-		log.Printf("AGUI: Failed to load events for %s: %v", sessionName, err)
+		logging.Errorf(c, "AGUI: Failed to load events for %s: %v", sessionName, err)
 	}
 
 	if len(events) > 0 {
@@ -694,29 +687,28 @@ func sendBasicStateSnapshot(c *gin.Context, runState *AGUIRunState, projectName,
 
 // writeSSEEvent writes an event in SSE format
 func writeSSEEvent(w http.ResponseWriter, event interface{}) {
-	data, err := json.Marshal(event)
-	if err != nil {
-		log.Printf("AGUI: failed to marshal event: %v", err)
+	if err := agui.WriteSSE(w, event); err != nil {
+		logging.Errorf(context.Background(), "AGUI: failed to write event: %v", err)
 		return
 	}
-
-	fmt.Fprintf(w, "data: %s\n\n", data)
 	if f, ok := w.(http.Flusher); ok {
 		f.Flush()
 	}
 }
 
-// scheduleRunCleanup removes a run from the active runs map after a delay
+// scheduleRunCleanup removes a run from the active runs map after a delay. A timer
+// rather than a sleeping goroutine per run.
 func scheduleRunCleanup(runID string, delay time.Duration) {
-	time.Sleep(delay)
-	aguiRunsMu.Lock()
-	if run, ok := aguiRuns[runID]; ok {
-		// Only delete if run is no longer active
-		if run.Status != "running" {
-			delete(aguiRuns, runID)
+	time.AfterFunc(delay, func() {
+		aguiRunsMu.Lock()
+		if run, ok := aguiRuns[runID]; ok {
+			// Only delete if run is no longer active
+			if run.Status != "running" {
+				delete(aguiRuns, runID)
+			}
 		}
-	}
-	aguiRunsMu.Unlock()
+		aguiRunsMu.Unlock()
+	})
 }
 
 // cleanupOldRuns periodically cleans up old inactive runs
@@ -740,6 +732,35 @@ func cleanupInactiveRuns() {
 	}
 }
 
+// ActiveRunCounts returns the number of running AG-UI runs per session name
+func ActiveRunCounts() map[string]int {
+	counts := map[string]int{}
+	aguiRunsMu.RLock()
+	defer aguiRunsMu.RUnlock()
+	for _, run := range aguiRuns {
+		if run.Status == "running" {
+			counts[run.SessionID]++
+		}
+	}
+	return counts
+}
+
+// RunFinalMessage returns the text of the last assistant message in a run, or "" if
+// the run produced none
+func RunFinalMessage(sessionID, runID string) string {
+	events, err := loadEventsForRun(sessionID, runID)
+	if err != nil {
+		return ""
+	}
+	messages := CompactEvents(events)
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Role == types.RoleAssistant && strings.TrimSpace(messages[i].Content) != "" {
+			return messages[i].Content
+		}
+	}
+	return ""
+}
+
 // Legacy translation functions removed - AG-UI events now route directly via RouteAGUIEvent
 
 // Helper functions for state and message retrieval
@@ -759,7 +780,7 @@ func getSessionState(projectName, sessionName string) (map[string]interface{}, e
 		context.Background(), sessionName, metav1.GetOptions{},
 	)
 	if err != nil {
-		log.Printf("AGUI: failed to get session state: %v", err)
+		logging.Errorf(context.Background(), "AGUI: failed to get session state: %v", err)
 		return map[string]interface{}{
 			"phase":       "Unknown",
 			"interactive": true,
@@ -817,20 +838,23 @@ func persistRunMetadata(sessionID string, meta types.AGUIRunMetadata) {
 
 	data, err := json.Marshal(meta)
 	if err != nil {
-		log.Printf("AGUI: failed to marshal run metadata: %v", err)
+		logging.Errorf(context.Background(), "AGUI: failed to marshal run metadata: %v", err)
 		return
 	}
 
 	f, err := openFileAppend(path)
 	if err != nil {
-		log.Printf("AGUI: failed to open runs index: %v", err)
+		logging.Errorf(context.Background(), "AGUI: failed to open runs index: %v", err)
 		return
 	}
 	defer f.Close()
 
 	if _, err := f.Write(append(data, '\n')); err != nil {
-		log.Printf("AGUI: failed to write run metadata: %v", err)
+		logging.Errorf(context.Background(), "AGUI: failed to write run metadata: %v", err)
+		return
 	}
+
+	eventbridge.PublishRun(meta.ProjectName, sessionID, meta.RunID, meta.Status, data)
 }
 
 // loadRunsFromDisk loads persisted run metadata from disk
@@ -841,7 +865,7 @@ func loadRunsFromDisk(sessionID string) []types.AGUIRunMetadata {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		if !os.IsNotExist(err) {
-			log.Printf("AGUI: failed to read runs index: %v", err)
+			logging.Errorf(context.Background(), "AGUI: failed to read runs index: %v", err)
 		}
 		return runs
 	}
@@ -871,7 +895,7 @@ func loadEventsForRun(sessionID, runID string) ([]map[string]interface{}, error)
 		if os.IsNotExist(err) {
 			// Check if legacy messages.json exists and migrate
 			if err := MigrateLegacySessionToAGUI(sessionID); err != nil {
-				log.Printf("LegacyMigration: Failed to migrate session %s: %v", sessionID, err)
+				logging.Errorf(context.Background(), "LegacyMigration: Failed to migrate session %s: %v", sessionID, err)
 			} else {
 				// Try reading again after migration
 				data, err = os.ReadFile(path)
@@ -890,7 +914,7 @@ func loadEventsForRun(sessionID, runID string) ([]map[string]interface{}, error)
 	events := make([]map[string]interface{}, 0)
 	lines := splitLines(data)
 	for _, line := range lines {
-		if len(line) == 0 {
+		if line = openEventLine(sessionID, line); len(line) == 0 {
 			continue
 		}
 		var event map[string]interface{}
@@ -931,37 +955,7 @@ func splitLines(data []byte) [][]byte {
 // HandleAGUIHistory handles GET /api/projects/:projectName/agentic-sessions/:sessionName/agui/history
 // Returns compacted message history for a session
 func HandleAGUIHistory(c *gin.Context) {
-	projectName := c.Param("projectName")
 	sessionName := c.Param("sessionName")
-
-	// SECURITY: Authenticate user and get user-scoped K8s client
-	reqK8s, _ := handlers.GetK8sClientsForRequest(c)
-	if reqK8s == nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing token"})
-		c.Abort()
-		return
-	}
-
-	// SECURITY: Verify user has permission to read this session
-	ctx := context.Background()
-	ssar := &authv1.SelfSubjectAccessReview{
-		Spec: authv1.SelfSubjectAccessReviewSpec{
-			ResourceAttributes: &authv1.ResourceAttributes{
-				Group:     "vteam.ambient-code",
-				Resource:  "agenticsessions",
-				Verb:      "get",
-				Namespace: projectName,
-				Name:      sessionName,
-			},
-		},
-	}
-	res, err := reqK8s.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, ssar, metav1.CreateOptions{})
-	if err != nil || !res.Status.Allowed {
-		log.Printf("AGUI History: User not authorized to read session %s/%s", projectName, sessionName)
-		c.JSON(http.StatusForbidden, gin.H{"error": "Unauthorized"})
-		c.Abort()
-		return
-	}
 	runID := c.Query("runId")
 
 	// Compact events to messages
@@ -985,48 +979,75 @@ func HandleAGUIHistory(c *gin.Context) {
 }
 
 // HandleAGUIRuns handles GET /api/projects/:projectName/agentic-sessions/:sessionName/agui/runs
-// Returns list of runs for a session (thread)
+// Returns list of runs for a session (thread). ?fields= selects run fields;
+// ?expand=stats adds each run's token, cost and timing stats. Answers 304 to an
+// If-None-Match naming the current list's ETag.
 func HandleAGUIRuns(c *gin.Context) {
-	projectName := c.Param("projectName")
 	sessionName := c.Param("sessionName")
-
-	// SECURITY: Authenticate user and get user-scoped K8s client
-	reqK8s, _ := handlers.GetK8sClientsForRequest(c)
-	if reqK8s == nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing token"})
-		c.Abort()
+	fields, expand, ok := handlers.ParseListSelection(c, "stats")
+	if !ok {
 		return
 	}
 
-	// SECURITY: Verify user has permission to read this session
-	ctx := context.Background()
-	ssar := &authv1.SelfSubjectAccessReview{
-		Spec: authv1.SelfSubjectAccessReviewSpec{
-			ResourceAttributes: &authv1.ResourceAttributes{
-				Group:     "vteam.ambient-code",
-				Resource:  "agenticsessions",
-				Verb:      "get",
-				Namespace: projectName,
-				Name:      sessionName,
-			},
-		},
-	}
-	res, err := reqK8s.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, ssar, metav1.CreateOptions{})
-	if err != nil || !res.Status.Allowed {
-		log.Printf("AGUI Runs: User not authorized to read session %s/%s", projectName, sessionName)
-		c.JSON(http.StatusForbidden, gin.H{"error": "Unauthorized"})
-		c.Abort()
+	runs := getRunsForSession(sessionName)
+	if fields == nil && len(expand) == 0 {
+		handlers.JSONWithETag(c, http.StatusOK, gin.H{
+			"threadId": sessionName,
+			"runs":     runs,
+		})
 		return
 	}
 
-	runs := getRunsForSession(sessionName)
-
-	c.JSON(http.StatusOK, gin.H{
+	selected, err := selectRuns(sessionName, runs, fields, expand)
+	if err != nil {
+		logging.Errorf(c, "AGUI Runs: Failed to select fields for %s: %v", sessionName, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list runs"})
+		return
+	}
+	handlers.JSONWithETag(c, http.StatusOK, gin.H{
 		"threadId": sessionName,
-		"runs":     runs,
+		"runs":     selected,
 	})
 }
 
+// selectRuns adds the requested expansions to a session's runs and cuts them down to
+// the selected fields
+func selectRuns(sessionName string, runs []types.AGUIRunMetadata, fields handlers.FieldSelection, expand map[string]bool) ([]interface{}, error) {
+	var eventsByRun map[string][]map[string]interface{}
+	if expand["stats"] {
+		// One read of the thread's log serves every run
+		events, err := loadEventsForRun(sessionName, "")
+		if err != nil {
+			return nil, err
+		}
+		eventsByRun = map[string][]map[string]interface{}{}
+		for _, event := range events {
+			runID, _ := event["runId"].(string)
+			eventsByRun[runID] = append(eventsByRun[runID], event)
+		}
+	}
+	out := make([]interface{}, 0, len(runs))
+	for _, run := range runs {
+		v, err := handlers.ToJSONValue(run)
+		if err != nil {
+			return nil, err
+		}
+		obj, _ := v.(map[string]interface{})
+		if expand["stats"] {
+			if obj["stats"], err = handlers.ToJSONValue(runStats(run, eventsByRun[run.RunID])); err != nil {
+				return nil, err
+			}
+		}
+		out = append(out, fields.Select(obj))
+	}
+	return out, nil
+}
+
+// SessionRuns returns a session's runs, persisted, active and queued
+func SessionRuns(sessionName string) []types.AGUIRunMetadata {
+	return getRunsForSession(sessionName)
+}
+
 func getRunsForSession(sessionID string) []types.AGUIRunMetadata {
 	// First load from disk (historical runs)
 	runs := loadRunsFromDisk(sessionID)
@@ -1042,18 +1063,29 @@ func getRunsForSession(sessionID string) []types.AGUIRunMetadata {
 	for _, run := range aguiRuns {
 		if run.SessionID == sessionID && !diskRunIDs[run.RunID] {
 			meta := types.AGUIRunMetadata{
-				ThreadID:    run.ThreadID,
-				RunID:       run.RunID,
-				ParentRunID: run.ParentRunID,
-				SessionName: run.SessionID,
-				ProjectName: run.ProjectName,
-				StartedAt:   run.StartedAt.Format(time.RFC3339),
-				Status:      run.Status,
+				ThreadID:        run.ThreadID,
+				RunID:           run.RunID,
+				ParentRunID:     run.ParentRunID,
+				BranchID:        run.BranchID,
+				AgentName:       run.AgentName,
+				SessionName:     run.SessionID,
+				ProjectName:     run.ProjectName,
+				StartedAt:       run.StartedAt.Format(time.RFC3339),
+				Status:          run.Status,
+				ProtocolVersion: run.ProtocolVersion,
+				RunnerVersion:   run.RunnerVersion,
+				Priority:        run.input.Priority,
 			}
 			runs = append(runs, meta)
 		}
 	}
 	aguiRunsMu.RUnlock()
+	runs = append(runs, queuedRunsForSession(sessionID)...)
+
+	summaries := loadRunSummaries(sessionID)
+	for i := range runs {
+		runs[i].Summary = summaries[runs[i].RunID]
+	}
 
 	return runs
 }
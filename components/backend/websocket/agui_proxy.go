@@ -2,23 +2,23 @@
 package websocket
 
 import (
+	"ambient-code-backend/config"
 	"ambient-code-backend/handlers"
+	"ambient-code-backend/logging"
+	"ambient-code-backend/pkg/agui"
 	"ambient-code-backend/types"
-	"bufio"
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"log"
 	"net/http"
 	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
-	authv1 "k8s.io/api/authorization/v1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 )
 
@@ -28,44 +28,23 @@ func HandleAGUIRunProxy(c *gin.Context) {
 	projectName := c.Param("projectName")
 	sessionName := c.Param("sessionName")
 
-	// SECURITY: Authenticate user and get user-scoped K8s client
-	reqK8s, _ := handlers.GetK8sClientsForRequest(c)
-	if reqK8s == nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing token"})
-		c.Abort()
-		return
-	}
-
-	// SECURITY: Verify user has permission to update this session
-	ctx := context.Background()
-	ssar := &authv1.SelfSubjectAccessReview{
-		Spec: authv1.SelfSubjectAccessReviewSpec{
-			ResourceAttributes: &authv1.ResourceAttributes{
-				Group:     "vteam.ambient-code",
-				Resource:  "agenticsessions",
-				Verb:      "update",
-				Namespace: projectName,
-				Name:      sessionName,
-			},
-		},
-	}
-	res, err := reqK8s.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, ssar, metav1.CreateOptions{})
-	if err != nil || !res.Status.Allowed {
-		log.Printf("AGUI Proxy: User not authorized to update session %s/%s", projectName, sessionName)
-		c.JSON(http.StatusForbidden, gin.H{"error": "Unauthorized"})
-		c.Abort()
-		return
-	}
-
-	log.Printf("AGUI Proxy: Forwarding run request for %s/%s", projectName, sessionName)
+	logging.Infof(c, "AGUI Proxy: Forwarding run request for %s/%s", projectName, sessionName)
 
 	var input types.RunAgentInput
 	if err := c.ShouldBindJSON(&input); err != nil {
-		log.Printf("AGUI Proxy: Failed to parse input: %v", err)
+		logging.Errorf(c, "AGUI Proxy: Failed to parse input: %v", err)
 		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid input: %v", err)})
 		return
 	}
-	log.Printf("AGUI Proxy: Input has %d messages", len(input.Messages))
+	logging.Debugf(c, "AGUI Proxy: Input has %d messages", len(input.Messages))
+	proxyRun(c, input, nil)
+}
+
+// proxyRun validates and starts a run of the session named in the route, answering
+// with the run metadata; extra entries are added to successful responses
+func proxyRun(c *gin.Context, input types.RunAgentInput, extra gin.H) {
+	projectName := c.Param("projectName")
+	sessionName := c.Param("sessionName")
 
 	// Generate or use provided IDs
 	threadID := input.ThreadID
@@ -79,7 +58,147 @@ func HandleAGUIRunProxy(c *gin.Context) {
 	input.ThreadID = threadID
 	input.RunID = runID
 
-	log.Printf("AGUI Proxy: Creating run %s for session %s (threadId=%s)", runID, sessionName, threadID)
+	logging.Infof(c, "AGUI Proxy: Creating run %s for session %s (threadId=%s)", runID, sessionName, threadID)
+
+	if err := handlers.ValidateFallbackModels(input.Model, input.FallbackModels); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err := validateRunPriority(&input); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	// Runs past the project's or the session owner's monthly token quota are refused
+	if quota := handlers.CheckTokenQuota(c.Request.Context(), projectName, sessionName); quota != nil {
+		logging.Warnf(c, "AGUI Proxy: Refusing run %s of %s/%s: monthly token quota exceeded", runID, projectName, sessionName)
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": "Monthly token quota exceeded", "code": types.ErrorCodeQuotaExceeded, "quota": quota})
+		return
+	}
+	if !applySessionPersona(c, projectName, sessionName, &input) {
+		return
+	}
+	resolveModelChain(c.Request.Context(), projectName, sessionName, &input)
+
+	// A prompt template reference is rendered here and sent as the run's user message,
+	// so team prompts live in the project library rather than in clients
+	if input.PromptTemplate != nil {
+		msg, ok := renderRunPrompt(c, projectName, input.PromptTemplate)
+		if !ok {
+			return
+		}
+		input.Messages = append(input.Messages, msg)
+		input.PromptTemplate = nil
+	}
+
+	// Attached files are resolved to what the runner can read: uploads inline, workspace
+	// files as file:// URLs
+	if err := resolveAttachments(sessionName, input.Messages); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// compact=true replaces older turns with a summary so long threads stay within the
+	// runner's context limit
+	var compaction *Compaction
+	if c.Query("compact") == "true" && isValidSessionName(sessionName) {
+		messages, compacted, err := compactMessages(c.Request.Context(), projectName, sessionName, runID, input.Messages)
+		if err != nil {
+			logging.Errorf(c, "AGUI Proxy: Failed to compact context for %s: %v", sessionName, err)
+			c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to compact conversation context"})
+			return
+		}
+		if compacted != nil {
+			logging.Infof(c, "AGUI Proxy: Compacted %d messages of run %s into %s", len(compacted.ReplacedMessageIDs), runID, compacted.ID)
+		}
+		input.Messages, compaction = messages, compacted
+	}
+
+	// User messages are persisted as TEXT_MESSAGE events when they stream through
+	// this proxy: echoed by the runner, or by the proxy for runners whose GET /version
+	// doesn't announce userMessageEcho (see negotiateRunnerVersion)
+
+	// Trigger async display name generation on first user message
+	// This generates a descriptive name using Claude Haiku based on the message
+	backgroundPool.Submit(sessionName, func() {
+		triggerDisplayNameGenerationIfNeeded(projectName, sessionName, input.Messages)
+	})
+
+	// Return run metadata immediately (don't wait for stream)
+	// Events will be broadcast to GET /agui/events subscribers
+	streamURL := fmt.Sprintf("/api/projects/%s/agentic-sessions/%s/agui/events", projectName, sessionName)
+	handlers.RecordSessionActivity(projectName, sessionName)
+	// The message is sent, so its author no longer shows as typing
+	setTyping(sessionName, c.GetString("userID"), false)
+
+	// A session paused for inactivity is resumed by its next run, which starts once
+	// the runner is back
+	resumed, err := handlers.ResumeIdleSession(c, projectName, sessionName)
+	if err != nil {
+		logging.Warnf(c, "AGUI Proxy: Failed to resume idle session %s: %v", sessionName, err)
+	}
+	if resumed {
+		runCtx := logging.WithRequestID(context.Background(), logging.RequestIDFromContext(c.Request.Context()))
+		go startRunWhenResumed(runCtx, projectName, sessionName, input)
+		response := gin.H{
+			"threadId":  threadID,
+			"runId":     runID,
+			"streamUrl": streamURL,
+			"status":    "resuming",
+		}
+		for k, v := range extra {
+			response[k] = v
+		}
+		c.JSON(http.StatusAccepted, response)
+		return
+	}
+
+	// Runs past the project's run limit wait in its queue, interactive before batch
+	queued, position, err := submitRun(c.Request.Context(), projectName, sessionName, input)
+	if errors.Is(err, errRunQueueFull) {
+		logging.Warnf(c, "AGUI Proxy: Refusing run %s of %s/%s: run queue is full", runID, projectName, sessionName)
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": "Run queue is full"})
+		return
+	}
+	if err != nil {
+		logging.Errorf(c, "AGUI Proxy: Failed to start run %s: %v", runID, err)
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Runner not available"})
+		return
+	}
+
+	response := gin.H{
+		"threadId":  threadID,
+		"runId":     runID,
+		"streamUrl": streamURL,
+		"status":    "started",
+	}
+	for k, v := range extra {
+		response[k] = v
+	}
+	if compaction != nil {
+		response["compaction"] = gin.H{
+			"id":               compaction.ID,
+			"replacedMessages": len(compaction.ReplacedMessageIDs),
+		}
+	}
+	if queued {
+		response["status"] = "queued"
+		response["queuePosition"] = position
+		c.JSON(http.StatusAccepted, response)
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// startRunStream registers a run and starts a background goroutine that POSTs the
+// input to the session's runner and persists/broadcasts the streamed events.
+// input.ThreadID and input.RunID must already be set. The request ID carried by ctx
+// is forwarded to the runner and recorded on the run; the stream outlives ctx.
+func startRunStream(ctx context.Context, projectName, sessionName string, input types.RunAgentInput) (*AGUIRunState, error) {
+	threadID := input.ThreadID
+	runID := input.RunID
+	requestID := logging.RequestIDFromContext(ctx)
+	runCtx := logging.WithRequestID(context.Background(), requestID)
+	applyRunModel(&input)
 
 	// Create run state for tracking
 	runState := &AGUIRunState{
@@ -88,79 +207,81 @@ func HandleAGUIRunProxy(c *gin.Context) {
 		ParentRunID:  input.ParentRunID,
 		SessionID:    sessionName,
 		ProjectName:  projectName,
+		RequestID:    requestID,
 		Status:       "running",
 		StartedAt:    time.Now(),
 		subscribers:  make(map[chan *types.BaseEvent]bool),
 		fullEventSub: make(map[chan interface{}]bool),
+		toolPolicy:   loadSessionToolPolicy(projectName, sessionName),
+		egressPolicy: loadSessionEgressPolicy(projectName, sessionName),
+		guardrails:   handlers.GuardrailEngineForProject(ctx, projectName),
+		moderator:    handlers.ContentModeratorForProject(ctx, projectName),
+		input:        input,
 	}
 
 	aguiRunsMu.Lock()
 	aguiRuns[runID] = runState
 	aguiRunsMu.Unlock()
 
+	if handlers.TranscriptEncryptionEnabled(ctx, projectName) {
+		markSessionEncrypted(projectName, sessionName)
+	}
+
 	// Persist run metadata
-	go persistRunMetadata(sessionName, types.AGUIRunMetadata{
+	meta := types.AGUIRunMetadata{
 		ThreadID:    threadID,
 		RunID:       runID,
 		ParentRunID: input.ParentRunID,
 		SessionName: sessionName,
 		ProjectName: projectName,
+		RequestID:   requestID,
 		StartedAt:   runState.StartedAt.Format(time.RFC3339),
 		Status:      "running",
-	})
-
-	// NOTE: User messages are now echoed by the runner (AG-UI server pattern)
-	// The runner emits TEXT_MESSAGE_START/CONTENT/END events which are persisted
-	// when they stream through this proxy. No need to echo them here.
-
-	// Trigger async display name generation on first user message
-	// This generates a descriptive name using Claude Haiku based on the message
-	go triggerDisplayNameGenerationIfNeeded(projectName, sessionName, input.Messages)
+		Priority:    input.Priority,
+	}
+	persistPool.Submit(sessionName, func() { persistRunMetadata(sessionName, meta) })
+	backgroundPool.Submit(sessionName, func() { handlers.ReportRunCheck(projectName, sessionName, "running") })
 
 	// Get runner endpoint
 	runnerURL, err := getRunnerEndpoint(projectName, sessionName)
 	if err != nil {
-		log.Printf("AGUI Proxy: Failed to get runner endpoint: %v", err)
-		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Runner not available"})
-		return
+		updateRunStatus(runID, "error")
+		return nil, fmt.Errorf("failed to get runner endpoint: %w", err)
 	}
 
-	log.Printf("AGUI Proxy: Runner endpoint: %s", runnerURL)
+	logging.Debugf(runCtx, "AGUI Proxy: Runner endpoint: %s", runnerURL)
 
 	// Serialize input for proxy request
 	bodyBytes, err := json.Marshal(input)
 	if err != nil {
-		log.Printf("AGUI Proxy: Failed to serialize input: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to serialize input"})
-		return
+		updateRunStatus(runID, "error")
+		return nil, fmt.Errorf("failed to serialize input: %w", err)
 	}
 
-	log.Printf("AGUI Proxy: Run %s starting, will consume runner stream in background", runID)
+	logging.Infof(runCtx, "AGUI Proxy: Run %s starting, will consume runner stream in background", runID)
 
 	// Start background goroutine that owns the entire HTTP lifecycle
 	// This ensures the connection stays open after we return to client
-	// Note: We use context.Background() (not request context) because this goroutine
+	// Note: runCtx derives from context.Background() (not the request context) because this goroutine
 	// must continue running after the HTTP request completes. The timeout and terminal
 	// event handling prevent unbounded goroutine accumulation.
 	go func() {
 		// Create request with long timeout (detached from client request lifecycle)
-		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Hour)
+		ctx, cancel := context.WithTimeout(runCtx, 2*time.Hour)
 		defer cancel()
 
 		// Execute request with retries (runner may not be ready immediately after startup)
-		client := &http.Client{
-			Timeout: 0, // No timeout, context handles it
-		}
+		client := runnerStreamClient()
 
 		var resp *http.Response
-		maxRetries := 15
+		maxRetries := config.Current().RunnerConnectRetries
 		retryDelay := 500 * time.Millisecond
 
 		for attempt := 1; attempt <= maxRetries; attempt++ {
 			// Create fresh request for each attempt (body reader needs reset)
 			proxyReq, err := http.NewRequestWithContext(ctx, "POST", runnerURL, bytes.NewReader(bodyBytes))
 			if err != nil {
-				log.Printf("AGUI Proxy: Failed to create request in background: %v", err)
+				logging.Errorf(runCtx, "AGUI Proxy: Failed to create request in background: %v", err)
 				updateRunStatus(runID, "error")
 				return
 			}
@@ -168,6 +289,7 @@ func HandleAGUIRunProxy(c *gin.Context) {
 			// Forward headers
 			proxyReq.Header.Set("Content-Type", "application/json")
 			proxyReq.Header.Set("Accept", "text/event-stream")
+			logging.SetRequestIDHeader(proxyReq)
 
 			resp, err = client.Do(proxyReq)
 			if err == nil {
@@ -181,16 +303,16 @@ func HandleAGUIRunProxy(c *gin.Context) {
 				strings.Contains(errStr, "dial tcp")
 
 			if !isConnectionRefused || attempt == maxRetries {
-				log.Printf("AGUI Proxy: Background request failed after %d attempts: %v", attempt, err)
+				logging.Warnf(runCtx, "AGUI Proxy: Background request failed after %d attempts: %v", attempt, err)
 				updateRunStatus(runID, "error")
 				return
 			}
 
-			log.Printf("AGUI Proxy: Runner not ready (attempt %d/%d), retrying in %v...", attempt, maxRetries, retryDelay)
+			logging.Warnf(runCtx, "AGUI Proxy: Runner not ready (attempt %d/%d), retrying in %v...", attempt, maxRetries, retryDelay)
 
 			select {
 			case <-ctx.Done():
-				log.Printf("AGUI Proxy: Context cancelled during retry for run %s", runID)
+				logging.Infof(runCtx, "AGUI Proxy: Context cancelled during retry for run %s", runID)
 				return
 			case <-time.After(retryDelay):
 				// Exponential backoff with cap at 5 seconds
@@ -204,125 +326,244 @@ func HandleAGUIRunProxy(c *gin.Context) {
 
 		if resp.StatusCode != http.StatusOK {
 			body, _ := io.ReadAll(resp.Body)
-			log.Printf("AGUI Proxy: Runner returned status %d: %s", resp.StatusCode, string(body))
+			logging.Warnf(runCtx, "AGUI Proxy: Runner returned status %d: %s", resp.StatusCode, string(body))
 			updateRunStatus(runID, "error")
 			return
 		}
 
-		log.Printf("AGUI Proxy: Background stream started for run %s", runID)
-
-		reader := bufio.NewReader(resp.Body)
+		logging.Infof(runCtx, "AGUI Proxy: Background stream started for run %s", runID)
+		recordRunnerVersion(runState, negotiateRunnerVersion(ctx, runnerURL))
 
-		for {
-			// Check if context was cancelled (timeout or cleanup)
-			select {
-			case <-ctx.Done():
-				log.Printf("AGUI Proxy: Context cancelled for run %s", runID)
-				return
-			default:
-			}
-
-			line, err := reader.ReadString('\n')
-			if err != nil {
-				if err == io.EOF {
-					log.Printf("AGUI Proxy: Background stream ended for run %s", runID)
-					break
-				}
-				log.Printf("AGUI Proxy: Background stream read error: %v", err)
-				break
-			}
-
-			// Parse and persist SSE events
-			line = strings.TrimSpace(line)
-			if strings.HasPrefix(line, "data: ") {
-				jsonData := strings.TrimPrefix(line, "data: ")
-				handleStreamedEvent(sessionName, runID, threadID, jsonData, runState)
-			}
+		if !consumeRunStream(ctx, resp.Body, sessionName, runID, threadID, runState) {
+			return
 		}
 
-		// Mark run as completed
+		// A stream that ends without RUN_FINISHED/RUN_ERROR was cut off (e.g. the runner
+		// pod was preempted). Mark it interrupted and wait for the operator to bring the
+		// session back so the run can be resubmitted.
 		aguiRunsMu.RLock()
-		currentStatus := "completed"
-		if state, exists := aguiRuns[runID]; exists && state.Status == "error" {
-			currentStatus = "error"
+		currentStatus := "interrupted"
+		if state, exists := aguiRuns[runID]; exists && state.Status != "running" {
+			currentStatus = state.Status
 		}
 		aguiRunsMu.RUnlock()
 
 		updateRunStatus(runID, currentStatus)
-		log.Printf("AGUI Proxy: Background stream completed for run %s (status=%s)", runID, currentStatus)
+		finishSubAgentRuns(runID, currentStatus)
+		logging.Infof(runCtx, "AGUI Proxy: Background stream completed for run %s (status=%s)", runID, currentStatus)
+		if currentStatus == "interrupted" {
+			forgetRunnerVersion(runnerURL)
+			go watchForRunRecovery(runCtx, projectName, sessionName, input)
+		}
+		startModelFallback(runCtx, projectName, sessionName, runState)
 	}()
 
-	// Return run metadata immediately (don't wait for stream)
-	// Events will be broadcast to GET /agui/events subscribers
-	streamURL := fmt.Sprintf("/api/projects/%s/agentic-sessions/%s/agui/events", projectName, sessionName)
+	return runState, nil
+}
 
-	c.JSON(http.StatusOK, gin.H{
-		"threadId":  threadID,
-		"runId":     runID,
-		"streamUrl": streamURL,
-		"status":    "started",
-	})
+// consumeRunStream persists and broadcasts the events of a runner's SSE stream until
+// it ends, then releases held-back output. It returns false when ctx was cancelled.
+func consumeRunStream(ctx context.Context, body io.Reader, sessionName, runID, threadID string, runState *AGUIRunState) bool {
+	runCtx := runState.logContext()
+	reader := agui.NewSSEReader(body)
+
+	for {
+		// Check if context was cancelled (timeout or cleanup)
+		select {
+		case <-ctx.Done():
+			logging.Infof(runCtx, "AGUI Proxy: Context cancelled for run %s", runID)
+			return false
+		default:
+		}
+
+		event, err := reader.Next()
+		if err != nil {
+			if err == io.EOF {
+				logging.Infof(runCtx, "AGUI Proxy: Background stream ended for run %s", runID)
+				break
+			}
+			logging.Errorf(runCtx, "AGUI Proxy: Background stream read error: %v", err)
+			break
+		}
+
+		// Persist and broadcast the AG-UI event
+		handleStreamedEvent(sessionName, runID, threadID, event.Data, runState)
+	}
+
+	flushStreamedEvents(sessionName, runID, threadID, runState)
+	return true
 }
 
-// handleStreamedEvent parses and persists a streamed AG-UI event
-func handleStreamedEvent(sessionID, runID, threadID, jsonData string, runState *AGUIRunState) {
-	var event map[string]interface{}
-	if err := json.Unmarshal([]byte(jsonData), &event); err != nil {
-		log.Printf("AGUI Proxy: Failed to parse event JSON: %v", err)
+// handleStreamedEvent decodes, persists and broadcasts a streamed AG-UI event
+func handleStreamedEvent(sessionID, runID, threadID string, data []byte, runState *AGUIRunState) {
+	event, err := types.DecodeEvent(data)
+	if err != nil {
+		logging.Errorf(runState.logContext(), "AGUI Proxy: Failed to decode event: %v", err)
 		return
 	}
 
-	eventType, _ := event["type"].(string)
+	// Ensure threadId, runId, and timestamp are set - critical for message timestamp tracking
+	event.FillBase(threadID, runID, time.Now().UTC().Format(types.AGUITimestampFormat))
+
+	// Events of a registered sub-agent carry its run ID; they are tracked on its run
+	// and tagged with its branch
+	targetRunID, target := runID, runState
+	child := subAgentRun(runState, event.Base().RunID)
+	if child != nil {
+		targetRunID, target = child.RunID, child
+	}
+	checkConformance(runState, target, event)
 
-	// Ensure threadId, runId, and timestamp are set
-	if _, ok := event["threadId"]; !ok {
-		event["threadId"] = threadID
+	events, findings := applyGuardrails(event, runState)
+	events = applyModeration(sessionID, targetRunID, threadID, events, runState)
+	for _, e := range events {
+		if child != nil {
+			tagBranch(e, child.BranchID)
+		}
+		if args, ok := e.Payload.(*types.ToolCallArgsEvent); ok {
+			checkToolCallEgress(sessionID, targetRunID, threadID, args, target)
+		}
+		processStreamedEvent(sessionID, targetRunID, threadID, e, target)
 	}
-	if _, ok := event["runId"]; !ok {
-		event["runId"] = runID
+	if len(findings) > 0 {
+		reportGuardrailFindings(sessionID, targetRunID, threadID, findings, runState)
 	}
-	// Add timestamp if not present - critical for message timestamp tracking
-	if _, ok := event["timestamp"]; !ok {
-		event["timestamp"] = time.Now().UTC().Format(types.AGUITimestampFormat)
+	if child == nil && runState.echoUserMessages && event.Type() == types.EventTypeRunStarted {
+		echoUserMessages(sessionID, runID, threadID, runState)
 	}
+}
 
-	// Check for terminal events
-	switch eventType {
-	case types.EventTypeRunFinished:
+// flushStreamedEvents releases output still held back for guardrails or moderation
+// when a stream ends
+func flushStreamedEvents(sessionID, runID, threadID string, runState *AGUIRunState) {
+	if runState == nil {
+		return
+	}
+	var events []*types.Event
+	var findings []guardrailFinding
+	if runState.guardrails != nil {
+		events, findings = flushHeldText(runState)
+	}
+	events = applyModeration(sessionID, runID, threadID, events, runState)
+	if runState.moderator != nil {
+		events = append(events, releaseModeratedMessages(sessionID, runID, threadID, runState)...)
+	}
+	for _, e := range events {
+		processStreamedEvent(sessionID, runID, threadID, e, runState)
+	}
+	if len(findings) > 0 {
+		reportGuardrailFindings(sessionID, runID, threadID, findings, runState)
+	}
+}
+
+// processStreamedEvent persists and broadcasts a streamed event that passed guardrails
+func processStreamedEvent(sessionID, runID, threadID string, event *types.Event, runState *AGUIRunState) {
+	// Check for terminal events. The first one decides the run's status; a runner
+	// sending another after it is out of spec, and that event is only persisted.
+	payload := event.Payload
+	if isTerminalEventType(event.Type()) && runEnded(runID) {
+		payload = nil
+	}
+	switch e := payload.(type) {
+	case *types.RunFinishedEvent:
 		updateRunStatus(runID, "completed")
-	case types.EventTypeRunError:
+	case *types.RunErrorEvent:
+		message := e.Message
+		if message == "" {
+			message = e.Error
+		}
+		aguiRunsMu.Lock()
+		if state, exists := aguiRuns[runID]; exists {
+			state.errorMessage = message
+		}
+		aguiRunsMu.Unlock()
+		planModelFallback(sessionID, runID, threadID, e, runState)
 		updateRunStatus(runID, "error")
+	case *types.BaseEvent:
+		// A RUN_ERROR whose fields didn't fit RunErrorEvent still ends the run
+		if e.Type == types.EventTypeRunError {
+			updateRunStatus(runID, "error")
+		}
 	}
 
 	// Persist event
-	persistAGUIEventMap(sessionID, runID, event)
+	persistAGUIEvent(sessionID, runID, event)
 
 	// Broadcast to subscribers (for SSE /events endpoint)
 	if runState != nil {
 		runState.BroadcastFull(event)
+		handlers.RecordSessionActivity(runState.ProjectName, sessionID)
 	}
 
 	// Also broadcast to thread subscribers
 	broadcastToThread(sessionID, event)
+
+	trackToolUsage(event.Payload, runState)
+	trackTokenUsage(event.Payload, runState)
+	if start, ok := event.Payload.(*types.ToolCallStartEvent); ok {
+		checkToolCallPolicy(sessionID, runID, threadID, start, runState)
+	}
+}
+
+// runEnded reports whether a tracked run already completed or failed
+func runEnded(runID string) bool {
+	aguiRunsMu.RLock()
+	defer aguiRunsMu.RUnlock()
+	state, ok := aguiRuns[runID]
+	return ok && (state.Status == "completed" || state.Status == "error")
 }
 
 // updateRunStatus updates the status of a run
 func updateRunStatus(runID, status string) {
 	aguiRunsMu.Lock()
-	if state, exists := aguiRuns[runID]; exists {
-		state.Status = status
-		// Update persisted metadata
-		go persistRunMetadata(state.SessionID, types.AGUIRunMetadata{
-			ThreadID:    state.ThreadID,
-			RunID:       state.RunID,
-			ParentRunID: state.ParentRunID,
-			SessionName: state.SessionID,
-			ProjectName: state.ProjectName,
-			StartedAt:   state.StartedAt.Format(time.RFC3339),
-			Status:      status,
+	state, exists := aguiRuns[runID]
+	if !exists {
+		aguiRunsMu.Unlock()
+		return
+	}
+	changed := state.Status != status
+	state.Status = status
+	meta := types.AGUIRunMetadata{
+		ThreadID:        state.ThreadID,
+		RunID:           state.RunID,
+		ParentRunID:     state.ParentRunID,
+		BranchID:        state.BranchID,
+		AgentName:       state.AgentName,
+		SessionName:     state.SessionID,
+		ProjectName:     state.ProjectName,
+		StartedAt:       state.StartedAt.Format(time.RFC3339),
+		Status:          status,
+		ProtocolVersion: state.ProtocolVersion,
+		RunnerVersion:   state.RunnerVersion,
+		Priority:        state.input.Priority,
+	}
+	project, session, errorMessage := state.ProjectName, state.SessionID, state.errorMessage
+	retrying := state.fallback != nil
+	branch := state.BranchID != ""
+	// Submit after unlocking: a full persistence queue blocks, and its tasks take aguiRunsMu
+	aguiRunsMu.Unlock()
+
+	// Update persisted metadata
+	persistPool.Submit(session, func() { persistRunMetadata(session, meta) })
+	// A run that stopped streaming frees a slot for the project's queued runs
+	if changed && !branch && status != "running" {
+		go dispatchQueuedRuns(project)
+	}
+	if changed && !branch && status == "completed" {
+		persistPool.Submit(session, func() { summarizeRun(project, session, runID) })
+	}
+	// Reflect terminal states on linked PRs, triggering incidents and in project notifications
+	// A failed attempt that falls back to another model isn't reported; its retry is.
+	// Sub-agent runs are summarized and reported through their parent
+	if changed && !retrying && !branch && (status == "completed" || status == "error" || status == "interrupted") {
+		backgroundPool.Submit(session, func() {
+			handlers.ReportRunCheck(project, session, status)
+			handlers.ReportIncidentNote(project, session, runID, status)
+			handlers.NotifyRunStatus(project, session, runID, status, errorMessage)
 		})
+		// Queued behind the run's events, from which the stage's artifact is read
+		persistPool.Submit(session, func() { completeWorkflowStage(project, session, runID, status) })
 	}
-	aguiRunsMu.Unlock()
 }
 
 // HandleAGUIInterrupt sends interrupt signal to runner to stop current execution
@@ -331,36 +572,8 @@ func HandleAGUIInterrupt(c *gin.Context) {
 	projectName := c.Param("projectName")
 	sessionName := c.Param("sessionName")
 
-	// SECURITY: Authenticate user and get user-scoped K8s client
-	reqK8s, _ := handlers.GetK8sClientsForRequest(c)
-	if reqK8s == nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing token"})
-		c.Abort()
-		return
-	}
-
-	// SECURITY: Verify user has permission to update this session
-	ctx := context.Background()
-	ssar := &authv1.SelfSubjectAccessReview{
-		Spec: authv1.SelfSubjectAccessReviewSpec{
-			ResourceAttributes: &authv1.ResourceAttributes{
-				Group:     "vteam.ambient-code",
-				Resource:  "agenticsessions",
-				Verb:      "update",
-				Namespace: projectName,
-				Name:      sessionName,
-			},
-		},
-	}
-	res, err := reqK8s.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, ssar, metav1.CreateOptions{})
-	if err != nil || !res.Status.Allowed {
-		log.Printf("AGUI Interrupt: User not authorized to update session %s/%s", projectName, sessionName)
-		c.JSON(http.StatusForbidden, gin.H{"error": "Unauthorized"})
-		c.Abort()
-		return
-	}
-
-	log.Printf("AGUI Interrupt: Request for %s/%s", projectName, sessionName)
+	logging.Infof(c, "AGUI Interrupt: Request for %s/%s", projectName, sessionName)
+	handlers.RecordSessionActivity(projectName, sessionName)
 
 	var input struct {
 		RunID string `json:"runId"`
@@ -370,43 +583,50 @@ func HandleAGUIInterrupt(c *gin.Context) {
 		return
 	}
 
+	// A queued run never reached the runner; it is just dropped from the queue
+	if input.RunID != "" && cancelQueuedRun(projectName, sessionName, input.RunID) {
+		logging.Infof(c, "AGUI Interrupt: Cancelled queued run %s", input.RunID)
+		c.JSON(http.StatusOK, gin.H{"message": "Queued run cancelled"})
+		return
+	}
+
 	// Get runner endpoint
 	runnerURL, err := getRunnerEndpoint(projectName, sessionName)
 	if err != nil {
-		log.Printf("AGUI Interrupt: Failed to get runner endpoint: %v", err)
+		logging.Errorf(c, "AGUI Interrupt: Failed to get runner endpoint: %v", err)
 		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Runner not available"})
 		return
 	}
 
 	interruptURL := strings.TrimSuffix(runnerURL, "/") + "/interrupt"
-	log.Printf("AGUI Interrupt: Forwarding to runner: %s", interruptURL)
+	logging.Infof(c, "AGUI Interrupt: Forwarding to runner: %s", interruptURL)
 
 	// POST to runner's interrupt endpoint
-	req, err := http.NewRequest("POST", interruptURL, bytes.NewReader([]byte("{}")))
+	req, err := http.NewRequestWithContext(c.Request.Context(), "POST", interruptURL, bytes.NewReader([]byte("{}")))
 	if err != nil {
-		log.Printf("AGUI Interrupt: Failed to create request: %v", err)
+		logging.Errorf(c, "AGUI Interrupt: Failed to create request: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Do(req)
+	logging.SetRequestIDHeader(req)
+	resp, err := runnerClient(config.Current().RunnerRequestTimeout.Duration).Do(req)
 	if err != nil {
-		log.Printf("AGUI Interrupt: Request failed: %v", err)
+		logging.Errorf(c, "AGUI Interrupt: Request failed: %v", err)
 		c.JSON(http.StatusBadGateway, gin.H{"error": err.Error()})
 		return
 	}
-	defer resp.Body.Close()
+	defer closeRunnerResponse(resp)
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		log.Printf("AGUI Interrupt: Runner returned %d: %s", resp.StatusCode, string(body))
+		logging.Warnf(c, "AGUI Interrupt: Runner returned %d: %s", resp.StatusCode, string(body))
 		c.JSON(resp.StatusCode, gin.H{"error": string(body)})
 		return
 	}
 
-	log.Printf("AGUI Interrupt: Successfully interrupted run %s", input.RunID)
+	logging.Infof(c, "AGUI Interrupt: Successfully interrupted run %s", input.RunID)
 	c.JSON(http.StatusOK, gin.H{"message": "Interrupt signal sent"})
 }
 
@@ -416,67 +636,38 @@ func HandleMCPStatus(c *gin.Context) {
 	projectName := c.Param("projectName")
 	sessionName := c.Param("sessionName")
 
-	// SECURITY: Authenticate user and get user-scoped K8s client
-	reqK8s, _ := handlers.GetK8sClientsForRequest(c)
-	if reqK8s == nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing token"})
-		c.Abort()
-		return
-	}
-
-	// SECURITY: Verify user has permission to read this session
-	ctx := context.Background()
-	ssar := &authv1.SelfSubjectAccessReview{
-		Spec: authv1.SelfSubjectAccessReviewSpec{
-			ResourceAttributes: &authv1.ResourceAttributes{
-				Group:     "vteam.ambient-code",
-				Resource:  "agenticsessions",
-				Verb:      "get",
-				Namespace: projectName,
-				Name:      sessionName,
-			},
-		},
-	}
-	res, err := reqK8s.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, ssar, metav1.CreateOptions{})
-	if err != nil || !res.Status.Allowed {
-		log.Printf("MCP Status: User not authorized to read session %s/%s", projectName, sessionName)
-		c.JSON(http.StatusForbidden, gin.H{"error": "Unauthorized"})
-		c.Abort()
-		return
-	}
-
 	// Get runner endpoint
 	runnerURL, err := getRunnerEndpoint(projectName, sessionName)
 	if err != nil {
-		log.Printf("MCP Status: Failed to get runner endpoint: %v", err)
+		logging.Errorf(c, "MCP Status: Failed to get runner endpoint: %v", err)
 		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Runner not available"})
 		return
 	}
 
 	mcpStatusURL := strings.TrimSuffix(runnerURL, "/") + "/mcp/status"
-	log.Printf("MCP Status: Forwarding to runner: %s", mcpStatusURL)
+	logging.Infof(c, "MCP Status: Forwarding to runner: %s", mcpStatusURL)
 
 	// GET from runner's MCP status endpoint
-	req, err := http.NewRequest("GET", mcpStatusURL, nil)
+	req, err := http.NewRequestWithContext(c.Request.Context(), "GET", mcpStatusURL, nil)
 	if err != nil {
-		log.Printf("MCP Status: Failed to create request: %v", err)
+		logging.Errorf(c, "MCP Status: Failed to create request: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Do(req)
+	logging.SetRequestIDHeader(req)
+	resp, err := runnerClient(config.Current().RunnerRequestTimeout.Duration).Do(req)
 	if err != nil {
-		log.Printf("MCP Status: Request failed: %v", err)
+		logging.Errorf(c, "MCP Status: Request failed: %v", err)
 		// Runner might not be running yet - return empty list
 		c.JSON(http.StatusOK, gin.H{"servers": []interface{}{}, "totalCount": 0})
 		return
 	}
-	defer resp.Body.Close()
+	defer closeRunnerResponse(resp)
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		log.Printf("MCP Status: Runner returned %d: %s", resp.StatusCode, string(body))
+		logging.Warnf(c, "MCP Status: Runner returned %d: %s", resp.StatusCode, string(body))
 		c.JSON(http.StatusOK, gin.H{"servers": []interface{}{}, "totalCount": 0})
 		return
 	}
@@ -484,10 +675,11 @@ func HandleMCPStatus(c *gin.Context) {
 	// Forward runner response to client
 	var result map[string]interface{}
 	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		log.Printf("MCP Status: Failed to decode response: %v", err)
+		logging.Errorf(c, "MCP Status: Failed to decode response: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse runner response"})
 		return
 	}
+	recordMCPStatus(projectName, sessionName, result)
 
 	c.JSON(http.StatusOK, result)
 }
@@ -495,23 +687,20 @@ func HandleMCPStatus(c *gin.Context) {
 // getRunnerEndpoint returns the AG-UI server endpoint for a session
 // The operator creates a Service named "session-{sessionName}" in the project namespace
 func getRunnerEndpoint(projectName, sessionName string) (string, error) {
-	// Use naming convention for service discovery
-	// Format: http://session-{sessionName}.{projectName}.svc.cluster.local:8001/
-	// The operator creates this Service automatically when spawning the runner Job
-	return fmt.Sprintf("http://session-%s.%s.svc.cluster.local:8001/", sessionName, projectName), nil
+	// Format: http://session-{sessionName}.{projectName}.svc.cluster.local:{runnerPort}/
+	// for runners in this cluster, or the runner URL of the runner cluster the
+	// operator placed the session on
+	return handlers.RunnerEndpoint(context.Background(), projectName, sessionName)
 }
 
 // broadcastToThread sends event to all thread-level subscribers
 func broadcastToThread(sessionID string, event interface{}) {
+	// Hold the lock while sending so subscribers can't unsubscribe (and close their
+	// channel) mid-broadcast; sends never block
 	threadSubscribersMu.RLock()
-	subs, exists := threadSubscribers[sessionID]
-	threadSubscribersMu.RUnlock()
+	defer threadSubscribersMu.RUnlock()
 
-	if !exists {
-		return
-	}
-
-	for ch := range subs {
+	for ch := range threadSubscribers[sessionID] {
 		select {
 		case ch <- event:
 		default:
@@ -533,49 +722,46 @@ func triggerDisplayNameGenerationIfNeeded(projectName, sessionName string, messa
 	}
 
 	if userMessage == "" {
-		log.Printf("DisplayNameGen: No user message found in run request for %s/%s", projectName, sessionName)
+		logging.Infof(context.Background(), "DisplayNameGen: No user message found in run request for %s/%s", projectName, sessionName)
 		return
 	}
 
 	// Check if session already has a display name
 	if handlers.DynamicClient == nil {
-		log.Printf("DisplayNameGen: DynamicClient not initialized, skipping display name generation")
+		logging.Warnf(context.Background(), "DisplayNameGen: DynamicClient not initialized, skipping display name generation")
 		return
 	}
 
-	gvr := handlers.GetAgenticSessionV1Alpha1Resource()
-	ctx := context.Background()
-
-	item, err := handlers.DynamicClient.Resource(gvr).Namespace(projectName).Get(ctx, sessionName, metav1.GetOptions{})
+	item, err := handlers.GetCachedSession(context.Background(), projectName, sessionName)
 	if err != nil {
-		log.Printf("DisplayNameGen: Failed to get session %s/%s: %v", projectName, sessionName, err)
+		logging.Errorf(context.Background(), "DisplayNameGen: Failed to get session %s/%s: %v", projectName, sessionName, err)
 		return
 	}
 
 	// Extract spec using unstructured helpers (per CLAUDE.md guidelines)
 	spec, found, err := unstructured.NestedMap(item.Object, "spec")
 	if err != nil || !found {
-		log.Printf("DisplayNameGen: Failed to get spec for %s/%s", projectName, sessionName)
+		logging.Errorf(context.Background(), "DisplayNameGen: Failed to get spec for %s/%s", projectName, sessionName)
 		return
 	}
 
 	// Skip if this message is the auto-sent initialPrompt (not a real user message)
 	initialPrompt, _, _ := unstructured.NestedString(spec, "initialPrompt")
 	if initialPrompt != "" && strings.TrimSpace(userMessage) == strings.TrimSpace(initialPrompt) {
-		log.Printf("DisplayNameGen: Skipping auto-sent initialPrompt for %s/%s", projectName, sessionName)
+		logging.Warnf(context.Background(), "DisplayNameGen: Skipping auto-sent initialPrompt for %s/%s", projectName, sessionName)
 		return
 	}
 
 	// Check if display name generation is needed
 	if !handlers.ShouldGenerateDisplayName(spec) {
-		log.Printf("DisplayNameGen: Session %s/%s already has display name, skipping", projectName, sessionName)
+		logging.Warnf(context.Background(), "DisplayNameGen: Session %s/%s already has display name, skipping", projectName, sessionName)
 		return
 	}
 
 	// Extract session context for better name generation
 	sessionCtx := handlers.ExtractSessionContext(spec)
 
-	log.Printf("DisplayNameGen: Triggering async generation for %s/%s with message: %q",
+	logging.Infof(context.Background(), "DisplayNameGen: Triggering async generation for %s/%s with message: %q",
 		projectName, sessionName, truncateForLog(userMessage, 50))
 
 	// Trigger async generation (runs in background, fails silently)
@@ -599,128 +785,98 @@ func HandleAGUIFeedback(c *gin.Context) {
 	projectName := handlers.SanitizeForLog(c.Param("projectName"))
 	sessionName := handlers.SanitizeForLog(c.Param("sessionName"))
 
-	// SECURITY: Authenticate user and get user-scoped K8s client
-	reqK8s, _ := handlers.GetK8sClientsForRequest(c)
-	if reqK8s == nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing token"})
-		c.Abort()
-		return
-	}
-
-	// SECURITY: Verify user has permission to update this session
-	ctx := context.Background()
-	ssar := &authv1.SelfSubjectAccessReview{
-		Spec: authv1.SelfSubjectAccessReviewSpec{
-			ResourceAttributes: &authv1.ResourceAttributes{
-				Group:     "vteam.ambient-code",
-				Resource:  "agenticsessions",
-				Verb:      "update",
-				Namespace: projectName,
-				Name:      sessionName,
-			},
-		},
-	}
-	res, err := reqK8s.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, ssar, metav1.CreateOptions{})
-	if err != nil || !res.Status.Allowed {
-		log.Printf("AGUI Feedback: User not authorized to update session %s/%s", projectName, sessionName)
-		c.JSON(http.StatusForbidden, gin.H{"error": "Unauthorized"})
-		c.Abort()
-		return
-	}
-
 	// Parse AG-UI META event from frontend
 	// Frontend constructs the full event, we just validate and forward
-	var metaEvent map[string]interface{}
-	if err := c.ShouldBindJSON(&metaEvent); err != nil {
-		log.Printf("AGUI Feedback: Failed to parse META event: %v", err)
+	body, err := c.GetRawData()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "failed to read request body"})
+		return
+	}
+	event, err := types.DecodeEvent(body)
+	if err != nil {
+		logging.Errorf(c, "AGUI Feedback: Failed to parse META event: %v", err)
 		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid META event: %v", err)})
 		return
 	}
 
 	// Validate it's a META event
-	eventType, ok := metaEvent["type"].(string)
-	if !ok || eventType != types.EventTypeMeta {
-		log.Printf("AGUI Feedback: Invalid event type: %v", eventType)
+	if event.Type() != types.EventTypeMeta {
+		logging.Errorf(c, "AGUI Feedback: Invalid event type: %s", handlers.SanitizeForLog(event.Type()))
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Expected META event type"})
 		return
 	}
+	metaEvent, ok := event.Payload.(*types.MetaEvent)
+	if !ok {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid META event: payload must be an object"})
+		return
+	}
 
 	// Extract metaType for logging
-	metaType, _ := metaEvent["metaType"].(string)
-	username := handlers.SanitizeForLog(c.GetHeader("X-Forwarded-User"))
-	log.Printf("AGUI Feedback: Received %s feedback from %s for session %s/%s",
+	metaType := metaEvent.MetaType
+	username := handlers.SanitizeForLog(c.GetString("userID"))
+	logging.Infof(c, "AGUI Feedback: Received %s feedback from %s for session %s/%s",
 		handlers.SanitizeForLog(metaType), username, projectName, sessionName)
 
+	// Feedback on a workflow stage approves or rejects it, whether or not the runner
+	// is still up to receive it
+	reviewWorkflowStage(c, c.Param("projectName"), c.Param("sessionName"), metaEvent)
+
 	// Get runner endpoint
 	runnerURL, err := getRunnerEndpoint(projectName, sessionName)
 	if err != nil {
-		log.Printf("AGUI Feedback: Failed to get runner endpoint: %v", err)
+		logging.Errorf(c, "AGUI Feedback: Failed to get runner endpoint: %v", err)
 		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Runner not available"})
 		return
 	}
 
-	// Serialize event for POST to runner (forward as-is)
-	bodyBytes, err := json.Marshal(metaEvent)
-	if err != nil {
-		log.Printf("AGUI Feedback: Failed to serialize META event: %v", err)
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to serialize event"})
-		return
-	}
-
 	// POST to runner's feedback endpoint
 	feedbackURL := strings.TrimSuffix(runnerURL, "/") + "/feedback"
-	log.Printf("AGUI Feedback: Forwarding META event to runner: %s", feedbackURL)
+	logging.Infof(c, "AGUI Feedback: Forwarding META event to runner: %s", feedbackURL)
 
-	req, err := http.NewRequest("POST", feedbackURL, bytes.NewReader(bodyBytes))
+	// Forward the event as-is
+	req, err := http.NewRequestWithContext(c.Request.Context(), "POST", feedbackURL, bytes.NewReader(event.Raw))
 	if err != nil {
-		log.Printf("AGUI Feedback: Failed to create request: %v", err)
+		logging.Errorf(c, "AGUI Feedback: Failed to create request: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Do(req)
+	logging.SetRequestIDHeader(req)
+	resp, err := runnerClient(config.Current().RunnerRequestTimeout.Duration).Do(req)
 	if err != nil {
 		// Runner might not be running - log but don't fail (feedback is best-effort)
-		log.Printf("AGUI Feedback: Request failed (runner may not be running): %v", err)
+		logging.Errorf(c, "AGUI Feedback: Request failed (runner may not be running): %v", err)
 		c.JSON(http.StatusAccepted, gin.H{
 			"message": "Feedback queued (runner not available)",
 			"status":  "pending",
 		})
 		return
 	}
-	defer resp.Body.Close()
+	defer closeRunnerResponse(resp)
 
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusAccepted {
 		body, _ := io.ReadAll(resp.Body)
-		log.Printf("AGUI Feedback: Runner returned %d: %s", resp.StatusCode, string(body))
+		logging.Warnf(c, "AGUI Feedback: Runner returned %d: %s", resp.StatusCode, string(body))
 		c.JSON(resp.StatusCode, gin.H{"error": string(body)})
 		return
 	}
 
-	log.Printf("AGUI Feedback: Successfully forwarded %s feedback to runner", handlers.SanitizeForLog(metaType))
+	logging.Infof(c, "AGUI Feedback: Successfully forwarded %s feedback to runner", handlers.SanitizeForLog(metaType))
 
 	// Broadcast the META event on the event stream so UI can see feedback submissions
 	// This allows the frontend to display "Feedback submitted" or track which traces have feedback
-	broadcastToThread(sessionName, metaEvent)
+	broadcastToThread(sessionName, event)
 
 	// CRITICAL: Persist the META event so it survives reconnects and session restarts
 	// Without this, feedback events are lost when clients disconnect
 	// Extract runId from event payload if present (feedback is associated with a specific run/message)
-	runID := ""
-	if payload, ok := metaEvent["payload"].(map[string]interface{}); ok {
-		if rid, ok := payload["runId"].(string); ok {
-			runID = rid
-		}
-	}
+	runID, _ := metaEvent.Payload["runId"].(string)
 	// Fallback: try top-level runId
 	if runID == "" {
-		if rid, ok := metaEvent["runId"].(string); ok {
-			runID = rid
-		}
+		runID = metaEvent.RunID
 	}
-	go persistAGUIEventMap(sessionName, runID, metaEvent)
+	persistPool.Submit(sessionName, func() { persistAGUIEvent(sessionName, runID, event) })
 
 	c.JSON(http.StatusOK, gin.H{
 		"message": "Feedback submitted successfully",
@@ -0,0 +1,98 @@
+package websocket
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestHandleAGUIRunsFieldSelection(t *testing.T) {
+	StateBaseDir = t.TempDir()
+	dir := StateBaseDir + "/sessions/s1"
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	events := strings.Join([]string{
+		`{"type":"RUN_STARTED","runId":"r1"}`,
+		`{"type":"STATE_DELTA","runId":"r1","delta":[{"op":"replace","path":"/lastResult","value":{"total_cost_usd":0.5,"usage":{"input_tokens":100,"output_tokens":20}}}]}`,
+		`{"type":"STATE_DELTA","runId":"r2","delta":[{"op":"replace","path":"/lastResult","value":{"usage":{"input_tokens":7}}}]}`,
+	}, "\n") + "\n"
+	runs := `{"runId":"r1","threadId":"s1","status":"completed"}` + "\n" + `{"runId":"r2","threadId":"s1","status":"error"}` + "\n"
+	if err := os.WriteFile(dir+"/agui-events.jsonl", []byte(events), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(dir+"/agui-runs.jsonl", []byte(runs), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	list := func(query string) *httptest.ResponseRecorder {
+		gin.SetMode(gin.TestMode)
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, "/?"+query, nil)
+		c.Params = gin.Params{{Key: "sessionName", Value: "s1"}}
+		HandleAGUIRuns(c)
+		return w
+	}
+
+	w := list("fields=runId,stats.inputTokens,stats.costUsd&expand=stats")
+	var resp struct {
+		Runs []map[string]interface{} `json:"runs"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil || w.Code != http.StatusOK {
+		t.Fatalf("runs = %d %s", w.Code, w.Body.String())
+	}
+	got, _ := json.Marshal(resp.Runs)
+	want := `[{"runId":"r1","stats":{"costUsd":0.5,"inputTokens":100}},{"runId":"r2","stats":{"costUsd":0,"inputTokens":7}}]`
+	if string(got) != want {
+		t.Errorf("runs = %s; want %s", got, want)
+	}
+
+	if w := list("expand=messages"); w.Code != http.StatusBadRequest {
+		t.Errorf("unknown expansion = %d", w.Code)
+	}
+}
+
+func TestHandleAGUIRunsETag(t *testing.T) {
+	StateBaseDir = t.TempDir()
+	dir := StateBaseDir + "/sessions/s1"
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	runs := dir + "/agui-runs.jsonl"
+	if err := os.WriteFile(runs, []byte(`{"runId":"r1","threadId":"s1","status":"running"}`+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	list := func(ifNoneMatch string) *httptest.ResponseRecorder {
+		gin.SetMode(gin.TestMode)
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+		c.Request.Header.Set("If-None-Match", ifNoneMatch)
+		c.Params = gin.Params{{Key: "sessionName", Value: "s1"}}
+		HandleAGUIRuns(c)
+		return w
+	}
+
+	w := list("")
+	etag := w.Header().Get("ETag")
+	if w.Code != http.StatusOK || etag == "" {
+		t.Fatalf("runs = %d, ETag %q", w.Code, etag)
+	}
+	if w := list(etag); w.Code != http.StatusNotModified || w.Body.Len() != 0 {
+		t.Errorf("unchanged runs = %d %s", w.Code, w.Body.String())
+	}
+
+	if err := os.WriteFile(runs, []byte(`{"runId":"r1","threadId":"s1","status":"completed"}`+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if w := list(etag); w.Code != http.StatusOK || w.Header().Get("ETag") == etag {
+		t.Errorf("changed runs = %d, ETag %q", w.Code, w.Header().Get("ETag"))
+	}
+}
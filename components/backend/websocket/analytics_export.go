@@ -0,0 +1,167 @@
+package websocket
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	"ambient-code-backend/handlers"
+	"ambient-code-backend/logging"
+	"ambient-code-backend/types"
+
+	"github.com/gin-gonic/gin"
+	authv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// AnalyticsEvent is an AG-UI event with its content removed: message text, tool
+// arguments and results, state and raw payloads are reduced to their size
+type AnalyticsEvent struct {
+	Type      string `json:"type"`
+	Timestamp string `json:"timestamp,omitempty"`
+	Role      string `json:"role,omitempty"`
+	Tool      string `json:"tool,omitempty"`
+	Bytes     int    `json:"bytes,omitempty"`
+}
+
+// AnalyticsRun is one line of the pseudonymized analytics export. Session, user and
+// run IDs are pseudonyms; usernames, emails, repo URLs and conversation content are
+// not included.
+type AnalyticsRun struct {
+	Session string           `json:"session"`
+	User    string           `json:"user,omitempty"`
+	Model   string           `json:"model,omitempty"`
+	Stats   RunStats         `json:"stats"`
+	Events  []AnalyticsEvent `json:"events"`
+}
+
+// analyticsEvent strips an event down to its type, timing, role, tool name and size
+func analyticsEvent(event map[string]interface{}) AnalyticsEvent {
+	out := AnalyticsEvent{}
+	out.Type, _ = event["type"].(string)
+	out.Timestamp, _ = event["timestamp"].(string)
+	switch out.Type {
+	case types.EventTypeTextMessageStart:
+		out.Role, _ = event["role"].(string)
+	case types.EventTypeTextMessageContent, types.EventTypeToolCallArgs:
+		delta, _ := event["delta"].(string)
+		out.Bytes = len(delta)
+	case types.EventTypeToolCallStart:
+		out.Tool, _ = event["toolCallName"].(string)
+	}
+	return out
+}
+
+// pseudonymizedRun builds the export line for one run
+func pseudonymizedRun(pseudonym func(kind, value string) string, session *unstructured.Unstructured, meta types.AGUIRunMetadata, events []map[string]interface{}) AnalyticsRun {
+	owner, _, _ := unstructured.NestedString(session.Object, "spec", "userContext", "userId")
+	model, _, _ := unstructured.NestedString(session.Object, "spec", "llmSettings", "model")
+
+	stats := runStats(meta, events)
+	stats.SessionName = pseudonym("s", session.GetName())
+	stats.RunID = pseudonym("r", meta.RunID)
+	for _, msg := range ThreadMessages(events) {
+		stats.Messages++
+		stats.ToolCalls += len(msg.ToolCalls)
+	}
+
+	run := AnalyticsRun{
+		Session: stats.SessionName,
+		User:    pseudonym("u", owner),
+		Model:   model,
+		Stats:   stats,
+		Events:  make([]AnalyticsEvent, 0, len(events)),
+	}
+	for _, event := range events {
+		run.Events = append(run.Events, analyticsEvent(event))
+	}
+	return run
+}
+
+// HandleAnalyticsExport downloads a project's runs of the last ?days= days (default
+// 30) as pseudonymized JSONL, one run per line, for usage analysis without access
+// to conversation content
+// GET /api/projects/:projectName/analytics/export?days=30
+func HandleAnalyticsExport(c *gin.Context) {
+	projectName := c.Param("projectName")
+
+	reqK8s, _ := handlers.GetK8sClientsForRequest(c)
+	if reqK8s == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing token"})
+		c.Abort()
+		return
+	}
+
+	// SECURITY: Verify user can list sessions in this project
+	allowed, err := handlers.CheckAccessForRequest(c, reqK8s, authv1.ResourceAttributes{
+		Group:     "vteam.ambient-code",
+		Resource:  "agenticsessions",
+		Verb:      "list",
+		Namespace: projectName,
+	})
+	if err != nil || !allowed {
+		logging.Warnf(c, "Analytics export: User not authorized to list sessions in %s", projectName)
+		c.JSON(http.StatusForbidden, gin.H{"error": "Unauthorized"})
+		c.Abort()
+		return
+	}
+	if handlers.DynamicClient == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Kubernetes client not initialized"})
+		return
+	}
+
+	days := 30
+	if v := c.Query("days"); v != "" {
+		if _, err := fmt.Sscanf(v, "%d", &days); err != nil || days < 1 || days > 365 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "days must be between 1 and 365"})
+			return
+		}
+	}
+	since := time.Now().Add(-time.Duration(days) * 24 * time.Hour)
+
+	pseudonym, err := handlers.AnalyticsPseudonymizer(c.Request.Context())
+	if err != nil {
+		logging.Errorf(c, "Analytics export: failed to load pseudonym key: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to prepare export"})
+		return
+	}
+
+	list, err := handlers.DynamicClient.Resource(handlers.GetAgenticSessionV1Alpha1Resource()).Namespace(projectName).List(c.Request.Context(), metav1.ListOptions{})
+	if err != nil {
+		logging.Errorf(c, "Analytics export: failed to list sessions in %s: %v", projectName, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list sessions"})
+		return
+	}
+	sort.Slice(list.Items, func(a, b int) bool { return list.Items[a].GetName() < list.Items[b].GetName() })
+
+	c.Header("Content-Type", "application/x-ndjson")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s-analytics.jsonl\"", projectName))
+	c.Status(http.StatusOK)
+	enc := json.NewEncoder(c.Writer)
+	exported := 0
+	for i := range list.Items {
+		session := &list.Items[i]
+		if !isValidSessionName(session.GetName()) {
+			continue
+		}
+		for _, meta := range getRunsForSession(session.GetName()) {
+			if started, err := time.Parse(time.RFC3339, meta.StartedAt); err != nil || started.Before(since) {
+				continue
+			}
+			events, err := loadEventsForRun(session.GetName(), meta.RunID)
+			if err != nil {
+				logging.Warnf(c, "Analytics export: failed to read events of %s: %v", session.GetName(), err)
+				continue
+			}
+			if err := enc.Encode(pseudonymizedRun(pseudonym, session, meta, events)); err != nil {
+				logging.Errorf(c, "Analytics export: failed to write run: %v", err)
+				return
+			}
+			exported++
+		}
+	}
+	logging.Infof(c, "Analytics export: exported %d runs of %s", exported, projectName)
+}
@@ -0,0 +1,273 @@
+package websocket
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"ambient-code-backend/handlers"
+	"ambient-code-backend/logging"
+
+	"github.com/gin-gonic/gin"
+)
+
+// EventAnnotation is a user's note on one message or tool call of a session, kept
+// next to the event log so exports can be turned into evaluation datasets
+type EventAnnotation struct {
+	ID        string   `json:"id"`
+	EventID   string   `json:"eventId"` // messageId or toolCallId of the annotated events
+	RunID     string   `json:"runId,omitempty"`
+	Rating    string   `json:"rating,omitempty"` // "up" or "down"
+	Comment   string   `json:"comment,omitempty"`
+	Tags      []string `json:"tags,omitempty"`
+	User      string   `json:"user,omitempty"`
+	CreatedAt string   `json:"createdAt"`
+}
+
+const (
+	maxAnnotationComment = 2000
+	maxAnnotationTags    = 10
+)
+
+var (
+	annotationTagPattern = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,39}$`)
+	annotationsFileMu    sync.Mutex
+)
+
+func annotationsPath(sessionID string) string {
+	return fmt.Sprintf("%s/sessions/%s/agui-annotations.jsonl", StateBaseDir, sessionID)
+}
+
+// loadAnnotations returns the session's annotations in the order they were added
+func loadAnnotations(sessionID string) ([]EventAnnotation, error) {
+	f, err := os.Open(annotationsPath(sessionID))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []EventAnnotation{}, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	annotations := []EventAnnotation{}
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var a EventAnnotation
+		if err := json.Unmarshal(scanner.Bytes(), &a); err != nil {
+			continue
+		}
+		annotations = append(annotations, a)
+	}
+	return annotations, scanner.Err()
+}
+
+// writeAnnotations replaces the session's annotation log
+func writeAnnotations(sessionID string, annotations []EventAnnotation) error {
+	var buf strings.Builder
+	for _, a := range annotations {
+		line, err := json.Marshal(a)
+		if err != nil {
+			return err
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+	path := annotationsPath(sessionID)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(buf.String()), 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// findAnnotatedRun returns the run whose events carry the given messageId or
+// toolCallId, and whether any event does
+func findAnnotatedRun(events []map[string]interface{}, eventID string) (string, bool) {
+	for _, event := range events {
+		if event["messageId"] == eventID || event["toolCallId"] == eventID {
+			runID, _ := event["runId"].(string)
+			return runID, true
+		}
+	}
+	return "", false
+}
+
+// validateAnnotation normalizes the annotation's tags and checks it says something
+func validateAnnotation(a *EventAnnotation) error {
+	if a.EventID == "" {
+		return fmt.Errorf("eventId is required")
+	}
+	if a.Rating != "" && a.Rating != "up" && a.Rating != "down" {
+		return fmt.Errorf("rating must be \"up\" or \"down\"")
+	}
+	if len(a.Comment) > maxAnnotationComment {
+		return fmt.Errorf("comment exceeds %d characters", maxAnnotationComment)
+	}
+	if len(a.Tags) > maxAnnotationTags {
+		return fmt.Errorf("at most %d tags are allowed", maxAnnotationTags)
+	}
+	seen := map[string]bool{}
+	tags := make([]string, 0, len(a.Tags))
+	for _, tag := range a.Tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if !annotationTagPattern.MatchString(tag) {
+			return fmt.Errorf("invalid tag %q: use lowercase letters, digits, '-' or '_'", tag)
+		}
+		if !seen[tag] {
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+	}
+	a.Tags = tags
+	if a.Rating == "" && strings.TrimSpace(a.Comment) == "" && len(a.Tags) == 0 {
+		return fmt.Errorf("annotation needs a rating, comment or tag")
+	}
+	return nil
+}
+
+// HandleAddAnnotation handles POST /api/projects/:projectName/agentic-sessions/:sessionName/agui/annotations
+// Attaches a rating, comment and/or tags to a message or tool call of the session
+func HandleAddAnnotation(c *gin.Context) {
+	sessionName := c.Param("sessionName")
+	if !isValidSessionName(sessionName) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session name"})
+		return
+	}
+
+	var req struct {
+		EventID string   `json:"eventId" binding:"required"`
+		Rating  string   `json:"rating"`
+		Comment string   `json:"comment"`
+		Tags    []string `json:"tags"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	annotation := EventAnnotation{
+		ID:        generateEventID(),
+		EventID:   req.EventID,
+		Rating:    req.Rating,
+		Comment:   req.Comment,
+		Tags:      req.Tags,
+		User:      c.GetString("userID"),
+		CreatedAt: time.Now().UTC().Format(time.RFC3339),
+	}
+	if err := validateAnnotation(&annotation); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	events, err := loadEventsForRun(sessionName, "")
+	if err != nil {
+		logging.Errorf(c, "Annotations: Failed to load events for %s: %v", sessionName, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load session events"})
+		return
+	}
+	runID, ok := findAnnotatedRun(events, annotation.EventID)
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "No message or tool call with that eventId in this session"})
+		return
+	}
+	annotation.RunID = runID
+
+	line, err := json.Marshal(annotation)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to encode annotation"})
+		return
+	}
+	annotationsFileMu.Lock()
+	defer annotationsFileMu.Unlock()
+	f, err := openFileAppend(annotationsPath(sessionName))
+	if err != nil {
+		logging.Errorf(c, "Annotations: Failed to open annotation log for %s: %v", sessionName, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save annotation"})
+		return
+	}
+	defer f.Close()
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		logging.Errorf(c, "Annotations: Failed to write annotation for %s: %v", sessionName, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save annotation"})
+		return
+	}
+
+	logging.Infof(c, "Annotations: %s annotated %s in session %s",
+		handlers.SanitizeForLog(annotation.User), handlers.SanitizeForLog(annotation.EventID), sessionName)
+	c.JSON(http.StatusCreated, annotation)
+}
+
+// HandleListAnnotations handles GET /api/projects/:projectName/agentic-sessions/:sessionName/agui/annotations
+// Lists the session's annotations, optionally only those on ?eventId=
+func HandleListAnnotations(c *gin.Context) {
+	sessionName := c.Param("sessionName")
+	if !isValidSessionName(sessionName) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session name"})
+		return
+	}
+
+	annotations, err := loadAnnotations(sessionName)
+	if err != nil {
+		logging.Errorf(c, "Annotations: Failed to load annotations for %s: %v", sessionName, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load annotations"})
+		return
+	}
+	if eventID := c.Query("eventId"); eventID != "" {
+		filtered := []EventAnnotation{}
+		for _, a := range annotations {
+			if a.EventID == eventID {
+				filtered = append(filtered, a)
+			}
+		}
+		annotations = filtered
+	}
+
+	c.JSON(http.StatusOK, gin.H{"annotations": annotations})
+}
+
+// HandleDeleteAnnotation handles DELETE /api/projects/:projectName/agentic-sessions/:sessionName/agui/annotations/:annotationId
+// Only the user who added an annotation can remove it
+func HandleDeleteAnnotation(c *gin.Context) {
+	sessionName := c.Param("sessionName")
+	if !isValidSessionName(sessionName) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session name"})
+		return
+	}
+	annotationID := c.Param("annotationId")
+
+	annotationsFileMu.Lock()
+	defer annotationsFileMu.Unlock()
+	annotations, err := loadAnnotations(sessionName)
+	if err != nil {
+		logging.Errorf(c, "Annotations: Failed to load annotations for %s: %v", sessionName, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load annotations"})
+		return
+	}
+	for i, a := range annotations {
+		if a.ID != annotationID {
+			continue
+		}
+		// Annotations saved without an authenticated author belong to no one
+		if user := c.GetString("userID"); user == "" || a.User != user {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Only the author can delete an annotation"})
+			return
+		}
+		if err := writeAnnotations(sessionName, append(annotations[:i], annotations[i+1:]...)); err != nil {
+			logging.Errorf(c, "Annotations: Failed to rewrite annotations for %s: %v", sessionName, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete annotation"})
+			return
+		}
+		c.Status(http.StatusNoContent)
+		return
+	}
+	c.JSON(http.StatusNotFound, gin.H{"error": "Annotation not found"})
+}
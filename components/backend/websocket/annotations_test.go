@@ -0,0 +1,58 @@
+package websocket
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestAnnotationAuthorship(t *testing.T) {
+	StateBaseDir = t.TempDir()
+	dir := StateBaseDir + "/sessions/s1"
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(dir+"/agui-events.jsonl", []byte(`{"type":"TEXT_MESSAGE_START","runId":"r1","messageId":"m1"}`+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	call := func(handler gin.HandlerFunc, method, body, userID, annotationID string) *httptest.ResponseRecorder {
+		gin.SetMode(gin.TestMode)
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(method, "/", strings.NewReader(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		// Identity comes from authentication, never from a client-supplied header
+		c.Request.Header.Set("X-Forwarded-User", "alice")
+		if userID != "" {
+			c.Set("userID", userID)
+		}
+		c.Params = gin.Params{{Key: "sessionName", Value: "s1"}, {Key: "annotationId", Value: annotationID}}
+		handler(c)
+		c.Writer.WriteHeaderNow()
+		return w
+	}
+
+	w := call(HandleAddAnnotation, http.MethodPost, `{"eventId":"m1","rating":"up"}`, "alice", "")
+	var created EventAnnotation
+	if err := json.Unmarshal(w.Body.Bytes(), &created); err != nil || w.Code != http.StatusCreated {
+		t.Fatalf("add = %d %s", w.Code, w.Body.String())
+	}
+	if created.User != "alice" {
+		t.Errorf("author = %q; want alice", created.User)
+	}
+
+	for _, user := range []string{"", "bob"} {
+		if w := call(HandleDeleteAnnotation, http.MethodDelete, "", user, created.ID); w.Code != http.StatusForbidden {
+			t.Errorf("delete by %q = %d; want 403", user, w.Code)
+		}
+	}
+	if w := call(HandleDeleteAnnotation, http.MethodDelete, "", "alice", created.ID); w.Code != http.StatusNoContent {
+		t.Errorf("delete by author = %d", w.Code)
+	}
+}
@@ -0,0 +1,226 @@
+package websocket
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+
+	"ambient-code-backend/config"
+	"ambient-code-backend/logging"
+	"ambient-code-backend/types"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Files attached to run messages are either uploaded to the backend, which keeps them
+// with the session's state, or referenced as session workspace files. Before a run
+// reaches the runner, uploaded files are inlined as base64 and workspace files become
+// file:// URLs under the runner's workspace.
+const (
+	maxAttachmentsPerMessage = 10
+	// runnerWorkspaceDir is where runners mount the session workspace
+	runnerWorkspaceDir = "/workspace"
+)
+
+// attachmentMeta is stored next to an uploaded attachment's content
+type attachmentMeta struct {
+	ID         string `json:"id"`
+	Filename   string `json:"filename"`
+	MimeType   string `json:"mimeType"`
+	Size       int64  `json:"size"`
+	UploadedBy string `json:"uploadedBy,omitempty"`
+	UploadedAt string `json:"uploadedAt"`
+}
+
+func attachmentDir(sessionName string) string {
+	return filepath.Join(StateBaseDir, "sessions", sessionName, "attachments")
+}
+
+// loadAttachment reads an uploaded attachment; a missing one returns os.ErrNotExist
+func loadAttachment(sessionName, id string) (*attachmentMeta, []byte, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, nil, os.ErrNotExist
+	}
+	raw, err := os.ReadFile(filepath.Join(attachmentDir(sessionName), id+".json"))
+	if err != nil {
+		return nil, nil, err
+	}
+	var meta attachmentMeta
+	if err := json.Unmarshal(raw, &meta); err != nil {
+		return nil, nil, err
+	}
+	data, err := os.ReadFile(filepath.Join(attachmentDir(sessionName), id))
+	if err != nil {
+		return nil, nil, err
+	}
+	return &meta, data, nil
+}
+
+// saveAttachment stores an uploaded file and returns its metadata
+func saveAttachment(sessionName, filename, mimeType, uploadedBy string, data []byte) (*attachmentMeta, error) {
+	dir := attachmentDir(sessionName)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	if mimeType == "" || mimeType == "application/octet-stream" {
+		mimeType = http.DetectContentType(data)
+	}
+	meta := &attachmentMeta{
+		ID:         uuid.New().String(),
+		Filename:   filepath.Base(filename),
+		MimeType:   mimeType,
+		Size:       int64(len(data)),
+		UploadedBy: uploadedBy,
+		UploadedAt: time.Now().UTC().Format(time.RFC3339),
+	}
+	if err := os.WriteFile(filepath.Join(dir, meta.ID), data, 0o644); err != nil {
+		return nil, err
+	}
+	raw, err := json.Marshal(meta)
+	if err != nil {
+		return nil, err
+	}
+	// Written last: an attachment without metadata doesn't exist
+	if err := os.WriteFile(filepath.Join(dir, meta.ID+".json"), raw, 0o644); err != nil {
+		return nil, err
+	}
+	return meta, nil
+}
+
+// resolveAttachments turns the attachment references of the run's messages into what
+// the runner reads: uploaded files as base64 Data, workspace files as file:// URLs.
+// Inline base64 Data sent by clients is checked against the size limit.
+func resolveAttachments(sessionName string, messages []types.Message) error {
+	maxBytes := config.Current().MaxAttachmentBytes
+	for i := range messages {
+		attachments := messages[i].Attachments
+		if len(attachments) == 0 {
+			continue
+		}
+		if maxBytes == 0 {
+			return fmt.Errorf("attachments are disabled")
+		}
+		if len(attachments) > maxAttachmentsPerMessage {
+			return fmt.Errorf("a message may have at most %d attachments", maxAttachmentsPerMessage)
+		}
+		for j := range attachments {
+			a := &attachments[j]
+			switch {
+			case a.ID != "":
+				meta, data, err := loadAttachment(sessionName, a.ID)
+				if err != nil {
+					return fmt.Errorf("attachment %q not found", a.ID)
+				}
+				a.Filename, a.MimeType, a.Size = meta.Filename, meta.MimeType, meta.Size
+				a.Data = base64.StdEncoding.EncodeToString(data)
+				a.Path, a.URL = "", ""
+			case a.Path != "":
+				// Cleaning against the root keeps the path inside the workspace
+				p := path.Clean("/" + a.Path)
+				if p == "/" {
+					return fmt.Errorf("attachment path %q is not a file", a.Path)
+				}
+				a.Path = p[1:]
+				a.URL = "file://" + runnerWorkspaceDir + p
+				if a.Filename == "" {
+					a.Filename = path.Base(p)
+				}
+				if a.MimeType == "" {
+					a.MimeType = mime.TypeByExtension(path.Ext(p))
+				}
+				a.Data = ""
+			case a.Data != "":
+				data, err := base64.StdEncoding.DecodeString(a.Data)
+				if err != nil {
+					return fmt.Errorf("attachment %q: data must be base64", a.Filename)
+				}
+				if len(data) > maxBytes {
+					return fmt.Errorf("attachment %q is larger than %d bytes", a.Filename, maxBytes)
+				}
+				if a.MimeType == "" {
+					a.MimeType = http.DetectContentType(data)
+				}
+				a.Size, a.URL = int64(len(data)), ""
+			default:
+				return fmt.Errorf("an attachment needs an id, a workspace path or data")
+			}
+		}
+	}
+	return nil
+}
+
+// HandleUploadAttachments handles POST /api/projects/:projectName/agentic-sessions/:sessionName/agui/attachments
+// Stores the multipart "file" parts for later runs to reference by ID.
+func HandleUploadAttachments(c *gin.Context) {
+	sessionName := c.Param("sessionName")
+	maxBytes := config.Current().MaxAttachmentBytes
+	if maxBytes == 0 {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Attachments are disabled"})
+		return
+	}
+	// Room for the largest allowed files plus the multipart framing
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, int64(maxBytes)*maxAttachmentsPerMessage+1<<20)
+	form, err := c.MultipartForm()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Expected a multipart upload within the size limit"})
+		return
+	}
+	files := form.File["file"]
+	if len(files) == 0 || len(files) > maxAttachmentsPerMessage {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Upload 1 to %d files as \"file\" parts", maxAttachmentsPerMessage)})
+		return
+	}
+
+	uploaded := make([]types.Attachment, 0, len(files))
+	for _, file := range files {
+		if file.Size > int64(maxBytes) {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("%s is larger than %d bytes", file.Filename, maxBytes)})
+			return
+		}
+		f, err := file.Open()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read upload"})
+			return
+		}
+		data, err := io.ReadAll(io.LimitReader(f, int64(maxBytes)+1))
+		f.Close()
+		if err != nil || len(data) > maxBytes {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read upload"})
+			return
+		}
+		meta, err := saveAttachment(sessionName, file.Filename, file.Header.Get("Content-Type"), c.GetString("userID"), data)
+		if err != nil {
+			logging.Errorf(c, "Attachments: failed to store %s for %s: %v", file.Filename, sessionName, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store attachment"})
+			return
+		}
+		uploaded = append(uploaded, types.Attachment{ID: meta.ID, Filename: meta.Filename, MimeType: meta.MimeType, Size: meta.Size})
+	}
+	logging.Infof(c, "Attachments: stored %d files for %s", len(uploaded), sessionName)
+	c.JSON(http.StatusCreated, gin.H{"attachments": uploaded})
+}
+
+// HandleGetAttachment handles GET /api/projects/:projectName/agentic-sessions/:sessionName/agui/attachments/:attachmentId
+func HandleGetAttachment(c *gin.Context) {
+	meta, data, err := loadAttachment(c.Param("sessionName"), c.Param("attachmentId"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Attachment not found"})
+			return
+		}
+		logging.Errorf(c, "Attachments: failed to read %s: %v", c.Param("attachmentId"), err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read attachment"})
+		return
+	}
+	c.Header("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": meta.Filename}))
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Data(http.StatusOK, meta.MimeType, data)
+}
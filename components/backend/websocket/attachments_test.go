@@ -0,0 +1,88 @@
+package websocket
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"ambient-code-backend/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestAttachments(t *testing.T) {
+	StateBaseDir = t.TempDir()
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/sessions/:sessionName/attachments", HandleUploadAttachments)
+	router.GET("/sessions/:sessionName/attachments/:attachmentId", HandleGetAttachment)
+
+	png := []byte("\x89PNG\r\n\x1a\n screenshot")
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	part, _ := form.CreateFormFile("file", "../bug.png")
+	part.Write(png)
+	form.Close()
+	req := httptest.NewRequest(http.MethodPost, "/sessions/s1/attachments", &body)
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("upload = %d %s", w.Code, w.Body.String())
+	}
+	var uploaded struct {
+		Attachments []types.Attachment `json:"attachments"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &uploaded); err != nil || len(uploaded.Attachments) != 1 {
+		t.Fatalf("upload response = %s", w.Body.String())
+	}
+	stored := uploaded.Attachments[0]
+	if stored.Filename != "bug.png" || stored.MimeType != "image/png" || stored.Size != int64(len(png)) {
+		t.Errorf("stored = %+v", stored)
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/sessions/s1/attachments/"+stored.ID, nil))
+	if w.Code != http.StatusOK || !bytes.Equal(w.Body.Bytes(), png) {
+		t.Errorf("download = %d %q", w.Code, w.Body.String())
+	}
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/sessions/s2/attachments/"+stored.ID, nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("other session's download = %d", w.Code)
+	}
+
+	messages := []types.Message{{Role: types.RoleUser, Content: "fix this", Attachments: []types.Attachment{
+		{ID: stored.ID},
+		{Path: "../../etc/app.log"},
+		{Filename: "note.txt", Data: base64.StdEncoding.EncodeToString([]byte("hello"))},
+	}}}
+	if err := resolveAttachments("s1", messages); err != nil {
+		t.Fatalf("resolveAttachments: %v", err)
+	}
+	resolved := messages[0].Attachments
+	if resolved[0].Data != base64.StdEncoding.EncodeToString(png) || resolved[0].MimeType != "image/png" {
+		t.Errorf("upload resolved to %+v", resolved[0])
+	}
+	if resolved[1].URL != "file:///workspace/etc/app.log" || resolved[1].Filename != "app.log" {
+		t.Errorf("workspace file resolved to %+v", resolved[1])
+	}
+	if resolved[2].Size != 5 || resolved[2].MimeType == "" {
+		t.Errorf("inline data resolved to %+v", resolved[2])
+	}
+
+	for name, attachment := range map[string]types.Attachment{
+		"unknown upload": {ID: "6f1c1e4e-0000-4000-8000-000000000000"},
+		"empty":          {Filename: "x"},
+		"bad data":       {Data: "not base64!"},
+		"workspace root": {Path: "/"},
+	} {
+		if err := resolveAttachments("s1", []types.Message{{Attachments: []types.Attachment{attachment}}}); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
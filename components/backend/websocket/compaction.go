@@ -1,8 +1,9 @@
 package websocket
 
 import (
+	"ambient-code-backend/logging"
 	"ambient-code-backend/types"
-	"log"
+	"context"
 
 	"github.com/google/uuid"
 )
@@ -51,6 +52,12 @@ func (c *MessageCompactor) HandleEvent(event map[string]interface{}) {
 		c.handleToolCallArgs(event)
 	case types.EventTypeToolCallEnd:
 		c.handleToolCallEnd(event)
+	case types.EventTypeTextMessageChunk:
+		c.handleTextMessageChunk(event)
+	case types.EventTypeToolCallChunk:
+		c.handleToolCallChunk(event)
+	case types.EventTypeToolCallResult:
+		c.handleToolCallResult(event)
 	case types.EventTypeRaw:
 		c.handleRawEvent(event)
 	case types.EventTypeMessagesSnapshot:
@@ -63,14 +70,41 @@ func (c *MessageCompactor) HandleEvent(event map[string]interface{}) {
 		// State events - skip, don't affect message compaction
 	case types.EventTypeActivitySnapshot, types.EventTypeActivityDelta:
 		// Activity events - skip, don't affect message compaction
+	case types.EventTypeThinkingStart, types.EventTypeThinkingEnd, types.EventTypeThinkingTextMessageStart,
+		types.EventTypeThinkingTextMessageContent, types.EventTypeThinkingTextMessageEnd:
+		// Thinking events - skip, reasoning isn't part of the conversation
+	case types.EventTypeCustom, types.EventTypeMeta:
+		// Application events - skip, don't affect message compaction
 	default:
-		log.Printf("Compaction: WARNING - Unhandled event type: %s", eventType)
+		logging.Warnf(context.Background(), "Compaction: Unhandled event type: %s", eventType)
 	}
 }
 
 // GetMessages returns the compacted messages (excluding hidden ones)
 func (c *MessageCompactor) GetMessages() []types.Message {
-	// Flush any active message
+	c.flush()
+
+	// Filter out hidden messages (auto-sent initial/workflow prompts)
+	visibleMessages := make([]types.Message, 0, len(c.messages))
+	for _, msg := range c.messages {
+		if c.hiddenMessages[msg.ID] {
+			continue
+		}
+		visibleMessages = append(visibleMessages, msg)
+	}
+
+	return visibleMessages
+}
+
+// GetAllMessages returns the compacted messages including hidden ones, i.e. everything
+// the agent saw
+func (c *MessageCompactor) GetAllMessages() []types.Message {
+	c.flush()
+	return c.messages
+}
+
+// flush closes the open message and drops in-progress tool calls
+func (c *MessageCompactor) flush() {
 	if c.currentMessage != nil {
 		c.messages = append(c.messages, *c.currentMessage)
 		c.currentMessage = nil
@@ -86,19 +120,6 @@ func (c *MessageCompactor) GetMessages() []types.Message {
 		// Clear activeToolCalls - don't include them in snapshot
 		c.activeToolCalls = make(map[string]*ActiveToolCall)
 	}
-
-	// Filter out hidden messages (auto-sent initial/workflow prompts)
-	visibleMessages := make([]types.Message, 0, len(c.messages))
-	hiddenCount := 0
-	for _, msg := range c.messages {
-		if c.hiddenMessages[msg.ID] {
-			hiddenCount++
-			continue
-		}
-		visibleMessages = append(visibleMessages, msg)
-	}
-
-	return visibleMessages
 }
 
 // Event Handlers
@@ -257,6 +278,69 @@ func (c *MessageCompactor) handleToolCallEnd(event map[string]interface{}) {
 	delete(c.activeToolCalls, toolID)
 }
 
+func (c *MessageCompactor) handleTextMessageChunk(event map[string]interface{}) {
+	// A chunk with a new message ID starts a message; later chunks continue it
+	messageID, _ := event["messageId"].(string)
+	if c.currentMessage == nil || (messageID != "" && messageID != c.currentMessage.ID) {
+		c.handleTextMessageStart(event)
+	}
+	c.handleTextMessageContent(event)
+}
+
+func (c *MessageCompactor) handleToolCallChunk(event map[string]interface{}) {
+	// A chunk with a new tool call ID starts the call; its result completes it
+	toolID, _ := event["toolCallId"].(string)
+	if toolID == "" {
+		return
+	}
+	if _, ok := c.activeToolCalls[toolID]; !ok {
+		c.handleToolCallStart(event)
+	}
+	c.handleToolCallArgs(event)
+}
+
+func (c *MessageCompactor) handleToolCallResult(event map[string]interface{}) {
+	toolID, _ := event["toolCallId"].(string)
+	if toolID == "" {
+		toolID, _ = event["tool_call_id"].(string)
+	}
+	content, _ := event["content"].(string)
+	if toolID == "" {
+		return
+	}
+
+	// A call still in progress (e.g. streamed as chunks) ends with its result
+	if _, ok := c.activeToolCalls[toolID]; ok {
+		c.handleToolCallEnd(map[string]interface{}{
+			"toolCallId": toolID,
+			"result":     content,
+			"timestamp":  event["timestamp"],
+		})
+		return
+	}
+
+	// Otherwise the result belongs to a call that already ended
+	setResult := func(msg *types.Message) bool {
+		for i := range msg.ToolCalls {
+			if msg.ToolCalls[i].ID == toolID {
+				if msg.ToolCalls[i].Result == "" {
+					msg.ToolCalls[i].Result = content
+				}
+				return true
+			}
+		}
+		return false
+	}
+	if c.currentMessage != nil && setResult(c.currentMessage) {
+		return
+	}
+	for i := len(c.messages) - 1; i >= 0; i-- {
+		if setResult(&c.messages[i]) {
+			return
+		}
+	}
+}
+
 func (c *MessageCompactor) handleRawEvent(event map[string]interface{}) {
 	// Check for both "data" and "event" fields (AG-UI uses "event")
 	var data map[string]interface{}
@@ -0,0 +1,40 @@
+package websocket
+
+import (
+	"ambient-code-backend/config"
+	"ambient-code-backend/logging"
+	"ambient-code-backend/metrics"
+	"ambient-code-backend/pkg/agui"
+	"ambient-code-backend/types"
+)
+
+// checkConformance validates a runner event against the AG-UI spec and the order of
+// its run's events when conformance mode is on. Violations are logged and counted;
+// the event is still persisted and broadcast as received. stream is the run whose
+// runner stream carried the event and target the run it belongs to.
+func checkConformance(stream, target *AGUIRunState, event *types.Event) {
+	if stream == nil || target == nil || !config.Current().AGUIConformance {
+		return
+	}
+	if stream.conformance == nil {
+		stream.conformance = make(map[string]*agui.StreamValidator)
+	}
+	v := stream.conformance[target.RunID]
+	if v == nil {
+		v = agui.NewStreamValidator()
+		if target != stream {
+			// A sub-agent run was started by its registration rather than the stream
+			_ = v.Next(&agui.RunStartedEvent{BaseEvent: agui.NewBaseEvent(agui.EventTypeRunStarted, target.ThreadID, target.RunID)})
+		}
+		stream.conformance[target.RunID] = v
+	}
+	if err := v.Next(event.Payload); err != nil {
+		// Event types without a struct are runner-defined; don't let them label metrics
+		eventType := event.Type()
+		if _, ok := event.Payload.(*types.BaseEvent); ok {
+			eventType = "other"
+		}
+		metrics.ObserveAGUIConformanceViolation(eventType)
+		logging.Warnf(stream.logContext(), "AGUI Conformance: run %s of session %s: %v", target.RunID, target.SessionID, err)
+	}
+}
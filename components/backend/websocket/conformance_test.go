@@ -0,0 +1,305 @@
+package websocket
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"ambient-code-backend/config"
+	"ambient-code-backend/handlers"
+	"ambient-code-backend/types"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// everyEventType is a well-formed run using each AG-UI event type
+var everyEventType = []string{
+	`{"type":"RUN_STARTED","threadId":"s1","runId":"r1"}`,
+	`{"type":"MESSAGES_SNAPSHOT","messages":[{"id":"u1","role":"user","content":"List files"}]}`,
+	`{"type":"STEP_STARTED","stepName":"plan"}`,
+	`{"type":"THINKING_START"}`,
+	`{"type":"THINKING_TEXT_MESSAGE_START"}`,
+	`{"type":"THINKING_TEXT_MESSAGE_CONTENT","delta":"hmm"}`,
+	`{"type":"THINKING_TEXT_MESSAGE_END"}`,
+	`{"type":"THINKING_END"}`,
+	`{"type":"TEXT_MESSAGE_START","messageId":"m1","role":"assistant"}`,
+	`{"type":"TEXT_MESSAGE_CONTENT","messageId":"m1","delta":"Hello"}`,
+	`{"type":"TEXT_MESSAGE_END","messageId":"m1"}`,
+	`{"type":"TOOL_CALL_START","toolCallId":"t1","toolCallName":"Bash","parentMessageId":"m1"}`,
+	`{"type":"TOOL_CALL_ARGS","toolCallId":"t1","delta":"{\"command\":\"ls\"}"}`,
+	`{"type":"TOOL_CALL_END","toolCallId":"t1"}`,
+	`{"type":"TOOL_CALL_CHUNK","toolCallId":"t2","toolCallName":"Read","delta":"{}"}`,
+	`{"type":"TOOL_CALL_RESULT","messageId":"m3","toolCallId":"t1","content":"README.md"}`,
+	`{"type":"TEXT_MESSAGE_CHUNK","messageId":"m2","role":"assistant","delta":"Done"}`,
+	`{"type":"TOOL_CALL_RESULT","messageId":"m4","toolCallId":"t2","content":"# vTeam"}`,
+	`{"type":"STATE_SNAPSHOT","state":{"step":1}}`,
+	`{"type":"STATE_DELTA","delta":[{"op":"replace","path":"/step","value":2}]}`,
+	`{"type":"ACTIVITY_SNAPSHOT","activities":[]}`,
+	`{"type":"ACTIVITY_DELTA","delta":[]}`,
+	`{"type":"RAW","event":{"x":1},"source":"claude"}`,
+	`{"type":"CUSTOM","name":"progress","value":{"pct":50}}`,
+	`{"type":"META","metaType":"thumbs_up","payload":{}}`,
+	`{"type":"STEP_FINISHED","stepName":"plan"}`,
+	`{"type":"RUN_FINISHED","threadId":"s1","runId":"r1"}`,
+}
+
+// fakeRunner serves events as the SSE stream of a run
+func fakeRunner(t *testing.T, events []string) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, event := range events {
+			fmt.Fprintf(w, "data: %s\n\n", event)
+			w.(http.Flusher).Flush()
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// conformanceRun is the outcome of streaming events from a fake runner through the proxy
+type conformanceRun struct {
+	state       *AGUIRunState
+	persisted   []map[string]interface{}
+	broadcast   []*types.Event
+	violations  float64
+	fullEvents  int
+	openToolIDs int
+}
+
+// streamThroughProxy registers run r1 of session s1, consumes the fake runner's stream
+// of events and collects what the proxy persisted and broadcast. setup adjusts the
+// run state before the stream is read.
+func streamThroughProxy(t *testing.T, events []string, setup ...func(*AGUIRunState)) conformanceRun {
+	t.Helper()
+	StateBaseDir = t.TempDir()
+	handlers.K8sClient = fake.NewSimpleClientset()
+	t.Setenv("AGUI_CONFORMANCE", "true")
+	if err := config.Init(); err != nil {
+		t.Fatalf("config.Init: %v", err)
+	}
+
+	state := &AGUIRunState{
+		ThreadID:     "s1",
+		RunID:        "r1",
+		SessionID:    "s1",
+		ProjectName:  "p1",
+		Status:       "running",
+		StartedAt:    time.Now(),
+		subscribers:  make(map[chan *types.BaseEvent]bool),
+		fullEventSub: make(map[chan interface{}]bool),
+	}
+	for _, f := range setup {
+		f(state)
+	}
+	aguiRunsMu.Lock()
+	aguiRuns[state.RunID] = state
+	aguiRunsMu.Unlock()
+	t.Cleanup(func() {
+		aguiRunsMu.Lock()
+		delete(aguiRuns, state.RunID)
+		aguiRunsMu.Unlock()
+	})
+	thread, unsubscribe := SubscribeSession("s1")
+	defer unsubscribe()
+	full := make(chan interface{}, 100)
+	state.fullEventSub[full] = true
+
+	resp, err := http.Post(fakeRunner(t, events).URL, "application/json", nil)
+	if err != nil {
+		t.Fatalf("POST fake runner: %v", err)
+	}
+	defer resp.Body.Close()
+	before := conformanceViolations(t)
+	if !consumeRunStream(context.Background(), resp.Body, "s1", "r1", "s1", state) {
+		t.Fatal("consumeRunStream was cancelled")
+	}
+	done := make(chan struct{})
+	persistPool.Submit("s1", func() { close(done) })
+	<-done
+
+	run := conformanceRun{state: state, violations: conformanceViolations(t) - before}
+	run.persisted, err = loadEventsForRun("s1", "")
+	if err != nil {
+		t.Fatalf("loadEventsForRun: %v", err)
+	}
+	for len(thread) > 0 {
+		run.broadcast = append(run.broadcast, (<-thread).(*types.Event))
+	}
+	run.fullEvents = len(full)
+	state.toolCallsMu.Lock()
+	run.openToolIDs = len(state.toolCalls)
+	state.toolCallsMu.Unlock()
+	return run
+}
+
+// conformanceViolations sums the conformance violations counted so far
+func conformanceViolations(t *testing.T) float64 {
+	t.Helper()
+	families, err := prometheus.DefaultGatherer.Gather()
+	if err != nil {
+		t.Fatalf("Gather: %v", err)
+	}
+	var total float64
+	for _, family := range families {
+		if family.GetName() == "ambient_backend_agui_conformance_violations_total" {
+			for _, m := range family.GetMetric() {
+				total += m.GetCounter().GetValue()
+			}
+		}
+	}
+	return total
+}
+
+func persistedTypes(run conformanceRun) []string {
+	var types []string
+	for _, event := range run.persisted {
+		eventType, _ := event["type"].(string)
+		types = append(types, eventType)
+	}
+	return types
+}
+
+func TestConformanceEveryEventType(t *testing.T) {
+	run := streamThroughProxy(t, everyEventType)
+
+	got := persistedTypes(run)
+	if len(got) != len(everyEventType) {
+		t.Fatalf("persisted %d events, want %d: %v", len(got), len(everyEventType), got)
+	}
+	for i, data := range everyEventType {
+		want, err := types.DecodeEvent([]byte(data))
+		if err != nil {
+			t.Fatalf("DecodeEvent(%s): %v", data, err)
+		}
+		if got[i] != want.Type() {
+			t.Errorf("persisted[%d] = %s, want %s", i, got[i], want.Type())
+		}
+		if run.persisted[i]["threadId"] != "s1" || run.persisted[i]["runId"] != "r1" || run.persisted[i]["timestamp"] == nil {
+			t.Errorf("persisted[%d] base fields not filled: %v", i, run.persisted[i])
+		}
+	}
+	if len(run.broadcast) != len(everyEventType) || run.fullEvents != len(everyEventType) {
+		t.Errorf("broadcast %d thread and %d run events, want %d", len(run.broadcast), run.fullEvents, len(everyEventType))
+	}
+	for i, event := range run.broadcast {
+		if _, untyped := event.Payload.(*types.BaseEvent); untyped {
+			t.Errorf("broadcast[%d] %s was not decoded into its event struct", i, event.Type())
+		}
+	}
+	if run.state.Status != "completed" {
+		t.Errorf("status = %s, want completed", run.state.Status)
+	}
+	if run.openToolIDs != 0 {
+		t.Errorf("%d tool calls still tracked", run.openToolIDs)
+	}
+	if run.violations != 0 {
+		t.Errorf("%v conformance violations in a valid run", run.violations)
+	}
+
+	// The persisted events replay as the conversation
+	messages := CompactEvents(run.persisted)
+	if len(messages) != 3 || messages[0].ID != "u1" || messages[1].Content != "Hello" || messages[2].Content != "Done" {
+		t.Fatalf("compacted messages = %+v", messages)
+	}
+	calls := messages[1].ToolCalls
+	if len(calls) != 1 || calls[0].ID != "t1" || calls[0].Result != "README.md" {
+		t.Errorf("tool calls of %s = %+v", messages[1].ID, calls)
+	}
+	if calls := messages[2].ToolCalls; len(calls) != 1 || calls[0].ID != "t2" || calls[0].Result != "# vTeam" {
+		t.Errorf("chunked tool calls of %s = %+v", messages[2].ID, calls)
+	}
+}
+
+func TestConformancePathologicalStreams(t *testing.T) {
+	tests := []struct {
+		name       string
+		events     []string
+		persisted  int
+		status     string
+		violations bool
+	}{
+		{
+			name: "content before start and a second end",
+			events: []string{
+				`{"type":"RUN_STARTED"}`,
+				`{"type":"TEXT_MESSAGE_CONTENT","messageId":"m1","delta":"early"}`,
+				`{"type":"TEXT_MESSAGE_START","messageId":"m1"}`,
+				`{"type":"TEXT_MESSAGE_END","messageId":"m1"}`,
+				`{"type":"TEXT_MESSAGE_END","messageId":"m1"}`,
+				`{"type":"RUN_FINISHED"}`,
+			},
+			persisted: 6, status: "completed", violations: true,
+		},
+		{
+			name: "events after the run finished",
+			events: []string{
+				`{"type":"RUN_STARTED"}`,
+				`{"type":"RUN_FINISHED"}`,
+				`{"type":"TEXT_MESSAGE_CONTENT","messageId":"m1","delta":"late"}`,
+				`{"type":"RUN_ERROR","message":"late failure"}`,
+			},
+			persisted: 4, status: "completed", violations: true,
+		},
+		{
+			name: "malformed and untyped lines",
+			events: []string{
+				`{"type":"RUN_STARTED"}`,
+				`not json`,
+				`{"delta":"no type"}`,
+				`null`,
+				`{"type":"RUN_FINISHED"}`,
+			},
+			persisted: 2, status: "completed",
+		},
+		{
+			name: "RUN_ERROR not matching its struct",
+			events: []string{
+				`{"type":"RUN_STARTED"}`,
+				`{"type":"RUN_ERROR","code":137}`,
+			},
+			persisted: 2, status: "error", violations: true,
+		},
+		{
+			name: "unknown types and snake_case tool call IDs",
+			events: []string{
+				`{"type":"RUN_STARTED"}`,
+				`{"type":"X_PROGRESS","pct":10}`,
+				`{"type":"TOOL_CALL_START","tool_call_id":"t1","toolCallName":"Bash"}`,
+				`{"type":"TOOL_CALL_END","tool_call_id":"t1"}`,
+				`{"type":"RUN_FINISHED"}`,
+			},
+			persisted: 5, status: "completed",
+		},
+		{
+			name: "nothing before RUN_STARTED",
+			events: []string{
+				`{"type":"TOOL_CALL_ARGS","toolCallId":"t9","delta":"{}"}`,
+				`{"type":"RUN_STARTED"}`,
+				`{"type":"RUN_FINISHED"}`,
+			},
+			persisted: 3, status: "completed", violations: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			run := streamThroughProxy(t, tt.events)
+			if len(run.persisted) != tt.persisted || len(run.broadcast) != tt.persisted {
+				t.Errorf("persisted %v and broadcast %d events, want %d", persistedTypes(run), len(run.broadcast), tt.persisted)
+			}
+			if run.state.Status != tt.status {
+				t.Errorf("status = %s, want %s", run.state.Status, tt.status)
+			}
+			if (run.violations > 0) != tt.violations {
+				t.Errorf("%v conformance violations, want any: %v", run.violations, tt.violations)
+			}
+			if run.openToolIDs != 0 {
+				t.Errorf("%d tool calls still tracked", run.openToolIDs)
+			}
+		})
+	}
+}
@@ -0,0 +1,204 @@
+package websocket
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"ambient-code-backend/handlers"
+	"ambient-code-backend/logging"
+	"ambient-code-backend/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// compactKeepMessages is how many of the latest messages compaction leaves as they are
+	compactKeepMessages = 10
+	// compactMinMessages is the fewest older messages worth replacing with a summary
+	compactMinMessages = 4
+	// maxCompactedMessageChars truncates each message in the transcript sent for
+	// summarization, so large tool results don't crowd out the conversation
+	maxCompactedMessageChars = 2000
+	// compactedContextPrefix marks the message standing in for compacted turns
+	compactedContextPrefix = "[Compacted context] Summary of earlier conversation, replacing %d messages:\n\n"
+)
+
+// Compaction maps a context block to the messages it replaced
+type Compaction struct {
+	ID                 string   `json:"id"` // ID of the context block message
+	RunID              string   `json:"runId,omitempty"`
+	ReplacedMessageIDs []string `json:"replacedMessageIds"`
+	Summary            string   `json:"summary"`
+	CreatedAt          string   `json:"createdAt"`
+}
+
+var compactionsFileMu sync.Mutex
+
+func compactionsPath(sessionID string) string {
+	return fmt.Sprintf("%s/sessions/%s/agui-compactions.jsonl", StateBaseDir, sessionID)
+}
+
+// loadCompactions returns the session's compactions, oldest first
+func loadCompactions(sessionID string) ([]Compaction, error) {
+	compactionsFileMu.Lock()
+	defer compactionsFileMu.Unlock()
+	data, err := os.ReadFile(compactionsPath(sessionID))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []Compaction{}, nil
+		}
+		return nil, err
+	}
+	compactions := []Compaction{}
+	for _, line := range splitLines(data) {
+		var c Compaction
+		if len(line) == 0 || json.Unmarshal(line, &c) != nil {
+			continue
+		}
+		compactions = append(compactions, c)
+	}
+	return compactions, nil
+}
+
+func persistCompaction(sessionID string, c Compaction) error {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	compactionsFileMu.Lock()
+	defer compactionsFileMu.Unlock()
+	_ = ensureDir(fmt.Sprintf("%s/sessions/%s", StateBaseDir, sessionID))
+	f, err := openFileAppend(compactionsPath(sessionID))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write(append(data, '\n'))
+	return err
+}
+
+// compactionSplit returns the index of the first message compaction keeps, or 0 when
+// there is too little to compact. The kept tail never starts with a tool result, which
+// would be separated from its call.
+func compactionSplit(messages []types.Message) int {
+	split := len(messages) - compactKeepMessages
+	for split > 0 && messages[split].Role == types.RoleTool {
+		split--
+	}
+	if split < compactMinMessages {
+		return 0
+	}
+	return split
+}
+
+// compactionID derives the context block's ID from the messages it replaces, so
+// resubmitting the same thread reuses the stored summary
+func compactionID(messages []types.Message) string {
+	h := sha256.New()
+	for _, msg := range messages {
+		h.Write([]byte(msg.ID))
+		h.Write([]byte{0})
+	}
+	return "compacted-" + hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// compactionTranscript renders messages as plain text for summarization
+func compactionTranscript(messages []types.Message) string {
+	truncate := func(s string) string {
+		if len(s) > maxCompactedMessageChars {
+			return s[:maxCompactedMessageChars] + " [truncated]"
+		}
+		return s
+	}
+	var b strings.Builder
+	for _, msg := range messages {
+		if msg.Content != "" {
+			fmt.Fprintf(&b, "%s: %s\n", msg.Role, truncate(msg.Content))
+		}
+		for _, tc := range msg.ToolCalls {
+			fmt.Fprintf(&b, "[tool call %s: %s]\n", tc.Name, truncate(tc.Args))
+		}
+	}
+	return b.String()
+}
+
+// compactMessages replaces all but the latest messages of a run's input with one
+// explicitly marked system message summarizing them, recording the mapping. It
+// returns the messages unchanged and a nil compaction when there is too little to
+// compact.
+func compactMessages(ctx context.Context, projectName, sessionName, runID string, messages []types.Message) ([]types.Message, *Compaction, error) {
+	split := compactionSplit(messages)
+	if split == 0 {
+		return messages, nil, nil
+	}
+	older := messages[:split]
+	id := compactionID(older)
+
+	existing, err := loadCompactions(sessionName)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load compactions: %w", err)
+	}
+	var compaction *Compaction
+	for i := range existing {
+		if existing[i].ID == id {
+			compaction = &existing[i]
+			break
+		}
+	}
+	if compaction == nil {
+		summary, err := handlers.SummarizeContext(ctx, projectName, compactionTranscript(older))
+		if err != nil {
+			return nil, nil, err
+		}
+		replaced := make([]string, len(older))
+		for i, msg := range older {
+			replaced[i] = msg.ID
+		}
+		compaction = &Compaction{
+			ID:                 id,
+			RunID:              runID,
+			ReplacedMessageIDs: replaced,
+			Summary:            summary,
+			CreatedAt:          time.Now().UTC().Format(time.RFC3339),
+		}
+		if err := persistCompaction(sessionName, *compaction); err != nil {
+			return nil, nil, fmt.Errorf("failed to record compaction: %w", err)
+		}
+	}
+
+	block := types.Message{
+		ID:      compaction.ID,
+		Role:    types.RoleSystem,
+		Content: fmt.Sprintf(compactedContextPrefix, len(compaction.ReplacedMessageIDs)) + compaction.Summary,
+		Metadata: map[string]interface{}{
+			"compacted":          true,
+			"replacedMessageIds": compaction.ReplacedMessageIDs,
+		},
+	}
+	return append([]types.Message{block}, messages[split:]...), compaction, nil
+}
+
+// HandleAGUICompactions handles GET /api/projects/:projectName/agentic-sessions/:sessionName/agui/compactions
+// Lists the context blocks that replaced older messages and what each replaced
+func HandleAGUICompactions(c *gin.Context) {
+	sessionName := c.Param("sessionName")
+	if !isValidSessionName(sessionName) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session name"})
+		return
+	}
+	compactions, err := loadCompactions(sessionName)
+	if err != nil {
+		logging.Errorf(c, "Compactions: Failed to load compactions for %s: %v", sessionName, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load compactions"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"compactions": compactions})
+}
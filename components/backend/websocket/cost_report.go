@@ -0,0 +1,483 @@
+package websocket
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"ambient-code-backend/config"
+	"ambient-code-backend/handlers"
+	"ambient-code-backend/logging"
+
+	"github.com/gin-gonic/gin"
+	authv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// Cost reports combine the token cost runners report per run with runner pod CPU and
+// memory sampled from metrics-server and the size of sized workspace PVCs. Resources
+// are priced with the configured costRates.
+const (
+	resourceSampleInterval = 5 * time.Minute
+	// hoursPerMonth converts the monthly storage price to an hourly one
+	hoursPerMonth = 730
+	gib           = 1 << 30
+)
+
+// podMetricsResource is metrics-server's PodMetrics, read through the dynamic client
+var podMetricsResource = schema.GroupVersionResource{Group: "metrics.k8s.io", Version: "v1beta1", Resource: "pods"}
+
+// ResourceUsageSample is what a session's runner used at one sampling. It stands for
+// the Seconds before At.
+type ResourceUsageSample struct {
+	At           string  `json:"at"`
+	Seconds      float64 `json:"seconds"`
+	CPUCores     float64 `json:"cpuCores"`
+	MemoryBytes  int64   `json:"memoryBytes"`
+	StorageBytes int64   `json:"storageBytes"`
+}
+
+// CostReportRow totals the usage and cost of one group of sessions
+type CostReportRow struct {
+	Project             string  `json:"project,omitempty"`
+	User                string  `json:"user,omitempty"`
+	Session             string  `json:"session,omitempty"`
+	Runs                int     `json:"runs"`
+	InputTokens         int64   `json:"inputTokens"`
+	OutputTokens        int64   `json:"outputTokens"`
+	CacheReadTokens     int64   `json:"cacheReadTokens"`
+	CacheCreationTokens int64   `json:"cacheCreationTokens"`
+	TokenCostUSD        float64 `json:"tokenCostUsd"`
+	CPUCoreHours        float64 `json:"cpuCoreHours"`
+	MemoryGiBHours      float64 `json:"memoryGiBHours"`
+	StorageGiBHours     float64 `json:"storageGiBHours"`
+	ComputeCostUSD      float64 `json:"computeCostUsd"`
+	StorageCostUSD      float64 `json:"storageCostUsd"`
+	TotalCostUSD        float64 `json:"totalCostUsd"`
+}
+
+// CostReport is the cost of the runs and runner resources of a time range
+type CostReport struct {
+	From    string           `json:"from"`
+	To      string           `json:"to"`
+	GroupBy string           `json:"groupBy"`
+	Rates   config.CostRates `json:"rates"`
+	Rows    []CostReportRow  `json:"rows"`
+	Total   CostReportRow    `json:"total"`
+}
+
+func resourceUsagePath(sessionName string) string {
+	return fmt.Sprintf("%s/sessions/%s/resource-usage.jsonl", StateBaseDir, sessionName)
+}
+
+// StartResourceUsageSampler records runner resource usage every resourceSampleInterval
+// for the life of the process
+func StartResourceUsageSampler() {
+	if handlers.K8sClient == nil || handlers.DynamicClient == nil {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(resourceSampleInterval)
+		defer ticker.Stop()
+		for now := range ticker.C {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			sampleResourceUsage(ctx, now)
+			cancel()
+		}
+	}()
+}
+
+// metricsServerMissing avoids logging on every sample of clusters without metrics-server
+var metricsServerMissing bool
+
+// sampleResourceUsage appends a sample for every session with a running runner pod or
+// a workspace PVC
+func sampleResourceUsage(ctx context.Context, now time.Time) {
+	samples := map[string]*ResourceUsageSample{} // by session name
+	sample := func(sessionName string) *ResourceUsageSample {
+		s, ok := samples[sessionName]
+		if !ok {
+			s = &ResourceUsageSample{At: now.UTC().Format(time.RFC3339), Seconds: resourceSampleInterval.Seconds()}
+			samples[sessionName] = s
+		}
+		return s
+	}
+
+	podMetrics, err := handlers.DynamicClient.Resource(podMetricsResource).Namespace("").List(ctx, metav1.ListOptions{LabelSelector: "app=ambient-code-runner"})
+	switch {
+	case errors.IsNotFound(err):
+		if !metricsServerMissing {
+			logging.Warnf(ctx, "Cost report: metrics-server is not available; CPU and memory are not sampled")
+			metricsServerMissing = true
+		}
+	case err != nil:
+		logging.Warnf(ctx, "Cost report: failed to read runner pod metrics: %v", err)
+	default:
+		metricsServerMissing = false
+		for _, item := range podMetrics.Items {
+			sessionName := item.GetLabels()["agentic-session"]
+			if !isValidSessionName(sessionName) {
+				continue
+			}
+			cpu, memory := podMetricsUsage(item)
+			s := sample(sessionName)
+			s.CPUCores += cpu
+			s.MemoryBytes += memory
+		}
+	}
+
+	pvcs, err := handlers.K8sClient.CoreV1().PersistentVolumeClaims("").List(ctx, metav1.ListOptions{LabelSelector: "app=ambient-workspace"})
+	if err != nil {
+		logging.Warnf(ctx, "Cost report: failed to list workspace PVCs: %v", err)
+	} else {
+		for _, pvc := range pvcs.Items {
+			sessionName := strings.TrimSuffix(pvc.Name, "-runner-workspace")
+			if sessionName == pvc.Name || !isValidSessionName(sessionName) {
+				continue
+			}
+			size, ok := pvc.Status.Capacity[corev1.ResourceStorage]
+			if !ok {
+				size = pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+			}
+			sample(sessionName).StorageBytes += size.Value()
+		}
+	}
+
+	for sessionName, s := range samples {
+		rec := *s
+		persistPool.Submit(sessionName, func() { appendResourceUsage(sessionName, rec) })
+	}
+}
+
+// podMetricsUsage sums the CPU cores and memory bytes of a PodMetrics' containers
+func podMetricsUsage(item unstructured.Unstructured) (float64, int64) {
+	containers, _, _ := unstructured.NestedSlice(item.Object, "containers")
+	var cpu float64
+	var memory int64
+	for _, c := range containers {
+		container, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		usage, _, _ := unstructured.NestedStringMap(container, "usage")
+		if q, err := resource.ParseQuantity(usage["cpu"]); err == nil {
+			cpu += q.AsApproximateFloat64()
+		}
+		if q, err := resource.ParseQuantity(usage["memory"]); err == nil {
+			memory += q.Value()
+		}
+	}
+	return cpu, memory
+}
+
+func appendResourceUsage(sessionName string, rec ResourceUsageSample) {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		return
+	}
+	_ = ensureDir(fmt.Sprintf("%s/sessions/%s", StateBaseDir, sessionName))
+	f, err := openFileAppend(resourceUsagePath(sessionName))
+	if err != nil {
+		logging.Errorf(context.Background(), "Cost report: failed to open resource usage of %s: %v", sessionName, err)
+		return
+	}
+	defer f.Close()
+	_, _ = f.Write(append(data, '\n'))
+}
+
+// loadResourceUsage reads the samples of a session taken in [from, to)
+func loadResourceUsage(sessionName string, from, to time.Time) []ResourceUsageSample {
+	data, err := os.ReadFile(resourceUsagePath(sessionName))
+	if err != nil {
+		return nil
+	}
+	var samples []ResourceUsageSample
+	for _, line := range splitLines(data) {
+		var s ResourceUsageSample
+		if json.Unmarshal(line, &s) != nil {
+			continue
+		}
+		if at, err := time.Parse(time.RFC3339, s.At); err == nil && !at.Before(from) && at.Before(to) {
+			samples = append(samples, s)
+		}
+	}
+	return samples
+}
+
+// costReportKey names the group a session's costs are added to
+func costReportKey(session *unstructured.Unstructured, groupBy string) CostReportRow {
+	owner, _, _ := unstructured.NestedString(session.Object, "spec", "userContext", "userId")
+	switch groupBy {
+	case "user":
+		return CostReportRow{User: owner}
+	case "session":
+		return CostReportRow{Project: session.GetNamespace(), User: owner, Session: session.GetName()}
+	}
+	return CostReportRow{Project: session.GetNamespace()}
+}
+
+// add adds other's usage to r
+func (r *CostReportRow) add(other CostReportRow) {
+	r.Runs += other.Runs
+	r.InputTokens += other.InputTokens
+	r.OutputTokens += other.OutputTokens
+	r.CacheReadTokens += other.CacheReadTokens
+	r.CacheCreationTokens += other.CacheCreationTokens
+	r.TokenCostUSD += other.TokenCostUSD
+	r.CPUCoreHours += other.CPUCoreHours
+	r.MemoryGiBHours += other.MemoryGiBHours
+	r.StorageGiBHours += other.StorageGiBHours
+}
+
+// price computes the resource and total costs from the usage, rounding to cents
+// for display
+func (r *CostReportRow) price(rates config.CostRates) {
+	cents := func(v float64) float64 { return math.Round(v*100) / 100 }
+	r.ComputeCostUSD = cents(r.CPUCoreHours*rates.CPUCoreHour + r.MemoryGiBHours*rates.MemoryGiBHour)
+	r.StorageCostUSD = cents(r.StorageGiBHours * rates.StorageGiBMonth / hoursPerMonth)
+	r.TotalCostUSD = cents(r.TokenCostUSD + r.ComputeCostUSD + r.StorageCostUSD)
+	r.TokenCostUSD = cents(r.TokenCostUSD)
+	r.CPUCoreHours = math.Round(r.CPUCoreHours*1000) / 1000
+	r.MemoryGiBHours = math.Round(r.MemoryGiBHours*1000) / 1000
+	r.StorageGiBHours = math.Round(r.StorageGiBHours*1000) / 1000
+}
+
+// sessionUsage totals the runs started and resources sampled in [from, to) of a session
+func sessionUsage(session *unstructured.Unstructured, from, to time.Time) (CostReportRow, error) {
+	var usage CostReportRow
+	for _, meta := range getRunsForSession(session.GetName()) {
+		if started, err := time.Parse(time.RFC3339, meta.StartedAt); err != nil || started.Before(from) || !started.Before(to) {
+			continue
+		}
+		events, err := loadEventsForRun(session.GetName(), meta.RunID)
+		if err != nil {
+			return usage, err
+		}
+		stats := runStats(meta, events)
+		usage.Runs++
+		usage.InputTokens += stats.InputTokens
+		usage.OutputTokens += stats.OutputTokens
+		usage.CacheReadTokens += stats.CacheReadTokens
+		usage.CacheCreationTokens += stats.CacheCreationTokens
+		usage.TokenCostUSD += stats.CostUSD
+	}
+	for _, s := range loadResourceUsage(session.GetName(), from, to) {
+		hours := s.Seconds / 3600
+		usage.CPUCoreHours += s.CPUCores * hours
+		usage.MemoryGiBHours += float64(s.MemoryBytes) / gib * hours
+		usage.StorageGiBHours += float64(s.StorageBytes) / gib * hours
+	}
+	return usage, nil
+}
+
+// buildCostReport groups the usage of sessions in [from, to) by project, user or session
+func buildCostReport(c *gin.Context, sessions []unstructured.Unstructured, from, to time.Time, groupBy string) CostReport {
+	rates := config.Current().CostRates
+	groups := map[CostReportRow]*CostReportRow{}
+	for i := range sessions {
+		session := &sessions[i]
+		if !isValidSessionName(session.GetName()) {
+			continue
+		}
+		usage, err := sessionUsage(session, from, to)
+		if err != nil {
+			logging.Warnf(c, "Cost report: failed to read runs of %s/%s: %v", session.GetNamespace(), session.GetName(), err)
+		}
+		if usage == (CostReportRow{}) {
+			continue
+		}
+		key := costReportKey(session, groupBy)
+		row, ok := groups[key]
+		if !ok {
+			row = &CostReportRow{Project: key.Project, User: key.User, Session: key.Session}
+			groups[key] = row
+		}
+		row.add(usage)
+	}
+
+	report := CostReport{
+		From:    from.UTC().Format(time.RFC3339),
+		To:      to.UTC().Format(time.RFC3339),
+		GroupBy: groupBy,
+		Rates:   rates,
+		Rows:    make([]CostReportRow, 0, len(groups)),
+	}
+	for _, row := range groups {
+		report.Total.add(*row)
+		row.price(rates)
+		report.Rows = append(report.Rows, *row)
+	}
+	report.Total.price(rates)
+	sort.Slice(report.Rows, func(a, b int) bool {
+		if report.Rows[a].TotalCostUSD != report.Rows[b].TotalCostUSD {
+			return report.Rows[a].TotalCostUSD > report.Rows[b].TotalCostUSD
+		}
+		ka, kb := report.Rows[a], report.Rows[b]
+		return ka.Project+"/"+ka.User+"/"+ka.Session < kb.Project+"/"+kb.User+"/"+kb.Session
+	})
+	return report
+}
+
+// parseCostReportRange reads ?from= and ?to= as RFC3339 times or YYYY-MM-DD dates,
+// defaulting to the 30 days up to now
+func parseCostReportRange(c *gin.Context) (time.Time, time.Time, error) {
+	parse := func(name string, def time.Time) (time.Time, error) {
+		v := c.Query(name)
+		if v == "" {
+			return def, nil
+		}
+		if t, err := time.Parse(time.RFC3339, v); err == nil {
+			return t, nil
+		}
+		if t, err := time.Parse(time.DateOnly, v); err == nil {
+			return t, nil
+		}
+		return time.Time{}, fmt.Errorf("%s must be an RFC3339 time or a YYYY-MM-DD date", name)
+	}
+	to, err := parse("to", time.Now())
+	if err != nil {
+		return to, to, err
+	}
+	from, err := parse("from", to.Add(-30*24*time.Hour))
+	if err != nil {
+		return from, to, err
+	}
+	if !from.Before(to) {
+		return from, to, fmt.Errorf("from must be before to")
+	}
+	if to.Sub(from) > 366*24*time.Hour {
+		return from, to, fmt.Errorf("the range must not exceed 366 days")
+	}
+	return from, to, nil
+}
+
+// writeCostReport answers with the report as JSON, or as CSV for ?format=csv
+func writeCostReport(c *gin.Context, report CostReport, filename string) {
+	if c.DefaultQuery("format", "json") != "csv" {
+		c.JSON(http.StatusOK, report)
+		return
+	}
+	c.Header("Content-Type", "text/csv")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s.csv\"", filename))
+	c.Status(http.StatusOK)
+	w := csv.NewWriter(c.Writer)
+	_ = w.Write([]string{"from", "to", "project", "user", "session", "runs", "inputTokens", "outputTokens",
+		"cacheReadTokens", "cacheCreationTokens", "tokenCostUsd", "cpuCoreHours", "memoryGiBHours",
+		"storageGiBHours", "computeCostUsd", "storageCostUsd", "totalCostUsd"})
+	num := func(v float64) string { return strconv.FormatFloat(v, 'f', -1, 64) }
+	for _, r := range append(report.Rows, report.Total) {
+		_ = w.Write([]string{report.From, report.To, r.Project, r.User, r.Session, strconv.Itoa(r.Runs),
+			strconv.FormatInt(r.InputTokens, 10), strconv.FormatInt(r.OutputTokens, 10),
+			strconv.FormatInt(r.CacheReadTokens, 10), strconv.FormatInt(r.CacheCreationTokens, 10),
+			num(r.TokenCostUSD), num(r.CPUCoreHours), num(r.MemoryGiBHours), num(r.StorageGiBHours),
+			num(r.ComputeCostUSD), num(r.StorageCostUSD), num(r.TotalCostUSD)})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		logging.Errorf(c, "Cost report: failed to write CSV: %v", err)
+	}
+}
+
+// HandleProjectCostReport reports a project's costs per user (default) or session.
+// The last CSV row, without user and session, is the project total.
+// GET /api/projects/:projectName/cost-report?from=2026-09-01&to=2026-10-01&groupBy=user|session&format=json|csv
+func HandleProjectCostReport(c *gin.Context) {
+	projectName := c.Param("projectName")
+
+	reqK8s, _ := handlers.GetK8sClientsForRequest(c)
+	if reqK8s == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing token"})
+		c.Abort()
+		return
+	}
+
+	// SECURITY: Verify user can list sessions in this project
+	allowed, err := handlers.CheckAccessForRequest(c, reqK8s, authv1.ResourceAttributes{
+		Group:     "vteam.ambient-code",
+		Resource:  "agenticsessions",
+		Verb:      "list",
+		Namespace: projectName,
+	})
+	if err != nil || !allowed {
+		logging.Warnf(c, "Cost report: User not authorized to list sessions in %s", projectName)
+		c.JSON(http.StatusForbidden, gin.H{"error": "Unauthorized"})
+		c.Abort()
+		return
+	}
+	if handlers.DynamicClient == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Kubernetes client not initialized"})
+		return
+	}
+
+	groupBy := c.DefaultQuery("groupBy", "user")
+	if groupBy != "user" && groupBy != "session" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "groupBy must be user or session"})
+		return
+	}
+	from, to, err := parseCostReportRange(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	list, err := handlers.DynamicClient.Resource(handlers.GetAgenticSessionV1Alpha1Resource()).Namespace(projectName).List(c.Request.Context(), metav1.ListOptions{})
+	if err != nil {
+		logging.Errorf(c, "Cost report: failed to list sessions in %s: %v", projectName, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list sessions"})
+		return
+	}
+	// Rows carry the project too, so CSVs of several projects can be joined
+	report := buildCostReport(c, list.Items, from, to, groupBy)
+	for i := range report.Rows {
+		report.Rows[i].Project = projectName
+	}
+	report.Total.Project = projectName
+	writeCostReport(c, report, projectName+"-cost")
+}
+
+// HandleAdminCostReport reports costs across projects per project (default), user or
+// session, for showback to the teams using the platform
+// GET /api/admin/cost-report?from=&to=&groupBy=project|user|session&project=&format=json|csv
+func HandleAdminCostReport(c *gin.Context) {
+	if handlers.DynamicClient == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Kubernetes client not initialized"})
+		return
+	}
+	project := c.Query("project")
+	if project != "" && !isValidSessionName(project) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid project name format"})
+		return
+	}
+	groupBy := c.DefaultQuery("groupBy", "project")
+	if groupBy != "project" && groupBy != "user" && groupBy != "session" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "groupBy must be project, user or session"})
+		return
+	}
+	from, to, err := parseCostReportRange(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Empty namespace lists across all projects
+	list, err := handlers.DynamicClient.Resource(handlers.GetAgenticSessionV1Alpha1Resource()).Namespace(project).List(c.Request.Context(), metav1.ListOptions{})
+	if err != nil {
+		logging.Errorf(c, "Cost report: failed to list sessions: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list sessions"})
+		return
+	}
+	writeCostReport(c, buildCostReport(c, list.Items, from, to, groupBy), "cost")
+}
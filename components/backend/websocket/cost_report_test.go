@@ -0,0 +1,89 @@
+package websocket
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"ambient-code-backend/config"
+
+	"github.com/gin-gonic/gin"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestBuildCostReport(t *testing.T) {
+	StateBaseDir = t.TempDir()
+	// Runs after the environment is restored
+	t.Cleanup(func() { _ = config.Init() })
+	t.Setenv("COST_CPU_CORE_HOUR", "0.04")
+	t.Setenv("COST_MEMORY_GIB_HOUR", "0.005")
+	t.Setenv("COST_STORAGE_GIB_MONTH", "73")
+	if err := config.Init(); err != nil {
+		t.Fatalf("config.Init: %v", err)
+	}
+
+	from := time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+	session := func(name, owner string) unstructured.Unstructured {
+		return unstructured.Unstructured{Object: map[string]interface{}{
+			"metadata": map[string]interface{}{"name": name, "namespace": "team-a"},
+			"spec":     map[string]interface{}{"userContext": map[string]interface{}{"userId": owner}},
+		}}
+	}
+	write := func(sessionName, file, content string) {
+		dir := filepath.Join(StateBaseDir, "sessions", sessionName)
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, file), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	result := `{"type":"STATE_DELTA","runId":"%s","delta":[{"op":"add","path":"/lastResult","value":{"total_cost_usd":%s,"usage":{"input_tokens":100,"output_tokens":50}}}]}`
+	write("s1", "agui-runs.jsonl", `{"runId":"r1","sessionName":"s1","startedAt":"2026-09-10T10:00:00Z","status":"completed"}
+{"runId":"r0","sessionName":"s1","startedAt":"2026-08-31T10:00:00Z","status":"completed"}
+`)
+	write("s1", "agui-events.jsonl", fmt.Sprintf(result, "r1", "1.25")+"\n"+fmt.Sprintf(result, "r0", "9")+"\n")
+	// One hour of 2 cores and 4GiB, and 10GiB of storage
+	write("s1", "resource-usage.jsonl", `{"at":"2026-09-10T10:30:00Z","seconds":1800,"cpuCores":2,"memoryBytes":4294967296,"storageBytes":10737418240}
+{"at":"2026-09-10T11:00:00Z","seconds":1800,"cpuCores":2,"memoryBytes":4294967296,"storageBytes":10737418240}
+{"at":"2026-10-02T11:00:00Z","seconds":1800,"cpuCores":64}
+`)
+	write("s2", "resource-usage.jsonl", `{"at":"2026-09-20T00:00:00Z","seconds":3600,"cpuCores":1}
+`)
+	sessions := []unstructured.Unstructured{session("s1", "alice"), session("s2", "bob"), session("s3", "alice")}
+
+	gin.SetMode(gin.TestMode)
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	report := buildCostReport(c, sessions, from, to, "user")
+	if len(report.Rows) != 2 {
+		t.Fatalf("rows = %+v, want alice and bob", report.Rows)
+	}
+	alice := report.Rows[0]
+	if alice.User != "alice" || alice.Runs != 1 || alice.InputTokens != 100 || alice.TokenCostUSD != 1.25 {
+		t.Errorf("alice runs = %+v", alice)
+	}
+	// 2 core-hours at 0.04, 4 GiB-hours at 0.005, 10 GiB-hours at 73/730
+	if alice.CPUCoreHours != 2 || alice.MemoryGiBHours != 4 || alice.ComputeCostUSD != 0.1 || alice.StorageCostUSD != 1 {
+		t.Errorf("alice resources = %+v", alice)
+	}
+	if alice.TotalCostUSD != 2.35 || report.Total.TotalCostUSD != 2.39 || report.Total.Runs != 1 {
+		t.Errorf("totals = %v, %+v", alice.TotalCostUSD, report.Total)
+	}
+
+	w := httptest.NewRecorder()
+	c, _ = gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("GET", "/cost-report?format=csv", nil)
+	writeCostReport(c, buildCostReport(c, sessions, from, to, "session"), "team-a-cost")
+	records, err := csv.NewReader(w.Body).ReadAll()
+	if err != nil {
+		t.Fatalf("CSV: %v", err)
+	}
+	if len(records) != 4 || records[1][2] != "team-a" || records[1][4] != "s1" || records[3][16] != "2.39" {
+		t.Errorf("CSV = %v", records)
+	}
+}
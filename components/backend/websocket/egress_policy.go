@@ -0,0 +1,83 @@
+package websocket
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"ambient-code-backend/handlers"
+	"ambient-code-backend/logging"
+	"ambient-code-backend/types"
+)
+
+// egressPolicyViolationEvent is the RAW event subtype emitted when a tool call's
+// arguments reach a host outside the project's egress allowlist
+const egressPolicyViolationEvent = "egress_policy_violation"
+
+// loadSessionEgressPolicy returns the egress policy snapshotted on the session, if any
+func loadSessionEgressPolicy(projectName, sessionName string) *types.EgressPolicy {
+	if handlers.DynamicClient == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	item, err := handlers.GetCachedSession(ctx, projectName, sessionName)
+	if err != nil {
+		logging.Errorf(context.Background(), "AGUI Proxy: failed to load egress policy for %s/%s: %v", projectName, sessionName, err)
+		return nil
+	}
+	return handlers.EgressPolicyForSession(item)
+}
+
+// checkToolCallEgress emits a policy-violation event, ahead of the TOOL_CALL_ARGS
+// event itself, when the arguments name hosts the session's egress policy doesn't
+// allow. In block mode the runner refuses the call itself and the agent carries on,
+// so the run is not interrupted.
+func checkToolCallEgress(sessionID, runID, threadID string, args *types.ToolCallArgsEvent, runState *AGUIRunState) {
+	if runState == nil || runState.egressPolicy == nil {
+		return
+	}
+	toolName := ""
+	runState.toolCallsMu.Lock()
+	if pending, ok := runState.toolCalls[args.ToolCallID]; ok {
+		toolName = pending.name
+	}
+	runState.toolCallsMu.Unlock()
+	if types.EgressLocalTools[toolName] {
+		return
+	}
+
+	var denied []string
+	for _, host := range types.ExtractEgressHosts(args.Delta) {
+		if !runState.egressPolicy.AllowsHost(host) {
+			denied = append(denied, host)
+		}
+	}
+	if len(denied) == 0 {
+		return
+	}
+
+	policy := runState.egressPolicy
+	logging.Warnf(runState.logContext(), "AGUI Proxy: egress policy violation in %s/%s run %s: %s reaches %s (%s)",
+		runState.ProjectName, sessionID, runID, toolName, strings.Join(denied, ", "), policy.Enforcement)
+
+	violation, err := types.NewEvent(&types.RawEvent{
+		BaseEvent: types.NewBaseEvent(types.EventTypeRaw, threadID, runID),
+		Event: map[string]interface{}{
+			"type":        egressPolicyViolationEvent,
+			"toolCallId":  args.ToolCallID,
+			"toolName":    toolName,
+			"hosts":       denied,
+			"enforcement": policy.Enforcement,
+			"message":     fmt.Sprintf("%s is not in the project's egress allowlist", strings.Join(denied, ", ")),
+		},
+	})
+	if err != nil {
+		logging.Errorf(runState.logContext(), "AGUI Proxy: failed to build egress violation event: %v", err)
+	} else {
+		persistAGUIEvent(sessionID, runID, violation)
+		runState.BroadcastFull(violation)
+		broadcastToThread(sessionID, violation)
+	}
+}
@@ -0,0 +1,497 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"ambient-code-backend/handlers"
+	"ambient-code-backend/logging"
+	"ambient-code-backend/types"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	authv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	evalPollInterval = 5 * time.Second
+	// evalStartTimeout bounds how long a sandbox session may take to start
+	evalStartTimeout = 15 * time.Minute
+	// evalRunTimeout matches the runner stream timeout
+	evalRunTimeout = 2 * time.Hour
+)
+
+// EvalRequest asks for a stored run to be replayed in a sandbox session
+type EvalRequest struct {
+	SessionName string `json:"sessionName" binding:"required"`
+	RunID       string `json:"runId" binding:"required"`
+	// Model replaces the session's model for the replay
+	Model string `json:"model,omitempty"`
+	// SystemPrompt is a prompt variant appended to the runner's system prompt
+	SystemPrompt string `json:"systemPrompt,omitempty"`
+	// UseToolFixtures answers tool calls with the source run's recorded results
+	// instead of executing them
+	UseToolFixtures bool `json:"useToolFixtures,omitempty"`
+}
+
+// EvalResult compares a replay with its source run
+type EvalResult struct {
+	Source            RunStats       `json:"source"`
+	Replay            RunStats       `json:"replay"`
+	TokensDelta       int64          `json:"tokensDelta"`
+	CostDeltaUSD      float64        `json:"costDeltaUsd"`
+	DurationDeltaMs   int64          `json:"durationDeltaMs"`
+	ToolSequenceMatch bool           `json:"toolSequenceMatch"`
+	FinalAnswerMatch  bool           `json:"finalAnswerMatch"`
+	ArtifactCounts    map[string]int `json:"artifactCounts"`
+	Artifacts         []ArtifactDiff `json:"artifacts"`
+}
+
+// EvalRecord is one replay of a stored run and its outcome
+type EvalRecord struct {
+	ID              string      `json:"id"`
+	SourceSession   string      `json:"sourceSession"`
+	SourceRunID     string      `json:"sourceRunId"`
+	Model           string      `json:"model,omitempty"`
+	SystemPrompt    string      `json:"systemPrompt,omitempty"`
+	UseToolFixtures bool        `json:"useToolFixtures,omitempty"`
+	SandboxSession  string      `json:"sandboxSession"`
+	ReplayRunID     string      `json:"replayRunId"`
+	Status          string      `json:"status"` // "pending", "running", "completed", "failed"
+	Error           string      `json:"error,omitempty"`
+	Result          *EvalResult `json:"result,omitempty"`
+	CreatedBy       string      `json:"createdBy,omitempty"`
+	CreatedAt       string      `json:"createdAt"`
+	CompletedAt     string      `json:"completedAt,omitempty"`
+}
+
+var evalsFileMu sync.Mutex
+
+func evalsPath(project string) string {
+	return fmt.Sprintf("%s/evals/%s.jsonl", StateBaseDir, project)
+}
+
+// persistEval appends the record's current state; the latest line per ID wins
+func persistEval(project string, rec EvalRecord) {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		logging.Errorf(context.Background(), "Eval: failed to marshal record: %v", err)
+		return
+	}
+	evalsFileMu.Lock()
+	defer evalsFileMu.Unlock()
+	_ = ensureDir(fmt.Sprintf("%s/evals", StateBaseDir))
+	f, err := openFileAppend(evalsPath(project))
+	if err != nil {
+		logging.Errorf(context.Background(), "Eval: failed to open evals of %s: %v", project, err)
+		return
+	}
+	defer f.Close()
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		logging.Errorf(context.Background(), "Eval: failed to write record: %v", err)
+	}
+}
+
+// loadEvals returns the project's evals, oldest first
+func loadEvals(project string) ([]EvalRecord, error) {
+	evalsFileMu.Lock()
+	data, err := os.ReadFile(evalsPath(project))
+	evalsFileMu.Unlock()
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []EvalRecord{}, nil
+		}
+		return nil, err
+	}
+	latest := map[string]int{}
+	evals := []EvalRecord{}
+	for _, line := range splitLines(data) {
+		var rec EvalRecord
+		if len(line) == 0 || json.Unmarshal(line, &rec) != nil {
+			continue
+		}
+		if i, ok := latest[rec.ID]; ok {
+			evals[i] = rec
+			continue
+		}
+		latest[rec.ID] = len(evals)
+		evals = append(evals, rec)
+	}
+	return evals, nil
+}
+
+// replayInput rebuilds what the source run was given: the thread's messages before
+// it and its own user messages. With fixtures, it also returns the run's tool calls
+// and their recorded results.
+func replayInput(sessionName, runID string) ([]types.Message, []map[string]interface{}, error) {
+	events, err := loadEventsForRun(sessionName, "")
+	if err != nil {
+		return nil, nil, err
+	}
+	var before, during []map[string]interface{}
+	for _, event := range events {
+		if event["runId"] == runID {
+			during = append(during, event)
+		} else if len(during) == 0 {
+			before = append(before, event)
+		}
+	}
+	if len(during) == 0 {
+		return nil, nil, nil
+	}
+
+	messages := ThreadMessages(before)
+	runMessages := ThreadMessages(during)
+	results := map[string]string{}
+	for _, msg := range runMessages {
+		switch msg.Role {
+		case types.RoleUser:
+			messages = append(messages, msg)
+		case types.RoleTool:
+			results[msg.ToolCallID] = msg.Content
+		}
+	}
+	fixtures := []map[string]interface{}{}
+	for _, msg := range runMessages {
+		for _, tc := range msg.ToolCalls {
+			result, ok := results[tc.ID]
+			if !ok {
+				continue
+			}
+			fixtures = append(fixtures, map[string]interface{}{
+				"tool":   tc.Name,
+				"input":  tc.Args,
+				"result": result,
+			})
+		}
+	}
+	return messages, fixtures, nil
+}
+
+// compareEval compares a finished replay with its source run
+func compareEval(source, replay *compareRun) *EvalResult {
+	result := &EvalResult{
+		Source:            source.stats,
+		Replay:            replay.stats,
+		TokensDelta:       (replay.stats.InputTokens + replay.stats.OutputTokens) - (source.stats.InputTokens + source.stats.OutputTokens),
+		CostDeltaUSD:      replay.stats.CostUSD - source.stats.CostUSD,
+		DurationDeltaMs:   replay.stats.DurationMs - source.stats.DurationMs,
+		ToolSequenceMatch: strings.Join(toolSequence(source.messages), ",") == strings.Join(toolSequence(replay.messages), ","),
+		FinalAnswerMatch:  finalAnswer(source.messages) == finalAnswer(replay.messages),
+		ArtifactCounts:    map[string]int{},
+		Artifacts:         compareArtifacts(source.artifacts, replay.artifacts),
+	}
+	for _, a := range result.Artifacts {
+		result.ArtifactCounts[a.Status]++
+	}
+	return result
+}
+
+// toolSequence lists the tools a run called, in order
+func toolSequence(messages []types.Message) []string {
+	var tools []string
+	for _, msg := range messages {
+		for _, tc := range msg.ToolCalls {
+			tools = append(tools, tc.Name)
+		}
+	}
+	return tools
+}
+
+// finalAnswer is the run's last assistant text
+func finalAnswer(messages []types.Message) string {
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Role == types.RoleAssistant && strings.TrimSpace(messages[i].Content) != "" {
+			return strings.TrimSpace(messages[i].Content)
+		}
+	}
+	return ""
+}
+
+// currentRunStatus reads a run's status from memory, or from the runs index once the
+// run has been cleaned up
+func currentRunStatus(sessionName, runID string) string {
+	aguiRunsMu.RLock()
+	state, ok := aguiRuns[runID]
+	status := ""
+	if ok {
+		status = state.Status
+	}
+	aguiRunsMu.RUnlock()
+	if ok {
+		return status
+	}
+	for _, meta := range loadRunsFromDisk(sessionName) {
+		if meta.RunID == runID {
+			status = meta.Status
+		}
+	}
+	return status
+}
+
+// runEval waits for the sandbox to start, replays the run and records the comparison.
+// The sandbox is stopped afterwards and kept for inspection.
+func runEval(project string, rec EvalRecord, input types.RunAgentInput) {
+	ctx := context.Background()
+	fail := func(format string, args ...interface{}) {
+		rec.Status = "failed"
+		rec.Error = fmt.Sprintf(format, args...)
+		rec.CompletedAt = time.Now().UTC().Format(time.RFC3339)
+		logging.Errorf(ctx, "Eval: %s in %s failed: %s", rec.ID, project, rec.Error)
+		persistEval(project, rec)
+		_ = handlers.RequestSessionStop(ctx, project, rec.SandboxSession)
+	}
+
+	deadline := time.Now().Add(evalStartTimeout)
+	for {
+		if time.Now().After(deadline) {
+			fail("sandbox session did not start within %v", evalStartTimeout)
+			return
+		}
+		time.Sleep(evalPollInterval)
+		item, err := handlers.GetCachedSession(ctx, project, rec.SandboxSession)
+		if errors.IsNotFound(err) {
+			fail("sandbox session was deleted")
+			return
+		}
+		if err != nil {
+			continue
+		}
+		phase, _, _ := unstructured.NestedString(item.Object, "status", "phase")
+		if phase == "Running" {
+			break
+		}
+		if phase == "Failed" || phase == "Stopped" || phase == "Completed" {
+			fail("sandbox session is %s", phase)
+			return
+		}
+	}
+
+	if _, err := startRunStream(ctx, project, rec.SandboxSession, input); err != nil {
+		fail("failed to start replay: %v", err)
+		return
+	}
+	rec.Status = "running"
+	persistEval(project, rec)
+
+	deadline = time.Now().Add(evalRunTimeout)
+	for currentRunStatus(rec.SandboxSession, rec.ReplayRunID) == "running" {
+		if time.Now().After(deadline) {
+			fail("replay did not finish within %v", evalRunTimeout)
+			return
+		}
+		time.Sleep(evalPollInterval)
+	}
+
+	// Queued behind the replay's event writes
+	persistPool.Submit(rec.SandboxSession, func() {
+		source, err := loadCompareRun(rec.SourceSession, rec.SourceRunID)
+		if err == nil && source == nil {
+			err = fmt.Errorf("source run no longer exists")
+		}
+		if err != nil {
+			fail("failed to load source run: %v", err)
+			return
+		}
+		replay, err := loadCompareRun(rec.SandboxSession, rec.ReplayRunID)
+		if err == nil && replay == nil {
+			err = fmt.Errorf("replay run not recorded")
+		}
+		if err != nil {
+			fail("failed to load replay: %v", err)
+			return
+		}
+		rec.Status = "completed"
+		rec.Result = compareEval(source, replay)
+		rec.CompletedAt = time.Now().UTC().Format(time.RFC3339)
+		persistEval(project, rec)
+		logging.Infof(ctx, "Eval: %s in %s completed (replay %s)", rec.ID, project, replay.stats.Status)
+		if err := handlers.RequestSessionStop(ctx, project, rec.SandboxSession); err != nil {
+			logging.Warnf(ctx, "Eval: failed to stop sandbox %s/%s: %v", project, rec.SandboxSession, err)
+		}
+	})
+}
+
+// HandleCreateEval handles POST /api/projects/:projectName/evals
+// Replays a stored run in a new sandbox session, optionally on another model or with
+// a prompt variant, and records how the replay compares. Returns immediately; poll
+// GET /evals/:evalId for the result.
+func HandleCreateEval(c *gin.Context) {
+	project := c.Param("projectName")
+	reqK8s, k8sDyn := handlers.GetK8sClientsForRequest(c)
+	if reqK8s == nil || k8sDyn == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing token"})
+		c.Abort()
+		return
+	}
+
+	var req EvalRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if !isValidSessionName(req.SessionName) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session name"})
+		return
+	}
+
+	// Reading the source session with the caller's client checks they may see its runs
+	item, err := k8sDyn.Resource(handlers.GetAgenticSessionV1Alpha1Resource()).Namespace(project).Get(c.Request.Context(), req.SessionName, metav1.GetOptions{})
+	if err != nil {
+		switch {
+		case errors.IsNotFound(err):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
+		case errors.IsForbidden(err):
+			c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions to read the session"})
+		default:
+			logging.Errorf(c, "Eval: Failed to read session %s: %v", req.SessionName, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read session"})
+		}
+		return
+	}
+
+	messages, fixtures, err := replayInput(req.SessionName, req.RunID)
+	if err != nil {
+		logging.Errorf(c, "Eval: Failed to load run %s of %s: %v", req.RunID, req.SessionName, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load run"})
+		return
+	}
+	if messages == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Run not found"})
+		return
+	}
+
+	evalID := uuid.New().String()[:8]
+	rec := EvalRecord{
+		ID:              evalID,
+		SourceSession:   req.SessionName,
+		SourceRunID:     req.RunID,
+		Model:           req.Model,
+		SystemPrompt:    req.SystemPrompt,
+		UseToolFixtures: req.UseToolFixtures,
+		SandboxSession:  "eval-" + evalID,
+		ReplayRunID:     uuid.New().String(),
+		Status:          "pending",
+		CreatedBy:       c.GetString("userID"),
+		CreatedAt:       time.Now().UTC().Format(time.RFC3339),
+	}
+
+	props := map[string]interface{}{}
+	if req.UseToolFixtures {
+		props["toolFixtures"] = fixtures
+	}
+	// The sandbox keeps the source session's persona; a prompt variant replaces its prompt
+	persona := types.AgentPersona{Name: "eval"}
+	if name, _, _ := unstructured.NestedString(item.Object, "spec", "persona"); name != "" {
+		if persona, err = handlers.LoadAgentPersona(c.Request.Context(), reqK8s, project, name); err != nil {
+			c.JSON(http.StatusConflict, gin.H{"error": "The session's persona " + name + " is not available"})
+			return
+		}
+	}
+	if req.SystemPrompt != "" {
+		persona.SystemPrompt = req.SystemPrompt
+	}
+	if persona.SystemPrompt != "" || len(persona.AllowedTools) > 0 || len(persona.MCPServers) > 0 {
+		props["persona"] = personaProps(persona)
+	}
+	input := types.RunAgentInput{
+		ThreadID:       rec.SandboxSession,
+		RunID:          rec.ReplayRunID,
+		Messages:       messages,
+		ForwardedProps: props,
+		Model:          req.Model,
+	}
+
+	if err := handlers.CreateEvalSandbox(c.Request.Context(), k8sDyn, project, req.SessionName, rec.SandboxSession, evalID, req.Model); err != nil {
+		if errors.IsForbidden(err) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions to create the sandbox session"})
+			return
+		}
+		logging.Errorf(c, "Eval: Failed to create sandbox for %s: %v", req.SessionName, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create sandbox session"})
+		return
+	}
+	persistEval(project, rec)
+	logging.Infof(c, "Eval: %s replays run %s of %s in %s", evalID, req.RunID, req.SessionName, rec.SandboxSession)
+
+	go runEval(project, rec, input)
+	c.JSON(http.StatusAccepted, rec)
+}
+
+// authorizeProjectSessions checks the caller may perform verb on the project's
+// sessions, e.g. list them, whose runs evals reveal. It writes the error response itself.
+func authorizeProjectSessions(c *gin.Context, project, verb string) bool {
+	reqK8s, _ := handlers.GetK8sClientsForRequest(c)
+	if reqK8s == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing token"})
+		c.Abort()
+		return false
+	}
+	allowed, err := handlers.CheckAccessForRequest(c, reqK8s, authv1.ResourceAttributes{
+		Group:     "vteam.ambient-code",
+		Resource:  "agenticsessions",
+		Verb:      verb,
+		Namespace: project,
+	})
+	if err != nil || !allowed {
+		c.JSON(http.StatusForbidden, gin.H{"error": "Unauthorized"})
+		c.Abort()
+		return false
+	}
+	return true
+}
+
+// HandleListEvals handles GET /api/projects/:projectName/evals
+// Optional ?sessionName= lists the evals of one source session
+func HandleListEvals(c *gin.Context) {
+	project := c.Param("projectName")
+	if !authorizeProjectSessions(c, project, "list") {
+		return
+	}
+	evals, err := loadEvals(project)
+	if err != nil {
+		logging.Errorf(c, "Eval: Failed to load evals for %s: %v", project, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load evals"})
+		return
+	}
+	if session := c.Query("sessionName"); session != "" {
+		filtered := []EvalRecord{}
+		for _, e := range evals {
+			if e.SourceSession == session {
+				filtered = append(filtered, e)
+			}
+		}
+		evals = filtered
+	}
+	c.JSON(http.StatusOK, gin.H{"items": evals})
+}
+
+// HandleGetEval handles GET /api/projects/:projectName/evals/:evalId
+func HandleGetEval(c *gin.Context) {
+	project := c.Param("projectName")
+	if !authorizeProjectSessions(c, project, "list") {
+		return
+	}
+	evals, err := loadEvals(project)
+	if err != nil {
+		logging.Errorf(c, "Eval: Failed to load evals for %s: %v", project, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load evals"})
+		return
+	}
+	for _, e := range evals {
+		if e.ID == c.Param("evalId") {
+			c.JSON(http.StatusOK, e)
+			return
+		}
+	}
+	c.JSON(http.StatusNotFound, gin.H{"error": "Eval not found"})
+}
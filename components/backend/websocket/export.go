@@ -5,28 +5,27 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"log"
 	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
 	"time"
 
-	"ambient-code-backend/handlers"
+	"ambient-code-backend/logging"
+	"ambient-code-backend/render"
 
 	"github.com/gin-gonic/gin"
-	authv1 "k8s.io/api/authorization/v1"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 // ExportResponse contains the exported session data
 type ExportResponse struct {
-	SessionID      string          `json:"sessionId"`
-	ProjectName    string          `json:"projectName"`
-	ExportDate     string          `json:"exportDate"`
-	AGUIEvents     json.RawMessage `json:"aguiEvents"`
-	LegacyMessages json.RawMessage `json:"legacyMessages,omitempty"`
-	HasLegacy      bool            `json:"hasLegacy"`
+	SessionID      string            `json:"sessionId"`
+	ProjectName    string            `json:"projectName"`
+	ExportDate     string            `json:"exportDate"`
+	AGUIEvents     json.RawMessage   `json:"aguiEvents"`
+	Annotations    []EventAnnotation `json:"annotations"`
+	LegacyMessages json.RawMessage   `json:"legacyMessages,omitempty"`
+	HasLegacy      bool              `json:"hasLegacy"`
 }
 
 // HandleExportSession exports session chat data as JSON
@@ -35,40 +34,11 @@ func HandleExportSession(c *gin.Context) {
 	projectName := c.Param("projectName")
 	sessionName := c.Param("sessionName")
 
-	log.Printf("Export: Exporting session %s/%s", projectName, sessionName)
-
-	// SECURITY: Authenticate user and get user-scoped K8s client
-	reqK8s, _ := handlers.GetK8sClientsForRequest(c)
-	if reqK8s == nil {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing token"})
-		c.Abort()
-		return
-	}
-
-	// SECURITY: Verify user has permission to read this session
-	ctx := context.Background()
-	ssar := &authv1.SelfSubjectAccessReview{
-		Spec: authv1.SelfSubjectAccessReviewSpec{
-			ResourceAttributes: &authv1.ResourceAttributes{
-				Group:     "vteam.ambient-code",
-				Resource:  "agenticsessions",
-				Verb:      "get",
-				Namespace: projectName,
-				Name:      sessionName,
-			},
-		},
-	}
-	res, err := reqK8s.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, ssar, metav1.CreateOptions{})
-	if err != nil || !res.Status.Allowed {
-		log.Printf("Export: User not authorized to read session %s/%s", projectName, sessionName)
-		c.JSON(http.StatusForbidden, gin.H{"error": "Unauthorized"})
-		c.Abort()
-		return
-	}
+	logging.Infof(c, "Export: Exporting session %s/%s", projectName, sessionName)
 
 	// SECURITY: Validate sessionName to prevent path traversal
 	if !isValidSessionName(sessionName) {
-		log.Printf("Export: Invalid session name detected: %s", sessionName)
+		logging.Errorf(c, "Export: Invalid session name detected: %s", sessionName)
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session name"})
 		return
 	}
@@ -80,7 +50,7 @@ func HandleExportSession(c *gin.Context) {
 
 	// SECURITY: Ensure path is within allowed directory (prevent path traversal)
 	if !strings.HasPrefix(sessionDir, baseDir) {
-		log.Printf("Export: Security - path traversal attempt detected: %s", sessionName)
+		logging.Warnf(c, "Export: Security - path traversal attempt detected: %s", sessionName)
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session name"})
 		return
 	}
@@ -91,7 +61,7 @@ func HandleExportSession(c *gin.Context) {
 
 	// Check if session directory exists
 	if _, err := os.Stat(sessionDir); os.IsNotExist(err) {
-		log.Printf("Export: Session directory not found: %s", sessionDir)
+		logging.Errorf(c, "Export: Session directory not found: %s", sessionDir)
 		c.JSON(http.StatusNotFound, gin.H{"error": "Session not found"})
 		return
 	}
@@ -104,13 +74,13 @@ func HandleExportSession(c *gin.Context) {
 	}
 
 	// Read AG-UI events
-	aguiData, err := readJSONLFile(aguiEventsPath)
+	aguiData, err := readJSONLFile(aguiEventsPath, sessionName)
 	if err != nil {
 		if os.IsNotExist(err) {
 			// No AG-UI events yet - return empty array
 			response.AGUIEvents = json.RawMessage("[]")
 		} else {
-			log.Printf("Export: Error reading AG-UI events: %v", err)
+			logging.Errorf(c, "Export: Error reading AG-UI events: %v", err)
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read session events"})
 			return
 		}
@@ -118,31 +88,39 @@ func HandleExportSession(c *gin.Context) {
 		// Pretty-print the events array
 		prettyJSON, err := json.MarshalIndent(aguiData, "", "  ")
 		if err != nil {
-			log.Printf("Export: Error formatting AG-UI events: %v", err)
+			logging.Errorf(c, "Export: Error formatting AG-UI events: %v", err)
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to format events"})
 			return
 		}
 		response.AGUIEvents = prettyJSON
 	}
 
+	annotations, err := loadAnnotations(sessionName)
+	if err != nil {
+		logging.Errorf(c, "Export: Error reading annotations: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read annotations"})
+		return
+	}
+	response.Annotations = annotations
+
 	// Check for legacy messages - try migrated file first, then original
 	legacyPath := ""
 	if _, err := os.Stat(legacyMigratedPath); err == nil {
 		legacyPath = legacyMigratedPath
-		log.Printf("Export: Found migrated legacy file: %s", legacyMigratedPath)
+		logging.Infof(c, "Export: Found migrated legacy file: %s", legacyMigratedPath)
 	} else if _, err := os.Stat(legacyOriginalPath); err == nil {
 		legacyPath = legacyOriginalPath
-		log.Printf("Export: Found original legacy file: %s", legacyOriginalPath)
+		logging.Infof(c, "Export: Found original legacy file: %s", legacyOriginalPath)
 	}
 
 	if legacyPath != "" {
-		legacyData, err := readJSONLFile(legacyPath)
+		legacyData, err := readJSONLFile(legacyPath, sessionName)
 		if err != nil {
-			log.Printf("Export: Warning - failed to read legacy messages: %v", err)
+			logging.Warnf(c, "Export: failed to read legacy messages: %v", err)
 		} else {
 			prettyJSON, err := json.MarshalIndent(legacyData, "", "  ")
 			if err != nil {
-				log.Printf("Export: Warning - failed to format legacy messages: %v", err)
+				logging.Warnf(c, "Export: failed to format legacy messages: %v", err)
 			} else {
 				response.LegacyMessages = prettyJSON
 				response.HasLegacy = true
@@ -150,7 +128,7 @@ func HandleExportSession(c *gin.Context) {
 		}
 	}
 
-	log.Printf("Export: Successfully exported session %s (hasLegacy=%v)", sessionName, response.HasLegacy)
+	logging.Infof(c, "Export: Successfully exported session %s (hasLegacy=%v)", sessionName, response.HasLegacy)
 
 	// Set headers for JSON download
 	c.Header("Content-Type", "application/json")
@@ -159,6 +137,49 @@ func HandleExportSession(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
+// HandleRenderSession renders a session's transcript as standalone HTML, with
+// highlighted code blocks and rendered diffs, for email notifications and PR comments
+// GET /api/projects/:projectName/agentic-sessions/:sessionName/export/html
+// Query: runId limits it to one run, fragment=true returns only the transcript's
+// <div> for embedding, toolCalls=false leaves tool calls out, title overrides the heading
+func HandleRenderSession(c *gin.Context) {
+	sessionName := c.Param("sessionName")
+	if !isValidSessionName(sessionName) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session name"})
+		return
+	}
+	runID := c.Query("runId")
+	events, err := loadEventsForRun(sessionName, runID)
+	if err != nil {
+		logging.Errorf(c, "Export: Error reading events of %s: %v", sessionName, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to read session events"})
+		return
+	}
+	if runID != "" && len(events) == 0 {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Run not found"})
+		return
+	}
+
+	compactor := NewMessageCompactor()
+	for _, event := range events {
+		compactor.HandleEvent(event)
+	}
+	title := c.Query("title")
+	if title == "" {
+		title = sessionName
+	}
+	page := render.Transcript(compactor.GetMessages(), render.Options{
+		Title:         title,
+		Fragment:      c.Query("fragment") == "true",
+		OmitToolCalls: c.Query("toolCalls") == "false",
+	})
+
+	// Rendered content is escaped, but nothing in it should run or load anyway
+	c.Header("Content-Security-Policy", "default-src 'none'; style-src 'unsafe-inline'")
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Data(http.StatusOK, "text/html; charset=utf-8", []byte(page))
+}
+
 // isValidSessionName validates that the session name is a valid Kubernetes resource name
 // and doesn't contain path traversal characters
 func isValidSessionName(name string) bool {
@@ -197,8 +218,9 @@ func isValidSessionName(name string) bool {
 	return true
 }
 
-// readJSONLFile reads a JSONL file and returns parsed array of objects
-func readJSONLFile(path string) ([]map[string]interface{}, error) {
+// readJSONLFile reads a session's JSONL file and returns parsed array of objects,
+// decrypting lines written by an encrypted session
+func readJSONLFile(path, sessionID string) ([]map[string]interface{}, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, err
@@ -208,13 +230,13 @@ func readJSONLFile(path string) ([]map[string]interface{}, error) {
 	lines := splitLines(data)
 
 	for _, line := range lines {
-		if len(line) == 0 {
+		if line = openEventLine(sessionID, line); len(line) == 0 {
 			continue
 		}
 		var event map[string]interface{}
 		if err := json.Unmarshal(line, &event); err != nil {
 			// Skip malformed lines
-			log.Printf("Export: Skipping malformed JSON line: %v", err)
+			logging.Warnf(context.Background(), "Export: Skipping malformed JSON line: %v", err)
 			continue
 		}
 		events = append(events, event)
@@ -0,0 +1,328 @@
+package websocket
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"ambient-code-backend/handlers"
+	"ambient-code-backend/logging"
+	"ambient-code-backend/types"
+
+	"github.com/gin-gonic/gin"
+	authv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// FeedbackRecord is one thumbs up/down META event, joined with what the rated run used
+type FeedbackRecord struct {
+	Project   string   `json:"project"`
+	Session   string   `json:"session"`
+	RunID     string   `json:"runId,omitempty"`
+	MessageID string   `json:"messageId,omitempty"`
+	User      string   `json:"user,omitempty"`
+	Rating    string   `json:"rating"` // "positive" or "negative"
+	Reason    string   `json:"reason,omitempty"`
+	Comment   string   `json:"comment,omitempty"`
+	Model     string   `json:"model,omitempty"`
+	Workflow  string   `json:"workflow,omitempty"`
+	Tools     []string `json:"tools,omitempty"` // tools called in the rated run
+	Timestamp string   `json:"timestamp"`
+}
+
+// FeedbackStats summarizes the ratings of one model, tool or workflow
+type FeedbackStats struct {
+	Name         string  `json:"name"`
+	Total        int     `json:"total"`
+	Positive     int     `json:"positive"`
+	Negative     int     `json:"negative"`
+	PositiveRate float64 `json:"positiveRate"`
+	NegativeRate float64 `json:"negativeRate"`
+}
+
+// sessionWorkflow names the session's active workflow by its path, or its repository
+// when the workflow sits at the repository root
+func sessionWorkflow(session *unstructured.Unstructured) string {
+	if path, _, _ := unstructured.NestedString(session.Object, "spec", "activeWorkflow", "path"); path != "" {
+		return path
+	}
+	gitURL, _, _ := unstructured.NestedString(session.Object, "spec", "activeWorkflow", "gitUrl")
+	return gitURL
+}
+
+// sessionFeedback extracts the feedback events of a session's event log rated at or
+// after since
+func sessionFeedback(project string, session *unstructured.Unstructured, events []map[string]interface{}, since time.Time) []FeedbackRecord {
+	model, _, _ := unstructured.NestedString(session.Object, "spec", "llmSettings", "model")
+	workflow := sessionWorkflow(session)
+
+	runTools := map[string][]string{}
+	seen := map[string]bool{}
+	for _, event := range events {
+		if event["type"] != types.EventTypeToolCallStart {
+			continue
+		}
+		runID, _ := event["runId"].(string)
+		name, _ := event["toolCallName"].(string)
+		if name == "" || seen[runID+"/"+name] {
+			continue
+		}
+		seen[runID+"/"+name] = true
+		runTools[runID] = append(runTools[runID], name)
+	}
+
+	var records []FeedbackRecord
+	for _, event := range events {
+		if event["type"] != types.EventTypeMeta {
+			continue
+		}
+		var rating string
+		switch event["metaType"] {
+		case "thumbs_up":
+			rating = "positive"
+		case "thumbs_down":
+			rating = "negative"
+		default:
+			continue
+		}
+		timestamp, _ := event["timestamp"].(string)
+		if t, err := time.Parse(time.RFC3339Nano, timestamp); err == nil && t.Before(since) {
+			continue
+		}
+
+		payload, _ := event["payload"].(map[string]interface{})
+		str := func(key string) string {
+			s, _ := payload[key].(string)
+			return s
+		}
+		runID := str("runId")
+		if runID == "" {
+			runID, _ = event["runId"].(string)
+		}
+		rec := FeedbackRecord{
+			Project:   project,
+			Session:   session.GetName(),
+			RunID:     runID,
+			MessageID: str("messageId"),
+			User:      str("userId"),
+			Rating:    rating,
+			Reason:    str("reason"),
+			Comment:   str("comment"),
+			Model:     model,
+			Workflow:  workflow,
+			Tools:     runTools[runID],
+			Timestamp: timestamp,
+		}
+		if w := str("workflow"); w != "" {
+			rec.Workflow = w
+		}
+		records = append(records, rec)
+	}
+	return records
+}
+
+// summarizeFeedback groups records by the keys each maps to and computes rating rates,
+// ordered by number of ratings
+func summarizeFeedback(records []FeedbackRecord, keys func(FeedbackRecord) []string) []FeedbackStats {
+	groups := map[string]*FeedbackStats{}
+	for _, rec := range records {
+		for _, key := range keys(rec) {
+			g, ok := groups[key]
+			if !ok {
+				g = &FeedbackStats{Name: key}
+				groups[key] = g
+			}
+			g.Total++
+			if rec.Rating == "positive" {
+				g.Positive++
+			} else {
+				g.Negative++
+			}
+		}
+	}
+
+	out := make([]FeedbackStats, 0, len(groups))
+	for _, g := range groups {
+		g.PositiveRate = math.Round(float64(g.Positive)/float64(g.Total)*1000) / 1000
+		g.NegativeRate = math.Round(float64(g.Negative)/float64(g.Total)*1000) / 1000
+		out = append(out, *g)
+	}
+	sort.Slice(out, func(a, b int) bool {
+		if out[a].Total != out[b].Total {
+			return out[a].Total > out[b].Total
+		}
+		return out[a].Name < out[b].Name
+	})
+	return out
+}
+
+// orNone labels records missing a grouping value
+func orNone(v string) []string {
+	if v == "" {
+		return []string{"(none)"}
+	}
+	return []string{v}
+}
+
+// loadProjectFeedback authorizes the request and collects the project's feedback of
+// the last ?days= days (default 30), oldest first. It writes the error response
+// itself and returns ok=false on failure.
+func loadProjectFeedback(c *gin.Context) ([]FeedbackRecord, time.Time, bool) {
+	projectName := c.Param("projectName")
+
+	reqK8s, _ := handlers.GetK8sClientsForRequest(c)
+	if reqK8s == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing token"})
+		c.Abort()
+		return nil, time.Time{}, false
+	}
+
+	// SECURITY: Verify user can list sessions in this project
+	allowed, err := handlers.CheckAccessForRequest(c, reqK8s, authv1.ResourceAttributes{
+		Group:     "vteam.ambient-code",
+		Resource:  "agenticsessions",
+		Verb:      "list",
+		Namespace: projectName,
+	})
+	if err != nil || !allowed {
+		logging.Warnf(c, "Feedback analytics: User not authorized to list sessions in %s", projectName)
+		c.JSON(http.StatusForbidden, gin.H{"error": "Unauthorized"})
+		c.Abort()
+		return nil, time.Time{}, false
+	}
+	// Feedback comments and reasons are conversation content: require transcripts
+	// access to every session in the project, like project-wide exports
+	allowed, err = handlers.CheckAccessForRequest(c, reqK8s, authv1.ResourceAttributes{
+		Group:       "vteam.ambient-code",
+		Resource:    "agenticsessions",
+		Subresource: handlers.TranscriptsSubresource,
+		Verb:        "get",
+		Namespace:   projectName,
+	})
+	if err != nil || !allowed {
+		logging.Warnf(c, "Feedback analytics: User not authorized to read transcripts in %s", projectName)
+		c.JSON(http.StatusForbidden, gin.H{"error": "Unauthorized"})
+		c.Abort()
+		return nil, time.Time{}, false
+	}
+	if handlers.DynamicClient == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Kubernetes client not initialized"})
+		return nil, time.Time{}, false
+	}
+
+	days := 30
+	if v := c.Query("days"); v != "" {
+		if _, err := fmt.Sscanf(v, "%d", &days); err != nil || days < 1 || days > 365 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "days must be between 1 and 365"})
+			return nil, time.Time{}, false
+		}
+	}
+	since := time.Now().Add(-time.Duration(days) * 24 * time.Hour)
+
+	list, err := handlers.DynamicClient.Resource(handlers.GetAgenticSessionV1Alpha1Resource()).Namespace(projectName).List(c.Request.Context(), metav1.ListOptions{})
+	if err != nil {
+		logging.Errorf(c, "Feedback analytics: failed to list sessions in %s: %v", projectName, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list sessions"})
+		return nil, time.Time{}, false
+	}
+
+	var records []FeedbackRecord
+	for i := range list.Items {
+		session := &list.Items[i]
+		if !isValidSessionName(session.GetName()) {
+			continue
+		}
+		events, err := readJSONLFile(fmt.Sprintf("%s/sessions/%s/agui-events.jsonl", StateBaseDir, session.GetName()), session.GetName())
+		if err != nil {
+			if !os.IsNotExist(err) {
+				logging.Warnf(c, "Feedback analytics: failed to read events of %s: %v", session.GetName(), err)
+			}
+			continue
+		}
+		records = append(records, sessionFeedback(projectName, session, events, since)...)
+	}
+	sort.SliceStable(records, func(a, b int) bool { return records[a].Timestamp < records[b].Timestamp })
+	return records, since, true
+}
+
+// HandleFeedbackAnalytics reports thumbs up/down rates for a project
+// GET /api/projects/:projectName/feedback/analytics?days=30
+func HandleFeedbackAnalytics(c *gin.Context) {
+	records, since, ok := loadProjectFeedback(c)
+	if !ok {
+		return
+	}
+
+	overall := summarizeFeedback(records, func(FeedbackRecord) []string { return []string{"all"} })
+	c.JSON(http.StatusOK, gin.H{
+		"since":     since.UTC().Format(time.RFC3339),
+		"total":     len(records),
+		"overall":   overall,
+		"models":    summarizeFeedback(records, func(r FeedbackRecord) []string { return orNone(r.Model) }),
+		"tools":     summarizeFeedback(records, func(r FeedbackRecord) []string { return r.Tools }),
+		"workflows": summarizeFeedback(records, func(r FeedbackRecord) []string { return orNone(r.Workflow) }),
+	})
+}
+
+// HandleFeedbackExport downloads a project's feedback records
+// GET /api/projects/:projectName/feedback/export?format=csv|jsonl&days=30
+func HandleFeedbackExport(c *gin.Context) {
+	format := c.DefaultQuery("format", "jsonl")
+	if format != "csv" && format != "jsonl" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "format must be csv or jsonl"})
+		return
+	}
+	records, _, ok := loadProjectFeedback(c)
+	if !ok {
+		return
+	}
+
+	filename := fmt.Sprintf("%s-feedback.%s", c.Param("projectName"), format)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filename))
+
+	if format == "jsonl" {
+		c.Header("Content-Type", "application/x-ndjson")
+		c.Status(http.StatusOK)
+		enc := json.NewEncoder(c.Writer)
+		for _, rec := range records {
+			if err := enc.Encode(rec); err != nil {
+				logging.Errorf(c, "Feedback export: failed to write record: %v", err)
+				return
+			}
+		}
+		return
+	}
+
+	c.Header("Content-Type", "text/csv")
+	c.Status(http.StatusOK)
+	w := csv.NewWriter(c.Writer)
+	_ = w.Write([]string{"timestamp", "session", "runId", "messageId", "user", "rating", "reason", "comment", "model", "workflow", "tools"})
+	for _, rec := range records {
+		row := []string{rec.Timestamp, rec.Session, rec.RunID, rec.MessageID, rec.User, rec.Rating,
+			rec.Reason, rec.Comment, rec.Model, rec.Workflow, strings.Join(rec.Tools, ";")}
+		for i := range row {
+			row[i] = csvSafe(row[i])
+		}
+		_ = w.Write(row)
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		logging.Errorf(c, "Feedback export: failed to write CSV: %v", err)
+	}
+}
+
+// csvSafe keeps a user-supplied cell from being evaluated as a formula by spreadsheet
+// applications, by prefixing cells that start with a formula character with '
+func csvSafe(cell string) string {
+	if cell != "" && strings.ContainsRune("=+-@\t\r", rune(cell[0])) {
+		return "'" + cell
+	}
+	return cell
+}
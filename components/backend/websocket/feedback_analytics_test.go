@@ -0,0 +1,21 @@
+package websocket
+
+import "testing"
+
+func TestCSVSafe(t *testing.T) {
+	cases := map[string]string{
+		"":                     "",
+		"great answer":         "great answer",
+		"=HYPERLINK(\"x\")":    "'=HYPERLINK(\"x\")",
+		"+1":                   "'+1",
+		"-2+3":                 "'-2+3",
+		"@SUM(A1)":             "'@SUM(A1)",
+		"\t=1":                 "'\t=1",
+		"2026-01-01T00:00:00Z": "2026-01-01T00:00:00Z",
+	}
+	for in, want := range cases {
+		if got := csvSafe(in); got != want {
+			t.Errorf("csvSafe(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package websocket
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"path"
+	"strings"
+
+	"ambient-code-backend/config"
+	"ambient-code-backend/gdrive"
+	"ambient-code-backend/handlers"
+	"ambient-code-backend/logging"
+	"ambient-code-backend/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HandleImportGoogleDoc handles POST /api/projects/:projectName/agentic-sessions/:sessionName/context/google-drive
+// Reads a Google Doc (exported as markdown) or Drive text file with the caller's stored
+// Google credentials. By default it is stored as an attachment, wrapped in a
+// <google-doc> block, for the next run message to reference; with "as": "file" it is
+// written to the session workspace instead.
+func HandleImportGoogleDoc(c *gin.Context) {
+	projectName := c.Param("projectName")
+	sessionName := c.Param("sessionName")
+
+	var req struct {
+		Document string `json:"document" binding:"required"`
+		As       string `json:"as"`
+		Path     string `json:"path"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "document is required"})
+		return
+	}
+	fileID, err := gdrive.ParseFileID(req.Document)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "document: " + err.Error()})
+		return
+	}
+	maxBytes := config.Current().MaxAttachmentBytes
+	switch req.As {
+	case "", "context":
+		if maxBytes == 0 {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Attachments are disabled; import the document as a file"})
+			return
+		}
+	case "file":
+	default:
+		c.JSON(http.StatusBadRequest, gin.H{"error": `as must be "context" or "file"`})
+		return
+	}
+
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User authentication required"})
+		return
+	}
+	creds, err := handlers.GetFreshGoogleCredentials(c.Request.Context(), userID)
+	if err != nil {
+		logging.Errorf(c, "Google Drive: failed to get credentials for user %s: %v", userID, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to get Google credentials"})
+		return
+	}
+	if creds == nil {
+		c.JSON(http.StatusPreconditionFailed, gin.H{"error": "Google is not connected; connect it to import documents"})
+		return
+	}
+
+	doc, err := gdrive.NewClient(creds.AccessToken).GetDocument(c.Request.Context(), fileID)
+	switch {
+	case err == nil:
+	case errors.Is(err, gdrive.ErrUnauthorized):
+		c.JSON(http.StatusPreconditionFailed, gin.H{"error": "Google rejected your credentials or they lack Drive access; reconnect Google"})
+		return
+	case errors.Is(err, gdrive.ErrNotFound):
+		c.JSON(http.StatusNotFound, gin.H{"error": "Drive document " + fileID + " not found"})
+		return
+	case errors.Is(err, gdrive.ErrUnsupported):
+		c.JSON(http.StatusUnsupportedMediaType, gin.H{"error": err.Error()})
+		return
+	case errors.Is(err, gdrive.ErrTooLarge):
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": err.Error()})
+		return
+	default:
+		logging.Infof(c, "Google Drive: failed to fetch %s: %v", fileID, err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to fetch Drive document: " + err.Error()})
+		return
+	}
+
+	if req.As == "file" {
+		importGoogleDocFile(c, projectName, sessionName, req.Path, doc)
+		return
+	}
+
+	content := []byte(doc.Context())
+	if len(content) > maxBytes {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("Document is larger than the %d byte attachment limit; import it as a file", maxBytes)})
+		return
+	}
+	meta, err := saveAttachment(sessionName, doc.Filename(), "text/markdown", userID, content)
+	if err != nil {
+		logging.Errorf(c, "Google Drive: failed to store %s for %s: %v", fileID, sessionName, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store attachment"})
+		return
+	}
+	logging.Infof(c, "Google Drive: attached %s to %s/%s", fileID, projectName, sessionName)
+	c.JSON(http.StatusCreated, gin.H{
+		"document":   doc,
+		"attachment": types.Attachment{ID: meta.ID, Filename: meta.Filename, MimeType: meta.MimeType, Size: meta.Size},
+	})
+}
+
+// importGoogleDocFile writes the document's markdown to the session workspace at
+// relPath, by default a file named after the document at the workspace root
+func importGoogleDocFile(c *gin.Context, projectName, sessionName, relPath string, doc *gdrive.Document) {
+	if strings.TrimSpace(relPath) == "" {
+		relPath = doc.Filename()
+	}
+	// Cleaning against the root keeps the path inside the workspace
+	p := path.Clean("/" + relPath)
+	if p == "/" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "path must name a file"})
+		return
+	}
+	relPath = p[1:]
+
+	authorization := c.GetHeader("Authorization")
+	if strings.TrimSpace(authorization) == "" {
+		authorization = c.GetHeader("X-Forwarded-Access-Token")
+	}
+	status, _, body, err := handlers.WriteSessionWorkspaceFile(c.Request.Context(), projectName, sessionName, relPath, []byte(doc.Markdown), "text/markdown", authorization)
+	if err != nil {
+		logging.Infof(c, "Google Drive: content service unreachable for %s/%s: %v", projectName, sessionName, err)
+		c.JSON(http.StatusConflict, gin.H{"error": "Session is not running. Start the session to write workspace files."})
+		return
+	}
+	if status >= 400 {
+		logging.Errorf(c, "Google Drive: content service returned %d writing %s: %s", status, relPath, string(body))
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to write the document to the workspace"})
+		return
+	}
+	logging.Infof(c, "Google Drive: wrote %s to %s/%s:%s", doc.ID, projectName, sessionName, relPath)
+	c.JSON(http.StatusCreated, gin.H{"document": doc, "path": relPath})
+}
@@ -0,0 +1,204 @@
+package websocket
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"ambient-code-backend/audit"
+	"ambient-code-backend/logging"
+	"ambient-code-backend/types"
+)
+
+// guardrailViolationEvent is the RAW event subtype emitted when streamed output
+// matches a rule of the project's guardrail policy
+const guardrailViolationEvent = "guardrail_violation"
+
+// maxGuardrailHeldText bounds the unfinished word held back from a text message, so a
+// long run of text without whitespace still streams
+const maxGuardrailHeldText = 2048
+
+// guardrailFinding is what matched in one inspected event
+type guardrailFinding struct {
+	messageID  string
+	toolCallID string
+	matches    []types.GuardrailMatch
+}
+
+// applyGuardrails runs the run's guardrail policy over a streamed event. It returns
+// the events to persist and broadcast in its place, with redact matches replaced.
+//
+// Assistant text streams in small deltas, so a secret may be split across several.
+// While output is inspected, each message's trailing unfinished word is held back
+// and prepended to the next delta; held text is released before any other event.
+// Matches spanning whitespace across deltas can still be missed.
+func applyGuardrails(event *types.Event, runState *AGUIRunState) ([]*types.Event, []guardrailFinding) {
+	if runState == nil || runState.guardrails == nil {
+		return []*types.Event{event}, nil
+	}
+	engine := runState.guardrails
+
+	if content, ok := event.Payload.(*types.TextMessageContentEvent); ok && engine.Inspects(types.GuardrailTargetOutput) {
+		text := runState.heldText[content.MessageID] + content.Delta
+		ready, held := splitHeldText(text)
+		if runState.heldText == nil {
+			runState.heldText = map[string]string{}
+		}
+		runState.heldText[content.MessageID] = held
+		if ready == "" {
+			return nil, nil
+		}
+		finding := redactEventField(event, "delta", &content.Delta, ready, types.GuardrailTargetOutput, engine, runState)
+		finding.messageID = content.MessageID
+		return []*types.Event{event}, nonEmptyFindings(finding)
+	}
+
+	events, findings := flushHeldText(runState)
+	switch e := event.Payload.(type) {
+	case *types.ToolCallArgsEvent:
+		finding := redactEventField(event, "delta", &e.Delta, e.Delta, types.GuardrailTargetToolArgs, engine, runState)
+		finding.toolCallID = e.ToolCallID
+		findings = append(findings, nonEmptyFindings(finding)...)
+	case *types.ToolCallEndEvent:
+		if e.Result != "" {
+			finding := redactEventField(event, "result", &e.Result, e.Result, types.GuardrailTargetToolResult, engine, runState)
+			finding.toolCallID = e.ToolCallID
+			findings = append(findings, nonEmptyFindings(finding)...)
+		}
+	}
+	return append(events, event), findings
+}
+
+// splitHeldText splits text after its last whitespace, into the part that can be
+// inspected now and the unfinished word to hold back
+func splitHeldText(text string) (string, string) {
+	i := strings.LastIndexFunc(text, unicode.IsSpace)
+	if i < 0 {
+		if len(text) > maxGuardrailHeldText {
+			return text, ""
+		}
+		return "", text
+	}
+	_, size := utf8.DecodeRuneInString(text[i:])
+	end := i + size
+	if len(text)-end > maxGuardrailHeldText {
+		return text, ""
+	}
+	return text[:end], text[end:]
+}
+
+// flushHeldText returns content events releasing the text held back from every message
+func flushHeldText(runState *AGUIRunState) ([]*types.Event, []guardrailFinding) {
+	var events []*types.Event
+	var findings []guardrailFinding
+	for messageID, held := range runState.heldText {
+		if held == "" {
+			continue
+		}
+		redacted, matches := runState.guardrails.Inspect(types.GuardrailTargetOutput, held)
+		event, err := types.NewEvent(&types.TextMessageContentEvent{
+			BaseEvent: types.NewBaseEvent(types.EventTypeTextMessageContent, runState.ThreadID, runState.RunID).WithMessageID(messageID),
+			Delta:     redacted,
+		})
+		if err != nil {
+			logging.Errorf(runState.logContext(), "AGUI Proxy: failed to build held text event: %v", err)
+			continue
+		}
+		events = append(events, event)
+		findings = append(findings, nonEmptyFindings(guardrailFinding{messageID: messageID, matches: matches})...)
+	}
+	runState.heldText = nil
+	return events, findings
+}
+
+// redactEventField inspects text and, when a redact rule matched, writes the redacted
+// text to the event field. text may differ from *field when held text was prepended.
+func redactEventField(event *types.Event, key string, field *string, text, target string, engine *types.GuardrailEngine, runState *AGUIRunState) guardrailFinding {
+	redacted, matches := engine.Inspect(target, text)
+	if redacted != *field {
+		*field = redacted
+		if err := event.SetField(key, redacted); err != nil {
+			logging.Errorf(runState.logContext(), "AGUI Proxy: failed to rewrite %s event: %v", event.Type(), err)
+		}
+	}
+	return guardrailFinding{matches: matches}
+}
+
+func nonEmptyFindings(f guardrailFinding) []guardrailFinding {
+	if len(f.matches) == 0 {
+		return nil
+	}
+	return []guardrailFinding{f}
+}
+
+// reportGuardrailFindings records each finding as a violation event on the run and in
+// the audit log, and interrupts the run when an interrupt rule matched
+func reportGuardrailFindings(sessionID, runID, threadID string, findings []guardrailFinding, runState *AGUIRunState) {
+	interrupt := false
+	for _, f := range findings {
+		for _, m := range f.matches {
+			logging.Warnf(runState.logContext(), "AGUI Proxy: guardrail %q (%s) matched %s in %s/%s run %s: %s",
+				m.Rule, m.Kind, m.Target, runState.ProjectName, sessionID, runID, m.Action)
+			outcome := audit.OutcomeFlagged
+			if m.Action == types.GuardrailActionInterrupt {
+				outcome = audit.OutcomeDenied
+				interrupt = true
+			}
+			audit.Emit(audit.Record{
+				Time:      time.Now().UTC(),
+				RequestID: runState.RequestID,
+				Verb:      "GUARDRAIL",
+				Resource:  "/api/projects/:projectName/agentic-sessions/:sessionName/agui/run",
+				Path:      fmt.Sprintf("/api/projects/%s/agentic-sessions/%s/agui/run", runState.ProjectName, sessionID),
+				Project:   runState.ProjectName,
+				Outcome:   outcome,
+				Detail:    fmt.Sprintf("run=%s rule=%s kind=%s target=%s action=%s matches=%d", runID, m.Rule, m.Kind, m.Target, m.Action, m.Count),
+			})
+		}
+
+		violation, err := types.NewEvent(&types.RawEvent{
+			BaseEvent: types.NewBaseEvent(types.EventTypeRaw, threadID, runID),
+			Event: map[string]interface{}{
+				"type":       guardrailViolationEvent,
+				"messageId":  f.messageID,
+				"toolCallId": f.toolCallID,
+				"matches":    f.matches,
+				"message":    guardrailMessage(f.matches),
+			},
+		})
+		if err != nil {
+			logging.Errorf(runState.logContext(), "AGUI Proxy: failed to build guardrail violation event: %v", err)
+			continue
+		}
+		persistAGUIEvent(sessionID, runID, violation)
+		runState.BroadcastFull(violation)
+		broadcastToThread(sessionID, violation)
+	}
+
+	if interrupt && !runState.guardrailInterrupted {
+		runState.guardrailInterrupted = true
+		go interruptRunner(runState.logContext(), runState.ProjectName, sessionID)
+	}
+}
+
+// guardrailMessage describes matches for display
+func guardrailMessage(matches []types.GuardrailMatch) string {
+	rules := make([]string, len(matches))
+	action := types.GuardrailActionWarn
+	for i, m := range matches {
+		rules[i] = m.Rule
+		if m.Action == types.GuardrailActionInterrupt || (m.Action == types.GuardrailActionRedact && action == types.GuardrailActionWarn) {
+			action = m.Action
+		}
+	}
+	switch action {
+	case types.GuardrailActionInterrupt:
+		return fmt.Sprintf("Run interrupted by guardrail %s", strings.Join(rules, ", "))
+	case types.GuardrailActionRedact:
+		return fmt.Sprintf("Output redacted by guardrail %s", strings.Join(rules, ", "))
+	default:
+		return fmt.Sprintf("Output matched guardrail %s", strings.Join(rules, ", "))
+	}
+}
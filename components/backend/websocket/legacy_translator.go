@@ -1,13 +1,13 @@
 package websocket
 
 import (
+	"ambient-code-backend/logging"
 	"ambient-code-backend/types"
+	"context"
 	"crypto/rand"
 	"encoding/hex"
 	"encoding/json"
-	"log"
 	"os"
-	"time"
 )
 
 // MigrateLegacySessionToAGUI converts old message format to AG-UI events
@@ -24,7 +24,7 @@ func MigrateLegacySessionToAGUI(sessionID string) error {
 		return err
 	}
 
-	log.Printf("LegacyMigration: Found legacy messages.jsonl for %s, converting to AG-UI", sessionID)
+	logging.Infof(context.Background(), "LegacyMigration: Found legacy messages.jsonl for %s, converting to AG-UI", sessionID)
 
 	// Parse JSONL - each line is a complete message
 	var legacyMessages []map[string]interface{}
@@ -75,32 +75,32 @@ func MigrateLegacySessionToAGUI(sessionID string) error {
 	}
 
 	if len(messages) == 0 {
-		log.Printf("LegacyMigration: No chat messages found in legacy file")
+		logging.Infof(context.Background(), "LegacyMigration: No chat messages found in legacy file")
 		return nil
 	}
 
-	log.Printf("LegacyMigration: Converted %d legacy messages to AG-UI format", len(messages))
+	logging.Infof(context.Background(), "LegacyMigration: Converted %d legacy messages to AG-UI format", len(messages))
 
 	// Create MESSAGES_SNAPSHOT event and persist it
-	snapshot := map[string]interface{}{
-		"type":      types.EventTypeMessagesSnapshot,
-		"threadId":  sessionID,
-		"runId":     "legacy-migration",
-		"timestamp": time.Now().UTC().Format(types.AGUITimestampFormat),
-		"messages":  messages,
+	snapshot, err := types.NewEvent(&types.MessagesSnapshotEvent{
+		BaseEvent: types.NewBaseEvent(types.EventTypeMessagesSnapshot, sessionID, "legacy-migration"),
+		Messages:  messages,
+	})
+	if err != nil {
+		return err
 	}
 
 	// Persist to agui-events.jsonl
-	persistAGUIEventMap(sessionID, "legacy-migration", snapshot)
+	persistAGUIEvent(sessionID, "legacy-migration", snapshot)
 
-	log.Printf("LegacyMigration: Persisted MESSAGES_SNAPSHOT with %d messages", len(messages))
+	logging.Infof(context.Background(), "LegacyMigration: Persisted MESSAGES_SNAPSHOT with %d messages", len(messages))
 
 	// Rename legacy file to indicate it's been migrated
 	migratedPath := legacyPath + ".migrated"
 	if err := os.Rename(legacyPath, migratedPath); err != nil {
-		log.Printf("LegacyMigration: Warning - failed to rename legacy file: %v", err)
+		logging.Warnf(context.Background(), "LegacyMigration: failed to rename legacy file: %v", err)
 	} else {
-		log.Printf("LegacyMigration: Renamed %s to %s", legacyPath, migratedPath)
+		logging.Infof(context.Background(), "LegacyMigration: Renamed %s to %s", legacyPath, migratedPath)
 	}
 
 	return nil
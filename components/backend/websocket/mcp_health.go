@@ -0,0 +1,275 @@
+package websocket
+
+import (
+	"ambient-code-backend/handlers"
+	"ambient-code-backend/logging"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	authv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	// mcpStatusCacheTTL is how long a runner's MCP status is reused before re-probing.
+	// Probing starts an SDK client in the runner, so it is too expensive to do per request.
+	mcpStatusCacheTTL = 2 * time.Minute
+	// mcpStatusMaxProbes bounds concurrent runner probes per aggregate request
+	mcpStatusMaxProbes = 5
+)
+
+// mcpServerStatus is one server as reported by a runner's /mcp/status
+type mcpServerStatus struct {
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName,omitempty"`
+	Status      string `json:"status"`
+	Version     string `json:"version,omitempty"`
+}
+
+// mcpStatusSnapshot is the last-known MCP status of one session's runner
+type mcpStatusSnapshot struct {
+	Servers   []mcpServerStatus
+	CheckedAt time.Time
+	Error     string
+}
+
+var (
+	mcpStatusCacheMu sync.RWMutex
+	mcpStatusCache   = map[string]mcpStatusSnapshot{} // key: project/session
+)
+
+func mcpStatusCacheKey(projectName, sessionName string) string {
+	return projectName + "/" + sessionName
+}
+
+// recordMCPStatus caches a runner's /mcp/status response
+func recordMCPStatus(projectName, sessionName string, result map[string]interface{}) {
+	snap := mcpStatusSnapshot{CheckedAt: time.Now()}
+	if b, err := json.Marshal(result["servers"]); err == nil {
+		_ = json.Unmarshal(b, &snap.Servers)
+	}
+	if e, ok := result["error"].(string); ok {
+		snap.Error = e
+	}
+	mcpStatusCacheMu.Lock()
+	mcpStatusCache[mcpStatusCacheKey(projectName, sessionName)] = snap
+	mcpStatusCacheMu.Unlock()
+}
+
+// fetchRunnerMCPStatus queries a session runner's /mcp/status endpoint
+func fetchRunnerMCPStatus(ctx context.Context, projectName, sessionName string) (map[string]interface{}, error) {
+	runnerURL, err := getRunnerEndpoint(projectName, sessionName)
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(runnerURL, "/")+"/mcp/status", nil)
+	if err != nil {
+		return nil, err
+	}
+	logging.SetRequestIDHeader(req)
+	resp, err := runnerClient(30 * time.Second).Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer closeRunnerResponse(resp)
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("runner returned %d: %s", resp.StatusCode, string(body))
+	}
+	var result map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to parse runner response: %w", err)
+	}
+	return result, nil
+}
+
+// mcpServerHealth is the aggregated health of one MCP server across a project
+type mcpServerHealth struct {
+	Name       string                  `json:"name"`
+	Configured bool                    `json:"configured"`
+	Enabled    bool                    `json:"enabled"`
+	Transport  string                  `json:"transport,omitempty"`
+	Health     string                  `json:"health"` // healthy, degraded, failing, unknown
+	Counts     map[string]int          `json:"counts"`
+	LastSeen   string                  `json:"lastSeen,omitempty"`
+	Sessions   []mcpServerSessionState `json:"sessions"`
+}
+
+type mcpServerSessionState struct {
+	Session   string `json:"session"`
+	Status    string `json:"status"`
+	CheckedAt string `json:"checkedAt"`
+}
+
+// aggregateMCPHealth merges configured servers with observed runner status.
+// Servers seen on runners but not in the project registry (e.g. platform
+// defaults) are included with configured=false.
+func aggregateMCPHealth(configured map[string]mcpServerHealth, snapshots map[string]mcpStatusSnapshot) []mcpServerHealth {
+	byName := map[string]*mcpServerHealth{}
+	for name, h := range configured {
+		h := h
+		h.Counts = map[string]int{}
+		h.Sessions = []mcpServerSessionState{}
+		byName[name] = &h
+	}
+	for session, snap := range snapshots {
+		for _, s := range snap.Servers {
+			h, ok := byName[s.Name]
+			if !ok {
+				h = &mcpServerHealth{Name: s.Name, Enabled: true, Counts: map[string]int{}, Sessions: []mcpServerSessionState{}}
+				byName[s.Name] = h
+			}
+			status := s.Status
+			if status == "" {
+				status = "unknown"
+			}
+			h.Counts[status]++
+			checkedAt := snap.CheckedAt.UTC().Format(time.RFC3339)
+			h.Sessions = append(h.Sessions, mcpServerSessionState{Session: session, Status: status, CheckedAt: checkedAt})
+			if checkedAt > h.LastSeen {
+				h.LastSeen = checkedAt
+			}
+		}
+	}
+
+	out := make([]mcpServerHealth, 0, len(byName))
+	for _, h := range byName {
+		total := 0
+		for _, n := range h.Counts {
+			total += n
+		}
+		switch {
+		case total == 0:
+			h.Health = "unknown"
+		case h.Counts["connected"] == total:
+			h.Health = "healthy"
+		case h.Counts["connected"] == 0:
+			h.Health = "failing"
+		default:
+			h.Health = "degraded"
+		}
+		sort.Slice(h.Sessions, func(a, b int) bool { return h.Sessions[a].Session < h.Sessions[b].Session })
+		out = append(out, *h)
+	}
+	sort.Slice(out, func(a, b int) bool { return out[a].Name < out[b].Name })
+	return out
+}
+
+// HandleProjectMCPStatus aggregates MCP server health across a project's running sessions
+// GET /api/projects/:projectName/mcp/status?refresh=true
+func HandleProjectMCPStatus(c *gin.Context) {
+	projectName := c.Param("projectName")
+
+	reqK8s, _ := handlers.GetK8sClientsForRequest(c)
+	if reqK8s == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing token"})
+		c.Abort()
+		return
+	}
+
+	// SECURITY: Verify user can list sessions in this project
+	ctx := c.Request.Context()
+	allowed, err := handlers.CheckAccessForRequest(c, reqK8s, authv1.ResourceAttributes{
+		Group:     "vteam.ambient-code",
+		Resource:  "agenticsessions",
+		Verb:      "list",
+		Namespace: projectName,
+	})
+	if err != nil || !allowed {
+		logging.Warnf(c, "MCP Status: User not authorized to list sessions in %s", projectName)
+		c.JSON(http.StatusForbidden, gin.H{"error": "Unauthorized"})
+		c.Abort()
+		return
+	}
+	if handlers.DynamicClient == nil || handlers.K8sClient == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Kubernetes client not initialized"})
+		return
+	}
+
+	configured := map[string]mcpServerHealth{}
+	registry, err := handlers.LoadProjectMCPServers(ctx, handlers.K8sClient, projectName)
+	if err != nil {
+		logging.Errorf(c, "MCP Status: failed to load MCP registry for %s: %v", projectName, err)
+	}
+	for _, s := range registry {
+		configured[s.Name] = mcpServerHealth{Name: s.Name, Configured: true, Enabled: s.Enabled, Transport: s.Transport}
+	}
+
+	list, err := handlers.DynamicClient.Resource(handlers.GetAgenticSessionV1Alpha1Resource()).Namespace(projectName).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		logging.Errorf(c, "MCP Status: failed to list sessions in %s: %v", projectName, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list sessions"})
+		return
+	}
+
+	refresh := c.Query("refresh") == "true"
+	running := map[string]bool{}
+	var toProbe []string
+	for _, item := range list.Items {
+		phase, _, _ := unstructured.NestedString(item.Object, "status", "phase")
+		if phase != "Running" {
+			continue
+		}
+		running[item.GetName()] = true
+		mcpStatusCacheMu.RLock()
+		snap, cached := mcpStatusCache[mcpStatusCacheKey(projectName, item.GetName())]
+		mcpStatusCacheMu.RUnlock()
+		if refresh || !cached || time.Since(snap.CheckedAt) > mcpStatusCacheTTL {
+			toProbe = append(toProbe, item.GetName())
+		}
+	}
+
+	probeCtx, cancel := context.WithTimeout(ctx, 45*time.Second)
+	defer cancel()
+	sem := make(chan struct{}, mcpStatusMaxProbes)
+	var wg sync.WaitGroup
+	for _, session := range toProbe {
+		wg.Add(1)
+		go func(session string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			result, err := fetchRunnerMCPStatus(probeCtx, projectName, session)
+			if err != nil {
+				// Keep the last-known state; the runner may be busy or restarting
+				logging.Errorf(context.Background(), "MCP Status: probe of %s/%s failed: %v", projectName, session, err)
+				return
+			}
+			recordMCPStatus(projectName, session, result)
+		}(session)
+	}
+	wg.Wait()
+
+	// Use last-known state for running sessions only; stopped sessions no longer reflect reality
+	snapshots := map[string]mcpStatusSnapshot{}
+	mcpStatusCacheMu.Lock()
+	for key, snap := range mcpStatusCache {
+		session, ok := strings.CutPrefix(key, projectName+"/")
+		if !ok {
+			continue
+		}
+		if !running[session] {
+			delete(mcpStatusCache, key)
+			continue
+		}
+		snapshots[session] = snap
+	}
+	mcpStatusCacheMu.Unlock()
+
+	servers := aggregateMCPHealth(configured, snapshots)
+	c.JSON(http.StatusOK, gin.H{
+		"servers":         servers,
+		"totalCount":      len(servers),
+		"runningSessions": len(running),
+		"probedSessions":  len(toProbe),
+	})
+}
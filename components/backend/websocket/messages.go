@@ -0,0 +1,86 @@
+package websocket
+
+import (
+	"net/http"
+
+	"ambient-code-backend/logging"
+	"ambient-code-backend/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ThreadMessages reconstructs a thread's conversation from its persisted events in
+// the shape RunAgentInput.Messages expects: tool calls carry only id, name and
+// arguments, and each call is answered by a following "tool" message holding its
+// result. Hidden messages (auto-sent prompts) are kept since the agent saw them;
+// sub-agent tool calls and calls still in progress are left out.
+func ThreadMessages(events []map[string]interface{}) []types.Message {
+	compactor := NewMessageCompactor()
+	for _, event := range events {
+		compactor.HandleEvent(event)
+	}
+
+	compacted := compactor.GetAllMessages()
+	messages := make([]types.Message, 0, len(compacted))
+	for _, msg := range compacted {
+		out := types.Message{
+			ID:         msg.ID,
+			Role:       msg.Role,
+			Content:    msg.Content,
+			ToolCallID: msg.ToolCallID,
+			Name:       msg.Name,
+			Timestamp:  msg.Timestamp,
+		}
+		var results []types.Message
+		for _, tc := range msg.ToolCalls {
+			if tc.ParentToolUseID != "" {
+				continue
+			}
+			out.ToolCalls = append(out.ToolCalls, types.ToolCall{
+				ID:   tc.ID,
+				Name: tc.Name,
+				Args: tc.Args,
+				Type: "function",
+			})
+			content := tc.Result
+			if tc.Error != "" {
+				content = tc.Error
+			}
+			results = append(results, types.Message{
+				ID:         tc.ID + "-result",
+				Role:       types.RoleTool,
+				Content:    content,
+				ToolCallID: tc.ID,
+			})
+		}
+		if out.Content == "" && len(out.ToolCalls) == 0 && out.Role != types.RoleTool {
+			// Only sub-agent calls: nothing left the agent said at this level
+			continue
+		}
+		messages = append(messages, out)
+		messages = append(messages, results...)
+	}
+	return messages
+}
+
+// HandleAGUIMessages handles GET /api/projects/:projectName/agentic-sessions/:sessionName/agui/messages
+// Returns the thread's messages, ready to send back as RunAgentInput.Messages
+func HandleAGUIMessages(c *gin.Context) {
+	sessionName := c.Param("sessionName")
+	if !isValidSessionName(sessionName) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session name"})
+		return
+	}
+
+	events, err := loadEventsForRun(sessionName, "")
+	if err != nil {
+		logging.Errorf(c, "AGUIMessages: Failed to load events for %s: %v", sessionName, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load session events"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"threadId": sessionName,
+		"messages": ThreadMessages(events),
+	})
+}
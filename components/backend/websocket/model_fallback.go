@@ -0,0 +1,139 @@
+package websocket
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"ambient-code-backend/handlers"
+	"ambient-code-backend/logging"
+	"ambient-code-backend/types"
+
+	"github.com/google/uuid"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// modelFallbackEvent is the RAW event subtype emitted when a run is resubmitted with
+// the next model of its fallback chain
+const modelFallbackEvent = "MODEL_FALLBACK"
+
+// modelUnavailableCodes are RUN_ERROR codes meaning another model may succeed.
+// IMPORTANT: Keep in sync with runner (claude-code-runner/utils.py)
+var modelUnavailableCodes = map[string]bool{
+	"rate_limited":      true,
+	"model_unavailable": true,
+	"overloaded":        true,
+}
+
+// modelUnavailableMessages match RUN_ERROR messages of runners that send no code
+var modelUnavailableMessages = []string{
+	"429", "529", "rate limit", "rate_limit", "overloaded", "model_not_found", "not_found_error",
+	"model is not available", "model unavailable",
+}
+
+// isModelUnavailable reports whether a run failed because its model couldn't serve it
+func isModelUnavailable(e *types.RunErrorEvent) bool {
+	if modelUnavailableCodes[e.Code] {
+		return true
+	}
+	message := strings.ToLower(e.Message + " " + e.Error)
+	for _, m := range modelUnavailableMessages {
+		if strings.Contains(message, m) {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveModelChain fills a run's model and fallback chain from the session's
+// llmSettings where the input doesn't set them. A run without fallbacks keeps
+// whatever model it asked for.
+func resolveModelChain(ctx context.Context, projectName, sessionName string, input *types.RunAgentInput) {
+	if input.Model != "" && input.FallbackModels != nil {
+		return
+	}
+	item, err := handlers.GetCachedSession(ctx, projectName, sessionName)
+	if err != nil {
+		logging.Warnf(ctx, "AGUI Proxy: cannot read model settings of %s/%s: %v", projectName, sessionName, err)
+		return
+	}
+	if input.FallbackModels == nil {
+		input.FallbackModels, _, _ = unstructured.NestedStringSlice(item.Object, "spec", "llmSettings", "fallbackModels")
+	}
+	if input.Model == "" && len(input.FallbackModels) > 0 {
+		input.Model, _, _ = unstructured.NestedString(item.Object, "spec", "llmSettings", "model")
+	}
+}
+
+// applyRunModel tells the runner which model to use for the run
+func applyRunModel(input *types.RunAgentInput) {
+	if input.Model == "" {
+		return
+	}
+	props := make(map[string]interface{}, len(input.ForwardedProps)+1)
+	for k, v := range input.ForwardedProps {
+		props[k] = v
+	}
+	props["model"] = input.Model
+	input.ForwardedProps = props
+}
+
+// planModelFallback decides whether a failed run is retried with its next model. If
+// so it records the retry on the run state, to be started once the stream ends, and
+// emits MODEL_FALLBACK on the run so users know what ran.
+func planModelFallback(sessionID, runID, threadID string, e *types.RunErrorEvent, runState *AGUIRunState) {
+	if runState == nil || len(runState.input.FallbackModels) == 0 || !isModelUnavailable(e) {
+		return
+	}
+	retry := runState.input
+	retry.ParentRunID = runID
+	retry.RunID = uuid.New().String()
+	retry.Model = runState.input.FallbackModels[0]
+	retry.FallbackModels = runState.input.FallbackModels[1:]
+
+	reason := e.Message
+	if reason == "" {
+		reason = e.Error
+	}
+	aguiRunsMu.Lock()
+	runState.fallback = &retry
+	aguiRunsMu.Unlock()
+
+	logging.Warnf(runState.logContext(), "AGUI Proxy: model %q unavailable for run %s of %s, retrying with %q as %s",
+		runState.input.Model, runID, sessionID, retry.Model, retry.RunID)
+
+	event, err := types.NewEvent(&types.RawEvent{
+		BaseEvent: types.NewBaseEvent(types.EventTypeRaw, threadID, runID),
+		Event: map[string]interface{}{
+			"type":       modelFallbackEvent,
+			"fromModel":  runState.input.Model,
+			"toModel":    retry.Model,
+			"reason":     reason,
+			"retryRunId": retry.RunID,
+			"message":    fmt.Sprintf("Model %s is unavailable, continuing with %s", runState.input.Model, retry.Model),
+		},
+	})
+	if err != nil {
+		logging.Errorf(runState.logContext(), "AGUI Proxy: failed to build model fallback event: %v", err)
+		return
+	}
+	persistAGUIEvent(sessionID, runID, event)
+	runState.BroadcastFull(event)
+	broadcastToThread(sessionID, event)
+}
+
+// startModelFallback submits the retry planned for a run whose stream has ended
+func startModelFallback(runCtx context.Context, projectName, sessionName string, runState *AGUIRunState) {
+	aguiRunsMu.RLock()
+	retry := runState.fallback
+	aguiRunsMu.RUnlock()
+	if retry == nil {
+		return
+	}
+	// Let the runner finish tearing down the failed run
+	time.Sleep(time.Second)
+	if _, err := startRunStream(runCtx, projectName, sessionName, *retry); err != nil {
+		logging.Errorf(runCtx, "AGUI Proxy: Failed to start model fallback run %s for %s/%s: %v", retry.RunID, projectName, sessionName, err)
+	}
+}
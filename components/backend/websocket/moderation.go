@@ -0,0 +1,260 @@
+package websocket
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"ambient-code-backend/handlers"
+	"ambient-code-backend/logging"
+	"ambient-code-backend/types"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	authv1 "k8s.io/api/authorization/v1"
+)
+
+// contentModeratedEvent is the RAW event subtype emitted when an assistant message
+// was withheld by content moderation
+const contentModeratedEvent = "content_moderated"
+
+// moderationPlaceholder stands in for a withheld message
+const moderationPlaceholder = "[This response was withheld by content moderation.]"
+
+var quarantineFileMu sync.Mutex
+
+func quarantinePath(project string) string {
+	return fmt.Sprintf("%s/quarantine/%s.jsonl", StateBaseDir, project)
+}
+
+// applyModeration holds back assistant text until its message ends, then moderates
+// the whole message before any of it is stored or streamed. A blocked message is
+// replaced by a placeholder and its text quarantined. Other events pass through; a
+// run's terminal event first releases every held message.
+func applyModeration(sessionID, runID, threadID string, events []*types.Event, runState *AGUIRunState) []*types.Event {
+	if runState == nil || runState.moderator == nil {
+		return events
+	}
+	var out []*types.Event
+	for _, event := range events {
+		switch e := event.Payload.(type) {
+		case *types.TextMessageContentEvent:
+			if runState.moderationHeld == nil {
+				runState.moderationHeld = map[string][]*types.Event{}
+			}
+			runState.moderationHeld[e.MessageID] = append(runState.moderationHeld[e.MessageID], event)
+			continue
+		case *types.TextMessageEndEvent:
+			released, notice := releaseModeratedMessage(sessionID, runID, threadID, e.MessageID, runState)
+			out = append(append(out, released...), event)
+			if notice != nil {
+				out = append(out, notice)
+			}
+			continue
+		}
+		if t := event.Type(); t == types.EventTypeRunFinished || t == types.EventTypeRunError {
+			out = append(out, releaseModeratedMessages(sessionID, runID, threadID, runState)...)
+		}
+		out = append(out, event)
+	}
+	return out
+}
+
+// releaseModeratedMessages moderates and releases every held message, e.g. when the
+// run ends without closing them
+func releaseModeratedMessages(sessionID, runID, threadID string, runState *AGUIRunState) []*types.Event {
+	var out []*types.Event
+	for messageID := range runState.moderationHeld {
+		released, notice := releaseModeratedMessage(sessionID, runID, threadID, messageID, runState)
+		out = append(out, released...)
+		if notice != nil {
+			out = append(out, notice)
+		}
+	}
+	return out
+}
+
+// releaseModeratedMessage moderates one held message. It returns the content events
+// to stream in its place, and the moderation notice when the message was withheld.
+func releaseModeratedMessage(sessionID, runID, threadID, messageID string, runState *AGUIRunState) ([]*types.Event, *types.Event) {
+	held := runState.moderationHeld[messageID]
+	delete(runState.moderationHeld, messageID)
+	if len(held) == 0 {
+		return nil, nil
+	}
+	var text strings.Builder
+	for _, event := range held {
+		text.WriteString(event.Payload.(*types.TextMessageContentEvent).Delta)
+	}
+
+	ctx := runState.logContext()
+	verdict, err := runState.moderator.Moderate(ctx, text.String())
+	if err != nil {
+		if runState.moderator.FailOpen {
+			logging.Warnf(ctx, "AGUI Proxy: moderation of message %s in %s/%s failed, delivering it unchecked: %v", messageID, runState.ProjectName, sessionID, err)
+			return held, nil
+		}
+		logging.Errorf(ctx, "AGUI Proxy: moderation of message %s in %s/%s failed, withholding it: %v", messageID, runState.ProjectName, sessionID, err)
+	} else if !verdict.Flagged {
+		return held, nil
+	}
+
+	record := types.QuarantinedContent{
+		ID:          uuid.New().String(),
+		SessionName: sessionID,
+		RunID:       runID,
+		MessageID:   messageID,
+		Text:        text.String(),
+		Provider:    verdict.Provider,
+		Categories:  verdict.Categories,
+		CreatedAt:   time.Now().UTC().Format(time.RFC3339),
+	}
+	if err != nil {
+		record.Error = err.Error()
+	}
+	if qerr := persistQuarantined(runState.ProjectName, record); qerr != nil {
+		logging.Errorf(ctx, "AGUI Proxy: failed to quarantine message %s of %s/%s: %v", messageID, runState.ProjectName, sessionID, qerr)
+	}
+	logging.Warnf(ctx, "AGUI Proxy: message %s in %s/%s run %s withheld by moderation (%s: %s)",
+		messageID, runState.ProjectName, sessionID, runID, verdict.Provider, strings.Join(verdict.Categories, ","))
+
+	placeholder, perr := types.NewEvent(&types.TextMessageContentEvent{
+		BaseEvent: types.NewBaseEvent(types.EventTypeTextMessageContent, threadID, runID).WithMessageID(messageID),
+		Delta:     moderationPlaceholder,
+	})
+	if perr != nil {
+		logging.Errorf(ctx, "AGUI Proxy: failed to build moderation placeholder: %v", perr)
+		return nil, nil
+	}
+	notice, nerr := types.NewEvent(&types.RawEvent{
+		BaseEvent: types.NewBaseEvent(types.EventTypeRaw, threadID, runID),
+		Event: map[string]interface{}{
+			"type":         contentModeratedEvent,
+			"messageId":    messageID,
+			"quarantineId": record.ID,
+			"provider":     verdict.Provider,
+			"categories":   verdict.Categories,
+			"message":      "A response was withheld by content moderation",
+		},
+	})
+	if nerr != nil {
+		logging.Errorf(ctx, "AGUI Proxy: failed to build moderation event: %v", nerr)
+		notice = nil
+	}
+	return []*types.Event{placeholder}, notice
+}
+
+func persistQuarantined(project string, record types.QuarantinedContent) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	quarantineFileMu.Lock()
+	defer quarantineFileMu.Unlock()
+	_ = ensureDir(fmt.Sprintf("%s/quarantine", StateBaseDir))
+	f, err := openFileAppend(quarantinePath(project))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	_, err = f.Write(append(data, '\n'))
+	return err
+}
+
+// loadQuarantined returns the project's quarantined content, newest first
+func loadQuarantined(project string) ([]types.QuarantinedContent, error) {
+	quarantineFileMu.Lock()
+	defer quarantineFileMu.Unlock()
+	data, err := os.ReadFile(quarantinePath(project))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []types.QuarantinedContent{}, nil
+		}
+		return nil, err
+	}
+	records := []types.QuarantinedContent{}
+	lines := splitLines(data)
+	for i := len(lines) - 1; i >= 0; i-- {
+		var r types.QuarantinedContent
+		if len(lines[i]) == 0 || json.Unmarshal(lines[i], &r) != nil {
+			continue
+		}
+		records = append(records, r)
+	}
+	return records, nil
+}
+
+// authorizeQuarantineRead allows project admins, who may change the project's
+// settings, to review withheld content
+func authorizeQuarantineRead(c *gin.Context, projectName string) bool {
+	reqK8s, _ := handlers.GetK8sClientsForRequest(c)
+	if reqK8s == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing token"})
+		c.Abort()
+		return false
+	}
+	allowed, err := handlers.CheckAccessForRequest(c, reqK8s, authv1.ResourceAttributes{
+		Group:     "vteam.ambient-code",
+		Resource:  "projectsettings",
+		Verb:      "update",
+		Namespace: projectName,
+	})
+	if err != nil || !allowed {
+		logging.Warnf(c, "Quarantine: User not authorized to review quarantined content in %s", projectName)
+		c.JSON(http.StatusForbidden, gin.H{"error": "Unauthorized"})
+		c.Abort()
+		return false
+	}
+	return true
+}
+
+// HandleListQuarantined handles GET /api/projects/:projectName/quarantine
+// Lists withheld messages, newest first; ?session= filters by session
+func HandleListQuarantined(c *gin.Context) {
+	projectName := c.Param("projectName")
+	if !authorizeQuarantineRead(c, projectName) {
+		return
+	}
+	records, err := loadQuarantined(projectName)
+	if err != nil {
+		logging.Errorf(c, "Quarantine: Failed to load quarantined content for %s: %v", projectName, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load quarantined content"})
+		return
+	}
+	if session := c.Query("session"); session != "" {
+		filtered := []types.QuarantinedContent{}
+		for _, r := range records {
+			if r.SessionName == session {
+				filtered = append(filtered, r)
+			}
+		}
+		records = filtered
+	}
+	c.JSON(http.StatusOK, gin.H{"items": records})
+}
+
+// HandleGetQuarantined handles GET /api/projects/:projectName/quarantine/:quarantineId
+func HandleGetQuarantined(c *gin.Context) {
+	projectName := c.Param("projectName")
+	if !authorizeQuarantineRead(c, projectName) {
+		return
+	}
+	records, err := loadQuarantined(projectName)
+	if err != nil {
+		logging.Errorf(c, "Quarantine: Failed to load quarantined content for %s: %v", projectName, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load quarantined content"})
+		return
+	}
+	id := c.Param("quarantineId")
+	for _, r := range records {
+		if r.ID == id {
+			c.JSON(http.StatusOK, r)
+			return
+		}
+	}
+	c.JSON(http.StatusNotFound, gin.H{"error": "Quarantined content not found"})
+}
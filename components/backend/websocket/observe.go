@@ -0,0 +1,97 @@
+package websocket
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"ambient-code-backend/audit"
+	"ambient-code-backend/handlers"
+	"ambient-code-backend/logging"
+	"ambient-code-backend/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HandleAGUIObserve handles GET /api/projects/:projectName/agentic-sessions/:sessionName/agui/observe
+// Streams a session's live events to observers: callers with get on
+// agenticsessions/observe, e.g. managers with the observer role. Unlike .../agui/events
+// nothing persisted is replayed; observers join at the current point of the active runs.
+// Every watch is recorded in the audit log with verb OBSERVE.
+func HandleAGUIObserve(c *gin.Context) {
+	projectName := c.Param("projectName")
+	sessionName := c.Param("sessionName")
+	start := time.Now()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+
+	defer joinPresence(c, sessionName, presenceObserver)()
+	eventCh, unsubscribe := SubscribeSession(sessionName)
+	defer unsubscribe()
+
+	user := c.GetString("userIDOriginal")
+	if user == "" {
+		user = c.GetString("userID")
+	}
+	logging.Infof(c, "AGUI Observe: %s watching %s/%s", user, projectName, sessionName)
+	defer func() {
+		audit.Emit(audit.Record{
+			Time:      start.UTC(),
+			RequestID: handlers.RequestID(c),
+			User:      user,
+			Verb:      "OBSERVE",
+			Resource:  c.FullPath(),
+			Path:      c.Request.URL.Path,
+			Project:   projectName,
+			Status:    http.StatusOK,
+			Outcome:   audit.OutcomeSuccess,
+			LatencyMs: time.Since(start).Milliseconds(),
+			ClientIP:  c.ClientIP(),
+			Detail:    fmt.Sprintf("session=%s", sessionName),
+		})
+	}()
+
+	// Announce the runs in progress so clients can attribute the events that follow
+	var running []*types.RunStartedEvent
+	aguiRunsMu.RLock()
+	for _, state := range aguiRuns {
+		if state.SessionID != sessionName || state.Status != "running" {
+			continue
+		}
+		runStarted := &types.RunStartedEvent{
+			BaseEvent: types.NewBaseEvent(types.EventTypeRunStarted, state.ThreadID, state.RunID),
+		}
+		runStarted.ParentRunID = state.ParentRunID
+		runStarted.BranchID = state.BranchID
+		running = append(running, runStarted)
+	}
+	aguiRunsMu.RUnlock()
+	for _, runStarted := range running {
+		writeSSEEvent(c.Writer, runStarted)
+	}
+	c.Writer.(http.Flusher).Flush()
+
+	keepaliveTicker := time.NewTicker(15 * time.Second)
+	defer keepaliveTicker.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-keepaliveTicker.C:
+			if _, err := c.Writer.Write([]byte(": keepalive\n\n")); err != nil {
+				return
+			}
+			c.Writer.(http.Flusher).Flush()
+		case event, ok := <-eventCh:
+			if !ok {
+				return
+			}
+			writeSSEEvent(c.Writer, event)
+			c.Writer.(http.Flusher).Flush()
+		}
+	}
+}
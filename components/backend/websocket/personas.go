@@ -0,0 +1,73 @@
+package websocket
+
+import (
+	"net/http"
+
+	"ambient-code-backend/handlers"
+	"ambient-code-backend/logging"
+	"ambient-code-backend/types"
+
+	"github.com/gin-gonic/gin"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// applySessionPersona resolves the session's spec.persona from the project registry
+// and passes it to the runner as forwardedProps.persona; the persona's model applies
+// when the run doesn't pick one. Personas are only ever resolved here, so clients
+// cannot supply their own. On failure it writes the error response and returns false.
+func applySessionPersona(c *gin.Context, projectName, sessionName string, input *types.RunAgentInput) bool {
+	props := make(map[string]interface{}, len(input.ForwardedProps)+1)
+	for k, v := range input.ForwardedProps {
+		if k != "persona" {
+			props[k] = v
+		}
+	}
+	input.ForwardedProps = props
+
+	item, err := handlers.GetCachedSession(c.Request.Context(), projectName, sessionName)
+	if err != nil {
+		logging.Warnf(c, "AGUI Proxy: cannot read persona of %s/%s: %v", projectName, sessionName, err)
+		return true
+	}
+	name, _, _ := unstructured.NestedString(item.Object, "spec", "persona")
+	if name == "" {
+		return true
+	}
+
+	reqK8s, _ := handlers.GetK8sClientsForRequest(c)
+	if reqK8s == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing token"})
+		return false
+	}
+	persona, err := handlers.LoadAgentPersona(c.Request.Context(), reqK8s, projectName, name)
+	if err != nil {
+		switch {
+		case handlers.IsPersonaNotFound(err):
+			c.JSON(http.StatusConflict, gin.H{"error": "The session's persona " + name + " no longer exists"})
+		case errors.IsForbidden(err):
+			c.JSON(http.StatusForbidden, gin.H{"error": "Insufficient permissions to read personas"})
+		default:
+			logging.Errorf(c, "AGUI Proxy: Failed to load persona %s for %s/%s: %v", name, projectName, sessionName, err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load persona"})
+		}
+		return false
+	}
+
+	if input.Model == "" {
+		input.Model = persona.Model
+	}
+	props["persona"] = personaProps(persona)
+	logging.Infof(c, "AGUI Proxy: Applying persona %s to run %s", persona.Name, input.RunID)
+	return true
+}
+
+// personaProps is the forwardedProps.persona the runner applies
+func personaProps(persona types.AgentPersona) map[string]interface{} {
+	return map[string]interface{}{
+		"name":         persona.Name,
+		"systemPrompt": persona.SystemPrompt,
+		"allowedTools": persona.AllowedTools,
+		"mcpServers":   persona.MCPServers,
+	}
+}
@@ -0,0 +1,237 @@
+package websocket
+
+import (
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"ambient-code-backend/handlers"
+	"ambient-code-backend/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Presence tells collaborators who else has a session's event stream open and who is
+// about to send a message. The backend tracks stream connections per user and
+// broadcasts changes as META events with metaType "presence"; they are never persisted.
+const (
+	presenceMetaType = "presence"
+
+	presenceParticipant = "participant" // connected to .../agui/events
+	presenceObserver    = "observer"    // connected to .../agui/observe
+
+	// presenceTypingTTL is how long a typing signal lasts unless the client repeats it
+	presenceTypingTTL = 10 * time.Second
+)
+
+// PresenceUser is a user connected to a session's event stream
+type PresenceUser struct {
+	UserID   string `json:"userId"`
+	UserName string `json:"userName,omitempty"`
+	// Role is participant, or observer when the user only has observe streams open
+	Role     string `json:"role"`
+	JoinedAt string `json:"joinedAt"`
+	Typing   bool   `json:"typing"`
+}
+
+type presenceEntry struct {
+	user         PresenceUser
+	participants int // open .../agui/events streams
+	observers    int // open .../agui/observe streams
+	typingUntil  time.Time
+}
+
+var (
+	presenceMu      sync.Mutex
+	sessionPresence = map[string]map[string]*presenceEntry{}
+)
+
+// presenceEvent builds a presence META event for a session
+func presenceEvent(sessionName string, payload map[string]interface{}) *types.MetaEvent {
+	return &types.MetaEvent{
+		BaseEvent: types.NewBaseEvent(types.EventTypeMeta, sessionName, ""),
+		MetaType:  presenceMetaType,
+		Payload:   payload,
+	}
+}
+
+// role reports the entry's presence role; callers hold presenceMu
+func (e *presenceEntry) role() string {
+	if e.participants > 0 {
+		return presenceParticipant
+	}
+	return presenceObserver
+}
+
+// joinPresence registers the caller's stream on a session, writes the current
+// presence snapshot to it and tells the others the caller joined. Call the returned
+// function when the stream closes. Callers without a user ID (e.g. runner tokens)
+// aren't tracked.
+func joinPresence(c *gin.Context, sessionName, role string) func() {
+	userID := c.GetString("userID")
+	if userID == "" {
+		return func() {}
+	}
+
+	presenceMu.Lock()
+	users := sessionPresence[sessionName]
+	if users == nil {
+		users = map[string]*presenceEntry{}
+		sessionPresence[sessionName] = users
+	}
+	entry := users[userID]
+	isNew := entry == nil
+	if isNew {
+		entry = &presenceEntry{user: PresenceUser{
+			UserID:   userID,
+			UserName: c.GetString("userName"),
+			JoinedAt: time.Now().UTC().Format(time.RFC3339),
+		}}
+		users[userID] = entry
+	}
+	previousRole := ""
+	if !isNew {
+		previousRole = entry.role()
+	}
+	if role == presenceParticipant {
+		entry.participants++
+	} else {
+		entry.observers++
+	}
+	entry.user.Role = entry.role()
+	joined := entry.user
+	snapshot := presenceSnapshotLocked(sessionName)
+	presenceMu.Unlock()
+
+	writeSSEEvent(c.Writer, presenceEvent(sessionName, map[string]interface{}{"action": "snapshot", "users": snapshot}))
+	c.Writer.(http.Flusher).Flush()
+	if previousRole != joined.Role {
+		broadcastToThread(sessionName, presenceEvent(sessionName, map[string]interface{}{"action": "joined", "user": joined}))
+	}
+
+	return func() { leavePresence(sessionName, userID, role) }
+}
+
+// leavePresence drops one of the user's streams and tells the others when it was
+// their last one, or when only observe streams remain
+func leavePresence(sessionName, userID, role string) {
+	presenceMu.Lock()
+	entry := sessionPresence[sessionName][userID]
+	if entry == nil {
+		presenceMu.Unlock()
+		return
+	}
+	previousRole := entry.role()
+	if role == presenceParticipant {
+		entry.participants--
+	} else {
+		entry.observers--
+	}
+	left := entry.participants <= 0 && entry.observers <= 0
+	if left {
+		delete(sessionPresence[sessionName], userID)
+		if len(sessionPresence[sessionName]) == 0 {
+			delete(sessionPresence, sessionName)
+		}
+	}
+	entry.user.Role = entry.role()
+	user := entry.user
+	presenceMu.Unlock()
+
+	if left {
+		broadcastToThread(sessionName, presenceEvent(sessionName, map[string]interface{}{"action": "left", "userId": userID}))
+	} else if user.Role != previousRole {
+		broadcastToThread(sessionName, presenceEvent(sessionName, map[string]interface{}{"action": "joined", "user": user}))
+	}
+}
+
+// setTyping marks whether a connected user is typing. Only changes are broadcast;
+// repeating typing=true extends it by presenceTypingTTL. It reports whether the user
+// has the session's stream open.
+func setTyping(sessionName, userID string, typing bool) bool {
+	presenceMu.Lock()
+	entry := sessionPresence[sessionName][userID]
+	if entry == nil {
+		presenceMu.Unlock()
+		return false
+	}
+	changed := entry.user.Typing != typing
+	entry.user.Typing = typing
+	if typing {
+		entry.typingUntil = time.Now().Add(presenceTypingTTL)
+	}
+	presenceMu.Unlock()
+
+	if typing {
+		time.AfterFunc(presenceTypingTTL, func() { expireTyping(sessionName, userID) })
+	}
+	if changed {
+		broadcastToThread(sessionName, presenceEvent(sessionName, map[string]interface{}{"action": "typing", "userId": userID, "typing": typing}))
+	}
+	return true
+}
+
+// expireTyping clears a typing signal that wasn't repeated in time
+func expireTyping(sessionName, userID string) {
+	presenceMu.Lock()
+	entry := sessionPresence[sessionName][userID]
+	expired := entry != nil && entry.user.Typing && !time.Now().Before(entry.typingUntil)
+	if expired {
+		entry.user.Typing = false
+	}
+	presenceMu.Unlock()
+	if expired {
+		broadcastToThread(sessionName, presenceEvent(sessionName, map[string]interface{}{"action": "typing", "userId": userID, "typing": false}))
+	}
+}
+
+// presenceSnapshotLocked lists a session's connected users by join time; callers hold presenceMu
+func presenceSnapshotLocked(sessionName string) []PresenceUser {
+	users := make([]PresenceUser, 0, len(sessionPresence[sessionName]))
+	for _, entry := range sessionPresence[sessionName] {
+		users = append(users, entry.user)
+	}
+	sort.Slice(users, func(i, j int) bool {
+		if users[i].JoinedAt != users[j].JoinedAt {
+			return users[i].JoinedAt < users[j].JoinedAt
+		}
+		return users[i].UserID < users[j].UserID
+	})
+	return users
+}
+
+// HandleGetPresence handles GET /api/projects/:projectName/agentic-sessions/:sessionName/agui/presence
+// Lists the users connected to the session's event stream.
+func HandleGetPresence(c *gin.Context) {
+	sessionName := c.Param("sessionName")
+	presenceMu.Lock()
+	users := presenceSnapshotLocked(sessionName)
+	presenceMu.Unlock()
+	c.JSON(http.StatusOK, gin.H{"users": users})
+}
+
+// HandleUpdatePresence handles POST /api/projects/:projectName/agentic-sessions/:sessionName/agui/presence
+// Body {"typing": true|false} signals that the caller is (or stopped) writing a
+// message. The caller must have the session's event stream open.
+func HandleUpdatePresence(c *gin.Context) {
+	sessionName := c.Param("sessionName")
+	var req struct {
+		Typing *bool `json:"typing"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil || req.Typing == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "typing is required"})
+		return
+	}
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Presence requires a user identity"})
+		return
+	}
+	if !setTyping(sessionName, userID, *req.Typing) {
+		c.JSON(http.StatusConflict, gin.H{"error": "Open the session's event stream first"})
+		return
+	}
+	handlers.RecordSessionActivity(c.Param("projectName"), sessionName)
+	c.JSON(http.StatusOK, gin.H{"typing": *req.Typing})
+}
@@ -0,0 +1,82 @@
+package websocket
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"ambient-code-backend/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestSessionPresence(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	events, unsubscribe := SubscribeSession("shared")
+	defer unsubscribe()
+	next := func() map[string]interface{} {
+		t.Helper()
+		select {
+		case event := <-events:
+			meta, ok := event.(*types.MetaEvent)
+			if !ok || meta.MetaType != presenceMetaType {
+				t.Fatalf("event = %#v, want a presence META event", event)
+			}
+			return meta.Payload
+		case <-time.After(time.Second):
+			t.Fatal("no presence event broadcast")
+			return nil
+		}
+	}
+	connect := func(userID, role string) (*httptest.ResponseRecorder, func()) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Set("userID", userID)
+		return w, joinPresence(c, "shared", role)
+	}
+
+	_, aliceLeaves := connect("alice", presenceParticipant)
+	if joined := next(); joined["action"] != "joined" || joined["user"].(PresenceUser).Role != presenceParticipant {
+		t.Errorf("alice joined = %v", joined)
+	}
+	w, bobLeaves := connect("bob", presenceObserver)
+	if joined := next(); joined["user"].(PresenceUser).UserID != "bob" {
+		t.Errorf("bob joined = %v", joined)
+	}
+	// New connections get the snapshot of everyone connected, themselves included
+	if body := w.Body.String(); !strings.Contains(body, `"action":"snapshot"`) || !strings.Contains(body, `"userId":"alice"`) || !strings.Contains(body, `"role":"observer"`) {
+		t.Errorf("snapshot = %s", body)
+	}
+
+	// A second stream of a connected user isn't announced
+	_, aliceLeavesAgain := connect("alice", presenceParticipant)
+	aliceLeavesAgain()
+
+	if !setTyping("shared", "alice", true) || setTyping("shared", "carol", true) {
+		t.Fatal("only connected users can type")
+	}
+	if typing := next(); typing["action"] != "typing" || typing["userId"] != "alice" || typing["typing"] != true {
+		t.Errorf("typing = %v", typing)
+	}
+	setTyping("shared", "alice", true)
+	presenceMu.Lock()
+	sessionPresence["shared"]["alice"].typingUntil = time.Now().Add(-time.Second)
+	presenceMu.Unlock()
+	expireTyping("shared", "alice")
+	if stopped := next(); stopped["typing"] != false {
+		t.Errorf("expired typing = %v", stopped)
+	}
+
+	bobLeaves()
+	if left := next(); left["action"] != "left" || left["userId"] != "bob" {
+		t.Errorf("bob left = %v", left)
+	}
+	aliceLeaves()
+	next()
+	presenceMu.Lock()
+	defer presenceMu.Unlock()
+	if len(sessionPresence) != 0 {
+		t.Errorf("presence not cleaned up: %v", sessionPresence)
+	}
+}
@@ -0,0 +1,43 @@
+package websocket
+
+import (
+	"net/http"
+
+	"ambient-code-backend/handlers"
+	"ambient-code-backend/logging"
+	"ambient-code-backend/types"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// renderRunPrompt renders a run's prompt template into a user message, reading the
+// library with the caller's permissions. On failure it writes the error response and
+// returns false.
+func renderRunPrompt(c *gin.Context, projectName string, ref *types.PromptTemplateRef) (types.Message, bool) {
+	reqK8s, _ := handlers.GetK8sClientsForRequest(c)
+	if reqK8s == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing token"})
+		return types.Message{}, false
+	}
+	p, err := handlers.LoadPromptTemplate(c.Request.Context(), reqK8s, projectName, ref.Name, ref.Version)
+	if err != nil {
+		handlers.RespondPromptTemplateError(c, projectName, err)
+		return types.Message{}, false
+	}
+	rendered, err := handlers.RenderPromptTemplate(p, ref.Variables)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return types.Message{}, false
+	}
+	logging.Infof(c, "AGUI Proxy: Rendered prompt template %s v%d", p.Name, p.Version)
+	return types.Message{
+		ID:      uuid.New().String(),
+		Role:    types.RoleUser,
+		Content: rendered,
+		Metadata: map[string]interface{}{
+			"promptTemplate":        p.Name,
+			"promptTemplateVersion": p.Version,
+		},
+	}, true
+}
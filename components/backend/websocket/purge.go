@@ -0,0 +1,173 @@
+package websocket
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"ambient-code-backend/logging"
+	"ambient-code-backend/types"
+)
+
+// PurgeSessionData removes everything the backend persisted for a session: its event
+// log, run records and other session files, and its entries in the project's
+// quarantine. It returns the number of files and quarantine records removed. Session
+// files are stored by session name alone, so they are left in place when their run
+// records name another project: they belong to that project's session of the same
+// name. Used by the user data erasure endpoint; set on handlers.PurgeSessionData from
+// main.
+func PurgeSessionData(project, sessionName string) (int, error) {
+	if !isValidSessionName(sessionName) {
+		return 0, fmt.Errorf("invalid session name %q", sessionName)
+	}
+	removed := 0
+	if stored := storedSessionProject(sessionName); stored != "" && stored != project {
+		logging.Warnf(context.Background(), "Erasure: session files of %s belong to project %s, not %s; keeping them", sessionName, stored, project)
+	} else {
+		dir := filepath.Join(StateBaseDir, "sessions", sessionName)
+		if entries, err := os.ReadDir(dir); err == nil {
+			removed += len(entries)
+		}
+		if err := os.RemoveAll(dir); err != nil {
+			return removed, err
+		}
+		sessionEncryptionProjects.Delete(sessionName)
+		dropUnsealedEvents(sessionName)
+	}
+
+	n, err := purgeQuarantinedForSession(project, sessionName)
+	return removed + n, err
+}
+
+// storedSessionProject returns the project named by the session's persisted run records,
+// or by its encryption marker when it has none, or "" when nothing on disk names one
+func storedSessionProject(sessionName string) string {
+	runs := loadRunsFromDisk(sessionName)
+	for i := len(runs) - 1; i >= 0; i-- {
+		if runs[i].ProjectName != "" {
+			return runs[i].ProjectName
+		}
+	}
+	return encryptedSessionProject(sessionName)
+}
+
+// PurgeUserContributions removes the annotations userID added to the sessions still on
+// disk, and reports the feedback they gave in those sessions, which stays in the event
+// logs. Used by the user data erasure endpoint after the user's own sessions are
+// purged; set on handlers.PurgeUserContributions from main.
+func PurgeUserContributions(userID string) (int, []types.ErasureRetained, error) {
+	retained := []types.ErasureRetained{}
+	entries, err := os.ReadDir(filepath.Join(StateBaseDir, "sessions"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, retained, nil
+		}
+		return 0, retained, err
+	}
+
+	removed := 0
+	var firstErr error
+	for _, entry := range entries {
+		sessionName := entry.Name()
+		if !entry.IsDir() || !isValidSessionName(sessionName) {
+			continue
+		}
+		n, err := purgeUserAnnotations(sessionName, userID)
+		removed += n
+		if err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("session %s: %w", sessionName, err)
+		}
+		if count := countUserFeedback(sessionName, userID); count > 0 {
+			retained = append(retained, types.ErasureRetained{
+				Project: storedSessionProject(sessionName),
+				Session: sessionName,
+				Kind:    "feedback",
+				Count:   count,
+			})
+		}
+	}
+	return removed, retained, firstErr
+}
+
+// purgeUserAnnotations drops the user's annotations from the session's annotation log
+func purgeUserAnnotations(sessionName, userID string) (int, error) {
+	annotationsFileMu.Lock()
+	defer annotationsFileMu.Unlock()
+	annotations, err := loadAnnotations(sessionName)
+	if err != nil {
+		return 0, err
+	}
+	kept := make([]EventAnnotation, 0, len(annotations))
+	for _, a := range annotations {
+		if a.User != userID {
+			kept = append(kept, a)
+		}
+	}
+	removed := len(annotations) - len(kept)
+	if removed == 0 {
+		return 0, nil
+	}
+	return removed, writeAnnotations(sessionName, kept)
+}
+
+// countUserFeedback counts the META events in the session's event log the user sent
+func countUserFeedback(sessionName, userID string) int {
+	events, err := readJSONLFile(filepath.Join(StateBaseDir, "sessions", sessionName, "agui-events.jsonl"), sessionName)
+	if err != nil {
+		return 0
+	}
+	count := 0
+	for _, event := range events {
+		if event["type"] != types.EventTypeMeta {
+			continue
+		}
+		payload, _ := event["payload"].(map[string]interface{})
+		if user, _ := payload["userId"].(string); user == userID {
+			count++
+		}
+	}
+	return count
+}
+
+// purgeQuarantinedForSession drops the session's records from the project's quarantine
+func purgeQuarantinedForSession(project, sessionName string) (int, error) {
+	quarantineFileMu.Lock()
+	defer quarantineFileMu.Unlock()
+	path := quarantinePath(project)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	var kept bytes.Buffer
+	removed := 0
+	for _, line := range splitLines(data) {
+		var record struct {
+			SessionName string `json:"sessionName"`
+		}
+		if json.Unmarshal(line, &record) == nil && record.SessionName == sessionName {
+			removed++
+			continue
+		}
+		kept.Write(line)
+		kept.WriteByte('\n')
+	}
+	if removed == 0 {
+		return 0, nil
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, kept.Bytes(), 0644); err != nil {
+		return 0, err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return 0, err
+	}
+	return removed, nil
+}
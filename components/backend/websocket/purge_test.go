@@ -0,0 +1,67 @@
+package websocket
+
+import (
+	"os"
+	"testing"
+)
+
+func writeSessionFile(t *testing.T, sessionName, file, content string) {
+	t.Helper()
+	dir := StateBaseDir + "/sessions/" + sessionName
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(dir+"/"+file, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestPurgeSessionDataChecksProject(t *testing.T) {
+	StateBaseDir = t.TempDir()
+	writeSessionFile(t, "shared", "agui-runs.jsonl", `{"runId":"r1","projectName":"team-b"}`+"\n")
+	writeSessionFile(t, "mine", "agui-runs.jsonl", `{"runId":"r2","projectName":"team-a"}`+"\n")
+
+	// Files recorded for another project's session of the same name are kept
+	if n, err := PurgeSessionData("team-a", "shared"); err != nil || n != 0 {
+		t.Fatalf("PurgeSessionData(team-a, shared) = %d, %v; want 0, nil", n, err)
+	}
+	if _, err := os.Stat(StateBaseDir + "/sessions/shared/agui-runs.jsonl"); err != nil {
+		t.Fatalf("team-b's session files were removed: %v", err)
+	}
+
+	if n, err := PurgeSessionData("team-a", "mine"); err != nil || n != 1 {
+		t.Fatalf("PurgeSessionData(team-a, mine) = %d, %v; want 1, nil", n, err)
+	}
+	if _, err := os.Stat(StateBaseDir + "/sessions/mine"); !os.IsNotExist(err) {
+		t.Fatalf("session directory still present: %v", err)
+	}
+}
+
+func TestPurgeUserContributions(t *testing.T) {
+	StateBaseDir = t.TempDir()
+	writeSessionFile(t, "bob-1", "agui-runs.jsonl", `{"runId":"r1","projectName":"team-a"}`+"\n")
+	writeSessionFile(t, "bob-1", "agui-annotations.jsonl",
+		`{"id":"a1","eventId":"m1","rating":"up","user":"alice","createdAt":"2026-01-01T00:00:00Z"}`+"\n"+
+			`{"id":"a2","eventId":"m1","rating":"down","user":"bob","createdAt":"2026-01-01T00:00:00Z"}`+"\n")
+	writeSessionFile(t, "bob-1", "agui-events.jsonl",
+		`{"type":"META","metaType":"thumbs_up","payload":{"userId":"alice","messageId":"m1"}}`+"\n"+
+			`{"type":"META","metaType":"thumbs_down","payload":{"userId":"bob","messageId":"m1"}}`+"\n")
+
+	removed, retained, err := PurgeUserContributions("alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if removed != 1 {
+		t.Errorf("removed %d annotations, want 1", removed)
+	}
+	annotations, err := loadAnnotations("bob-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(annotations) != 1 || annotations[0].User != "bob" {
+		t.Errorf("remaining annotations = %+v, want only bob's", annotations)
+	}
+	if len(retained) != 1 || retained[0].Project != "team-a" || retained[0].Session != "bob-1" || retained[0].Kind != "feedback" || retained[0].Count != 1 {
+		t.Errorf("retained = %+v, want alice's one feedback event in team-a/bob-1", retained)
+	}
+}
@@ -0,0 +1,396 @@
+package websocket
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"ambient-code-backend/logging"
+	"ambient-code-backend/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// maxDiffLines bounds each side of an artifact diff; larger files are only
+	// reported as changed
+	maxDiffLines = 2000
+	// diffContextLines is the unchanged context shown around each hunk
+	diffContextLines = 3
+)
+
+// RunStats are the cost and latency figures of one compared run
+type RunStats struct {
+	RunID               string  `json:"runId"`
+	SessionName         string  `json:"sessionName"`
+	Status              string  `json:"status"`
+	StartedAt           string  `json:"startedAt"`
+	FinishedAt          string  `json:"finishedAt,omitempty"`
+	DurationMs          int64   `json:"durationMs,omitempty"`
+	TimeToFirstTokenMs  int64   `json:"timeToFirstTokenMs,omitempty"`
+	InputTokens         int64   `json:"inputTokens"`
+	OutputTokens        int64   `json:"outputTokens"`
+	CacheReadTokens     int64   `json:"cacheReadTokens,omitempty"`
+	CacheCreationTokens int64   `json:"cacheCreationTokens,omitempty"`
+	CostUSD             float64 `json:"costUsd"`
+	Turns               int     `json:"turns"`
+	Messages            int     `json:"messages"`
+	ToolCalls           int     `json:"toolCalls"`
+}
+
+// ComparedTurn pairs the messages of the same turn of both runs. A turn starts at a
+// user message; a run with fewer turns has no messages for the extra ones.
+type ComparedTurn struct {
+	Index int             `json:"index"`
+	A     []types.Message `json:"a"`
+	B     []types.Message `json:"b"`
+}
+
+// ArtifactDiff compares what both runs wrote to one file
+type ArtifactDiff struct {
+	Path   string `json:"path"`
+	Status string `json:"status"` // "onlyA", "onlyB", "changed", "identical"
+	Diff   string `json:"diff,omitempty"`
+}
+
+// RunComparison is the response of the run comparison endpoint
+type RunComparison struct {
+	A         RunStats       `json:"a"`
+	B         RunStats       `json:"b"`
+	Turns     []ComparedTurn `json:"turns"`
+	Artifacts []ArtifactDiff `json:"artifacts"`
+}
+
+// compareRun is a run loaded for comparison
+type compareRun struct {
+	stats     RunStats
+	messages  []types.Message
+	artifacts map[string]string
+}
+
+// loadCompareRun loads a run's metadata, messages and artifacts, or nil when the
+// session has no such run
+func loadCompareRun(sessionName, runID string) (*compareRun, error) {
+	var meta *types.AGUIRunMetadata
+	for _, r := range getRunsForSession(sessionName) {
+		if r.RunID == runID {
+			meta = &r
+		}
+	}
+	if meta == nil {
+		return nil, nil
+	}
+	events, err := loadEventsForRun(sessionName, runID)
+	if err != nil {
+		return nil, err
+	}
+	messages := ThreadMessages(events)
+	stats := runStats(*meta, events)
+	stats.Messages = len(messages)
+	for _, msg := range messages {
+		stats.ToolCalls += len(msg.ToolCalls)
+	}
+	return &compareRun{stats: stats, messages: messages, artifacts: runArtifacts(messages)}, nil
+}
+
+// runStats reads timing from the run metadata and events, and token usage and cost
+// from the runner's /lastResult state updates
+func runStats(meta types.AGUIRunMetadata, events []map[string]interface{}) RunStats {
+	stats := RunStats{
+		RunID:       meta.RunID,
+		SessionName: meta.SessionName,
+		Status:      meta.Status,
+		StartedAt:   meta.StartedAt,
+		FinishedAt:  meta.FinishedAt,
+	}
+	started, startErr := time.Parse(time.RFC3339, meta.StartedAt)
+	if finished, err := time.Parse(time.RFC3339, meta.FinishedAt); err == nil && startErr == nil {
+		stats.DurationMs = finished.Sub(started).Milliseconds()
+	}
+
+	var resultDurationMs int64
+	for _, event := range events {
+		eventType, _ := event["type"].(string)
+		if eventType == types.EventTypeTextMessageContent && stats.TimeToFirstTokenMs == 0 && startErr == nil {
+			ts, _ := event["timestamp"].(string)
+			if t, err := time.Parse(types.AGUITimestampFormat, ts); err == nil {
+				stats.TimeToFirstTokenMs = t.Sub(started).Milliseconds()
+			}
+		}
+		if eventType != types.EventTypStateDelta {
+			continue
+		}
+		ops, _ := event["delta"].([]interface{})
+		for _, op := range ops {
+			patch, _ := op.(map[string]interface{})
+			if patch["path"] != "/lastResult" {
+				continue
+			}
+			result, _ := patch["value"].(map[string]interface{})
+			stats.CostUSD += jsonNumber(result["total_cost_usd"])
+			stats.Turns += int(jsonNumber(result["num_turns"]))
+			resultDurationMs += int64(jsonNumber(result["duration_ms"]))
+			usage, _ := result["usage"].(map[string]interface{})
+			stats.InputTokens += int64(jsonNumber(usage["input_tokens"]))
+			stats.OutputTokens += int64(jsonNumber(usage["output_tokens"]))
+			stats.CacheReadTokens += int64(jsonNumber(usage["cache_read_input_tokens"]))
+			stats.CacheCreationTokens += int64(jsonNumber(usage["cache_creation_input_tokens"]))
+		}
+	}
+	// Runs still in progress have no finish time; use what the runner reported
+	if stats.DurationMs == 0 {
+		stats.DurationMs = resultDurationMs
+	}
+	return stats
+}
+
+// jsonNumber reads a decoded JSON number, treating anything else as zero
+func jsonNumber(v interface{}) float64 {
+	n, _ := v.(float64)
+	return n
+}
+
+// alignTurns pairs the runs' turns by position
+func alignTurns(a, b []types.Message) []ComparedTurn {
+	turnsA, turnsB := splitTurns(a), splitTurns(b)
+	n := max(len(turnsA), len(turnsB))
+	turns := make([]ComparedTurn, n)
+	for i := range turns {
+		turns[i] = ComparedTurn{Index: i, A: []types.Message{}, B: []types.Message{}}
+		if i < len(turnsA) {
+			turns[i].A = turnsA[i]
+		}
+		if i < len(turnsB) {
+			turns[i].B = turnsB[i]
+		}
+	}
+	return turns
+}
+
+// splitTurns groups messages into turns, each starting at a user message
+func splitTurns(messages []types.Message) [][]types.Message {
+	var turns [][]types.Message
+	for _, msg := range messages {
+		if msg.Role == types.RoleUser || len(turns) == 0 {
+			turns = append(turns, nil)
+		}
+		turns[len(turns)-1] = append(turns[len(turns)-1], msg)
+	}
+	return turns
+}
+
+// runArtifacts reconstructs what a run's edit tools wrote, by path. Files the run
+// created with Write have their content, with later edits applied; files only edited
+// in place are represented by their edits.
+func runArtifacts(messages []types.Message) map[string]string {
+	artifacts := map[string]string{}
+	written := map[string]bool{}
+	for _, msg := range messages {
+		for _, tc := range msg.ToolCalls {
+			if !fileEditTools[tc.Name] {
+				continue
+			}
+			var args struct {
+				FilePath     string `json:"file_path"`
+				NotebookPath string `json:"notebook_path"`
+				Content      string `json:"content"`
+				OldString    string `json:"old_string"`
+				NewString    string `json:"new_string"`
+				NewSource    string `json:"new_source"`
+				Edits        []struct {
+					OldString string `json:"old_string"`
+					NewString string `json:"new_string"`
+				} `json:"edits"`
+			}
+			if json.Unmarshal([]byte(tc.Args), &args) != nil {
+				continue
+			}
+			path := args.FilePath
+			if path == "" {
+				path = args.NotebookPath
+			}
+			if path == "" {
+				continue
+			}
+			switch tc.Name {
+			case "Write":
+				artifacts[path], written[path] = args.Content, true
+			case "Edit":
+				artifacts[path] = applyArtifactEdit(artifacts[path], written[path], args.OldString, args.NewString)
+			case "MultiEdit":
+				for _, e := range args.Edits {
+					artifacts[path] = applyArtifactEdit(artifacts[path], written[path], e.OldString, e.NewString)
+				}
+			case "NotebookEdit":
+				artifacts[path] += fmt.Sprintf("@@ cell @@\n%s\n", args.NewSource)
+			}
+		}
+	}
+	return artifacts
+}
+
+// applyArtifactEdit applies an edit to known content, or records it when the file's
+// content is unknown
+func applyArtifactEdit(content string, known bool, oldString, newString string) string {
+	if known {
+		return strings.Replace(content, oldString, newString, 1)
+	}
+	return content + fmt.Sprintf("@@ edit @@\n-%s\n+%s\n",
+		strings.ReplaceAll(oldString, "\n", "\n-"), strings.ReplaceAll(newString, "\n", "\n+"))
+}
+
+// compareArtifacts diffs the files written by both runs, sorted by path
+func compareArtifacts(a, b map[string]string) []ArtifactDiff {
+	paths := map[string]bool{}
+	for p := range a {
+		paths[p] = true
+	}
+	for p := range b {
+		paths[p] = true
+	}
+	diffs := make([]ArtifactDiff, 0, len(paths))
+	for p := range paths {
+		contentA, inA := a[p]
+		contentB, inB := b[p]
+		d := ArtifactDiff{Path: p}
+		switch {
+		case !inB:
+			d.Status = "onlyA"
+		case !inA:
+			d.Status = "onlyB"
+		case contentA == contentB:
+			d.Status = "identical"
+		default:
+			d.Status = "changed"
+			d.Diff = unifiedDiff(contentA, contentB)
+		}
+		diffs = append(diffs, d)
+	}
+	sort.Slice(diffs, func(i, j int) bool { return diffs[i].Path < diffs[j].Path })
+	return diffs
+}
+
+// unifiedDiff renders a line diff of a (run A) against b (run B) in unified format
+func unifiedDiff(a, b string) string {
+	linesA, linesB := strings.Split(a, "\n"), strings.Split(b, "\n")
+	if len(linesA) > maxDiffLines || len(linesB) > maxDiffLines {
+		return fmt.Sprintf("files differ (%d vs %d lines, too large to diff)\n", len(linesA), len(linesB))
+	}
+
+	// Longest common subsequence table, filled from the end
+	lcs := make([][]int, len(linesA)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(linesB)+1)
+	}
+	for i := len(linesA) - 1; i >= 0; i-- {
+		for j := len(linesB) - 1; j >= 0; j-- {
+			if linesA[i] == linesB[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	type line struct {
+		op   byte // ' ', '-', '+'
+		text string
+		a, b int // 1-based line numbers before this line
+	}
+	var ops []line
+	i, j := 0, 0
+	for i < len(linesA) || j < len(linesB) {
+		switch {
+		case i < len(linesA) && j < len(linesB) && linesA[i] == linesB[j]:
+			ops = append(ops, line{' ', linesA[i], i, j})
+			i, j = i+1, j+1
+		case i < len(linesA) && (j == len(linesB) || lcs[i+1][j] >= lcs[i][j+1]):
+			ops = append(ops, line{'-', linesA[i], i, j})
+			i++
+		default:
+			ops = append(ops, line{'+', linesB[j], i, j})
+			j++
+		}
+	}
+
+	var out strings.Builder
+	out.WriteString("--- a\n+++ b\n")
+	for start := 0; start < len(ops); {
+		if ops[start].op == ' ' {
+			start++
+			continue
+		}
+		// Extend the hunk while changes are within twice the context of each other
+		from := max(0, start-diffContextLines)
+		end := start
+		for k := start; k < len(ops); k++ {
+			if ops[k].op != ' ' {
+				end = k
+			} else if k-end > 2*diffContextLines {
+				break
+			}
+		}
+		to := min(len(ops), end+diffContextLines+1)
+		countA, countB := 0, 0
+		for _, l := range ops[from:to] {
+			if l.op != '+' {
+				countA++
+			}
+			if l.op != '-' {
+				countB++
+			}
+		}
+		fmt.Fprintf(&out, "@@ -%d,%d +%d,%d @@\n", ops[from].a+1, countA, ops[from].b+1, countB)
+		for _, l := range ops[from:to] {
+			out.WriteByte(l.op)
+			out.WriteString(l.text)
+			out.WriteByte('\n')
+		}
+		start = to
+	}
+	return out.String()
+}
+
+// HandleAGUIRunCompare handles GET /api/projects/:projectName/agentic-sessions/:sessionName/agui/runs/compare?runA=&runB=
+// Compares two runs, e.g. the same task on two models or prompt variants: aligned
+// transcripts, token, cost and latency stats, and diffs of the files they wrote. Run B
+// may belong to another session of the project (sessionB=).
+func HandleAGUIRunCompare(c *gin.Context) {
+	sessionName := c.Param("sessionName")
+	sessionB := c.DefaultQuery("sessionB", sessionName)
+	runA, runB := c.Query("runA"), c.Query("runB")
+	if !isValidSessionName(sessionName) || !isValidSessionName(sessionB) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session name"})
+		return
+	}
+	if runA == "" || runB == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "runA and runB are required"})
+		return
+	}
+
+	runs := make([]*compareRun, 2)
+	for i, ref := range [][2]string{{sessionName, runA}, {sessionB, runB}} {
+		run, err := loadCompareRun(ref[0], ref[1])
+		if err != nil {
+			logging.Errorf(c, "RunCompare: Failed to load run %s of %s: %v", ref[1], ref[0], err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load runs"})
+			return
+		}
+		if run == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("Run %s not found", ref[1])})
+			return
+		}
+		runs[i] = run
+	}
+	a, b := runs[0], runs[1]
+
+	c.JSON(http.StatusOK, RunComparison{
+		A:         a.stats,
+		B:         b.stats,
+		Turns:     alignTurns(a.messages, b.messages),
+		Artifacts: compareArtifacts(a.artifacts, b.artifacts),
+	})
+}
@@ -0,0 +1,319 @@
+package websocket
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"ambient-code-backend/handlers"
+	"ambient-code-backend/logging"
+	"ambient-code-backend/metrics"
+	"ambient-code-backend/types"
+
+	"github.com/gin-gonic/gin"
+	authv1 "k8s.io/api/authorization/v1"
+)
+
+// Runs submitted while a project already streams its placement policy's
+// maxConcurrentRuns wait in a per-project queue, interactive before batch and oldest
+// first within a priority, and start as running ones end. The queue is held in
+// memory: a backend restart drops queued runs.
+
+// errRunQueueFull is returned when a run finds the project's queue full
+var errRunQueueFull = errors.New("run queue is full")
+
+// queuedRun is a run waiting for one of the project's run slots
+type queuedRun struct {
+	project   string
+	session   string
+	input     types.RunAgentInput
+	requestID string
+	queuedAt  time.Time
+}
+
+// QueuedRun describes a queued run in API responses
+type QueuedRun struct {
+	RunID       string `json:"runId"`
+	SessionName string `json:"sessionName"`
+	Priority    string `json:"priority"`
+	Position    int    `json:"position"` // 1 is next to start
+	QueuedAt    string `json:"queuedAt"`
+}
+
+var (
+	runQueueMu sync.Mutex
+	runQueues  = map[string][]*queuedRun{}
+	// runSlotsStarting counts runs per project admitted but not yet registered in aguiRuns
+	runSlotsStarting = map[string]int{}
+)
+
+// runPriorityRank orders priorities; lower starts first
+func runPriorityRank(priority string) int {
+	if priority == types.RunPriorityBatch {
+		return 1
+	}
+	return 0
+}
+
+// validateRunPriority checks a run's priority and defaults it to interactive
+func validateRunPriority(input *types.RunAgentInput) error {
+	switch input.Priority {
+	case "":
+		input.Priority = types.RunPriorityInteractive
+	case types.RunPriorityInteractive, types.RunPriorityBatch:
+	default:
+		return errors.New("priority must be interactive or batch")
+	}
+	return nil
+}
+
+// streamingRuns counts the project's top-level runs that are streaming. Sub-agent runs
+// share their parent's stream and aren't counted. Callers hold runQueueMu.
+func streamingRuns(project string) int {
+	aguiRunsMu.RLock()
+	defer aguiRunsMu.RUnlock()
+	n := runSlotsStarting[project]
+	for _, run := range aguiRuns {
+		if run.ProjectName == project && run.BranchID == "" && run.Status == "running" {
+			n++
+		}
+	}
+	return n
+}
+
+// submitRun starts a run, or queues it when the project's run limit is reached. It
+// returns whether the run was queued and its 1-based queue position. Runs past a full
+// queue fail with errRunQueueFull unless they preempt a queued batch run.
+func submitRun(ctx context.Context, project, session string, input types.RunAgentInput) (bool, int, error) {
+	policy, err := handlers.ProjectPlacementPolicy(ctx, project)
+	if err != nil {
+		// The run limit is best effort: a policy that can't be read doesn't hold runs back
+		logging.Warnf(ctx, "Run queue: failed to load placement policy for %s: %v", project, err)
+	}
+	if policy == nil || policy.MaxConcurrentRuns == 0 {
+		_, err := startRunStream(ctx, project, session, input)
+		return false, 0, err
+	}
+
+	runQueueMu.Lock()
+	if len(runQueues[project]) == 0 && streamingRuns(project) < policy.MaxConcurrentRuns {
+		runSlotsStarting[project]++
+		runQueueMu.Unlock()
+		_, err := startRunStream(ctx, project, session, input)
+		releaseRunSlot(project)
+		return false, 0, err
+	}
+
+	queue := runQueues[project]
+	var preempted *queuedRun
+	if policy.MaxQueuedRuns > 0 && len(queue) >= policy.MaxQueuedRuns {
+		// The queue is sorted, so the newest batch run is the last one
+		last := queue[len(queue)-1]
+		if !policy.PreemptQueuedRuns || runPriorityRank(input.Priority) >= runPriorityRank(last.input.Priority) {
+			runQueueMu.Unlock()
+			metrics.ObserveRunQueue("rejected")
+			return false, 0, errRunQueueFull
+		}
+		preempted, queue = last, queue[:len(queue)-1]
+	}
+	run := &queuedRun{
+		project:   project,
+		session:   session,
+		input:     input,
+		requestID: logging.RequestIDFromContext(ctx),
+		queuedAt:  time.Now(),
+	}
+	position := sort.Search(len(queue), func(i int) bool {
+		return runPriorityRank(queue[i].input.Priority) > runPriorityRank(input.Priority)
+	})
+	queue = append(queue, nil)
+	copy(queue[position+1:], queue[position:])
+	queue[position] = run
+	runQueues[project] = queue
+	runQueueMu.Unlock()
+
+	metrics.ObserveRunQueue("queued")
+	logging.Infof(ctx, "Run queue: queued %s run %s of %s/%s at position %d", input.Priority, input.RunID, project, session, position+1)
+	if preempted != nil {
+		metrics.ObserveRunQueue("preempted")
+		logging.Infof(ctx, "Run queue: run %s of %s/%s preempted by run %s", preempted.input.RunID, project, preempted.session, input.RunID)
+		persistDequeuedRun(preempted, "preempted")
+	}
+	// A slot may have freed up while the policy was read
+	go dispatchQueuedRuns(project)
+	return true, position + 1, nil
+}
+
+// releaseRunSlot ends a start reservation once the run is registered (or failed)
+func releaseRunSlot(project string) {
+	runQueueMu.Lock()
+	if runSlotsStarting[project]--; runSlotsStarting[project] <= 0 {
+		delete(runSlotsStarting, project)
+	}
+	runQueueMu.Unlock()
+}
+
+// dispatchQueuedRuns starts the project's queued runs while it has free run slots.
+// Called whenever one of its runs ends.
+func dispatchQueuedRuns(project string) {
+	runQueueMu.Lock()
+	waiting := len(runQueues[project])
+	runQueueMu.Unlock()
+	if waiting == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	policy, err := handlers.ProjectPlacementPolicy(ctx, project)
+	cancel()
+	if err != nil {
+		logging.Warnf(context.Background(), "Run queue: failed to load placement policy for %s: %v", project, err)
+	}
+	limit := 0
+	if policy != nil {
+		limit = policy.MaxConcurrentRuns
+	}
+
+	for {
+		runQueueMu.Lock()
+		queue := runQueues[project]
+		// Without a limit (the policy was removed) the whole queue is started
+		if len(queue) == 0 || (limit > 0 && streamingRuns(project) >= limit) {
+			runQueueMu.Unlock()
+			return
+		}
+		run := queue[0]
+		if len(queue) == 1 {
+			delete(runQueues, project)
+		} else {
+			runQueues[project] = queue[1:]
+		}
+		runSlotsStarting[project]++
+		runQueueMu.Unlock()
+
+		runCtx := logging.WithRequestID(context.Background(), run.requestID)
+		logging.Infof(runCtx, "Run queue: starting run %s of %s/%s after %v", run.input.RunID, project, run.session, time.Since(run.queuedAt).Round(time.Second))
+		if _, err := startRunStream(runCtx, project, run.session, run.input); err != nil {
+			logging.Errorf(runCtx, "Run queue: failed to start run %s: %v", run.input.RunID, err)
+		}
+		releaseRunSlot(project)
+	}
+}
+
+// cancelQueuedRun removes a queued run of the session; it reports whether one was found
+func cancelQueuedRun(project, session, runID string) bool {
+	runQueueMu.Lock()
+	queue := runQueues[project]
+	for i, run := range queue {
+		if run.session != session || run.input.RunID != runID {
+			continue
+		}
+		runQueues[project] = append(queue[:i:i], queue[i+1:]...)
+		if len(runQueues[project]) == 0 {
+			delete(runQueues, project)
+		}
+		runQueueMu.Unlock()
+		persistDequeuedRun(run, "cancelled")
+		return true
+	}
+	runQueueMu.Unlock()
+	return false
+}
+
+// persistDequeuedRun records a queued run that will never start
+func persistDequeuedRun(run *queuedRun, status string) {
+	meta := types.AGUIRunMetadata{
+		ThreadID:    run.input.ThreadID,
+		RunID:       run.input.RunID,
+		ParentRunID: run.input.ParentRunID,
+		SessionName: run.session,
+		ProjectName: run.project,
+		RequestID:   run.requestID,
+		StartedAt:   run.queuedAt.Format(time.RFC3339),
+		FinishedAt:  time.Now().Format(time.RFC3339),
+		Status:      status,
+		Priority:    run.input.Priority,
+	}
+	persistPool.Submit(run.session, func() { persistRunMetadata(run.session, meta) })
+}
+
+// queuedRuns lists the project's queued runs in start order
+func queuedRuns(project string) []QueuedRun {
+	runQueueMu.Lock()
+	defer runQueueMu.Unlock()
+	runs := make([]QueuedRun, 0, len(runQueues[project]))
+	for i, run := range runQueues[project] {
+		runs = append(runs, QueuedRun{
+			RunID:       run.input.RunID,
+			SessionName: run.session,
+			Priority:    run.input.Priority,
+			Position:    i + 1,
+			QueuedAt:    run.queuedAt.Format(time.RFC3339),
+		})
+	}
+	return runs
+}
+
+// queuedRunsForSession returns run metadata for a session's queued runs
+func queuedRunsForSession(session string) []types.AGUIRunMetadata {
+	runQueueMu.Lock()
+	defer runQueueMu.Unlock()
+	var runs []types.AGUIRunMetadata
+	for project, queue := range runQueues {
+		for _, run := range queue {
+			if run.session != session {
+				continue
+			}
+			runs = append(runs, types.AGUIRunMetadata{
+				ThreadID:    run.input.ThreadID,
+				RunID:       run.input.RunID,
+				ParentRunID: run.input.ParentRunID,
+				SessionName: session,
+				ProjectName: project,
+				RequestID:   run.requestID,
+				StartedAt:   run.queuedAt.Format(time.RFC3339),
+				Status:      "queued",
+				Priority:    run.input.Priority,
+			})
+		}
+	}
+	return runs
+}
+
+// HandleRunQueue handles GET /api/projects/:projectName/run-queue
+// Lists the runs waiting for one of the project's run slots, next to start first.
+func HandleRunQueue(c *gin.Context) {
+	projectName := c.Param("projectName")
+
+	reqK8s, _ := handlers.GetK8sClientsForRequest(c)
+	if reqK8s == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing token"})
+		c.Abort()
+		return
+	}
+
+	// SECURITY: Verify user can list sessions in this project
+	allowed, err := handlers.CheckAccessForRequest(c, reqK8s, authv1.ResourceAttributes{
+		Group:     "vteam.ambient-code",
+		Resource:  "agenticsessions",
+		Verb:      "list",
+		Namespace: projectName,
+	})
+	if err != nil || !allowed {
+		logging.Warnf(c, "Run queue: User not authorized to list sessions in %s", projectName)
+		c.JSON(http.StatusForbidden, gin.H{"error": "Unauthorized"})
+		c.Abort()
+		return
+	}
+
+	runQueueMu.Lock()
+	streaming := streamingRuns(projectName)
+	runQueueMu.Unlock()
+	c.JSON(http.StatusOK, gin.H{
+		"streamingRuns": streaming,
+		"queued":        queuedRuns(projectName),
+	})
+}
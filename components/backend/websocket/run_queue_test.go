@@ -0,0 +1,102 @@
+package websocket
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"ambient-code-backend/handlers"
+	"ambient-code-backend/types"
+
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+func TestSubmitRunQueuesByPriority(t *testing.T) {
+	StateBaseDir = t.TempDir()
+	ctx := context.Background()
+	originalClient := handlers.DynamicClient
+	t.Cleanup(func() { handlers.DynamicClient = originalClient })
+	handlers.DynamicClient = dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{handlers.GetProjectSettingsResource(): "ProjectSettingsList"})
+	settings := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "vteam.ambient-code/v1alpha1",
+		"kind":       "ProjectSettings",
+		"metadata":   map[string]interface{}{"name": "projectsettings", "namespace": "team-q"},
+		"spec": map[string]interface{}{"placementPolicy": map[string]interface{}{
+			"maxConcurrentRuns": int64(1),
+			"maxQueuedRuns":     int64(2),
+			"preemptQueuedRuns": true,
+		}},
+	}}
+	if _, err := handlers.DynamicClient.Resource(handlers.GetProjectSettingsResource()).Namespace("team-q").
+		Create(ctx, settings, v1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// The project's one run slot is taken
+	aguiRunsMu.Lock()
+	aguiRuns["streaming"] = &AGUIRunState{RunID: "streaming", SessionID: "s0", ProjectName: "team-q", Status: "running"}
+	aguiRunsMu.Unlock()
+	t.Cleanup(func() {
+		aguiRunsMu.Lock()
+		delete(aguiRuns, "streaming")
+		aguiRunsMu.Unlock()
+		runQueueMu.Lock()
+		delete(runQueues, "team-q")
+		runQueueMu.Unlock()
+	})
+
+	submit := func(runID, priority string) (bool, int, error) {
+		input := types.RunAgentInput{ThreadID: "s1", RunID: runID, Priority: priority}
+		if err := validateRunPriority(&input); err != nil {
+			t.Fatal(err)
+		}
+		return submitRun(ctx, "team-q", "s1", input)
+	}
+	if queued, position, err := submit("nightly-1", types.RunPriorityBatch); err != nil || !queued || position != 1 {
+		t.Fatalf("nightly-1: queued=%v position=%d err=%v", queued, position, err)
+	}
+	if _, position, _ := submit("nightly-2", types.RunPriorityBatch); position != 2 {
+		t.Fatalf("nightly-2 position = %d, want 2", position)
+	}
+	// The queue is full: an interactive run preempts the newest batch run
+	if queued, position, err := submit("chat", ""); err != nil || !queued || position != 1 {
+		t.Fatalf("chat: queued=%v position=%d err=%v", queued, position, err)
+	}
+	if _, _, err := submit("nightly-3", types.RunPriorityBatch); !errors.Is(err, errRunQueueFull) {
+		t.Fatalf("nightly-3 err = %v, want errRunQueueFull", err)
+	}
+
+	var order []string
+	for _, run := range queuedRuns("team-q") {
+		order = append(order, run.RunID+"/"+run.Priority)
+	}
+	if len(order) != 2 || order[0] != "chat/interactive" || order[1] != "nightly-1/batch" {
+		t.Errorf("queue = %v", order)
+	}
+	if runs := queuedRunsForSession("s1"); len(runs) != 2 || runs[0].Status != "queued" {
+		t.Errorf("session runs = %+v", runs)
+	}
+
+	if !cancelQueuedRun("team-q", "s1", "nightly-1") || cancelQueuedRun("team-q", "s1", "nightly-2") {
+		t.Error("only a queued run can be cancelled")
+	}
+	done := make(chan struct{})
+	persistPool.Submit("s1", func() { close(done) })
+	<-done
+	statuses := map[string]string{}
+	for _, run := range loadRunsFromDisk("s1") {
+		statuses[run.RunID] = run.Status
+	}
+	if statuses["nightly-2"] != "preempted" || statuses["nightly-1"] != "cancelled" {
+		t.Errorf("persisted runs = %v", statuses)
+	}
+
+	if err := validateRunPriority(&types.RunAgentInput{Priority: "urgent"}); err == nil {
+		t.Error("unknown priority accepted")
+	}
+}
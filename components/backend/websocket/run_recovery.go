@@ -0,0 +1,140 @@
+package websocket
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"ambient-code-backend/handlers"
+	"ambient-code-backend/logging"
+	"ambient-code-backend/types"
+
+	"github.com/google/uuid"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// resubmitRunAnnotation is set by the operator when a spot runner was preempted
+// mid-run. The backend clears it and resubmits the interrupted run.
+// IMPORTANT: Keep in sync with operator (internal/handlers/spot.go)
+const resubmitRunAnnotation = "ambient-code.io/resubmit-interrupted-run"
+
+const (
+	runRecoveryPollInterval = 10 * time.Second
+	// runRecoveryTimeout bounds how long we wait for the session to come back
+	// on replacement capacity.
+	runRecoveryTimeout = 30 * time.Minute
+	// runRecoveryGracePeriod is how long we wait for the operator to flag the
+	// session for resubmission before treating the interruption as final.
+	runRecoveryGracePeriod = 2 * time.Minute
+)
+
+// runRecoveries holds the sessions with a recovery poller in this process
+var runRecoveries sync.Map
+
+// watchForRunRecovery waits for an interrupted session to be restarted by the
+// operator and resubmits the run as a child of the interrupted one. runCtx carries
+// the request ID of the interrupted run, which the resubmitted run keeps. A session
+// has at most one poller: a second interruption while one waits is left to it.
+func watchForRunRecovery(runCtx context.Context, projectName, sessionName string, input types.RunAgentInput) {
+	if handlers.DynamicClient == nil {
+		return
+	}
+	key := projectName + "/" + sessionName
+	if _, busy := runRecoveries.LoadOrStore(key, input.RunID); busy {
+		logging.Infof(runCtx, "RunRecovery: %s already waiting for recovery, not watching run %s", key, input.RunID)
+		return
+	}
+	defer runRecoveries.Delete(key)
+	gvr := handlers.GetAgenticSessionV1Alpha1Resource()
+	deadline := time.Now().Add(runRecoveryTimeout)
+	graceDeadline := time.Now().Add(runRecoveryGracePeriod)
+	flagged := false
+
+	for time.Now().Before(deadline) {
+		time.Sleep(runRecoveryPollInterval)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		item, err := handlers.GetCachedSession(ctx, projectName, sessionName)
+		cancel()
+		if err != nil {
+			if errors.IsNotFound(err) {
+				return
+			}
+			logging.Errorf(runCtx, "RunRecovery: Failed to get session %s/%s: %v", projectName, sessionName, err)
+			continue
+		}
+
+		annotations := item.GetAnnotations()
+		phase, _, _ := unstructured.NestedString(item.Object, "status", "phase")
+
+		if annotations[resubmitRunAnnotation] != "true" {
+			if !flagged && time.Now().After(graceDeadline) {
+				logging.Warnf(runCtx, "RunRecovery: Run %s in %s/%s was not flagged for resubmission, giving up", input.RunID, projectName, sessionName)
+				return
+			}
+			continue
+		}
+		flagged = true
+		if phase != "Running" {
+			continue
+		}
+
+		// Clear the flag first; the resourceVersion check ensures only one
+		// backend replica resubmits the run.
+		delete(annotations, resubmitRunAnnotation)
+		item.SetAnnotations(annotations)
+		ctx, cancel = context.WithTimeout(context.Background(), 10*time.Second)
+		_, err = handlers.DynamicClient.Resource(gvr).Namespace(projectName).Update(ctx, item, metav1.UpdateOptions{})
+		cancel()
+		if err != nil {
+			if errors.IsConflict(err) {
+				continue
+			}
+			logging.Errorf(runCtx, "RunRecovery: Failed to clear resubmit flag on %s/%s: %v", projectName, sessionName, err)
+			return
+		}
+
+		resubmit := input
+		resubmit.ParentRunID = input.RunID
+		resubmit.RunID = uuid.New().String()
+		if _, err := startRunStream(runCtx, projectName, sessionName, resubmit); err != nil {
+			logging.Errorf(runCtx, "RunRecovery: Failed to resubmit run %s for %s/%s: %v", input.RunID, projectName, sessionName, err)
+			return
+		}
+		logging.Infof(runCtx, "RunRecovery: Resubmitted interrupted run %s as %s for %s/%s", input.RunID, resubmit.RunID, projectName, sessionName)
+		return
+	}
+	logging.Errorf(runCtx, "RunRecovery: Timed out waiting for %s/%s to recover run %s", projectName, sessionName, input.RunID)
+}
+
+// startRunWhenResumed starts a run on a session resumed from an idle pause once the
+// operator reports its runner Running again. runCtx carries the run request's ID.
+func startRunWhenResumed(runCtx context.Context, projectName, sessionName string, input types.RunAgentInput) {
+	deadline := time.Now().Add(runRecoveryTimeout)
+	for time.Now().Before(deadline) {
+		time.Sleep(runRecoveryPollInterval)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		item, err := handlers.GetCachedSession(ctx, projectName, sessionName)
+		cancel()
+		if err != nil {
+			if errors.IsNotFound(err) {
+				return
+			}
+			logging.Errorf(runCtx, "IdleResume: Failed to get session %s/%s: %v", projectName, sessionName, err)
+			continue
+		}
+		if phase, _, _ := unstructured.NestedString(item.Object, "status", "phase"); phase != "Running" {
+			continue
+		}
+		if _, err := startRunStream(runCtx, projectName, sessionName, input); err != nil {
+			logging.Errorf(runCtx, "IdleResume: Failed to start run %s on resumed %s/%s: %v", input.RunID, projectName, sessionName, err)
+			return
+		}
+		logging.Infof(runCtx, "IdleResume: Started run %s on resumed %s/%s", input.RunID, projectName, sessionName)
+		return
+	}
+	logging.Errorf(runCtx, "IdleResume: Timed out waiting for %s/%s to resume, run %s not started", projectName, sessionName, input.RunID)
+}
@@ -0,0 +1,110 @@
+package websocket
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"ambient-code-backend/logging"
+	"ambient-code-backend/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	minReplaySpeed = 0.1
+	maxReplaySpeed = 100
+	// maxReplayGap caps the (scaled) pause between replayed events, so a run that sat
+	// waiting on a long tool call or for input doesn't stall its replay
+	maxReplayGap = 10 * time.Second
+)
+
+// parseReplaySpeed parses a replay speed such as "2x", "0.5x" or "3"; empty means 1x
+func parseReplaySpeed(s string) (float64, error) {
+	if s == "" {
+		return 1, nil
+	}
+	speed, err := strconv.ParseFloat(strings.TrimSuffix(strings.ToLower(s), "x"), 64)
+	if err != nil || speed < minReplaySpeed || speed > maxReplaySpeed {
+		return 0, fmt.Errorf("speed must be between %gx and %gx", float64(minReplaySpeed), float64(maxReplaySpeed))
+	}
+	return speed, nil
+}
+
+// replayDelay is the pause before an event at next, following one at prev, when
+// replaying at speed. Events without a timestamp follow immediately.
+func replayDelay(prev, next string, speed float64) time.Duration {
+	from, err := time.Parse(types.AGUITimestampFormat, prev)
+	if err != nil {
+		return 0
+	}
+	to, err := time.Parse(types.AGUITimestampFormat, next)
+	if err != nil || !to.After(from) {
+		return 0
+	}
+	return min(time.Duration(float64(to.Sub(from))/speed), maxReplayGap)
+}
+
+// HandleAGUIRunReplay handles GET /api/projects/:projectName/agentic-sessions/:sessionName/agui/runs/:runId/replay
+// Re-streams a run's persisted events over SSE with their original pauses, scaled by
+// ?speed= (e.g. 2x), to show how the run unfolded
+func HandleAGUIRunReplay(c *gin.Context) {
+	sessionName := c.Param("sessionName")
+	runID := c.Param("runId")
+	if !isValidSessionName(sessionName) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session name"})
+		return
+	}
+	speed, err := parseReplaySpeed(c.Query("speed"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	found := false
+	for _, run := range getRunsForSession(sessionName) {
+		if run.RunID == runID {
+			found = true
+			break
+		}
+	}
+	if !found {
+		c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("Run %s not found", runID)})
+		return
+	}
+	events, err := loadEventsForRun(sessionName, runID)
+	if err != nil {
+		logging.Errorf(c, "RunReplay: Failed to load events of run %s for %s: %v", runID, sessionName, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load run events"})
+		return
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+
+	ctx := c.Request.Context()
+	prev := ""
+	for i, event := range events {
+		ts, _ := event["timestamp"].(string)
+		if i > 0 {
+			if delay := replayDelay(prev, ts, speed); delay > 0 {
+				timer := time.NewTimer(delay)
+				select {
+				case <-ctx.Done():
+					timer.Stop()
+					return
+				case <-timer.C:
+				}
+			}
+		}
+		if ts != "" {
+			prev = ts
+		}
+		writeSSEEvent(c.Writer, event)
+	}
+}
@@ -0,0 +1,98 @@
+package websocket
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestParseReplaySpeed(t *testing.T) {
+	for in, want := range map[string]float64{"": 1, "2x": 2, "0.5X": 0.5, "3": 3} {
+		if got, err := parseReplaySpeed(in); err != nil || got != want {
+			t.Errorf("parseReplaySpeed(%q) = %v, %v; want %v", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"fast", "0x", "-1x", "1000x"} {
+		if _, err := parseReplaySpeed(in); err == nil {
+			t.Errorf("parseReplaySpeed(%q) accepted", in)
+		}
+	}
+}
+
+func TestReplayDelay(t *testing.T) {
+	at := func(d time.Duration) string {
+		return time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC).Add(d).Format(time.RFC3339Nano)
+	}
+	tests := []struct {
+		prev, next string
+		speed      float64
+		want       time.Duration
+	}{
+		{at(0), at(2 * time.Second), 1, 2 * time.Second},
+		{at(0), at(2 * time.Second), 2, time.Second},
+		{at(0), at(time.Hour), 1, maxReplayGap},
+		{at(time.Second), at(0), 1, 0},
+		{"", at(0), 1, 0},
+		{at(0), "not a time", 1, 0},
+	}
+	for _, tt := range tests {
+		if got := replayDelay(tt.prev, tt.next, tt.speed); got != tt.want {
+			t.Errorf("replayDelay(%q, %q, %v) = %v; want %v", tt.prev, tt.next, tt.speed, got, tt.want)
+		}
+	}
+}
+
+func TestHandleAGUIRunReplay(t *testing.T) {
+	StateBaseDir = t.TempDir()
+	dir := StateBaseDir + "/sessions/s1"
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	events := strings.Join([]string{
+		`{"type":"RUN_STARTED","runId":"r1","timestamp":"2026-10-16T12:00:00Z"}`,
+		`{"type":"RUN_STARTED","runId":"r0","timestamp":"2026-10-16T11:00:00Z"}`,
+		`{"type":"TEXT_MESSAGE_CONTENT","runId":"r1","delta":"Hi","timestamp":"2026-10-16T12:00:00.2Z"}`,
+		`{"type":"RUN_FINISHED","runId":"r1","timestamp":"2026-10-16T12:00:00.4Z"}`,
+	}, "\n") + "\n"
+	if err := os.WriteFile(dir+"/agui-events.jsonl", []byte(events), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(dir+"/agui-runs.jsonl", []byte(`{"runId":"r1","threadId":"s1","status":"completed"}`+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	replay := func(runID, speed string) *httptest.ResponseRecorder {
+		gin.SetMode(gin.TestMode)
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, "/?speed="+speed, nil)
+		c.Params = gin.Params{{Key: "sessionName", Value: "s1"}, {Key: "runId", Value: runID}}
+		HandleAGUIRunReplay(c)
+		return w
+	}
+
+	start := time.Now()
+	w := replay("r1", "4x")
+	if elapsed := time.Since(start); elapsed < 80*time.Millisecond || elapsed > 2*time.Second {
+		t.Errorf("replay at 4x took %v; want about 100ms", elapsed)
+	}
+	body := w.Body.String()
+	if w.Code != http.StatusOK || strings.Count(body, "data: ") != 3 || strings.Contains(body, `"r0"`) {
+		t.Fatalf("replay = %d %s", w.Code, body)
+	}
+	if strings.Index(body, "RUN_STARTED") > strings.Index(body, "TEXT_MESSAGE_CONTENT") {
+		t.Errorf("events replayed out of order: %s", body)
+	}
+
+	if w := replay("missing", ""); w.Code != http.StatusNotFound {
+		t.Errorf("unknown run = %d", w.Code)
+	}
+	if w := replay("r1", "fast"); w.Code != http.StatusBadRequest {
+		t.Errorf("invalid speed = %d", w.Code)
+	}
+}
@@ -0,0 +1,156 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"ambient-code-backend/handlers"
+	"ambient-code-backend/logging"
+	"ambient-code-backend/types"
+)
+
+// maxSummaryTranscript bounds the transcript sent for summarization; longer runs keep
+// their end, where the outcome is
+const maxSummaryTranscript = 24000
+
+// fileEditTools are the runner's built-in tools that write the file named by their
+// file_path (or notebook_path) argument
+var fileEditTools = map[string]bool{"Write": true, "Edit": true, "MultiEdit": true, "NotebookEdit": true}
+
+func runSummariesPath(sessionID string) string {
+	return fmt.Sprintf("%s/sessions/%s/agui-run-summaries.jsonl", StateBaseDir, sessionID)
+}
+
+// summarizeRun queues summarization of a completed run. Runs on persistPool after the
+// run's events are persisted.
+func summarizeRun(projectName, sessionID, runID string) {
+	events, err := loadEventsForRun(sessionID, "")
+	if err != nil {
+		logging.Errorf(context.Background(), "RunSummary: Failed to load events for %s: %v", sessionID, err)
+		return
+	}
+	var runEvents []map[string]interface{}
+	for _, event := range events {
+		if event["runId"] == runID {
+			runEvents = append(runEvents, event)
+		}
+	}
+	messages := ThreadMessages(runEvents)
+	if len(messages) == 0 {
+		return
+	}
+
+	transcript, files := runTranscript(messages)
+	handlers.SummarizeRunAsync(projectName, sessionID, runID, transcript, files, func(summary types.RunSummary) {
+		persistPool.Submit(sessionID, func() { persistRunSummary(sessionID, summary) })
+	})
+}
+
+// runTranscript renders a run's messages as plain text, listing tool calls by name,
+// and collects the files its edit tools wrote
+func runTranscript(messages []types.Message) (string, []string) {
+	var b strings.Builder
+	seen := map[string]bool{}
+	var files []string
+	for _, msg := range messages {
+		switch msg.Role {
+		case types.RoleUser, types.RoleAssistant:
+			if msg.Content != "" {
+				fmt.Fprintf(&b, "%s: %s\n", msg.Role, msg.Content)
+			}
+		}
+		for _, tc := range msg.ToolCalls {
+			fmt.Fprintf(&b, "[tool %s]\n", tc.Name)
+			if !fileEditTools[tc.Name] {
+				continue
+			}
+			var args struct {
+				FilePath     string `json:"file_path"`
+				NotebookPath string `json:"notebook_path"`
+			}
+			if json.Unmarshal([]byte(tc.Args), &args) != nil {
+				continue
+			}
+			path := args.FilePath
+			if path == "" {
+				path = args.NotebookPath
+			}
+			if path != "" && !seen[path] {
+				seen[path] = true
+				files = append(files, path)
+			}
+		}
+	}
+	sort.Strings(files)
+
+	transcript := b.String()
+	if len(transcript) > maxSummaryTranscript {
+		transcript = "...\n" + transcript[len(transcript)-maxSummaryTranscript:]
+	}
+	return transcript, files
+}
+
+func persistRunSummary(sessionID string, summary types.RunSummary) {
+	data, err := json.Marshal(summary)
+	if err != nil {
+		logging.Errorf(context.Background(), "RunSummary: failed to marshal summary: %v", err)
+		return
+	}
+	f, err := openFileAppend(runSummariesPath(sessionID))
+	if err != nil {
+		logging.Errorf(context.Background(), "RunSummary: failed to open summaries of %s: %v", sessionID, err)
+		return
+	}
+	defer f.Close()
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		logging.Errorf(context.Background(), "RunSummary: failed to write summary: %v", err)
+	}
+}
+
+// loadRunSummaries returns the session's run summaries by run ID, the latest winning
+func loadRunSummaries(sessionID string) map[string]*types.RunSummary {
+	summaries := map[string]*types.RunSummary{}
+	data, err := os.ReadFile(runSummariesPath(sessionID))
+	if err != nil {
+		if !os.IsNotExist(err) {
+			logging.Errorf(context.Background(), "RunSummary: failed to read summaries of %s: %v", sessionID, err)
+		}
+		return summaries
+	}
+	for _, line := range splitLines(data) {
+		var summary types.RunSummary
+		if len(line) == 0 || json.Unmarshal(line, &summary) != nil {
+			continue
+		}
+		summaries[summary.RunID] = &summary
+	}
+	return summaries
+}
+
+// LatestRunSummary returns the summary persisted last for the session, or nil. Set on
+// handlers.LatestRunSummary from main.
+func LatestRunSummary(sessionName string) *types.RunSummary {
+	if !isValidSessionName(sessionName) {
+		return nil
+	}
+	data, err := os.ReadFile(runSummariesPath(sessionName))
+	if err != nil {
+		if !os.IsNotExist(err) {
+			logging.Errorf(context.Background(), "RunSummary: failed to read summaries of %s: %v", sessionName, err)
+		}
+		return nil
+	}
+	var latest *types.RunSummary
+	for _, line := range splitLines(data) {
+		var summary types.RunSummary
+		if len(line) == 0 || json.Unmarshal(line, &summary) != nil {
+			continue
+		}
+		latest = &summary
+	}
+	return latest
+}
@@ -0,0 +1,20 @@
+package websocket
+
+import (
+	"testing"
+
+	"ambient-code-backend/types"
+)
+
+func TestLatestRunSummary(t *testing.T) {
+	StateBaseDir = t.TempDir()
+	if got := LatestRunSummary("s1"); got != nil {
+		t.Fatalf("LatestRunSummary without summaries = %+v, want nil", got)
+	}
+	writeSessionFile(t, "s1", "agui-runs.jsonl", "")
+	persistRunSummary("s1", types.RunSummary{RunID: "r1", Summary: "First run."})
+	persistRunSummary("s1", types.RunSummary{RunID: "r2", Summary: "Second run."})
+	if got := LatestRunSummary("s1"); got == nil || got.RunID != "r2" {
+		t.Fatalf("LatestRunSummary = %+v, want r2", got)
+	}
+}
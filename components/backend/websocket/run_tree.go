@@ -0,0 +1,109 @@
+package websocket
+
+import (
+	"fmt"
+	"net/http"
+
+	"ambient-code-backend/types"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RunTreeNode is a run with the runs started from it: sub-agents, model fallback
+// retries and recovery resubmits
+type RunTreeNode struct {
+	types.AGUIRunMetadata
+	Children []*RunTreeNode `json:"children,omitempty"`
+	parent   *RunTreeNode
+}
+
+// buildRunTree nests runs under the run they were started from and indexes the nodes
+// by run ID. Runs are in start order and only attach to an earlier run, so the tree
+// has no cycles.
+func buildRunTree(runs []types.AGUIRunMetadata) ([]*RunTreeNode, map[string]*RunTreeNode) {
+	nodes := map[string]*RunTreeNode{}
+	order := []*RunTreeNode{}
+	for _, run := range runs {
+		// The runs index has a line per status change; the last one wins
+		if node, ok := nodes[run.RunID]; ok {
+			node.AGUIRunMetadata = run
+			continue
+		}
+		node := &RunTreeNode{AGUIRunMetadata: run}
+		nodes[run.RunID] = node
+		order = append(order, node)
+	}
+
+	roots := []*RunTreeNode{}
+	seen := map[string]bool{}
+	for _, node := range order {
+		if parent, ok := nodes[node.ParentRunID]; ok && seen[node.ParentRunID] {
+			parent.Children = append(parent.Children, node)
+			node.parent = parent
+		} else {
+			roots = append(roots, node)
+		}
+		seen[node.RunID] = true
+	}
+	return roots, nodes
+}
+
+// runTree nests runs under the run they were started from
+func runTree(runs []types.AGUIRunMetadata) []*RunTreeNode {
+	roots, _ := buildRunTree(runs)
+	return roots
+}
+
+// runLineage returns the ancestors of a run, root first, and the run with its
+// descendants, or nil when there is no such run
+func runLineage(runs []types.AGUIRunMetadata, runID string) ([]types.AGUIRunMetadata, *RunTreeNode) {
+	_, nodes := buildRunTree(runs)
+	node, ok := nodes[runID]
+	if !ok {
+		return nil, nil
+	}
+	ancestors := []types.AGUIRunMetadata{}
+	for p := node.parent; p != nil; p = p.parent {
+		ancestors = append([]types.AGUIRunMetadata{p.AGUIRunMetadata}, ancestors...)
+	}
+	return ancestors, node
+}
+
+// HandleAGUIRunTree handles GET /api/projects/:projectName/agentic-sessions/:sessionName/agui/runs/tree
+// Returns the session's runs nested by parent run, so parallel sub-agent work renders
+// as branches of the run that started it
+func HandleAGUIRunTree(c *gin.Context) {
+	sessionName := c.Param("sessionName")
+	if !isValidSessionName(sessionName) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session name"})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"threadId": sessionName,
+		"runs":     runTree(getRunsForSession(sessionName)),
+	})
+}
+
+// HandleAGUIRunLineage handles GET /api/projects/:projectName/agentic-sessions/:sessionName/agui/runs/:runId/tree
+// Returns the run's ancestor chain (root first) and the run with its descendants, so
+// retries, forks and sub-agents can be shown in context
+func HandleAGUIRunLineage(c *gin.Context) {
+	sessionName := c.Param("sessionName")
+	runID := c.Param("runId")
+	if !isValidSessionName(sessionName) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid session name"})
+		return
+	}
+
+	ancestors, run := runLineage(getRunsForSession(sessionName), runID)
+	if run == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": fmt.Sprintf("Run %s not found", runID)})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"threadId":  sessionName,
+		"ancestors": ancestors,
+		"run":       run,
+	})
+}
@@ -0,0 +1,63 @@
+package websocket
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"ambient-code-backend/config"
+)
+
+// Runner calls share one transport. Its pool is keyed by host, and every session has
+// its own runner Service host, so each session keeps a few warm connections that its
+// runs, interrupts, feedback and MCP status calls reuse instead of dialing per call.
+// It is built on first use, after the configuration has loaded.
+var runnerTransport = sync.OnceValue(func() *http.Transport {
+	return newRunnerTransport(config.Current().RunnerHTTP2)
+})
+
+// newRunnerTransport returns the transport for runner calls. HTTPS runners negotiate
+// HTTP/2 through ALPN. Runners are normally reached over plain HTTP, where HTTP/2
+// can't be negotiated; h2c uses it with prior knowledge, so only enable it when every
+// runner image serves HTTP/2 cleartext.
+func newRunnerTransport(h2c bool) *http.Transport {
+	t := &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   5 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		ForceAttemptHTTP2:     true,
+		MaxIdleConns:          512,
+		MaxIdleConnsPerHost:   8,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+	if h2c {
+		t.Protocols = new(http.Protocols)
+		t.Protocols.SetHTTP2(true)
+		t.Protocols.SetUnencryptedHTTP2(true)
+	}
+	return t
+}
+
+// runnerClient returns a client for a short runner call bounded by timeout
+func runnerClient(timeout time.Duration) *http.Client {
+	return &http.Client{Timeout: timeout, Transport: runnerTransport()}
+}
+
+// runnerStreamClient returns a client without a timeout; run streams are bounded by
+// their context
+func runnerStreamClient() *http.Client {
+	return &http.Client{Transport: runnerTransport()}
+}
+
+// closeRunnerResponse drains and closes resp's body so its connection returns to
+// the pool. Bodies larger than the limit aren't worth reading and are discarded.
+func closeRunnerResponse(resp *http.Response) {
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	resp.Body.Close()
+}
@@ -0,0 +1,161 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"ambient-code-backend/logging"
+	"ambient-code-backend/types"
+
+	"github.com/google/uuid"
+)
+
+// Runner protocol versions. Runners report theirs on GET /version; runners that
+// predate the handshake don't serve it and speak RunnerProtocolLegacy.
+const (
+	RunnerProtocolLegacy  = "0"
+	RunnerProtocolCurrent = "1"
+)
+
+// RunnerCapabilityUserMessageEcho means the runner streams a run's user messages
+// back as TEXT_MESSAGE events; otherwise the proxy persists them itself
+const RunnerCapabilityUserMessageEcho = "userMessageEcho"
+
+// RunnerVersion is a runner's answer to GET /version
+type RunnerVersion struct {
+	RunnerVersion   string   `json:"runnerVersion"`
+	ProtocolVersion string   `json:"protocolVersion"`
+	Capabilities    []string `json:"capabilities"`
+}
+
+// Has reports whether the runner announced capability
+func (v RunnerVersion) Has(capability string) bool {
+	for _, c := range v.Capabilities {
+		if c == capability {
+			return true
+		}
+	}
+	return false
+}
+
+// legacyRunnerVersion describes runners without GET /version, which echo user messages
+var legacyRunnerVersion = RunnerVersion{
+	ProtocolVersion: RunnerProtocolLegacy,
+	Capabilities:    []string{RunnerCapabilityUserMessageEcho},
+}
+
+var (
+	// Negotiated versions by runner URL; a runner keeps its version until its pod
+	// goes away, so it is asked once per session start
+	runnerVersions   = make(map[string]RunnerVersion)
+	runnerVersionsMu sync.Mutex
+)
+
+// negotiateRunnerVersion returns the protocol version and capabilities of the runner
+// at runnerURL, asking its GET /version the first time. Runners that answer 404
+// predate the handshake; when the runner can't be asked, the legacy behavior is
+// assumed for this run only.
+func negotiateRunnerVersion(ctx context.Context, runnerURL string) RunnerVersion {
+	runnerVersionsMu.Lock()
+	v, ok := runnerVersions[runnerURL]
+	runnerVersionsMu.Unlock()
+	if ok {
+		return v
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(runnerURL, "/")+"/version", nil)
+	if err != nil {
+		return legacyRunnerVersion
+	}
+	logging.SetRequestIDHeader(req)
+	resp, err := runnerClient(5 * time.Second).Do(req)
+	if err != nil {
+		logging.Warnf(ctx, "AGUI Proxy: Runner version handshake failed, assuming protocol %s: %v", RunnerProtocolLegacy, err)
+		return legacyRunnerVersion
+	}
+	defer closeRunnerResponse(resp)
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		v = legacyRunnerVersion
+	case resp.StatusCode != http.StatusOK:
+		logging.Warnf(ctx, "AGUI Proxy: Runner version handshake returned %d, assuming protocol %s", resp.StatusCode, RunnerProtocolLegacy)
+		return legacyRunnerVersion
+	default:
+		if err := json.NewDecoder(resp.Body).Decode(&v); err != nil || v.ProtocolVersion == "" {
+			logging.Warnf(ctx, "AGUI Proxy: Invalid runner version response, assuming protocol %s: %v", RunnerProtocolLegacy, err)
+			return legacyRunnerVersion
+		}
+		if v.ProtocolVersion > RunnerProtocolCurrent {
+			logging.Warnf(ctx, "AGUI Proxy: Runner speaks protocol %s, newer than %s; relying on its capabilities", v.ProtocolVersion, RunnerProtocolCurrent)
+		}
+	}
+
+	runnerVersionsMu.Lock()
+	runnerVersions[runnerURL] = v
+	runnerVersionsMu.Unlock()
+	return v
+}
+
+// forgetRunnerVersion drops the negotiated version of a runner that went away; its
+// replacement may run another image
+func forgetRunnerVersion(runnerURL string) {
+	runnerVersionsMu.Lock()
+	delete(runnerVersions, runnerURL)
+	runnerVersionsMu.Unlock()
+}
+
+// recordRunnerVersion applies a negotiated version to a run and persists it on the
+// run metadata
+func recordRunnerVersion(runState *AGUIRunState, v RunnerVersion) {
+	runState.echoUserMessages = !v.Has(RunnerCapabilityUserMessageEcho)
+	aguiRunsMu.Lock()
+	runState.ProtocolVersion, runState.RunnerVersion = v.ProtocolVersion, v.RunnerVersion
+	status := runState.Status
+	aguiRunsMu.Unlock()
+	updateRunStatus(runState.RunID, status)
+}
+
+// echoUserMessages streams the run's user messages as TEXT_MESSAGE events for
+// runners that don't, so they are persisted and replayed like the runner's output
+func echoUserMessages(sessionID, runID, threadID string, runState *AGUIRunState) {
+	for _, msg := range runState.input.Messages {
+		if msg.Role != types.RoleUser {
+			continue
+		}
+		messageID := msg.ID
+		if messageID == "" {
+			messageID = uuid.New().String()
+		}
+		var events []types.TypedEvent
+		if metadata, ok := msg.Metadata.(map[string]interface{}); ok && metadata["hidden"] == true {
+			events = append(events, &types.RawEvent{
+				BaseEvent: types.BaseEvent{Type: types.EventTypeRaw},
+				Event: map[string]interface{}{
+					"type":      "message_metadata",
+					"messageId": messageID,
+					"metadata":  metadata,
+					"hidden":    true,
+				},
+			})
+		}
+		events = append(events, &types.TextMessageStartEvent{BaseEvent: types.BaseEvent{Type: types.EventTypeTextMessageStart, MessageID: messageID}, Role: types.RoleUser})
+		if msg.Content != "" {
+			events = append(events, &types.TextMessageContentEvent{BaseEvent: types.BaseEvent{Type: types.EventTypeTextMessageContent, MessageID: messageID}, Delta: msg.Content})
+		}
+		events = append(events, &types.TextMessageEndEvent{BaseEvent: types.BaseEvent{Type: types.EventTypeTextMessageEnd, MessageID: messageID}})
+		for _, event := range events {
+			data, err := json.Marshal(event)
+			if err != nil {
+				continue
+			}
+			handleStreamedEvent(sessionID, runID, threadID, data, runState)
+		}
+	}
+}
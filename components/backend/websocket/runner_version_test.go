@@ -0,0 +1,85 @@
+package websocket
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"ambient-code-backend/pkg/mockrunner"
+	"ambient-code-backend/types"
+)
+
+func TestNegotiateRunnerVersion(t *testing.T) {
+	current := httptest.NewServer(mockrunner.New())
+	legacy := httptest.NewServer(http.NotFoundHandler())
+	defer legacy.Close()
+
+	v := negotiateRunnerVersion(context.Background(), current.URL+"/")
+	if v.ProtocolVersion != mockrunner.ProtocolVersion || v.RunnerVersion != "mock" || v.Has(RunnerCapabilityUserMessageEcho) {
+		t.Errorf("mock runner version = %+v", v)
+	}
+	// Negotiated once per runner
+	current.Close()
+	if v := negotiateRunnerVersion(context.Background(), current.URL+"/"); v.RunnerVersion != "mock" {
+		t.Errorf("cached version = %+v", v)
+	}
+	forgetRunnerVersion(current.URL + "/")
+	if v := negotiateRunnerVersion(context.Background(), current.URL+"/"); v.ProtocolVersion != RunnerProtocolLegacy {
+		t.Errorf("unreachable runner version = %+v", v)
+	}
+	if _, cached := runnerVersions[current.URL+"/"]; cached {
+		t.Error("version of an unreachable runner was cached")
+	}
+
+	if v := negotiateRunnerVersion(context.Background(), legacy.URL+"/"); v.ProtocolVersion != RunnerProtocolLegacy || !v.Has(RunnerCapabilityUserMessageEcho) {
+		t.Errorf("legacy runner version = %+v", v)
+	}
+}
+
+func TestProxyEchoesUserMessages(t *testing.T) {
+	events := []string{
+		`{"type":"RUN_STARTED"}`,
+		`{"type":"TEXT_MESSAGE_START","messageId":"m1","role":"assistant"}`,
+		`{"type":"TEXT_MESSAGE_CONTENT","messageId":"m1","delta":"Hi"}`,
+		`{"type":"TEXT_MESSAGE_END","messageId":"m1"}`,
+		`{"type":"RUN_FINISHED"}`,
+	}
+	input := []types.Message{
+		{ID: "u1", Role: types.RoleUser, Content: "Hello"},
+		{ID: "a0", Role: types.RoleAssistant, Content: "earlier"},
+	}
+
+	tests := []struct {
+		name      string
+		echo      bool
+		persisted int
+	}{
+		{name: "runner echoes", echo: false, persisted: 5},
+		{name: "proxy echoes", echo: true, persisted: 8},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			run := streamThroughProxy(t, events, func(state *AGUIRunState) {
+				state.input.Messages = input
+				state.echoUserMessages = tt.echo
+			})
+			if len(run.persisted) != tt.persisted {
+				t.Fatalf("persisted %v, want %d events", persistedTypes(run), tt.persisted)
+			}
+			if run.violations != 0 {
+				t.Errorf("%v conformance violations", run.violations)
+			}
+			if !tt.echo {
+				return
+			}
+			if run.persisted[1]["messageId"] != "u1" || run.persisted[1]["role"] != types.RoleUser {
+				t.Errorf("echoed start = %v", run.persisted[1])
+			}
+			messages := CompactEvents(run.persisted)
+			if len(messages) != 2 || messages[0].ID != "u1" || messages[0].Content != "Hello" || messages[1].Content != "Hi" {
+				t.Errorf("compacted messages = %+v", messages)
+			}
+		})
+	}
+}
@@ -0,0 +1,175 @@
+package websocket
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"ambient-code-backend/logging"
+	"ambient-code-backend/types"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const (
+	// maxSubAgentRuns bounds the sub-agent runs registered within one runner stream
+	maxSubAgentRuns = 100
+	// maxBranchIDLength bounds runner-provided run and branch IDs
+	maxBranchIDLength = 128
+	// maxAgentNameLength bounds the sub-agent name shown in the run tree
+	maxAgentNameLength = 200
+)
+
+// HandleRegisterChildRun handles POST /api/projects/:projectName/agentic-sessions/:sessionName/agui/runs/:runId/children
+// The runner registers a sub-agent run before streaming its events within the stream
+// of run :runId, so the proxy tracks it as its own run and tags its events with branchId
+func HandleRegisterChildRun(c *gin.Context) {
+	projectName := c.Param("projectName")
+	sessionName := c.Param("sessionName")
+	parentRunID := c.Param("runId")
+
+	var req struct {
+		RunID    string `json:"runId"`
+		BranchID string `json:"branchId"`
+		Name     string `json:"name"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid request: %v", err)})
+		return
+	}
+	if len(req.RunID) > maxBranchIDLength || len(req.BranchID) > maxBranchIDLength || len(req.Name) > maxAgentNameLength {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "runId, branchId or name too long"})
+		return
+	}
+	if req.RunID == "" {
+		req.RunID = uuid.New().String()
+	}
+	if req.BranchID == "" {
+		req.BranchID = req.RunID
+	}
+
+	aguiRunsMu.Lock()
+	parent := aguiRuns[parentRunID]
+	if parent == nil || parent.SessionID != sessionName || parent.Status != "running" {
+		aguiRunsMu.Unlock()
+		c.JSON(http.StatusNotFound, gin.H{"error": "Parent run not found or not running"})
+		return
+	}
+	if _, exists := aguiRuns[req.RunID]; exists {
+		aguiRunsMu.Unlock()
+		c.JSON(http.StatusConflict, gin.H{"error": fmt.Sprintf("Run %s already exists", req.RunID)})
+		return
+	}
+	// Nested sub-agents stream through the same runner stream as their parent
+	streamRunID := parent.RunID
+	if parent.streamRunID != "" {
+		streamRunID = parent.streamRunID
+	}
+	registered := 0
+	for _, state := range aguiRuns {
+		if state.streamRunID == streamRunID {
+			registered++
+		}
+	}
+	if registered >= maxSubAgentRuns {
+		aguiRunsMu.Unlock()
+		c.JSON(http.StatusTooManyRequests, gin.H{"error": fmt.Sprintf("At most %d sub-agent runs per run", maxSubAgentRuns), "code": types.ErrorCodeLimitExceeded})
+		return
+	}
+	child := &AGUIRunState{
+		ThreadID:     parent.ThreadID,
+		RunID:        req.RunID,
+		ParentRunID:  parent.RunID,
+		BranchID:     req.BranchID,
+		AgentName:    req.Name,
+		SessionID:    sessionName,
+		ProjectName:  projectName,
+		RequestID:    parent.RequestID,
+		Status:       "running",
+		StartedAt:    time.Now(),
+		subscribers:  make(map[chan *types.BaseEvent]bool),
+		fullEventSub: make(map[chan interface{}]bool),
+		toolPolicy:   parent.toolPolicy,
+		egressPolicy: parent.egressPolicy,
+		streamRunID:  streamRunID,
+	}
+	aguiRuns[child.RunID] = child
+	aguiRunsMu.Unlock()
+
+	meta := types.AGUIRunMetadata{
+		ThreadID:    child.ThreadID,
+		RunID:       child.RunID,
+		ParentRunID: child.ParentRunID,
+		BranchID:    child.BranchID,
+		AgentName:   child.AgentName,
+		SessionName: sessionName,
+		ProjectName: projectName,
+		RequestID:   child.RequestID,
+		StartedAt:   child.StartedAt.Format(time.RFC3339),
+		Status:      "running",
+	}
+	persistPool.Submit(sessionName, func() { persistRunMetadata(sessionName, meta) })
+
+	runStarted := &types.RunStartedEvent{
+		BaseEvent: types.NewBaseEvent(types.EventTypeRunStarted, child.ThreadID, child.RunID).WithParentRunID(child.ParentRunID),
+	}
+	runStarted.BranchID = child.BranchID
+	if event, err := types.NewEvent(runStarted); err == nil {
+		persistPool.Submit(sessionName, func() { persistAGUIEvent(sessionName, child.RunID, event) })
+		broadcastToThread(sessionName, event)
+	}
+
+	logging.Infof(c, "AGUI Proxy: Registered sub-agent run %s (branch %s) of run %s in %s/%s",
+		child.RunID, child.BranchID, parentRunID, projectName, sessionName)
+	c.JSON(http.StatusCreated, gin.H{
+		"runId":       child.RunID,
+		"parentRunId": child.ParentRunID,
+		"branchId":    child.BranchID,
+	})
+}
+
+// subAgentRun returns the registered sub-agent run streaming within stream whose ID
+// the event carries, or nil for the stream's own events
+func subAgentRun(stream *AGUIRunState, runID string) *AGUIRunState {
+	if stream == nil || runID == "" || runID == stream.RunID {
+		return nil
+	}
+	aguiRunsMu.RLock()
+	defer aguiRunsMu.RUnlock()
+	if state, ok := aguiRuns[runID]; ok && state.streamRunID == stream.RunID {
+		return state
+	}
+	return nil
+}
+
+// tagBranch sets the sub-agent branch on an event
+func tagBranch(event *types.Event, branchID string) {
+	base := event.Base()
+	if base.BranchID == branchID {
+		return
+	}
+	if err := event.SetField("branchId", branchID); err != nil {
+		logging.Errorf(context.Background(), "AGUI Proxy: Failed to tag event with branch %s: %v", branchID, err)
+		return
+	}
+	base.BranchID = branchID
+}
+
+// finishSubAgentRuns ends the sub-agent runs of a stream that are still running when
+// the stream ends, with the status of the stream's run
+func finishSubAgentRuns(streamRunID, status string) {
+	var running []string
+	aguiRunsMu.RLock()
+	for _, state := range aguiRuns {
+		if state.streamRunID == streamRunID && state.Status == "running" {
+			running = append(running, state.RunID)
+		}
+	}
+	aguiRunsMu.RUnlock()
+	for _, runID := range running {
+		updateRunStatus(runID, status)
+		scheduleRunCleanup(runID, 5*time.Minute)
+	}
+}
@@ -0,0 +1,43 @@
+package websocket
+
+import (
+	"context"
+	"time"
+
+	"ambient-code-backend/handlers"
+	"ambient-code-backend/types"
+)
+
+// trackTokenUsage charges the tokens of a run result the runner reports as a
+// /lastResult state delta to the project's monthly token quota
+func trackTokenUsage(event types.TypedEvent, runState *AGUIRunState) {
+	delta, ok := event.(*types.StateDeltaEvent)
+	if !ok || runState == nil {
+		return
+	}
+	var tokens int64
+	for _, patch := range delta.Delta {
+		if patch.Path != "/lastResult" {
+			continue
+		}
+		result, _ := patch.Value.(map[string]interface{})
+		tokens += resultTokens(result)
+	}
+	if tokens == 0 {
+		return
+	}
+	project, session := runState.ProjectName, runState.SessionID
+	persistPool.Submit(session, func() {
+		ctx, cancel := context.WithTimeout(runState.logContext(), 10*time.Second)
+		defer cancel()
+		handlers.RecordTokenUsage(ctx, project, session, tokens)
+	})
+}
+
+// resultTokens counts the input and output tokens of a runner result, including cache
+// writes; cache reads are not counted
+func resultTokens(result map[string]interface{}) int64 {
+	usage, _ := result["usage"].(map[string]interface{})
+	return int64(jsonNumber(usage["input_tokens"]) + jsonNumber(usage["output_tokens"]) +
+		jsonNumber(usage["cache_creation_input_tokens"]))
+}
@@ -0,0 +1,95 @@
+package websocket
+
+import (
+	"ambient-code-backend/config"
+	"ambient-code-backend/handlers"
+	"ambient-code-backend/logging"
+	"ambient-code-backend/types"
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// mcpToolPolicyViolationEvent is the RAW event subtype emitted when a session calls
+// an MCP tool its project policy does not allow
+const mcpToolPolicyViolationEvent = "mcp_tool_policy_violation"
+
+// loadSessionToolPolicy returns the MCP tool policy snapshotted on the session, if any
+func loadSessionToolPolicy(projectName, sessionName string) *types.MCPToolPolicy {
+	if handlers.DynamicClient == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	item, err := handlers.GetCachedSession(ctx, projectName, sessionName)
+	if err != nil {
+		logging.Errorf(context.Background(), "AGUI Proxy: failed to load MCP tool policy for %s/%s: %v", projectName, sessionName, err)
+		return nil
+	}
+	return handlers.MCPToolPolicyForSession(item)
+}
+
+// checkToolCallPolicy emits a policy-violation event when a TOOL_CALL_START names a tool
+// the session's policy disallows. In block mode the run is interrupted as a backstop;
+// the runner is expected to have refused the call already.
+func checkToolCallPolicy(sessionID, runID, threadID string, start *types.ToolCallStartEvent, runState *AGUIRunState) {
+	if runState == nil || runState.toolPolicy == nil {
+		return
+	}
+	toolName := start.ToolCallName
+	if runState.toolPolicy.Allows(toolName) {
+		return
+	}
+
+	policy := runState.toolPolicy
+	logging.Warnf(runState.logContext(), "AGUI Proxy: MCP tool policy violation in %s/%s run %s: %s (%s, %s)",
+		runState.ProjectName, sessionID, runID, toolName, policy.Mode, policy.Enforcement)
+
+	violation, err := types.NewEvent(&types.RawEvent{
+		BaseEvent: types.NewBaseEvent(types.EventTypeRaw, threadID, runID),
+		Event: map[string]interface{}{
+			"type":        mcpToolPolicyViolationEvent,
+			"toolCallId":  start.ToolCallID,
+			"toolName":    toolName,
+			"mode":        policy.Mode,
+			"enforcement": policy.Enforcement,
+			"message":     fmt.Sprintf("MCP tool %s is not permitted by the project policy", toolName),
+		},
+	})
+	if err != nil {
+		logging.Errorf(runState.logContext(), "AGUI Proxy: failed to build policy violation event: %v", err)
+	} else {
+		persistAGUIEvent(sessionID, runID, violation)
+		runState.BroadcastFull(violation)
+		broadcastToThread(sessionID, violation)
+	}
+
+	if policy.Enforcement == types.MCPToolPolicyBlock {
+		go interruptRunner(runState.logContext(), runState.ProjectName, sessionID)
+	}
+}
+
+// interruptRunner asks the session's runner to stop the current run
+func interruptRunner(ctx context.Context, projectName, sessionName string) {
+	runnerURL, err := getRunnerEndpoint(projectName, sessionName)
+	if err != nil {
+		logging.Errorf(ctx, "AGUI Proxy: cannot interrupt %s/%s: %v", projectName, sessionName, err)
+		return
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(runnerURL, "/")+"/interrupt", bytes.NewReader([]byte("{}")))
+	if err != nil {
+		logging.Errorf(ctx, "AGUI Proxy: failed to build interrupt request: %v", err)
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	logging.SetRequestIDHeader(req)
+	resp, err := runnerClient(config.Current().RunnerRequestTimeout.Duration).Do(req)
+	if err != nil {
+		logging.Errorf(ctx, "AGUI Proxy: interrupt of %s/%s failed: %v", projectName, sessionName, err)
+		return
+	}
+	closeRunnerResponse(resp)
+}
@@ -0,0 +1,303 @@
+package websocket
+
+import (
+	"ambient-code-backend/handlers"
+	"ambient-code-backend/logging"
+	"ambient-code-backend/types"
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	authv1 "k8s.io/api/authorization/v1"
+)
+
+// ToolUsageRecord is one completed tool invocation. Arguments and results are not
+// stored, only their size, so the usage log is safe to aggregate across users.
+type ToolUsageRecord struct {
+	Project    string `json:"project"`
+	Session    string `json:"session"`
+	RunID      string `json:"runId"`
+	ToolCallID string `json:"toolCallId"`
+	Tool       string `json:"tool"`
+	Server     string `json:"server,omitempty"` // MCP server name; empty for built-in tools
+	StartedAt  string `json:"startedAt"`
+	DurationMs int64  `json:"durationMs"`
+	Status     string `json:"status"` // "success" or "error"
+	ArgsBytes  int    `json:"argsBytes"`
+}
+
+// pendingToolCall tracks a tool call between TOOL_CALL_START and TOOL_CALL_END
+type pendingToolCall struct {
+	name      string
+	startedAt time.Time
+	argsBytes int
+}
+
+var toolUsageFileMu sync.Mutex
+
+func toolUsagePath(project string) string {
+	return fmt.Sprintf("%s/tool-usage/%s.jsonl", StateBaseDir, project)
+}
+
+// mcpServerForTool returns the MCP server of an mcp__<server>__<tool> name
+func mcpServerForTool(tool string) string {
+	if !strings.HasPrefix(tool, "mcp__") {
+		return ""
+	}
+	parts := strings.SplitN(strings.TrimPrefix(tool, "mcp__"), "__", 2)
+	return parts[0]
+}
+
+// trackToolUsage folds TOOL_CALL_* events into usage records for the run's project
+func trackToolUsage(event types.TypedEvent, runState *AGUIRunState) {
+	if runState == nil {
+		return
+	}
+	var id string
+	switch e := event.(type) {
+	case *types.ToolCallStartEvent:
+		id = e.ToolCallID
+	case *types.ToolCallArgsEvent:
+		id = e.ToolCallID
+	case *types.ToolCallEndEvent:
+		id = e.ToolCallID
+	default:
+		return
+	}
+	if id == "" {
+		return
+	}
+
+	runState.toolCallsMu.Lock()
+	defer runState.toolCallsMu.Unlock()
+	if runState.toolCalls == nil {
+		runState.toolCalls = map[string]*pendingToolCall{}
+	}
+
+	switch e := event.(type) {
+	case *types.ToolCallStartEvent:
+		runState.toolCalls[id] = &pendingToolCall{name: e.ToolCallName, startedAt: time.Now()}
+	case *types.ToolCallArgsEvent:
+		if pending, ok := runState.toolCalls[id]; ok {
+			pending.argsBytes += len(e.Delta)
+		}
+	case *types.ToolCallEndEvent:
+		pending, ok := runState.toolCalls[id]
+		if !ok {
+			return
+		}
+		delete(runState.toolCalls, id)
+		status := "success"
+		if e.Error != "" {
+			status = "error"
+		}
+		rec := ToolUsageRecord{
+			Project:    runState.ProjectName,
+			Session:    runState.SessionID,
+			RunID:      runState.RunID,
+			ToolCallID: id,
+			Tool:       pending.name,
+			Server:     mcpServerForTool(pending.name),
+			StartedAt:  pending.startedAt.UTC().Format(time.RFC3339),
+			DurationMs: time.Since(pending.startedAt).Milliseconds(),
+			Status:     status,
+			ArgsBytes:  pending.argsBytes,
+		}
+		persistPool.Submit(runState.SessionID, func() { appendToolUsage(rec) })
+	}
+}
+
+func appendToolUsage(rec ToolUsageRecord) {
+	data, err := json.Marshal(rec)
+	if err != nil {
+		logging.Errorf(context.Background(), "Tool usage: failed to marshal record: %v", err)
+		return
+	}
+	toolUsageFileMu.Lock()
+	defer toolUsageFileMu.Unlock()
+	_ = ensureDir(fmt.Sprintf("%s/tool-usage", StateBaseDir))
+	f, err := openFileAppend(toolUsagePath(rec.Project))
+	if err != nil {
+		logging.Errorf(context.Background(), "Tool usage: failed to open log for %s: %v", rec.Project, err)
+		return
+	}
+	defer f.Close()
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		logging.Errorf(context.Background(), "Tool usage: failed to write record: %v", err)
+	}
+}
+
+// loadToolUsage reads a project's usage records started at or after since
+func loadToolUsage(project string, since time.Time) ([]ToolUsageRecord, error) {
+	toolUsageFileMu.Lock()
+	defer toolUsageFileMu.Unlock()
+	f, err := os.Open(toolUsagePath(project))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer f.Close()
+
+	var records []ToolUsageRecord
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var rec ToolUsageRecord
+		if err := json.Unmarshal(scanner.Bytes(), &rec); err != nil {
+			continue
+		}
+		if t, err := time.Parse(time.RFC3339, rec.StartedAt); err != nil || t.Before(since) {
+			continue
+		}
+		records = append(records, rec)
+	}
+	return records, scanner.Err()
+}
+
+// ToolUsageStats summarizes invocations of one tool or server
+type ToolUsageStats struct {
+	Name        string  `json:"name"`
+	Server      string  `json:"server,omitempty"`
+	Calls       int     `json:"calls"`
+	Failures    int     `json:"failures"`
+	FailureRate float64 `json:"failureRate"`
+	P50Ms       int64   `json:"p50Ms"`
+	P90Ms       int64   `json:"p90Ms"`
+	P99Ms       int64   `json:"p99Ms"`
+	Sessions    int     `json:"sessions"`
+}
+
+// percentile returns the nearest-rank percentile of sorted durations
+func percentile(sorted []int64, p float64) int64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := int(math.Ceil(p/100*float64(len(sorted)))) - 1
+	if rank < 0 {
+		rank = 0
+	}
+	return sorted[rank]
+}
+
+// summarizeToolUsage groups records by key and computes call counts, failure rates
+// and latency percentiles, ordered by call count
+func summarizeToolUsage(records []ToolUsageRecord, key func(ToolUsageRecord) (string, string)) []ToolUsageStats {
+	type group struct {
+		stats     ToolUsageStats
+		durations []int64
+		sessions  map[string]bool
+	}
+	groups := map[string]*group{}
+	for _, rec := range records {
+		name, server := key(rec)
+		g, ok := groups[name]
+		if !ok {
+			g = &group{stats: ToolUsageStats{Name: name, Server: server}, sessions: map[string]bool{}}
+			groups[name] = g
+		}
+		g.stats.Calls++
+		if rec.Status == "error" {
+			g.stats.Failures++
+		}
+		g.durations = append(g.durations, rec.DurationMs)
+		g.sessions[rec.Session] = true
+	}
+
+	out := make([]ToolUsageStats, 0, len(groups))
+	for _, g := range groups {
+		sort.Slice(g.durations, func(a, b int) bool { return g.durations[a] < g.durations[b] })
+		g.stats.FailureRate = math.Round(float64(g.stats.Failures)/float64(g.stats.Calls)*1000) / 1000
+		g.stats.P50Ms = percentile(g.durations, 50)
+		g.stats.P90Ms = percentile(g.durations, 90)
+		g.stats.P99Ms = percentile(g.durations, 99)
+		g.stats.Sessions = len(g.sessions)
+		out = append(out, g.stats)
+	}
+	sort.Slice(out, func(a, b int) bool {
+		if out[a].Calls != out[b].Calls {
+			return out[a].Calls > out[b].Calls
+		}
+		return out[a].Name < out[b].Name
+	})
+	return out
+}
+
+// HandleToolUsageAnalytics reports tool usage for a project
+// GET /api/projects/:projectName/mcp/analytics?days=7&all=true
+// By default only MCP tools are included; all=true adds built-in tools.
+func HandleToolUsageAnalytics(c *gin.Context) {
+	projectName := c.Param("projectName")
+
+	reqK8s, _ := handlers.GetK8sClientsForRequest(c)
+	if reqK8s == nil {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or missing token"})
+		c.Abort()
+		return
+	}
+
+	// SECURITY: Verify user can list sessions in this project
+	allowed, err := handlers.CheckAccessForRequest(c, reqK8s, authv1.ResourceAttributes{
+		Group:     "vteam.ambient-code",
+		Resource:  "agenticsessions",
+		Verb:      "list",
+		Namespace: projectName,
+	})
+	if err != nil || !allowed {
+		logging.Warnf(c, "Tool usage: User not authorized to list sessions in %s", projectName)
+		c.JSON(http.StatusForbidden, gin.H{"error": "Unauthorized"})
+		c.Abort()
+		return
+	}
+
+	days := 7
+	if v := c.Query("days"); v != "" {
+		if _, err := fmt.Sscanf(v, "%d", &days); err != nil || days < 1 || days > 90 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "days must be between 1 and 90"})
+			return
+		}
+	}
+	since := time.Now().Add(-time.Duration(days) * 24 * time.Hour)
+	includeBuiltin := c.Query("all") == "true"
+
+	records, err := loadToolUsage(projectName, since)
+	if err != nil {
+		logging.Errorf(c, "Tool usage: failed to load records for %s: %v", projectName, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load tool usage"})
+		return
+	}
+	if !includeBuiltin {
+		filtered := records[:0]
+		for _, rec := range records {
+			if rec.Server != "" {
+				filtered = append(filtered, rec)
+			}
+		}
+		records = filtered
+	}
+
+	tools := summarizeToolUsage(records, func(r ToolUsageRecord) (string, string) { return r.Tool, r.Server })
+	servers := summarizeToolUsage(records, func(r ToolUsageRecord) (string, string) {
+		if r.Server == "" {
+			return "(built-in)", ""
+		}
+		return r.Server, ""
+	})
+
+	c.JSON(http.StatusOK, gin.H{
+		"since":      since.UTC().Format(time.RFC3339),
+		"totalCalls": len(records),
+		"tools":      tools,
+		"servers":    servers,
+	})
+}
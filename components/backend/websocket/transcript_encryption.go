@@ -0,0 +1,198 @@
+package websocket
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"ambient-code-backend/handlers"
+	"ambient-code-backend/kms"
+	"ambient-code-backend/logging"
+)
+
+// encryptedEventAlg marks event log lines sealed with a project's data key
+const encryptedEventAlg = "aes-256-gcm"
+
+// encryptedEventLine is how an encrypted event is stored in agui-events.jsonl. The
+// session ID is bound as additional data so lines can't be moved between sessions.
+type encryptedEventLine struct {
+	Enc   string `json:"enc"`
+	KeyID string `json:"kid"`
+	Data  string `json:"data"` // base64 nonce + ciphertext
+}
+
+// sessionEncryption records that a session's events are persisted encrypted
+type sessionEncryption struct {
+	Project   string `json:"project"`
+	EnabledAt string `json:"enabledAt"`
+}
+
+// sessionEncryptionProjects caches each session's encryption marker ("" = plaintext)
+var sessionEncryptionProjects sync.Map
+
+// transcriptDataKey returns a project's data key; a variable so tests can stub the KMS
+var transcriptDataKey = handlers.TranscriptDataKey
+
+// unsealedRetryDelay is how long held-back events wait before sealing is retried
+var unsealedRetryDelay = 5 * time.Second
+
+// maxUnsealedEvents bounds the events held back per session while its data key is
+// unavailable; the oldest are dropped beyond it
+const maxUnsealedEvents = 10000
+
+// unsealedEvents holds, in order, event lines of encrypted sessions that couldn't be
+// sealed yet. They are only kept in memory and are written ahead of the session's next
+// event, or by a retry scheduled in unsealedRetries.
+var (
+	unsealedEventsMu sync.Mutex
+	unsealedEvents   = map[string][][]byte{}
+	unsealedRetries  = map[string]bool{}
+)
+
+func sessionEncryptionPath(sessionID string) string {
+	return fmt.Sprintf("%s/sessions/%s/encryption.json", StateBaseDir, sessionID)
+}
+
+// markSessionEncrypted switches the session's event log to encrypted writes. Once
+// marked a session stays encrypted, even if the project later turns encryption off.
+func markSessionEncrypted(projectName, sessionID string) {
+	if encryptedSessionProject(sessionID) != "" {
+		return
+	}
+	_ = ensureDir(fmt.Sprintf("%s/sessions/%s", StateBaseDir, sessionID))
+	b, _ := json.Marshal(sessionEncryption{Project: projectName, EnabledAt: time.Now().UTC().Format(time.RFC3339)})
+	if err := os.WriteFile(sessionEncryptionPath(sessionID), b, 0o600); err != nil {
+		logging.Errorf(context.Background(), "AGUI: failed to mark session %s encrypted: %v", sessionID, err)
+	}
+	// Cache even if the marker couldn't be written so this process never writes plaintext
+	sessionEncryptionProjects.Store(sessionID, projectName)
+}
+
+// encryptedSessionProject returns the project whose data key encrypts the session's
+// events, or "" when they are stored in plaintext
+func encryptedSessionProject(sessionID string) string {
+	if v, ok := sessionEncryptionProjects.Load(sessionID); ok {
+		return v.(string)
+	}
+	project := ""
+	if b, err := os.ReadFile(sessionEncryptionPath(sessionID)); err == nil {
+		var marker sessionEncryption
+		if json.Unmarshal(b, &marker) == nil {
+			project = marker.Project
+		}
+	}
+	sessionEncryptionProjects.Store(sessionID, project)
+	return project
+}
+
+// sealEventLine encrypts one event log line when the session is encrypted. It fails
+// rather than fall back to plaintext.
+func sealEventLine(sessionID string, line []byte) ([]byte, error) {
+	project := encryptedSessionProject(sessionID)
+	if project == "" {
+		return line, nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	keyID, key, err := transcriptDataKey(ctx, project)
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := kms.Seal(key, line, []byte(sessionID))
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(encryptedEventLine{
+		Enc:   encryptedEventAlg,
+		KeyID: keyID,
+		Data:  base64.StdEncoding.EncodeToString(sealed),
+	})
+}
+
+// sealEventLines seals the session's held-back lines followed by line, returning the
+// records to append in order. When sealing fails the remaining lines are held back and
+// a retry is scheduled, so a KMS outage delays persistence instead of losing events.
+func sealEventLines(sessionID string, line []byte) [][]byte {
+	unsealedEventsMu.Lock()
+	pending := unsealedEvents[sessionID]
+	delete(unsealedEvents, sessionID)
+	unsealedEventsMu.Unlock()
+	if line != nil {
+		pending = append(pending, line)
+	}
+
+	records := make([][]byte, 0, len(pending))
+	for i, l := range pending {
+		record, err := sealEventLine(sessionID, l)
+		if err != nil {
+			logging.Errorf(context.Background(), "AGUI: failed to encrypt events for session %s, holding back %d: %v", sessionID, len(pending)-i, err)
+			holdUnsealedEvents(sessionID, pending[i:])
+			break
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+// holdUnsealedEvents keeps lines for a later sealing attempt and schedules one
+func holdUnsealedEvents(sessionID string, lines [][]byte) {
+	if over := len(lines) - maxUnsealedEvents; over > 0 {
+		logging.Errorf(context.Background(), "AGUI: dropping %d unencrypted events of session %s held back too long", over, sessionID)
+		lines = lines[over:]
+	}
+	unsealedEventsMu.Lock()
+	defer unsealedEventsMu.Unlock()
+	unsealedEvents[sessionID] = lines
+	if unsealedRetries[sessionID] {
+		return
+	}
+	unsealedRetries[sessionID] = true
+	time.AfterFunc(unsealedRetryDelay, func() {
+		unsealedEventsMu.Lock()
+		delete(unsealedRetries, sessionID)
+		unsealedEventsMu.Unlock()
+		persistPool.Submit(sessionID, func() { appendEventRecords(sessionID, sealEventLines(sessionID, nil)) })
+	})
+}
+
+// dropUnsealedEvents forgets a session's held-back events
+func dropUnsealedEvents(sessionID string) {
+	unsealedEventsMu.Lock()
+	defer unsealedEventsMu.Unlock()
+	delete(unsealedEvents, sessionID)
+}
+
+// openEventLine returns the plaintext of an event log line, decrypting encrypted
+// lines. Lines that can't be decrypted are dropped (nil) and logged.
+func openEventLine(sessionID string, line []byte) []byte {
+	if !bytes.HasPrefix(line, []byte(`{"enc":`)) {
+		return line
+	}
+	var enc encryptedEventLine
+	if err := json.Unmarshal(line, &enc); err != nil || enc.Enc != encryptedEventAlg {
+		return line
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	key, err := handlers.TranscriptKey(ctx, enc.KeyID)
+	if err != nil {
+		logging.Errorf(ctx, "AGUI: cannot decrypt events of session %s: %v", sessionID, err)
+		return nil
+	}
+	sealed, err := base64.StdEncoding.DecodeString(enc.Data)
+	if err != nil {
+		logging.Errorf(ctx, "AGUI: skipping corrupt encrypted event in session %s: %v", sessionID, err)
+		return nil
+	}
+	plain, err := kms.Open(key, sealed, []byte(sessionID))
+	if err != nil {
+		logging.Errorf(ctx, "AGUI: skipping undecryptable event in session %s: %v", sessionID, err)
+		return nil
+	}
+	return plain
+}
@@ -0,0 +1,62 @@
+package websocket
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"ambient-code-backend/kms"
+	"ambient-code-backend/types"
+)
+
+// TestPersistAGUIEvent_HoldsBackUnsealedEvents verifies events of an encrypted session
+// are held back while its data key is unavailable and written sealed, in order, once it is
+func TestPersistAGUIEvent_HoldsBackUnsealedEvents(t *testing.T) {
+	StateBaseDir = t.TempDir()
+	origKey, origDelay := transcriptDataKey, unsealedRetryDelay
+	defer func() { transcriptDataKey, unsealedRetryDelay = origKey, origDelay }()
+	unsealedRetryDelay = time.Hour
+	defer dropUnsealedEvents("s1")
+	defer sessionEncryptionProjects.Delete("s1")
+
+	markSessionEncrypted("p1", "s1")
+	transcriptDataKey = func(context.Context, string) (string, []byte, error) {
+		return "", nil, errors.New("kms unavailable")
+	}
+	first, _ := types.DecodeEvent([]byte(`{"type":"RUN_STARTED","runId":"r1"}`))
+	persistAGUIEvent("s1", "r1", first)
+	path := StateBaseDir + "/sessions/s1/agui-events.jsonl"
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("event log written without a data key: %v", err)
+	}
+
+	key := bytes.Repeat([]byte{7}, 32)
+	transcriptDataKey = func(context.Context, string) (string, []byte, error) { return "k1", key, nil }
+	second, _ := types.DecodeEvent([]byte(`{"type":"RUN_FINISHED","runId":"r1"}`))
+	persistAGUIEvent("s1", "r1", second)
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("event log not written: %v", err)
+	}
+	lines := bytes.Split(bytes.TrimSpace(raw), []byte("\n"))
+	if len(lines) != 2 {
+		t.Fatalf("event log has %d lines, want 2", len(lines))
+	}
+	for i, want := range []*types.Event{first, second} {
+		var enc encryptedEventLine
+		if err := json.Unmarshal(lines[i], &enc); err != nil || enc.KeyID != "k1" {
+			t.Fatalf("line %d is not sealed: %s", i, lines[i])
+		}
+		sealed, _ := base64.StdEncoding.DecodeString(enc.Data)
+		plain, err := kms.Open(key, sealed, []byte("s1"))
+		if err != nil || !bytes.Equal(plain, want.Raw) {
+			t.Errorf("line %d = %s (%v), want %s", i, plain, err, want.Raw)
+		}
+	}
+}
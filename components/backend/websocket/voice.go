@@ -0,0 +1,104 @@
+package websocket
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+
+	"ambient-code-backend/config"
+	"ambient-code-backend/logging"
+	"ambient-code-backend/transcription"
+	"ambient-code-backend/types"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// newTranscriber returns the configured speech-to-text backend; tests replace it
+var newTranscriber = func() (transcription.Transcriber, error) {
+	cfg := config.Current()
+	return transcription.New(cfg.TranscriptionBackend, transcription.Options{
+		URL:     cfg.TranscriptionURL,
+		Model:   cfg.TranscriptionModel,
+		Token:   os.Getenv("TRANSCRIPTION_TOKEN"),
+		Timeout: cfg.TranscriptionTimeout.Duration,
+	})
+}
+
+// HandleAGUIVoiceRun handles POST /api/projects/:projectName/agentic-sessions/:sessionName/agui/voice
+// Transcribes the multipart "audio" clip and starts a run with the transcript as its
+// user message, so clients such as the mobile frontend can drive sessions by voice.
+// Optional form fields: language (a hint like "en"), threadId, runId, parentRunId
+// and model. The response is the run's, plus the transcript.
+func HandleAGUIVoiceRun(c *gin.Context) {
+	projectName := c.Param("projectName")
+	sessionName := c.Param("sessionName")
+	maxBytes := config.Current().MaxAudioBytes
+
+	transcriber, err := newTranscriber()
+	if errors.Is(err, transcription.ErrDisabled) {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "Voice input is not configured"})
+		return
+	}
+	if err != nil {
+		logging.Errorf(c, "AGUI Voice: %v", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Voice input is misconfigured"})
+		return
+	}
+
+	// Room for the clip plus the multipart framing and fields
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, int64(maxBytes)+1<<20)
+	file, header, err := c.Request.FormFile("audio")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Expected a multipart upload with an \"audio\" part within the size limit"})
+		return
+	}
+	audio, err := io.ReadAll(io.LimitReader(file, int64(maxBytes)+1))
+	file.Close()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read audio"})
+		return
+	}
+	if len(audio) > maxBytes {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": fmt.Sprintf("Audio is larger than %d bytes", maxBytes)})
+		return
+	}
+	if len(audio) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Audio is empty"})
+		return
+	}
+
+	clip := transcription.Clip{
+		Data:     audio,
+		Filename: header.Filename,
+		MimeType: header.Header.Get("Content-Type"),
+		Language: c.Request.FormValue("language"),
+	}
+	text, err := transcriber.Transcribe(c.Request.Context(), clip)
+	if err != nil {
+		logging.Errorf(c, "AGUI Voice: %s transcription for %s/%s failed: %v", transcriber.Name(), projectName, sessionName, err)
+		c.JSON(http.StatusBadGateway, gin.H{"error": "Failed to transcribe audio"})
+		return
+	}
+	if text == "" {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "No speech recognized"})
+		return
+	}
+	logging.Infof(c, "AGUI Voice: Transcribed %d bytes of audio for %s/%s", len(audio), projectName, sessionName)
+
+	input := types.RunAgentInput{
+		ThreadID:    c.Request.FormValue("threadId"),
+		RunID:       c.Request.FormValue("runId"),
+		ParentRunID: c.Request.FormValue("parentRunId"),
+		Model:       c.Request.FormValue("model"),
+		Messages: []types.Message{{
+			ID:       uuid.New().String(),
+			Role:     types.RoleUser,
+			Content:  text,
+			Metadata: map[string]interface{}{"inputMode": "voice"},
+		}},
+	}
+	proxyRun(c, input, gin.H{"transcript": text})
+}
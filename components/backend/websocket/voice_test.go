@@ -0,0 +1,59 @@
+package websocket
+
+import (
+	"bytes"
+	"context"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"ambient-code-backend/transcription"
+
+	"github.com/gin-gonic/gin"
+)
+
+type fakeTranscriber struct{ text string }
+
+func (f fakeTranscriber) Name() string { return "fake" }
+
+func (f fakeTranscriber) Transcribe(context.Context, transcription.Clip) (string, error) {
+	return f.text, nil
+}
+
+func TestVoiceRunRejects(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/sessions/:sessionName/voice", HandleAGUIVoiceRun)
+	defer func(original func() (transcription.Transcriber, error)) { newTranscriber = original }(newTranscriber)
+
+	post := func(audio []byte) int {
+		var body bytes.Buffer
+		form := multipart.NewWriter(&body)
+		if audio != nil {
+			part, _ := form.CreateFormFile("audio", "clip.webm")
+			part.Write(audio)
+		}
+		form.Close()
+		req := httptest.NewRequest(http.MethodPost, "/sessions/s1/voice", &body)
+		req.Header.Set("Content-Type", form.FormDataContentType())
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w.Code
+	}
+
+	if code := post([]byte("clip")); code != http.StatusNotImplemented {
+		t.Errorf("without a backend = %d", code)
+	}
+
+	newTranscriber = func() (transcription.Transcriber, error) { return fakeTranscriber{}, nil }
+	if code := post(nil); code != http.StatusBadRequest {
+		t.Errorf("without audio = %d", code)
+	}
+	if code := post([]byte{}); code != http.StatusBadRequest {
+		t.Errorf("empty audio = %d", code)
+	}
+	if code := post([]byte("silence")); code != http.StatusUnprocessableEntity {
+		t.Errorf("no speech = %d", code)
+	}
+}
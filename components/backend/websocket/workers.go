@@ -0,0 +1,12 @@
+package websocket
+
+import "ambient-code-backend/workpool"
+
+// persistPool appends events, run metadata and tool usage to the state files. Tasks
+// are keyed by session so each session's records are written in the order they
+// happened. Dropping would lose history, so a full queue slows the event stream down.
+var persistPool = workpool.New("persistence", 8, 1024, workpool.Block)
+
+// backgroundPool runs best-effort follow-ups: display-name generation and reporting
+// run status to linked PRs, incidents and notification rules
+var backgroundPool = workpool.New("background", 4, 256, workpool.Drop)
@@ -0,0 +1,574 @@
+package websocket
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"ambient-code-backend/handlers"
+	"ambient-code-backend/logging"
+	"ambient-code-backend/types"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// Workflows chain session templates into a multi-stage flow, by default the RFE flow
+// the platform was built around: ideation, then a specification, then an
+// implementation plan. Each stage runs as its own non-interactive session whose
+// prompt carries the workflow input and the artifacts of the stages before it. A stage
+// that requires approval waits once its run completes: thumbs up feedback on its
+// session approves it and starts the next stage, thumbs down reruns it in a new
+// session with the reviewer's comment.
+const (
+	maxWorkflowStages = 10
+	// maxStageAttempts bounds how often a rejected stage is rerun
+	maxStageAttempts = 5
+	// maxStageOutputBytes bounds the artifact each stage hands to the next
+	maxStageOutputBytes = 64 << 10
+)
+
+// WorkflowStageTemplate is one stage of a workflow
+type WorkflowStageTemplate struct {
+	Name string `json:"name"`
+	// Prompt tells the stage's agent what to produce
+	Prompt string `json:"prompt"`
+	// Artifact is the workspace file the stage writes, handed to later stages
+	Artifact        string `json:"artifact"`
+	RequireApproval bool   `json:"requireApproval"`
+	Model           string `json:"model,omitempty"`
+}
+
+// WorkflowTemplate is a predefined chain of stages
+type WorkflowTemplate struct {
+	Name        string                  `json:"name"`
+	Description string                  `json:"description"`
+	Stages      []WorkflowStageTemplate `json:"stages"`
+}
+
+// workflowTemplates are the predefined workflows; the first is the default
+var workflowTemplates = []WorkflowTemplate{
+	{
+		Name:        "rfe",
+		Description: "Turn a feature request into an approved specification and implementation plan",
+		Stages: []WorkflowStageTemplate{
+			{
+				Name: "ideation",
+				Prompt: "You are starting the ideation stage of a feature request. Explore the request and the " +
+					"repositories: restate the problem and who has it, list the options for solving it with their " +
+					"trade-offs, recommend one, and list the open questions a reviewer should answer.",
+				Artifact:        "artifacts/ideation.md",
+				RequireApproval: true,
+			},
+			{
+				Name: "spec",
+				Prompt: "You are writing the specification of a feature request, following the approved ideation " +
+					"below. Cover the goals and non-goals, user-facing behavior, API and data changes, edge cases, " +
+					"security considerations and acceptance criteria.",
+				Artifact:        "artifacts/spec.md",
+				RequireApproval: true,
+			},
+			{
+				Name: "plan",
+				Prompt: "You are writing the implementation plan of the approved specification below. Break the " +
+					"work into ordered, independently reviewable tasks, naming the files and components each " +
+					"touches and how it is tested. Do not implement it.",
+				Artifact:        "artifacts/plan.md",
+				RequireApproval: true,
+			},
+		},
+	},
+}
+
+// WorkflowRequest starts a workflow from a template or custom stages
+type WorkflowRequest struct {
+	Title string `json:"title" binding:"required"`
+	// Input is what the workflow works from, e.g. the feature request
+	Input string `json:"input" binding:"required"`
+	// Template names a predefined workflow, "rfe" by default; Stages replace it
+	Template string                  `json:"template,omitempty"`
+	Stages   []WorkflowStageTemplate `json:"stages,omitempty"`
+	Repos    []types.SimpleRepo      `json:"repos,omitempty"`
+}
+
+// WorkflowReview is an approval or rejection of a stage attempt
+type WorkflowReview struct {
+	Session    string `json:"session"`
+	Approved   bool   `json:"approved"`
+	Comment    string `json:"comment,omitempty"`
+	ReviewedBy string `json:"reviewedBy,omitempty"`
+	ReviewedAt string `json:"reviewedAt"`
+}
+
+// WorkflowStage is a stage of a workflow and its progress
+type WorkflowStage struct {
+	WorkflowStageTemplate
+	Status string `json:"status"` // "pending", "running", "awaiting_approval", "approved", "failed"
+	// Sessions are the stage's attempts, the current one last
+	Sessions []string `json:"sessions,omitempty"`
+	RunID    string   `json:"runId,omitempty"`
+	// Output is the artifact the latest attempt wrote, or its final message when it
+	// wrote none
+	Output  string           `json:"output,omitempty"`
+	Reviews []WorkflowReview `json:"reviews,omitempty"`
+}
+
+// WorkflowRecord is a workflow and its progress
+type WorkflowRecord struct {
+	ID       string             `json:"id"`
+	Title    string             `json:"title"`
+	Template string             `json:"template,omitempty"`
+	Input    string             `json:"input"`
+	Repos    []types.SimpleRepo `json:"repos,omitempty"`
+	Stages   []WorkflowStage    `json:"stages"`
+	// CurrentStage indexes the stage running or awaiting approval
+	CurrentStage int    `json:"currentStage"`
+	Status       string `json:"status"` // "running", "awaiting_approval", "completed", "failed"
+	Error        string `json:"error,omitempty"`
+	// Owner started the workflow; stage sessions run as theirs
+	Owner       types.UserContext `json:"owner"`
+	CreatedAt   string            `json:"createdAt"`
+	UpdatedAt   string            `json:"updatedAt"`
+	CompletedAt string            `json:"completedAt,omitempty"`
+}
+
+var (
+	workflowsFileMu sync.Mutex
+	// workflowsMu serializes workflow updates, which read, change and append a record
+	workflowsMu sync.Mutex
+	// errWorkflowUnchanged tells updateWorkflow there is nothing to persist
+	errWorkflowUnchanged = errors.New("workflow unchanged")
+)
+
+func workflowsPath(project string) string {
+	return fmt.Sprintf("%s/workflows/%s.jsonl", StateBaseDir, project)
+}
+
+// persistWorkflow appends the record's current state; the latest line per ID wins
+func persistWorkflow(project string, rec WorkflowRecord) {
+	rec.UpdatedAt = time.Now().UTC().Format(time.RFC3339)
+	data, err := json.Marshal(rec)
+	if err != nil {
+		logging.Errorf(context.Background(), "Workflow: failed to marshal record: %v", err)
+		return
+	}
+	workflowsFileMu.Lock()
+	defer workflowsFileMu.Unlock()
+	_ = ensureDir(fmt.Sprintf("%s/workflows", StateBaseDir))
+	f, err := openFileAppend(workflowsPath(project))
+	if err != nil {
+		logging.Errorf(context.Background(), "Workflow: failed to open workflows of %s: %v", project, err)
+		return
+	}
+	defer f.Close()
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		logging.Errorf(context.Background(), "Workflow: failed to write record: %v", err)
+	}
+}
+
+// loadWorkflows returns the project's workflows, oldest first
+func loadWorkflows(project string) ([]WorkflowRecord, error) {
+	workflowsFileMu.Lock()
+	data, err := os.ReadFile(workflowsPath(project))
+	workflowsFileMu.Unlock()
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []WorkflowRecord{}, nil
+		}
+		return nil, err
+	}
+	latest := map[string]int{}
+	workflows := []WorkflowRecord{}
+	for _, line := range splitLines(data) {
+		var rec WorkflowRecord
+		if len(line) == 0 || json.Unmarshal(line, &rec) != nil {
+			continue
+		}
+		if i, ok := latest[rec.ID]; ok {
+			workflows[i] = rec
+			continue
+		}
+		latest[rec.ID] = len(workflows)
+		workflows = append(workflows, rec)
+	}
+	return workflows, nil
+}
+
+// updateWorkflow applies mutate to the workflow and persists the result, unless
+// mutate returns errWorkflowUnchanged
+func updateWorkflow(project, id string, mutate func(*WorkflowRecord) error) error {
+	workflowsMu.Lock()
+	defer workflowsMu.Unlock()
+	workflows, err := loadWorkflows(project)
+	if err != nil {
+		return err
+	}
+	for _, rec := range workflows {
+		if rec.ID != id {
+			continue
+		}
+		if err := mutate(&rec); err != nil {
+			if errors.Is(err, errWorkflowUnchanged) {
+				return nil
+			}
+			return err
+		}
+		persistWorkflow(project, rec)
+		return nil
+	}
+	return fmt.Errorf("workflow %s not found", id)
+}
+
+// resolveWorkflowStages returns the request's custom stages or its template's
+func resolveWorkflowStages(req WorkflowRequest) (string, []WorkflowStageTemplate, error) {
+	if len(req.Stages) == 0 {
+		name := req.Template
+		if name == "" {
+			name = workflowTemplates[0].Name
+		}
+		for _, t := range workflowTemplates {
+			if t.Name == name {
+				return name, t.Stages, nil
+			}
+		}
+		return "", nil, fmt.Errorf("unknown workflow template %q", name)
+	}
+	if req.Template != "" {
+		return "", nil, fmt.Errorf("pass either a template or stages")
+	}
+	if len(req.Stages) > maxWorkflowStages {
+		return "", nil, fmt.Errorf("a workflow has at most %d stages", maxWorkflowStages)
+	}
+	seen := map[string]bool{}
+	stages := make([]WorkflowStageTemplate, len(req.Stages))
+	for i, s := range req.Stages {
+		s.Name = strings.TrimSpace(s.Name)
+		if s.Name == "" || strings.TrimSpace(s.Prompt) == "" || strings.TrimSpace(s.Artifact) == "" {
+			return "", nil, fmt.Errorf("stage %d needs a name, prompt and artifact", i+1)
+		}
+		if seen[s.Name] {
+			return "", nil, fmt.Errorf("duplicate stage %q", s.Name)
+		}
+		seen[s.Name] = true
+		// Cleaning against the root keeps the artifact inside the workspace
+		p := path.Clean("/" + s.Artifact)
+		if p == "/" {
+			return "", nil, fmt.Errorf("stage %q: artifact must name a file", s.Name)
+		}
+		s.Artifact = p[1:]
+		stages[i] = s
+	}
+	return "", stages, nil
+}
+
+// stagePrompt builds the prompt of the current stage's next attempt: the stage
+// instructions, the workflow input and the earlier stages' artifacts, plus the
+// rejected version and its review when the stage is rerun
+func stagePrompt(rec *WorkflowRecord) string {
+	stage := rec.Stages[rec.CurrentStage]
+	var b strings.Builder
+	b.WriteString(strings.TrimSpace(stage.Prompt))
+	fmt.Fprintf(&b, "\n\n<workflow-input title=%q>\n%s\n</workflow-input>\n", rec.Title, strings.TrimSpace(rec.Input))
+	for _, prev := range rec.Stages[:rec.CurrentStage] {
+		fmt.Fprintf(&b, "\n<workflow-artifact stage=%q path=%q>\n%s\n</workflow-artifact>\n", prev.Name, prev.Artifact, strings.TrimSpace(prev.Output))
+	}
+	if n := len(stage.Reviews); n > 0 && !stage.Reviews[n-1].Approved {
+		review := stage.Reviews[n-1]
+		comment := strings.TrimSpace(review.Comment)
+		if comment == "" {
+			comment = "(no comment)"
+		}
+		fmt.Fprintf(&b, "\nA reviewer rejected the previous version of this stage. Revise it to address their feedback.\n"+
+			"\n<previous-version path=%q>\n%s\n</previous-version>\n\n<review-feedback>\n%s\n</review-feedback>\n",
+			stage.Artifact, strings.TrimSpace(stage.Output), comment)
+	}
+	fmt.Fprintf(&b, "\nWrite the result to %s in the workspace. It is handed to the next stage", stage.Artifact)
+	if stage.RequireApproval {
+		b.WriteString(" after review")
+	}
+	b.WriteString(", so make it complete on its own.\n")
+	return b.String()
+}
+
+// startWorkflowStage creates the session of the current stage's next attempt
+func startWorkflowStage(ctx context.Context, project string, rec *WorkflowRecord) error {
+	stage := &rec.Stages[rec.CurrentStage]
+	attempt := len(stage.Sessions) + 1
+	if attempt > maxStageAttempts {
+		return fmt.Errorf("stage %s was rejected %d times", stage.Name, maxStageAttempts)
+	}
+	name := fmt.Sprintf("wf-%s-%d-%d", rec.ID, rec.CurrentStage+1, attempt)
+	displayName := []rune(fmt.Sprintf("%s: %s", stage.Name, rec.Title))
+	if len(displayName) > 60 {
+		displayName = append(displayName[:59], '…')
+	}
+	err := handlers.CreateWorkflowStageSession(ctx, project, handlers.WorkflowStageSession{
+		Name:        name,
+		DisplayName: string(displayName),
+		Prompt:      stagePrompt(rec),
+		Model:       stage.Model,
+		Repos:       rec.Repos,
+		Owner:       rec.Owner,
+		Labels: map[string]string{
+			handlers.WorkflowIDLabel:    rec.ID,
+			handlers.WorkflowStageLabel: fmt.Sprintf("%d", rec.CurrentStage),
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create the session of stage %s: %w", stage.Name, err)
+	}
+	stage.Sessions = append(stage.Sessions, name)
+	stage.Status, stage.RunID = "running", ""
+	rec.Status = "running"
+	logging.Infof(ctx, "Workflow: %s in %s started stage %s in %s", rec.ID, project, stage.Name, name)
+	return nil
+}
+
+// failWorkflow marks the current stage and the workflow failed
+func failWorkflow(rec *WorkflowRecord, reason string) {
+	rec.Stages[rec.CurrentStage].Status = "failed"
+	rec.Status = "failed"
+	rec.Error = reason
+	rec.CompletedAt = time.Now().UTC().Format(time.RFC3339)
+}
+
+// advanceWorkflow approves the current stage and starts the next, completing the
+// workflow after its last stage
+func advanceWorkflow(ctx context.Context, project string, rec *WorkflowRecord) {
+	rec.Stages[rec.CurrentStage].Status = "approved"
+	if rec.CurrentStage+1 == len(rec.Stages) {
+		rec.Status = "completed"
+		rec.CompletedAt = time.Now().UTC().Format(time.RFC3339)
+		logging.Infof(ctx, "Workflow: %s in %s completed", rec.ID, project)
+		return
+	}
+	rec.CurrentStage++
+	if err := startWorkflowStage(ctx, project, rec); err != nil {
+		logging.Errorf(ctx, "Workflow: %s in %s: %v", rec.ID, project, err)
+		failWorkflow(rec, err.Error())
+	}
+}
+
+// stageOutput reads the stage's artifact from what the run's edit tools wrote, or
+// its final message when it wrote none
+func stageOutput(sessionName, runID, artifact string) string {
+	events, err := loadEventsForRun(sessionName, runID)
+	if err != nil {
+		logging.Errorf(context.Background(), "Workflow: failed to load run %s of %s: %v", runID, sessionName, err)
+		return ""
+	}
+	messages := ThreadMessages(events)
+	output := ""
+	for p, content := range runArtifacts(messages) {
+		if p == artifact || strings.HasSuffix(p, "/"+artifact) {
+			output = content
+			break
+		}
+	}
+	if output == "" {
+		output = finalAnswer(messages)
+	}
+	if len(output) > maxStageOutputBytes {
+		cut := maxStageOutputBytes
+		for cut > 0 && !utf8.RuneStart(output[cut]) {
+			cut--
+		}
+		output = output[:cut] + "\n\n[truncated]"
+	}
+	return output
+}
+
+// completeWorkflowStage records the finished run of a workflow stage session: the
+// stage waits for approval, or the workflow moves on. Queued on persistPool behind
+// the run's event writes.
+func completeWorkflowStage(project, sessionName, runID, status string) {
+	ctx := context.Background()
+	item, err := handlers.GetCachedSession(ctx, project, sessionName)
+	if err != nil {
+		return
+	}
+	id := item.GetLabels()[handlers.WorkflowIDLabel]
+	if id == "" {
+		return
+	}
+	err = updateWorkflow(project, id, func(rec *WorkflowRecord) error {
+		stage := &rec.Stages[rec.CurrentStage]
+		if stage.Status != "running" || stage.Sessions[len(stage.Sessions)-1] != sessionName {
+			return errWorkflowUnchanged
+		}
+		stage.RunID = runID
+		if status != "completed" {
+			failWorkflow(rec, fmt.Sprintf("the run of stage %s ended %s", stage.Name, status))
+			return nil
+		}
+		stage.Output = stageOutput(sessionName, runID, stage.Artifact)
+		if stage.RequireApproval {
+			stage.Status = "awaiting_approval"
+			rec.Status = "awaiting_approval"
+			return nil
+		}
+		advanceWorkflow(ctx, project, rec)
+		return nil
+	})
+	if err != nil {
+		logging.Errorf(ctx, "Workflow: failed to record run %s of %s/%s: %v", runID, project, sessionName, err)
+	}
+}
+
+// reviewWorkflowStage applies thumbs up/down feedback on a workflow stage session
+// awaiting approval: approval moves the workflow on, rejection reruns the stage
+func reviewWorkflowStage(c *gin.Context, project, sessionName string, meta *types.MetaEvent) {
+	if meta.MetaType != "thumbs_up" && meta.MetaType != "thumbs_down" {
+		return
+	}
+	item, err := handlers.GetCachedSession(c.Request.Context(), project, sessionName)
+	if err != nil {
+		return
+	}
+	id := item.GetLabels()[handlers.WorkflowIDLabel]
+	if id == "" {
+		return
+	}
+	comment := func(key string) string {
+		s, _ := meta.Payload[key].(string)
+		return strings.TrimSpace(s)
+	}
+	review := WorkflowReview{
+		Session:    sessionName,
+		Approved:   meta.MetaType == "thumbs_up",
+		Comment:    strings.TrimSpace(comment("reason") + "\n" + comment("comment")),
+		ReviewedBy: c.GetString("userID"),
+		ReviewedAt: time.Now().UTC().Format(time.RFC3339),
+	}
+	// Feedback is answered before the review's next stage session is created
+	ctx := context.WithoutCancel(c.Request.Context())
+	err = updateWorkflow(project, id, func(rec *WorkflowRecord) error {
+		stage := &rec.Stages[rec.CurrentStage]
+		if stage.Status != "awaiting_approval" || stage.Sessions[len(stage.Sessions)-1] != sessionName {
+			return errWorkflowUnchanged
+		}
+		stage.Reviews = append(stage.Reviews, review)
+		if review.Approved {
+			advanceWorkflow(ctx, project, rec)
+			return nil
+		}
+		if err := startWorkflowStage(ctx, project, rec); err != nil {
+			logging.Errorf(ctx, "Workflow: %s in %s: %v", rec.ID, project, err)
+			failWorkflow(rec, err.Error())
+		}
+		return nil
+	})
+	if err != nil {
+		logging.Errorf(c, "Workflow: failed to record review of %s/%s: %v", project, sessionName, err)
+	}
+}
+
+// HandleListWorkflowTemplates handles GET /api/projects/:projectName/workflows/templates
+func HandleListWorkflowTemplates(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"items": workflowTemplates})
+}
+
+// HandleCreateWorkflow handles POST /api/projects/:projectName/workflows
+// Starts the workflow's first stage session. Callers must be allowed to create
+// sessions; later stages are started by the backend as earlier ones are approved.
+func HandleCreateWorkflow(c *gin.Context) {
+	project := c.Param("projectName")
+	if !authorizeProjectSessions(c, project, "create") {
+		return
+	}
+	userID := c.GetString("userID")
+	if userID == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "User authentication required"})
+		return
+	}
+
+	var req WorkflowRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "title and input are required"})
+		return
+	}
+	template, stages, err := resolveWorkflowStages(req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	now := time.Now().UTC().Format(time.RFC3339)
+	rec := WorkflowRecord{
+		ID:        uuid.New().String()[:8],
+		Title:     strings.TrimSpace(req.Title),
+		Template:  template,
+		Input:     req.Input,
+		Repos:     req.Repos,
+		Stages:    make([]WorkflowStage, len(stages)),
+		Owner:     types.UserContext{UserID: userID, DisplayName: c.GetString("userName"), Groups: c.GetStringSlice("userGroups")},
+		CreatedAt: now,
+	}
+	for i, s := range stages {
+		rec.Stages[i] = WorkflowStage{WorkflowStageTemplate: s, Status: "pending"}
+	}
+
+	workflowsMu.Lock()
+	defer workflowsMu.Unlock()
+	if err := startWorkflowStage(c.Request.Context(), project, &rec); err != nil {
+		logging.Errorf(c, "Workflow: failed to start %s in %s: %v", rec.ID, project, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start the workflow's first stage"})
+		return
+	}
+	persistWorkflow(project, rec)
+	c.JSON(http.StatusCreated, rec)
+}
+
+// HandleListWorkflows handles GET /api/projects/:projectName/workflows
+// Optional ?status= filters by workflow status
+func HandleListWorkflows(c *gin.Context) {
+	project := c.Param("projectName")
+	if !authorizeProjectSessions(c, project, "list") {
+		return
+	}
+	workflows, err := loadWorkflows(project)
+	if err != nil {
+		logging.Errorf(c, "Workflow: Failed to load workflows for %s: %v", project, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load workflows"})
+		return
+	}
+	if status := c.Query("status"); status != "" {
+		filtered := []WorkflowRecord{}
+		for _, w := range workflows {
+			if w.Status == status {
+				filtered = append(filtered, w)
+			}
+		}
+		workflows = filtered
+	}
+	c.JSON(http.StatusOK, gin.H{"items": workflows})
+}
+
+// HandleGetWorkflow handles GET /api/projects/:projectName/workflows/:workflowId
+func HandleGetWorkflow(c *gin.Context) {
+	project := c.Param("projectName")
+	if !authorizeProjectSessions(c, project, "list") {
+		return
+	}
+	workflows, err := loadWorkflows(project)
+	if err != nil {
+		logging.Errorf(c, "Workflow: Failed to load workflows for %s: %v", project, err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load workflows"})
+		return
+	}
+	for _, w := range workflows {
+		if w.ID == c.Param("workflowId") {
+			c.JSON(http.StatusOK, w)
+			return
+		}
+	}
+	c.JSON(http.StatusNotFound, gin.H{"error": "Workflow not found"})
+}
@@ -0,0 +1,146 @@
+package websocket
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"ambient-code-backend/handlers"
+
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+func TestResolveWorkflowStages(t *testing.T) {
+	template, stages, err := resolveWorkflowStages(WorkflowRequest{Title: "t", Input: "i"})
+	if err != nil || template != "rfe" || len(stages) != 3 || stages[1].Artifact != "artifacts/spec.md" {
+		t.Fatalf("default template = %q %+v, %v", template, stages, err)
+	}
+	if _, _, err := resolveWorkflowStages(WorkflowRequest{Template: "nope"}); err == nil {
+		t.Error("unknown template accepted")
+	}
+
+	_, stages, err = resolveWorkflowStages(WorkflowRequest{Stages: []WorkflowStageTemplate{
+		{Name: "draft", Prompt: "Draft it", Artifact: "../../etc/draft.md"},
+	}})
+	if err != nil || stages[0].Artifact != "etc/draft.md" {
+		t.Fatalf("custom stages = %+v, %v", stages, err)
+	}
+	for _, bad := range [][]WorkflowStageTemplate{
+		{{Name: "draft", Prompt: "Draft it"}},
+		{{Name: "draft", Prompt: "Draft it", Artifact: "a.md"}, {Name: "draft", Prompt: "Again", Artifact: "b.md"}},
+		{{Name: "draft", Prompt: "Draft it", Artifact: "/"}},
+	} {
+		if _, _, err := resolveWorkflowStages(WorkflowRequest{Stages: bad}); err == nil {
+			t.Errorf("stages %+v accepted", bad)
+		}
+	}
+}
+
+func TestStagePromptHandsOffArtifactsAndReviews(t *testing.T) {
+	rec := &WorkflowRecord{
+		Title: "Export",
+		Input: "Users want to export sessions",
+		Stages: []WorkflowStage{
+			{WorkflowStageTemplate: workflowTemplates[0].Stages[0], Status: "approved", Output: "Use a zip archive"},
+			{WorkflowStageTemplate: workflowTemplates[0].Stages[1], Status: "awaiting_approval", Output: "Spec v1",
+				Reviews: []WorkflowReview{{Approved: false, Comment: "Cover size limits"}}},
+		},
+		CurrentStage: 1,
+	}
+	prompt := stagePrompt(rec)
+	for _, want := range []string{
+		`<workflow-input title="Export">` + "\nUsers want to export sessions",
+		`<workflow-artifact stage="ideation" path="artifacts/ideation.md">` + "\nUse a zip archive",
+		"<previous-version path=\"artifacts/spec.md\">\nSpec v1",
+		"<review-feedback>\nCover size limits",
+		"Write the result to artifacts/spec.md",
+	} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("prompt lacks %q:\n%s", want, prompt)
+		}
+	}
+
+	// Once approved, the review no longer asks for a revision
+	rec.Stages[1].Reviews = append(rec.Stages[1].Reviews, WorkflowReview{Approved: true})
+	if strings.Contains(stagePrompt(rec), "<review-feedback>") {
+		t.Error("approved stage prompt asks for a revision")
+	}
+}
+
+func TestWorkflowStagesAdvanceAndRerun(t *testing.T) {
+	StateBaseDir = t.TempDir()
+	ctx := context.Background()
+	gvr := schema.GroupVersionResource{Group: "vteam.ambient-code", Version: "v1alpha1", Resource: "agenticsessions"}
+	originalClient, originalGVR := handlers.DynamicClient, handlers.GetAgenticSessionV1Alpha1Resource
+	t.Cleanup(func() {
+		handlers.DynamicClient, handlers.GetAgenticSessionV1Alpha1Resource = originalClient, originalGVR
+	})
+	handlers.GetAgenticSessionV1Alpha1Resource = func() schema.GroupVersionResource { return gvr }
+	handlers.DynamicClient = dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{
+			handlers.GetProjectSettingsResource(): "ProjectSettingsList",
+			gvr:                                   "AgenticSessionList",
+		})
+
+	rec := WorkflowRecord{ID: "abc12345", Title: "Export", Input: "Export sessions"}
+	for _, s := range workflowTemplates[0].Stages[:2] {
+		rec.Stages = append(rec.Stages, WorkflowStage{WorkflowStageTemplate: s, Status: "pending"})
+	}
+	if err := startWorkflowStage(ctx, "team-w", &rec); err != nil {
+		t.Fatal(err)
+	}
+	persistWorkflow("team-w", rec)
+
+	session, err := handlers.DynamicClient.Resource(handlers.GetAgenticSessionV1Alpha1Resource()).Namespace("team-w").
+		Get(ctx, "wf-abc12345-1-1", v1.GetOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if session.GetLabels()[handlers.WorkflowIDLabel] != "abc12345" {
+		t.Errorf("labels = %v", session.GetLabels())
+	}
+
+	// The first stage's run completed; a rejection reruns it
+	reject := func(r *WorkflowRecord) error {
+		r.Stages[0].Status, r.Status = "awaiting_approval", "awaiting_approval"
+		r.Stages[0].Reviews = append(r.Stages[0].Reviews, WorkflowReview{Comment: "Too vague"})
+		return startWorkflowStage(ctx, "team-w", r)
+	}
+	if err := updateWorkflow("team-w", rec.ID, reject); err != nil {
+		t.Fatal(err)
+	}
+	if err := updateWorkflow("team-w", rec.ID, func(r *WorkflowRecord) error {
+		advanceWorkflow(ctx, "team-w", r)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	workflows, err := loadWorkflows("team-w")
+	if err != nil || len(workflows) != 1 {
+		t.Fatalf("workflows = %+v, %v", workflows, err)
+	}
+	got := workflows[0]
+	if got.CurrentStage != 1 || got.Status != "running" || got.Stages[0].Status != "approved" {
+		t.Errorf("workflow = %+v", got)
+	}
+	if strings.Join(got.Stages[0].Sessions, ",") != "wf-abc12345-1-1,wf-abc12345-1-2" ||
+		strings.Join(got.Stages[1].Sessions, ",") != "wf-abc12345-2-1" {
+		t.Errorf("sessions = %v, %v", got.Stages[0].Sessions, got.Stages[1].Sessions)
+	}
+
+	// Approving the last stage completes the workflow
+	if err := updateWorkflow("team-w", rec.ID, func(r *WorkflowRecord) error {
+		advanceWorkflow(ctx, "team-w", r)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	workflows, _ = loadWorkflows("team-w")
+	if workflows[0].Status != "completed" || workflows[0].CompletedAt == "" {
+		t.Errorf("workflow = %+v", workflows[0])
+	}
+}
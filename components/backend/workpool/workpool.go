@@ -0,0 +1,102 @@
+// Package workpool runs background tasks on a fixed number of workers with bounded
+// queues, so a burst of events can't spawn unbounded goroutines.
+package workpool
+
+import (
+	"context"
+	"hash/fnv"
+	"runtime/debug"
+	"sync/atomic"
+
+	"ambient-code-backend/logging"
+	"ambient-code-backend/metrics"
+)
+
+// Policy decides what Submit does when a task's queue is full
+type Policy int
+
+const (
+	// Block makes Submit wait for room, slowing the submitter down. For work that
+	// must not be lost, e.g. persisting events.
+	Block Policy = iota
+	// Drop discards the task and counts it in ambient_backend_work_tasks_rejected_total.
+	// For best-effort work, e.g. notifications.
+	Drop
+)
+
+// Pool is a fixed set of workers, each with its own queue. Tasks submitted with the
+// same key go to the same worker, so they run one at a time in submission order.
+type Pool struct {
+	name   string
+	policy Policy
+	queues []chan func()
+	next   atomic.Uint32
+}
+
+// New starts a pool of workers, each queueing up to queueSize tasks. The pool runs
+// for the life of the process and its queue depth is exported as a metric.
+func New(name string, workers, queueSize int, policy Policy) *Pool {
+	if workers < 1 {
+		workers = 1
+	}
+	p := &Pool{name: name, policy: policy, queues: make([]chan func(), workers)}
+	for i := range p.queues {
+		p.queues[i] = make(chan func(), queueSize)
+		go p.work(p.queues[i])
+	}
+	metrics.RegisterWorkQueue(name, p.Depth)
+	return p
+}
+
+// Submit queues task. Tasks with the same non-empty key run in submission order;
+// tasks without a key are spread across the workers. It reports false when the
+// pool's policy is Drop and the task was rejected.
+func (p *Pool) Submit(key string, task func()) bool {
+	queue := p.queues[p.index(key)]
+	if p.policy == Block {
+		queue <- task
+		return true
+	}
+	select {
+	case queue <- task:
+		return true
+	default:
+		metrics.ObserveWorkRejected(p.name)
+		logging.Warnf(context.Background(), "Worker pool %s is full, dropping task", p.name)
+		return false
+	}
+}
+
+// Depth returns the number of queued tasks not yet started
+func (p *Pool) Depth() int {
+	n := 0
+	for _, q := range p.queues {
+		n += len(q)
+	}
+	return n
+}
+
+func (p *Pool) index(key string) int {
+	if key == "" {
+		return int(p.next.Add(1) % uint32(len(p.queues)))
+	}
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+	return int(h.Sum32() % uint32(len(p.queues)))
+}
+
+func (p *Pool) work(queue <-chan func()) {
+	for task := range queue {
+		p.run(task)
+	}
+}
+
+// run runs task, keeping the worker alive if it panics
+func (p *Pool) run(task func()) {
+	defer func() {
+		if r := recover(); r != nil {
+			logging.Errorf(context.Background(), "Worker pool %s: task panicked: %v\n%s", p.name, r, debug.Stack())
+		}
+	}()
+	task()
+}
@@ -0,0 +1,65 @@
+package workpool
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSubmitKeepsKeyOrder(t *testing.T) {
+	p := New("test-order", 4, 16, Block)
+	var mu sync.Mutex
+	got := map[string][]int{}
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		for _, key := range []string{"a", "b", "c"} {
+			i, key := i, key
+			wg.Add(1)
+			p.Submit(key, func() {
+				defer wg.Done()
+				mu.Lock()
+				got[key] = append(got[key], i)
+				mu.Unlock()
+			})
+		}
+	}
+	wg.Wait()
+	for key, seq := range got {
+		for i, v := range seq {
+			if v != i {
+				t.Fatalf("key %s ran out of order: %v", key, seq)
+			}
+		}
+	}
+}
+
+func TestDropRejectsWhenFull(t *testing.T) {
+	p := New("test-drop", 1, 1, Drop)
+	release := make(chan struct{})
+	started := make(chan struct{})
+	p.Submit("", func() { close(started); <-release })
+	<-started
+
+	if !p.Submit("", func() {}) {
+		t.Fatal("task fitting the queue was rejected")
+	}
+	if p.Depth() != 1 {
+		t.Errorf("Depth = %d, want 1", p.Depth())
+	}
+	if p.Submit("", func() {}) {
+		t.Error("task beyond the queue was accepted")
+	}
+	close(release)
+}
+
+func TestPanickingTaskKeepsWorker(t *testing.T) {
+	p := New("test-panic", 1, 1, Block)
+	p.Submit("k", func() { panic("boom") })
+	done := make(chan struct{})
+	p.Submit("k", func() { close(done) })
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("worker did not survive a panicking task")
+	}
+}
@@ -10,10 +10,16 @@ import { Input } from '@/components/ui/input';
 import { Label } from '@/components/ui/label';
 import { Tabs, TabsList, TabsTrigger } from '@/components/ui/tabs';
 import { Table, TableBody, TableCell, TableHead, TableHeader, TableRow } from '@/components/ui/table';
+import { Select, SelectContent, SelectItem, SelectTrigger, SelectValue } from '@/components/ui/select';
 import { Dialog, DialogContent, DialogDescription, DialogFooter, DialogHeader, DialogTitle } from '@/components/ui/dialog';
 import { DestructiveConfirmationDialog } from '@/components/confirmation-dialog';
 
-import { useProjectPermissions, useAddProjectPermission, useRemoveProjectPermission } from '@/services/queries';
+import {
+  useProjectPermissions,
+  useAddProjectPermission,
+  useRemoveProjectPermission,
+  useUpdateProjectPermission,
+} from '@/services/queries';
 import { successToast, errorToast } from '@/hooks/use-toast';
 import type { PermissionRole, SubjectType } from '@/types/project';
 import { ROLE_DEFINITIONS } from '@/lib/role-colors';
@@ -32,6 +38,7 @@ export function SharingSection({ projectName }: SharingSectionProps) {
   const { data: permissions = [], isLoading, refetch } = useProjectPermissions(projectName);
   const addPermissionMutation = useAddProjectPermission();
   const removePermissionMutation = useRemoveProjectPermission();
+  const updatePermissionMutation = useUpdateProjectPermission();
 
   const [showGrantDialog, setShowGrantDialog] = useState(false);
   const [grantForm, setGrantForm] = useState<GrantPermissionForm>({
@@ -106,6 +113,23 @@ export function SharingSection({ projectName }: SharingSectionProps) {
     );
   }, [toRevoke, projectName, removePermissionMutation]);
 
+  const handleRoleChange = useCallback(
+    (subjectType: SubjectType, subjectName: string, role: PermissionRole) => {
+      updatePermissionMutation.mutate(
+        { projectName, subjectType, subjectName, role },
+        {
+          onSuccess: () => {
+            successToast(`Changed ${subjectName} to ${ROLE_DEFINITIONS[role].label}`);
+          },
+          onError: (error) => {
+            errorToast(error instanceof Error ? error.message : 'Failed to change role');
+          },
+        }
+      );
+    },
+    [projectName, updatePermissionMutation]
+  );
+
   const emptyState = useMemo(
     () => (
       <div className="text-center py-8">
@@ -192,10 +216,29 @@ export function SharingSection({ projectName }: SharingSectionProps) {
                         </div>
                       </TableCell>
                       <TableCell>
-                        <Badge className={roleConfig.color} style={{ cursor: 'default' }}>
-                          <RoleIcon className="w-3 h-3 mr-1" />
-                          {roleConfig.label}
-                        </Badge>
+                        {isAdmin ? (
+                          <Select
+                            value={p.role}
+                            onValueChange={(value) => handleRoleChange(p.subjectType, p.subjectName, value as PermissionRole)}
+                            disabled={updatePermissionMutation.isPending}
+                          >
+                            <SelectTrigger className="w-36">
+                              <SelectValue />
+                            </SelectTrigger>
+                            <SelectContent>
+                              {Object.entries(ROLE_DEFINITIONS).map(([roleKey, config]) => (
+                                <SelectItem key={roleKey} value={roleKey}>
+                                  {config.label}
+                                </SelectItem>
+                              ))}
+                            </SelectContent>
+                          </Select>
+                        ) : (
+                          <Badge className={roleConfig.color} style={{ cursor: 'default' }}>
+                            <RoleIcon className="w-3 h-3 mr-1" />
+                            {roleConfig.label}
+                          </Badge>
+                        )}
                       </TableCell>
 
                       {isAdmin && (
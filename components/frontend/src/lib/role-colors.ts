@@ -6,10 +6,10 @@
  * The design system automatically handles light/dark mode transitions.
  */
 
-import { Eye, Edit, Shield } from "lucide-react";
+import { Eye, Edit, Play, Shield } from "lucide-react";
 import type { LucideIcon } from "lucide-react";
 
-export type PermissionRole = 'view' | 'edit' | 'admin';
+export type PermissionRole = 'view' | 'run' | 'edit' | 'admin';
 
 export type RoleConfig = {
   label: string;
@@ -25,6 +25,7 @@ export type RoleConfig = {
  */
 export const ROLE_COLORS: Record<PermissionRole, string> = {
   view: 'bg-role-view text-role-view-foreground',
+  run: 'bg-role-edit text-role-edit-foreground',
   edit: 'bg-role-edit text-role-edit-foreground',
   admin: 'bg-role-admin text-role-admin-foreground',
 };
@@ -41,6 +42,13 @@ export const ROLE_DEFINITIONS: Record<PermissionRole, RoleConfig> = {
     color: ROLE_COLORS.view,
     icon: Eye,
   },
+  run: {
+    label: 'Run',
+    description: 'Can create sessions and start runs, but not read conversation transcripts',
+    permissions: ['sessions:create', 'sessions:run'] as const,
+    color: ROLE_COLORS.run,
+    icon: Play,
+  },
   edit: {
     label: 'Edit',
     description: 'Can create sessions in the workspace',
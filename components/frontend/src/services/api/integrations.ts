@@ -1,5 +1,8 @@
 import { apiClient } from './client'
 
+/** 'timeout' means the backend gave up on the lookup; the other fields are unknown */
+export type IntegrationLookupStatus = 'ok' | 'timeout'
+
 export type IntegrationsStatus = {
   github: {
     installed: boolean
@@ -13,6 +16,7 @@ export type IntegrationsStatus = {
       valid?: boolean
     }
     active?: 'app' | 'pat'
+    status?: IntegrationLookupStatus
   }
   google: {
     connected: boolean
@@ -20,6 +24,7 @@ export type IntegrationsStatus = {
     expiresAt?: string
     updatedAt?: string
     valid?: boolean
+    status?: IntegrationLookupStatus
   }
   jira: {
     connected: boolean
@@ -27,12 +32,14 @@ export type IntegrationsStatus = {
     email?: string
     updatedAt?: string
     valid?: boolean
+    status?: IntegrationLookupStatus
   }
   gitlab: {
     connected: boolean
     instanceUrl?: string
     updatedAt?: string
     valid?: boolean
+    status?: IntegrationLookupStatus
   }
 }
 
@@ -108,6 +108,21 @@ export async function addProjectPermission(
   );
 }
 
+/**
+ * Change the role of an existing project member
+ */
+export async function updateProjectPermission(
+  projectName: string,
+  subjectType: string,
+  subjectName: string,
+  role: PermissionAssignment['role']
+): Promise<PermissionAssignment> {
+  return apiClient.put<PermissionAssignment, { role: PermissionAssignment['role'] }>(
+    `/projects/${projectName}/permissions/${subjectType}/${subjectName}`,
+    { role }
+  );
+}
+
 /**
  * Remove permission from project
  */
@@ -201,6 +201,34 @@ export function useAddProjectPermission() {
   });
 }
 
+/**
+ * Hook to change a project member's role
+ */
+export function useUpdateProjectPermission() {
+  const queryClient = useQueryClient();
+
+  return useMutation({
+    mutationFn: ({
+      projectName,
+      subjectType,
+      subjectName,
+      role,
+    }: {
+      projectName: string;
+      subjectType: string;
+      subjectName: string;
+      role: PermissionAssignment['role'];
+    }) =>
+      projectsApi.updateProjectPermission(projectName, subjectType, subjectName, role),
+    onSuccess: (_data, { projectName }) => {
+      // Invalidate permissions to refetch
+      queryClient.invalidateQueries({
+        queryKey: projectKeys.permissions(projectName),
+      });
+    },
+  });
+}
+
 /**
  * Hook to remove project permission
  */
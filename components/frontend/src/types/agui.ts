@@ -67,6 +67,8 @@ export type AGUIBaseEvent = {
   timestamp: string
   messageId?: string
   parentRunId?: string
+  // Set on events of a sub-agent run streamed within its parent's run
+  branchId?: string
 }
 
 // Run input/output types
@@ -279,6 +281,8 @@ export type AGUIRunMetadata = {
   threadId: string
   runId: string
   parentRunId?: string
+  branchId?: string
+  agentName?: string
   sessionName: string
   projectName: string
   startedAt: string
@@ -286,6 +290,21 @@ export type AGUIRunMetadata = {
   status: 'running' | 'completed' | 'error'
   eventCount?: number
   restartCount?: number
+  // Reported by the runner's GET /version ('0' for runners that predate it)
+  protocolVersion?: string
+  runnerVersion?: string
+}
+
+// Run tree node (GET .../agui/runs/tree)
+export type AGUIRunTreeNode = AGUIRunMetadata & {
+  children?: AGUIRunTreeNode[]
+}
+
+// Run lineage (GET .../agui/runs/:runId/tree)
+export type AGUIRunLineage = {
+  threadId: string
+  ancestors: AGUIRunMetadata[]
+  run: AGUIRunTreeNode
 }
 
 // History response type
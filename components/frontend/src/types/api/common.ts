@@ -10,6 +10,8 @@ export type ApiResponse<T> = {
 export type ApiError = {
   error: string;
   code?: string;
+  retryable?: boolean;
+  requestId?: string;
   details?: Record<string, unknown>;
 };
 
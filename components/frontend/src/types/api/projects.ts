@@ -68,7 +68,7 @@ export type DeleteProjectResponse = {
   message: string;
 };
 
-export type PermissionRole = 'view' | 'edit' | 'admin';
+export type PermissionRole = 'view' | 'run' | 'edit' | 'admin';
 
 export type SubjectType = 'user' | 'group';
 
@@ -16,7 +16,7 @@ export interface BotAccount {
   description?: string;
 }
 
-export type PermissionRole = "view" | "edit" | "admin";
+export type PermissionRole = "view" | "run" | "edit" | "admin";
 
 export type SubjectType = "user" | "group";
 
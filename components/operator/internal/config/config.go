@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/client-go/dynamic"
@@ -30,6 +31,27 @@ type Config struct {
 	S3Endpoint             string
 	S3Bucket               string
 	PodFSGroup             *int64
+	// Spot scheduling: node label (key=value) identifying spot capacity and the
+	// taint key spot nodes carry. Used only for sessions with spec.spot=true.
+	SpotNodeLabelKey   string
+	SpotNodeLabelValue string
+	SpotTaintKey       string
+	// GitMirrorServiceURL is the in-cluster repo-mirror service that hydrate bootstraps
+	// clones from; empty disables the mirror.
+	GitMirrorServiceURL string
+	// Direct runner access for debugging (ProjectSettings spec.runnerDebugAccess):
+	// the oauth-proxy image, provider arguments replacing the OpenShift defaults, and
+	// the domain sessions get Ingress hosts under instead of OpenShift Routes.
+	DebugProxyImage    string
+	DebugProxyArgs     []string
+	DebugIngressDomain string
+	// Runner image prepull: a DaemonSet pulling the runner pod images (plus
+	// PrepullImages) onto every matching node, so first sessions on fresh nodes don't
+	// wait for image pulls.
+	ImagePrepull        bool
+	PrepullImages       []string
+	PrepullPauseImage   string
+	PrepullNodeSelector map[string]string
 }
 
 // InitK8sClients initializes the Kubernetes clients
@@ -128,6 +150,31 @@ func LoadConfig() *Config {
 		}
 	}
 
+	// Spot capacity selector (defaults match Karpenter's well-known label)
+	spotLabelKey, spotLabelValue := "karpenter.sh/capacity-type", "spot"
+	if sel := os.Getenv("SPOT_NODE_SELECTOR"); sel != "" {
+		if k, v, ok := strings.Cut(sel, "="); ok && k != "" {
+			spotLabelKey, spotLabelValue = k, v
+		}
+	}
+	spotTaintKey := os.Getenv("SPOT_TAINT_KEY")
+
+	debugProxyImage := os.Getenv("RUNNER_DEBUG_PROXY_IMAGE")
+	if debugProxyImage == "" {
+		debugProxyImage = "quay.io/openshift/origin-oauth-proxy:4.14"
+	}
+
+	prepullPauseImage := os.Getenv("PREPULL_PAUSE_IMAGE")
+	if prepullPauseImage == "" {
+		prepullPauseImage = "registry.k8s.io/pause:3.9"
+	}
+	var prepullNodeSelector map[string]string
+	if sel := os.Getenv("PREPULL_NODE_SELECTOR"); sel != "" {
+		if k, v, ok := strings.Cut(sel, "="); ok && k != "" {
+			prepullNodeSelector = map[string]string{k: v}
+		}
+	}
+
 	return &Config{
 		Namespace:              namespace,
 		BackendNamespace:       backendNamespace,
@@ -138,5 +185,16 @@ func LoadConfig() *Config {
 		S3Endpoint:             s3Endpoint,
 		S3Bucket:               s3Bucket,
 		PodFSGroup:             podFSGroup,
+		SpotNodeLabelKey:       spotLabelKey,
+		SpotNodeLabelValue:     spotLabelValue,
+		SpotTaintKey:           spotTaintKey,
+		GitMirrorServiceURL:    os.Getenv("GIT_MIRROR_SERVICE_URL"),
+		DebugProxyImage:        debugProxyImage,
+		DebugProxyArgs:         strings.Fields(os.Getenv("RUNNER_DEBUG_PROXY_ARGS")),
+		DebugIngressDomain:     os.Getenv("RUNNER_DEBUG_INGRESS_DOMAIN"),
+		ImagePrepull:           os.Getenv("RUNNER_IMAGE_PREPULL") == "true",
+		PrepullImages:          strings.FieldsFunc(os.Getenv("PREPULL_IMAGES"), func(r rune) bool { return r == ',' || r == ' ' }),
+		PrepullPauseImage:      prepullPauseImage,
+		PrepullNodeSelector:    prepullNodeSelector,
 	}
 }
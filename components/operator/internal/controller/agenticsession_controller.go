@@ -14,6 +14,7 @@ import (
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
@@ -40,6 +41,9 @@ type AgenticSessionReconciler struct {
 
 	// appConfig holds operator configuration (images, namespaces, etc.)
 	appConfig *config.Config
+
+	// Recorder emits Kubernetes Events on AgenticSession resources.
+	Recorder record.EventRecorder
 }
 
 // NewAgenticSessionReconciler creates a new reconciler with the given configuration.
@@ -73,6 +77,7 @@ func (r *AgenticSessionReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 		if errors.IsNotFound(err) {
 			// Object deleted - cleanup is handled by OwnerReferences
 			logger.V(1).Info("AgenticSession deleted", "name", req.Name, "namespace", req.Namespace)
+			phaseTracker.forget(req.NamespacedName)
 			return ctrl.Result{}, nil
 		}
 		return ctrl.Result{}, fmt.Errorf("failed to get AgenticSession: %w", err)
@@ -143,12 +148,17 @@ func (r *AgenticSessionReconciler) Reconcile(ctx context.Context, req ctrl.Reque
 		phase = "Pending" // Normalize empty phase
 	}
 	RecordReconcileDuration(phase, reconcileDuration, success)
+	observeReconcile(phase, reconcileDuration, err)
+	phaseTracker.observe(req.NamespacedName, phase)
 
 	if err != nil {
 		logger.Error(err, "Reconciliation failed",
 			"name", session.GetName(),
 			"phase", phase,
 		)
+		if r.Recorder != nil {
+			r.Recorder.Eventf(session, corev1.EventTypeWarning, "ReconcileError", "Reconcile failed in phase %s: %v", phase, err)
+		}
 		// Requeue with backoff on error
 		return ctrl.Result{RequeueAfter: 5 * time.Second}, err
 	}
@@ -185,6 +195,12 @@ func (r *AgenticSessionReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		maxConcurrent = 10 // Default to 10 concurrent reconcilers
 	}
 
+	if r.Recorder == nil {
+		r.Recorder = mgr.GetEventRecorderFor("agenticsession-controller")
+	}
+	// Legacy handlers emit events through the same recorder
+	handlers.SetEventRecorder(r.Recorder)
+
 	// Create the controller with concurrency settings
 	c, err := controller.New("agenticsession-controller", mgr, controller.Options{
 		Reconciler:              r,
@@ -223,6 +239,10 @@ func (r *AgenticSessionReconciler) SetupWithManager(mgr ctrl.Manager) error {
 			if !mapsEqual(oldAnns, newAnns) {
 				return true
 			}
+			// Process if labels changed (admission queue release)
+			if !mapsEqual(e.ObjectOld.GetLabels(), e.ObjectNew.GetLabels()) {
+				return true
+			}
 			// Process if status changed (phase transitions)
 			oldStatus, _, _ := unstructured.NestedMap(e.ObjectOld.Object, "status")
 			newStatus, _, _ := unstructured.NestedMap(e.ObjectNew.Object, "status")
@@ -261,8 +281,11 @@ func (r *AgenticSessionReconciler) SetupWithManager(mgr ctrl.Manager) error {
 			if !strings.HasSuffix(e.ObjectNew.Name, "-runner") {
 				return false
 			}
-			// Trigger if phase changed
-			return e.ObjectOld.Status.Phase != e.ObjectNew.Status.Phase
+			// Trigger if phase changed or the pod was marked for disruption (eviction/preemption)
+			if e.ObjectOld.Status.Phase != e.ObjectNew.Status.Phase {
+				return true
+			}
+			return hasDisruptionTarget(e.ObjectNew) && !hasDisruptionTarget(e.ObjectOld)
 		},
 		DeleteFunc: func(e event.TypedDeleteEvent[*corev1.Pod]) bool {
 			return strings.HasSuffix(e.Object.Name, "-runner")
@@ -286,6 +309,16 @@ func GetGVR() schema.GroupVersionResource {
 	return optypes.GetAgenticSessionResource()
 }
 
+// hasDisruptionTarget reports whether the pod carries a true DisruptionTarget condition
+func hasDisruptionTarget(pod *corev1.Pod) bool {
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == "DisruptionTarget" && cond.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
+
 // mapsEqual compares two string maps for equality
 func mapsEqual(a, b map[string]string) bool {
 	if len(a) != len(b) {
@@ -0,0 +1,87 @@
+package controller
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/types"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// Prometheus metrics served on the manager's /metrics endpoint (--metrics-bind-address).
+// These complement the OTLP-exported metrics in otel_metrics.go so cluster operators
+// can scrape and alert on reconcile health without an OpenTelemetry collector.
+var (
+	promReconcileDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "ambient_operator_reconcile_duration_seconds",
+			Help:    "Time spent reconciling an AgenticSession, by phase and result.",
+			Buckets: prometheus.ExponentialBuckets(0.01, 2, 12), // 10ms .. ~20s
+		},
+		[]string{"phase", "result"},
+	)
+
+	promReconcileErrors = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "ambient_operator_reconcile_errors_total",
+			Help: "Number of AgenticSession reconciles that returned an error, by phase.",
+		},
+		[]string{"phase"},
+	)
+
+	promSessions = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "ambient_operator_sessions",
+			Help: "Number of AgenticSessions last observed by the controller, by phase.",
+		},
+		[]string{"phase"},
+	)
+)
+
+func init() {
+	ctrlmetrics.Registry.MustRegister(promReconcileDuration, promReconcileErrors, promSessions)
+}
+
+// sessionPhaseTracker remembers the last phase seen for each session so the
+// sessions gauge can be maintained incrementally instead of listing CRs on scrape.
+type sessionPhaseTracker struct {
+	mu     sync.Mutex
+	phases map[types.NamespacedName]string
+}
+
+var phaseTracker = &sessionPhaseTracker{phases: make(map[types.NamespacedName]string)}
+
+// observe records the current phase for a session and adjusts the gauge.
+func (t *sessionPhaseTracker) observe(key types.NamespacedName, phase string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	prev, ok := t.phases[key]
+	if ok && prev == phase {
+		return
+	}
+	if ok {
+		promSessions.WithLabelValues(prev).Dec()
+	}
+	t.phases[key] = phase
+	promSessions.WithLabelValues(phase).Inc()
+}
+
+// forget drops a deleted session from the gauge.
+func (t *sessionPhaseTracker) forget(key types.NamespacedName) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if prev, ok := t.phases[key]; ok {
+		promSessions.WithLabelValues(prev).Dec()
+		delete(t.phases, key)
+	}
+}
+
+// observeReconcile records Prometheus reconcile metrics for one pass.
+func observeReconcile(phase string, durationSeconds float64, err error) {
+	result := "success"
+	if err != nil {
+		result = "error"
+		promReconcileErrors.WithLabelValues(phase).Inc()
+	}
+	promReconcileDuration.WithLabelValues(phase, result).Observe(durationSeconds)
+}
@@ -178,6 +178,15 @@ func (r *AgenticSessionReconciler) reconcilePending(ctx context.Context, session
 		return ctrl.Result{}, nil
 	}
 
+	// Queued sessions wait for the backend to admit them; the label change requeues
+	if handlers.IsQueuedForAdmission(session) {
+		logger.Info("Session is queued for capacity, skipping pod creation", "name", name)
+		if err := handlers.MarkAdmissionQueued(ctx, session); err != nil {
+			logger.Error(err, "Failed to record queued status", "name", name)
+		}
+		return ctrl.Result{}, nil
+	}
+
 	// Delegate to existing handler logic (refactored to be called from here)
 	// This preserves all the existing pod creation, secret handling, etc.
 	if err := handlers.ReconcilePendingSession(ctx, session, r.appConfig); err != nil {
@@ -201,11 +210,9 @@ func (r *AgenticSessionReconciler) reconcileCreating(ctx context.Context, sessio
 	logger := log.FromContext(ctx)
 	name := session.GetName()
 	namespace := session.GetNamespace()
-	podName := fmt.Sprintf("%s-runner", name)
 
 	// Check if pod exists
-	pod := &corev1.Pod{}
-	err := r.Get(ctx, types.NamespacedName{Name: podName, Namespace: namespace}, pod)
+	pod, err := handlers.GetRunnerPod(ctx, session)
 	if err != nil {
 		if errors.IsNotFound(err) {
 			// Pod doesn't exist - check if stop was requested
@@ -231,6 +238,10 @@ func (r *AgenticSessionReconciler) reconcileCreating(ctx context.Context, sessio
 		return ctrl.Result{}, fmt.Errorf("failed to get pod: %w", err)
 	}
 
+	if disrupted, err := r.handleDisruption(ctx, session, pod, "Creating"); disrupted || err != nil {
+		return ctrl.Result{}, err
+	}
+
 	// Check pod status and update session accordingly
 	if err := handlers.UpdateSessionFromPodStatus(ctx, session, pod); err != nil {
 		logger.Error(err, "Failed to update session from pod status", "name", name)
@@ -282,16 +293,17 @@ func (r *AgenticSessionReconciler) reconcileRunning(ctx context.Context, session
 	logger := log.FromContext(ctx)
 	name := session.GetName()
 	namespace := session.GetNamespace()
-	podName := fmt.Sprintf("%s-runner", name)
 
 	// Check if pod still exists
-	pod := &corev1.Pod{}
-	err := r.Get(ctx, types.NamespacedName{Name: podName, Namespace: namespace}, pod)
+	pod, err := handlers.GetRunnerPod(ctx, session)
 	if err != nil {
 		if errors.IsNotFound(err) {
 			// Pod deleted unexpectedly while Running - reset to Pending to recreate
 			logger.Info("Pod missing during Running phase, resetting to Pending", "name", name)
 			RecordReconcileRetry(namespace, "Running")
+			if err := handlers.RecoverLostSpotRunner(ctx, session); err != nil {
+				logger.Error(err, "Failed to mark spot session for on-demand recovery", "name", name)
+			}
 			if err := handlers.ResetToPending(ctx, session); err != nil {
 				return ctrl.Result{RequeueAfter: 5 * time.Second}, err
 			}
@@ -317,6 +329,10 @@ func (r *AgenticSessionReconciler) reconcileRunning(ctx context.Context, session
 		return ctrl.Result{RequeueAfter: 2 * time.Second}, nil
 	}
 
+	if disrupted, err := r.handleDisruption(ctx, session, pod, "Running"); disrupted || err != nil {
+		return ctrl.Result{}, err
+	}
+
 	// Check for generation drift (spec changed)
 	status, _, _ := unstructured.NestedMap(session.Object, "status")
 	observedGen, _, _ := unstructured.NestedInt64(status, "observedGeneration")
@@ -344,17 +360,43 @@ func (r *AgenticSessionReconciler) reconcileRunning(ctx context.Context, session
 	return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
 }
 
+// handleDisruption checkpoints the session when its runner pod is being evicted or
+// its node is draining. Returns true when the session was checkpointed.
+func (r *AgenticSessionReconciler) handleDisruption(ctx context.Context, session *unstructured.Unstructured, pod *corev1.Pod, fromPhase string) (bool, error) {
+	var node *corev1.Node
+	if pod.Spec.NodeName != "" {
+		if n, err := handlers.GetRunnerNode(ctx, session, pod.Spec.NodeName); err == nil {
+			node = n
+		}
+	}
+
+	reason, disrupted := handlers.RunnerDisruptionReason(pod, node)
+	if !disrupted {
+		return false, nil
+	}
+
+	log.FromContext(ctx).Info("Runner pod disrupted, checkpointing session",
+		"name", session.GetName(),
+		"reason", reason,
+	)
+	recordPhaseTransition(session.GetNamespace(), fromPhase, "Stopped")
+	if err := handlers.CheckpointDisruptedSession(ctx, session, reason); err != nil {
+		return true, err
+	}
+	recordSessionCompleted(session.GetNamespace(), "Stopped", session)
+	return true, nil
+}
+
 // reconcileStopping handles sessions in Stopping phase.
 // This waits for pod deletion and transitions to Stopped.
 func (r *AgenticSessionReconciler) reconcileStopping(ctx context.Context, session *unstructured.Unstructured) (ctrl.Result, error) {
 	logger := log.FromContext(ctx)
 	name := session.GetName()
 	namespace := session.GetNamespace()
-	podName := fmt.Sprintf("%s-runner", name)
+	podName := handlers.RunnerPodName(session)
 
 	// Check if pod still exists
-	pod := &corev1.Pod{}
-	err := r.Get(ctx, types.NamespacedName{Name: podName, Namespace: namespace}, pod)
+	_, err := handlers.GetRunnerPod(ctx, session)
 	if err != nil {
 		if errors.IsNotFound(err) {
 			// Pod is gone - transition to Stopped
@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// The backend admits sessions at creation. Sessions that didn't fit are labeled queued
+// until the backend admits them; admitted sessions may be pinned to a node pool of
+// the project's placement policy (ProjectSettings spec.placementPolicy).
+// IMPORTANT: Keep in sync with backend (handlers/admission.go)
+const (
+	admissionLabel            = "ambient-code.io/admission"
+	admissionQueued           = "queued"
+	admissionReasonAnnotation = "ambient-code.io/admission-reason"
+	nodePoolAnnotation        = "ambient-code.io/node-pool"
+)
+
+// IsQueuedForAdmission reports whether the session waits for capacity. No runner pod
+// is created for it until the backend admits it.
+func IsQueuedForAdmission(session *unstructured.Unstructured) bool {
+	return session.GetLabels()[admissionLabel] == admissionQueued
+}
+
+// MarkAdmissionQueued records on the session why it is queued, emitting an event the
+// first time
+func MarkAdmissionQueued(ctx context.Context, session *unstructured.Unstructured) error {
+	reason := session.GetAnnotations()[admissionReasonAnnotation]
+	if reason == "" {
+		reason = "Waiting for capacity"
+	}
+	current := admittedCondition(session)
+	if current == "Queued:"+reason {
+		return nil
+	}
+	if !strings.HasPrefix(current, "Queued:") {
+		recordSessionEvent(session, corev1.EventTypeNormal, eventReasonSessionQueued, "Queued: %s", reason)
+	}
+	statusPatch := NewStatusPatch(session.GetNamespace(), session.GetName())
+	statusPatch.AddCondition(conditionUpdate{
+		Type:    conditionAdmitted,
+		Status:  "False",
+		Reason:  "Queued",
+		Message: reason,
+	})
+	return statusPatch.Apply()
+}
+
+// admittedCondition returns the reason and message of the session's Admitted
+// condition as "reason:message", or "" if it has none
+func admittedCondition(session *unstructured.Unstructured) string {
+	conditions, _, _ := unstructured.NestedSlice(session.Object, "status", "conditions")
+	for _, c := range conditions {
+		m, ok := c.(map[string]interface{})
+		if !ok || m["type"] != conditionAdmitted {
+			continue
+		}
+		reason, _ := m["reason"].(string)
+		message, _ := m["message"].(string)
+		return reason + ":" + message
+	}
+	return ""
+}
+
+// applyNodePool pins the runner to the node pool the backend admitted the session to.
+// A pool that was removed from the policy since leaves the pod unpinned.
+func applyNodePool(pod *corev1.Pod, session *unstructured.Unstructured, projectSettings map[string]interface{}) error {
+	pool := session.GetAnnotations()[nodePoolAnnotation]
+	if pool == "" {
+		return nil
+	}
+	pools, _, err := unstructured.NestedSlice(projectSettings, "placementPolicy", "nodePools")
+	if err != nil {
+		return fmt.Errorf("invalid placementPolicy.nodePools: %w", err)
+	}
+	for _, item := range pools {
+		m, ok := item.(map[string]interface{})
+		if !ok || m["name"] != pool {
+			continue
+		}
+		selector, _, err := unstructured.NestedStringMap(m, "nodeSelector")
+		if err != nil {
+			return fmt.Errorf("node pool %s: invalid nodeSelector: %w", pool, err)
+		}
+		if pod.Spec.NodeSelector == nil {
+			pod.Spec.NodeSelector = map[string]string{}
+		}
+		for k, v := range selector {
+			pod.Spec.NodeSelector[k] = v
+		}
+		if pod.Labels == nil {
+			pod.Labels = map[string]string{}
+		}
+		pod.Labels[nodePoolAnnotation] = pool
+		return nil
+	}
+	log.Printf("Session %s/%s: node pool %q is no longer in the placement policy, not pinning the runner", session.GetNamespace(), session.GetName(), pool)
+	return nil
+}
@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func admissionTestSettings() map[string]interface{} {
+	return map[string]interface{}{
+		"placementPolicy": map[string]interface{}{
+			"onInsufficientCapacity": "fallback",
+			"nodePools": []interface{}{
+				map[string]interface{}{"name": "general", "nodeSelector": map[string]interface{}{"pool": "general"}},
+				map[string]interface{}{"name": "burst", "nodeSelector": map[string]interface{}{"pool": "burst"}},
+			},
+		},
+	}
+}
+
+// TestApplyNodePool_PinsRunner verifies the admitted pool's selector and label are applied
+func TestApplyNodePool_PinsRunner(t *testing.T) {
+	session := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	session.SetAnnotations(map[string]string{nodePoolAnnotation: "burst"})
+	pod := &corev1.Pod{Spec: corev1.PodSpec{NodeSelector: map[string]string{"kubernetes.io/os": "linux"}}}
+
+	if err := applyNodePool(pod, session, admissionTestSettings()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if pod.Spec.NodeSelector["pool"] != "burst" || pod.Spec.NodeSelector["kubernetes.io/os"] != "linux" {
+		t.Errorf("expected burst selector merged into existing, got %v", pod.Spec.NodeSelector)
+	}
+	if pod.Labels[nodePoolAnnotation] != "burst" {
+		t.Errorf("expected node pool label, got %v", pod.Labels)
+	}
+}
+
+// TestApplyNodePool_UnknownPool verifies a pool removed from the policy leaves the pod unpinned
+func TestApplyNodePool_UnknownPool(t *testing.T) {
+	session := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	session.SetAnnotations(map[string]string{nodePoolAnnotation: "gpu"})
+	pod := &corev1.Pod{}
+
+	if err := applyNodePool(pod, session, admissionTestSettings()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(pod.Spec.NodeSelector) != 0 || len(pod.Labels) != 0 {
+		t.Errorf("expected pod untouched, got selector %v labels %v", pod.Spec.NodeSelector, pod.Labels)
+	}
+}
+
+// TestIsQueuedForAdmission verifies only the queued label holds a session
+func TestIsQueuedForAdmission(t *testing.T) {
+	session := &unstructured.Unstructured{Object: map[string]interface{}{}}
+	if IsQueuedForAdmission(session) {
+		t.Errorf("expected unlabeled session to be admitted")
+	}
+	session.SetLabels(map[string]string{admissionLabel: admissionQueued})
+	if !IsQueuedForAdmission(session) {
+		t.Errorf("expected queued session")
+	}
+}
@@ -0,0 +1,305 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"ambient-code-operator/internal/config"
+	"ambient-code-operator/internal/types"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	intstr "k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// Direct runner access for debugging (ProjectSettings spec.runnerDebugAccess). An
+// oauth-proxy container in the runner pod fronts the runner's FastAPI server, and a
+// per-session Route (or Ingress) exposes the proxy. Only callers allowed to update the
+// session get through.
+const (
+	debugProxyContainerName = "debug-proxy"
+	debugProxyPort          = 4180
+	debugProxyCookieKey     = "cookie-secret"
+	debugProxyCookieDir     = "/etc/debug-proxy"
+	debugProxyTokenVolume   = "debug-proxy-token"
+	debugProxyCookieVolume  = "debug-proxy-cookie"
+)
+
+var routeGVR = schema.GroupVersionResource{Group: "route.openshift.io", Version: "v1", Resource: "routes"}
+
+// debugAccessName names the session's debug ServiceAccount, cookie Secret, Service and Route/Ingress
+func debugAccessName(sessionName string) string {
+	return "ambient-debug-" + sessionName
+}
+
+// runnerDebugAccessEnabled reports whether the project enables direct runner access
+func runnerDebugAccessEnabled(projectSettings map[string]interface{}) bool {
+	enabled, _, _ := unstructured.NestedBool(projectSettings, "runnerDebugAccess", "enabled")
+	return enabled
+}
+
+// debugProxyArgs are the proxy's arguments. By default the proxy uses the OpenShift
+// provider and requires update on the session; RUNNER_DEBUG_PROXY_ARGS replaces the
+// provider arguments, e.g. with an OIDC provider on plain Kubernetes.
+func debugProxyArgs(appConfig *config.Config, namespace, sessionName string) []string {
+	args := []string{
+		fmt.Sprintf("--http-address=0.0.0.0:%d", debugProxyPort),
+		"--https-address=",
+		"--upstream=http://localhost:8001",
+		fmt.Sprintf("--cookie-secret-file=%s/%s", debugProxyCookieDir, debugProxyCookieKey),
+		"--cookie-secure=true",
+	}
+	if len(appConfig.DebugProxyArgs) > 0 {
+		return append(args, appConfig.DebugProxyArgs...)
+	}
+	sar, _ := json.Marshal(map[string]string{
+		"group":        "vteam.ambient-code",
+		"resource":     "agenticsessions",
+		"resourceName": sessionName,
+		"namespace":    namespace,
+		"verb":         "update",
+	})
+	return append(args,
+		"--provider=openshift",
+		"--openshift-service-account="+debugAccessName(sessionName),
+		"--openshift-sar="+string(sar),
+		"--skip-provider-button",
+	)
+}
+
+// errDebugAccessRemote refuses debug access for runners on remote runner clusters: the
+// Route is created in this cluster, and the Secret and ServiceAccount would be owned by
+// a session that doesn't exist where they are
+var errDebugAccessRemote = fmt.Errorf("runner debug access is not available on remote runner clusters")
+
+// prepareDebugAccess creates the session's debug ServiceAccount and cookie Secret,
+// owned by the session, and adds the proxy to the pod spec. The pod runs as the debug
+// ServiceAccount so OpenShift accepts it as the proxy's OAuth client; its token is
+// only mounted into the proxy.
+func prepareDebugAccess(ctx context.Context, runner *runnerCluster, appConfig *config.Config, session *unstructured.Unstructured, podSpec *corev1.PodSpec) error {
+	if runner.remote() {
+		return errDebugAccessRemote
+	}
+	client := runner.client
+	namespace, sessionName := session.GetNamespace(), session.GetName()
+	name := debugAccessName(sessionName)
+	owner := []v1.OwnerReference{{
+		APIVersion: "vteam.ambient-code/v1alpha1",
+		Kind:       "AgenticSession",
+		Name:       sessionName,
+		UID:        session.GetUID(),
+		Controller: boolPtr(true),
+	}}
+	labels := map[string]string{"app": "ambient-code", "agentic-session": sessionName}
+
+	redirect, _ := json.Marshal(map[string]interface{}{
+		"kind":       "OAuthRedirectReference",
+		"apiVersion": "v1",
+		"reference":  map[string]string{"kind": "Route", "name": name},
+	})
+	sa := &corev1.ServiceAccount{
+		ObjectMeta: v1.ObjectMeta{
+			Name:            name,
+			Namespace:       namespace,
+			Labels:          labels,
+			OwnerReferences: owner,
+			Annotations: map[string]string{
+				"serviceaccounts.openshift.io/oauth-redirectreference.primary": string(redirect),
+			},
+		},
+		AutomountServiceAccountToken: boolPtr(false),
+	}
+	if _, err := client.CoreV1().ServiceAccounts(namespace).Create(ctx, sa, v1.CreateOptions{}); err != nil && !errors.IsAlreadyExists(err) {
+		return fmt.Errorf("failed to create debug ServiceAccount %s: %w", name, err)
+	}
+
+	cookie := make([]byte, 32)
+	if _, err := rand.Read(cookie); err != nil {
+		return err
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: v1.ObjectMeta{Name: name, Namespace: namespace, Labels: labels, OwnerReferences: owner},
+		Type:       corev1.SecretTypeOpaque,
+		Data:       map[string][]byte{debugProxyCookieKey: []byte(base64.URLEncoding.EncodeToString(cookie))},
+	}
+	if _, err := client.CoreV1().Secrets(namespace).Create(ctx, secret, v1.CreateOptions{}); err != nil && !errors.IsAlreadyExists(err) {
+		return fmt.Errorf("failed to create debug proxy Secret %s: %w", name, err)
+	}
+
+	addDebugProxy(podSpec, appConfig, namespace, sessionName)
+	return nil
+}
+
+// addDebugProxy runs the pod as the debug ServiceAccount and adds the proxy as a
+// native sidecar, so it never keeps the pod from completing when the runner exits
+func addDebugProxy(podSpec *corev1.PodSpec, appConfig *config.Config, namespace, sessionName string) {
+	name := debugAccessName(sessionName)
+	expiry := int64(3600)
+	always := corev1.ContainerRestartPolicyAlways
+	podSpec.ServiceAccountName = name
+	podSpec.Volumes = append(podSpec.Volumes,
+		corev1.Volume{
+			Name:         debugProxyCookieVolume,
+			VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: name}},
+		},
+		corev1.Volume{
+			Name: debugProxyTokenVolume,
+			VolumeSource: corev1.VolumeSource{Projected: &corev1.ProjectedVolumeSource{Sources: []corev1.VolumeProjection{
+				{ServiceAccountToken: &corev1.ServiceAccountTokenProjection{Path: "token", ExpirationSeconds: &expiry}},
+				{ConfigMap: &corev1.ConfigMapProjection{
+					LocalObjectReference: corev1.LocalObjectReference{Name: "kube-root-ca.crt"},
+					Items:                []corev1.KeyToPath{{Key: "ca.crt", Path: "ca.crt"}},
+				}},
+				{DownwardAPI: &corev1.DownwardAPIProjection{Items: []corev1.DownwardAPIVolumeFile{{
+					Path:     "namespace",
+					FieldRef: &corev1.ObjectFieldSelector{FieldPath: "metadata.namespace"},
+				}}}},
+			}}},
+		},
+	)
+	podSpec.InitContainers = append(podSpec.InitContainers, corev1.Container{
+		Name:            debugProxyContainerName,
+		Image:           appConfig.DebugProxyImage,
+		ImagePullPolicy: appConfig.ImagePullPolicy,
+		RestartPolicy:   &always,
+		Args:            debugProxyArgs(appConfig, namespace, sessionName),
+		Ports:           []corev1.ContainerPort{{Name: "debug", ContainerPort: debugProxyPort, Protocol: corev1.ProtocolTCP}},
+		VolumeMounts: []corev1.VolumeMount{
+			{Name: debugProxyCookieVolume, MountPath: debugProxyCookieDir, ReadOnly: true},
+			{Name: debugProxyTokenVolume, MountPath: "/var/run/secrets/kubernetes.io/serviceaccount", ReadOnly: true},
+		},
+		SecurityContext: &corev1.SecurityContext{
+			AllowPrivilegeEscalation: boolPtr(false),
+			Capabilities:             &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}},
+		},
+	})
+}
+
+// exposeDebugAccess creates the Service and the Route (or, with RUNNER_DEBUG_INGRESS_DOMAIN,
+// the Ingress) for the session's debug proxy. Both are owned by the pod and go away with
+// it. Returns the URL the runner is reachable at, or "" when the Route has no host yet.
+func exposeDebugAccess(ctx context.Context, runner *runnerCluster, appConfig *config.Config, namespace, sessionName string, pod *corev1.Pod) (string, error) {
+	if runner.remote() {
+		return "", errDebugAccessRemote
+	}
+	client := runner.client
+	name := debugAccessName(sessionName)
+	labels := map[string]string{"app": "ambient-code", "agentic-session": sessionName}
+	owner := []v1.OwnerReference{{
+		APIVersion: "v1",
+		Kind:       "Pod",
+		Name:       pod.Name,
+		UID:        pod.UID,
+		Controller: boolPtr(true),
+	}}
+
+	svc := &corev1.Service{
+		ObjectMeta: v1.ObjectMeta{Name: name, Namespace: namespace, Labels: labels, OwnerReferences: owner},
+		Spec: corev1.ServiceSpec{
+			Type:     corev1.ServiceTypeClusterIP,
+			Selector: map[string]string{"agentic-session": sessionName, "app": "ambient-code-runner"},
+			Ports:    []corev1.ServicePort{{Name: "debug", Protocol: corev1.ProtocolTCP, Port: debugProxyPort, TargetPort: intstr.FromString("debug")}},
+		},
+	}
+	if _, err := client.CoreV1().Services(namespace).Create(ctx, svc, v1.CreateOptions{}); err != nil && !errors.IsAlreadyExists(err) {
+		return "", fmt.Errorf("failed to create debug Service %s: %w", name, err)
+	}
+
+	if domain := appConfig.DebugIngressDomain; domain != "" {
+		host := fmt.Sprintf("%s-%s.%s", sessionName, namespace, strings.TrimPrefix(domain, "."))
+		pathType := networkingv1.PathTypePrefix
+		ing := &networkingv1.Ingress{
+			ObjectMeta: v1.ObjectMeta{Name: name, Namespace: namespace, Labels: labels, OwnerReferences: owner},
+			Spec: networkingv1.IngressSpec{
+				TLS: []networkingv1.IngressTLS{{Hosts: []string{host}}},
+				Rules: []networkingv1.IngressRule{{
+					Host: host,
+					IngressRuleValue: networkingv1.IngressRuleValue{HTTP: &networkingv1.HTTPIngressRuleValue{
+						Paths: []networkingv1.HTTPIngressPath{{
+							Path:     "/",
+							PathType: &pathType,
+							Backend: networkingv1.IngressBackend{Service: &networkingv1.IngressServiceBackend{
+								Name: name,
+								Port: networkingv1.ServiceBackendPort{Name: "debug"},
+							}},
+						}},
+					}},
+				}},
+			},
+		}
+		if _, err := client.NetworkingV1().Ingresses(namespace).Create(ctx, ing, v1.CreateOptions{}); err != nil && !errors.IsAlreadyExists(err) {
+			return "", fmt.Errorf("failed to create debug Ingress %s: %w", name, err)
+		}
+		return "https://" + host, nil
+	}
+
+	route := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "route.openshift.io/v1",
+		"kind":       "Route",
+		"metadata": map[string]interface{}{
+			"name":      name,
+			"namespace": namespace,
+			"labels":    map[string]interface{}{"app": "ambient-code", "agentic-session": sessionName},
+			"ownerReferences": []interface{}{map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "Pod",
+				"name":       pod.Name,
+				"uid":        string(pod.UID),
+				"controller": true,
+			}},
+		},
+		"spec": map[string]interface{}{
+			"to":   map[string]interface{}{"kind": "Service", "name": name},
+			"port": map[string]interface{}{"targetPort": "debug"},
+			"tls": map[string]interface{}{
+				"termination":                   "edge",
+				"insecureEdgeTerminationPolicy": "Redirect",
+			},
+		},
+	}}
+	created, err := config.DynamicClient.Resource(routeGVR).Namespace(namespace).Create(ctx, route, v1.CreateOptions{})
+	if errors.IsAlreadyExists(err) {
+		created, err = config.DynamicClient.Resource(routeGVR).Namespace(namespace).Get(ctx, name, v1.GetOptions{})
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to create debug Route %s: %w", name, err)
+	}
+	if host, _, _ := unstructured.NestedString(created.Object, "spec", "host"); host != "" {
+		return "https://" + host, nil
+	}
+	return "", nil
+}
+
+// deleteDebugAccess removes the session's debug Service and Route/Ingress and clears
+// status.debugURL. They are owned by the pod; this only makes cleanup immediate, like
+// the other per-pod services. Remote runners never get debug access.
+func deleteDebugAccess(ctx context.Context, runner *runnerCluster, namespace, sessionName string) {
+	if runner.remote() {
+		return
+	}
+	name := debugAccessName(sessionName)
+	_ = runner.client.CoreV1().Services(namespace).Delete(ctx, name, v1.DeleteOptions{})
+	_ = runner.client.NetworkingV1().Ingresses(namespace).Delete(ctx, name, v1.DeleteOptions{})
+	if config.DynamicClient == nil {
+		return
+	}
+	_ = config.DynamicClient.Resource(routeGVR).Namespace(namespace).Delete(ctx, name, v1.DeleteOptions{})
+
+	session, err := config.DynamicClient.Resource(types.GetAgenticSessionResource()).Namespace(namespace).Get(ctx, sessionName, v1.GetOptions{})
+	if err != nil {
+		return
+	}
+	if _, found, _ := unstructured.NestedString(session.Object, "status", "debugURL"); found {
+		_ = mutateAgenticSessionStatus(namespace, sessionName, func(status map[string]interface{}) {
+			delete(status, "debugURL")
+		})
+	}
+}
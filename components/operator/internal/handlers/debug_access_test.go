@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"ambient-code-operator/internal/config"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+// TestPrepareDebugAccess verifies the proxy runs as a native sidecar under the debug
+// ServiceAccount and authorizes callers against the session
+func TestPrepareDebugAccess(t *testing.T) {
+	if !runnerDebugAccessEnabled(map[string]interface{}{"runnerDebugAccess": map[string]interface{}{"enabled": true}}) {
+		t.Fatal("runnerDebugAccess.enabled=true should enable debug access")
+	}
+	if runnerDebugAccessEnabled(nil) {
+		t.Fatal("debug access should be off without ProjectSettings")
+	}
+
+	client := fake.NewSimpleClientset()
+	session := &unstructured.Unstructured{}
+	session.SetNamespace("team-a")
+	session.SetName("s1")
+	appConfig := &config.Config{DebugProxyImage: "oauth-proxy:test"}
+	podSpec := &corev1.PodSpec{Containers: []corev1.Container{{Name: "ambient-code-runner"}}}
+
+	if err := prepareDebugAccess(context.Background(), &runnerCluster{name: localRunnerCluster, client: client}, appConfig, session, podSpec); err != nil {
+		t.Fatalf("prepareDebugAccess: %v", err)
+	}
+	if podSpec.ServiceAccountName != "ambient-debug-s1" {
+		t.Errorf("ServiceAccountName = %q", podSpec.ServiceAccountName)
+	}
+	if len(podSpec.InitContainers) != 1 || podSpec.InitContainers[0].RestartPolicy == nil {
+		t.Fatalf("expected the proxy as a native sidecar, got %+v", podSpec.InitContainers)
+	}
+	args := strings.Join(podSpec.InitContainers[0].Args, " ")
+	if !strings.Contains(args, "--provider=openshift") || !strings.Contains(args, `"resourceName":"s1"`) || !strings.Contains(args, `"verb":"update"`) {
+		t.Errorf("proxy args = %s", args)
+	}
+
+	sa, err := client.CoreV1().ServiceAccounts("team-a").Get(context.Background(), "ambient-debug-s1", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("debug ServiceAccount not created: %v", err)
+	}
+	if !strings.Contains(sa.Annotations["serviceaccounts.openshift.io/oauth-redirectreference.primary"], `"name":"ambient-debug-s1"`) {
+		t.Errorf("missing OAuth redirect reference: %v", sa.Annotations)
+	}
+	if _, err := client.CoreV1().Secrets("team-a").Get(context.Background(), "ambient-debug-s1", metav1.GetOptions{}); err != nil {
+		t.Errorf("cookie Secret not created: %v", err)
+	}
+
+	// Custom provider arguments replace the OpenShift defaults
+	appConfig.DebugProxyArgs = []string{"--provider=oidc"}
+	args = strings.Join(debugProxyArgs(appConfig, "team-a", "s1"), " ")
+	if strings.Contains(args, "openshift") || !strings.Contains(args, "--provider=oidc") {
+		t.Errorf("proxy args with override = %s", args)
+	}
+}
+
+// TestExposeDebugAccess_Ingress verifies the Ingress host and pod ownership
+func TestExposeDebugAccess_Ingress(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "s1-runner", UID: "pod-uid"}}
+	appConfig := &config.Config{DebugIngressDomain: "debug.example.com"}
+
+	url, err := exposeDebugAccess(context.Background(), &runnerCluster{name: localRunnerCluster, client: client}, appConfig, "team-a", "s1", pod)
+	if err != nil {
+		t.Fatalf("exposeDebugAccess: %v", err)
+	}
+	if url != "https://s1-team-a.debug.example.com" {
+		t.Errorf("url = %q", url)
+	}
+	ing, err := client.NetworkingV1().Ingresses("team-a").Get(context.Background(), "ambient-debug-s1", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Ingress not created: %v", err)
+	}
+	if len(ing.OwnerReferences) != 1 || ing.OwnerReferences[0].UID != "pod-uid" {
+		t.Errorf("Ingress should be owned by the pod, got %+v", ing.OwnerReferences)
+	}
+	if _, err := client.CoreV1().Services("team-a").Get(context.Background(), "ambient-debug-s1", metav1.GetOptions{}); err != nil {
+		t.Errorf("Service not created: %v", err)
+	}
+}
+
+// TestDebugAccess_RemoteRunner verifies nothing is created for a remote runner, whose
+// Route would land in this cluster
+func TestDebugAccess_RemoteRunner(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	remote := &runnerCluster{name: "east", client: client}
+	session := &unstructured.Unstructured{}
+	session.SetNamespace("team-a")
+	session.SetName("s1")
+	appConfig := &config.Config{DebugProxyImage: "oauth-proxy:test"}
+	podSpec := &corev1.PodSpec{Containers: []corev1.Container{{Name: "ambient-code-runner"}}}
+
+	if err := prepareDebugAccess(context.Background(), remote, appConfig, session, podSpec); err != errDebugAccessRemote {
+		t.Fatalf("prepareDebugAccess on a remote runner = %v, want errDebugAccessRemote", err)
+	}
+	if podSpec.ServiceAccountName != "" || len(podSpec.InitContainers) != 0 {
+		t.Errorf("pod spec changed for a remote runner: %+v", podSpec)
+	}
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "s1-runner", UID: "pod-uid"}}
+	if _, err := exposeDebugAccess(context.Background(), remote, appConfig, "team-a", "s1", pod); err != errDebugAccessRemote {
+		t.Fatalf("exposeDebugAccess on a remote runner = %v, want errDebugAccessRemote", err)
+	}
+	if svcs, _ := client.CoreV1().Services("team-a").List(context.Background(), metav1.ListOptions{}); len(svcs.Items) != 0 {
+		t.Errorf("Services created for a remote runner: %d", len(svcs.Items))
+	}
+}
@@ -0,0 +1,216 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	intstr "k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// resumableAnnotation marks a session that was stopped by a disruption and can be
+	// restarted with desired-phase=Running to continue where it left off.
+	resumableAnnotation = "ambient-code.io/resumable"
+
+	// podDisruptionTargetCondition is set by the control plane on pods about to be
+	// terminated by eviction, preemption, or taint-based deletion.
+	podDisruptionTargetCondition corev1.PodConditionType = "DisruptionTarget"
+)
+
+// drainTaints are node taint keys set by node lifecycle controllers once they have
+// decided to drain and remove the node. A plain cordon (spec.unschedulable) is not a
+// drain: it is often used only to keep new pods off a node.
+var drainTaints = map[string]bool{
+	"karpenter.sh/disrupted":            true,
+	"karpenter.sh/disruption":           true,
+	"ToBeDeletedByClusterAutoscaler":    true,
+	"node.kubernetes.io/out-of-service": true,
+}
+
+// ensureRunnerPDB creates a PodDisruptionBudget that blocks voluntary eviction of the
+// runner pod. Drains therefore wait for the operator, which checkpoints the run and
+// deletes the pod itself. The PDB is owned by the pod and disappears with it.
+func ensureRunnerPDB(client kubernetes.Interface, namespace, sessionName string, pod *corev1.Pod) error {
+	maxUnavailable := intstr.FromInt(0)
+	pdb := &policyv1.PodDisruptionBudget{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      fmt.Sprintf("%s-runner", sessionName),
+			Namespace: namespace,
+			Labels: map[string]string{
+				"app":             "ambient-code",
+				"agentic-session": sessionName,
+			},
+			OwnerReferences: []v1.OwnerReference{{
+				APIVersion: "v1",
+				Kind:       "Pod",
+				Name:       pod.Name,
+				UID:        pod.UID,
+				Controller: boolPtr(true),
+			}},
+		},
+		Spec: policyv1.PodDisruptionBudgetSpec{
+			MaxUnavailable: &maxUnavailable,
+			Selector: &v1.LabelSelector{
+				MatchLabels: map[string]string{"agentic-session": sessionName, "app": "ambient-code-runner"},
+			},
+		},
+	}
+	_, err := client.PolicyV1().PodDisruptionBudgets(namespace).Create(context.TODO(), pdb, v1.CreateOptions{})
+	if err != nil && !errors.IsAlreadyExists(err) {
+		return fmt.Errorf("failed to create PodDisruptionBudget for %s: %w", sessionName, err)
+	}
+	return nil
+}
+
+// RunnerDisruptionReason reports whether the runner pod is being disrupted and why:
+// the pod is targeted for or failed by eviction, or its node carries a drain taint.
+// node may be nil when the pod is not yet scheduled or the node lookup failed.
+func RunnerDisruptionReason(pod *corev1.Pod, node *corev1.Node) (string, bool) {
+	if pod.DeletionTimestamp != nil {
+		return "", false
+	}
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == podDisruptionTargetCondition && cond.Status == corev1.ConditionTrue {
+			return fmt.Sprintf("%s: %s", cond.Reason, cond.Message), true
+		}
+	}
+	if pod.Status.Phase == corev1.PodFailed && pod.Status.Reason == "Evicted" {
+		return fmt.Sprintf("Evicted: %s", pod.Status.Message), true
+	}
+	if node != nil {
+		for _, taint := range node.Spec.Taints {
+			if drainTaints[taint.Key] {
+				return fmt.Sprintf("NodeDrain: node %s has taint %s", node.Name, taint.Key), true
+			}
+		}
+	}
+	return "", false
+}
+
+// CheckpointDisruptedSession interrupts the active run, marks the session resumable, and
+// deletes the runner pod so a node drain can proceed. The session ends in Stopped with a
+// Resumable condition; setting desired-phase=Running restarts it from the synced state.
+// Spot sessions are restarted automatically on on-demand capacity.
+func CheckpointDisruptedSession(ctx context.Context, session *unstructured.Unstructured, reason string) error {
+	namespace := session.GetNamespace()
+	name := session.GetName()
+	podName := RunnerPodName(session)
+
+	log.Printf("[Disruption] Checkpointing session %s/%s: %s", namespace, name, reason)
+
+	// Best effort: ask the runner to stop the current turn cleanly before termination.
+	// state-sync performs its final upload during the pod's termination grace period.
+	if err := interruptRunner(ctx, namespace, name); err != nil {
+		log.Printf("[Disruption] Interrupt for %s/%s failed (continuing): %v", namespace, name, err)
+	}
+
+	annotations := map[string]string{}
+	for k, v := range session.GetAnnotations() {
+		annotations[k] = v
+	}
+	annotations[resumableAnnotation] = "true"
+	spot := isSpotSession(session)
+	if spot {
+		markSpotPreempted(annotations)
+	}
+	if err := updateAnnotations(namespace, name, annotations); err != nil {
+		return err
+	}
+	resumeReason := "Disrupted"
+	if spot {
+		resumeReason = "SpotPreempted"
+	}
+
+	statusPatch := NewStatusPatch(namespace, name)
+	statusPatch.SetField("phase", "Stopped")
+	statusPatch.SetField("completionTime", time.Now().UTC().Format(time.RFC3339))
+	statusPatch.AddCondition(conditionUpdate{
+		Type:    conditionReady,
+		Status:  "False",
+		Reason:  "Disrupted",
+		Message: reason,
+	})
+	statusPatch.AddCondition(conditionUpdate{
+		Type:    conditionRunActive,
+		Status:  "False",
+		Reason:  "Interrupted",
+		Message: "Run interrupted by pod disruption",
+	})
+	statusPatch.AddCondition(conditionUpdate{
+		Type:    conditionResumable,
+		Status:  "True",
+		Reason:  resumeReason,
+		Message: reason,
+	})
+	if err := statusPatch.Apply(); err != nil {
+		return err
+	}
+	recordSessionWarning(session, eventReasonSessionDisrupted, "Runner disrupted, session marked resumable: %s", reason)
+
+	_ = ensureSessionIsInteractive(namespace, name)
+	return deletePodAndPerPodService(namespace, podName, name)
+}
+
+// RecoverLostSpotRunner handles a spot runner whose pod vanished without a drain
+// (e.g. the instance was reclaimed). It switches the session to on-demand capacity and
+// requests run resubmission; the caller then resets the session to Pending.
+func RecoverLostSpotRunner(ctx context.Context, session *unstructured.Unstructured) error {
+	if !isSpotSession(session) {
+		return nil
+	}
+	// The caller restarts the session itself, so desired-phase is left alone
+	recordSessionWarning(session, eventReasonSessionDisrupted, "Spot runner lost, rescheduling on on-demand capacity")
+	return patchAnnotations(session.GetNamespace(), session.GetName(), spotFallbackAnnotations())
+}
+
+// spotFallbackAnnotations are the annotations that move a spot session to on-demand
+// capacity and have the backend resubmit the interrupted run
+func spotFallbackAnnotations() map[string]string {
+	return map[string]string{
+		spotFallbackAnnotation: "true",
+		resubmitRunAnnotation:  "true",
+	}
+}
+
+// markSpotPreempted sets the spot fallback annotations and restarts the session
+func markSpotPreempted(annotations map[string]string) {
+	for k, v := range spotFallbackAnnotations() {
+		annotations[k] = v
+	}
+	annotations["ambient-code.io/desired-phase"] = "Running"
+}
+
+// interruptRunner POSTs to the runner's AG-UI interrupt endpoint.
+func interruptRunner(ctx context.Context, namespace, sessionName string) error {
+	runner, err := runnerClusterFor(ctx, namespace, sessionName)
+	if err != nil {
+		return err
+	}
+	url := runner.runnerEndpoint(namespace, sessionName) + "interrupt"
+	reqCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(reqCtx, http.MethodPost, url, bytes.NewReader([]byte("{}")))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("runner returned %d", resp.StatusCode)
+	}
+	return nil
+}
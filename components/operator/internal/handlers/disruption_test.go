@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TestRunnerDisruptionReason covers the signals that trigger checkpointing
+func TestRunnerDisruptionReason(t *testing.T) {
+	cordoned := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-a"}, Spec: corev1.NodeSpec{Unschedulable: true}}
+	draining := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-a"}, Spec: corev1.NodeSpec{
+		Unschedulable: true,
+		Taints:        []corev1.Taint{{Key: "karpenter.sh/disrupted", Effect: corev1.TaintEffectNoSchedule}},
+	}}
+	healthy := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-a"}}
+	now := metav1.Now()
+
+	cases := []struct {
+		name string
+		pod  *corev1.Pod
+		node *corev1.Node
+		want bool
+	}{
+		{"healthy pod and node", &corev1.Pod{}, healthy, false},
+		{"cordoned node", &corev1.Pod{}, cordoned, false},
+		{"drain taint", &corev1.Pod{}, draining, true},
+		{"disruption target condition", &corev1.Pod{Status: corev1.PodStatus{Conditions: []corev1.PodCondition{
+			{Type: podDisruptionTargetCondition, Status: corev1.ConditionTrue, Reason: "EvictionByEvictionAPI"},
+		}}}, nil, true},
+		{"evicted pod", &corev1.Pod{Status: corev1.PodStatus{Phase: corev1.PodFailed, Reason: "Evicted"}}, nil, true},
+		{"already terminating", &corev1.Pod{ObjectMeta: metav1.ObjectMeta{DeletionTimestamp: &now}}, draining, false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, got := RunnerDisruptionReason(tc.pod, tc.node); got != tc.want {
+				t.Errorf("RunnerDisruptionReason() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,49 @@
+package handlers
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+)
+
+// Event reasons emitted on AgenticSession resources.
+const (
+	eventReasonPodCreated            = "PodCreated"
+	eventReasonPodCreateFailed       = "PodCreateFailed"
+	eventReasonServiceCreated        = "ServiceCreated"
+	eventReasonServiceCreateFailed   = "ServiceCreateFailed"
+	eventReasonSecretMissing         = "SecretMissing"
+	eventReasonInvalidConfig         = "InvalidConfig"
+	eventReasonSessionFailed         = "SessionFailed"
+	eventReasonSessionCompleted      = "SessionCompleted"
+	eventReasonSessionStopped        = "SessionStopped"
+	eventReasonSessionDisrupted      = "SessionDisrupted"
+	eventReasonRunnerClusterPlaced   = "RunnerClusterPlaced"
+	eventReasonSessionQueued         = "SessionQueued"
+	eventReasonWorkspaceResized      = "WorkspaceResized"
+	eventReasonWorkspaceResizeFailed = "WorkspaceResizeFailed"
+	eventReasonWarmRunnerClaimed     = "WarmRunnerClaimed"
+)
+
+// eventRecorder is installed by the AgenticSession controller at setup.
+// Nil means events are disabled (tests, legacy watch mode).
+var eventRecorder record.EventRecorder
+
+// SetEventRecorder installs the recorder used to emit Kubernetes Events on sessions.
+func SetEventRecorder(r record.EventRecorder) {
+	eventRecorder = r
+}
+
+// recordSessionEvent emits a Kubernetes Event on the session object.
+// The object must carry apiVersion/kind (true for objects read via the dynamic client).
+func recordSessionEvent(session runtime.Object, eventType, reason, messageFmt string, args ...interface{}) {
+	if eventRecorder == nil || session == nil {
+		return
+	}
+	eventRecorder.Eventf(session, eventType, reason, messageFmt, args...)
+}
+
+// recordSessionWarning is shorthand for a Warning event.
+func recordSessionWarning(session runtime.Object, reason, messageFmt string, args ...interface{}) {
+	recordSessionEvent(session, corev1.EventTypeWarning, reason, messageFmt, args...)
+}
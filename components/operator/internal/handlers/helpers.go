@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"log"
 	"strings"
@@ -14,6 +15,7 @@ import (
 	"k8s.io/apimachinery/pkg/api/errors"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	k8stypes "k8s.io/apimachinery/pkg/types"
 )
 
 const (
@@ -26,6 +28,12 @@ const (
 	conditionReposReconciled           = "ReposReconciled"
 	conditionWorkflowReconciled        = "WorkflowReconciled"
 	conditionReconciled                = "Reconciled"
+	conditionWorkspaceReady            = "WorkspaceReady"
+	conditionRunnerReady               = "RunnerReady"
+	conditionCredentialsResolved       = "CredentialsResolved"
+	conditionRunActive                 = "RunActive"
+	conditionResumable                 = "Resumable"
+	conditionAdmitted                  = "Admitted"
 	runnerTokenSecretAnnotation        = "ambient-code.io/runner-token-secret"
 	runnerServiceAccountAnnotation     = "ambient-code.io/runner-sa"
 	runnerTokenRefreshedAtAnnotation   = "ambient-code.io/token-refreshed-at"
@@ -221,6 +229,23 @@ func updateAnnotations(sessionNamespace, name string, annotations map[string]str
 	return nil
 }
 
+// patchAnnotations sets the given annotations on the AgenticSession CR with a merge
+// patch, leaving its other annotations as they are.
+func patchAnnotations(sessionNamespace, name string, annotations map[string]string) error {
+	gvr := types.GetAgenticSessionResource()
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{"annotations": annotations},
+	})
+	if err != nil {
+		return err
+	}
+	_, err = config.DynamicClient.Resource(gvr).Namespace(sessionNamespace).Patch(context.TODO(), name, k8stypes.MergePatchType, patch, v1.PatchOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("failed to patch annotations for %s: %w", name, err)
+	}
+	return nil
+}
+
 // clearAnnotation removes a specific annotation from the AgenticSession CR.
 func clearAnnotation(sessionNamespace, name, annotationKey string) error {
 	gvr := types.GetAgenticSessionResource()
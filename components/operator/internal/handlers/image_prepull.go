@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"ambient-code-operator/internal/config"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// imagePrepullName is the DaemonSet caching runner images on every node
+	imagePrepullName = "ambient-image-prepull"
+	// imagePrepullAnnotation lists the images the DaemonSet was built for
+	imagePrepullAnnotation = "ambient-code.io/prepull-images"
+)
+
+// prepullImages returns the images new runner pods start with, plus PREPULL_IMAGES
+// (e.g. custom runner images projects allow), without duplicates
+func prepullImages(appConfig *config.Config) []string {
+	seen := map[string]bool{}
+	images := []string{}
+	for _, image := range append([]string{appConfig.AmbientCodeRunnerImage, appConfig.ContentServiceImage, appConfig.StateSyncImage}, appConfig.PrepullImages...) {
+		image = strings.TrimSpace(image)
+		if image == "" || seen[image] {
+			continue
+		}
+		seen[image] = true
+		images = append(images, image)
+	}
+	return images
+}
+
+// ReconcileImagePrepull creates, updates or (when RUNNER_IMAGE_PREPULL is off) removes
+// the image prepull DaemonSet in the operator namespace
+func ReconcileImagePrepull(ctx context.Context, appConfig *config.Config) error {
+	return reconcileImagePrepull(ctx, config.K8sClient, appConfig)
+}
+
+// reconcileImagePrepull runs one init container per image that exits immediately: the
+// kubelet pulls each image when the pod lands on a node, and the pause container keeps
+// the pod (and so the images, from garbage collection) around. Pods tolerate every taint
+// so dedicated and spot runner nodes are covered; PREPULL_NODE_SELECTOR narrows them.
+func reconcileImagePrepull(ctx context.Context, client kubernetes.Interface, appConfig *config.Config) error {
+	namespace := appConfig.Namespace
+	daemonSets := client.AppsV1().DaemonSets(namespace)
+	if !appConfig.ImagePrepull {
+		if err := daemonSets.Delete(ctx, imagePrepullName, v1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("delete image prepull daemonset: %w", err)
+		}
+		return nil
+	}
+
+	images := prepullImages(appConfig)
+	smallResources := corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("5m"), corev1.ResourceMemory: resource.MustParse("8Mi")},
+		Limits:   corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("50m"), corev1.ResourceMemory: resource.MustParse("32Mi")},
+	}
+	securityContext := &corev1.SecurityContext{
+		AllowPrivilegeEscalation: boolPtr(false),
+		Capabilities:             &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}},
+	}
+	pulls := make([]corev1.Container, 0, len(images))
+	for i, image := range images {
+		pulls = append(pulls, corev1.Container{
+			Name:            fmt.Sprintf("pull-%d", i),
+			Image:           image,
+			ImagePullPolicy: appConfig.ImagePullPolicy,
+			Command:         []string{"/bin/sh", "-c", "exit 0"},
+			Resources:       smallResources,
+			SecurityContext: securityContext,
+		})
+	}
+
+	labels := map[string]string{"app": imagePrepullName}
+	desired := &appsv1.DaemonSet{
+		ObjectMeta: v1.ObjectMeta{
+			Name:        imagePrepullName,
+			Namespace:   namespace,
+			Labels:      labels,
+			Annotations: map[string]string{imagePrepullAnnotation: strings.Join(images, ",")},
+		},
+		Spec: appsv1.DaemonSetSpec{
+			Selector: &v1.LabelSelector{MatchLabels: labels},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: v1.ObjectMeta{Labels: labels},
+				Spec: corev1.PodSpec{
+					AutomountServiceAccountToken: boolPtr(false),
+					NodeSelector:                 appConfig.PrepullNodeSelector,
+					Tolerations:                  []corev1.Toleration{{Operator: corev1.TolerationOpExists}},
+					InitContainers:               pulls,
+					Containers: []corev1.Container{{
+						Name:            "pause",
+						Image:           appConfig.PrepullPauseImage,
+						ImagePullPolicy: corev1.PullIfNotPresent,
+						Resources:       smallResources,
+						SecurityContext: securityContext,
+					}},
+				},
+			},
+		},
+	}
+
+	current, err := daemonSets.Get(ctx, imagePrepullName, v1.GetOptions{})
+	if errors.IsNotFound(err) {
+		if _, err := daemonSets.Create(ctx, desired, v1.CreateOptions{}); err != nil {
+			return fmt.Errorf("create image prepull daemonset: %w", err)
+		}
+		log.Printf("Created image prepull daemonset %s/%s for %d images", namespace, imagePrepullName, len(images))
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("get image prepull daemonset: %w", err)
+	}
+	if current.Annotations[imagePrepullAnnotation] == desired.Annotations[imagePrepullAnnotation] {
+		return nil
+	}
+	current.Annotations = desired.Annotations
+	current.Spec.Template = desired.Spec.Template
+	if _, err := daemonSets.Update(ctx, current, v1.UpdateOptions{}); err != nil {
+		return fmt.Errorf("update image prepull daemonset: %w", err)
+	}
+	log.Printf("Updated image prepull daemonset %s/%s for %d images", namespace, imagePrepullName, len(images))
+	return nil
+}
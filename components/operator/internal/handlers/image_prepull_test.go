@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+
+	"ambient-code-operator/internal/config"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestReconcileImagePrepull(t *testing.T) {
+	ctx := context.Background()
+	client := fake.NewSimpleClientset()
+	appConfig := &config.Config{
+		Namespace:              "ambient-code",
+		AmbientCodeRunnerImage: "runner:v1",
+		ContentServiceImage:    "backend:v1",
+		StateSyncImage:         "sync:v1",
+		PrepullImages:          []string{"custom-runner:v1", "runner:v1"},
+		PrepullPauseImage:      "pause:3.9",
+		ImagePrepull:           true,
+	}
+
+	if err := reconcileImagePrepull(ctx, client, appConfig); err != nil {
+		t.Fatalf("reconcileImagePrepull: %v", err)
+	}
+	ds, err := client.AppsV1().DaemonSets("ambient-code").Get(ctx, imagePrepullName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("daemonset not created: %v", err)
+	}
+	pulls := ds.Spec.Template.Spec.InitContainers
+	if len(pulls) != 4 {
+		t.Fatalf("expected one init container per distinct image, got %d", len(pulls))
+	}
+	if pulls[3].Image != "custom-runner:v1" || ds.Spec.Template.Spec.Containers[0].Image != "pause:3.9" {
+		t.Errorf("unexpected images: %+v", ds.Spec.Template.Spec)
+	}
+
+	// A new runner image rolls the DaemonSet
+	appConfig.AmbientCodeRunnerImage = "runner:v2"
+	if err := reconcileImagePrepull(ctx, client, appConfig); err != nil {
+		t.Fatalf("reconcile with new image: %v", err)
+	}
+	ds, _ = client.AppsV1().DaemonSets("ambient-code").Get(ctx, imagePrepullName, metav1.GetOptions{})
+	if got := ds.Spec.Template.Spec.InitContainers[0].Image; got != "runner:v2" {
+		t.Errorf("runner image = %s", got)
+	}
+
+	appConfig.ImagePrepull = false
+	if err := reconcileImagePrepull(ctx, client, appConfig); err != nil {
+		t.Fatalf("reconcile disabled: %v", err)
+	}
+	if _, err := client.AppsV1().DaemonSets("ambient-code").Get(ctx, imagePrepullName, metav1.GetOptions{}); !errors.IsNotFound(err) {
+		t.Errorf("disabled prepull should delete the daemonset, got %v", err)
+	}
+}
@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"sort"
+
+	"ambient-code-operator/internal/config"
+	"ambient-code-operator/internal/types"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// projectMCPConfigDir is where the project MCP server registry is mounted in the runner
+const projectMCPConfigDir = "/etc/ambient/mcp"
+
+// projectMCPServer is the subset of the backend's MCPServerConfig the operator needs
+type projectMCPServer struct {
+	Name                 string `json:"name"`
+	CredentialsSecretRef string `json:"credentialsSecretRef,omitempty"`
+	Enabled              bool   `json:"enabled"`
+}
+
+// mcpCredentialSecretNames returns the credential secrets referenced by enabled servers
+// in the registry, deduplicated and sorted for a stable pod spec.
+func mcpCredentialSecretNames(raw string) []string {
+	var servers []projectMCPServer
+	if err := json.Unmarshal([]byte(raw), &servers); err != nil {
+		log.Printf("Ignoring invalid MCP server registry: %v", err)
+		return nil
+	}
+	seen := map[string]bool{}
+	names := []string{}
+	for _, s := range servers {
+		if !s.Enabled || s.CredentialsSecretRef == "" || seen[s.CredentialsSecretRef] {
+			continue
+		}
+		seen[s.CredentialsSecretRef] = true
+		names = append(names, s.CredentialsSecretRef)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// projectMCPCredentialSecrets reads the project MCP registry and returns the credential
+// secrets to inject into the runner. A missing registry is not an error.
+func projectMCPCredentialSecrets(namespace string) []string {
+	cm, err := config.K8sClient.CoreV1().ConfigMaps(namespace).Get(context.TODO(), types.ProjectMCPServersConfigMap, v1.GetOptions{})
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			log.Printf("Error reading MCP server registry in %s: %v", namespace, err)
+		}
+		return nil
+	}
+	return mcpCredentialSecretNames(cm.Data[types.ProjectMCPServersKey])
+}
+
+// projectMCPEnvFrom injects each credential secret as optional env so a deleted
+// secret degrades the server rather than blocking the pod.
+func projectMCPEnvFrom(secretNames []string) []corev1.EnvFromSource {
+	sources := make([]corev1.EnvFromSource, 0, len(secretNames))
+	for _, name := range secretNames {
+		sources = append(sources, corev1.EnvFromSource{
+			SecretRef: &corev1.SecretEnvSource{
+				LocalObjectReference: corev1.LocalObjectReference{Name: name},
+				Optional:             boolPtr(true),
+			},
+		})
+	}
+	return sources
+}
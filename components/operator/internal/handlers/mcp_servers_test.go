@@ -0,0 +1,26 @@
+package handlers
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestMCPCredentialSecretNames verifies only enabled servers' secrets are injected, once each
+func TestMCPCredentialSecretNames(t *testing.T) {
+	raw := `[
+		{"name": "b", "transport": "http", "credentialsSecretRef": "shared", "enabled": true},
+		{"name": "a", "transport": "stdio", "credentialsSecretRef": "github-mcp", "enabled": true},
+		{"name": "c", "transport": "http", "credentialsSecretRef": "shared", "enabled": true},
+		{"name": "d", "transport": "http", "credentialsSecretRef": "disabled-only", "enabled": false},
+		{"name": "e", "transport": "http", "enabled": true}
+	]`
+	got := mcpCredentialSecretNames(raw)
+	want := []string{"github-mcp", "shared"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v, got %v", want, got)
+	}
+
+	if got := mcpCredentialSecretNames("not json"); len(got) != 0 {
+		t.Errorf("expected no secrets for invalid registry, got %v", got)
+	}
+}
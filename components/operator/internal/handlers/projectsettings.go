@@ -141,6 +141,16 @@ func reconcileProjectSettings(obj *unstructured.Unstructured) error {
 		}
 	}
 
+	// Shared project workspace (read-only in runner pods)
+	if err := reconcileSharedWorkspace(context.TODO(), config.K8sClient, config.LoadConfig(), obj); err != nil {
+		log.Printf("Error reconciling shared workspace in namespace %s: %v", namespace, err)
+	}
+
+	// Idle runner pods sessions claim for instant startup
+	if err := reconcileWarmPool(context.TODO(), config.K8sClient, config.LoadConfig(), obj); err != nil {
+		log.Printf("Error reconciling warm pool in namespace %s: %v", namespace, err)
+	}
+
 	// Update status with reconciliation results (only fields defined in CRD)
 	statusUpdate := map[string]interface{}{
 		"groupBindingsCreated": groupBindingsCreated,
@@ -245,3 +255,18 @@ func updateProjectSettingsStatus(namespace, name string, statusUpdate map[string
 
 	return nil
 }
+
+// getProjectSettingsSpec returns the spec of the namespace's ProjectSettings singleton.
+// A missing ProjectSettings yields a nil spec and no error.
+func getProjectSettingsSpec(namespace string) (map[string]interface{}, error) {
+	gvr := types.GetProjectSettingsResource()
+	obj, err := config.DynamicClient.Resource(gvr).Namespace(namespace).Get(context.TODO(), "projectsettings", v1.GetOptions{})
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get ProjectSettings in %s: %w", namespace, err)
+	}
+	spec, _, _ := unstructured.NestedMap(obj.Object, "spec")
+	return spec, nil
+}
@@ -43,8 +43,20 @@ func ResetToPending(ctx context.Context, session *unstructured.Unstructured) err
 		Reason:  "PodMissing",
 		Message: "Pod not found, will recreate",
 	})
+	if session.GetAnnotations()[resumableAnnotation] == "true" {
+		statusPatch.AddCondition(conditionUpdate{
+			Type:    conditionResumable,
+			Status:  "False",
+			Reason:  "Resumed",
+			Message: "Session restarted after disruption",
+		})
+	}
 
-	return statusPatch.Apply()
+	if err := statusPatch.Apply(); err != nil {
+		return err
+	}
+	_ = clearAnnotation(namespace, name, resumableAnnotation)
+	return nil
 }
 
 // TransitionToStopped transitions a session to Stopped phase.
@@ -73,11 +85,25 @@ func TransitionToStopped(ctx context.Context, session *unstructured.Unstructured
 		Reason:  "UserStopped",
 		Message: "Runner stopped by user",
 	})
+	statusPatch.AddCondition(conditionUpdate{
+		Type:    conditionRunnerReady,
+		Status:  "False",
+		Reason:  "UserStopped",
+		Message: "Runner stopped by user",
+	})
+	statusPatch.AddCondition(conditionUpdate{
+		Type:    conditionRunActive,
+		Status:  "False",
+		Reason:  "UserStopped",
+		Message: "Session stopped by user",
+	})
 
 	if err := statusPatch.Apply(); err != nil {
 		return err
 	}
 
+	recordSessionEvent(session, corev1.EventTypeNormal, eventReasonSessionStopped, "Session stopped by user")
+
 	// Clear annotations
 	_ = clearAnnotation(namespace, name, "ambient-code.io/desired-phase")
 	_ = clearAnnotation(namespace, name, "ambient-code.io/stop-requested-at")
@@ -105,10 +131,17 @@ func TransitionToFailed(ctx context.Context, session *unstructured.Unstructured,
 		Reason:  "PodFailed",
 		Message: errorMsg,
 	})
+	statusPatch.AddCondition(conditionUpdate{
+		Type:    conditionRunActive,
+		Status:  "False",
+		Reason:  "PodFailed",
+		Message: errorMsg,
+	})
 
 	if err := statusPatch.Apply(); err != nil {
 		return err
 	}
+	recordSessionWarning(session, eventReasonSessionFailed, "%s", errorMsg)
 
 	_ = ensureSessionIsInteractive(namespace, name)
 
@@ -119,7 +152,7 @@ func TransitionToFailed(ctx context.Context, session *unstructured.Unstructured,
 func InitiateStop(ctx context.Context, session *unstructured.Unstructured) error {
 	namespace := session.GetNamespace()
 	name := session.GetName()
-	podName := fmt.Sprintf("%s-runner", name)
+	podName := RunnerPodName(session)
 
 	log.Printf("[Stop] Initiating stop for session %s/%s", namespace, name)
 
@@ -204,6 +237,7 @@ func UpdateSessionFromPodStatus(ctx context.Context, session *unstructured.Unstr
 			Message: fmt.Sprintf("Scheduled on %s", pod.Spec.NodeName),
 		})
 	}
+	addPodLifecycleConditions(statusPatch, pod)
 
 	switch pod.Status.Phase {
 	case corev1.PodSucceeded:
@@ -218,6 +252,7 @@ func UpdateSessionFromPodStatus(ctx context.Context, session *unstructured.Unstr
 		if err := statusPatch.Apply(); err != nil {
 			return err
 		}
+		recordSessionEvent(session, corev1.EventTypeNormal, eventReasonSessionCompleted, "Runner pod %s succeeded", podName)
 		_ = ensureSessionIsInteractive(namespace, name)
 		return DeletePodAndServices(ctx, namespace, podName, name)
 
@@ -235,6 +270,7 @@ func UpdateSessionFromPodStatus(ctx context.Context, session *unstructured.Unstr
 		if err := statusPatch.Apply(); err != nil {
 			return err
 		}
+		recordSessionWarning(session, eventReasonSessionFailed, "Runner pod %s failed: %s", podName, errorMsg)
 		_ = ensureSessionIsInteractive(namespace, name)
 		return DeletePodAndServices(ctx, namespace, podName, name)
 	}
@@ -284,6 +320,7 @@ func UpdateSessionFromPodStatus(ctx context.Context, session *unstructured.Unstr
 			if err := statusPatch.Apply(); err != nil {
 				return err
 			}
+			recordSessionWarning(session, eventReasonSessionFailed, "%s", msg)
 			_ = ensureSessionIsInteractive(namespace, name)
 			return DeletePodAndServices(ctx, namespace, podName, name)
 		}
@@ -336,6 +373,86 @@ func UpdateSessionFromPodStatus(ctx context.Context, session *unstructured.Unstr
 	return statusPatch.Apply()
 }
 
+// addPodLifecycleConditions derives WorkspaceReady and RunnerReady from the runner
+// pod's container statuses, and clears RunActive once the runner exits. Whether a run
+// is in progress is tracked by the backend, not inferred from the container running.
+// Phase transitions are left to the caller.
+func addPodLifecycleConditions(statusPatch *StatusPatch, pod *corev1.Pod) {
+	for _, cs := range pod.Status.InitContainerStatuses {
+		if cs.Name != "init-hydrate" {
+			continue
+		}
+		switch {
+		case cs.State.Terminated != nil && cs.State.Terminated.ExitCode == 0:
+			statusPatch.AddCondition(conditionUpdate{
+				Type:    conditionWorkspaceReady,
+				Status:  "True",
+				Reason:  "Hydrated",
+				Message: "Workspace state restored",
+			})
+		case cs.State.Terminated != nil:
+			statusPatch.AddCondition(conditionUpdate{
+				Type:    conditionWorkspaceReady,
+				Status:  "False",
+				Reason:  "HydrationFailed",
+				Message: fmt.Sprintf("init-hydrate exited with code %d: %s", cs.State.Terminated.ExitCode, cs.State.Terminated.Reason),
+			})
+		default:
+			statusPatch.AddCondition(conditionUpdate{
+				Type:    conditionWorkspaceReady,
+				Status:  "False",
+				Reason:  "Hydrating",
+				Message: "Restoring workspace state",
+			})
+		}
+		break
+	}
+
+	runner := getContainerStatusByName(pod, "ambient-code-runner")
+	if runner == nil {
+		return
+	}
+	switch {
+	case runner.State.Running != nil:
+		if runner.Ready {
+			statusPatch.AddCondition(conditionUpdate{
+				Type:    conditionRunnerReady,
+				Status:  "True",
+				Reason:  "ContainerReady",
+				Message: "Runner is accepting requests",
+			})
+		} else {
+			statusPatch.AddCondition(conditionUpdate{
+				Type:    conditionRunnerReady,
+				Status:  "False",
+				Reason:  "ContainerNotReady",
+				Message: "Runner started but is not ready yet",
+			})
+		}
+	case runner.State.Waiting != nil:
+		statusPatch.AddCondition(conditionUpdate{
+			Type:    conditionRunnerReady,
+			Status:  "False",
+			Reason:  runner.State.Waiting.Reason,
+			Message: runner.State.Waiting.Message,
+		})
+	case runner.State.Terminated != nil:
+		msg := fmt.Sprintf("Runner exited with code %d", runner.State.Terminated.ExitCode)
+		statusPatch.AddCondition(conditionUpdate{
+			Type:    conditionRunnerReady,
+			Status:  "False",
+			Reason:  "Terminated",
+			Message: msg,
+		})
+		statusPatch.AddCondition(conditionUpdate{
+			Type:    conditionRunActive,
+			Status:  "False",
+			Reason:  "Terminated",
+			Message: msg,
+		})
+	}
+}
+
 // DeletePodAndServices deletes the pod and associated services.
 func DeletePodAndServices(ctx context.Context, namespace, podName, sessionName string) error {
 	return deletePodAndPerPodService(namespace, podName, sessionName)
@@ -0,0 +1,556 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"ambient-code-operator/internal/config"
+	"ambient-code-operator/internal/types"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// Runner clusters are registered as Secrets in the backend namespace (see the backend's
+// handlers/runner_clusters.go). Sessions are placed on one of them, or on the local
+// cluster, and the placement is recorded on the session.
+// IMPORTANT: Keep in sync with backend (handlers/runner_clusters.go)
+const (
+	// runnerClusterLabel carries the cluster name on registration Secrets and, as an
+	// annotation, the placement on sessions
+	runnerClusterLabel = "ambient-code.io/runner-cluster"
+
+	runnerClusterKubeconfigKey      = "kubeconfig"
+	runnerClusterMaxSessionsKey     = "ambient-code.io/max-sessions"
+	runnerClusterBackendURLKey      = "ambient-code.io/backend-url"
+	runnerClusterRunnerURLKey       = "ambient-code.io/runner-url"
+	runnerClusterDefaultMaxSessions = 20
+
+	// localRunnerCluster is the cluster the operator runs in
+	localRunnerCluster = "local"
+
+	// mirroredLabel marks Secrets and ConfigMaps copied to a runner cluster. Sessions in
+	// a namespace share them, so they are removed once no runner there is left.
+	mirroredLabel = "ambient-code.io/mirrored"
+)
+
+// runnerCluster is a cluster runner pods can be placed on
+type runnerCluster struct {
+	name   string
+	client kubernetes.Interface
+	// maxSessions is the number of active sessions placed here before it counts as
+	// full; 0 means unbounded
+	maxSessions int
+	// backendURL is the backend API as reachable from this cluster's runners
+	backendURL string
+	// runnerURL is the template for a runner's AG-UI endpoint, with {project} and {session}
+	runnerURL string
+}
+
+func (c *runnerCluster) remote() bool {
+	return c.name != localRunnerCluster
+}
+
+// runnerEndpoint returns the base URL of the session's runner, ending in "/"
+func (c *runnerCluster) runnerEndpoint(namespace, sessionName string) string {
+	if !c.remote() || c.runnerURL == "" {
+		return fmt.Sprintf("http://session-%s.%s.svc.cluster.local:8001/", sessionName, namespace)
+	}
+	u := strings.NewReplacer("{project}", namespace, "{session}", sessionName).Replace(c.runnerURL)
+	return strings.TrimSuffix(u, "/") + "/"
+}
+
+type cachedRunnerCluster struct {
+	resourceVersion string
+	cluster         *runnerCluster
+}
+
+var (
+	runnerClustersMu sync.Mutex
+	// runnerClusterCache keeps clients for registration Secrets that haven't changed
+	runnerClusterCache = map[string]cachedRunnerCluster{}
+	// placementMu serializes placements so concurrent sessions see each other's load
+	placementMu sync.Mutex
+)
+
+func localCluster() *runnerCluster {
+	maxSessions, _ := strconv.Atoi(os.Getenv("RUNNER_CLUSTER_LOCAL_MAX_SESSIONS"))
+	return &runnerCluster{name: localRunnerCluster, client: config.K8sClient, maxSessions: maxSessions}
+}
+
+// runnerClusters returns the local cluster followed by the registered ones, by name
+func runnerClusters(ctx context.Context) ([]*runnerCluster, error) {
+	secrets, err := config.K8sClient.CoreV1().Secrets(config.LoadConfig().BackendNamespace).List(ctx, v1.ListOptions{
+		LabelSelector: runnerClusterLabel,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list runner clusters: %w", err)
+	}
+
+	runnerClustersMu.Lock()
+	defer runnerClustersMu.Unlock()
+	seen := map[string]bool{}
+	clusters := []*runnerCluster{}
+	for i := range secrets.Items {
+		s := &secrets.Items[i]
+		name := s.Labels[runnerClusterLabel]
+		if name == "" || name == localRunnerCluster {
+			continue
+		}
+		seen[name] = true
+		if cached, ok := runnerClusterCache[name]; ok && cached.resourceVersion == s.ResourceVersion {
+			clusters = append(clusters, cached.cluster)
+			continue
+		}
+		c, err := runnerClusterFromSecret(name, s)
+		if err != nil {
+			log.Printf("Skipping runner cluster %s: %v", name, err)
+			continue
+		}
+		runnerClusterCache[name] = cachedRunnerCluster{resourceVersion: s.ResourceVersion, cluster: c}
+		clusters = append(clusters, c)
+	}
+	for name := range runnerClusterCache {
+		if !seen[name] {
+			delete(runnerClusterCache, name)
+		}
+	}
+	sort.Slice(clusters, func(a, b int) bool { return clusters[a].name < clusters[b].name })
+	return append([]*runnerCluster{localCluster()}, clusters...), nil
+}
+
+func runnerClusterFromSecret(name string, s *corev1.Secret) (*runnerCluster, error) {
+	restConfig, err := clientcmd.RESTConfigFromKubeConfig(s.Data[runnerClusterKubeconfigKey])
+	if err != nil {
+		return nil, fmt.Errorf("invalid kubeconfig: %w", err)
+	}
+	restConfig.QPS, restConfig.Burst = 50, 100
+	client, err := kubernetes.NewForConfig(restConfig)
+	if err != nil {
+		return nil, err
+	}
+	maxSessions := runnerClusterDefaultMaxSessions
+	if v := s.Annotations[runnerClusterMaxSessionsKey]; v != "" {
+		if maxSessions, err = strconv.Atoi(v); err != nil || maxSessions < 0 {
+			return nil, fmt.Errorf("invalid %s %q", runnerClusterMaxSessionsKey, v)
+		}
+	}
+	backendURL := s.Annotations[runnerClusterBackendURLKey]
+	if backendURL == "" {
+		return nil, fmt.Errorf("%s is required", runnerClusterBackendURLKey)
+	}
+	return &runnerCluster{
+		name:        name,
+		client:      client,
+		maxSessions: maxSessions,
+		backendURL:  backendURL,
+		runnerURL:   s.Annotations[runnerClusterRunnerURLKey],
+	}, nil
+}
+
+// sessionRunnerCluster returns the cluster the session is placed on, the local cluster
+// when it hasn't been placed
+func sessionRunnerCluster(ctx context.Context, session *unstructured.Unstructured) (*runnerCluster, error) {
+	name := session.GetAnnotations()[runnerClusterLabel]
+	if name == "" || name == localRunnerCluster {
+		return localCluster(), nil
+	}
+	clusters, err := runnerClusters(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range clusters {
+		if c.name == name {
+			return c, nil
+		}
+	}
+	return nil, fmt.Errorf("session %s/%s is placed on unknown runner cluster %q", session.GetNamespace(), session.GetName(), name)
+}
+
+// runnerClusterFor is sessionRunnerCluster for a session known by name. A deleted
+// session resolves to the local cluster.
+func runnerClusterFor(ctx context.Context, namespace, sessionName string) (*runnerCluster, error) {
+	obj, err := config.DynamicClient.Resource(types.GetAgenticSessionResource()).Namespace(namespace).Get(ctx, sessionName, v1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return localCluster(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return sessionRunnerCluster(ctx, obj)
+}
+
+// placeSession returns the session's runner cluster, choosing one if it has none yet
+// or its cluster was unregistered. The choice is the least loaded cluster by active
+// sessions relative to its capacity, preferring clusters that aren't full, and is
+// recorded in the session's runner-cluster annotation.
+func placeSession(ctx context.Context, session *unstructured.Unstructured) (*runnerCluster, error) {
+	placementMu.Lock()
+	defer placementMu.Unlock()
+
+	clusters, err := runnerClusters(ctx)
+	if err != nil {
+		return nil, err
+	}
+	placed := session.GetAnnotations()[runnerClusterLabel]
+	if placed == "" && len(clusters) == 1 {
+		return clusters[0], nil
+	}
+	for _, c := range clusters {
+		if c.name == placed {
+			return c, nil
+		}
+	}
+	if placed != "" {
+		log.Printf("Session %s/%s: runner cluster %q is no longer registered, placing again", session.GetNamespace(), session.GetName(), placed)
+	}
+
+	load, err := activeSessionsByCluster(ctx)
+	if err != nil {
+		return nil, err
+	}
+	chosen := leastLoadedCluster(clusters, load)
+
+	annotations := map[string]string{}
+	for k, v := range session.GetAnnotations() {
+		annotations[k] = v
+	}
+	annotations[runnerClusterLabel] = chosen.name
+	if err := updateAnnotations(session.GetNamespace(), session.GetName(), annotations); err != nil {
+		return nil, err
+	}
+	session.SetAnnotations(annotations)
+	log.Printf("Session %s/%s placed on runner cluster %s (%d active)", session.GetNamespace(), session.GetName(), chosen.name, load[chosen.name])
+	recordSessionEvent(session, corev1.EventTypeNormal, eventReasonRunnerClusterPlaced, "Placed on runner cluster %s", chosen.name)
+	return chosen, nil
+}
+
+// leastLoadedCluster picks the cluster with the lowest share of its capacity in use,
+// skipping full clusters unless all are full. Unbounded clusters count as empty; ties
+// go to the earlier cluster, so the local cluster wins them.
+func leastLoadedCluster(clusters []*runnerCluster, load map[string]int) *runnerCluster {
+	utilization := func(c *runnerCluster) float64 {
+		if c.maxSessions <= 0 {
+			return 0
+		}
+		return float64(load[c.name]) / float64(c.maxSessions)
+	}
+	var best *runnerCluster
+	for _, c := range clusters {
+		full := c.maxSessions > 0 && load[c.name] >= c.maxSessions
+		if full {
+			continue
+		}
+		if best == nil || utilization(c) < utilization(best) {
+			best = c
+		}
+	}
+	if best != nil {
+		return best
+	}
+	best = clusters[0]
+	for _, c := range clusters[1:] {
+		if utilization(c) < utilization(best) {
+			best = c
+		}
+	}
+	return best
+}
+
+// activeSessionsByCluster counts Pending, Creating and Running sessions per runner cluster
+func activeSessionsByCluster(ctx context.Context) (map[string]int, error) {
+	list, err := config.DynamicClient.Resource(types.GetAgenticSessionResource()).Namespace("").List(ctx, v1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list sessions: %w", err)
+	}
+	load := map[string]int{}
+	for _, item := range list.Items {
+		phase, _, _ := unstructured.NestedString(item.Object, "status", "phase")
+		if phase != "Pending" && phase != "Creating" && phase != "Running" {
+			continue
+		}
+		name := item.GetAnnotations()[runnerClusterLabel]
+		if name == "" {
+			name = localRunnerCluster
+		}
+		load[name]++
+	}
+	return load, nil
+}
+
+// prepareRemoteRunnerPod adapts a runner pod built for the local cluster to a remote
+// one: the session's owner reference can't cross clusters, and runners reach the
+// backend through the cluster's backend URL. It then creates the namespace and copies
+// the Secrets and ConfigMaps the pod references.
+func prepareRemoteRunnerPod(ctx context.Context, c *runnerCluster, pod *corev1.Pod) error {
+	pod.OwnerReferences = nil
+	for _, containers := range [][]corev1.Container{pod.Spec.InitContainers, pod.Spec.Containers} {
+		for i := range containers {
+			for j := range containers[i].Env {
+				if containers[i].Env[j].Name == "BACKEND_API_URL" {
+					containers[i].Env[j].Value = c.backendURL
+				}
+			}
+		}
+	}
+
+	ns := &corev1.Namespace{ObjectMeta: v1.ObjectMeta{
+		Name:   pod.Namespace,
+		Labels: map[string]string{"app": "ambient-code", runnerClusterLabel: c.name},
+	}}
+	if _, err := c.client.CoreV1().Namespaces().Create(ctx, ns, v1.CreateOptions{}); err != nil && !errors.IsAlreadyExists(err) {
+		return fmt.Errorf("failed to create namespace %s on runner cluster %s: %w", pod.Namespace, c.name, err)
+	}
+
+	secrets, configMaps := podReferences(pod)
+	for _, name := range secrets {
+		src, err := config.K8sClient.CoreV1().Secrets(pod.Namespace).Get(ctx, name, v1.GetOptions{})
+		if errors.IsNotFound(err) {
+			continue // optional references
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read secret %s: %w", name, err)
+		}
+		dst := &corev1.Secret{
+			ObjectMeta: mirroredObjectMeta(src.ObjectMeta),
+			Type:       src.Type,
+			Data:       src.Data,
+		}
+		if err := applyMirrored(func() error {
+			_, err := c.client.CoreV1().Secrets(pod.Namespace).Create(ctx, dst, v1.CreateOptions{})
+			return err
+		}, func() error {
+			_, err := c.client.CoreV1().Secrets(pod.Namespace).Update(ctx, dst, v1.UpdateOptions{})
+			return err
+		}); err != nil {
+			return fmt.Errorf("failed to copy secret %s to runner cluster %s: %w", name, c.name, err)
+		}
+	}
+	for _, name := range configMaps {
+		src, err := config.K8sClient.CoreV1().ConfigMaps(pod.Namespace).Get(ctx, name, v1.GetOptions{})
+		if errors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read configmap %s: %w", name, err)
+		}
+		dst := &corev1.ConfigMap{
+			ObjectMeta: mirroredObjectMeta(src.ObjectMeta),
+			Data:       src.Data,
+			BinaryData: src.BinaryData,
+		}
+		if err := applyMirrored(func() error {
+			_, err := c.client.CoreV1().ConfigMaps(pod.Namespace).Create(ctx, dst, v1.CreateOptions{})
+			return err
+		}, func() error {
+			_, err := c.client.CoreV1().ConfigMaps(pod.Namespace).Update(ctx, dst, v1.UpdateOptions{})
+			return err
+		}); err != nil {
+			return fmt.Errorf("failed to copy configmap %s to runner cluster %s: %w", name, c.name, err)
+		}
+	}
+	return nil
+}
+
+// applyMirrored creates an object, replacing it when it already exists
+func applyMirrored(create, update func() error) error {
+	err := create()
+	if errors.IsAlreadyExists(err) {
+		err = update()
+	}
+	return err
+}
+
+func mirroredObjectMeta(src v1.ObjectMeta) v1.ObjectMeta {
+	labels := map[string]string{}
+	for k, v := range src.Labels {
+		labels[k] = v
+	}
+	labels[mirroredLabel] = "true"
+	return v1.ObjectMeta{Name: src.Name, Namespace: src.Namespace, Labels: labels}
+}
+
+// podReferences returns the names of the Secrets and ConfigMaps pod uses
+func podReferences(pod *corev1.Pod) (secrets, configMaps []string) {
+	seenSecret, seenConfigMap := map[string]bool{}, map[string]bool{}
+	addSecret := func(name string) {
+		if name != "" && !seenSecret[name] {
+			seenSecret[name] = true
+			secrets = append(secrets, name)
+		}
+	}
+	addConfigMap := func(name string) {
+		if name != "" && !seenConfigMap[name] {
+			seenConfigMap[name] = true
+			configMaps = append(configMaps, name)
+		}
+	}
+	for _, containers := range [][]corev1.Container{pod.Spec.InitContainers, pod.Spec.Containers} {
+		for _, ctr := range containers {
+			for _, e := range ctr.Env {
+				if e.ValueFrom != nil && e.ValueFrom.SecretKeyRef != nil {
+					addSecret(e.ValueFrom.SecretKeyRef.Name)
+				}
+				if e.ValueFrom != nil && e.ValueFrom.ConfigMapKeyRef != nil {
+					addConfigMap(e.ValueFrom.ConfigMapKeyRef.Name)
+				}
+			}
+			for _, e := range ctr.EnvFrom {
+				if e.SecretRef != nil {
+					addSecret(e.SecretRef.Name)
+				}
+				if e.ConfigMapRef != nil {
+					addConfigMap(e.ConfigMapRef.Name)
+				}
+			}
+		}
+	}
+	for _, vol := range pod.Spec.Volumes {
+		if vol.Secret != nil {
+			addSecret(vol.Secret.SecretName)
+		}
+		if vol.ConfigMap != nil {
+			addConfigMap(vol.ConfigMap.Name)
+		}
+		if vol.Projected != nil {
+			for _, src := range vol.Projected.Sources {
+				if src.Secret != nil {
+					addSecret(src.Secret.Name)
+				}
+				if src.ConfigMap != nil {
+					addConfigMap(src.ConfigMap.Name)
+				}
+			}
+		}
+	}
+	return secrets, configMaps
+}
+
+// GetRunnerPod returns the session's runner pod from the cluster it is placed on
+func GetRunnerPod(ctx context.Context, session *unstructured.Unstructured) (*corev1.Pod, error) {
+	c, err := sessionRunnerCluster(ctx, session)
+	if err != nil {
+		return nil, err
+	}
+	return c.client.CoreV1().Pods(session.GetNamespace()).Get(ctx, RunnerPodName(session), v1.GetOptions{})
+}
+
+// GetRunnerNode returns the node the session's runner pod runs on
+func GetRunnerNode(ctx context.Context, session *unstructured.Unstructured, nodeName string) (*corev1.Node, error) {
+	c, err := sessionRunnerCluster(ctx, session)
+	if err != nil {
+		return nil, err
+	}
+	return c.client.CoreV1().Nodes().Get(ctx, nodeName, v1.GetOptions{})
+}
+
+// WatchRunnerClusters periodically removes runner pods and Services left on remote
+// runner clusters by sessions that were deleted or placed elsewhere, and the copied
+// objects of namespaces without runners.
+// Owner references can't cross clusters, so this replaces garbage collection there.
+func WatchRunnerClusters() {
+	ticker := time.NewTicker(5 * time.Minute)
+	defer ticker.Stop()
+	for range ticker.C {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+		sweepRunnerClusters(ctx)
+		cancel()
+	}
+}
+
+func sweepRunnerClusters(ctx context.Context) {
+	clusters, err := runnerClusters(ctx)
+	if err != nil {
+		log.Printf("Runner cluster sweep: %v", err)
+		return
+	}
+	for _, c := range clusters {
+		if !c.remote() {
+			continue
+		}
+		pods, err := c.client.CoreV1().Pods("").List(ctx, v1.ListOptions{LabelSelector: "app=ambient-code-runner"})
+		if err != nil {
+			log.Printf("Runner cluster sweep: failed to list pods on %s: %v", c.name, err)
+			continue
+		}
+		live := map[string]bool{}
+		for _, pod := range pods.Items {
+			sessionName := pod.Labels["agentic-session"]
+			if sessionName == "" || sessionPlacedOn(ctx, pod.Namespace, sessionName, c.name) {
+				live[pod.Namespace] = true
+				continue
+			}
+			log.Printf("Runner cluster sweep: removing orphaned runner %s/%s from %s", pod.Namespace, pod.Name, c.name)
+			deleteRemoteRunner(ctx, c, pod.Namespace, pod.Name, sessionName)
+		}
+		deleteUnusedMirrors(ctx, c, live)
+	}
+}
+
+// sessionPlacedOn reports whether the session exists and is placed on cluster. Lookup
+// errors count as placed, so a flaky API server never removes live runners.
+func sessionPlacedOn(ctx context.Context, namespace, sessionName, cluster string) bool {
+	obj, err := config.DynamicClient.Resource(types.GetAgenticSessionResource()).Namespace(namespace).Get(ctx, sessionName, v1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return false
+	}
+	if err != nil {
+		return true
+	}
+	return obj.GetAnnotations()[runnerClusterLabel] == cluster
+}
+
+// deleteRemoteRunner deletes a runner pod and its Services
+func deleteRemoteRunner(ctx context.Context, c *runnerCluster, namespace, podName, sessionName string) {
+	core := c.client.CoreV1()
+	for _, svc := range []string{fmt.Sprintf("ambient-content-%s", sessionName), fmt.Sprintf("session-%s", sessionName)} {
+		if err := core.Services(namespace).Delete(ctx, svc, v1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+			log.Printf("Failed to delete service %s/%s on %s: %v", namespace, svc, c.name, err)
+		}
+	}
+	policy := v1.DeletePropagationBackground
+	if err := core.Pods(namespace).Delete(ctx, podName, v1.DeleteOptions{PropagationPolicy: &policy}); err != nil && !errors.IsNotFound(err) {
+		log.Printf("Failed to delete pod %s/%s on %s: %v", namespace, podName, c.name, err)
+	}
+}
+
+// deleteUnusedMirrors deletes the copied Secrets and ConfigMaps of namespaces on c
+// that have no live runner
+func deleteUnusedMirrors(ctx context.Context, c *runnerCluster, live map[string]bool) {
+	selector := v1.ListOptions{LabelSelector: mirroredLabel}
+	secrets, err := c.client.CoreV1().Secrets("").List(ctx, selector)
+	if err != nil {
+		log.Printf("Runner cluster sweep: failed to list copied secrets on %s: %v", c.name, err)
+		return
+	}
+	for _, s := range secrets.Items {
+		if !live[s.Namespace] {
+			if err := c.client.CoreV1().Secrets(s.Namespace).Delete(ctx, s.Name, v1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+				log.Printf("Failed to delete copied secret %s/%s on %s: %v", s.Namespace, s.Name, c.name, err)
+			}
+		}
+	}
+	configMaps, err := c.client.CoreV1().ConfigMaps("").List(ctx, selector)
+	if err != nil {
+		log.Printf("Runner cluster sweep: failed to list copied configmaps on %s: %v", c.name, err)
+		return
+	}
+	for _, cm := range configMaps.Items {
+		if !live[cm.Namespace] {
+			if err := c.client.CoreV1().ConfigMaps(cm.Namespace).Delete(ctx, cm.Name, v1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+				log.Printf("Failed to delete copied configmap %s/%s on %s: %v", cm.Namespace, cm.Name, c.name, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package handlers
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// TestLeastLoadedCluster_PrefersSpareCapacity verifies placement by share of capacity in use
+func TestLeastLoadedCluster_PrefersSpareCapacity(t *testing.T) {
+	clusters := []*runnerCluster{
+		{name: localRunnerCluster, maxSessions: 10},
+		{name: "east", maxSessions: 20},
+		{name: "west", maxSessions: 5},
+	}
+
+	got := leastLoadedCluster(clusters, map[string]int{localRunnerCluster: 5, "east": 4, "west": 1})
+	if got.name != "east" {
+		t.Errorf("expected east (20%% used), got %s", got.name)
+	}
+
+	// Full clusters are skipped even when another cluster is busier by share
+	got = leastLoadedCluster(clusters, map[string]int{localRunnerCluster: 10, "east": 19, "west": 5})
+	if got.name != "east" {
+		t.Errorf("expected the only cluster with room, got %s", got.name)
+	}
+}
+
+// TestLeastLoadedCluster_AllFull verifies the least overloaded cluster is used when none has room
+func TestLeastLoadedCluster_AllFull(t *testing.T) {
+	clusters := []*runnerCluster{
+		{name: localRunnerCluster, maxSessions: 2},
+		{name: "east", maxSessions: 4},
+	}
+	got := leastLoadedCluster(clusters, map[string]int{localRunnerCluster: 4, "east": 4})
+	if got.name != "east" {
+		t.Errorf("expected east, got %s", got.name)
+	}
+}
+
+// TestLeastLoadedCluster_UnboundedLocal verifies an unbounded local cluster keeps sessions local
+func TestLeastLoadedCluster_UnboundedLocal(t *testing.T) {
+	clusters := []*runnerCluster{
+		{name: localRunnerCluster},
+		{name: "east", maxSessions: 20},
+	}
+	got := leastLoadedCluster(clusters, map[string]int{localRunnerCluster: 100})
+	if got.name != localRunnerCluster {
+		t.Errorf("expected local, got %s", got.name)
+	}
+}
+
+// TestPodReferences verifies every Secret and ConfigMap a runner pod uses is found once
+func TestPodReferences(t *testing.T) {
+	pod := &corev1.Pod{Spec: corev1.PodSpec{
+		Containers: []corev1.Container{{
+			Env: []corev1.EnvVar{
+				{Name: "BOT_TOKEN", ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "ambient-runner-token-s1"}}}},
+				{Name: "PLAIN", Value: "x"},
+			},
+			EnvFrom: []corev1.EnvFromSource{
+				{SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "ambient-runner-secrets"}}},
+				{SecretRef: &corev1.SecretEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "ambient-runner-token-s1"}}},
+			},
+		}},
+		Volumes: []corev1.Volume{
+			{Name: "mcp", VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: "ambient-mcp-servers"}}}},
+			{Name: "vertex", VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "ambient-vertex"}}},
+			{Name: "workspace", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}},
+		},
+	}}
+
+	secrets, configMaps := podReferences(pod)
+	if want := []string{"ambient-runner-token-s1", "ambient-runner-secrets", "ambient-vertex"}; !reflect.DeepEqual(secrets, want) {
+		t.Errorf("secrets = %v, want %v", secrets, want)
+	}
+	if want := []string{"ambient-mcp-servers"}; !reflect.DeepEqual(configMaps, want) {
+		t.Errorf("configMaps = %v, want %v", configMaps, want)
+	}
+}
+
+// TestRunnerEndpoint verifies remote runners are reached through the cluster's URL template
+func TestRunnerEndpoint(t *testing.T) {
+	local := &runnerCluster{name: localRunnerCluster}
+	if got := local.runnerEndpoint("team-a", "s1"); got != "http://session-s1.team-a.svc.cluster.local:8001/" {
+		t.Errorf("local endpoint = %s", got)
+	}
+	remote := &runnerCluster{name: "east", runnerURL: "https://runners.east.example.com/{project}/{session}"}
+	if got := remote.runnerEndpoint("team-a", "s1"); got != "https://runners.east.example.com/team-a/s1/" {
+		t.Errorf("remote endpoint = %s", got)
+	}
+}
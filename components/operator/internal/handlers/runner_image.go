@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// isRunnerImageAllowed reports whether image matches any entry in allowlist.
+// IMPORTANT: Keep matching rules in sync with backend (handlers/runner_image.go)
+func isRunnerImageAllowed(image string, allowlist []string) bool {
+	image = strings.TrimSpace(image)
+	if image == "" || strings.ContainsAny(image, " \t\n") {
+		return false
+	}
+	for _, entry := range allowlist {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if strings.HasSuffix(entry, "*") {
+			if strings.HasPrefix(image, strings.TrimSuffix(entry, "*")) {
+				return true
+			}
+			continue
+		}
+		if strings.HasSuffix(entry, "/") {
+			if strings.HasPrefix(image, entry) {
+				return true
+			}
+			continue
+		}
+		if image == entry {
+			return true
+		}
+		if !imageHasTagOrDigest(entry) &&
+			(strings.HasPrefix(image, entry+":") || strings.HasPrefix(image, entry+"@")) {
+			return true
+		}
+	}
+	return false
+}
+
+// imageHasTagOrDigest reports whether ref pins a tag or digest.
+// A colon before the last slash is a registry port, not a tag.
+func imageHasTagOrDigest(ref string) bool {
+	if strings.Contains(ref, "@") {
+		return true
+	}
+	return strings.Contains(ref[strings.LastIndex(ref, "/")+1:], ":")
+}
+
+// resolveRunnerImage returns the image for the runner container. Sessions without
+// spec.runnerImage use the operator default; overrides are re-checked against the
+// ProjectSettings allowlist so CRs created outside the backend are held to the same rules.
+func resolveRunnerImage(spec, projectSettings map[string]interface{}, defaultImage string) (string, error) {
+	requested, _, _ := unstructured.NestedString(spec, "runnerImage")
+	requested = strings.TrimSpace(requested)
+	if requested == "" {
+		return defaultImage, nil
+	}
+
+	allowlist, _, _ := unstructured.NestedStringSlice(projectSettings, "runnerImageAllowlist")
+	if !isRunnerImageAllowed(requested, allowlist) {
+		return "", fmt.Errorf("runner image %q is not in the project allowlist", requested)
+	}
+	return requested, nil
+}
@@ -90,6 +90,13 @@ func handleAgenticSessionEvent(obj *unstructured.Unstructured) error {
 
 	log.Printf("Processing AgenticSession %s with phase %s (desired: %s)", name, phase, desiredPhase)
 
+	// The runner pod lives on the cluster the session is placed on
+	runner, err := sessionRunnerCluster(context.TODO(), currentObj)
+	if err != nil {
+		log.Printf("Warning: %v; using the local cluster", err)
+		runner = localCluster()
+	}
+
 	// === DESIRED PHASE RECONCILIATION ===
 	// Handle user-requested state transitions via annotations
 
@@ -98,8 +105,8 @@ func handleAgenticSessionEvent(obj *unstructured.Unstructured) error {
 		log.Printf("[DesiredPhase] Session %s/%s: user requested start/restart (current=%s → desired=Running)", sessionNamespace, name, phase)
 
 		// Delete old pod if it exists (from previous run)
-		podName := fmt.Sprintf("%s-runner", name)
-		_, err = config.K8sClient.CoreV1().Pods(sessionNamespace).Get(context.TODO(), podName, v1.GetOptions{})
+		podName := RunnerPodName(currentObj)
+		_, err = runner.client.CoreV1().Pods(sessionNamespace).Get(context.TODO(), podName, v1.GetOptions{})
 		if err == nil {
 			log.Printf("[DesiredPhase] Cleaning up old pod %s before restart", podName)
 			if err := deletePodAndPerPodService(sessionNamespace, podName, name); err != nil {
@@ -108,6 +115,8 @@ func handleAgenticSessionEvent(obj *unstructured.Unstructured) error {
 		} else if !errors.IsNotFound(err) {
 			log.Printf("[DesiredPhase] Error checking for old job: %v", err)
 		}
+		// A claimed warm pool pod isn't reused; the restart gets its own runner
+		_ = clearAnnotation(sessionNamespace, name, runnerPodAnnotation)
 
 		// Regenerate runner token if this is a continuation
 		// Check if parent-session-id annotation is set
@@ -153,7 +162,7 @@ func handleAgenticSessionEvent(obj *unstructured.Unstructured) error {
 		log.Printf("[DesiredPhase] Session %s/%s: user requested stop (current=%s → desired=Stopped)", sessionNamespace, name, phase)
 
 		// Delete running pod
-		podName := fmt.Sprintf("%s-runner", name)
+		podName := RunnerPodName(currentObj)
 		if err := deletePodAndPerPodService(sessionNamespace, podName, name); err != nil {
 			log.Printf("[DesiredPhase] Warning: failed to delete pod: %v", err)
 		}
@@ -179,8 +188,8 @@ func handleAgenticSessionEvent(obj *unstructured.Unstructured) error {
 	// === STOPPING PHASE HANDLER ===
 	// Complete the stop transition: verify cleanup and transition to Stopped
 	if phase == "Stopping" {
-		podName := fmt.Sprintf("%s-runner", name)
-		_, err := config.K8sClient.CoreV1().Pods(sessionNamespace).Get(context.TODO(), podName, v1.GetOptions{})
+		podName := RunnerPodName(currentObj)
+		_, err := runner.client.CoreV1().Pods(sessionNamespace).Get(context.TODO(), podName, v1.GetOptions{})
 
 		if errors.IsNotFound(err) {
 			// Pod is gone - safe to transition to Stopped
@@ -249,16 +258,16 @@ func handleAgenticSessionEvent(obj *unstructured.Unstructured) error {
 	// Handle Stopped phase - clean up running pod if it exists
 	if phase == "Stopped" {
 		log.Printf("Session %s is stopped, checking for running pod to clean up", name)
-		podName := fmt.Sprintf("%s-runner", name)
+		podName := RunnerPodName(currentObj)
 
-		_, err := config.K8sClient.CoreV1().Pods(sessionNamespace).Get(context.TODO(), podName, v1.GetOptions{})
+		_, err := runner.client.CoreV1().Pods(sessionNamespace).Get(context.TODO(), podName, v1.GetOptions{})
 		if err == nil {
 			// Pod exists, delete it
 			log.Printf("Pod %s is still active, cleaning up pod", podName)
 
 			// Delete the pod
 			deletePolicy := v1.DeletePropagationForeground
-			err = config.K8sClient.CoreV1().Pods(sessionNamespace).Delete(context.TODO(), podName, v1.DeleteOptions{
+			err = runner.client.CoreV1().Pods(sessionNamespace).Delete(context.TODO(), podName, v1.DeleteOptions{
 				PropagationPolicy: &deletePolicy,
 			})
 			if err != nil && !errors.IsNotFound(err) {
@@ -270,7 +279,7 @@ func handleAgenticSessionEvent(obj *unstructured.Unstructured) error {
 			// Also delete any other pods labeled with this session (in case owner refs are lost)
 			sessionPodSelector := fmt.Sprintf("agentic-session=%s", name)
 			log.Printf("Deleting pods with agentic-session selector: %s", sessionPodSelector)
-			err = config.K8sClient.CoreV1().Pods(sessionNamespace).DeleteCollection(context.TODO(), v1.DeleteOptions{}, v1.ListOptions{
+			err = runner.client.CoreV1().Pods(sessionNamespace).DeleteCollection(context.TODO(), v1.DeleteOptions{}, v1.ListOptions{
 				LabelSelector: sessionPodSelector,
 			})
 			if err != nil && !errors.IsNotFound(err) {
@@ -318,6 +327,7 @@ func handleAgenticSessionEvent(obj *unstructured.Unstructured) error {
 
 		if currentGeneration > observedGeneration {
 			spec, _, _ := unstructured.NestedMap(currentObj.Object, "spec")
+			reconcileWorkspaceSize(runner, sessionNamespace, name, spec, currentObj)
 			reposErr := reconcileSpecReposWithPatch(sessionNamespace, name, spec, currentObj, statusPatch)
 			if reposErr != nil {
 				log.Printf("[Reconcile] Failed to reconcile repos for %s/%s: %v", sessionNamespace, name, reposErr)
@@ -369,8 +379,8 @@ func handleAgenticSessionEvent(obj *unstructured.Unstructured) error {
 
 	// If in Creating phase, check if job exists
 	if phase == "Creating" {
-		podName := fmt.Sprintf("%s-runner", name)
-		_, err := config.K8sClient.CoreV1().Pods(sessionNamespace).Get(context.TODO(), podName, v1.GetOptions{})
+		podName := RunnerPodName(currentObj)
+		_, err := runner.client.CoreV1().Pods(sessionNamespace).Get(context.TODO(), podName, v1.GetOptions{})
 		if err == nil {
 			// Pod exists, start monitoring if not already running
 			monitorKey := fmt.Sprintf("%s/%s", sessionNamespace, podName)
@@ -465,6 +475,12 @@ func handleAgenticSessionEvent(obj *unstructured.Unstructured) error {
 		}
 	}
 
+	// Sessions waiting for capacity get no runner until the backend admits them
+	if IsQueuedForAdmission(currentObj) {
+		log.Printf("Session %s is queued for admission, not creating a runner", name)
+		return MarkAdmissionQueued(context.TODO(), currentObj)
+	}
+
 	// Check for session continuation (parent session ID)
 	parentSessionID := ""
 	// Annotations already loaded above, reuse
@@ -515,6 +531,12 @@ func handleAgenticSessionEvent(obj *unstructured.Unstructured) error {
 				Reason:  "SecretCheckFailed",
 				Message: errMsg,
 			})
+			statusPatch.AddCondition(conditionUpdate{
+				Type:    conditionCredentialsResolved,
+				Status:  "False",
+				Reason:  "SecretCheckFailed",
+				Message: errMsg,
+			})
 			statusPatch.AddCondition(conditionUpdate{
 				Type:    conditionReady,
 				Status:  "False",
@@ -534,6 +556,12 @@ func handleAgenticSessionEvent(obj *unstructured.Unstructured) error {
 				Reason:  "VertexSecretMissing",
 				Message: errMsg,
 			})
+			statusPatch.AddCondition(conditionUpdate{
+				Type:    conditionCredentialsResolved,
+				Status:  "False",
+				Reason:  "VertexSecretMissing",
+				Message: errMsg,
+			})
 			statusPatch.AddCondition(conditionUpdate{
 				Type:    conditionReady,
 				Status:  "False",
@@ -574,8 +602,13 @@ func handleAgenticSessionEvent(obj *unstructured.Unstructured) error {
 		log.Printf("Langfuse disabled, skipping secret copy")
 	}
 
+	// Choose the runner cluster before anything is created on it
+	if runner, err = placeSession(context.TODO(), currentObj); err != nil {
+		return fmt.Errorf("failed to place session %s on a runner cluster: %w", name, err)
+	}
+
 	// Create a Kubernetes Pod for this AgenticSession
-	podName := fmt.Sprintf("%s-runner", name)
+	podName := RunnerPodName(currentObj)
 
 	// Ensure runner token exists before creating pod
 	// This handles cases where sessions are created directly via kubectl (bypassing the backend)
@@ -594,6 +627,12 @@ func handleAgenticSessionEvent(obj *unstructured.Unstructured) error {
 					Reason:  "TokenProvisionFailed",
 					Message: errMsg,
 				})
+				statusPatch.AddCondition(conditionUpdate{
+					Type:    conditionCredentialsResolved,
+					Status:  "False",
+					Reason:  "TokenProvisionFailed",
+					Message: errMsg,
+				})
 				_ = statusPatch.Apply()
 				return fmt.Errorf("failed to provision runner token for session %s: %v", name, err)
 			}
@@ -604,7 +643,7 @@ func handleAgenticSessionEvent(obj *unstructured.Unstructured) error {
 	}
 
 	// Check if pod already exists in the session's namespace
-	_, err = config.K8sClient.CoreV1().Pods(sessionNamespace).Get(context.TODO(), podName, v1.GetOptions{})
+	_, err = runner.client.CoreV1().Pods(sessionNamespace).Get(context.TODO(), podName, v1.GetOptions{})
 	if err == nil {
 		log.Printf("Pod %s already exists for AgenticSession %s", podName, name)
 		statusPatch.SetField("phase", "Creating")
@@ -620,6 +659,11 @@ func handleAgenticSessionEvent(obj *unstructured.Unstructured) error {
 		_ = clearAnnotation(sessionNamespace, name, "ambient-code.io/desired-phase")
 		return nil
 	}
+	if _, claimed := currentObj.GetAnnotations()[runnerPodAnnotation]; claimed {
+		// The warm pool pod this session claimed is gone
+		_ = clearAnnotation(sessionNamespace, name, runnerPodAnnotation)
+		podName = fmt.Sprintf("%s-runner", name)
+	}
 
 	// Extract spec information from the fresh object
 	spec, _, _ := unstructured.NestedMap(currentObj.Object, "spec")
@@ -634,6 +678,59 @@ func handleAgenticSessionEvent(obj *unstructured.Unstructured) error {
 	temperature, _, _ := unstructured.NestedFloat64(llmSettings, "temperature")
 	maxTokens, _, _ := unstructured.NestedInt64(llmSettings, "maxTokens")
 
+	projectSettings, err := getProjectSettingsSpec(sessionNamespace)
+	if err != nil {
+		return err
+	}
+
+	runnerImage, err := resolveRunnerImage(spec, projectSettings, appConfig.AmbientCodeRunnerImage)
+	if err != nil {
+		errMsg := fmt.Sprintf("Invalid runner image: %v", err)
+		log.Printf("Session %s: %s", name, errMsg)
+		recordSessionWarning(currentObj, eventReasonInvalidConfig, "%s", errMsg)
+		statusPatch.SetField("phase", "Failed")
+		statusPatch.AddCondition(conditionUpdate{
+			Type:    conditionReady,
+			Status:  "False",
+			Reason:  "RunnerImageNotAllowed",
+			Message: errMsg,
+		})
+		_ = statusPatch.Apply()
+		return fmt.Errorf("session %s: %w", name, err)
+	}
+
+	workspaceSize, err := resolveWorkspaceSize(spec, projectSettings)
+	if err != nil {
+		errMsg := fmt.Sprintf("Invalid workspace size: %v", err)
+		log.Printf("Session %s: %s", name, errMsg)
+		recordSessionWarning(currentObj, eventReasonInvalidConfig, "%s", errMsg)
+		statusPatch.SetField("phase", "Failed")
+		statusPatch.AddCondition(conditionUpdate{
+			Type:    conditionReady,
+			Status:  "False",
+			Reason:  "WorkspaceSizeNotAllowed",
+			Message: errMsg,
+		})
+		_ = statusPatch.Apply()
+		return fmt.Errorf("session %s: %w", name, err)
+	}
+
+	sidecars, err := buildRunnerSidecars(projectSettings, appConfig.ImagePullPolicy)
+	if err != nil {
+		errMsg := fmt.Sprintf("Invalid sidecar configuration: %v", err)
+		log.Printf("Session %s: %s", name, errMsg)
+		recordSessionWarning(currentObj, eventReasonInvalidConfig, "%s", errMsg)
+		statusPatch.SetField("phase", "Failed")
+		statusPatch.AddCondition(conditionUpdate{
+			Type:    conditionReady,
+			Status:  "False",
+			Reason:  "SidecarConfigInvalid",
+			Message: errMsg,
+		})
+		_ = statusPatch.Apply()
+		return fmt.Errorf("session %s: %w", name, err)
+	}
+
 	// Hardcoded secret names (convention over configuration)
 	const runnerSecretsName = "ambient-runner-secrets"               // ANTHROPIC_API_KEY only (ignored when Vertex enabled)
 	const integrationSecretsName = "ambient-non-vertex-integrations" // GIT_*, JIRA_*, custom keys (optional)
@@ -647,12 +744,19 @@ func handleAgenticSessionEvent(obj *unstructured.Unstructured) error {
 			} else {
 				log.Printf("Runner secret %s missing in %s (Vertex disabled)", runnerSecretsName, sessionNamespace)
 			}
+			recordSessionWarning(currentObj, eventReasonSecretMissing, "Secret %s missing in namespace %s", runnerSecretsName, sessionNamespace)
 			statusPatch.AddCondition(conditionUpdate{
 				Type:    conditionSecretsReady,
 				Status:  "False",
 				Reason:  "RunnerSecretMissing",
 				Message: fmt.Sprintf("Secret %s missing", runnerSecretsName),
 			})
+			statusPatch.AddCondition(conditionUpdate{
+				Type:    conditionCredentialsResolved,
+				Status:  "False",
+				Reason:  "RunnerSecretMissing",
+				Message: fmt.Sprintf("Secret %s missing", runnerSecretsName),
+			})
 			_ = statusPatch.Apply()
 			return fmt.Errorf("runner secret %s missing in namespace %s", runnerSecretsName, sessionNamespace)
 		}
@@ -671,12 +775,21 @@ func handleAgenticSessionEvent(obj *unstructured.Unstructured) error {
 		log.Printf("No %s secret found in %s (optional, skipping)", integrationSecretsName, sessionNamespace)
 	}
 
+	// Credentials for project-registered MCP servers
+	mcpCredentialSecrets := projectMCPCredentialSecrets(sessionNamespace)
+
 	statusPatch.AddCondition(conditionUpdate{
 		Type:    conditionSecretsReady,
 		Status:  "True",
 		Reason:  "AllRequiredSecretsFound",
 		Message: "Runner secret available",
 	})
+	statusPatch.AddCondition(conditionUpdate{
+		Type:    conditionCredentialsResolved,
+		Status:  "True",
+		Reason:  "AllRequiredSecretsFound",
+		Message: "Runner secret available",
+	})
 	if integrationSecretsExist {
 		statusPatch.AddCondition(conditionUpdate{
 			Type:    "IntegrationSecretsReady",
@@ -735,10 +848,16 @@ func handleAgenticSessionEvent(obj *unstructured.Unstructured) error {
 		AutomountServiceAccountToken: boolPtr(false),
 		Volumes: []corev1.Volume{
 			{
-				Name: "workspace",
+				Name:         "workspace",
+				VolumeSource: workspaceVolumeSource(workspaceSize, projectSettings),
+			},
+			{
+				// Project MCP server registry (optional; absent until a server is registered)
+				Name: "mcp-servers",
 				VolumeSource: corev1.VolumeSource{
-					EmptyDir: &corev1.EmptyDirVolumeSource{
-						SizeLimit: resource.NewQuantity(10*1024*1024*1024, resource.BinarySI), // 10Gi
+					ConfigMap: &corev1.ConfigMapVolumeSource{
+						LocalObjectReference: corev1.LocalObjectReference{Name: types.ProjectMCPServersConfigMap},
+						Optional:             boolPtr(true),
 					},
 				},
 			},
@@ -774,6 +893,9 @@ func handleAgenticSessionEvent(obj *unstructured.Unstructured) error {
 						b, _ := json.Marshal(repos)
 						base = append(base, corev1.EnvVar{Name: "REPOS_JSON", Value: string(b)})
 					}
+					if appConfig.GitMirrorServiceURL != "" {
+						base = append(base, corev1.EnvVar{Name: "GIT_MIRROR_SERVICE_URL", Value: appConfig.GitMirrorServiceURL})
+					}
 
 					// Add workflow info if present
 					if workflow, ok := spec["activeWorkflow"].(map[string]interface{}); ok {
@@ -854,7 +976,7 @@ func handleAgenticSessionEvent(obj *unstructured.Unstructured) error {
 			},
 			{
 				Name:            "ambient-code-runner",
-				Image:           appConfig.AmbientCodeRunnerImage,
+				Image:           runnerImage,
 				ImagePullPolicy: appConfig.ImagePullPolicy,
 				// 🔒 Container-level security (SCC-compatible, no privileged capabilities)
 				SecurityContext: &corev1.SecurityContext{
@@ -877,6 +999,7 @@ func handleAgenticSessionEvent(obj *unstructured.Unstructured) error {
 					// Mount .claude directory for session state persistence (synced to S3)
 					// This enables SDK's built-in resume functionality
 					{Name: "workspace", MountPath: "/app/.claude", SubPath: ".claude", ReadOnly: false},
+					{Name: "mcp-servers", MountPath: projectMCPConfigDir, ReadOnly: true},
 				},
 
 				// Lifecycle hook to copy Google credentials from read-only secret mount to writable workspace
@@ -916,6 +1039,7 @@ func handleAgenticSessionEvent(obj *unstructured.Unstructured) error {
 					if mcpConfigFile := os.Getenv("MCP_CONFIG_FILE"); strings.TrimSpace(mcpConfigFile) != "" {
 						base = append(base, corev1.EnvVar{Name: "MCP_CONFIG_FILE", Value: mcpConfigFile})
 					}
+					base = append(base, corev1.EnvVar{Name: "PROJECT_MCP_CONFIG_FILE", Value: projectMCPConfigDir + "/" + types.ProjectMCPServersKey})
 
 					// Add user context for observability and auditing (Langfuse userId, logs, etc.)
 					if userID != "" {
@@ -1133,6 +1257,11 @@ func handleAgenticSessionEvent(obj *unstructured.Unstructured) error {
 						log.Printf("Skipping runner secrets '%s' for session %s (Vertex enabled)", runnerSecretsName, name)
 					}
 
+					if len(mcpCredentialSecrets) > 0 {
+						sources = append(sources, projectMCPEnvFrom(mcpCredentialSecrets)...)
+						log.Printf("Injecting MCP credential secrets %v for session %s", mcpCredentialSecrets, name)
+					}
+
 					return sources
 				}(),
 
@@ -1189,6 +1318,12 @@ func handleAgenticSessionEvent(obj *unstructured.Unstructured) error {
 		},
 	}
 
+	// Project-configured sidecars (ProjectSettings spec.runnerSidecars)
+	injectRunnerSidecars(&podSpec, sidecars)
+
+	// Spot capacity (spec.spot), or on-demand after a preemption
+	applySpotScheduling(&podSpec, currentObj, appConfig)
+
 	if appConfig.PodFSGroup != nil {
 		podSpec.SecurityContext = &corev1.PodSecurityContext{
 			FSGroup:             appConfig.PodFSGroup,
@@ -1256,9 +1391,57 @@ func handleAgenticSessionEvent(obj *unstructured.Unstructured) error {
 
 	// Do not mount runner Secret volume; runner fetches tokens on demand
 
-	// Create the pod
-	createdPod, err := config.K8sClient.CoreV1().Pods(sessionNamespace).Create(context.TODO(), pod, v1.CreateOptions{})
-	if err != nil {
+	// Node pool the backend admitted the session to (placement policy)
+	if err := applyNodePool(pod, currentObj, projectSettings); err != nil {
+		log.Printf("Session %s: %v", name, err)
+		recordSessionWarning(currentObj, eventReasonInvalidConfig, "%v", err)
+	}
+
+	// Project shared files, mounted read-only
+	if sharedWorkspaceEnabled(projectSettings) {
+		if runner.remote() {
+			recordSessionWarning(currentObj, eventReasonInvalidConfig, "Shared project workspace is not available on remote runner cluster %s", runner.name)
+		} else {
+			addSharedWorkspace(&pod.Spec)
+		}
+	}
+
+	// Direct runner access for debugging, when the project enables it
+	debugAccess := runnerDebugAccessEnabled(projectSettings)
+	if debugAccess && runner.remote() {
+		recordSessionWarning(currentObj, eventReasonInvalidConfig, "Runner debug access is not available on remote runner cluster %s", runner.name)
+		debugAccess = false
+	}
+	if debugAccess {
+		if err := prepareDebugAccess(context.TODO(), runner, appConfig, currentObj, &pod.Spec); err != nil {
+			log.Printf("Session %s: %v", name, err)
+			recordSessionWarning(currentObj, eventReasonServiceCreateFailed, "Runner debug access unavailable: %v", err)
+			debugAccess = false
+		}
+	}
+
+	// A remote runner cluster needs the namespace and the objects the pod references
+	if runner.remote() {
+		if err := prepareRemoteRunnerPod(context.TODO(), runner, pod); err != nil {
+			log.Printf("Failed to prepare runner cluster %s for pod %s: %v", runner.name, podName, err)
+			recordSessionWarning(currentObj, eventReasonPodCreateFailed, "Failed to prepare runner cluster %s: %v", runner.name, err)
+			statusPatch.AddCondition(conditionUpdate{
+				Type:    conditionPodCreated,
+				Status:  "False",
+				Reason:  "RunnerClusterNotReady",
+				Message: err.Error(),
+			})
+			_ = statusPatch.Apply()
+			return err
+		}
+	}
+
+	// Claim an idle pod from the project's warm pool, or create the pod
+	createdPod := claimWarmRunner(context.TODO(), runner, currentObj, pod, projectSettings, appConfig.AmbientCodeRunnerImage)
+	claimed := createdPod != nil
+	if claimed {
+		podName = createdPod.Name
+	} else if createdPod, err = runner.client.CoreV1().Pods(sessionNamespace).Create(context.TODO(), pod, v1.CreateOptions{}); err != nil {
 		// If pod already exists, this is likely a race condition from duplicate watch events - not an error
 		if errors.IsAlreadyExists(err) {
 			log.Printf("Pod %s already exists (race condition), continuing", podName)
@@ -1267,6 +1450,7 @@ func handleAgenticSessionEvent(obj *unstructured.Unstructured) error {
 			return nil
 		}
 		log.Printf("Failed to create pod %s: %v", podName, err)
+		recordSessionWarning(currentObj, eventReasonPodCreateFailed, "Failed to create runner pod %s: %v", podName, err)
 		statusPatch.AddCondition(conditionUpdate{
 			Type:    conditionPodCreated,
 			Status:  "False",
@@ -1283,7 +1467,15 @@ func handleAgenticSessionEvent(obj *unstructured.Unstructured) error {
 		return fmt.Errorf("failed to create pod: %v", err)
 	}
 
-	log.Printf("Created pod %s for AgenticSession %s", podName, name)
+	if !claimed {
+		log.Printf("Created pod %s for AgenticSession %s", podName, name)
+		recordSessionEvent(currentObj, corev1.EventTypeNormal, eventReasonPodCreated, "Created runner pod %s", podName)
+	}
+
+	// Guard the runner against voluntary eviction; drains are handled by checkpointing
+	if err := ensureRunnerPDB(runner.client, sessionNamespace, name, createdPod); err != nil {
+		log.Printf("Warning: %v", err)
+	}
 	statusPatch.SetField("phase", "Creating")
 	statusPatch.SetField("observedGeneration", currentObj.GetGeneration())
 	statusPatch.AddCondition(conditionUpdate{
@@ -1292,6 +1484,24 @@ func handleAgenticSessionEvent(obj *unstructured.Unstructured) error {
 		Reason:  "PodCreated",
 		Message: "Runner pod created",
 	})
+	statusPatch.AddCondition(conditionUpdate{
+		Type:    conditionAdmitted,
+		Status:  "True",
+		Reason:  "Admitted",
+		Message: "Capacity available for the runner",
+	})
+	statusPatch.AddCondition(conditionUpdate{
+		Type:    conditionWorkspaceReady,
+		Status:  "False",
+		Reason:  "Hydrating",
+		Message: "Waiting for workspace hydration",
+	})
+	statusPatch.AddCondition(conditionUpdate{
+		Type:    conditionRunActive,
+		Status:  "False",
+		Reason:  "PodStarting",
+		Message: "Runner pod is starting",
+	})
 	// Apply all accumulated status changes in a single API call
 	if err := statusPatch.Apply(); err != nil {
 		log.Printf("Warning: failed to apply status patch: %v", err)
@@ -1322,8 +1532,9 @@ func handleAgenticSessionEvent(obj *unstructured.Unstructured) error {
 			Type:     corev1.ServiceTypeClusterIP,
 		},
 	}
-	if _, serr := config.K8sClient.CoreV1().Services(sessionNamespace).Create(context.TODO(), svc, v1.CreateOptions{}); serr != nil && !errors.IsAlreadyExists(serr) {
+	if _, serr := runner.client.CoreV1().Services(sessionNamespace).Create(context.TODO(), svc, v1.CreateOptions{}); serr != nil && !errors.IsAlreadyExists(serr) {
 		log.Printf("Failed to create per-pod content service for %s: %v", name, serr)
+		recordSessionWarning(currentObj, eventReasonServiceCreateFailed, "Failed to create service %s: %v", svc.Name, serr)
 	}
 
 	// Create AG-UI Service pointing to the runner's FastAPI server
@@ -1355,10 +1566,25 @@ func handleAgenticSessionEvent(obj *unstructured.Unstructured) error {
 			}},
 		},
 	}
-	if _, serr := config.K8sClient.CoreV1().Services(sessionNamespace).Create(context.TODO(), aguiSvc, v1.CreateOptions{}); serr != nil && !errors.IsAlreadyExists(serr) {
+	if _, serr := runner.client.CoreV1().Services(sessionNamespace).Create(context.TODO(), aguiSvc, v1.CreateOptions{}); serr != nil && !errors.IsAlreadyExists(serr) {
 		log.Printf("Failed to create AG-UI service for %s: %v", name, serr)
+		recordSessionWarning(currentObj, eventReasonServiceCreateFailed, "Failed to create service %s: %v", aguiSvc.Name, serr)
 	} else {
 		log.Printf("Created AG-UI service session-%s for AgenticSession %s", name, name)
+		recordSessionEvent(currentObj, corev1.EventTypeNormal, eventReasonServiceCreated, "Created AG-UI service %s", aguiSvc.Name)
+	}
+
+	if debugAccess {
+		debugURL, err := exposeDebugAccess(context.TODO(), runner, appConfig, sessionNamespace, name, createdPod)
+		if err != nil {
+			log.Printf("Session %s: %v", name, err)
+			recordSessionWarning(currentObj, eventReasonServiceCreateFailed, "Runner debug access unavailable: %v", err)
+		} else if debugURL != "" {
+			recordSessionEvent(currentObj, corev1.EventTypeNormal, eventReasonServiceCreated, "Runner debug access at %s", debugURL)
+			_ = mutateAgenticSessionStatus(sessionNamespace, name, func(status map[string]interface{}) {
+				status["debugURL"] = debugURL
+			})
+		}
 	}
 
 	// Start monitoring the pod (only if not already being monitored)
@@ -1741,7 +1967,12 @@ func monitorPod(podName, sessionName, sessionNamespace string) {
 			log.Printf("Failed to refresh runner token for %s/%s: %v", sessionNamespace, sessionName, err)
 		}
 
-		pod, err := config.K8sClient.CoreV1().Pods(sessionNamespace).Get(context.TODO(), podName, v1.GetOptions{})
+		cluster, err := sessionRunnerCluster(context.TODO(), sessionObj)
+		if err != nil {
+			log.Printf("Error resolving runner cluster for %s: %v", sessionName, err)
+			continue
+		}
+		pod, err := cluster.client.CoreV1().Pods(sessionNamespace).Get(context.TODO(), podName, v1.GetOptions{})
 		if err != nil {
 			if errors.IsNotFound(err) {
 				log.Printf("Pod %s deleted; stopping monitor", podName)
@@ -1756,6 +1987,7 @@ func monitorPod(podName, sessionName, sessionNamespace string) {
 		if pod.Spec.NodeName != "" {
 			statusPatch.AddCondition(conditionUpdate{Type: conditionPodScheduled, Status: "True", Reason: "Scheduled", Message: fmt.Sprintf("Scheduled on %s", pod.Spec.NodeName)})
 		}
+		addPodLifecycleConditions(statusPatch, pod)
 
 		if pod.Status.Phase == corev1.PodSucceeded {
 			statusPatch.SetField("phase", "Completed")
@@ -1987,21 +2219,29 @@ func getS3ConfigForProject(namespace string, appConfig *config.Config) (endpoint
 
 // deleteJobAndPerJobService deletes the Job and its associated per-job Service
 func deletePodAndPerPodService(namespace, podName, sessionName string) error {
+	runner, err := runnerClusterFor(context.TODO(), namespace, sessionName)
+	if err != nil {
+		return err
+	}
+
 	// Delete Service first (it has ownerRef to Pod, but delete explicitly just in case)
 	svcName := fmt.Sprintf("ambient-content-%s", sessionName)
-	if err := config.K8sClient.CoreV1().Services(namespace).Delete(context.TODO(), svcName, v1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+	if err := runner.client.CoreV1().Services(namespace).Delete(context.TODO(), svcName, v1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
 		log.Printf("Failed to delete per-pod service %s/%s: %v", namespace, svcName, err)
 	}
 
 	// Delete AG-UI service
 	aguiSvcName := fmt.Sprintf("session-%s", sessionName)
-	if err := config.K8sClient.CoreV1().Services(namespace).Delete(context.TODO(), aguiSvcName, v1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+	if err := runner.client.CoreV1().Services(namespace).Delete(context.TODO(), aguiSvcName, v1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
 		log.Printf("Failed to delete AG-UI service %s/%s: %v", namespace, aguiSvcName, err)
 	}
 
+	// Delete the debug access Service and Route/Ingress, if any
+	deleteDebugAccess(context.TODO(), runner, namespace, sessionName)
+
 	// Delete the Pod with background propagation
 	policy := v1.DeletePropagationBackground
-	if err := config.K8sClient.CoreV1().Pods(namespace).Delete(context.TODO(), podName, v1.DeleteOptions{PropagationPolicy: &policy}); err != nil && !errors.IsNotFound(err) {
+	if err := runner.client.CoreV1().Pods(namespace).Delete(context.TODO(), podName, v1.DeleteOptions{PropagationPolicy: &policy}); err != nil && !errors.IsNotFound(err) {
 		log.Printf("Failed to delete pod %s/%s: %v", namespace, podName, err)
 		return err
 	}
@@ -0,0 +1,177 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"ambient-code-operator/internal/config"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// sharedWorkspacePVCName holds the project's shared files (docs, datasets, style guides)
+	sharedWorkspacePVCName = "ambient-shared-workspace"
+	// sharedContentName is the Deployment and Service writing the shared volume for the backend.
+	// IMPORTANT: Keep in sync with backend (handlers/shared_workspace.go)
+	sharedContentName = "ambient-shared-content"
+	// sharedWorkspaceVolumeName is the runner pod volume of the shared PVC
+	sharedWorkspaceVolumeName = "shared-workspace"
+	// sharedWorkspaceMountPath is where runners see the shared files, read-only
+	sharedWorkspaceMountPath = "/shared"
+	// defaultSharedWorkspaceSize is the PVC size when spec.sharedWorkspace.size is unset
+	defaultSharedWorkspaceSize = "5Gi"
+)
+
+// sharedWorkspaceEnabled reports whether ProjectSettings spec.sharedWorkspace.enabled is set
+func sharedWorkspaceEnabled(projectSettings map[string]interface{}) bool {
+	enabled, _, _ := unstructured.NestedBool(projectSettings, "sharedWorkspace", "enabled")
+	return enabled
+}
+
+// reconcileSharedWorkspace creates the project's shared volume and the content service
+// the backend writes it through, or removes the content service when the project turns
+// the shared workspace off. The PVC is kept so its files survive re-enabling; it is
+// owned by the ProjectSettings and removed with it.
+func reconcileSharedWorkspace(ctx context.Context, client kubernetes.Interface, appConfig *config.Config, settings *unstructured.Unstructured) error {
+	namespace := settings.GetNamespace()
+	spec, _, _ := unstructured.NestedMap(settings.Object, "spec")
+	if !sharedWorkspaceEnabled(spec) {
+		return deleteSharedContentService(ctx, client, namespace)
+	}
+
+	ownerRefs := []v1.OwnerReference{{
+		APIVersion: settings.GetAPIVersion(),
+		Kind:       settings.GetKind(),
+		Name:       settings.GetName(),
+		UID:        settings.GetUID(),
+	}}
+	labels := map[string]string{"app": sharedContentName}
+
+	size, _, _ := unstructured.NestedString(spec, "sharedWorkspace", "size")
+	if size == "" {
+		size = defaultSharedWorkspaceSize
+	}
+	quantity, err := resource.ParseQuantity(size)
+	if err != nil {
+		return fmt.Errorf("invalid sharedWorkspace.size %q: %w", size, err)
+	}
+	pvc := &corev1.PersistentVolumeClaim{
+		ObjectMeta: v1.ObjectMeta{Name: sharedWorkspacePVCName, Namespace: namespace, Labels: labels, OwnerReferences: ownerRefs},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			// Runner pods on any node mount the volume alongside the content service
+			AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteMany},
+			Resources: corev1.VolumeResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceStorage: quantity},
+			},
+		},
+	}
+	if storageClass, _, _ := unstructured.NestedString(spec, "sharedWorkspace", "storageClass"); storageClass != "" {
+		pvc.Spec.StorageClassName = &storageClass
+	}
+	if _, err := client.CoreV1().PersistentVolumeClaims(namespace).Create(ctx, pvc, v1.CreateOptions{}); err != nil && !errors.IsAlreadyExists(err) {
+		return fmt.Errorf("create shared workspace PVC: %w", err)
+	}
+
+	replicas := int32(1)
+	deployment := &appsv1.Deployment{
+		ObjectMeta: v1.ObjectMeta{Name: sharedContentName, Namespace: namespace, Labels: labels, OwnerReferences: ownerRefs},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Selector: &v1.LabelSelector{MatchLabels: labels},
+			// Never run two writers against the volume
+			Strategy: appsv1.DeploymentStrategy{Type: appsv1.RecreateDeploymentStrategyType},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: v1.ObjectMeta{Labels: labels},
+				Spec: corev1.PodSpec{
+					AutomountServiceAccountToken: boolPtr(false),
+					Containers: []corev1.Container{{
+						Name:            "ambient-content",
+						Image:           appConfig.ContentServiceImage,
+						ImagePullPolicy: appConfig.ImagePullPolicy,
+						Env: []corev1.EnvVar{
+							{Name: "CONTENT_SERVICE_MODE", Value: "true"},
+							{Name: "STATE_BASE_DIR", Value: sharedWorkspaceMountPath},
+						},
+						Ports: []corev1.ContainerPort{{ContainerPort: 8080, Name: "http"}},
+						ReadinessProbe: &corev1.Probe{
+							ProbeHandler: corev1.ProbeHandler{
+								HTTPGet: &corev1.HTTPGetAction{Path: "/health", Port: intstr.FromString("http")},
+							},
+							InitialDelaySeconds: 5,
+							PeriodSeconds:       5,
+						},
+						SecurityContext: &corev1.SecurityContext{
+							AllowPrivilegeEscalation: boolPtr(false),
+							Capabilities:             &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}},
+						},
+						VolumeMounts: []corev1.VolumeMount{{Name: sharedWorkspaceVolumeName, MountPath: sharedWorkspaceMountPath}},
+					}},
+					Volumes: []corev1.Volume{sharedWorkspaceVolume(false)},
+				},
+			},
+		},
+	}
+	if _, err := client.AppsV1().Deployments(namespace).Create(ctx, deployment, v1.CreateOptions{}); err != nil && !errors.IsAlreadyExists(err) {
+		return fmt.Errorf("create shared content deployment: %w", err)
+	}
+
+	svc := &corev1.Service{
+		ObjectMeta: v1.ObjectMeta{Name: sharedContentName, Namespace: namespace, Labels: labels, OwnerReferences: ownerRefs},
+		Spec: corev1.ServiceSpec{
+			Selector: labels,
+			Ports:    []corev1.ServicePort{{Name: "http", Port: 8080, TargetPort: intstr.FromString("http")}},
+		},
+	}
+	if _, err := client.CoreV1().Services(namespace).Create(ctx, svc, v1.CreateOptions{}); err != nil && !errors.IsAlreadyExists(err) {
+		return fmt.Errorf("create shared content service: %w", err)
+	}
+	return nil
+}
+
+// deleteSharedContentService removes the shared content Deployment and Service, keeping the PVC
+func deleteSharedContentService(ctx context.Context, client kubernetes.Interface, namespace string) error {
+	if err := client.AppsV1().Deployments(namespace).Delete(ctx, sharedContentName, v1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("delete shared content deployment: %w", err)
+	} else if err == nil {
+		log.Printf("Shared workspace disabled in %s, removed the shared content service", namespace)
+	}
+	if err := client.CoreV1().Services(namespace).Delete(ctx, sharedContentName, v1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+		return fmt.Errorf("delete shared content service: %w", err)
+	}
+	return nil
+}
+
+func sharedWorkspaceVolume(readOnly bool) corev1.Volume {
+	return corev1.Volume{
+		Name: sharedWorkspaceVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: sharedWorkspacePVCName, ReadOnly: readOnly},
+		},
+	}
+}
+
+// addSharedWorkspace mounts the project's shared volume read-only into the runner
+// container and tells the runner where to find it
+func addSharedWorkspace(podSpec *corev1.PodSpec) {
+	podSpec.Volumes = append(podSpec.Volumes, sharedWorkspaceVolume(true))
+	for i := range podSpec.Containers {
+		if podSpec.Containers[i].Name != "ambient-code-runner" {
+			continue
+		}
+		podSpec.Containers[i].VolumeMounts = append(podSpec.Containers[i].VolumeMounts, corev1.VolumeMount{
+			Name:      sharedWorkspaceVolumeName,
+			MountPath: sharedWorkspaceMountPath,
+			ReadOnly:  true,
+		})
+		podSpec.Containers[i].Env = append(podSpec.Containers[i].Env, corev1.EnvVar{Name: "SHARED_WORKSPACE_PATH", Value: sharedWorkspaceMountPath})
+	}
+}
@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+
+	"ambient-code-operator/internal/config"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestReconcileSharedWorkspace(t *testing.T) {
+	ctx := context.Background()
+	client := fake.NewSimpleClientset()
+	appConfig := &config.Config{ContentServiceImage: "backend:test"}
+	settings := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "vteam.ambient-code/v1alpha1",
+		"kind":       "ProjectSettings",
+		"metadata":   map[string]interface{}{"name": "projectsettings", "namespace": "team-a"},
+		"spec": map[string]interface{}{
+			"sharedWorkspace": map[string]interface{}{"enabled": true, "size": "20Gi", "storageClass": "nfs"},
+		},
+	}}
+
+	if err := reconcileSharedWorkspace(ctx, client, appConfig, settings); err != nil {
+		t.Fatalf("reconcileSharedWorkspace: %v", err)
+	}
+	pvc, err := client.CoreV1().PersistentVolumeClaims("team-a").Get(ctx, sharedWorkspacePVCName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("shared PVC not created: %v", err)
+	}
+	if got := pvc.Spec.Resources.Requests[corev1.ResourceStorage]; got.String() != "20Gi" {
+		t.Errorf("PVC size = %s", got.String())
+	}
+	if pvc.Spec.StorageClassName == nil || *pvc.Spec.StorageClassName != "nfs" || pvc.Spec.AccessModes[0] != corev1.ReadWriteMany {
+		t.Errorf("PVC spec = %+v", pvc.Spec)
+	}
+	deployment, err := client.AppsV1().Deployments("team-a").Get(ctx, sharedContentName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("shared content deployment not created: %v", err)
+	}
+	if mounts := deployment.Spec.Template.Spec.Containers[0].VolumeMounts; len(mounts) != 1 || mounts[0].ReadOnly {
+		t.Errorf("content service should mount the volume writable, got %+v", mounts)
+	}
+	if _, err := client.CoreV1().Services("team-a").Get(ctx, sharedContentName, metav1.GetOptions{}); err != nil {
+		t.Errorf("shared content service not created: %v", err)
+	}
+
+	// Reconciling again is a no-op
+	if err := reconcileSharedWorkspace(ctx, client, appConfig, settings); err != nil {
+		t.Fatalf("second reconcile: %v", err)
+	}
+
+	// Disabling removes the content service but keeps the files
+	_ = unstructured.SetNestedField(settings.Object, false, "spec", "sharedWorkspace", "enabled")
+	if err := reconcileSharedWorkspace(ctx, client, appConfig, settings); err != nil {
+		t.Fatalf("reconcile disabled: %v", err)
+	}
+	if _, err := client.AppsV1().Deployments("team-a").Get(ctx, sharedContentName, metav1.GetOptions{}); !errors.IsNotFound(err) {
+		t.Errorf("content deployment should be deleted, got %v", err)
+	}
+	if _, err := client.CoreV1().PersistentVolumeClaims("team-a").Get(ctx, sharedWorkspacePVCName, metav1.GetOptions{}); err != nil {
+		t.Errorf("shared PVC should be kept: %v", err)
+	}
+}
+
+func TestAddSharedWorkspace(t *testing.T) {
+	podSpec := &corev1.PodSpec{Containers: []corev1.Container{{Name: "ambient-content"}, {Name: "ambient-code-runner"}}}
+	addSharedWorkspace(podSpec)
+
+	if len(podSpec.Volumes) != 1 || !podSpec.Volumes[0].PersistentVolumeClaim.ReadOnly {
+		t.Fatalf("expected a read-only shared PVC volume, got %+v", podSpec.Volumes)
+	}
+	if len(podSpec.Containers[0].VolumeMounts) != 0 {
+		t.Errorf("only the runner should mount the shared workspace")
+	}
+	runner := podSpec.Containers[1]
+	if len(runner.VolumeMounts) != 1 || !runner.VolumeMounts[0].ReadOnly || runner.VolumeMounts[0].MountPath != "/shared" {
+		t.Errorf("runner mounts = %+v", runner.VolumeMounts)
+	}
+	if len(runner.Env) != 1 || runner.Env[0].Name != "SHARED_WORKSPACE_PATH" {
+		t.Errorf("runner env = %+v", runner.Env)
+	}
+}
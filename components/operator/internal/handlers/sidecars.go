@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	// sidecarSharedVolumeName is an emptyDir mounted into the runner and every
+	// project sidecar so they can exchange sockets and files without the network.
+	sidecarSharedVolumeName = "sidecar-shared"
+	sidecarSharedMountPath  = "/var/run/ambient-sidecars"
+)
+
+// reservedContainerNames are the containers the operator always adds to runner pods.
+var reservedContainerNames = map[string]bool{
+	"init-hydrate":        true,
+	"ambient-content":     true,
+	"ambient-code-runner": true,
+	"state-sync":          true,
+	"debug-proxy":         true,
+}
+
+// runnerSidecarSpec is one entry of ProjectSettings spec.runnerSidecars.
+type runnerSidecarSpec struct {
+	Name           string                      `json:"name"`
+	Image          string                      `json:"image"`
+	Command        []string                    `json:"command,omitempty"`
+	Args           []string                    `json:"args,omitempty"`
+	Env            []corev1.EnvVar             `json:"env,omitempty"`
+	Ports          []corev1.ContainerPort      `json:"ports,omitempty"`
+	Resources      corev1.ResourceRequirements `json:"resources,omitempty"`
+	ReadinessProbe *corev1.Probe               `json:"readinessProbe,omitempty"`
+	MountWorkspace bool                        `json:"mountWorkspace,omitempty"`
+}
+
+// buildRunnerSidecars converts ProjectSettings spec.runnerSidecars into containers.
+//
+// Sidecars are returned as native sidecars (init containers with restartPolicy Always):
+// the kubelet starts them after init-hydrate and before the runner, and stops them once
+// the runner and state-sync exit, so a long-lived sidecar never keeps the pod from completing.
+func buildRunnerSidecars(projectSettings map[string]interface{}, imagePullPolicy corev1.PullPolicy) ([]corev1.Container, error) {
+	raw, found, err := unstructured.NestedSlice(projectSettings, "runnerSidecars")
+	if err != nil {
+		return nil, fmt.Errorf("invalid runnerSidecars: %w", err)
+	}
+	if !found || len(raw) == 0 {
+		return nil, nil
+	}
+
+	always := corev1.ContainerRestartPolicyAlways
+	seen := make(map[string]bool, len(raw))
+	sidecars := make([]corev1.Container, 0, len(raw))
+	for i, item := range raw {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("runnerSidecars[%d]: expected object", i)
+		}
+		var sc runnerSidecarSpec
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(m, &sc); err != nil {
+			return nil, fmt.Errorf("runnerSidecars[%d]: %w", i, err)
+		}
+		sc.Name = strings.TrimSpace(sc.Name)
+		sc.Image = strings.TrimSpace(sc.Image)
+		if sc.Name == "" || sc.Image == "" {
+			return nil, fmt.Errorf("runnerSidecars[%d]: name and image are required", i)
+		}
+		if reservedContainerNames[sc.Name] {
+			return nil, fmt.Errorf("runnerSidecars[%d]: container name %q is reserved", i, sc.Name)
+		}
+		if seen[sc.Name] {
+			return nil, fmt.Errorf("runnerSidecars[%d]: duplicate container name %q", i, sc.Name)
+		}
+		seen[sc.Name] = true
+
+		mounts := []corev1.VolumeMount{{Name: sidecarSharedVolumeName, MountPath: sidecarSharedMountPath}}
+		if sc.MountWorkspace {
+			mounts = append(mounts, corev1.VolumeMount{Name: "workspace", MountPath: "/workspace"})
+		}
+
+		sidecars = append(sidecars, corev1.Container{
+			Name:            sc.Name,
+			Image:           sc.Image,
+			ImagePullPolicy: imagePullPolicy,
+			Command:         sc.Command,
+			Args:            sc.Args,
+			Env:             sc.Env,
+			Ports:           sc.Ports,
+			Resources:       sc.Resources,
+			ReadinessProbe:  sc.ReadinessProbe,
+			RestartPolicy:   &always,
+			// Same hardening as the runner container; sidecars never run privileged
+			SecurityContext: &corev1.SecurityContext{
+				AllowPrivilegeEscalation: boolPtr(false),
+				Capabilities: &corev1.Capabilities{
+					Drop: []corev1.Capability{"ALL"},
+				},
+			},
+			VolumeMounts: mounts,
+		})
+	}
+	return sidecars, nil
+}
+
+// injectRunnerSidecars appends sidecars to the pod and wires the shared volume
+// into the runner container. It is a no-op when there are no sidecars.
+func injectRunnerSidecars(podSpec *corev1.PodSpec, sidecars []corev1.Container) {
+	if len(sidecars) == 0 {
+		return
+	}
+	podSpec.Volumes = append(podSpec.Volumes, corev1.Volume{
+		Name:         sidecarSharedVolumeName,
+		VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+	})
+	podSpec.InitContainers = append(podSpec.InitContainers, sidecars...)
+
+	names := make([]string, 0, len(sidecars))
+	for _, sc := range sidecars {
+		names = append(names, sc.Name)
+	}
+	for i := range podSpec.Containers {
+		if podSpec.Containers[i].Name != "ambient-code-runner" {
+			continue
+		}
+		podSpec.Containers[i].VolumeMounts = append(podSpec.Containers[i].VolumeMounts, corev1.VolumeMount{
+			Name:      sidecarSharedVolumeName,
+			MountPath: sidecarSharedMountPath,
+		})
+		podSpec.Containers[i].Env = append(podSpec.Containers[i].Env,
+			corev1.EnvVar{Name: "AMBIENT_SIDECARS", Value: strings.Join(names, ",")},
+			corev1.EnvVar{Name: "AMBIENT_SIDECAR_SHARED_DIR", Value: sidecarSharedMountPath},
+		)
+		break
+	}
+}
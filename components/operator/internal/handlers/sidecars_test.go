@@ -0,0 +1,84 @@
+package handlers
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// TestBuildRunnerSidecars_NativeSidecar verifies sidecars are emitted as restartable init containers
+func TestBuildRunnerSidecars_NativeSidecar(t *testing.T) {
+	settings := map[string]interface{}{
+		"runnerSidecars": []interface{}{
+			map[string]interface{}{
+				"name":           "code-index",
+				"image":          "quay.io/example/code-index:v1",
+				"args":           []interface{}{"--socket", "/var/run/ambient-sidecars/index.sock"},
+				"mountWorkspace": true,
+			},
+		},
+	}
+
+	sidecars, err := buildRunnerSidecars(settings, corev1.PullIfNotPresent)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(sidecars) != 1 {
+		t.Fatalf("expected 1 sidecar, got %d", len(sidecars))
+	}
+	sc := sidecars[0]
+	if sc.RestartPolicy == nil || *sc.RestartPolicy != corev1.ContainerRestartPolicyAlways {
+		t.Errorf("expected restartPolicy Always for native sidecar")
+	}
+	if len(sc.VolumeMounts) != 2 {
+		t.Errorf("expected shared and workspace mounts, got %v", sc.VolumeMounts)
+	}
+	if len(sc.Args) != 2 {
+		t.Errorf("expected args to be carried over, got %v", sc.Args)
+	}
+}
+
+// TestBuildRunnerSidecars_RejectsInvalid verifies reserved, duplicate, and incomplete entries fail
+func TestBuildRunnerSidecars_RejectsInvalid(t *testing.T) {
+	cases := map[string][]interface{}{
+		"reserved name": {
+			map[string]interface{}{"name": "ambient-code-runner", "image": "busybox"},
+		},
+		"duplicate name": {
+			map[string]interface{}{"name": "proxy", "image": "busybox"},
+			map[string]interface{}{"name": "proxy", "image": "busybox"},
+		},
+		"missing image": {
+			map[string]interface{}{"name": "proxy"},
+		},
+	}
+	for name, entries := range cases {
+		t.Run(name, func(t *testing.T) {
+			_, err := buildRunnerSidecars(map[string]interface{}{"runnerSidecars": entries}, corev1.PullIfNotPresent)
+			if err == nil {
+				t.Errorf("expected error")
+			}
+		})
+	}
+}
+
+// TestInjectRunnerSidecars_WiresRunner verifies the shared volume reaches the runner container
+func TestInjectRunnerSidecars_WiresRunner(t *testing.T) {
+	podSpec := corev1.PodSpec{
+		Containers: []corev1.Container{{Name: "ambient-content"}, {Name: "ambient-code-runner"}},
+	}
+	injectRunnerSidecars(&podSpec, []corev1.Container{{Name: "proxy"}})
+
+	if len(podSpec.InitContainers) != 1 || podSpec.InitContainers[0].Name != "proxy" {
+		t.Fatalf("expected proxy init container, got %v", podSpec.InitContainers)
+	}
+	if len(podSpec.Volumes) != 1 || podSpec.Volumes[0].Name != sidecarSharedVolumeName {
+		t.Fatalf("expected shared volume, got %v", podSpec.Volumes)
+	}
+	if len(podSpec.Containers[0].VolumeMounts) != 0 {
+		t.Errorf("content container should not get the shared mount")
+	}
+	if len(podSpec.Containers[1].VolumeMounts) != 1 {
+		t.Errorf("runner container should get the shared mount")
+	}
+}
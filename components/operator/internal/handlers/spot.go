@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"ambient-code-operator/internal/config"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	// spotFallbackAnnotation is set once a spot session has been preempted. From then on
+	// the runner is scheduled away from spot capacity for the rest of the session.
+	spotFallbackAnnotation = "ambient-code.io/spot-fallback"
+
+	// resubmitRunAnnotation asks the backend to replay the run that was in flight when
+	// the runner was preempted. The backend clears it after resubmitting.
+	// IMPORTANT: Keep in sync with backend (websocket/run_recovery.go)
+	resubmitRunAnnotation = "ambient-code.io/resubmit-interrupted-run"
+)
+
+// isSpotSession reports whether the session asked for spot capacity and has not yet
+// fallen back to on-demand nodes.
+func isSpotSession(session *unstructured.Unstructured) bool {
+	spot, _, _ := unstructured.NestedBool(session.Object, "spec", "spot")
+	return spot && session.GetAnnotations()[spotFallbackAnnotation] != "true"
+}
+
+// applySpotScheduling pins the runner to spot nodes (tolerating the spot taint when
+// configured) or, after a preemption, steers it away from them.
+func applySpotScheduling(podSpec *corev1.PodSpec, session *unstructured.Unstructured, appConfig *config.Config) {
+	spot, _, _ := unstructured.NestedBool(session.Object, "spec", "spot")
+	if !spot || appConfig.SpotNodeLabelKey == "" {
+		return
+	}
+
+	operator := corev1.NodeSelectorOpIn
+	if !isSpotSession(session) {
+		operator = corev1.NodeSelectorOpNotIn
+	}
+	podSpec.Affinity = &corev1.Affinity{
+		NodeAffinity: &corev1.NodeAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+				NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+					MatchExpressions: []corev1.NodeSelectorRequirement{{
+						Key:      appConfig.SpotNodeLabelKey,
+						Operator: operator,
+						Values:   []string{appConfig.SpotNodeLabelValue},
+					}},
+				}},
+			},
+		},
+	}
+
+	if operator == corev1.NodeSelectorOpIn && appConfig.SpotTaintKey != "" {
+		podSpec.Tolerations = append(podSpec.Tolerations, corev1.Toleration{
+			Key:      appConfig.SpotTaintKey,
+			Operator: corev1.TolerationOpExists,
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+
+	"ambient-code-operator/internal/config"
+	"ambient-code-operator/internal/types"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+func spotTestSession(spot bool, annotations map[string]string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{"spot": spot},
+	}}
+	obj.SetAnnotations(annotations)
+	return obj
+}
+
+// TestApplySpotScheduling_PinsToSpot verifies spot sessions require spot nodes and tolerate the taint
+func TestApplySpotScheduling_PinsToSpot(t *testing.T) {
+	cfg := &config.Config{SpotNodeLabelKey: "karpenter.sh/capacity-type", SpotNodeLabelValue: "spot", SpotTaintKey: "spot"}
+	podSpec := corev1.PodSpec{}
+	applySpotScheduling(&podSpec, spotTestSession(true, nil), cfg)
+
+	if podSpec.Affinity == nil || podSpec.Affinity.NodeAffinity == nil {
+		t.Fatalf("expected node affinity to be set")
+	}
+	req := podSpec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms[0].MatchExpressions[0]
+	if req.Operator != corev1.NodeSelectorOpIn || req.Values[0] != "spot" {
+		t.Errorf("expected In spot, got %v %v", req.Operator, req.Values)
+	}
+	if len(podSpec.Tolerations) != 1 || podSpec.Tolerations[0].Key != "spot" {
+		t.Errorf("expected spot toleration, got %v", podSpec.Tolerations)
+	}
+}
+
+// TestApplySpotScheduling_FallsBack verifies preempted sessions avoid spot nodes
+func TestApplySpotScheduling_FallsBack(t *testing.T) {
+	cfg := &config.Config{SpotNodeLabelKey: "karpenter.sh/capacity-type", SpotNodeLabelValue: "spot", SpotTaintKey: "spot"}
+	podSpec := corev1.PodSpec{}
+	applySpotScheduling(&podSpec, spotTestSession(true, map[string]string{spotFallbackAnnotation: "true"}), cfg)
+
+	req := podSpec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms[0].MatchExpressions[0]
+	if req.Operator != corev1.NodeSelectorOpNotIn {
+		t.Errorf("expected NotIn after fallback, got %v", req.Operator)
+	}
+	if len(podSpec.Tolerations) != 0 {
+		t.Errorf("expected no spot toleration after fallback, got %v", podSpec.Tolerations)
+	}
+}
+
+// TestApplySpotScheduling_NonSpot verifies regular sessions are left untouched
+func TestApplySpotScheduling_NonSpot(t *testing.T) {
+	cfg := &config.Config{SpotNodeLabelKey: "karpenter.sh/capacity-type", SpotNodeLabelValue: "spot"}
+	podSpec := corev1.PodSpec{}
+	applySpotScheduling(&podSpec, spotTestSession(false, nil), cfg)
+	if podSpec.Affinity != nil {
+		t.Errorf("expected no affinity for non-spot session")
+	}
+}
+
+// TestRecoverLostSpotRunner_OnlySetsFallback verifies a lost spot runner gets the
+// fallback and resubmit annotations without its other annotations being rewritten
+func TestRecoverLostSpotRunner_OnlySetsFallback(t *testing.T) {
+	stored := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "vteam.ambient-code/v1alpha1",
+		"kind":       "AgenticSession",
+		"metadata":   map[string]interface{}{"name": "s1", "namespace": "team-a"},
+		"spec":       map[string]interface{}{"spot": true},
+	}}
+	stored.SetAnnotations(map[string]string{"ambient-code.io/desired-phase": "Stopped", "team": "a"})
+	origDynamic := config.DynamicClient
+	defer func() { config.DynamicClient = origDynamic }()
+	config.DynamicClient = dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), stored.DeepCopy())
+
+	// The reconciler's copy predates the stop request
+	session := stored.DeepCopy()
+	session.SetAnnotations(map[string]string{"team": "a"})
+	if err := RecoverLostSpotRunner(context.Background(), session); err != nil {
+		t.Fatalf("RecoverLostSpotRunner: %v", err)
+	}
+
+	got, err := config.DynamicClient.Resource(types.GetAgenticSessionResource()).Namespace("team-a").Get(context.Background(), "s1", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("get session: %v", err)
+	}
+	want := map[string]string{
+		"ambient-code.io/desired-phase": "Stopped",
+		"team":                          "a",
+		spotFallbackAnnotation:          "true",
+		resubmitRunAnnotation:           "true",
+	}
+	for k, v := range want {
+		if got.GetAnnotations()[k] != v {
+			t.Errorf("annotation %s = %q, want %q", k, got.GetAnnotations()[k], v)
+		}
+	}
+}
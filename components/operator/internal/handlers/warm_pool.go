@@ -0,0 +1,559 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"ambient-code-operator/internal/config"
+	"ambient-code-operator/internal/types"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	utilrand "k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// warmPoolLabel marks idle pre-provisioned runner pods; claiming a pod removes it
+	warmPoolLabel = "ambient-code.io/warm-pool"
+	// warmRunnerApp is the app label of idle pods. Claimed pods become "ambient-code-runner",
+	// so idle pods are never counted as active sessions.
+	warmRunnerApp = "ambient-code-warm-runner"
+	// warmPoolTemplateAnnotation is a hash of the idle pod's spec; pods built from older
+	// project settings or images are replaced
+	warmPoolTemplateAnnotation = "ambient-code.io/warm-pool-template"
+	// runnerPodAnnotation names the runner pod of a session that claimed a warm pool pod
+	runnerPodAnnotation = "ambient-code.io/runner-pod"
+	// maxWarmPoolSize caps spec.warmPool.size; idle pods hold runner resources
+	maxWarmPoolSize = 10
+	// warmSessionNameFile is written by the runner when claimed; state-sync waits for it
+	warmSessionNameFile = "/workspace/.ambient/session-name"
+)
+
+// warmRunnerClaimURL returns the claim endpoint of an idle runner pod
+var warmRunnerClaimURL = func(pod *corev1.Pod) string {
+	return fmt.Sprintf("http://%s:8001/claim", pod.Status.PodIP)
+}
+
+// RunnerPodName returns the session's runner pod: "<session>-runner", or the warm pool
+// pod the session claimed
+func RunnerPodName(session *unstructured.Unstructured) string {
+	if pod := session.GetAnnotations()[runnerPodAnnotation]; pod != "" {
+		return pod
+	}
+	return fmt.Sprintf("%s-runner", session.GetName())
+}
+
+// warmPoolSize returns ProjectSettings spec.warmPool.size, capped at maxWarmPoolSize
+func warmPoolSize(projectSettings map[string]interface{}) int {
+	size, _, _ := unstructured.NestedInt64(projectSettings, "warmPool", "size")
+	if size < 0 {
+		return 0
+	}
+	if size > maxWarmPoolSize {
+		return maxWarmPoolSize
+	}
+	return int(size)
+}
+
+// reconcileWarmPool keeps spec.warmPool.size idle runner pods in the project. Idle pods
+// that exited, or were built from older settings or images, are replaced.
+func reconcileWarmPool(ctx context.Context, client kubernetes.Interface, appConfig *config.Config, settings *unstructured.Unstructured) error {
+	namespace := settings.GetNamespace()
+	spec, _, _ := unstructured.NestedMap(settings.Object, "spec")
+	size := warmPoolSize(spec)
+
+	pods, err := client.CoreV1().Pods(namespace).List(ctx, v1.ListOptions{LabelSelector: warmPoolLabel + "=idle"})
+	if err != nil {
+		return fmt.Errorf("list warm pool pods: %w", err)
+	}
+
+	var desired *corev1.Pod
+	if size > 0 {
+		if desired, err = buildWarmRunnerPod(namespace, appConfig, settings); err != nil {
+			return err
+		}
+	}
+
+	idle := 0
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		stale := desired == nil || pod.Status.Phase == corev1.PodFailed || pod.Status.Phase == corev1.PodSucceeded ||
+			pod.Annotations[warmPoolTemplateAnnotation] != desired.Annotations[warmPoolTemplateAnnotation]
+		if !stale && idle < size {
+			idle++
+			continue
+		}
+		if err := client.CoreV1().Pods(namespace).Delete(ctx, pod.Name, v1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+			return fmt.Errorf("delete warm pool pod %s: %w", pod.Name, err)
+		}
+		log.Printf("Removed warm pool pod %s/%s", namespace, pod.Name)
+	}
+
+	for ; idle < size; idle++ {
+		pod := desired.DeepCopy()
+		pod.Name = fmt.Sprintf("ambient-warm-%s-runner", utilrand.String(5))
+		if _, err := client.CoreV1().Pods(namespace).Create(ctx, pod, v1.CreateOptions{}); err != nil {
+			return fmt.Errorf("create warm pool pod: %w", err)
+		}
+		log.Printf("Created warm pool pod %s/%s", namespace, pod.Name)
+	}
+	return nil
+}
+
+// replenishWarmPool tops up the project's warm pool after a claim
+func replenishWarmPool(namespace string) {
+	settings, err := config.DynamicClient.Resource(types.GetProjectSettingsResource()).Namespace(namespace).Get(context.TODO(), "projectsettings", v1.GetOptions{})
+	if err != nil {
+		log.Printf("Warm pool: failed to read ProjectSettings in %s: %v", namespace, err)
+		return
+	}
+	if err := reconcileWarmPool(context.TODO(), config.K8sClient, config.LoadConfig(), settings); err != nil {
+		log.Printf("Warm pool: failed to replenish %s: %v", namespace, err)
+	}
+}
+
+// buildWarmRunnerPod returns an idle runner pod with the project-wide parts of a session
+// pod: images, sidecars, S3 state sync and the shared workspace. Everything that
+// identifies a session (name, token, credentials, repos, prompt) is sent at claim time.
+func buildWarmRunnerPod(namespace string, appConfig *config.Config, settings *unstructured.Unstructured) (*corev1.Pod, error) {
+	spec, _, _ := unstructured.NestedMap(settings.Object, "spec")
+	sidecars, err := buildRunnerSidecars(spec, appConfig.ImagePullPolicy)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sidecar configuration: %w", err)
+	}
+	s3Endpoint, s3Bucket, s3AccessKey, s3SecretKey, err := getS3ConfigForProject(namespace, appConfig)
+	if err != nil {
+		s3Endpoint, s3Bucket, s3AccessKey, s3SecretKey = "", "", "", ""
+	}
+
+	securityContext := &corev1.SecurityContext{
+		AllowPrivilegeEscalation: boolPtr(false),
+		ReadOnlyRootFilesystem:   boolPtr(false),
+		Capabilities:             &corev1.Capabilities{Drop: []corev1.Capability{"ALL"}},
+	}
+	workspaceMounts := []corev1.VolumeMount{
+		{Name: "workspace", MountPath: "/workspace"},
+		{Name: "workspace", MountPath: "/app/.claude", SubPath: ".claude"},
+	}
+	podSpec := corev1.PodSpec{
+		RestartPolicy:                 corev1.RestartPolicyNever,
+		TerminationGracePeriodSeconds: int64Ptr(30),
+		AutomountServiceAccountToken:  boolPtr(false),
+		Volumes: []corev1.Volume{
+			{Name: "workspace", VolumeSource: workspaceVolumeSource(nil, spec)},
+			{
+				Name: "mcp-servers",
+				VolumeSource: corev1.VolumeSource{
+					ConfigMap: &corev1.ConfigMapVolumeSource{
+						LocalObjectReference: corev1.LocalObjectReference{Name: types.ProjectMCPServersConfigMap},
+						Optional:             boolPtr(true),
+					},
+				},
+			},
+		},
+		// Without a session or repos, hydrate only lays out the workspace
+		InitContainers: []corev1.Container{{
+			Name:            "init-hydrate",
+			Image:           appConfig.StateSyncImage,
+			ImagePullPolicy: appConfig.ImagePullPolicy,
+			Command:         []string{"/usr/local/bin/hydrate.sh"},
+			SecurityContext: securityContext,
+			Env:             []corev1.EnvVar{{Name: "NAMESPACE", Value: namespace}},
+			VolumeMounts:    workspaceMounts,
+		}},
+		Containers: []corev1.Container{
+			{
+				Name:            "ambient-content",
+				Image:           appConfig.ContentServiceImage,
+				ImagePullPolicy: appConfig.ImagePullPolicy,
+				Env: []corev1.EnvVar{
+					{Name: "CONTENT_SERVICE_MODE", Value: "true"},
+					{Name: "STATE_BASE_DIR", Value: "/workspace"},
+				},
+				EnvFrom: []corev1.EnvFromSource{{
+					SecretRef: &corev1.SecretEnvSource{
+						LocalObjectReference: corev1.LocalObjectReference{Name: "ambient-non-vertex-integrations"},
+						Optional:             boolPtr(true),
+					},
+				}},
+				Ports: []corev1.ContainerPort{{ContainerPort: 8080, Name: "http"}},
+				ReadinessProbe: &corev1.Probe{
+					ProbeHandler:        corev1.ProbeHandler{HTTPGet: &corev1.HTTPGetAction{Path: "/health", Port: intstr.FromString("http")}},
+					InitialDelaySeconds: 5,
+					PeriodSeconds:       5,
+				},
+				VolumeMounts: []corev1.VolumeMount{{Name: "workspace", MountPath: "/workspace"}},
+			},
+			{
+				Name:            "ambient-code-runner",
+				Image:           appConfig.AmbientCodeRunnerImage,
+				ImagePullPolicy: appConfig.ImagePullPolicy,
+				SecurityContext: securityContext,
+				Ports:           []corev1.ContainerPort{{Name: "agui", ContainerPort: 8001, Protocol: corev1.ProtocolTCP}},
+				// Only claim runners whose server is up
+				ReadinessProbe: &corev1.Probe{
+					ProbeHandler:  corev1.ProbeHandler{HTTPGet: &corev1.HTTPGetAction{Path: "/health", Port: intstr.FromString("agui")}},
+					PeriodSeconds: 5,
+				},
+				VolumeMounts: append(append([]corev1.VolumeMount{}, workspaceMounts...),
+					corev1.VolumeMount{Name: "mcp-servers", MountPath: projectMCPConfigDir, ReadOnly: true}),
+				Lifecycle: &corev1.Lifecycle{
+					PostStart: &corev1.LifecycleHandler{
+						Exec: &corev1.ExecAction{
+							Command: []string{"/bin/sh", "-c",
+								"mkdir -p /workspace/.google_workspace_mcp/credentials && " +
+									"cp -f /app/.google_workspace_mcp/credentials/* /workspace/.google_workspace_mcp/credentials/ 2>/dev/null || true"},
+						},
+					},
+				},
+				Env: []corev1.EnvVar{
+					{Name: "WARM_POOL", Value: "true"},
+					{Name: "SESSION_NAME_FILE", Value: warmSessionNameFile},
+					{Name: "PROJECT_NAME", Value: namespace},
+					{Name: "AGENTIC_SESSION_NAMESPACE", Value: namespace},
+					{Name: "WORKSPACE_PATH", Value: "/workspace"},
+					{Name: "AGUI_PORT", Value: "8001"},
+				},
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse("500m"),
+						corev1.ResourceMemory: resource.MustParse("512Mi"),
+					},
+					Limits: corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse("2000m"),
+						corev1.ResourceMemory: resource.MustParse("4Gi"),
+					},
+				},
+			},
+			{
+				Name:            "state-sync",
+				Image:           appConfig.StateSyncImage,
+				ImagePullPolicy: appConfig.ImagePullPolicy,
+				Command:         []string{"/usr/local/bin/sync.sh"},
+				SecurityContext: securityContext,
+				Env: []corev1.EnvVar{
+					// Syncing starts once the runner is claimed and writes the session name
+					{Name: "SESSION_NAME_FILE", Value: warmSessionNameFile},
+					{Name: "NAMESPACE", Value: namespace},
+					{Name: "S3_ENDPOINT", Value: s3Endpoint},
+					{Name: "S3_BUCKET", Value: s3Bucket},
+					{Name: "SYNC_INTERVAL", Value: "60"},
+					{Name: "MAX_SYNC_SIZE", Value: "1073741824"},
+					{Name: "AWS_ACCESS_KEY_ID", Value: s3AccessKey},
+					{Name: "AWS_SECRET_ACCESS_KEY", Value: s3SecretKey},
+				},
+				VolumeMounts: workspaceMounts,
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse("100m"),
+						corev1.ResourceMemory: resource.MustParse("128Mi"),
+					},
+					Limits: corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse("1000m"),
+						corev1.ResourceMemory: resource.MustParse("1Gi"),
+					},
+				},
+			},
+		},
+	}
+	injectRunnerSidecars(&podSpec, sidecars)
+	if sharedWorkspaceEnabled(spec) {
+		addSharedWorkspace(&podSpec)
+	}
+	if appConfig.PodFSGroup != nil {
+		podSpec.SecurityContext = &corev1.PodSecurityContext{
+			FSGroup:             appConfig.PodFSGroup,
+			FSGroupChangePolicy: func() *corev1.PodFSGroupChangePolicy { p := corev1.FSGroupChangeOnRootMismatch; return &p }(),
+		}
+	}
+
+	template, err := json.Marshal(podSpec)
+	if err != nil {
+		return nil, err
+	}
+	hash := sha256.Sum256(template)
+	return &corev1.Pod{
+		ObjectMeta: v1.ObjectMeta{
+			Namespace: namespace,
+			Labels:    map[string]string{"app": warmRunnerApp, warmPoolLabel: "idle"},
+			Annotations: map[string]string{
+				warmPoolTemplateAnnotation: hex.EncodeToString(hash[:8]),
+			},
+			OwnerReferences: []v1.OwnerReference{{
+				APIVersion: settings.GetAPIVersion(),
+				Kind:       settings.GetKind(),
+				Name:       settings.GetName(),
+				UID:        settings.GetUID(),
+			}},
+		},
+		Spec: podSpec,
+	}, nil
+}
+
+// warmPoolIneligible returns why the session pod the operator would create can't be
+// served by a warm pool pod, or "" if it can. Idle pods only differ from session pods in
+// the environment, so anything else the session needs rules the pool out.
+func warmPoolIneligible(runner *runnerCluster, spec map[string]interface{}, pod *corev1.Pod, runnerImage string) string {
+	if runner.remote() {
+		return "remote runner cluster"
+	}
+	if interactive, _, _ := unstructured.NestedBool(spec, "interactive"); !interactive {
+		return "not interactive"
+	}
+	repos, _, _ := unstructured.NestedSlice(spec, "repos")
+	for _, r := range repos {
+		repo, _ := r.(map[string]interface{})
+		for _, option := range []string{"depth", "sparsePaths", "lfs", "singleBranch"} {
+			if _, set := repo[option]; set {
+				return "repo clone options"
+			}
+		}
+	}
+	if pod.Spec.NodeSelector != nil || pod.Spec.Affinity != nil || len(pod.Spec.Tolerations) > 0 {
+		return "node placement"
+	}
+	for _, vol := range pod.Spec.Volumes {
+		if vol.Name == "workspace" && vol.EmptyDir == nil {
+			return "sized workspace"
+		}
+		if vol.Name == "vertex" {
+			return "Vertex AI credentials"
+		}
+	}
+	for _, c := range pod.Spec.Containers {
+		if c.Name != "ambient-code-runner" {
+			continue
+		}
+		if c.Image != runnerImage {
+			return "custom runner image"
+		}
+		for _, env := range c.Env {
+			if env.Name == "IS_RESUME" || env.Name == "PARENT_SESSION_ID" {
+				return "resumed session"
+			}
+		}
+	}
+	return ""
+}
+
+// containerNames lists a pod's init and regular containers, sorted
+func containerNames(spec *corev1.PodSpec) string {
+	names := []string{}
+	for _, c := range append(append([]corev1.Container{}, spec.InitContainers...), spec.Containers...) {
+		names = append(names, c.Name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ",")
+}
+
+// claimWarmRunner hands an idle warm pool pod to the session instead of creating pod.
+// The pod is relabeled so the session's Services select it and re-owned by the session,
+// then the runner receives the environment pod would have had, with secret references
+// resolved. Returns nil, and the caller creates pod, when the session isn't eligible or
+// no idle pod could be claimed.
+func claimWarmRunner(ctx context.Context, runner *runnerCluster, session *unstructured.Unstructured, pod *corev1.Pod, projectSettings map[string]interface{}, runnerImage string) *corev1.Pod {
+	namespace, name := session.GetNamespace(), session.GetName()
+	if warmPoolSize(projectSettings) == 0 {
+		return nil
+	}
+	spec, _, _ := unstructured.NestedMap(session.Object, "spec")
+	if reason := warmPoolIneligible(runner, spec, pod, runnerImage); reason != "" {
+		log.Printf("Warm pool: session %s/%s needs a new runner pod (%s)", namespace, name, reason)
+		return nil
+	}
+
+	idle, err := runner.client.CoreV1().Pods(namespace).List(ctx, v1.ListOptions{LabelSelector: warmPoolLabel + "=idle"})
+	if err != nil {
+		log.Printf("Warm pool: failed to list idle pods in %s: %v", namespace, err)
+		return nil
+	}
+	var env map[string]string
+	for i := range idle.Items {
+		candidate := &idle.Items[i]
+		if !podReady(candidate) || candidate.DeletionTimestamp != nil || containerNames(&candidate.Spec) != containerNames(&pod.Spec) {
+			continue
+		}
+		if env == nil {
+			if env, err = claimEnv(ctx, runner.client, namespace, pod); err != nil {
+				log.Printf("Warm pool: session %s/%s needs a new runner pod: %v", namespace, name, err)
+				return nil
+			}
+		}
+
+		claimed, err := relabelWarmRunner(ctx, runner.client, candidate, pod)
+		if err != nil {
+			// Most likely claimed concurrently by another session
+			log.Printf("Warm pool: could not claim %s/%s: %v", namespace, candidate.Name, err)
+			continue
+		}
+		if err := sendClaim(ctx, claimed, env); err != nil {
+			log.Printf("Warm pool: runner %s/%s rejected the claim: %v", namespace, claimed.Name, err)
+			_ = runner.client.CoreV1().Pods(namespace).Delete(ctx, claimed.Name, v1.DeleteOptions{})
+			continue
+		}
+
+		annotations := map[string]string{}
+		for k, v := range session.GetAnnotations() {
+			annotations[k] = v
+		}
+		annotations[runnerPodAnnotation] = claimed.Name
+		if err := updateAnnotations(namespace, name, annotations); err != nil {
+			log.Printf("Warm pool: failed to record runner pod on %s/%s: %v", namespace, name, err)
+			_ = runner.client.CoreV1().Pods(namespace).Delete(ctx, claimed.Name, v1.DeleteOptions{})
+			return nil
+		}
+		session.SetAnnotations(annotations)
+		log.Printf("Warm pool: session %s/%s claimed runner pod %s", namespace, name, claimed.Name)
+		recordSessionEvent(session, corev1.EventTypeNormal, eventReasonWarmRunnerClaimed, "Claimed warm pool runner pod %s", claimed.Name)
+		go replenishWarmPool(namespace)
+		return claimed
+	}
+	log.Printf("Warm pool: no idle runner pod available for %s/%s", namespace, name)
+	return nil
+}
+
+func podReady(pod *corev1.Pod) bool {
+	if pod.Status.Phase != corev1.PodRunning {
+		return false
+	}
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == corev1.PodReady {
+			return cond.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// relabelWarmRunner gives an idle pod the labels and owner of the pod it replaces. The
+// resourceVersion precondition makes concurrent claims of the same pod fail.
+func relabelWarmRunner(ctx context.Context, client kubernetes.Interface, idle, pod *corev1.Pod) (*corev1.Pod, error) {
+	labels := map[string]interface{}{warmPoolLabel: nil}
+	for k, v := range pod.Labels {
+		labels[k] = v
+	}
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"resourceVersion": idle.ResourceVersion,
+			"labels":          labels,
+			"ownerReferences": pod.OwnerReferences,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return client.CoreV1().Pods(idle.Namespace).Patch(ctx, idle.Name, k8stypes.MergePatchType, patch, v1.PatchOptions{})
+}
+
+// claimEnv flattens the runner container environment of pod, reading referenced
+// secrets, into what the claimed runner applies to its process environment
+func claimEnv(ctx context.Context, client kubernetes.Interface, namespace string, pod *corev1.Pod) (map[string]string, error) {
+	var runner *corev1.Container
+	for i := range pod.Spec.Containers {
+		if pod.Spec.Containers[i].Name == "ambient-code-runner" {
+			runner = &pod.Spec.Containers[i]
+		}
+	}
+	if runner == nil {
+		return nil, fmt.Errorf("pod has no runner container")
+	}
+
+	secrets := map[string]*corev1.Secret{}
+	getSecret := func(secretName string) (*corev1.Secret, error) {
+		if s, ok := secrets[secretName]; ok {
+			return s, nil
+		}
+		s, err := client.CoreV1().Secrets(namespace).Get(ctx, secretName, v1.GetOptions{})
+		if errors.IsNotFound(err) {
+			s, err = nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		secrets[secretName] = s
+		return s, nil
+	}
+
+	// Like the kubelet: envFrom first, explicit env overrides
+	env := map[string]string{}
+	for _, from := range runner.EnvFrom {
+		if from.SecretRef == nil {
+			return nil, fmt.Errorf("unsupported envFrom source")
+		}
+		s, err := getSecret(from.SecretRef.Name)
+		if err != nil {
+			return nil, err
+		}
+		if s == nil {
+			if from.SecretRef.Optional == nil || !*from.SecretRef.Optional {
+				return nil, fmt.Errorf("secret %s not found", from.SecretRef.Name)
+			}
+			continue
+		}
+		for k, v := range s.Data {
+			env[from.Prefix+k] = string(v)
+		}
+	}
+	for _, e := range runner.Env {
+		if e.ValueFrom == nil {
+			env[e.Name] = e.Value
+			continue
+		}
+		ref := e.ValueFrom.SecretKeyRef
+		if ref == nil {
+			return nil, fmt.Errorf("unsupported valueFrom for %s", e.Name)
+		}
+		s, err := getSecret(ref.Name)
+		if err != nil {
+			return nil, err
+		}
+		if s != nil {
+			if v, ok := s.Data[ref.Key]; ok {
+				env[e.Name] = string(v)
+				continue
+			}
+		}
+		if ref.Optional == nil || !*ref.Optional {
+			return nil, fmt.Errorf("secret key %s/%s not found", ref.Name, ref.Key)
+		}
+	}
+	return env, nil
+}
+
+// sendClaim hands the session environment to the idle runner
+func sendClaim(ctx context.Context, pod *corev1.Pod, env map[string]string) error {
+	body, err := json.Marshal(map[string]interface{}{"env": env})
+	if err != nil {
+		return err
+	}
+	reqCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	req, err := http.NewRequestWithContext(reqCtx, http.MethodPost, warmRunnerClaimURL(pod), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("runner returned %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,180 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"ambient-code-operator/internal/config"
+	"ambient-code-operator/internal/types"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func warmPoolSettings(size int64) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "vteam.ambient-code/v1alpha1",
+		"kind":       "ProjectSettings",
+		"metadata":   map[string]interface{}{"name": "projectsettings", "namespace": "team-a"},
+		"spec":       map[string]interface{}{"warmPool": map[string]interface{}{"size": size}},
+	}}
+}
+
+func listIdlePods(t *testing.T) []corev1.Pod {
+	t.Helper()
+	pods, err := config.K8sClient.CoreV1().Pods("team-a").List(context.Background(), metav1.ListOptions{LabelSelector: warmPoolLabel + "=idle"})
+	if err != nil {
+		t.Fatalf("list idle pods: %v", err)
+	}
+	return pods.Items
+}
+
+func TestReconcileWarmPool(t *testing.T) {
+	ctx := context.Background()
+	config.K8sClient = fake.NewSimpleClientset()
+	appConfig := &config.Config{AmbientCodeRunnerImage: "runner:v1", ContentServiceImage: "backend:v1", StateSyncImage: "sync:v1"}
+
+	if err := reconcileWarmPool(ctx, config.K8sClient, appConfig, warmPoolSettings(2)); err != nil {
+		t.Fatalf("reconcileWarmPool: %v", err)
+	}
+	pods := listIdlePods(t)
+	if len(pods) != 2 {
+		t.Fatalf("expected 2 idle pods, got %d", len(pods))
+	}
+	if pods[0].Labels["app"] != warmRunnerApp || pods[0].Labels["agentic-session"] != "" {
+		t.Errorf("idle pod labels = %v", pods[0].Labels)
+	}
+
+	// A failed idle pod is replaced
+	pods[0].Status.Phase = corev1.PodFailed
+	if _, err := config.K8sClient.CoreV1().Pods("team-a").UpdateStatus(ctx, &pods[0], metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := reconcileWarmPool(ctx, config.K8sClient, appConfig, warmPoolSettings(2)); err != nil {
+		t.Fatalf("reconcile after failure: %v", err)
+	}
+	pods = listIdlePods(t)
+	if len(pods) != 2 {
+		t.Fatalf("expected 2 idle pods after replacing the failed one, got %d", len(pods))
+	}
+	for _, pod := range pods {
+		if pod.Status.Phase == corev1.PodFailed {
+			t.Errorf("failed pod %s was kept", pod.Name)
+		}
+	}
+
+	// A new runner image replaces idle pods; shrinking the pool removes the excess
+	appConfig.AmbientCodeRunnerImage = "runner:v2"
+	if err := reconcileWarmPool(ctx, config.K8sClient, appConfig, warmPoolSettings(1)); err != nil {
+		t.Fatalf("reconcile with new image: %v", err)
+	}
+	pods = listIdlePods(t)
+	if len(pods) != 1 || pods[0].Spec.Containers[1].Image != "runner:v2" {
+		t.Fatalf("expected one runner:v2 idle pod, got %+v", pods)
+	}
+
+	if err := reconcileWarmPool(ctx, config.K8sClient, appConfig, warmPoolSettings(0)); err != nil {
+		t.Fatalf("reconcile disabled: %v", err)
+	}
+	if pods := listIdlePods(t); len(pods) != 0 {
+		t.Errorf("disabling the pool should remove idle pods, got %d", len(pods))
+	}
+}
+
+func TestClaimWarmRunner(t *testing.T) {
+	ctx := context.Background()
+	appConfig := &config.Config{AmbientCodeRunnerImage: "runner:v1", ContentServiceImage: "backend:v1", StateSyncImage: "sync:v1"}
+	idle, err := buildWarmRunnerPod("team-a", appConfig, warmPoolSettings(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	idle.Name = "ambient-warm-abcde-runner"
+	idle.Status = corev1.PodStatus{
+		Phase:      corev1.PodRunning,
+		PodIP:      "10.0.0.7",
+		Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+	}
+	config.K8sClient = fake.NewSimpleClientset(idle, &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "ambient-runner-token-s1", Namespace: "team-a"},
+		Data:       map[string][]byte{"k8s-token": []byte("bot-token")},
+	})
+
+	session := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "vteam.ambient-code/v1alpha1",
+		"kind":       "AgenticSession",
+		"metadata":   map[string]interface{}{"name": "s1", "namespace": "team-a", "uid": "uid-s1"},
+		"spec":       map[string]interface{}{"interactive": true},
+	}}
+	config.DynamicClient = dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), map[schema.GroupVersionResource]string{
+		types.GetAgenticSessionResource():  "AgenticSessionList",
+		types.GetProjectSettingsResource(): "ProjectSettingsList",
+	}, session.DeepCopy())
+
+	var claimedEnv map[string]string
+	runnerServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Env map[string]string `json:"env"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		claimedEnv = body.Env
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer runnerServer.Close()
+	originalClaimURL := warmRunnerClaimURL
+	warmRunnerClaimURL = func(*corev1.Pod) string { return runnerServer.URL + "/claim" }
+	defer func() { warmRunnerClaimURL = originalClaimURL }()
+
+	// The pod the operator would have created for the session
+	sessionPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            "s1-runner",
+			Namespace:       "team-a",
+			Labels:          map[string]string{"agentic-session": "s1", "app": "ambient-code-runner"},
+			OwnerReferences: []metav1.OwnerReference{{APIVersion: "vteam.ambient-code/v1alpha1", Kind: "AgenticSession", Name: "s1", UID: "uid-s1", Controller: boolPtr(true)}},
+		},
+		Spec: *idle.Spec.DeepCopy(),
+	}
+	sessionPod.Spec.Containers[1].Env = []corev1.EnvVar{
+		{Name: "SESSION_ID", Value: "s1"},
+		{Name: "BOT_TOKEN", ValueFrom: &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{
+			LocalObjectReference: corev1.LocalObjectReference{Name: "ambient-runner-token-s1"},
+			Key:                  "k8s-token",
+		}}},
+	}
+	settings := map[string]interface{}{"warmPool": map[string]interface{}{"size": int64(1)}}
+
+	// Non-interactive sessions get their own pod
+	batch := session.DeepCopy()
+	_ = unstructured.SetNestedField(batch.Object, false, "spec", "interactive")
+	if pod := claimWarmRunner(ctx, localCluster(), batch, sessionPod, settings, "runner:v1"); pod != nil {
+		t.Fatalf("non-interactive session should not claim a warm runner")
+	}
+
+	claimed := claimWarmRunner(ctx, localCluster(), session, sessionPod, settings, "runner:v1")
+	if claimed == nil {
+		t.Fatal("expected the idle pod to be claimed")
+	}
+	if claimed.Labels["agentic-session"] != "s1" || claimed.Labels["app"] != "ambient-code-runner" {
+		t.Errorf("claimed pod labels = %v", claimed.Labels)
+	}
+	if _, idle := claimed.Labels[warmPoolLabel]; idle {
+		t.Errorf("claimed pod still labeled idle")
+	}
+	if len(claimed.OwnerReferences) != 1 || claimed.OwnerReferences[0].Name != "s1" {
+		t.Errorf("claimed pod owners = %+v", claimed.OwnerReferences)
+	}
+	if claimedEnv["SESSION_ID"] != "s1" || claimedEnv["BOT_TOKEN"] != "bot-token" {
+		t.Errorf("claim env = %v", claimedEnv)
+	}
+	if got := RunnerPodName(session); got != "ambient-warm-abcde-runner" {
+		t.Errorf("RunnerPodName = %s", got)
+	}
+}
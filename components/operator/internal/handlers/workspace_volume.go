@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+)
+
+// defaultWorkspaceSizeLimit caps the EmptyDir workspace of sessions without spec.workspace.size
+const defaultWorkspaceSizeLimit = 10 * 1024 * 1024 * 1024 // 10Gi
+
+// workspacePVCName is the PVC Kubernetes creates for the generic ephemeral "workspace"
+// volume of pod "<session>-runner".
+// IMPORTANT: Keep in sync with backend (handlers/workspace_size.go)
+func workspacePVCName(sessionName string) string {
+	return sessionName + "-runner-workspace"
+}
+
+// resolveWorkspaceSize returns the session's spec.workspace.size, or nil for the default
+// EmptyDir workspace. Sizes are re-checked against the ProjectSettings workspace.maxSize
+// so CRs created outside the backend are held to the same rules.
+func resolveWorkspaceSize(spec, projectSettings map[string]interface{}) (*resource.Quantity, error) {
+	requested, _, _ := unstructured.NestedString(spec, "workspace", "size")
+	requested = strings.TrimSpace(requested)
+	if requested == "" {
+		return nil, nil
+	}
+	size, err := resource.ParseQuantity(requested)
+	if err != nil || size.Sign() <= 0 {
+		return nil, fmt.Errorf("invalid workspace size %q", requested)
+	}
+
+	maxSize, _, _ := unstructured.NestedString(projectSettings, "workspace", "maxSize")
+	if maxSize == "" {
+		return nil, fmt.Errorf("workspace sizing is not enabled for this project")
+	}
+	limit, err := resource.ParseQuantity(maxSize)
+	if err != nil {
+		return nil, fmt.Errorf("invalid project workspace.maxSize %q", maxSize)
+	}
+	if size.Cmp(limit) > 0 {
+		return nil, fmt.Errorf("workspace size %s exceeds the project maximum of %s", size.String(), limit.String())
+	}
+	return &size, nil
+}
+
+// workspaceVolumeSource returns the runner's workspace volume: an EmptyDir by default, or
+// a generic ephemeral PVC of the requested size in the project's workspace.storageClass.
+// The PVC is owned by the pod, so it's removed with it; state still persists through S3.
+func workspaceVolumeSource(size *resource.Quantity, projectSettings map[string]interface{}) corev1.VolumeSource {
+	if size == nil {
+		return corev1.VolumeSource{
+			EmptyDir: &corev1.EmptyDirVolumeSource{
+				SizeLimit: resource.NewQuantity(defaultWorkspaceSizeLimit, resource.BinarySI),
+			},
+		}
+	}
+
+	claim := corev1.PersistentVolumeClaimSpec{
+		AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+		Resources: corev1.VolumeResourceRequirements{
+			Requests: corev1.ResourceList{corev1.ResourceStorage: *size},
+		},
+	}
+	if storageClass, _, _ := unstructured.NestedString(projectSettings, "workspace", "storageClass"); storageClass != "" {
+		claim.StorageClassName = &storageClass
+	}
+	return corev1.VolumeSource{
+		Ephemeral: &corev1.EphemeralVolumeSource{
+			VolumeClaimTemplate: &corev1.PersistentVolumeClaimTemplate{
+				ObjectMeta: v1.ObjectMeta{Labels: map[string]string{"app": "ambient-workspace"}},
+				Spec:       claim,
+			},
+		},
+	}
+}
+
+// reconcileWorkspaceSize grows the running pod's workspace PVC to spec.workspace.size
+// after an expansion request. Shrinking is not possible and is ignored. Failures (e.g. a
+// storage class without allowVolumeExpansion) are reported as events and don't block
+// other spec reconciliation.
+func reconcileWorkspaceSize(runner *runnerCluster, namespace, sessionName string, spec map[string]interface{}, session *unstructured.Unstructured) {
+	requested, _, _ := unstructured.NestedString(spec, "workspace", "size")
+	if requested == "" {
+		return
+	}
+	size, err := resource.ParseQuantity(requested)
+	if err != nil {
+		return
+	}
+
+	pvcName := workspacePVCName(sessionName)
+	pvc, err := runner.client.CoreV1().PersistentVolumeClaims(namespace).Get(context.TODO(), pvcName, v1.GetOptions{})
+	if err != nil {
+		if !errors.IsNotFound(err) {
+			log.Printf("[Workspace] Failed to get PVC %s/%s: %v", namespace, pvcName, err)
+		}
+		return
+	}
+	current := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+	if size.Cmp(current) <= 0 {
+		return
+	}
+
+	patch, _ := json.Marshal(map[string]interface{}{
+		"spec": map[string]interface{}{
+			"resources": map[string]interface{}{
+				"requests": map[string]interface{}{string(corev1.ResourceStorage): size.String()},
+			},
+		},
+	})
+	if _, err := runner.client.CoreV1().PersistentVolumeClaims(namespace).Patch(context.TODO(), pvcName, k8stypes.MergePatchType, patch, v1.PatchOptions{}); err != nil {
+		log.Printf("[Workspace] Failed to expand PVC %s/%s to %s: %v", namespace, pvcName, size.String(), err)
+		recordSessionWarning(session, eventReasonWorkspaceResizeFailed, "Failed to expand workspace to %s: %v", size.String(), err)
+		return
+	}
+	log.Printf("[Workspace] Expanding PVC %s/%s from %s to %s", namespace, pvcName, current.String(), size.String())
+	recordSessionEvent(session, corev1.EventTypeNormal, eventReasonWorkspaceResized, "Expanding workspace from %s to %s", current.String(), size.String())
+}
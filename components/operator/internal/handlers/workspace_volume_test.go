@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestResolveWorkspaceSize(t *testing.T) {
+	settings := map[string]interface{}{
+		"workspace": map[string]interface{}{"maxSize": "50Gi", "storageClass": "expandable"},
+	}
+	sized := func(size string) map[string]interface{} {
+		return map[string]interface{}{"workspace": map[string]interface{}{"size": size}}
+	}
+
+	size, err := resolveWorkspaceSize(map[string]interface{}{}, settings)
+	if err != nil || size != nil {
+		t.Fatalf("unsized session: got %v, %v", size, err)
+	}
+	if source := workspaceVolumeSource(size, settings); source.EmptyDir == nil {
+		t.Errorf("unsized session should keep the EmptyDir workspace, got %+v", source)
+	}
+
+	size, err = resolveWorkspaceSize(sized("20Gi"), settings)
+	if err != nil || size.String() != "20Gi" {
+		t.Fatalf("20Gi: got %v, %v", size, err)
+	}
+	source := workspaceVolumeSource(size, settings)
+	if source.Ephemeral == nil {
+		t.Fatalf("sized session should get an ephemeral PVC, got %+v", source)
+	}
+	claim := source.Ephemeral.VolumeClaimTemplate.Spec
+	if got := claim.Resources.Requests[corev1.ResourceStorage]; got.String() != "20Gi" {
+		t.Errorf("requested storage = %s", got.String())
+	}
+	if claim.StorageClassName == nil || *claim.StorageClassName != "expandable" {
+		t.Errorf("storage class = %v", claim.StorageClassName)
+	}
+
+	for name, tc := range map[string]struct {
+		spec, settings map[string]interface{}
+	}{
+		"above maximum": {sized("100Gi"), settings},
+		"not enabled":   {sized("20Gi"), nil},
+		"invalid size":  {sized("lots"), settings},
+		"non-positive":  {sized("0"), settings},
+		"bad maximum":   {sized("20Gi"), map[string]interface{}{"workspace": map[string]interface{}{"maxSize": "big"}}},
+	} {
+		if _, err := resolveWorkspaceSize(tc.spec, tc.settings); err == nil {
+			t.Errorf("%s: expected an error", name)
+		}
+	}
+}
+
+func TestReconcileWorkspaceSize(t *testing.T) {
+	client := fake.NewSimpleClientset(&corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{Name: workspacePVCName("s1"), Namespace: "team-a"},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			Resources: corev1.VolumeResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceStorage: resource.MustParse("20Gi")},
+			},
+		},
+	})
+	runner := &runnerCluster{name: localRunnerCluster, client: client}
+	session := &unstructured.Unstructured{}
+	sized := func(size string) map[string]interface{} {
+		return map[string]interface{}{"workspace": map[string]interface{}{"size": size}}
+	}
+	requested := func() string {
+		pvc, err := client.CoreV1().PersistentVolumeClaims("team-a").Get(context.Background(), workspacePVCName("s1"), metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("get PVC: %v", err)
+		}
+		q := pvc.Spec.Resources.Requests[corev1.ResourceStorage]
+		return q.String()
+	}
+
+	reconcileWorkspaceSize(runner, "team-a", "s1", sized("40Gi"), session)
+	if got := requested(); got != "40Gi" {
+		t.Errorf("after expansion PVC requests %s, want 40Gi", got)
+	}
+
+	// Workspaces never shrink
+	reconcileWorkspaceSize(runner, "team-a", "s1", sized("10Gi"), session)
+	if got := requested(); got != "40Gi" {
+		t.Errorf("after shrink request PVC requests %s, want 40Gi", got)
+	}
+
+	// Sessions without a running pod have no PVC yet; nothing to do
+	reconcileWorkspaceSize(runner, "team-a", "s2", sized("40Gi"), session)
+}
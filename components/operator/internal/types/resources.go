@@ -7,6 +7,11 @@ const (
 	// AmbientVertexSecretName is the name of the secret containing Vertex AI credentials
 	AmbientVertexSecretName = "ambient-vertex"
 
+	// ProjectMCPServersConfigMap holds the project's MCP server registry (managed by the backend)
+	ProjectMCPServersConfigMap = "ambient-mcp-servers"
+	// ProjectMCPServersKey is the registry's data key in ProjectMCPServersConfigMap
+	ProjectMCPServersKey = "mcp-servers.json"
+
 	// CopiedFromAnnotation is the annotation key used to track secrets copied by the operator
 	CopiedFromAnnotation = "vteam.ambient-code/copied-from"
 )
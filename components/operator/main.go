@@ -148,10 +148,16 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Cache runner images on every node (RUNNER_IMAGE_PREPULL)
+	if err := handlers.ReconcileImagePrepull(context.Background(), appConfig); err != nil {
+		logger.Error(err, "Failed to reconcile runner image prepull, continuing without it")
+	}
+
 	// Start namespace and project settings watchers (these remain as watch loops for now)
 	// Note: These could be migrated to controller-runtime controllers in the future
 	go handlers.WatchNamespaces()
 	go handlers.WatchProjectSettings()
+	go handlers.WatchRunnerClusters()
 
 	logger.Info("Starting manager with controller-runtime",
 		"maxConcurrentReconciles", maxConcurrentReconciles,
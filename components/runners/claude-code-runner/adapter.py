@@ -48,8 +48,17 @@ import config as runner_config
 import prompts
 import workspace
 from context import RunnerContext
+from egress import create_egress_hook, load_egress_policy
+from fixtures import create_fixture_hook
+from subagents import SUB_AGENT_TOOLS, SubAgentRuns
 from tools import create_restart_session_tool, create_rubric_mcp_tool, load_rubric_content
-from utils import redact_secrets, run_cmd, url_with_token, parse_owner_repo
+from utils import (
+    classify_run_error,
+    parse_owner_repo,
+    redact_secrets,
+    run_cmd,
+    url_with_token,
+)
 from workspace import PrerequisiteError
 
 logger = logging.getLogger(__name__)
@@ -214,8 +223,24 @@ class ClaudeCodeAdapter:
             logger.info(
                 f"Starting Claude SDK with prompt: '{user_message[:50]}...'"
             )
+            # The backend picks the run's model from its fallback chain and
+            # resolves the session's persona
+            forwarded = input_data.forwarded_props
+            if not isinstance(forwarded, dict):
+                forwarded = {}
+            run_model = forwarded.get("model")
+            persona = forwarded.get("persona")
+            # Eval replays answer tool calls with recorded results
+            tool_fixtures = forwarded.get("toolFixtures")
             async for event in self._run_claude_agent_sdk(
-                user_message, thread_id, run_id
+                user_message,
+                thread_id,
+                run_id,
+                model_override=run_model if isinstance(run_model, str) else None,
+                persona=persona if isinstance(persona, dict) else None,
+                tool_fixtures=(
+                    tool_fixtures if isinstance(tool_fixtures, list) else None
+                ),
             ):
                 yield event
             logger.info(f"Claude SDK processing completed for run {run_id}")
@@ -246,6 +271,7 @@ class ClaudeCodeAdapter:
                 thread_id=thread_id,
                 run_id=run_id,
                 message=str(e),
+                code=classify_run_error(e),
             )
 
     def _extract_user_message(self, input_data: RunAgentInput) -> str:
@@ -281,7 +307,13 @@ class ClaudeCodeAdapter:
     # ------------------------------------------------------------------
 
     async def _run_claude_agent_sdk(
-        self, prompt: str, thread_id: str, run_id: str
+        self,
+        prompt: str,
+        thread_id: str,
+        run_id: str,
+        model_override: Optional[str] = None,
+        persona: Optional[dict] = None,
+        tool_fixtures: Optional[list] = None,
     ) -> AsyncIterator[BaseEvent]:
         """Execute the Claude Code SDK with the given prompt and yield AG-UI events."""
         current_message_id: Optional[str] = None
@@ -340,6 +372,7 @@ class ClaudeCodeAdapter:
                 AssistantMessage,
                 ClaudeAgentOptions,
                 ClaudeSDKClient,
+                HookMatcher,
                 ResultMessage,
                 SystemMessage,
                 TextBlock,
@@ -361,7 +394,7 @@ class ClaudeCodeAdapter:
                 raw_user_id, raw_user_name
             )
 
-            model = self.context.get_env("LLM_MODEL")
+            model = model_override or self.context.get_env("LLM_MODEL")
             configured_model = model or "claude-sonnet-4-5@20250929"
 
             if use_vertex and model:
@@ -419,6 +452,11 @@ class ClaudeCodeAdapter:
                 else {}
             )
 
+            # Project shared files (read-only, mounted by the operator)
+            shared_path = (os.getenv("SHARED_WORKSPACE_PATH") or "").strip()
+            if shared_path and Path(shared_path).is_dir():
+                add_dirs.append(shared_path)
+
             cwd_path_obj = Path(cwd_path)
             if not cwd_path_obj.exists():
                 logger.warning(
@@ -437,14 +475,24 @@ class ClaudeCodeAdapter:
             await auth.populate_runtime_credentials(self.context)
 
             # --- MCP servers ---
-            mcp_servers = (
-                runner_config.load_mcp_config(self.context, cwd_path) or {}
+            mcp_servers = runner_config.apply_persona_mcp_servers(
+                persona,
+                runner_config.load_mcp_config(self.context, cwd_path) or {},
+            )
+            oauth_servers = runner_config.load_project_mcp_oauth_servers(
+                self.context
+            )
+            missing_tokens = await auth.apply_mcp_oauth_tokens(
+                self.context, mcp_servers, oauth_servers
             )
 
             # Pre-flight check: Validate MCP server authentication
             from main import _check_mcp_authentication
 
-            mcp_auth_warnings = []
+            mcp_auth_warnings = [
+                f"⚠️  {name}: {oauth_servers[name]} account not connected"
+                for name in missing_tokens
+            ]
             if mcp_servers:
                 for server_name in mcp_servers.keys():
                     is_auth, msg = _check_mcp_authentication(server_name)
@@ -532,6 +580,24 @@ class ClaudeCodeAdapter:
                     f"{list(mcp_servers.keys())}"
                 )
 
+            # Persona tool set, narrowed further by project policy
+            allowed_tools = runner_config.apply_persona_tools(
+                persona, allowed_tools
+            )
+            if persona:
+                logger.info(f"Applied persona: {persona.get('name')}")
+
+            # Project MCP tool policy (block mode narrows permissions)
+            tool_policy = runner_config.load_mcp_tool_policy(self.context)
+            allowed_tools, disallowed_tools = (
+                runner_config.apply_mcp_tool_policy(tool_policy, allowed_tools)
+            )
+            if tool_policy:
+                logger.info(
+                    f"Applied MCP tool policy: mode={tool_policy.get('mode')} "
+                    f"enforcement={tool_policy.get('enforcement')}"
+                )
+
             # --- System prompt ---
             workspace_prompt = prompts.build_workspace_context_prompt(
                 repos_cfg=repos_cfg,
@@ -542,6 +608,9 @@ class ClaudeCodeAdapter:
                 ambient_config=ambient_config,
                 workspace_path=self.context.workspace_path,
             )
+            persona_prompt = (persona or {}).get("systemPrompt")
+            if persona_prompt:
+                workspace_prompt += "\n\n" + persona_prompt
             system_prompt_config = {
                 "type": "preset",
                 "preset": "claude_code",
@@ -557,6 +626,7 @@ class ClaudeCodeAdapter:
                 cwd=cwd_path,
                 permission_mode="acceptEdits",
                 allowed_tools=allowed_tools,
+                disallowed_tools=disallowed_tools,
                 mcp_servers=mcp_servers,
                 setting_sources=["project"],
                 system_prompt=system_prompt_config,
@@ -564,6 +634,28 @@ class ClaudeCodeAdapter:
                 stderr=sdk_stderr_handler,
             )
 
+            pre_tool_hooks = []
+            egress_policy = load_egress_policy(
+                self.context.get_env("EGRESS_POLICY")
+            )
+            if egress_policy and egress_policy.get("enforcement") == "block":
+                pre_tool_hooks.append(create_egress_hook(egress_policy))
+                logger.info(
+                    f"Enforcing egress allowlist: "
+                    f"{egress_policy.get('allowedHosts') or []}"
+                )
+            if tool_fixtures is not None:
+                pre_tool_hooks.append(create_fixture_hook(tool_fixtures))
+                logger.info(
+                    f"Replaying with {len(tool_fixtures)} tool fixtures"
+                )
+            if pre_tool_hooks:
+                options.hooks = {
+                    "PreToolUse": [
+                        HookMatcher(matcher=None, hooks=pre_tool_hooks)
+                    ]
+                }
+
             if self._skip_resume_on_restart:
                 self._skip_resume_on_restart = False
 
@@ -685,6 +777,7 @@ class ClaudeCodeAdapter:
                     "Starting to consume receive_response() iterator..."
                 )
                 message_count = 0
+                sub_agents = SubAgentRuns(run_id)
 
                 async for message in client.receive_response():
                     message_count += 1
@@ -697,13 +790,16 @@ class ClaudeCodeAdapter:
                     if isinstance(message, StreamEvent):
                         event_data = message.event
                         event_type = event_data.get("type")
+                        stream_run_id = sub_agents.run_id_for(
+                            getattr(message, "parent_tool_use_id", None)
+                        )
 
                         if event_type == "message_start":
                             current_message_id = str(uuid.uuid4())
                             yield TextMessageStartEvent(
                                 type=EventType.TEXT_MESSAGE_START,
                                 thread_id=thread_id,
-                                run_id=run_id,
+                                run_id=stream_run_id,
                                 message_id=current_message_id,
                                 role="assistant",
                             )
@@ -716,7 +812,7 @@ class ClaudeCodeAdapter:
                                     yield TextMessageContentEvent(
                                         type=EventType.TEXT_MESSAGE_CONTENT,
                                         thread_id=thread_id,
-                                        run_id=run_id,
+                                        run_id=stream_run_id,
                                         message_id=current_message_id,
                                         delta=text_chunk,
                                     )
@@ -733,6 +829,10 @@ class ClaudeCodeAdapter:
                             )
 
                     if isinstance(message, (AssistantMessage, UserMessage)):
+                        # Sub-agent messages stream as the sub-agent's run
+                        msg_run_id = sub_agents.run_id_for(
+                            getattr(message, "parent_tool_use_id", None)
+                        )
                         if isinstance(message, AssistantMessage):
                             current_message = message
                             obs.start_turn(
@@ -744,7 +844,7 @@ class ClaudeCodeAdapter:
                                 yield RawEvent(
                                     type=EventType.RAW,
                                     thread_id=thread_id,
-                                    run_id=run_id,
+                                    run_id=msg_run_id,
                                     event={
                                         "type": "langfuse_trace",
                                         "traceId": trace_id,
@@ -785,7 +885,7 @@ class ClaudeCodeAdapter:
                                 yield ToolCallStartEvent(
                                     type=EventType.TOOL_CALL_START,
                                     thread_id=thread_id,
-                                    run_id=run_id,
+                                    run_id=msg_run_id,
                                     tool_call_id=tool_id,
                                     tool_call_name=tool_name,
                                     parent_tool_call_id=parent_tool_use_id,
@@ -796,7 +896,7 @@ class ClaudeCodeAdapter:
                                     yield ToolCallArgsEvent(
                                         type=EventType.TOOL_CALL_ARGS,
                                         thread_id=thread_id,
-                                        run_id=run_id,
+                                        run_id=msg_run_id,
                                         tool_call_id=tool_id,
                                         delta=args_json,
                                     )
@@ -805,6 +905,11 @@ class ClaudeCodeAdapter:
                                     tool_name, tool_id, tool_input
                                 )
 
+                                if tool_name in SUB_AGENT_TOOLS:
+                                    await sub_agents.start(
+                                        tool_id, tool_input, msg_run_id
+                                    )
+
                             elif isinstance(block, ToolResultBlock):
                                 tool_use_id = getattr(
                                     block, "tool_use_id", None
@@ -832,7 +937,7 @@ class ClaudeCodeAdapter:
                                     yield ToolCallEndEvent(
                                         type=EventType.TOOL_CALL_END,
                                         thread_id=thread_id,
-                                        run_id=run_id,
+                                        run_id=msg_run_id,
                                         tool_call_id=tool_use_id,
                                         result=(
                                             result_str
@@ -852,6 +957,25 @@ class ClaudeCodeAdapter:
                                     is_error or False,
                                 )
 
+                                child_run_id = (
+                                    sub_agents.finish(tool_use_id)
+                                    if tool_use_id
+                                    else None
+                                )
+                                if child_run_id and is_error:
+                                    yield RunErrorEvent(
+                                        type=EventType.RUN_ERROR,
+                                        thread_id=thread_id,
+                                        run_id=child_run_id,
+                                        message=result_str,
+                                    )
+                                elif child_run_id:
+                                    yield RunFinishedEvent(
+                                        type=EventType.RUN_FINISHED,
+                                        thread_id=thread_id,
+                                        run_id=child_run_id,
+                                    )
+
                             elif isinstance(block, ThinkingBlock):
                                 thinking_text = getattr(
                                     block, "thinking", ""
@@ -860,7 +984,7 @@ class ClaudeCodeAdapter:
                                 yield RawEvent(
                                     type=EventType.RAW,
                                     thread_id=thread_id,
-                                    run_id=run_id,
+                                    run_id=msg_run_id,
                                     event={
                                         "type": "thinking_block",
                                         "thinking": thinking_text,
@@ -876,7 +1000,7 @@ class ClaudeCodeAdapter:
                             yield TextMessageEndEvent(
                                 type=EventType.TEXT_MESSAGE_END,
                                 thread_id=thread_id,
-                                run_id=run_id,
+                                run_id=msg_run_id,
                                 message_id=current_message_id,
                             )
                             current_message_id = None
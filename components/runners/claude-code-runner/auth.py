@@ -2,7 +2,8 @@
 Authentication and credential management for the Claude Code runner.
 
 Handles Anthropic API keys, Vertex AI setup, and runtime credential
-fetching from the backend API (GitHub, Google, Jira, GitLab).
+fetching from the backend API (GitHub, Google, Jira, GitLab, commit signing,
+MCP server token passthrough).
 """
 
 import asyncio
@@ -10,11 +11,14 @@ import json as _json
 import logging
 import os
 import re
+import shutil
+import subprocess
 from pathlib import Path
 from urllib import request as _urllib_request
 from urllib.parse import urlparse
 
 from context import RunnerContext
+from utils import redact_secrets
 
 logger = logging.getLogger(__name__)
 
@@ -108,7 +112,7 @@ async def _fetch_credential(context: RunnerContext, credential_type: str) -> dic
 
     Args:
         context: Runner context with session_id.
-        credential_type: One of 'github', 'google', 'jira', 'gitlab'.
+        credential_type: One of 'github', 'google', 'jira', 'gitlab', 'signing-key'.
 
     Returns:
         Dictionary with credential data or empty dict if unavailable.
@@ -208,6 +212,291 @@ async def fetch_gitlab_token(context: RunnerContext) -> str:
     return token
 
 
+async def fetch_signing_key(context: RunnerContext) -> dict:
+    """Fetch the commit signing key (user key, else project key) from backend API."""
+    data = await _fetch_credential(context, "signing-key")
+    if data.get("format"):
+        logger.info(
+            f"Using {data.get('format')} commit signing "
+            f"({data.get('scope', 'unknown')} key)"
+        )
+    return data
+
+
+async def fetch_mcp_server_token(context: RunnerContext, server_name: str) -> dict:
+    """Fetch the session owner's token for an MCP server that uses token
+    passthrough. The backend only issues tokens for registry servers that
+    declare an allowed ``oauthProvider``."""
+    data = await _fetch_credential(context, f"mcp/{server_name}")
+    if data.get("token"):
+        logger.info(
+            f"Using {data.get('provider', 'unknown')} user token for "
+            f"MCP server {server_name}"
+        )
+    return data
+
+
+async def apply_mcp_oauth_tokens(
+    context: RunnerContext, mcp_servers: dict, oauth_servers: dict[str, str]
+) -> list[str]:
+    """Inject user tokens into MCP servers configured for token passthrough.
+
+    HTTP/SSE servers get an Authorization header; stdio servers get
+    MCP_OAUTH_TOKEN and MCP_OAUTH_AUTHORIZATION in their environment.
+    Tokens are fetched fresh for every run and never written to disk.
+
+    Returns:
+        Names of servers whose token could not be obtained.
+    """
+    missing = []
+    for name, provider in oauth_servers.items():
+        entry = mcp_servers.get(name)
+        if not isinstance(entry, dict):
+            continue
+        data = await fetch_mcp_server_token(context, name)
+        if not data.get("token"):
+            logger.warning(
+                f"No {provider} token available for MCP server {name}; "
+                f"the session owner may need to connect {provider}"
+            )
+            missing.append(name)
+            continue
+        if "url" in entry:
+            entry["headers"] = {
+                **entry.get("headers", {}),
+                "Authorization": data.get("authorization")
+                or f"Bearer {data['token']}",
+            }
+        else:
+            entry["env"] = {
+                **entry.get("env", {}),
+                "MCP_OAUTH_TOKEN": data["token"],
+                "MCP_OAUTH_AUTHORIZATION": data.get("authorization", ""),
+            }
+    return missing
+
+
+def _git_config(*args: str) -> None:
+    subprocess.run(
+        ["git", "config", "--global", *args], check=True, capture_output=True
+    )
+
+
+def configure_commit_signing(signing: dict) -> bool:
+    """Configure global git so workspace commits are signed.
+
+    Supports SSH keys, GPG keys, and gitsign (Sigstore keyless). Key material is
+    written under SIGNING_KEY_DIR with owner-only permissions.
+
+    Returns:
+        True when signing was configured.
+    """
+    fmt = signing.get("format", "")
+    private_key = signing.get("privateKey", "")
+    key_dir = Path(os.getenv("SIGNING_KEY_DIR", "/tmp/.vteam-signing"))
+
+    try:
+        if fmt == "ssh":
+            key_dir.mkdir(mode=0o700, parents=True, exist_ok=True)
+            key_file = key_dir / "id_signing"
+            key_file.write_text(private_key)
+            key_file.chmod(0o600)
+            _git_config("gpg.format", "ssh")
+            _git_config("user.signingkey", str(key_file))
+        elif fmt == "gpg":
+            if not shutil.which("gpg"):
+                logger.warning("gpg not installed; commit signing disabled")
+                return False
+            gnupg_home = key_dir / "gnupg"
+            gnupg_home.mkdir(mode=0o700, parents=True, exist_ok=True)
+            os.environ["GNUPGHOME"] = str(gnupg_home)
+            result = subprocess.run(
+                [
+                    "gpg",
+                    "--batch",
+                    "--import",
+                    "--import-options",
+                    "import-show",
+                    "--with-colons",
+                ],
+                input=private_key.encode(),
+                capture_output=True,
+                check=True,
+            )
+            fingerprint = next(
+                (
+                    line.split(":")[9]
+                    for line in result.stdout.decode().splitlines()
+                    if line.startswith("fpr:")
+                ),
+                "",
+            )
+            if not fingerprint:
+                logger.warning(
+                    "Could not determine GPG key fingerprint; commit signing disabled"
+                )
+                return False
+            _git_config("gpg.format", "openpgp")
+            _git_config("user.signingkey", fingerprint)
+        elif fmt == "gitsign":
+            if not shutil.which("gitsign"):
+                logger.warning("gitsign not installed; commit signing disabled")
+                return False
+            _git_config("gpg.format", "x509")
+            _git_config("gpg.x509.program", "gitsign")
+        else:
+            return False
+
+        _git_config("commit.gpgsign", "true")
+        _git_config("tag.gpgsign", "true")
+        # Attribute commits to the signing identity so verification matches
+        if signing.get("signerName"):
+            _git_config("user.name", signing["signerName"])
+        if signing.get("signerEmail"):
+            _git_config("user.email", signing["signerEmail"])
+        logger.info(f"✓ Configured {fmt} commit signing")
+        return True
+    except (OSError, subprocess.CalledProcessError) as e:
+        logger.error(f"Failed to configure commit signing: {e}")
+        return False
+
+
+# Repos whose submodules were already checked out in this runner process
+_submodules_initialized: set[Path] = set()
+
+_SCP_LIKE_URL = re.compile(r"^(?:[^@/]+@)?([^:/]+):(?!//)")
+
+
+def resolve_submodule_url(url: str, parent_url: str) -> str:
+    """Resolve a .gitmodules URL to an absolute https URL.
+
+    Handles relative URLs (../sibling.git, resolved against the parent's
+    origin) and scp-style SSH URLs (git@host:org/repo.git).
+    """
+    url = url.strip()
+    if url.startswith(("./", "../")):
+        base = parent_url.rstrip("/")
+        for part in url.split("/"):
+            if part == "..":
+                base = base.rsplit("/", 1)[0]
+            elif part and part != ".":
+                base = f"{base}/{part}"
+        return base
+    if url.startswith("ssh://"):
+        parsed = urlparse(url)
+        return f"https://{parsed.hostname}{parsed.path}"
+    match = _SCP_LIKE_URL.match(url)
+    if match and not url.startswith(("http://", "https://")):
+        return f"https://{match.group(1)}/{url[match.end():]}"
+    return url
+
+
+def submodule_hosts(gitmodules_urls: list[str], parent_url: str) -> set[str]:
+    """Return the https hosts referenced by a repo's submodules."""
+    hosts = set()
+    for url in gitmodules_urls:
+        resolved = resolve_submodule_url(url, parent_url)
+        if resolved.startswith("https://"):
+            host = urlparse(resolved).hostname
+            if host:
+                hosts.add(host.lower())
+    return hosts
+
+
+def _read_gitmodules_urls(repo_dir: Path) -> list[str]:
+    result = subprocess.run(
+        ["git", "config", "-f", ".gitmodules", "--get-regexp", r"submodule\..*\.url"],
+        cwd=repo_dir,
+        capture_output=True,
+        text=True,
+    )
+    if result.returncode != 0:
+        return []
+    return [
+        line.split(None, 1)[1] for line in result.stdout.splitlines() if " " in line
+    ]
+
+
+def _origin_url(repo_dir: Path) -> str:
+    result = subprocess.run(
+        ["git", "-C", str(repo_dir), "config", "--get", "remote.origin.url"],
+        capture_output=True,
+        text=True,
+    )
+    # Strip any embedded credentials before resolving relative submodule URLs
+    return re.sub(r"https://[^@/]+@", "https://", result.stdout.strip())
+
+
+def configure_submodule_credentials(repos_dir: Path) -> set[str]:
+    """Configure per-host credential helpers for every host referenced by
+    .gitmodules in the workspace repos, then initialize the submodules.
+
+    Helpers read GITHUB_TOKEN / GITLAB_TOKEN at use time, so tokens refreshed by
+    populate_runtime_credentials apply without reconfiguring git. SSH submodule
+    URLs are rewritten to https since no SSH keys are provisioned.
+
+    Returns:
+        The set of hosts that were configured.
+    """
+    configured: set[str] = set()
+    if not repos_dir.is_dir():
+        return configured
+
+    for repo_dir in sorted(p for p in repos_dir.iterdir() if p.is_dir()):
+        if not (repo_dir / ".gitmodules").is_file():
+            continue
+        urls = _read_gitmodules_urls(repo_dir)
+        hosts = submodule_hosts(urls, _origin_url(repo_dir))
+
+        for host in hosts - configured:
+            if "gitlab" in host:
+                username, env_var = "oauth2", "GITLAB_TOKEN"
+            else:
+                username, env_var = "x-access-token", "GITHUB_TOKEN"
+            helper = (
+                f'!f() {{ test "$1" = get && test -n "${env_var}" || exit 0; '
+                f'echo username={username}; echo "password=${env_var}"; }}; f'
+            )
+            try:
+                _git_config(f"credential.https://{host}.helper", helper)
+                _git_config(
+                    "--replace-all", f"url.https://{host}/.insteadOf", f"git@{host}:"
+                )
+                _git_config(
+                    "--add", f"url.https://{host}/.insteadOf", f"ssh://git@{host}/"
+                )
+                configured.add(host)
+            except subprocess.CalledProcessError as e:
+                logger.warning(f"Failed to configure credentials for {host}: {e}")
+
+        if repo_dir in _submodules_initialized:
+            continue
+        result = subprocess.run(
+            [
+                "git",
+                "-C",
+                str(repo_dir),
+                "submodule",
+                "update",
+                "--init",
+                "--recursive",
+            ],
+            capture_output=True,
+            text=True,
+            env={**os.environ, "GIT_TERMINAL_PROMPT": "0"},
+        )
+        if result.returncode == 0:
+            _submodules_initialized.add(repo_dir)
+            logger.info(f"✓ Initialized submodules for {repo_dir.name}")
+        else:
+            logger.warning(
+                f"Submodule init failed for {repo_dir.name}: "
+                f"{redact_secrets(result.stderr)[:500]}"
+            )
+
+    return configured
+
+
 async def fetch_token_for_url(context: RunnerContext, url: str) -> str:
     """Fetch appropriate token based on repository URL host."""
     try:
@@ -294,6 +583,19 @@ async def populate_runtime_credentials(context: RunnerContext) -> None:
         os.environ["GITHUB_TOKEN"] = github_token
         logger.info("✓ Updated GitHub token in environment")
 
+    # Submodules may live on other hosts than the top-level repos
+    workspace_path = os.getenv("WORKSPACE_PATH", "/workspace")
+    hosts = configure_submodule_credentials(Path(workspace_path) / "repos")
+    if hosts:
+        logger.info(
+            f"✓ Configured submodule credentials for: {', '.join(sorted(hosts))}"
+        )
+
+    # Commit signing key
+    signing = await fetch_signing_key(context)
+    if signing.get("format"):
+        configure_commit_signing(signing)
+
     logger.info("Runtime credentials populated successfully")
 
 
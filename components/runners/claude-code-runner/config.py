@@ -48,9 +48,13 @@ def load_ambient_config(cwd_path: str) -> dict:
 def load_mcp_config(context: RunnerContext, cwd_path: str) -> Optional[dict]:
     """Load MCP server configuration from the ambient runner's .mcp.json file.
 
+    Servers registered for the project (PROJECT_MCP_CONFIG_FILE, mounted by
+    the operator) are merged on top and win over same-named defaults.
+
     Returns:
         Dict of MCP server configs with env vars expanded, or None.
     """
+    mcp_servers: Optional[dict] = None
     try:
         mcp_config_file = context.get_env(
             "MCP_CONFIG_FILE", "/app/claude-runner/.mcp.json"
@@ -62,21 +66,202 @@ def load_mcp_config(context: RunnerContext, cwd_path: str) -> Optional[dict]:
             with open(runner_mcp_file, "r") as f:
                 config = _json.load(f)
                 mcp_servers = config.get("mcpServers", {})
-                expanded = expand_env_vars(mcp_servers)
-                logger.info(
-                    f"Expanded MCP config env vars for {len(expanded)} servers"
-                )
-                return expanded
         else:
             logger.info(f"No MCP config file found at: {runner_mcp_file}")
-            return None
 
     except _json.JSONDecodeError as e:
         logger.error(f"Failed to parse MCP config: {e}")
-        return None
     except Exception as e:
         logger.error(f"Error loading MCP config: {e}")
+
+    project_servers = load_project_mcp_servers(context)
+    if project_servers:
+        mcp_servers = {**(mcp_servers or {}), **project_servers}
+
+    if mcp_servers is None:
+        return None
+    expanded = expand_env_vars(mcp_servers)
+    logger.info(f"Expanded MCP config env vars for {len(expanded)} servers")
+    return expanded
+
+
+def project_mcp_server_entry(server: dict) -> Optional[dict]:
+    """Convert a project registry entry to the SDK's mcpServers format."""
+    transport = server.get("transport")
+    if transport == "stdio":
+        entry = {"command": server.get("command", "")}
+        if server.get("args"):
+            entry["args"] = list(server["args"])
+        if server.get("env"):
+            entry["env"] = dict(server["env"])
+        return entry
+    if transport in ("http", "sse"):
+        entry = {"type": transport, "url": server.get("url", "")}
+        if server.get("headers"):
+            entry["headers"] = dict(server["headers"])
+        return entry
+    return None
+
+
+def _read_project_mcp_registry(context: RunnerContext) -> list[dict]:
+    """Read the project MCP registry mounted by the operator, if any."""
+    path = Path(
+        context.get_env(
+            "PROJECT_MCP_CONFIG_FILE", "/etc/ambient/mcp/mcp-servers.json"
+        )
+    )
+    if not path.is_file():
+        return []
+    try:
+        with open(path, "r") as f:
+            registry = _json.load(f)
+    except (OSError, _json.JSONDecodeError) as e:
+        logger.error(f"Failed to load project MCP servers from {path}: {e}")
+        return []
+    if not isinstance(registry, list):
+        logger.error(f"Project MCP registry at {path} is not a list, ignoring")
+        return []
+    return [
+        server
+        for server in registry
+        if isinstance(server, dict) and server.get("enabled")
+    ]
+
+
+def load_project_mcp_servers(context: RunnerContext) -> dict:
+    """Load enabled MCP servers from the project registry, if mounted.
+
+    The registry is a JSON list managed via the backend's
+    /projects/:project/mcp-servers API. Credentials referenced by a server
+    are injected as env vars by the operator and used via ${VAR}.
+    """
+    servers = {}
+    for server in _read_project_mcp_registry(context):
+        name = server.get("name")
+        entry = project_mcp_server_entry(server)
+        if not name or entry is None:
+            logger.warning(f"Skipping invalid project MCP server: {name!r}")
+            continue
+        servers[name] = entry
+    if servers:
+        logger.info(
+            f"Loaded {len(servers)} project MCP servers: {sorted(servers)}"
+        )
+    return servers
+
+
+def load_project_mcp_oauth_servers(context: RunnerContext) -> dict[str, str]:
+    """Return {server name: provider} for project MCP servers that use the
+    session owner's own token (``oauthProvider``) instead of a shared secret.
+    """
+    return {
+        server["name"]: server["oauthProvider"]
+        for server in _read_project_mcp_registry(context)
+        if server.get("name") and server.get("oauthProvider")
+    }
+
+
+# MCP servers provided by the runner itself; never restricted by project policy
+PLATFORM_MCP_SERVERS = ("session", "rubric")
+
+
+def load_mcp_tool_policy(context: RunnerContext) -> Optional[dict]:
+    """Load the project MCP tool policy snapshotted on the session.
+
+    The backend sets MCP_TOOL_POLICY at session creation from ProjectSettings
+    spec.mcpToolPolicy: ``{"mode": "allowlist"|"denylist", "tools": [...],
+    "enforcement": "flag"|"block"}``.
+    """
+    raw = (context.get_env("MCP_TOOL_POLICY") or "").strip()
+    if not raw:
+        return None
+    try:
+        policy = _json.loads(raw)
+    except _json.JSONDecodeError as e:
+        logger.error(f"Failed to parse MCP_TOOL_POLICY: {e}")
+        return None
+    if not isinstance(policy, dict) or policy.get("mode") not in (
+        "allowlist",
+        "denylist",
+    ):
+        logger.error("Ignoring MCP_TOOL_POLICY with unknown mode")
         return None
+    return policy
+
+
+def _sdk_tool_rule(pattern: str) -> Optional[str]:
+    """Translate a policy pattern to an SDK tool rule.
+
+    The SDK matches exact tool names or whole servers (``mcp__<server>``), so
+    ``mcp__<server>__*`` maps to the server and other wildcards are left to
+    the backend proxy, which flags and interrupts disallowed calls.
+    """
+    pattern = pattern.strip()
+    if not pattern.endswith("*"):
+        return pattern
+    prefix = pattern[:-1]
+    parts = prefix.split("__")
+    if len(parts) == 3 and parts[0] == "mcp" and parts[1] and parts[2] == "":
+        return f"mcp__{parts[1]}"
+    logger.warning(f"MCP tool pattern {pattern!r} cannot be enforced by the SDK")
+    return None
+
+
+def apply_mcp_tool_policy(
+    policy: Optional[dict], allowed_tools: list[str]
+) -> tuple[list[str], list[str]]:
+    """Apply a block-mode MCP tool policy to the SDK tool permissions.
+
+    Returns:
+        (allowed_tools, disallowed_tools). Flag-mode policies leave permissions
+        unchanged; violations are reported by the backend.
+    """
+    if not policy or policy.get("enforcement") != "block":
+        return allowed_tools, []
+
+    rules = [
+        r for r in (_sdk_tool_rule(t) for t in policy.get("tools") or []) if r
+    ]
+    if policy.get("mode") == "denylist":
+        return allowed_tools, rules
+
+    # Allowlist: replace per-server grants with the allowed tools only
+    platform = {f"mcp__{name}" for name in PLATFORM_MCP_SERVERS}
+    allowed = [
+        t for t in allowed_tools if not t.startswith("mcp__") or t in platform
+    ]
+    allowed.extend(r for r in rules if r not in allowed)
+    return allowed, []
+
+
+def apply_persona_mcp_servers(persona: Optional[dict], mcp_servers: dict) -> dict:
+    """Limit MCP servers to those a persona lists.
+
+    The persona is resolved by the backend from the project registry and
+    forwarded with the run. Personas without ``mcpServers`` keep all servers;
+    platform servers are added after this and always remain.
+    """
+    names = (persona or {}).get("mcpServers") or []
+    if not names:
+        return mcp_servers
+    missing = [n for n in names if n not in mcp_servers]
+    if missing:
+        logger.warning(f"Persona MCP servers not configured: {missing}")
+    return {n: cfg for n, cfg in mcp_servers.items() if n in names}
+
+
+def apply_persona_tools(persona: Optional[dict], allowed_tools: list[str]) -> list[str]:
+    """Replace the built-in tool grants with a persona's ``allowedTools``.
+
+    MCP server grants (``mcp__<server>``) are kept; the servers themselves are
+    limited by apply_persona_mcp_servers.
+    """
+    tools = (persona or {}).get("allowedTools") or []
+    if not tools:
+        return allowed_tools
+    allowed = [t for t in allowed_tools if t.startswith("mcp__")]
+    allowed.extend(t for t in tools if t not in allowed)
+    return allowed
 
 
 def get_repos_config() -> list[dict]:
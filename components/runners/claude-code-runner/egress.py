@@ -0,0 +1,144 @@
+"""
+Project egress allowlist enforcement for tool calls.
+
+The backend snapshots the project's egress policy onto the session as
+EGRESS_POLICY. In block mode a PreToolUse hook refuses tool calls whose
+arguments name hosts outside the allowlist; in flag mode the backend only
+reports them.
+
+IMPORTANT: Keep host extraction and matching in sync with backend
+(components/backend/types/egress.go).
+"""
+
+import ipaddress
+import json as _json
+import logging
+import re
+from typing import Any, Optional
+from urllib.parse import urlsplit
+
+logger = logging.getLogger(__name__)
+
+# Tools that work on the workspace only; URLs in their arguments are not egress
+LOCAL_TOOLS = {
+    "Read",
+    "Write",
+    "Edit",
+    "MultiEdit",
+    "NotebookEdit",
+    "Glob",
+    "Grep",
+    "LS",
+    "TodoWrite",
+}
+
+_URL_PATTERN = re.compile(r"(?i)\b(?:https?|wss?|ftp|ssh|git)://[^\s\"'<>\\]+")
+_SCP_PATTERN = re.compile(r"\b[A-Za-z0-9._-]+@([A-Za-z0-9.-]+\.[A-Za-z]{2,}):")
+
+
+def load_egress_policy(raw: Optional[str]) -> Optional[dict]:
+    """Parse the EGRESS_POLICY snapshot: ``{"allowedHosts": [...],
+    "enforcement": "flag"|"block"}``."""
+    raw = (raw or "").strip()
+    if not raw:
+        return None
+    try:
+        policy = _json.loads(raw)
+    except _json.JSONDecodeError as e:
+        logger.error(f"Failed to parse EGRESS_POLICY: {e}")
+        return None
+    if not isinstance(policy, dict):
+        logger.error("Ignoring EGRESS_POLICY that is not an object")
+        return None
+    return policy
+
+
+def _collect_strings(value: Any, out: list[str]) -> None:
+    if isinstance(value, str):
+        out.append(value)
+    elif isinstance(value, list):
+        for item in value:
+            _collect_strings(item, out)
+    elif isinstance(value, dict):
+        for item in value.values():
+            _collect_strings(item, out)
+
+
+def extract_hosts(tool_input: Any) -> list[str]:
+    """Return the hosts named by URLs and git remotes in tool arguments."""
+    if isinstance(tool_input, str):
+        try:
+            tool_input = _json.loads(tool_input)
+        except _json.JSONDecodeError:
+            pass
+    texts: list[str] = []
+    _collect_strings(tool_input, texts)
+
+    hosts: set[str] = set()
+    for text in texts:
+        for raw in _URL_PATTERN.findall(text):
+            try:
+                host = urlsplit(raw.rstrip(".,;:)]}")).hostname
+            except ValueError:
+                continue
+            if host:
+                hosts.add(host.lower())
+        for host in _SCP_PATTERN.findall(text):
+            hosts.add(host.lower())
+    return sorted(hosts)
+
+
+def host_allowed(policy: Optional[dict], host: str) -> bool:
+    """Whether the policy permits reaching host."""
+    if policy is None:
+        return True
+    host = host.lower().rstrip(".")
+    try:
+        ip = ipaddress.ip_address(host)
+    except ValueError:
+        ip = None
+    for entry in policy.get("allowedHosts") or []:
+        entry = entry.strip().lower()
+        if "/" in entry:
+            try:
+                if ip is not None and ip in ipaddress.ip_network(entry, strict=False):
+                    return True
+            except ValueError:
+                continue
+        elif entry.startswith("*."):
+            if host.endswith(entry[1:]):
+                return True
+        elif entry == host:
+            return True
+    return False
+
+
+def denied_hosts(policy: Optional[dict], tool_name: str, tool_input: Any) -> list[str]:
+    """Hosts in a tool call the policy does not allow."""
+    if policy is None or tool_name in LOCAL_TOOLS:
+        return []
+    return [h for h in extract_hosts(tool_input) if not host_allowed(policy, h)]
+
+
+def create_egress_hook(policy: dict):
+    """Create a PreToolUse hook refusing calls to hosts outside the allowlist."""
+
+    async def egress_hook(input_data, tool_use_id, context):
+        tool_name = input_data.get("tool_name", "")
+        denied = denied_hosts(policy, tool_name, input_data.get("tool_input"))
+        if not denied:
+            return {}
+        logger.warning(f"Egress policy refused {tool_name} reaching {denied}")
+        return {
+            "hookSpecificOutput": {
+                "hookEventName": "PreToolUse",
+                "permissionDecision": "deny",
+                "permissionDecisionReason": (
+                    f"{', '.join(denied)} is not in the project's egress "
+                    "allowlist. Use an allowed host or ask the user to "
+                    "update the project's egress policy."
+                ),
+            }
+        }
+
+    return egress_hook
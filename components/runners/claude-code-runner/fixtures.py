@@ -0,0 +1,80 @@
+"""
+Tool-result fixtures for evaluation replays.
+
+When the backend replays a recorded run with ``useToolFixtures``, it forwards
+the run's tool calls and their results as ``forwardedProps.toolFixtures``.
+A PreToolUse hook then answers each tool call with the recorded result
+instead of executing it, so replays are repeatable and side-effect free.
+"""
+
+import json as _json
+import logging
+from typing import Any, Optional
+
+logger = logging.getLogger(__name__)
+
+
+def _normalize_input(value: Any) -> Any:
+    """Decode JSON-encoded tool arguments so they compare by value."""
+    if isinstance(value, str):
+        try:
+            return _json.loads(value)
+        except _json.JSONDecodeError:
+            return value
+    return value
+
+
+def match_fixture(
+    fixtures: list[dict], used: set[int], tool_name: str, tool_input: Any
+) -> Optional[int]:
+    """Pick the recorded call answering a tool call.
+
+    Prefers an unused fixture for the same tool with identical input, then
+    the next unused fixture for the same tool. Returns its index, or None.
+    """
+    tool_input = _normalize_input(tool_input)
+    candidates = [
+        i
+        for i, f in enumerate(fixtures)
+        if i not in used and f.get("tool") == tool_name
+    ]
+    for i in candidates:
+        if _normalize_input(fixtures[i].get("input")) == tool_input:
+            return i
+    return candidates[0] if candidates else None
+
+
+def create_fixture_hook(fixtures: list[dict]):
+    """Create a PreToolUse hook answering tool calls from fixtures.
+
+    The SDK cannot substitute a tool result, so the call is denied with the
+    recorded result as the reason, which the model receives in its place.
+    """
+    used: set[int] = set()
+
+    async def fixture_hook(input_data, tool_use_id, context):
+        tool_name = input_data.get("tool_name", "")
+        index = match_fixture(
+            fixtures, used, tool_name, input_data.get("tool_input")
+        )
+        if index is None:
+            logger.info(f"No tool fixture for {tool_name}, denying call")
+            reason = (
+                f"[Replay] No recorded result for {tool_name}; "
+                "this tool is unavailable during the replay."
+            )
+        else:
+            used.add(index)
+            reason = (
+                f"[Replay] Recorded result of {tool_name}:\n"
+                f"{fixtures[index].get('result', '')}"
+            )
+        return {
+            "hookSpecificOutput": {
+                "hookEventName": "PreToolUse",
+                "permissionDecision": "deny",
+                "permissionDecisionReason": reason,
+            }
+        }
+
+    return fixture_hook
@@ -103,6 +103,20 @@ async def lifespan(app: FastAPI):
 
     logger.info("Adapter initialized - fresh client will be created for each run")
 
+    # Warm pool runners wait for a session to claim them (POST /claim)
+    if os.getenv("WARM_POOL", "").strip().lower() == "true":
+        logger.info("WARM_POOL=true - standing by until claimed by a session")
+    else:
+        start_session(session_id)
+
+    yield
+
+    # Cleanup
+    logger.info("Shutting down AG-UI server...")
+
+
+def start_session(session_id: str):
+    """Start the session once its environment is known (at startup, or when claimed)."""
     # Check if this is a resume session via IS_RESUME env var
     # This is set by the operator when restarting a stopped/completed/failed session
     is_resume = os.getenv("IS_RESUME", "").strip().lower() == "true"
@@ -126,11 +140,6 @@ async def lifespan(app: FastAPI):
     
     logger.info(f"AG-UI server ready for session {session_id}")
 
-    yield
-
-    # Cleanup
-    logger.info("Shutting down AG-UI server...")
-
 
 async def auto_execute_initial_prompt(prompt: str, session_id: str):
     """Auto-execute INITIAL_PROMPT by POSTing to backend after short delay.
@@ -218,9 +227,19 @@ async def auto_execute_initial_prompt(prompt: str, session_id: str):
 
 app = FastAPI(title="Claude Code AG-UI Server", version="0.2.0", lifespan=lifespan)
 
+# Runner protocol spoken with the backend proxy, reported on GET /version.
+# Bump it when the contract changes; capabilities announce optional behavior.
+PROTOCOL_VERSION = "1"
+CAPABILITIES = [
+    # User messages of a run are streamed back as TEXT_MESSAGE events (adapter.py)
+    "userMessageEcho",
+]
+
 
 # Track if adapter has been initialized
 _adapter_initialized = False
+# Workspace preparation of a claimed warm pool runner (repos, workflow)
+_claim_task: Optional[asyncio.Task] = None
 # Prevent duplicate workflow updates/greetings from concurrent calls
 _workflow_change_lock = asyncio.Lock()
 
@@ -239,6 +258,11 @@ async def run_agent(input_data: RunnerInput, request: Request):
     if not adapter:
         raise HTTPException(status_code=503, detail="Adapter not initialized")
 
+    # A claimed warm pool runner finishes cloning before its first run
+    if _claim_task and not _claim_task.done():
+        logger.info("Waiting for claimed workspace preparation to finish...")
+        await asyncio.shield(_claim_task)
+
     # Convert to official RunAgentInput
     run_agent_input = input_data.to_run_agent_input()
 
@@ -1625,6 +1649,88 @@ async def get_repos_status():
     return {"repos": repos_status}
 
 
+@app.post("/claim")
+async def claim_runner(request: Request):
+    """
+    Claim an idle warm pool runner for a session.
+
+    Called by the operator when a session claims this pod. Applies the session's
+    environment (the env a dedicated runner pod would have had), records the session
+    for the state-sync sidecar, then clones the session's repos and workflow in the
+    background. Runs wait for the clone to finish.
+
+    Accepts: {"env": {"SESSION_ID": "...", "BOT_TOKEN": "...", ...}}
+    """
+    global _claim_task
+
+    if os.getenv("WARM_POOL", "").strip().lower() != "true":
+        raise HTTPException(status_code=409, detail="Runner is not an idle warm pool runner")
+    if not context or not adapter:
+        raise HTTPException(status_code=503, detail="Adapter not initialized")
+
+    body = await request.json()
+    env = body.get("env") or {}
+    session_id = str(env.get("SESSION_ID", "")).strip()
+    if not session_id:
+        raise HTTPException(status_code=400, detail="env.SESSION_ID is required")
+
+    os.environ.update({k: str(v) for k, v in env.items()})
+    os.environ.pop("WARM_POOL", None)
+    context.session_id = session_id
+    context.environment.update(os.environ)
+    logger.info(f"Warm pool runner claimed by session {session_id}")
+
+    # state-sync starts syncing to the session's S3 prefix once this exists
+    session_name_file = os.getenv("SESSION_NAME_FILE", "").strip()
+    if session_name_file:
+        try:
+            Path(session_name_file).parent.mkdir(parents=True, exist_ok=True)
+            Path(session_name_file).write_text(session_id)
+        except OSError as e:
+            logger.warning(f"Failed to record session name for state-sync: {e}")
+
+    _claim_task = asyncio.create_task(prepare_claimed_workspace())
+    start_session(session_id)
+    return {"message": "Runner claimed", "session": session_id}
+
+
+async def prepare_claimed_workspace():
+    """Clone the claimed session's repos and workflow, as hydrate.sh does for new pods."""
+    try:
+        repos = json.loads(os.getenv("REPOS_JSON", "") or "[]")
+    except json.JSONDecodeError:
+        logger.warning("Invalid REPOS_JSON, skipping repo clones")
+        repos = []
+    for repo in repos if isinstance(repos, list) else []:
+        url = (repo.get("url") or "").strip() if isinstance(repo, dict) else ""
+        if not url:
+            continue
+        success, _, _ = await clone_repo_at_runtime(url, repo.get("branch") or "main", "")
+        if not success:
+            logger.warning(f"Failed to clone {url} for claimed session")
+
+    workflow_url = os.getenv("ACTIVE_WORKFLOW_GIT_URL", "").strip()
+    if workflow_url:
+        success, _ = await clone_workflow_at_runtime(
+            workflow_url,
+            os.getenv("ACTIVE_WORKFLOW_BRANCH", "main").strip() or "main",
+            os.getenv("ACTIVE_WORKFLOW_PATH", "").strip(),
+        )
+        if not success:
+            logger.warning(f"Failed to clone workflow {workflow_url} for claimed session")
+    logger.info("Claimed workspace ready")
+
+
+@app.get("/version")
+async def version():
+    """Protocol version handshake queried by the backend proxy at session start."""
+    return {
+        "runnerVersion": app.version,
+        "protocolVersion": PROTOCOL_VERSION,
+        "capabilities": CAPABILITIES,
+    }
+
+
 @app.get("/health")
 async def health():
     """Health check endpoint."""
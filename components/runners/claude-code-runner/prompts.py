@@ -41,6 +41,11 @@ GIT_PUSH_STEPS = (
     "3. Use `git push origin {branch}` to push to the remote repository\n\n"
 )
 
+SHARED_WORKSPACE_PROMPT = (
+    "**Shared Project Files**: {path}/ (read-only docs, datasets and style "
+    "guides shared by every session in this project)\n\n"
+)
+
 RUBRIC_EVALUATION_HEADER = "## Rubric Evaluation\n\n"
 
 RUBRIC_EVALUATION_INTRO = (
@@ -122,6 +127,11 @@ def build_workspace_context_prompt(
     else:
         prompt += "**Uploaded Files**: None\n\n"
 
+    # Shared project files
+    shared_path = os.getenv("SHARED_WORKSPACE_PATH", "").strip()
+    if shared_path and Path(shared_path).is_dir():
+        prompt += SHARED_WORKSPACE_PROMPT.format(path=shared_path)
+
     # Repositories
     if repos_cfg:
         session_id = os.getenv("AGENTIC_SESSION_NAME", "").strip()
@@ -0,0 +1,93 @@
+"""
+Sub-agent runs for Claude's Task tool.
+
+Messages of a sub-agent carry the ID of the Task tool call that started it as
+``parent_tool_use_id``. Each sub-agent is registered with the backend as a
+child run of the current run before its events are streamed; its events then
+carry the child's run ID, and the backend tags them with a ``branchId`` so the
+UI can render parallel agent work as branches.
+"""
+
+import asyncio
+import json as _json
+import logging
+import os
+import urllib.request
+import uuid
+from typing import Dict, Optional
+
+logger = logging.getLogger(__name__)
+
+# Tools whose calls run a sub-agent
+SUB_AGENT_TOOLS = {"Task"}
+
+
+def register_child_run(
+    parent_run_id: str, child_run_id: str, branch_id: str, name: str
+) -> bool:
+    """Register a sub-agent run with the backend; False when it could not be."""
+    base = os.getenv("BACKEND_API_URL", "").rstrip("/")
+    project = (
+        os.getenv("PROJECT_NAME") or os.getenv("AGENTIC_SESSION_NAMESPACE", "")
+    ).strip()
+    session_id = os.getenv("SESSION_ID", "").strip()
+    if not (base and project and session_id):
+        return False
+
+    url = (
+        f"{base}/projects/{project}/agentic-sessions/{session_id}"
+        f"/agui/runs/{parent_run_id}/children"
+    )
+    body = _json.dumps(
+        {"runId": child_run_id, "branchId": branch_id, "name": name}
+    ).encode()
+    req = urllib.request.Request(url, data=body, method="POST")
+    req.add_header("Content-Type", "application/json")
+    bot = (os.getenv("BOT_TOKEN") or "").strip()
+    if bot:
+        req.add_header("Authorization", f"Bearer {bot}")
+    try:
+        with urllib.request.urlopen(req, timeout=5) as resp:
+            return resp.status == 201
+    except Exception as e:
+        logger.warning(f"Failed to register sub-agent run {child_run_id}: {e}")
+        return False
+
+
+class SubAgentRuns:
+    """Maps the Task tool calls of a run to the child runs of their sub-agents."""
+
+    def __init__(self, run_id: str):
+        self.run_id = run_id
+        self._runs: Dict[str, str] = {}
+
+    async def start(
+        self, tool_call_id: str, tool_input: dict, parent_run_id: str
+    ) -> Optional[str]:
+        """Register the sub-agent of a Task tool call and return its run ID.
+
+        Unregistered sub-agents stream as part of their parent run.
+        """
+        name = tool_input.get("subagent_type") or tool_input.get("description")
+        child_run_id = str(uuid.uuid4())
+        registered = await asyncio.to_thread(
+            register_child_run,
+            parent_run_id,
+            child_run_id,
+            tool_call_id,
+            str(name or "sub-agent")[:200],
+        )
+        if not registered:
+            return None
+        self._runs[tool_call_id] = child_run_id
+        return child_run_id
+
+    def run_id_for(self, parent_tool_use_id: Optional[str]) -> str:
+        """Run ID for the events of a message from the given Task tool call."""
+        if not parent_tool_use_id:
+            return self.run_id
+        return self._runs.get(parent_tool_use_id, self.run_id)
+
+    def finish(self, tool_call_id: str) -> Optional[str]:
+        """Forget a finished Task tool call, returning its sub-agent's run ID."""
+        return self._runs.pop(tool_call_id, None)
@@ -0,0 +1,86 @@
+"""
+Test cases for project egress allowlist enforcement (egress.py)
+
+Hosts are taken from URLs and git remotes anywhere in a tool call's
+arguments; block-mode policies refuse calls reaching other hosts.
+"""
+
+import asyncio
+import sys
+from pathlib import Path
+
+# Add parent directory to path for importing egress module
+runner_dir = Path(__file__).parent.parent
+if str(runner_dir) not in sys.path:
+    sys.path.insert(0, str(runner_dir))
+
+from egress import (  # type: ignore[import]
+    create_egress_hook,
+    denied_hosts,
+    extract_hosts,
+    host_allowed,
+    load_egress_policy,
+)
+
+POLICY = {
+    "allowedHosts": ["github.com", "*.internal.example.com", "10.0.0.0/8"],
+    "enforcement": "block",
+}
+
+
+class TestExtractHosts:
+    """Test suite for extract_hosts"""
+
+    def test_urls_and_git_remotes(self):
+        command = (
+            "curl -s https://API.example.org/v1?x=1 && "
+            "git clone git@github.com:org/repo.git"
+        )
+        assert extract_hosts({"command": command}) == ["api.example.org", "github.com"]
+
+    def test_json_encoded_arguments(self):
+        assert extract_hosts('{"url": "http://10.1.2.3:8080/health."}') == ["10.1.2.3"]
+
+    def test_no_hosts(self):
+        assert extract_hosts({"command": "ls -la"}) == []
+
+
+class TestHostAllowed:
+    """Test suite for host_allowed"""
+
+    def test_exact_wildcard_and_cidr(self):
+        assert host_allowed(POLICY, "github.com")
+        assert host_allowed(POLICY, "git.internal.example.com")
+        assert host_allowed(POLICY, "10.20.30.40")
+        assert not host_allowed(POLICY, "internal.example.com")
+        assert not host_allowed(POLICY, "evil-github.com")
+        assert not host_allowed(POLICY, "192.168.0.1")
+
+    def test_no_policy_allows_everything(self):
+        assert host_allowed(None, "example.org")
+
+
+class TestEgressHook:
+    """Test suite for create_egress_hook"""
+
+    def test_refuses_hosts_outside_allowlist(self):
+        hook = create_egress_hook(POLICY)
+        call = {"tool_name": "WebFetch", "tool_input": {"url": "https://pastebin.com/raw/x"}}
+        out = asyncio.run(hook(call, "t1", None))["hookSpecificOutput"]
+        assert out["permissionDecision"] == "deny"
+        assert "pastebin.com" in out["permissionDecisionReason"]
+
+    def test_allows_listed_hosts_and_local_tools(self):
+        hook = create_egress_hook(POLICY)
+        call = {"tool_name": "Bash", "tool_input": {"command": "git fetch https://github.com/org/repo"}}
+        assert asyncio.run(hook(call, "t1", None)) == {}
+        # Links written to files are not egress
+        assert denied_hosts(POLICY, "Write", {"content": "see https://pastebin.com"}) == []
+
+    def test_load_policy(self):
+        assert load_egress_policy('{"allowedHosts": [], "enforcement": "flag"}') == {
+            "allowedHosts": [],
+            "enforcement": "flag",
+        }
+        assert load_egress_policy("") is None
+        assert load_egress_policy("not json") is None
@@ -0,0 +1,120 @@
+"""
+Test cases for MCP server OAuth token passthrough
+
+Project MCP servers with an ``oauthProvider`` receive the session owner's own
+token, brokered by the backend, instead of a shared secret.
+"""
+
+import json
+import sys
+from pathlib import Path
+
+import pytest
+
+# Add parent directory to path for importing runner modules
+runner_dir = Path(__file__).parent.parent
+if str(runner_dir) not in sys.path:
+    sys.path.insert(0, str(runner_dir))
+
+import auth  # type: ignore[import]
+from config import load_project_mcp_oauth_servers  # type: ignore[import]
+from context import RunnerContext  # type: ignore[import]
+
+
+def _context(tmp_path, registry=None):
+    env = {}
+    if registry is not None:
+        registry_file = tmp_path / "mcp-servers.json"
+        registry_file.write_text(json.dumps(registry))
+        env["PROJECT_MCP_CONFIG_FILE"] = str(registry_file)
+    else:
+        env["PROJECT_MCP_CONFIG_FILE"] = str(tmp_path / "missing.json")
+    return RunnerContext(
+        session_id="s", workspace_path=str(tmp_path), environment=env
+    )
+
+
+class TestLoadProjectMCPOAuthServers:
+    """Test suite for load_project_mcp_oauth_servers"""
+
+    def test_only_enabled_servers_with_provider(self, tmp_path):
+        ctx = _context(
+            tmp_path,
+            [
+                {
+                    "name": "drive",
+                    "transport": "http",
+                    "url": "https://drive.example.com/mcp",
+                    "oauthProvider": "google",
+                    "enabled": True,
+                },
+                {
+                    "name": "docs",
+                    "transport": "http",
+                    "url": "https://docs.example.com/mcp",
+                    "enabled": True,
+                },
+                {
+                    "name": "jira",
+                    "transport": "stdio",
+                    "command": "mcp-atlassian",
+                    "oauthProvider": "jira",
+                    "enabled": False,
+                },
+            ],
+        )
+        assert load_project_mcp_oauth_servers(ctx) == {"drive": "google"}
+
+    def test_no_registry(self, tmp_path):
+        assert load_project_mcp_oauth_servers(_context(tmp_path)) == {}
+
+
+class TestApplyMCPOAuthTokens:
+    """Test suite for apply_mcp_oauth_tokens"""
+
+    @pytest.mark.asyncio
+    async def test_injects_header_and_env(self, tmp_path, monkeypatch):
+        tokens = {
+            "drive": {"token": "ya29", "authorization": "Bearer ya29"},
+            "jira": {"token": "api", "authorization": "Basic dTphcGk="},
+        }
+
+        async def fake_fetch(context, name):
+            return tokens.get(name, {})
+
+        monkeypatch.setattr(auth, "fetch_mcp_server_token", fake_fetch)
+        servers = {
+            "drive": {
+                "type": "http",
+                "url": "https://drive.example.com/mcp",
+                "headers": {"X-Extra": "1"},
+            },
+            "jira": {"command": "mcp-atlassian"},
+            "docs": {"type": "http", "url": "https://docs.example.com/mcp"},
+        }
+        missing = await auth.apply_mcp_oauth_tokens(
+            _context(tmp_path), servers, {"drive": "google", "jira": "jira"}
+        )
+
+        assert missing == []
+        assert servers["drive"]["headers"] == {
+            "X-Extra": "1",
+            "Authorization": "Bearer ya29",
+        }
+        assert servers["jira"]["env"]["MCP_OAUTH_TOKEN"] == "api"
+        assert servers["jira"]["env"]["MCP_OAUTH_AUTHORIZATION"] == "Basic dTphcGk="
+        assert "headers" not in servers["docs"]
+
+    @pytest.mark.asyncio
+    async def test_reports_missing_tokens(self, tmp_path, monkeypatch):
+        async def fake_fetch(context, name):
+            return {}
+
+        monkeypatch.setattr(auth, "fetch_mcp_server_token", fake_fetch)
+        servers = {"drive": {"type": "http", "url": "https://d.example.com"}}
+        missing = await auth.apply_mcp_oauth_tokens(
+            _context(tmp_path), servers, {"drive": "google", "absent": "github"}
+        )
+
+        assert missing == ["drive"]
+        assert "headers" not in servers["drive"]
@@ -0,0 +1,75 @@
+"""
+Test cases for applying the project MCP tool policy in config.py
+
+Block-mode policies must narrow the SDK tool permissions; flag-mode policies
+leave them alone and are reported by the backend proxy instead.
+"""
+
+import json
+import sys
+from pathlib import Path
+
+# Add parent directory to path for importing config module
+runner_dir = Path(__file__).parent.parent
+if str(runner_dir) not in sys.path:
+    sys.path.insert(0, str(runner_dir))
+
+from config import apply_mcp_tool_policy, load_mcp_tool_policy  # type: ignore[import]
+from context import RunnerContext  # type: ignore[import]
+
+BASE_TOOLS = ["Read", "Bash", "mcp__github", "mcp__jira", "mcp__session"]
+
+
+class TestLoadMCPToolPolicy:
+    """Test suite for load_mcp_tool_policy"""
+
+    def _context(self, tmp_path, value):
+        env = {"MCP_TOOL_POLICY": value} if value is not None else {}
+        return RunnerContext(
+            session_id="s", workspace_path=str(tmp_path), environment=env
+        )
+
+    def test_missing(self, tmp_path, monkeypatch):
+        monkeypatch.delenv("MCP_TOOL_POLICY", raising=False)
+        assert load_mcp_tool_policy(self._context(tmp_path, None)) is None
+
+    def test_invalid(self, tmp_path):
+        assert load_mcp_tool_policy(self._context(tmp_path, "{bad")) is None
+        assert (
+            load_mcp_tool_policy(self._context(tmp_path, '{"mode": "other"}'))
+            is None
+        )
+
+    def test_valid(self, tmp_path):
+        policy = {"mode": "denylist", "tools": ["mcp__jira__*"], "enforcement": "flag"}
+        loaded = load_mcp_tool_policy(self._context(tmp_path, json.dumps(policy)))
+        assert loaded == policy
+
+
+class TestApplyMCPToolPolicy:
+    """Test suite for apply_mcp_tool_policy"""
+
+    def test_flag_mode_unchanged(self):
+        policy = {"mode": "denylist", "tools": ["mcp__jira__*"], "enforcement": "flag"}
+        assert apply_mcp_tool_policy(policy, BASE_TOOLS) == (BASE_TOOLS, [])
+
+    def test_denylist_block(self):
+        policy = {
+            "mode": "denylist",
+            "tools": ["mcp__jira__*", "mcp__github__delete_repo"],
+            "enforcement": "block",
+        }
+        allowed, disallowed = apply_mcp_tool_policy(policy, BASE_TOOLS)
+        assert allowed == BASE_TOOLS
+        assert disallowed == ["mcp__jira", "mcp__github__delete_repo"]
+
+    def test_allowlist_block(self):
+        policy = {
+            "mode": "allowlist",
+            "tools": ["mcp__github__get_*", "mcp__github__search_code"],
+            "enforcement": "block",
+        }
+        allowed, disallowed = apply_mcp_tool_policy(policy, BASE_TOOLS)
+        # Unexpressible prefix patterns are left to the backend proxy
+        assert allowed == ["Read", "Bash", "mcp__session", "mcp__github__search_code"]
+        assert disallowed == []
@@ -0,0 +1,30 @@
+"""
+Test cases for classifying run errors the backend falls back to another model on
+"""
+
+import sys
+from pathlib import Path
+
+runner_dir = Path(__file__).parent.parent
+if str(runner_dir) not in sys.path:
+    sys.path.insert(0, str(runner_dir))
+
+from utils import classify_run_error  # type: ignore[import]
+
+
+class TestClassifyRunError:
+    """Test suite for classify_run_error"""
+
+    def test_rate_limited(self):
+        err = Exception("Error code: 429 - rate limit exceeded")
+        assert classify_run_error(err) == "rate_limited"
+
+    def test_overloaded(self):
+        assert classify_run_error(Exception("API Error: Overloaded")) == "overloaded"
+
+    def test_model_unavailable(self):
+        err = Exception("{'type': 'not_found_error', 'message': 'model: claude-x'}")
+        assert classify_run_error(err) == "model_unavailable"
+
+    def test_other_errors(self):
+        assert classify_run_error(Exception("git clone failed")) is None
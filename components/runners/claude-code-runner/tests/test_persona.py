@@ -0,0 +1,49 @@
+"""
+Test cases for applying an agent persona in config.py
+
+The backend forwards the session's persona with each run; the runner limits
+MCP servers and built-in tools to what the persona lists.
+"""
+
+import sys
+from pathlib import Path
+
+# Add parent directory to path for importing config module
+runner_dir = Path(__file__).parent.parent
+if str(runner_dir) not in sys.path:
+    sys.path.insert(0, str(runner_dir))
+
+from config import apply_persona_mcp_servers, apply_persona_tools  # type: ignore[import]
+
+SERVERS = {"github": {"command": "gh"}, "jira": {"type": "http", "url": "https://j"}}
+TOOLS = ["Read", "Write", "Bash", "Grep", "mcp__github", "mcp__session"]
+
+
+class TestApplyPersonaMCPServers:
+    """Test suite for apply_persona_mcp_servers"""
+
+    def test_no_persona(self):
+        assert apply_persona_mcp_servers(None, SERVERS) == SERVERS
+        assert apply_persona_mcp_servers({"name": "p"}, SERVERS) == SERVERS
+
+    def test_limits_servers(self):
+        persona = {"name": "reviewer", "mcpServers": ["jira", "missing"]}
+        assert apply_persona_mcp_servers(persona, SERVERS) == {"jira": SERVERS["jira"]}
+
+
+class TestApplyPersonaTools:
+    """Test suite for apply_persona_tools"""
+
+    def test_no_tools(self):
+        assert apply_persona_tools(None, TOOLS) == TOOLS
+        assert apply_persona_tools({"allowedTools": []}, TOOLS) == TOOLS
+
+    def test_replaces_builtin_tools(self):
+        persona = {"name": "reviewer", "allowedTools": ["Read", "Grep", "WebFetch"]}
+        assert apply_persona_tools(persona, TOOLS) == [
+            "mcp__github",
+            "mcp__session",
+            "Read",
+            "Grep",
+            "WebFetch",
+        ]
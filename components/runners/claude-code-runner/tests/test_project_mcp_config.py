@@ -0,0 +1,90 @@
+"""
+Test cases for merging project-registered MCP servers in config.py
+
+The operator mounts the project's MCP server registry into the runner; enabled
+entries must be converted to the SDK format and override same-named defaults.
+"""
+
+import json
+import sys
+from pathlib import Path
+
+# Add parent directory to path for importing config module
+runner_dir = Path(__file__).parent.parent
+if str(runner_dir) not in sys.path:
+    sys.path.insert(0, str(runner_dir))
+
+from config import load_mcp_config, project_mcp_server_entry  # type: ignore[import]
+from context import RunnerContext  # type: ignore[import]
+
+
+def _context(tmp_path, defaults, registry):
+    default_file = tmp_path / ".mcp.json"
+    default_file.write_text(json.dumps({"mcpServers": defaults}))
+    registry_file = tmp_path / "mcp-servers.json"
+    registry_file.write_text(json.dumps(registry))
+    return RunnerContext(
+        session_id="s",
+        workspace_path=str(tmp_path),
+        environment={
+            "MCP_CONFIG_FILE": str(default_file),
+            "PROJECT_MCP_CONFIG_FILE": str(registry_file),
+        },
+    )
+
+
+class TestProjectMCPServerEntry:
+    """Test suite for project_mcp_server_entry"""
+
+    def test_stdio(self):
+        entry = project_mcp_server_entry(
+            {"transport": "stdio", "command": "npx", "args": ["-y", "srv"]}
+        )
+        assert entry == {"command": "npx", "args": ["-y", "srv"]}
+
+    def test_http_with_headers(self):
+        entry = project_mcp_server_entry(
+            {
+                "transport": "http",
+                "url": "https://mcp.example.com",
+                "headers": {"Authorization": "Bearer ${DOCS_TOKEN}"},
+            }
+        )
+        assert entry == {
+            "type": "http",
+            "url": "https://mcp.example.com",
+            "headers": {"Authorization": "Bearer ${DOCS_TOKEN}"},
+        }
+
+    def test_unknown_transport(self):
+        assert project_mcp_server_entry({"transport": "websocket"}) is None
+
+
+class TestLoadMCPConfigWithProjectServers:
+    """Test suite for load_mcp_config merging the project registry"""
+
+    def test_project_servers_override_defaults(self, tmp_path, monkeypatch):
+        monkeypatch.setenv("DOCS_TOKEN", "secret")
+        ctx = _context(
+            tmp_path,
+            {"docs": {"command": "old"}, "webfetch": {"command": "fetch"}},
+            [
+                {
+                    "name": "docs",
+                    "transport": "sse",
+                    "url": "https://docs.example.com/sse",
+                    "headers": {"Authorization": "Bearer ${DOCS_TOKEN}"},
+                    "enabled": True,
+                },
+                {
+                    "name": "disabled",
+                    "transport": "stdio",
+                    "command": "x",
+                    "enabled": False,
+                },
+            ],
+        )
+        servers = load_mcp_config(ctx, str(tmp_path))
+        assert set(servers) == {"docs", "webfetch"}
+        assert servers["docs"]["type"] == "sse"
+        assert servers["docs"]["headers"]["Authorization"] == "Bearer secret"
@@ -0,0 +1,54 @@
+"""
+Test cases for sub-agent run tracking (subagents.py)
+
+Task tool calls register a child run with the backend; messages from the
+sub-agent map to the child's run ID until the Task call has a result.
+"""
+
+import asyncio
+import sys
+from pathlib import Path
+
+# Add parent directory to path for importing subagents module
+runner_dir = Path(__file__).parent.parent
+if str(runner_dir) not in sys.path:
+    sys.path.insert(0, str(runner_dir))
+
+import subagents  # type: ignore[import]
+from subagents import SubAgentRuns  # type: ignore[import]
+
+
+class TestSubAgentRuns:
+    """Test suite for SubAgentRuns"""
+
+    def test_registered_sub_agent_gets_its_own_run(self, monkeypatch):
+        calls = []
+
+        def fake_register(parent_run_id, child_run_id, branch_id, name):
+            calls.append((parent_run_id, branch_id, name))
+            return True
+
+        monkeypatch.setattr(subagents, "register_child_run", fake_register)
+        runs = SubAgentRuns("run-1")
+        child = asyncio.run(
+            runs.start("toolu_1", {"subagent_type": "code-reviewer"}, "run-1")
+        )
+
+        assert child and child != "run-1"
+        assert calls == [("run-1", "toolu_1", "code-reviewer")]
+        assert runs.run_id_for("toolu_1") == child
+        assert runs.run_id_for(None) == "run-1"
+        assert runs.finish("toolu_1") == child
+        assert runs.run_id_for("toolu_1") == "run-1"
+
+    def test_unregistered_sub_agent_streams_as_parent(self, monkeypatch):
+        monkeypatch.setattr(subagents, "register_child_run", lambda *args: False)
+        runs = SubAgentRuns("run-1")
+
+        assert asyncio.run(runs.start("toolu_1", {}, "run-1")) is None
+        assert runs.run_id_for("toolu_1") == "run-1"
+        assert runs.finish("toolu_1") is None
+
+    def test_register_needs_backend_env(self, monkeypatch):
+        monkeypatch.delenv("BACKEND_API_URL", raising=False)
+        assert subagents.register_child_run("run-1", "child", "toolu_1", "x") is False
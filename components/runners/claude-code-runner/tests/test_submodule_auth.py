@@ -0,0 +1,68 @@
+"""
+Test cases for submodule URL resolution in auth.py
+
+Submodule credentials are configured per host, so every .gitmodules URL form
+(relative, scp-style SSH, ssh://, https) must resolve to the right host.
+"""
+
+import sys
+from pathlib import Path
+
+# Add parent directory to path for importing auth module
+runner_dir = Path(__file__).parent.parent
+if str(runner_dir) not in sys.path:
+    sys.path.insert(0, str(runner_dir))
+
+from auth import resolve_submodule_url, submodule_hosts  # type: ignore[import]
+
+
+class TestResolveSubmoduleURL:
+    """Test suite for resolve_submodule_url"""
+
+    parent = "https://github.com/org/app.git"
+
+    def test_relative_sibling(self):
+        assert (
+            resolve_submodule_url("../lib.git", self.parent)
+            == "https://github.com/org/lib.git"
+        )
+
+    def test_relative_other_org(self):
+        assert (
+            resolve_submodule_url("../../other/lib.git", self.parent)
+            == "https://github.com/other/lib.git"
+        )
+
+    def test_scp_like_ssh(self):
+        assert (
+            resolve_submodule_url("git@gitlab.example.com:team/lib.git", self.parent)
+            == "https://gitlab.example.com/team/lib.git"
+        )
+
+    def test_ssh_scheme(self):
+        assert (
+            resolve_submodule_url("ssh://git@github.com/org/lib.git", self.parent)
+            == "https://github.com/org/lib.git"
+        )
+
+    def test_https_unchanged(self):
+        url = "https://gitlab.com/group/lib.git"
+        assert resolve_submodule_url(url, self.parent) == url
+
+
+class TestSubmoduleHosts:
+    """Test suite for submodule_hosts"""
+
+    def test_collects_unique_hosts(self):
+        hosts = submodule_hosts(
+            [
+                "../lib.git",
+                "git@gitlab.example.com:team/proto.git",
+                "https://GitHub.com/org/tools.git",
+            ],
+            "https://github.com/org/app.git",
+        )
+        assert hosts == {"github.com", "gitlab.example.com"}
+
+    def test_ignores_local_paths(self):
+        assert submodule_hosts(["/srv/git/lib.git"], "https://github.com/o/a") == set()
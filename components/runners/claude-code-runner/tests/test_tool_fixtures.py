@@ -0,0 +1,52 @@
+"""
+Test cases for tool-result fixtures used by evaluation replays (fixtures.py)
+
+Replayed tool calls must be answered with the recorded result of the matching
+call, preferring identical input and never reusing a fixture.
+"""
+
+import asyncio
+import sys
+from pathlib import Path
+
+# Add parent directory to path for importing fixtures module
+runner_dir = Path(__file__).parent.parent
+if str(runner_dir) not in sys.path:
+    sys.path.insert(0, str(runner_dir))
+
+from fixtures import create_fixture_hook, match_fixture  # type: ignore[import]
+
+FIXTURES = [
+    {"tool": "Read", "input": '{"file_path": "a.py"}', "result": "print('a')"},
+    {"tool": "Read", "input": '{"file_path": "b.py"}', "result": "print('b')"},
+    {"tool": "Bash", "input": '{"command": "ls"}', "result": "a.py b.py"},
+]
+
+
+class TestMatchFixture:
+    """Test suite for match_fixture"""
+
+    def test_identical_input_wins(self):
+        assert match_fixture(FIXTURES, set(), "Read", {"file_path": "b.py"}) == 1
+
+    def test_falls_back_to_next_unused_call(self):
+        assert match_fixture(FIXTURES, {0}, "Read", {"file_path": "c.py"}) == 1
+        assert match_fixture(FIXTURES, {0, 1}, "Read", {"file_path": "c.py"}) is None
+
+    def test_unknown_tool(self):
+        assert match_fixture(FIXTURES, set(), "Write", {"file_path": "a.py"}) is None
+
+
+class TestFixtureHook:
+    """Test suite for create_fixture_hook"""
+
+    def test_denies_with_recorded_result(self):
+        hook = create_fixture_hook(FIXTURES)
+        call = {"tool_name": "Bash", "tool_input": {"command": "ls"}}
+
+        out = asyncio.run(hook(call, "t1", None))["hookSpecificOutput"]
+        assert out["permissionDecision"] == "deny"
+        assert "a.py b.py" in out["permissionDecisionReason"]
+
+        out = asyncio.run(hook(call, "t2", None))["hookSpecificOutput"]
+        assert "No recorded result" in out["permissionDecisionReason"]
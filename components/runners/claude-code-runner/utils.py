@@ -128,6 +128,24 @@ def expand_env_vars(value: Any) -> Any:
     return value
 
 
+# Error text meaning another model may succeed; the backend falls back on these codes.
+# IMPORTANT: Keep in sync with backend (websocket/model_fallback.go)
+_MODEL_ERROR_PATTERNS = [
+    ("rate_limited", ("429", "rate limit", "rate_limit")),
+    ("overloaded", ("529", "overloaded")),
+    ("model_unavailable", ("model_not_found", "not_found_error", "model is not available")),
+]
+
+
+def classify_run_error(error: Exception) -> str | None:
+    """Return the RUN_ERROR code for model availability errors, or None."""
+    text = str(error).lower()
+    for code, patterns in _MODEL_ERROR_PATTERNS:
+        if any(p in text for p in patterns):
+            return code
+    return None
+
+
 async def run_cmd(
     cmd: list,
     cwd: str | None = None,
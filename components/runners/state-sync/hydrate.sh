@@ -168,6 +168,77 @@ git config --global user.email "bot@ambient-code.local" || echo "Warning: failed
 # Mark workspace as safe (in case runner needs it)
 git config --global --add safe.directory /workspace 2>/dev/null || true
 
+# Repo mirror cache key; keep in sync with git.MirrorKey in the backend
+mirror_key() {
+    local u="$1"
+    u="${u%/}"
+    u="${u%.git}"
+    printf '%s' "$u" | tr '[:upper:]' '[:lower:]' | sha256sum | cut -d' ' -f1
+}
+
+# Bootstrap a clone from the in-cluster repo mirror, then repoint origin at the real
+# remote and fetch whatever the mirror is missing. Uncached repos are queued for
+# mirroring so later sessions benefit. Returns non-zero to fall back to a direct clone.
+# Args: repo_url branch repo_dir clone_args
+clone_from_mirror() {
+    local repo_url="$1" branch="$2" repo_dir="$3" clone_args="$4"
+    if [ -z "$GIT_MIRROR_SERVICE_URL" ] || [ "${repo_url#https://}" = "$repo_url" ]; then
+        return 1
+    fi
+    local svc="${GIT_MIRROR_SERVICE_URL%/}"
+    local encoded cached
+    encoded=$(jq -rn --arg u "$repo_url" '$u | @uri')
+    cached=$(curl -sf --max-time 5 "$svc/mirror/status?url=$encoded" | jq -r '.cached // false' 2>/dev/null || echo "false")
+    if [ "$cached" != "true" ]; then
+        curl -sf --max-time 5 -X POST -H "Content-Type: application/json" \
+            -d "$(jq -n --arg u "$repo_url" '{url: $u}')" "$svc/mirror/sync" >/dev/null 2>&1 || true
+        return 1
+    fi
+
+    echo "    using repo mirror"
+    # LFS objects are never served by the mirror; fetch them from the real remote later
+    # shellcheck disable=SC2086
+    GIT_LFS_SKIP_SMUDGE=1 git clone $clone_args "$svc/mirror/git/$(mirror_key "$repo_url").git" "$repo_dir" 2>&1 || return 1
+    git -C "$repo_dir" remote set-url origin "$repo_url" || return 1
+    # Catch up with commits pushed since the mirror was last synced (best effort)
+    if git -C "$repo_dir" fetch origin "$branch" 2>&1; then
+        git -C "$repo_dir" update-ref "refs/heads/$branch" "refs/remotes/origin/$branch" 2>/dev/null || true
+    else
+        echo "    ⚠ Could not refresh from $repo_url; using mirror state"
+    fi
+    return 0
+}
+
+# Install LFS hooks and download objects, skipping any over the size threshold
+# Args: repo_dir skip_smudge max_object_size_mb
+pull_lfs_objects() {
+    local repo_dir="$1" skip_smudge="$2" max_mb="$3"
+    if ! git -C "$repo_dir" lfs install --local >/dev/null 2>&1; then
+        echo "  ⚠ git-lfs not available; leaving LFS pointer files"
+        return 0
+    fi
+    if [ "$skip_smudge" = "true" ]; then
+        echo "    LFS objects skipped (pointer files only)"
+        return 0
+    fi
+    if [ "$max_mb" -gt 0 ] 2>/dev/null; then
+        local max_bytes=$((max_mb * 1024 * 1024))
+        local include
+        include=$(git -C "$repo_dir" lfs ls-files --json 2>/dev/null \
+            | jq -r --argjson max "$max_bytes" '[.files[]? | select(.size <= $max) | .name] | join(",")')
+        local skipped
+        skipped=$(git -C "$repo_dir" lfs ls-files --json 2>/dev/null \
+            | jq -r --argjson max "$max_bytes" '[.files[]? | select(.size > $max)] | length')
+        echo "    LFS: skipping ${skipped:-0} object(s) over ${max_mb}MiB"
+        if [ -z "$include" ]; then
+            return 0
+        fi
+        git -C "$repo_dir" lfs pull --include "$include" 2>&1 || echo "  ⚠ Failed to pull LFS objects"
+    else
+        git -C "$repo_dir" lfs pull 2>&1 || echo "  ⚠ Failed to pull LFS objects"
+    fi
+}
+
 # Clone repos from REPOS_JSON
 if [ -n "$REPOS_JSON" ] && [ "$REPOS_JSON" != "null" ] && [ "$REPOS_JSON" != "" ]; then
     echo "Cloning repositories from spec..."
@@ -179,6 +250,12 @@ if [ -n "$REPOS_JSON" ] && [ "$REPOS_JSON" != "null" ] && [ "$REPOS_JSON" != ""
         while [ $i -lt $REPO_COUNT ]; do
             REPO_URL=$(echo "$REPOS_JSON" | jq -r ".[$i].url // empty" 2>/dev/null || echo "")
             REPO_BRANCH=$(echo "$REPOS_JSON" | jq -r ".[$i].branch // \"main\"" 2>/dev/null || echo "main")
+            REPO_DEPTH=$(echo "$REPOS_JSON" | jq -r ".[$i].depth // 0" 2>/dev/null || echo "0")
+            REPO_SINGLE_BRANCH=$(echo "$REPOS_JSON" | jq -r ".[$i].singleBranch // true" 2>/dev/null || echo "true")
+            REPO_SPARSE_PATHS=$(echo "$REPOS_JSON" | jq -r ".[$i].sparsePaths // [] | .[]" 2>/dev/null || echo "")
+            REPO_LFS=$(echo "$REPOS_JSON" | jq -r "if .[$i].lfs then \"true\" else \"false\" end" 2>/dev/null || echo "false")
+            REPO_LFS_SKIP_SMUDGE=$(echo "$REPOS_JSON" | jq -r ".[$i].lfs.skipSmudge // false" 2>/dev/null || echo "false")
+            REPO_LFS_MAX_MB=$(echo "$REPOS_JSON" | jq -r ".[$i].lfs.maxObjectSizeMB // 0" 2>/dev/null || echo "0")
             
             # Derive repo name from URL
             REPO_NAME=$(basename "$REPO_URL" .git 2>/dev/null || echo "")
@@ -190,8 +267,51 @@ if [ -n "$REPOS_JSON" ] && [ "$REPOS_JSON" != "null" ] && [ "$REPOS_JSON" != ""
                 # Mark repo directory as safe
                 git config --global --add safe.directory "$REPO_DIR" 2>/dev/null || true
                 
+                # Partial clone options for large repositories
+                CLONE_ARGS="--branch $REPO_BRANCH"
+                if [ "$REPO_SINGLE_BRANCH" = "false" ]; then
+                    CLONE_ARGS="$CLONE_ARGS --no-single-branch"
+                else
+                    CLONE_ARGS="$CLONE_ARGS --single-branch"
+                fi
+                if [ "$REPO_DEPTH" -gt 0 ] 2>/dev/null; then
+                    CLONE_ARGS="$CLONE_ARGS --depth $REPO_DEPTH"
+                    echo "    shallow clone (depth: $REPO_DEPTH)"
+                fi
+                if [ -n "$REPO_SPARSE_PATHS" ]; then
+                    CLONE_ARGS="$CLONE_ARGS --filter=blob:none --no-checkout"
+                    echo "    sparse checkout: $(echo "$REPO_SPARSE_PATHS" | tr '\n' ' ')"
+                fi
+
+                # LFS objects are fetched after checkout so the size threshold can be applied
+                LFS_SKIP_SMUDGE=0
+                if [ "$REPO_LFS" = "true" ] && { [ "$REPO_LFS_SKIP_SMUDGE" = "true" ] || [ "$REPO_LFS_MAX_MB" -gt 0 ] 2>/dev/null; }; then
+                    LFS_SKIP_SMUDGE=1
+                fi
+
                 # Clone repository (for private repos, runner will handle token injection)
-                if git clone --branch "$REPO_BRANCH" --single-branch "$REPO_URL" "$REPO_DIR" 2>&1; then
+                CLONED=false
+                if clone_from_mirror "$REPO_URL" "$REPO_BRANCH" "$REPO_DIR" "$CLONE_ARGS"; then
+                    CLONED=true
+                    if [ -z "$REPO_SPARSE_PATHS" ]; then
+                        GIT_LFS_SKIP_SMUDGE=$LFS_SKIP_SMUDGE git -C "$REPO_DIR" reset --hard --quiet 2>&1 || true
+                    fi
+                else
+                    rm -rf "$REPO_DIR"
+                    # shellcheck disable=SC2086
+                    if GIT_LFS_SKIP_SMUDGE=$LFS_SKIP_SMUDGE git clone $CLONE_ARGS "$REPO_URL" "$REPO_DIR" 2>&1; then
+                        CLONED=true
+                    fi
+                fi
+                if [ "$CLONED" = "true" ]; then
+                    if [ -n "$REPO_SPARSE_PATHS" ]; then
+                        echo "$REPO_SPARSE_PATHS" | git -C "$REPO_DIR" sparse-checkout set --cone --stdin 2>&1 \
+                            && GIT_LFS_SKIP_SMUDGE=$LFS_SKIP_SMUDGE git -C "$REPO_DIR" checkout 2>&1 \
+                            || echo "  ⚠ Failed to apply sparse checkout for $REPO_NAME"
+                    fi
+                    if [ "$REPO_LFS" = "true" ]; then
+                        pull_lfs_objects "$REPO_DIR" "$REPO_LFS_SKIP_SMUDGE" "$REPO_LFS_MAX_MB"
+                    fi
                     echo "  ✓ Cloned $REPO_NAME"
                 else
                     echo "  ⚠ Failed to clone $REPO_NAME (may require authentication)"
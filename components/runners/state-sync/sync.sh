@@ -11,6 +11,15 @@ SESSION_NAME="${SESSION_NAME:-unknown}"
 SYNC_INTERVAL="${SYNC_INTERVAL:-60}"
 MAX_SYNC_SIZE="${MAX_SYNC_SIZE:-1073741824}"  # 1GB default
 
+# Warm pool runners learn their session when claimed; the runner writes it here
+if [ -n "${SESSION_NAME_FILE}" ]; then
+    echo "Waiting for a session to claim this runner..."
+    until [ -s "${SESSION_NAME_FILE}" ]; do
+        sleep 2
+    done
+    SESSION_NAME="$(cat "${SESSION_NAME_FILE}")"
+fi
+
 # Sanitize inputs to prevent path traversal
 NAMESPACE="${NAMESPACE//[^a-zA-Z0-9-]/}"
 SESSION_NAME="${SESSION_NAME//[^a-zA-Z0-9-]/}"